@@ -58,12 +58,26 @@ type ConnectionArgs struct {
 	// OpenID Connect tokens
 	OIDCTokens *oidc.Tokens[*oidc.IDTokenClaims]
 
+	// OpenID Connect client ID and secret of a service account. When set, the client credentials flow is used to
+	// get access tokens instead of the interactive device code flow.
+	OIDCClientID     string
+	OIDCClientSecret string
+
+	// Bearer token issued by LXD (used when AuthType is "bearer")
+	BearerToken string
+
 	// Skip automatic GetServer request upon connection
 	SkipGetServer bool
 
 	// Caching support for image servers
 	CachePath   string
 	CacheExpiry time.Duration
+
+	// Mirrors of a simplestreams server, tried in order when downloading an image file from the server fails
+	Mirrors []string
+
+	// Maximum number of parallel ranged requests used to download an image file from a simplestreams server
+	DownloadSegments int
 }
 
 // ConnectLXD lets you connect to a remote LXD daemon over HTTPs.
@@ -261,9 +275,14 @@ func ConnectSimpleStreams(url string, args *ConnectionArgs) (ImageServer, error)
 
 	// Initialize the client struct
 	server := ProtocolSimpleStreams{
-		httpHost:        url,
-		httpUserAgent:   args.UserAgent,
-		httpCertificate: args.TLSServerCert,
+		httpHost:         url,
+		httpUserAgent:    args.UserAgent,
+		httpCertificate:  args.TLSServerCert,
+		downloadSegments: args.DownloadSegments,
+	}
+
+	for _, mirror := range args.Mirrors {
+		server.mirrors = append(server.mirrors, strings.TrimSuffix(mirror, "/"))
 	}
 
 	// Setup the HTTP client
@@ -305,6 +324,40 @@ func ConnectSimpleStreams(url string, args *ConnectionArgs) (ImageServer, error)
 	return &server, nil
 }
 
+// ConnectOCI lets you connect to a remote OCI registry over HTTPs.
+//
+// Images are looked up and retrieved using the skopeo tool and converted into LXD images using the umoci tool.
+// Unless a remote server is trusted by the system CA, the remote certificate must be provided (TLSServerCert).
+func ConnectOCI(url string, args *ConnectionArgs) (ImageServer, error) {
+	logger.Debug("Connecting to a remote OCI registry", logger.Ctx{"URL": url})
+
+	// Cleanup URL
+	url = strings.TrimSuffix(url, "/")
+
+	// Use empty args if not specified
+	if args == nil {
+		args = &ConnectionArgs{}
+	}
+
+	// Initialize the client struct
+	server := ProtocolOCI{
+		httpHost:        url,
+		httpUserAgent:   args.UserAgent,
+		httpCertificate: args.TLSServerCert,
+		cache:           map[string]ociInfo{},
+	}
+
+	// Setup the HTTP client
+	httpClient, err := tlsHTTPClient(args.HTTPClient, args.TLSClientCert, args.TLSClientKey, args.TLSCA, args.TLSServerCert, args.InsecureSkipVerify, args.Proxy, args.TransportWrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	server.http = httpClient
+
+	return &server, nil
+}
+
 // Internal function called by ConnectLXD and ConnectPublicLXD.
 func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (InstanceServer, error) {
 	// Use empty args if not specified
@@ -332,7 +385,7 @@ func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (Ins
 		eventListeners:     make(map[string][]*EventListener),
 	}
 
-	if shared.ValueInSlice(args.AuthType, []string{api.AuthenticationMethodOIDC}) {
+	if shared.ValueInSlice(args.AuthType, []string{api.AuthenticationMethodOIDC, api.AuthenticationMethodBearer}) {
 		server.RequireAuthenticated(true)
 	}
 
@@ -348,7 +401,11 @@ func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (Ins
 
 	server.http = httpClient
 	if args.AuthType == api.AuthenticationMethodOIDC {
-		server.setupOIDCClient(args.OIDCTokens)
+		server.setupOIDCClient(args.OIDCTokens, args.OIDCClientID, args.OIDCClientSecret)
+	}
+
+	if args.AuthType == api.AuthenticationMethodBearer {
+		server.bearerToken = args.BearerToken
 	}
 
 	// Test the connection and seed the server information
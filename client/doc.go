@@ -143,4 +143,30 @@
 //	if err != nil {
 //	  return err
 //	}
+//
+// # Example - cancellation
+//
+// This stops an instance, giving up if it takes more than a minute
+//
+//	// Connect to LXD over the Unix socket
+//	c, err := lxd.ConnectLXDUnix("", nil)
+//	if err != nil {
+//	  return err
+//	}
+//
+//	// Setup a context for the requests
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//
+//	// All the requests of the client are cancelled with the context
+//	op, err := c.WithContext(ctx).UpdateInstanceState("c1", api.InstanceStatePut{Action: "stop"}, "")
+//	if err != nil {
+//	  return err
+//	}
+//
+//	// Waiting for the operation also stops once the context is done
+//	err = op.Wait()
+//	if err != nil {
+//	  return err
+//	}
 package lxd
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -76,12 +77,14 @@ type InstanceServer interface {
 	GetMetrics() (metrics string, err error)
 	GetServer() (server *api.Server, ETag string, err error)
 	GetServerResources() (resources *api.Resources, err error)
+	GetServerStartup() (startup *api.ServerStartup, err error)
 	UpdateServer(server api.ServerPut, ETag string) (err error)
 	HasExtension(extension string) (exists bool)
 	RequireAuthenticated(authenticated bool)
 	IsClustered() (clustered bool)
 	UseTarget(name string) (client InstanceServer)
 	UseProject(name string) (client InstanceServer)
+	WithContext(ctx context.Context) (client InstanceServer)
 
 	// Certificate functions
 	GetCertificateFingerprints() (fingerprints []string, err error)
@@ -91,6 +94,9 @@ type InstanceServer interface {
 	UpdateCertificate(fingerprint string, certificate api.CertificatePut, ETag string) (err error)
 	DeleteCertificate(fingerprint string) (err error)
 	CreateCertificateToken(certificate api.CertificatesPost) (op Operation, err error)
+	IssueCertificate(certificate api.CertificatesPost) (issued *api.CertificateIssued, err error)
+	RenewCertificate(fingerprint string, certificate api.CertificatePut, ETag string) (issued *api.CertificateIssued, err error)
+	GetCertificateAuthority() (ca *api.CertificateAuthority, err error)
 
 	// Container functions
 	//
@@ -163,12 +169,16 @@ type InstanceServer interface {
 	GetInstancesFullWithFilter(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
 	GetInstancesAllProjectsWithFilter(instanceType api.InstanceType, filters []string) (instances []api.Instance, err error)
 	GetInstancesFullAllProjectsWithFilter(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
+	GetInstancesWithNamePattern(instanceType api.InstanceType, pattern string) (instances []api.Instance, err error)
+	GetInstancesWithState(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
+	GetInstancesWithStateAllProjects(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
 	GetInstance(name string) (instance *api.Instance, ETag string, err error)
 	GetInstanceFull(name string) (instance *api.InstanceFull, ETag string, err error)
 	CreateInstance(instance api.InstancesPost) (op Operation, err error)
 	CreateInstanceFromImage(source ImageServer, image api.Image, req api.InstancesPost) (op RemoteOperation, err error)
 	CopyInstance(source InstanceServer, instance api.Instance, args *InstanceCopyArgs) (op RemoteOperation, err error)
 	UpdateInstance(name string, instance api.InstancePut, ETag string) (op Operation, err error)
+	UpdateInstanceDryRun(name string, instance api.InstancePut, ETag string) (diff *api.ConfigDiff, err error)
 	RenameInstance(name string, instance api.InstancePost) (op Operation, err error)
 	MigrateInstance(name string, instance api.InstancePost) (op Operation, err error)
 	DeleteInstance(name string) (op Operation, err error)
@@ -181,11 +191,14 @@ type InstanceServer interface {
 	ExecInstance(instanceName string, exec api.InstanceExecPost, args *InstanceExecArgs) (op Operation, err error)
 	ConsoleInstance(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (op Operation, err error)
 	ConsoleInstanceDynamic(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (Operation, func(io.ReadWriteCloser) error, error)
+	CreateInstanceAccessToken(instanceName string, token api.InstanceAccessTokensPost) (accessToken *api.InstanceAccessToken, err error)
 
 	GetInstanceConsoleLog(instanceName string, args *InstanceConsoleLogArgs) (content io.ReadCloser, err error)
+	GetInstanceConsoleSessions(instanceName string) (sessions []api.InstanceConsoleSession, err error)
 	DeleteInstanceConsoleLog(instanceName string, args *InstanceConsoleLogArgs) (err error)
 
 	GetInstanceFile(instanceName string, path string) (content io.ReadCloser, resp *InstanceFileResponse, err error)
+	GetInstanceFileGlob(instanceName string, pattern string) (paths []string, err error)
 	CreateInstanceFile(instanceName string, path string, args InstanceFileArgs) (err error)
 	DeleteInstanceFile(instanceName string, path string) (err error)
 
@@ -210,12 +223,14 @@ type InstanceServer interface {
 	DeleteInstanceBackup(instanceName string, name string) (op Operation, err error)
 	GetInstanceBackupFile(instanceName string, name string, req *BackupFileRequest) (resp *BackupFileResponse, err error)
 	CreateInstanceFromBackup(args InstanceBackupArgs) (op Operation, err error)
+	CreateInstanceFromDisk(instance api.InstancesPost, args InstanceDiskArgs) (op Operation, err error)
 
 	GetInstanceState(name string) (state *api.InstanceState, ETag string, err error)
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
 
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
 	GetInstanceLogfile(name string, filename string) (content io.ReadCloser, err error)
+	GetInstanceLogfileFollow(name string, filename string, lines int) (conn *websocket.Conn, err error)
 	DeleteInstanceLogfile(name string, filename string) (err error)
 
 	GetInstanceMetadata(name string) (metadata *api.ImageMetadata, ETag string, err error)
@@ -237,6 +252,9 @@ type InstanceServer interface {
 	UpdateImage(fingerprint string, image api.ImagePut, ETag string) (err error)
 	DeleteImage(fingerprint string) (op Operation, err error)
 	RefreshImage(fingerprint string) (op Operation, err error)
+	GetImageSyncStatus(fingerprint string) (status *api.ImageSyncStatus, err error)
+	RepairImageSync(fingerprint string) (op Operation, err error)
+	GetImageCacheExpiry(expiry string) (images []api.ImageCacheExpiry, err error)
 	CreateImageSecret(fingerprint string) (op Operation, err error)
 	CreateImageAlias(alias api.ImageAliasesPost) (err error)
 	UpdateImageAlias(name string, alias api.ImageAliasesEntryPut, ETag string) (err error)
@@ -248,16 +266,20 @@ type InstanceServer interface {
 	GetNetworks() (networks []api.Network, err error)
 	GetNetwork(name string) (network *api.Network, ETag string, err error)
 	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
+	GetNetworkDNSRecords(name string) (records []api.NetworkDNSRecord, err error)
 	GetNetworkState(name string) (state *api.NetworkState, err error)
 	CreateNetwork(network api.NetworksPost) (err error)
 	UpdateNetwork(name string, network api.NetworkPut, ETag string) (err error)
+	UpdateNetworkDryRun(name string, network api.NetworkPut, ETag string) (diff *api.ConfigDiff, err error)
 	RenameNetwork(name string, network api.NetworkPost) (err error)
 	DeleteNetwork(name string) (err error)
+	CaptureNetwork(name string, capture api.NetworkCapturePost, args *NetworkCaptureArgs) (op Operation, err error)
 
 	// Network forward functions ("network_forward" API extension)
 	GetNetworkForwardAddresses(networkName string) ([]string, error)
 	GetNetworkForwards(networkName string) ([]api.NetworkForward, error)
 	GetNetworkForward(networkName string, listenAddress string) (forward *api.NetworkForward, ETag string, err error)
+	GetNetworkForwardState(networkName string, listenAddress string) (state *api.NetworkForwardState, err error)
 	CreateNetworkForward(networkName string, forward api.NetworkForwardsPost) error
 	UpdateNetworkForward(networkName string, listenAddress string, forward api.NetworkForwardPut, ETag string) (err error)
 	DeleteNetworkForward(networkName string, listenAddress string) (err error)
@@ -322,9 +344,21 @@ type InstanceServer interface {
 	GetProfile(name string) (profile *api.Profile, ETag string, err error)
 	CreateProfile(profile api.ProfilesPost) (err error)
 	UpdateProfile(name string, profile api.ProfilePut, ETag string) (err error)
+	UpdateProfileDryRun(name string, profile api.ProfilePut, ETag string) (diff *api.ConfigDiff, err error)
 	RenameProfile(name string, profile api.ProfilePost) (err error)
 	DeleteProfile(name string) (err error)
 
+	// Device group functions ("device_groups" API extension)
+	GetDeviceGroupNames() (names []string, err error)
+	GetDeviceGroups() (groups []api.DeviceGroup, err error)
+	GetDeviceGroup(name string) (group *api.DeviceGroup, ETag string, err error)
+	CreateDeviceGroup(group api.DeviceGroupsPost) (err error)
+	UpdateDeviceGroup(name string, group api.DeviceGroupPut, ETag string) (err error)
+	RenameDeviceGroup(name string, group api.DeviceGroupPost) (err error)
+	DeleteDeviceGroup(name string) (err error)
+	AttachInstanceDeviceGroup(instanceName string, groupName string) (err error)
+	DetachInstanceDeviceGroup(instanceName string, groupName string) (err error)
+
 	// Project functions
 	GetProjectNames() (names []string, err error)
 	GetProjects() (projects []api.Project, err error)
@@ -342,8 +376,19 @@ type InstanceServer interface {
 	GetStoragePoolResources(name string) (resources *api.ResourcesStoragePool, err error)
 	CreateStoragePool(pool api.StoragePoolsPost) (err error)
 	UpdateStoragePool(name string, pool api.StoragePoolPut, ETag string) (err error)
+	UpdateStoragePoolDryRun(name string, pool api.StoragePoolPut, ETag string) (diff *api.ConfigDiff, err error)
 	DeleteStoragePool(name string) (err error)
 
+	// Storage pool benchmark functions ("storage_pool_benchmark" API extension)
+	GetStoragePoolBenchmarks(poolName string) (benchmarks []api.StoragePoolBenchmark, err error)
+	GetStoragePoolBenchmark(poolName string, id int64) (benchmark *api.StoragePoolBenchmark, err error)
+	CreateStoragePoolBenchmark(poolName string, benchmark api.StoragePoolBenchmarksPost) (op Operation, err error)
+	DeleteStoragePoolBenchmark(poolName string, id int64) (err error)
+
+	// Storage pool migration functions ("storage_pool_migrate" API extension)
+	GetStoragePoolMigrateVolumes(poolName string, targetPoolName string) (volumes []api.StoragePoolMigrateVolume, err error)
+	MigrateStoragePool(poolName string, migration api.StoragePoolMigratePost) (op Operation, err error)
+
 	// Storage bucket functions ("storage_buckets" API extension)
 	GetStoragePoolBucketNames(poolName string) ([]string, error)
 	GetStoragePoolBuckets(poolName string) ([]api.StorageBucket, error)
@@ -388,6 +433,10 @@ type InstanceServer interface {
 	RenameStoragePoolVolumeSnapshot(pool string, volumeType string, volumeName string, snapshotName string, snapshot api.StorageVolumeSnapshotPost) (op Operation, err error)
 	UpdateStoragePoolVolumeSnapshot(pool string, volumeType string, volumeName string, snapshotName string, volume api.StorageVolumeSnapshotPut, ETag string) (err error)
 
+	// Storage volume snapshot SFTP functions ("storage_volume_snapshot_sftp" API extension)
+	GetStoragePoolVolumeSnapshotSFTPConn(pool string, volumeType string, volumeName string, snapshotName string) (net.Conn, error)
+	GetStoragePoolVolumeSnapshotSFTP(pool string, volumeType string, volumeName string, snapshotName string) (*sftp.Client, error)
+
 	// Storage volume backup functions ("custom_volume_backup" API extension)
 	GetStoragePoolVolumeBackupNames(pool string, volName string) (names []string, err error)
 	GetStoragePoolVolumeBackups(pool string, volName string) (backups []api.StoragePoolVolumeBackup, err error)
@@ -401,6 +450,9 @@ type InstanceServer interface {
 	// Storage volume ISO import function ("custom_volume_iso" API extension)
 	CreateStoragePoolVolumeFromISO(pool string, args StoragePoolVolumeBackupArgs) (op Operation, err error)
 
+	// Storage volume URL import function ("storage_volume_import_url" API extension)
+	CreateStoragePoolVolumeFromURL(pool string, volume api.StorageVolumesPost) (op Operation, err error)
+
 	// Cluster functions ("cluster" API extensions)
 	GetCluster() (cluster *api.Cluster, ETag string, err error)
 	UpdateCluster(cluster api.ClusterPut, ETag string) (op Operation, err error)
@@ -421,6 +473,14 @@ type InstanceServer interface {
 	DeleteClusterGroup(name string) error
 	UpdateClusterGroup(name string, group api.ClusterGroupPut, ETag string) error
 	GetClusterGroup(name string) (*api.ClusterGroup, string, error)
+	GetClusterInventory() (entries []api.ClusterInventoryEntry, err error)
+	GetClusterJoinPreseed(serverName string, serverAddress string) (preseed *api.InitPreseed, err error)
+	CheckClusterJoin(member api.ClusterMemberJoinCheckPost) (check *api.ClusterMemberJoinCheck, err error)
+	GetClusterDrift() (drift []api.ClusterDrift, err error)
+	GetClusterState() (clusterState *api.ClusterState, err error)
+
+	// Audit log functions ("audit_log" API extension)
+	GetAuditLog(since time.Time) (entries []api.AuditEntry, err error)
 
 	// Warning functions
 	GetWarningUUIDs() (uuids []string, err error)
@@ -437,6 +497,13 @@ type InstanceServer interface {
 	UpdateAuthGroup(groupName string, groupPut api.AuthGroupPut, ETag string) error
 	RenameAuthGroup(groupName string, groupPost api.AuthGroupPost) error
 	DeleteAuthGroup(groupName string) error
+	GetAuthRoleNames() (roleNames []string, err error)
+	GetAuthRoles() (roles []api.AuthRole, err error)
+	GetAuthRole(roleName string) (role *api.AuthRole, ETag string, err error)
+	CreateAuthRole(rolesPost api.AuthRolesPost) error
+	UpdateAuthRole(roleName string, rolePut api.AuthRolePut, ETag string) error
+	RenameAuthRole(roleName string, rolePost api.AuthRolePost) error
+	DeleteAuthRole(roleName string) error
 	GetIdentityAuthenticationMethodsIdentifiers() (authMethodsIdentifiers map[string][]string, err error)
 	GetIdentityIdentifiersByAuthenticationMethod(authenticationMethod string) (identifiers []string, err error)
 	GetIdentities() (identities []api.Identity, err error)
@@ -444,6 +511,10 @@ type InstanceServer interface {
 	GetIdentity(authenticationMethod string, nameOrIdentifier string) (identity *api.Identity, ETag string, err error)
 	GetCurrentIdentityInfo() (identityInfo *api.IdentityInfo, ETag string, err error)
 	UpdateIdentity(authenticationMethod string, nameOrIdentifier string, identityPut api.IdentityPut, ETag string) error
+	CreateIdentityBearer(identitiesBearerPost api.IdentitiesBearerPost) error
+	DeleteIdentity(authenticationMethod string, nameOrIdentifier string) error
+	IssueIdentityBearerToken(nameOrIdentifier string) (token *api.IdentityBearerToken, err error)
+	RevokeIdentityBearerToken(nameOrIdentifier string) error
 	GetIdentityProviderGroupNames() (identityProviderGroupNames []string, err error)
 	GetIdentityProviderGroups() (identityProviderGroups []api.IdentityProviderGroup, err error)
 	GetIdentityProviderGroup(identityProviderGroupName string) (identityProviderGroup *api.IdentityProviderGroup, ETag string, err error)
@@ -451,6 +522,13 @@ type InstanceServer interface {
 	UpdateIdentityProviderGroup(identityProviderGroupName string, identityProviderGroupPut api.IdentityProviderGroupPut, ETag string) error
 	RenameIdentityProviderGroup(identityProviderGroupName string, identityProviderGroupPost api.IdentityProviderGroupPost) error
 	DeleteIdentityProviderGroup(identityProviderGroupName string) error
+	GetIdentityProviderGroupRuleNames() (ruleNames []string, err error)
+	GetIdentityProviderGroupRules() (rules []api.IdentityProviderGroupRule, err error)
+	GetIdentityProviderGroupRule(ruleName string) (rule *api.IdentityProviderGroupRule, ETag string, err error)
+	CreateIdentityProviderGroupRule(rule api.IdentityProviderGroupRulesPost) error
+	UpdateIdentityProviderGroupRule(ruleName string, rulePut api.IdentityProviderGroupRulePut, ETag string) error
+	RenameIdentityProviderGroupRule(ruleName string, rulePost api.IdentityProviderGroupRulePost) error
+	DeleteIdentityProviderGroupRule(ruleName string) error
 	GetPermissions(args GetPermissionsArgs) (permissions []api.Permission, err error)
 	GetPermissionsInfo(args GetPermissionsArgs) (permissions []api.PermissionInfo, err error)
 
@@ -503,7 +581,7 @@ type ImageCreateArgs struct {
 	// Filename for the rootfs file
 	RootfsName string
 
-	// Progress handler (called with upload progress)
+	// Progress handler (called with upload progress, or with the publication progress when no file is provided)
 	ProgressHandler func(progress ioprogress.ProgressData)
 
 	// Type of the image (container or virtual-machine)
@@ -582,6 +660,9 @@ type StoragePoolVolumeCopyArgs struct {
 
 	// API extension: custom_volume_refresh
 	Refresh bool
+
+	// Progress handler (called with the transfer progress reported by the target server)
+	ProgressHandler func(progress ioprogress.ProgressData)
 }
 
 // The StoragePoolVolumeMoveArgs struct is used to pass additional options
@@ -601,6 +682,12 @@ type StoragePoolVolumeBackupArgs struct {
 
 	// Name to import backup as
 	Name string
+
+	// Size of the backup file in bytes (optional, used for progress reporting)
+	Size int64
+
+	// Progress handler (called with upload progress)
+	ProgressHandler func(progress ioprogress.ProgressData)
 }
 
 // The InstanceBackupArgs struct is used when creating a instance from a backup.
@@ -616,6 +703,12 @@ type InstanceBackupArgs struct {
 
 	// If set, it would override devices
 	Devices map[string]map[string]string
+
+	// Size of the backup file in bytes (optional, used for progress reporting)
+	Size int64
+
+	// Progress handler (called with upload progress)
+	ProgressHandler func(progress ioprogress.ProgressData)
 }
 
 // The InstanceCopyArgs struct is used to pass additional options during instance copy.
@@ -638,6 +731,13 @@ type InstanceCopyArgs struct {
 
 	// API extension: instance_allow_inconsistent_copy
 	AllowInconsistent bool
+
+	// API extension: instance_migration_bandwidth_limit
+	// Maximum transfer rate in bytes per second (e.g. 10MiB), only used when migrating between servers
+	BandwidthLimit string
+
+	// Progress handler (called with the transfer progress reported by the target server)
+	ProgressHandler func(progress ioprogress.ProgressData)
 }
 
 // The InstanceSnapshotCopyArgs struct is used to pass additional options during instance copy.
@@ -651,6 +751,9 @@ type InstanceSnapshotCopyArgs struct {
 	// API extension: container_snapshot_stateful_migration
 	// If set, the instance running state will be transferred (live migration)
 	Live bool
+
+	// Progress handler (called with the transfer progress reported by the target server)
+	ProgressHandler func(progress ioprogress.ProgressData)
 }
 
 // The InstanceConsoleArgs struct is used to pass additional options during a
@@ -689,6 +792,27 @@ type InstanceExecArgs struct {
 	DataDone chan bool
 }
 
+// The InstanceDiskArgs struct is used when creating a virtual machine from a disk.
+type InstanceDiskArgs struct {
+	// The disk content (qcow2, raw, vmdk, vhdx or vdi)
+	Disk io.Reader
+
+	// Size of the disk content in bytes (optional)
+	Size int64
+
+	// Progress handler (called with upload progress)
+	ProgressHandler func(progress ioprogress.ProgressData)
+}
+
+// The NetworkCaptureArgs struct is used to pass additional options during a network capture.
+type NetworkCaptureArgs struct {
+	// Writer receiving the captured traffic in pcap format
+	Output io.Writer
+
+	// Channel that will be closed when all the captured traffic was received
+	DataDone chan bool
+}
+
 // The InstanceFileArgs struct is used to pass the various options for a instance file upload.
 type InstanceFileArgs struct {
 	// File content
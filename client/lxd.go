@@ -50,6 +50,8 @@ type ProtocolLXD struct {
 	project       string
 
 	oidcClient *oidcClient
+
+	bearerToken string
 }
 
 // Disconnect gets rid of any background goroutines.
@@ -158,6 +160,7 @@ func (r *ProtocolLXD) DoHTTP(req *http.Request) (*http.Response, error) {
 // User-Agent (if r.httpUserAgent is set).
 // X-LXD-authenticated (if r.requireAuthenticated is set).
 // OIDC Authorization header (if r.oidcClient is set).
+// Bearer Authorization header (if r.bearerToken is set).
 func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 	if r.httpUserAgent != "" {
 		req.Header.Set("User-Agent", r.httpUserAgent)
@@ -170,6 +173,10 @@ func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 	if r.oidcClient != nil {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.oidcClient.getAccessToken()))
 	}
+
+	if r.bearerToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.bearerToken))
+	}
 }
 
 // RequireAuthenticated sets whether we expect to be authenticated with the server.
@@ -429,7 +436,7 @@ func (r *ProtocolLXD) rawWebsocket(url string) (*websocket.Conn, error) {
 	r.addClientHeaders(req)
 
 	// Establish the connection
-	conn, resp, err := dialer.Dial(url, req.Header)
+	conn, resp, err := dialer.DialContext(r.ctx, url, req.Header)
 	if err != nil {
 		if resp != nil {
 			_, _, err = lxdParseResponse(resp)
@@ -438,6 +445,11 @@ func (r *ProtocolLXD) rawWebsocket(url string) (*websocket.Conn, error) {
 		return nil, err
 	}
 
+	// Close the connection once the context of the client is cancelled.
+	if r.ctx.Done() != nil {
+		context.AfterFunc(r.ctx, func() { _ = conn.Close() })
+	}
+
 	// Set TCP timeout options.
 	remoteTCP, _ := tcp.ExtractConn(conn.UnderlyingConn())
 	if remoteTCP != nil {
@@ -467,11 +479,30 @@ func (r *ProtocolLXD) websocket(path string) (*websocket.Conn, error) {
 	return r.rawWebsocket(url)
 }
 
-// WithContext returns a client that will add context.Context.
+// WithContext returns a client that will use the provided context for all its requests.
+// Cancelling the context aborts the pending requests and closes the websockets opened by the client,
+// such as those of exec, console and migration operations. Waiting for the operations returned by the
+// client also stops when the context is done.
 func (r *ProtocolLXD) WithContext(ctx context.Context) InstanceServer {
-	rr := r
-	rr.ctx = ctx
-	return rr
+	return &ProtocolLXD{
+		ctx:                  ctx,
+		ctxConnected:         r.ctxConnected,
+		ctxConnectedCancel:   r.ctxConnectedCancel,
+		server:               r.server,
+		http:                 r.http,
+		httpCertificate:      r.httpCertificate,
+		httpBaseURL:          r.httpBaseURL,
+		httpUnixPath:         r.httpUnixPath,
+		httpProtocol:         r.httpProtocol,
+		httpUserAgent:        r.httpUserAgent,
+		requireAuthenticated: r.requireAuthenticated,
+		clusterTarget:        r.clusterTarget,
+		project:              r.project,
+		eventConns:           make(map[string]*websocket.Conn),  // New context specific listener conns.
+		eventListeners:       make(map[string][]*EventListener), // New context specific listeners.
+		oidcClient:           r.oidcClient,
+		bearerToken:          r.bearerToken,
+	}
 }
 
 // getUnderlyingHTTPTransport returns the *http.Transport used by the http client. If the http
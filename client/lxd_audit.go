@@ -0,0 +1,28 @@
+package lxd
+
+import (
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetAuditLog returns the audit log entries recorded after the given time (all entries if it is zero).
+func (r *ProtocolLXD) GetAuditLog(since time.Time) ([]api.AuditEntry, error) {
+	err := r.CheckExtension("audit_log")
+	if err != nil {
+		return nil, err
+	}
+
+	u := api.NewURL().Path("audit")
+	if !since.IsZero() {
+		u = u.WithQuery("since", since.UTC().Format(time.RFC3339))
+	}
+
+	entries := []api.AuditEntry{}
+	_, err = r.queryStruct("GET", u.String(), nil, "", &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -118,6 +118,115 @@ func (r *ProtocolLXD) DeleteAuthGroup(groupName string) error {
 	return nil
 }
 
+// GetAuthRoleNames returns a slice of all role names.
+func (r *ProtocolLXD) GetAuthRoleNames() ([]string, error) {
+	err := r.CheckExtension("auth_roles")
+	if err != nil {
+		return nil, err
+	}
+
+	urls := []string{}
+	baseURL := "auth/roles"
+	_, err = r.queryStruct(http.MethodGet, baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetAuthRole returns a single role by its name.
+func (r *ProtocolLXD) GetAuthRole(roleName string) (*api.AuthRole, string, error) {
+	err := r.CheckExtension("auth_roles")
+	if err != nil {
+		return nil, "", err
+	}
+
+	role := api.AuthRole{}
+	etag, err := r.queryStruct(http.MethodGet, api.NewURL().Path("auth", "roles", roleName).String(), nil, "", &role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &role, etag, nil
+}
+
+// GetAuthRoles returns a list of all roles.
+func (r *ProtocolLXD) GetAuthRoles() ([]api.AuthRole, error) {
+	err := r.CheckExtension("auth_roles")
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []api.AuthRole
+	_, err = r.queryStruct(http.MethodGet, api.NewURL().Path("auth", "roles").WithQuery("recursion", "1").String(), nil, "", &roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// CreateAuthRole creates a new role.
+func (r *ProtocolLXD) CreateAuthRole(role api.AuthRolesPost) error {
+	err := r.CheckExtension("auth_roles")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodPost, api.NewURL().Path("auth", "roles").String(), role, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAuthRole replaces the editable fields of the role with the given name.
+func (r *ProtocolLXD) UpdateAuthRole(roleName string, rolePut api.AuthRolePut, ETag string) error {
+	err := r.CheckExtension("auth_roles")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodPut, api.NewURL().Path("auth", "roles", roleName).String(), rolePut, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameAuthRole renames the role with the given name.
+func (r *ProtocolLXD) RenameAuthRole(roleName string, rolePost api.AuthRolePost) error {
+	err := r.CheckExtension("auth_roles")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodPost, api.NewURL().Path("auth", "roles", roleName).String(), rolePost, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAuthRole deletes the role with the given name.
+func (r *ProtocolLXD) DeleteAuthRole(roleName string) error {
+	err := r.CheckExtension("auth_roles")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodDelete, api.NewURL().Path("auth", "roles", roleName).String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetIdentityAuthenticationMethodsIdentifiers returns a map of authentication method to list of identifiers (e.g. certificate fingerprint, email address)
 // for all identities.
 func (r *ProtocolLXD) GetIdentityAuthenticationMethodsIdentifiers() (map[string][]string, error) {
@@ -261,6 +370,68 @@ func (r *ProtocolLXD) UpdateIdentity(authenticationMethod string, nameOrIdentife
 	return nil
 }
 
+// CreateIdentityBearer creates a new identity that authenticates with a bearer token issued by LXD.
+func (r *ProtocolLXD) CreateIdentityBearer(identitiesBearerPost api.IdentitiesBearerPost) error {
+	err := r.CheckExtension("auth_bearer")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodPost, api.NewURL().Path("auth", "identities", api.AuthenticationMethodBearer).String(), identitiesBearerPost, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteIdentity deletes the identity with the given authentication method and name or identifier.
+func (r *ProtocolLXD) DeleteIdentity(authenticationMethod string, nameOrIdentifier string) error {
+	err := r.CheckExtension("auth_bearer")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodDelete, api.NewURL().Path("auth", "identities", authenticationMethod, nameOrIdentifier).String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IssueIdentityBearerToken issues a new bearer token for the bearer identity with the given name or identifier.
+// Any previously issued token for the identity is revoked.
+func (r *ProtocolLXD) IssueIdentityBearerToken(nameOrIdentifier string) (*api.IdentityBearerToken, error) {
+	err := r.CheckExtension("auth_bearer")
+	if err != nil {
+		return nil, err
+	}
+
+	token := api.IdentityBearerToken{}
+	_, err = r.queryStruct(http.MethodPost, api.NewURL().Path("auth", "identities", api.AuthenticationMethodBearer, nameOrIdentifier, "token").String(), nil, "", &token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RevokeIdentityBearerToken revokes the bearer token of the bearer identity with the given name or identifier.
+func (r *ProtocolLXD) RevokeIdentityBearerToken(nameOrIdentifier string) error {
+	err := r.CheckExtension("auth_bearer")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodDelete, api.NewURL().Path("auth", "identities", api.AuthenticationMethodBearer, nameOrIdentifier, "token").String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetIdentityProviderGroupNames returns a list of identity provider group names.
 func (r *ProtocolLXD) GetIdentityProviderGroupNames() ([]string, error) {
 	err := r.CheckExtension("access_management")
@@ -370,6 +541,115 @@ func (r *ProtocolLXD) DeleteIdentityProviderGroup(identityProviderGroupName stri
 	return nil
 }
 
+// GetIdentityProviderGroupRuleNames returns a list of identity provider group rule names.
+func (r *ProtocolLXD) GetIdentityProviderGroupRuleNames() ([]string, error) {
+	err := r.CheckExtension("identity_provider_group_rules")
+	if err != nil {
+		return nil, err
+	}
+
+	urls := []string{}
+	baseURL := "auth/identity-provider-group-rules"
+	_, err = r.queryStruct(http.MethodGet, baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetIdentityProviderGroupRules returns all identity provider group rules defined on the server.
+func (r *ProtocolLXD) GetIdentityProviderGroupRules() ([]api.IdentityProviderGroupRule, error) {
+	err := r.CheckExtension("identity_provider_group_rules")
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []api.IdentityProviderGroupRule
+	_, err = r.queryStruct(http.MethodGet, api.NewURL().Path("auth", "identity-provider-group-rules").WithQuery("recursion", "1").String(), nil, "", &rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// GetIdentityProviderGroupRule returns the identity provider group rule with the given name.
+func (r *ProtocolLXD) GetIdentityProviderGroupRule(ruleName string) (*api.IdentityProviderGroupRule, string, error) {
+	err := r.CheckExtension("identity_provider_group_rules")
+	if err != nil {
+		return nil, "", err
+	}
+
+	rule := api.IdentityProviderGroupRule{}
+	etag, err := r.queryStruct(http.MethodGet, api.NewURL().Path("auth", "identity-provider-group-rules", ruleName).String(), nil, "", &rule)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &rule, etag, nil
+}
+
+// CreateIdentityProviderGroupRule creates a new identity provider group rule.
+func (r *ProtocolLXD) CreateIdentityProviderGroupRule(rule api.IdentityProviderGroupRulesPost) error {
+	err := r.CheckExtension("identity_provider_group_rules")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodPost, api.NewURL().Path("auth", "identity-provider-group-rules").String(), rule, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateIdentityProviderGroupRule replaces the editable fields of the identity provider group rule with the given name.
+func (r *ProtocolLXD) UpdateIdentityProviderGroupRule(ruleName string, rulePut api.IdentityProviderGroupRulePut, ETag string) error {
+	err := r.CheckExtension("identity_provider_group_rules")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodPut, api.NewURL().Path("auth", "identity-provider-group-rules", ruleName).String(), rulePut, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameIdentityProviderGroupRule renames the identity provider group rule with the given name.
+func (r *ProtocolLXD) RenameIdentityProviderGroupRule(ruleName string, rulePost api.IdentityProviderGroupRulePost) error {
+	err := r.CheckExtension("identity_provider_group_rules")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodPost, api.NewURL().Path("auth", "identity-provider-group-rules", ruleName).String(), rulePost, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteIdentityProviderGroupRule deletes the identity provider group rule with the given name.
+func (r *ProtocolLXD) DeleteIdentityProviderGroupRule(ruleName string) error {
+	err := r.CheckExtension("identity_provider_group_rules")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodDelete, api.NewURL().Path("auth", "identity-provider-group-rules", ruleName).String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetPermissions returns all permissions available on the server. It does not return information on whether these
 // permissions are assigned to groups.
 func (r *ProtocolLXD) GetPermissions(args GetPermissionsArgs) ([]api.Permission, error) {
@@ -60,6 +60,70 @@ func (r *ProtocolLXD) CreateCertificate(certificate api.CertificatesPost) error
 	return nil
 }
 
+// IssueCertificate adds a new client certificate issued by the built-in certificate authority to the LXD trust store.
+// The certificate is issued for the certificate signing request and returned.
+func (r *ProtocolLXD) IssueCertificate(certificate api.CertificatesPost) (*api.CertificateIssued, error) {
+	err := r.CheckExtension("certificate_authority")
+	if err != nil {
+		return nil, err
+	}
+
+	if certificate.CertificateSigningRequest == "" {
+		return nil, fmt.Errorf("A certificate signing request is required to issue a certificate")
+	}
+
+	issued := api.CertificateIssued{}
+
+	// Send the request
+	_, err = r.queryStruct("POST", "/certificates", certificate, "", &issued)
+	if err != nil {
+		return nil, err
+	}
+
+	return &issued, nil
+}
+
+// RenewCertificate replaces the certificate with a new one issued by the built-in certificate authority.
+// The certificate is issued for the certificate signing request and returned.
+func (r *ProtocolLXD) RenewCertificate(fingerprint string, certificate api.CertificatePut, ETag string) (*api.CertificateIssued, error) {
+	err := r.CheckExtension("certificate_authority")
+	if err != nil {
+		return nil, err
+	}
+
+	if certificate.CertificateSigningRequest == "" {
+		return nil, fmt.Errorf("A certificate signing request is required to renew a certificate")
+	}
+
+	issued := api.CertificateIssued{}
+
+	// Send the request
+	_, err = r.queryStruct("PUT", fmt.Sprintf("/certificates/%s", url.PathEscape(fingerprint)), certificate, ETag, &issued)
+	if err != nil {
+		return nil, err
+	}
+
+	return &issued, nil
+}
+
+// GetCertificateAuthority returns the certificate and revocation list of the built-in certificate authority.
+func (r *ProtocolLXD) GetCertificateAuthority() (*api.CertificateAuthority, error) {
+	err := r.CheckExtension("certificate_authority")
+	if err != nil {
+		return nil, err
+	}
+
+	ca := api.CertificateAuthority{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", "/certificate-authority", nil, "", &ca)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ca, nil
+}
+
 // UpdateCertificate updates the certificate definition.
 func (r *ProtocolLXD) UpdateCertificate(fingerprint string, certificate api.CertificatePut, ETag string) error {
 	err := r.CheckExtension("certificate_update")
@@ -98,6 +162,13 @@ func (r *ProtocolLXD) CreateCertificateToken(certificate api.CertificatesPost) (
 		return nil, fmt.Errorf("Token needs to be true if requesting a token")
 	}
 
+	if !certificate.ExpiresAt.IsZero() {
+		err := r.CheckExtension("certificate_token_expiry")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", "/certificates", certificate, "", true)
 	if err != nil {
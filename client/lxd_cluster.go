@@ -215,6 +215,13 @@ func (r *ProtocolLXD) UpdateClusterMemberState(name string, state api.ClusterMem
 		return nil, err
 	}
 
+	if state.Action == "upgrade" {
+		err = r.CheckExtension("clustering_rolling_upgrade")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("/cluster/members/%s/state", name), state, "", true)
 	if err != nil {
 		return nil, err
@@ -334,3 +341,110 @@ func (r *ProtocolLXD) GetClusterGroup(name string) (*api.ClusterGroup, string, e
 
 	return &group, etag, nil
 }
+
+// GetClusterInventory returns the inventory of all the entities of the cluster.
+// The inventory is retrieved page by page until complete.
+func (r *ProtocolLXD) GetClusterInventory() ([]api.ClusterInventoryEntry, error) {
+	err := r.CheckExtension("cluster_inventory")
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []api.ClusterInventoryEntry{}
+	after := ""
+	for {
+		u := api.NewURL().Path("cluster", "inventory")
+		if after != "" {
+			u = u.WithQuery("after", after)
+		}
+
+		inventory := api.ClusterInventory{}
+		_, err = r.queryStruct("GET", u.String(), nil, "", &inventory)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, inventory.Entries...)
+
+		if inventory.Next == "" {
+			return entries, nil
+		}
+
+		after = inventory.Next
+	}
+}
+
+// GetClusterJoinPreseed returns a preseed that a new member with the given name and address can use to join the
+// cluster, with suggested values for the member specific configuration keys.
+func (r *ProtocolLXD) GetClusterJoinPreseed(serverName string, serverAddress string) (*api.InitPreseed, error) {
+	err := r.CheckExtension("cluster_join_preseed")
+	if err != nil {
+		return nil, err
+	}
+
+	u := api.NewURL().Path("cluster", "join-preseed")
+	if serverName != "" {
+		u = u.WithQuery("server_name", serverName)
+	}
+
+	if serverAddress != "" {
+		u = u.WithQuery("server_address", serverAddress)
+	}
+
+	preseed := api.InitPreseed{}
+	_, err = r.queryStruct("GET", u.String(), nil, "", &preseed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &preseed, nil
+}
+
+// CheckClusterJoin checks that a new member can join the cluster with the given member specific configuration and
+// returns the configuration keys that still need a value.
+func (r *ProtocolLXD) CheckClusterJoin(member api.ClusterMemberJoinCheckPost) (*api.ClusterMemberJoinCheck, error) {
+	err := r.CheckExtension("cluster_join_check")
+	if err != nil {
+		return nil, err
+	}
+
+	check := api.ClusterMemberJoinCheck{}
+	_, err = r.queryStruct("POST", api.NewURL().Path("cluster", "join-check").String(), member, "", &check)
+	if err != nil {
+		return nil, err
+	}
+
+	return &check, nil
+}
+
+// GetClusterDrift returns the settings that differ between the online cluster members.
+func (r *ProtocolLXD) GetClusterDrift() ([]api.ClusterDrift, error) {
+	err := r.CheckExtension("cluster_drift")
+	if err != nil {
+		return nil, err
+	}
+
+	drift := []api.ClusterDrift{}
+	_, err = r.queryStruct("GET", api.NewURL().Path("cluster", "drift").String(), nil, "", &drift)
+	if err != nil {
+		return nil, err
+	}
+
+	return drift, nil
+}
+
+// GetClusterState returns the health of the cluster database and of its members.
+func (r *ProtocolLXD) GetClusterState() (*api.ClusterState, error) {
+	err := r.CheckExtension("cluster_state")
+	if err != nil {
+		return nil, err
+	}
+
+	clusterState := api.ClusterState{}
+	_, err = r.queryStruct("GET", api.NewURL().Path("cluster", "state").String(), nil, "", &clusterState)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clusterState, nil
+}
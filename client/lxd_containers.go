@@ -13,8 +13,6 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
-	"github.com/canonical/lxd/shared/ioprogress"
-	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/ws"
 )
 
@@ -110,7 +108,7 @@ func (r *ProtocolLXD) CreateContainerFromBackup(args ContainerBackupArgs) (Opera
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", reqURL, args.BackupFile)
+	req, err := http.NewRequestWithContext(r.ctx, "POST", reqURL, args.BackupFile)
 	if err != nil {
 		return nil, err
 	}
@@ -789,7 +787,7 @@ func (r *ProtocolLXD) GetContainerFile(containerName string, path string) (io.Re
 		return nil, nil, err
 	}
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -877,7 +875,7 @@ func (r *ProtocolLXD) CreateContainerFile(containerName string, path string, arg
 		return err
 	}
 
-	req, err := http.NewRequest("POST", requestURL, args.Content)
+	req, err := http.NewRequestWithContext(r.ctx, "POST", requestURL, args.Content)
 	if err != nil {
 		return err
 	}
@@ -1378,7 +1376,7 @@ func (r *ProtocolLXD) GetContainerLogfile(name string, filename string) (io.Read
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1477,7 +1475,7 @@ func (r *ProtocolLXD) GetContainerTemplateFile(containerName string, templateNam
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1513,7 +1511,7 @@ func (r *ProtocolLXD) CreateContainerTemplateFile(containerName string, template
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, content)
+	req, err := http.NewRequestWithContext(r.ctx, "POST", url, content)
 	if err != nil {
 		return err
 	}
@@ -1637,7 +1635,7 @@ func (r *ProtocolLXD) GetContainerConsoleLog(containerName string, args *Contain
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1795,7 +1793,7 @@ func (r *ProtocolLXD) GetContainerBackupFile(containerName string, name string,
 	}
 
 	// Prepare the download request
-	request, err := http.NewRequest("GET", uri, nil)
+	request, err := http.NewRequestWithContext(r.ctx, "GET", uri, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1821,17 +1819,9 @@ func (r *ProtocolLXD) GetContainerBackupFile(containerName string, name string,
 	}
 
 	// Handle the data
-	body := response.Body
+	var body io.ReadCloser = response.Body
 	if req.ProgressHandler != nil {
-		body = &ioprogress.ProgressReader{
-			ReadCloser: response.Body,
-			Tracker: &ioprogress.ProgressTracker{
-				Length: response.ContentLength,
-				Handler: func(percent int64, speed int64) {
-					req.ProgressHandler(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
-				},
-			},
-		}
+		body = progressReader(response.Body, response.ContentLength, req.ProgressHandler)
 	}
 
 	size, err := io.Copy(req.BackupFile, body)
@@ -0,0 +1,172 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Device group handling functions
+
+// GetDeviceGroupNames returns a list of available device group names.
+func (r *ProtocolLXD) GetDeviceGroupNames() ([]string, error) {
+	err := r.CheckExtension("device_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/device-groups"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetDeviceGroups returns a list of available DeviceGroup structs.
+func (r *ProtocolLXD) GetDeviceGroups() ([]api.DeviceGroup, error) {
+	err := r.CheckExtension("device_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []api.DeviceGroup{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", "/device-groups?recursion=1", nil, "", &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// GetDeviceGroup returns a DeviceGroup entry for the provided name.
+func (r *ProtocolLXD) GetDeviceGroup(name string) (*api.DeviceGroup, string, error) {
+	err := r.CheckExtension("device_groups")
+	if err != nil {
+		return nil, "", err
+	}
+
+	group := api.DeviceGroup{}
+
+	// Fetch the raw value
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/device-groups/%s", url.PathEscape(name)), nil, "", &group)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &group, etag, nil
+}
+
+// CreateDeviceGroup defines a new device group.
+func (r *ProtocolLXD) CreateDeviceGroup(group api.DeviceGroupsPost) error {
+	err := r.CheckExtension("device_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("POST", "/device-groups", group, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateDeviceGroup updates the device group to match the provided DeviceGroupPut struct.
+func (r *ProtocolLXD) UpdateDeviceGroup(name string, group api.DeviceGroupPut, ETag string) error {
+	err := r.CheckExtension("device_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("PUT", fmt.Sprintf("/device-groups/%s", url.PathEscape(name)), group, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameDeviceGroup renames an existing device group entry.
+func (r *ProtocolLXD) RenameDeviceGroup(name string, group api.DeviceGroupPost) error {
+	err := r.CheckExtension("device_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("POST", fmt.Sprintf("/device-groups/%s", url.PathEscape(name)), group, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteDeviceGroup deletes a device group.
+func (r *ProtocolLXD) DeleteDeviceGroup(name string) error {
+	err := r.CheckExtension("device_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("DELETE", fmt.Sprintf("/device-groups/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AttachInstanceDeviceGroup adds the devices of the device group to the instance.
+func (r *ProtocolLXD) AttachInstanceDeviceGroup(instanceName string, groupName string) error {
+	err := r.CheckExtension("device_groups")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	req := api.InstanceDeviceGroupsPost{Name: groupName}
+	_, _, err = r.query("POST", fmt.Sprintf("%s/%s/device-groups", path, url.PathEscape(instanceName)), req, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DetachInstanceDeviceGroup removes the devices of the device group from the instance.
+func (r *ProtocolLXD) DetachInstanceDeviceGroup(instanceName string, groupName string) error {
+	err := r.CheckExtension("device_groups")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("DELETE", fmt.Sprintf("%s/%s/device-groups/%s", path, url.PathEscape(instanceName), url.PathEscape(groupName)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
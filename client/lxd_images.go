@@ -1,6 +1,7 @@
 package lxd
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"io"
@@ -15,8 +16,6 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
-	"github.com/canonical/lxd/shared/ioprogress"
-	"github.com/canonical/lxd/shared/units"
 )
 
 // Image handling functions
@@ -145,7 +144,7 @@ func (r *ProtocolLXD) GetPrivateImageFile(fingerprint string, secret string, req
 		// Setup the HTTP client
 		devlxdHTTP, err := unixHTTPClient(nil, "/dev/lxd/sock", nil)
 		if err == nil {
-			resp, err := lxdDownloadImage(fingerprint, unixURI, r.httpUserAgent, devlxdHTTP.Do, req)
+			resp, err := lxdDownloadImage(r.ctx, fingerprint, unixURI, r.httpUserAgent, devlxdHTTP.Do, req)
 			if err == nil {
 				return resp, nil
 			}
@@ -168,15 +167,15 @@ func (r *ProtocolLXD) GetPrivateImageFile(fingerprint string, secret string, req
 	httpTransport.ResponseHeaderTimeout = 30 * time.Second
 	httpClient.Transport = httpTransport
 
-	return lxdDownloadImage(fingerprint, uri, r.httpUserAgent, r.DoHTTP, req)
+	return lxdDownloadImage(r.ctx, fingerprint, uri, r.httpUserAgent, r.DoHTTP, req)
 }
 
-func lxdDownloadImage(fingerprint string, uri string, userAgent string, do func(*http.Request) (*http.Response, error), req ImageFileRequest) (*ImageFileResponse, error) {
+func lxdDownloadImage(ctx context.Context, fingerprint string, uri string, userAgent string, do func(*http.Request) (*http.Response, error), req ImageFileRequest) (*ImageFileResponse, error) {
 	// Prepare the response
 	resp := ImageFileResponse{}
 
 	// Prepare the download request
-	request, err := http.NewRequest("GET", uri, nil)
+	request, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -207,26 +206,9 @@ func lxdDownloadImage(fingerprint string, uri string, userAgent string, do func(
 	}
 
 	// Handle the data
-	body := response.Body
+	var body io.ReadCloser = response.Body
 	if req.ProgressHandler != nil {
-		reader := &ioprogress.ProgressReader{
-			ReadCloser: response.Body,
-			Tracker: &ioprogress.ProgressTracker{
-				Length: response.ContentLength,
-			},
-		}
-
-		if response.ContentLength > 0 {
-			reader.Tracker.Handler = func(percent int64, speed int64) {
-				req.ProgressHandler(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
-			}
-		} else {
-			reader.Tracker.Handler = func(received int64, speed int64) {
-				req.ProgressHandler(ioprogress.ProgressData{Text: fmt.Sprintf("%s (%s/s)", units.GetByteSizeString(received, 2), units.GetByteSizeString(speed, 2))})
-			}
-		}
-
-		body = reader
+		body = progressReader(response.Body, response.ContentLength, req.ProgressHandler)
 	}
 
 	// Hashing
@@ -399,12 +381,19 @@ func (r *ProtocolLXD) CreateImage(image api.ImagesPost, args *ImageCreateArgs) (
 	}
 
 	// Send the JSON based request
-	if args == nil {
+	if args == nil || (args.MetaFile == nil && args.RootfsFile == nil) {
 		op, _, err := r.queryOperation("POST", "/images", image, "", true)
 		if err != nil {
 			return nil, err
 		}
 
+		if args != nil && args.ProgressHandler != nil {
+			_, err = op.AddHandler(operationProgressHandler(args.ProgressHandler))
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		return op, nil
 	}
 
@@ -478,14 +467,7 @@ func (r *ProtocolLXD) CreateImage(image api.ImagesPost, args *ImageCreateArgs) (
 
 		// Setup progress handler
 		if args.ProgressHandler != nil {
-			body = &ioprogress.ProgressReader{
-				ReadCloser: pr,
-				Tracker: &ioprogress.ProgressTracker{
-					Handler: func(received int64, speed int64) {
-						args.ProgressHandler(ioprogress.ProgressData{Text: fmt.Sprintf("%s (%s/s)", units.GetByteSizeString(received, 2), units.GetByteSizeString(speed, 2))})
-					},
-				},
-			}
+			body = progressReader(pr, 0, args.ProgressHandler)
 		} else {
 			body = pr
 		}
@@ -499,7 +481,7 @@ func (r *ProtocolLXD) CreateImage(image api.ImagesPost, args *ImageCreateArgs) (
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", reqURL, body)
+	req, err := http.NewRequestWithContext(r.ctx, "POST", reqURL, body)
 	if err != nil {
 		return nil, err
 	}
@@ -947,6 +929,63 @@ func (r *ProtocolLXD) RefreshImage(fingerprint string) (Operation, error) {
 	return op, nil
 }
 
+// GetImageSyncStatus returns the replication status of the image on each cluster member.
+func (r *ProtocolLXD) GetImageSyncStatus(fingerprint string) (*api.ImageSyncStatus, error) {
+	err := r.CheckExtension("image_sync_status")
+	if err != nil {
+		return nil, err
+	}
+
+	status := api.ImageSyncStatus{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/images/%s/sync", url.PathEscape(fingerprint)), nil, "", &status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// RepairImageSync requests that LXD copies the image to the cluster members missing it.
+func (r *ProtocolLXD) RepairImageSync(fingerprint string) (Operation, error) {
+	err := r.CheckExtension("image_sync_status")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/images/%s/sync", url.PathEscape(fingerprint)), nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// GetImageCacheExpiry returns the expired cached images that the next image cache expiry run deletes.
+// If expiry isn't empty, it is used as the number of days after which unused cached images expire instead of the
+// configured expiry.
+func (r *ProtocolLXD) GetImageCacheExpiry(expiry string) ([]api.ImageCacheExpiry, error) {
+	err := r.CheckExtension("images_remote_cache_expiry_preview")
+	if err != nil {
+		return nil, err
+	}
+
+	u := api.NewURL().Path("images", "cache-expiry")
+	if expiry != "" {
+		u = u.WithQuery("expiry", expiry)
+	}
+
+	images := []api.ImageCacheExpiry{}
+	_, err = r.queryStruct("GET", u.String(), nil, "", &images)
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
 // CreateImageSecret requests that LXD issues a temporary image secret.
 func (r *ProtocolLXD) CreateImageSecret(fingerprint string) (Operation, error) {
 	// Send the request
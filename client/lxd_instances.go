@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/websocket"
@@ -18,9 +19,7 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
-	"github.com/canonical/lxd/shared/ioprogress"
 	"github.com/canonical/lxd/shared/tcp"
-	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/ws"
 )
 
@@ -142,6 +141,29 @@ func (r *ProtocolLXD) GetInstancesWithFilter(instanceType api.InstanceType, filt
 	return instances, nil
 }
 
+// GetInstancesWithNamePattern returns a list of instances whose name matches the given shell pattern.
+func (r *ProtocolLXD) GetInstancesWithNamePattern(instanceType api.InstanceType, pattern string) ([]api.Instance, error) {
+	_, err := filepath.Match(pattern, "")
+	if err != nil {
+		return nil, fmt.Errorf("Invalid instance name pattern %q: %w", pattern, err)
+	}
+
+	instances, err := r.GetInstances(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := []api.Instance{}
+	for _, instance := range instances {
+		match, _ := filepath.Match(pattern, instance.Name)
+		if match {
+			matching = append(matching, instance)
+		}
+	}
+
+	return matching, nil
+}
+
 // GetInstancesAllProjects returns a list of instances from all projects.
 func (r *ProtocolLXD) GetInstancesAllProjects(instanceType api.InstanceType) ([]api.Instance, error) {
 	instances := []api.Instance{}
@@ -304,6 +326,13 @@ func (r *ProtocolLXD) RebuildInstanceFromImage(source ImageServer, image api.Ima
 		return nil, err
 	}
 
+	if len(req.PreservePaths) > 0 {
+		err = r.CheckExtension("instance_rebuild_preserve_paths")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	info, err := r.getSourceImageConnectionInfo(source, image, &req.Source)
 	if err != nil {
 		return nil, err
@@ -339,6 +368,13 @@ func (r *ProtocolLXD) RebuildInstance(instanceName string, instance api.Instance
 		return nil, err
 	}
 
+	if len(instance.PreservePaths) > 0 {
+		err = r.CheckExtension("instance_rebuild_preserve_paths")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return r.rebuildInstance(instanceName, instance)
 }
 
@@ -466,6 +502,49 @@ func (r *ProtocolLXD) GetInstancesFullAllProjectsWithFilter(instanceType api.Ins
 	return instances, nil
 }
 
+// GetInstancesWithState returns a filtered list of instances including their state, but not their snapshots and backups.
+func (r *ProtocolLXD) GetInstancesWithState(instanceType api.InstanceType, filters []string) ([]api.InstanceFull, error) {
+	return r.getInstancesWithState(instanceType, filters, false)
+}
+
+// GetInstancesWithStateAllProjects returns a filtered list of instances from all projects including their state,
+// but not their snapshots and backups.
+func (r *ProtocolLXD) GetInstancesWithStateAllProjects(instanceType api.InstanceType, filters []string) ([]api.InstanceFull, error) {
+	return r.getInstancesWithState(instanceType, filters, true)
+}
+
+func (r *ProtocolLXD) getInstancesWithState(instanceType api.InstanceType, filters []string, allProjects bool) ([]api.InstanceFull, error) {
+	err := r.CheckExtension("instances_state_bulk")
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []api.InstanceFull{}
+
+	path, v, err := r.instanceTypeToPath(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Set("recursion", "3")
+
+	if allProjects {
+		v.Set("all-projects", "true")
+	}
+
+	if len(filters) > 0 {
+		v.Set("filter", parseFilters(filters))
+	}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s?%s", path, v.Encode()), nil, "", &instances)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
 // GetInstance returns the instance entry for the provided name.
 func (r *ProtocolLXD) GetInstance(name string) (*api.Instance, string, error) {
 	instance := api.Instance{}
@@ -589,6 +668,10 @@ func (r *ProtocolLXD) CreateInstanceFromBackup(args InstanceBackupArgs) (Operati
 		return nil, err
 	}
 
+	if args.ProgressHandler != nil {
+		args.BackupFile = progressReader(args.BackupFile, args.Size, args.ProgressHandler)
+	}
+
 	if args.PoolName == "" && args.Name == "" && len(args.Devices) == 0 {
 		// Send the request
 		op, _, err := r.queryOperation("POST", path, args.BackupFile, "", true)
@@ -626,7 +709,7 @@ func (r *ProtocolLXD) CreateInstanceFromBackup(args InstanceBackupArgs) (Operati
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", reqURL, args.BackupFile)
+	req, err := http.NewRequestWithContext(r.ctx, "POST", reqURL, args.BackupFile)
 	if err != nil {
 		return nil, err
 	}
@@ -709,6 +792,92 @@ func (r *ProtocolLXD) CreateInstance(instance api.InstancesPost) (Operation, err
 	return op, nil
 }
 
+// CreateInstanceFromDisk creates a new virtual machine and streams the disk to be imported into its root volume.
+// The returned operation completes once the server converted the disk.
+func (r *ProtocolLXD) CreateInstanceFromDisk(instance api.InstancesPost, args InstanceDiskArgs) (Operation, error) {
+	err := r.CheckExtension("instance_import_disk")
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Disk == nil {
+		return nil, fmt.Errorf("A disk is required to create an instance from a disk")
+	}
+
+	if instance.Type == "" {
+		instance.Type = api.InstanceTypeVM
+	}
+
+	instance.Source = api.InstanceSource{
+		Type:     "disk",
+		DiskSize: args.Size,
+	}
+
+	path, _, err := r.instanceTypeToPath(instance.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", path, instance, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	opAPI := op.Get()
+
+	// Parse the fds
+	fds := map[string]string{}
+
+	value, ok := opAPI.Metadata["fds"]
+	if ok {
+		values, _ := value.(map[string]any)
+		for k, v := range values {
+			fds[k], _ = v.(string)
+		}
+	}
+
+	if fds["0"] == "" {
+		return nil, fmt.Errorf("Did not receive a disk import websocket secret")
+	}
+
+	// Connect to the websocket
+	conn, err := r.GetOperationWebsocket(opAPI.ID, fds["0"])
+	if err != nil {
+		return nil, err
+	}
+
+	disk := args.Disk
+	if args.ProgressHandler != nil {
+		disk = progressReader(args.Disk, args.Size, args.ProgressHandler)
+	}
+
+	// And stream the disk
+	go func() {
+		defer func() { _ = conn.Close() }()
+
+		wrapper := ws.NewWrapper(conn)
+
+		_, err := io.Copy(wrapper, disk)
+		if err != nil {
+			// Closing the connection without the end of stream marker makes the import fail.
+			return
+		}
+
+		_ = wrapper.Close()
+
+		// Wait for the server to close the connection once the disk was converted.
+		for {
+			_, _, err := conn.NextReader()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return op, nil
+}
+
 // tryCreateInstance attempts to create a new instance on multiple target servers specified by their URLs.
 // It runs the instance creation asynchronously and returns a RemoteOperation to monitor the progress and any errors.
 func (r *ProtocolLXD) tryCreateInstance(req api.InstancesPost, urls []string, op Operation) (RemoteOperation, error) {
@@ -808,6 +977,23 @@ func (r *ProtocolLXD) CreateInstanceFromImage(source ImageServer, image api.Imag
 
 // CopyInstance copies a instance from a remote server. Additional options can be passed using InstanceCopyArgs.
 func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance, args *InstanceCopyArgs) (RemoteOperation, error) {
+	rop, err := r.copyInstance(source, instance, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if args != nil && args.ProgressHandler != nil {
+		_, err = rop.AddHandler(operationProgressHandler(args.ProgressHandler))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rop, nil
+}
+
+// copyInstance implements CopyInstance, without the progress reporting.
+func (r *ProtocolLXD) copyInstance(source InstanceServer, instance api.Instance, args *InstanceCopyArgs) (RemoteOperation, error) {
 	// Base request
 	req := api.InstancesPost{
 		Name:        instance.Name,
@@ -860,6 +1046,10 @@ func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance,
 			}
 		}
 
+		if args.BandwidthLimit != "" && !source.HasExtension("instance_migration_bandwidth_limit") {
+			return nil, fmt.Errorf("The source server is missing the required \"instance_migration_bandwidth_limit\" API extension")
+		}
+
 		// Allow overriding the target name
 		if args.Name != "" {
 			req.Name = args.Name
@@ -931,6 +1121,10 @@ func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance,
 		AllowInconsistent: req.Source.AllowInconsistent,
 	}
 
+	if args != nil {
+		sourceReq.BandwidthLimit = args.BandwidthLimit
+	}
+
 	// Push mode migration
 	if args != nil && args.Mode == "push" {
 		// Get target server connection information
@@ -1051,6 +1245,29 @@ func (r *ProtocolLXD) UpdateInstance(name string, instance api.InstancePut, ETag
 	return op, nil
 }
 
+// UpdateInstanceDryRun validates the instance update and returns the changes it would make without applying them.
+func (r *ProtocolLXD) UpdateInstanceDryRun(name string, instance api.InstancePut, ETag string) (*api.ConfigDiff, error) {
+	err := r.CheckExtension("config_dry_run")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := api.ConfigDiff{}
+
+	// Send the request
+	_, err = r.queryStruct("PUT", fmt.Sprintf("%s/%s?dry-run=1", path, url.PathEscape(name)), instance, ETag, &diff)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diff, nil
+}
+
 // RenameInstance requests that LXD renames the instance.
 func (r *ProtocolLXD) RenameInstance(name string, instance api.InstancePost) (Operation, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -1164,6 +1381,20 @@ func (r *ProtocolLXD) MigrateInstance(name string, instance api.InstancePost) (O
 		}
 	}
 
+	if instance.BandwidthLimit != "" {
+		err := r.CheckExtension("instance_migration_bandwidth_limit")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if instance.StatelessFallback {
+		err := r.CheckExtension("instance_move_stateless_fallback")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Quick check.
 	if !instance.Migration {
 		return nil, fmt.Errorf("Can't ask for a rename through MigrateInstance")
@@ -1472,7 +1703,7 @@ func (r *ProtocolLXD) GetInstanceFile(instanceName string, filePath string) (io.
 		return nil, nil, err
 	}
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1571,7 +1802,7 @@ func (r *ProtocolLXD) CreateInstanceFile(instanceName string, filePath string, a
 		return err
 	}
 
-	req, err := http.NewRequest("POST", requestURL, args.Content)
+	req, err := http.NewRequestWithContext(r.ctx, "POST", requestURL, args.Content)
 	if err != nil {
 		return err
 	}
@@ -1630,6 +1861,42 @@ func (r *ProtocolLXD) CreateInstanceFile(instanceName string, filePath string, a
 	return nil
 }
 
+// GetInstanceFileGlob returns the paths in the instance matching the glob pattern.
+// The pattern is expanded by the server.
+func (r *ProtocolLXD) GetInstanceFileGlob(instanceName string, pattern string) ([]string, error) {
+	err := r.CheckExtension("instance_file_glob")
+	if err != nil {
+		return nil, err
+	}
+
+	if r.IsAgent() {
+		return nil, fmt.Errorf("Glob patterns aren't supported by the agent")
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the HTTP request
+	requestURL := fmt.Sprintf("%s/%s/files?path=%s&glob=true", path, url.PathEscape(instanceName), url.QueryEscape(pattern))
+
+	requestURL, err = r.setQueryAttributes(requestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+
+	// Send the request
+	_, err = r.queryStruct("GET", requestURL, nil, "", &paths)
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
 // DeleteInstanceFile deletes a file in the instance.
 func (r *ProtocolLXD) DeleteInstanceFile(instanceName string, filePath string) error {
 	err := r.CheckExtension("file_delete")
@@ -1692,15 +1959,20 @@ func (r *ProtocolLXD) rawSFTPConn(apiURL *url.URL) (net.Conn, error) {
 	var conn net.Conn
 
 	if httpTransport.TLSClientConfig != nil {
-		conn, err = httpTransport.DialTLSContext(context.Background(), "tcp", apiURL.Host)
+		conn, err = httpTransport.DialTLSContext(r.ctx, "tcp", apiURL.Host)
 	} else {
-		conn, err = httpTransport.DialContext(context.Background(), "tcp", apiURL.Host)
+		conn, err = httpTransport.DialContext(r.ctx, "tcp", apiURL.Host)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	// Close the connection once the context of the client is cancelled.
+	if r.ctx.Done() != nil {
+		context.AfterFunc(r.ctx, func() { _ = conn.Close() })
+	}
+
 	remoteTCP, _ := tcp.ExtractConn(conn)
 	if remoteTCP != nil {
 		err = tcp.SetTimeouts(remoteTCP, 0)
@@ -1847,6 +2119,23 @@ func (r *ProtocolLXD) CreateInstanceSnapshot(instanceName string, snapshot api.I
 
 // CopyInstanceSnapshot copies a snapshot from a remote server into a new instance. Additional options can be passed using InstanceCopyArgs.
 func (r *ProtocolLXD) CopyInstanceSnapshot(source InstanceServer, instanceName string, snapshot api.InstanceSnapshot, args *InstanceSnapshotCopyArgs) (RemoteOperation, error) {
+	rop, err := r.copyInstanceSnapshot(source, instanceName, snapshot, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if args != nil && args.ProgressHandler != nil {
+		_, err = rop.AddHandler(operationProgressHandler(args.ProgressHandler))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rop, nil
+}
+
+// copyInstanceSnapshot implements CopyInstanceSnapshot, without the progress reporting.
+func (r *ProtocolLXD) copyInstanceSnapshot(source InstanceServer, instanceName string, snapshot api.InstanceSnapshot, args *InstanceSnapshotCopyArgs) (RemoteOperation, error) {
 	// Backward compatibility (with broken Name field)
 	fields := strings.Split(snapshot.Name, shared.SnapshotDelimiter)
 	cName := instanceName
@@ -2286,7 +2575,7 @@ func (r *ProtocolLXD) GetInstanceLogfile(name string, filename string) (io.ReadC
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2308,6 +2597,34 @@ func (r *ProtocolLXD) GetInstanceLogfile(name string, filename string) (io.ReadC
 	return resp.Body, err
 }
 
+// GetInstanceLogfileFollow returns a websocket over which the requested logfile is streamed as it grows.
+// The stream starts with the given number of lines from the end of the logfile, or with the whole logfile if
+// lines is negative.
+func (r *ProtocolLXD) GetInstanceLogfileFollow(name string, filename string, lines int) (*websocket.Conn, error) {
+	err := r.CheckExtension("instance_log_follow")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("follow", "true")
+	if lines >= 0 {
+		values.Set("lines", strconv.Itoa(lines))
+	}
+
+	uri, err := r.setQueryAttributes(fmt.Sprintf("%s/%s/logs/%s?%s", path, url.PathEscape(name), url.PathEscape(filename), values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.websocket(uri)
+}
+
 // DeleteInstanceLogfile deletes the requested logfile.
 func (r *ProtocolLXD) DeleteInstanceLogfile(name string, filename string) error {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2346,7 +2663,7 @@ func (r *ProtocolLXD) getInstanceExecOutputLogFile(name string, filename string)
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2475,7 +2792,7 @@ func (r *ProtocolLXD) GetInstanceTemplateFile(instanceName string, templateName
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2516,7 +2833,7 @@ func (r *ProtocolLXD) CreateInstanceTemplateFile(instanceName string, templateNa
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, content)
+	req, err := http.NewRequestWithContext(r.ctx, "POST", url, content)
 	if err != nil {
 		return err
 	}
@@ -2574,6 +2891,13 @@ func (r *ProtocolLXD) ConsoleInstance(instanceName string, console api.InstanceC
 		}
 	}
 
+	if console.Force {
+		err = r.CheckExtension("console_multiplexing")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	useEventListener := r.CheckExtension("operation_wait") != nil
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/console", path, url.PathEscape(instanceName)), console, "", useEventListener)
@@ -2755,7 +3079,7 @@ func (r *ProtocolLXD) GetInstanceConsoleLog(instanceName string, args *InstanceC
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2798,6 +3122,52 @@ func (r *ProtocolLXD) DeleteInstanceConsoleLog(instanceName string, args *Instan
 	return nil
 }
 
+// GetInstanceConsoleSessions returns the clients attached to the text console of a virtual machine.
+func (r *ProtocolLXD) GetInstanceConsoleSessions(instanceName string) ([]api.InstanceConsoleSession, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.CheckExtension("console_multiplexing")
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []api.InstanceConsoleSession{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/console/sessions", path, url.PathEscape(instanceName)), nil, "", &sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// CreateInstanceAccessToken issues a temporary token granting exec or console access to the instance.
+func (r *ProtocolLXD) CreateInstanceAccessToken(instanceName string, token api.InstanceAccessTokensPost) (*api.InstanceAccessToken, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.CheckExtension("instance_access_tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken := api.InstanceAccessToken{}
+
+	// Send the request
+	_, err = r.queryStruct("POST", fmt.Sprintf("%s/%s/access-tokens", path, url.PathEscape(instanceName)), token, "", &accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accessToken, nil
+}
+
 // GetInstanceBackupNames returns a list of backup names for the instance.
 func (r *ProtocolLXD) GetInstanceBackupNames(instanceName string) ([]string, error) {
 	err := r.CheckExtension("container_backup")
@@ -2949,7 +3319,7 @@ func (r *ProtocolLXD) GetInstanceBackupFile(instanceName string, name string, re
 	}
 
 	// Prepare the download request
-	request, err := http.NewRequest("GET", uri, nil)
+	request, err := http.NewRequestWithContext(r.ctx, "GET", uri, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2975,17 +3345,9 @@ func (r *ProtocolLXD) GetInstanceBackupFile(instanceName string, name string, re
 	}
 
 	// Handle the data
-	body := response.Body
+	var body io.ReadCloser = response.Body
 	if req.ProgressHandler != nil {
-		body = &ioprogress.ProgressReader{
-			ReadCloser: response.Body,
-			Tracker: &ioprogress.ProgressTracker{
-				Length: response.ContentLength,
-				Handler: func(percent int64, speed int64) {
-					req.ProgressHandler(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
-				},
-			},
-		}
+		body = progressReader(response.Body, response.ContentLength, req.ProgressHandler)
 	}
 
 	size, err := io.Copy(req.BackupFile, body)
@@ -80,7 +80,7 @@ func (r *ProtocolLXD) GetNetworkACLLogfile(name string) (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
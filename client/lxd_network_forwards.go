@@ -63,6 +63,24 @@ func (r *ProtocolLXD) GetNetworkForward(networkName string, listenAddress string
 	return &forward, etag, nil
 }
 
+// GetNetworkForwardState returns the health state of the port specifications of a network forward.
+func (r *ProtocolLXD) GetNetworkForwardState(networkName string, listenAddress string) (*api.NetworkForwardState, error) {
+	err := r.CheckExtension("network_forward_health_check")
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.NetworkForwardState{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/networks/%s/forwards/%s/state", url.PathEscape(networkName), url.PathEscape(listenAddress)), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
 // CreateNetworkForward defines a new network forward using the provided struct.
 func (r *ProtocolLXD) CreateNetworkForward(networkName string, forward api.NetworkForwardsPost) error {
 	err := r.CheckExtension("network_forward")
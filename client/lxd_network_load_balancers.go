@@ -83,6 +83,11 @@ func (r *ProtocolLXD) CreateNetworkLoadBalancer(networkName string, loadBalancer
 		}
 	}
 
+	err = r.checkNetworkLoadBalancerPorts(loadBalancer.Ports)
+	if err != nil {
+		return err
+	}
+
 	// Send the request.
 	u := api.NewURL().Path("networks", networkName, "load-balancers")
 	_, _, err = r.query("POST", u.String(), loadBalancer, "")
@@ -100,6 +105,11 @@ func (r *ProtocolLXD) UpdateNetworkLoadBalancer(networkName string, listenAddres
 		return err
 	}
 
+	err = r.checkNetworkLoadBalancerPorts(loadBalancer.Ports)
+	if err != nil {
+		return err
+	}
+
 	// Send the request.
 	u := api.NewURL().Path("networks", networkName, "load-balancers", listenAddress)
 	_, _, err = r.query("PUT", u.String(), loadBalancer, ETag)
@@ -126,3 +136,14 @@ func (r *ProtocolLXD) DeleteNetworkLoadBalancer(networkName string, listenAddres
 
 	return nil
 }
+
+// checkNetworkLoadBalancerPorts checks that the server supports the settings of the load balancer ports.
+func (r *ProtocolLXD) checkNetworkLoadBalancerPorts(ports []api.NetworkLoadBalancerPort) error {
+	for _, port := range ports {
+		if port.SessionPersistence != "" || port.SessionPersistenceTimeout != 0 {
+			return r.CheckExtension("network_load_balancer_session_persistence")
+		}
+	}
+
+	return nil
+}
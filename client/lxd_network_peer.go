@@ -70,6 +70,13 @@ func (r *ProtocolLXD) CreateNetworkPeer(networkName string, peer api.NetworkPeer
 		return err
 	}
 
+	if peer.Type != "" && peer.Type != api.NetworkPeerTypeLocal {
+		err = r.CheckExtension("network_peer_ovn_interconnect")
+		if err != nil {
+			return err
+		}
+	}
+
 	// Send the request.
 	_, _, err = r.query("POST", fmt.Sprintf("/networks/%s/peers", url.PathEscape(networkName)), peer, "")
 	if err != nil {
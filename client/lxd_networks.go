@@ -5,6 +5,7 @@ import (
 	"net/url"
 
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/ws"
 )
 
 // GetNetworkNames returns a list of network names.
@@ -80,6 +81,24 @@ func (r *ProtocolLXD) GetNetworkLeases(name string) ([]api.NetworkLease, error)
 	return leases, nil
 }
 
+// GetNetworkDNSRecords returns the DNS records served for instances on the network.
+func (r *ProtocolLXD) GetNetworkDNSRecords(name string) ([]api.NetworkDNSRecord, error) {
+	err := r.CheckExtension("network_bridge_dns_records")
+	if err != nil {
+		return nil, err
+	}
+
+	records := []api.NetworkDNSRecord{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/networks/%s/dns-records", url.PathEscape(name)), nil, "", &records)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
 // GetNetworkState returns metrics and information on the running network.
 func (r *ProtocolLXD) GetNetworkState(name string) (*api.NetworkState, error) {
 	err := r.CheckExtension("network_state")
@@ -130,6 +149,24 @@ func (r *ProtocolLXD) UpdateNetwork(name string, network api.NetworkPut, ETag st
 	return nil
 }
 
+// UpdateNetworkDryRun validates the network update and returns the changes it would make without applying them.
+func (r *ProtocolLXD) UpdateNetworkDryRun(name string, network api.NetworkPut, ETag string) (*api.ConfigDiff, error) {
+	err := r.CheckExtension("config_dry_run")
+	if err != nil {
+		return nil, err
+	}
+
+	diff := api.ConfigDiff{}
+
+	// Send the request
+	_, err = r.queryStruct("PUT", fmt.Sprintf("/networks/%s?dry-run=1", url.PathEscape(name)), network, ETag, &diff)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diff, nil
+}
+
 // RenameNetwork renames an existing network entry.
 func (r *ProtocolLXD) RenameNetwork(name string, network api.NetworkPost) error {
 	err := r.CheckExtension("network")
@@ -161,3 +198,56 @@ func (r *ProtocolLXD) DeleteNetwork(name string) error {
 
 	return nil
 }
+
+// CaptureNetwork starts a capture of the network traffic and streams it in pcap format to args.Output.
+func (r *ProtocolLXD) CaptureNetwork(name string, capture api.NetworkCapturePost, args *NetworkCaptureArgs) (Operation, error) {
+	err := r.CheckExtension("network_capture")
+	if err != nil {
+		return nil, err
+	}
+
+	if args == nil || args.Output == nil {
+		return nil, fmt.Errorf("An output writer is required for the capture")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/networks/%s/capture", url.PathEscape(name)), capture, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	opAPI := op.Get()
+
+	// Parse the fds
+	fds := map[string]string{}
+
+	value, ok := opAPI.Metadata["fds"]
+	if ok {
+		values, _ := value.(map[string]any)
+		for k, v := range values {
+			fds[k], _ = v.(string)
+		}
+	}
+
+	if fds["0"] == "" {
+		return nil, fmt.Errorf("Did not receive a capture websocket secret")
+	}
+
+	// Connect to the websocket
+	conn, err := r.GetOperationWebsocket(opAPI.ID, fds["0"])
+	if err != nil {
+		return nil, err
+	}
+
+	// And write the captured traffic to the output
+	go func() {
+		<-ws.MirrorWrite(conn, args.Output)
+		_ = conn.Close()
+
+		if args.DataDone != nil {
+			close(args.DataDone)
+		}
+	}()
+
+	return op, nil
+}
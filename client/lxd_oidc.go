@@ -20,14 +20,17 @@ import (
 )
 
 // setupOIDCClient initializes the OIDC (OpenID Connect) client with given tokens if it hasn't been set up already.
+// If a client secret is given, the client credentials of the service account are used to get access tokens.
 // It also assigns the protocol's http client to the oidcClient's httpClient.
-func (r *ProtocolLXD) setupOIDCClient(token *oidc.Tokens[*oidc.IDTokenClaims]) {
+func (r *ProtocolLXD) setupOIDCClient(token *oidc.Tokens[*oidc.IDTokenClaims], clientID string, clientSecret string) {
 	if r.oidcClient != nil {
 		return
 	}
 
 	r.oidcClient = newOIDCClient(token)
 	r.oidcClient.httpClient = r.http
+	r.oidcClient.clientID = clientID
+	r.oidcClient.clientSecret = clientSecret
 }
 
 // oidcTransport is a custom HTTP transport that injects the audience field into requests directed at the device
@@ -70,6 +73,10 @@ type oidcClient struct {
 	httpClient    *http.Client
 	oidcTransport *oidcTransport
 	tokens        *oidc.Tokens[*oidc.IDTokenClaims]
+
+	// clientID and clientSecret are the credentials of a service account when using the client credentials flow.
+	clientID     string
+	clientSecret string
 }
 
 // oidcClient is a structure encapsulating an HTTP client, OIDC transport, and a token for OpenID Connect (OIDC) operations.
@@ -116,12 +123,20 @@ func (o *oidcClient) do(req *http.Request) (*http.Response, error) {
 	audience := resp.Header.Get("X-LXD-OIDC-audience")
 	groupsClaim := resp.Header.Get("X-LXD-OIDC-groups-claim")
 
-	err = o.refresh(issuer, clientID, groupsClaim)
-	if err != nil {
-		err = o.authenticate(issuer, clientID, audience, groupsClaim)
+	if o.clientSecret != "" {
+		// Service accounts don't get refresh tokens, so always request a new access token.
+		err = o.authenticateClientCredentials(issuer, audience, groupsClaim)
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		err = o.refresh(issuer, clientID, groupsClaim)
+		if err != nil {
+			err = o.authenticate(issuer, clientID, audience, groupsClaim)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Set the new access token in the header.
@@ -137,7 +152,7 @@ func (o *oidcClient) do(req *http.Request) (*http.Response, error) {
 
 // getProvider initializes a new OpenID Connect Relying Party for a given issuer and clientID.
 // The function also creates a secure CookieHandler with random encryption and hash keys, and applies a series of configurations on the Relying Party.
-func (o *oidcClient) getProvider(issuer string, clientID string, groupsClaim string) (rp.RelyingParty, error) {
+func (o *oidcClient) getProvider(issuer string, clientID string, clientSecret string, groupsClaim string) (rp.RelyingParty, error) {
 	hashKey := make([]byte, 16)
 	encryptKey := make([]byte, 16)
 
@@ -167,7 +182,7 @@ func (o *oidcClient) getProvider(issuer string, clientID string, groupsClaim str
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	provider, err := rp.NewRelyingPartyOIDC(ctx, issuer, clientID, "", "", scopes, options...)
+	provider, err := rp.NewRelyingPartyOIDC(ctx, issuer, clientID, clientSecret, "", scopes, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -182,7 +197,7 @@ func (o *oidcClient) refresh(issuer string, clientID string, groupsClaim string)
 		return errRefreshAccessToken
 	}
 
-	provider, err := o.getProvider(issuer, clientID, groupsClaim)
+	provider, err := o.getProvider(issuer, clientID, "", groupsClaim)
 	if err != nil {
 		return errRefreshAccessToken
 	}
@@ -219,7 +234,7 @@ func (o *oidcClient) authenticate(issuer string, clientID string, audience strin
 		o.httpClient.Transport = oldTransport
 	}()
 
-	provider, err := o.getProvider(issuer, clientID, groupsClaim)
+	provider, err := o.getProvider(issuer, clientID, "", groupsClaim)
 	if err != nil {
 		return err
 	}
@@ -261,3 +276,39 @@ func (o *oidcClient) authenticate(issuer string, clientID string, audience strin
 
 	return nil
 }
+
+// authenticateClientCredentials gets a new access token for the service account of the client using the OpenID Connect
+// client credentials flow. No user interaction is required, which makes it suitable for headless automation.
+func (o *oidcClient) authenticateClientCredentials(issuer string, audience string, groupsClaim string) error {
+	if o.clientID == "" {
+		return fmt.Errorf("An OIDC client ID is required for the client credentials flow")
+	}
+
+	provider, err := o.getProvider(issuer, o.clientID, o.clientSecret, groupsClaim)
+	if err != nil {
+		return err
+	}
+
+	endpointParams := url.Values{}
+	if audience != "" {
+		endpointParams.Set("audience", audience)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	token, err := rp.ClientCredentials(ctx, provider, endpointParams)
+	if err != nil {
+		return fmt.Errorf("Failed getting access token using client credentials: %w", err)
+	}
+
+	o.tokens.Token = token
+	o.tokens.IDToken = ""
+
+	idToken, ok := token.Extra("id_token").(string)
+	if ok {
+		o.tokens.IDToken = idToken
+	}
+
+	return nil
+}
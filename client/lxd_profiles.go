@@ -71,6 +71,25 @@ func (r *ProtocolLXD) UpdateProfile(name string, profile api.ProfilePut, ETag st
 	return nil
 }
 
+// UpdateProfileDryRun validates the profile update and returns the changes it would make to the profile and to
+// the instances using it without applying them.
+func (r *ProtocolLXD) UpdateProfileDryRun(name string, profile api.ProfilePut, ETag string) (*api.ConfigDiff, error) {
+	err := r.CheckExtension("config_dry_run")
+	if err != nil {
+		return nil, err
+	}
+
+	diff := api.ConfigDiff{}
+
+	// Send the request
+	_, err = r.queryStruct("PUT", fmt.Sprintf("/profiles/%s?dry-run=1", url.PathEscape(name)), profile, ETag, &diff)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diff, nil
+}
+
 // RenameProfile renames an existing profile entry.
 func (r *ProtocolLXD) RenameProfile(name string, profile api.ProfilePost) error {
 	// Send the request
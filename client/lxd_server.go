@@ -98,6 +98,20 @@ func (r *ProtocolLXD) GetServerResources() (*api.Resources, error) {
 	return &resources, nil
 }
 
+// GetServerStartup returns the initialization progress and timings of the LXD server subsystems.
+func (r *ProtocolLXD) GetServerStartup() (*api.ServerStartup, error) {
+	// The extension isn't checked as the server information isn't available while the server is starting up.
+	startup := api.ServerStartup{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", "/startup", nil, "", &startup)
+	if err != nil {
+		return nil, err
+	}
+
+	return &startup, nil
+}
+
 // UseProject returns a client that will use a specific project.
 func (r *ProtocolLXD) UseProject(name string) InstanceServer {
 	return &ProtocolLXD{
@@ -161,7 +175,7 @@ func (r *ProtocolLXD) GetMetrics() (string, error) {
 		return "", err
 	}
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+	req, err := http.NewRequestWithContext(r.ctx, "GET", requestURL, nil)
 	if err != nil {
 		return "", err
 	}
@@ -103,6 +103,24 @@ func (r *ProtocolLXD) UpdateStoragePool(name string, pool api.StoragePoolPut, ET
 	return nil
 }
 
+// UpdateStoragePoolDryRun validates the storage pool update and returns the changes it would make without applying them.
+func (r *ProtocolLXD) UpdateStoragePoolDryRun(name string, pool api.StoragePoolPut, ETag string) (*api.ConfigDiff, error) {
+	err := r.CheckExtension("config_dry_run")
+	if err != nil {
+		return nil, err
+	}
+
+	diff := api.ConfigDiff{}
+
+	// Send the request
+	_, err = r.queryStruct("PUT", fmt.Sprintf("/storage-pools/%s?dry-run=1", url.PathEscape(name)), pool, ETag, &diff)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diff, nil
+}
+
 // DeleteStoragePool deletes a storage pool.
 func (r *ProtocolLXD) DeleteStoragePool(name string) error {
 	err := r.CheckExtension("storage")
@@ -136,3 +154,113 @@ func (r *ProtocolLXD) GetStoragePoolResources(name string) (*api.ResourcesStorag
 
 	return &res, nil
 }
+
+// GetStoragePoolBenchmarks returns the benchmark results of a storage pool, oldest first.
+func (r *ProtocolLXD) GetStoragePoolBenchmarks(poolName string) ([]api.StoragePoolBenchmark, error) {
+	err := r.CheckExtension("storage_pool_benchmark")
+	if err != nil {
+		return nil, err
+	}
+
+	benchmarks := []api.StoragePoolBenchmark{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/benchmarks?recursion=1", url.PathEscape(poolName)), nil, "", &benchmarks)
+	if err != nil {
+		return nil, err
+	}
+
+	return benchmarks, nil
+}
+
+// GetStoragePoolBenchmark returns a benchmark result of a storage pool.
+func (r *ProtocolLXD) GetStoragePoolBenchmark(poolName string, id int64) (*api.StoragePoolBenchmark, error) {
+	err := r.CheckExtension("storage_pool_benchmark")
+	if err != nil {
+		return nil, err
+	}
+
+	benchmark := api.StoragePoolBenchmark{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/benchmarks/%d", url.PathEscape(poolName), id), nil, "", &benchmark)
+	if err != nil {
+		return nil, err
+	}
+
+	return &benchmark, nil
+}
+
+// CreateStoragePoolBenchmark runs a new benchmark against a storage pool.
+func (r *ProtocolLXD) CreateStoragePoolBenchmark(poolName string, benchmark api.StoragePoolBenchmarksPost) (Operation, error) {
+	err := r.CheckExtension("storage_pool_benchmark")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/benchmarks", url.PathEscape(poolName)), benchmark, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// DeleteStoragePoolBenchmark deletes a benchmark result of a storage pool.
+func (r *ProtocolLXD) DeleteStoragePoolBenchmark(poolName string, id int64) error {
+	err := r.CheckExtension("storage_pool_benchmark")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("DELETE", fmt.Sprintf("/storage-pools/%s/benchmarks/%d", url.PathEscape(poolName), id), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetStoragePoolMigrateVolumes returns the volumes that would be moved when migrating a storage pool to another one.
+func (r *ProtocolLXD) GetStoragePoolMigrateVolumes(poolName string, targetPoolName string) ([]api.StoragePoolMigrateVolume, error) {
+	err := r.CheckExtension("storage_pool_migrate")
+	if err != nil {
+		return nil, err
+	}
+
+	migration := api.StoragePoolMigratePost{
+		Pool:   targetPoolName,
+		DryRun: true,
+	}
+
+	volumes := []api.StoragePoolMigrateVolume{}
+
+	// Send the request
+	_, err = r.queryStruct("POST", fmt.Sprintf("/storage-pools/%s/migrate", url.PathEscape(poolName)), migration, "", &volumes)
+	if err != nil {
+		return nil, err
+	}
+
+	return volumes, nil
+}
+
+// MigrateStoragePool moves all volumes of a storage pool to another one.
+func (r *ProtocolLXD) MigrateStoragePool(poolName string, migration api.StoragePoolMigratePost) (Operation, error) {
+	err := r.CheckExtension("storage_pool_migrate")
+	if err != nil {
+		return nil, err
+	}
+
+	// Dry runs return a synchronous response, see GetStoragePoolMigrateVolumes.
+	migration.DryRun = false
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/migrate", url.PathEscape(poolName)), migration, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
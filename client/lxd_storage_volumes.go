@@ -3,15 +3,16 @@ package lxd
 import (
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/pkg/sftp"
+
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
-	"github.com/canonical/lxd/shared/ioprogress"
-	"github.com/canonical/lxd/shared/units"
 )
 
 // Storage volumes handling function
@@ -370,6 +371,44 @@ func (r *ProtocolLXD) GetStoragePoolVolumeSnapshot(pool string, volumeType strin
 	return &snapshot, etag, nil
 }
 
+// GetStoragePoolVolumeSnapshotSFTPConn returns a connection to the SFTP endpoint of a storage volume snapshot.
+func (r *ProtocolLXD) GetStoragePoolVolumeSnapshotSFTPConn(pool string, volumeType string, volumeName string, snapshotName string) (net.Conn, error) {
+	err := r.CheckExtension("storage_volume_snapshot_sftp")
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := api.NewURL()
+	apiURL.URL = r.httpBaseURL // Preload the URL with the client base URL.
+	apiURL.Path("1.0", "storage-pools", pool, "volumes", volumeType, volumeName, "snapshots", snapshotName, "sftp")
+	r.setURLQueryAttributes(&apiURL.URL)
+
+	return r.rawSFTPConn(&apiURL.URL)
+}
+
+// GetStoragePoolVolumeSnapshotSFTP returns a read-only SFTP connection to a storage volume snapshot.
+func (r *ProtocolLXD) GetStoragePoolVolumeSnapshotSFTP(pool string, volumeType string, volumeName string, snapshotName string) (*sftp.Client, error) {
+	conn, err := r.GetStoragePoolVolumeSnapshotSFTPConn(pool, volumeType, volumeName, snapshotName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get a SFTP client.
+	client, err := sftp.NewClientPipe(conn, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	go func() {
+		// Wait for the client to be done before closing the connection.
+		_ = client.Wait()
+		_ = conn.Close()
+	}()
+
+	return client, nil
+}
+
 // RenameStoragePoolVolumeSnapshot renames a storage volume snapshot.
 func (r *ProtocolLXD) RenameStoragePoolVolumeSnapshot(pool string, volumeType string, volumeName string, snapshotName string, snapshot api.StorageVolumeSnapshotPost) (Operation, error) {
 	err := r.CheckExtension("storage_api_volume_snapshots")
@@ -591,6 +630,23 @@ func (r *ProtocolLXD) tryCreateStoragePoolVolume(pool string, req api.StorageVol
 
 // CopyStoragePoolVolume copies an existing storage volume.
 func (r *ProtocolLXD) CopyStoragePoolVolume(pool string, source InstanceServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeCopyArgs) (RemoteOperation, error) {
+	rop, err := r.copyStoragePoolVolume(pool, source, sourcePool, volume, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if args != nil && args.ProgressHandler != nil {
+		_, err = rop.AddHandler(operationProgressHandler(args.ProgressHandler))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rop, nil
+}
+
+// copyStoragePoolVolume implements CopyStoragePoolVolume, without the progress reporting.
+func (r *ProtocolLXD) copyStoragePoolVolume(pool string, source InstanceServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeCopyArgs) (RemoteOperation, error) {
 	err := r.CheckExtension("storage_api_local_volume_handling")
 	if err != nil {
 		return nil, err
@@ -1016,7 +1072,7 @@ func (r *ProtocolLXD) GetStoragePoolVolumeBackupFile(pool string, volName string
 	}
 
 	// Prepare the download request
-	request, err := http.NewRequest("GET", uri, nil)
+	request, err := http.NewRequestWithContext(r.ctx, "GET", uri, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1042,17 +1098,9 @@ func (r *ProtocolLXD) GetStoragePoolVolumeBackupFile(pool string, volName string
 	}
 
 	// Handle the data
-	body := response.Body
+	var body io.ReadCloser = response.Body
 	if req.ProgressHandler != nil {
-		body = &ioprogress.ProgressReader{
-			ReadCloser: response.Body,
-			Tracker: &ioprogress.ProgressTracker{
-				Length: response.ContentLength,
-				Handler: func(percent int64, speed int64) {
-					req.ProgressHandler(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
-				},
-			},
-		}
+		body = progressReader(response.Body, response.ContentLength, req.ProgressHandler)
 	}
 
 	size, err := io.Copy(req.BackupFile, body)
@@ -1066,6 +1114,30 @@ func (r *ProtocolLXD) GetStoragePoolVolumeBackupFile(pool string, volName string
 	return &resp, nil
 }
 
+// CreateStoragePoolVolumeFromURL creates a custom volume from content downloaded by the server from a URL.
+func (r *ProtocolLXD) CreateStoragePoolVolumeFromURL(pool string, volume api.StorageVolumesPost) (Operation, error) {
+	err := r.CheckExtension("storage_volume_import_url")
+	if err != nil {
+		return nil, err
+	}
+
+	if volume.Name == "" {
+		return nil, fmt.Errorf("Missing volume name")
+	}
+
+	volume.Type = "custom"
+	volume.Source.Type = "url"
+
+	// Send the request
+	path := fmt.Sprintf("/storage-pools/%s/volumes/custom", url.PathEscape(pool))
+	op, _, err := r.queryOperation("POST", path, volume, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // CreateStoragePoolVolumeFromISO creates a custom volume from an ISO file.
 func (r *ProtocolLXD) CreateStoragePoolVolumeFromISO(pool string, args StoragePoolVolumeBackupArgs) (Operation, error) {
 	err := r.CheckExtension("custom_volume_iso")
@@ -1075,13 +1147,17 @@ func (r *ProtocolLXD) CreateStoragePoolVolumeFromISO(pool string, args StoragePo
 
 	path := fmt.Sprintf("/storage-pools/%s/volumes/custom", url.PathEscape(pool))
 
+	if args.ProgressHandler != nil {
+		args.BackupFile = progressReader(args.BackupFile, args.Size, args.ProgressHandler)
+	}
+
 	// Prepare the HTTP request.
 	reqURL, err := r.setQueryAttributes(fmt.Sprintf("%s/1.0%s", r.httpBaseURL.String(), path))
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", reqURL, args.BackupFile)
+	req, err := http.NewRequestWithContext(r.ctx, "POST", reqURL, args.BackupFile)
 	if err != nil {
 		return nil, err
 	}
@@ -1140,13 +1216,17 @@ func (r *ProtocolLXD) CreateStoragePoolVolumeFromBackup(pool string, args Storag
 
 	path := fmt.Sprintf("/storage-pools/%s/volumes/custom", url.PathEscape(pool))
 
+	if args.ProgressHandler != nil {
+		args.BackupFile = progressReader(args.BackupFile, args.Size, args.ProgressHandler)
+	}
+
 	// Prepare the HTTP request.
 	reqURL, err := r.setQueryAttributes(fmt.Sprintf("%s/1.0%s", r.httpBaseURL.String(), path))
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", reqURL, args.BackupFile)
+	req, err := http.NewRequestWithContext(r.ctx, "POST", reqURL, args.BackupFile)
 	if err != nil {
 		return nil, err
 	}
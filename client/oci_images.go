@@ -0,0 +1,397 @@
+package lxd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/osarch"
+)
+
+// ociInfo is the information about an OCI image as returned by skopeo inspect.
+type ociInfo struct {
+	Digest       string    `json:"Digest"`
+	Created      time.Time `json:"Created"`
+	Architecture string    `json:"Architecture"`
+	OS           string    `json:"Os"`
+	LayersData   []struct {
+		Size int64 `json:"Size"`
+	} `json:"LayersData"`
+
+	// Name of the image in the registry.
+	name string
+}
+
+// ociRuntimeConfig is the part of the runtime configuration generated by umoci that describes how to run the image.
+type ociRuntimeConfig struct {
+	Process struct {
+		Args []string `json:"args"`
+		Env  []string `json:"env"`
+		Cwd  string   `json:"cwd"`
+		User struct {
+			UID uint32 `json:"uid"`
+			GID uint32 `json:"gid"`
+		} `json:"user"`
+	} `json:"process"`
+}
+
+// ociRunTool runs one of the tools used to retrieve and convert OCI images.
+func ociRunTool(ctx context.Context, name string, args ...string) (string, error) {
+	_, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("OCI images require %q to be installed: %w", name, err)
+	}
+
+	return shared.RunCommandContext(ctx, name, args...)
+}
+
+// inspect looks up the image with the given name in the registry.
+func (r *ProtocolOCI) inspect(name string) (*ociInfo, error) {
+	out, err := ociRunTool(context.TODO(), "skopeo", "inspect", fmt.Sprintf("docker://%s/%s", r.registry(), name))
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting image %q: %w", name, err)
+	}
+
+	info := ociInfo{}
+	err = json.Unmarshal([]byte(out), &info)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing information of image %q: %w", name, err)
+	}
+
+	if info.OS != "" && info.OS != "linux" {
+		return nil, fmt.Errorf("Image %q is for unsupported operating system %q", name, info.OS)
+	}
+
+	info.name = name
+	r.cache[info.fingerprint()] = info
+
+	return &info, nil
+}
+
+// fingerprint returns the fingerprint of the image, which is its manifest digest.
+func (i *ociInfo) fingerprint() string {
+	return strings.TrimPrefix(i.Digest, "sha256:")
+}
+
+// toAPI returns the API representation of the image.
+func (i *ociInfo) toAPI() (*api.Image, error) {
+	archID, err := osarch.ArchitectureId(i.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	architecture, err := osarch.ArchitectureName(archID)
+	if err != nil {
+		return nil, err
+	}
+
+	var size int64
+	for _, layer := range i.LayersData {
+		size += layer.Size
+	}
+
+	image := api.Image{
+		Fingerprint:  i.fingerprint(),
+		Filename:     i.fingerprint() + ".tar",
+		Size:         size,
+		Architecture: architecture,
+		Type:         "container",
+		CreatedAt:    i.Created,
+		UploadedAt:   i.Created,
+		Public:       true,
+		Properties: map[string]string{
+			"architecture": architecture,
+			"description":  i.name,
+			"type":         "oci",
+		},
+		Aliases: []api.ImageAlias{{Name: i.name}},
+	}
+
+	return &image, nil
+}
+
+// Image handling functions
+
+// GetImages returns a list of available images as Image structs.
+func (r *ProtocolOCI) GetImages() ([]api.Image, error) {
+	return nil, fmt.Errorf("GetImages is not supported by the OCI protocol")
+}
+
+// GetImageFingerprints returns a list of available image fingerprints.
+func (r *ProtocolOCI) GetImageFingerprints() ([]string, error) {
+	return nil, fmt.Errorf("GetImageFingerprints is not supported by the OCI protocol")
+}
+
+// GetImagesWithFilter returns a filtered list of available images as Image structs.
+func (r *ProtocolOCI) GetImagesWithFilter(filters []string) ([]api.Image, error) {
+	return nil, fmt.Errorf("GetImagesWithFilter is not supported by the OCI protocol")
+}
+
+// GetImage returns an Image struct for the provided fingerprint.
+// As registries can't be searched by fingerprint, only images that were previously looked up by name can be
+// retrieved by fingerprint. Otherwise the fingerprint is used as the name of the image.
+func (r *ProtocolOCI) GetImage(fingerprint string) (*api.Image, string, error) {
+	info, ok := r.cache[fingerprint]
+	if !ok {
+		infoPtr, err := r.inspect(fingerprint)
+		if err != nil {
+			return nil, "", err
+		}
+
+		info = *infoPtr
+	}
+
+	image, err := info.toAPI()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return image, "", nil
+}
+
+// GetImageFile downloads an image from the registry and converts it into a unified LXD image,
+// returning an ImageFileResponse struct.
+func (r *ProtocolOCI) GetImageFile(fingerprint string, req ImageFileRequest) (*ImageFileResponse, error) {
+	// Quick checks.
+	if req.MetaFile == nil {
+		return nil, fmt.Errorf("No file requested")
+	}
+
+	info, ok := r.cache[fingerprint]
+	if !ok {
+		infoPtr, err := r.inspect(fingerprint)
+		if err != nil {
+			return nil, err
+		}
+
+		info = *infoPtr
+	}
+
+	image, err := info.toAPI()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.TODO()
+	progress := func(text string) {
+		if req.ProgressHandler != nil {
+			req.ProgressHandler(ioprogress.ProgressData{Text: text})
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "lxd_oci_")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// Download the image layers, pinning the image to the looked up digest.
+	progress("Retrieving OCI image from registry")
+
+	ociPath := filepath.Join(tmpDir, "oci")
+	_, err = ociRunTool(ctx, "skopeo", "--insecure-policy", "copy", "--remove-signatures", fmt.Sprintf("docker://%s/%s@%s", r.registry(), info.name, info.Digest), fmt.Sprintf("oci:%s:latest", ociPath))
+	if err != nil {
+		return nil, fmt.Errorf("Failed retrieving image %q: %w", info.name, err)
+	}
+
+	// Flatten the layers into a root filesystem.
+	progress("Unpacking OCI image")
+
+	bundlePath := filepath.Join(tmpDir, "image")
+	unpackArgs := []string{"--log=error", "unpack", "--keep-dirlinks", "--image", fmt.Sprintf("%s:latest", ociPath)}
+	if os.Geteuid() != 0 {
+		unpackArgs = append(unpackArgs, "--rootless")
+	}
+
+	_, err = ociRunTool(ctx, "umoci", append(unpackArgs, bundlePath)...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed unpacking image %q: %w", info.name, err)
+	}
+
+	// Ensure the mount points the container needs exist.
+	for _, dir := range []string{"dev", "proc", "sys"} {
+		err = os.MkdirAll(filepath.Join(bundlePath, "rootfs", dir), 0755)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Record how to run the image in the image properties.
+	content, err := os.ReadFile(filepath.Join(bundlePath, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	config := ociRuntimeConfig{}
+	err = json.Unmarshal(content, &config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing runtime configuration of image %q: %w", info.name, err)
+	}
+
+	properties := image.Properties
+	for k, v := range ociProperties(config) {
+		properties[k] = v
+	}
+
+	metadata := api.ImageMetadata{
+		Architecture: image.Architecture,
+		CreationDate: image.CreatedAt.Unix(),
+		Properties:   properties,
+	}
+
+	data, err := yaml.Marshal(&metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(bundlePath, "metadata.yaml"), data, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pack the unified image.
+	progress("Packing LXD image")
+
+	err = shared.RunCommandWithFds(ctx, nil, req.MetaFile, "tar", "--xattrs", "--numeric-owner", "-C", bundlePath, "-cf", "-", "metadata.yaml", "rootfs")
+	if err != nil {
+		return nil, fmt.Errorf("Failed packing image %q: %w", info.name, err)
+	}
+
+	size, err := req.MetaFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := ImageFileResponse{
+		MetaName: image.Filename,
+		MetaSize: size,
+	}
+
+	return &resp, nil
+}
+
+// ociProperties returns the image properties describing how to run the image.
+// The command is quoted the way the init command of LXC is split into arguments.
+func ociProperties(config ociRuntimeConfig) map[string]string {
+	properties := map[string]string{}
+
+	args := make([]string, 0, len(config.Process.Args))
+	for _, arg := range config.Process.Args {
+		if !strings.ContainsAny(arg, " \t'\"") {
+			args = append(args, arg)
+		} else if !strings.Contains(arg, "'") {
+			args = append(args, "'"+arg+"'")
+		} else {
+			args = append(args, `"`+arg+`"`)
+		}
+	}
+
+	if len(args) > 0 {
+		properties["oci.entrypoint"] = strings.Join(args, " ")
+	}
+
+	if config.Process.Cwd != "" && config.Process.Cwd != "/" {
+		properties["oci.cwd"] = config.Process.Cwd
+	}
+
+	if config.Process.User.UID != 0 {
+		properties["oci.uid"] = fmt.Sprintf("%d", config.Process.User.UID)
+	}
+
+	if config.Process.User.GID != 0 {
+		properties["oci.gid"] = fmt.Sprintf("%d", config.Process.User.GID)
+	}
+
+	for _, env := range config.Process.Env {
+		key, value, found := strings.Cut(env, "=")
+		if found && key != "" {
+			properties["oci.env."+key] = value
+		}
+	}
+
+	return properties
+}
+
+// GetImageSecret isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetImageSecret(fingerprint string) (string, error) {
+	return "", fmt.Errorf("Private images aren't supported by the OCI protocol")
+}
+
+// GetPrivateImage isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetPrivateImage(fingerprint string, secret string) (*api.Image, string, error) {
+	return nil, "", fmt.Errorf("Private images aren't supported by the OCI protocol")
+}
+
+// GetPrivateImageFile isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetPrivateImageFile(fingerprint string, secret string, req ImageFileRequest) (*ImageFileResponse, error) {
+	return nil, fmt.Errorf("Private images aren't supported by the OCI protocol")
+}
+
+// GetImageAliases returns the list of available aliases as ImageAliasesEntry structs.
+func (r *ProtocolOCI) GetImageAliases() ([]api.ImageAliasesEntry, error) {
+	return nil, fmt.Errorf("GetImageAliases is not supported by the OCI protocol")
+}
+
+// GetImageAliasNames returns the list of available alias names.
+func (r *ProtocolOCI) GetImageAliasNames() ([]string, error) {
+	return nil, fmt.Errorf("GetImageAliasNames is not supported by the OCI protocol")
+}
+
+// GetImageAlias returns an existing alias as an ImageAliasesEntry struct.
+// The alias is the name of the image in the registry, optionally followed by a tag.
+func (r *ProtocolOCI) GetImageAlias(name string) (*api.ImageAliasesEntry, string, error) {
+	return r.GetImageAliasType("", name)
+}
+
+// GetImageAliasType returns an existing alias as an ImageAliasesEntry struct.
+func (r *ProtocolOCI) GetImageAliasType(imageType string, name string) (*api.ImageAliasesEntry, string, error) {
+	if imageType != "" && imageType != "container" {
+		return nil, "", fmt.Errorf("OCI images can only be used for containers")
+	}
+
+	info, err := r.inspect(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	alias := api.ImageAliasesEntry{
+		Name:   name,
+		Target: info.fingerprint(),
+		Type:   "container",
+	}
+
+	return &alias, "", nil
+}
+
+// GetImageAliasArchitectures returns a map of architectures / targets.
+// Only the architecture of the image that matches the local system is returned.
+func (r *ProtocolOCI) GetImageAliasArchitectures(imageType string, name string) (map[string]*api.ImageAliasesEntry, error) {
+	alias, _, err := r.GetImageAliasType(imageType, name)
+	if err != nil {
+		return nil, err
+	}
+
+	image, _, err := r.GetImage(alias.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]*api.ImageAliasesEntry{image.Architecture: alias}, nil
+}
+
+// ExportImage exports (copies) an image to a remote server.
+func (r *ProtocolOCI) ExportImage(fingerprint string, image api.ImageExportPost) (Operation, error) {
+	return nil, fmt.Errorf("Exporting images is not supported by the OCI protocol")
+}
@@ -114,8 +114,9 @@ func (op *operation) Refresh() error {
 }
 
 // Wait lets you wait until the operation reaches a final state.
+// It stops waiting once the context of the client that created the operation is done.
 func (op *operation) Wait() error {
-	return op.WaitContext(context.Background())
+	return op.WaitContext(op.r.ctx)
 }
 
 // WaitContext lets you wait until the operation reaches a final state with context.Context.
@@ -15,6 +15,12 @@ type ProtocolSimpleStreams struct {
 	httpHost        string
 	httpUserAgent   string
 	httpCertificate string
+
+	// Mirrors serving the same files as the server.
+	mirrors []string
+
+	// Maximum number of parallel ranged requests per file download.
+	downloadSegments int
 }
 
 // Disconnect is a no-op for simplestreams.
@@ -24,7 +30,7 @@ func (r *ProtocolSimpleStreams) Disconnect() {
 // GetConnectionInfo returns the basic connection information used to interact with the server.
 func (r *ProtocolSimpleStreams) GetConnectionInfo() (*ConnectionInfo, error) {
 	info := ConnectionInfo{}
-	info.Addresses = []string{r.httpHost}
+	info.Addresses = append([]string{r.httpHost}, r.mirrors...)
 	info.Certificate = r.httpCertificate
 	info.Protocol = "simplestreams"
 	info.URL = r.httpHost
@@ -3,6 +3,7 @@ package lxd
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -69,7 +70,7 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 		// Setup the HTTP client
 		devlxdHTTP, err := unixHTTPClient(nil, "/dev/lxd/sock", nil)
 		if err == nil {
-			resp, err := lxdDownloadImage(fingerprint, unixURI, r.httpUserAgent, devlxdHTTP.Do, req)
+			resp, err := lxdDownloadImage(context.TODO(), fingerprint, unixURI, r.httpUserAgent, devlxdHTTP.Do, req)
 			if err == nil {
 				return resp, nil
 			}
@@ -94,32 +95,45 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 
 	// Download function
 	download := func(path string, filename string, hash string, target io.WriteSeeker) (int64, error) {
-		// Try over http
-		url, err := shared.JoinUrls(fmt.Sprintf("http://%s", strings.TrimPrefix(r.httpHost, "https://")), path)
-		if err != nil {
-			return -1, err
-		}
+		var errs []error
+
+		// Try the server and then each of its mirrors.
+		for _, host := range append([]string{r.httpHost}, r.mirrors...) {
+			// Try over http
+			url, err := shared.JoinUrls(fmt.Sprintf("http://%s", strings.TrimPrefix(host, "https://")), path)
+			if err != nil {
+				return -1, err
+			}
+
+			size, err := shared.DownloadFileHashSegments(context.TODO(), &httpClient, r.httpUserAgent, req.ProgressHandler, req.Canceler, filename, url, hash, sha256.New(), target, r.downloadSegments)
+			if err == nil {
+				return size, nil
+			}
 
-		size, err := shared.DownloadFileHash(context.TODO(), &httpClient, r.httpUserAgent, req.ProgressHandler, req.Canceler, filename, url, hash, sha256.New(), target)
-		if err != nil {
 			// Handle cancelation
-			if err.Error() == "net/http: request canceled" {
+			if err.Error() == "net/http: request canceled" || errors.Is(err, context.Canceled) {
 				return -1, err
 			}
 
 			// Try over https
-			url, err := shared.JoinUrls(r.httpHost, path)
+			url, err = shared.JoinUrls(host, path)
 			if err != nil {
 				return -1, err
 			}
 
-			size, err = shared.DownloadFileHash(context.TODO(), &httpClient, r.httpUserAgent, req.ProgressHandler, req.Canceler, filename, url, hash, sha256.New(), target)
-			if err != nil {
+			size, err = shared.DownloadFileHashSegments(context.TODO(), &httpClient, r.httpUserAgent, req.ProgressHandler, req.Canceler, filename, url, hash, sha256.New(), target, r.downloadSegments)
+			if err == nil {
+				return size, nil
+			}
+
+			if err.Error() == "net/http: request canceled" || errors.Is(err, context.Canceled) {
 				return -1, err
 			}
+
+			errs = append(errs, err)
 		}
 
-		return size, nil
+		return -1, errors.Join(errs...)
 	}
 
 	// Download the LXD image file
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -14,6 +15,9 @@ import (
 	"time"
 
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/units"
 )
 
 // tlsHTTPClient creates an HTTP client with a specified Transport Layer Security (TLS) configuration.
@@ -287,3 +291,51 @@ func openBrowser(url string) error {
 
 	return nil
 }
+
+// progressReader wraps the reader so that the progress of reading from it is reported to the handler.
+// If size is positive, the progress is also reported as a percentage of it.
+func progressReader(reader io.Reader, size int64, handler func(ioprogress.ProgressData)) io.ReadCloser {
+	readCloser, ok := reader.(io.ReadCloser)
+	if !ok {
+		readCloser = io.NopCloser(reader)
+	}
+
+	tracker := &ioprogress.ProgressTracker{Length: size}
+	if size > 0 {
+		tracker.Handler = func(percent int64, speed int64) {
+			handler(ioprogress.ProgressData{
+				Text:             fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2)),
+				Percentage:       int(percent),
+				TransferredBytes: size * percent / 100,
+				TotalBytes:       size,
+			})
+		}
+	} else {
+		tracker.Handler = func(received int64, speed int64) {
+			handler(ioprogress.ProgressData{
+				Text:             fmt.Sprintf("%s (%s/s)", units.GetByteSizeString(received, 2), units.GetByteSizeString(speed, 2)),
+				TransferredBytes: received,
+			})
+		}
+	}
+
+	return &ioprogress.ProgressReader{ReadCloser: readCloser, Tracker: tracker}
+}
+
+// operationProgressHandler returns an operation handler reporting the progress found in the operation metadata
+// to the handler. This is used for the operations streaming data between servers.
+func operationProgressHandler(handler func(ioprogress.ProgressData)) func(api.Operation) {
+	return func(op api.Operation) {
+		for key, value := range op.Metadata {
+			if !strings.HasSuffix(key, "_progress") {
+				continue
+			}
+
+			text, ok := value.(string)
+			if ok {
+				handler(ioprogress.ProgressData{Text: text})
+				return
+			}
+		}
+	}
+}
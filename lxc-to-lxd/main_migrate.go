@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -17,6 +19,7 @@ import (
 	cli "github.com/canonical/lxd/shared/cmd"
 	"github.com/canonical/lxd/shared/i18n"
 	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/revert"
 )
 
 type cmdMigrate struct {
@@ -31,6 +34,7 @@ type cmdMigrate struct {
 	flagLXCPath    string
 	flagRsyncArgs  string
 	flagContainers []string
+	flagVolumes    []string
 }
 
 // Command line for lxc-to-lxd.
@@ -56,6 +60,8 @@ func (c *cmdMigrate) Command() *cobra.Command {
 		"Extra arguments to pass to rsync"+"``")
 	cmd.Flags().StringSliceVar(&c.flagContainers, "containers", nil,
 		i18n.G("Container(s) to import")+"``")
+	cmd.Flags().StringSliceVar(&c.flagVolumes, "custom-volumes", nil,
+		i18n.G("Source path(s) of bind mounts to migrate into custom storage volumes")+"``")
 
 	return cmd
 }
@@ -66,6 +72,11 @@ func (c *cmdMigrate) RunE(cmd *cobra.Command, args []string) error {
 		fmt.Fprintln(os.Stderr, "You must either pass container names or --all")
 		os.Exit(1)
 	}
+
+	if len(c.flagVolumes) > 0 && c.flagStorage == "" {
+		return fmt.Errorf("--custom-volumes requires --storage to be set")
+	}
+
 	// Connect to LXD
 	d, err := lxd.ConnectLXDUnix("", nil)
 	if err != nil {
@@ -78,7 +89,7 @@ func (c *cmdMigrate) RunE(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		err := convertContainer(d, container, c.flagStorage,
+		err := convertContainer(d, container, c.flagStorage, c.flagVolumes,
 			c.flagDryRun, c.flagRsyncArgs, c.flagDebug)
 		if err != nil {
 			fmt.Printf("Skipping container '%s': %v\n", container.Name(), err)
@@ -166,7 +177,7 @@ func validateConfig(conf []string, container *liblxc.Container) error {
 }
 
 func convertContainer(d lxd.ContainerServer, container *liblxc.Container, storage string,
-	dryRun bool, rsyncArgs string, debug bool) error {
+	customVolumes []string, dryRun bool, rsyncArgs string, debug bool) error {
 	// Don't migrate running containers
 	if container.Running() {
 		return fmt.Errorf("Only stopped containers can be migrated")
@@ -246,6 +257,12 @@ func convertContainer(d lxd.ContainerServer, container *liblxc.Container, storag
 		return err
 	}
 
+	// Convert selected bind mounts into custom storage volumes
+	volumes, err := convertMountsToVolumes(container.Name(), storage, customVolumes, newDevices)
+	if err != nil {
+		return err
+	}
+
 	// Convert environment
 	fmt.Println("Processing environment configuration")
 	value = getConfig(conf, "lxc.environment")
@@ -454,6 +471,34 @@ func convertContainer(d lxd.ContainerServer, container *liblxc.Container, storag
 		fmt.Printf("LXD container config:\n%v\n", string(out))
 	}
 
+	// Create the custom volumes (the container devices reference them)
+	volumeNames := make([]string, 0, len(volumes))
+	for volName := range volumes {
+		volumeNames = append(volumeNames, volName)
+	}
+
+	sort.Strings(volumeNames)
+
+	// Remove the custom volumes created so far if a later step fails.
+	revert := revert.New()
+	defer revert.Fail()
+
+	for _, volName := range volumeNames {
+		fmt.Printf("Creating custom storage volume '%s' from '%s'\n", volName, volumes[volName])
+		if dryRun {
+			fmt.Println("Would create custom storage volume now")
+			continue
+		}
+
+		// The volume may have been created even if the transfer of its content failed.
+		revert.Add(func() { _ = d.DeleteStoragePoolVolume(storage, "custom", volName) })
+
+		err := transferVolume(d, storage, volName, volumes[volName], rsyncArgs)
+		if err != nil {
+			return fmt.Errorf("Failed creating custom storage volume %q: %w", volName, err)
+		}
+	}
+
 	// Create container
 	fmt.Println("Creating container")
 	if dryRun {
@@ -481,6 +526,7 @@ func convertContainer(d lxd.ContainerServer, container *liblxc.Container, storag
 		progress.Done(fmt.Sprintf("Container '%s' successfully created", container.Name()))
 	}
 
+	revert.Success()
 	return nil
 }
 
@@ -619,6 +665,49 @@ func convertStorageConfig(conf []string, devices map[string]map[string]string) e
 	return nil
 }
 
+// convertMountsToVolumes turns the disk devices whose source matches one of the given host paths
+// into custom storage volume devices on the given pool. It returns a map of the volume names to
+// the host paths whose content needs to be copied into them.
+func convertMountsToVolumes(containerName string, pool string, paths []string, devices map[string]map[string]string) (map[string]string, error) {
+	volumes := make(map[string]string)
+	if len(paths) == 0 {
+		return volumes, nil
+	}
+
+	fmt.Println("Processing custom storage volume configuration")
+
+	for _, path := range paths {
+		path = filepath.Clean(path)
+
+		found := false
+		for devName, device := range devices {
+			if device["type"] != "disk" || device["pool"] != "" || filepath.Clean(device["source"]) != path {
+				continue
+			}
+
+			if !shared.IsDir(path) {
+				return nil, fmt.Errorf("Mount source %q isn't a directory", path)
+			}
+
+			volName := fmt.Sprintf("%s-%s", containerName, devName)
+			volumes[volName] = path
+
+			delete(device, "optional")
+			device["pool"] = pool
+			device["source"] = volName
+
+			found = true
+			break
+		}
+
+		if !found {
+			return nil, fmt.Errorf("Couldn't find a mount with source %q", path)
+		}
+	}
+
+	return volumes, nil
+}
+
 func getRootfs(conf []string) (string, error) {
 	value := getConfig(conf, "lxc.rootfs.path")
 	if value == nil {
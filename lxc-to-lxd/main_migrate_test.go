@@ -385,3 +385,94 @@ func TestGetRootfs(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertMountsToVolumes(t *testing.T) {
+	tests := []struct {
+		name            string
+		paths           []string
+		devices         map[string]map[string]string
+		expectedDevices map[string]map[string]string
+		expectedVolumes map[string]string
+		expectedError   string
+		shouldFail      bool
+	}{
+		{
+			"no custom volumes",
+			nil,
+			map[string]map[string]string{
+				"mount0": {
+					"type":   "disk",
+					"source": "/tmp",
+					"path":   "/data",
+				},
+			},
+			map[string]map[string]string{
+				"mount0": {
+					"type":   "disk",
+					"source": "/tmp",
+					"path":   "/data",
+				},
+			},
+			map[string]string{},
+			"",
+			false,
+		},
+		{
+			"unknown mount",
+			[]string{"/srv"},
+			map[string]map[string]string{},
+			nil,
+			nil,
+			`Couldn't find a mount with source "/srv"`,
+			true,
+		},
+		{
+			"valid custom volume",
+			[]string{"/tmp/"},
+			map[string]map[string]string{
+				"mount0": {
+					"type":     "disk",
+					"readonly": "true",
+					"source":   "/tmp",
+					"path":     "/data",
+				},
+				"mount1": {
+					"type":   "disk",
+					"source": "/lib",
+					"path":   "/lib",
+				},
+			},
+			map[string]map[string]string{
+				"mount0": {
+					"type":     "disk",
+					"readonly": "true",
+					"pool":     "default",
+					"source":   "c1-mount0",
+					"path":     "/data",
+				},
+				"mount1": {
+					"type":   "disk",
+					"source": "/lib",
+					"path":   "/lib",
+				},
+			},
+			map[string]string{
+				"c1-mount0": "/tmp",
+			},
+			"",
+			false,
+		},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		volumes, err := convertMountsToVolumes("c1", "default", tt.paths, tt.devices)
+		if tt.shouldFail {
+			require.EqualError(t, err, tt.expectedError)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedDevices, tt.devices)
+			require.Equal(t, tt.expectedVolumes, volumes)
+		}
+	}
+}
@@ -2,13 +2,41 @@ package main
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/migration"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 )
 
+// transferVolume creates a custom filesystem volume in push migration mode and sends the content of path into it.
+func transferVolume(d lxd.ContainerServer, pool string, name string, path string, rsyncArgs string) error {
+	req := api.StorageVolumesPost{
+		Name:        name,
+		Type:        "custom",
+		ContentType: "filesystem",
+		Source: api.StorageVolumeSource{
+			Type: "migration",
+			Mode: "push",
+		},
+	}
+
+	op, _, err := d.RawOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/custom", url.PathEscape(pool)), req, "")
+	if err != nil {
+		return err
+	}
+
+	// The trailing slash makes rsync copy the content of the directory rather than the directory itself.
+	err = transferRootfs(op, shared.AddSlash(path), rsyncArgs)
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
+
 func transferRootfs(op lxd.Operation, rootfs string, rsyncArgs string) error {
 	opAPI := op.Get()
 
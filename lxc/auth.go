@@ -36,12 +36,21 @@ func (c *cmdAuth) command() *cobra.Command {
 	permissionCmd := cmdPermission{global: c.global}
 	cmd.AddCommand(permissionCmd.command())
 
+	roleCmd := cmdRole{global: c.global}
+	cmd.AddCommand(roleCmd.command())
+
 	identityCmd := cmdIdentity{global: c.global}
 	cmd.AddCommand(identityCmd.command())
 
 	identityProviderGroupCmd := cmdIdentityProviderGroup{global: c.global}
 	cmd.AddCommand(identityProviderGroupCmd.command())
 
+	identityProviderGroupRuleCmd := cmdIdentityProviderGroupRule{global: c.global}
+	cmd.AddCommand(identityProviderGroupRuleCmd.command())
+
+	accessTokenCmd := cmdAccessToken{global: c.global}
+	cmd.AddCommand(accessTokenCmd.command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -80,6 +89,9 @@ func (c *cmdGroup) command() *cobra.Command {
 	permissionCmd := cmdGroupPermission{global: c.global}
 	cmd.AddCommand(permissionCmd.command())
 
+	roleCmd := cmdGroupRole{global: c.global}
+	cmd.AddCommand(roleCmd.command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -632,6 +644,188 @@ func (c *cmdGroupPermissionRemove) run(cmd *cobra.Command, args []string) error
 	return resource.server.UpdateAuthGroup(resource.name, group.Writable(), eTag)
 }
 
+type cmdGroupRole struct {
+	global *cmdGlobal
+}
+
+func (c *cmdGroupRole) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("role")
+	cmd.Short = i18n.G("Manage group roles")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage group roles`))
+
+	groupRoleAddCmd := cmdGroupRoleAdd{global: c.global}
+	cmd.AddCommand(groupRoleAddCmd.command())
+
+	groupRoleRemoveCmd := cmdGroupRoleRemove{global: c.global}
+	cmd.AddCommand(groupRoleRemoveCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdGroupRoleAdd struct {
+	global *cmdGlobal
+}
+
+func (c *cmdGroupRoleAdd) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<group> <role> [<entity_name>] [<key>=<value>...]"))
+	cmd.Short = i18n.G("Assign roles to groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Assign roles to groups
+
+The role is assigned on the entity of the role's entity type with the given name.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth group role add my-group instance-operator c1 project=default
+   Assign the instance-operator role to my-group on instance c1 in the default project`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdGroupRoleAdd) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	group, eTag, err := resource.server.GetAuthGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	groupRole, err := parseGroupRoleArgs(resource.server, args)
+	if err != nil {
+		return err
+	}
+
+	if shared.ValueInSlice(*groupRole, group.Roles) {
+		return fmt.Errorf("Group %q already has role %q on entity %q", resource.name, groupRole.Role, groupRole.EntityReference)
+	}
+
+	group.Roles = append(group.Roles, *groupRole)
+	return resource.server.UpdateAuthGroup(resource.name, group.Writable(), eTag)
+}
+
+type cmdGroupRoleRemove struct {
+	global *cmdGlobal
+}
+
+func (c *cmdGroupRoleRemove) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<group> <role> [<entity_name>] [<key>=<value>...]"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Remove roles from groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Remove roles from groups`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdGroupRoleRemove) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	group, eTag, err := resource.server.GetAuthGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	groupRole, err := parseGroupRoleArgs(resource.server, args)
+	if err != nil {
+		return err
+	}
+
+	roles := make([]api.AuthGroupRole, 0, len(group.Roles))
+	removed := false
+	for _, existingRole := range group.Roles {
+		if *groupRole == existingRole {
+			removed = true
+			continue
+		}
+
+		roles = append(roles, existingRole)
+	}
+
+	if !removed {
+		return fmt.Errorf("Group %q does not have role %q on entity %q", resource.name, groupRole.Role, groupRole.EntityReference)
+	}
+
+	group.Roles = roles
+	return resource.server.UpdateAuthGroup(resource.name, group.Writable(), eTag)
+}
+
+// parseGroupRoleArgs parses the `<role> [<entity_name>] [<key>=<value>...]` arguments of
+// `lxc auth group role add/remove` and returns an api.AuthGroupRole that can be appended/removed from the list of
+// roles assigned to a group. The role is fetched from the server to determine the type of entity it applies to.
+func parseGroupRoleArgs(server lxd.InstanceServer, args []string) (*api.AuthGroupRole, error) {
+	role, _, err := server.GetAuthRole(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	entityType := entity.Type(role.EntityType)
+	if entityType == entity.TypeServer {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Expected two arguments: `lxc auth group role add [<remote>:]<group> <role>`")
+		}
+
+		return &api.AuthGroupRole{
+			Role:            role.Name,
+			EntityReference: entity.ServerURL().String(),
+		}, nil
+	}
+
+	if len(args) < 3 {
+		return nil, fmt.Errorf("Expected at least three arguments: `lxc auth group role add [<remote>:]<group> <role> <entity_name> [<key>=<value>...]`")
+	}
+
+	entityURL, err := parseEntityArgs(entityType, args[2], args[3:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.AuthGroupRole{
+		Role:            role.Name,
+		EntityReference: entityURL.String(),
+	}, nil
+}
+
 // parsePermissionArgs parses the `<entity_type> [<entity_name>] <entitlement> [<key>=<value>...]` arguments of
 // `lxc auth group permission add/remove` and returns an api.Permission that can be appended/removed from the list of
 // permissions belonging to a group.
@@ -658,19 +852,29 @@ func parsePermissionArgs(args []string) (*api.Permission, error) {
 		return nil, fmt.Errorf("Expected at least four arguments: `lxc auth group grant [<remote>:]<group> <object_type> <object_name> <entitlement> [<key>=<value>...]`")
 	}
 
-	entityName := args[2]
-	entitlement := args[3]
+	entityURL, err := parseEntityArgs(entityType, args[2], args[4:])
+	if err != nil {
+		return nil, err
+	}
 
-	kv := make(map[string]string)
-	if len(args) > 4 {
-		for _, arg := range args[4:] {
-			k, v, ok := strings.Cut(arg, "=")
-			if !ok {
-				return nil, fmt.Errorf("Supplementary arguments must be of the form <key>=<value>")
-			}
+	return &api.Permission{
+		EntityType:      string(entityType),
+		EntityReference: entityURL.String(),
+		Entitlement:     args[3],
+	}, nil
+}
 
-			kv[k] = v
+// parseEntityArgs parses the `<entity_name> [<key>=<value>...]` arguments used to reference an entity of the given
+// type and returns the URL of the entity.
+func parseEntityArgs(entityType entity.Type, entityName string, kvArgs []string) (*api.URL, error) {
+	kv := make(map[string]string)
+	for _, arg := range kvArgs {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("Supplementary arguments must be of the form <key>=<value>")
 		}
+
+		kv[k] = v
 	}
 
 	pathArgs := []string{entityName}
@@ -712,39 +916,43 @@ func parsePermissionArgs(args []string) (*api.Permission, error) {
 		return nil, err
 	}
 
-	return &api.Permission{
-		EntityType:      string(entityType),
-		EntityReference: entityURL.String(),
-		Entitlement:     entitlement,
-	}, nil
+	return entityURL, nil
 }
 
-type cmdIdentity struct {
+type cmdRole struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentity) command() *cobra.Command {
+func (c *cmdRole) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("identity")
-	cmd.Aliases = []string{"user"}
-	cmd.Short = i18n.G("Manage identities")
+	cmd.Use = usage("role")
+	cmd.Short = i18n.G("Manage roles")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Manage identities`))
+		`Manage roles
 
-	identityListCmd := cmdIdentityList{global: c.global}
-	cmd.AddCommand(identityListCmd.command())
+A role is a named set of entitlements on a single entity type. Roles are
+assigned to groups on a specific entity with "lxc auth group role add".`))
 
-	identityShowCmd := cmdIdentityShow{global: c.global}
-	cmd.AddCommand(identityShowCmd.command())
+	roleCreateCmd := cmdRoleCreate{global: c.global}
+	cmd.AddCommand(roleCreateCmd.command())
 
-	identityInfoCmd := cmdIdentityInfo{global: c.global}
-	cmd.AddCommand(identityInfoCmd.command())
+	roleDeleteCmd := cmdRoleDelete{global: c.global}
+	cmd.AddCommand(roleDeleteCmd.command())
 
-	identityEditCmd := cmdIdentityEdit{global: c.global}
-	cmd.AddCommand(identityEditCmd.command())
+	roleEditCmd := cmdRoleEdit{global: c.global}
+	cmd.AddCommand(roleEditCmd.command())
 
-	identityGroupCmd := cmdIdentityGroup{global: c.global}
-	cmd.AddCommand(identityGroupCmd.command())
+	roleShowCmd := cmdRoleShow{global: c.global}
+	cmd.AddCommand(roleShowCmd.command())
+
+	roleListCmd := cmdRoleList{global: c.global}
+	cmd.AddCommand(roleListCmd.command())
+
+	roleRenameCmd := cmdRoleRename{global: c.global}
+	cmd.AddCommand(roleRenameCmd.command())
+
+	roleEntitlementCmd := cmdRoleEntitlement{global: c.global}
+	cmd.AddCommand(roleEntitlementCmd.command())
 
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
@@ -752,98 +960,89 @@ func (c *cmdIdentity) command() *cobra.Command {
 	return cmd
 }
 
-type cmdIdentityList struct {
-	global     *cmdGlobal
-	flagFormat string
+type cmdRoleCreate struct {
+	global          *cmdGlobal
+	flagDescription string
 }
 
-func (c *cmdIdentityList) command() *cobra.Command {
+func (c *cmdRoleCreate) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("list", i18n.G("[<remote>:]"))
-	cmd.Aliases = []string{"ls"}
-	cmd.Short = i18n.G("List identities")
+	cmd.Use = usage("create", i18n.G("[<remote>:]<role> <entity_type> [<entitlement>...]"))
+	cmd.Short = i18n.G("Create roles")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`List identities`))
-
+		`Create roles`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth role create instance-operator instance can_view can_exec can_access_console
+   Create a role granting view, exec and console access on instances`))
+	cmd.Flags().StringVarP(&c.flagDescription, "description", "d", "", "Role description")
 	cmd.RunE = c.run
-	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
 
 	return cmd
 }
 
-func (c *cmdIdentityList) run(cmd *cobra.Command, args []string) error {
+func (c *cmdRoleCreate) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
 	if exit {
 		return err
 	}
 
 	// Parse remote
-	remote := ""
-	if len(args) > 0 {
-		remote = args[0]
-	}
-
-	resources, err := c.global.ParseServers(remote)
+	resources, err := c.global.ParseServers(args[0])
 	if err != nil {
 		return err
 	}
 
 	resource := resources[0]
 
-	// List identities
-	identities, err := resource.server.GetIdentities()
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing role name"))
+	}
+
+	entityType := entity.Type(args[1])
+	err = entityType.Validate()
 	if err != nil {
 		return err
 	}
 
-	data := [][]string{}
-	delimiter := "\n"
-	if c.flagFormat == cli.TableFormatCSV {
-		delimiter = ","
-	}
+	// Create the role
+	role := api.AuthRolesPost{}
+	role.Name = resource.name
+	role.Description = c.flagDescription
+	role.EntityType = string(entityType)
+	role.Entitlements = args[2:]
 
-	for _, identity := range identities {
-		data = append(data, []string{identity.AuthenticationMethod, identity.Type, identity.Name, identity.Identifier, strings.Join(identity.Groups, delimiter)})
+	err = resource.server.CreateAuthRole(role)
+	if err != nil {
+		return err
 	}
 
-	sort.Sort(cli.SortColumnsNaturally(data))
-
-	header := []string{
-		i18n.G("AUTHENTICATION METHOD"),
-		i18n.G("TYPE"),
-		i18n.G("NAME"),
-		i18n.G("IDENTIFIER"),
-		i18n.G("GROUPS"),
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Role %s created")+"\n", resource.name)
 	}
 
-	return cli.RenderTable(c.flagFormat, header, data, identities)
+	return nil
 }
 
-// Show.
-type cmdIdentityShow struct {
+// Delete.
+type cmdRoleDelete struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityShow) command() *cobra.Command {
+func (c *cmdRoleDelete) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("show", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier>"))
-	cmd.Short = i18n.G("View an identity")
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<role>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete roles")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Show identity configurations
-
-The argument must be a concatenation of the authentication method and either the
-name or identifier of the identity, delimited by a forward slash. This command
-will fail if an identity name is used that is not unique within the authentication
-method. Use the identifier instead if this occurs.
-`))
+		`Delete roles`))
 
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityShow) run(cmd *cobra.Command, args []string) error {
+func (c *cmdRoleDelete) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
 	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
 	if exit {
@@ -859,132 +1058,62 @@ func (c *cmdIdentityShow) run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity argument"))
-	}
-
-	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
-	if !ok {
-		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+		return fmt.Errorf(i18n.G("Missing role name"))
 	}
 
-	// Show the identity
-	identity, _, err := resource.server.GetIdentity(authenticationMethod, nameOrID)
+	// Delete the role
+	err = resource.server.DeleteAuthRole(resource.name)
 	if err != nil {
 		return err
 	}
 
-	data, err := yaml.Marshal(&identity)
-	if err != nil {
-		return err
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Role %s deleted")+"\n", resource.name)
 	}
 
-	fmt.Printf("%s", data)
-
 	return nil
 }
 
-// Show current.
-type cmdIdentityInfo struct {
+// Edit.
+type cmdRoleEdit struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityInfo) command() *cobra.Command {
+func (c *cmdRoleEdit) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("info", i18n.G("[<remote>:]"))
-	cmd.Short = i18n.G("View the current identity")
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<role>"))
+	cmd.Short = i18n.G("Edit roles as YAML")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Show the current identity
-
-This command will display permissions for the current user.
-This includes contextual information, such as effective groups and permissions
-that are granted via identity provider group mappings. 
-`))
-
-	cmd.RunE = c.run
-
-	return cmd
-}
-
-func (c *cmdIdentityInfo) run(cmd *cobra.Command, args []string) error {
-	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
-	if exit {
-		return err
-	}
-
-	// Connect to LXD
-	var server lxd.InstanceServer
-	if len(args) == 0 {
-		server, err = c.global.conf.GetInstanceServer(c.global.conf.DefaultRemote)
-		if err != nil {
-			return err
-		}
-	} else {
-		resources, err := c.global.ParseServers(args[0])
-		if err != nil {
-			return err
-		}
-
-		server = resources[0].server
-	}
-
-	// Show the identity
-	identity, _, err := server.GetCurrentIdentityInfo()
-	if err != nil {
-		return err
-	}
-
-	data, err := yaml.Marshal(&identity)
-	if err != nil {
-		return err
-	}
-
-	fmt.Printf("%s", data)
-
-	return nil
-}
-
-// Edit.
-type cmdIdentityEdit struct {
-	global *cmdGlobal
-}
-
-func (c *cmdIdentityEdit) command() *cobra.Command {
-	cmd := &cobra.Command{}
-	cmd.Use = usage("edit", i18n.G("[<remote>:]<group>"))
-	cmd.Short = i18n.G("Edit an identity as YAML")
-	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Edit an identity as YAML`))
-	cmd.Example = cli.FormatSection("", i18n.G(
-		`lxc auth identity edit <authentication_method>/<name_or_identifier> < identity.yaml
-   Update an identity using the content of identity.yaml`))
+		`Edit roles as YAML`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth role edit <role> < role.yaml
+   Update a role using the content of role.yaml`))
 
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityEdit) helpTemplate() string {
+func (c *cmdRoleEdit) helpTemplate() string {
 	return i18n.G(
-		`### This is a YAML representation of the group.
+		`### This is a YAML representation of the role.
 ### Any line starting with a '# will be ignored.
 ###
-### An identity has the following format:
-### authentication_method: oidc
-### type: OIDC client
-### identifier: jane.doe@example.com
-### name: Jane Doe
-### metadata:
-###   subject: auth0|123456789
-### projects:
-### - default
+### A role has the following format:
+### name: instance-operator
+### description: Operate instances without changing their configuration.
+### entity_type: instance
+### entitlements:
+### - can_view
+### - can_exec
+### - can_access_console
 ### groups:
-### - my-first-group
+### - operators
 ###
-### Note that all identity information is shown but only the projects and groups can be modified`)
+### Note that all role information is shown but only the description and entitlements can be modified`)
 }
 
-func (c *cmdIdentityEdit) run(cmd *cobra.Command, args []string) error {
+func (c *cmdRoleEdit) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
 	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
 	if exit {
@@ -1000,12 +1129,7 @@ func (c *cmdIdentityEdit) run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity argument"))
-	}
-
-	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
-	if !ok {
-		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+		return fmt.Errorf(i18n.G("Missing role name"))
 	}
 
 	// If stdin isn't a terminal, read text from it
@@ -1015,22 +1139,22 @@ func (c *cmdIdentityEdit) run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		newdata := api.IdentityPut{}
+		newdata := api.AuthRolePut{}
 		err = yaml.Unmarshal(contents, &newdata)
 		if err != nil {
 			return err
 		}
 
-		return resource.server.UpdateIdentity(authenticationMethod, nameOrID, newdata, "")
+		return resource.server.UpdateAuthRole(resource.name, newdata, "")
 	}
 
 	// Extract the current value
-	identity, etag, err := resource.server.GetIdentity(authenticationMethod, nameOrID)
+	role, etag, err := resource.server.GetAuthRole(resource.name)
 	if err != nil {
 		return err
 	}
 
-	data, err := yaml.Marshal(&identity)
+	data, err := yaml.Marshal(&role)
 	if err != nil {
 		return err
 	}
@@ -1043,15 +1167,15 @@ func (c *cmdIdentityEdit) run(cmd *cobra.Command, args []string) error {
 
 	for {
 		// Parse the text received from the editor
-		newdata := api.IdentityPut{}
+		newdata := api.AuthRolePut{}
 		err = yaml.Unmarshal(content, &newdata)
 		if err == nil {
-			err = resource.server.UpdateIdentity(authenticationMethod, nameOrID, newdata, etag)
+			err = resource.server.UpdateAuthRole(resource.name, newdata, etag)
 		}
 
 		// Respawn the editor
 		if err != nil {
-			fmt.Fprintf(os.Stderr, i18n.G("Could not parse identity: %s")+"\n", err)
+			fmt.Fprintf(os.Stderr, i18n.G("Could not parse role: %s")+"\n", err)
 			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
 
 			_, err := os.Stdin.Read(make([]byte, 1))
@@ -1073,46 +1197,87 @@ func (c *cmdIdentityEdit) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-type cmdIdentityGroup struct {
-	global *cmdGlobal
+type cmdRoleList struct {
+	global     *cmdGlobal
+	flagFormat string
 }
 
-func (c *cmdIdentityGroup) command() *cobra.Command {
+func (c *cmdRoleList) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("group")
-	cmd.Short = i18n.G("Manage groups for the identity")
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List roles")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Manage groups for the identity`))
+		`List roles`))
 
-	identityGroupAddCmd := cmdIdentityGroupAdd{global: c.global}
-	cmd.AddCommand(identityGroupAddCmd.command())
-
-	identityGroupRemoveCmd := cmdIdentityGroupRemove{global: c.global}
-	cmd.AddCommand(identityGroupRemoveCmd.command())
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
 
-	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
-	cmd.Args = cobra.NoArgs
-	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
 	return cmd
 }
 
-type cmdIdentityGroupAdd struct {
+func (c *cmdRoleList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List roles
+	roles, err := resource.server.GetAuthRoles()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, role := range roles {
+		data = append(data, []string{role.Name, role.EntityType, strings.Join(role.Entitlements, "\n"), role.Description})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("ENTITY TYPE"),
+		i18n.G("ENTITLEMENTS"),
+		i18n.G("DESCRIPTION"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, roles)
+}
+
+// Rename.
+type cmdRoleRename struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityGroupAdd) command() *cobra.Command {
+func (c *cmdRoleRename) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("add", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <group>"))
-	cmd.Short = i18n.G("Add a group to an identity")
+	cmd.Use = usage("rename", i18n.G("[<remote>:]<role> <new_name>"))
+	cmd.Aliases = []string{"mv"}
+	cmd.Short = i18n.G("Rename roles")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Add a group to an identity`))
+		`Rename roles`))
 
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityGroupAdd) run(cmd *cobra.Command, args []string) error {
+func (c *cmdRoleRename) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
 	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
 	if exit {
@@ -1128,51 +1293,42 @@ func (c *cmdIdentityGroupAdd) run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity argument"))
-	}
-
-	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
-	if !ok {
-		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+		return fmt.Errorf(i18n.G("Missing role name"))
 	}
 
-	identity, eTag, err := resource.server.GetIdentity(authenticationMethod, nameOrID)
+	// Rename the role
+	err = resource.server.RenameAuthRole(resource.name, api.AuthRolePost{Name: args[1]})
 	if err != nil {
 		return err
 	}
 
-	added := false
-	if !shared.ValueInSlice(args[1], identity.Groups) {
-		identity.Groups = append(identity.Groups, args[1])
-		added = true
-	}
-
-	if !added {
-		return fmt.Errorf("Identity %q is already a member of group %q", resource.name, args[1])
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Role %s renamed to %s")+"\n", resource.name, args[1])
 	}
 
-	return resource.server.UpdateIdentity(authenticationMethod, nameOrID, identity.Writable(), eTag)
+	return nil
 }
 
-type cmdIdentityGroupRemove struct {
+// Show.
+type cmdRoleShow struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityGroupRemove) command() *cobra.Command {
+func (c *cmdRoleShow) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("remove", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <group>"))
-	cmd.Short = i18n.G("Remove a group from an identity")
+	cmd.Use = usage("show", i18n.G("[<remote>:]<role>"))
+	cmd.Short = i18n.G("Show role configurations")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Remove a group from an identity`))
+		`Show role configurations`))
 
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityGroupRemove) run(cmd *cobra.Command, args []string) error {
+func (c *cmdRoleShow) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
 	if exit {
 		return err
 	}
@@ -1186,56 +1342,41 @@ func (c *cmdIdentityGroupRemove) run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity argument"))
-	}
-
-	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
-	if !ok {
-		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+		return fmt.Errorf(i18n.G("Missing role name"))
 	}
 
-	identity, eTag, err := resource.server.GetIdentity(authenticationMethod, nameOrID)
+	// Show the role
+	role, _, err := resource.server.GetAuthRole(resource.name)
 	if err != nil {
 		return err
 	}
 
-	if len(identity.Groups) == 0 {
-		return fmt.Errorf("Identity %q is not a member of any groups", resource.name)
-	}
-
-	groups := make([]string, 0, len(identity.Groups)-1)
-	removed := false
-	for _, existingGroup := range identity.Groups {
-		if args[1] == existingGroup {
-			removed = true
-			continue
-		}
-
-		groups = append(groups, existingGroup)
+	data, err := yaml.Marshal(&role)
+	if err != nil {
+		return err
 	}
 
-	if !removed {
-		return fmt.Errorf("Identity %q is not a member of group %q", resource.name, args[0])
-	}
+	fmt.Printf("%s", data)
 
-	identity.Groups = groups
-	return resource.server.UpdateIdentity(authenticationMethod, nameOrID, identity.Writable(), eTag)
+	return nil
 }
 
-type cmdPermission struct {
+type cmdRoleEntitlement struct {
 	global *cmdGlobal
 }
 
-func (c *cmdPermission) command() *cobra.Command {
+func (c *cmdRoleEntitlement) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("permission")
-	cmd.Aliases = []string{"perm"}
-	cmd.Short = i18n.G("Inspect permissions")
+	cmd.Use = usage("entitlement")
+	cmd.Short = i18n.G("Manage role entitlements")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Inspect permissions`))
+		`Manage role entitlements`))
 
-	permissionListCmd := cmdPermissionList{global: c.global}
-	cmd.AddCommand(permissionListCmd.command())
+	roleEntitlementAddCmd := cmdRoleEntitlementAdd{global: c.global}
+	cmd.AddCommand(roleEntitlementAddCmd.command())
+
+	roleEntitlementRemoveCmd := cmdRoleEntitlementRemove{global: c.global}
+	cmd.AddCommand(roleEntitlementRemoveCmd.command())
 
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
@@ -1243,217 +1384,1672 @@ func (c *cmdPermission) command() *cobra.Command {
 	return cmd
 }
 
-type cmdPermissionList struct {
-	global              *cmdGlobal
-	flagMaxEntitlements int
-	flagFormat          string
+type cmdRoleEntitlementAdd struct {
+	global *cmdGlobal
 }
 
-func (c *cmdPermissionList) command() *cobra.Command {
+func (c *cmdRoleEntitlementAdd) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("list", i18n.G("[<remote>:] [project=<project_name>] [entity_type=<entity_type>]"))
-	cmd.Short = i18n.G("List permissions")
+	cmd.Use = usage("add", i18n.G("[<remote>:]<role> <entitlement>"))
+	cmd.Short = i18n.G("Add entitlements to roles")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`List permissions`))
+		`Add entitlements to roles`))
 
-	cmd.Flags().IntVar(&c.flagMaxEntitlements, "max-entitlements", 3, "Maximum number of unassigned entitlements to display before overflowing (set to zero to display all)")
-	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", cli.TableFormatTable, "Display format (json, yaml, table, compact, csv)")
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdPermissionList) run(cmd *cobra.Command, args []string) error {
+func (c *cmdRoleEntitlementAdd) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 0, 3)
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
 	if exit {
 		return err
 	}
 
-	filters := args
-	remote := c.global.conf.DefaultRemote
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
 
-	// If there are arguments, and first argument contains a colon and does not contain an equals, use it as the remote name.
-	if len(args) > 0 && strings.Contains(args[0], ":") && !strings.Contains(args[0], "=") {
-		var err error
-		remote, _, err = c.global.conf.ParseRemote(args[0])
-		if err != nil {
-			return err
-		}
+	resource := resources[0]
 
-		filters = args[1:]
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing role name"))
 	}
 
-	client, err := c.global.conf.GetInstanceServer(remote)
+	role, eTag, err := resource.server.GetAuthRole(resource.name)
 	if err != nil {
 		return err
 	}
 
-	projectName := ""
-	entityType := entity.Type("")
-	for _, filter := range filters {
-		k, v, ok := strings.Cut(filter, "=")
-		if !ok {
-			return fmt.Errorf("Badly formatted supplementary argument %q", filter)
-		}
+	if shared.ValueInSlice(args[1], role.Entitlements) {
+		return fmt.Errorf("Role %q already has entitlement %q", resource.name, args[1])
+	}
 
-		if k == "project" {
-			projectName = v
-		} else if k == "entity_type" {
-			entityType = entity.Type(v)
-			err = entityType.Validate()
-			if err != nil {
-				return fmt.Errorf("Invalid entity type in supplementary argument %q: %w", filter, err)
-			}
-		} else {
-			return fmt.Errorf("Available filters are `entity_type` and `project`, got %q", filter)
-		}
+	role.Entitlements = append(role.Entitlements, args[1])
+	return resource.server.UpdateAuthRole(resource.name, role.Writable(), eTag)
+}
+
+type cmdRoleEntitlementRemove struct {
+	global *cmdGlobal
+}
+
+func (c *cmdRoleEntitlementRemove) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<role> <entitlement>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Remove entitlements from roles")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Remove entitlements from roles`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdRoleEntitlementRemove) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
 	}
 
-	permissionsInfo, err := client.GetPermissionsInfo(lxd.GetPermissionsArgs{
-		EntityType:  string(entityType),
-		ProjectName: projectName,
-	})
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
 	if err != nil {
 		return err
 	}
 
-	// If we're displaying with JSON or YAML, display the raw data now.
-	if c.flagFormat == cli.TableFormatJSON || c.flagFormat == cli.TableFormatYAML {
-		return cli.RenderTable(c.flagFormat, nil, nil, permissionsInfo)
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing role name"))
 	}
 
-	// Otherwise, data returned from the permissions API can be condensed into a more easily viewable format.
-	// We'll group entitlements together by the API resource they are defined on, and separate the entitlements that
-	// are assigned to groups from the ones that are not assigned.
-	type displayPermission struct {
-		entityType              string
-		url                     string
-		entitlementsAssigned    map[string][]string
-		entitlementsNotAssigned []string
+	role, eTag, err := resource.server.GetAuthRole(resource.name)
+	if err != nil {
+		return err
 	}
 
-	i := 0
-	var displayPermissions []*displayPermission
-	displayPermissionIdx := make(map[string]int)
-	for _, perm := range permissionsInfo {
-		idx, ok := displayPermissionIdx[perm.EntityReference]
-		if ok {
-			dp := displayPermissions[idx]
-			if len(perm.Groups) > 0 {
-				dp.entitlementsAssigned[perm.Entitlement] = perm.Groups
-			} else {
-				dp.entitlementsNotAssigned = append(dp.entitlementsNotAssigned, perm.Entitlement)
-			}
+	if !shared.ValueInSlice(args[1], role.Entitlements) {
+		return fmt.Errorf("Role %q does not have entitlement %q", resource.name, args[1])
+	}
 
-			continue
+	entitlements := make([]string, 0, len(role.Entitlements)-1)
+	for _, entitlement := range role.Entitlements {
+		if entitlement != args[1] {
+			entitlements = append(entitlements, entitlement)
 		}
+	}
 
-		dp := displayPermission{
-			entityType:           perm.EntityType,
-			url:                  perm.EntityReference,
-			entitlementsAssigned: make(map[string][]string),
-		}
+	role.Entitlements = entitlements
+	return resource.server.UpdateAuthRole(resource.name, role.Writable(), eTag)
+}
 
-		if len(perm.Groups) > 0 {
-			dp.entitlementsAssigned[perm.Entitlement] = perm.Groups
-		} else {
-			dp.entitlementsNotAssigned = append(dp.entitlementsNotAssigned, perm.Entitlement)
-		}
+type cmdIdentity struct {
+	global *cmdGlobal
+}
 
-		displayPermissions = append(displayPermissions, &dp)
-		displayPermissionIdx[perm.EntityReference] = i
-		i++
+func (c *cmdIdentity) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("identity")
+	cmd.Aliases = []string{"user"}
+	cmd.Short = i18n.G("Manage identities")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage identities`))
+
+	identityCreateCmd := cmdIdentityCreate{global: c.global}
+	cmd.AddCommand(identityCreateCmd.command())
+
+	identityDeleteCmd := cmdIdentityDelete{global: c.global}
+	cmd.AddCommand(identityDeleteCmd.command())
+
+	identityListCmd := cmdIdentityList{global: c.global}
+	cmd.AddCommand(identityListCmd.command())
+
+	identityShowCmd := cmdIdentityShow{global: c.global}
+	cmd.AddCommand(identityShowCmd.command())
+
+	identityInfoCmd := cmdIdentityInfo{global: c.global}
+	cmd.AddCommand(identityInfoCmd.command())
+
+	identityEditCmd := cmdIdentityEdit{global: c.global}
+	cmd.AddCommand(identityEditCmd.command())
+
+	identityGroupCmd := cmdIdentityGroup{global: c.global}
+	cmd.AddCommand(identityGroupCmd.command())
+
+	identityTokenCmd := cmdIdentityToken{global: c.global}
+	cmd.AddCommand(identityTokenCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdIdentityList struct {
+	global     *cmdGlobal
+	flagFormat string
+}
+
+func (c *cmdIdentityList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List identities")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List identities`))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdIdentityList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
 	}
 
-	columns := map[rune]cli.Column{
-		't': {
-			Header: "ENTITY TYPE",
-			DataFunc: func(a any) (string, error) {
-				p, _ := a.(*displayPermission)
-				return p.entityType, nil
-			},
-		},
-		'u': {
-			Header: "URL",
-			DataFunc: func(a any) (string, error) {
-				p, _ := a.(*displayPermission)
-				return p.url, nil
-			},
-		},
-		'e': {
-			Header: "ENTITLEMENTS ==> (GROUPS)",
-			DataFunc: func(a any) (string, error) {
-				p, _ := a.(*displayPermission)
-				var rowsAssigned []string
-				for k, v := range p.entitlementsAssigned {
-					// Pretty format for tables.
-					assignedRow := fmt.Sprintf("%s ==> (%s)", k, strings.Join(v, ", "))
-					if c.flagFormat == cli.TableFormatCSV {
-						// Machine readable format for CSV.
-						assignedRow = fmt.Sprintf("%s:(%s)", k, strings.Join(v, ","))
-					}
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List identities
+	identities, err := resource.server.GetIdentities()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	delimiter := "\n"
+	if c.flagFormat == cli.TableFormatCSV {
+		delimiter = ","
+	}
+
+	for _, identity := range identities {
+		data = append(data, []string{identity.AuthenticationMethod, identity.Type, identity.Name, identity.Identifier, strings.Join(identity.Groups, delimiter)})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("AUTHENTICATION METHOD"),
+		i18n.G("TYPE"),
+		i18n.G("NAME"),
+		i18n.G("IDENTIFIER"),
+		i18n.G("GROUPS"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, identities)
+}
+
+// Show.
+type cmdIdentityShow struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier>"))
+	cmd.Short = i18n.G("View an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show identity configurations
+
+The argument must be a concatenation of the authentication method and either the
+name or identifier of the identity, delimited by a forward slash. This command
+will fail if an identity name is used that is not unique within the authentication
+method. Use the identifier instead if this occurs.
+`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
+	if !ok {
+		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+	}
+
+	// Show the identity
+	identity, _, err := resource.server.GetIdentity(authenticationMethod, nameOrID)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&identity)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Show current.
+type cmdIdentityInfo struct {
+	global *cmdGlobal
+
+	flagFormat string
+}
+
+func (c *cmdIdentityInfo) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("info", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("View the current identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the current identity
+
+This command will display permissions for the current user.
+This includes contextual information, such as effective groups and permissions
+that are granted via identity provider group mappings. 
+`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityInfo) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Connect to LXD
+	var server lxd.InstanceServer
+	if len(args) == 0 {
+		server, err = c.global.conf.GetInstanceServer(c.global.conf.DefaultRemote)
+		if err != nil {
+			return err
+		}
+	} else {
+		resources, err := c.global.ParseServers(args[0])
+		if err != nil {
+			return err
+		}
+
+		server = resources[0].server
+	}
+
+	// Show the identity
+	identity, _, err := server.GetCurrentIdentityInfo()
+	if err != nil {
+		return err
+	}
+
+	return cli.RenderInfo(c.flagFormat, identity, func() error {
+		data, err := yaml.Marshal(&identity)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s", data)
+
+		return nil
+	})
+}
+
+// Edit.
+type cmdIdentityEdit struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Edit an identity as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Edit an identity as YAML`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth identity edit <authentication_method>/<name_or_identifier> < identity.yaml
+   Update an identity using the content of identity.yaml`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the group.
+### Any line starting with a '# will be ignored.
+###
+### An identity has the following format:
+### authentication_method: oidc
+### type: OIDC client
+### identifier: jane.doe@example.com
+### name: Jane Doe
+### metadata:
+###   subject: auth0|123456789
+### projects:
+### - default
+### groups:
+### - my-first-group
+###
+### Note that all identity information is shown but only the projects and groups can be modified`)
+}
+
+func (c *cmdIdentityEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
+	if !ok {
+		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		newdata := api.IdentityPut{}
+		err = yaml.Unmarshal(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateIdentity(authenticationMethod, nameOrID, newdata, "")
+	}
+
+	// Extract the current value
+	identity, etag, err := resource.server.GetIdentity(authenticationMethod, nameOrID)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&identity)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor
+		newdata := api.IdentityPut{}
+		err = yaml.Unmarshal(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateIdentity(authenticationMethod, nameOrID, newdata, etag)
+		}
+
+		// Respawn the editor
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Could not parse identity: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+type cmdIdentityGroup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityGroup) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("group")
+	cmd.Short = i18n.G("Manage groups for the identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage groups for the identity`))
+
+	identityGroupAddCmd := cmdIdentityGroupAdd{global: c.global}
+	cmd.AddCommand(identityGroupAddCmd.command())
+
+	identityGroupRemoveCmd := cmdIdentityGroupRemove{global: c.global}
+	cmd.AddCommand(identityGroupRemoveCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdIdentityGroupAdd struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityGroupAdd) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <group>"))
+	cmd.Short = i18n.G("Add a group to an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Add a group to an identity`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityGroupAdd) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
+	if !ok {
+		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+	}
+
+	identity, eTag, err := resource.server.GetIdentity(authenticationMethod, nameOrID)
+	if err != nil {
+		return err
+	}
+
+	added := false
+	if !shared.ValueInSlice(args[1], identity.Groups) {
+		identity.Groups = append(identity.Groups, args[1])
+		added = true
+	}
+
+	if !added {
+		return fmt.Errorf("Identity %q is already a member of group %q", resource.name, args[1])
+	}
+
+	return resource.server.UpdateIdentity(authenticationMethod, nameOrID, identity.Writable(), eTag)
+}
+
+type cmdIdentityGroupRemove struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityGroupRemove) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <group>"))
+	cmd.Short = i18n.G("Remove a group from an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Remove a group from an identity`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityGroupRemove) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
+	if !ok {
+		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+	}
+
+	identity, eTag, err := resource.server.GetIdentity(authenticationMethod, nameOrID)
+	if err != nil {
+		return err
+	}
+
+	if len(identity.Groups) == 0 {
+		return fmt.Errorf("Identity %q is not a member of any groups", resource.name)
+	}
+
+	groups := make([]string, 0, len(identity.Groups)-1)
+	removed := false
+	for _, existingGroup := range identity.Groups {
+		if args[1] == existingGroup {
+			removed = true
+			continue
+		}
+
+		groups = append(groups, existingGroup)
+	}
+
+	if !removed {
+		return fmt.Errorf("Identity %q is not a member of group %q", resource.name, args[0])
+	}
+
+	identity.Groups = groups
+	return resource.server.UpdateIdentity(authenticationMethod, nameOrID, identity.Writable(), eTag)
+}
+
+// Create.
+type cmdIdentityCreate struct {
+	global     *cmdGlobal
+	flagGroups []string
+}
+
+func (c *cmdIdentityCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<authentication_method>/<name>"))
+	cmd.Short = i18n.G("Create an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create an identity
+
+Only identities with the "bearer" authentication method can be created. These are
+service accounts that authenticate with a bearer token issued by LXD. Use
+"lxc auth identity token issue" to issue a token for the identity.
+`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth identity create bearer/ci-runner --group ci
+    Create a bearer identity named "ci-runner" in group "ci".`))
+
+	cmd.Flags().StringSliceVarP(&c.flagGroups, "group", "g", nil, i18n.G("Group to add the identity to")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	authenticationMethod, name, ok := strings.Cut(resource.name, "/")
+	if !ok {
+		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name>`, got %q", args[0])
+	}
+
+	if authenticationMethod != api.AuthenticationMethodBearer {
+		return fmt.Errorf(i18n.G("Only identities with authentication method %q can be created"), api.AuthenticationMethodBearer)
+	}
+
+	// Create the identity
+	err = resource.server.CreateIdentityBearer(api.IdentitiesBearerPost{
+		Name:   name,
+		Groups: c.flagGroups,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Identity %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdIdentityDelete struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Delete an identity
+
+Only identities with the "bearer" authentication method can be deleted.
+`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
+	if !ok {
+		return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+	}
+
+	// Delete the identity
+	err = resource.server.DeleteIdentity(authenticationMethod, nameOrID)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Identity %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+type cmdIdentityToken struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityToken) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("token")
+	cmd.Short = i18n.G("Manage bearer tokens for identities")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage bearer tokens for identities`))
+
+	identityTokenIssueCmd := cmdIdentityTokenIssue{global: c.global}
+	cmd.AddCommand(identityTokenIssueCmd.command())
+
+	identityTokenRevokeCmd := cmdIdentityTokenRevoke{global: c.global}
+	cmd.AddCommand(identityTokenRevokeCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdIdentityTokenIssue struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityTokenIssue) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("issue", i18n.G("[<remote>:]<name_or_identifier>"))
+	cmd.Short = i18n.G("Issue a bearer token for an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Issue a bearer token for an identity
+
+A new token is issued for the bearer identity and printed. Any token previously
+issued for the identity is revoked, so this command is also used to rotate tokens.
+The token cannot be retrieved again after it has been issued.
+`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityTokenIssue) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	// Issue the token
+	token, err := resource.server.IssueIdentityBearerToken(resource.name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(token.Token)
+
+	return nil
+}
+
+type cmdIdentityTokenRevoke struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityTokenRevoke) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("revoke", i18n.G("[<remote>:]<name_or_identifier>"))
+	cmd.Short = i18n.G("Revoke the bearer token of an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Revoke the bearer token of an identity
+
+The identity is kept and a new token can be issued later.
+`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityTokenRevoke) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	// Revoke the token
+	err = resource.server.RevokeIdentityBearerToken(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Bearer token of identity %s revoked")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+type cmdPermission struct {
+	global *cmdGlobal
+}
+
+func (c *cmdPermission) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("permission")
+	cmd.Aliases = []string{"perm"}
+	cmd.Short = i18n.G("Inspect permissions")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Inspect permissions`))
+
+	permissionListCmd := cmdPermissionList{global: c.global}
+	cmd.AddCommand(permissionListCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdPermissionList struct {
+	global              *cmdGlobal
+	flagMaxEntitlements int
+	flagFormat          string
+}
+
+func (c *cmdPermissionList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:] [project=<project_name>] [entity_type=<entity_type>]"))
+	cmd.Short = i18n.G("List permissions")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List permissions`))
+
+	cmd.Flags().IntVar(&c.flagMaxEntitlements, "max-entitlements", 3, "Maximum number of unassigned entitlements to display before overflowing (set to zero to display all)")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", cli.TableFormatTable, "Display format (json, yaml, table, compact, csv)")
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdPermissionList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 3)
+	if exit {
+		return err
+	}
+
+	filters := args
+	remote := c.global.conf.DefaultRemote
+
+	// If there are arguments, and first argument contains a colon and does not contain an equals, use it as the remote name.
+	if len(args) > 0 && strings.Contains(args[0], ":") && !strings.Contains(args[0], "=") {
+		var err error
+		remote, _, err = c.global.conf.ParseRemote(args[0])
+		if err != nil {
+			return err
+		}
+
+		filters = args[1:]
+	}
+
+	client, err := c.global.conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	projectName := ""
+	entityType := entity.Type("")
+	for _, filter := range filters {
+		k, v, ok := strings.Cut(filter, "=")
+		if !ok {
+			return fmt.Errorf("Badly formatted supplementary argument %q", filter)
+		}
+
+		if k == "project" {
+			projectName = v
+		} else if k == "entity_type" {
+			entityType = entity.Type(v)
+			err = entityType.Validate()
+			if err != nil {
+				return fmt.Errorf("Invalid entity type in supplementary argument %q: %w", filter, err)
+			}
+		} else {
+			return fmt.Errorf("Available filters are `entity_type` and `project`, got %q", filter)
+		}
+	}
+
+	permissionsInfo, err := client.GetPermissionsInfo(lxd.GetPermissionsArgs{
+		EntityType:  string(entityType),
+		ProjectName: projectName,
+	})
+	if err != nil {
+		return err
+	}
+
+	// If we're displaying with JSON or YAML, display the raw data now.
+	if c.flagFormat == cli.TableFormatJSON || c.flagFormat == cli.TableFormatYAML {
+		return cli.RenderTable(c.flagFormat, nil, nil, permissionsInfo)
+	}
+
+	// Otherwise, data returned from the permissions API can be condensed into a more easily viewable format.
+	// We'll group entitlements together by the API resource they are defined on, and separate the entitlements that
+	// are assigned to groups from the ones that are not assigned.
+	type displayPermission struct {
+		entityType              string
+		url                     string
+		entitlementsAssigned    map[string][]string
+		entitlementsNotAssigned []string
+	}
+
+	i := 0
+	var displayPermissions []*displayPermission
+	displayPermissionIdx := make(map[string]int)
+	for _, perm := range permissionsInfo {
+		idx, ok := displayPermissionIdx[perm.EntityReference]
+		if ok {
+			dp := displayPermissions[idx]
+			if len(perm.Groups) > 0 {
+				dp.entitlementsAssigned[perm.Entitlement] = perm.Groups
+			} else {
+				dp.entitlementsNotAssigned = append(dp.entitlementsNotAssigned, perm.Entitlement)
+			}
+
+			continue
+		}
+
+		dp := displayPermission{
+			entityType:           perm.EntityType,
+			url:                  perm.EntityReference,
+			entitlementsAssigned: make(map[string][]string),
+		}
+
+		if len(perm.Groups) > 0 {
+			dp.entitlementsAssigned[perm.Entitlement] = perm.Groups
+		} else {
+			dp.entitlementsNotAssigned = append(dp.entitlementsNotAssigned, perm.Entitlement)
+		}
+
+		displayPermissions = append(displayPermissions, &dp)
+		displayPermissionIdx[perm.EntityReference] = i
+		i++
+	}
+
+	columns := map[rune]cli.Column{
+		't': {
+			Header: "ENTITY TYPE",
+			DataFunc: func(a any) (string, error) {
+				p, _ := a.(*displayPermission)
+				return p.entityType, nil
+			},
+		},
+		'u': {
+			Header: "URL",
+			DataFunc: func(a any) (string, error) {
+				p, _ := a.(*displayPermission)
+				return p.url, nil
+			},
+		},
+		'e': {
+			Header: "ENTITLEMENTS ==> (GROUPS)",
+			DataFunc: func(a any) (string, error) {
+				p, _ := a.(*displayPermission)
+				var rowsAssigned []string
+				for k, v := range p.entitlementsAssigned {
+					// Pretty format for tables.
+					assignedRow := fmt.Sprintf("%s ==> (%s)", k, strings.Join(v, ", "))
+					if c.flagFormat == cli.TableFormatCSV {
+						// Machine readable format for CSV.
+						assignedRow = fmt.Sprintf("%s:(%s)", k, strings.Join(v, ","))
+					}
+
+					rowsAssigned = append(rowsAssigned, assignedRow)
+				}
+
+				// Sort the entitlements alphabetically, and put the assigned entitlements first.
+				sort.Strings(rowsAssigned)
+				sort.Strings(p.entitlementsNotAssigned)
+
+				// Only show unassigned entitlements up to and including `--max-entitlements`
+				if c.flagMaxEntitlements > 0 && len(p.entitlementsNotAssigned) > c.flagMaxEntitlements {
+					p.entitlementsNotAssigned = p.entitlementsNotAssigned[:c.flagMaxEntitlements]
+					p.entitlementsNotAssigned = append(p.entitlementsNotAssigned[:c.flagMaxEntitlements], "...")
+				}
+
+				rows := append(rowsAssigned, p.entitlementsNotAssigned...)
+				delimiter := "\n"
+				if c.flagFormat == cli.TableFormatCSV {
+					// Don't use newlines for CSV. We can use a comma because the field will be wrapped in quotes.
+					delimiter = ","
+				}
+
+				return strings.Join(rows, delimiter), nil
+			},
+		},
+	}
+
+	return cli.RenderSlice(displayPermissions, c.flagFormat, "tue", "u", columns)
+}
+
+type cmdIdentityProviderGroup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityProviderGroup) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("identity-provider-group")
+	cmd.Aliases = []string{"idp-group"}
+	cmd.Short = i18n.G("Manage groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage groups`))
+
+	idpGroupCreateCmd := cmdIdentityProviderGroupCreate{global: c.global}
+	cmd.AddCommand(idpGroupCreateCmd.command())
+
+	idpGroupDeleteCmd := cmdIdentityProviderGroupDelete{global: c.global}
+	cmd.AddCommand(idpGroupDeleteCmd.command())
+
+	idpGroupEditCmd := cmdIdentityProviderGroupEdit{global: c.global}
+	cmd.AddCommand(idpGroupEditCmd.command())
+
+	idpGroupShowCmd := cmdIdentityProviderGroupShow{global: c.global}
+	cmd.AddCommand(idpGroupShowCmd.command())
+
+	idpGroupListCmd := cmdIdentityProviderGroupList{global: c.global}
+	cmd.AddCommand(idpGroupListCmd.command())
+
+	idpGroupRenameCmd := cmdIdentityProviderGroupRename{global: c.global}
+	cmd.AddCommand(idpGroupRenameCmd.command())
+
+	idpGroupGroupCmd := cmdIdentityProviderGroupGroup{global: c.global}
+	cmd.AddCommand(idpGroupGroupCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdIdentityProviderGroupCreate struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityProviderGroupCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Create identity provider groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create identity provider groups`))
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityProviderGroupCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity provider group name"))
+	}
+
+	// Create the identity provider group
+	group := api.IdentityProviderGroup{}
+	group.Name = resource.name
+
+	err = resource.server.CreateIdentityProviderGroup(group)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Identity provider group %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdIdentityProviderGroupDelete struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityProviderGroupDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<identity_provider_group>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete identity provider groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Delete identity provider groups`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityProviderGroupDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity provider group name"))
+	}
+
+	// Delete the identity provider group
+	err = resource.server.DeleteIdentityProviderGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Identity provider group %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Edit.
+type cmdIdentityProviderGroupEdit struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityProviderGroupEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<identity_provider_group>"))
+	cmd.Short = i18n.G("Edit identity provider groups as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Edit identity provider groups as YAML`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth identity-provider-group edit <identity_provider_group> < identity-provider-group.yaml
+   Update an identity provider group using the content of identity-provider-group.yaml`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityProviderGroupEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the identity provider group.
+### Any line starting with a '# will be ignored.
+###
+### An identity provider group has the following format:
+### name: operations
+### groups:
+### - foo
+### - bar
+###
+### Note that the name is shown but cannot be modified`)
+}
+
+func (c *cmdIdentityProviderGroupEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity provider group name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		newdata := api.IdentityProviderGroupPut{}
+		err = yaml.Unmarshal(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateIdentityProviderGroup(resource.name, newdata, "")
+	}
+
+	// Extract the current value
+	group, etag, err := resource.server.GetIdentityProviderGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor
+		newdata := api.IdentityProviderGroupPut{}
+		err = yaml.Unmarshal(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateIdentityProviderGroup(resource.name, newdata, etag)
+		}
+
+		// Respawn the editor
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Could not parse group: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+type cmdIdentityProviderGroupList struct {
+	global     *cmdGlobal
+	flagFormat string
+}
+
+func (c *cmdIdentityProviderGroupList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List identity provider groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List identity provider groups`))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdIdentityProviderGroupList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List identity provider groups
+	groups, err := resource.server.GetIdentityProviderGroups()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, group := range groups {
+		data = append(data, []string{group.Name, strings.Join(group.Groups, "\n")})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("GROUPS"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, groups)
+}
+
+// Rename.
+type cmdIdentityProviderGroupRename struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityProviderGroupRename) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rename", i18n.G("[<remote>:]<identity_provider_group> <new_name>"))
+	cmd.Aliases = []string{"mv"}
+	cmd.Short = i18n.G("Rename identity provider groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Rename identity provider groups`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityProviderGroupRename) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity provider group name"))
+	}
+
+	// Rename the group
+	err = resource.server.RenameIdentityProviderGroup(resource.name, api.IdentityProviderGroupPost{Name: args[1]})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Group %s renamed to %s")+"\n", resource.name, args[1])
+	}
+
+	return nil
+}
+
+// Show.
+type cmdIdentityProviderGroupShow struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityProviderGroupShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<identity_provider_group>"))
+	cmd.Short = i18n.G("Show an identity provider group")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show an identity provider group`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityProviderGroupShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	// Show the group
+	group, _, err := resource.server.GetIdentityProviderGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+type cmdIdentityProviderGroupGroup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityProviderGroupGroup) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("group")
+	cmd.Short = i18n.G("Manage identity provider group mappings")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage identity provider group mappings`))
+
+	identityProviderGroupGroupAddCmd := cmdIdentityProviderGroupGroupAdd{global: c.global}
+	cmd.AddCommand(identityProviderGroupGroupAddCmd.command())
+
+	identityProviderGroupGroupRemoveCmd := cmdIdentityProviderGroupGroupRemove{global: c.global}
+	cmd.AddCommand(identityProviderGroupGroupRemoveCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdIdentityProviderGroupGroupAdd struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityProviderGroupGroupAdd) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<identity_provider_group> <group>"))
+	cmd.Short = i18n.G("Add a group to an identity provider group")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Add a group to an identity provider group`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityProviderGroupGroupAdd) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity provider group name argument"))
+	}
+
+	idpGroup, eTag, err := resource.server.GetIdentityProviderGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	added := false
+	if !shared.ValueInSlice(args[1], idpGroup.Groups) {
+		idpGroup.Groups = append(idpGroup.Groups, args[1])
+		added = true
+	}
+
+	if !added {
+		return fmt.Errorf("Identity group %q is already mapped to group %q", resource.name, args[1])
+	}
+
+	return resource.server.UpdateIdentityProviderGroup(resource.name, idpGroup.Writable(), eTag)
+}
+
+type cmdIdentityProviderGroupGroupRemove struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityProviderGroupGroupRemove) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <group>"))
+	cmd.Short = i18n.G("Remove identities from groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Remove identities from groups`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityProviderGroupGroupRemove) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
 
-					rowsAssigned = append(rowsAssigned, assignedRow)
-				}
+	resource := resources[0]
 
-				// Sort the entitlements alphabetically, and put the assigned entitlements first.
-				sort.Strings(rowsAssigned)
-				sort.Strings(p.entitlementsNotAssigned)
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity provider group name argument"))
+	}
 
-				// Only show unassigned entitlements up to and including `--max-entitlements`
-				if c.flagMaxEntitlements > 0 && len(p.entitlementsNotAssigned) > c.flagMaxEntitlements {
-					p.entitlementsNotAssigned = p.entitlementsNotAssigned[:c.flagMaxEntitlements]
-					p.entitlementsNotAssigned = append(p.entitlementsNotAssigned[:c.flagMaxEntitlements], "...")
-				}
+	idpGroup, eTag, err := resource.server.GetIdentityProviderGroup(resource.name)
+	if err != nil {
+		return err
+	}
 
-				rows := append(rowsAssigned, p.entitlementsNotAssigned...)
-				delimiter := "\n"
-				if c.flagFormat == cli.TableFormatCSV {
-					// Don't use newlines for CSV. We can use a comma because the field will be wrapped in quotes.
-					delimiter = ","
-				}
+	if len(idpGroup.Groups) == 0 {
+		return fmt.Errorf("Identity provider group %q is not mapped to any groups", resource.name)
+	}
 
-				return strings.Join(rows, delimiter), nil
-			},
-		},
+	groups := make([]string, 0, len(idpGroup.Groups)-1)
+	removed := false
+	for _, existingGroup := range idpGroup.Groups {
+		if args[1] == existingGroup {
+			removed = true
+			continue
+		}
+
+		groups = append(groups, existingGroup)
 	}
 
-	return cli.RenderSlice(displayPermissions, c.flagFormat, "tue", "u", columns)
+	if !removed {
+		return fmt.Errorf("Identity provider group %q is not mapped to group %q", resource.name, args[1])
+	}
+
+	idpGroup.Groups = groups
+	return resource.server.UpdateIdentityProviderGroup(resource.name, idpGroup.Writable(), eTag)
 }
 
-type cmdIdentityProviderGroup struct {
+type cmdIdentityProviderGroupRule struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityProviderGroup) command() *cobra.Command {
+func (c *cmdIdentityProviderGroupRule) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("identity-provider-group")
-	cmd.Aliases = []string{"idp-group"}
-	cmd.Short = i18n.G("Manage groups")
+	cmd.Use = usage("identity-provider-group-rule")
+	cmd.Aliases = []string{"idp-group-rule"}
+	cmd.Short = i18n.G("Manage identity provider group rules")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Manage groups`))
+		`Manage identity provider group rules
 
-	idpGroupCreateCmd := cmdIdentityProviderGroupCreate{global: c.global}
-	cmd.AddCommand(idpGroupCreateCmd.command())
+Identity provider group rules map OIDC clients to groups when the claims of their token match
+all conditions of the rule.`))
 
-	idpGroupDeleteCmd := cmdIdentityProviderGroupDelete{global: c.global}
-	cmd.AddCommand(idpGroupDeleteCmd.command())
+	ruleCreateCmd := cmdIdentityProviderGroupRuleCreate{global: c.global}
+	cmd.AddCommand(ruleCreateCmd.command())
 
-	idpGroupEditCmd := cmdIdentityProviderGroupEdit{global: c.global}
-	cmd.AddCommand(idpGroupEditCmd.command())
+	ruleDeleteCmd := cmdIdentityProviderGroupRuleDelete{global: c.global}
+	cmd.AddCommand(ruleDeleteCmd.command())
 
-	idpGroupShowCmd := cmdIdentityProviderGroupShow{global: c.global}
-	cmd.AddCommand(idpGroupShowCmd.command())
+	ruleEditCmd := cmdIdentityProviderGroupRuleEdit{global: c.global}
+	cmd.AddCommand(ruleEditCmd.command())
 
-	idpGroupListCmd := cmdIdentityProviderGroupList{global: c.global}
-	cmd.AddCommand(idpGroupListCmd.command())
+	ruleShowCmd := cmdIdentityProviderGroupRuleShow{global: c.global}
+	cmd.AddCommand(ruleShowCmd.command())
 
-	idpGroupRenameCmd := cmdIdentityProviderGroupRename{global: c.global}
-	cmd.AddCommand(idpGroupRenameCmd.command())
+	ruleListCmd := cmdIdentityProviderGroupRuleList{global: c.global}
+	cmd.AddCommand(ruleListCmd.command())
 
-	idpGroupGroupCmd := cmdIdentityProviderGroupGroup{global: c.global}
-	cmd.AddCommand(idpGroupGroupCmd.command())
+	ruleRenameCmd := cmdIdentityProviderGroupRuleRename{global: c.global}
+	cmd.AddCommand(ruleRenameCmd.command())
 
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
@@ -1461,24 +3057,34 @@ func (c *cmdIdentityProviderGroup) command() *cobra.Command {
 	return cmd
 }
 
-type cmdIdentityProviderGroupCreate struct {
-	global *cmdGlobal
+type cmdIdentityProviderGroupRuleCreate struct {
+	global          *cmdGlobal
+	flagDescription string
+	flagGroups      []string
 }
 
-func (c *cmdIdentityProviderGroupCreate) command() *cobra.Command {
+func (c *cmdIdentityProviderGroupRuleCreate) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("create", i18n.G("[<remote>:]<group>"))
-	cmd.Short = i18n.G("Create identity provider groups")
+	cmd.Use = usage("create", i18n.G("[<remote>:]<rule> <claim>=<pattern>..."))
+	cmd.Short = i18n.G("Create identity provider group rules")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Create identity provider groups`))
+		`Create identity provider group rules
+
+Each <claim>=<pattern> argument adds a condition on a claim of the token. Patterns may contain the
+"*" and "?" wildcards. If the claim is a list, the condition is met if any of its values matches.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth identity-provider-group-rule create engineering groups=team-* email=*@example.com --group developers
+   Map clients in any "team-*" identity provider group with an example.com email address to the developers group`))
+	cmd.Flags().StringVarP(&c.flagDescription, "description", "d", "", i18n.G("Rule description")+"``")
+	cmd.Flags().StringSliceVarP(&c.flagGroups, "group", "g", nil, i18n.G("Group that the rule maps to")+"``")
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityProviderGroupCreate) run(cmd *cobra.Command, args []string) error {
+func (c *cmdIdentityProviderGroupRuleCreate) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
 	if exit {
 		return err
 	}
@@ -1492,44 +3098,56 @@ func (c *cmdIdentityProviderGroupCreate) run(cmd *cobra.Command, args []string)
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity provider group name"))
+		return fmt.Errorf(i18n.G("Missing identity provider group rule name"))
 	}
 
-	// Create the identity provider group
-	group := api.IdentityProviderGroup{}
-	group.Name = resource.name
+	rule := api.IdentityProviderGroupRulesPost{}
+	rule.Name = resource.name
+	rule.Description = c.flagDescription
+	rule.Groups = c.flagGroups
 
-	err = resource.server.CreateIdentityProviderGroup(group)
+	for _, arg := range args[1:] {
+		claim, pattern, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf(i18n.G("Bad condition %q, expected <claim>=<pattern>"), arg)
+		}
+
+		rule.Conditions = append(rule.Conditions, api.IdentityProviderGroupRuleCondition{
+			Claim:   claim,
+			Pattern: pattern,
+		})
+	}
+
+	err = resource.server.CreateIdentityProviderGroupRule(rule)
 	if err != nil {
 		return err
 	}
 
 	if !c.global.flagQuiet {
-		fmt.Printf(i18n.G("Identity provider group %s created")+"\n", resource.name)
+		fmt.Printf(i18n.G("Identity provider group rule %s created")+"\n", resource.name)
 	}
 
 	return nil
 }
 
-// Delete.
-type cmdIdentityProviderGroupDelete struct {
+type cmdIdentityProviderGroupRuleDelete struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityProviderGroupDelete) command() *cobra.Command {
+func (c *cmdIdentityProviderGroupRuleDelete) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("delete", i18n.G("[<remote>:]<identity_provider_group>"))
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<rule>"))
 	cmd.Aliases = []string{"rm"}
-	cmd.Short = i18n.G("Delete identity provider groups")
+	cmd.Short = i18n.G("Delete identity provider group rules")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Delete identity provider groups`))
+		`Delete identity provider group rules`))
 
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityProviderGroupDelete) run(cmd *cobra.Command, args []string) error {
+func (c *cmdIdentityProviderGroupRuleDelete) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
 	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
 	if exit {
@@ -1545,57 +3163,60 @@ func (c *cmdIdentityProviderGroupDelete) run(cmd *cobra.Command, args []string)
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity provider group name"))
+		return fmt.Errorf(i18n.G("Missing identity provider group rule name"))
 	}
 
-	// Delete the identity provider group
-	err = resource.server.DeleteIdentityProviderGroup(resource.name)
+	err = resource.server.DeleteIdentityProviderGroupRule(resource.name)
 	if err != nil {
 		return err
 	}
 
 	if !c.global.flagQuiet {
-		fmt.Printf(i18n.G("Identity provider group %s deleted")+"\n", resource.name)
+		fmt.Printf(i18n.G("Identity provider group rule %s deleted")+"\n", resource.name)
 	}
 
 	return nil
 }
 
-// Edit.
-type cmdIdentityProviderGroupEdit struct {
+type cmdIdentityProviderGroupRuleEdit struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityProviderGroupEdit) command() *cobra.Command {
+func (c *cmdIdentityProviderGroupRuleEdit) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("edit", i18n.G("[<remote>:]<identity_provider_group>"))
-	cmd.Short = i18n.G("Edit identity provider groups as YAML")
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<rule>"))
+	cmd.Short = i18n.G("Edit identity provider group rules as YAML")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Edit identity provider groups as YAML`))
+		`Edit identity provider group rules as YAML`))
 	cmd.Example = cli.FormatSection("", i18n.G(
-		`lxc auth identity-provider-group edit <identity_provider_group> < identity-provider-group.yaml
-   Update an identity provider group using the content of identity-provider-group.yaml`))
+		`lxc auth identity-provider-group-rule edit <rule> < rule.yaml
+   Update an identity provider group rule using the content of rule.yaml`))
 
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityProviderGroupEdit) helpTemplate() string {
+func (c *cmdIdentityProviderGroupRuleEdit) helpTemplate() string {
 	return i18n.G(
-		`### This is a YAML representation of the identity provider group.
+		`### This is a YAML representation of the identity provider group rule.
 ### Any line starting with a '# will be ignored.
 ###
-### An identity provider group has the following format:
-### name: operations
+### An identity provider group rule has the following format:
+### name: engineering
+### description: Engineering teams.
+### conditions:
+### - claim: groups
+###   pattern: team-*
+### - claim: email
+###   pattern: '*@example.com'
 ### groups:
-### - foo
-### - bar
+### - developers
 ###
 ### Note that the name is shown but cannot be modified`)
 }
 
-func (c *cmdIdentityProviderGroupEdit) run(cmd *cobra.Command, args []string) error {
+func (c *cmdIdentityProviderGroupRuleEdit) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
 	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
 	if exit {
@@ -1611,7 +3232,7 @@ func (c *cmdIdentityProviderGroupEdit) run(cmd *cobra.Command, args []string) er
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity provider group name"))
+		return fmt.Errorf(i18n.G("Missing identity provider group rule name"))
 	}
 
 	// If stdin isn't a terminal, read text from it
@@ -1621,22 +3242,22 @@ func (c *cmdIdentityProviderGroupEdit) run(cmd *cobra.Command, args []string) er
 			return err
 		}
 
-		newdata := api.IdentityProviderGroupPut{}
+		newdata := api.IdentityProviderGroupRulePut{}
 		err = yaml.Unmarshal(contents, &newdata)
 		if err != nil {
 			return err
 		}
 
-		return resource.server.UpdateIdentityProviderGroup(resource.name, newdata, "")
+		return resource.server.UpdateIdentityProviderGroupRule(resource.name, newdata, "")
 	}
 
 	// Extract the current value
-	group, etag, err := resource.server.GetIdentityProviderGroup(resource.name)
+	rule, etag, err := resource.server.GetIdentityProviderGroupRule(resource.name)
 	if err != nil {
 		return err
 	}
 
-	data, err := yaml.Marshal(&group)
+	data, err := yaml.Marshal(&rule)
 	if err != nil {
 		return err
 	}
@@ -1649,15 +3270,15 @@ func (c *cmdIdentityProviderGroupEdit) run(cmd *cobra.Command, args []string) er
 
 	for {
 		// Parse the text received from the editor
-		newdata := api.IdentityProviderGroupPut{}
+		newdata := api.IdentityProviderGroupRulePut{}
 		err = yaml.Unmarshal(content, &newdata)
 		if err == nil {
-			err = resource.server.UpdateIdentityProviderGroup(resource.name, newdata, etag)
+			err = resource.server.UpdateIdentityProviderGroupRule(resource.name, newdata, etag)
 		}
 
 		// Respawn the editor
 		if err != nil {
-			fmt.Fprintf(os.Stderr, i18n.G("Could not parse group: %s")+"\n", err)
+			fmt.Fprintf(os.Stderr, i18n.G("Could not parse identity provider group rule: %s")+"\n", err)
 			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
 
 			_, err := os.Stdin.Read(make([]byte, 1))
@@ -1679,18 +3300,18 @@ func (c *cmdIdentityProviderGroupEdit) run(cmd *cobra.Command, args []string) er
 	return nil
 }
 
-type cmdIdentityProviderGroupList struct {
+type cmdIdentityProviderGroupRuleList struct {
 	global     *cmdGlobal
 	flagFormat string
 }
 
-func (c *cmdIdentityProviderGroupList) command() *cobra.Command {
+func (c *cmdIdentityProviderGroupRuleList) command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Use = usage("list", i18n.G("[<remote>:]"))
 	cmd.Aliases = []string{"ls"}
-	cmd.Short = i18n.G("List identity provider groups")
+	cmd.Short = i18n.G("List identity provider group rules")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`List identity provider groups`))
+		`List identity provider group rules`))
 
 	cmd.RunE = c.run
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
@@ -1698,7 +3319,7 @@ func (c *cmdIdentityProviderGroupList) command() *cobra.Command {
 	return cmd
 }
 
-func (c *cmdIdentityProviderGroupList) run(cmd *cobra.Command, args []string) error {
+func (c *cmdIdentityProviderGroupRuleList) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
 	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
 	if exit {
@@ -1718,46 +3339,51 @@ func (c *cmdIdentityProviderGroupList) run(cmd *cobra.Command, args []string) er
 
 	resource := resources[0]
 
-	// List identity provider groups
-	groups, err := resource.server.GetIdentityProviderGroups()
+	rules, err := resource.server.GetIdentityProviderGroupRules()
 	if err != nil {
 		return err
 	}
 
 	data := [][]string{}
-	for _, group := range groups {
-		data = append(data, []string{group.Name, strings.Join(group.Groups, "\n")})
+	for _, rule := range rules {
+		conditions := make([]string, 0, len(rule.Conditions))
+		for _, condition := range rule.Conditions {
+			conditions = append(conditions, condition.Claim+"="+condition.Pattern)
+		}
+
+		data = append(data, []string{rule.Name, strings.Join(conditions, "\n"), strings.Join(rule.Groups, "\n"), rule.Description})
 	}
 
 	sort.Sort(cli.SortColumnsNaturally(data))
 
 	header := []string{
 		i18n.G("NAME"),
+		i18n.G("CONDITIONS"),
 		i18n.G("GROUPS"),
+		i18n.G("DESCRIPTION"),
 	}
 
-	return cli.RenderTable(c.flagFormat, header, data, groups)
+	return cli.RenderTable(c.flagFormat, header, data, rules)
 }
 
-// Rename.
-type cmdIdentityProviderGroupRename struct {
+type cmdIdentityProviderGroupRuleRename struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityProviderGroupRename) command() *cobra.Command {
+func (c *cmdIdentityProviderGroupRuleRename) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("rename", i18n.G("[<remote>:]<identity_provider_group> <new_name>"))
+	cmd.Use = usage("rename", i18n.G("[<remote>:]<rule> <new_name>"))
 	cmd.Aliases = []string{"mv"}
-	cmd.Short = i18n.G("Rename identity provider groups")
+	cmd.Short = i18n.G("Rename identity provider group rules")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Rename identity provider groups`))
+		`Rename identity provider group rules`))
 
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityProviderGroupRename) run(cmd *cobra.Command, args []string) error {
+func (c *cmdIdentityProviderGroupRuleRename) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
 	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
 	if exit {
@@ -1773,40 +3399,38 @@ func (c *cmdIdentityProviderGroupRename) run(cmd *cobra.Command, args []string)
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity provider group name"))
+		return fmt.Errorf(i18n.G("Missing identity provider group rule name"))
 	}
 
-	// Rename the group
-	err = resource.server.RenameIdentityProviderGroup(resource.name, api.IdentityProviderGroupPost{Name: args[1]})
+	err = resource.server.RenameIdentityProviderGroupRule(resource.name, api.IdentityProviderGroupRulePost{Name: args[1]})
 	if err != nil {
 		return err
 	}
 
 	if !c.global.flagQuiet {
-		fmt.Printf(i18n.G("Group %s renamed to %s")+"\n", resource.name, args[1])
+		fmt.Printf(i18n.G("Identity provider group rule %s renamed to %s")+"\n", resource.name, args[1])
 	}
 
 	return nil
 }
 
-// Show.
-type cmdIdentityProviderGroupShow struct {
+type cmdIdentityProviderGroupRuleShow struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityProviderGroupShow) command() *cobra.Command {
+func (c *cmdIdentityProviderGroupRuleShow) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("show", i18n.G("[<remote>:]<identity_provider_group>"))
-	cmd.Short = i18n.G("Show an identity provider group")
+	cmd.Use = usage("show", i18n.G("[<remote>:]<rule>"))
+	cmd.Short = i18n.G("Show identity provider group rules")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Show an identity provider group`))
+		`Show identity provider group rules`))
 
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityProviderGroupShow) run(cmd *cobra.Command, args []string) error {
+func (c *cmdIdentityProviderGroupRuleShow) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
 	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
 	if exit {
@@ -1822,16 +3446,15 @@ func (c *cmdIdentityProviderGroupShow) run(cmd *cobra.Command, args []string) er
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing group name"))
+		return fmt.Errorf(i18n.G("Missing identity provider group rule name"))
 	}
 
-	// Show the group
-	group, _, err := resource.server.GetIdentityProviderGroup(resource.name)
+	rule, _, err := resource.server.GetIdentityProviderGroupRule(resource.name)
 	if err != nil {
 		return err
 	}
 
-	data, err := yaml.Marshal(&group)
+	data, err := yaml.Marshal(&rule)
 	if err != nil {
 		return err
 	}
@@ -1841,22 +3464,19 @@ func (c *cmdIdentityProviderGroupShow) run(cmd *cobra.Command, args []string) er
 	return nil
 }
 
-type cmdIdentityProviderGroupGroup struct {
+type cmdAccessToken struct {
 	global *cmdGlobal
 }
 
-func (c *cmdIdentityProviderGroupGroup) command() *cobra.Command {
+func (c *cmdAccessToken) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("group")
-	cmd.Short = i18n.G("Manage identity provider group mappings")
+	cmd.Use = usage("access-token")
+	cmd.Short = i18n.G("Manage temporary instance access tokens")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Manage identity provider group mappings`))
-
-	identityProviderGroupGroupAddCmd := cmdIdentityProviderGroupGroupAdd{global: c.global}
-	cmd.AddCommand(identityProviderGroupGroupAddCmd.command())
+		`Manage temporary instance access tokens`))
 
-	identityProviderGroupGroupRemoveCmd := cmdIdentityProviderGroupGroupRemove{global: c.global}
-	cmd.AddCommand(identityProviderGroupGroupRemoveCmd.command())
+	accessTokenIssueCmd := cmdAccessTokenIssue{global: c.global}
+	cmd.AddCommand(accessTokenIssueCmd.command())
 
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
@@ -1864,76 +3484,36 @@ func (c *cmdIdentityProviderGroupGroup) command() *cobra.Command {
 	return cmd
 }
 
-type cmdIdentityProviderGroupGroupAdd struct {
+type cmdAccessTokenIssue struct {
 	global *cmdGlobal
-}
-
-func (c *cmdIdentityProviderGroupGroupAdd) command() *cobra.Command {
-	cmd := &cobra.Command{}
-	cmd.Use = usage("add", i18n.G("[<remote>:]<identity_provider_group> <group>"))
-	cmd.Short = i18n.G("Add a group to an identity provider group")
-	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Add a group to an identity provider group`))
-
-	cmd.RunE = c.run
-
-	return cmd
-}
-
-func (c *cmdIdentityProviderGroupGroupAdd) run(cmd *cobra.Command, args []string) error {
-	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
-	if exit {
-		return err
-	}
 
-	// Parse remote
-	resources, err := c.global.ParseServers(args[0])
-	if err != nil {
-		return err
-	}
-
-	resource := resources[0]
-
-	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity provider group name argument"))
-	}
-
-	idpGroup, eTag, err := resource.server.GetIdentityProviderGroup(resource.name)
-	if err != nil {
-		return err
-	}
-
-	added := false
-	if !shared.ValueInSlice(args[1], idpGroup.Groups) {
-		idpGroup.Groups = append(idpGroup.Groups, args[1])
-		added = true
-	}
-
-	if !added {
-		return fmt.Errorf("Identity group %q is already mapped to group %q", resource.name, args[1])
-	}
-
-	return resource.server.UpdateIdentityProviderGroup(resource.name, idpGroup.Writable(), eTag)
-}
-
-type cmdIdentityProviderGroupGroupRemove struct {
-	global *cmdGlobal
+	flagReadOnly bool
+	flagExpiry   string
 }
 
-func (c *cmdIdentityProviderGroupGroupRemove) command() *cobra.Command {
+func (c *cmdAccessTokenIssue) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("remove", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <group>"))
-	cmd.Short = i18n.G("Remove identities from groups")
+	cmd.Use = usage("issue", i18n.G("[<remote>:]<instance> exec|console"))
+	cmd.Short = i18n.G("Issue a temporary access token for an instance")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Remove identities from groups`))
+		`Issue a temporary access token for an instance
 
+The token grants exec or console access to the instance only, until it expires.
+It can't be revoked, so keep its expiry short. Use it as a bearer token:
+  lxc remote add support https://example.com:8443 --auth-type=bearer --token=<token>
+`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth access-token issue c1 console --read-only --expiry 30m
+    Issue a token allowing to watch the console of c1 for 30 minutes.`))
+
+	cmd.Flags().BoolVar(&c.flagReadOnly, "read-only", false, i18n.G("Only allow watching the console"))
+	cmd.Flags().StringVar(&c.flagExpiry, "expiry", "", i18n.G("Validity of the token (defaults to 1h)")+"``")
 	cmd.RunE = c.run
 
 	return cmd
 }
 
-func (c *cmdIdentityProviderGroupGroupRemove) run(cmd *cobra.Command, args []string) error {
+func (c *cmdAccessTokenIssue) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
 	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
 	if exit {
@@ -1949,33 +3529,20 @@ func (c *cmdIdentityProviderGroupGroupRemove) run(cmd *cobra.Command, args []str
 	resource := resources[0]
 
 	if resource.name == "" {
-		return fmt.Errorf(i18n.G("Missing identity provider group name argument"))
+		return fmt.Errorf(i18n.G("Missing instance name"))
 	}
 
-	idpGroup, eTag, err := resource.server.GetIdentityProviderGroup(resource.name)
+	// Issue the token
+	token, err := resource.server.CreateInstanceAccessToken(resource.name, api.InstanceAccessTokensPost{
+		Access:   args[1],
+		ReadOnly: c.flagReadOnly,
+		Expiry:   c.flagExpiry,
+	})
 	if err != nil {
 		return err
 	}
 
-	if len(idpGroup.Groups) == 0 {
-		return fmt.Errorf("Identity provider group %q is not mapped to any groups", resource.name)
-	}
-
-	groups := make([]string, 0, len(idpGroup.Groups)-1)
-	removed := false
-	for _, existingGroup := range idpGroup.Groups {
-		if args[1] == existingGroup {
-			removed = true
-			continue
-		}
-
-		groups = append(groups, existingGroup)
-	}
-
-	if !removed {
-		return fmt.Errorf("Identity provider group %q is not mapped to group %q", resource.name, args[1])
-	}
+	fmt.Println(token.Token)
 
-	idpGroup.Groups = groups
-	return resource.server.UpdateIdentityProviderGroup(resource.name, idpGroup.Writable(), eTag)
+	return nil
 }
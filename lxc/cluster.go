@@ -49,6 +49,10 @@ func (c *cmdCluster) command() *cobra.Command {
 	clusterInfoCmd := cmdClusterInfo{global: c.global, cluster: c}
 	cmd.AddCommand(clusterInfoCmd.command())
 
+	// Inventory
+	clusterInventoryCmd := cmdClusterInventory{global: c.global, cluster: c}
+	cmd.AddCommand(clusterInventoryCmd.command())
+
 	// Get
 	clusterGetCmd := cmdClusterGet{global: c.global, cluster: c}
 	cmd.AddCommand(clusterGetCmd.command())
@@ -93,6 +97,10 @@ func (c *cmdCluster) command() *cobra.Command {
 	cmdClusterRestore := cmdClusterRestore{global: c.global, cluster: c}
 	cmd.AddCommand(cmdClusterRestore.command())
 
+	// Upgrade cluster member
+	cmdClusterUpgrade := cmdClusterUpgrade{global: c.global, cluster: c}
+	cmd.AddCommand(cmdClusterUpgrade.command())
+
 	clusterGroupCmd := cmdClusterGroup{global: c.global, cluster: c}
 	cmd.AddCommand(clusterGroupCmd.command())
 
@@ -246,14 +254,30 @@ func (c *cmdClusterShow) run(cmd *cobra.Command, args []string) error {
 type cmdClusterInfo struct {
 	global  *cmdGlobal
 	cluster *cmdCluster
+
+	flagFormat string
+	flagDrift  bool
 }
 
 func (c *cmdClusterInfo) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("info", i18n.G("[<remote>:]<member>"))
+	cmd.Use = usage("info", i18n.G("[<remote>:][<member>]"))
 	cmd.Short = i18n.G("Show useful information about a cluster member")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Show useful information about a cluster member`))
+		`Show useful information about a cluster member
+
+Without a member, the health of the cluster is shown. This includes the database role,
+last heartbeat and version of each member, as seen by the member answering the request.
+
+With --drift, the settings which differ between the cluster members are shown instead.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc cluster info
+    Show the database role, last heartbeat and version of each cluster member.
+
+lxc cluster info --drift
+    Show the settings which differ between the cluster members.`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
+	cmd.Flags().BoolVar(&c.flagDrift, "drift", false, i18n.G("Show the settings which differ between cluster members"))
 
 	cmd.RunE = c.run
 
@@ -261,34 +285,227 @@ func (c *cmdClusterInfo) command() *cobra.Command {
 }
 
 func (c *cmdClusterInfo) run(cmd *cobra.Command, args []string) error {
+	if c.flagDrift {
+		return c.runDrift(cmd, args)
+	}
+
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
 	if exit {
 		return err
 	}
 
 	// Parse remote.
-	resources, err := c.global.ParseServers(args[0])
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
 	if err != nil {
 		return err
 	}
 
 	resource := resources[0]
 
+	if resource.name == "" {
+		return c.runState(resource)
+	}
+
 	// Get the member state information.
 	member, _, err := resource.server.GetClusterMemberState(resource.name)
 	if err != nil {
 		return err
 	}
 
-	// Render as YAML.
-	data, err := yaml.Marshal(&member)
+	return cli.RenderInfo(c.flagFormat, member, func() error {
+		// Render as YAML.
+		data, err := yaml.Marshal(&member)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s", data)
+		return nil
+	})
+}
+
+// runState shows the health of the cluster database and of its members.
+func (c *cmdClusterInfo) runState(resource remoteResource) error {
+	clusterState, err := resource.server.GetClusterState()
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("%s", data)
-	return nil
+	const layout = "2006/01/02 15:04:05 MST"
+
+	// Render the table.
+	data := [][]string{}
+	for _, member := range clusterState.Members {
+		leader := "NO"
+		if member.DatabaseLeader {
+			leader = "YES"
+		}
+
+		pendingUpgrade := "NO"
+		if member.PendingUpgrade {
+			pendingUpgrade = "YES"
+		}
+
+		lastHeartbeat := ""
+		if !member.LastHeartbeat.IsZero() {
+			lastHeartbeat = member.LastHeartbeat.Local().Format(layout)
+		}
+
+		data = append(data, []string{
+			member.ServerName,
+			member.Status,
+			member.DatabaseRole,
+			leader,
+			lastHeartbeat,
+			fmt.Sprintf("%d", member.SchemaVersion),
+			fmt.Sprintf("%d", member.APIExtensions),
+			pendingUpgrade,
+		})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("STATUS"),
+		i18n.G("DATABASE ROLE"),
+		i18n.G("LEADER"),
+		i18n.G("LAST HEARTBEAT"),
+		i18n.G("SCHEMA"),
+		i18n.G("API EXTENSIONS"),
+		i18n.G("PENDING UPGRADE"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, clusterState)
+}
+
+// runDrift shows the settings which differ between the cluster members.
+func (c *cmdClusterInfo) runDrift(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// Get the drift.
+	drift, err := resource.server.GetClusterDrift()
+	if err != nil {
+		return err
+	}
+
+	// Render the table.
+	data := [][]string{}
+	for _, setting := range drift {
+		members := make([]string, 0, len(setting.Values))
+		for member := range setting.Values {
+			members = append(members, member)
+		}
+
+		sort.Strings(members)
+
+		for _, member := range members {
+			data = append(data, []string{setting.Setting, member, setting.Values[member]})
+		}
+	}
+
+	header := []string{
+		i18n.G("SETTING"),
+		i18n.G("MEMBER"),
+		i18n.G("VALUE"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, drift)
+}
+
+// Inventory.
+type cmdClusterInventory struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+
+	flagFormat string
+}
+
+func (c *cmdClusterInventory) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("inventory", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Export the inventory of the cluster")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Export the inventory of the cluster
+
+The inventory lists the cluster members, projects, instances, storage volumes, networks and images
+of the cluster along with their configuration. Use the json or yaml format to get all the details.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc cluster inventory --format=json > inventory.json
+    Save the inventory of the cluster in JSON format.`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdClusterInventory) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// Get the inventory.
+	entries, err := resource.server.GetClusterInventory()
+	if err != nil {
+		return err
+	}
+
+	// Render the table.
+	data := [][]string{}
+	for _, entry := range entries {
+		line := []string{entry.EntityType, entry.Project, entry.Location, entry.Name, entry.Description, strings.ToUpper(entry.Status)}
+		data = append(data, line)
+	}
+
+	header := []string{
+		i18n.G("TYPE"),
+		i18n.G("PROJECT"),
+		i18n.G("LOCATION"),
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("STATE"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, entries)
 }
 
 // Get.
@@ -794,15 +1011,36 @@ type cmdClusterAdd struct {
 	global  *cmdGlobal
 	cluster *cmdCluster
 
-	flagName string
+	flagName    string
+	flagPreseed bool
+	flagAddress string
+	flagURL     bool
 }
 
 func (c *cmdClusterAdd) command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Use = usage("add", i18n.G("[[<remote>:]<name>]"))
 	cmd.Short = i18n.G("Request a join token for adding a cluster member")
-	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Request a join token for adding a cluster member`))
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Request a join token for adding a cluster member
+
+With --preseed, a complete "lxd init" preseed including the join token is printed instead.
+The preseed contains the member specific configuration keys of the storage pools and networks
+of the cluster, with values suggested from the configuration and hardware of the cluster member
+answering the request. Review and adjust those values before using the preseed.
+
+With --url, the join token is printed as a "lxd://join" URL which can be shared as a link
+or turned into a QR code.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc cluster add lxd02 --preseed --address 10.0.0.2 > lxd02.yaml
+    Generate a preseed for joining the member lxd02 with address 10.0.0.2.
+    Then run "lxd init --preseed < lxd02.yaml" on the new member.
+
+lxc cluster add lxd02 --url --quiet | qrencode -t ansiutf8
+    Show the join token for the member lxd02 as a QR code.`))
 	cmd.Flags().StringVar(&c.flagName, "name", "", i18n.G("Cluster member name (alternative to passing it as an argument)")+"``")
+	cmd.Flags().BoolVar(&c.flagPreseed, "preseed", false, i18n.G("Print a join preseed instead of the join token"))
+	cmd.Flags().StringVar(&c.flagAddress, "address", "", i18n.G("Address of the new member (used with --preseed)")+"``")
+	cmd.Flags().BoolVar(&c.flagURL, "url", false, i18n.G("Print the join token as a URL"))
 
 	cmd.RunE = c.run
 
@@ -824,6 +1062,10 @@ func (c *cmdClusterAdd) run(cmd *cobra.Command, args []string) error {
 
 	resource := resources[0]
 
+	if c.flagPreseed && c.flagURL {
+		return fmt.Errorf(i18n.G("The --preseed and --url flags can't be used together"))
+	}
+
 	// Determine the machine name.
 	if resource.name != "" && c.flagName != "" && resource.name != c.flagName {
 		return fmt.Errorf(i18n.G("Cluster member name was provided as both a flag and as an argument"))
@@ -840,6 +1082,15 @@ func (c *cmdClusterAdd) run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Get the join preseed before issuing a token so that no token is left behind on failure.
+	var preseed *api.InitPreseed
+	if c.flagPreseed {
+		preseed, err = resource.server.GetClusterJoinPreseed(resource.name, c.flagAddress)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Request the join token.
 	member := api.ClusterMembersPost{
 		ServerName: resource.name,
@@ -856,11 +1107,28 @@ func (c *cmdClusterAdd) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("Failed converting token operation to join token: %w", err)
 	}
 
+	if preseed != nil {
+		preseed.Cluster.ClusterToken = joinToken.String()
+
+		data, err := yaml.Marshal(preseed)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s", data)
+
+		return nil
+	}
+
 	if !c.global.flagQuiet {
 		fmt.Printf(i18n.G("Member %s join token:")+"\n", resource.name)
 	}
 
-	fmt.Println(joinToken.String())
+	if c.flagURL {
+		fmt.Println(joinToken.URL())
+	} else {
+		fmt.Println(joinToken.String())
+	}
 
 	return nil
 }
@@ -1194,6 +1462,33 @@ func (c *cmdClusterRestore) command() *cobra.Command {
 	return cmd
 }
 
+// Cluster member upgrade.
+type cmdClusterUpgrade struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+	action  *cmdClusterEvacuateAction
+}
+
+func (c *cmdClusterUpgrade) command() *cobra.Command {
+	cmdAction := cmdClusterEvacuateAction{global: c.global}
+	c.action = &cmdAction
+
+	cmd := c.action.command("upgrade")
+	cmd.Use = usage("upgrade", i18n.G("[<remote>:]<member>"))
+	cmd.Short = i18n.G("Upgrade cluster member")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Upgrade cluster member
+
+The cluster member is evacuated and then upgraded using the cluster update
+executable configured on the server (LXD_CLUSTER_UPDATE).
+Once it has been upgraded and all other cluster members run the same version,
+the member is automatically restored.`))
+
+	cmd.Flags().BoolVar(&c.action.flagForce, "force", false, i18n.G(`Force upgrade without user confirmation`)+"``")
+	cmd.Flags().StringVar(&c.action.flagAction, "action", "", i18n.G(`Force a particular evacuation action`)+"``")
+
+	return cmd
+}
+
 func (c *cmdClusterEvacuateAction) command(action string) *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.RunE = c.run
@@ -1245,6 +1540,8 @@ func (c *cmdClusterEvacuateAction) run(cmd *cobra.Command, args []string) error
 
 	if cmd.Name() == "restore" {
 		format = i18n.G("Restoring cluster member: %s")
+	} else if cmd.Name() == "upgrade" {
+		format = i18n.G("Upgrading cluster member: %s")
 	} else {
 		format = i18n.G("Evacuating cluster member: %s")
 	}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -36,6 +38,10 @@ func (c *cmdConfig) command() *cobra.Command {
 	configDeviceCmd := cmdConfigDevice{global: c.global, config: c}
 	cmd.AddCommand(configDeviceCmd.command())
 
+	// Diff
+	configDiffCmd := cmdConfigDiff{global: c.global, config: c}
+	cmd.AddCommand(configDiffCmd.command())
+
 	// Edit
 	configEditCmd := cmdConfigEdit{global: c.global, config: c}
 	cmd.AddCommand(configEditCmd.command())
@@ -89,6 +95,9 @@ func (c *cmdConfig) command() *cobra.Command {
 type cmdConfigEdit struct {
 	global *cmdGlobal
 	config *cmdConfig
+
+	flagBatch  string
+	flagDryRun bool
 }
 
 // Command creates a Cobra command to edit instance or server configurations using YAML, with optional flags for targeting cluster members.
@@ -100,9 +109,17 @@ func (c *cmdConfigEdit) command() *cobra.Command {
 		`Edit instance or server configurations as YAML`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`lxc config edit <instance> < instance.yaml
-    Update the instance configuration from config.yaml.`))
+    Update the instance configuration from config.yaml.
+
+lxc config edit --batch "web-*"
+    Edit the configuration of all instances whose name starts with "web-" in a single document.
+
+lxc config edit <instance> --dry-run < instance.yaml
+    Show the changes that updating the instance configuration from instance.yaml would make.`))
 
 	cmd.Flags().StringVar(&c.config.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVar(&c.flagBatch, "batch", "", i18n.G("Edit all instances whose name matches the pattern")+"``")
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the changes that would be made"))
 	cmd.RunE = c.run
 
 	return cmd
@@ -138,6 +155,14 @@ func (c *cmdConfigEdit) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.flagBatch != "" {
+		if c.flagDryRun {
+			return fmt.Errorf(i18n.G("--dry-run cannot be used with --batch"))
+		}
+
+		return c.runBatch(args)
+	}
+
 	// Parse remote
 	remote := ""
 	if len(args) > 0 {
@@ -154,6 +179,10 @@ func (c *cmdConfigEdit) run(cmd *cobra.Command, args []string) error {
 	fields := strings.SplitN(resource.name, "/", 2)
 	isSnapshot := len(fields) == 2
 
+	if c.flagDryRun && (resource.name == "" || isSnapshot) {
+		return fmt.Errorf(i18n.G("--dry-run can only be used with instances"))
+	}
+
 	// Edit the config
 	if resource.name != "" {
 		// Quick checks.
@@ -189,6 +218,16 @@ func (c *cmdConfigEdit) run(cmd *cobra.Command, args []string) error {
 					return err
 				}
 
+				if c.flagDryRun {
+					diff, err := resource.server.UpdateInstanceDryRun(resource.name, newdata, "")
+					if err != nil {
+						return err
+					}
+
+					printConfigDiff(diff)
+					return nil
+				}
+
 				op, err = resource.server.UpdateInstance(resource.name, newdata, "")
 				if err != nil {
 					return err
@@ -242,6 +281,7 @@ func (c *cmdConfigEdit) run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		var diff *api.ConfigDiff
 		for {
 			// Parse the text received from the editor
 			if isSnapshot {
@@ -258,7 +298,9 @@ func (c *cmdConfigEdit) run(cmd *cobra.Command, args []string) error {
 			} else {
 				newdata := api.InstancePut{}
 				err = yaml.Unmarshal(content, &newdata)
-				if err == nil {
+				if err == nil && c.flagDryRun {
+					diff, err = resource.server.UpdateInstanceDryRun(resource.name, newdata, etag)
+				} else if err == nil {
 					var op lxd.Operation
 					op, err = resource.server.UpdateInstance(resource.name, newdata, etag)
 					if err == nil {
@@ -288,6 +330,10 @@ func (c *cmdConfigEdit) run(cmd *cobra.Command, args []string) error {
 			break
 		}
 
+		if diff != nil {
+			printConfigDiff(diff)
+		}
+
 		return nil
 	}
 
@@ -366,6 +412,250 @@ func (c *cmdConfigEdit) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// batchHelpTemplate returns guidelines for editing the configuration of multiple instances.
+func (c *cmdConfigEdit) batchHelpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the configuration of multiple instances.
+### Any line starting with a '# will be ignored.
+###
+### Each top level key is the name of an instance, for example:
+### instance1:
+###   profiles:
+###   - default
+###   config:
+###     limits.cpu: "2"
+###   devices: {}
+###   ephemeral: false
+###
+### Only the instances that are changed are updated. Removing an instance from
+### the document leaves it unchanged. Instances cannot be added or renamed.`)
+}
+
+// runBatch edits the configuration of all instances matching the batch pattern as a single YAML document.
+// Each changed instance is updated on its own, so a failure to update one instance doesn't affect the others.
+func (c *cmdConfigEdit) runBatch(args []string) error {
+	if c.config.flagTarget != "" {
+		return fmt.Errorf(i18n.G("--target cannot be used with instances"))
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Instance names cannot be used with --batch"))
+	}
+
+	instances, err := resource.server.GetInstancesWithNamePattern(api.InstanceTypeAny, c.flagBatch)
+	if err != nil {
+		return err
+	}
+
+	if len(instances) == 0 {
+		return fmt.Errorf(i18n.G("No instances match %q"), c.flagBatch)
+	}
+
+	// Extract the current values.
+	current := map[string]api.InstancePut{}
+	etags := map[string]string{}
+	for _, instance := range instances {
+		inst, etag, err := resource.server.GetInstance(instance.Name)
+		if err != nil {
+			return err
+		}
+
+		current[inst.Name] = inst.Writable()
+		etags[inst.Name] = etag
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		newdata, err := c.parseBatch(contents, current)
+		if err != nil {
+			return err
+		}
+
+		return c.applyBatch(resource.server, current, newdata, etags)
+	}
+
+	data, err := yaml.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor
+	content, err := shared.TextEditor("", []byte(c.batchHelpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor
+		newdata, err := c.parseBatch(content, current)
+		if err == nil {
+			return c.applyBatch(resource.server, current, newdata, etags)
+		}
+
+		// Respawn the editor
+		fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+		fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+		_, err = os.Stdin.Read(make([]byte, 1))
+		if err != nil {
+			return err
+		}
+
+		content, err = shared.TextEditor("", content)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseBatch parses a combined YAML document and checks that it only contains the instances being edited.
+func (c *cmdConfigEdit) parseBatch(content []byte, current map[string]api.InstancePut) (map[string]api.InstancePut, error) {
+	newdata := map[string]api.InstancePut{}
+	err := yaml.Unmarshal(content, &newdata)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range newdata {
+		_, ok := current[name]
+		if !ok {
+			return nil, fmt.Errorf(i18n.G("Instance %q isn't part of the edited instances"), name)
+		}
+	}
+
+	return newdata, nil
+}
+
+// applyBatch updates each changed instance using its ETag and reports the result for each of them.
+func (c *cmdConfigEdit) applyBatch(server lxd.InstanceServer, current map[string]api.InstancePut, newdata map[string]api.InstancePut, etags map[string]string) error {
+	names := make([]string, 0, len(newdata))
+	for name := range newdata {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	failed := 0
+	for _, name := range names {
+		if reflect.DeepEqual(current[name], newdata[name]) {
+			continue
+		}
+
+		op, err := server.UpdateInstance(name, newdata[name], etags[name])
+		if err == nil {
+			err = op.Wait()
+		}
+
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, i18n.G("Failed updating instance %q: %v")+"\n", name, err)
+			continue
+		}
+
+		if !c.global.flagQuiet {
+			fmt.Printf(i18n.G("Instance %q updated")+"\n", name)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf(i18n.G("Failed updating %d instance(s)"), failed)
+	}
+
+	return nil
+}
+
+// Diff.
+type cmdConfigDiff struct {
+	global *cmdGlobal
+	config *cmdConfig
+}
+
+// Command creates a Cobra command to show the changes that a YAML configuration would make to an instance.
+func (c *cmdConfigDiff) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("diff", i18n.G("[<remote>:]<instance>"))
+	cmd.Short = i18n.G("Show the changes a YAML configuration would make to an instance")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the changes a YAML configuration would make to an instance
+
+The configuration is read from standard input and validated by the server, without being applied.
+The changes to the expanded configuration of the instance, including the effects of its profiles, are also shown.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc config diff <instance> < instance.yaml
+    Show the changes that updating the instance configuration from instance.yaml would make.`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+// Run reads the configuration from standard input and shows the changes it would make to the instance.
+func (c *cmdConfigDiff) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing instance name"))
+	}
+
+	if shared.IsSnapshot(resource.name) {
+		return fmt.Errorf(i18n.G("Snapshots aren't supported"))
+	}
+
+	if termios.IsTerminal(getStdinFd()) {
+		return fmt.Errorf(i18n.G("The configuration must be provided on standard input"))
+	}
+
+	contents, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	newdata := api.InstancePut{}
+	err = yaml.Unmarshal(contents, &newdata)
+	if err != nil {
+		return err
+	}
+
+	diff, err := resource.server.UpdateInstanceDryRun(resource.name, newdata, "")
+	if err != nil {
+		return err
+	}
+
+	printConfigDiff(diff)
+
+	return nil
+}
+
 // Get.
 type cmdConfigGet struct {
 	global *cmdGlobal
@@ -882,7 +1172,7 @@ type cmdConfigUefi struct {
 }
 
 // Command creates a Cobra command for managing virtual machine instance UEFI variables,
-// including options for get, set, unset, show, edit.
+// including options for get, set, unset, show, edit, export, import and enroll-key.
 func (c *cmdConfigUefi) command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Use = usage("uefi")
@@ -910,6 +1200,18 @@ func (c *cmdConfigUefi) command() *cobra.Command {
 	configUefiEditCmd := cmdConfigUefiEdit{global: c.global, configUefi: c}
 	cmd.AddCommand(configUefiEditCmd.command())
 
+	// Export
+	configUefiExportCmd := cmdConfigUefiExport{global: c.global, configUefi: c}
+	cmd.AddCommand(configUefiExportCmd.command())
+
+	// Import
+	configUefiImportCmd := cmdConfigUefiImport{global: c.global, configUefi: c}
+	cmd.AddCommand(configUefiImportCmd.command())
+
+	// Enroll key
+	configUefiEnrollKeyCmd := cmdConfigUefiEnrollKey{global: c.global, configUefi: c}
+	cmd.AddCommand(configUefiEnrollKeyCmd.command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
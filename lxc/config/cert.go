@@ -31,6 +31,40 @@ func (c *Config) GenerateClientCertificate() error {
 	return shared.FindOrGenCert(certf, keyf, true, false)
 }
 
+// ClientCertPath returns the path for the client certificate issued by the remote's certificate authority.
+func (c *Config) ClientCertPath(remote string) string {
+	return c.ConfigPath("clientcerts", fmt.Sprintf("%s.crt", remote))
+}
+
+// ClientKeyPath returns the path for the key of the client certificate issued by the remote's certificate authority.
+func (c *Config) ClientKeyPath(remote string) string {
+	return c.ConfigPath("clientcerts", fmt.Sprintf("%s.key", remote))
+}
+
+// HasRemoteClientCertificate will return true if the remote has its own client certificate.
+func (c *Config) HasRemoteClientCertificate(remote string) bool {
+	return shared.PathExists(c.ClientCertPath(remote)) && shared.PathExists(c.ClientKeyPath(remote))
+}
+
+// SaveClientCertificate saves the client certificate issued by the remote's certificate authority and its key to disk.
+func (c *Config) SaveClientCertificate(remote string, cert []byte, key []byte) error {
+	certParentPath := c.ConfigPath("clientcerts")
+
+	if !shared.PathExists(certParentPath) {
+		err := os.MkdirAll(certParentPath, 0700)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := os.WriteFile(c.ClientKeyPath(remote), key, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.ClientCertPath(remote), cert, 0644)
+}
+
 // CopyGlobalCert will copy global (system-wide) certificate to the user config path.
 func (c *Config) CopyGlobalCert(src string, dst string) error {
 	oldPath := c.GlobalConfigPath("servercerts", fmt.Sprintf("%s.crt", src))
@@ -24,6 +24,12 @@ type Config struct {
 	// Command line aliases for `lxc`
 	Aliases map[string]string `yaml:"aliases"`
 
+	// Named column sets for `lxc list`
+	ListColumns map[string]string `yaml:"list-columns,omitempty"`
+
+	// Team configuration providing additional remotes and aliases
+	TeamConfig *TeamConfig `yaml:"team-config,omitempty"`
+
 	// Configuration directory
 	ConfigDir string `yaml:"-"`
 
@@ -38,6 +44,9 @@ type Config struct {
 
 	// OIDC tokens
 	oidcTokens map[string]*oidc.Tokens[*oidc.IDTokenClaims]
+
+	// Aliases added by the team configuration
+	teamAliases map[string]string
 }
 
 // GlobalConfigPath returns a joined path of the global configuration directory and passed arguments.
@@ -90,6 +99,25 @@ func (c *Config) SaveOIDCTokens() {
 	}
 }
 
+// BearerTokenPath returns the path for the remote's bearer token.
+func (c *Config) BearerTokenPath(remote string) string {
+	return c.ConfigPath("bearertokens", fmt.Sprintf("%s.token", remote))
+}
+
+// SaveBearerToken saves the bearer token of a remote to disk.
+func (c *Config) SaveBearerToken(remote string, token string) error {
+	tokenParentPath := c.ConfigPath("bearertokens")
+
+	if !shared.PathExists(tokenParentPath) {
+		err := os.MkdirAll(tokenParentPath, 0700)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(c.BearerTokenPath(remote), []byte(token), 0600)
+}
+
 // NewConfig returns a Config, optionally using default remotes.
 func NewConfig(configDir string, defaults bool) *Config {
 	var config *Config
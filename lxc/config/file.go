@@ -52,6 +52,11 @@ func LoadConfig(path string) (*Config, error) {
 		}
 	}
 
+	// Apply the team configuration (errors are reported when refreshing it explicitly)
+	if c.TeamConfig != nil {
+		_ = c.applyTeamConfig()
+	}
+
 	// Set default values
 	if c.Remotes == nil {
 		c.Remotes = make(map[string]Remote)
@@ -87,13 +92,20 @@ func (c *Config) SaveConfig(path string) error {
 		return fmt.Errorf("Unable to copy the configuration: %w", err)
 	}
 
-	// Remove the global remotes
+	// Remove the global and team remotes
 	for k, v := range c.Remotes {
-		if v.Global {
+		if v.Global || v.Team {
 			delete(conf.Remotes, k)
 		}
 	}
 
+	// Remove the unmodified team aliases
+	for k, v := range c.teamAliases {
+		if conf.Aliases[k] == v {
+			delete(conf.Aliases, k)
+		}
+	}
+
 	defaultRemote := DefaultConfig().DefaultRemote
 
 	// Remove the static remotes
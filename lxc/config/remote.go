@@ -21,13 +21,15 @@ import (
 
 // Remote holds details for communication with a remote daemon.
 type Remote struct {
-	Addr     string `yaml:"addr"`
-	AuthType string `yaml:"auth_type,omitempty"`
-	Project  string `yaml:"project,omitempty"`
-	Protocol string `yaml:"protocol,omitempty"`
-	Public   bool   `yaml:"public"`
-	Global   bool   `yaml:"-"`
-	Static   bool   `yaml:"-"`
+	Addr     string   `yaml:"addr"`
+	AuthType string   `yaml:"auth_type,omitempty"`
+	Mirrors  []string `yaml:"mirrors,omitempty"`
+	Project  string   `yaml:"project,omitempty"`
+	Protocol string   `yaml:"protocol,omitempty"`
+	Public   bool     `yaml:"public"`
+	Global   bool     `yaml:"-"`
+	Static   bool     `yaml:"-"`
+	Team     bool     `yaml:"-"`
 }
 
 // ParseRemote splits remote and object.
@@ -74,7 +76,7 @@ func (c *Config) getPrivateRemoteByName(name string) (*Remote, error) {
 	}
 
 	// Check the remote is private.
-	if remote.Public || remote.Protocol == "simplestreams" {
+	if remote.Public || shared.ValueInSlice(remote.Protocol, []string{"simplestreams", "oci"}) {
 		return nil, fmt.Errorf("The remote isn't a private LXD server")
 	}
 
@@ -132,7 +134,7 @@ func (c *Config) connectRemote(remote Remote, args *lxd.ConnectionArgs) (lxd.Ins
 	}
 
 	// HTTPS
-	if !shared.ValueInSlice(remote.AuthType, []string{api.AuthenticationMethodOIDC}) && (args.TLSClientCert == "" || args.TLSClientKey == "") {
+	if !shared.ValueInSlice(remote.AuthType, []string{api.AuthenticationMethodOIDC, api.AuthenticationMethodBearer}) && (args.TLSClientCert == "" || args.TLSClientKey == "") {
 		return nil, fmt.Errorf("Missing TLS client certificate and key")
 	}
 
@@ -212,6 +214,16 @@ func (c *Config) GetImageServer(name string) (lxd.ImageServer, error) {
 		return d, nil
 	}
 
+	// HTTPs (OCI registry)
+	if remote.Protocol == "oci" {
+		d, err := lxd.ConnectOCI(remote.Addr, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return d, nil
+	}
+
 	// HTTPs (public LXD)
 	if remote.Public {
 		d, err := lxd.ConnectPublicLXD(remote.Addr, args)
@@ -241,13 +253,14 @@ func (c *Config) GetImageServer(name string) (lxd.ImageServer, error) {
 
 // getConnectionArgs retrieves the connection arguments for the specified remote.
 // It constructs the necessary connection arguments based on the remote's configuration, including authentication type,
-// authentication interactors, cookie jar, OIDC tokens, TLS certificates, and client key.
+// authentication interactors, cookie jar, OIDC tokens, bearer token, TLS certificates, and client key.
 // The function returns the connection arguments or an error if any configuration is missing or encounters a problem.
 func (c *Config) getConnectionArgs(name string) (*lxd.ConnectionArgs, error) {
 	remote := c.Remotes[name]
 	args := lxd.ConnectionArgs{
 		UserAgent: c.UserAgent,
 		AuthType:  remote.AuthType,
+		Mirrors:   remote.Mirrors,
 	}
 
 	if args.AuthType == api.AuthenticationMethodOIDC {
@@ -278,6 +291,19 @@ func (c *Config) getConnectionArgs(name string) (*lxd.ConnectionArgs, error) {
 		}
 
 		args.OIDCTokens = c.oidcTokens[name]
+
+		// Service accounts authenticate non-interactively using the client credentials flow.
+		args.OIDCClientID = os.Getenv("LXD_OIDC_CLIENT_ID")
+		args.OIDCClientSecret = os.Getenv("LXD_OIDC_CLIENT_SECRET")
+	}
+
+	if args.AuthType == api.AuthenticationMethodBearer {
+		content, err := os.ReadFile(c.BearerTokenPath(name))
+		if err != nil {
+			return nil, fmt.Errorf("Failed reading bearer token: %w", err)
+		}
+
+		args.BearerToken = strings.TrimSpace(string(content))
 	}
 
 	// Stop here if no TLS involved
@@ -296,7 +322,26 @@ func (c *Config) getConnectionArgs(name string) (*lxd.ConnectionArgs, error) {
 	}
 
 	// Stop here if no client certificate involved
-	if remote.Protocol == "simplestreams" || shared.ValueInSlice(remote.AuthType, []string{api.AuthenticationMethodOIDC}) {
+	if shared.ValueInSlice(remote.Protocol, []string{"simplestreams", "oci"}) || shared.ValueInSlice(remote.AuthType, []string{api.AuthenticationMethodOIDC, api.AuthenticationMethodBearer}) {
+		return &args, nil
+	}
+
+	// Client certificate issued by the remote's certificate authority
+	if c.HasRemoteClientCertificate(name) {
+		content, err := os.ReadFile(c.ClientCertPath(name))
+		if err != nil {
+			return nil, err
+		}
+
+		args.TLSClientCert = string(content)
+
+		content, err = os.ReadFile(c.ClientKeyPath(name))
+		if err != nil {
+			return nil, err
+		}
+
+		args.TLSClientKey = string(content)
+
 		return &args, nil
 	}
 
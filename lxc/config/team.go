@@ -0,0 +1,254 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// TeamConfigDefaultRefreshInterval is the default interval at which the team configuration is refreshed.
+const TeamConfigDefaultRefreshInterval = 24 * time.Hour
+
+// TeamConfig describes a configuration that is shared by a team and retrieved from a URL.
+type TeamConfig struct {
+	// URL of the team configuration. The signature is retrieved from the same URL with a ".sig" suffix.
+	URL string `yaml:"url"`
+
+	// PEM encoded public key used to verify the signature of the team configuration
+	PublicKey string `yaml:"public-key"`
+
+	// Interval at which the team configuration is refreshed (Go duration, for example "12h")
+	RefreshInterval string `yaml:"refresh-interval,omitempty"`
+}
+
+// teamConfigContent is the content of a team configuration.
+type teamConfigContent struct {
+	// Remotes to add to the configuration of the team members
+	Remotes map[string]Remote `yaml:"remotes"`
+
+	// Command line aliases to add to the configuration of the team members
+	Aliases map[string]string `yaml:"aliases"`
+}
+
+// TeamConfigPath returns the path of the cached team configuration.
+func (c *Config) TeamConfigPath() string {
+	return c.ConfigPath("team-config.yml")
+}
+
+// teamConfigRefreshInterval returns the interval at which the team configuration is refreshed.
+func (c *Config) teamConfigRefreshInterval() (time.Duration, error) {
+	if c.TeamConfig.RefreshInterval == "" {
+		return TeamConfigDefaultRefreshInterval, nil
+	}
+
+	interval, err := time.ParseDuration(c.TeamConfig.RefreshInterval)
+	if err != nil {
+		return -1, fmt.Errorf("Invalid team configuration refresh interval %q: %w", c.TeamConfig.RefreshInterval, err)
+	}
+
+	return interval, nil
+}
+
+// verifyTeamConfig checks that the signature of the team configuration was made with the configured public key.
+// RSA (PKCS #1 v1.5) and ECDSA signatures must be made over the SHA-256 digest of the content, as done by
+// "openssl dgst -sha256 -sign". Ed25519 signatures are made over the content itself.
+func (c *Config) verifyTeamConfig(content []byte, signature []byte) error {
+	block, _ := pem.Decode([]byte(c.TeamConfig.PublicKey))
+	if block == nil {
+		return fmt.Errorf("Invalid team configuration public key: No PEM data found")
+	}
+
+	var publicKey any
+	var err error
+	if block.Type == "CERTIFICATE" {
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			publicKey = cert.PublicKey
+		}
+	} else {
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Invalid team configuration public key: %w", err)
+	}
+
+	digest := sha256.Sum256(content)
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			err = fmt.Errorf("Invalid signature")
+		}
+
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, content, signature) {
+			err = fmt.Errorf("Invalid signature")
+		}
+
+	default:
+		return fmt.Errorf("Unsupported team configuration public key type %T", publicKey)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed verifying team configuration signature: %w", err)
+	}
+
+	return nil
+}
+
+// fetchTeamConfigFile downloads a file related to the team configuration.
+func fetchTeamConfigFile(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to fetch %s: %s", url, resp.Status)
+	}
+
+	// Team configurations are small, don't read more than 1MiB.
+	return io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+}
+
+// RefreshTeamConfig downloads the team configuration, verifies its signature and caches it locally.
+// The cached team configuration is applied the next time the configuration is loaded.
+func (c *Config) RefreshTeamConfig() error {
+	if c.TeamConfig == nil {
+		return fmt.Errorf("No team configuration is set")
+	}
+
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+	}
+
+	content, err := fetchTeamConfigFile(client, c.TeamConfig.URL)
+	if err != nil {
+		return fmt.Errorf("Failed retrieving team configuration: %w", err)
+	}
+
+	signature, err := fetchTeamConfigFile(client, c.TeamConfig.URL+".sig")
+	if err != nil {
+		return fmt.Errorf("Failed retrieving team configuration signature: %w", err)
+	}
+
+	err = c.verifyTeamConfig(content, signature)
+	if err != nil {
+		return err
+	}
+
+	// Check the content is valid before caching it.
+	team := teamConfigContent{}
+	err = yaml.Unmarshal(content, &team)
+	if err != nil {
+		return fmt.Errorf("Unable to decode the team configuration: %w", err)
+	}
+
+	err = os.MkdirAll(c.ConfigDir, 0750)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(c.TeamConfigPath()+".sig", signature, 0600)
+	if err != nil {
+		return fmt.Errorf("Unable to write the team configuration signature: %w", err)
+	}
+
+	err = os.WriteFile(c.TeamConfigPath(), content, 0600)
+	if err != nil {
+		return fmt.Errorf("Unable to write the team configuration: %w", err)
+	}
+
+	return nil
+}
+
+// applyTeamConfig merges the cached team configuration into the configuration, refreshing it first if it's stale.
+// The remotes and aliases defined locally take precedence over the ones of the team configuration.
+func (c *Config) applyTeamConfig() error {
+	interval, err := c.teamConfigRefreshInterval()
+	if err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(c.TeamConfigPath())
+	if err != nil || time.Since(fi.ModTime()) > interval {
+		// Keep using the cached team configuration if it can't be refreshed (for example when offline).
+		err = c.RefreshTeamConfig()
+		if err != nil && !shared.PathExists(c.TeamConfigPath()) {
+			return err
+		}
+	}
+
+	content, err := os.ReadFile(c.TeamConfigPath())
+	if err != nil {
+		return fmt.Errorf("Unable to read the team configuration: %w", err)
+	}
+
+	// Verify the cached copy too, in case the public key was changed since it was retrieved.
+	signature, err := os.ReadFile(c.TeamConfigPath() + ".sig")
+	if err != nil {
+		return fmt.Errorf("Unable to read the team configuration signature: %w", err)
+	}
+
+	err = c.verifyTeamConfig(content, signature)
+	if err != nil {
+		return err
+	}
+
+	team := teamConfigContent{}
+	err = yaml.Unmarshal(content, &team)
+	if err != nil {
+		return fmt.Errorf("Unable to decode the team configuration: %w", err)
+	}
+
+	if c.Remotes == nil {
+		c.Remotes = make(map[string]Remote)
+	}
+
+	for name, remote := range team.Remotes {
+		_, ok := c.Remotes[name]
+		if ok {
+			continue
+		}
+
+		remote.Team = true
+		c.Remotes[name] = remote
+	}
+
+	if c.Aliases == nil {
+		c.Aliases = make(map[string]string)
+	}
+
+	c.teamAliases = make(map[string]string)
+	for name, target := range team.Aliases {
+		_, ok := c.Aliases[name]
+		if ok {
+			continue
+		}
+
+		c.Aliases[name] = target
+		c.teamAliases[name] = target
+	}
+
+	return nil
+}
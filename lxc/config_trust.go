@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -74,6 +75,7 @@ type cmdConfigTrustAdd struct {
 	config      *cmdConfig
 	configTrust *cmdConfigTrust
 
+	flagExpiry     string
 	flagName       string
 	flagProjects   string
 	flagRestricted bool
@@ -95,12 +97,16 @@ If the certificate is omitted, a token will be generated and returned. A client
 providing a valid token will have its client certificate added to the trusted list
 and the consumed token will be invalidated. Similar to certificates, tokens can be
 restricted to one or more projects.
+
+Tokens expire after the time set in --expiry (for example "1d" or "2H 30M"),
+or the server's core.remote_token_expiry if not set.
 `))
 
 	cmd.Flags().BoolVar(&c.flagRestricted, "restricted", false, i18n.G("Restrict the certificate to one or more projects"))
 	cmd.Flags().StringVar(&c.flagProjects, "projects", "", i18n.G("List of projects to restrict the certificate to")+"``")
 	cmd.Flags().StringVar(&c.flagName, "name", "", i18n.G("Alternative certificate name")+"``")
 	cmd.Flags().StringVar(&c.flagType, "type", "client", i18n.G("Type of certificate")+"``")
+	cmd.Flags().StringVar(&c.flagExpiry, "expiry", "", i18n.G("Time after which the token expires")+"``")
 
 	cmd.RunE = c.run
 
@@ -198,6 +204,17 @@ func (c *cmdConfigTrustAdd) run(cmd *cobra.Command, args []string) error {
 		cert.Projects = strings.Split(c.flagProjects, ",")
 	}
 
+	if c.flagExpiry != "" {
+		if !cert.Token {
+			return fmt.Errorf(i18n.G("Cannot set an expiry when adding a certificate"))
+		}
+
+		cert.ExpiresAt, err = shared.GetExpiry(time.Now(), c.flagExpiry)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Invalid expiry: %w"), err)
+		}
+	}
+
 	if cert.Token {
 		op, err := resource.server.CreateCertificateToken(cert)
 		if err != nil {
@@ -468,6 +485,7 @@ func (c *cmdConfigTrustListTokens) run(cmd *cobra.Command, args []string) error
 	type displayToken struct {
 		ClientName string
 		Token      string
+		Projects   string
 		ExpiresAt  string
 	}
 
@@ -494,9 +512,27 @@ func (c *cmdConfigTrustListTokens) run(cmd *cobra.Command, args []string) error
 			expiresAt = joinToken.ExpiresAt.Format("2006/01/02 15:04 MST")
 		}
 
+		// Only show the projects if the token is restricted.
+		var projects string
+		req, _ := op.Metadata["request"].(map[string]any)
+		restricted, _ := req["restricted"].(bool)
+		if restricted {
+			projectNames := []string{}
+			reqProjects, _ := req["projects"].([]any)
+			for _, project := range reqProjects {
+				projectName, ok := project.(string)
+				if ok {
+					projectNames = append(projectNames, projectName)
+				}
+			}
+
+			projects = strings.Join(projectNames, ", ")
+		}
+
 		displayTokens = append(displayTokens, displayToken{
 			ClientName: joinToken.ClientName,
 			Token:      joinToken.String(),
+			Projects:   projects,
 			ExpiresAt:  expiresAt,
 		})
 	}
@@ -504,7 +540,7 @@ func (c *cmdConfigTrustListTokens) run(cmd *cobra.Command, args []string) error
 	// Render the table.
 	data := [][]string{}
 	for _, token := range displayTokens {
-		line := []string{token.ClientName, token.Token, token.ExpiresAt}
+		line := []string{token.ClientName, token.Token, token.Projects, token.ExpiresAt}
 		data = append(data, line)
 	}
 
@@ -513,6 +549,7 @@ func (c *cmdConfigTrustListTokens) run(cmd *cobra.Command, args []string) error
 	header := []string{
 		i18n.G("NAME"),
 		i18n.G("TOKEN"),
+		i18n.G("PROJECTS"),
 		i18n.G("EXPIRES AT"),
 	}
 
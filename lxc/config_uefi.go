@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+// UEFI variable GUIDs used by the Secure Boot key databases.
+const (
+	uefiGlobalVariableGUID        = "8be4df61-93ca-11d2-aa0d-00e098032b8c"
+	uefiImageSecurityDatabaseGUID = "d719b2cb-3d3a-4596-a3bc-dad00e67656f"
+	uefiCertX509GUID              = "a5c059a1-94e4-4aa7-87b5-ab155c2bf072"
+)
+
+// uefiAuthenticatedVariableAttr is the attribute mask used by the Secure Boot key databases:
+// EFI_VARIABLE_NON_VOLATILE | EFI_VARIABLE_BOOTSERVICE_ACCESS | EFI_VARIABLE_RUNTIME_ACCESS |
+// EFI_VARIABLE_TIME_BASED_AUTHENTICATED_WRITE_ACCESS.
+const uefiAuthenticatedVariableAttr = 0x27
+
+// uefiSecureBootKeyGUIDs maps the Secure Boot key databases to their vendor GUID.
+var uefiSecureBootKeyGUIDs = map[string]string{
+	"PK":  uefiGlobalVariableGUID,
+	"KEK": uefiGlobalVariableGUID,
+	"db":  uefiImageSecurityDatabaseGUID,
+	"dbx": uefiImageSecurityDatabaseGUID,
+}
+
+// uefiGUIDBytes returns the binary (mixed-endian) representation of an EFI GUID.
+func uefiGUIDBytes(guid uuid.UUID) []byte {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint32(b[0:4], binary.BigEndian.Uint32(guid[0:4]))
+	binary.LittleEndian.PutUint16(b[4:6], binary.BigEndian.Uint16(guid[4:6]))
+	binary.LittleEndian.PutUint16(b[6:8], binary.BigEndian.Uint16(guid[6:8]))
+	copy(b[8:], guid[8:])
+
+	return b
+}
+
+// uefiSignatureList returns an EFI_SIGNATURE_LIST for each of the DER encoded X.509 certificates.
+func uefiSignatureList(owner uuid.UUID, certs ...[]byte) []byte {
+	var buf bytes.Buffer
+
+	for _, cert := range certs {
+		signatureSize := 16 + len(cert)
+
+		// EFI_SIGNATURE_LIST header.
+		buf.Write(uefiGUIDBytes(uuid.MustParse(uefiCertX509GUID)))
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(28+signatureSize)) // SignatureListSize
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(0))                // SignatureHeaderSize
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(signatureSize))    // SignatureSize
+
+		// EFI_SIGNATURE_DATA.
+		buf.Write(uefiGUIDBytes(owner))
+		buf.Write(cert)
+	}
+
+	return buf.Bytes()
+}
+
+// uefiTimestamp returns the EFI_TIME representation of the given time.
+func uefiTimestamp(t time.Time) []byte {
+	t = t.UTC()
+
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint16(b[0:2], uint16(t.Year()))
+	b[2] = byte(t.Month())
+	b[3] = byte(t.Day())
+	b[4] = byte(t.Hour())
+	b[5] = byte(t.Minute())
+	b[6] = byte(t.Second())
+
+	// Pad1, Nanosecond, TimeZone, Daylight and Pad2 are left zeroed.
+	return b
+}
+
+// uefiParsePEMCertificates returns the DER encoded certificates found in the PEM data.
+func uefiParsePEMCertificates(data []byte) ([][]byte, error) {
+	var certs [][]byte
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		_, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		certs = append(certs, block.Bytes)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf(i18n.G("No PEM encoded certificate found"))
+	}
+
+	return certs, nil
+}
+
+// Export.
+type cmdConfigUefiExport struct {
+	global     *cmdGlobal
+	configUefi *cmdConfigUefi
+}
+
+// Command creates a Cobra command to export virtual machine instance UEFI variables.
+func (c *cmdConfigUefiExport) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("export", i18n.G("[<remote>:]<instance> [<path>]"))
+	cmd.Short = i18n.G("Export instance UEFI variables")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Export instance UEFI variables
+
+The variables are exported in JSON format, to the provided path or to stdout.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc config uefi export v1 v1_uefi_vars.json
+    Export the UEFI variables of instance "v1" to v1_uefi_vars.json.`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+// Run executes the "export" command, writing the virtual machine instance UEFI variables in JSON format.
+func (c *cmdConfigUefiExport) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := args[0]
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Instance name must be specified"))
+	}
+
+	instEFI, _, err := resource.server.GetInstanceUEFIVars(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(instEFI, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+
+	if len(args) < 2 || args[1] == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	return os.WriteFile(args[1], data, 0600)
+}
+
+// Import.
+type cmdConfigUefiImport struct {
+	global     *cmdGlobal
+	configUefi *cmdConfigUefi
+}
+
+// Command creates a Cobra command to import virtual machine instance UEFI variables.
+func (c *cmdConfigUefiImport) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("import", i18n.G("[<remote>:]<instance> [<path>]"))
+	cmd.Short = i18n.G("Import instance UEFI variables")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Import instance UEFI variables
+
+The variables are read in JSON format (as produced by "lxc config uefi export"), from the provided path or from stdin.
+All existing UEFI variables of the instance are replaced.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc config uefi import v1 v1_uefi_vars.json
+    Replace the UEFI variables of instance "v1" with the ones from v1_uefi_vars.json.`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+// Run executes the "import" command, replacing the virtual machine instance UEFI variables.
+func (c *cmdConfigUefiImport) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := args[0]
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Instance name must be specified"))
+	}
+
+	var contents []byte
+	if len(args) < 2 || args[1] == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else {
+		contents, err = os.ReadFile(args[1])
+	}
+
+	if err != nil {
+		return err
+	}
+
+	newUEFIVarsSet := api.InstanceUEFIVars{}
+	err = json.Unmarshal(contents, &newUEFIVarsSet)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed parsing UEFI variables: %w"), err)
+	}
+
+	if newUEFIVarsSet.Variables == nil {
+		return fmt.Errorf(i18n.G("No UEFI variables found"))
+	}
+
+	return resource.server.UpdateInstanceUEFIVars(resource.name, newUEFIVarsSet, "")
+}
+
+// Enroll key.
+type cmdConfigUefiEnrollKey struct {
+	global     *cmdGlobal
+	configUefi *cmdConfigUefi
+
+	flagAppend bool
+	flagOwner  string
+}
+
+// Command creates a Cobra command to enroll Secure Boot keys into the virtual machine instance UEFI variables.
+func (c *cmdConfigUefiEnrollKey) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("enroll-key", i18n.G("[<remote>:]<instance> <PK|KEK|db|dbx> <certificate>..."))
+	cmd.Short = i18n.G("Enroll Secure Boot keys into instance UEFI variables")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Enroll Secure Boot keys into instance UEFI variables
+
+The PEM encoded X.509 certificates are converted into an EFI signature list and stored in the
+Secure Boot platform key (PK), key exchange key (KEK), allowed (db) or forbidden (dbx) signature database.
+By default, the existing content of the database is replaced.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc config uefi enroll-key v1 db my-signing-key.crt --append
+    Add the certificate from my-signing-key.crt to the allowed signature database of instance "v1".`))
+
+	cmd.Flags().BoolVar(&c.flagAppend, "append", false, i18n.G("Append the certificates to the existing database instead of replacing it"))
+	cmd.Flags().StringVar(&c.flagOwner, "owner", "", i18n.G("Signature owner GUID (a random one is generated if not set)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+// Run executes the "enroll-key" command, updating the Secure Boot key database of the virtual machine instance.
+func (c *cmdConfigUefiEnrollKey) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := args[0]
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Instance name must be specified"))
+	}
+
+	database := args[1]
+	guid, ok := uefiSecureBootKeyGUIDs[database]
+	if !ok {
+		return fmt.Errorf(i18n.G("Invalid Secure Boot key database %q, must be one of: PK, KEK, db, dbx"), database)
+	}
+
+	if database == "PK" && (c.flagAppend || len(args) > 3) {
+		return fmt.Errorf(i18n.G("The platform key (PK) can only hold a single certificate"))
+	}
+
+	owner := uuid.New()
+	if c.flagOwner != "" {
+		owner, err = uuid.Parse(c.flagOwner)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Invalid signature owner GUID %q: %w"), c.flagOwner, err)
+		}
+	}
+
+	var certs [][]byte
+	for _, path := range args[2:] {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		pathCerts, err := uefiParsePEMCertificates(data)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed parsing certificate %q: %w"), path, err)
+		}
+
+		certs = append(certs, pathCerts...)
+	}
+
+	if database == "PK" && len(certs) > 1 {
+		return fmt.Errorf(i18n.G("The platform key (PK) can only hold a single certificate"))
+	}
+
+	instUEFI, etag, err := resource.server.GetInstanceUEFIVars(resource.name)
+	if err != nil {
+		return err
+	}
+
+	err = uefiEnrollKey(instUEFI, database+"-"+guid, uefiSignatureList(owner, certs...), c.flagAppend, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return resource.server.UpdateInstanceUEFIVars(resource.name, *instUEFI, etag)
+}
+
+// uefiEnrollKey stores the signature list in the Secure Boot key database variable with the given key, after
+// any signature list already stored in it if appendKeys is true.
+func uefiEnrollKey(instUEFI *api.InstanceUEFIVars, key string, signatureList []byte, appendKeys bool, now time.Time) error {
+	if instUEFI.Variables == nil {
+		instUEFI.Variables = map[string]api.InstanceUEFIVariable{}
+	}
+
+	uefiVar, ok := instUEFI.Variables[key]
+	if ok && appendKeys {
+		existing, err := hex.DecodeString(uefiVar.Data)
+		if err != nil {
+			return err
+		}
+
+		signatureList = append(existing, signatureList...)
+	}
+
+	// The digest identifies the certificate that signed the current content of an authenticated variable.
+	// The new content isn't signed, so the digest of the previous content must not be carried over.
+	instUEFI.Variables[key] = api.InstanceUEFIVariable{
+		Data:      hex.EncodeToString(signatureList),
+		Attr:      uefiAuthenticatedVariableAttr,
+		Timestamp: hex.EncodeToString(uefiTimestamp(now)),
+	}
+
+	return nil
+}
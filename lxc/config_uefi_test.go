@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+type configUefiTestSuite struct {
+	suite.Suite
+}
+
+func TestConfigUefiTestSuite(t *testing.T) {
+	suite.Run(t, new(configUefiTestSuite))
+}
+
+func (s *configUefiTestSuite) TestUefiGUIDBytes() {
+	guid := uuid.MustParse(uefiCertX509GUID)
+
+	s.Equal("a159c0a5e494a74a87b5ab155c2bf072", hex.EncodeToString(uefiGUIDBytes(guid)))
+}
+
+func (s *configUefiTestSuite) TestUefiSignatureList() {
+	owner := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	certs := [][]byte{{0x01, 0x02, 0x03}, {0x04, 0x05}}
+
+	list := uefiSignatureList(owner, certs...)
+	s.Len(list, (28+16+3)+(28+16+2))
+
+	// First signature list.
+	s.Equal(uefiGUIDBytes(uuid.MustParse(uefiCertX509GUID)), list[0:16])
+	s.Equal(uint32(28+16+3), binary.LittleEndian.Uint32(list[16:20]))
+	s.Equal(uint32(0), binary.LittleEndian.Uint32(list[20:24]))
+	s.Equal(uint32(16+3), binary.LittleEndian.Uint32(list[24:28]))
+	s.Equal(uefiGUIDBytes(owner), list[28:44])
+	s.Equal(certs[0], list[44:47])
+
+	// Second signature list.
+	s.Equal(uint32(28+16+2), binary.LittleEndian.Uint32(list[47+16:47+20]))
+	s.Equal(certs[1], list[len(list)-2:])
+}
+
+func (s *configUefiTestSuite) TestUefiTimestamp() {
+	ts := uefiTimestamp(time.Date(2024, time.March, 5, 6, 7, 8, 9, time.UTC))
+
+	s.Equal("e8070305060708000000000000000000", hex.EncodeToString(ts))
+}
+
+func (s *configUefiTestSuite) TestUefiParsePEMCertificates() {
+	_, err := uefiParsePEMCertificates([]byte("not a certificate"))
+	s.Error(err)
+}
+
+func (s *configUefiTestSuite) TestUefiEnrollKey() {
+	now := time.Date(2024, time.March, 5, 6, 7, 8, 9, time.UTC)
+	key := "db-" + uefiImageSecurityDatabaseGUID
+
+	// The variables are initialised if the server didn't return any.
+	instUEFI := &api.InstanceUEFIVars{}
+	s.NoError(uefiEnrollKey(instUEFI, key, []byte{0x01}, true, now))
+	s.Equal(api.InstanceUEFIVariable{Data: "01", Attr: uefiAuthenticatedVariableAttr, Timestamp: "e8070305060708000000000000000000"}, instUEFI.Variables[key])
+
+	// The digest of the previous content isn't kept.
+	instUEFI.Variables[key] = api.InstanceUEFIVariable{Data: "01", Attr: uefiAuthenticatedVariableAttr, Digest: "aabb"}
+	s.NoError(uefiEnrollKey(instUEFI, key, []byte{0x02}, true, now))
+	s.Equal("0102", instUEFI.Variables[key].Data)
+	s.Equal("", instUEFI.Variables[key].Digest)
+
+	s.NoError(uefiEnrollKey(instUEFI, key, []byte{0x03}, false, now))
+	s.Equal("03", instUEFI.Variables[key].Data)
+
+	// Other variables are left untouched.
+	other := api.InstanceUEFIVariable{Data: "00", Attr: 7, Digest: "ccdd"}
+	instUEFI.Variables["foo-"+uefiGlobalVariableGUID] = other
+	s.NoError(uefiEnrollKey(instUEFI, key, []byte{0x04}, false, now))
+	s.Equal(other, instUEFI.Variables["foo-"+uefiGlobalVariableGUID])
+
+	instUEFI.Variables[key] = api.InstanceUEFIVariable{Data: "zz"}
+	s.Error(uefiEnrollKey(instUEFI, key, []byte{0x05}, true, now))
+}
@@ -26,8 +26,12 @@ import (
 type cmdConsole struct {
 	global *cmdGlobal
 
-	flagShowLog bool
-	flagType    string
+	flagShowLog      bool
+	flagShowSessions bool
+	flagForce        bool
+	flagReadOnly     bool
+	flagType         string
+	flagListen       string
 }
 
 func (c *cmdConsole) command() *cobra.Command {
@@ -38,11 +42,23 @@ func (c *cmdConsole) command() *cobra.Command {
 		`Attach to instance consoles
 
 This command allows you to interact with the boot console of an instance
-as well as retrieve past log entries from it.`))
+as well as retrieve past log entries from it.
+
+Multiple clients can be attached to the text console of a virtual machine
+at the same time. They all see the console output and can send input to it.
+
+For the 'vga' console, the SPICE connection is proxied through the LXD API
+to a local socket and a SPICE viewer (remote-viewer or spicy) is started if
+one can be found. Use --listen to only run the proxy on a local TCP address
+and connect any SPICE viewer to it.`))
 
 	cmd.RunE = c.run
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Retrieve the instance's console log"))
+	cmd.Flags().BoolVar(&c.flagShowSessions, "show-sessions", false, i18n.G("List the clients attached to the virtual machine's text console"))
+	cmd.Flags().BoolVar(&c.flagForce, "force", false, i18n.G("Disconnect the other clients attached to the virtual machine's text console"))
+	cmd.Flags().BoolVar(&c.flagReadOnly, "read-only", false, i18n.G("Only watch the text console, ignoring any input"))
 	cmd.Flags().StringVarP(&c.flagType, "type", "t", "console", i18n.G("Type of connection to establish: 'console' for serial console, 'vga' for SPICE graphical output")+"``")
+	cmd.Flags().StringVar(&c.flagListen, "listen", "", i18n.G("Local address to proxy the SPICE connection to instead of starting a viewer (e.g. 127.0.0.1:5900)")+"``")
 
 	return cmd
 }
@@ -109,6 +125,10 @@ func (c *cmdConsole) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Unknown output type %q"), c.flagType)
 	}
 
+	if c.flagListen != "" && c.flagType != "vga" {
+		return fmt.Errorf(i18n.G("The --listen flag is only supported for by 'vga' output type"))
+	}
+
 	// Connect to LXD
 	remote, name, err := conf.ParseRemote(args[0])
 	if err != nil {
@@ -141,6 +161,32 @@ func (c *cmdConsole) run(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Show the attached clients if requested
+	if c.flagShowSessions {
+		if c.flagType != "console" {
+			return fmt.Errorf(i18n.G("The --show-sessions flag is only supported for by 'console' output type"))
+		}
+
+		sessions, err := d.GetInstanceConsoleSessions(name)
+		if err != nil {
+			return err
+		}
+
+		data := [][]string{}
+		for _, session := range sessions {
+			data = append(data, []string{session.ID, session.Username, session.Address, session.CreatedAt.UTC().Format("2006/01/02 15:04 MST")})
+		}
+
+		header := []string{
+			i18n.G("ID"),
+			i18n.G("USERNAME"),
+			i18n.G("ADDRESS"),
+			i18n.G("ATTACHED AT"),
+		}
+
+		return cli.RenderTable(cli.TableFormatTable, header, data, sessions)
+	}
+
 	return c.runConsole(d, name)
 }
 
@@ -180,9 +226,11 @@ func (c *cmdConsole) console(d lxd.InstanceServer, name string) error {
 
 	// Prepare the remote console
 	req := api.InstanceConsolePost{
-		Width:  width,
-		Height: height,
-		Type:   "console",
+		Width:    width,
+		Height:   height,
+		Type:     "console",
+		Force:    c.flagForce,
+		ReadOnly: c.flagReadOnly,
 	}
 
 	consoleDisconnect := make(chan bool)
@@ -225,9 +273,6 @@ func (c *cmdConsole) console(d lxd.InstanceServer, name string) error {
 }
 
 func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
-	var err error
-	conf := c.global.conf
-
 	// We currently use the control websocket just to abort in case of errors.
 	controlDone := make(chan struct{}, 1)
 	handler := func(control *websocket.Conn) {
@@ -250,53 +295,13 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 	}
 
 	// Setup local socket.
-	var socket string
-	var listener net.Listener
-	if runtime.GOOS != "windows" {
-		// Create a temporary unix socket mirroring the instance's spice socket.
-		if !shared.PathExists(conf.ConfigPath("sockets")) {
-			err := os.MkdirAll(conf.ConfigPath("sockets"), 0700)
-			if err != nil {
-				return err
-			}
-		}
-
-		// Generate a random file name.
-		path, err := os.CreateTemp(conf.ConfigPath("sockets"), "*.spice")
-		if err != nil {
-			return err
-		}
-
-		_ = path.Close()
-
-		err = os.Remove(path.Name())
-		if err != nil {
-			return err
-		}
-
-		// Listen on the socket.
-		listener, err = net.Listen("unix", path.Name())
-		if err != nil {
-			return err
-		}
-
-		defer func() { _ = os.Remove(path.Name()) }()
-
-		socket = fmt.Sprintf("spice+unix://%s", path.Name())
-	} else {
-		listener, err = net.Listen("tcp", "127.0.0.1:0")
-		if err != nil {
-			return err
-		}
-
-		addr, ok := listener.Addr().(*net.TCPAddr)
-		if !ok {
-			return errors.New("Failed to get TCP listen address")
-		}
-
-		socket = fmt.Sprintf("spice://127.0.0.1:%d", addr.Port)
+	listener, socket, cleanup, err := c.vgaListener()
+	if err != nil {
+		return err
 	}
 
+	defer cleanup()
+
 	// Clean everything up when the viewer is done.
 	go func() {
 		<-chViewer
@@ -341,9 +346,12 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 		}
 	}()
 
-	// Use either spicy or remote-viewer if available.
-	remoteViewer := c.findCommand("remote-viewer")
-	spicy := c.findCommand("spicy")
+	// Use either spicy or remote-viewer if available, unless only the proxy was requested.
+	var remoteViewer, spicy string
+	if c.flagListen == "" {
+		remoteViewer = c.findCommand("remote-viewer")
+		spicy = c.findCommand("spicy")
+	}
 
 	if remoteViewer != "" || spicy != "" {
 		var cmd *exec.Cmd
@@ -379,8 +387,13 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 			_ = cmd.Process.Kill()
 		}()
 	} else {
-		fmt.Println(i18n.G("LXD automatically uses either spicy or remote-viewer when present."))
-		fmt.Println(i18n.G("As neither could be found, the raw SPICE socket can be found at:"))
+		if c.flagListen != "" {
+			fmt.Println(i18n.G("The SPICE connection is available at:"))
+		} else {
+			fmt.Println(i18n.G("LXD automatically uses either spicy or remote-viewer when present."))
+			fmt.Println(i18n.G("As neither could be found, the raw SPICE socket can be found at:"))
+		}
+
 		fmt.Printf("  %s\n", socket)
 
 		// Wait for all connections to complete.
@@ -397,3 +410,59 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 
 	return nil
 }
+
+// vgaListener sets up the local socket the SPICE connection is proxied to and returns it along with its SPICE URI.
+// A unix socket is used on Linux as it can't be reached by other users. Other platforms use a TCP socket on the
+// loopback address as SPICE viewers there don't support unix sockets.
+func (c *cmdConsole) vgaListener() (net.Listener, string, func(), error) {
+	conf := c.global.conf
+
+	if runtime.GOOS != "linux" || c.flagListen != "" {
+		address := c.flagListen
+		if address == "" {
+			address = "127.0.0.1:0"
+		}
+
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		addr, ok := listener.Addr().(*net.TCPAddr)
+		if !ok {
+			_ = listener.Close()
+			return nil, "", nil, errors.New("Failed to get TCP listen address")
+		}
+
+		return listener, fmt.Sprintf("spice://%s", net.JoinHostPort(addr.IP.String(), strconv.Itoa(addr.Port))), func() {}, nil
+	}
+
+	// Create a temporary unix socket mirroring the instance's spice socket.
+	if !shared.PathExists(conf.ConfigPath("sockets")) {
+		err := os.MkdirAll(conf.ConfigPath("sockets"), 0700)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	// Generate a random file name.
+	path, err := os.CreateTemp(conf.ConfigPath("sockets"), "*.spice")
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	_ = path.Close()
+
+	err = os.Remove(path.Name())
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// Listen on the socket.
+	listener, err := net.Listen("unix", path.Name())
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return listener, fmt.Sprintf("spice+unix://%s", path.Name()), func() { _ = os.Remove(path.Name()) }, nil
+}
@@ -6,10 +6,13 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"runtime"
 
 	"github.com/gorilla/websocket"
 	"golang.org/x/sys/unix"
 
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
 )
 
@@ -33,5 +36,15 @@ func (c *cmdConsole) controlSocketHandler(control *websocket.Conn) {
 
 func (c *cmdConsole) findCommand(name string) string {
 	path, _ := exec.LookPath(name)
+	if path == "" && runtime.GOOS == "darwin" {
+		// Homebrew's bin directory may not be in PATH when not started from a login shell.
+		for _, dir := range []string{"/opt/homebrew/bin", "/usr/local/bin"} {
+			candidate := filepath.Join(dir, name)
+			if shared.PathExists(candidate) {
+				return candidate
+			}
+		}
+	}
+
 	return path
 }
@@ -31,6 +31,7 @@ type cmdCopy struct {
 	flagTargetProject     string
 	flagRefresh           bool
 	flagAllowInconsistent bool
+	flagBwlimit           string
 }
 
 func (c *cmdCopy) command() *cobra.Command {
@@ -63,6 +64,7 @@ The pull transfer mode is the default as it is compatible with all LXD versions.
 	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, i18n.G("Create the instance with no profiles applied"))
 	cmd.Flags().BoolVar(&c.flagRefresh, "refresh", false, i18n.G("Perform an incremental copy"))
 	cmd.Flags().BoolVar(&c.flagAllowInconsistent, "allow-inconsistent", false, i18n.G("Ignore copy errors for volatile files"))
+	cmd.Flags().StringVar(&c.flagBwlimit, "bwlimit", "", i18n.G("Maximum transfer rate of the migration data in bytes per second (e.g. 10MiB)")+"``")
 
 	return cmd
 }
@@ -147,6 +149,12 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 		return err
 	}
 
+	// Watch the background operation
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Transferring instance: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
 	var op lxd.RemoteOperation
 	var writable api.InstancePut
 	var start bool
@@ -156,11 +164,16 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			return fmt.Errorf(i18n.G("--instance-only can't be passed when the source is a snapshot"))
 		}
 
+		if c.flagBwlimit != "" {
+			return fmt.Errorf(i18n.G("--bwlimit can't be passed when the source is a snapshot"))
+		}
+
 		// Prepare the instance creation request
 		args := lxd.InstanceSnapshotCopyArgs{
-			Name: destName,
-			Mode: mode,
-			Live: stateful,
+			Name:            destName,
+			Mode:            mode,
+			Live:            stateful,
+			ProgressHandler: progress.UpdateProgress,
 		}
 
 		if c.flagRefresh {
@@ -265,6 +278,8 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			Mode:              mode,
 			Refresh:           c.flagRefresh,
 			AllowInconsistent: c.flagAllowInconsistent,
+			BandwidthLimit:    c.flagBwlimit,
+			ProgressHandler:   progress.UpdateProgress,
 		}
 
 		// Copy of an instance into a new instance
@@ -364,18 +379,6 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 		writable = entry.Writable()
 	}
 
-	// Watch the background operation
-	progress := cli.ProgressRenderer{
-		Format: i18n.G("Transferring instance: %s"),
-		Quiet:  c.global.flagQuiet,
-	}
-
-	_, err = op.AddHandler(progress.UpdateOp)
-	if err != nil {
-		progress.Done("")
-		return err
-	}
-
 	// Wait for the copy to complete
 	err = cli.CancelableWait(op, &progress)
 	if err != nil {
@@ -0,0 +1,624 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdDeviceGroup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdDeviceGroup) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("device-group")
+	cmd.Short = i18n.G("Manage device groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage device groups
+
+Device groups are named sets of devices that can be attached to and detached from
+instances as a unit, including while they are running.`))
+
+	// Attach.
+	deviceGroupAttachCmd := cmdDeviceGroupAttach{global: c.global, deviceGroup: c}
+	cmd.AddCommand(deviceGroupAttachCmd.command())
+
+	// Create.
+	deviceGroupCreateCmd := cmdDeviceGroupCreate{global: c.global, deviceGroup: c}
+	cmd.AddCommand(deviceGroupCreateCmd.command())
+
+	// Delete.
+	deviceGroupDeleteCmd := cmdDeviceGroupDelete{global: c.global, deviceGroup: c}
+	cmd.AddCommand(deviceGroupDeleteCmd.command())
+
+	// Detach.
+	deviceGroupDetachCmd := cmdDeviceGroupDetach{global: c.global, deviceGroup: c}
+	cmd.AddCommand(deviceGroupDetachCmd.command())
+
+	// Edit.
+	deviceGroupEditCmd := cmdDeviceGroupEdit{global: c.global, deviceGroup: c}
+	cmd.AddCommand(deviceGroupEditCmd.command())
+
+	// List.
+	deviceGroupListCmd := cmdDeviceGroupList{global: c.global, deviceGroup: c}
+	cmd.AddCommand(deviceGroupListCmd.command())
+
+	// Rename.
+	deviceGroupRenameCmd := cmdDeviceGroupRename{global: c.global, deviceGroup: c}
+	cmd.AddCommand(deviceGroupRenameCmd.command())
+
+	// Show.
+	deviceGroupShowCmd := cmdDeviceGroupShow{global: c.global, deviceGroup: c}
+	cmd.AddCommand(deviceGroupShowCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Attach.
+type cmdDeviceGroupAttach struct {
+	global      *cmdGlobal
+	deviceGroup *cmdDeviceGroup
+}
+
+func (c *cmdDeviceGroupAttach) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("attach", i18n.G("[<remote>:]<instance> <device group>"))
+	cmd.Short = i18n.G("Attach device groups to instances")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Attach device groups to instances
+
+All the devices of the device group are added to the instance.
+If the instance is running, the devices are hotplugged.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc device-group attach desktop01 gpu-workstation
+    Add the devices of the "gpu-workstation" device group to the instance "desktop01".`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdDeviceGroupAttach) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing instance name"))
+	}
+
+	// Attach the device group.
+	err = resource.server.AttachInstanceDeviceGroup(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Device group %s attached to %s")+"\n", args[1], resource.name)
+	}
+
+	return nil
+}
+
+// Create.
+type cmdDeviceGroupCreate struct {
+	global      *cmdGlobal
+	deviceGroup *cmdDeviceGroup
+
+	flagDescription string
+}
+
+func (c *cmdDeviceGroupCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<device group>"))
+	cmd.Short = i18n.G("Create device groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create device groups
+
+The devices of the device group can be provided as YAML on standard input.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc device-group create gpu-workstation < gpu-workstation.yaml
+    Create a device group with the devices defined in gpu-workstation.yaml.`))
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Device group description")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdDeviceGroupCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing device group name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var groupPut api.DeviceGroupPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &groupPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.flagDescription != "" {
+		groupPut.Description = c.flagDescription
+	}
+
+	// Create the device group.
+	group := api.DeviceGroupsPost{
+		DeviceGroupPut: groupPut,
+		Name:           resource.name,
+	}
+
+	err = resource.server.CreateDeviceGroup(group)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Device group %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdDeviceGroupDelete struct {
+	global      *cmdGlobal
+	deviceGroup *cmdDeviceGroup
+}
+
+func (c *cmdDeviceGroupDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<device group>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete device groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete device groups"))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdDeviceGroupDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing device group name"))
+	}
+
+	// Delete the device group.
+	err = resource.server.DeleteDeviceGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Device group %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Detach.
+type cmdDeviceGroupDetach struct {
+	global      *cmdGlobal
+	deviceGroup *cmdDeviceGroup
+}
+
+func (c *cmdDeviceGroupDetach) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("detach", i18n.G("[<remote>:]<instance> <device group>"))
+	cmd.Short = i18n.G("Detach device groups from instances")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Detach device groups from instances
+
+The devices that were added to the instance when attaching the device group are removed.`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdDeviceGroupDetach) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing instance name"))
+	}
+
+	// Detach the device group.
+	err = resource.server.DetachInstanceDeviceGroup(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Device group %s detached from %s")+"\n", args[1], resource.name)
+	}
+
+	return nil
+}
+
+// Edit.
+type cmdDeviceGroupEdit struct {
+	global      *cmdGlobal
+	deviceGroup *cmdDeviceGroup
+}
+
+func (c *cmdDeviceGroupEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<device group>"))
+	cmd.Short = i18n.G("Edit device groups as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Edit device groups as YAML
+
+Instances the device group is already attached to aren't changed.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc device-group edit <device group> < device-group.yaml
+    Update a device group using the content of device-group.yaml`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdDeviceGroupEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the device group.
+### Any line starting with a '# will be ignored.
+###
+### A device group consists of a set of devices.
+###
+### An example would look like:
+### name: gpu-workstation
+### description: GPU with USB controller and audio
+### devices:
+###   gpu:
+###     type: gpu
+###     pci: "0000:01:00.0"
+###   usb:
+###     type: pci
+###     address: "0000:00:14.0"
+###
+### Note that only the description and devices can be changed.`)
+}
+
+func (c *cmdDeviceGroupEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing device group name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc device-group show` command to be passed in here, but only take the contents
+		// of the DeviceGroupPut fields when updating the device group.
+		newdata := api.DeviceGroup{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateDeviceGroup(resource.name, newdata.Writable(), "")
+	}
+
+	// Get the current device group.
+	group, etag, err := resource.server.GetDeviceGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.DeviceGroup{} // We show the full device group, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateDeviceGroup(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// List.
+type cmdDeviceGroupList struct {
+	global      *cmdGlobal
+	deviceGroup *cmdDeviceGroup
+
+	flagFormat string
+}
+
+func (c *cmdDeviceGroupList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List device groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List device groups"))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdDeviceGroupList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
+	}
+
+	groups, err := resource.server.GetDeviceGroups()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, group := range groups {
+		devices := make([]string, 0, len(group.Devices))
+		for name := range group.Devices {
+			devices = append(devices, name)
+		}
+
+		sort.Strings(devices)
+
+		details := []string{
+			group.Name,
+			group.Description,
+			strings.Join(devices, "\n"),
+			fmt.Sprintf("%d", len(group.UsedBy)),
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("DEVICES"),
+		i18n.G("USED BY"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, groups)
+}
+
+// Rename.
+type cmdDeviceGroupRename struct {
+	global      *cmdGlobal
+	deviceGroup *cmdDeviceGroup
+}
+
+func (c *cmdDeviceGroupRename) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rename", i18n.G("[<remote>:]<device group> <new-name>"))
+	cmd.Aliases = []string{"mv"}
+	cmd.Short = i18n.G("Rename device groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Rename device groups"))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdDeviceGroupRename) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing device group name"))
+	}
+
+	// Rename the device group.
+	err = resource.server.RenameDeviceGroup(resource.name, api.DeviceGroupPost{Name: args[1]})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Device group %s renamed to %s")+"\n", resource.name, args[1])
+	}
+
+	return nil
+}
+
+// Show.
+type cmdDeviceGroupShow struct {
+	global      *cmdGlobal
+	deviceGroup *cmdDeviceGroup
+}
+
+func (c *cmdDeviceGroupShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<device group>"))
+	cmd.Short = i18n.G("Show device group configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show device group configurations"))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdDeviceGroupShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing device group name"))
+	}
+
+	// Show the device group.
+	group, _, err := resource.server.GetDeviceGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(group.UsedBy)
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
@@ -2,11 +2,13 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/spf13/cobra"
@@ -30,10 +32,42 @@ type cmdExec struct {
 	flagUser                uint32
 	flagGroup               uint32
 	flagCwd                 string
+	flagFormat              string
+	flagIncludeOutput       bool
 
 	interactive bool
 }
 
+// execResult is the result of a command printed in JSON output mode.
+type execResult struct {
+	ExitCode    int     `json:"exit_code"`
+	Signal      int     `json:"signal,omitempty"`
+	Duration    float64 `json:"duration_seconds"`
+	StdoutBytes int64   `json:"stdout_bytes"`
+	StderrBytes int64   `json:"stderr_bytes"`
+
+	// Byte slices are encoded as base64.
+	Stdout []byte `json:"stdout,omitempty"`
+	Stderr []byte `json:"stderr,omitempty"`
+}
+
+// execOutputWriter counts the bytes written to an output stream, optionally keeping them.
+type execOutputWriter struct {
+	buf   bytes.Buffer
+	keep  bool
+	count int64
+}
+
+// Write counts the bytes written and keeps them if requested.
+func (w *execOutputWriter) Write(p []byte) (int, error) {
+	w.count += int64(len(p))
+	if w.keep {
+		return w.buf.Write(p)
+	}
+
+	return len(p), nil
+}
+
 func (c *cmdExec) command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Use = usage("exec", i18n.G("[<remote>:]<instance> [flags] [--] <command line>"))
@@ -48,7 +82,11 @@ executable, passing the shell commands as arguments, for example:
 
   lxc exec <instance> -- sh -c "cd /tmp && pwd"
 
-Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).`))
+Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).
+
+With --format=json, the output of the command isn't displayed. Instead, a JSON object with the exit code,
+the signal that terminated the command (derived from exit codes above 128), the duration and the size of
+stdout and stderr is printed once the command completes. The output itself can be added with --include-output.`))
 
 	cmd.RunE = c.run
 	cmd.Flags().StringArrayVar(&c.flagEnvironment, "env", nil, i18n.G("Environment variable to set (e.g. HOME=/home/foo)")+"``")
@@ -59,6 +97,8 @@ Mode defaults to non-interactive, interactive mode is selected if both stdin AND
 	cmd.Flags().Uint32Var(&c.flagUser, "user", 0, i18n.G("User ID to run the command as (default 0)")+"``")
 	cmd.Flags().Uint32Var(&c.flagGroup, "group", 0, i18n.G("Group ID to run the command as (default 0)")+"``")
 	cmd.Flags().StringVar(&c.flagCwd, "cwd", "", i18n.G("Directory to run the command in (default /root)")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "", i18n.G("Format (json)")+"``")
+	cmd.Flags().BoolVar(&c.flagIncludeOutput, "include-output", false, i18n.G("Include the base64 encoded stdout and stderr in the JSON output"))
 
 	return cmd
 }
@@ -97,6 +137,19 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("You can't pass -t or -T at the same time as --mode"))
 	}
 
+	if c.flagFormat != "" && c.flagFormat != "json" {
+		return fmt.Errorf(i18n.G("Invalid format: %s"), c.flagFormat)
+	}
+
+	jsonOutput := c.flagFormat == "json"
+	if jsonOutput && (c.flagMode == "interactive" || c.flagForceInteractive) {
+		return fmt.Errorf(i18n.G("JSON output is only supported in non-interactive mode"))
+	}
+
+	if c.flagIncludeOutput && !jsonOutput {
+		return fmt.Errorf(i18n.G("--include-output can only be used with --format=json"))
+	}
+
 	// Connect to the daemon
 	remote, name, err := conf.ParseRemote(args[0])
 	if err != nil {
@@ -133,7 +186,7 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 	stdoutTerminal := termios.IsTerminal(stdoutFd)
 
 	// Determine interaction mode
-	if c.flagDisableStdin {
+	if c.flagDisableStdin || jsonOutput {
 		c.interactive = false
 	} else if c.flagMode == "interactive" || c.flagForceInteractive {
 		c.interactive = true
@@ -172,7 +225,17 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 		stdin = bytes.NewReader(nil)
 	}
 
-	stdout := getStdout()
+	var stdout, stderr io.Writer
+	stdout = getStdout()
+	stderr = os.Stderr
+
+	// Capture the output streams in JSON output mode.
+	stdoutWriter := &execOutputWriter{keep: c.flagIncludeOutput}
+	stderrWriter := &execOutputWriter{keep: c.flagIncludeOutput}
+	if jsonOutput {
+		stdout = stdoutWriter
+		stderr = stderrWriter
+	}
 
 	// Prepare the command
 	req := api.InstanceExecPost{
@@ -190,12 +253,13 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 	execArgs := lxd.InstanceExecArgs{
 		Stdin:    stdin,
 		Stdout:   stdout,
-		Stderr:   os.Stderr,
+		Stderr:   stderr,
 		Control:  handler,
 		DataDone: make(chan bool),
 	}
 
 	// Run the command in the instance
+	start := time.Now()
 	op, err := d.ExecInstance(name, req, &execArgs)
 	if err != nil {
 		return err
@@ -218,5 +282,31 @@ func (c *cmdExec) run(cmd *cobra.Command, args []string) error {
 	// Wait for any remaining I/O to be flushed
 	<-execArgs.DataDone
 
+	if jsonOutput {
+		result := execResult{
+			ExitCode:    c.global.ret,
+			Duration:    time.Since(start).Seconds(),
+			StdoutBytes: stdoutWriter.count,
+			StderrBytes: stderrWriter.count,
+		}
+
+		// Commands terminated by a signal are reported with an exit code of 128 plus the signal number.
+		if result.ExitCode > 128 {
+			result.Signal = result.ExitCode - 128
+		}
+
+		if c.flagIncludeOutput {
+			result.Stdout = stdoutWriter.buf.Bytes()
+			result.Stderr = stderrWriter.buf.Bytes()
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(data))
+	}
+
 	return nil
 }
@@ -17,6 +17,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/shared"
@@ -232,7 +233,8 @@ type cmdFilePull struct {
 	global *cmdGlobal
 	file   *cmdFile
 
-	edit bool
+	edit         bool
+	flagParallel int
 }
 
 func (c *cmdFilePull) command() *cobra.Command {
@@ -240,13 +242,20 @@ func (c *cmdFilePull) command() *cobra.Command {
 	cmd.Use = usage("pull", i18n.G("[<remote>:]<instance>/<path> [[<remote>:]<instance>/<path>...] <target path>"))
 	cmd.Short = i18n.G("Pull files from instances")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Pull files from instances`))
+		`Pull files from instances
+
+The source path may contain glob patterns (*, ? and [...]), which are expanded against the instance filesystem.
+Quote such paths so that they aren't expanded by the local shell.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`lxc file pull foo/etc/hosts .
-   To pull /etc/hosts from the instance and write it to the current directory.`))
+   To pull /etc/hosts from the instance and write it to the current directory.
+
+lxc file pull "foo/var/log/*.log" logs/ --parallel 4
+   To pull all .log files from /var/log in the instance into the logs directory, transferring up to 4 files at a time.`))
 
 	cmd.Flags().BoolVarP(&c.file.flagMkdir, "create-dirs", "p", false, i18n.G("Create any directories necessary"))
 	cmd.Flags().BoolVarP(&c.file.flagRecursive, "recursive", "r", false, i18n.G("Recursively transfer files"))
+	cmd.Flags().IntVar(&c.flagParallel, "parallel", 1, i18n.G("Number of files to transfer in parallel")+"``")
 	cmd.RunE = c.run
 
 	return cmd
@@ -259,6 +268,43 @@ func (c *cmdFilePull) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.flagParallel < 1 {
+		return fmt.Errorf(i18n.G("Invalid number of parallel transfers: %d"), c.flagParallel)
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[:len(args)-1]...)
+	if err != nil {
+		return err
+	}
+
+	// Expand the glob patterns of the sources against the instance filesystem.
+	sources := make([]remoteResource, 0, len(resources))
+	for _, resource := range resources {
+		pathSpec := strings.SplitN(resource.name, "/", 2)
+		if len(pathSpec) != 2 {
+			return fmt.Errorf(i18n.G("Invalid source %s"), resource.name)
+		}
+
+		if c.edit || !strings.ContainsAny(pathSpec[1], "*?[") {
+			sources = append(sources, resource)
+			continue
+		}
+
+		matches, err := resource.server.GetInstanceFileGlob(pathSpec[0], pathSpec[1])
+		if err != nil {
+			return err
+		}
+
+		if len(matches) == 0 {
+			return fmt.Errorf(i18n.G("No files in instance %q match %q"), pathSpec[0], pathSpec[1])
+		}
+
+		for _, match := range matches {
+			sources = append(sources, remoteResource{remote: resource.remote, server: resource.server, name: pathSpec[0] + "/" + match})
+		}
+	}
+
 	// Determine the target
 	target := filepath.Clean(args[len(args)-1])
 	if !c.edit {
@@ -275,16 +321,17 @@ func (c *cmdFilePull) run(cmd *cobra.Command, args []string) error {
 	 * If the path exists, just use it. If it doesn't exist, it might be a
 	 * directory in one of three cases:
 	 *   1. Someone explicitly put "/" at the end
-	 *   2. Someone provided more than one source. In this case the target
-	 *      should be a directory so we can save all the files into it.
+	 *   2. Someone provided more than one source (or a glob pattern matching
+	 *      more than one file). In this case the target should be a directory
+	 *      so we can save all the files into it.
 	 *   3. We are dealing with recursive copy
 	 */
 	if err == nil {
 		targetIsDir = sb.IsDir()
-		if !targetIsDir && len(args)-1 > 1 {
+		if !targetIsDir && len(sources) > 1 {
 			return fmt.Errorf(i18n.G("More than one file to download, but target is not a directory"))
 		}
-	} else if strings.HasSuffix(args[len(args)-1], string(os.PathSeparator)) || len(args)-1 > 1 {
+	} else if strings.HasSuffix(args[len(args)-1], string(os.PathSeparator)) || len(sources) > 1 {
 		err := os.MkdirAll(target, DirMode)
 		if err != nil {
 			return err
@@ -298,156 +345,161 @@ func (c *cmdFilePull) run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Parse remote
-	resources, err := c.global.ParseServers(args[:len(args)-1]...)
-	if err != nil {
-		return err
+	// Progress output of concurrent transfers would be interleaved, so only show it for serial transfers.
+	parallel := c.flagParallel
+	if target == "-" {
+		parallel = 1
 	}
 
-	reverter := revert.New()
-	defer reverter.Fail()
-
-	for _, resource := range resources {
-		pathSpec := strings.SplitN(resource.name, "/", 2)
-		if len(pathSpec) != 2 {
-			return fmt.Errorf(i18n.G("Invalid source %s"), resource.name)
+	if parallel == 1 || len(sources) == 1 {
+		for _, resource := range sources {
+			err := c.pullFile(resource, target, targetIsDir, c.global.flagQuiet)
+			if err != nil {
+				return err
+			}
 		}
 
-		buf, resp, err := fileGetWrapper(resource.server, pathSpec[0], pathSpec[1])
-		if err != nil {
-			return err
-		}
+		return nil
+	}
 
-		// Deal with recursion
-		if resp.Type == "directory" {
-			if c.file.flagRecursive {
-				if !shared.PathExists(target) {
-					err := os.MkdirAll(target, DirMode)
-					if err != nil {
-						return err
-					}
+	g := errgroup.Group{}
+	g.SetLimit(parallel)
 
-					targetIsDir = true
-				}
+	for _, resource := range sources {
+		g.Go(func() error {
+			return c.pullFile(resource, target, targetIsDir, true)
+		})
+	}
 
-				err := c.file.recursivePullFile(resource.server, pathSpec[0], pathSpec[1], target)
-				if err != nil {
-					return err
-				}
+	return g.Wait()
+}
 
-				continue
-			} else {
-				return fmt.Errorf(i18n.G("Can't pull a directory without --recursive"))
-			}
-		}
+// pullFile pulls a single source into the target.
+func (c *cmdFilePull) pullFile(resource remoteResource, target string, targetIsDir bool, quiet bool) error {
+	pathSpec := strings.SplitN(resource.name, "/", 2)
 
-		var targetPath string
-		if targetIsDir {
-			targetPath = path.Join(target, path.Base(pathSpec[1]))
-		} else {
-			targetPath = target
-		}
+	buf, resp, err := fileGetWrapper(resource.server, pathSpec[0], pathSpec[1])
+	if err != nil {
+		return err
+	}
 
-		logger.Infof("Pulling %s from %s (%s)", targetPath, pathSpec[1], resp.Type)
+	// Deal with recursion
+	if resp.Type == "directory" {
+		if !c.file.flagRecursive {
+			return fmt.Errorf(i18n.G("Can't pull a directory without --recursive"))
+		}
 
-		if resp.Type == "symlink" {
-			linkTarget, err := io.ReadAll(buf)
+		if !shared.PathExists(target) {
+			err := os.MkdirAll(target, DirMode)
 			if err != nil {
 				return err
 			}
+		}
 
-			// Follow the symlink
-			if !(targetPath == "-" || c.file.flagRecursive) {
-				err = os.Symlink(strings.TrimSpace(string(linkTarget)), targetPath)
-				if err != nil {
-					return err
-				}
-
-				continue
-			}
+		return c.file.recursivePullFile(resource.server, pathSpec[0], pathSpec[1], target)
+	}
 
-			i := 0
-			for {
-				newPath := strings.TrimSuffix(string(linkTarget), "\n")
-				if !strings.HasPrefix(newPath, "/") {
-					newPath = filepath.Clean(filepath.Join(filepath.Dir(pathSpec[1]), newPath))
-				}
+	var targetPath string
+	if targetIsDir {
+		targetPath = path.Join(target, path.Base(pathSpec[1]))
+	} else {
+		targetPath = target
+	}
 
-				buf, resp, err = resource.server.GetInstanceFile(pathSpec[0], newPath)
-				if err != nil {
-					return err
-				}
+	logger.Infof("Pulling %s from %s (%s)", targetPath, pathSpec[1], resp.Type)
 
-				if resp.Type != "symlink" {
-					break
-				}
+	if resp.Type == "symlink" {
+		linkTarget, err := io.ReadAll(buf)
+		if err != nil {
+			return err
+		}
 
-				i++
-				if i > 255 {
-					return fmt.Errorf("Too many links")
-				}
+		// Follow the symlink
+		if !(targetPath == "-" || c.file.flagRecursive) {
+			return os.Symlink(strings.TrimSpace(string(linkTarget)), targetPath)
+		}
 
-				// Update link target for next iteration.
-				linkTarget, err = io.ReadAll(buf)
-				if err != nil {
-					return err
-				}
+		i := 0
+		for {
+			newPath := strings.TrimSuffix(string(linkTarget), "\n")
+			if !strings.HasPrefix(newPath, "/") {
+				newPath = filepath.Clean(filepath.Join(filepath.Dir(pathSpec[1]), newPath))
 			}
-		}
 
-		var f *os.File
-		if targetPath == "-" {
-			f = os.Stdout
-		} else {
-			f, err = os.Create(targetPath)
+			buf, resp, err = resource.server.GetInstanceFile(pathSpec[0], newPath)
 			if err != nil {
 				return err
 			}
 
-			reverter.Add(func() { _ = f.Close() })
+			if resp.Type != "symlink" {
+				break
+			}
+
+			i++
+			if i > 255 {
+				return fmt.Errorf("Too many links")
+			}
 
-			err = os.Chmod(targetPath, os.FileMode(resp.Mode))
+			// Update link target for next iteration.
+			linkTarget, err = io.ReadAll(buf)
 			if err != nil {
 				return err
 			}
 		}
+	}
 
-		progress := cli.ProgressRenderer{
-			Format: fmt.Sprintf(i18n.G("Pulling %s from %s: %%s"), targetPath, pathSpec[1]),
-			Quiet:  c.global.flagQuiet,
-		}
-
-		writer := &ioprogress.ProgressWriter{
-			WriteCloser: f,
-			Tracker: &ioprogress.ProgressTracker{
-				Handler: func(bytesReceived int64, speed int64) {
-					if targetPath == "-" {
-						return
-					}
-
-					progress.UpdateProgress(ioprogress.ProgressData{
-						Text: fmt.Sprintf("%s (%s/s)",
-							units.GetByteSizeString(bytesReceived, 2),
-							units.GetByteSizeString(speed, 2))})
-				},
-			},
-		}
-
-		_, err = io.Copy(writer, buf)
+	var f *os.File
+	if targetPath == "-" {
+		f = os.Stdout
+	} else {
+		f, err = os.Create(targetPath)
 		if err != nil {
-			progress.Done("")
 			return err
 		}
 
-		err = f.Close()
+		defer func() { _ = f.Close() }()
+
+		err = os.Chmod(targetPath, os.FileMode(resp.Mode))
 		if err != nil {
-			progress.Done("")
 			return err
 		}
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: fmt.Sprintf(i18n.G("Pulling %s from %s: %%s"), targetPath, pathSpec[1]),
+		Quiet:  quiet,
+	}
+
+	writer := &ioprogress.ProgressWriter{
+		WriteCloser: f,
+		Tracker: &ioprogress.ProgressTracker{
+			Handler: func(bytesReceived int64, speed int64) {
+				if targetPath == "-" {
+					return
+				}
+
+				progress.UpdateProgress(ioprogress.ProgressData{
+					Text: fmt.Sprintf("%s (%s/s)",
+						units.GetByteSizeString(bytesReceived, 2),
+						units.GetByteSizeString(speed, 2))})
+			},
+		},
+	}
 
+	_, err = io.Copy(writer, buf)
+	if err != nil {
 		progress.Done("")
+		return err
 	}
 
+	err = f.Close()
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
 	return nil
 }
 
@@ -1057,26 +1109,35 @@ func (c *cmdFileMount) run(cmd *cobra.Command, args []string) error {
 		// Setup sourcePath with leading / to ensure we reference the instance path from / location.
 		instPath := filepath.Join(string(filepath.Separator), filepath.Clean(instSpec[1]))
 
+		sftpConn, err := resource.server.GetInstanceFileSFTPConn(instName)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed connecting to instance SFTP: %w"), err)
+		}
+
+		defer func() { _ = sftpConn.Close() }()
+
 		// If sshfs command is found, use it to mount the SFTP connection to the targetPath.
-		return c.sshfsMount(cmd.Context(), resource, instName, instPath, sshfsPath, targetPath)
+		return c.sshfsMount(cmd.Context(), sftpConn, instName, instPath, sshfsPath, targetPath)
 	}
 
-	// If SSH SFTP listener specified or no target mount path specified, then use SSH SFTP server.
-	return c.sshSFTPServer(cmd.Context(), instName, resource)
-}
-
-// sshfsMount mounts the instance's filesystem using sshfs by piping the instance's SFTP connection to sshfs.
-func (c *cmdFileMount) sshfsMount(ctx context.Context, resource remoteResource, instName string, instPath string, sshfsPath string, targetPath string) error {
-	sftpConn, err := resource.server.GetInstanceFileSFTPConn(instName)
+	// Check instance exists.
+	_, _, err = resource.server.GetInstance(instName)
 	if err != nil {
-		return fmt.Errorf(i18n.G("Failed connecting to instance SFTP: %w"), err)
+		return err
 	}
 
-	defer func() { _ = sftpConn.Close() }()
+	// If SSH SFTP listener specified or no target mount path specified, then use SSH SFTP server.
+	return c.sshSFTPServer(cmd.Context(), func() (net.Conn, error) {
+		return resource.server.GetInstanceFileSFTPConn(instName)
+	})
+}
 
+// sshfsMount mounts a filesystem using sshfs by piping the given SFTP connection to sshfs.
+// The sourceName is the name of the instance (or volume) the SFTP connection belongs to.
+func (c *cmdFileMount) sshfsMount(ctx context.Context, sftpConn net.Conn, sourceName string, sourcePath string, sshfsPath string, targetPath string) error {
 	// Use the format "lxd.<instance_name>" as the source "host" (although not used for communication)
 	// so that the mount can be seen to be associated with LXD and the instance in the local mount table.
-	sourceURL := fmt.Sprintf("lxd.%s:%s", instName, instPath)
+	sourceURL := fmt.Sprintf("lxd.%s:%s", sourceName, sourcePath)
 
 	sshfsCmd := exec.Command(sshfsPath, "-o", "slave", sourceURL, targetPath)
 
@@ -1098,7 +1159,7 @@ func (c *cmdFileMount) sshfsMount(ctx context.Context, resource remoteResource,
 		return fmt.Errorf(i18n.G("Failed starting sshfs: %w"), err)
 	}
 
-	fmt.Printf(i18n.G("sshfs mounting %q on %q")+"\n", fmt.Sprintf("%s%s", instName, instPath), targetPath)
+	fmt.Printf(i18n.G("sshfs mounting %q on %q")+"\n", fmt.Sprintf("%s%s", sourceName, sourcePath), targetPath)
 	fmt.Println(i18n.G("Press ctrl+c to finish"))
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -1145,14 +1206,8 @@ func (c *cmdFileMount) sshfsMount(ctx context.Context, resource remoteResource,
 }
 
 // sshSFTPServer runs an SSH server listening on a random port of 127.0.0.1.
-// It provides an unauthenticated SFTP server connected to the instance's filesystem.
-func (c *cmdFileMount) sshSFTPServer(ctx context.Context, instName string, resource remoteResource) error {
-	// Check instance exists.
-	_, _, err := resource.server.GetInstance(instName)
-	if err != nil {
-		return err
-	}
-
+// It provides an unauthenticated SFTP server connected to the SFTP connections returned by connect.
+func (c *cmdFileMount) sshSFTPServer(ctx context.Context, connect func() (net.Conn, error)) error {
 	randString := func(length int) string {
 		var chars = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0987654321")
 		randStr := make([]rune, length)
@@ -1282,7 +1337,7 @@ func (c *cmdFileMount) sshSFTPServer(ctx context.Context, instName string, resou
 					defer func() { _ = channel.Close() }()
 
 					// Connect to the instance's SFTP server.
-					sftpConn, err := resource.server.GetInstanceFileSFTPConn(instName)
+					sftpConn, err := connect()
 					if err != nil {
 						fmt.Fprintf(os.Stderr, i18n.G("Failed connecting to instance SFTP for client %q: %v")+"\n", nConn.RemoteAddr(), err)
 						return
@@ -83,10 +83,18 @@ hash or alias name (if one is set).`))
 	imageListCmd := cmdImageList{global: c.global, image: c}
 	cmd.AddCommand(imageListCmd.command())
 
+	// List expired
+	imageListExpiredCmd := cmdImageListExpired{global: c.global, image: c}
+	cmd.AddCommand(imageListExpiredCmd.command())
+
 	// Refresh
 	imageRefreshCmd := cmdImageRefresh{global: c.global, image: c}
 	cmd.AddCommand(imageRefreshCmd.command())
 
+	// Repair
+	imageRepairCmd := cmdImageRepair{global: c.global, image: c}
+	cmd.AddCommand(imageRepairCmd.command())
+
 	// Show
 	imageShowCmd := cmdImageShow{global: c.global, image: c}
 	cmd.AddCommand(imageShowCmd.command())
@@ -891,7 +899,8 @@ type cmdImageInfo struct {
 	global *cmdGlobal
 	image  *cmdImage
 
-	flagVM bool
+	flagVM     bool
+	flagFormat string
 }
 
 func (c *cmdImageInfo) command() *cobra.Command {
@@ -902,6 +911,7 @@ func (c *cmdImageInfo) command() *cobra.Command {
 		`Show useful information about images`))
 
 	cmd.Flags().BoolVar(&c.flagVM, "vm", false, i18n.G("Query virtual machine images"))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
 	cmd.RunE = c.run
 
 	return cmd
@@ -936,6 +946,10 @@ func (c *cmdImageInfo) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	return cli.RenderInfo(c.flagFormat, info, func() error { return c.renderInfo(info) })
+}
+
+func (c *cmdImageInfo) renderInfo(info *api.Image) error {
 	public := i18n.G("no")
 	if info.Public {
 		public = i18n.G("yes")
@@ -1341,6 +1355,91 @@ func (c *cmdImageList) run(cmd *cobra.Command, args []string) error {
 	return cli.RenderTable(c.flagFormat, headers, data, rawData)
 }
 
+// List expired.
+type cmdImageListExpired struct {
+	global *cmdGlobal
+	image  *cmdImage
+
+	flagExpiry string
+	flagFormat string
+}
+
+func (c *cmdImageListExpired) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-expired", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("List the expired cached images")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List the expired cached images
+
+The cached images listed are deleted by the next image cache expiry run.
+Use --expiry to preview the effect of a different images.remote_cache_expiry value.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc image list-expired
+    List the cached images of the current project that expired.
+
+lxc image list-expired --expiry=5
+    List the cached images of the current project that would expire when unused for 5 days.`))
+
+	cmd.Flags().StringVar(&c.flagExpiry, "expiry", "", i18n.G("Number of days after which unused cached images expire")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdImageListExpired) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
+	}
+
+	images, err := resource.server.GetImageCacheExpiry(c.flagExpiry)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04 MST"
+
+	data := [][]string{}
+	for _, image := range images {
+		lastUsed := ""
+		if !image.LastUsedAt.IsZero() {
+			lastUsed = image.LastUsedAt.UTC().Format(layout)
+		}
+
+		data = append(data, []string{image.Fingerprint[0:12], image.Project, lastUsed, image.ExpiresAt.UTC().Format(layout)})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("FINGERPRINT"),
+		i18n.G("PROJECT"),
+		i18n.G("LAST USED AT"),
+		i18n.G("EXPIRED AT"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, images)
+}
+
 // Refresh.
 type cmdImageRefresh struct {
 	global *cmdGlobal
@@ -1426,6 +1525,148 @@ func (c *cmdImageRefresh) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Repair.
+type cmdImageRepair struct {
+	global *cmdGlobal
+	image  *cmdImage
+
+	flagCheck bool
+}
+
+func (c *cmdImageRepair) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("repair", i18n.G("[<remote>:][<image>...]"))
+	cmd.Short = i18n.G("Repair the replication of images across cluster members")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Repair the replication of images across cluster members
+
+Images that aren't available on enough cluster members are copied to the members missing them.
+If no image is specified, all the images of the project are checked.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc image repair
+    Repair the replication of all the images.
+
+lxc image repair ubuntu --check
+    Show on which cluster members the image with alias "ubuntu" is available without repairing it.`))
+
+	cmd.Flags().BoolVar(&c.flagCheck, "check", false, i18n.G("Only show the replication status of the images"))
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdImageRepair) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, -1)
+	if exit {
+		return err
+	}
+
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args...)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		if !resource.server.IsClustered() {
+			return fmt.Errorf(i18n.G("The server isn't clustered"))
+		}
+
+		var fingerprints []string
+		if resource.name == "" {
+			fingerprints, err = resource.server.GetImageFingerprints()
+			if err != nil {
+				return err
+			}
+		} else {
+			image, _, err := c.image.dereferenceAlias(resource.server, "", resource.name)
+			if err != nil {
+				return err
+			}
+
+			fingerprints = []string{image.Fingerprint}
+		}
+
+		for _, fingerprint := range fingerprints {
+			err = c.repair(resource.server, fingerprint)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// repair shows the replication status of the image and repairs it if needed.
+func (c *cmdImageRepair) repair(server lxd.InstanceServer, fingerprint string) error {
+	status, err := server.GetImageSyncStatus(fingerprint)
+	if err != nil {
+		return err
+	}
+
+	available := 0
+	for _, member := range status.Members {
+		if member.Available {
+			available++
+		}
+	}
+
+	fmt.Printf(i18n.G("Image %s is available on %d cluster members (%d desired)")+"\n", fingerprint[0:12], available, status.DesiredReplicas)
+
+	if c.flagCheck {
+		for _, member := range status.Members {
+			state := i18n.G("available")
+			if !member.Available {
+				state = i18n.G("missing")
+			}
+
+			if !member.Online {
+				state = fmt.Sprintf(i18n.G("%s, offline"), state)
+			}
+
+			fmt.Printf("  - %s: %s\n", member.Name, state)
+		}
+
+		return nil
+	}
+
+	if status.Synced {
+		return nil
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Replicating the image: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	op, err := server.RepairImageSync(fingerprint)
+	if err != nil {
+		return err
+	}
+
+	// Register progress handler
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done(i18n.G("Image replication repaired"))
+
+	return nil
+}
+
 // Show.
 type cmdImageShow struct {
 	global *cmdGlobal
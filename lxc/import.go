@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 	"strings"
 
@@ -11,8 +10,6 @@ import (
 	"github.com/canonical/lxd/shared"
 	cli "github.com/canonical/lxd/shared/cmd"
 	"github.com/canonical/lxd/shared/i18n"
-	"github.com/canonical/lxd/shared/ioprogress"
-	"github.com/canonical/lxd/shared/units"
 )
 
 type cmdImport struct {
@@ -103,18 +100,12 @@ func (c *cmdImport) run(cmd *cobra.Command, args []string) error {
 	}
 
 	createArgs := lxd.InstanceBackupArgs{
-		BackupFile: &ioprogress.ProgressReader{
-			ReadCloser: file,
-			Tracker: &ioprogress.ProgressTracker{
-				Length: fstat.Size(),
-				Handler: func(percent int64, speed int64) {
-					progress.UpdateProgress(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
-				},
-			},
-		},
-		PoolName: c.flagStorage,
-		Name:     instanceName,
-		Devices:  deviceMap,
+		BackupFile:      file,
+		Size:            fstat.Size(),
+		ProgressHandler: progress.UpdateProgress,
+		PoolName:        c.flagStorage,
+		Name:            instanceName,
+		Devices:         deviceMap,
 	}
 
 	op, err := resource.server.CreateInstanceFromBackup(createArgs)
@@ -3,9 +3,11 @@ package main
 import (
 	"fmt"
 	"io"
+	"os"
 	"sort"
 	"strings"
 
+	"github.com/gorilla/websocket"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 
@@ -21,8 +23,10 @@ type cmdInfo struct {
 	global *cmdGlobal
 
 	flagShowLog   bool
+	flagFollowLog bool
 	flagResources bool
 	flagTarget    string
+	flagFormat    string
 }
 
 func (c *cmdInfo) command() *cobra.Command {
@@ -35,13 +39,18 @@ func (c *cmdInfo) command() *cobra.Command {
 		`lxc info [<remote>:]<instance> [--show-log]
     For instance information.
 
+lxc info [<remote>:]<instance> --follow-log
+    For instance information, followed by the instance's log as it grows.
+
 lxc info [<remote>:] [--resources]
     For LXD server information.`))
 
 	cmd.RunE = c.run
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Show the instance's last 100 log lines?"))
+	cmd.Flags().BoolVar(&c.flagFollowLog, "follow-log", false, i18n.G("Show the instance's last 100 log lines and follow the log"))
 	cmd.Flags().BoolVar(&c.flagResources, "resources", false, i18n.G("Show the resources available to the server"))
 	cmd.Flags().StringVar(&c.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
 
 	return cmd
 }
@@ -100,6 +109,10 @@ func (c *cmdInfo) renderGPU(gpu api.ResourcesGPUCard, prefix string, initial boo
 		fmt.Printf(prefix+i18n.G("PCI address: %v")+"\n", gpu.PCIAddress)
 	}
 
+	if gpu.Link != nil && gpu.Link.CurrentWidth > 0 {
+		fmt.Printf(prefix+i18n.G("PCIe link: %v GT/s x%d (max %v GT/s x%d)")+"\n", gpu.Link.CurrentSpeed, gpu.Link.CurrentWidth, gpu.Link.MaximumSpeed, gpu.Link.MaximumWidth)
+	}
+
 	if gpu.Driver != "" {
 		fmt.Printf(prefix+i18n.G("Driver: %v (%v)")+"\n", gpu.Driver, gpu.DriverVersion)
 	}
@@ -135,6 +148,22 @@ func (c *cmdInfo) renderGPU(gpu api.ResourcesGPUCard, prefix string, initial boo
 		fmt.Printf(prefix + i18n.G("SR-IOV information:") + "\n")
 		fmt.Printf(prefix+"  "+i18n.G("Current number of VFs: %d")+"\n", gpu.SRIOV.CurrentVFs)
 		fmt.Printf(prefix+"  "+i18n.G("Maximum number of VFs: %d")+"\n", gpu.SRIOV.MaximumVFs)
+		fmt.Printf(prefix+"  "+i18n.G("Available VFs: %d")+"\n", gpu.SRIOV.AvailableVFs)
+		if len(gpu.SRIOV.Allocations) > 0 {
+			fmt.Printf(prefix + "  " + i18n.G("Allocations:") + "\n")
+
+			vfAddresses := make([]string, 0, len(gpu.SRIOV.Allocations))
+			for vfAddress := range gpu.SRIOV.Allocations {
+				vfAddresses = append(vfAddresses, vfAddress)
+			}
+
+			sort.Strings(vfAddresses)
+
+			for _, vfAddress := range vfAddresses {
+				fmt.Printf(prefix+"    - %s (%s)\n", vfAddress, gpu.SRIOV.Allocations[vfAddress])
+			}
+		}
+
 		if len(gpu.SRIOV.VFs) > 0 {
 			fmt.Printf(prefix+"  "+i18n.G("VFs: %d")+"\n", gpu.SRIOV.MaximumVFs)
 			for _, vf := range gpu.SRIOV.VFs {
@@ -163,6 +192,80 @@ func (c *cmdInfo) renderGPU(gpu api.ResourcesGPUCard, prefix string, initial boo
 					fmt.Printf(prefix+"      %s\n", line)
 				}
 			}
+
+			if len(v.Allocations) > 0 {
+				fmt.Printf(prefix + "    " + i18n.G("Allocations:") + "\n")
+
+				mdevUUIDs := make([]string, 0, len(v.Allocations))
+				for mdevUUID := range v.Allocations {
+					mdevUUIDs = append(mdevUUIDs, mdevUUID)
+				}
+
+				sort.Strings(mdevUUIDs)
+
+				for _, mdevUUID := range mdevUUIDs {
+					fmt.Printf(prefix+"      - %s (%s)\n", mdevUUID, v.Allocations[mdevUUID])
+				}
+			}
+		}
+	}
+}
+
+// renderGPUCapacity prints a summary of the SR-IOV virtual functions and mediated devices that can still be
+// allocated on each GPU.
+func (c *cmdInfo) renderGPUCapacity(cards []api.ResourcesGPUCard) {
+	type mdevCapacity struct {
+		free uint64
+		used int
+	}
+
+	header := false
+	for id, card := range cards {
+		// Aggregate the mdev profiles of the card and of its VFs.
+		mdevs := make(map[string]*mdevCapacity)
+		addMdevs := func(mdev map[string]api.ResourcesGPUCardMdev) {
+			for profile, entry := range mdev {
+				_, ok := mdevs[profile]
+				if !ok {
+					mdevs[profile] = &mdevCapacity{}
+				}
+
+				mdevs[profile].free += entry.Available
+				mdevs[profile].used += len(entry.Devices)
+			}
+		}
+
+		addMdevs(card.Mdev)
+		if card.SRIOV != nil {
+			for _, vf := range card.SRIOV.VFs {
+				addMdevs(vf.Mdev)
+			}
+		}
+
+		if card.SRIOV == nil && len(mdevs) == 0 {
+			continue
+		}
+
+		if !header {
+			fmt.Printf("\n" + i18n.G("GPU capacity:") + "\n")
+			header = true
+		}
+
+		fmt.Printf("  "+i18n.G("Card %d (%s):")+"\n", id, card.PCIAddress)
+
+		if card.SRIOV != nil {
+			fmt.Printf("    "+i18n.G("VFs: %d free, %d used (%d max)")+"\n", card.SRIOV.AvailableVFs, card.SRIOV.CurrentVFs-card.SRIOV.AvailableVFs, card.SRIOV.MaximumVFs)
+		}
+
+		profiles := make([]string, 0, len(mdevs))
+		for profile := range mdevs {
+			profiles = append(profiles, profile)
+		}
+
+		sort.Strings(profiles)
+
+		for _, profile := range profiles {
+			fmt.Printf("    "+i18n.G("%s: %d free, %d used")+"\n", profile, mdevs[profile].free, mdevs[profile].used)
 		}
 	}
 }
@@ -186,6 +289,10 @@ func (c *cmdInfo) renderNIC(nic api.ResourcesNetworkCard, prefix string, initial
 		fmt.Printf(prefix+i18n.G("PCI address: %v")+"\n", nic.PCIAddress)
 	}
 
+	if nic.Link != nil && nic.Link.CurrentWidth > 0 {
+		fmt.Printf(prefix+i18n.G("PCIe link: %v GT/s x%d (max %v GT/s x%d)")+"\n", nic.Link.CurrentSpeed, nic.Link.CurrentWidth, nic.Link.MaximumSpeed, nic.Link.MaximumWidth)
+	}
+
 	if nic.Driver != "" {
 		fmt.Printf(prefix+i18n.G("Driver: %v (%v)")+"\n", nic.Driver, nic.DriverVersion)
 	}
@@ -244,6 +351,7 @@ func (c *cmdInfo) renderNIC(nic api.ResourcesNetworkCard, prefix string, initial
 		fmt.Printf(prefix + i18n.G("SR-IOV information:") + "\n")
 		fmt.Printf(prefix+"  "+i18n.G("Current number of VFs: %d")+"\n", nic.SRIOV.CurrentVFs)
 		fmt.Printf(prefix+"  "+i18n.G("Maximum number of VFs: %d")+"\n", nic.SRIOV.MaximumVFs)
+		fmt.Printf(prefix+"  "+i18n.G("Available VFs: %d")+"\n", nic.SRIOV.AvailableVFs)
 		if len(nic.SRIOV.VFs) > 0 {
 			fmt.Printf(prefix+"  "+i18n.G("VFs: %d")+"\n", nic.SRIOV.MaximumVFs)
 			for _, vf := range nic.SRIOV.VFs {
@@ -350,98 +458,106 @@ func (c *cmdInfo) remoteInfo(d lxd.InstanceServer) error {
 			return err
 		}
 
-		// CPU
-		if len(resources.CPU.Sockets) == 1 {
-			fmt.Printf(i18n.G("CPU (%s):")+"\n", resources.CPU.Architecture)
-			c.renderCPU(resources.CPU.Sockets[0], "  ")
-		} else if len(resources.CPU.Sockets) > 1 {
-			fmt.Printf(i18n.G("CPUs (%s):")+"\n", resources.CPU.Architecture)
-			for _, cpu := range resources.CPU.Sockets {
-				fmt.Printf("  "+i18n.G("Socket %d:")+"\n", cpu.Socket)
-				c.renderCPU(cpu, "    ")
-			}
-		}
-
-		// Memory
-		fmt.Printf("\n" + i18n.G("Memory:") + "\n")
-		if resources.Memory.HugepagesTotal > 0 {
-			fmt.Printf("  " + i18n.G("Hugepages:"+"\n"))
-			fmt.Printf("    "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.HugepagesTotal-resources.Memory.HugepagesUsed), 2))
-			fmt.Printf("    "+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.HugepagesUsed), 2))
-			fmt.Printf("    "+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.HugepagesTotal), 2))
-		}
-
-		if len(resources.Memory.Nodes) > 1 {
-			fmt.Printf("  " + i18n.G("NUMA nodes:"+"\n"))
-			for _, node := range resources.Memory.Nodes {
-				fmt.Printf("    "+i18n.G("Node %d:"+"\n"), node.NUMANode)
-				if node.HugepagesTotal > 0 {
-					fmt.Printf("      " + i18n.G("Hugepages:"+"\n"))
-					fmt.Printf("        "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(node.HugepagesTotal-node.HugepagesUsed), 2))
-					fmt.Printf("        "+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(int64(node.HugepagesUsed), 2))
-					fmt.Printf("        "+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(int64(node.HugepagesTotal), 2))
-				}
+		return cli.RenderInfo(c.flagFormat, resources, func() error { return c.renderResources(resources) })
+	}
 
-				fmt.Printf("      "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(node.Total-node.Used), 2))
-				fmt.Printf("      "+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(int64(node.Used), 2))
-				fmt.Printf("      "+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(int64(node.Total), 2))
-			}
+	serverStatus, _, err := d.GetServer()
+	if err != nil {
+		return err
+	}
+
+	return cli.RenderInfo(c.flagFormat, serverStatus, func() error {
+		data, err := yaml.Marshal(&serverStatus)
+		if err != nil {
+			return err
 		}
 
-		fmt.Printf("  "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Total-resources.Memory.Used), 2))
-		fmt.Printf("  "+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Used), 2))
-		fmt.Printf("  "+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Total), 2))
+		fmt.Printf("%s", data)
 
-		// GPUs
-		if len(resources.GPU.Cards) == 1 {
-			fmt.Printf("\n" + i18n.G("GPU:") + "\n")
-			c.renderGPU(resources.GPU.Cards[0], "  ", true)
-		} else if len(resources.GPU.Cards) > 1 {
-			fmt.Printf("\n" + i18n.G("GPUs:") + "\n")
-			for id, gpu := range resources.GPU.Cards {
-				fmt.Printf("  "+i18n.G("Card %d:")+"\n", id)
-				c.renderGPU(gpu, "    ", true)
-			}
-		}
+		return nil
+	})
+}
 
-		// Network interfaces
-		if len(resources.Network.Cards) == 1 {
-			fmt.Printf("\n" + i18n.G("NIC:") + "\n")
-			c.renderNIC(resources.Network.Cards[0], "  ", true)
-		} else if len(resources.Network.Cards) > 1 {
-			fmt.Printf("\n" + i18n.G("NICs:") + "\n")
-			for id, nic := range resources.Network.Cards {
-				fmt.Printf("  "+i18n.G("Card %d:")+"\n", id)
-				c.renderNIC(nic, "    ", true)
+func (c *cmdInfo) renderResources(resources *api.Resources) error {
+	// CPU
+	if len(resources.CPU.Sockets) == 1 {
+		fmt.Printf(i18n.G("CPU (%s):")+"\n", resources.CPU.Architecture)
+		c.renderCPU(resources.CPU.Sockets[0], "  ")
+	} else if len(resources.CPU.Sockets) > 1 {
+		fmt.Printf(i18n.G("CPUs (%s):")+"\n", resources.CPU.Architecture)
+		for _, cpu := range resources.CPU.Sockets {
+			fmt.Printf("  "+i18n.G("Socket %d:")+"\n", cpu.Socket)
+			c.renderCPU(cpu, "    ")
+		}
+	}
+
+	// Memory
+	fmt.Printf("\n" + i18n.G("Memory:") + "\n")
+	if resources.Memory.HugepagesTotal > 0 {
+		fmt.Printf("  " + i18n.G("Hugepages:"+"\n"))
+		fmt.Printf("    "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.HugepagesTotal-resources.Memory.HugepagesUsed), 2))
+		fmt.Printf("    "+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.HugepagesUsed), 2))
+		fmt.Printf("    "+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.HugepagesTotal), 2))
+	}
+
+	if len(resources.Memory.Nodes) > 1 {
+		fmt.Printf("  " + i18n.G("NUMA nodes:"+"\n"))
+		for _, node := range resources.Memory.Nodes {
+			fmt.Printf("    "+i18n.G("Node %d:"+"\n"), node.NUMANode)
+			if node.HugepagesTotal > 0 {
+				fmt.Printf("      " + i18n.G("Hugepages:"+"\n"))
+				fmt.Printf("        "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(node.HugepagesTotal-node.HugepagesUsed), 2))
+				fmt.Printf("        "+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(int64(node.HugepagesUsed), 2))
+				fmt.Printf("        "+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(int64(node.HugepagesTotal), 2))
 			}
-		}
 
-		// Storage
-		if len(resources.Storage.Disks) == 1 {
-			fmt.Printf("\n" + i18n.G("Disk:") + "\n")
-			c.renderDisk(resources.Storage.Disks[0], "  ", true)
-		} else if len(resources.Storage.Disks) > 1 {
-			fmt.Printf("\n" + i18n.G("Disks:") + "\n")
-			for id, nic := range resources.Storage.Disks {
-				fmt.Printf("  "+i18n.G("Disk %d:")+"\n", id)
-				c.renderDisk(nic, "    ", true)
-			}
+			fmt.Printf("      "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(node.Total-node.Used), 2))
+			fmt.Printf("      "+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(int64(node.Used), 2))
+			fmt.Printf("      "+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(int64(node.Total), 2))
 		}
-
-		return nil
 	}
 
-	serverStatus, _, err := d.GetServer()
-	if err != nil {
-		return err
+	fmt.Printf("  "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Total-resources.Memory.Used), 2))
+	fmt.Printf("  "+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Used), 2))
+	fmt.Printf("  "+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Total), 2))
+
+	// GPUs
+	if len(resources.GPU.Cards) == 1 {
+		fmt.Printf("\n" + i18n.G("GPU:") + "\n")
+		c.renderGPU(resources.GPU.Cards[0], "  ", true)
+	} else if len(resources.GPU.Cards) > 1 {
+		fmt.Printf("\n" + i18n.G("GPUs:") + "\n")
+		for id, gpu := range resources.GPU.Cards {
+			fmt.Printf("  "+i18n.G("Card %d:")+"\n", id)
+			c.renderGPU(gpu, "    ", true)
+		}
 	}
 
-	data, err := yaml.Marshal(&serverStatus)
-	if err != nil {
-		return err
+	c.renderGPUCapacity(resources.GPU.Cards)
+
+	// Network interfaces
+	if len(resources.Network.Cards) == 1 {
+		fmt.Printf("\n" + i18n.G("NIC:") + "\n")
+		c.renderNIC(resources.Network.Cards[0], "  ", true)
+	} else if len(resources.Network.Cards) > 1 {
+		fmt.Printf("\n" + i18n.G("NICs:") + "\n")
+		for id, nic := range resources.Network.Cards {
+			fmt.Printf("  "+i18n.G("Card %d:")+"\n", id)
+			c.renderNIC(nic, "    ", true)
+		}
 	}
 
-	fmt.Printf("%s", data)
+	// Storage
+	if len(resources.Storage.Disks) == 1 {
+		fmt.Printf("\n" + i18n.G("Disk:") + "\n")
+		c.renderDisk(resources.Storage.Disks[0], "  ", true)
+	} else if len(resources.Storage.Disks) > 1 {
+		fmt.Printf("\n" + i18n.G("Disks:") + "\n")
+		for id, nic := range resources.Storage.Disks {
+			fmt.Printf("  "+i18n.G("Disk %d:")+"\n", id)
+			c.renderDisk(nic, "    ", true)
+		}
+	}
 
 	return nil
 }
@@ -458,6 +574,10 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, name string, showLog bool)
 		return err
 	}
 
+	return cli.RenderInfo(c.flagFormat, inst, func() error { return c.renderInstanceInfo(d, inst, showLog) })
+}
+
+func (c *cmdInfo) renderInstanceInfo(d lxd.InstanceServer, inst *api.InstanceFull, showLog bool) error {
 	const layout = "2006/01/02 15:04 MST"
 
 	fmt.Printf(i18n.G("Name: %s")+"\n", inst.Name)
@@ -518,6 +638,10 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, name string, showLog bool)
 			cpuInfo += fmt.Sprintf("    %s: %v\n", i18n.G("CPU usage (in seconds)"), inst.State.CPU.Usage/1000000000)
 		}
 
+		if inst.State.CPU.CoreSchedulingCookie != 0 {
+			cpuInfo += fmt.Sprintf("    %s: %#x\n", i18n.G("Core scheduling cookie"), inst.State.CPU.CoreSchedulingCookie)
+		}
+
 		if cpuInfo != "" {
 			fmt.Printf("  %s\n", i18n.G("CPU usage:"))
 			fmt.Print(cpuInfo)
@@ -541,6 +665,20 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, name string, showLog bool)
 			memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Swap (peak)"), units.GetByteSizeStringIEC(inst.State.Memory.SwapUsagePeak, 2))
 		}
 
+		balloon := inst.State.Memory.Balloon
+		if balloon != nil {
+			memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Balloon (actual)"), units.GetByteSizeStringIEC(balloon.Actual, 2))
+			memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Balloon (target)"), units.GetByteSizeStringIEC(balloon.Target, 2))
+
+			if balloon.GuestFree >= 0 {
+				memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Guest free"), units.GetByteSizeStringIEC(balloon.GuestFree, 2))
+			}
+
+			if balloon.GuestAvailable >= 0 {
+				memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Guest available"), units.GetByteSizeStringIEC(balloon.GuestAvailable, 2))
+			}
+		}
+
 		if memoryInfo != "" {
 			fmt.Printf("  %s\n", i18n.G("Memory usage:"))
 			fmt.Print(memoryInfo)
@@ -687,15 +825,20 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, name string, showLog bool)
 		_ = cli.RenderTable(cli.TableFormatTable, backupHeader, backupData, inst.Backups)
 	}
 
+	if c.flagFollowLog {
+		return c.followLog(d, inst)
+	}
+
 	if showLog {
 		var log io.Reader
+		var err error
 		if inst.Type == "container" {
-			log, err = d.GetInstanceLogfile(name, "lxc.log")
+			log, err = d.GetInstanceLogfile(inst.Name, "lxc.log")
 			if err != nil {
 				return err
 			}
 		} else if inst.Type == "virtual-machine" {
-			log, err = d.GetInstanceLogfile(name, "qemu.log")
+			log, err = d.GetInstanceLogfile(inst.Name, "qemu.log")
 			if err != nil {
 				return err
 			}
@@ -713,3 +856,41 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, name string, showLog bool)
 
 	return nil
 }
+
+// followLog prints the last lines of the log of the instance and then the lines added to it until interrupted.
+func (c *cmdInfo) followLog(d lxd.InstanceServer, inst *api.InstanceFull) error {
+	var logFile string
+	switch inst.Type {
+	case "container":
+		logFile = "lxc.log"
+	case "virtual-machine":
+		logFile = "qemu.log"
+	default:
+		return fmt.Errorf(i18n.G("Unsupported instance type: %s"), inst.Type)
+	}
+
+	conn, err := d.GetInstanceLogfileFollow(inst.Name, logFile, 100)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	fmt.Printf("\n" + i18n.G("Log:") + "\n\n")
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+
+			return err
+		}
+
+		_, err = os.Stdout.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -34,6 +34,7 @@ type cmdInit struct {
 	flagNoProfiles bool
 	flagEmpty      bool
 	flagVM         bool
+	flagImportDisk string
 }
 
 func (c *cmdInit) command() *cobra.Command {
@@ -51,7 +52,10 @@ lxc init ubuntu:24.04 v1 --vm -c limits.cpu=4 -c limits.memory=4GiB
     Create a virtual machine with 4 vCPUs and 4GiB of RAM
 
 lxc init ubuntu:24.04 v1 --vm -c limits.cpu=2 -c limits.memory=8GiB -d root,size=32GiB
-    Create a virtual machine with 2 vCPUs, 8GiB of RAM and a root disk of 32GiB`))
+    Create a virtual machine with 2 vCPUs, 8GiB of RAM and a root disk of 32GiB
+
+lxc init v1 --empty --vm --import-disk ./disk.qcow2
+    Create a virtual machine from a local qcow2 or raw disk`))
 
 	cmd.RunE = c.run
 	cmd.Flags().StringArrayVarP(&c.flagConfig, "config", "c", nil, i18n.G("Config key/value to apply to the new instance")+"``")
@@ -65,6 +69,7 @@ lxc init ubuntu:24.04 v1 --vm -c limits.cpu=2 -c limits.memory=8GiB -d root,size
 	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, i18n.G("Create the instance with no profiles applied"))
 	cmd.Flags().BoolVar(&c.flagEmpty, "empty", false, i18n.G("Create an empty instance"))
 	cmd.Flags().BoolVar(&c.flagVM, "vm", false, i18n.G("Create a virtual machine"))
+	cmd.Flags().StringVar(&c.flagImportDisk, "import-disk", "", i18n.G("Import a local disk into the root volume of an empty virtual machine")+"``")
 
 	return cmd
 }
@@ -128,6 +133,10 @@ func (c *cmdInit) create(conf *config.Config, args []string) (lxd.InstanceServer
 		}
 	}
 
+	if c.flagImportDisk != "" && (!c.flagEmpty || !c.flagVM) {
+		return nil, "", fmt.Errorf(i18n.G("--import-disk requires --empty and --vm"))
+	}
+
 	if c.flagEmpty {
 		if len(args) > 1 {
 			return nil, "", fmt.Errorf(i18n.G("--empty cannot be combined with an image name"))
@@ -360,6 +369,51 @@ func (c *cmdInit) create(conf *config.Config, args []string) (lxd.InstanceServer
 		}
 
 		opInfo = *info
+	} else if c.flagImportDisk != "" {
+		file, err := os.Open(shared.HostPathFollow(c.flagImportDisk))
+		if err != nil {
+			return nil, "", err
+		}
+
+		defer func() { _ = file.Close() }()
+
+		fstat, err := file.Stat()
+		if err != nil {
+			return nil, "", err
+		}
+
+		progress := cli.ProgressRenderer{
+			Format: i18n.G("Importing disk: %s"),
+			Quiet:  c.global.flagQuiet,
+		}
+
+		diskArgs := lxd.InstanceDiskArgs{
+			Disk:            file,
+			Size:            fstat.Size(),
+			ProgressHandler: progress.UpdateProgress,
+		}
+
+		op, err := d.CreateInstanceFromDisk(req, diskArgs)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// The conversion progress is reported once the disk was sent.
+		_, err = op.AddHandler(progress.UpdateOp)
+		if err != nil {
+			progress.Done("")
+			return nil, "", err
+		}
+
+		err = cli.CancelableWait(op, &progress)
+		if err != nil {
+			progress.Done("")
+			return nil, "", err
+		}
+
+		progress.Done("")
+
+		opInfo = op.Get()
 	} else {
 		req.Source.Type = "none"
 
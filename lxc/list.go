@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net"
 	"regexp"
@@ -110,13 +112,17 @@ Pre-defined column shorthand chars:
   f - Base Image Fingerprint (short)
   F - Base Image Fingerprint (long)
 
-Custom columns are defined with "[config:|devices:]key[:name][:maxWidth]":
-  KEY: The (extended) config or devices key to display. If [config:|devices:] is omitted then it defaults to config key.
+Custom columns are defined with "[config:|devices:|state:]key[:name][:maxWidth]":
+  KEY: The (extended) config or devices key, or the state field to display. If [config:|devices:|state:] is omitted then it defaults to config key.
+  State fields are given by their period separated path in the JSON representation of the instance state, with list elements selected by index (e.g. network.eth0.addresses[0].address).
   NAME: Name to display in the column header.
   Defaults to the key if not specified or empty.
 
   MAXWIDTH: Max width of the column (longer results are truncated).
-  Defaults to -1 (unlimited). Use 0 to limit to the column header size.`))
+  Defaults to -1 (unlimited). Use 0 to limit to the column header size.
+
+Column sets can be saved under "list-columns" in the client configuration file (config.yml) and used with "@<name>".
+A column set named "default" replaces the default column layout.`))
 
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`lxc list -c nFs46,volatile.eth0.hwaddr:MAC,config:image.os,devices:eth0.parent:ETHP
@@ -125,7 +131,13 @@ Custom columns are defined with "[config:|devices:]key[:name][:maxWidth]":
   "ETHP" is a custom column generated from a device key.
 
 lxc list -c ns,user.comment:comment
-  List instances with their running state and user comment.`))
+  List instances with their running state and user comment.
+
+lxc list -c n,state:network.eth0.addresses[0].address:ADDRESS,state:memory.usage:MEMORY
+  List instances with the first address of eth0 and their memory usage in bytes.
+
+lxc list -c @web
+  List instances using the "web" column set from the client configuration.`))
 
 	cmd.RunE = c.run
 	cmd.Flags().StringVarP(&c.flagColumns, "columns", "c", defaultColumns, i18n.G("Columns")+"``")
@@ -140,6 +152,7 @@ const defaultColumns = "ns46tSL"
 const defaultColumnsAllProjects = "ens46tSL"
 const configColumnType = "config"
 const deviceColumnType = "devices"
+const stateColumnType = "state"
 
 // This seems a little excessive.
 func (c *cmdList) dotPrefixMatch(short string, full string) bool {
@@ -493,6 +506,12 @@ func (c *cmdList) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Expand any column sets defined in the client configuration
+	err = c.expandColumnSets(conf.ListColumns, cmd.Flags().Changed("columns"))
+	if err != nil {
+		return err
+	}
+
 	// Get the list of columns
 	columns, needsData, err := c.parseColumns(d.IsClustered())
 	if err != nil {
@@ -505,7 +524,22 @@ func (c *cmdList) run(cmd *cobra.Command, args []string) error {
 
 		serverFilters, clientFilters := getServerSupportedFilters(filters, api.InstanceFull{})
 
-		if c.flagAllProjects {
+		needsSnapshots := false
+		for _, column := range columns {
+			if column.NeedsSnapshots {
+				needsSnapshots = true
+				break
+			}
+		}
+
+		if !needsSnapshots && d.HasExtension("instances_state_bulk") {
+			// Skip loading the snapshots and backups when only the state is needed.
+			if c.flagAllProjects {
+				instances, err = d.GetInstancesWithStateAllProjects(api.InstanceTypeAny, serverFilters)
+			} else {
+				instances, err = d.GetInstancesWithState(api.InstanceTypeAny, serverFilters)
+			}
+		} else if c.flagAllProjects {
 			instances, err = d.GetInstancesFullAllProjectsWithFilter(api.InstanceTypeAny, serverFilters)
 		} else {
 			instances, err = d.GetInstancesFullWithFilter(api.InstanceTypeAny, serverFilters)
@@ -614,8 +648,8 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 		}
 
 		// Config keys always contain a period, parse anything without a
-		// period as a series of shorthand runes.
-		if !strings.Contains(columnEntry, ".") {
+		// period (other than state fields) as a series of shorthand runes.
+		if !strings.Contains(columnEntry, ".") && !strings.HasPrefix(columnEntry, stateColumnType+":") {
 			for _, columnRune := range columnEntry {
 				column, ok := columnsShorthandMap[columnRune]
 				if !ok {
@@ -631,7 +665,7 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 		} else {
 			cc := strings.Split(columnEntry, ":")
 			colType := configColumnType
-			if (cc[0] == configColumnType || cc[0] == deviceColumnType) && len(cc) > 1 {
+			if (cc[0] == configColumnType || cc[0] == deviceColumnType || cc[0] == stateColumnType) && len(cc) > 1 {
 				colType = cc[0]
 				cc = append(cc[:0], cc[1:]...)
 			}
@@ -648,6 +682,15 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 				}
 			}
 
+			var statePath []statePathSegment
+			if colType == stateColumnType {
+				var err error
+				statePath, err = parseStatePath(k)
+				if err != nil {
+					return nil, false, fmt.Errorf(i18n.G("Invalid state field '%s' in '%s': %w"), k, columnEntry, err)
+				}
+			}
+
 			column := column{Name: k}
 			if len(cc) > 1 {
 				if len(cc[1]) == 0 && len(cc) != 3 {
@@ -711,6 +754,21 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 					return v
 				}
 			}
+			if colType == stateColumnType {
+				column.NeedsState = true
+				column.Data = func(cInfo api.InstanceFull) string {
+					v := stateColumnData(cInfo.State, statePath)
+
+					// Truncate the data according to the max width.  A negative max width
+					// indicates there is no effective limit.
+					if maxWidth > 0 && len(v) > maxWidth {
+						return v[:maxWidth]
+					}
+
+					return v
+				}
+			}
+
 			columns = append(columns, column)
 
 			if column.NeedsState || column.NeedsSnapshots {
@@ -722,6 +780,177 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 	return columns, needsData, nil
 }
 
+// statePathSegment is a single period separated field of a state column path, with any list indexes following it.
+type statePathSegment struct {
+	key     string
+	indexes []int
+}
+
+// parseStatePath parses a state column path such as "network.eth0.addresses[0].address".
+func parseStatePath(path string) ([]statePathSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("Empty path")
+	}
+
+	segments := []statePathSegment{}
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		rest := ""
+		bracket := strings.Index(part, "[")
+		if bracket >= 0 {
+			key = part[:bracket]
+			rest = part[bracket:]
+		}
+
+		if key == "" {
+			return nil, fmt.Errorf("Empty field name")
+		}
+
+		segment := statePathSegment{key: key}
+		for rest != "" {
+			index, after, ok := strings.Cut(strings.TrimPrefix(rest, "["), "]")
+			if !ok || !strings.HasPrefix(rest, "[") {
+				return nil, fmt.Errorf("Malformed list index in %q", part)
+			}
+
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("Invalid list index %q in %q", index, part)
+			}
+
+			segment.indexes = append(segment.indexes, i)
+			rest = after
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
+
+// stateColumnData returns the value at the given path of the JSON representation of the instance state.
+// Scalar values are rendered as is, objects and lists are rendered as JSON.
+func stateColumnData(state *api.InstanceState, path []statePathSegment) string {
+	if state == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return ""
+	}
+
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&value)
+	if err != nil {
+		return ""
+	}
+
+	value, ok := lookupStatePath(value, path)
+	if !ok {
+		return ""
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+
+		return string(data)
+	}
+}
+
+// lookupStatePath walks the decoded JSON value along the given path.
+func lookupStatePath(value any, path []statePathSegment) (any, bool) {
+	if len(path) == 0 {
+		return value, true
+	}
+
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	// Keys such as network interface names may themselves contain periods (e.g. "eth0.100"), so try to match the
+	// longest key first.
+	for j := len(path); j > 0; j-- {
+		keys := make([]string, 0, j)
+		for _, segment := range path[:j-1] {
+			if len(segment.indexes) > 0 {
+				break
+			}
+
+			keys = append(keys, segment.key)
+		}
+
+		if len(keys) != j-1 {
+			continue
+		}
+
+		keys = append(keys, path[j-1].key)
+
+		v, ok := fields[strings.Join(keys, ".")]
+		if !ok {
+			continue
+		}
+
+		for _, i := range path[j-1].indexes {
+			list, ok := v.([]any)
+			if !ok || i >= len(list) {
+				return nil, false
+			}
+
+			v = list[i]
+		}
+
+		return lookupStatePath(v, path[j:])
+	}
+
+	return nil, false
+}
+
+// expandColumnSets replaces "@<name>" entries of the column list with the matching column set from the client
+// configuration. If no columns were specified, the column set named "default" is used when defined.
+func (c *cmdList) expandColumnSets(columnSets map[string]string, columnsChanged bool) error {
+	if !columnsChanged && !c.flagFast {
+		defaultColumnSet, ok := columnSets["default"]
+		if ok {
+			c.flagColumns = defaultColumnSet
+		}
+	}
+
+	entries := strings.Split(c.flagColumns, ",")
+	for i, entry := range entries {
+		name, ok := strings.CutPrefix(entry, "@")
+		if !ok {
+			continue
+		}
+
+		columnSet, ok := columnSets[name]
+		if !ok {
+			return fmt.Errorf(i18n.G("Unknown column set '%s'"), name)
+		}
+
+		entries[i] = columnSet
+	}
+
+	c.flagColumns = strings.Join(entries, ",")
+
+	return nil
+}
+
 func (c *cmdList) getBaseImage(cInfo api.InstanceFull, long bool) string {
 	v, ok := cInfo.Config["volatile.base_image"]
 	if !ok {
@@ -330,4 +330,120 @@ func TestInvalidColumns(t *testing.T) {
 	run("config:")
 	run("config:image")
 	run("devices:eth0")
+	run("state:")
+	run("state:network..addresses")
+	run("state:network.eth0.addresses[")
+	run("state:network.eth0.addresses[a]")
+	run("state:network.eth0.addresses[-1]")
+	run("state:network.eth0.addresses[0]x")
+	run("state:[0]")
+}
+
+func TestStateColumns(t *testing.T) {
+	inst := api.InstanceFull{
+		State: &api.InstanceState{
+			Status: "Running",
+			Pid:    1234,
+			Memory: api.InstanceStateMemory{Usage: 4096},
+			Network: map[string]api.InstanceStateNetwork{
+				"eth0": {
+					Addresses: []api.InstanceStateNetworkAddress{
+						{Family: "inet", Address: "10.0.0.2", Netmask: "24", Scope: "global"},
+						{Family: "inet6", Address: "fd42::2", Netmask: "64", Scope: "global"},
+					},
+				},
+				"eth0.100": {
+					Addresses: []api.InstanceStateNetworkAddress{
+						{Family: "inet", Address: "10.100.0.2", Netmask: "24", Scope: "global"},
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]string{
+		"state:status":       "Running",
+		"state:pid":          "1234",
+		"state:memory.usage": "4096",
+		"state:network.eth0.addresses[1].address":     "fd42::2",
+		"state:network.eth0.100.addresses[0].address": "10.100.0.2",
+		"state:network.eth0.addresses[0]":             `{"address":"10.0.0.2","family":"inet","netmask":"24","scope":"global"}`,
+		"state:network.eth0.addresses[2].address":     "",
+		"state:network.eth1.addresses[0].address":     "",
+		"state:status:STATUS:3":                       "Run",
+	}
+
+	for raw, expected := range tests {
+		list := cmdList{flagColumns: raw}
+		columns, needsData, err := list.parseColumns(false)
+		if err != nil {
+			t.Errorf("Failed to parse columns string.  Input: %s, Error: %s", raw, err)
+			continue
+		}
+
+		if len(columns) != 1 || !columns[0].NeedsState || !needsData {
+			t.Errorf("Expected a single column requiring state.  Input: %s", raw)
+			continue
+		}
+
+		actual := columns[0].Data(inst)
+		if actual != expected {
+			t.Errorf("Unexpected column data.  Input: %s, Expected: %q, Actual: %q", raw, expected, actual)
+		}
+	}
+
+	// Instances without state render empty columns.
+	list := cmdList{flagColumns: "state:status"}
+	columns, _, err := list.parseColumns(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if columns[0].Data(api.InstanceFull{}) != "" {
+		t.Error("Expected an empty column for an instance without state")
+	}
+}
+
+func TestExpandColumnSets(t *testing.T) {
+	columnSets := map[string]string{
+		"default": "ns,user.comment",
+		"web":     "n,state:network.eth0.addresses[0].address:IP",
+	}
+
+	tests := []struct {
+		columns        string
+		columnsChanged bool
+		expected       string
+	}{
+		{defaultColumns, false, "ns,user.comment"},
+		{"ns4", true, "ns4"},
+		{"@web", true, "n,state:network.eth0.addresses[0].address:IP"},
+		{"s,@web,@default", true, "s,n,state:network.eth0.addresses[0].address:IP,ns,user.comment"},
+	}
+
+	for _, test := range tests {
+		list := cmdList{flagColumns: test.columns}
+		err := list.expandColumnSets(columnSets, test.columnsChanged)
+		if err != nil {
+			t.Errorf("Failed to expand column sets.  Input: %s, Error: %s", test.columns, err)
+			continue
+		}
+
+		if list.flagColumns != test.expected {
+			t.Errorf("Unexpected columns.  Input: %s, Expected: %s, Actual: %s", test.columns, test.expected, list.flagColumns)
+		}
+	}
+
+	list := cmdList{flagColumns: "@unknown"}
+	err := list.expandColumnSets(columnSets, true)
+	if err == nil {
+		t.Error("Expected error for unknown column set")
+	}
+
+	// The default column set is not used in fast mode.
+	list = cmdList{flagColumns: defaultColumns, flagFast: true}
+	err = list.expandColumnSets(columnSets, false)
+	if err != nil || list.flagColumns != defaultColumns {
+		t.Errorf("Expected default columns in fast mode, got %s (%v)", list.flagColumns, err)
+	}
 }
@@ -134,6 +134,10 @@ For help with any of those, simply call them with --help.`))
 	deleteCmd := cmdDelete{global: &globalCmd}
 	app.AddCommand(deleteCmd.command())
 
+	// device-group sub-command
+	deviceGroupCmd := cmdDeviceGroup{global: &globalCmd}
+	app.AddCommand(deviceGroupCmd.command())
+
 	// exec sub-command
 	execCmd := cmdExec{global: &globalCmd}
 	app.AddCommand(execCmd.command())
@@ -194,6 +198,10 @@ For help with any of those, simply call them with --help.`))
 	pauseCmd := cmdPause{global: &globalCmd}
 	app.AddCommand(pauseCmd.command())
 
+	// preseed sub-command
+	preseedCmd := cmdPreseed{global: &globalCmd}
+	app.AddCommand(preseedCmd.command())
+
 	// publish sub-command
 	publishCmd := cmdPublish{global: &globalCmd}
 	app.AddCommand(publishCmd.command())
@@ -246,6 +254,10 @@ For help with any of those, simply call them with --help.`))
 	stopCmd := cmdStop{global: &globalCmd}
 	app.AddCommand(stopCmd.command())
 
+	// top sub-command
+	topCmd := cmdTop{global: &globalCmd}
+	app.AddCommand(topCmd.command())
+
 	// version sub-command
 	versionCmd := cmdVersion{global: &globalCmd}
 	app.AddCommand(versionCmd.command())
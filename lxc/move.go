@@ -27,6 +27,8 @@ type cmdMove struct {
 	flagTarget            string
 	flagTargetProject     string
 	flagAllowInconsistent bool
+	flagBwlimit           string
+	flagStatelessFallback bool
 }
 
 func (c *cmdMove) command() *cobra.Command {
@@ -52,7 +54,13 @@ lxc move <old name> <new name> [--instance-only]
     Rename a local instance.
 
 lxc move <instance>/<old snapshot name> <instance>/<new snapshot name>
-    Rename a snapshot.`))
+    Rename a snapshot.
+
+lxc move <instance> --target auto
+    Move an instance to the cluster member picked by the cluster's instance placement.
+
+lxc move <instance> --target <member> --stateless-fallback
+    Live migrate a running instance to another cluster member, stopping it and moving it statelessly if the live migration keeps failing.`))
 
 	cmd.RunE = c.run
 	cmd.Flags().StringArrayVarP(&c.flagConfig, "config", "c", nil, i18n.G("Config key/value to apply to the target instance")+"``")
@@ -66,6 +74,8 @@ lxc move <instance>/<old snapshot name> <instance>/<new snapshot name>
 	cmd.Flags().StringVar(&c.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.Flags().StringVar(&c.flagTargetProject, "target-project", "", i18n.G("Copy to a project different from the source")+"``")
 	cmd.Flags().BoolVar(&c.flagAllowInconsistent, "allow-inconsistent", false, i18n.G("Ignore copy errors for volatile files"))
+	cmd.Flags().StringVar(&c.flagBwlimit, "bwlimit", "", i18n.G("Maximum transfer rate of the migration data in bytes per second (e.g. 10MiB)")+"``")
+	cmd.Flags().BoolVar(&c.flagStatelessFallback, "stateless-fallback", false, i18n.G("Stop the instance and move it statelessly if its live migration keeps failing (cluster moves only)"))
 
 	return cmd
 }
@@ -160,6 +170,14 @@ func (c *cmdMove) run(cmd *cobra.Command, args []string) error {
 
 	stateful := !c.flagStateless
 
+	if c.flagStatelessFallback && c.flagStateless {
+		return fmt.Errorf(i18n.G("The --stateless-fallback flag can't be used with --stateless"))
+	}
+
+	if c.flagStatelessFallback && (c.flagTarget == "" || sourceRemote != destRemote) {
+		return fmt.Errorf(i18n.G("The --stateless-fallback flag can only be used when moving an instance between cluster members"))
+	}
+
 	if c.flagTarget != "" {
 		// If the target option was specified, we're moving an instance from a
 		// cluster member to another, let's use the dedicated API.
@@ -180,7 +198,7 @@ func (c *cmdMove) run(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf(i18n.G("The --mode flag can't be used with --target"))
 			}
 
-			return moveClusterInstance(conf, sourceResource, destResource, c.flagTarget, c.global.flagQuiet, stateful)
+			return moveClusterInstance(conf, sourceResource, destResource, c.flagTarget, c.global.flagQuiet, stateful, c.flagStatelessFallback, c.flagBwlimit)
 		}
 
 		dest, err := conf.GetInstanceServer(destRemote)
@@ -240,6 +258,7 @@ func (c *cmdMove) run(cmd *cobra.Command, args []string) error {
 	cpy.flagProfile = c.flagProfile
 	cpy.flagNoProfiles = c.flagNoProfiles
 	cpy.flagAllowInconsistent = c.flagAllowInconsistent
+	cpy.flagBwlimit = c.flagBwlimit
 
 	instanceOnly := c.flagInstanceOnly
 
@@ -262,7 +281,7 @@ func (c *cmdMove) run(cmd *cobra.Command, args []string) error {
 }
 
 // Move an instance using special POST /instances/<name>?target=<member> API.
-func moveClusterInstance(conf *config.Config, sourceResource string, destResource string, target string, quiet bool, stateful bool) error {
+func moveClusterInstance(conf *config.Config, sourceResource string, destResource string, target string, quiet bool, stateful bool, statelessFallback bool, bwlimit string) error {
 	// Parse the source.
 	sourceRemote, sourceName, err := conf.ParseRemote(sourceResource)
 	if err != nil {
@@ -299,9 +318,11 @@ func moveClusterInstance(conf *config.Config, sourceResource string, destResourc
 	// The migrate API will do the right thing when passed a target.
 	source = source.UseTarget(target)
 	req := api.InstancePost{
-		Name:      destName,
-		Migration: true,
-		Live:      stateful,
+		Name:              destName,
+		Migration:         true,
+		Live:              stateful,
+		BandwidthLimit:    bwlimit,
+		StatelessFallback: statelessFallback,
 	}
 
 	op, err := source.MigrateInstance(sourceName, req)
@@ -334,6 +355,11 @@ func moveClusterInstance(conf *config.Config, sourceResource string, destResourc
 		return fmt.Errorf(i18n.G("Migration operation failure: %w"), err)
 	}
 
+	// Let the user know if the instance had to be moved statelessly.
+	if statelessFallback && !quiet && op.Get().Metadata["migration_mode"] == "stateless" {
+		fmt.Println(i18n.G("The live migration failed, the instance was stopped and moved statelessly"))
+	}
+
 	return nil
 }
 
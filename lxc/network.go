@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 
+	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	cli "github.com/canonical/lxd/shared/cmd"
@@ -40,6 +41,10 @@ func (c *cmdNetwork) command() *cobra.Command {
 	networkAttachProfileCmd := cmdNetworkAttachProfile{global: c.global, network: c}
 	cmd.AddCommand(networkAttachProfileCmd.command())
 
+	// Capture
+	networkCaptureCmd := cmdNetworkCapture{global: c.global, network: c}
+	cmd.AddCommand(networkCaptureCmd.command())
+
 	// Create
 	networkCreateCmd := cmdNetworkCreate{global: c.global, network: c}
 	cmd.AddCommand(networkCreateCmd.command())
@@ -80,6 +85,9 @@ func (c *cmdNetwork) command() *cobra.Command {
 	networkListLeasesCmd := cmdNetworkListLeases{global: c.global, network: c}
 	cmd.AddCommand(networkListLeasesCmd.command())
 
+	networkListDNSRecordsCmd := cmdNetworkListDNSRecords{global: c.global, network: c}
+	cmd.AddCommand(networkListDNSRecordsCmd.command())
+
 	// Rename
 	networkRenameCmd := cmdNetworkRename{global: c.global, network: c}
 	cmd.AddCommand(networkRenameCmd.command())
@@ -280,6 +288,105 @@ func (c *cmdNetworkAttachProfile) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Capture.
+type cmdNetworkCapture struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+
+	flagInstance string
+	flagDevice   string
+	flagDuration string
+	flagMaxSize  string
+}
+
+func (c *cmdNetworkCapture) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("capture", i18n.G("[<remote>:]<network>"))
+	cmd.Short = i18n.G("Capture network traffic")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Capture network traffic
+
+The traffic is captured on the host interface of the network, or on the host side of an instance NIC,
+and written to standard output in pcap format.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network capture lxdbr0 --duration 30s > out.pcap
+    Capture the traffic of lxdbr0 for 30 seconds
+
+lxc network capture lxdbr0 --instance c1 --device eth0 --max-size 10MiB > c1.pcap
+    Capture up to 10MiB of the traffic of the eth0 NIC of instance c1`))
+
+	cmd.Flags().StringVar(&c.network.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVar(&c.flagInstance, "instance", "", i18n.G("Instance whose NIC should be captured")+"``")
+	cmd.Flags().StringVar(&c.flagDevice, "device", "", i18n.G("Name of the instance NIC to capture")+"``")
+	cmd.Flags().StringVar(&c.flagDuration, "duration", "", i18n.G("Duration of the capture (default 30s)")+"``")
+	cmd.Flags().StringVar(&c.flagMaxSize, "max-size", "", i18n.G("Maximum size of the capture (default 1GiB)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdNetworkCapture) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	if termios.IsTerminal(getStdoutFd()) {
+		return fmt.Errorf(i18n.G("Refusing to write the capture to a terminal, redirect the output to a file"))
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	client := resource.server
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Targeting.
+	if c.network.flagTarget != "" {
+		if !client.IsClustered() {
+			return fmt.Errorf(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		client = client.UseTarget(c.network.flagTarget)
+	}
+
+	req := api.NetworkCapturePost{
+		Duration: c.flagDuration,
+		MaxSize:  c.flagMaxSize,
+		Instance: c.flagInstance,
+		Device:   c.flagDevice,
+	}
+
+	captureArgs := lxd.NetworkCaptureArgs{
+		Output:   os.Stdout,
+		DataDone: make(chan bool),
+	}
+
+	op, err := client.CaptureNetwork(resource.name, req, &captureArgs)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the capture to complete.
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	// Wait for the remaining captured traffic to be written.
+	<-captureArgs.DataDone
+
+	return nil
+}
+
 // Create.
 type cmdNetworkCreate struct {
 	global  *cmdGlobal
@@ -583,6 +690,8 @@ func (c *cmdNetworkDetachProfile) run(cmd *cobra.Command, args []string) error {
 type cmdNetworkEdit struct {
 	global  *cmdGlobal
 	network *cmdNetwork
+
+	flagDryRun bool
 }
 
 func (c *cmdNetworkEdit) command() *cobra.Command {
@@ -592,6 +701,7 @@ func (c *cmdNetworkEdit) command() *cobra.Command {
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`Edit network configurations as YAML`))
 
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the changes that would be made"))
 	cmd.RunE = c.run
 
 	return cmd
@@ -649,6 +759,16 @@ func (c *cmdNetworkEdit) run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		if c.flagDryRun {
+			diff, err := resource.server.UpdateNetworkDryRun(resource.name, newdata, "")
+			if err != nil {
+				return err
+			}
+
+			printConfigDiff(diff)
+			return nil
+		}
+
 		return resource.server.UpdateNetwork(resource.name, newdata, "")
 	}
 
@@ -673,12 +793,17 @@ func (c *cmdNetworkEdit) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var diff *api.ConfigDiff
 	for {
 		// Parse the text received from the editor
 		newdata := api.NetworkPut{}
 		err = yaml.Unmarshal(content, &newdata)
 		if err == nil {
-			err = resource.server.UpdateNetwork(resource.name, newdata, etag)
+			if c.flagDryRun {
+				diff, err = resource.server.UpdateNetworkDryRun(resource.name, newdata, etag)
+			} else {
+				err = resource.server.UpdateNetwork(resource.name, newdata, etag)
+			}
 		}
 
 		// Respawn the editor
@@ -702,6 +827,10 @@ func (c *cmdNetworkEdit) run(cmd *cobra.Command, args []string) error {
 		break
 	}
 
+	if diff != nil {
+		printConfigDiff(diff)
+	}
+
 	return nil
 }
 
@@ -780,6 +909,8 @@ func (c *cmdNetworkGet) run(cmd *cobra.Command, args []string) error {
 type cmdNetworkInfo struct {
 	global  *cmdGlobal
 	network *cmdNetwork
+
+	flagFormat string
 }
 
 func (c *cmdNetworkInfo) command() *cobra.Command {
@@ -790,6 +921,7 @@ func (c *cmdNetworkInfo) command() *cobra.Command {
 		`Get runtime information on networks`))
 
 	cmd.Flags().StringVar(&c.network.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
 	cmd.RunE = c.run
 
 	return cmd
@@ -829,8 +961,12 @@ func (c *cmdNetworkInfo) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	return cli.RenderInfo(c.flagFormat, state, func() error { return c.renderInfo(resource.name, state) })
+}
+
+func (c *cmdNetworkInfo) renderInfo(name string, state *api.NetworkState) error {
 	// Interface information.
-	fmt.Printf(i18n.G("Name: %s")+"\n", resource.name)
+	fmt.Printf(i18n.G("Name: %s")+"\n", name)
 	fmt.Printf(i18n.G("MAC address: %s")+"\n", state.Hwaddr)
 	fmt.Printf(i18n.G("MTU: %d")+"\n", state.Mtu)
 	fmt.Printf(i18n.G("State: %s")+"\n", state.State)
@@ -1062,6 +1198,79 @@ func (c *cmdNetworkListLeases) run(cmd *cobra.Command, args []string) error {
 	return cli.RenderTable(c.flagFormat, header, data, leases)
 }
 
+// List DNS records.
+type cmdNetworkListDNSRecords struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+
+	flagFormat string
+}
+
+func (c *cmdNetworkListDNSRecords) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-dns-records", i18n.G("[<remote>:]<network>"))
+	cmd.Short = i18n.G("List the DNS records served for instances")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List the DNS records served for instances`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdNetworkListDNSRecords) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// List DNS records
+	records, err := resource.server.GetNetworkDNSRecords(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, record := range records {
+		entry := []string{record.Name, record.Type, record.Address, record.Instance, record.Device}
+		if resource.server.IsClustered() {
+			entry = append(entry, record.Location)
+		}
+
+		data = append(data, entry)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("TYPE"),
+		i18n.G("IP ADDRESS"),
+		i18n.G("INSTANCE"),
+		i18n.G("DEVICE"),
+	}
+
+	if resource.server.IsClustered() {
+		header = append(header, i18n.G("LOCATION"))
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, records)
+}
+
 // Rename.
 type cmdNetworkRename struct {
 	global  *cmdGlobal
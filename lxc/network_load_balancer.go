@@ -936,9 +936,11 @@ func (c *cmdNetworkLoadBalancerBackend) runRemove(cmd *cobra.Command, args []str
 
 // Add/Remove Port.
 type cmdNetworkLoadBalancerPort struct {
-	global              *cmdGlobal
-	networkLoadBalancer *cmdNetworkLoadBalancer
-	flagRemoveForce     bool
+	global                        *cmdGlobal
+	networkLoadBalancer           *cmdNetworkLoadBalancer
+	flagRemoveForce               bool
+	flagSessionPersistence        string
+	flagSessionPersistenceTimeout int64
 }
 
 func (c *cmdNetworkLoadBalancerPort) command() *cobra.Command {
@@ -961,9 +963,13 @@ func (c *cmdNetworkLoadBalancerPort) commandAdd() *cobra.Command {
 	cmd.Use = usage("add", i18n.G("[<remote>:]<network> <listen_address> <protocol> <listen_port(s)> <backend_name>[,<backend_name>...]"))
 	cmd.Short = i18n.G("Add ports to a load balancer")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Add ports to a load balancer"))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network load-balancer port add ovn0 192.0.2.10 tcp 80,443 web1,web2 --session-persistence source_ip --session-persistence-timeout 3600
+    Balance the HTTP and HTTPS traffic between two backends, sending each client to the same backend until it was idle for an hour`))
 	cmd.RunE = c.runAdd
 
 	cmd.Flags().StringVar(&c.networkLoadBalancer.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVar(&c.flagSessionPersistence, "session-persistence", "", i18n.G("Session persistence mode (none or source_ip)")+"``")
+	cmd.Flags().Int64Var(&c.flagSessionPersistenceTimeout, "session-persistence-timeout", 0, i18n.G("Session persistence timeout in seconds")+"``")
 
 	return cmd
 }
@@ -1005,9 +1011,11 @@ func (c *cmdNetworkLoadBalancerPort) runAdd(cmd *cobra.Command, args []string) e
 	}
 
 	port := api.NetworkLoadBalancerPort{
-		Protocol:      args[2],
-		ListenPort:    args[3],
-		TargetBackend: shared.SplitNTrimSpace(args[4], ",", -1, false),
+		Protocol:                  args[2],
+		ListenPort:                args[3],
+		TargetBackend:             shared.SplitNTrimSpace(args[4], ",", -1, false),
+		SessionPersistence:        c.flagSessionPersistence,
+		SessionPersistenceTimeout: c.flagSessionPersistenceTimeout,
 	}
 
 	loadBalancer.Ports = append(loadBalancer.Ports, port)
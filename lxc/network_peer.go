@@ -119,7 +119,9 @@ func (c *cmdNetworkPeerList) run(cmd *cobra.Command, args []string) error {
 	for _, peer := range peers {
 		targetPeer := "Unknown"
 
-		if peer.TargetProject != "" && peer.TargetNetwork != "" {
+		if peer.Type == api.NetworkPeerTypeRemote {
+			targetPeer = i18n.G("Remote")
+		} else if peer.TargetProject != "" && peer.TargetNetwork != "" {
 			targetPeer = fmt.Sprintf("%s/%s", peer.TargetProject, peer.TargetNetwork)
 		}
 
@@ -206,13 +208,25 @@ func (c *cmdNetworkPeerShow) run(cmd *cobra.Command, args []string) error {
 type cmdNetworkPeerCreate struct {
 	global      *cmdGlobal
 	networkPeer *cmdNetworkPeer
+
+	flagType string
 }
 
 func (c *cmdNetworkPeerCreate) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <peer_name> <[target project/]target_network> [key=value...]"))
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <peer_name> [<[target project/]target_network>] [key=value...]"))
 	cmd.Short = i18n.G("Create new network peering")
-	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network peering"))
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Create new network peering
+
+Local peerings connect to another network of the same deployment, which must create the mutual peering.
+Remote peerings connect to a network of another deployment through an OVN interconnection transit switch
+and don't take a target network.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network peer create ovn1 peer1 project1/ovn2
+    Create a peering between ovn1 and the network ovn2 of project1.
+
+lxc network peer create ovn1 site2 --type=remote ovn.ic.northbound_connection=tcp:192.0.2.10:6645 ovn.ic.address=169.254.100.1/24
+    Connect ovn1 to the transit switch lxd-ts-site2 of the OVN interconnection database at 192.0.2.10.`))
+	cmd.Flags().StringVar(&c.flagType, "type", api.NetworkPeerTypeLocal, i18n.G("Peer type (local or remote)")+"``")
 	cmd.RunE = c.run
 
 	return cmd
@@ -220,7 +234,12 @@ func (c *cmdNetworkPeerCreate) command() *cobra.Command {
 
 func (c *cmdNetworkPeerCreate) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	minArgs := 3
+	if c.flagType == api.NetworkPeerTypeRemote {
+		minArgs = 2
+	}
+
+	exit, err := c.global.CheckArgs(cmd, args, minArgs, -1)
 	if exit {
 		return err
 	}
@@ -241,18 +260,23 @@ func (c *cmdNetworkPeerCreate) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Missing peer name"))
 	}
 
-	if args[2] == "" {
-		return fmt.Errorf(i18n.G("Missing target network"))
-	}
+	// Remote peers have no target network, so their config starts right after the peer name.
+	var targetProject, targetNetwork string
+	configArgs := args[2:]
+	if c.flagType != api.NetworkPeerTypeRemote {
+		if args[2] == "" {
+			return fmt.Errorf(i18n.G("Missing target network"))
+		}
 
-	targetParts := strings.SplitN(args[2], "/", 2)
+		targetParts := strings.SplitN(args[2], "/", 2)
+		if len(targetParts) == 2 {
+			targetProject = targetParts[0]
+			targetNetwork = targetParts[1]
+		} else {
+			targetNetwork = targetParts[0]
+		}
 
-	var targetProject, targetNetwork string
-	if len(targetParts) == 2 {
-		targetProject = targetParts[0]
-		targetNetwork = targetParts[1]
-	} else {
-		targetNetwork = targetParts[0]
+		configArgs = args[3:]
 	}
 
 	// If stdin isn't a terminal, read yaml from it.
@@ -274,10 +298,10 @@ func (c *cmdNetworkPeerCreate) run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get config filters from arguments.
-	for i := 3; i < len(args); i++ {
-		entry := strings.SplitN(args[i], "=", 2)
+	for _, arg := range configArgs {
+		entry := strings.SplitN(arg, "=", 2)
 		if len(entry) < 2 {
-			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), arg)
 		}
 
 		peerPut.Config[entry[0]] = entry[1]
@@ -291,6 +315,10 @@ func (c *cmdNetworkPeerCreate) run(cmd *cobra.Command, args []string) error {
 		NetworkPeerPut: peerPut,
 	}
 
+	if c.flagType != api.NetworkPeerTypeLocal {
+		peer.Type = c.flagType
+	}
+
 	client := resource.server
 
 	err = client.CreateNetworkPeer(resource.name, peer)
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdPreseed struct {
+	global *cmdGlobal
+}
+
+func (c *cmdPreseed) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("preseed")
+	cmd.Short = i18n.G("Manage server configuration from preseed files")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage server configuration from preseed files`))
+
+	// Apply
+	preseedApplyCmd := cmdPreseedApply{global: c.global, preseed: c}
+	cmd.AddCommand(preseedApplyCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Apply.
+type cmdPreseedApply struct {
+	global  *cmdGlobal
+	preseed *cmdPreseed
+
+	flagDryRun bool
+}
+
+// preseedResult records the outcome of reconciling a single entity.
+type preseedResult struct {
+	entity string
+	name   string
+	action string
+	detail string
+}
+
+func (c *cmdPreseedApply) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("apply", i18n.G("[<remote>:] < preseed.yaml"))
+	cmd.Short = i18n.G("Apply a preseed file to a running server")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Apply a preseed file to a running server
+
+The storage pools, networks, projects and profiles described in the preseed file
+are created if missing and updated to match the file otherwise. Configuration keys
+and devices which aren't in the file are left untouched, so the same file can be
+applied repeatedly.
+
+Entities which can't be reconciled, for example a storage pool using a different
+driver, are reported as conflicts and left unchanged.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc preseed apply < preseed.yaml
+    Reconcile the local server with the content of preseed.yaml.
+
+lxc preseed apply remote: --dry-run < preseed.yaml
+    Show the changes that would be made to the server "remote".`))
+
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only report the changes without applying them"))
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdPreseedApply) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Invalid argument %q, only a remote can be specified"), args[0])
+	}
+
+	if termios.IsTerminal(getStdinFd()) {
+		return fmt.Errorf(i18n.G("The preseed file must be provided on stdin"))
+	}
+
+	contents, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	config := api.InitPreseed{}
+	err = yaml.UnmarshalStrict(contents, &config)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to parse the preseed file: %w"), err)
+	}
+
+	if config.Cluster != nil {
+		return fmt.Errorf(i18n.G("Cluster configuration can only be applied by \"lxd init\""))
+	}
+
+	if len(config.Node.Config) > 0 || len(config.Node.StorageVolumes) > 0 {
+		return fmt.Errorf(i18n.G("Only storage pools, networks, projects and profiles can be applied"))
+	}
+
+	results, err := c.apply(resource.server, config.Node)
+
+	// Report what was done, even if a later step failed.
+	conflicts := 0
+	for _, result := range results {
+		if result.action == "conflict" {
+			conflicts++
+		}
+
+		line := fmt.Sprintf("%s %s %q", result.action, result.entity, result.name)
+		if result.detail != "" {
+			line = fmt.Sprintf("%s: %s", line, result.detail)
+		}
+
+		fmt.Println(line)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if conflicts > 0 {
+		return fmt.Errorf(i18n.G("Found %d conflicts"), conflicts)
+	}
+
+	return nil
+}
+
+// apply reconciles the server towards the given preseed configuration.
+// Entities are processed in the same order as "lxd init" so that dependencies are satisfied.
+func (c *cmdPreseedApply) apply(d lxd.InstanceServer, config api.InitLocalPreseed) ([]preseedResult, error) {
+	results := []preseedResult{}
+
+	for _, pool := range config.StoragePools {
+		result, err := c.applyStoragePool(d, pool)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, *result)
+	}
+
+	// Networks in the default project go first as projects may depend on them.
+	for _, network := range config.Networks {
+		if network.Project != "" && network.Project != api.ProjectDefaultName {
+			continue
+		}
+
+		result, err := c.applyNetwork(d.UseProject(api.ProjectDefaultName), network.NetworksPost)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, *result)
+	}
+
+	for _, project := range config.Projects {
+		result, err := c.applyProject(d, project)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, *result)
+	}
+
+	for _, network := range config.Networks {
+		if network.Project == "" || network.Project == api.ProjectDefaultName {
+			continue
+		}
+
+		result, err := c.applyNetwork(d.UseProject(network.Project), network.NetworksPost)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, *result)
+	}
+
+	for _, profile := range config.Profiles {
+		result, err := c.applyProfile(d, profile)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// preseedMergeConfig applies the desired configuration on top of the current one.
+// It returns the sorted list of the keys whose value changed.
+func preseedMergeConfig(current map[string]string, desired map[string]string) []string {
+	changed := []string{}
+	for k, v := range desired {
+		oldValue, ok := current[k]
+		if ok && oldValue == v {
+			continue
+		}
+
+		current[k] = v
+		changed = append(changed, k)
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+// preseedUpdateResult builds the result of an update from the list of changed fields.
+func preseedUpdateResult(entity string, name string, changed []string) *preseedResult {
+	if len(changed) == 0 {
+		return &preseedResult{entity: entity, name: name, action: "unchanged"}
+	}
+
+	return &preseedResult{entity: entity, name: name, action: "updated", detail: strings.Join(changed, ", ")}
+}
+
+func (c *cmdPreseedApply) applyStoragePool(d lxd.InstanceServer, pool api.StoragePoolsPost) (*preseedResult, error) {
+	entity := i18n.G("storage pool")
+
+	current, etag, err := d.GetStoragePool(pool.Name)
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, fmt.Errorf(i18n.G("Failed to retrieve storage pool %q: %w"), pool.Name, err)
+		}
+
+		if !c.flagDryRun {
+			err = d.CreateStoragePool(pool)
+			if err != nil {
+				return nil, fmt.Errorf(i18n.G("Failed to create storage pool %q: %w"), pool.Name, err)
+			}
+		}
+
+		return &preseedResult{entity: entity, name: pool.Name, action: "created"}, nil
+	}
+
+	if pool.Driver != "" && current.Driver != pool.Driver {
+		return &preseedResult{entity: entity, name: pool.Name, action: "conflict", detail: fmt.Sprintf(i18n.G("Driver is %q instead of %q"), current.Driver, pool.Driver)}, nil
+	}
+
+	newPool := api.StoragePoolPut{}
+	err = shared.DeepCopy(current.Writable(), &newPool)
+	if err != nil {
+		return nil, err
+	}
+
+	if newPool.Config == nil {
+		newPool.Config = map[string]string{}
+	}
+
+	changed := preseedMergeConfig(newPool.Config, pool.Config)
+	if pool.Description != "" && pool.Description != newPool.Description {
+		newPool.Description = pool.Description
+		changed = append(changed, "description")
+	}
+
+	if len(changed) > 0 && !c.flagDryRun {
+		err = d.UpdateStoragePool(pool.Name, newPool, etag)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("Failed to update storage pool %q: %w"), pool.Name, err)
+		}
+	}
+
+	return preseedUpdateResult(entity, pool.Name, changed), nil
+}
+
+func (c *cmdPreseedApply) applyNetwork(d lxd.InstanceServer, network api.NetworksPost) (*preseedResult, error) {
+	entity := i18n.G("network")
+
+	current, etag, err := d.GetNetwork(network.Name)
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, fmt.Errorf(i18n.G("Failed to retrieve network %q: %w"), network.Name, err)
+		}
+
+		if !c.flagDryRun {
+			err = d.CreateNetwork(network)
+			if err != nil {
+				return nil, fmt.Errorf(i18n.G("Failed to create network %q: %w"), network.Name, err)
+			}
+		}
+
+		return &preseedResult{entity: entity, name: network.Name, action: "created"}, nil
+	}
+
+	if network.Type != "" && current.Type != network.Type {
+		return &preseedResult{entity: entity, name: network.Name, action: "conflict", detail: fmt.Sprintf(i18n.G("Type is %q instead of %q"), current.Type, network.Type)}, nil
+	}
+
+	if !current.Managed {
+		return &preseedResult{entity: entity, name: network.Name, action: "conflict", detail: i18n.G("Network isn't managed by LXD")}, nil
+	}
+
+	newNetwork := api.NetworkPut{}
+	err = shared.DeepCopy(current.Writable(), &newNetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	if newNetwork.Config == nil {
+		newNetwork.Config = map[string]string{}
+	}
+
+	changed := preseedMergeConfig(newNetwork.Config, network.Config)
+	if network.Description != "" && network.Description != newNetwork.Description {
+		newNetwork.Description = network.Description
+		changed = append(changed, "description")
+	}
+
+	if len(changed) > 0 && !c.flagDryRun {
+		err = d.UpdateNetwork(network.Name, newNetwork, etag)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("Failed to update network %q: %w"), network.Name, err)
+		}
+	}
+
+	return preseedUpdateResult(entity, network.Name, changed), nil
+}
+
+func (c *cmdPreseedApply) applyProject(d lxd.InstanceServer, project api.ProjectsPost) (*preseedResult, error) {
+	entity := i18n.G("project")
+
+	current, etag, err := d.GetProject(project.Name)
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, fmt.Errorf(i18n.G("Failed to retrieve project %q: %w"), project.Name, err)
+		}
+
+		if !c.flagDryRun {
+			err = d.CreateProject(project)
+			if err != nil {
+				return nil, fmt.Errorf(i18n.G("Failed to create project %q: %w"), project.Name, err)
+			}
+		}
+
+		return &preseedResult{entity: entity, name: project.Name, action: "created"}, nil
+	}
+
+	newProject := api.ProjectPut{}
+	err = shared.DeepCopy(current.Writable(), &newProject)
+	if err != nil {
+		return nil, err
+	}
+
+	if newProject.Config == nil {
+		newProject.Config = map[string]string{}
+	}
+
+	changed := preseedMergeConfig(newProject.Config, project.Config)
+	if project.Description != "" && project.Description != newProject.Description {
+		newProject.Description = project.Description
+		changed = append(changed, "description")
+	}
+
+	if len(changed) > 0 && !c.flagDryRun {
+		err = d.UpdateProject(project.Name, newProject, etag)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("Failed to update project %q: %w"), project.Name, err)
+		}
+	}
+
+	return preseedUpdateResult(entity, project.Name, changed), nil
+}
+
+func (c *cmdPreseedApply) applyProfile(d lxd.InstanceServer, profile api.ProfilesPost) (*preseedResult, error) {
+	entity := i18n.G("profile")
+
+	current, etag, err := d.GetProfile(profile.Name)
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, fmt.Errorf(i18n.G("Failed to retrieve profile %q: %w"), profile.Name, err)
+		}
+
+		if !c.flagDryRun {
+			err = d.CreateProfile(profile)
+			if err != nil {
+				return nil, fmt.Errorf(i18n.G("Failed to create profile %q: %w"), profile.Name, err)
+			}
+		}
+
+		return &preseedResult{entity: entity, name: profile.Name, action: "created"}, nil
+	}
+
+	newProfile := api.ProfilePut{}
+	err = shared.DeepCopy(current.Writable(), &newProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	if newProfile.Config == nil {
+		newProfile.Config = map[string]string{}
+	}
+
+	if newProfile.Devices == nil {
+		newProfile.Devices = map[string]map[string]string{}
+	}
+
+	changed := preseedMergeConfig(newProfile.Config, profile.Config)
+	for devName, devConfig := range profile.Devices {
+		_, ok := newProfile.Devices[devName]
+		if !ok {
+			newProfile.Devices[devName] = map[string]string{}
+		}
+
+		for _, key := range preseedMergeConfig(newProfile.Devices[devName], devConfig) {
+			changed = append(changed, fmt.Sprintf("devices.%s.%s", devName, key))
+		}
+	}
+
+	if profile.Description != "" && profile.Description != newProfile.Description {
+		newProfile.Description = profile.Description
+		changed = append(changed, "description")
+	}
+
+	if len(changed) > 0 && !c.flagDryRun {
+		err = d.UpdateProfile(profile.Name, newProfile, etag)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("Failed to update profile %q: %w"), profile.Name, err)
+		}
+	}
+
+	return preseedUpdateResult(entity, profile.Name, changed), nil
+}
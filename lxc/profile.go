@@ -165,7 +165,10 @@ func (c *cmdProfileAssign) command() *cobra.Command {
 	cmd.Aliases = []string{"apply"}
 	cmd.Short = i18n.G("Assign sets of profiles to instances")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Assign sets of profiles to instances`))
+		`Assign sets of profiles to instances
+
+The new set of profiles is validated against the instance before being applied.
+If it can't be applied, the instance is left unchanged.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`lxc profile assign foo default,bar
     Set the profiles for "foo" to "default" and "bar".
@@ -415,6 +418,8 @@ func (c *cmdProfileDelete) run(cmd *cobra.Command, args []string) error {
 type cmdProfileEdit struct {
 	global  *cmdGlobal
 	profile *cmdProfile
+
+	flagDryRun bool
 }
 
 func (c *cmdProfileEdit) command() *cobra.Command {
@@ -425,8 +430,12 @@ func (c *cmdProfileEdit) command() *cobra.Command {
 		`Edit profile configurations as YAML`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`lxc profile edit <profile> < profile.yaml
-    Update a profile using the content of profile.yaml`))
+    Update a profile using the content of profile.yaml
+
+lxc profile edit <profile> --dry-run < profile.yaml
+    Show the changes that updating the profile would make to the profile and to the instances using it`))
 
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the changes that would be made"))
 	cmd.RunE = c.run
 
 	return cmd
@@ -485,6 +494,16 @@ func (c *cmdProfileEdit) run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		if c.flagDryRun {
+			diff, err := resource.server.UpdateProfileDryRun(resource.name, newdata, "")
+			if err != nil {
+				return err
+			}
+
+			printConfigDiff(diff)
+			return nil
+		}
+
 		return resource.server.UpdateProfile(resource.name, newdata, "")
 	}
 
@@ -505,12 +524,17 @@ func (c *cmdProfileEdit) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var diff *api.ConfigDiff
 	for {
 		// Parse the text received from the editor
 		newdata := api.ProfilePut{}
 		err = yaml.Unmarshal(content, &newdata)
 		if err == nil {
-			err = resource.server.UpdateProfile(resource.name, newdata, etag)
+			if c.flagDryRun {
+				diff, err = resource.server.UpdateProfileDryRun(resource.name, newdata, etag)
+			} else {
+				err = resource.server.UpdateProfile(resource.name, newdata, etag)
+			}
 		}
 
 		// Respawn the editor
@@ -534,6 +558,10 @@ func (c *cmdProfileEdit) run(cmd *cobra.Command, args []string) error {
 		break
 	}
 
+	if diff != nil {
+		printConfigDiff(diff)
+	}
+
 	return nil
 }
 
@@ -243,20 +243,14 @@ func (c *cmdPublish) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Aliases already exists: %s"), strings.Join(names, ", "))
 	}
 
-	op, err := s.CreateImage(req, nil)
-	if err != nil {
-		return err
-	}
-
 	// Watch the background operation
 	progress := cli.ProgressRenderer{
 		Format: i18n.G("Publishing instance: %s"),
 		Quiet:  c.global.flagQuiet,
 	}
 
-	_, err = op.AddHandler(progress.UpdateOp)
+	op, err := s.CreateImage(req, &lxd.ImageCreateArgs{ProgressHandler: progress.UpdateProgress})
 	if err != nil {
-		progress.Done("")
 		return err
 	}
 
@@ -15,9 +15,10 @@ import (
 
 // Rebuild.
 type cmdRebuild struct {
-	global    *cmdGlobal
-	flagEmpty bool
-	flagForce bool
+	global       *cmdGlobal
+	flagEmpty    bool
+	flagForce    bool
+	flagPreserve []string
 }
 
 func (c *cmdRebuild) command() *cobra.Command {
@@ -30,6 +31,7 @@ func (c *cmdRebuild) command() *cobra.Command {
 	cmd.RunE = c.run
 	cmd.Flags().BoolVar(&c.flagEmpty, "empty", false, i18n.G("Rebuild as an empty instance"))
 	cmd.Flags().BoolVarP(&c.flagForce, "force", "f", false, i18n.G("If an instance is running, stop it and then rebuild it"))
+	cmd.Flags().StringArrayVar(&c.flagPreserve, "preserve", nil, i18n.G("Directory of the root filesystem to preserve across the rebuild (containers only, can be repeated)")+"``")
 
 	return cmd
 }
@@ -111,7 +113,8 @@ func (c *cmdRebuild) rebuild(conf *config.Config, args []string) error {
 
 	// Base request
 	req := api.InstanceRebuildPost{
-		Source: api.InstanceSource{},
+		Source:        api.InstanceSource{},
+		PreservePaths: c.flagPreserve,
 	}
 
 	if !c.flagEmpty {
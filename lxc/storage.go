@@ -33,6 +33,10 @@ func (c *cmdStorage) command() *cobra.Command {
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`Manage storage pools and volumes`))
 
+	// Benchmark
+	storageBenchmarkCmd := cmdStorageBenchmark{global: c.global, storage: c}
+	cmd.AddCommand(storageBenchmarkCmd.command())
+
 	// Create
 	storageCreateCmd := cmdStorageCreate{global: c.global, storage: c}
 	cmd.AddCommand(storageCreateCmd.command())
@@ -57,6 +61,10 @@ func (c *cmdStorage) command() *cobra.Command {
 	storageListCmd := cmdStorageList{global: c.global, storage: c}
 	cmd.AddCommand(storageListCmd.command())
 
+	// Migrate
+	storageMigrateCmd := cmdStorageMigrate{global: c.global, storage: c}
+	cmd.AddCommand(storageMigrateCmd.command())
+
 	// Set
 	storageSetCmd := cmdStorageSet{global: c.global, storage: c}
 	cmd.AddCommand(storageSetCmd.command())
@@ -211,6 +219,8 @@ func (c *cmdStorageDelete) run(cmd *cobra.Command, args []string) error {
 type cmdStorageEdit struct {
 	global  *cmdGlobal
 	storage *cmdStorage
+
+	flagDryRun bool
 }
 
 func (c *cmdStorageEdit) command() *cobra.Command {
@@ -223,6 +233,7 @@ func (c *cmdStorageEdit) command() *cobra.Command {
 		`lxc storage edit [<remote>:]<pool> < pool.yaml
     Update a storage pool using the content of pool.yaml.`))
 
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the changes that would be made"))
 	cmd.RunE = c.run
 
 	return cmd
@@ -277,6 +288,16 @@ func (c *cmdStorageEdit) run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		if c.flagDryRun {
+			diff, err := resource.server.UpdateStoragePoolDryRun(resource.name, newdata, "")
+			if err != nil {
+				return err
+			}
+
+			printConfigDiff(diff)
+			return nil
+		}
+
 		return resource.server.UpdateStoragePool(resource.name, newdata, "")
 	}
 
@@ -297,12 +318,17 @@ func (c *cmdStorageEdit) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var diff *api.ConfigDiff
 	for {
 		// Parse the text received from the editor
 		newdata := api.StoragePoolPut{}
 		err = yaml.Unmarshal(content, &newdata)
 		if err == nil {
-			err = resource.server.UpdateStoragePool(resource.name, newdata, etag)
+			if c.flagDryRun {
+				diff, err = resource.server.UpdateStoragePoolDryRun(resource.name, newdata, etag)
+			} else {
+				err = resource.server.UpdateStoragePool(resource.name, newdata, etag)
+			}
 		}
 
 		// Respawn the editor
@@ -326,6 +352,10 @@ func (c *cmdStorageEdit) run(cmd *cobra.Command, args []string) error {
 		break
 	}
 
+	if diff != nil {
+		printConfigDiff(diff)
+	}
+
 	return nil
 }
 
@@ -404,7 +434,15 @@ type cmdStorageInfo struct {
 	global  *cmdGlobal
 	storage *cmdStorage
 
-	flagBytes bool
+	flagBytes  bool
+	flagFormat string
+}
+
+// storagePoolInfo is the storage pool information rendered in the structured output formats.
+type storagePoolInfo struct {
+	api.StoragePool `yaml:",inline"`
+
+	Resources *api.ResourcesStoragePool `json:"resources" yaml:"resources"`
 }
 
 func (c *cmdStorageInfo) command() *cobra.Command {
@@ -416,6 +454,7 @@ func (c *cmdStorageInfo) command() *cobra.Command {
 
 	cmd.Flags().BoolVar(&c.flagBytes, "bytes", false, i18n.G("Show the used and free space in bytes"))
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
 	cmd.RunE = c.run
 
 	return cmd
@@ -460,6 +499,12 @@ func (c *cmdStorageInfo) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	info := storagePoolInfo{StoragePool: *pool, Resources: res}
+
+	return cli.RenderInfo(c.flagFormat, info, func() error { return c.renderInfo(pool, res) })
+}
+
+func (c *cmdStorageInfo) renderInfo(pool *api.StoragePool, res *api.ResourcesStoragePool) error {
 	// Declare the poolinfo map of maps in order to build up the yaml
 	poolinfo := make(map[string]map[string]string)
 	poolusedby := make(map[string]map[string][]string)
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// Benchmark.
+type cmdStorageBenchmark struct {
+	global  *cmdGlobal
+	storage *cmdStorage
+
+	flagSize   string
+	flagList   bool
+	flagFormat string
+}
+
+func (c *cmdStorageBenchmark) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("benchmark", i18n.G("[<remote>:]<pool>"))
+	cmd.Short = i18n.G("Benchmark storage pools")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Benchmark storage pools
+
+Runs a short I/O test against a temporary volume on the storage pool and records
+the sequential throughput, random IOPS and latency. The new result is shown
+together with the previous results of the same cluster member for comparison.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc storage benchmark default
+    Benchmark the "default" storage pool.
+
+lxc storage benchmark default --target lxd02 --size 1GiB
+    Benchmark the "default" storage pool on cluster member "lxd02" using a 1GiB test file.
+
+lxc storage benchmark default --list
+    Show the recorded benchmark results of the "default" storage pool.`))
+
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVar(&c.flagSize, "size", "", i18n.G("Size of the test file (defaults to 256MiB)")+"``")
+	cmd.Flags().BoolVar(&c.flagList, "list", false, i18n.G("Only show the recorded results without running a new benchmark"))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdStorageBenchmark) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	client := resource.server
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	// Targeting
+	if c.storage.flagTarget != "" {
+		if !client.IsClustered() {
+			return fmt.Errorf(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		client = client.UseTarget(c.storage.flagTarget)
+	}
+
+	location := ""
+	if !c.flagList {
+		op, err := client.CreateStoragePoolBenchmark(resource.name, api.StoragePoolBenchmarksPost{Size: c.flagSize})
+		if err != nil {
+			return err
+		}
+
+		// Watch the background operation
+		progress := cli.ProgressRenderer{
+			Format: i18n.G("Benchmarking storage pool: %s"),
+			Quiet:  c.global.flagQuiet,
+		}
+
+		_, err = op.AddHandler(progress.UpdateOp)
+		if err != nil {
+			progress.Done("")
+			return err
+		}
+
+		err = cli.CancelableWait(op, &progress)
+		if err != nil {
+			progress.Done("")
+			return err
+		}
+
+		progress.Done("")
+
+		// Only compare with the results of the member the benchmark ran on.
+		benchmark, ok := op.Get().Metadata["benchmark"].(map[string]any)
+		if ok {
+			location, _ = benchmark["location"].(string)
+		}
+	}
+
+	benchmarks, err := client.GetStoragePoolBenchmarks(resource.name)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04 MST"
+
+	clustered := client.IsClustered()
+
+	data := [][]string{}
+	rawData := []api.StoragePoolBenchmark{}
+	for _, benchmark := range benchmarks {
+		if location != "" && benchmark.Location != location {
+			continue
+		}
+
+		row := []string{strconv.FormatInt(benchmark.ID, 10)}
+		if clustered {
+			row = append(row, benchmark.Location)
+		}
+
+		row = append(row,
+			benchmark.CreatedAt.Local().Format(layout),
+			units.GetByteSizeStringIEC(benchmark.Size, 0),
+			units.GetByteSizeStringIEC(benchmark.SequentialRead, 2)+"/s",
+			units.GetByteSizeStringIEC(benchmark.SequentialWrite, 2)+"/s",
+			strconv.FormatInt(benchmark.RandomReadIOPS, 10),
+			strconv.FormatInt(benchmark.RandomWriteIOPS, 10),
+			fmt.Sprintf("%dµs", benchmark.RandomReadLatency),
+			fmt.Sprintf("%dµs", benchmark.RandomWriteLatency),
+		)
+
+		data = append(data, row)
+		rawData = append(rawData, benchmark)
+	}
+
+	header := []string{i18n.G("ID")}
+	if clustered {
+		header = append(header, i18n.G("LOCATION"))
+	}
+
+	header = append(header,
+		i18n.G("DATE"),
+		i18n.G("SIZE"),
+		i18n.G("SEQ READ"),
+		i18n.G("SEQ WRITE"),
+		i18n.G("RAND READ IOPS"),
+		i18n.G("RAND WRITE IOPS"),
+		i18n.G("READ LATENCY"),
+		i18n.G("WRITE LATENCY"),
+	)
+
+	return cli.RenderTable(c.flagFormat, header, data, rawData)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+// Migrate.
+type cmdStorageMigrate struct {
+	global  *cmdGlobal
+	storage *cmdStorage
+
+	flagDryRun bool
+	flagFormat string
+}
+
+func (c *cmdStorageMigrate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("migrate", i18n.G("[<remote>:]<pool> <target pool>"))
+	cmd.Short = i18n.G("Move all volumes of a storage pool to another pool")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Move all volumes of a storage pool to another pool
+
+Moves the volumes of all instances and all custom volumes, including their
+snapshots, to the target pool and updates the root disk devices of the moved
+instances. If a volume can't be moved, the volumes moved so far are moved back.
+
+All instances using the pool must be stopped. Image volumes aren't moved.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc storage migrate default zfs --dry-run
+    List the volumes that would be moved from the "default" storage pool to the "zfs" storage pool.
+
+lxc storage migrate default zfs
+    Move all volumes of the "default" storage pool to the "zfs" storage pool.`))
+
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only list the volumes that would be moved"))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdStorageMigrate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	client := resource.server
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	// Targeting
+	if c.storage.flagTarget != "" {
+		if !client.IsClustered() {
+			return fmt.Errorf(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		client = client.UseTarget(c.storage.flagTarget)
+	}
+
+	if c.flagDryRun {
+		volumes, err := client.GetStoragePoolMigrateVolumes(resource.name, args[1])
+		if err != nil {
+			return err
+		}
+
+		data := [][]string{}
+		for _, vol := range volumes {
+			data = append(data, []string{vol.Project, vol.Type, vol.Name, strconv.Itoa(vol.Snapshots)})
+		}
+
+		header := []string{
+			i18n.G("PROJECT"),
+			i18n.G("TYPE"),
+			i18n.G("NAME"),
+			i18n.G("SNAPSHOTS"),
+		}
+
+		return cli.RenderTable(c.flagFormat, header, data, volumes)
+	}
+
+	op, err := client.MigrateStoragePool(resource.name, api.StoragePoolMigratePost{Pool: args[1]})
+	if err != nil {
+		return err
+	}
+
+	// Watch the background operation
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Moving volume: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage pool %s migrated to %s")+"\n", resource.name, args[1])
+	}
+
+	return nil
+}
@@ -3,9 +3,12 @@ package main
 import (
 	"fmt"
 	"io"
+	"net"
 	"net/url"
 	"os"
+	"os/exec"
 	"path"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,7 +22,6 @@ import (
 	"github.com/canonical/lxd/shared/api"
 	cli "github.com/canonical/lxd/shared/cmd"
 	"github.com/canonical/lxd/shared/i18n"
-	"github.com/canonical/lxd/shared/ioprogress"
 	"github.com/canonical/lxd/shared/termios"
 	"github.com/canonical/lxd/shared/units"
 )
@@ -117,6 +119,10 @@ Unless specified through a prefix, all volume operations affect "custom" (user c
 	storageVolumeSnapshotCmd := cmdStorageVolumeSnapshot{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeSnapshotCmd.command())
 
+	// Mount
+	storageVolumeMountCmd := cmdStorageVolumeMount{global: c.global, storage: c.storage, storageVolume: c}
+	cmd.AddCommand(storageVolumeMountCmd.command())
+
 	// Restore
 	storageVolumeRestoreCmd := cmdStorageVolumeRestore{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeRestoreCmd.command())
@@ -478,6 +484,12 @@ func (c *cmdStorageVolumeCopy) run(cmd *cobra.Command, args []string) error {
 		srcVol.Description = srcVolSnapshot.Description
 	}
 
+	// Register progress handler
+	progress := cli.ProgressRenderer{
+		Format: opMsg,
+		Quiet:  c.global.flagQuiet,
+	}
+
 	if cmd.Name() == "move" && srcServer == dstServer {
 		args := &lxd.StoragePoolVolumeMoveArgs{}
 		args.Name = dstVolName
@@ -489,12 +501,19 @@ func (c *cmdStorageVolumeCopy) run(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
+
+		_, err = op.AddHandler(progress.UpdateOp)
+		if err != nil {
+			progress.Done("")
+			return err
+		}
 	} else {
 		args := &lxd.StoragePoolVolumeCopyArgs{}
 		args.Name = dstVolName
 		args.Mode = mode
 		args.VolumeOnly = c.flagVolumeOnly
 		args.Refresh = c.flagRefresh
+		args.ProgressHandler = progress.UpdateProgress
 
 		if c.flagTargetProject != "" {
 			dstServer = dstServer.UseProject(c.flagTargetProject)
@@ -506,18 +525,6 @@ func (c *cmdStorageVolumeCopy) run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Register progress handler
-	progress := cli.ProgressRenderer{
-		Format: opMsg,
-		Quiet:  c.global.flagQuiet,
-	}
-
-	_, err = op.AddHandler(progress.UpdateOp)
-	if err != nil {
-		progress.Done("")
-		return err
-	}
-
 	// Wait for operation to finish
 	err = cli.CancelableWait(op, &progress)
 	if err != nil {
@@ -1189,6 +1196,17 @@ type cmdStorageVolumeInfo struct {
 	global        *cmdGlobal
 	storage       *cmdStorage
 	storageVolume *cmdStorageVolume
+
+	flagFormat string
+}
+
+// storageVolumeInfo is the storage volume information rendered in the structured output formats.
+type storageVolumeInfo struct {
+	api.StorageVolume `yaml:",inline"`
+
+	State     *api.StorageVolumeState       `json:"state" yaml:"state"`
+	Snapshots []api.StorageVolumeSnapshot   `json:"snapshots" yaml:"snapshots"`
+	Backups   []api.StoragePoolVolumeBackup `json:"backups" yaml:"backups"`
 }
 
 func (c *cmdStorageVolumeInfo) command() *cobra.Command {
@@ -1208,6 +1226,7 @@ lxc storage volume info default virtual-machine/data
     Returns state information for a virtual machine "data" in pool "default".`))
 
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
 	cmd.RunE = c.run
 
 	return cmd
@@ -1278,6 +1297,17 @@ func (c *cmdStorageVolumeInfo) run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	info := storageVolumeInfo{
+		StorageVolume: *vol,
+		State:         volState,
+		Snapshots:     volSnapshots,
+		Backups:       volBackups,
+	}
+
+	return cli.RenderInfo(c.flagFormat, info, func() error { return c.renderInfo(client, vol, volState, volSnapshots, volBackups) })
+}
+
+func (c *cmdStorageVolumeInfo) renderInfo(client lxd.InstanceServer, vol *api.StorageVolume, volState *api.StorageVolumeState, volSnapshots []api.StorageVolumeSnapshot, volBackups []api.StoragePoolVolumeBackup) error {
 	// Render the overview.
 	const layout = "2006/01/02 15:04 MST"
 
@@ -1313,6 +1343,35 @@ func (c *cmdStorageVolumeInfo) run(cmd *cobra.Command, args []string) error {
 		fmt.Printf(i18n.G("Created: %s")+"\n", vol.CreatedAt.Local().Format(layout))
 	}
 
+	// List I/O counters of the running instances using the volume
+	if volState != nil && len(volState.IO) > 0 {
+		fmt.Println("\n" + i18n.G("I/O:"))
+
+		ioData := [][]string{}
+		for _, io := range volState.IO {
+			ioData = append(ioData, []string{
+				io.Instance,
+				io.Device,
+				units.GetByteSizeStringIEC(int64(io.ReadBytes), 2),
+				strconv.FormatUint(io.ReadsCompleted, 10),
+				units.GetByteSizeStringIEC(int64(io.WrittenBytes), 2),
+				strconv.FormatUint(io.WritesCompleted, 10),
+			})
+		}
+
+		sort.Sort(cli.SortColumnsNaturally(ioData))
+		ioHeader := []string{
+			i18n.G("Instance"),
+			i18n.G("Device"),
+			i18n.G("Read"),
+			i18n.G("Reads"),
+			i18n.G("Written"),
+			i18n.G("Writes"),
+		}
+
+		_ = cli.RenderTable(cli.TableFormatTable, ioHeader, ioData, volState.IO)
+	}
+
 	// List snapshots
 	firstSnapshot := true
 	if len(volSnapshots) > 0 {
@@ -2315,6 +2374,115 @@ func (c *cmdStorageVolumeRestore) run(cmd *cobra.Command, args []string) error {
 	return client.UpdateStoragePoolVolume(resource.name, "custom", args[1], req, etag)
 }
 
+// Mount.
+type cmdStorageVolumeMount struct {
+	global        *cmdGlobal
+	storage       *cmdStorage
+	storageVolume *cmdStorageVolume
+	fileMount     cmdFileMount
+}
+
+func (c *cmdStorageVolumeMount) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("mount", i18n.G("[<remote>:]<pool> <volume>/<snapshot> [<target path>]"))
+	cmd.Short = i18n.G("Mount storage volume snapshots")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Mount storage volume snapshots
+
+The snapshot is exposed read-only for file-level recovery. The server stops exposing it
+once it is no longer in use or after one hour.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc storage volume mount default data/snap0 restore
+   To mount the snapshot snap0 of the custom volume data onto the local restore directory.`))
+
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVar(&c.fileMount.flagListen, "listen", "", i18n.G("Setup SSH SFTP listener on address:port instead of mounting"))
+	cmd.Flags().BoolVar(&c.fileMount.flagAuthNone, "no-auth", false, i18n.G("Disable authentication when using SSH SFTP listener"))
+	cmd.Flags().StringVar(&c.fileMount.flagAuthUser, "auth-user", "", i18n.G("Set authentication user when using SSH SFTP listener"))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdStorageVolumeMount) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	volName, snapName, isSnapshot := api.GetParentAndSnapshotName(args[1])
+	if !isSnapshot {
+		return fmt.Errorf(i18n.G("Only storage volume snapshots can be mounted"))
+	}
+
+	client := resource.server
+
+	// Use the provided target.
+	if c.storage.flagTarget != "" {
+		client = client.UseTarget(c.storage.flagTarget)
+	}
+
+	var targetPath string
+
+	// Determine the target if specified.
+	if len(args) >= 3 {
+		targetPath = shared.HostPathFollow(filepath.Clean(args[2]))
+		sb, err := os.Stat(targetPath)
+		if err != nil {
+			return err
+		}
+
+		if !sb.IsDir() {
+			return fmt.Errorf(i18n.G("Target path must be a directory"))
+		}
+	}
+
+	// Check which mode we should operate in. If target path is provided we use sshfs mode.
+	if targetPath != "" && c.fileMount.flagListen != "" {
+		return fmt.Errorf(i18n.G("Target path and --listen flag cannot be used together"))
+	}
+
+	// Check the snapshot exists.
+	_, _, err = client.GetStoragePoolVolumeSnapshot(resource.name, "custom", volName, snapName)
+	if err != nil {
+		return err
+	}
+
+	if c.fileMount.flagListen == "" && targetPath != "" {
+		sshfsPath, err := exec.LookPath("sshfs")
+		if err != nil {
+			// If sshfs command not found, then advise user of the --listen flag.
+			return fmt.Errorf(i18n.G("sshfs not found. Try SSH SFTP mode using the --listen flag"))
+		}
+
+		sftpConn, err := client.GetStoragePoolVolumeSnapshotSFTPConn(resource.name, "custom", volName, snapName)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed connecting to storage volume snapshot SFTP: %w"), err)
+		}
+
+		defer func() { _ = sftpConn.Close() }()
+
+		return c.fileMount.sshfsMount(cmd.Context(), sftpConn, fmt.Sprintf("%s_%s", volName, snapName), "/", sshfsPath, targetPath)
+	}
+
+	return c.fileMount.sshSFTPServer(cmd.Context(), func() (net.Conn, error) {
+		return client.GetStoragePoolVolumeSnapshotSFTPConn(resource.name, "custom", volName, snapName)
+	})
+}
+
 // Export.
 type cmdStorageVolumeExport struct {
 	global        *cmdGlobal
@@ -2477,21 +2645,29 @@ type cmdStorageVolumeImport struct {
 	storage       *cmdStorage
 	storageVolume *cmdStorageVolume
 
-	flagType string
+	flagType     string
+	flagChecksum string
 }
 
 func (c *cmdStorageVolumeImport) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("import", i18n.G("[<remote>:]<pool> <backup file> [<volume name>]"))
+	cmd.Use = usage("import", i18n.G("[<remote>:]<pool> <backup file>|<URL> [<volume name>]"))
 	cmd.Short = i18n.G("Import custom storage volumes")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Import backups of custom volumes including their snapshots.`))
+		`Import backups of custom volumes including their snapshots.
+
+ISO images can also be imported from an HTTP or HTTPS URL, in which case the
+server downloads the image directly.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`lxc storage volume import default backup0.tar.gz
-		Create a new custom volume using backup0.tar.gz as the source.`))
+		Create a new custom volume using backup0.tar.gz as the source.
+
+lxc storage volume import default https://example.com/disk.img disk --checksum sha256:<digest>
+		Create a new ISO volume named disk from an image downloaded by the server.`))
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.RunE = c.run
 	cmd.Flags().StringVar(&c.flagType, "type", "", i18n.G("Import type, backup or iso (default \"backup\")")+"``")
+	cmd.Flags().StringVar(&c.flagChecksum, "checksum", "", i18n.G("Expected checksum of an image imported from a URL (<algorithm>:<digest>)")+"``")
 
 	return cmd
 }
@@ -2521,6 +2697,19 @@ func (c *cmdStorageVolumeImport) run(cmd *cobra.Command, args []string) error {
 		d = d.UseTarget(c.storage.flagTarget)
 	}
 
+	volName := ""
+	if len(args) >= 3 {
+		volName = args[2]
+	}
+
+	if strings.HasPrefix(args[1], "http://") || strings.HasPrefix(args[1], "https://") {
+		return c.importURL(d, pool, args[1], volName)
+	}
+
+	if c.flagChecksum != "" {
+		return fmt.Errorf(i18n.G("--checksum can only be used when importing from a URL"))
+	}
+
 	file, err := os.Open(shared.HostPathFollow(args[1]))
 	if err != nil {
 		return err
@@ -2533,11 +2722,6 @@ func (c *cmdStorageVolumeImport) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	volName := ""
-	if len(args) >= 3 {
-		volName = args[2]
-	}
-
 	if c.flagType == "" {
 		// Set type to iso if filename suffix is .iso
 		if strings.HasSuffix(file.Name(), ".iso") {
@@ -2562,16 +2746,10 @@ func (c *cmdStorageVolumeImport) run(cmd *cobra.Command, args []string) error {
 	}
 
 	createArgs := lxd.StoragePoolVolumeBackupArgs{
-		BackupFile: &ioprogress.ProgressReader{
-			ReadCloser: file,
-			Tracker: &ioprogress.ProgressTracker{
-				Length: fstat.Size(),
-				Handler: func(percent int64, speed int64) {
-					progress.UpdateProgress(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
-				},
-			},
-		},
-		Name: volName,
+		BackupFile:      file,
+		Size:            fstat.Size(),
+		ProgressHandler: progress.UpdateProgress,
+		Name:            volName,
 	}
 
 	var op lxd.Operation
@@ -2597,3 +2775,50 @@ func (c *cmdStorageVolumeImport) run(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// importURL has the server download an ISO image from a URL into a new custom volume.
+func (c *cmdStorageVolumeImport) importURL(d lxd.InstanceServer, pool string, sourceURL string, volName string) error {
+	if c.flagType != "" && c.flagType != "iso" {
+		return fmt.Errorf(i18n.G("Only ISO images can be imported from a URL"))
+	}
+
+	if volName == "" {
+		return fmt.Errorf(i18n.G("Importing from a URL requires a volume name to be set"))
+	}
+
+	req := api.StorageVolumesPost{
+		Name:        volName,
+		ContentType: "iso",
+		Source: api.StorageVolumeSource{
+			URL:      sourceURL,
+			Checksum: c.flagChecksum,
+		},
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Importing custom volume: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	op, err := d.CreateStoragePoolVolumeFromURL(pool, req)
+	if err != nil {
+		return err
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// Wait for operation to finish.
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	return nil
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+	"github.com/canonical/lxd/shared/units"
+)
+
+type cmdTop struct {
+	global *cmdGlobal
+
+	flagAllProjects bool
+	flagRefresh     int
+	flagSort        string
+}
+
+// topSource is a server (or cluster member) the metrics are gathered from.
+type topSource struct {
+	remote   string
+	location string
+	server   lxd.InstanceServer
+}
+
+// topCounters holds the cumulative metrics of an instance at a given time.
+type topCounters struct {
+	cpuSeconds   float64
+	memoryTotal  float64
+	memoryAvail  float64
+	diskRead     float64
+	diskWritten  float64
+	instanceType string
+	sampledAt    time.Time
+}
+
+// topInstance represents a row of the top table.
+// Rates are negative until two samples of the instance are available.
+type topInstance struct {
+	remote   string
+	location string
+	project  string
+	name     string
+	instType string
+
+	cpu       float64
+	memory    float64
+	diskRead  float64
+	diskWrite float64
+}
+
+// topSample is a single sample parsed from the OpenMetrics text.
+type topSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+func (c *cmdTop) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("top", i18n.G("[<remote>:]..."))
+	cmd.Short = i18n.G("Display the resource usage of instances")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Display the resource usage of instances
+
+The table is refreshed continuously from the metrics of the servers and sorted by
+CPU, memory or disk I/O usage. Only running instances are shown.
+
+The metrics are cached by the server for a few seconds, so refresh intervals
+below 10 seconds may show stale values.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc top
+    Show the instances of the current project sorted by CPU usage.
+
+lxc top local: remote1: --all-projects --sort memory
+    Show the instances of all projects on two remotes sorted by memory usage.`))
+
+	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, i18n.G("Display instances from all projects"))
+	cmd.Flags().IntVar(&c.flagRefresh, "refresh", 10, i18n.G("Refresh interval in seconds")+"``")
+	cmd.Flags().StringVar(&c.flagSort, "sort", "cpu", i18n.G("Sort column (cpu|memory|disk)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdTop) run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, -1)
+	if exit {
+		return err
+	}
+
+	if c.global.flagProject != "" && c.flagAllProjects {
+		return fmt.Errorf(i18n.G("Can't specify --project with --all-projects"))
+	}
+
+	if !shared.ValueInSlice(c.flagSort, []string{"cpu", "memory", "disk"}) {
+		return fmt.Errorf(i18n.G("Invalid sort column: %s"), c.flagSort)
+	}
+
+	if c.flagRefresh < 1 {
+		return fmt.Errorf(i18n.G("The refresh interval must be at least 1 second"))
+	}
+
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	// Connect to the remotes and their cluster members.
+	sources := []topSource{}
+	remotes := []string{}
+	for _, arg := range args {
+		remote, _, err := conf.ParseRemote(arg)
+		if err != nil {
+			return err
+		}
+
+		if shared.ValueInSlice(remote, remotes) {
+			continue
+		}
+
+		remotes = append(remotes, remote)
+
+		d, err := conf.GetInstanceServer(remote)
+		if err != nil {
+			return err
+		}
+
+		if c.flagAllProjects {
+			d = d.UseProject("")
+		} else {
+			info, err := d.GetConnectionInfo()
+			if err != nil {
+				return err
+			}
+
+			if info.Project == "" {
+				d = d.UseProject("default")
+			}
+		}
+
+		if !d.IsClustered() {
+			sources = append(sources, topSource{remote: remote, server: d})
+			continue
+		}
+
+		// The metrics only cover the instances of the member handling the request.
+		members, err := d.GetClusterMemberNames()
+		if err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			sources = append(sources, topSource{remote: remote, location: member, server: d.UseTarget(member)})
+		}
+	}
+
+	previous := map[string]topCounters{}
+	for {
+		rows, current, err := c.collect(sources, previous)
+		if err != nil {
+			return err
+		}
+
+		previous = current
+
+		err = c.render(rows, len(remotes) > 1)
+		if err != nil {
+			return err
+		}
+
+		time.Sleep(time.Duration(c.flagRefresh) * time.Second)
+	}
+}
+
+// collect gathers the metrics of all sources and computes the usage of each instance since the previous sample.
+func (c *cmdTop) collect(sources []topSource, previous map[string]topCounters) ([]topInstance, map[string]topCounters, error) {
+	rows := []topInstance{}
+	current := map[string]topCounters{}
+
+	for _, source := range sources {
+		text, err := source.server.GetMetrics()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		samples, err := topParseMetrics(text)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		now := time.Now()
+		instances := map[string]*topCounters{}
+		projects := map[string]string{}
+		names := map[string]string{}
+
+		for _, sample := range samples {
+			name := sample.labels["name"]
+			project := sample.labels["project"]
+			if name == "" || project == "" {
+				continue
+			}
+
+			key := strings.Join([]string{source.remote, source.location, project, name}, "/")
+			counters, ok := instances[key]
+			if !ok {
+				counters = &topCounters{instanceType: sample.labels["type"], sampledAt: now}
+				instances[key] = counters
+				projects[key] = project
+				names[key] = name
+			}
+
+			switch sample.name {
+			case "lxd_cpu_seconds_total":
+				if !shared.ValueInSlice(sample.labels["mode"], []string{"idle", "iowait", "steal"}) {
+					counters.cpuSeconds += sample.value
+				}
+
+			case "lxd_memory_MemTotal_bytes":
+				counters.memoryTotal = sample.value
+			case "lxd_memory_MemAvailable_bytes":
+				counters.memoryAvail = sample.value
+			case "lxd_disk_read_bytes_total":
+				counters.diskRead += sample.value
+			case "lxd_disk_written_bytes_total":
+				counters.diskWritten += sample.value
+			}
+		}
+
+		for key, counters := range instances {
+			row := topInstance{
+				remote:    source.remote,
+				location:  source.location,
+				project:   projects[key],
+				name:      names[key],
+				instType:  counters.instanceType,
+				memory:    counters.memoryTotal - counters.memoryAvail,
+				cpu:       -1,
+				diskRead:  -1,
+				diskWrite: -1,
+			}
+
+			prev, ok := previous[key]
+			elapsed := counters.sampledAt.Sub(prev.sampledAt).Seconds()
+			if ok && elapsed > 0 {
+				row.cpu = max(counters.cpuSeconds-prev.cpuSeconds, 0) / elapsed * 100
+				row.diskRead = max(counters.diskRead-prev.diskRead, 0) / elapsed
+				row.diskWrite = max(counters.diskWritten-prev.diskWritten, 0) / elapsed
+			}
+
+			rows = append(rows, row)
+			current[key] = *counters
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+
+		var valueA, valueB float64
+		switch c.flagSort {
+		case "cpu":
+			valueA, valueB = a.cpu, b.cpu
+		case "memory":
+			valueA, valueB = a.memory, b.memory
+		case "disk":
+			valueA, valueB = a.diskRead+a.diskWrite, b.diskRead+b.diskWrite
+		}
+
+		if valueA != valueB {
+			return valueA > valueB
+		}
+
+		return a.name < b.name
+	})
+
+	return rows, current, nil
+}
+
+// render displays the table, replacing the previous one when writing to a terminal.
+func (c *cmdTop) render(rows []topInstance, multipleRemotes bool) error {
+	clustered := false
+	for _, row := range rows {
+		if row.location != "" {
+			clustered = true
+			break
+		}
+	}
+
+	header := []string{i18n.G("NAME")}
+	if c.flagAllProjects {
+		header = append(header, i18n.G("PROJECT"))
+	}
+
+	if multipleRemotes {
+		header = append(header, i18n.G("REMOTE"))
+	}
+
+	if clustered {
+		header = append(header, i18n.G("LOCATION"))
+	}
+
+	header = append(header, i18n.G("TYPE"), i18n.G("CPU"), i18n.G("MEMORY"), i18n.G("DISK READ"), i18n.G("DISK WRITE"))
+
+	rate := func(value float64, format func(float64) string) string {
+		if value < 0 {
+			return "-"
+		}
+
+		return format(value)
+	}
+
+	data := [][]string{}
+	for _, row := range rows {
+		line := []string{row.name}
+		if c.flagAllProjects {
+			line = append(line, row.project)
+		}
+
+		if multipleRemotes {
+			line = append(line, row.remote)
+		}
+
+		if clustered {
+			line = append(line, row.location)
+		}
+
+		line = append(line,
+			strings.ToUpper(row.instType),
+			rate(row.cpu, func(v float64) string { return fmt.Sprintf("%.1f%%", v) }),
+			units.GetByteSizeStringIEC(int64(row.memory), 2),
+			rate(row.diskRead, func(v float64) string { return units.GetByteSizeStringIEC(int64(v), 2) + "/s" }),
+			rate(row.diskWrite, func(v float64) string { return units.GetByteSizeStringIEC(int64(v), 2) + "/s" }),
+		)
+
+		data = append(data, line)
+	}
+
+	if termios.IsTerminal(getStdoutFd()) {
+		// Move the cursor home and clear the screen.
+		fmt.Print("\033[H\033[2J")
+	}
+
+	fmt.Printf(i18n.G("Instances: %d (refreshed at %s)")+"\n", len(rows), time.Now().Format(time.TimeOnly))
+
+	return cli.RenderTable(cli.TableFormatTable, header, data, rows)
+}
+
+// topParseMetrics parses the samples of the OpenMetrics text returned by the server.
+func topParseMetrics(text string) ([]topSample, error) {
+	samples := []topSample{}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sample := topSample{labels: map[string]string{}}
+
+		// The value follows the last space of the line.
+		pos := strings.LastIndex(line, " ")
+		if pos < 0 {
+			return nil, fmt.Errorf("Invalid metrics line %q", line)
+		}
+
+		value, err := strconv.ParseFloat(line[pos+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid metrics value in line %q: %w", line, err)
+		}
+
+		sample.value = value
+		metric := line[:pos]
+
+		start := strings.Index(metric, "{")
+		if start < 0 {
+			sample.name = metric
+			samples = append(samples, sample)
+			continue
+		}
+
+		if !strings.HasSuffix(metric, "}") {
+			return nil, fmt.Errorf("Invalid metrics labels in line %q", line)
+		}
+
+		sample.name = metric[:start]
+
+		// Parse the comma separated key="value" labels, handling escaped characters in values.
+		labels := metric[start+1 : len(metric)-1]
+		for labels != "" {
+			eq := strings.Index(labels, `="`)
+			if eq < 0 {
+				return nil, fmt.Errorf("Invalid metrics labels in line %q", line)
+			}
+
+			key := labels[:eq]
+			labels = labels[eq+2:]
+
+			var value strings.Builder
+			end := -1
+			for i := 0; i < len(labels); i++ {
+				if labels[i] == '\\' && i+1 < len(labels) {
+					i++
+					switch labels[i] {
+					case 'n':
+						value.WriteByte('\n')
+					default:
+						value.WriteByte(labels[i])
+					}
+
+					continue
+				}
+
+				if labels[i] == '"' {
+					end = i
+					break
+				}
+
+				value.WriteByte(labels[i])
+			}
+
+			if end < 0 {
+				return nil, fmt.Errorf("Invalid metrics labels in line %q", line)
+			}
+
+			sample.labels[key] = value.String()
+			labels = strings.TrimPrefix(labels[end+1:], ",")
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type topTestSuite struct {
+	suite.Suite
+}
+
+func TestTopTestSuite(t *testing.T) {
+	suite.Run(t, new(topTestSuite))
+}
+
+func (s *topTestSuite) TestParseMetrics() {
+	text := `# HELP lxd_cpu_seconds_total The total number of CPU time used in seconds.
+# TYPE lxd_cpu_seconds_total counter
+lxd_cpu_seconds_total{cpu="0",mode="user",name="c1",project="default",type="container"} 12.5
+lxd_disk_read_bytes_total{device="sda",name="say \"hi\"\\",project="p1",type="virtual-machine"} 1024
+lxd_uptime_seconds 42
+`
+
+	samples, err := topParseMetrics(text)
+	s.Require().NoError(err)
+	s.Require().Len(samples, 3)
+
+	s.Equal("lxd_cpu_seconds_total", samples[0].name)
+	s.Equal(map[string]string{"cpu": "0", "mode": "user", "name": "c1", "project": "default", "type": "container"}, samples[0].labels)
+	s.Equal(12.5, samples[0].value)
+
+	s.Equal(`say "hi"\`, samples[1].labels["name"])
+	s.Equal("p1", samples[1].labels["project"])
+	s.Equal(float64(1024), samples[1].value)
+
+	s.Equal("lxd_uptime_seconds", samples[2].name)
+	s.Empty(samples[2].labels)
+	s.Equal(float64(42), samples[2].value)
+}
+
+func (s *topTestSuite) TestParseMetricsInvalid() {
+	for _, line := range []string{
+		"lxd_uptime_seconds",
+		"lxd_uptime_seconds abc",
+		`lxd_cpu_seconds_total{name="c1" 1`,
+		`lxd_cpu_seconds_total{name="c1} 1`,
+	} {
+		_, err := topParseMetrics(line)
+		s.Error(err, line)
+	}
+}
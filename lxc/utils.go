@@ -452,3 +452,40 @@ func (c *locationHeaderTransport) RoundTrip(r *http.Request) (*http.Response, er
 func (c *locationHeaderTransport) Transport() *http.Transport {
 	return c.transport
 }
+
+// printConfigDiff prints the changes that would be made by an update performed as a dry run.
+func printConfigDiff(diff *api.ConfigDiff) {
+	printEntries := func(entries []api.ConfigDiffEntry) {
+		for _, entry := range entries {
+			switch entry.Action {
+			case "added":
+				fmt.Printf("  + %s: %s\n", entry.Key, entry.New)
+			case "removed":
+				fmt.Printf("  - %s: %s\n", entry.Key, entry.Old)
+			default:
+				fmt.Printf("  ~ %s: %s -> %s\n", entry.Key, entry.Old, entry.New)
+			}
+		}
+	}
+
+	if len(diff.Changes) == 0 {
+		fmt.Println(i18n.G("No changes"))
+	} else {
+		fmt.Println(i18n.G("Changes:"))
+		printEntries(diff.Changes)
+	}
+
+	instNames := make([]string, 0, len(diff.Instances))
+	for instName, entries := range diff.Instances {
+		if len(entries) > 0 {
+			instNames = append(instNames, instName)
+		}
+	}
+
+	sort.Strings(instNames)
+
+	for _, instName := range instNames {
+		fmt.Printf(i18n.G("Expanded configuration of instance %q:")+"\n", instName)
+		printEntries(diff.Instances[instName])
+	}
+}
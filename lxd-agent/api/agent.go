@@ -18,3 +18,14 @@ type API10Put struct {
 	// Example: true
 	Devlxd bool `json:"devlxd" yaml:"devlxd"`
 }
+
+// ExecSessionPost contains the fields used to re-attach to the output of a non-interactive exec session.
+type ExecSessionPost struct {
+	// Offset in the standard output of the command to resume from
+	// Example: 1024
+	StdoutOffset int64 `json:"stdout_offset" yaml:"stdout_offset"`
+
+	// Offset in the standard error of the command to resume from
+	// Example: 0
+	StderrOffset int64 `json:"stderr_offset" yaml:"stderr_offset"`
+}
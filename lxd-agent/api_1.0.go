@@ -28,6 +28,7 @@ var api10Cmd = APIEndpoint{
 var api10 = []APIEndpoint{
 	api10Cmd,
 	execCmd,
+	execSessionCmd,
 	eventsCmd,
 	metricsCmd,
 	operationsCmd,
@@ -116,39 +116,44 @@ var devlxdConfigKeyGet = devLxdHandler{"/1.0/config/{key}", func(d *Daemon, w ht
 	return okResponse(value, "raw")
 }}
 
-var devlxdMetadataGet = devLxdHandler{"/1.0/meta-data", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
-	var client lxd.InstanceServer
-	var err error
-
-	for i := 0; i < 10; i++ {
-		client, err = getVsockClient(d)
-		if err == nil {
-			break
-		}
+var devlxdMetadataGet = devlxdCloudInitDataGet("meta-data")
 
-		time.Sleep(500 * time.Millisecond)
-	}
+// devlxdCloudInitDataGet returns a handler forwarding requests for the cloud-init data of the given kind to LXD.
+func devlxdCloudInitDataGet(kind string) devLxdHandler {
+	return devLxdHandler{"/1.0/" + kind, func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+		var client lxd.InstanceServer
+		var err error
 
-	if err != nil {
-		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
-	}
+		for i := 0; i < 10; i++ {
+			client, err = getVsockClient(d)
+			if err == nil {
+				break
+			}
 
-	defer client.Disconnect()
+			time.Sleep(500 * time.Millisecond)
+		}
 
-	resp, _, err := client.RawQuery("GET", "/1.0/meta-data", nil, "")
-	if err != nil {
-		return smartResponse(err)
-	}
+		if err != nil {
+			return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+		}
 
-	var metaData string
+		defer client.Disconnect()
 
-	err = resp.MetadataAsStruct(&metaData)
-	if err != nil {
-		return smartResponse(fmt.Errorf("Failed parsing response from LXD: %w", err))
-	}
+		resp, _, err := client.RawQuery("GET", "/1.0/"+kind, nil, "")
+		if err != nil {
+			return smartResponse(err)
+		}
 
-	return okResponse(metaData, "raw")
-}}
+		var data string
+
+		err = resp.MetadataAsStruct(&data)
+		if err != nil {
+			return smartResponse(fmt.Errorf("Failed parsing response from LXD: %w", err))
+		}
+
+		return okResponse(data, "raw")
+	}}
+}
 
 var devLxdEventsGet = devLxdHandler{"/1.0/events", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
 	err := eventsGet(d, r).Render(w)
@@ -216,6 +221,33 @@ var devlxdDevicesGet = devLxdHandler{"/1.0/devices", func(d *Daemon, w http.Resp
 	return okResponse(devices, "json")
 }}
 
+var devlxdTokenPost = devLxdHandler{"/1.0/token", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+	if r.Method != "POST" {
+		return &devLxdResponse{fmt.Sprintf("method %q not allowed", r.Method), http.StatusBadRequest, "raw"}
+	}
+
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	resp, _, err := client.RawQuery("POST", "/1.0/token", nil, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	var token api.DevLXDToken
+
+	err = resp.MetadataAsStruct(&token)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed parsing response from LXD: %w", err))
+	}
+
+	return okResponse(token, "json")
+}}
+
 var handlers = []devLxdHandler{
 	{"/", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
 		return okResponse([]string{"/1.0"}, "json")
@@ -224,8 +256,12 @@ var handlers = []devLxdHandler{
 	devlxdConfigGet,
 	devlxdConfigKeyGet,
 	devlxdMetadataGet,
+	devlxdCloudInitDataGet("user-data"),
+	devlxdCloudInitDataGet("vendor-data"),
+	devlxdCloudInitDataGet("network-config"),
 	devLxdEventsGet,
 	devlxdDevicesGet,
+	devlxdTokenPost,
 }
 
 func hoistReq(f func(*Daemon, http.ResponseWriter, *http.Request) *devLxdResponse, d *Daemon) func(http.ResponseWriter, *http.Request) {
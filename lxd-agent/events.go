@@ -8,9 +8,12 @@ import (
 	"strings"
 	"time"
 
+	"golang.org/x/sys/unix"
+
 	"github.com/canonical/lxd/lxd/events"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
@@ -122,6 +125,9 @@ func eventsProcess(event api.Event) {
 		Config map[string]string         `json:"config"`
 		Name   string                    `json:"name"`
 		Mount  instancetype.VMAgentMount `json:"mount"`
+
+		// Set when the agent.mounts option of the instance is disabled.
+		SkipMount bool `json:"skip_mount"`
 	}
 
 	e := deviceEvent{}
@@ -130,33 +136,57 @@ func eventsProcess(event api.Event) {
 		return
 	}
 
-	// Only care about device additions, we don't try to handle remove.
-	if e.Action != "added" {
-		return
-	}
-
 	// We only handle disk hotplug.
 	if e.Config["type"] != "disk" {
 		return
 	}
 
-	// And only for path based devices.
-	if e.Config["path"] == "" {
+	// And only for path based devices that LXD didn't ask to be left alone.
+	if e.Config["path"] == "" || e.SkipMount {
 		return
 	}
 
-	// Attempt to perform the mount.
-	mntSource := fmt.Sprintf("lxd_%s", e.Name)
-	if e.Mount.Source != "" {
-		mntSource = e.Mount.Source
+	// Fall back to the device config for events from LXD versions that don't provide the mount details.
+	if e.Mount.Target == "" {
+		e.Mount.Target = e.Config["path"]
+	}
+
+	if e.Mount.Source == "" {
+		e.Mount.Source = fmt.Sprintf("lxd_%s", e.Name)
 	}
 
-	l := logger.AddContext(logger.Ctx{"type": "virtiofs", "source": mntSource, "path": e.Config["path"]})
+	// Convert relative mounts to absolute from / otherwise dir creation fails or mount fails.
+	if !strings.HasPrefix(e.Mount.Target, "/") {
+		e.Mount.Target = fmt.Sprintf("/%s", e.Mount.Target)
+	}
+
+	switch e.Action {
+	case "added":
+		eventsMountHotplug(e.Mount)
+	case "removed":
+		eventsUnmountHotplug(e.Mount)
+	}
+}
 
-	_ = os.MkdirAll(e.Config["path"], 0755)
+// eventsMountHotplug mounts a virtio-fs share that was hotplugged into the VM.
+func eventsMountHotplug(mount instancetype.VMAgentMount) {
+	l := logger.AddContext(logger.Ctx{"type": "virtiofs", "source": mount.Source, "path": mount.Target})
+
+	if filesystem.IsMountPoint(mount.Target) {
+		l.Info("Hotplug already mounted")
+		return
+	}
 
+	_ = os.MkdirAll(mount.Target, 0755)
+
+	args := []string{"-t", "virtiofs", mount.Source, mount.Target}
+	for _, opt := range mount.Options {
+		args = append(args, "-o", opt)
+	}
+
+	var err error
 	for i := 0; i < 5; i++ {
-		_, err = shared.RunCommand("mount", "-t", "virtiofs", mntSource, e.Config["path"])
+		_, err = shared.RunCommand("mount", args...)
 		if err == nil {
 			l.Info("Mounted hotplug")
 			return
@@ -167,3 +197,21 @@ func eventsProcess(event api.Event) {
 
 	l.Info("Failed to mount hotplug", logger.Ctx{"err": err})
 }
+
+// eventsUnmountHotplug unmounts a share that was hot-unplugged from the VM.
+// The share is already gone by the time the event is received, so a lazy unmount is used.
+func eventsUnmountHotplug(mount instancetype.VMAgentMount) {
+	l := logger.AddContext(logger.Ctx{"path": mount.Target})
+
+	if !filesystem.IsMountPoint(mount.Target) {
+		return
+	}
+
+	err := unix.Unmount(mount.Target, unix.MNT_DETACH)
+	if err != nil {
+		l.Info("Failed to unmount hotplug", logger.Ctx{"err": err})
+		return
+	}
+
+	l.Info("Unmounted hotplug")
+}
@@ -8,16 +8,20 @@ import (
 	"io"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"golang.org/x/sys/unix"
 
+	agentAPI "github.com/canonical/lxd/lxd-agent/api"
 	"github.com/canonical/lxd/lxd/db/operationtype"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/response"
@@ -39,6 +43,13 @@ var execCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: execPost},
 }
 
+var execSessionCmd = APIEndpoint{
+	Name: "exec_session",
+	Path: "exec/{id}",
+
+	Post: APIEndpointAction{Handler: execSessionPost},
+}
+
 func execPost(d *Daemon, r *http.Request) response.Response {
 	post := api.ContainerExecPost{}
 
@@ -147,7 +158,68 @@ func execPost(d *Daemon, r *http.Request) response.Response {
 	return operations.OperationResponse(op)
 }
 
+// execSessionPost re-attaches to the output of a non-interactive exec session, for example after the agent
+// restarted. The standard input of the command can't be re-attached.
+func execSessionPost(d *Daemon, r *http.Request) response.Response {
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	session, err := execSessionLoad(id)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return response.NotFound(fmt.Errorf("Exec session not found"))
+		}
+
+		return response.SmartError(err)
+	}
+
+	post := agentAPI.ExecSessionPost{}
+	err = json.NewDecoder(r.Body).Decode(&post)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	ws := &execWs{}
+	ws.session = session
+	ws.offsets = map[int]int64{
+		execWSStdout: post.StdoutOffset,
+		execWSStderr: post.StderrOffset,
+	}
+
+	ws.fds = map[int]string{}
+	ws.conns = map[int]*websocket.Conn{}
+	ws.conns[execWSControl] = nil
+	ws.conns[execWSStdout] = nil
+	ws.conns[execWSStderr] = nil
+
+	ws.requiredConnectedCtx, ws.requiredConnectedDone = context.WithCancel(context.Background())
+
+	for i := range ws.conns {
+		ws.fds[i], err = shared.RandomCryptoString()
+		if err != nil {
+			return response.InternalError(err)
+		}
+	}
+
+	ws.command = session.Command
+	ws.env = session.Environment
+
+	op, err := operations.OperationCreate(nil, "", operations.OperationClassWebsocket, operationtype.CommandExec, map[string][]api.URL{}, ws.Metadata(), ws.Do, nil, ws.Connect, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	// Link the operation to the agent's event server.
+	op.SetEventServer(d.events)
+
+	return operations.OperationResponse(op)
+}
+
 type execWs struct {
+	session               *execSession
+	offsets               map[int]int64
 	command               []string
 	env                   map[string]string
 	conns                 map[int]*websocket.Conn
@@ -244,6 +316,11 @@ func (s *execWs) Do(op *operations.Operation) error {
 		return fmt.Errorf("Timed out waiting for websockets to connect")
 	}
 
+	// Non-interactive commands are run through a session so that they survive agent restarts.
+	if !s.interactive {
+		return s.doSession(op)
+	}
+
 	var err error
 	var ttys []*os.File
 	var ptys []*os.File
@@ -252,34 +329,19 @@ func (s *execWs) Do(op *operations.Operation) error {
 	var stdout *os.File
 	var stderr *os.File
 
-	if s.interactive {
-		ttys = make([]*os.File, 1)
-		ptys = make([]*os.File, 1)
-		ptys[0], ttys[0], err = shared.OpenPty(int64(s.uid), int64(s.gid))
-		if err != nil {
-			return err
-		}
+	ttys = make([]*os.File, 1)
+	ptys = make([]*os.File, 1)
+	ptys[0], ttys[0], err = shared.OpenPty(int64(s.uid), int64(s.gid))
+	if err != nil {
+		return err
+	}
 
-		stdin = ttys[0]
-		stdout = ttys[0]
-		stderr = ttys[0]
+	stdin = ttys[0]
+	stdout = ttys[0]
+	stderr = ttys[0]
 
-		if s.width > 0 && s.height > 0 {
-			_ = shared.SetSize(int(ptys[0].Fd()), s.width, s.height)
-		}
-	} else {
-		ttys = make([]*os.File, 3)
-		ptys = make([]*os.File, 3)
-		for i := 0; i < len(ttys); i++ {
-			ptys[i], ttys[i], err = os.Pipe()
-			if err != nil {
-				return err
-			}
-		}
-
-		stdin = ptys[execWSStdin]
-		stdout = ttys[execWSStdout]
-		stderr = ttys[execWSStderr]
+	if s.width > 0 && s.height > 0 {
+		_ = shared.SetSize(int(ptys[0].Fd()), s.width, s.height)
 	}
 
 	waitAttachedChildIsDead, markAttachedChildIsDead := context.WithCancel(context.Background())
@@ -347,9 +409,7 @@ func (s *execWs) Do(op *operations.Operation) error {
 	// Make the given terminal the controlling terminal of the calling process.
 	// The calling process must be a session leader and not have a controlling terminal already.
 	// This is important as allows ctrl+c to work as expected for non-shell programs.
-	if s.interactive {
-		cmd.SysProcAttr.Setctty = true
-	}
+	cmd.SysProcAttr.Setctty = true
 
 	cmd.Dir = s.cwd
 
@@ -373,133 +433,245 @@ func (s *execWs) Do(op *operations.Operation) error {
 	go func() {
 		defer wgEOF.Done()
 
-		l.Debug("Exec control handler started")
-		defer l.Debug("Exec control handler finished")
+		s.handleControl(l, cmd.Process.Pid, ptys[0], waitAttachedChildIsDead)
+	}()
+
+	wgEOF.Add(1)
+	go func() {
+		defer wgEOF.Done()
+
+		l.Debug("Exec mirror websocket started", logger.Ctx{"number": 0})
+		defer l.Debug("Exec mirror websocket finished", logger.Ctx{"number": 0})
 
 		s.connsLock.Lock()
-		conn := s.conns[-1]
+		conn := s.conns[0]
 		s.connsLock.Unlock()
 
-		for {
-			mt, r, err := conn.NextReader()
-			if err != nil || mt == websocket.CloseMessage {
-				// Check if command process has finished normally, if so, no need to kill it.
-				if waitAttachedChildIsDead.Err() != nil {
-					return
-				}
+		readDone, writeDone := ws.Mirror(conn, shared.NewExecWrapper(waitAttachedChildIsDead, ptys[0]))
 
-				if mt == websocket.CloseMessage {
-					l.Warn("Got exec control websocket close message, killing command")
-				} else {
-					l.Warn("Failed getting exec control websocket reader, killing command", logger.Ctx{"err": err})
-				}
+		<-readDone
+		<-writeDone
+		_ = conn.Close()
+	}()
 
-				err := unix.Kill(cmd.Process.Pid, unix.SIGKILL)
-				if err != nil {
-					l.Error("Failed to send SIGKILL")
-				} else {
-					l.Info("Sent SIGKILL")
-				}
+	exitStatus, err := shared.ExitStatus(cmd.Wait())
+
+	l.Debug("Instance process stopped", logger.Ctx{"err": err, "exitStatus": exitStatus})
+	return finisher(exitStatus, nil)
+}
 
+// handleControl handles the messages of the control websocket for the command with the given PID.
+// The command is killed if the control websocket is closed before childDead is cancelled.
+func (s *execWs) handleControl(l logger.Logger, pid int, pty *os.File, childDead context.Context) {
+	l.Debug("Exec control handler started")
+	defer l.Debug("Exec control handler finished")
+
+	s.connsLock.Lock()
+	conn := s.conns[-1]
+	s.connsLock.Unlock()
+
+	for {
+		mt, r, err := conn.NextReader()
+		if err != nil || mt == websocket.CloseMessage {
+			// Check if command process has finished normally, if so, no need to kill it.
+			if childDead.Err() != nil {
 				return
 			}
 
-			buf, err := io.ReadAll(r)
+			if mt == websocket.CloseMessage {
+				l.Warn("Got exec control websocket close message, killing command")
+			} else {
+				l.Warn("Failed getting exec control websocket reader, killing command", logger.Ctx{"err": err})
+			}
+
+			err := unix.Kill(pid, unix.SIGKILL)
 			if err != nil {
-				// Check if command process has finished normally, if so, no need to kill it.
-				if waitAttachedChildIsDead.Err() != nil {
-					return
-				}
+				l.Error("Failed to send SIGKILL")
+			} else {
+				l.Info("Sent SIGKILL")
+			}
 
-				l.Warn("Failed reading control websocket message, killing command", logger.Ctx{"err": err})
+			return
+		}
 
+		buf, err := io.ReadAll(r)
+		if err != nil {
+			// Check if command process has finished normally, if so, no need to kill it.
+			if childDead.Err() != nil {
 				return
 			}
 
-			command := api.ContainerExecControl{}
-			err = json.Unmarshal(buf, &command)
+			l.Warn("Failed reading control websocket message, killing command", logger.Ctx{"err": err})
+
+			return
+		}
+
+		command := api.ContainerExecControl{}
+		err = json.Unmarshal(buf, &command)
+		if err != nil {
+			l.Debug("Failed to unmarshal control socket command", logger.Ctx{"err": err})
+			continue
+		}
+
+		if command.Command == "window-resize" && s.interactive {
+			winchWidth, err := strconv.Atoi(command.Args["width"])
+			if err != nil {
+				l.Debug("Unable to extract window width", logger.Ctx{"err": err})
+				continue
+			}
+
+			winchHeight, err := strconv.Atoi(command.Args["height"])
 			if err != nil {
-				l.Debug("Failed to unmarshal control socket command", logger.Ctx{"err": err})
+				l.Debug("Unable to extract window height", logger.Ctx{"err": err})
 				continue
 			}
 
-			if command.Command == "window-resize" && s.interactive {
-				winchWidth, err := strconv.Atoi(command.Args["width"])
-				if err != nil {
-					l.Debug("Unable to extract window width", logger.Ctx{"err": err})
-					continue
-				}
+			err = shared.SetSize(int(pty.Fd()), winchWidth, winchHeight)
+			if err != nil {
+				l.Debug("Failed to set window size", logger.Ctx{"err": err, "width": winchWidth, "height": winchHeight})
+				continue
+			}
+		} else if command.Command == "signal" {
+			err := unix.Kill(pid, unix.Signal(command.Signal))
+			if err != nil {
+				l.Debug("Failed forwarding signal", logger.Ctx{"err": err, "signal": command.Signal})
+				continue
+			}
 
-				winchHeight, err := strconv.Atoi(command.Args["height"])
-				if err != nil {
-					l.Debug("Unable to extract window height", logger.Ctx{"err": err})
-					continue
-				}
+			l.Info("Forwarded signal", logger.Ctx{"signal": command.Signal})
+		}
+	}
+}
 
-				err = shared.SetSize(int(ptys[0].Fd()), winchWidth, winchHeight)
-				if err != nil {
-					l.Debug("Failed to set window size", logger.Ctx{"err": err, "width": winchWidth, "height": winchHeight})
-					continue
-				}
-			} else if command.Command == "signal" {
-				err := unix.Kill(cmd.Process.Pid, unix.Signal(command.Signal))
-				if err != nil {
-					l.Debug("Failed forwarding signal", logger.Ctx{"err": err, "signal": command.Signal})
-					continue
-				}
+// doSession runs the command through an exec session, or re-attaches to an existing session, and mirrors the
+// output of the session to the websockets.
+func (s *execWs) doSession(op *operations.Operation) error {
+	var stdin *os.File
 
-				l.Info("Forwarded signal", logger.Ctx{"signal": command.Signal})
-			}
+	session := s.session
+	if session == nil {
+		session = &execSession{
+			ID:          op.ID(),
+			Command:     s.command,
+			Environment: s.env,
+			Cwd:         s.cwd,
+			UID:         s.uid,
+			GID:         s.gid,
+		}
+
+		var supervisor *exec.Cmd
+		var err error
+
+		stdin, supervisor, err = execSessionStart(session)
+		if err != nil {
+			return err
 		}
-	}()
 
-	if s.interactive {
-		wgEOF.Add(1)
 		go func() {
-			defer wgEOF.Done()
+			_ = supervisor.Wait()
+
+			// Record a failure if the supervisor ended without recording the exit status of the command.
+			if !shared.PathExists(filepath.Join(session.path(), "exit")) {
+				_ = session.setExitStatus(-1)
+			}
+		}()
+	}
 
-			l.Debug("Exec mirror websocket started", logger.Ctx{"number": 0})
-			defer l.Debug("Exec mirror websocket finished", logger.Ctx{"number": 0})
+	if !session.attach() {
+		return fmt.Errorf("Exec session %q is already attached", session.ID)
+	}
 
-			s.connsLock.Lock()
-			conn := s.conns[0]
-			s.connsLock.Unlock()
+	defer session.detach()
 
-			readDone, writeDone := ws.Mirror(conn, shared.NewExecWrapper(waitAttachedChildIsDead, ptys[0]))
+	l := logger.AddContext(logger.Ctx{"session": session.ID, "interactive": false})
 
-			<-readDone
-			<-writeDone
-			_ = conn.Close()
+	// Forward the standard input of the command until the client closes it.
+	if stdin != nil {
+		s.connsLock.Lock()
+		conn := s.conns[execWSStdin]
+		s.connsLock.Unlock()
+
+		go func() {
+			<-ws.MirrorWrite(conn, stdin)
+			_ = stdin.Close()
 		}()
-	} else {
-		wgEOF.Add(len(ttys) - 1)
-		for i := 0; i < len(ttys); i++ {
-			go func(i int) {
-				l.Debug("Exec mirror websocket started", logger.Ctx{"number": i})
-				defer l.Debug("Exec mirror websocket finished", logger.Ctx{"number": i})
-
-				if i == 0 {
-					s.connsLock.Lock()
-					conn := s.conns[i]
-					s.connsLock.Unlock()
-
-					<-ws.MirrorWrite(conn, ttys[i])
-					_ = ttys[i].Close()
-				} else {
-					s.connsLock.Lock()
-					conn := s.conns[i]
-					s.connsLock.Unlock()
-
-					<-ws.MirrorRead(conn, ptys[i])
-					_ = ptys[i].Close()
-					wgEOF.Done()
-				}
-			}(i)
+	}
+
+	var wgOutput sync.WaitGroup
+
+	for fd, name := range map[int]string{execWSStdout: "stdout", execWSStderr: "stderr"} {
+		follower, err := session.follow(name, s.offsets[fd])
+		if err != nil {
+			return err
 		}
+
+		s.connsLock.Lock()
+		conn := s.conns[fd]
+		s.connsLock.Unlock()
+
+		wgOutput.Add(1)
+		go func() {
+			defer wgOutput.Done()
+
+			l.Debug("Exec mirror websocket started", logger.Ctx{"number": fd})
+			defer l.Debug("Exec mirror websocket finished", logger.Ctx{"number": fd})
+
+			<-ws.MirrorRead(conn, follower)
+			_ = follower.Close()
+		}()
 	}
 
-	exitStatus, err := shared.ExitStatus(cmd.Wait())
+	childDead, markChildDead := context.WithCancel(context.Background())
+	var wgControl sync.WaitGroup
 
-	l.Debug("Instance process stopped", logger.Ctx{"err": err, "exitStatus": exitStatus})
-	return finisher(exitStatus, nil)
+	pid, err := session.waitPID()
+	if err != nil {
+		l.Warn("Failed waiting for the command to start", logger.Ctx{"err": err})
+	}
+
+	if pid > 0 {
+		l = logger.AddContext(logger.Ctx{"session": session.ID, "interactive": false, "PID": pid})
+		l.Debug("Instance process started")
+
+		wgControl.Add(1)
+		go func() {
+			defer wgControl.Done()
+
+			s.handleControl(l, pid, nil, childDead)
+		}()
+	}
+
+	wgOutput.Wait()
+	exitStatus, err := session.waitExit()
+	if err != nil {
+		l.Warn("Failed waiting for the command to end", logger.Ctx{"err": err})
+	}
+
+	l.Debug("Instance process stopped", logger.Ctx{"exitStatus": exitStatus})
+
+	// Cancel this before closing the control connection so control handler can detect command ending.
+	markChildDead()
+
+	s.connsLock.Lock()
+	conn := s.conns[execWSControl]
+	s.connsLock.Unlock()
+
+	if conn != nil {
+		_ = conn.Close() // Close control connection (will cause control go routine to end).
+	}
+
+	wgControl.Wait()
+
+	err = op.UpdateMetadata(shared.Jmap{"return": exitStatus})
+	if err != nil {
+		return err
+	}
+
+	// The output of the command has been sent, so the session isn't needed anymore.
+	err = session.remove()
+	if err != nil {
+		l.Warn("Failed removing exec session", logger.Ctx{"err": err})
+	}
+
+	return nil
 }
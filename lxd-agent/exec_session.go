@@ -0,0 +1,574 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// execSessionsDir is where the state of non-interactive exec sessions is kept.
+// It is kept outside of /run/lxd_agent as that is replaced each time the agent starts.
+var execSessionsDir = "/run/lxd-agent-exec"
+
+// execSessionExpiry is how long the output of a finished session is kept if no client re-attaches to it.
+const execSessionExpiry = time.Hour
+
+// execSessionMaxOutput is the maximum size of the output of a session that hasn't been read by a client yet.
+// The command is killed once it is reached, as its output would otherwise fill the tmpfs the sessions are kept on.
+const execSessionMaxOutput = 16 * 1024 * 1024
+
+// execSessionCheckInterval is how often the supervisor of a session is checked for having disappeared while
+// waiting for changes to the session files.
+const execSessionCheckInterval = 5 * time.Second
+
+// execSessionsAttached records the sessions whose output is currently being sent to a client.
+var execSessionsAttached = map[string]bool{}
+var execSessionsAttachedMu sync.Mutex
+
+// execSession represents a non-interactive command run through a supervisor process so that it survives agent
+// restarts. The output of the command is written to files in the session directory so that clients can re-attach
+// to it.
+type execSession struct {
+	ID          string            `json:"id"`
+	Command     []string          `json:"command"`
+	Environment map[string]string `json:"environment"`
+	Cwd         string            `json:"cwd"`
+	UID         uint32            `json:"uid"`
+	GID         uint32            `json:"gid"`
+}
+
+// execSessionState is written by the supervisor once the command has started.
+type execSessionState struct {
+	SupervisorPID int `json:"supervisor_pid"`
+	PID           int `json:"pid"`
+}
+
+// execSessionLoad loads the session with the given ID.
+func execSessionLoad(id string) (*execSession, error) {
+	if id == "" || strings.ContainsAny(id, "/.") {
+		return nil, fmt.Errorf("Invalid exec session ID %q", id)
+	}
+
+	buf, err := os.ReadFile(filepath.Join(execSessionsDir, id, "session.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	session := &execSession{}
+	err = json.Unmarshal(buf, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// execSessionStart creates the session directory and starts the supervisor of the session.
+// Returns the writer for the standard input of the command and the supervisor process.
+func execSessionStart(session *execSession) (*os.File, *exec.Cmd, error) {
+	path := session.path()
+
+	err := session.save()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stdout, err := os.OpenFile(filepath.Join(path, "stdout"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() { _ = stdout.Close() }()
+
+	stderr, err := os.OpenFile(filepath.Join(path, "stderr"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() { _ = stderr.Close() }()
+
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() { _ = stdinReader.Close() }()
+
+	execPath, err := os.Executable()
+	if err != nil {
+		_ = stdinWriter.Close()
+		return nil, nil, err
+	}
+
+	// Run the supervisor in its own systemd scope when possible so that it isn't stopped along with the agent's
+	// service.
+	supervisor := exec.Command(execPath, "exec-session", session.ID)
+	systemdRun, err := exec.LookPath("systemd-run")
+	if err == nil && shared.PathExists("/run/systemd/system") {
+		supervisor = exec.Command(systemdRun, "--quiet", "--scope", "--collect", "--unit=lxd-agent-exec-"+session.ID, "--", execPath, "exec-session", session.ID)
+	}
+
+	supervisor.Stdin = stdinReader
+	supervisor.Stdout = stdout
+	supervisor.Stderr = stderr
+
+	// Run the supervisor in its own session so that it isn't affected by the agent's terminal or signals.
+	supervisor.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	err = supervisor.Start()
+	if err != nil {
+		_ = stdinWriter.Close()
+		return nil, nil, err
+	}
+
+	return stdinWriter, supervisor, nil
+}
+
+// execSessionsPrune removes the sessions that finished more than execSessionExpiry ago.
+func execSessionsPrune() {
+	entries, err := os.ReadDir(execSessionsDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := os.Stat(filepath.Join(execSessionsDir, entry.Name(), "exit"))
+		if err != nil || time.Since(info.ModTime()) < execSessionExpiry {
+			continue
+		}
+
+		logger.Debug("Removing expired exec session", logger.Ctx{"session": entry.Name()})
+		_ = os.RemoveAll(filepath.Join(execSessionsDir, entry.Name()))
+	}
+}
+
+// save creates the session directory and writes the session to it.
+func (s *execSession) save() error {
+	err := os.MkdirAll(s.path(), 0700)
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(s.path(), "session.json"), buf, 0600)
+}
+
+// path returns the directory of the session.
+func (s *execSession) path() string {
+	return filepath.Join(execSessionsDir, s.ID)
+}
+
+// state returns the state written by the supervisor or nil if the command hasn't started yet.
+func (s *execSession) state() *execSessionState {
+	buf, err := os.ReadFile(filepath.Join(s.path(), "state.json"))
+	if err != nil {
+		return nil
+	}
+
+	state := &execSessionState{}
+	err = json.Unmarshal(buf, state)
+	if err != nil {
+		return nil
+	}
+
+	return state
+}
+
+// exitStatus returns the exit status of the command and whether the command has finished.
+// A command whose supervisor disappeared without recording an exit status is considered finished with status -1.
+func (s *execSession) exitStatus() (int, bool) {
+	buf, err := os.ReadFile(filepath.Join(s.path(), "exit"))
+	if err == nil {
+		exitStatus, err := strconv.Atoi(strings.TrimSpace(string(buf)))
+		if err != nil {
+			return -1, true
+		}
+
+		return exitStatus, true
+	}
+
+	state := s.state()
+	if state != nil && unix.Kill(state.SupervisorPID, 0) == unix.ESRCH {
+		// Check again in case the supervisor recorded the exit status before ending.
+		_, err = os.Stat(filepath.Join(s.path(), "exit"))
+		if err != nil {
+			return -1, true
+		}
+
+		return s.exitStatus()
+	}
+
+	return -1, false
+}
+
+// setExitStatus records the exit status of the command.
+func (s *execSession) setExitStatus(exitStatus int) error {
+	tmpPath := filepath.Join(s.path(), "exit.tmp")
+
+	err := os.WriteFile(tmpPath, []byte(strconv.Itoa(exitStatus)), 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Join(s.path(), "exit"))
+}
+
+// waitPID waits for the command to start and returns its PID.
+// Returns 0 if the command ended or failed to start.
+func (s *execSession) waitPID() (int, error) {
+	watcher, err := newExecSessionWatcher(s.path())
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = watcher.Close() }()
+
+	for {
+		state := s.state()
+		if state != nil {
+			return state.PID, nil
+		}
+
+		_, done := s.exitStatus()
+		if done {
+			return 0, nil
+		}
+
+		err = watcher.wait(execSessionCheckInterval)
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// waitExit waits for the command to end and returns its exit status.
+func (s *execSession) waitExit() (int, error) {
+	watcher, err := newExecSessionWatcher(s.path())
+	if err != nil {
+		return -1, err
+	}
+
+	defer func() { _ = watcher.Close() }()
+
+	for {
+		exitStatus, done := s.exitStatus()
+		if done {
+			return exitStatus, nil
+		}
+
+		err = watcher.wait(execSessionCheckInterval)
+		if err != nil {
+			return -1, err
+		}
+	}
+}
+
+// attach marks the session as attached to a client.
+// Returns false if the session is already attached.
+func (s *execSession) attach() bool {
+	execSessionsAttachedMu.Lock()
+	defer execSessionsAttachedMu.Unlock()
+
+	if execSessionsAttached[s.ID] {
+		return false
+	}
+
+	execSessionsAttached[s.ID] = true
+
+	return true
+}
+
+// detach marks the session as no longer attached to a client.
+func (s *execSession) detach() {
+	execSessionsAttachedMu.Lock()
+	delete(execSessionsAttached, s.ID)
+	execSessionsAttachedMu.Unlock()
+}
+
+// remove deletes the session directory.
+func (s *execSession) remove() error {
+	return os.RemoveAll(s.path())
+}
+
+// follow returns a reader for the given output file of the session starting from offset.
+// The reader returns io.EOF once the command has finished and all of its output has been read.
+func (s *execSession) follow(name string, offset int64) (*execSessionFollower, error) {
+	// Watch for changes before opening the file so that no output written in between is missed.
+	watcher, err := newExecSessionWatcher(s.path())
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(s.path(), name))
+	if err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		_ = f.Close()
+		_ = watcher.Close()
+		return nil, err
+	}
+
+	return &execSessionFollower{session: s, watcher: watcher, file: f, offset: offset, released: offset}, nil
+}
+
+// execSessionFollower reads the output of a session as the command writes it.
+type execSessionFollower struct {
+	session  *execSession
+	watcher  *execSessionWatcher
+	file     *os.File
+	offset   int64
+	released int64
+	finished bool
+}
+
+// Read reads the next chunk of output, waiting for the command to write more if needed.
+func (f *execSessionFollower) Read(p []byte) (int, error) {
+	// The previous chunk has been sent to the client by the time the next one is requested, so release the memory
+	// it used on the tmpfs.
+	f.release()
+
+	for {
+		n, err := f.file.Read(p)
+		if n > 0 {
+			f.offset += int64(n)
+			return n, nil
+		}
+
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		if f.finished {
+			return 0, io.EOF
+		}
+
+		// Read once more after the command has finished to catch its last output.
+		_, f.finished = f.session.exitStatus()
+		if !f.finished {
+			err = f.watcher.wait(execSessionCheckInterval)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// release punches a hole in the output file for the pages that have been read.
+func (f *execSessionFollower) release() {
+	pageSize := int64(os.Getpagesize())
+	end := f.offset - (f.offset % pageSize)
+	if end <= f.released {
+		return
+	}
+
+	// Use a separate writable file descriptor as the follower opens the output read-only.
+	w, err := os.OpenFile(f.file.Name(), os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+
+	defer func() { _ = w.Close() }()
+
+	err = unix.Fallocate(int(w.Fd()), unix.FALLOC_FL_PUNCH_HOLE|unix.FALLOC_FL_KEEP_SIZE, f.released, end-f.released)
+	if err != nil {
+		return
+	}
+
+	f.released = end
+}
+
+// Close closes the output file.
+func (f *execSessionFollower) Close() error {
+	_ = f.watcher.Close()
+
+	return f.file.Close()
+}
+
+// execSessionWatcher waits for changes to the files of a session.
+type execSessionWatcher struct {
+	fd int
+}
+
+// newExecSessionWatcher returns a watcher for the files in the given session directory.
+func newExecSessionWatcher(path string) (*execSessionWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("Failed initialising inotify: %w", err)
+	}
+
+	_, err = unix.InotifyAddWatch(fd, path, unix.IN_MODIFY|unix.IN_CREATE|unix.IN_MOVED_TO|unix.IN_DELETE_SELF)
+	if err != nil {
+		_ = unix.Close(fd)
+		return nil, fmt.Errorf("Failed watching %q: %w", path, err)
+	}
+
+	return &execSessionWatcher{fd: fd}, nil
+}
+
+// wait waits until a file of the session changes or the timeout expires.
+func (w *execSessionWatcher) wait(timeout time.Duration) error {
+	fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+	_, err := unix.Poll(fds, int(timeout.Milliseconds()))
+	if err != nil && !errors.Is(err, unix.EINTR) {
+		return err
+	}
+
+	// Consume the pending events, only the fact that something changed matters.
+	buf := make([]byte, 4096)
+	for {
+		n, err := unix.Read(w.fd, buf)
+		if n <= 0 || err != nil {
+			return nil
+		}
+	}
+}
+
+// Close stops watching the session.
+func (w *execSessionWatcher) Close() error {
+	return unix.Close(w.fd)
+}
+
+// execSessionOutput writes the output of the command to an output file of the session. Once the output that hasn't
+// been read by a client reaches the limit, the rest of the output is discarded and onFull is called.
+type execSessionOutput struct {
+	file   *os.File
+	limit  int64
+	onFull func()
+
+	full bool
+}
+
+// Write writes the output to the file unless the limit has been reached.
+func (o *execSessionOutput) Write(p []byte) (int, error) {
+	if o.full {
+		return len(p), nil
+	}
+
+	if o.pending()+int64(len(p)) > o.limit {
+		o.full = true
+		o.onFull()
+
+		return len(p), nil
+	}
+
+	return o.file.Write(p)
+}
+
+// pending returns the size of the output that hasn't been read by a client yet. Clients release the output they
+// have read by punching holes in the file, so this is the size of the file after the first data.
+func (o *execSessionOutput) pending() int64 {
+	info, err := o.file.Stat()
+	if err != nil {
+		return 0
+	}
+
+	dataStart, err := unix.Seek(int(o.file.Fd()), 0, unix.SEEK_DATA)
+	if err != nil {
+		// The whole file has been released (or is empty).
+		return 0
+	}
+
+	return info.Size() - dataStart
+}
+
+// runExecSession runs the command of the session and records its exit status. This is run by the supervisor with
+// the standard input of the command and the output files of the session.
+func runExecSession(session *execSession, stdin *os.File, stdout *os.File, stderr *os.File) error {
+	var cmd *exec.Cmd
+
+	if len(session.Command) > 1 {
+		cmd = exec.Command(session.Command[0], session.Command[1:]...)
+	} else {
+		cmd = exec.Command(session.Command[0])
+	}
+
+	for k, v := range session.Environment {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	// Kill the command if its output isn't read, the output is kept in memory.
+	var fullOnce sync.Once
+	onFull := func() {
+		fullOnce.Do(func() {
+			_, _ = fmt.Fprintf(stderr, "\nThe output of the command exceeded %d bytes without being read, killing it\n", execSessionMaxOutput)
+			_ = unix.Kill(-cmd.Process.Pid, unix.SIGKILL)
+		})
+	}
+
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+
+	cmd.Stdout = &execSessionOutput{file: stdout, limit: execSessionMaxOutput, onFull: onFull}
+	cmd.Stderr = &execSessionOutput{file: stderr, limit: execSessionMaxOutput, onFull: onFull}
+	cmd.Dir = session.Cwd
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{
+			Uid: session.UID,
+			Gid: session.GID,
+		},
+		// Creates a new session so that the command can handle signals like it does for interactive sessions.
+		Setsid: true,
+	}
+
+	err := cmd.Start()
+	if err != nil {
+		exitStatus := -1
+
+		if errors.Is(err, exec.ErrNotFound) || os.IsNotExist(err) {
+			exitStatus = 127
+		} else if errors.Is(err, fs.ErrPermission) {
+			exitStatus = 126
+		}
+
+		return session.setExitStatus(exitStatus)
+	}
+
+	buf, err := json.Marshal(execSessionState{SupervisorPID: os.Getpid(), PID: cmd.Process.Pid})
+	if err != nil {
+		return err
+	}
+
+	tmpPath := filepath.Join(session.path(), "state.json.tmp")
+	err = os.WriteFile(tmpPath, buf, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, filepath.Join(session.path(), "state.json"))
+	if err != nil {
+		return err
+	}
+
+	// The standard input of the command is its own now.
+	if stdin != nil {
+		_ = stdin.Close()
+	}
+
+	exitStatus, _ := shared.ExitStatus(cmd.Wait())
+
+	return session.setExitStatus(exitStatus)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// testExecSession creates the directory and output files of a session in a temporary sessions directory.
+func testExecSession(t *testing.T, command ...string) *execSession {
+	execSessionsDir = t.TempDir()
+
+	session := &execSession{
+		ID:          "test",
+		Command:     command,
+		Environment: map[string]string{"PATH": os.Getenv("PATH")},
+		Cwd:         "/",
+		UID:         uint32(os.Getuid()),
+		GID:         uint32(os.Getgid()),
+	}
+
+	require.NoError(t, session.save())
+
+	for _, name := range []string{"stdout", "stderr"} {
+		require.NoError(t, os.WriteFile(filepath.Join(session.path(), name), nil, 0600))
+	}
+
+	return session
+}
+
+// testExecSessionOutput opens an output file of the session for writing like the supervisor does.
+func testExecSessionOutput(t *testing.T, session *execSession, name string) *os.File {
+	f, err := os.OpenFile(filepath.Join(session.path(), name), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = f.Close() })
+
+	return f
+}
+
+// testReadAll reads the output of the session from the given offset until the command has finished.
+func testReadAll(t *testing.T, session *execSession, name string, offset int64) string {
+	follower, err := session.follow(name, offset)
+	require.NoError(t, err)
+
+	defer func() { _ = follower.Close() }()
+
+	buf, err := io.ReadAll(follower)
+	require.NoError(t, err)
+
+	return string(buf)
+}
+
+func TestExecSession_Run(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Running commands with credentials requires root")
+	}
+
+	session := testExecSession(t, "sh", "-c", "echo foo; echo bar >&2; exit 3")
+
+	// The session is persisted, so a restarted agent finds it again.
+	loaded, err := execSessionLoad(session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, session, loaded)
+
+	_, err = execSessionLoad("../test")
+	assert.Error(t, err)
+
+	err = runExecSession(loaded, nil, testExecSessionOutput(t, session, "stdout"), testExecSessionOutput(t, session, "stderr"))
+	require.NoError(t, err)
+
+	exitStatus, done := session.exitStatus()
+	assert.True(t, done)
+	assert.Equal(t, 3, exitStatus)
+
+	state := session.state()
+	require.NotNil(t, state)
+	assert.Equal(t, os.Getpid(), state.SupervisorPID)
+
+	assert.Equal(t, "foo\n", testReadAll(t, session, "stdout", 0))
+	assert.Equal(t, "bar\n", testReadAll(t, session, "stderr", 0))
+}
+
+func TestExecSession_RunNotFound(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Running commands with credentials requires root")
+	}
+
+	session := testExecSession(t, "/nonexistent")
+
+	err := runExecSession(session, nil, testExecSessionOutput(t, session, "stdout"), testExecSessionOutput(t, session, "stderr"))
+	require.NoError(t, err)
+
+	exitStatus, err := session.waitExit()
+	require.NoError(t, err)
+	assert.Equal(t, 127, exitStatus)
+
+	pid, err := session.waitPID()
+	require.NoError(t, err)
+	assert.Equal(t, 0, pid)
+}
+
+func TestExecSession_RunOutputLimit(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Running commands with credentials requires root")
+	}
+
+	// Without a client reading the output, the command is killed once the output limit is reached.
+	session := testExecSession(t, "sh", "-c", "head -c $((2 * 16 * 1024 * 1024)) /dev/zero; sleep 60")
+
+	start := time.Now()
+	err := runExecSession(session, nil, testExecSessionOutput(t, session, "stdout"), testExecSessionOutput(t, session, "stderr"))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 30*time.Second)
+
+	exitStatus, done := session.exitStatus()
+	assert.True(t, done)
+	assert.NotEqual(t, 0, exitStatus)
+
+	info, err := os.Stat(filepath.Join(session.path(), "stdout"))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(execSessionMaxOutput))
+	assert.Contains(t, testReadAllFile(t, filepath.Join(session.path(), "stderr")), "killing it")
+}
+
+func TestExecSession_FollowResume(t *testing.T) {
+	session := testExecSession(t)
+	stdout := testExecSessionOutput(t, session, "stdout")
+
+	_, err := stdout.WriteString("hello ")
+	require.NoError(t, err)
+
+	// The follower waits for more output until the command has finished.
+	done := make(chan string)
+	go func() {
+		done <- testReadAll(t, session, "stdout", 0)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = stdout.WriteString("world")
+	require.NoError(t, err)
+	require.NoError(t, session.setExitStatus(0))
+
+	select {
+	case output := <-done:
+		assert.Equal(t, "hello world", output)
+	case <-time.After(execSessionCheckInterval / 2):
+		t.Fatal("Follower wasn't woken up by the command finishing")
+	}
+
+	// Re-attaching resumes from the offset of the output already received.
+	assert.Equal(t, "world", testReadAll(t, session, "stdout", 6))
+	assert.Equal(t, "", testReadAll(t, session, "stdout", 11))
+}
+
+func TestExecSession_FollowRelease(t *testing.T) {
+	session := testExecSession(t)
+	stdout := testExecSessionOutput(t, session, "stdout")
+
+	pageSize := os.Getpagesize()
+	_, err := stdout.Write(make([]byte, 3*pageSize))
+	require.NoError(t, err)
+	require.NoError(t, session.setExitStatus(0))
+
+	follower, err := session.follow("stdout", 0)
+	require.NoError(t, err)
+
+	defer func() { _ = follower.Close() }()
+
+	buf := make([]byte, 2*pageSize)
+	n, err := io.ReadFull(follower, buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2*pageSize, n)
+
+	// Reading the next chunk releases the output read so far, which isn't pending anymore.
+	_, err = follower.Read(buf)
+	require.NoError(t, err)
+
+	output := &execSessionOutput{file: stdout}
+	dataStart, err := unix.Seek(int(stdout.Fd()), 0, unix.SEEK_DATA)
+	if err == nil && dataStart == 0 {
+		t.Skip("File system doesn't support punching holes")
+	}
+
+	assert.Equal(t, int64(pageSize), output.pending())
+}
+
+func TestExecSession_OutputLimit(t *testing.T) {
+	session := testExecSession(t)
+	stdout := testExecSessionOutput(t, session, "stdout")
+
+	full := 0
+	output := &execSessionOutput{file: stdout, limit: 10, onFull: func() { full++ }}
+
+	for _, chunk := range []string{"1234", "5678", "90", "abcd", "efgh"} {
+		n, err := output.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	// The output over the limit is discarded and the limit is only reported once.
+	assert.Equal(t, 1, full)
+	assert.Equal(t, "1234567890", testReadAllFile(t, stdout.Name()))
+}
+
+func TestExecSessionsPrune(t *testing.T) {
+	execSessionsDir = t.TempDir()
+
+	expired := &execSession{ID: "expired"}
+	recent := &execSession{ID: "recent"}
+	running := &execSession{ID: "running"}
+
+	for _, session := range []*execSession{expired, recent, running} {
+		require.NoError(t, session.save())
+	}
+
+	require.NoError(t, expired.setExitStatus(0))
+	require.NoError(t, recent.setExitStatus(0))
+
+	expiredTime := time.Now().Add(-execSessionExpiry - time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(expired.path(), "exit"), expiredTime, expiredTime))
+
+	execSessionsPrune()
+
+	assert.NoDirExists(t, expired.path())
+	assert.DirExists(t, recent.path())
+	assert.DirExists(t, running.path())
+}
+
+// testReadAllFile returns the content of the file at the given path.
+func testReadAllFile(t *testing.T, path string) string {
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return string(buf)
+}
@@ -35,6 +35,10 @@ func main() {
 	app.PersistentFlags().BoolVarP(&globalCmd.flagLogVerbose, "verbose", "v", false, "Show all information messages")
 	app.PersistentFlags().BoolVarP(&globalCmd.flagLogDebug, "debug", "d", false, "Show all debug messages")
 
+	// exec-session sub-command
+	execSessionCmd := cmdExecSession{global: &globalCmd}
+	app.AddCommand(execSessionCmd.Command())
+
 	// Version handling
 	app.SetVersionTemplate("{{.Version}}\n")
 	app.Version = version.Version
@@ -130,6 +130,9 @@ func (c *cmdAgent) Run(cmd *cobra.Command, args []string) error {
 	// Mount shares from host.
 	c.mountHostShares()
 
+	// Remove the output of exec sessions that finished a while ago.
+	execSessionsPrune()
+
 	d := newDaemon(c.global.flagLogDebug, c.global.flagLogVerbose)
 
 	// Start the server.
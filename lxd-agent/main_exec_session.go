@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+type cmdExecSession struct {
+	global *cmdGlobal
+}
+
+// Command returns the hidden command used to supervise non-interactive exec sessions.
+func (c *cmdExecSession) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "exec-session <id>"
+	cmd.Short = "Run the command of an exec session"
+	cmd.Long = `Description:
+  Run the command of an exec session
+
+  This internal command runs a non-interactive command on behalf of the agent
+  and records its exit status so that the command survives agent restarts.
+`
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Hidden = true
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+// Run executes the exec-session command.
+func (c *cmdExecSession) Run(cmd *cobra.Command, args []string) error {
+	session, err := execSessionLoad(args[0])
+	if err != nil {
+		return err
+	}
+
+	return runExecSession(session, os.Stdin, os.Stdout, os.Stderr)
+}
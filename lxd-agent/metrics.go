@@ -3,7 +3,10 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -61,9 +64,16 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		out.Network = netStats
 	}
 
-	out.ProcessesTotal, err = getTotalProcesses()
+	out.ProcessesTotal, out.ProcessesState, err = getProcessMetrics()
 	if err != nil {
-		logger.Warn("Failed to get total processes", logger.Ctx{"err": err})
+		logger.Warn("Failed to get process metrics", logger.Ctx{"err": err})
+	}
+
+	sessions, err := getSessionCount()
+	if err != nil {
+		logger.Warn("Failed to get logged-in sessions", logger.Ctx{"err": err})
+	} else {
+		out.Sessions = &sessions
 	}
 
 	cpuStats, err := getCPUMetrics()
@@ -164,13 +174,26 @@ func getCPUMetrics() (map[string]metrics.CPUMetrics, error) {
 	return out, nil
 }
 
-func getTotalProcesses() (uint64, error) {
+// processStates maps the state codes of /proc/<pid>/stat to the process_state label of the metrics.
+var processStates = map[byte]string{
+	'R': "running",
+	'S': "sleeping",
+	'D': "uninterruptible",
+	'Z': "zombie",
+	'T': "stopped",
+	't': "stopped",
+	'I': "idle",
+}
+
+// getProcessMetrics returns the number of user space processes, in total and by state.
+func getProcessMetrics() (uint64, map[string]uint64, error) {
 	entries, err := os.ReadDir("/proc")
 	if err != nil {
-		return 0, fmt.Errorf("Failed to read dir %q: %w", "/proc", err)
+		return 0, nil, fmt.Errorf("Failed to read dir %q: %w", "/proc", err)
 	}
 
 	pidCount := uint64(0)
+	states := map[string]uint64{}
 
 	for _, entry := range entries {
 		// Skip everything which isn't a directory
@@ -198,9 +221,58 @@ func getTotalProcesses() (uint64, error) {
 		}
 
 		pidCount++
+
+		// The state follows the command name, which is in parentheses and may contain spaces.
+		stat, err := os.ReadFile(filepath.Join("/proc", name, "stat"))
+		if err != nil {
+			continue
+		}
+
+		idx := bytes.LastIndexByte(stat, ')')
+		if idx < 0 || idx+2 >= len(stat) {
+			continue
+		}
+
+		state, ok := processStates[stat[idx+2]]
+		if !ok {
+			state = "other"
+		}
+
+		states[state]++
+	}
+
+	return pidCount, states, nil
+}
+
+// utmpRecordSize is the size of a utmp record on Linux.
+const utmpRecordSize = 384
+
+// utmpUserProcess is the utmp record type of a logged-in user session.
+const utmpUserProcess = 7
+
+// getSessionCount returns the number of logged-in user sessions recorded in utmp.
+func getSessionCount() (uint64, error) {
+	content, err := os.ReadFile("/run/utmp")
+	if errors.Is(err, fs.ErrNotExist) {
+		content, err = os.ReadFile("/var/run/utmp")
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		// No login service in the guest, so there can't be any session.
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("Failed to read utmp: %w", err)
 	}
 
-	return pidCount, nil
+	sessions := uint64(0)
+	for offset := 0; offset+utmpRecordSize <= len(content); offset += utmpRecordSize {
+		// The record type is a short at the beginning of the record.
+		if binary.NativeEndian.Uint16(content[offset:offset+2]) == utmpUserProcess {
+			sessions++
+		}
+	}
+
+	return sessions, nil
 }
 
 func getDiskMetrics() (map[string]metrics.DiskMetrics, error) {
@@ -280,6 +352,7 @@ func getFilesystemMetrics() (map[string]metrics.FilesystemMetrics, error) {
 
 		stats := metrics.FilesystemMetrics{}
 
+		stats.Device = fields[0]
 		stats.Mountpoint = fields[1]
 
 		statfs, err := filesystem.StatVFS(stats.Mountpoint)
@@ -295,8 +368,11 @@ func getFilesystemMetrics() (map[string]metrics.FilesystemMetrics, error) {
 		stats.AvailableBytes = statfs.Bavail * uint64(statfs.Bsize)
 		stats.FreeBytes = statfs.Bfree * uint64(statfs.Bsize)
 		stats.SizeBytes = statfs.Blocks * uint64(statfs.Bsize)
+		stats.Files = statfs.Files
+		stats.FilesFree = statfs.Ffree
 
-		out[fields[0]] = stats
+		// Key by mount point so that a device mounted in several places is reported for each mount.
+		out[stats.Mountpoint] = stats
 	}
 
 	return out, nil
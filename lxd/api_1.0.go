@@ -40,21 +40,35 @@ var api10Cmd = APIEndpoint{
 
 var api10 = []APIEndpoint{
 	api10Cmd,
+	auditCmd,
 	api10ResourcesCmd,
+	api10StartupCmd,
+	certificateAuthorityCmd,
 	certificateCmd,
 	certificatesCmd,
 	clusterCmd,
 	clusterGroupCmd,
 	clusterGroupsCmd,
+	clusterInventoryCmd,
+	clusterDriftCmd,
+	clusterStateCmd,
+	clusterJoinPreseedCmd,
+	clusterJoinCheckCmd,
 	clusterNodeCmd,
 	clusterNodeStateCmd,
 	clusterNodesCmd,
 	clusterCertificateCmd,
+	deviceGroupCmd,
+	deviceGroupsCmd,
+	instanceAccessTokensCmd,
 	instanceBackupCmd,
 	instanceBackupExportCmd,
 	instanceBackupsCmd,
 	instanceCmd,
 	instanceConsoleCmd,
+	instanceConsoleSessionsCmd,
+	instanceDeviceGroupCmd,
+	instanceDeviceGroupsCmd,
 	instanceExecCmd,
 	instanceFileCmd,
 	instanceExecOutputCmd,
@@ -73,22 +87,27 @@ var api10 = []APIEndpoint{
 	eventsCmd,
 	imageAliasCmd,
 	imageAliasesCmd,
+	imageCacheExpiryCmd,
 	imageCmd,
 	imageExportCmd,
 	imageRefreshCmd,
+	imageSyncCmd,
 	imagesCmd,
 	imageSecretCmd,
 	metadataConfigurationCmd,
 	networkCmd,
 	networkLeasesCmd,
+	networkDNSRecordsCmd,
 	networksCmd,
 	networkStateCmd,
+	networkCaptureCmd,
 	networkACLCmd,
 	networkACLsCmd,
 	networkACLLogCmd,
 	networkAllocationsCmd,
 	networkForwardCmd,
 	networkForwardsCmd,
+	networkForwardStateCmd,
 	networkLoadBalancerCmd,
 	networkLoadBalancersCmd,
 	networkPeerCmd,
@@ -108,6 +127,9 @@ var api10 = []APIEndpoint{
 	projectStateCmd,
 	storagePoolCmd,
 	storagePoolResourcesCmd,
+	storagePoolBenchmarksCmd,
+	storagePoolBenchmarkCmd,
+	storagePoolMigrateCmd,
 	storagePoolsCmd,
 	storagePoolBucketsCmd,
 	storagePoolBucketCmd,
@@ -116,6 +138,7 @@ var api10 = []APIEndpoint{
 	storagePoolVolumesCmd,
 	storagePoolVolumeSnapshotsTypeCmd,
 	storagePoolVolumeSnapshotTypeCmd,
+	storagePoolVolumeSnapshotTypeSFTPCmd,
 	storagePoolVolumesTypeCmd,
 	storagePoolVolumeTypeCmd,
 	storagePoolVolumeTypeCustomBackupsCmd,
@@ -128,10 +151,15 @@ var api10 = []APIEndpoint{
 	identitiesCmd,
 	identitiesByAuthenticationMethodCmd,
 	identityCmd,
+	identityBearerTokenCmd,
 	authGroupsCmd,
 	authGroupCmd,
+	authRolesCmd,
+	authRoleCmd,
 	identityProviderGroupsCmd,
 	identityProviderGroupCmd,
+	identityProviderGroupRulesCmd,
+	identityProviderGroupRuleCmd,
 	permissionsCmd,
 	storageVolumesCmd,
 	storageVolumesTypeCmd,
@@ -221,20 +249,23 @@ func api10Get(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
 	// Get the authentication methods.
-	authMethods := []string{api.AuthenticationMethodTLS}
+	authMethods := []string{api.AuthenticationMethodTLS, api.AuthenticationMethodBearer}
 
 	oidcIssuer, oidcClientID, _, _ := s.GlobalConfig.OIDCServer()
 	if oidcIssuer != "" && oidcClientID != "" {
 		authMethods = append(authMethods, api.AuthenticationMethodOIDC)
 	}
 
+	internalCA, _ := s.GlobalConfig.InternalCA()
+
 	srv := api.ServerUntrusted{
-		APIExtensions: version.APIExtensions,
-		APIStatus:     "stable",
-		APIVersion:    version.APIVersion,
-		Public:        false,
-		Auth:          "untrusted",
-		AuthMethods:   authMethods,
+		APIExtensions:         version.APIExtensions,
+		APIStatus:             "stable",
+		APIVersion:            version.APIVersion,
+		Public:                false,
+		Auth:                  "untrusted",
+		AuthMethods:           authMethods,
+		CertificateEnrollment: internalCA,
 	}
 
 	// If not authorized, return now. Untrusted users are not authorized.
@@ -797,10 +828,13 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 	bgpChanged := false
 	dnsChanged := false
 	lokiChanged := false
+	auditChanged := false
+	apiRequestLimitsChanged := false
 	acmeDomainChanged := false
 	acmeCAURLChanged := false
 	oidcChanged := false
 	syslogSocketChanged := false
+	hostShutdownProtectionChanged := false
 
 	for key := range clusterChanged {
 		switch key {
@@ -850,6 +884,10 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 			fallthrough
 		case "loki.types":
 			lokiChanged = true
+		case "core.audit_sinks":
+			auditChanged = true
+		case "limits.api.requests.project", "limits.api.requests.identity", "limits.api.requests.burst":
+			apiRequestLimitsChanged = true
 		case "acme.ca_url":
 			acmeCAURLChanged = true
 		case "acme.domain":
@@ -871,6 +909,8 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 			dnsChanged = true
 		case "core.syslog_socket":
 			syslogSocketChanged = true
+		case "core.host_shutdown_protection_override":
+			hostShutdownProtectionChanged = true
 		}
 	}
 
@@ -985,6 +1025,19 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	if auditChanged {
+		auditSinks, _ := clusterConfig.AuditLog()
+
+		err := d.auditLogger.Configure(auditSinks)
+		if err != nil {
+			return err
+		}
+	}
+
+	if apiRequestLimitsChanged {
+		d.configureAPIRequestLimits(clusterConfig.APIRequestLimits())
+	}
+
 	if acmeCAURLChanged || acmeDomainChanged {
 		err := autoRenewCertificate(s.ShutdownCtx, d, acmeCAURLChanged)
 		if err != nil {
@@ -1027,5 +1080,9 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	if hostShutdownProtectionChanged {
+		d.hostShutdownInhibitor.refresh(d.State())
+	}
+
 	return nil
 }
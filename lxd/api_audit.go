@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+var auditCmd = APIEndpoint{
+	Path: "audit",
+
+	Get: APIEndpointAction{Handler: auditGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// auditBodyReader computes the digest of a request body as it is read.
+type auditBodyReader struct {
+	io.ReadCloser
+
+	digest hash.Hash
+	size   int64
+}
+
+// Read reads from the request body and adds the data to the digest.
+func (r *auditBodyReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = r.digest.Write(p[:n])
+		r.size += int64(n)
+	}
+
+	return n, err
+}
+
+// Digest returns the hex encoded digest of the data read so far, or an empty string if nothing was read.
+func (r *auditBodyReader) Digest() string {
+	if r.size == 0 {
+		return ""
+	}
+
+	return hex.EncodeToString(r.digest.Sum(nil))
+}
+
+// recordAuditLogEntry records a handled mutating request in the audit log.
+func (d *Daemon) recordAuditLogEntry(r *http.Request, statusCode int, username string, protocol string, body *auditBodyReader, start time.Time) {
+	projectName := ""
+	if shared.IsFalseOrEmpty(request.QueryParam(r, "all-projects")) {
+		projectName = request.ProjectParam(r)
+	}
+
+	entityURL := &api.URL{}
+	entityURL.URL.Path = r.URL.Path
+	entityURL.URL.RawPath = r.URL.RawPath
+	entityURL.Project(projectName)
+
+	// Requests that hijack the connection (such as websockets) don't set a status code.
+	if statusCode == 0 {
+		statusCode = http.StatusSwitchingProtocols
+	}
+
+	d.auditLogger.Record(api.AuditEntry{
+		Date:                 start,
+		Location:             d.serverName,
+		Identity:             username,
+		AuthenticationMethod: protocol,
+		Project:              projectName,
+		Method:               r.Method,
+		EntityURL:            entityURL.String(),
+		BodyDigest:           body.Digest(),
+		StatusCode:           statusCode,
+	})
+}
+
+// swagger:operation GET /1.0/audit server audit_get
+//
+//	Get the audit log
+//
+//	Returns the mutating API requests recorded in the `api` audit log sink.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: since
+//	    description: Only return the entries recorded after this time (RFC3339)
+//	    type: string
+//	    example: 2024-06-10T00:00:00Z
+//	responses:
+//	  "200":
+//	    description: Audit log entries
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of audit log entries
+//	          items:
+//	            $ref: "#/definitions/AuditEntry"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func auditGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	since := time.Time{}
+	sinceStr := request.QueryParam(r, "since")
+	if sinceStr != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid since time %q: %w", sinceStr, err))
+		}
+	}
+
+	var entries []api.AuditEntry
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		entries, err = dbCluster.GetAuditLogEntries(ctx, tx.Tx(), since)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, entries)
+}
+
+func pruneAuditLogTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		opRun := func(op *operations.Operation) error {
+			return pruneAuditLog(ctx, s)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.AuditLogPrune, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating prune audit log operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Pruning audit log")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting prune audit log operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed pruning audit log", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done pruning audit log")
+	}
+
+	return f, task.Daily()
+}
+
+// pruneAuditLog removes the audit log entries older than the configured retention.
+func pruneAuditLog(ctx context.Context, s *state.State) error {
+	_, retention := s.GlobalConfig.AuditLog()
+
+	// shared.GetExpiry returns the time after the retention period, so reverse it to get the oldest time to keep.
+	now := time.Now()
+	expiry, err := shared.GetExpiry(now, retention)
+	if err != nil {
+		return fmt.Errorf("Failed parsing audit log retention: %w", err)
+	}
+
+	if expiry.IsZero() {
+		return nil
+	}
+
+	before := now.Add(-expiry.Sub(now))
+
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.DeleteAuditLogEntriesBefore(ctx, tx.Tx(), before)
+	})
+}
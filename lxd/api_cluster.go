@@ -434,7 +434,7 @@ func clusterPutBootstrap(d *Daemon, r *http.Request, req api.ClusterPut) respons
 
 	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterBootstrap, resources, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	// Add the cluster flag from the agent
@@ -568,6 +568,26 @@ func clusterPutJoin(d *Daemon, r *http.Request, req api.ClusterPut) response.Res
 			return err
 		}
 
+		// Validate the member specific configuration with the cluster before making any local change.
+		if client.HasExtension("cluster_join_check") {
+			check, err := client.CheckClusterJoin(api.ClusterMemberJoinCheckPost{
+				ServerName:   req.ServerName,
+				MemberConfig: req.MemberConfig,
+			})
+			if err != nil {
+				return fmt.Errorf("Failed checking member configuration: %w", err)
+			}
+
+			if len(check.MissingConfig) > 0 {
+				missing := make([]string, 0, len(check.MissingConfig))
+				for _, key := range check.MissingConfig {
+					missing = append(missing, key.Description)
+				}
+
+				return fmt.Errorf("Missing member configuration: %s", strings.Join(missing, ", "))
+			}
+		}
+
 		// As ServerAddress field is required to be set it means that we're using the new join API
 		// introduced with the 'clustering_join' extension.
 		// Connect to ourselves to initialize storage pools and networks using the API.
@@ -839,7 +859,7 @@ func clusterPutJoin(d *Daemon, r *http.Request, req api.ClusterPut) response.Res
 
 	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterJoin, resources, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -1321,7 +1341,7 @@ func clusterNodesPost(d *Daemon, r *http.Request) response.Response {
 
 		// Filter to online members.
 		for _, member := range members {
-			if member.State == db.ClusterMemberStateEvacuated || member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+			if member.State == db.ClusterMemberStateEvacuated || member.State == db.ClusterMemberStateUpgrading || member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
 				continue
 			}
 
@@ -1399,7 +1419,7 @@ func clusterNodesPost(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, api.ProjectDefaultName, operations.OperationClassToken, operationtype.ClusterJoinToken, resources, meta, nil, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	s.Events.SendLifecycle(request.ProjectParam(r), lifecycle.ClusterTokenCreated.Event("members", op.Requestor(), nil))
@@ -2318,6 +2338,20 @@ func updateClusterCertificate(ctx context.Context, s *state.State, gateway *clus
 				}
 			})
 		}
+
+		// The key of the built-in certificate authority is encrypted with the cluster private key.
+		oldKey := s.Endpoints.NetworkCert().PrivateKey()
+		err = certificateAuthorityReencryptKey(ctx, s, oldKey, newCertInfo.PrivateKey())
+		if err != nil {
+			return fmt.Errorf("Failed to re-encrypt the certificate authority key: %w", err)
+		}
+
+		revert.Add(func() {
+			err := certificateAuthorityReencryptKey(context.TODO(), s, newCertInfo.PrivateKey(), oldKey)
+			if err != nil {
+				logger.Error("Failed to restore the certificate authority key", logger.Ctx{"err": err})
+			}
+		})
 	}
 
 	err := util.WriteCert(s.OS.VarDir, "cluster", []byte(req.ClusterCertificate), []byte(req.ClusterCertificateKey), nil)
@@ -2935,9 +2969,9 @@ func clusterNodeStateGet(d *Daemon, r *http.Request) response.Response {
 
 // swagger:operation POST /1.0/cluster/members/{name}/state cluster cluster_member_state_post
 //
-//	Evacuate or restore a cluster member
+//	Evacuate, upgrade or restore a cluster member
 //
-//	Evacuates or restores a cluster member.
+//	Evacuates, upgrades or restores a cluster member.
 //
 //	---
 //	consumes:
@@ -2981,7 +3015,7 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
-	if req.Action == "evacuate" {
+	if req.Action == "evacuate" || req.Action == "upgrade" {
 		stopFunc := func(inst instance.Instance) error {
 			l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
@@ -3055,9 +3089,19 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 			return nil
 		}
 
-		return evacuateClusterMember(s, d.gateway, r, req.Mode, stopFunc, migrateFunc)
+		memberState := db.ClusterMemberStateEvacuated
+		if req.Action == "upgrade" {
+			memberState = db.ClusterMemberStateUpgrading
+		}
+
+		return evacuateClusterMember(s, d.gateway, r, req.Mode, memberState, stopFunc, migrateFunc)
 	} else if req.Action == "restore" {
-		return restoreClusterMember(d, r)
+		op, err := restoreClusterMember(s, r, name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return operations.OperationResponse(op)
 	}
 
 	return response.BadRequest(fmt.Errorf("Unknown action %q", req.Action))
@@ -3127,7 +3171,7 @@ func internalClusterHeal(d *Daemon, r *http.Request) response.Response {
 		return nil
 	}
 
-	return evacuateClusterMember(d.State(), d.gateway, r, "migrate", nil, migrateFunc)
+	return evacuateClusterMember(d.State(), d.gateway, r, "migrate", db.ClusterMemberStateEvacuated, nil, migrateFunc)
 }
 
 func evacuateClusterSetState(s *state.State, name string, state int) error {
@@ -3142,6 +3186,10 @@ func evacuateClusterSetState(s *state.State, name string, state int) error {
 			return fmt.Errorf("Cannot evacuate or restore a pending cluster member")
 		}
 
+		if node.State == db.ClusterMemberStateUpgrading && state != db.ClusterMemberStateCreated {
+			return fmt.Errorf("Cluster member is already being upgraded")
+		}
+
 		// Do nothing if the node is already in expected state.
 		if node.State == state {
 			if state == db.ClusterMemberStateEvacuated {
@@ -3166,7 +3214,10 @@ func evacuateClusterSetState(s *state.State, name string, state int) error {
 // evacuateHostShutdownDefaultTimeout default timeout (in seconds) for waiting for clean shutdown to complete.
 const evacuateHostShutdownDefaultTimeout = 30
 
-func evacuateClusterMember(s *state.State, gateway *cluster.Gateway, r *http.Request, mode string, stopInstance evacuateStopFunc, migrateInstance evacuateMigrateFunc) response.Response {
+// evacuateClusterMember moves the instances away from a cluster member and puts it in the given state, which is
+// either evacuated or upgrading. Members put in the upgrading state are then upgraded using the cluster update
+// executable (LXD_CLUSTER_UPDATE) if set, and restored automatically once they come back up.
+func evacuateClusterMember(s *state.State, gateway *cluster.Gateway, r *http.Request, mode string, memberState int, stopInstance evacuateStopFunc, migrateInstance evacuateMigrateFunc) response.Response {
 	nodeName, err := url.PathUnescape(mux.Vars(r)["name"])
 	if err != nil {
 		return response.SmartError(err)
@@ -3203,8 +3254,8 @@ func evacuateClusterMember(s *state.State, gateway *cluster.Gateway, r *http.Req
 		revert := revert.New()
 		defer revert.Fail()
 
-		// Set node status to EVACUATED.
-		err := evacuateClusterSetState(s, nodeName, db.ClusterMemberStateEvacuated)
+		// Set node status to EVACUATED (or UPGRADING).
+		err := evacuateClusterSetState(s, nodeName, memberState)
 		if err != nil {
 			return err
 		}
@@ -3234,12 +3285,25 @@ func evacuateClusterMember(s *state.State, gateway *cluster.Gateway, r *http.Req
 		}
 
 		revert.Success()
+
+		if memberState == db.ClusterMemberStateUpgrading {
+			// The update restarts the daemon so run it in the background to let the operation complete.
+			go func() {
+				triggered, err := cluster.TriggerMemberUpgrade()
+				if err != nil {
+					logger.Error("Failed upgrading cluster member", logger.Ctx{"member": nodeName, "err": err})
+				} else if !triggered {
+					logger.Warn("No cluster update executable set, cluster member must be upgraded manually", logger.Ctx{"member": nodeName})
+				}
+			}()
+		}
+
 		return nil
 	}
 
 	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterMemberEvacuate, nil, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -3337,17 +3401,11 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 	return nil
 }
 
-func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
-	s := d.State()
-
-	originName, err := url.PathUnescape(mux.Vars(r)["name"])
-	if err != nil {
-		return response.SmartError(err)
-	}
-
+// restoreClusterMember returns an operation bringing back the instances of an evacuated or upgraded cluster member.
+func restoreClusterMember(s *state.State, r *http.Request, originName string) (*operations.Operation, error) {
 	// List the instances.
 	var dbInstances []dbCluster.Instance
-	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		dbInstances, err = dbCluster.GetInstances(ctx, tx.Tx())
 		if err != nil {
 			return fmt.Errorf("Failed to get instances: %w", err)
@@ -3356,7 +3414,7 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 		return nil
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return nil, err
 	}
 
 	instances := make([]instance.Instance, 0)
@@ -3365,7 +3423,7 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 	for _, dbInst := range dbInstances {
 		inst, err := instance.LoadByProjectAndName(s, dbInst.Project, dbInst.Name)
 		if err != nil {
-			return response.SmartError(fmt.Errorf("Failed to load instance: %w", err))
+			return nil, fmt.Errorf("Failed to load instance: %w", err)
 		}
 
 		if dbInst.Node == originName {
@@ -3556,12 +3614,58 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 		return nil
 	}
 
-	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterMemberRestore, nil, nil, run, nil, nil, r)
+	return operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterMemberRestore, nil, nil, run, nil, nil, r)
+}
+
+// clusterMemberUpgradeRejoin restores the local member if it was drained for a rolling upgrade. It's called once the
+// daemon is ready, which for an upgraded member only happens after all members have been upgraded and the
+// database schema has been updated.
+func clusterMemberUpgradeRejoin(s *state.State) {
+	var memberName string
+	var upgrading bool
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		memberName, err = tx.GetLocalNodeName(ctx)
+		if err != nil {
+			return err
+		}
+
+		member, err := tx.GetNodeByName(ctx, memberName)
+		if err != nil {
+			return err
+		}
+
+		upgrading = member.State == db.ClusterMemberStateUpgrading
+		return nil
+	})
+	if err != nil {
+		logger.Warn("Failed checking whether the cluster member is being upgraded", logger.Ctx{"err": err})
+		return
+	}
+
+	if !upgrading {
+		return
+	}
+
+	logger.Info("Restoring cluster member after upgrade", logger.Ctx{"member": memberName})
+
+	op, err := restoreClusterMember(s, nil, memberName)
+	if err == nil {
+		err = op.Start()
+	}
+
+	if err == nil {
+		err = op.Wait(s.ShutdownCtx)
+	}
+
 	if err != nil {
-		return response.InternalError(err)
+		logger.Error("Failed restoring cluster member after upgrade", logger.Ctx{"member": memberName, "err": err})
+		return
 	}
 
-	return operations.OperationResponse(op)
+	logger.Info("Restored cluster member after upgrade", logger.Ctx{"member": memberName})
 }
 
 // swagger:operation POST /1.0/cluster/groups cluster cluster_groups_post
@@ -4423,9 +4527,9 @@ func autoHealClusterTask(d *Daemon) (task.Func, task.Schedule) {
 			}
 
 			for _, member := range members {
-				// Ignore members which have been evacuated, and those which haven't exceeded the
-				// healing offline trigger threshold.
-				if member.State == db.ClusterMemberStateEvacuated || !member.IsOffline(healingThreshold) {
+				// Ignore members which have been evacuated or drained for an upgrade, and those which haven't
+				// exceeded the healing offline trigger threshold.
+				if member.State == db.ClusterMemberStateEvacuated || member.State == db.ClusterMemberStateUpgrading || !member.IsOffline(healingThreshold) {
 					continue
 				}
 
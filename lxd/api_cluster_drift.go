@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/config"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
+	"github.com/canonical/lxd/lxd/node"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var clusterDriftCmd = APIEndpoint{
+	Path: "cluster/drift",
+
+	Get: APIEndpointAction{Handler: clusterDriftGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanView)},
+}
+
+var internalClusterDriftCmd = APIEndpoint{
+	Path: "cluster/drift",
+
+	Get: APIEndpointAction{Handler: internalClusterDriftGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanView)},
+}
+
+// swagger:operation GET /1.0/cluster/drift cluster cluster_drift_get
+//
+//	Get the settings that differ between cluster members
+//
+//	Compares the member specific configuration, the versions of the server, the instance and storage
+//	drivers and OVN, and the kernel features of the online cluster members.
+//	Returns the settings whose value isn't the same on all of them.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Settings that differ between cluster members
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of differing settings
+//	          items:
+//	            $ref: "#/definitions/ClusterDrift"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDriftGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	drift, err := clusterDrift(r.Context(), s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, drift)
+}
+
+// internalClusterDriftGet returns the settings of the local member that are compared between cluster members.
+func internalClusterDriftGet(d *Daemon, r *http.Request) response.Response {
+	return response.SyncResponse(true, clusterMemberSettings(d.State()))
+}
+
+// clusterMemberSettings returns the settings of the local member that are expected to be the same on all members.
+func clusterMemberSettings(s *state.State) map[string]string {
+	settings := map[string]string{
+		"server.version": version.Version,
+		"kernel.version": s.OS.Uname.Release,
+
+		"kernel_features.netnsid_getifaddrs":        fmt.Sprintf("%v", s.OS.NetnsGetifaddrs),
+		"kernel_features.uevent_injection":          fmt.Sprintf("%v", s.OS.UeventInjection),
+		"kernel_features.unpriv_fscaps":             fmt.Sprintf("%v", s.OS.VFS3Fscaps),
+		"kernel_features.seccomp_listener":          fmt.Sprintf("%v", s.OS.SeccompListener),
+		"kernel_features.seccomp_listener_continue": fmt.Sprintf("%v", s.OS.SeccompListenerContinue),
+		"kernel_features.idmapped_mounts":           fmt.Sprintf("%v", s.OS.IdmappedMounts),
+	}
+
+	for _, driver := range instanceDrivers.DriverStatuses() {
+		if !driver.Supported {
+			continue
+		}
+
+		settings["driver."+driver.Info.Name] = driver.Info.Version
+	}
+
+	supportedStorageDrivers, _ := readStoragePoolDriversCache()
+	for _, driver := range supportedStorageDrivers {
+		settings["storage_driver."+driver.Name] = driver.Version
+	}
+
+	// The first line of the output is the name of the tool followed by the OVN version.
+	_, err := exec.LookPath("ovn-nbctl")
+	if err == nil {
+		out, err := shared.RunCommandContext(context.TODO(), "ovn-nbctl", "--version")
+		if err == nil {
+			fields := strings.Fields(strings.SplitN(out, "\n", 2)[0])
+			if len(fields) > 1 {
+				settings["ovn.version"] = fields[1]
+			}
+		}
+	}
+
+	// Member specific addresses and volumes differ between members, so only compare whether they are set.
+	localConfig := s.LocalConfig.Dump()
+	for key, schemaKey := range node.ConfigSchema {
+		value, ok := localConfig[key]
+		if schemaKey.Type == config.Bool {
+			settings["config."+key] = fmt.Sprintf("%v", shared.IsTrue(fmt.Sprintf("%v", value)))
+		} else if ok && value != "" {
+			settings["config."+key] = "set"
+		} else {
+			settings["config."+key] = "unset"
+		}
+	}
+
+	return settings
+}
+
+// clusterDrift returns the settings that differ between the online cluster members.
+func clusterDrift(ctx context.Context, s *state.State) ([]api.ClusterDrift, error) {
+	var members []db.NodeInfo
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		members, err = tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	offlineThreshold := s.GlobalConfig.OfflineThreshold()
+	memberSettings := map[string]map[string]string{}
+	for _, member := range members {
+		if member.IsOffline(offlineThreshold) {
+			continue
+		}
+
+		if member.Name == s.ServerName {
+			memberSettings[member.Name] = clusterMemberSettings(s)
+			continue
+		}
+
+		client, err := cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+		if err != nil {
+			return nil, fmt.Errorf("Failed connecting to cluster member %q: %w", member.Name, err)
+		}
+
+		resp, _, err := client.RawQuery(http.MethodGet, "/internal/cluster/drift", nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting settings of cluster member %q: %w", member.Name, err)
+		}
+
+		settings := map[string]string{}
+		err = json.Unmarshal(resp.Metadata, &settings)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing settings of cluster member %q: %w", member.Name, err)
+		}
+
+		memberSettings[member.Name] = settings
+	}
+
+	// Collect the settings reported by any member, as settings missing on a member (such as a storage driver
+	// which isn't available) are drift too.
+	keys := []string{}
+	for _, settings := range memberSettings {
+		for key := range settings {
+			if !shared.ValueInSlice(key, keys) {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	drift := []api.ClusterDrift{}
+	for _, key := range keys {
+		values := map[string]string{}
+		differs := false
+		first := ""
+		for memberName, settings := range memberSettings {
+			values[memberName] = settings[key]
+			if len(values) == 1 {
+				first = settings[key]
+			} else if settings[key] != first {
+				differs = true
+			}
+		}
+
+		if differs {
+			drift = append(drift, api.ClusterDrift{Setting: key, Values: values})
+		}
+	}
+
+	return drift, nil
+}
+
+func clusterDriftTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil {
+			if errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				return // Skip the check if not clustered.
+			}
+
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if s.LocalConfig.ClusterAddress() != leader {
+			return // Skip the check if not cluster leader.
+		}
+
+		drift, err := clusterDrift(ctx, s)
+		if err != nil {
+			logger.Warn("Failed checking for settings drift between cluster members", logger.Ctx{"err": err})
+			return
+		}
+
+		if len(drift) == 0 {
+			_ = warnings.ResolveWarningsByLocalNodeAndType(s.DB.Cluster, warningtype.ClusterMemberDrift)
+			return
+		}
+
+		settings := make([]string, 0, len(drift))
+		for _, setting := range drift {
+			settings = append(settings, setting.Setting)
+		}
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, "", "", -1, warningtype.ClusterMemberDrift, fmt.Sprintf("Settings differing between cluster members: %s", strings.Join(settings, ", ")))
+		})
+		if err != nil {
+			logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Hourly()
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// clusterInventoryDefaultLimit is the number of entries returned per page if not specified by the client.
+const clusterInventoryDefaultLimit = 1000
+
+var clusterInventoryCmd = APIEndpoint{
+	Path: "cluster/inventory",
+
+	Get: APIEndpointAction{Handler: clusterInventoryGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanView)},
+}
+
+// swagger:operation GET /1.0/cluster/inventory cluster cluster_inventory_get
+//
+//	Get the cluster inventory
+//
+//	Returns a page of the inventory of the cluster, covering the cluster members, projects, instances,
+//	storage volumes, networks and images in all projects.
+//	The whole inventory is read from the database in a single transaction, without querying the cluster members.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: after
+//	    description: Only return entries after this one (as given in "next" of the previous page)
+//	    type: string
+//	    example: /1.0/instances/c1?project=default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return (defaults to 1000)
+//	    type: integer
+//	    example: 100
+//	responses:
+//	  "200":
+//	    description: Cluster inventory
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterInventory"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterInventoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	after := request.QueryParam(r, "after")
+
+	limit := clusterInventoryDefaultLimit
+	if request.QueryParam(r, "limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(request.QueryParam(r, "limit"))
+		if err != nil || limit <= 0 {
+			return response.BadRequest(fmt.Errorf("Invalid limit %q", request.QueryParam(r, "limit")))
+		}
+	}
+
+	// Project specific entities are only listed if the caller can view them.
+	canView := map[entity.Type]auth.PermissionChecker{}
+	for _, entityType := range []entity.Type{entity.TypeProject, entity.TypeInstance, entity.TypeStorageVolume, entity.TypeNetwork, entity.TypeImage} {
+		checker, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entityType)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		canView[entityType] = checker
+	}
+
+	// Only report the location of entities when clustered.
+	location := func(memberName string) string {
+		if !s.ServerClustered {
+			return ""
+		}
+
+		return memberName
+	}
+
+	entries := []api.ClusterInventoryEntry{}
+
+	// Helper function to add an entity to the inventory.
+	addEntry := func(entityType string, u *api.URL, entry api.ClusterInventoryEntry) {
+		checker, ok := canView[entity.Type(entityType)]
+		if ok && !checker(u) {
+			return
+		}
+
+		entry.EntityType = entityType
+		entry.URL = u.String()
+
+		// Volatile keys aren't user configuration and change too often to be of use in an inventory.
+		config := make(map[string]string, len(entry.Config))
+		for k, v := range entry.Config {
+			if !strings.HasPrefix(k, instancetype.ConfigVolatilePrefix) {
+				config[k] = v
+			}
+		}
+
+		entry.Config = config
+
+		if entry.Properties == nil {
+			entry.Properties = map[string]string{}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	// Helper function to get the URL of a project specific entity.
+	entityURL := func(entityType entity.Type, entry api.ClusterInventoryEntry, pathArguments ...string) (*api.URL, error) {
+		return entityType.URL(entry.Project, entry.Location, pathArguments...)
+	}
+
+	// Helper function to get the name of an architecture without failing on unknown ones.
+	architectureName := func(arch int) string {
+		name, err := osarch.ArchitectureName(arch)
+		if err != nil {
+			return ""
+		}
+
+		return name
+	}
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Cluster members.
+		if s.ServerClustered {
+			members, err := tx.GetNodes(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed loading cluster members: %w", err)
+			}
+
+			for _, member := range members {
+				status := "Online"
+				if member.State == db.ClusterMemberStateEvacuated {
+					status = "Evacuated"
+				} else if member.State == db.ClusterMemberStateUpgrading {
+					status = "Upgrading"
+				} else if member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+					status = "Offline"
+				}
+
+				roles := make([]string, 0, len(member.Roles))
+				for _, role := range member.Roles {
+					roles = append(roles, string(role))
+				}
+
+				addEntry("cluster_member", api.NewURL().Path(version.APIVersion, "cluster", "members", member.Name), api.ClusterInventoryEntry{
+					Name:        member.Name,
+					Description: member.Description,
+					Status:      status,
+					Config:      member.Config,
+					Properties: map[string]string{
+						"address":      member.Address,
+						"architecture": architectureName(member.Architecture),
+						"roles":        strings.Join(roles, ","),
+						"groups":       strings.Join(member.Groups, ","),
+					},
+				})
+			}
+		}
+
+		// Projects.
+		projects, err := dbCluster.GetProjects(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading projects: %w", err)
+		}
+
+		for _, p := range projects {
+			config, err := dbCluster.GetProjectConfig(ctx, tx.Tx(), p.ID)
+			if err != nil {
+				return fmt.Errorf("Failed loading project %q config: %w", p.Name, err)
+			}
+
+			entry := api.ClusterInventoryEntry{
+				Name:        p.Name,
+				Description: p.Description,
+				Config:      config,
+			}
+
+			u, err := entityURL(entity.TypeProject, entry, p.Name)
+			if err != nil {
+				return err
+			}
+
+			addEntry(string(entity.TypeProject), u, entry)
+		}
+
+		// Instances.
+		instances, err := dbCluster.GetInstances(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading instances: %w", err)
+		}
+
+		instanceArgs, err := tx.InstancesToInstanceArgs(ctx, true, instances...)
+		if err != nil {
+			return err
+		}
+
+		for _, inst := range instanceArgs {
+			profiles := make([]string, 0, len(inst.Profiles))
+			for _, profile := range inst.Profiles {
+				profiles = append(profiles, profile.Name)
+			}
+
+			entry := api.ClusterInventoryEntry{
+				Name:        inst.Name,
+				Project:     inst.Project,
+				Location:    location(inst.Node),
+				Description: inst.Description,
+				Config:      inst.Config,
+				Properties: map[string]string{
+					"type":         inst.Type.String(),
+					"architecture": architectureName(inst.Architecture),
+					"profiles":     strings.Join(profiles, ","),
+					"base_image":   inst.Config["volatile.base_image"],
+				},
+			}
+
+			u, err := entityURL(entity.TypeInstance, entry, inst.Name)
+			if err != nil {
+				return err
+			}
+
+			addEntry(string(entity.TypeInstance), u, entry)
+		}
+
+		// Storage volumes (snapshots are left out).
+		volumes, err := tx.GetStorageVolumes(ctx, false)
+		if err != nil {
+			return fmt.Errorf("Failed loading storage volumes: %w", err)
+		}
+
+		for _, vol := range volumes {
+			if shared.IsSnapshot(vol.Name) {
+				continue
+			}
+
+			entry := api.ClusterInventoryEntry{
+				Name:        vol.Name,
+				Project:     vol.Project,
+				Location:    location(vol.Location),
+				Description: vol.Description,
+				Config:      vol.Config,
+				Properties: map[string]string{
+					"pool":         vol.Pool,
+					"type":         vol.Type,
+					"content_type": vol.ContentType,
+				},
+			}
+
+			u, err := entityURL(entity.TypeStorageVolume, entry, vol.Pool, vol.Type, vol.Name)
+			if err != nil {
+				return err
+			}
+
+			addEntry(string(entity.TypeStorageVolume), u, entry)
+		}
+
+		// Networks.
+		networks, err := tx.GetCreatedNetworks(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading networks: %w", err)
+		}
+
+		for projectName, projectNetworks := range networks {
+			for _, network := range projectNetworks {
+				entry := api.ClusterInventoryEntry{
+					Name:        network.Name,
+					Project:     projectName,
+					Description: network.Description,
+					Status:      network.Status,
+					Config:      network.Config,
+					Properties: map[string]string{
+						"type": network.Type,
+					},
+				}
+
+				u, err := entityURL(entity.TypeNetwork, entry, network.Name)
+				if err != nil {
+					return err
+				}
+
+				addEntry(string(entity.TypeNetwork), u, entry)
+			}
+		}
+
+		// Images.
+		images, err := dbCluster.GetImages(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading images: %w", err)
+		}
+
+		for _, image := range images {
+			entry := api.ClusterInventoryEntry{
+				Name:    image.Fingerprint,
+				Project: image.Project,
+				Properties: map[string]string{
+					"type":         instancetype.Type(image.Type).String(),
+					"architecture": architectureName(image.Architecture),
+					"filename":     image.Filename,
+					"size":         strconv.FormatInt(image.Size, 10),
+					"public":       strconv.FormatBool(image.Public),
+					"cached":       strconv.FormatBool(image.Cached),
+				},
+			}
+
+			u, err := entityURL(entity.TypeImage, entry, image.Fingerprint)
+			if err != nil {
+				return err
+			}
+
+			addEntry(string(entity.TypeImage), u, entry)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Order the entries by URL so that pages can be requested with the URL of the last entry.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].URL < entries[j].URL })
+
+	start := 0
+	if after != "" {
+		start = sort.Search(len(entries), func(i int) bool { return entries[i].URL > after })
+	}
+
+	end := min(start+limit, len(entries))
+
+	inventory := api.ClusterInventory{
+		Entries: entries[start:end],
+	}
+
+	if end < len(entries) {
+		inventory.Next = entries[end-1].URL
+	}
+
+	return response.SyncResponse(true, inventory)
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/resources"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+var clusterJoinPreseedCmd = APIEndpoint{
+	Path: "cluster/join-preseed",
+
+	Get: APIEndpointAction{Handler: clusterJoinPreseedGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var clusterJoinCheckCmd = APIEndpoint{
+	Path: "cluster/join-check",
+
+	Post: APIEndpointAction{Handler: clusterJoinCheckPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// swagger:operation GET /1.0/cluster/join-preseed cluster cluster_join_preseed_get
+//
+//	Get a preseed for joining the cluster
+//
+//	Returns a `lxd init` preseed that a new member can use to join the cluster.
+//	The preseed contains all the member specific configuration keys of the storage pools and networks
+//	of the cluster, along with values suggested from the configuration and hardware of the member
+//	answering the request. A join token must be added to the preseed before it can be used.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: server_name
+//	    description: Name of the new member
+//	    type: string
+//	    example: lxd02
+//	  - in: query
+//	    name: server_address
+//	    description: Address of the new member
+//	    type: string
+//	    example: 10.0.0.2:8443
+//	responses:
+//	  "200":
+//	    description: Join preseed
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InitPreseed"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterJoinPreseedGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	serverName := request.QueryParam(r, "server_name")
+	serverAddress := request.QueryParam(r, "server_address")
+	if serverAddress != "" {
+		serverAddress = util.CanonicalNetworkAddress(serverAddress, shared.HTTPSDefaultPort)
+	}
+
+	memberConfig, _, err := clusterJoinMemberConfig(r.Context(), s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	preseed := api.InitPreseed{
+		Node: api.InitLocalPreseed{
+			ServerPut: api.ServerPut{
+				Config: map[string]any{},
+			},
+		},
+		Cluster: &api.InitClusterPreseed{
+			ClusterPut: api.ClusterPut{
+				Cluster: api.Cluster{
+					ServerName:   serverName,
+					Enabled:      true,
+					MemberConfig: memberConfig,
+				},
+				ClusterAddress:     s.LocalConfig.ClusterAddress(),
+				ClusterCertificate: string(s.Endpoints.NetworkCert().PublicKey()),
+				ServerAddress:      serverAddress,
+			},
+		},
+	}
+
+	if serverAddress != "" {
+		preseed.Node.Config["core.https_address"] = serverAddress
+	}
+
+	return response.SyncResponse(true, preseed)
+}
+
+// swagger:operation POST /1.0/cluster/join-check cluster cluster_join_check_post
+//
+//	Check the configuration of a new member
+//
+//	Checks that a new member can join the cluster with the provided member specific configuration
+//	of the storage pools and networks, before any change is made to the new member.
+//	Returns the member specific configuration keys that still need a value, along with a value
+//	suggested from the configuration and hardware of the member answering the request.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: member
+//	    description: Configuration of the new member
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ClusterMemberJoinCheckPost"
+//	responses:
+//	  "200":
+//	    description: Join check result
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterMemberJoinCheck"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterJoinCheckPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	req := api.ClusterMemberJoinCheckPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.ServerName == "" {
+		return response.BadRequest(fmt.Errorf("No server name provided"))
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := tx.GetNodeByName(ctx, req.ServerName)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "The cluster already has a member with name %q", req.ServerName)
+		}
+
+		if !response.IsNotFoundError(err) {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	memberConfig, required, err := clusterJoinMemberConfig(r.Context(), s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Reject keys that the storage pools and networks of the cluster don't expect, as they would be ignored
+	// when initializing the new member.
+	provided := make(map[string]string, len(req.MemberConfig))
+	for _, key := range req.MemberConfig {
+		id := clusterJoinMemberConfigID(key)
+		_, ok := required[id]
+		if !ok {
+			return response.BadRequest(fmt.Errorf("Unexpected %q key for %s %q", key.Key, key.Entity, key.Name))
+		}
+
+		provided[id] = key.Value
+	}
+
+	check := api.ClusterMemberJoinCheck{
+		MissingConfig: []api.ClusterMemberConfigKey{},
+	}
+
+	for _, key := range memberConfig {
+		id := clusterJoinMemberConfigID(key)
+		if provided[id] == "" && required[id] {
+			check.MissingConfig = append(check.MissingConfig, key)
+		}
+	}
+
+	return response.SyncResponse(true, check)
+}
+
+// clusterJoinMemberConfig returns the member specific configuration keys of the storage pools and networks of the
+// cluster, with the values suggested for a new member. It also returns the keys (in the "<entity>/<name>/<key>" form)
+// that a new member must provide a value for.
+func clusterJoinMemberConfig(ctx context.Context, s *state.State) ([]api.ClusterMemberConfigKey, map[string]bool, error) {
+	memberConfig, err := clusterGetMemberConfig(s.DB.Cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pools map[string]map[string]string
+	var networks map[string]map[string]string
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		pools, err = tx.GetStoragePoolsLocalConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to fetch storage pools configuration: %w", err)
+		}
+
+		networks, err = tx.GetNetworksLocalConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to fetch networks configuration: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The physical network ports of this member are used to check which interfaces can be suggested.
+	networkResources, err := resources.GetNetwork()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get network resources: %w", err)
+	}
+
+	ports := map[string]bool{}
+	for _, card := range networkResources.Cards {
+		for _, port := range card.Ports {
+			ports[port.ID] = true
+		}
+	}
+
+	required := map[string]bool{}
+	for i, key := range memberConfig {
+		switch key.Entity {
+		case "storage-pool":
+			// An empty suggestion is only given for keys that don't need a value on the new member,
+			// like the source of loop backed pools.
+			memberConfig[i].Value = clusterJoinSuggestStoragePoolValue(key.Key, pools[key.Name][key.Key])
+			required[clusterJoinMemberConfigID(key)] = memberConfig[i].Value != ""
+		case "network":
+			memberConfig[i].Value = clusterJoinSuggestNetworkValue(key.Key, networks[key.Name][key.Key], ports)
+			required[clusterJoinMemberConfigID(key)] = networks[key.Name][key.Key] != ""
+		}
+	}
+
+	return memberConfig, required, nil
+}
+
+// clusterJoinMemberConfigID returns the identifier of a member specific configuration key.
+func clusterJoinMemberConfigID(key api.ClusterMemberConfigKey) string {
+	return key.Entity + "/" + key.Name + "/" + key.Key
+}
+
+// clusterJoinSuggestStoragePoolValue returns the value suggested to a new member for a member specific storage
+// pool key, given the value of the key on this member.
+func clusterJoinSuggestStoragePoolValue(key string, value string) string {
+	if key != "source" || value == "" {
+		return value
+	}
+
+	// Loop files are created by the new member itself when the source is left empty.
+	if strings.HasPrefix(value, shared.VarPath("disks")+"/") {
+		return ""
+	}
+
+	// Persistent device paths (like /dev/disk/by-id/) embed serial numbers which are unique to this
+	// machine, so suggest the kernel name of the device instead which is more likely to match on the
+	// new member.
+	if strings.HasPrefix(value, "/dev/disk/") {
+		devPath, err := filepath.EvalSymlinks(value)
+		if err == nil && shared.IsBlockdevPath(devPath) {
+			return devPath
+		}
+	}
+
+	return value
+}
+
+// clusterJoinSuggestNetworkValue returns the value suggested to a new member for a member specific network key,
+// given the value of the key on this member and the physical network ports of this member.
+func clusterJoinSuggestNetworkValue(key string, value string, ports map[string]bool) string {
+	if value == "" || !shared.ValueInSlice(key, []string{"parent", "bridge.external_interfaces"}) {
+		return value
+	}
+
+	// Only suggest physical ports as their names are derived from the hardware and are likely to be the same
+	// on the new member, unlike virtual interfaces (like VLANs or bonds) which it must create beforehand.
+	suggested := []string{}
+	for _, iface := range shared.SplitNTrimSpace(value, ",", -1, true) {
+		if ports[iface] {
+			suggested = append(suggested, iface)
+		}
+	}
+
+	return strings.Join(suggested, ",")
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+var clusterStateCmd = APIEndpoint{
+	Path: "cluster/state",
+
+	Get: APIEndpointAction{Handler: clusterStateGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanView)},
+}
+
+// swagger:operation GET /1.0/cluster/state cluster cluster_state_get
+//
+//	Get the health of the cluster
+//
+//	Returns the database leader along with the database role, last heartbeat and version
+//	of each cluster member, as seen by the member answering the request.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Cluster health
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterState"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterStateGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	leaderAddress, err := d.gateway.LeaderAddress()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	var raftNodes []db.RaftNode
+	err = s.DB.Node.Transaction(r.Context(), func(ctx context.Context, tx *db.NodeTx) error {
+		raftNodes, err = tx.GetRaftNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading RAFT nodes: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	raftRoles := make(map[string]string, len(raftNodes))
+	for _, raftNode := range raftNodes {
+		raftRoles[raftNode.Address] = raftNode.Role.String()
+	}
+
+	clusterState := api.ClusterState{
+		LeaderAddress: leaderAddress,
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		members, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		maxVersion, err := tx.GetNodeMaxVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting max member version: %w", err)
+		}
+
+		clusterState.SchemaVersion = maxVersion[0]
+		clusterState.APIExtensions = maxVersion[1]
+
+		args := db.NodeInfoArgs{
+			LeaderAddress:    leaderAddress,
+			OfflineThreshold: s.GlobalConfig.OfflineThreshold(),
+			MaxMemberVersion: maxVersion,
+			RaftNodes:        raftNodes,
+		}
+
+		clusterState.Members = make([]api.ClusterStateMember, 0, len(members))
+		for _, member := range members {
+			memberInfo, err := member.ToAPI(ctx, tx, args)
+			if err != nil {
+				return err
+			}
+
+			// A member whose version is lower than the highest one prevents the upgraded members from starting.
+			outdated, err := util.CompareVersions(maxVersion, member.Version())
+			if err != nil {
+				return err
+			}
+
+			clusterState.Members = append(clusterState.Members, api.ClusterStateMember{
+				ServerName:     member.Name,
+				Address:        member.Address,
+				Status:         memberInfo.Status,
+				Message:        memberInfo.Message,
+				DatabaseRole:   raftRoles[member.Address],
+				DatabaseLeader: member.Address == leaderAddress,
+				LastHeartbeat:  member.Heartbeat,
+				SchemaVersion:  member.Schema,
+				APIExtensions:  member.APIExtensions,
+				PendingUpgrade: outdated == 1,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, clusterState)
+}
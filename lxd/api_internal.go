@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -13,6 +14,7 @@ import (
 	runtimeDebug "runtime/debug"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"golang.org/x/sys/unix"
@@ -26,6 +28,7 @@ import (
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
@@ -49,6 +52,7 @@ var apiInternal = []APIEndpoint{
 	internalClusterRaftNodeCmd,
 	internalClusterRebalanceCmd,
 	internalClusterHealCmd,
+	internalClusterDriftCmd,
 	internalContainerOnStartCmd,
 	internalContainerOnStopCmd,
 	internalContainerOnStopNSCmd,
@@ -61,6 +65,8 @@ var apiInternal = []APIEndpoint{
 	internalSQLCmd,
 	internalWarningCreateCmd,
 	internalIdentityCacheRefreshCmd,
+	internalNetworkLoadBalancerBGPRefreshCmd,
+	internalNetworkMembersRefreshCmd,
 }
 
 var internalShutdownCmd = APIEndpoint{
@@ -142,6 +148,18 @@ var internalIdentityCacheRefreshCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: internalIdentityCacheRefresh, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalNetworkLoadBalancerBGPRefreshCmd = APIEndpoint{
+	Path: "networks/{networkName}/load-balancers/bgp-refresh",
+
+	Post: APIEndpointAction{Handler: internalNetworkLoadBalancerBGPRefresh, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalNetworkMembersRefreshCmd = APIEndpoint{
+	Path: "networks/{networkName}/members-refresh",
+
+	Post: APIEndpointAction{Handler: internalNetworkMembersRefresh, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 type internalImageOptimizePost struct {
 	Image api.Image `json:"image" yaml:"image"`
 	Pool  string    `json:"pool"  yaml:"pool"`
@@ -392,8 +410,11 @@ type internalSQLDump struct {
 }
 
 type internalSQLQuery struct {
-	Database string `json:"database" yaml:"database"`
-	Query    string `json:"query"    yaml:"query"`
+	Database string `json:"database"  yaml:"database"`
+	Query    string `json:"query"     yaml:"query"`
+	ReadOnly bool   `json:"read_only" yaml:"read_only"`
+	Local    bool   `json:"local"     yaml:"local"`
+	Timeout  int    `json:"timeout"   yaml:"timeout"`
 }
 
 type internalSQLBatch struct {
@@ -423,21 +444,43 @@ func internalSQLGet(d *Daemon, r *http.Request) response.Response {
 		schemaOnly = 0
 	}
 
+	timeout, err := strconv.Atoi(r.FormValue("timeout"))
+	if err != nil {
+		timeout = 0
+	}
+
+	local := shared.IsTrue(r.FormValue("local"))
+	if local && database != "global" {
+		return response.BadRequest(fmt.Errorf("Only the global database can be read from the local replica"))
+	}
+
+	ctx, cancel := internalSQLContext(r.Context(), timeout)
+	defer cancel()
+
 	var db *sql.DB
-	if database == "global" {
+	if local {
+		replica, cleanup, err := internalSQLLocalReplica(ctx, d)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		defer cleanup()
+
+		db = replica
+	} else if database == "global" {
 		db = s.DB.Cluster.DB()
 	} else {
 		db = s.DB.Node.DB()
 	}
 
-	tx, err := db.BeginTx(r.Context(), nil)
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return response.SmartError(fmt.Errorf("Failed to start transaction: %w", err))
 	}
 
 	defer func() { _ = tx.Rollback() }()
 
-	dump, err := query.Dump(r.Context(), tx, schemaOnly == 1)
+	dump, err := query.Dump(ctx, tx, schemaOnly == 1)
 	if err != nil {
 		return response.SmartError(fmt.Errorf("Failed dump database %s: %w", database, err))
 	}
@@ -464,20 +507,29 @@ func internalSQLPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("No query provided"))
 	}
 
-	var db *sql.DB
-	if req.Database == "global" {
-		db = s.DB.Cluster.DB()
-	} else {
-		db = s.DB.Node.DB()
+	if req.Timeout < 0 {
+		return response.BadRequest(fmt.Errorf("Invalid timeout"))
+	}
+
+	if req.Local && req.Database != "global" {
+		return response.BadRequest(fmt.Errorf("Only the global database can be read from the local replica"))
 	}
 
+	// The local replica is a copy of the global database, so it is only ever read.
+	readOnly := req.ReadOnly || req.Local
+
 	batch := internalSQLBatch{}
 
 	if req.Query == ".sync" {
+		if readOnly {
+			return response.BadRequest(fmt.Errorf("The .sync command isn't allowed in read-only mode"))
+		}
+
 		d.gateway.Sync()
 		return response.SyncResponse(true, batch)
 	}
 
+	queries := []string{}
 	for _, query := range strings.Split(req.Query, ";") {
 		query = strings.TrimLeft(query, " ")
 
@@ -485,18 +537,46 @@ func internalSQLPost(d *Daemon, r *http.Request) response.Response {
 			continue
 		}
 
+		// Check all the queries upfront so that none of them is run if the batch isn't read-only.
+		if readOnly && !strings.HasPrefix(strings.ToUpper(query), "SELECT") {
+			return response.BadRequest(fmt.Errorf("Only SELECT queries are allowed in read-only mode"))
+		}
+
+		queries = append(queries, query)
+	}
+
+	ctx, cancel := internalSQLContext(r.Context(), req.Timeout)
+	defer cancel()
+
+	var db *sql.DB
+	if req.Local {
+		replica, cleanup, err := internalSQLLocalReplica(ctx, d)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		defer cleanup()
+
+		db = replica
+	} else if req.Database == "global" {
+		db = s.DB.Cluster.DB()
+	} else {
+		db = s.DB.Node.DB()
+	}
+
+	for _, query := range queries {
 		result := internalSQLResult{}
 
-		tx, err := db.Begin()
+		tx, err := db.BeginTx(ctx, nil)
 		if err != nil {
 			return response.SmartError(err)
 		}
 
 		if strings.HasPrefix(strings.ToUpper(query), "SELECT") {
-			err = internalSQLSelect(tx, query, &result)
+			err = internalSQLSelect(ctx, tx, query, &result)
 			_ = tx.Rollback()
 		} else {
-			err = internalSQLExec(tx, query, &result)
+			err = internalSQLExec(ctx, tx, query, &result)
 			if err != nil {
 				_ = tx.Rollback()
 			} else {
@@ -504,6 +584,10 @@ func internalSQLPost(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return response.SmartError(fmt.Errorf("Query timed out after %ds: %w", req.Timeout, err))
+			}
+
 			return response.SmartError(err)
 		}
 
@@ -513,10 +597,48 @@ func internalSQLPost(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, batch)
 }
 
-func internalSQLSelect(tx *sql.Tx, query string, result *internalSQLResult) error {
+// internalSQLLocalReplica opens a read-only copy of the global database as held by the local dqlite node, which
+// doesn't require the cluster to be available. The returned function closes the copy and removes it.
+func internalSQLLocalReplica(ctx context.Context, d *Daemon) (*sql.DB, func(), error) {
+	dir, err := os.MkdirTemp(shared.VarPath("database"), "sql_local_")
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create temporary directory: %w", err)
+	}
+
+	path, err := d.gateway.DumpLocal(ctx, dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	replica, err := sql.Open("sqlite3", "file:"+path+"?_query_only=1")
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, nil, fmt.Errorf("Failed to open local database replica: %w", err)
+	}
+
+	cleanup := func() {
+		_ = replica.Close()
+		_ = os.RemoveAll(dir)
+	}
+
+	return replica, cleanup, nil
+}
+
+// internalSQLContext returns the context to run the queries with, which is canceled after the given number of
+// seconds if greater than zero.
+func internalSQLContext(ctx context.Context, timeout int) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+}
+
+func internalSQLSelect(ctx context.Context, tx *sql.Tx, query string, result *internalSQLResult) error {
 	result.Type = "select"
 
-	rows, err := tx.Query(query)
+	rows, err := tx.QueryContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("Failed to execute query: %w", err)
 	}
@@ -560,9 +682,9 @@ func internalSQLSelect(tx *sql.Tx, query string, result *internalSQLResult) erro
 	return nil
 }
 
-func internalSQLExec(tx *sql.Tx, query string, result *internalSQLResult) error {
+func internalSQLExec(ctx context.Context, tx *sql.Tx, query string, result *internalSQLResult) error {
 	result.Type = "exec"
-	r, err := tx.Exec(query)
+	r, err := tx.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("Failed to exec query: %w", err)
 	}
@@ -1042,3 +1164,59 @@ func internalIdentityCacheRefresh(d *Daemon, r *http.Request) response.Response
 	d.State().UpdateIdentityCache()
 	return response.EmptySyncResponse
 }
+
+// internalNetworkLoadBalancerBGPRefresh refreshes the BGP prefixes exported for the load balancers of a network
+// on the local member. Other members call it after the backends of the load balancers have started or stopped.
+func internalNetworkLoadBalancerBGPRefresh(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	err = n.LoadBalancerBGPRefresh()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// internalNetworkMembersRefresh refreshes the local state of a network that depends on the other cluster members.
+// Other members call it after publishing member specific state, such as the public key of a wireguard network.
+func internalNetworkMembersRefresh(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	err = n.MembersRefresh()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
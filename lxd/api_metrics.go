@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
@@ -349,6 +350,10 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 }
 
 func getFilteredMetrics(s *state.State, r *http.Request, compress bool, metricSet *metrics.MetricSet) response.Response {
+	// API request metrics are always up to date so are not cached.
+	metricSet.Merge(metrics.APIRequestMetrics(request.QueryParam(r, "project")))
+	metricSet.Merge(metrics.RejectedOperationMetrics(request.QueryParam(r, "project")))
+
 	// Ignore filtering in case the authentication for metrics is disabled.
 	if !s.GlobalConfig.MetricsAuthentication() {
 		return response.SyncResponsePlain(true, compress, metricSet.String())
@@ -432,3 +437,72 @@ func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.Clus
 
 	return out
 }
+
+// metricsResponseWriter records the status code of the response for the API request metrics.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+
+	code int
+}
+
+// WriteHeader records the status code and writes it to the underlying response writer.
+func (w *metricsResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records an implicit http.StatusOK if no status code was written yet.
+func (w *metricsResponseWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying response writer if supported.
+func (w *metricsResponseWriter) Flush() {
+	f, ok := w.ResponseWriter.(http.Flusher)
+	if ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the underlying connection, which is recorded as http.StatusSwitchingProtocols.
+func (w *metricsResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("http.ResponseWriter is not type http.Hijacker")
+	}
+
+	if w.code == 0 {
+		w.code = http.StatusSwitchingProtocols
+	}
+
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying response writer.
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// apiRequestIdentityType returns the identity type of the caller used in the API request metrics.
+// Callers that aren't backed by an identity are reported with their authentication protocol.
+func (d *Daemon) apiRequestIdentityType(trusted bool, username string, protocol string) string {
+	if !trusted {
+		return "untrusted"
+	}
+
+	if shared.ValueInSlice(protocol, []string{api.AuthenticationMethodTLS, api.AuthenticationMethodOIDC, api.AuthenticationMethodBearer}) {
+		id, err := d.identityCache.Get(protocol, username)
+		if err == nil {
+			return id.IdentityType
+		}
+	}
+
+	return protocol
+}
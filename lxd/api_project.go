@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,9 +11,11 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 
+	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
@@ -288,6 +291,10 @@ func projectsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Check whether a default network should be created for the project.
+	uplink, _, _ := s.GlobalConfig.NetworkProjectDefault()
+	createNetwork := uplink != "" && shared.IsTrue(project.Config["features.networks"])
+
 	var id int64
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		id, err = cluster.CreateProject(ctx, tx.Tx(), cluster.Project{Description: project.Description, Name: project.Name})
@@ -306,6 +313,13 @@ func projectsPost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
+			if createNetwork {
+				err = projectAddDefaultNetworkNIC(ctx, tx, project.Name)
+				if err != nil {
+					return err
+				}
+			}
+
 			if project.Config["features.images"] == "false" {
 				err = cluster.InitProjectWithoutImages(ctx, tx.Tx(), project.Name)
 				if err != nil {
@@ -320,6 +334,18 @@ func projectsPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Failed creating project %q: %w", project.Name, err))
 	}
 
+	if createNetwork {
+		err = projectCreateDefaultNetwork(d, project.Name)
+		if err != nil {
+			// Remove the project so that its creation can be retried.
+			_ = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				return cluster.DeleteProject(ctx, tx.Tx(), project.Name)
+			})
+
+			return response.SmartError(fmt.Errorf("Failed creating project %q: %w", project.Name, err))
+		}
+	}
+
 	requestor := request.CreateRequestor(r)
 	lc := lifecycle.ProjectCreated.Event(project.Name, requestor, nil)
 	s.Events.SendLifecycle(project.Name, lc)
@@ -343,6 +369,154 @@ func projectCreateDefaultProfile(tx *db.ClusterTx, project string) error {
 	return nil
 }
 
+// projectDefaultNetworkName is the name of the network created for new projects when
+// network.project_default.uplink is set.
+const projectDefaultNetworkName = "default"
+
+// projectDefaultNetworkLock prevents concurrent project creations from being given the same subnet.
+var projectDefaultNetworkLock sync.Mutex
+
+// Add a NIC connected to the default network of a project to the default profile of the project.
+func projectAddDefaultNetworkNIC(ctx context.Context, tx *db.ClusterTx, projectName string) error {
+	profile, err := cluster.GetProfile(ctx, tx.Tx(), projectName, api.ProjectDefaultName)
+	if err != nil {
+		return err
+	}
+
+	devices, err := cluster.APIToDevices(map[string]map[string]string{
+		"eth0": {
+			"type":    "nic",
+			"name":    "eth0",
+			"network": projectDefaultNetworkName,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cluster.CreateProfileDevices(ctx, tx.Tx(), int64(profile.ID), devices)
+	if err != nil {
+		return fmt.Errorf("Add default network to default profile: %w", err)
+	}
+
+	return nil
+}
+
+// Create the default network of a project, using the uplink network and IPv4 range from the server configuration.
+func projectCreateDefaultNetwork(d *Daemon, projectName string) error {
+	s := d.State()
+
+	projectDefaultNetworkLock.Lock()
+	defer projectDefaultNetworkLock.Unlock()
+
+	uplink, ipv4Pool, ipv4Prefix := s.GlobalConfig.NetworkProjectDefault()
+
+	req := api.NetworksPost{
+		Name: projectDefaultNetworkName,
+		Type: "ovn",
+		NetworkPut: api.NetworkPut{
+			Description: fmt.Sprintf("Default network for project %s", projectName),
+			Config: map[string]string{
+				"network": uplink,
+			},
+		},
+	}
+
+	if ipv4Pool != "" {
+		var subnet *net.IPNet
+		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+			subnet, err = projectDefaultNetworkSubnet(ctx, tx, ipv4Pool, int(ipv4Prefix))
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		// Use the first address of the subnet as the gateway.
+		gateway := make(net.IP, len(subnet.IP))
+		copy(gateway, subnet.IP)
+		gateway[len(gateway)-1]++
+
+		ones, _ := subnet.Mask.Size()
+		req.Config["ipv4.address"] = fmt.Sprintf("%s/%d", gateway.String(), ones)
+		req.Config["ipv4.nat"] = "true"
+	}
+
+	// Create the network through the API, which takes care of creating it on all cluster members.
+	client, err := lxd.ConnectLXDUnix(d.UnixSocket(), nil)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to local LXD: %w", err)
+	}
+
+	err = client.UseProject(projectName).CreateNetwork(req)
+	if err != nil {
+		return fmt.Errorf("Failed creating default network: %w", err)
+	}
+
+	return nil
+}
+
+// projectDefaultNetworkSubnet returns the first subnet of the given prefix length in the IPv4 range that doesn't
+// overlap with the subnets of any existing network.
+func projectDefaultNetworkSubnet(ctx context.Context, tx *db.ClusterTx, ipv4Pool string, prefix int) (*net.IPNet, error) {
+	_, pool, err := net.ParseCIDR(ipv4Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	poolOnes, _ := pool.Mask.Size()
+	if prefix < poolOnes {
+		return nil, fmt.Errorf("Prefix length %d is shorter than the one of the IPv4 range %q", prefix, ipv4Pool)
+	}
+
+	networks, err := tx.GetCreatedNetworks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading networks: %w", err)
+	}
+
+	usedSubnets := []*net.IPNet{}
+	for _, projectNetworks := range networks {
+		for _, n := range projectNetworks {
+			for _, key := range []string{"ipv4.address", "ipv4.gateway", "ipv4.routes"} {
+				for _, value := range shared.SplitNTrimSpace(n.Config[key], ",", -1, true) {
+					_, subnet, err := net.ParseCIDR(value)
+					if err != nil {
+						continue // Skip values that aren't subnets, such as "none".
+					}
+
+					usedSubnets = append(usedSubnets, subnet)
+				}
+			}
+		}
+	}
+
+	base := binary.BigEndian.Uint32(pool.IP.To4())
+	count := uint64(1) << (prefix - poolOnes)
+	size := uint64(1) << (32 - prefix)
+	for i := uint64(0); i < count; i++ {
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, base+uint32(i*size))
+
+		candidate := &net.IPNet{IP: ip, Mask: net.CIDRMask(prefix, 32)}
+
+		overlaps := false
+		for _, subnet := range usedSubnets {
+			if network.SubnetContains(subnet, candidate) || network.SubnetContains(candidate, subnet) {
+				overlaps = true
+				break
+			}
+		}
+
+		if !overlaps {
+			return candidate, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No free subnet left in IPv4 range %q", ipv4Pool)
+}
+
 // swagger:operation GET /1.0/projects/{name} projects project_get
 //
 //	Get the project
@@ -813,7 +987,7 @@ func projectPost(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ProjectRename, nil, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -1102,6 +1276,13 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: integer
 		//  shortdesc: When an unused cached remote image is flushed in the project
 		"images.remote_cache_expiry": validate.Optional(validate.IsInt64),
+		// lxdmeta:generate(entities=project; group=specific; key=images.remote_cache_keep_referenced)
+		// When enabled, unused cached images that instances in the project were created from don't expire.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to keep cached images that instances were created from
+		"images.remote_cache_keep_referenced": validate.Optional(validate.IsBool),
 		// lxdmeta:generate(entities=project; group=limits; key=limits.instances)
 		//
 		// ---
@@ -1150,6 +1331,20 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: integer
 		//  shortdesc: Maximum number of networks that the project can have
 		"limits.networks": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.operations)
+		// This value is the maximum number of operations of the project that can be pending or running at the same time on a cluster member.
+		// Further API requests that would create an operation are rejected.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of concurrent operations in the project
+		"limits.operations": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.instances.creation_rate)
+		// This value is the maximum number of instances that can be created in the project on a cluster member within an hour.
+		// Further instance creation requests are rejected until the oldest creation is more than an hour old.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of instance creations per hour in the project
+		"limits.instances.creation_rate": validate.Optional(validate.IsUint32),
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted)
 		// This option must be enabled to allow the `restricted.*` keys to take effect.
 		// To temporarily remove the restrictions, you can disable this option instead of clearing the related keys.
@@ -1279,12 +1474,21 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  defaultdesc: `block`
 		//  shortdesc: Whether to prevent using devices of type `proxy`
 		"restricted.devices.proxy": isEitherAllowOrBlock,
+		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.shmem)
+		// Possible values are `allow` or `block`.
+		// ---
+		//  type: string
+		//  defaultdesc: `block`
+		//  shortdesc: Whether to prevent using devices of type `shmem`
+		"restricted.devices.shmem": isEitherAllowOrBlock,
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.nic)
 		// Possible values are `allow`, `block`, or `managed`.
 		//
 		// - When set to `block`, this option prevents using all network devices.
 		// - When set to `managed`, this option allows using network devices only if `network=` is set.
 		// - When set to `allow`, there is no restriction on which network devices can be used.
+		//
+		// Regardless of the value, NICs can only mirror their traffic to NICs of other instances in the project.
 		// ---
 		//  type: string
 		//  defaultdesc: `managed`
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// checkAPIRequestLimits takes a token from the rate limiters of the identity and project of the request.
+// If either limit is exceeded, it returns an error along with the duration after which the request can be retried.
+// The limits apply to each cluster member separately.
+func (d *Daemon) checkAPIRequestLimits(r *http.Request, username string, protocol string) (time.Duration, error) {
+	ok, retryAfter := d.apiIdentityLimiter.Allow(protocol + "/" + username)
+	if !ok {
+		return retryAfter, api.StatusErrorf(http.StatusTooManyRequests, "Too many API requests for identity %q", username)
+	}
+
+	if !d.apiProjectLimiter.Enabled() || shared.IsTrue(request.QueryParam(r, "all-projects")) {
+		return 0, nil
+	}
+
+	// Only take a token from the project's bucket if the caller can access the project, otherwise any caller
+	// could use up the budget of another project by targeting it. Requests for projects the caller can't
+	// access are rejected or filtered by the handlers.
+	projectName := request.ProjectParam(r)
+	err := d.authorizer.CheckPermission(r.Context(), r, entity.ProjectURL(projectName), auth.EntitlementCanView)
+	if err != nil {
+		return 0, nil
+	}
+
+	ok, retryAfter = d.apiProjectLimiter.Allow(projectName)
+	if !ok {
+		return retryAfter, api.StatusErrorf(http.StatusTooManyRequests, "Too many API requests for project %q", projectName)
+	}
+
+	return 0, nil
+}
+
+// setRetryAfterHeader sets the Retry-After header to the given duration, rounded up to the next second.
+func setRetryAfterHeader(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
+// configureAPIRequestLimits applies the API request limits from the server configuration.
+func (d *Daemon) configureAPIRequestLimits(projectLimit int64, identityLimit int64, burst int64) {
+	d.apiProjectLimiter.Configure(projectLimit, burst)
+	d.apiIdentityLimiter.Configure(identityLimit, burst)
+
+	if projectLimit > 0 || identityLimit > 0 {
+		logger.Info("Applied API request limits", logger.Ctx{"project": projectLimit, "identity": identityLimit, "burst": burst})
+	}
+}
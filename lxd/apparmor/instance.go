@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/canonical/lxd/lxd/cgroup"
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/sys"
@@ -20,6 +21,7 @@ type instance interface {
 	Project() api.Project
 	Name() string
 	ExpandedConfig() map[string]string
+	ExpandedDevices() deviceConfig.Devices
 	Type() instancetype.Type
 	LogPath() string
 	Path() string
@@ -193,6 +195,14 @@ func instanceProfile(sysOS *sys.OS, inst instance) (string, error) {
 			execPath = execPathFull
 		}
 
+		// Named shared memory regions live outside of the instance paths.
+		shmemPaths := []string{}
+		for _, dev := range inst.ExpandedDevices() {
+			if dev["type"] == "shmem" && dev["backend"] != "vhost-user" && dev["name"] != "" {
+				shmemPaths = append(shmemPaths, deviceConfig.ShmemPath(inst.Project().Name, dev["name"]))
+			}
+		}
+
 		err = qemuProfileTpl.Execute(sb, map[string]any{
 			"devicesPath": inst.DevicesPath(),
 			"exePath":     execPath,
@@ -205,6 +215,7 @@ func instanceProfile(sysOS *sys.OS, inst instance) (string, error) {
 			"snap":        shared.InSnap(),
 			"userns":      sysOS.RunningInUserNS,
 			"qemuFwPaths": qemuFwPathsArr,
+			"shmemPaths":  shmemPaths,
 		})
 		if err != nil {
 			return "", err
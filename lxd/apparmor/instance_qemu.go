@@ -92,6 +92,13 @@ profile "{{ .name }}" flags=(attach_disconnected,mediate_deleted) {
 {{- end }}
 {{- end }}
 
+{{if .shmemPaths -}}
+  # Named shared memory regions
+{{range $index, $element := .shmemPaths}}
+  {{$element}} rwk,
+{{- end }}
+{{- end }}
+
 {{if .qemuFwPaths -}}
   # Entries from LXD_OVMF_PATH or LXD_QEMU_FW_PATH
 {{range $index, $element := .qemuFwPaths}}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"os"
+	"sync"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+const (
+	// SinkFile records the audit log to the audit.log file in the LXD log directory.
+	SinkFile = "file"
+
+	// SinkSyslog records the audit log to the local syslog daemon.
+	SinkSyslog = "syslog"
+
+	// SinkAPI records the audit log to the cluster database, from which it is available through the API.
+	SinkAPI = "api"
+)
+
+// queueSize is the number of entries that can be waiting to be written before new entries are dropped.
+const queueSize = 1024
+
+// Logger records audit log entries to the configured sinks.
+type Logger struct {
+	mu     sync.Mutex
+	sinks  []string
+	file   *os.File
+	syslog *syslog.Writer
+
+	store   func(ctx context.Context, entry api.AuditEntry) error
+	entries chan api.AuditEntry
+}
+
+// NewLogger returns a Logger without any sink configured. The store function is used to save entries for the API
+// sink. Entries are written in the background until the given context is cancelled.
+func NewLogger(ctx context.Context, store func(ctx context.Context, entry api.AuditEntry) error) *Logger {
+	l := &Logger{
+		store:   store,
+		entries: make(chan api.AuditEntry, queueSize),
+	}
+
+	go l.run(ctx)
+
+	return l
+}
+
+// Configure sets the sinks that entries are recorded to, opening or closing the file and syslog sinks as needed.
+func (l *Logger) Configure(sinks []string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if shared.ValueInSlice(SinkFile, sinks) && l.file == nil {
+		f, err := os.OpenFile(shared.LogPath("audit.log"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("Failed opening audit log file: %w", err)
+		}
+
+		l.file = f
+	} else if !shared.ValueInSlice(SinkFile, sinks) && l.file != nil {
+		_ = l.file.Close()
+		l.file = nil
+	}
+
+	if shared.ValueInSlice(SinkSyslog, sinks) && l.syslog == nil {
+		w, err := syslog.New(syslog.LOG_NOTICE|syslog.LOG_AUTH, "lxd-audit")
+		if err != nil {
+			return fmt.Errorf("Failed connecting to syslog: %w", err)
+		}
+
+		l.syslog = w
+	} else if !shared.ValueInSlice(SinkSyslog, sinks) && l.syslog != nil {
+		_ = l.syslog.Close()
+		l.syslog = nil
+	}
+
+	l.sinks = sinks
+
+	return nil
+}
+
+// Enabled returns whether any sink is configured.
+func (l *Logger) Enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.sinks) > 0
+}
+
+// Record queues an entry to be written to the configured sinks. Recording never blocks the caller, so entries are
+// dropped (and a warning is logged) if the sinks can't keep up.
+func (l *Logger) Record(entry api.AuditEntry) {
+	if !l.Enabled() {
+		return
+	}
+
+	select {
+	case l.entries <- entry:
+	default:
+		logger.Warn("Dropping audit log entry, too many pending entries", logger.Ctx{"method": entry.Method, "url": entry.EntityURL, "identity": entry.Identity})
+	}
+}
+
+// run writes the queued entries until the context is cancelled.
+func (l *Logger) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			l.close()
+			return
+		case entry := <-l.entries:
+			l.write(ctx, entry)
+		}
+	}
+}
+
+// write records an entry to each configured sink.
+func (l *Logger) write(ctx context.Context, entry api.AuditEntry) {
+	l.mu.Lock()
+	if l.file != nil || l.syslog != nil {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			logger.Error("Failed encoding audit log entry", logger.Ctx{"err": err})
+		} else {
+			if l.file != nil {
+				_, err = l.file.Write(append(line, '\n'))
+				if err != nil {
+					logger.Error("Failed writing audit log entry to file", logger.Ctx{"err": err})
+				}
+			}
+
+			if l.syslog != nil {
+				err = l.syslog.Notice(string(line))
+				if err != nil {
+					logger.Error("Failed writing audit log entry to syslog", logger.Ctx{"err": err})
+				}
+			}
+		}
+	}
+
+	storeEntry := shared.ValueInSlice(SinkAPI, l.sinks) && l.store != nil
+	l.mu.Unlock()
+
+	// Don't hold the lock while waiting for the database so that requests can still be recorded.
+	if storeEntry {
+		err := l.store(ctx, entry)
+		if err != nil {
+			logger.Error("Failed storing audit log entry", logger.Ctx{"err": err})
+		}
+	}
+}
+
+// close releases the file and syslog sinks.
+func (l *Logger) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		_ = l.file.Close()
+		l.file = nil
+	}
+
+	if l.syslog != nil {
+		_ = l.syslog.Close()
+		l.syslog = nil
+	}
+
+	l.sinks = nil
+}
@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/identity"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
 )
 
@@ -40,6 +43,8 @@ type requestDetails struct {
 	isPKI                bool
 	idpGroups            []string
 	forwardedIDPGroups   []string
+	ruleGroups           []string
+	forwardedRuleGroups  []string
 }
 
 func (r *requestDetails) isInternalOrUnix() bool {
@@ -70,6 +75,16 @@ func (r *requestDetails) authenticationProtocol() string {
 	return r.protocol
 }
 
+// isDevLXDToken returns true if the request was authenticated with an instance scoped token issued over devlxd.
+func (r *requestDetails) isDevLXDToken() bool {
+	return r.authenticationProtocol() == api.AuthenticationMethodDevLXD
+}
+
+// isInstanceAccessToken returns true if the request was authenticated with a temporary instance access token.
+func (r *requestDetails) isInstanceAccessToken() bool {
+	return r.authenticationProtocol() == api.AuthenticationMethodInstanceAccess
+}
+
 func (r *requestDetails) identityProviderGroups() []string {
 	if r.protocol == "cluster" {
 		return r.forwardedIDPGroups
@@ -78,6 +93,15 @@ func (r *requestDetails) identityProviderGroups() []string {
 	return r.idpGroups
 }
 
+// identityProviderGroupRuleGroups returns the LXD groups resolved from identity provider group rules.
+func (r *requestDetails) identityProviderGroupRuleGroups() []string {
+	if r.protocol == "cluster" {
+		return r.forwardedRuleGroups
+	}
+
+	return r.ruleGroups
+}
+
 func (c *commonAuthorizer) requestDetails(r *http.Request) (*requestDetails, error) {
 	if r == nil {
 		return nil, api.StatusErrorf(http.StatusInternalServerError, "Cannot inspect nil request")
@@ -124,6 +148,10 @@ func (c *commonAuthorizer) requestDetails(r *http.Request) (*requestDetails, err
 	d.idpGroups, _ = request.GetCtxValue[[]string](r.Context(), request.CtxIdentityProviderGroups)
 	d.forwardedIDPGroups, _ = request.GetCtxValue[[]string](r.Context(), request.CtxForwardedIdentityProviderGroups)
 
+	// Check for groups resolved from identity provider group rules.
+	d.ruleGroups, _ = request.GetCtxValue[[]string](r.Context(), request.CtxIdentityProviderGroupRuleGroups)
+	d.forwardedRuleGroups, _ = request.GetCtxValue[[]string](r.Context(), request.CtxForwardedIdentityProviderGroupRuleGroups)
+
 	// Check if the request is for all projects.
 	values, err := url.ParseQuery(r.URL.RawQuery)
 	if err != nil {
@@ -136,7 +164,129 @@ func (c *commonAuthorizer) requestDetails(r *http.Request) (*requestDetails, err
 	return d, nil
 }
 
+// checkDevLXDTokenPermission returns an error if a request authenticated with an instance scoped token does not have
+// the given entitlement on the entity. These tokens only grant read-only access to the server and to the instance
+// they were issued for.
+func (c *commonAuthorizer) checkDevLXDTokenPermission(details *requestDetails, entityURL *api.URL, entitlement auth.Entitlement) error {
+	if details.isAllProjectsRequest {
+		return api.StatusErrorf(http.StatusForbidden, "Token is restricted")
+	}
+
+	projectName, instanceName, err := identity.DevLXDTokenUsername(details.username())
+	if err != nil {
+		return err
+	}
+
+	if !devLXDTokenAllows(projectName, instanceName, entityURL, entitlement) {
+		return api.StatusErrorf(http.StatusForbidden, "Token is restricted to viewing instance %q in project %q", instanceName, projectName)
+	}
+
+	return nil
+}
+
+// devLXDTokenPermissionChecker returns a PermissionChecker for a request authenticated with an instance scoped token.
+func (c *commonAuthorizer) devLXDTokenPermissionChecker(details *requestDetails, entitlement auth.Entitlement) (auth.PermissionChecker, error) {
+	if details.isAllProjectsRequest {
+		return nil, api.StatusErrorf(http.StatusForbidden, "Token is restricted")
+	}
+
+	projectName, instanceName, err := identity.DevLXDTokenUsername(details.username())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(entityURL *api.URL) bool {
+		return devLXDTokenAllows(projectName, instanceName, entityURL, entitlement)
+	}, nil
+}
+
+// devLXDTokenAllows returns true if a token issued for the given instance grants the entitlement on the entity.
+func devLXDTokenAllows(projectName string, instanceName string, entityURL *api.URL, entitlement auth.Entitlement) bool {
+	if entitlement != auth.EntitlementCanView {
+		return false
+	}
+
+	entityType, entityProject, _, pathArgs, err := entity.ParseURL(entityURL.URL)
+	if err != nil {
+		return false
+	}
+
+	switch entityType {
+	case entity.TypeServer:
+		return true
+	case entity.TypeInstance:
+		return entityProject == projectName && len(pathArgs) > 0 && pathArgs[0] == instanceName
+	}
+
+	return false
+}
+
 // Driver returns the driver name.
 func (c *commonAuthorizer) Driver() string {
 	return c.driverName
 }
+
+// checkInstanceAccessTokenPermission returns an error if a request authenticated with an instance access token does
+// not have the given entitlement on the entity.
+func (c *commonAuthorizer) checkInstanceAccessTokenPermission(details *requestDetails, entityURL *api.URL, entitlement auth.Entitlement) error {
+	if details.isAllProjectsRequest {
+		return api.StatusErrorf(http.StatusForbidden, "Token is restricted")
+	}
+
+	claims, err := identity.InstanceAccessTokenUsername(details.username())
+	if err != nil {
+		return err
+	}
+
+	if !instanceAccessTokenAllows(claims, entityURL, entitlement) {
+		return api.StatusErrorf(http.StatusForbidden, "Token is restricted to %s access to instance %q in project %q", claims.Access, claims.Instance, claims.Project)
+	}
+
+	return nil
+}
+
+// instanceAccessTokenPermissionChecker returns a PermissionChecker for a request authenticated with an instance access
+// token.
+func (c *commonAuthorizer) instanceAccessTokenPermissionChecker(details *requestDetails, entitlement auth.Entitlement) (auth.PermissionChecker, error) {
+	if details.isAllProjectsRequest {
+		return nil, api.StatusErrorf(http.StatusForbidden, "Token is restricted")
+	}
+
+	claims, err := identity.InstanceAccessTokenUsername(details.username())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(entityURL *api.URL) bool {
+		return instanceAccessTokenAllows(claims, entityURL, entitlement)
+	}, nil
+}
+
+// instanceAccessTokenAllows returns true if an instance access token grants the entitlement on the entity.
+// Besides viewing the server and the instance, the token only grants the exec or console entitlement it was issued for.
+func instanceAccessTokenAllows(claims *identity.InstanceAccessTokenClaims, entityURL *api.URL, entitlement auth.Entitlement) bool {
+	entityType, entityProject, _, pathArgs, err := entity.ParseURL(entityURL.URL)
+	if err != nil {
+		return false
+	}
+
+	switch entityType {
+	case entity.TypeServer:
+		return entitlement == auth.EntitlementCanView
+	case entity.TypeInstance:
+		if entityProject != claims.Project || len(pathArgs) == 0 || pathArgs[0] != claims.Instance {
+			return false
+		}
+
+		switch entitlement {
+		case auth.EntitlementCanView:
+			return true
+		case auth.EntitlementCanExec:
+			return claims.Access == api.InstanceAccessExec
+		case auth.EntitlementCanAccessConsole:
+			return claims.Access == api.InstanceAccessConsole
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/identity"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+func TestDevLXDTokenAllows(t *testing.T) {
+	tests := []struct {
+		name        string
+		entityURL   *api.URL
+		entitlement auth.Entitlement
+		allowed     bool
+	}{
+		{
+			name:        "View server",
+			entityURL:   entity.ServerURL(),
+			entitlement: auth.EntitlementCanView,
+			allowed:     true,
+		},
+		{
+			name:        "Edit server",
+			entityURL:   entity.ServerURL(),
+			entitlement: auth.EntitlementCanEdit,
+			allowed:     false,
+		},
+		{
+			name:        "View own instance",
+			entityURL:   entity.InstanceURL("default", "c1"),
+			entitlement: auth.EntitlementCanView,
+			allowed:     true,
+		},
+		{
+			name:        "Exec in own instance",
+			entityURL:   entity.InstanceURL("default", "c1"),
+			entitlement: auth.EntitlementCanExec,
+			allowed:     false,
+		},
+		{
+			name:        "Edit own instance",
+			entityURL:   entity.InstanceURL("default", "c1"),
+			entitlement: auth.EntitlementCanEdit,
+			allowed:     false,
+		},
+		{
+			name:        "View other instance",
+			entityURL:   entity.InstanceURL("default", "c2"),
+			entitlement: auth.EntitlementCanView,
+			allowed:     false,
+		},
+		{
+			name:        "View same instance name in other project",
+			entityURL:   entity.InstanceURL("other", "c1"),
+			entitlement: auth.EntitlementCanView,
+			allowed:     false,
+		},
+		{
+			name:        "View project",
+			entityURL:   entity.ProjectURL("default"),
+			entitlement: auth.EntitlementCanView,
+			allowed:     false,
+		},
+		{
+			name:        "View image",
+			entityURL:   entity.ImageURL("default", "abcdef"),
+			entitlement: auth.EntitlementCanView,
+			allowed:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed := devLXDTokenAllows("default", "c1", tt.entityURL, tt.entitlement)
+			if allowed != tt.allowed {
+				t.Fatalf("Expected %v, got %v", tt.allowed, allowed)
+			}
+		})
+	}
+}
+
+func TestInstanceAccessTokenAllows(t *testing.T) {
+	exec := &identity.InstanceAccessTokenClaims{Project: "default", Instance: "c1", Access: api.InstanceAccessExec}
+	console := &identity.InstanceAccessTokenClaims{Project: "default", Instance: "c1", Access: api.InstanceAccessConsole}
+	consoleReadOnly := &identity.InstanceAccessTokenClaims{Project: "default", Instance: "c1", Access: api.InstanceAccessConsole, ReadOnly: true}
+
+	ownInstance := entity.InstanceURL("default", "c1")
+
+	tests := []struct {
+		name        string
+		claims      *identity.InstanceAccessTokenClaims
+		entityURL   *api.URL
+		entitlement auth.Entitlement
+		allowed     bool
+	}{
+		{name: "Exec token views server", claims: exec, entityURL: entity.ServerURL(), entitlement: auth.EntitlementCanView, allowed: true},
+		{name: "Exec token edits server", claims: exec, entityURL: entity.ServerURL(), entitlement: auth.EntitlementCanEdit},
+		{name: "Exec token views instance", claims: exec, entityURL: ownInstance, entitlement: auth.EntitlementCanView, allowed: true},
+		{name: "Exec token execs in instance", claims: exec, entityURL: ownInstance, entitlement: auth.EntitlementCanExec, allowed: true},
+		{name: "Exec token accesses console", claims: exec, entityURL: ownInstance, entitlement: auth.EntitlementCanAccessConsole},
+		{name: "Exec token edits instance", claims: exec, entityURL: ownInstance, entitlement: auth.EntitlementCanEdit},
+		{name: "Exec token manages files", claims: exec, entityURL: ownInstance, entitlement: auth.EntitlementCanAccessFiles},
+		{name: "Exec token deletes instance", claims: exec, entityURL: ownInstance, entitlement: auth.EntitlementCanDelete},
+		{name: "Exec token execs in other instance", claims: exec, entityURL: entity.InstanceURL("default", "c2"), entitlement: auth.EntitlementCanExec},
+		{name: "Exec token execs in other project", claims: exec, entityURL: entity.InstanceURL("other", "c1"), entitlement: auth.EntitlementCanExec},
+		{name: "Exec token views project", claims: exec, entityURL: entity.ProjectURL("default"), entitlement: auth.EntitlementCanView},
+		{name: "Console token accesses console", claims: console, entityURL: ownInstance, entitlement: auth.EntitlementCanAccessConsole, allowed: true},
+		{name: "Console token execs in instance", claims: console, entityURL: ownInstance, entitlement: auth.EntitlementCanExec},
+		{name: "Console token updates state", claims: console, entityURL: ownInstance, entitlement: auth.EntitlementCanUpdateState},
+		{name: "Console token accesses other console", claims: console, entityURL: entity.InstanceURL("default", "c2"), entitlement: auth.EntitlementCanAccessConsole},
+		{name: "Read-only console token accesses console", claims: consoleReadOnly, entityURL: ownInstance, entitlement: auth.EntitlementCanAccessConsole, allowed: true},
+		{name: "Read-only console token execs in instance", claims: consoleReadOnly, entityURL: ownInstance, entitlement: auth.EntitlementCanExec},
+		{name: "Read-only console token edits instance", claims: consoleReadOnly, entityURL: ownInstance, entitlement: auth.EntitlementCanEdit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed := instanceAccessTokenAllows(tt.claims, tt.entityURL, tt.entitlement)
+			if allowed != tt.allowed {
+				t.Fatalf("Expected %v, got %v", tt.allowed, allowed)
+			}
+		})
+	}
+}
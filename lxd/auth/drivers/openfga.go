@@ -140,6 +140,16 @@ func (e *embeddedOpenFGA) CheckPermission(ctx context.Context, r *http.Request,
 		return e.tlsAuthorizer.CheckPermission(ctx, r, entityURL, entitlement)
 	}
 
+	// Instance scoped tokens issued over devlxd have no associated identity.
+	if protocol == api.AuthenticationMethodDevLXD {
+		return e.checkDevLXDTokenPermission(details, entityURL, entitlement)
+	}
+
+	// Instance access tokens have no associated identity either.
+	if protocol == api.AuthenticationMethodInstanceAccess {
+		return e.checkInstanceAccessTokenPermission(details, entityURL, entitlement)
+	}
+
 	// Get the identity.
 	identityCacheEntry, err := e.identityCache.Get(protocol, username)
 	if err != nil {
@@ -174,6 +184,13 @@ func (e *embeddedOpenFGA) CheckPermission(ctx context.Context, r *http.Request,
 		}
 	}
 
+	// Add any groups resolved from identity provider group rules.
+	for _, lxdGroup := range details.identityProviderGroupRuleGroups() {
+		if !shared.ValueInSlice(lxdGroup, groups) {
+			groups = append(groups, lxdGroup)
+		}
+	}
+
 	// Construct OpenFGA objects for the user (identity) and the entity.
 	entityType, _, _, _, err := entity.ParseURL(entityURL.URL)
 	if err != nil {
@@ -330,6 +347,16 @@ func (e *embeddedOpenFGA) GetPermissionChecker(ctx context.Context, r *http.Requ
 		return e.tlsAuthorizer.GetPermissionChecker(ctx, r, entitlement, entityType)
 	}
 
+	// Instance scoped tokens issued over devlxd have no associated identity.
+	if protocol == api.AuthenticationMethodDevLXD {
+		return e.devLXDTokenPermissionChecker(details, entitlement)
+	}
+
+	// Instance access tokens have no associated identity either.
+	if protocol == api.AuthenticationMethodInstanceAccess {
+		return e.instanceAccessTokenPermissionChecker(details, entitlement)
+	}
+
 	// Get the identity.
 	identityCacheEntry, err := e.identityCache.Get(protocol, username)
 	if err != nil {
@@ -364,6 +391,13 @@ func (e *embeddedOpenFGA) GetPermissionChecker(ctx context.Context, r *http.Requ
 		}
 	}
 
+	// Add any groups resolved from identity provider group rules.
+	for _, lxdGroup := range details.identityProviderGroupRuleGroups() {
+		if !shared.ValueInSlice(lxdGroup, groups) {
+			groups = append(groups, lxdGroup)
+		}
+	}
+
 	// Construct an OpenFGA list objects request.
 	userObject := fmt.Sprintf("%s:%s", entity.TypeIdentity, entity.IdentityURL(protocol, username).String())
 	req := &openfgav1.ListObjectsRequest{
@@ -56,6 +56,14 @@ func (t *tls) CheckPermission(ctx context.Context, r *http.Request, entityURL *a
 		return nil
 	}
 
+	if details.isDevLXDToken() {
+		return t.checkDevLXDTokenPermission(details, entityURL, entitlement)
+	}
+
+	if details.isInstanceAccessToken() {
+		return t.checkInstanceAccessTokenPermission(details, entityURL, entitlement)
+	}
+
 	authenticationProtocol := details.authenticationProtocol()
 	if authenticationProtocol != api.AuthenticationMethodTLS {
 		t.logger.Warn("Authentication protocol is not compatible with authorization driver", logger.Ctx{"protocol": authenticationProtocol})
@@ -146,6 +154,14 @@ func (t *tls) GetPermissionChecker(ctx context.Context, r *http.Request, entitle
 		return allowFunc(true), nil
 	}
 
+	if details.isDevLXDToken() {
+		return t.devLXDTokenPermissionChecker(details, entitlement)
+	}
+
+	if details.isInstanceAccessToken() {
+		return t.instanceAccessTokenPermissionChecker(details, entitlement)
+	}
+
 	authenticationProtocol := details.authenticationProtocol()
 	if authenticationProtocol != api.AuthenticationMethodTLS {
 		t.logger.Warn("Authentication protocol is not compatible with authorization driver", logger.Ctx{"protocol": authenticationProtocol})
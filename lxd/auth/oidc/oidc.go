@@ -72,6 +72,9 @@ type AuthenticationResult struct {
 	Email                  string
 	Name                   string
 	IdentityProviderGroups []string
+
+	// Claims are all claims of the verified token. They are used to evaluate identity provider group rules.
+	Claims map[string]any
 }
 
 // AuthError represents an authentication error. If an error of this type is returned, the caller should call
@@ -155,11 +158,26 @@ func (o *Verifier) authenticateAccessToken(ctx context.Context, accessToken stri
 			Name:                   id.Name,
 			Subject:                claims.Subject,
 			IdentityProviderGroups: o.getGroupsFromClaims(claims.Claims),
+			Claims:                 claims.Claims,
 		}, nil
 	} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
 		return nil, fmt.Errorf("Failed to get OIDC identity from identity cache by their subject (%s): %w", claims.Subject, err)
 	}
 
+	// Access tokens obtained with the client credentials grant are issued to a client (service account) rather than a
+	// user, so there is no email address to look up. Use the client ID to identify them instead.
+	clientID := clientCredentialsClientID(claims)
+	if clientID != "" {
+		return &AuthenticationResult{
+			IdentityType:           api.IdentityTypeOIDCClient,
+			Email:                  clientID,
+			Name:                   clientID,
+			Subject:                claims.Subject,
+			IdentityProviderGroups: o.getGroupsFromClaims(claims.Claims),
+			Claims:                 claims.Claims,
+		}, nil
+	}
+
 	userInfo, err := rp.Userinfo[*oidc.UserInfo](ctx, accessToken, oidc.BearerToken, claims.Subject, o.relyingParty)
 	if err != nil {
 		return nil, AuthError{Err: fmt.Errorf("Failed to call user info endpoint with given access token: %w", err)}
@@ -175,9 +193,38 @@ func (o *Verifier) authenticateAccessToken(ctx context.Context, accessToken stri
 		Name:                   userInfo.Name,
 		Subject:                claims.Subject,
 		IdentityProviderGroups: o.getGroupsFromClaims(claims.Claims),
+		Claims:                 claims.Claims,
 	}, nil
 }
 
+// clientCredentialsClientID returns the ID of the client the access token was issued to if the token was obtained
+// with the client credentials grant, or an empty string otherwise.
+//
+// Identity providers mark these tokens differently. Following RFC 9068, the subject of a token issued without a
+// resource owner is the client itself. Some providers instead set a "gty" (grant type) claim, or use a subject
+// derived from the client ID.
+func clientCredentialsClientID(claims *oidc.AccessTokenClaims) string {
+	clientID := claims.ClientID
+	if clientID == "" {
+		clientID = claims.AuthorizedParty
+	}
+
+	if clientID == "" {
+		return ""
+	}
+
+	grantType, _ := claims.Claims["gty"].(string)
+	if grantType == "client-credentials" || grantType == string(oidc.GrantTypeClientCredentials) {
+		return clientID
+	}
+
+	if claims.Subject == clientID || claims.Subject == clientID+"@clients" {
+		return clientID
+	}
+
+	return ""
+}
+
 // authenticateIDToken verifies the identity token and returns the ID token subject. If no identity token is given (or
 // verification fails) it will attempt to refresh the ID token.
 func (o *Verifier) authenticateIDToken(ctx context.Context, w http.ResponseWriter, idToken string, refreshToken string) (*AuthenticationResult, error) {
@@ -193,6 +240,7 @@ func (o *Verifier) authenticateIDToken(ctx context.Context, w http.ResponseWrite
 				Email:                  claims.Email,
 				Name:                   claims.Name,
 				IdentityProviderGroups: o.getGroupsFromClaims(claims.Claims),
+				Claims:                 claims.Claims,
 			}, nil
 		}
 	}
@@ -237,6 +285,7 @@ func (o *Verifier) authenticateIDToken(ctx context.Context, w http.ResponseWrite
 		Email:                  claims.Email,
 		Name:                   claims.Name,
 		IdentityProviderGroups: o.getGroupsFromClaims(claims.Claims),
+		Claims:                 claims.Claims,
 	}, nil
 }
 
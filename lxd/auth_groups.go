@@ -181,9 +181,11 @@ func getAuthGroups(d *Daemon, r *http.Request) response.Response {
 
 	var groups []dbCluster.AuthGroup
 	var authGroupPermissions []dbCluster.Permission
+	var authGroupRoles []dbCluster.AuthGroupRole
 	groupsIdentities := make(map[int][]dbCluster.Identity)
 	groupsIdentityProviderGroups := make(map[int][]dbCluster.IdentityProviderGroup)
 	entityURLs := make(map[entity.Type]map[int]*api.URL)
+	roleEntityURLs := make(map[entity.Type]map[int]*api.URL)
 	err = d.db.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		allGroups, err := dbCluster.GetAuthGroups(ctx, tx.Tx())
 		if err != nil {
@@ -224,6 +226,17 @@ func getAuthGroups(d *Daemon, r *http.Request) response.Response {
 			if err != nil {
 				return err
 			}
+
+			authGroupRoles, err = dbCluster.GetAuthGroupRoles(ctx, tx.Tx(), nil)
+			if err != nil {
+				return err
+			}
+
+			// Get the EntityURLs for the role assignments.
+			authGroupRoles, roleEntityURLs, err = dbCluster.GetAuthGroupRoleEntityURLs(ctx, tx.Tx(), authGroupRoles)
+			if err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -238,6 +251,11 @@ func getAuthGroups(d *Daemon, r *http.Request) response.Response {
 			authGroupPermissionsByGroupID[permission.GroupID] = append(authGroupPermissionsByGroupID[permission.GroupID], permission)
 		}
 
+		authGroupRolesByGroupID := make(map[int][]dbCluster.AuthGroupRole, len(groups))
+		for _, groupRole := range authGroupRoles {
+			authGroupRolesByGroupID[groupRole.GroupID] = append(authGroupRolesByGroupID[groupRole.GroupID], groupRole)
+		}
+
 		apiGroups := make([]api.AuthGroup, 0, len(groups))
 		for _, group := range groups {
 			var apiPermissions []api.Permission
@@ -255,6 +273,14 @@ func getAuthGroups(d *Daemon, r *http.Request) response.Response {
 				}
 			}
 
+			apiRoles := make([]api.AuthGroupRole, 0, len(authGroupRolesByGroupID[group.ID]))
+			for _, groupRole := range authGroupRolesByGroupID[group.ID] {
+				apiRoles = append(apiRoles, api.AuthGroupRole{
+					Role:            groupRole.RoleName,
+					EntityReference: roleEntityURLs[entity.Type(groupRole.EntityType)][groupRole.EntityID].String(),
+				})
+			}
+
 			apiIdentities := make(map[string][]string)
 			for _, identity := range groupsIdentities[group.ID] {
 				authenticationMethod := string(identity.AuthMethod)
@@ -276,6 +302,7 @@ func getAuthGroups(d *Daemon, r *http.Request) response.Response {
 				Permissions:            apiPermissions,
 				Identities:             apiIdentities,
 				IdentityProviderGroups: idpGroups,
+				Roles:                  apiRoles,
 			})
 		}
 
@@ -352,6 +379,11 @@ func createAuthGroup(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		err = upsertGroupRoles(ctx, tx.Tx(), int(groupID), group.Roles)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -525,6 +557,11 @@ func updateAuthGroup(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		err = upsertGroupRoles(ctx, tx.Tx(), group.ID, groupPut.Roles)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -633,6 +670,20 @@ func patchAuthGroup(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		if len(groupPut.Roles) > 0 {
+			roles := apiGroup.Roles
+			for _, role := range groupPut.Roles {
+				if !shared.ValueInSlice(role, roles) {
+					roles = append(roles, role)
+				}
+			}
+
+			err = upsertGroupRoles(ctx, tx.Tx(), group.ID, roles)
+			if err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -869,3 +920,71 @@ func upsertPermissions(ctx context.Context, tx *sql.Tx, groupID int, permissions
 
 	return nil
 }
+
+// upsertGroupRoles converts the given slice of api.AuthGroupRole into a slice of cluster.AuthGroupRole by resolving
+// the role names and the URLs of each assignment. Then sets those role assignments against the group with the given ID.
+func upsertGroupRoles(ctx context.Context, tx *sql.Tx, groupID int, groupRoles []api.AuthGroupRole) error {
+	roles := make(map[string]*dbCluster.AuthRole)
+	entityReferences := make(map[*api.URL]*dbCluster.EntityRef, len(groupRoles))
+	groupRoleToURL := make(map[api.AuthGroupRole]*api.URL, len(groupRoles))
+	for _, groupRole := range groupRoles {
+		role, ok := roles[groupRole.Role]
+		if !ok {
+			var err error
+			role, err = dbCluster.GetAuthRole(ctx, tx, groupRole.Role)
+			if err != nil && api.StatusErrorCheck(err, http.StatusNotFound) {
+				return api.StatusErrorf(http.StatusBadRequest, "Role %q not found", groupRole.Role)
+			} else if err != nil {
+				return err
+			}
+
+			roles[groupRole.Role] = role
+		}
+
+		u, err := url.Parse(groupRole.EntityReference)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Failed to parse entity reference %q of role %q: %w", groupRole.EntityReference, groupRole.Role, err)
+		}
+
+		entityType, _, _, _, err := entity.ParseURL(*u)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Failed to parse entity reference %q of role %q: %w", groupRole.EntityReference, groupRole.Role, err)
+		}
+
+		if entityType != entity.Type(role.EntityType) {
+			return api.StatusErrorf(http.StatusBadRequest, "Role %q can only be assigned on entities of type %q", groupRole.Role, role.EntityType)
+		}
+
+		apiURL := &api.URL{URL: *u}
+		entityReferences[apiURL] = &dbCluster.EntityRef{}
+		groupRoleToURL[groupRole] = apiURL
+	}
+
+	err := dbCluster.PopulateEntityReferencesFromURLs(ctx, tx, entityReferences)
+	if err != nil {
+		return err
+	}
+
+	authGroupRoles := make([]dbCluster.AuthGroupRole, 0, len(groupRoles))
+	for groupRole, apiURL := range groupRoleToURL {
+		entityRef, ok := entityReferences[apiURL]
+		if !ok {
+			return api.StatusErrorf(http.StatusBadRequest, "Missing entity ID for role %q with URL %q", groupRole.Role, groupRole.EntityReference)
+		}
+
+		role := roles[groupRole.Role]
+		authGroupRoles = append(authGroupRoles, dbCluster.AuthGroupRole{
+			GroupID:    groupID,
+			RoleID:     role.ID,
+			EntityType: role.EntityType,
+			EntityID:   entityRef.EntityID,
+		})
+	}
+
+	err = dbCluster.SetAuthGroupRoles(ctx, tx, groupID, authGroupRoles)
+	if err != nil {
+		return fmt.Errorf("Failed to set group roles: %w", err)
+	}
+
+	return nil
+}
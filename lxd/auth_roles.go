@@ -0,0 +1,641 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Roles change the permissions of every group they are assigned to, so they are managed with the same server level
+// entitlements as groups.
+var authRolesCmd = APIEndpoint{
+	Name: "auth_roles",
+	Path: "auth/roles",
+	Get: APIEndpointAction{
+		Handler:       getAuthRoles,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewGroups),
+	},
+	Post: APIEndpointAction{
+		Handler:       createAuthRole,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanCreateGroups),
+	},
+}
+
+var authRoleCmd = APIEndpoint{
+	Name: "auth_role",
+	Path: "auth/roles/{roleName}",
+	Get: APIEndpointAction{
+		Handler:       getAuthRole,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewGroups),
+	},
+	Put: APIEndpointAction{
+		Handler:       updateAuthRole,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEditGroups),
+	},
+	Post: APIEndpointAction{
+		Handler:       renameAuthRole,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEditGroups),
+	},
+	Delete: APIEndpointAction{
+		Handler:       deleteAuthRole,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanDeleteGroups),
+	},
+	Patch: APIEndpointAction{
+		Handler:       patchAuthRole,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEditGroups),
+	},
+}
+
+func validateRoleName(name string) error {
+	if name == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Role name cannot be empty")
+	}
+
+	if strings.Contains(name, "/") {
+		return api.StatusErrorf(http.StatusBadRequest, "Role name cannot contain a forward slash")
+	}
+
+	if strings.Contains(name, ":") {
+		return api.StatusErrorf(http.StatusBadRequest, "Role name cannot contain a colon")
+	}
+
+	return nil
+}
+
+// validateRoleEntitlements checks that each entitlement is valid for the entity type of the role and returns them
+// without duplicates.
+func validateRoleEntitlements(entityType entity.Type, entitlements []string) ([]auth.Entitlement, error) {
+	result := make([]auth.Entitlement, 0, len(entitlements))
+	for _, entitlement := range entitlements {
+		err := auth.ValidateEntitlement(entityType, auth.Entitlement(entitlement))
+		if err != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Failed to validate role entitlement %q: %w", entitlement, err)
+		}
+
+		if !shared.ValueInSlice(auth.Entitlement(entitlement), result) {
+			result = append(result, auth.Entitlement(entitlement))
+		}
+	}
+
+	return result, nil
+}
+
+// authRoleURL returns the URL of the role with the given name.
+func authRoleURL(roleName string) *api.URL {
+	return api.NewURL().Path(version.APIVersion, "auth", "roles", roleName)
+}
+
+// swagger:operation GET /1.0/auth/roles auth_roles auth_roles_get
+//
+//	Get the roles
+//
+//	Returns a list of authorization roles (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/auth/roles/foo",
+//	              "/1.0/auth/roles/bar"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/auth/roles?recursion=1 auth_roles auth_roles_get_recursion1
+//
+//	Get the roles
+//
+//	Returns a list of authorization roles.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of roles
+//	          items:
+//	            $ref: "#/definitions/AuthRole"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func getAuthRoles(d *Daemon, r *http.Request) response.Response {
+	recursion := request.QueryParam(r, "recursion")
+	s := d.State()
+
+	canViewGroup, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeAuthGroup)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed to get a permission checker: %w", err))
+	}
+
+	var apiRoles []api.AuthRole
+	var roles []dbCluster.AuthRole
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		roles, err = dbCluster.GetAuthRoles(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		if recursion != "1" {
+			return nil
+		}
+
+		apiRoles = make([]api.AuthRole, 0, len(roles))
+		for _, role := range roles {
+			apiRole, err := role.ToAPI(ctx, tx.Tx(), canViewGroup)
+			if err != nil {
+				return err
+			}
+
+			apiRoles = append(apiRoles, *apiRole)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if recursion == "1" {
+		return response.SyncResponse(true, apiRoles)
+	}
+
+	roleURLs := make([]string, 0, len(roles))
+	for _, role := range roles {
+		roleURLs = append(roleURLs, authRoleURL(role.Name).String())
+	}
+
+	return response.SyncResponse(true, roleURLs)
+}
+
+// swagger:operation POST /1.0/auth/roles auth_roles auth_roles_post
+//
+//	Create a new authorization role
+//
+//	Creates a new authorization role.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: role
+//	    description: Role request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/AuthRolesPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func createAuthRole(d *Daemon, r *http.Request) response.Response {
+	var role api.AuthRolesPost
+	err := json.NewDecoder(r.Body).Decode(&role)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid request body: %w", err))
+	}
+
+	err = validateRoleName(role.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	entityType := entity.Type(role.EntityType)
+	err = entityType.Validate()
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Failed to validate role entity type: %w", err))
+	}
+
+	entitlements, err := validateRoleEntitlements(entityType, role.Entitlements)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		roleID, err := dbCluster.CreateAuthRole(ctx, tx.Tx(), dbCluster.AuthRole{
+			Name:        role.Name,
+			Description: role.Description,
+			EntityType:  dbCluster.EntityType(entityType),
+		})
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.SetAuthRoleEntitlements(ctx, tx.Tx(), int(roleID), entitlements)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the role creation
+	lc := lifecycle.AuthRoleCreated.Event(role.Name, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.SyncResponseLocation(true, nil, authRoleURL(role.Name).String())
+}
+
+// swagger:operation GET /1.0/auth/roles/{roleName} auth_roles auth_role_get
+//
+//	Get the authorization role
+//
+//	Gets a specific authorization role.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/AuthRole"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func getAuthRole(d *Daemon, r *http.Request) response.Response {
+	roleName, err := url.PathUnescape(mux.Vars(r)["roleName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	s := d.State()
+	canViewGroup, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeAuthGroup)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed to get a permission checker: %w", err))
+	}
+
+	var apiRole *api.AuthRole
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		role, err := dbCluster.GetAuthRole(ctx, tx.Tx(), roleName)
+		if err != nil {
+			return err
+		}
+
+		apiRole, err = role.ToAPI(ctx, tx.Tx(), canViewGroup)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, *apiRole, *apiRole)
+}
+
+// swagger:operation PUT /1.0/auth/roles/{roleName} auth_roles auth_role_put
+//
+//	Update the authorization role
+//
+//	Replaces the editable fields of an authorization role.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: role
+//	    description: Update request
+//	    schema:
+//	      $ref: "#/definitions/AuthRolePut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func updateAuthRole(d *Daemon, r *http.Request) response.Response {
+	return doAuthRoleUpdate(d, r, false)
+}
+
+// swagger:operation PATCH /1.0/auth/roles/{roleName} auth_roles auth_role_patch
+//
+//	Partially update the authorization role
+//
+//	Updates the description of an authorization role and adds the given entitlements to it.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: role
+//	    description: Update request
+//	    schema:
+//	      $ref: "#/definitions/AuthRolePut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func patchAuthRole(d *Daemon, r *http.Request) response.Response {
+	return doAuthRoleUpdate(d, r, true)
+}
+
+// doAuthRoleUpdate updates the role with the request body. When patching, the description is only changed if set
+// and the given entitlements are added to the existing ones.
+func doAuthRoleUpdate(d *Daemon, r *http.Request, patch bool) response.Response {
+	roleName, err := url.PathUnescape(mux.Vars(r)["roleName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var rolePut api.AuthRolePut
+	err = json.NewDecoder(r.Body).Decode(&rolePut)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid request body: %w", err))
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	s := d.State()
+	canViewGroup, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeAuthGroup)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed to get a permission checker: %w", err))
+	}
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		role, err := dbCluster.GetAuthRole(ctx, tx.Tx(), roleName)
+		if err != nil {
+			return err
+		}
+
+		apiRole, err := role.ToAPI(ctx, tx.Tx(), canViewGroup)
+		if err != nil {
+			return err
+		}
+
+		err = util.EtagCheck(r, *apiRole)
+		if err != nil {
+			return err
+		}
+
+		if patch {
+			if rolePut.Description == "" {
+				rolePut.Description = apiRole.Description
+			}
+
+			rolePut.Entitlements = append(apiRole.Entitlements, rolePut.Entitlements...)
+		}
+
+		entitlements, err := validateRoleEntitlements(entity.Type(role.EntityType), rolePut.Entitlements)
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.UpdateAuthRole(ctx, tx.Tx(), roleName, dbCluster.AuthRole{
+			Name:        roleName,
+			Description: rolePut.Description,
+			EntityType:  role.EntityType,
+		})
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.SetAuthRoleEntitlements(ctx, tx.Tx(), role.ID, entitlements)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the role update
+	lc := lifecycle.AuthRoleUpdated.Event(roleName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/auth/roles/{roleName} auth_roles auth_role_post
+//
+//	Rename the authorization role
+//
+//	Renames the authorization role.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: role
+//	    description: Update request
+//	    schema:
+//	      $ref: "#/definitions/AuthRolePost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func renameAuthRole(d *Daemon, r *http.Request) response.Response {
+	roleName, err := url.PathUnescape(mux.Vars(r)["roleName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var rolePost api.AuthRolePost
+	err = json.NewDecoder(r.Body).Decode(&rolePost)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid request body: %w", err))
+	}
+
+	err = validateRoleName(rolePost.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		exists, err := dbCluster.AuthRoleExists(ctx, tx.Tx(), rolePost.Name)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return api.StatusErrorf(http.StatusConflict, "Role %q already exists", rolePost.Name)
+		}
+
+		return dbCluster.RenameAuthRole(ctx, tx.Tx(), roleName, rolePost.Name)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the role rename
+	lc := lifecycle.AuthRoleRenamed.Event(rolePost.Name, request.CreateRequestor(r), map[string]any{"old_name": roleName})
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.SyncResponseLocation(true, nil, authRoleURL(rolePost.Name).String())
+}
+
+// swagger:operation DELETE /1.0/auth/roles/{roleName} auth_roles auth_role_delete
+//
+//	Delete the authorization role
+//
+//	Deletes the authorization role. Roles that are assigned to groups cannot be deleted.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deleteAuthRole(d *Daemon, r *http.Request) response.Response {
+	roleName, err := url.PathUnescape(mux.Vars(r)["roleName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		role, err := dbCluster.GetAuthRole(ctx, tx.Tx(), roleName)
+		if err != nil {
+			return err
+		}
+
+		groups, err := dbCluster.GetAuthGroupsByAuthRoleID(ctx, tx.Tx(), role.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(groups) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "Role %q is still assigned to %d group(s)", roleName, len(groups))
+		}
+
+		return dbCluster.DeleteAuthRole(ctx, tx.Tx(), roleName)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the role deletion
+	lc := lifecycle.AuthRoleDeleted.Event(roleName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.EmptySyncResponse
+}
@@ -0,0 +1,238 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Authority is the built-in certificate authority used to issue client certificates.
+type Authority struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+// GenerateAuthority creates a new self-signed certificate authority and returns its PEM encoded certificate and key.
+func GenerateAuthority(commonName string) ([]byte, []byte, error) {
+	privk, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to generate key: %w", err)
+	}
+
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validFrom := time.Now().Add(-time.Minute)
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{"LXD"},
+			CommonName:   commonName,
+		},
+		NotBefore: validFrom,
+		NotAfter:  validFrom.Add(10 * 365 * 24 * time.Hour),
+
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &privk.PublicKey, privk)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create certificate: %w", err)
+	}
+
+	data, err := x509.MarshalECPrivateKey(privk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: data})
+
+	return cert, key, nil
+}
+
+// LoadAuthority returns the certificate authority from its PEM encoded certificate and key.
+func LoadAuthority(certPEM string, keyPEM string) (*Authority, error) {
+	keypair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load certificate authority: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(keypair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse certificate authority: %w", err)
+	}
+
+	key, ok := keypair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported certificate authority key")
+	}
+
+	return &Authority{cert: cert, key: key}, nil
+}
+
+// EncryptAuthorityKey encrypts the PEM encoded key of a certificate authority with a key derived from the given
+// secret, so that it can be stored along with the certificate of the certificate authority.
+func EncryptAuthorityKey(keyPEM []byte, secret []byte) (string, error) {
+	aead, err := authorityKeyCipher(secret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate nonce: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, keyPEM, nil)), nil
+}
+
+// DecryptAuthorityKey returns the PEM encoded key of a certificate authority encrypted by EncryptAuthorityKey with
+// the same secret.
+func DecryptAuthorityKey(encryptedKey string, secret []byte) ([]byte, error) {
+	aead, err := authorityKeyCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encryptedKey)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("Invalid encrypted certificate authority key")
+	}
+
+	keyPEM, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decrypt certificate authority key: %w", err)
+	}
+
+	return keyPEM, nil
+}
+
+// authorityKeyCipher returns the AES-256-GCM cipher used to encrypt the key of a certificate authority.
+func authorityKeyCipher(secret []byte) (cipher.AEAD, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("No secret to encrypt the certificate authority key with")
+	}
+
+	key := sha256.Sum256(append([]byte("lxd-certificate-authority-key"), secret...))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// ParseCertificateRequest parses a PEM encoded certificate signing request and checks its signature.
+func ParseCertificateRequest(csrPEM string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("Invalid certificate signing request")
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid certificate signing request: %w", err)
+	}
+
+	err = csr.CheckSignature()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid certificate signing request signature: %w", err)
+	}
+
+	return csr, nil
+}
+
+// Certificate returns the certificate of the certificate authority.
+func (a *Authority) Certificate() *x509.Certificate {
+	return a.cert
+}
+
+// Issued returns whether the certificate was issued by the certificate authority.
+func (a *Authority) Issued(cert *x509.Certificate) bool {
+	return cert.CheckSignatureFrom(a.cert) == nil
+}
+
+// Sign issues a client certificate for the certificate signing request, valid until the given time.
+// The validity is capped to the one of the certificate authority.
+func (a *Authority) Sign(csr *x509.CertificateRequest, notAfter time.Time) (*x509.Certificate, error) {
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	if notAfter.After(a.cert.NotAfter) {
+		notAfter = a.cert.NotAfter
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{"LXD"},
+			CommonName:   csr.Subject.CommonName,
+		},
+		NotBefore: time.Now().Add(-time.Minute),
+		NotAfter:  notAfter,
+
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, a.cert, csr.PublicKey, a.key)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to sign certificate: %w", err)
+	}
+
+	return x509.ParseCertificate(derBytes)
+}
+
+// RevocationList returns the PEM encoded certificate revocation list containing the given revoked certificates.
+func (a *Authority) RevocationList(revoked []x509.RevocationListEntry, nextUpdate time.Time) ([]byte, error) {
+	now := time.Now()
+
+	template := x509.RevocationList{
+		RevokedCertificateEntries: revoked,
+		Number:                    big.NewInt(now.UnixNano()),
+		ThisUpdate:                now,
+		NextUpdate:                nextUpdate,
+	}
+
+	derBytes, err := x509.CreateRevocationList(rand.Reader, &template, a.cert, a.key)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create certificate revocation list: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: derBytes}), nil
+}
+
+// newSerialNumber returns a random certificate serial number.
+func newSerialNumber() (*big.Int, error) {
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate serial number: %w", err)
+	}
+
+	return serialNumber, nil
+}
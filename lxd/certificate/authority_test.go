@@ -0,0 +1,123 @@
+package certificate_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/canonical/lxd/lxd/certificate"
+	"github.com/canonical/lxd/shared"
+)
+
+// A certificate signing request is signed by the authority and the issued certificate can be revoked.
+func TestAuthority(t *testing.T) {
+	caCert, caKey, err := certificate.GenerateAuthority("test")
+	if err != nil {
+		t.Fatalf("failed to generate authority: %v", err)
+	}
+
+	ca, err := certificate.LoadAuthority(string(caCert), string(caKey))
+	if err != nil {
+		t.Fatalf("failed to load authority: %v", err)
+	}
+
+	csrPEM, _, err := shared.GenerateMemCertRequest()
+	if err != nil {
+		t.Fatalf("failed to generate certificate request: %v", err)
+	}
+
+	csr, err := certificate.ParseCertificateRequest(string(csrPEM))
+	if err != nil {
+		t.Fatalf("failed to parse certificate request: %v", err)
+	}
+
+	notAfter := time.Now().Add(time.Hour).Truncate(time.Second)
+	cert, err := ca.Sign(csr, notAfter)
+	if err != nil {
+		t.Fatalf("failed to sign certificate: %v", err)
+	}
+
+	if !ca.Issued(cert) {
+		t.Errorf("expected certificate to be issued by the authority")
+	}
+
+	if !cert.NotAfter.Equal(notAfter) {
+		t.Errorf("expected certificate to expire at %v, got %v", notAfter, cert.NotAfter)
+	}
+
+	if cert.Subject.CommonName != csr.Subject.CommonName {
+		t.Errorf("expected common name %q, got %q", csr.Subject.CommonName, cert.Subject.CommonName)
+	}
+
+	_, err = certificate.ParseCertificateRequest(string(caCert))
+	if err == nil {
+		t.Errorf("expected a certificate to be rejected as certificate request")
+	}
+
+	crlPEM, err := ca.RevocationList([]x509.RevocationListEntry{{SerialNumber: cert.SerialNumber, RevocationTime: time.Now()}}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create revocation list: %v", err)
+	}
+
+	block, _ := pem.Decode(crlPEM)
+	if block == nil || block.Type != "X509 CRL" {
+		t.Fatalf("failed to decode revocation list")
+	}
+
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse revocation list: %v", err)
+	}
+
+	err = crl.CheckSignatureFrom(ca.Certificate())
+	if err != nil {
+		t.Errorf("expected revocation list to be signed by the authority: %v", err)
+	}
+
+	if len(crl.RevokedCertificateEntries) != 1 || crl.RevokedCertificateEntries[0].SerialNumber.Cmp(cert.SerialNumber) != 0 {
+		t.Errorf("expected revocation list to contain the issued certificate")
+	}
+}
+
+// The key of the authority can only be decrypted with the secret it was encrypted with.
+func TestAuthorityKeyEncryption(t *testing.T) {
+	_, caKey, err := certificate.GenerateAuthority("test")
+	if err != nil {
+		t.Fatalf("failed to generate authority: %v", err)
+	}
+
+	encryptedKey, err := certificate.EncryptAuthorityKey(caKey, []byte("cluster key"))
+	if err != nil {
+		t.Fatalf("failed to encrypt key: %v", err)
+	}
+
+	if strings.Contains(encryptedKey, "PRIVATE KEY") {
+		t.Errorf("expected key to be encrypted")
+	}
+
+	keyPEM, err := certificate.DecryptAuthorityKey(encryptedKey, []byte("cluster key"))
+	if err != nil {
+		t.Fatalf("failed to decrypt key: %v", err)
+	}
+
+	if string(keyPEM) != string(caKey) {
+		t.Errorf("expected decrypted key to match the original key")
+	}
+
+	_, err = certificate.DecryptAuthorityKey(encryptedKey, []byte("other key"))
+	if err == nil {
+		t.Errorf("expected decryption with another secret to fail")
+	}
+
+	_, err = certificate.DecryptAuthorityKey(string(caKey), []byte("cluster key"))
+	if err == nil {
+		t.Errorf("expected decryption of a plaintext key to fail")
+	}
+
+	_, err = certificate.EncryptAuthorityKey(caKey, nil)
+	if err == nil {
+		t.Errorf("expected encryption without secret to fail")
+	}
+}
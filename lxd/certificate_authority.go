@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/certificate"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// certificateAuthorityCommonName is the common name of the built-in certificate authority.
+const certificateAuthorityCommonName = "LXD certificate authority"
+
+// certificateAuthorityRevocationListValidity is how long the published revocation list is valid for.
+const certificateAuthorityRevocationListValidity = 24 * time.Hour
+
+var certificateAuthorityCmd = APIEndpoint{
+	Path: "certificate-authority",
+
+	Get: APIEndpointAction{Handler: certificateAuthorityGet, AllowUntrusted: true},
+}
+
+// swagger:operation GET /1.0/certificate-authority certificates certificate_authority_get
+//
+//	Get the built-in certificate authority
+//
+//	Gets the certificate of the built-in certificate authority along with the revocation list of the
+//	client certificates it issued.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Certificate authority
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/CertificateAuthority"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func certificateAuthorityGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	var ca *certificate.Authority
+	var revocations []dbCluster.CertificateAuthorityRevocation
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		ca, err = certificateAuthorityLoad(ctx, tx, s.Endpoints.NetworkCert().PrivateKey(), false)
+		if err != nil {
+			return err
+		}
+
+		revocations, err = dbCluster.GetCertificateAuthorityRevocations(ctx, tx.Tx(), time.Now())
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	entries := make([]x509.RevocationListEntry, 0, len(revocations))
+	for _, revocation := range revocations {
+		serialNumber, ok := new(big.Int).SetString(revocation.SerialNumber, 16)
+		if !ok {
+			return response.InternalError(fmt.Errorf("Invalid serial number %q of revoked certificate", revocation.SerialNumber))
+		}
+
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: serialNumber, RevocationTime: revocation.RevokedAt})
+	}
+
+	crl, err := ca.RevocationList(entries, time.Now().Add(certificateAuthorityRevocationListValidity))
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resp := api.CertificateAuthority{
+		Certificate:    string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate().Raw})),
+		RevocationList: string(crl),
+	}
+
+	return response.SyncResponse(true, resp)
+}
+
+// certificateAuthorityLoad returns the built-in certificate authority.
+// Its key is stored in the database encrypted with the given secret, which is the private key of the cluster
+// certificate. The key is therefore only usable by the cluster members, which hold the cluster private key on disk.
+// If create is true, the certificate authority is generated if it doesn't exist yet.
+func certificateAuthorityLoad(ctx context.Context, tx *db.ClusterTx, secret []byte, create bool) (*certificate.Authority, error) {
+	dbCA, err := dbCluster.GetCertificateAuthority(ctx, tx.Tx())
+	if err != nil {
+		if !create || !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, err
+		}
+
+		certPEM, keyPEM, err := certificate.GenerateAuthority(certificateAuthorityCommonName)
+		if err != nil {
+			return nil, err
+		}
+
+		encryptedKey, err := certificate.EncryptAuthorityKey(keyPEM, secret)
+		if err != nil {
+			return nil, err
+		}
+
+		dbCA = &dbCluster.CertificateAuthority{
+			Certificate:  string(certPEM),
+			EncryptedKey: encryptedKey,
+		}
+
+		err = dbCluster.CreateCertificateAuthority(ctx, tx.Tx(), *dbCA)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keyPEM, err := certificate.DecryptAuthorityKey(dbCA.EncryptedKey, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return certificate.LoadAuthority(dbCA.Certificate, string(keyPEM))
+}
+
+// certificateAuthorityReencryptKey re-encrypts the key of the built-in certificate authority, if any, when the
+// private key of the cluster certificate it is encrypted with changes.
+func certificateAuthorityReencryptKey(ctx context.Context, s *state.State, oldSecret []byte, newSecret []byte) error {
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbCA, err := dbCluster.GetCertificateAuthority(ctx, tx.Tx())
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		keyPEM, err := certificate.DecryptAuthorityKey(dbCA.EncryptedKey, oldSecret)
+		if err != nil {
+			return err
+		}
+
+		encryptedKey, err := certificate.EncryptAuthorityKey(keyPEM, newSecret)
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.UpdateCertificateAuthorityKey(ctx, tx.Tx(), encryptedKey)
+	})
+}
+
+// certificateAuthoritySign issues a client certificate for the certificate signing request using the built-in
+// certificate authority. The validity of the certificate is taken from core.internal_ca_certificate_expiry.
+func certificateAuthoritySign(ctx context.Context, s *state.State, csr *x509.CertificateRequest) (*x509.Certificate, error) {
+	enabled, expiry := s.GlobalConfig.InternalCA()
+	if !enabled {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "The built-in certificate authority isn't enabled")
+	}
+
+	notAfter, err := shared.GetExpiry(time.Now(), expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	var ca *certificate.Authority
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		ca, err = certificateAuthorityLoad(ctx, tx, s.Endpoints.NetworkCert().PrivateKey(), true)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading certificate authority: %w", err)
+	}
+
+	return ca.Sign(csr, notAfter)
+}
+
+// certificateAuthorityRevoke adds the PEM encoded certificate to the revocation list of the built-in certificate
+// authority if it was issued by it. Other certificates are ignored.
+func certificateAuthorityRevoke(ctx context.Context, tx *db.ClusterTx, certPEM string) error {
+	// Only the certificate of the certificate authority is needed to check whether it issued the certificate.
+	dbCA, err := dbCluster.GetCertificateAuthority(ctx, tx.Tx())
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	caCert, err := certificateAuthorityParsePEM(dbCA.Certificate)
+	if err != nil {
+		return fmt.Errorf("Invalid certificate authority: %w", err)
+	}
+
+	cert, err := certificateAuthorityParsePEM(certPEM)
+	if err != nil {
+		return err
+	}
+
+	if cert.CheckSignatureFrom(caCert) != nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	// Prune the revocations of certificates that have expired in the meantime.
+	err = dbCluster.DeleteExpiredCertificateAuthorityRevocations(ctx, tx.Tx(), now)
+	if err != nil {
+		return err
+	}
+
+	return dbCluster.CreateCertificateAuthorityRevocation(ctx, tx.Tx(), dbCluster.CertificateAuthorityRevocation{
+		SerialNumber: cert.SerialNumber.Text(16),
+		RevokedAt:    now,
+		ExpiresAt:    cert.NotAfter,
+	})
+}
+
+// certificateAuthorityIssued returns the API representation of a certificate issued by the built-in certificate authority.
+func certificateAuthorityIssued(cert *x509.Certificate) api.CertificateIssued {
+	return api.CertificateIssued{
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+		Fingerprint: shared.CertFingerprint(cert),
+		ExpiresAt:   cert.NotAfter,
+	}
+}
+
+// certificateAuthorityParsePEM parses a PEM encoded certificate.
+func certificateAuthorityParsePEM(certPEM string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, fmt.Errorf("Invalid certificate")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
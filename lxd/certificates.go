@@ -250,6 +250,35 @@ func clusterMemberJoinTokenValid(s *state.State, r *http.Request, projectName st
 	return nil, nil
 }
 
+// certificateTokenUse cancels the operation of a certificate add token, which is what makes the token single-use.
+// The operation is cancelled by the member that created it, which only allows a running operation to be cancelled
+// once. So if the same token is used concurrently, on the same or on different members, only one request succeeds.
+func certificateTokenUse(s *state.State, r *http.Request, op *api.Operation) error {
+	localOp, _ := operations.OperationGetInternal(op.ID)
+	if localOp == nil {
+		// The member owning the operation refuses to delete it if it isn't running anymore.
+		err := operationCancel(s, r, api.ProjectDefaultName, op)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusBadRequest, http.StatusNotFound) {
+				return api.StatusErrorf(http.StatusForbidden, "Token has already been used")
+			}
+
+			return fmt.Errorf("Failed to cancel operation %q: %w", op.ID, err)
+		}
+
+		return nil
+	}
+
+	_, err := localOp.Cancel()
+	if err != nil {
+		return api.StatusErrorf(http.StatusForbidden, "Token has already been used")
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.OperationCancelled.Event(localOp, request.CreateRequestor(r), nil))
+
+	return nil
+}
+
 // certificateTokenValid searches for certificate token that matches the add token provided.
 // Returns matching operation if found and cancels the operation, otherwise returns nil.
 func certificateTokenValid(s *state.State, r *http.Request, addToken *api.CertificateAddToken) (*api.Operation, error) {
@@ -277,14 +306,21 @@ func certificateTokenValid(s *state.State, r *http.Request, addToken *api.Certif
 
 	if foundOp != nil {
 		// Token is single-use, so cancel it now.
-		err = operationCancel(s, r, api.ProjectDefaultName, foundOp)
+		err = certificateTokenUse(s, r, foundOp)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to cancel operation %q: %w", foundOp.ID, err)
+			return nil, err
 		}
 
 		expiresAt, ok := foundOp.Metadata["expiresAt"]
 		if ok {
-			expiry, _ := expiresAt.(time.Time)
+			var expiry time.Time
+
+			// Depending on whether it's a local operation or not, expiry will either be a time.Time or a string.
+			if s.ServerName == foundOp.Location {
+				expiry, _ = expiresAt.(time.Time)
+			} else {
+				expiry, _ = time.Parse(time.RFC3339Nano, expiresAt.(string))
+			}
 
 			// Check if token has expired.
 			if time.Now().After(expiry) {
@@ -341,6 +377,8 @@ func certificateTokenValid(s *state.State, r *http.Request, addToken *api.Certif
 //
 //	Adds a certificate to the trust store.
 //	In this mode, the `token` property is always ignored.
+//	If a certificate signing request is provided, the certificate is issued by the built-in certificate authority
+//	and returned in the response.
 //
 //	---
 //	consumes:
@@ -380,6 +418,10 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Can't use certificate if token is requested"))
 	}
 
+	if req.CertificateSigningRequest != "" && (req.Token || req.Certificate != "") {
+		return response.BadRequest(fmt.Errorf("Can't use certificate signing request with certificate or if token is requested"))
+	}
+
 	if req.Token {
 		if req.Type != "client" {
 			return response.BadRequest(fmt.Errorf("Tokens can only be issued for client certificates"))
@@ -388,6 +430,12 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 		if localHTTPSAddress == "" {
 			return response.BadRequest(fmt.Errorf("Can't issue token when server isn't listening on network"))
 		}
+
+		if !req.ExpiresAt.IsZero() && req.ExpiresAt.Before(time.Now()) {
+			return response.BadRequest(fmt.Errorf("Token expiry date must be in the future"))
+		}
+	} else if !req.ExpiresAt.IsZero() {
+		return response.BadRequest(fmt.Errorf("Expiry date can only be set when requesting a token"))
 	}
 
 	// Check if the caller has permission to create certificates.
@@ -442,7 +490,7 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 			// If so then check there is a matching join operation.
 			joinOp, err := certificateTokenValid(s, r, joinToken)
 			if err != nil {
-				return response.InternalError(fmt.Errorf("Failed during search for certificate add token operation: %w", err))
+				return response.SmartError(fmt.Errorf("Failed during search for certificate add token operation: %w", err))
 			}
 
 			if joinOp == nil {
@@ -456,10 +504,11 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 
 			// Create a new request from the token data as the user isn't allowed to override anything.
 			req = api.CertificatesPost{
-				Name:       tokenReq.Name,
-				Type:       tokenReq.Type,
-				Restricted: tokenReq.Restricted,
-				Projects:   tokenReq.Projects,
+				Name:                      tokenReq.Name,
+				Type:                      tokenReq.Type,
+				Restricted:                tokenReq.Restricted,
+				Projects:                  tokenReq.Projects,
+				CertificateSigningRequest: req.CertificateSigningRequest,
 			}
 		}
 	}
@@ -471,6 +520,7 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 
 	// Extract the certificate.
 	var cert *x509.Certificate
+	var issued bool
 	if req.Certificate != "" {
 		// Add supplied certificate.
 		data, err := base64.StdEncoding.DecodeString(req.Certificate)
@@ -482,6 +532,23 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 		if err != nil {
 			return response.BadRequest(fmt.Errorf("Invalid certificate material: %w", err))
 		}
+	} else if req.CertificateSigningRequest != "" {
+		// Issue a certificate from the built-in certificate authority.
+		if dbReqType != certificate.TypeClient {
+			return response.BadRequest(fmt.Errorf("Certificate signing requests can only be used for client certificates"))
+		}
+
+		csr, err := certificate.ParseCertificateRequest(req.CertificateSigningRequest)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		cert, err = certificateAuthoritySign(r.Context(), s, csr)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		issued = true
 	} else if req.Token {
 		// Get all addresses the server is listening on. This is encoded in the certificate token,
 		// so that the client will not have to specify a server address. The client will iterate
@@ -510,6 +577,23 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 			req.Projects = []string{}
 		}
 
+		// Check the projects now rather than when the token is used, so that invalid tokens aren't handed out.
+		if req.Restricted {
+			err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+				for _, projectName := range req.Projects {
+					_, err := dbCluster.GetProjectID(ctx, tx.Tx(), projectName)
+					if err != nil {
+						return fmt.Errorf("Failed loading project %q: %w", projectName, err)
+					}
+				}
+
+				return nil
+			})
+			if err != nil {
+				return response.SmartError(err)
+			}
+		}
+
 		meta := map[string]any{
 			"secret":      joinSecret,
 			"fingerprint": fingerprint,
@@ -518,9 +602,12 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 		}
 
 		// If tokens should expire, add the expiry date to the op's metadata.
+		// An expiry date set in the request takes precedence over the server wide expiry.
 		expiry := s.GlobalConfig.RemoteTokenExpiry()
 
-		if expiry != "" {
+		if !req.ExpiresAt.IsZero() {
+			meta["expiresAt"] = req.ExpiresAt
+		} else if expiry != "" {
 			expiresAt, err := shared.GetExpiry(time.Now(), expiry)
 			if err != nil {
 				return response.InternalError(err)
@@ -531,7 +618,7 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 
 		op, err := operations.OperationCreate(s, api.ProjectDefaultName, operations.OperationClassToken, operationtype.CertificateAddToken, nil, meta, nil, nil, nil, r)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 
 		return operations.OperationResponse(op)
@@ -623,6 +710,11 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 	lc := lifecycle.CertificateCreated.Event(fingerprint, request.CreateRequestor(r), nil)
 	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
 
+	// Return the issued certificate to the client.
+	if issued {
+		return response.SyncResponseLocation(true, certificateAuthorityIssued(cert), lc.Source)
+	}
+
 	return response.SyncResponseLocation(true, nil, lc.Source)
 }
 
@@ -694,6 +786,8 @@ func certificateGet(d *Daemon, r *http.Request) response.Response {
 //	Update the trusted certificate
 //
 //	Updates the entire certificate configuration.
+//	If a certificate signing request is provided, the certificate is replaced with one issued by the built-in
+//	certificate authority, which is returned in the response.
 //
 //	---
 //	consumes:
@@ -830,6 +924,10 @@ func doCertificateUpdate(d *Daemon, dbInfo api.Certificate, req api.CertificateP
 		return response.BadRequest(err)
 	}
 
+	if req.CertificateSigningRequest != "" && req.Certificate != "" && dbInfo.Certificate != req.Certificate {
+		return response.BadRequest(fmt.Errorf("Can't use certificate signing request with a new certificate"))
+	}
+
 	// Convert to the database type.
 	dbCert := dbCluster.Certificate{
 		Certificate: dbInfo.Certificate,
@@ -878,7 +976,7 @@ func doCertificateUpdate(d *Daemon, dbInfo api.Certificate, req api.CertificateP
 
 		certProjects = dbInfo.Projects
 
-		if req.Certificate != "" && dbInfo.Certificate != req.Certificate {
+		if (req.Certificate != "" && dbInfo.Certificate != req.Certificate) || req.CertificateSigningRequest != "" {
 			certBlock, _ := pem.Decode([]byte(dbInfo.Certificate))
 
 			oldCert, err := x509.ParseCertificate(certBlock.Bytes)
@@ -906,6 +1004,26 @@ func doCertificateUpdate(d *Daemon, dbInfo api.Certificate, req api.CertificateP
 		}
 	}
 
+	// Replace the certificate with one issued by the built-in certificate authority.
+	var issued *x509.Certificate
+	if req.CertificateSigningRequest != "" {
+		if reqDBType != certificate.TypeClient {
+			return response.BadRequest(fmt.Errorf("Certificate signing requests can only be used for client certificates"))
+		}
+
+		csr, err := certificate.ParseCertificateRequest(req.CertificateSigningRequest)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		issued, err = certificateAuthoritySign(r.Context(), s, csr)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		req.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: issued.Raw}))
+	}
+
 	if req.Certificate != "" && dbInfo.Certificate != req.Certificate {
 		// Add supplied certificate.
 		block, _ := pem.Decode([]byte(req.Certificate))
@@ -931,6 +1049,16 @@ func doCertificateUpdate(d *Daemon, dbInfo api.Certificate, req api.CertificateP
 		return response.SmartError(err)
 	}
 
+	// The replaced certificate must not be usable anymore if it was issued by the built-in certificate authority.
+	if dbCert.Certificate != dbInfo.Certificate {
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return certificateAuthorityRevoke(ctx, tx, dbInfo.Certificate)
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// Notify other cluster members to update their identity cache.
 	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
@@ -950,6 +1078,11 @@ func doCertificateUpdate(d *Daemon, dbInfo api.Certificate, req api.CertificateP
 
 	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.CertificateUpdated.Event(dbInfo.Fingerprint, request.CreateRequestor(r), nil))
 
+	// Return the issued certificate to the client.
+	if issued != nil {
+		return response.SyncResponse(true, certificateAuthorityIssued(issued))
+	}
+
 	return response.EmptySyncResponse
 }
 
@@ -1035,6 +1168,12 @@ func certificateDelete(d *Daemon, r *http.Request) response.Response {
 	}
 
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Revoke the certificate if it was issued by the built-in certificate authority.
+		err := certificateAuthorityRevoke(ctx, tx, certInfo.Certificate)
+		if err != nil {
+			return err
+		}
+
 		// Perform the delete with the expanded fingerprint.
 		return dbCluster.DeleteCertificate(ctx, tx.Tx(), certInfo.Fingerprint)
 	})
@@ -80,6 +80,12 @@ func (c *Config) TrustCACertificates() bool {
 	return c.m.GetBool("core.trust_ca_certificates")
 }
 
+// InternalCA returns whether client certificates can be issued by the built-in certificate authority and how long
+// they are valid for.
+func (c *Config) InternalCA() (enabled bool, certificateExpiry string) {
+	return c.m.GetBool("core.internal_ca"), c.m.GetString("core.internal_ca_certificate_expiry")
+}
+
 // ProxyHTTPS returns the configured HTTPS proxy, if any.
 func (c *Config) ProxyHTTPS() string {
 	return c.m.GetString("core.proxy_https")
@@ -147,6 +153,12 @@ func (c *Config) NetworkOVNSSL() (caCert string, clientCert string, clientKey st
 	return c.m.GetString("network.ovn.ca_cert"), c.m.GetString("network.ovn.client_cert"), c.m.GetString("network.ovn.client_key")
 }
 
+// NetworkProjectDefault returns the uplink network and the IPv4 range and prefix length to use for the default
+// networks of new projects.
+func (c *Config) NetworkProjectDefault() (uplink string, ipv4Pool string, ipv4Prefix int64) {
+	return c.m.GetString("network.project_default.uplink"), c.m.GetString("network.project_default.ipv4.pool"), c.m.GetInt64("network.project_default.ipv4.prefix")
+}
+
 // ShutdownTimeout returns the number of minutes to wait for running operation to complete
 // before LXD server shut down.
 func (c *Config) ShutdownTimeout() time.Duration {
@@ -174,11 +186,21 @@ func (c *Config) ImagesAutoUpdateIntervalHours() int64 {
 	return c.m.GetInt64("images.auto_update_interval")
 }
 
+// ImagesDownloadSegments returns the maximum number of parallel ranged requests used to download an image file.
+func (c *Config) ImagesDownloadSegments() int64 {
+	return c.m.GetInt64("images.download_segments")
+}
+
 // ImagesRemoteCacheExpiryDays returns the number of days after which an unused cached remote image will be flushed.
 func (c *Config) ImagesRemoteCacheExpiryDays() int64 {
 	return c.m.GetInt64("images.remote_cache_expiry")
 }
 
+// APIRequestLimits returns the maximum number of API requests per second for each project and identity, and the burst size.
+func (c *Config) APIRequestLimits() (project int64, identity int64, burst int64) {
+	return c.m.GetInt64("limits.api.requests.project"), c.m.GetInt64("limits.api.requests.identity"), c.m.GetInt64("limits.api.requests.burst")
+}
+
 // InstancesNICHostname returns hostname mode to use for instance NICs.
 func (c *Config) InstancesNICHostname() string {
 	return c.m.GetString("instances.nic.host_name")
@@ -217,6 +239,15 @@ func (c *Config) ClusterJoinTokenExpiry() string {
 	return c.m.GetString("cluster.join_token_expiry")
 }
 
+// AuditLog returns the sinks that mutating API requests are recorded to and how long to keep them in the API sink.
+func (c *Config) AuditLog() (sinks []string, retention string) {
+	if c.m.GetString("core.audit_sinks") != "" {
+		sinks = strings.Split(c.m.GetString("core.audit_sinks"), ",")
+	}
+
+	return sinks, c.m.GetString("core.audit_retention")
+}
+
 // RemoteTokenExpiry returns the time after which a remote add token expires.
 func (c *Config) RemoteTokenExpiry() string {
 	return c.m.GetString("core.remote_token_expiry")
@@ -397,6 +428,24 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to enforce authentication on the metrics endpoint
 	"core.metrics_authentication": {Type: config.Bool, Default: "true"},
 
+	// lxdmeta:generate(entities=server; group=core; key=core.audit_retention)
+	// Specify how long entries are kept in the `api` audit log sink, for example `30d` or `1y`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `30d`
+	//  shortdesc: How long to keep audit log entries
+	"core.audit_retention": {Type: config.String, Default: "30d", Validator: expiryValidator},
+
+	// lxdmeta:generate(entities=server; group=core; key=core.audit_sinks)
+	// Specify a comma-separated list of sinks that mutating API requests are recorded to.
+	// The sinks can be any combination of `file` (`audit.log` in the LXD log directory), `syslog`, and `api` (available through `/1.0/audit`).
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Sinks to record the audit log to
+	"core.audit_sinks": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("file", "syslog", "api")))},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.bgp_asn)
 	//
 	// ---
@@ -446,6 +495,25 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Trusted servers to provide the client's address
 	"core.https_trusted_proxy": {},
 
+	// lxdmeta:generate(entities=server; group=core; key=core.internal_ca)
+	// When enabled, clients can enroll through a certificate signing request that is signed by a certificate authority managed by LXD.
+	// See {ref}`authentication-internal-ca`.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to issue client certificates from the built-in certificate authority
+	"core.internal_ca": {Type: config.Bool, Default: "false"},
+
+	// lxdmeta:generate(entities=server; group=core; key=core.internal_ca_certificate_expiry)
+	// Specify the validity of the client certificates issued by the built-in certificate authority, for example `30d` or `1y`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `30d`
+	//  shortdesc: Validity of the issued client certificates
+	"core.internal_ca_certificate_expiry": {Type: config.String, Default: "30d", Validator: expiryValidator},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.proxy_http)
 	// If this option is not specified, LXD falls back to the `HTTP_PROXY` environment variable (if set).
 	// ---
@@ -534,6 +602,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Default architecture to use in a mixed-architecture cluster
 	"images.default_architecture": {Validator: validate.Optional(validate.IsArchitecture)},
 
+	// lxdmeta:generate(entities=server; group=images; key=images.download_segments)
+	// Large image files are split into up to this number of segments that are downloaded in parallel from simple streams servers that support ranged requests.
+	// To download image files over a single connection, set this option to `1`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `4`
+	//  shortdesc: Maximum number of parallel connections used to download an image file
+	"images.download_segments": {Type: config.Int64, Default: "4", Validator: validate.Optional(validate.IsInRange(1, 32))},
+
 	// lxdmeta:generate(entities=server; group=images; key=images.remote_cache_expiry)
 	// Specify the number of days after which the unused cached image expires.
 	// ---
@@ -572,6 +650,43 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to set `migration.stateful` to `true` for the instances
 	"instances.migration.stateful": {Type: config.Bool, Default: "false"},
 
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=limits.api.requests.burst)
+	// Specify the number of API requests that a project or identity can make in a burst before {config:option}`server-miscellaneous:limits.api.requests.project` and {config:option}`server-miscellaneous:limits.api.requests.identity` apply.
+	// If set to `0`, the burst size is equal to the per-second limit.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Number of API requests allowed in a burst
+	"limits.api.requests.burst": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=limits.api.requests.identity)
+	// Specify the maximum number of API requests per second that each identity can make.
+	// Requests over the limit are rejected with a `429 Too Many Requests` error that includes a `Retry-After` header.
+	// Requests made through the local Unix socket are not limited.
+	// The limit applies on each cluster member separately, so a cluster allows up to this number of requests per second on each of its members.
+	// If set to `0`, the number of requests is not limited.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Maximum number of API requests per second for each identity
+	"limits.api.requests.identity": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=limits.api.requests.project)
+	// Specify the maximum number of API requests per second that can target each project.
+	// Requests over the limit are rejected with a `429 Too Many Requests` error that includes a `Retry-After` header.
+	// Requests made through the local Unix socket are not limited.
+	// Only requests from identities that can view the project count toward its limit.
+	// The limit applies on each cluster member separately, so a cluster allows up to this number of requests per second on each of its members.
+	// If set to `0`, the number of requests is not limited.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Maximum number of API requests per second for each project
+	"limits.api.requests.project": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
 	// lxdmeta:generate(entities=server; group=loki; key=loki.auth.username)
 	//
 	// ---
@@ -734,6 +849,33 @@ var ConfigSchema = config.Schema{
 	//  defaultdesc: Content of `/etc/ovn/key_host` if present
 	//  shortdesc: OVN SSL client key
 	"network.ovn.client_key": {Default: ""},
+
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=network.project_default.uplink)
+	// When set, a default OVN network that uses this uplink network is created for each new project that has `features.networks` enabled.
+	// See {ref}`network-ovn-project-default` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Uplink network for the default network of new projects
+	"network.project_default.uplink": {},
+
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=network.project_default.ipv4.pool)
+	// The IPv4 subnet of the default network of a new project is taken from this range, so that it doesn't overlap with the subnets of other networks.
+	// If not set, a random subnet is used.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: IPv4 range (CIDR) for the subnets of the default networks of new projects
+	"network.project_default.ipv4.pool": {Validator: validate.Optional(validate.IsNetworkV4)},
+
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=network.project_default.ipv4.prefix)
+	//
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `24`
+	//  shortdesc: Prefix length of the subnets of the default networks of new projects
+	"network.project_default.ipv4.prefix": {Type: config.Int64, Default: "24", Validator: validate.Optional(validate.IsInRange(8, 30))},
 }
 
 func expiryValidator(value string) error {
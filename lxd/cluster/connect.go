@@ -78,6 +78,14 @@ func Connect(address string, networkCert *shared.CertInfo, serverCert *shared.Ce
 				}
 			}
 
+			ruleGroups, ok := ctx.Value(request.CtxIdentityProviderGroupRuleGroups).([]string)
+			if ok {
+				b, err := json.Marshal(ruleGroups)
+				if err == nil {
+					req.Header.Add(request.HeaderForwardedIdentityProviderGroupRuleGroups, string(b))
+				}
+			}
+
 			return shared.ProxyFromEnvironment(req)
 		}
 
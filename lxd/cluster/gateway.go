@@ -575,6 +575,39 @@ func (g *Gateway) Sync() {
 	}
 }
 
+// DumpLocal writes the content of the global database held by the local dqlite node to the given directory and
+// returns the path of the database file. Unlike queries, which are always run by the leader, this doesn't need
+// the cluster to be available, but the content may lag behind the one of the leader.
+func (g *Gateway) DumpLocal(ctx context.Context, dir string) (string, error) {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	if g.server == nil || (g.info.Role != db.RaftVoter && g.info.Role != db.RaftStandBy) {
+		return "", fmt.Errorf("This member doesn't hold a replica of the global database")
+	}
+
+	client, err := g.getClient()
+	if err != nil {
+		return "", fmt.Errorf("Failed to get client: %w", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	files, err := client.Dump(ctx, "db.bin")
+	if err != nil {
+		return "", fmt.Errorf("Failed to dump local database replica: %w", err)
+	}
+
+	for _, file := range files {
+		err := os.WriteFile(filepath.Join(dir, file.Name), file.Data, 0600)
+		if err != nil {
+			return "", fmt.Errorf("Failed to write database file %s: %w", file.Name, err)
+		}
+	}
+
+	return filepath.Join(dir, "db.bin"), nil
+}
+
 func (g *Gateway) getClient() (*client.Client, error) {
 	return client.New(context.Background(), g.bindAddress)
 }
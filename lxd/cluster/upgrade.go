@@ -128,6 +128,23 @@ func triggerUpdate() error {
 	return nil
 }
 
+// TriggerMemberUpgrade runs the LXD_CLUSTER_UPDATE executable to upgrade this member as part of a rolling upgrade.
+// It returns false if no update executable is set, in which case the member must be upgraded manually.
+func TriggerMemberUpgrade() (bool, error) {
+	updateExecutable := os.Getenv("LXD_CLUSTER_UPDATE")
+	if updateExecutable == "" {
+		return false, nil
+	}
+
+	logger.Info("Triggering cluster member upgrade", logger.Ctx{"updateExecutable": updateExecutable})
+	_, err := shared.RunCommand(updateExecutable)
+	if err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
 // UpgradeMembersWithoutRole assigns the Spare raft role to all cluster members that are not currently part of the
 // raft configuration. It's used for upgrading a cluster from a version without roles support.
 func UpgradeMembersWithoutRole(gateway *Gateway, members []db.NodeInfo) error {
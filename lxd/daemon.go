@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -28,6 +29,7 @@ import (
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/acme"
 	"github.com/canonical/lxd/lxd/apparmor"
+	"github.com/canonical/lxd/lxd/audit"
 	"github.com/canonical/lxd/lxd/auth"
 	authDrivers "github.com/canonical/lxd/lxd/auth/drivers"
 	"github.com/canonical/lxd/lxd/auth/oidc"
@@ -52,8 +54,10 @@ import (
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/loki"
 	"github.com/canonical/lxd/lxd/maas"
+	"github.com/canonical/lxd/lxd/metrics"
 	networkZone "github.com/canonical/lxd/lxd/network/zone"
 	"github.com/canonical/lxd/lxd/node"
+	"github.com/canonical/lxd/lxd/ratelimit"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/rsync"
@@ -104,6 +108,9 @@ type Daemon struct {
 	// Stores startup time of daemon
 	startTime time.Time
 
+	// Initialization progress and timings of the daemon subsystems.
+	startup *startupTracker
+
 	// Whether daemon was started by systemd socket activation.
 	systemdSocketActivated bool
 
@@ -153,6 +160,13 @@ type Daemon struct {
 
 	lokiClient *loki.Client
 
+	// Audit log of mutating API requests.
+	auditLogger *audit.Logger
+
+	// API request rate limiters, keyed by project and by identity.
+	apiProjectLimiter  *ratelimit.Limiter
+	apiIdentityLimiter *ratelimit.Limiter
+
 	// HTTP-01 challenge provider for ACME
 	http01Provider acme.HTTP01Provider
 
@@ -161,6 +175,9 @@ type Daemon struct {
 
 	// Syslog listener cancel function.
 	syslogSocketCancel context.CancelFunc
+
+	// Systemd inhibitor lock held while protected instances are running.
+	hostShutdownInhibitor hostShutdownInhibitor
 }
 
 // DaemonConfig holds configuration values for Daemon.
@@ -192,6 +209,10 @@ func newDaemon(config *DaemonConfig, os *sys.OS) *Daemon {
 		shutdownCtx:    shutdownCtx,
 		shutdownCancel: shutdownCancel,
 		shutdownDoneCh: make(chan error),
+		startup:        &startupTracker{},
+
+		apiProjectLimiter:  ratelimit.NewLimiter(),
+		apiIdentityLimiter: ratelimit.NewLimiter(),
 	}
 
 	d.serverCert = func() *shared.CertInfo { return d.serverCertInt }
@@ -303,11 +324,11 @@ func allowPermission(entityType entity.Type, entitlement auth.Entitlement, muxVa
 
 // Authenticate validates an incoming http Request
 // It will check over what protocol it came, what type of request it is and
-// will validate the TLS certificate or OIDC token.
+// will validate the TLS certificate, bearer token or OIDC token.
 //
 // This does not perform authorization, only validates authentication.
 // Returns whether trusted or not, the username (or certificate fingerprint) of the trusted client, and the type of
-// client that has been authenticated (cluster, unix, oidc or tls).
+// client that has been authenticated (cluster, unix, oidc, bearer or tls).
 func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (trusted bool, username string, method string, identityProviderGroups []string, err error) {
 	// Allow internal cluster traffic by checking against the trusted certfificates.
 	if r.TLS != nil {
@@ -353,6 +374,49 @@ func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (trusted b
 		return false, "", "", nil, fmt.Errorf("Bad/missing TLS on network query")
 	}
 
+	// Validate instance scoped tokens issued over devlxd. These are signed with the cluster certificate and bound to a
+	// nonce in the instance configuration.
+	claims, ok, err := identity.DevLXDTokenFromRequest(r, identity.DevLXDTokenKey(d.endpoints.NetworkCert().PrivateKey()))
+	if ok {
+		if err != nil {
+			return false, "", "", nil, fmt.Errorf("Failed devlxd token authentication: %w", err)
+		}
+
+		// Check the token against the current instance configuration to honour revocation.
+		inst, err := instance.LoadByProjectAndName(d.State(), claims.Project, claims.Instance)
+		if err != nil {
+			return false, "", "", nil, fmt.Errorf("Failed devlxd token authentication: %w", err)
+		}
+
+		err = claims.CheckInstance(inst.ExpandedConfig())
+		if err != nil {
+			return false, "", "", nil, fmt.Errorf("Failed devlxd token authentication: %w", err)
+		}
+
+		return true, claims.Username(), api.AuthenticationMethodDevLXD, nil, nil
+	}
+
+	// Validate temporary instance access tokens. These are stateless and signed with the cluster certificate.
+	accessClaims, ok, err := identity.InstanceAccessTokenFromRequest(r, identity.InstanceAccessTokenKey(d.endpoints.NetworkCert().PrivateKey()))
+	if ok {
+		if err != nil {
+			return false, "", "", nil, fmt.Errorf("Failed instance access token authentication: %w", err)
+		}
+
+		return true, accessClaims.Username(), api.AuthenticationMethodInstanceAccess, nil, nil
+	}
+
+	// Validate bearer tokens issued by LXD. These must be checked before OIDC as both use the Authorization header.
+	identifier, secret, ok := identity.BearerTokenFromRequest(r)
+	if ok {
+		id, err := d.identityCache.Get(api.AuthenticationMethodBearer, identifier)
+		if err != nil || !id.VerifyBearerToken(secret) {
+			return false, "", "", nil, fmt.Errorf("Failed bearer token authentication: Invalid or revoked token")
+		}
+
+		return true, identifier, api.AuthenticationMethodBearer, nil, nil
+	}
+
 	if d.oidcVerifier != nil && d.oidcVerifier.IsRequest(r) {
 		result, err := d.oidcVerifier.Auth(d.shutdownCtx, w, r)
 		if err != nil {
@@ -364,6 +428,12 @@ func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (trusted b
 			return false, "", "", nil, fmt.Errorf("Failed to process OIDC authentication result: %w", err)
 		}
 
+		// Resolve any groups granted by identity provider group rules matching the token claims.
+		ruleGroups := d.identityCache.GetIdentityProviderGroupRuleMapping(result.Claims)
+		if len(ruleGroups) > 0 {
+			request.SetCtxValue(r, request.CtxIdentityProviderGroupRuleGroups, ruleGroups)
+		}
+
 		return true, result.Email, api.AuthenticationMethodOIDC, result.IdentityProviderGroups, nil
 	}
 
@@ -538,12 +608,48 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 		uri = fmt.Sprintf("/%s", c.Path)
 	}
 
-	route := restAPI.HandleFunc(uri, func(w http.ResponseWriter, r *http.Request) {
+	route := restAPI.HandleFunc(uri, func(rw http.ResponseWriter, r *http.Request) {
+		// Record the request in the API request metrics once handled.
+		start := time.Now()
+		w := &metricsResponseWriter{ResponseWriter: rw}
+		identityType := "untrusted"
+
+		var trusted bool
+		var username string
+		var protocol string
+		var auditBody *auditBodyReader
+
+		defer func() {
+			req := metrics.APIRequest{
+				Endpoint:     uri,
+				Method:       r.Method,
+				Code:         w.code,
+				IdentityType: identityType,
+			}
+
+			if shared.IsFalseOrEmpty(request.QueryParam(r, "all-projects")) {
+				req.Project = request.ProjectParam(r)
+			}
+
+			if shared.ValueInSlice(strings.SplitN(c.Path, "/", 2)[0], []string{"instances", "containers", "virtual-machines"}) {
+				req.Instance = mux.Vars(r)["name"]
+			}
+
+			metrics.TrackAPIRequest(req, time.Since(start))
+
+			// Record mutating requests in the audit log. Requests forwarded by other cluster members are
+			// recorded by the member that received them from the client.
+			if trusted && version != "internal" && protocol != "cluster" && auditBody != nil {
+				d.recordAuditLogEntry(r, w.code, username, protocol, auditBody, start)
+			}
+		}()
+
 		w.Header().Set("Content-Type", "application/json")
 
-		if !(r.RemoteAddr == "@" && version == "internal") {
+		if !(r.RemoteAddr == "@" && (version == "internal" || c.Path == "startup")) {
 			// Block public API requests until we're done with basic
 			// initialization tasks, such setting up the cluster database.
+			// The startup report stays available locally to troubleshoot slow starts.
 			select {
 			case <-d.setupChan:
 			default:
@@ -554,7 +660,9 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 		}
 
 		// Authentication
-		trusted, username, protocol, identityProviderGroups, err := d.Authenticate(w, r)
+		var identityProviderGroups []string
+		var err error
+		trusted, username, protocol, identityProviderGroups, err = d.Authenticate(w, r)
 		if err != nil {
 			var authError oidc.AuthError
 			if errors.As(err, &authError) {
@@ -575,6 +683,7 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 
 		// Set the "trusted" value in the request context.
 		request.SetCtxValue(r, request.CtxTrusted, trusted)
+		identityType = d.apiRequestIdentityType(trusted, username, protocol)
 
 		// Reject internal queries to remote, non-cluster, clients
 		if version == "internal" && !shared.ValueInSlice(protocol, []string{"unix", "cluster"}) {
@@ -620,6 +729,17 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 						ctx = context.WithValue(ctx, request.CtxForwardedIdentityProviderGroups, forwardedIdentityProviderGroups)
 					}
 				}
+
+				forwardedRuleGroupsJSON := r.Header.Get(request.HeaderForwardedIdentityProviderGroupRuleGroups)
+				if forwardedRuleGroupsJSON != "" {
+					var forwardedRuleGroups []string
+					err = json.Unmarshal([]byte(forwardedRuleGroupsJSON), &forwardedRuleGroups)
+					if err != nil {
+						logger.Error("Failed unmarshalling identity provider group rule groups from forwarded request header", logger.Ctx{"err": err})
+					} else {
+						ctx = context.WithValue(ctx, request.CtxForwardedIdentityProviderGroupRuleGroups, forwardedRuleGroups)
+					}
+				}
 			}
 
 			r = r.WithContext(ctx)
@@ -635,6 +755,24 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 			return
 		}
 
+		// Enforce the API request limits. Local requests and requests forwarded by other cluster members
+		// aren't limited, the latter being accounted for by the member that received them from the client.
+		if trusted && version != "internal" && !shared.ValueInSlice(protocol, []string{"unix", "cluster"}) {
+			retryAfter, err := d.checkAPIRequestLimits(r, username, protocol)
+			if err != nil {
+				logger.Debug("Rejecting API request over limit", logger.Ctx{"url": r.URL.RequestURI(), "ip": r.RemoteAddr, "username": username, "err": err})
+				setRetryAfterHeader(w, retryAfter)
+				_ = response.SmartError(err).Render(w)
+				return
+			}
+		}
+
+		// Compute the digest of the request body as the handler reads it for the audit log.
+		if trusted && d.auditLogger != nil && d.auditLogger.Enabled() && !shared.ValueInSlice(r.Method, []string{http.MethodGet, http.MethodHead, http.MethodOptions}) {
+			auditBody = &auditBodyReader{ReadCloser: r.Body, digest: sha256.New()}
+			r.Body = auditBody
+		}
+
 		// Dump full request JSON when in debug mode
 		if daemon.Debug && r.Method != "GET" && util.IsJSONRequest(r) {
 			newBody := &bytes.Buffer{}
@@ -880,7 +1018,10 @@ func (d *Daemon) init() error {
 	trace := d.config.Trace
 
 	/* Initialize the operating system facade */
-	dbWarnings, err = d.os.Init()
+	err = d.startup.run("os", func() error {
+		dbWarnings, err = d.os.Init()
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -1066,7 +1207,7 @@ func (d *Daemon) init() error {
 	}
 
 	/* Initialize the database */
-	err = initializeDbObject(d)
+	err = d.startup.run("local-database", func() error { return initializeDbObject(d) })
 	if err != nil {
 		return err
 	}
@@ -1196,6 +1337,7 @@ func (d *Daemon) init() error {
 	db.StorageRemoteDriverNames = storageDrivers.RemoteDriverNames
 
 	/* Open the cluster database */
+	globalDatabaseDone := d.startup.begin("global-database", false)
 	for {
 		logger.Info("Initializing global database")
 		dir := filepath.Join(d.os.VarDir, "database")
@@ -1225,6 +1367,7 @@ func (d *Daemon) init() error {
 		d.db.Cluster, err = db.OpenCluster(context.Background(), "db.bin", store, localClusterAddress, dir, d.config.DqliteSetupTimeout, nil, options...)
 		if err == nil {
 			logger.Info("Initialized global database")
+			globalDatabaseDone(nil)
 			break
 		} else if errors.Is(err, db.ErrSomeNodesAreBehind) {
 			// If some other nodes have schema or API versions less recent
@@ -1250,6 +1393,7 @@ func (d *Daemon) init() error {
 			continue
 		}
 
+		globalDatabaseDone(err)
 		return fmt.Errorf("Failed to initialize global database: %w", err)
 	}
 
@@ -1355,7 +1499,7 @@ func (d *Daemon) init() error {
 
 	// Mount the storage pools.
 	logger.Infof("Initializing storage pools")
-	err = storageStartup(d.State())
+	err = d.startup.run("storage", func() error { return storageStartup(d.State()) })
 	if err != nil {
 		return err
 	}
@@ -1368,7 +1512,7 @@ func (d *Daemon) init() error {
 
 	// Mount any daemon storage volumes.
 	logger.Infof("Initializing daemon storage mounts")
-	err = daemonStorageMount(d.State())
+	err = d.startup.run("daemon-storage", func() error { return daemonStorageMount(d.State()) })
 	if err != nil {
 		return err
 	}
@@ -1429,9 +1573,11 @@ func (d *Daemon) init() error {
 	maasAPIURL, maasAPIKey = d.globalConfig.MAASController()
 	d.gateway.HeartbeatOfflineThreshold = d.globalConfig.OfflineThreshold()
 	lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes := d.globalConfig.LokiServer()
+	auditSinks, _ := d.globalConfig.AuditLog()
 	oidcIssuer, oidcClientID, oidcAudience, oidcGroupsClaim := d.globalConfig.OIDCServer()
 	syslogSocketEnabled := d.localConfig.SyslogSocket()
 	instancePlacementScriptlet := d.globalConfig.InstancesPlacementScriptlet()
+	apiProjectLimit, apiIdentityLimit, apiRequestBurst := d.globalConfig.APIRequestLimits()
 
 	d.endpoints.NetworkUpdateTrustedProxy(d.globalConfig.HTTPSTrustedProxy())
 	d.globalConfigMu.Unlock()
@@ -1444,6 +1590,21 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Setup API request limits.
+	d.configureAPIRequestLimits(apiProjectLimit, apiIdentityLimit, apiRequestBurst)
+
+	// Setup audit logger.
+	d.auditLogger = audit.NewLogger(d.shutdownCtx, func(ctx context.Context, entry api.AuditEntry) error {
+		return d.db.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return dbCluster.CreateAuditLogEntry(ctx, tx.Tx(), entry)
+		})
+	})
+
+	err = d.auditLogger.Configure(auditSinks)
+	if err != nil {
+		return err
+	}
+
 	if syslogSocketEnabled {
 		err = d.setupSyslogSocket(true)
 		if err != nil {
@@ -1513,7 +1674,7 @@ func (d *Daemon) init() error {
 
 	// Setup the networks.
 	logger.Infof("Initializing networks")
-	err = networkStartup(d.State())
+	err = d.startup.run("networks", func() error { return networkStartup(d.State()) })
 	if err != nil {
 		return err
 	}
@@ -1560,7 +1721,10 @@ func (d *Daemon) init() error {
 	}
 
 	// Cleanup leftover images.
-	pruneLeftoverImages(d.State())
+	_ = d.startup.run("images-cleanup", func() error {
+		pruneLeftoverImages(d.State())
+		return nil
+	})
 
 	var instances []instance.Instance
 
@@ -1581,15 +1745,22 @@ func (d *Daemon) init() error {
 		}
 
 		// Must occur after d.devmonitor has been initialised.
-		instances, err = instance.LoadNodeAll(d.State(), instancetype.Any)
+		err = d.startup.run("instances", func() error {
+			instances, err = instance.LoadNodeAll(d.State(), instancetype.Any)
+			if err != nil {
+				return fmt.Errorf("Failed loading local instances: %w", err)
+			}
+
+			// Register devices on running instances to receive events and reconnect to VM monitor sockets.
+			// This should come after the event handler go routines have been started.
+			devicesRegister(instances)
+
+			return nil
+		})
 		if err != nil {
-			return fmt.Errorf("Failed loading local instances: %w", err)
+			return err
 		}
 
-		// Register devices on running instances to receive events and reconnect to VM monitor sockets.
-		// This should come after the event handler go routines have been started.
-		devicesRegister(instances)
-
 		// Setup seccomp handler
 		if d.os.SeccompListener {
 			seccompServer, err := seccomp.NewSeccompServer(d.State(), shared.VarPath("seccomp.socket"), func(pid int32, state *state.State) (seccomp.Instance, error) {
@@ -1710,28 +1881,68 @@ func (d *Daemon) init() error {
 		// Remove resolved warnings (daily)
 		d.tasks.Add(pruneResolvedWarningsTask(d))
 
+		// Remove expired audit log entries (daily)
+		d.tasks.Add(pruneAuditLogTask(d))
+
 		// Auto-renew server certificate (daily)
 		d.tasks.Add(autoRenewCertificateTask(d))
 
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Remove orphaned operations, device processes and mounts (every 10 minutes)
+		d.tasks.Add(autoRemoveOrphanedResourcesTask(d))
+
+		// Reclaim unused memory from VMs (every minute)
+		d.tasks.Add(instancesUpdateMemoryBalloonsTask(d))
+
+		// Trim instance file systems (minutely check of configurable cron expression)
+		d.tasks.Add(instancesTrimTask(d))
 	}
 
 	// Start all background tasks
 	d.tasks.Start(d.shutdownCtx)
 
+	// The API is available at this point, so finish the slower subsystems in the background.
+	go d.initBackground(instances)
+
+	logger.Info("Daemon started")
+
+	return nil
+}
+
+// initBackground initializes the subsystems that don't need to be ready for the API to be available.
+// Requests that depend on those subsystems wait for d.waitReady.
+func (d *Daemon) initBackground(instances []instance.Instance) {
+	s := d.State()
+
 	// Restore instances
-	instancesStart(d.State(), instances)
+	autostartDone := d.startup.begin("instances-autostart", true)
+	instancesStart(s, instances)
+	autostartDone(nil)
+
+	// Block host shutdowns while protected instances are running.
+	d.internalListener.AddHandler("host-shutdown-inhibitor", func(event api.Event) {
+		d.hostShutdownInhibitor.handleEvent(d.State(), event)
+	})
+
+	d.hostShutdownInhibitor.refresh(s)
 
 	// Re-balance in case things changed while LXD was down
-	deviceTaskBalance(d.State())
+	balanceDone := d.startup.begin("devices-balance", true)
+	deviceTaskBalance(s, "startup")
+	balanceDone(nil)
 
 	// Unblock incoming requests
 	d.waitReady.Cancel()
 
-	logger.Info("Daemon started")
+	logger.Info("Daemon ready", logger.Ctx{"duration": time.Since(d.startTime)})
 
-	return nil
+	// Rejoin the cluster if this member was drained for a rolling upgrade.
+	// This needs the API to be available as the instances are migrated back from other members.
+	if s.ServerClustered {
+		clusterMemberUpgradeRejoin(s)
+	}
 }
 
 func (d *Daemon) startClusterTasks() {
@@ -1753,6 +1964,9 @@ func (d *Daemon) startClusterTasks() {
 	// Perform automatic evacuation for offline cluster members
 	d.clusterTasks.Add(autoHealClusterTask(d))
 
+	// Check for settings drift between cluster members (hourly)
+	d.clusterTasks.Add(clusterDriftTask(d))
+
 	// Start all background tasks
 	d.clusterTasks.Start(d.shutdownCtx)
 }
@@ -1793,6 +2007,9 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 
 	s := d.State()
 
+	// Release the host shutdown inhibitor lock.
+	d.hostShutdownInhibitor.stop()
+
 	// Stop any running minio processes cleanly before unmount storage pools.
 	miniod.StopAll()
 
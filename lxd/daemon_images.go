@@ -78,13 +78,14 @@ func ImageDownload(r *http.Request, s *state.State, op *operations.Operation, ar
 	fp := alias
 
 	// Attempt to resolve the alias
-	if shared.ValueInSlice(protocol, []string{"lxd", "simplestreams"}) {
+	if shared.ValueInSlice(protocol, []string{"lxd", "simplestreams", "oci"}) {
 		clientArgs := &lxd.ConnectionArgs{
-			TLSServerCert: args.Certificate,
-			UserAgent:     version.UserAgent,
-			Proxy:         s.Proxy,
-			CachePath:     s.OS.CacheDir,
-			CacheExpiry:   time.Hour,
+			TLSServerCert:    args.Certificate,
+			UserAgent:        version.UserAgent,
+			Proxy:            s.Proxy,
+			CachePath:        s.OS.CacheDir,
+			CacheExpiry:      time.Hour,
+			DownloadSegments: int(s.GlobalConfig.ImagesDownloadSegments()),
 		}
 
 		if protocol == "lxd" {
@@ -98,6 +99,12 @@ func ImageDownload(r *http.Request, s *state.State, op *operations.Operation, ar
 			if ok {
 				remote = server.UseProject(args.SourceProjectName)
 			}
+		} else if protocol == "oci" {
+			// Setup OCI registry client
+			remote, err = lxd.ConnectOCI(args.Server, clientArgs)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to connect to OCI registry %q: %w", args.Server, err)
+			}
 		} else {
 			// Setup simplestreams client
 			remote, err = lxd.ConnectSimpleStreams(args.Server, clientArgs)
@@ -356,7 +363,7 @@ func ImageDownload(r *http.Request, s *state.State, op *operations.Operation, ar
 		op.SetCanceler(canceler)
 	}
 
-	if protocol == "lxd" || protocol == "simplestreams" {
+	if shared.ValueInSlice(protocol, []string{"lxd", "simplestreams", "oci"}) {
 		// Create the target files
 		dest, err := os.Create(destName)
 		if err != nil {
@@ -457,6 +464,20 @@ func ImageDownload(r *http.Request, s *state.State, op *operations.Operation, ar
 		if err != nil {
 			return nil, err
 		}
+
+		// OCI images are converted while being downloaded, which records how to run them in the image properties.
+		if protocol == "oci" {
+			imageMeta, _, err := getImageMetadata(destName)
+			if err != nil {
+				return nil, err
+			}
+
+			for k, v := range imageMeta.Properties {
+				info.Properties[k] = v
+			}
+
+			info.Size = resp.MetaSize
+		}
 	} else if protocol == "direct" {
 		// Setup HTTP client
 		httpClient, err := util.HTTPClient(args.Certificate, s.Proxy)
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+var api10StartupCmd = APIEndpoint{
+	Path: "startup",
+
+	Get: APIEndpointAction{Handler: api10StartupGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewResources)},
+}
+
+// startupSubsystem records the initialization of a single daemon subsystem.
+type startupSubsystem struct {
+	name       string
+	background bool
+	startedAt  time.Time
+	duration   time.Duration
+	done       bool
+	err        error
+}
+
+// startupTracker records the initialization progress and timings of the daemon subsystems.
+type startupTracker struct {
+	mu         sync.Mutex
+	subsystems []*startupSubsystem
+}
+
+// begin marks the named subsystem as initializing and returns a function that must be called with the result
+// of its initialization.
+func (t *startupTracker) begin(name string, background bool) func(err error) {
+	sub := &startupSubsystem{
+		name:       name,
+		background: background,
+		startedAt:  time.Now(),
+	}
+
+	t.mu.Lock()
+	t.subsystems = append(t.subsystems, sub)
+	t.mu.Unlock()
+
+	return func(err error) {
+		t.mu.Lock()
+		sub.done = true
+		sub.duration = time.Since(sub.startedAt)
+		sub.err = err
+		t.mu.Unlock()
+
+		logger.Debug("Subsystem initialized", logger.Ctx{"subsystem": name, "duration": sub.duration, "err": err})
+	}
+}
+
+// run records the initialization of the named subsystem performed by f.
+func (t *startupTracker) run(name string, f func() error) error {
+	done := t.begin(name, false)
+	err := f()
+	done(err)
+
+	return err
+}
+
+// ready returns true if all recorded subsystems are initialized successfully.
+func (t *startupTracker) ready() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, sub := range t.subsystems {
+		if !sub.done || sub.err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// render returns the API representation of the recorded subsystems.
+func (t *startupTracker) render() []api.ServerStartupSubsystem {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	subsystems := make([]api.ServerStartupSubsystem, 0, len(t.subsystems))
+	for _, sub := range t.subsystems {
+		apiSub := api.ServerStartupSubsystem{
+			Name:       sub.name,
+			Status:     api.ServerStartupStatusReady,
+			Background: sub.background,
+			StartedAt:  sub.startedAt,
+			Duration:   sub.duration.Milliseconds(),
+		}
+
+		if !sub.done {
+			apiSub.Status = api.ServerStartupStatusInitializing
+			apiSub.Duration = time.Since(sub.startedAt).Milliseconds()
+		} else if sub.err != nil {
+			apiSub.Status = api.ServerStartupStatusFailed
+			apiSub.Error = sub.err.Error()
+		}
+
+		subsystems = append(subsystems, apiSub)
+	}
+
+	return subsystems
+}
+
+// swagger:operation GET /1.0/startup server startup_get
+//
+//	Get the daemon startup report
+//
+//	Gets the initialization status and timing of each of the daemon subsystems.
+//	Over the local unix socket, this is also available while the daemon is still starting up.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "200":
+//	    description: Startup report
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ServerStartup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func api10StartupGet(d *Daemon, r *http.Request) response.Response {
+	// Forwarding requires the cluster database, so only consider it once the basic setup is done.
+	select {
+	case <-d.setupChan:
+		resp := forwardedResponseIfTargetIsRemote(d.State(), r)
+		if resp != nil {
+			return resp
+		}
+
+	default:
+	}
+
+	startup := api.ServerStartup{
+		StartedAt:  d.startTime,
+		Ready:      d.waitReady.Err() != nil && d.startup.ready(),
+		Subsystems: d.startup.render(),
+	}
+
+	return response.SyncResponse(true, startup)
+}
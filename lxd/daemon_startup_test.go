@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestStartupTracker(t *testing.T) {
+	tracker := &startupTracker{}
+
+	err := tracker.run("storage", func() error { return nil })
+	assert.NoError(t, err)
+
+	err = tracker.run("networks", func() error { return errors.New("Failed starting network") })
+	assert.EqualError(t, err, "Failed starting network")
+
+	done := tracker.begin("instances-autostart", true)
+
+	subsystems := tracker.render()
+	assert.Len(t, subsystems, 3)
+	assert.Equal(t, "storage", subsystems[0].Name)
+	assert.Equal(t, api.ServerStartupStatusReady, subsystems[0].Status)
+	assert.Equal(t, api.ServerStartupStatusFailed, subsystems[1].Status)
+	assert.Equal(t, "Failed starting network", subsystems[1].Error)
+	assert.Equal(t, api.ServerStartupStatusInitializing, subsystems[2].Status)
+	assert.True(t, subsystems[2].Background)
+	assert.False(t, tracker.ready())
+
+	done(nil)
+	assert.Equal(t, api.ServerStartupStatusReady, tracker.render()[2].Status)
+}
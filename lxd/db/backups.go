@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/canonical/lxd/lxd/db/query"
@@ -157,6 +158,66 @@ WHERE projects.name=? AND instances.name=?`
 	return result, nil
 }
 
+// GetInstancesBackups returns the backups of the instances with the given IDs, keyed by instance ID.
+// The backups of all the instances are loaded with a single query.
+func (c *ClusterTx) GetInstancesBackups(ctx context.Context, instanceIDs ...int) (map[int][]InstanceBackup, error) {
+	result := make(map[int][]InstanceBackup, len(instanceIDs))
+	if len(instanceIDs) == 0 {
+		return result, nil
+	}
+
+	// Don't use query parameters for the IN statement to workaround an issue in Dqlite (apparently)
+	// that means that >255 query parameters causes partial result sets. See #10705
+	// This is safe as the inputs are ints.
+	var q strings.Builder
+
+	q.WriteString(`SELECT
+		instances_backups.id,
+		instances_backups.instance_id,
+		instances_backups.name,
+		instances_backups.creation_date,
+		instances_backups.expiry_date,
+		instances_backups.container_only,
+		instances_backups.optimized_storage
+	FROM instances_backups
+	WHERE instances_backups.instance_id IN (`)
+
+	q.Grow(len(instanceIDs) * 2) // We know the minimum length of the separators and integers.
+
+	for i, instanceID := range instanceIDs {
+		if i > 0 {
+			q.WriteString(",")
+		}
+
+		q.WriteString(fmt.Sprintf("%d", instanceID))
+	}
+
+	q.WriteString(`)
+	ORDER BY instances_backups.id`)
+
+	err := query.Scan(ctx, c.Tx(), q.String(), func(scan func(dest ...any) error) error {
+		var instanceOnlyInt, optimizedStorageInt int
+		args := InstanceBackup{}
+
+		err := scan(&args.ID, &args.InstanceID, &args.Name, &args.CreationDate, &args.ExpiryDate, &instanceOnlyInt, &optimizedStorageInt)
+		if err != nil {
+			return err
+		}
+
+		args.InstanceOnly = instanceOnlyInt == 1
+		args.OptimizedStorage = optimizedStorageInt == 1
+
+		result[args.InstanceID] = append(result[args.InstanceID], args)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance backups: %w", err)
+	}
+
+	return result, nil
+}
+
 // CreateInstanceBackup creates a new backup.
 func (c *ClusterTx) CreateInstanceBackup(ctx context.Context, args InstanceBackup) error {
 	_, err := c.getInstanceBackupID(ctx, args.Name)
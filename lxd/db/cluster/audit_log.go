@@ -0,0 +1,66 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// CreateAuditLogEntry adds an entry to the audit log.
+func CreateAuditLogEntry(ctx context.Context, tx *sql.Tx, entry api.AuditEntry) error {
+	stmt := `
+INSERT INTO audit_log (date, location, identity, authentication_method, project, method, entity_url, body_digest, status_code)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := tx.ExecContext(ctx, stmt, entry.Date.UTC(), entry.Location, entry.Identity, entry.AuthenticationMethod, entry.Project, entry.Method, entry.EntityURL, entry.BodyDigest, entry.StatusCode)
+	if err != nil {
+		return fmt.Errorf("Failed adding audit log entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditLogEntries returns the audit log entries recorded after the given time, oldest first.
+func GetAuditLogEntries(ctx context.Context, tx *sql.Tx, since time.Time) ([]api.AuditEntry, error) {
+	stmt := `
+SELECT date, location, identity, authentication_method, project, method, entity_url, body_digest, status_code
+FROM audit_log
+WHERE date > ?
+ORDER BY date, id`
+
+	entries := []api.AuditEntry{}
+	dest := func(scan func(dest ...any) error) error {
+		entry := api.AuditEntry{}
+		err := scan(&entry.Date, &entry.Location, &entry.Identity, &entry.AuthenticationMethod, &entry.Project, &entry.Method, &entry.EntityURL, &entry.BodyDigest, &entry.StatusCode)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting audit log entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteAuditLogEntriesBefore removes the audit log entries recorded before the given time.
+func DeleteAuditLogEntriesBefore(ctx context.Context, tx *sql.Tx, before time.Time) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM audit_log WHERE date < ?", before.UTC())
+	if err != nil {
+		return fmt.Errorf("Failed deleting audit log entries: %w", err)
+	}
+
+	return nil
+}
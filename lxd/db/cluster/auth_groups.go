@@ -85,6 +85,24 @@ func (g *AuthGroup) ToAPI(ctx context.Context, tx *sql.Tx, canViewIdentity auth.
 
 	group.Permissions = apiPermissions
 
+	groupRoles, err := GetAuthGroupRoles(ctx, tx, &g.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRoles, roleEntityURLs, err := GetAuthGroupRoleEntityURLs(ctx, tx, groupRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Roles = make([]api.AuthGroupRole, 0, len(groupRoles))
+	for _, groupRole := range groupRoles {
+		group.Roles = append(group.Roles, api.AuthGroupRole{
+			Role:            groupRole.RoleName,
+			EntityReference: roleEntityURLs[entity.Type(groupRole.EntityType)][groupRole.EntityID].String(),
+		})
+	}
+
 	identities, err := GetIdentitiesByAuthGroupID(ctx, tx, g.ID)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,262 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t auth_roles.mapper.go
+//go:generate mapper reset -i -b "//go:build linux && cgo && !agent"
+//
+//go:generate mapper stmt -e auth_role objects table=auth_roles
+//go:generate mapper stmt -e auth_role objects-by-ID table=auth_roles
+//go:generate mapper stmt -e auth_role objects-by-Name table=auth_roles
+//go:generate mapper stmt -e auth_role id table=auth_roles
+//go:generate mapper stmt -e auth_role create table=auth_roles
+//go:generate mapper stmt -e auth_role delete-by-Name table=auth_roles
+//go:generate mapper stmt -e auth_role update table=auth_roles
+//go:generate mapper stmt -e auth_role rename table=auth_roles
+//
+//go:generate mapper method -i -e auth_role GetMany
+//go:generate mapper method -i -e auth_role GetOne
+//go:generate mapper method -i -e auth_role ID
+//go:generate mapper method -i -e auth_role Exists
+//go:generate mapper method -i -e auth_role Create
+//go:generate mapper method -i -e auth_role DeleteOne-by-Name
+//go:generate mapper method -i -e auth_role Update
+//go:generate mapper method -i -e auth_role Rename
+
+// AuthRole is the database representation of an api.AuthRole.
+type AuthRole struct {
+	ID          int
+	Name        string `db:"primary=true"`
+	Description string
+	EntityType  EntityType
+}
+
+// AuthRoleFilter contains fields upon which an AuthRole can be filtered.
+type AuthRoleFilter struct {
+	ID   *int
+	Name *string
+}
+
+// AuthGroupRole is the database representation of an api.AuthGroupRole.
+type AuthGroupRole struct {
+	ID         int
+	GroupID    int
+	RoleID     int
+	RoleName   string
+	EntityType EntityType
+	EntityID   int
+}
+
+// ToAPI converts the AuthRole to an api.AuthRole, making extra database queries as necessary.
+func (r *AuthRole) ToAPI(ctx context.Context, tx *sql.Tx, canViewGroup auth.PermissionChecker) (*api.AuthRole, error) {
+	role := &api.AuthRole{
+		Name:        r.Name,
+		Description: r.Description,
+		EntityType:  string(r.EntityType),
+	}
+
+	entitlements, err := GetAuthRoleEntitlements(ctx, tx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Entitlements = make([]string, 0, len(entitlements))
+	for _, entitlement := range entitlements {
+		role.Entitlements = append(role.Entitlements, string(entitlement))
+	}
+
+	groups, err := GetAuthGroupsByAuthRoleID(ctx, tx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Groups = make([]string, 0, len(groups))
+	for _, group := range groups {
+		if canViewGroup(entity.AuthGroupURL(group.Name)) {
+			role.Groups = append(role.Groups, group.Name)
+		}
+	}
+
+	return role, nil
+}
+
+// GetAuthRoleEntitlements returns the entitlements granted by the role with the given ID.
+func GetAuthRoleEntitlements(ctx context.Context, tx *sql.Tx, roleID int) ([]auth.Entitlement, error) {
+	entitlements, err := query.SelectStrings(ctx, tx, `SELECT entitlement FROM auth_roles_entitlements WHERE auth_role_id = ? ORDER BY entitlement`, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get entitlements for the role with ID `%d`: %w", roleID, err)
+	}
+
+	result := make([]auth.Entitlement, 0, len(entitlements))
+	for _, entitlement := range entitlements {
+		result = append(result, auth.Entitlement(entitlement))
+	}
+
+	return result, nil
+}
+
+// GetAllAuthRoleEntitlements returns a map of role IDs to the entitlements granted by the role with that ID.
+func GetAllAuthRoleEntitlements(ctx context.Context, tx *sql.Tx) (map[int][]auth.Entitlement, error) {
+	result := make(map[int][]auth.Entitlement)
+	dest := func(scan func(dest ...any) error) error {
+		var roleID int
+		var entitlement auth.Entitlement
+		err := scan(&roleID, &entitlement)
+		if err != nil {
+			return err
+		}
+
+		result[roleID] = append(result[roleID], entitlement)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, `SELECT auth_role_id, entitlement FROM auth_roles_entitlements ORDER BY entitlement`, dest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get entitlements for all roles: %w", err)
+	}
+
+	return result, nil
+}
+
+// SetAuthRoleEntitlements deletes all entitlements of the role with the given ID from the `auth_roles_entitlements`
+// table. Then it inserts a new row for each given entitlement.
+func SetAuthRoleEntitlements(ctx context.Context, tx *sql.Tx, roleID int, entitlements []auth.Entitlement) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM auth_roles_entitlements WHERE auth_role_id = ?`, roleID)
+	if err != nil {
+		return fmt.Errorf("Failed to delete existing entitlements for role with ID `%d`: %w", roleID, err)
+	}
+
+	for _, entitlement := range entitlements {
+		_, err := tx.ExecContext(ctx, `INSERT INTO auth_roles_entitlements (auth_role_id, entitlement) VALUES (?, ?)`, roleID, entitlement)
+		if err != nil {
+			return fmt.Errorf("Failed to write role entitlements: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetAuthGroupsByAuthRoleID returns the groups that the role with the given ID is assigned to.
+func GetAuthGroupsByAuthRoleID(ctx context.Context, tx *sql.Tx, roleID int) ([]AuthGroup, error) {
+	stmt := `
+SELECT DISTINCT auth_groups.id, auth_groups.name, auth_groups.description
+FROM auth_groups
+JOIN auth_groups_roles ON auth_groups.id = auth_groups_roles.auth_group_id
+WHERE auth_groups_roles.auth_role_id = ?
+ORDER BY auth_groups.name`
+
+	var result []AuthGroup
+	dest := func(scan func(dest ...any) error) error {
+		g := AuthGroup{}
+		err := scan(&g.ID, &g.Name, &g.Description)
+		if err != nil {
+			return err
+		}
+
+		result = append(result, g)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get groups for the role with ID `%d`: %w", roleID, err)
+	}
+
+	return result, nil
+}
+
+// GetAuthGroupRoles returns the roles assigned to the group with the given ID, or to all groups if no ID is given.
+func GetAuthGroupRoles(ctx context.Context, tx *sql.Tx, groupID *int) ([]AuthGroupRole, error) {
+	stmt := `
+SELECT auth_groups_roles.id, auth_groups_roles.auth_group_id, auth_groups_roles.auth_role_id, auth_roles.name, auth_groups_roles.entity_type, auth_groups_roles.entity_id
+FROM auth_groups_roles
+JOIN auth_roles ON auth_groups_roles.auth_role_id = auth_roles.id`
+
+	var args []any
+	if groupID != nil {
+		stmt += `
+WHERE auth_groups_roles.auth_group_id = ?`
+		args = append(args, *groupID)
+	}
+
+	var result []AuthGroupRole
+	dest := func(scan func(dest ...any) error) error {
+		r := AuthGroupRole{}
+		err := scan(&r.ID, &r.GroupID, &r.RoleID, &r.RoleName, &r.EntityType, &r.EntityID)
+		if err != nil {
+			return err
+		}
+
+		result = append(result, r)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get group roles: %w", err)
+	}
+
+	return result, nil
+}
+
+// SetAuthGroupRoles deletes all role assignments of the group with the given ID from the `auth_groups_roles` table.
+// Then it inserts a new row for each given role assignment.
+func SetAuthGroupRoles(ctx context.Context, tx *sql.Tx, groupID int, groupRoles []AuthGroupRole) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM auth_groups_roles WHERE auth_group_id = ?`, groupID)
+	if err != nil {
+		return fmt.Errorf("Failed to delete existing roles for group with ID `%d`: %w", groupID, err)
+	}
+
+	for _, groupRole := range groupRoles {
+		_, err := tx.ExecContext(ctx, `INSERT INTO auth_groups_roles (auth_group_id, auth_role_id, entity_type, entity_id) VALUES (?, ?, ?, ?)`, groupID, groupRole.RoleID, groupRole.EntityType, groupRole.EntityID)
+		if err != nil {
+			return fmt.Errorf("Failed to write group roles: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetAuthGroupRoleEntityURLs returns the role assignments whose entity still exists, and a map of entity.Type, to
+// entity ID, to api.URL containing the URL of the entity of each returned assignment.
+func GetAuthGroupRoleEntityURLs(ctx context.Context, tx *sql.Tx, groupRoles []AuthGroupRole) ([]AuthGroupRole, map[entity.Type]map[int]*api.URL, error) {
+	// Role assignments reference entities in the same way as permissions, so reuse the permission lookup.
+	permissions := make([]Permission, 0, len(groupRoles))
+	for _, groupRole := range groupRoles {
+		permissions = append(permissions, Permission{
+			ID:         groupRole.ID,
+			GroupID:    groupRole.GroupID,
+			EntityType: groupRole.EntityType,
+			EntityID:   groupRole.EntityID,
+		})
+	}
+
+	_, entityURLs, err := GetPermissionEntityURLs(ctx, tx, permissions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validGroupRoles := make([]AuthGroupRole, 0, len(groupRoles))
+	for _, groupRole := range groupRoles {
+		_, ok := entityURLs[entity.Type(groupRole.EntityType)][groupRole.EntityID]
+		if ok {
+			validGroupRoles = append(validGroupRoles, groupRole)
+		}
+	}
+
+	return validGroupRoles, entityURLs, nil
+}
@@ -0,0 +1,43 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+)
+
+// AuthRoleGenerated is an interface of generated methods for AuthRole.
+type AuthRoleGenerated interface {
+	// GetAuthRoles returns all available auth_roles.
+	// generator: auth_role GetMany
+	GetAuthRoles(ctx context.Context, tx *sql.Tx, filters ...AuthRoleFilter) ([]AuthRole, error)
+
+	// GetAuthRole returns the auth_role with the given key.
+	// generator: auth_role GetOne
+	GetAuthRole(ctx context.Context, tx *sql.Tx, name string) (*AuthRole, error)
+
+	// GetAuthRoleID return the ID of the auth_role with the given key.
+	// generator: auth_role ID
+	GetAuthRoleID(ctx context.Context, tx *sql.Tx, name string) (int64, error)
+
+	// AuthRoleExists checks if a auth_role with the given key exists.
+	// generator: auth_role Exists
+	AuthRoleExists(ctx context.Context, tx *sql.Tx, name string) (bool, error)
+
+	// CreateAuthRole adds a new auth_role to the database.
+	// generator: auth_role Create
+	CreateAuthRole(ctx context.Context, tx *sql.Tx, object AuthRole) (int64, error)
+
+	// DeleteAuthRole deletes the auth_role matching the given key parameters.
+	// generator: auth_role DeleteOne-by-Name
+	DeleteAuthRole(ctx context.Context, tx *sql.Tx, name string) error
+
+	// UpdateAuthRole updates the auth_role matching the given key parameters.
+	// generator: auth_role Update
+	UpdateAuthRole(ctx context.Context, tx *sql.Tx, name string, object AuthRole) error
+
+	// RenameAuthRole renames the auth_role matching the given key parameters.
+	// generator: auth_role Rename
+	RenameAuthRole(ctx context.Context, tx *sql.Tx, name string, to string) error
+}
@@ -0,0 +1,200 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db/query"
+)
+
+// newAuthRolesTestTx returns a transaction on a database with the latest schema and the entity deletion triggers.
+func newAuthRolesTestTx(t *testing.T) *sql.Tx {
+	db, err := Schema().ExerciseUpdate(len(updates), nil)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = tx.Rollback() })
+
+	err = applyTriggers(context.Background(), tx)
+	require.NoError(t, err)
+
+	return tx
+}
+
+func insertAuthRolesTestRow(t *testing.T, tx *sql.Tx, stmt string, args ...any) int {
+	result, err := tx.Exec(stmt, args...)
+	require.NoError(t, err)
+
+	id, err := result.LastInsertId()
+	require.NoError(t, err)
+
+	return int(id)
+}
+
+func TestAuthRoles(t *testing.T) {
+	ctx := context.Background()
+	tx := newAuthRolesTestTx(t)
+
+	directGroupID := insertAuthRolesTestRow(t, tx, `INSERT INTO auth_groups (name, description) VALUES ('direct', '')`)
+	roleGroupID := insertAuthRolesTestRow(t, tx, `INSERT INTO auth_groups (name, description) VALUES ('role', '')`)
+	insertAuthRolesTestRow(t, tx, `INSERT INTO auth_groups (name, description) VALUES ('none', '')`)
+
+	p1 := insertAuthRolesTestRow(t, tx, `INSERT INTO projects (name, description) VALUES ('p1', '')`)
+	p2 := insertAuthRolesTestRow(t, tx, `INSERT INTO projects (name, description) VALUES ('p2', '')`)
+
+	viewerRoleID := insertAuthRolesTestRow(t, tx, `INSERT INTO auth_roles (name, description, entity_type) VALUES ('viewer', '', ?)`, EntityType("project"))
+	err := SetAuthRoleEntitlements(ctx, tx, viewerRoleID, []auth.Entitlement{auth.EntitlementCanView, auth.EntitlementCanViewInstances})
+	require.NoError(t, err)
+
+	entitlements, err := GetAuthRoleEntitlements(ctx, tx, viewerRoleID)
+	require.NoError(t, err)
+	assert.Equal(t, []auth.Entitlement{auth.EntitlementCanView, auth.EntitlementCanViewInstances}, entitlements)
+
+	// The direct group can view p2 through a permission.
+	err = SetAuthGroupPermissions(ctx, tx, directGroupID, []Permission{{GroupID: directGroupID, Entitlement: auth.EntitlementCanView, EntityType: EntityType("project"), EntityID: p2}})
+	require.NoError(t, err)
+
+	// The role group can view p1 through the role.
+	err = SetAuthGroupRoles(ctx, tx, roleGroupID, []AuthGroupRole{{RoleID: viewerRoleID, EntityType: EntityType("project"), EntityID: p1}})
+	require.NoError(t, err)
+
+	groupRoles, err := GetAuthGroupRoles(ctx, tx, &roleGroupID)
+	require.NoError(t, err)
+	require.Len(t, groupRoles, 1)
+	assert.Equal(t, "viewer", groupRoles[0].RoleName)
+	assert.Equal(t, p1, groupRoles[0].EntityID)
+
+	groups, err := GetAuthGroupsByAuthRoleID(ctx, tx, viewerRoleID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "role", groups[0].Name)
+
+	// Groups granted an entitlement on an entity, either directly or through a role.
+	groupNames, err := GetAuthGroupNamesWithEntitlement(ctx, tx, auth.EntitlementCanView, EntityType("project"), p1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"role"}, groupNames)
+
+	groupNames, err = GetAuthGroupNamesWithEntitlement(ctx, tx, auth.EntitlementCanViewInstances, EntityType("project"), p1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"role"}, groupNames)
+
+	groupNames, err = GetAuthGroupNamesWithEntitlement(ctx, tx, auth.EntitlementCanView, EntityType("project"), p2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"direct"}, groupNames)
+
+	groupNames, err = GetAuthGroupNamesWithEntitlement(ctx, tx, auth.EntitlementCanEdit, EntityType("project"), p1)
+	require.NoError(t, err)
+	assert.Empty(t, groupNames)
+
+	// Permissions of a group with an entitlement, either directly or through a role.
+	permissions, err := GetAuthGroupPermissionsByEntitlement(ctx, tx, "role", auth.EntitlementCanView, EntityType("project"))
+	require.NoError(t, err)
+	assert.Equal(t, []Permission{{Entitlement: auth.EntitlementCanView, EntityType: EntityType("project"), EntityID: p1}}, permissions)
+
+	permissions, err = GetAuthGroupPermissionsByEntitlement(ctx, tx, "direct", auth.EntitlementCanView, EntityType("project"))
+	require.NoError(t, err)
+	assert.Equal(t, []Permission{{Entitlement: auth.EntitlementCanView, EntityType: EntityType("project"), EntityID: p2}}, permissions)
+
+	permissions, err = GetAuthGroupPermissionsByEntitlement(ctx, tx, "none", auth.EntitlementCanView, EntityType("project"))
+	require.NoError(t, err)
+	assert.Empty(t, permissions)
+
+	// Effective permissions of a set of groups.
+	permissions, err = GetDistinctPermissionsByGroupNames(ctx, tx, []string{"direct", "role", "none"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Permission{
+		{Entitlement: auth.EntitlementCanView, EntityType: EntityType("project"), EntityID: p2},
+		{Entitlement: auth.EntitlementCanView, EntityType: EntityType("project"), EntityID: p1},
+		{Entitlement: auth.EntitlementCanViewInstances, EntityType: EntityType("project"), EntityID: p1},
+	}, permissions)
+
+	// A permission granted both directly and through a role is only returned once.
+	err = SetAuthGroupPermissions(ctx, tx, roleGroupID, []Permission{{GroupID: roleGroupID, Entitlement: auth.EntitlementCanView, EntityType: EntityType("project"), EntityID: p1}})
+	require.NoError(t, err)
+
+	permissions, err = GetDistinctPermissionsByGroupNames(ctx, tx, []string{"role"})
+	require.NoError(t, err)
+	assert.Len(t, permissions, 2)
+
+	groupNames, err = GetAuthGroupNamesWithEntitlement(ctx, tx, auth.EntitlementCanView, EntityType("project"), p1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"role"}, groupNames)
+
+	err = SetAuthGroupPermissions(ctx, tx, roleGroupID, nil)
+	require.NoError(t, err)
+
+	// Editing the role entitlements changes what the assignment grants.
+	err = SetAuthRoleEntitlements(ctx, tx, viewerRoleID, []auth.Entitlement{auth.EntitlementCanViewInstances})
+	require.NoError(t, err)
+
+	groupNames, err = GetAuthGroupNamesWithEntitlement(ctx, tx, auth.EntitlementCanView, EntityType("project"), p1)
+	require.NoError(t, err)
+	assert.Empty(t, groupNames)
+
+	groupNames, err = GetAuthGroupNamesWithEntitlement(ctx, tx, auth.EntitlementCanViewInstances, EntityType("project"), p1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"role"}, groupNames)
+}
+
+func TestAuthRolesCleanup(t *testing.T) {
+	ctx := context.Background()
+	tx := newAuthRolesTestTx(t)
+
+	countAssignments := func() int {
+		count, err := query.Count(ctx, tx, "auth_groups_roles", "")
+		require.NoError(t, err)
+		return count
+	}
+
+	groupID := insertAuthRolesTestRow(t, tx, `INSERT INTO auth_groups (name, description) VALUES ('group', '')`)
+	p1 := insertAuthRolesTestRow(t, tx, `INSERT INTO projects (name, description) VALUES ('p1', '')`)
+	p2 := insertAuthRolesTestRow(t, tx, `INSERT INTO projects (name, description) VALUES ('p2', '')`)
+	roleID := insertAuthRolesTestRow(t, tx, `INSERT INTO auth_roles (name, description, entity_type) VALUES ('viewer', '', ?)`, EntityType("project"))
+
+	err := SetAuthRoleEntitlements(ctx, tx, roleID, []auth.Entitlement{auth.EntitlementCanView})
+	require.NoError(t, err)
+
+	err = SetAuthGroupRoles(ctx, tx, groupID, []AuthGroupRole{
+		{RoleID: roleID, EntityType: EntityType("project"), EntityID: p1},
+		{RoleID: roleID, EntityType: EntityType("project"), EntityID: p2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, countAssignments())
+
+	// Deleting an entity removes the roles assigned on it.
+	_, err = tx.Exec(`DELETE FROM projects WHERE id = ?`, p1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, countAssignments())
+
+	groupNames, err := GetAuthGroupNamesWithEntitlement(ctx, tx, auth.EntitlementCanView, EntityType("project"), p1)
+	require.NoError(t, err)
+	assert.Empty(t, groupNames)
+
+	// Deleting a role removes its entitlements and assignments.
+	_, err = tx.Exec(`DELETE FROM auth_roles WHERE id = ?`, roleID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, countAssignments())
+
+	count, err := query.Count(ctx, tx, "auth_roles_entitlements", "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// Deleting a group removes its role assignments.
+	roleID = insertAuthRolesTestRow(t, tx, `INSERT INTO auth_roles (name, description, entity_type) VALUES ('viewer', '', ?)`, EntityType("project"))
+	err = SetAuthGroupRoles(ctx, tx, groupID, []AuthGroupRole{{RoleID: roleID, EntityType: EntityType("project"), EntityID: p2}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, countAssignments())
+
+	_, err = tx.Exec(`DELETE FROM auth_groups WHERE id = ?`, groupID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, countAssignments())
+}
@@ -0,0 +1,121 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// CertificateAuthority is the built-in certificate authority.
+// Its key is encrypted, so that it can't be used by anyone with access to the database only.
+type CertificateAuthority struct {
+	Certificate  string
+	EncryptedKey string
+}
+
+// CertificateAuthorityRevocation is a certificate issued by the built-in certificate authority that was revoked.
+type CertificateAuthorityRevocation struct {
+	SerialNumber string
+	RevokedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// GetCertificateAuthority returns the PEM encoded certificate and the encrypted key of the built-in certificate authority.
+// Returns an api.StatusError with code http.StatusNotFound if it wasn't generated yet.
+func GetCertificateAuthority(ctx context.Context, tx *sql.Tx) (*CertificateAuthority, error) {
+	ca := CertificateAuthority{}
+
+	err := tx.QueryRowContext(ctx, "SELECT certificate, encrypted_key FROM certificate_authority ORDER BY id LIMIT 1").Scan(&ca.Certificate, &ca.EncryptedKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Certificate authority not found")
+		}
+
+		return nil, fmt.Errorf("Failed getting certificate authority: %w", err)
+	}
+
+	return &ca, nil
+}
+
+// CreateCertificateAuthority stores the PEM encoded certificate and the encrypted key of the built-in certificate authority.
+func CreateCertificateAuthority(ctx context.Context, tx *sql.Tx, ca CertificateAuthority) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO certificate_authority (certificate, encrypted_key) VALUES (?, ?)", ca.Certificate, ca.EncryptedKey)
+	if err != nil {
+		return fmt.Errorf("Failed creating certificate authority: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateCertificateAuthorityKey replaces the encrypted key of the built-in certificate authority.
+func UpdateCertificateAuthorityKey(ctx context.Context, tx *sql.Tx, encryptedKey string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE certificate_authority SET encrypted_key = ?", encryptedKey)
+	if err != nil {
+		return fmt.Errorf("Failed updating certificate authority key: %w", err)
+	}
+
+	return nil
+}
+
+// CreateCertificateAuthorityRevocation records the revocation of a certificate issued by the built-in certificate authority.
+func CreateCertificateAuthorityRevocation(ctx context.Context, tx *sql.Tx, revocation CertificateAuthorityRevocation) error {
+	stmt := `
+INSERT INTO certificate_authority_revocations (serial_number, revoked_at, expires_at)
+VALUES (?, ?, ?)
+ON CONFLICT (serial_number) DO NOTHING`
+
+	_, err := tx.ExecContext(ctx, stmt, revocation.SerialNumber, revocation.RevokedAt.UTC(), revocation.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("Failed recording certificate revocation: %w", err)
+	}
+
+	return nil
+}
+
+// GetCertificateAuthorityRevocations returns the revoked certificates that haven't expired at the given time.
+func GetCertificateAuthorityRevocations(ctx context.Context, tx *sql.Tx, now time.Time) ([]CertificateAuthorityRevocation, error) {
+	stmt := `
+SELECT serial_number, revoked_at, expires_at
+FROM certificate_authority_revocations
+WHERE expires_at > ?
+ORDER BY revoked_at, id`
+
+	revocations := []CertificateAuthorityRevocation{}
+	dest := func(scan func(dest ...any) error) error {
+		revocation := CertificateAuthorityRevocation{}
+		err := scan(&revocation.SerialNumber, &revocation.RevokedAt, &revocation.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		revocations = append(revocations, revocation)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting certificate revocations: %w", err)
+	}
+
+	return revocations, nil
+}
+
+// DeleteExpiredCertificateAuthorityRevocations removes the revocations of certificates that expired before the given time.
+// Expired certificates are rejected anyway, so they don't need to be part of the revocation list.
+func DeleteExpiredCertificateAuthorityRevocations(ctx context.Context, tx *sql.Tx, before time.Time) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM certificate_authority_revocations WHERE expires_at < ?", before.UTC())
+	if err != nil {
+		return fmt.Errorf("Failed deleting expired certificate revocations: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t device_groups.mapper.go
+//go:generate mapper reset -i -b "//go:build linux && cgo && !agent"
+//
+//go:generate mapper stmt -e device_group objects table=device_groups
+//go:generate mapper stmt -e device_group objects-by-ID table=device_groups
+//go:generate mapper stmt -e device_group objects-by-Name table=device_groups
+//go:generate mapper stmt -e device_group objects-by-Project table=device_groups
+//go:generate mapper stmt -e device_group objects-by-Project-and-Name table=device_groups
+//go:generate mapper stmt -e device_group id table=device_groups
+//go:generate mapper stmt -e device_group create table=device_groups
+//go:generate mapper stmt -e device_group rename table=device_groups
+//go:generate mapper stmt -e device_group update table=device_groups
+//go:generate mapper stmt -e device_group delete-by-Project-and-Name table=device_groups
+//
+//go:generate mapper method -i -e device_group ID
+//go:generate mapper method -i -e device_group Exists
+//go:generate mapper method -i -e device_group GetMany
+//go:generate mapper method -i -e device_group GetOne
+//go:generate mapper method -i -e device_group Create
+//go:generate mapper method -i -e device_group Rename
+//go:generate mapper method -i -e device_group Update
+//go:generate mapper method -i -e device_group DeleteOne-by-Project-and-Name
+
+// DeviceGroup is a value object holding db-related details about a device group.
+type DeviceGroup struct {
+	ID          int
+	ProjectID   int    `db:"omit=create,update"`
+	Project     string `db:"primary=yes&join=projects.name"`
+	Name        string `db:"primary=yes"`
+	Description string `db:"coalesce=''"`
+}
+
+// DeviceGroupFilter specifies potential query parameter fields.
+type DeviceGroupFilter struct {
+	ID      *int
+	Project *string
+	Name    *string
+}
+
+// ToAPI returns a cluster DeviceGroup as an API struct.
+func (g *DeviceGroup) ToAPI(ctx context.Context, tx *sql.Tx) (*api.DeviceGroup, error) {
+	devices, err := GetDeviceGroupDevices(ctx, tx, g.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &api.DeviceGroup{
+		Name:        g.Name,
+		Description: g.Description,
+		Devices:     DevicesToAPI(devices),
+		Project:     g.Project,
+	}
+
+	return group, nil
+}
+
+// GetDeviceGroupDevices returns the devices of the device group with the given ID.
+func GetDeviceGroupDevices(ctx context.Context, tx *sql.Tx, deviceGroupID int) (map[string]Device, error) {
+	devices := map[string]Device{}
+	err := query.Scan(ctx, tx, "SELECT id, name, type FROM device_groups_devices WHERE device_group_id = ?", func(scan func(dest ...any) error) error {
+		device := Device{ReferenceID: deviceGroupID}
+		err := scan(&device.ID, &device.Name, &device.Type)
+		if err != nil {
+			return err
+		}
+
+		devices[device.Name] = device
+
+		return nil
+	}, deviceGroupID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"device_groups_devices\" table: %w", err)
+	}
+
+	for name, device := range devices {
+		device.Config, err = query.SelectConfig(ctx, tx, "device_groups_devices_config", "device_group_device_id = ?", device.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to fetch from \"device_groups_devices_config\" table: %w", err)
+		}
+
+		devices[name] = device
+	}
+
+	return devices, nil
+}
+
+// CreateDeviceGroupDevices adds new device group devices to the database.
+func CreateDeviceGroupDevices(ctx context.Context, tx *sql.Tx, deviceGroupID int64, devices map[string]Device) error {
+	for _, device := range devices {
+		result, err := tx.ExecContext(ctx, "INSERT INTO device_groups_devices (device_group_id, name, type) VALUES (?, ?, ?)", deviceGroupID, device.Name, device.Type)
+		if err != nil {
+			return fmt.Errorf("Insert failed for \"device_groups_devices\" table: %w", err)
+		}
+
+		deviceID, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("Failed to fetch ID: %w", err)
+		}
+
+		for key, value := range device.Config {
+			_, err := tx.ExecContext(ctx, "INSERT INTO device_groups_devices_config (device_group_device_id, key, value) VALUES (?, ?, ?)", deviceID, key, value)
+			if err != nil {
+				return fmt.Errorf("Insert failed for \"device_groups_devices_config\" table: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// UpdateDeviceGroupDevices replaces the devices of the device group with the given ID.
+func UpdateDeviceGroupDevices(ctx context.Context, tx *sql.Tx, deviceGroupID int64, devices map[string]Device) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM device_groups_devices WHERE device_group_id = ?", deviceGroupID)
+	if err != nil {
+		return fmt.Errorf("Delete failed for \"device_groups_devices\" table: %w", err)
+	}
+
+	return CreateDeviceGroupDevices(ctx, tx, deviceGroupID, devices)
+}
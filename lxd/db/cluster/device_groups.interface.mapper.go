@@ -0,0 +1,43 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DeviceGroupGenerated is an interface of generated methods for DeviceGroup.
+type DeviceGroupGenerated interface {
+	// GetDeviceGroupID return the ID of the device_group with the given key.
+	// generator: device_group ID
+	GetDeviceGroupID(ctx context.Context, tx *sql.Tx, project string, name string) (int64, error)
+
+	// DeviceGroupExists checks if a device_group with the given key exists.
+	// generator: device_group Exists
+	DeviceGroupExists(ctx context.Context, tx *sql.Tx, project string, name string) (bool, error)
+
+	// GetDeviceGroups returns all available device_groups.
+	// generator: device_group GetMany
+	GetDeviceGroups(ctx context.Context, tx *sql.Tx, filters ...DeviceGroupFilter) ([]DeviceGroup, error)
+
+	// GetDeviceGroup returns the device_group with the given key.
+	// generator: device_group GetOne
+	GetDeviceGroup(ctx context.Context, tx *sql.Tx, project string, name string) (*DeviceGroup, error)
+
+	// CreateDeviceGroup adds a new device_group to the database.
+	// generator: device_group Create
+	CreateDeviceGroup(ctx context.Context, tx *sql.Tx, object DeviceGroup) (int64, error)
+
+	// RenameDeviceGroup renames the device_group matching the given key parameters.
+	// generator: device_group Rename
+	RenameDeviceGroup(ctx context.Context, tx *sql.Tx, project string, name string, to string) error
+
+	// UpdateDeviceGroup updates the device_group matching the given key parameters.
+	// generator: device_group Update
+	UpdateDeviceGroup(ctx context.Context, tx *sql.Tx, project string, name string, object DeviceGroup) error
+
+	// DeleteDeviceGroup deletes the device_group matching the given key parameters.
+	// generator: device_group DeleteOne-by-Project-and-Name
+	DeleteDeviceGroup(ctx context.Context, tx *sql.Tx, project string, name string) error
+}
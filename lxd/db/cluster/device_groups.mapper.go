@@ -0,0 +1,457 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+// The code below was generated by lxd-generate - DO NOT EDIT!
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+var _ = api.ServerEnvironment{}
+
+var deviceGroupObjects = RegisterStmt(`
+SELECT device_groups.id, device_groups.project_id, projects.name AS project, device_groups.name, coalesce(device_groups.description, '')
+  FROM device_groups
+  JOIN projects ON device_groups.project_id = projects.id
+  ORDER BY projects.id, device_groups.name
+`)
+
+var deviceGroupObjectsByID = RegisterStmt(`
+SELECT device_groups.id, device_groups.project_id, projects.name AS project, device_groups.name, coalesce(device_groups.description, '')
+  FROM device_groups
+  JOIN projects ON device_groups.project_id = projects.id
+  WHERE ( device_groups.id = ? )
+  ORDER BY projects.id, device_groups.name
+`)
+
+var deviceGroupObjectsByName = RegisterStmt(`
+SELECT device_groups.id, device_groups.project_id, projects.name AS project, device_groups.name, coalesce(device_groups.description, '')
+  FROM device_groups
+  JOIN projects ON device_groups.project_id = projects.id
+  WHERE ( device_groups.name = ? )
+  ORDER BY projects.id, device_groups.name
+`)
+
+var deviceGroupObjectsByProject = RegisterStmt(`
+SELECT device_groups.id, device_groups.project_id, projects.name AS project, device_groups.name, coalesce(device_groups.description, '')
+  FROM device_groups
+  JOIN projects ON device_groups.project_id = projects.id
+  WHERE ( project = ? )
+  ORDER BY projects.id, device_groups.name
+`)
+
+var deviceGroupObjectsByProjectAndName = RegisterStmt(`
+SELECT device_groups.id, device_groups.project_id, projects.name AS project, device_groups.name, coalesce(device_groups.description, '')
+  FROM device_groups
+  JOIN projects ON device_groups.project_id = projects.id
+  WHERE ( project = ? AND device_groups.name = ? )
+  ORDER BY projects.id, device_groups.name
+`)
+
+var deviceGroupID = RegisterStmt(`
+SELECT device_groups.id FROM device_groups
+  JOIN projects ON device_groups.project_id = projects.id
+  WHERE projects.name = ? AND device_groups.name = ?
+`)
+
+var deviceGroupCreate = RegisterStmt(`
+INSERT INTO device_groups (project_id, name, description)
+  VALUES ((SELECT projects.id FROM projects WHERE projects.name = ?), ?, ?)
+`)
+
+var deviceGroupRename = RegisterStmt(`
+UPDATE device_groups SET name = ? WHERE project_id = (SELECT projects.id FROM projects WHERE projects.name = ?) AND name = ?
+`)
+
+var deviceGroupUpdate = RegisterStmt(`
+UPDATE device_groups
+  SET project_id = (SELECT projects.id FROM projects WHERE projects.name = ?), name = ?, description = ?
+ WHERE id = ?
+`)
+
+var deviceGroupDeleteByProjectAndName = RegisterStmt(`
+DELETE FROM device_groups WHERE project_id = (SELECT projects.id FROM projects WHERE projects.name = ?) AND name = ?
+`)
+
+// GetDeviceGroupID return the ID of the device_group with the given key.
+// generator: device_group ID
+func GetDeviceGroupID(ctx context.Context, tx *sql.Tx, project string, name string) (int64, error) {
+	stmt, err := Stmt(tx, deviceGroupID)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"deviceGroupID\" prepared statement: %w", err)
+	}
+
+	row := stmt.QueryRowContext(ctx, project, name)
+	var id int64
+	err = row.Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, api.StatusErrorf(http.StatusNotFound, "DeviceGroup not found")
+	}
+
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"devices_groups\" ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeviceGroupExists checks if a device_group with the given key exists.
+// generator: device_group Exists
+func DeviceGroupExists(ctx context.Context, tx *sql.Tx, project string, name string) (bool, error) {
+	_, err := GetDeviceGroupID(ctx, tx, project, name)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// deviceGroupColumns returns a string of column names to be used with a SELECT statement for the entity.
+// Use this function when building statements to retrieve database entries matching the DeviceGroup entity.
+func deviceGroupColumns() string {
+	return "devices_groups.id, devices_groups.project_id, projects.name AS project, devices_groups.name, coalesce(devices_groups.description, '')"
+}
+
+// getDeviceGroups can be used to run handwritten sql.Stmts to return a slice of objects.
+func getDeviceGroups(ctx context.Context, stmt *sql.Stmt, args ...any) ([]DeviceGroup, error) {
+	objects := make([]DeviceGroup, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		d := DeviceGroup{}
+		err := scan(&d.ID, &d.ProjectID, &d.Project, &d.Name, &d.Description)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, d)
+
+		return nil
+	}
+
+	err := query.SelectObjects(ctx, stmt, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"devices_groups\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// getDeviceGroupsRaw can be used to run handwritten query strings to return a slice of objects.
+func getDeviceGroupsRaw(ctx context.Context, tx *sql.Tx, sql string, args ...any) ([]DeviceGroup, error) {
+	objects := make([]DeviceGroup, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		d := DeviceGroup{}
+		err := scan(&d.ID, &d.ProjectID, &d.Project, &d.Name, &d.Description)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, d)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, sql, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"devices_groups\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// GetDeviceGroups returns all available device_groups.
+// generator: device_group GetMany
+func GetDeviceGroups(ctx context.Context, tx *sql.Tx, filters ...DeviceGroupFilter) ([]DeviceGroup, error) {
+	var err error
+
+	// Result slice.
+	objects := make([]DeviceGroup, 0)
+
+	// Pick the prepared statement and arguments to use based on active criteria.
+	var sqlStmt *sql.Stmt
+	args := []any{}
+	queryParts := [2]string{}
+
+	if len(filters) == 0 {
+		sqlStmt, err = Stmt(tx, deviceGroupObjects)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get \"deviceGroupObjects\" prepared statement: %w", err)
+		}
+	}
+
+	for i, filter := range filters {
+		if filter.Project != nil && filter.Name != nil && filter.ID == nil {
+			args = append(args, []any{filter.Project, filter.Name}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, deviceGroupObjectsByProjectAndName)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"deviceGroupObjectsByProjectAndName\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(deviceGroupObjectsByProjectAndName)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"deviceGroupObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.Project != nil && filter.ID == nil && filter.Name == nil {
+			args = append(args, []any{filter.Project}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, deviceGroupObjectsByProject)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"deviceGroupObjectsByProject\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(deviceGroupObjectsByProject)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"deviceGroupObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.Name != nil && filter.ID == nil && filter.Project == nil {
+			args = append(args, []any{filter.Name}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, deviceGroupObjectsByName)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"deviceGroupObjectsByName\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(deviceGroupObjectsByName)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"deviceGroupObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID != nil && filter.Project == nil && filter.Name == nil {
+			args = append(args, []any{filter.ID}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, deviceGroupObjectsByID)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"deviceGroupObjectsByID\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(deviceGroupObjectsByID)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"deviceGroupObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID == nil && filter.Project == nil && filter.Name == nil {
+			return nil, fmt.Errorf("Cannot filter on empty DeviceGroupFilter")
+		} else {
+			return nil, fmt.Errorf("No statement exists for the given Filter")
+		}
+	}
+
+	// Select.
+	if sqlStmt != nil {
+		objects, err = getDeviceGroups(ctx, sqlStmt, args...)
+	} else {
+		queryStr := strings.Join(queryParts[:], "ORDER BY")
+		objects, err = getDeviceGroupsRaw(ctx, tx, queryStr, args...)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"devices_groups\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// GetDeviceGroup returns the device_group with the given key.
+// generator: device_group GetOne
+func GetDeviceGroup(ctx context.Context, tx *sql.Tx, project string, name string) (*DeviceGroup, error) {
+	filter := DeviceGroupFilter{}
+	filter.Project = &project
+	filter.Name = &name
+
+	objects, err := GetDeviceGroups(ctx, tx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"devices_groups\" table: %w", err)
+	}
+
+	switch len(objects) {
+	case 0:
+		return nil, api.StatusErrorf(http.StatusNotFound, "DeviceGroup not found")
+	case 1:
+		return &objects[0], nil
+	default:
+		return nil, fmt.Errorf("More than one \"devices_groups\" entry matches")
+	}
+}
+
+// CreateDeviceGroup adds a new device_group to the database.
+// generator: device_group Create
+func CreateDeviceGroup(ctx context.Context, tx *sql.Tx, object DeviceGroup) (int64, error) {
+	// Check if a device_group with the same key exists.
+	exists, err := DeviceGroupExists(ctx, tx, object.Project, object.Name)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to check for duplicates: %w", err)
+	}
+
+	if exists {
+		return -1, api.StatusErrorf(http.StatusConflict, "This \"devices_groups\" entry already exists")
+	}
+
+	args := make([]any, 3)
+
+	// Populate the statement arguments.
+	args[0] = object.Project
+	args[1] = object.Name
+	args[2] = object.Description
+
+	// Prepared statement to use.
+	stmt, err := Stmt(tx, deviceGroupCreate)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"deviceGroupCreate\" prepared statement: %w", err)
+	}
+
+	// Execute the statement.
+	result, err := stmt.Exec(args...)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to create \"devices_groups\" entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Failed to fetch \"devices_groups\" entry ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// RenameDeviceGroup renames the device_group matching the given key parameters.
+// generator: device_group Rename
+func RenameDeviceGroup(ctx context.Context, tx *sql.Tx, project string, name string, to string) error {
+	stmt, err := Stmt(tx, deviceGroupRename)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"deviceGroupRename\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(to, project, name)
+	if err != nil {
+		return fmt.Errorf("Rename DeviceGroup failed: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows failed: %w", err)
+	}
+
+	if n != 1 {
+		return fmt.Errorf("Query affected %d rows instead of 1", n)
+	}
+
+	return nil
+}
+
+// UpdateDeviceGroup updates the device_group matching the given key parameters.
+// generator: device_group Update
+func UpdateDeviceGroup(ctx context.Context, tx *sql.Tx, project string, name string, object DeviceGroup) error {
+	id, err := GetDeviceGroupID(ctx, tx, project, name)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := Stmt(tx, deviceGroupUpdate)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"deviceGroupUpdate\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(object.Project, object.Name, object.Description, id)
+	if err != nil {
+		return fmt.Errorf("Update \"devices_groups\" entry failed: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n != 1 {
+		return fmt.Errorf("Query updated %d rows instead of 1", n)
+	}
+
+	return nil
+}
+
+// DeleteDeviceGroup deletes the device_group matching the given key parameters.
+// generator: device_group DeleteOne-by-Project-and-Name
+func DeleteDeviceGroup(ctx context.Context, tx *sql.Tx, project string, name string) error {
+	stmt, err := Stmt(tx, deviceGroupDeleteByProjectAndName)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"deviceGroupDeleteByProjectAndName\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(project, name)
+	if err != nil {
+		return fmt.Errorf("Delete \"devices_groups\": %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "DeviceGroup not found")
+	} else if n > 1 {
+		return fmt.Errorf("Query deleted %d DeviceGroup rows instead of 1", n)
+	}
+
+	return nil
+}
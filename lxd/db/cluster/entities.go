@@ -491,6 +491,7 @@ SELECT
 		CASE identities.auth_method
 			WHEN %d THEN '%s'
 			WHEN %d THEN '%s'
+			WHEN %d THEN '%s'
 		END,
 		identities.identifier
 	) 
@@ -499,6 +500,7 @@ FROM identities
 	entityTypeIdentity,
 	authMethodTLS, api.AuthenticationMethodTLS,
 	authMethodOIDC, api.AuthenticationMethodOIDC,
+	authMethodBearer, api.AuthenticationMethodBearer,
 )
 
 // identityEntityByID gets the entity of type entity.TypeIdentity with a particular ID.
@@ -1007,10 +1009,12 @@ WHERE '' = ?
 	AND CASE identities.auth_method 
 		WHEN %d THEN '%s' 
 		WHEN %d THEN '%s' 
+		WHEN %d THEN '%s' 
 	END = ? 
 	AND identities.identifier = ?
 `, authMethodTLS, api.AuthenticationMethodTLS,
-	authMethodOIDC, api.AuthenticationMethodOIDC)
+	authMethodOIDC, api.AuthenticationMethodOIDC,
+	authMethodBearer, api.AuthenticationMethodBearer)
 
 // identityIDFromURLStatements is a map of entity.Type to a statement that can be used to get the ID of the entity from its URL.
 var entityIDFromURLStatements = map[entity.Type]string{
@@ -1227,11 +1231,14 @@ CREATE TRIGGER on_image_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeImage, entityTypeImage)
+`, entityTypeImage, entityTypeImage, entityTypeImage)
 
 // profileDeletionTrigger deletes any permissions or warnings associated with a profile when it is deleted.
 var profileDeletionTrigger = fmt.Sprintf(`
@@ -1242,11 +1249,14 @@ CREATE TRIGGER on_profile_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeProfile, entityTypeProfile)
+`, entityTypeProfile, entityTypeProfile, entityTypeProfile)
 
 // projectDeletionTrigger deletes any permissions or warnings associated with a project when it is deleted.
 var projectDeletionTrigger = fmt.Sprintf(`
@@ -1257,11 +1267,14 @@ CREATE TRIGGER on_project_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeProject, entityTypeProject)
+`, entityTypeProject, entityTypeProject, entityTypeProject)
 
 // instanceDeletionTrigger deletes any permissions or warnings associated with an instance when it is deleted.
 var instanceDeletionTrigger = fmt.Sprintf(`
@@ -1272,11 +1285,14 @@ CREATE TRIGGER on_instance_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeInstance, entityTypeInstance)
+`, entityTypeInstance, entityTypeInstance, entityTypeInstance)
 
 // instanceBackupDeletionTrigger deletes any permissions or warnings associated with an instance backup when it is deleted.
 var instanceBackupDeletionTrigger = fmt.Sprintf(`
@@ -1287,11 +1303,14 @@ CREATE TRIGGER on_instance_backup_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeInstanceBackup, entityTypeInstanceBackup)
+`, entityTypeInstanceBackup, entityTypeInstanceBackup, entityTypeInstanceBackup)
 
 // instanceSnapshotDeletionTrigger deletes any permissions or warnings associated with an instance snapshot when it is deleted.
 var instanceSnapshotDeletionTrigger = fmt.Sprintf(`
@@ -1302,11 +1321,14 @@ CREATE TRIGGER on_instance_snaphot_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeInstanceSnapshot, entityTypeInstanceSnapshot)
+`, entityTypeInstanceSnapshot, entityTypeInstanceSnapshot, entityTypeInstanceSnapshot)
 
 // networkDeletionTrigger deletes any permissions or warnings associated with a network when it is deleted.
 var networkDeletionTrigger = fmt.Sprintf(`
@@ -1317,11 +1339,14 @@ CREATE TRIGGER on_network_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeNetwork, entityTypeNetwork)
+`, entityTypeNetwork, entityTypeNetwork, entityTypeNetwork)
 
 // networkACLDeletionTrigger deletes any permissions or warnings associated with a network ACL when it is deleted.
 var networkACLDeletionTrigger = fmt.Sprintf(`
@@ -1332,11 +1357,14 @@ CREATE TRIGGER on_network_acl_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeNetworkACL, entityTypeNetworkACL)
+`, entityTypeNetworkACL, entityTypeNetworkACL, entityTypeNetworkACL)
 
 // nodeDeletionTrigger deletes any permissions or warnings associated with a node when it is deleted.
 var nodeDeletionTrigger = fmt.Sprintf(`
@@ -1347,11 +1375,14 @@ CREATE TRIGGER on_node_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeNode, entityTypeNode)
+`, entityTypeNode, entityTypeNode, entityTypeNode)
 
 // operationDeletionTrigger deletes any permissions or warnings associated with an operation when it is deleted.
 var operationDeletionTrigger = fmt.Sprintf(`
@@ -1362,11 +1393,14 @@ CREATE TRIGGER on_operation_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeOperation, entityTypeOperation)
+`, entityTypeOperation, entityTypeOperation, entityTypeOperation)
 
 // storagePoolDeletionTrigger deletes any permissions or warnings associated with a storage pool when it is deleted.
 var storagePoolDeletionTrigger = fmt.Sprintf(`
@@ -1377,11 +1411,14 @@ CREATE TRIGGER on_storage_pool_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeStoragePool, entityTypeStoragePool)
+`, entityTypeStoragePool, entityTypeStoragePool, entityTypeStoragePool)
 
 // storageVolumeDeletionTrigger deletes any permissions or warnings associated with a storage volume when it is deleted.
 var storageVolumeDeletionTrigger = fmt.Sprintf(`
@@ -1392,11 +1429,14 @@ CREATE TRIGGER on_storage_volume_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeStorageVolume, entityTypeStorageVolume)
+`, entityTypeStorageVolume, entityTypeStorageVolume, entityTypeStorageVolume)
 
 // storageVolumeBackupDeletionTrigger deletes any permissions or warnings associated with a storage volume backup when it is deleted.
 var storageVolumeBackupDeletionTrigger = fmt.Sprintf(`
@@ -1407,11 +1447,14 @@ CREATE TRIGGER on_storage_volume_backup_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeStorageVolumeBackup, entityTypeStorageVolumeBackup)
+`, entityTypeStorageVolumeBackup, entityTypeStorageVolumeBackup, entityTypeStorageVolumeBackup)
 
 // storageVolumeSnapshotDeletionTrigger deletes any permissions or warnings associated with a storage volume snapshot when it is deleted.
 var storageVolumeSnapshotDeletionTrigger = fmt.Sprintf(`
@@ -1422,11 +1465,14 @@ CREATE TRIGGER on_storage_volume_snapshot_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeStorageVolumeSnapshot, entityTypeStorageVolumeSnapshot)
+`, entityTypeStorageVolumeSnapshot, entityTypeStorageVolumeSnapshot, entityTypeStorageVolumeSnapshot)
 
 // warningDeletionTrigger deletes any permissions associated with a warning when it is deleted.
 var warningDeletionTrigger = fmt.Sprintf(`
@@ -1437,8 +1483,11 @@ CREATE TRIGGER on_warning_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	END
-`, entityTypeWarning)
+`, entityTypeWarning, entityTypeWarning)
 
 // clusterGroupDeletionTrigger deletes any permissions or warnings associated with a cluster group when it is deleted.
 var clusterGroupDeletionTrigger = fmt.Sprintf(`
@@ -1449,11 +1498,14 @@ CREATE TRIGGER on_cluster_group_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeClusterGroup, entityTypeClusterGroup)
+`, entityTypeClusterGroup, entityTypeClusterGroup, entityTypeClusterGroup)
 
 // storageBucketDeletionTrigger deletes any permissions or warnings associated with a storage bucket when it is deleted.
 var storageBucketDeletionTrigger = fmt.Sprintf(`
@@ -1464,11 +1516,14 @@ CREATE TRIGGER on_storage_bucket_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeStorageBucket, entityTypeStorageBucket)
+`, entityTypeStorageBucket, entityTypeStorageBucket, entityTypeStorageBucket)
 
 // networkZoneDeletionTrigger deletes any permissions or warnings associated with a network zone when it is deleted.
 var networkZoneDeletionTrigger = fmt.Sprintf(`
@@ -1479,11 +1534,14 @@ CREATE TRIGGER on_network_zone_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeNetworkZone, entityTypeNetworkZone)
+`, entityTypeNetworkZone, entityTypeNetworkZone, entityTypeNetworkZone)
 
 // imageAliasDeletionTrigger deletes any permissions or warnings associated with an image alias when it is deleted.
 var imageAliasDeletionTrigger = fmt.Sprintf(`
@@ -1494,11 +1552,14 @@ CREATE TRIGGER on_image_alias_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeImageAlias, entityTypeImageAlias)
+`, entityTypeImageAlias, entityTypeImageAlias, entityTypeImageAlias)
 
 // authGroupDeletionTrigger deletes any warnings associated with an auth group when it is deleted. Permissions are
 // related to auth groups via foreign key and will have already been deleted.
@@ -1522,11 +1583,14 @@ CREATE TRIGGER on_identity_provider_group_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeIdentityProviderGroup, entityTypeIdentityProviderGroup)
+`, entityTypeIdentityProviderGroup, entityTypeIdentityProviderGroup, entityTypeIdentityProviderGroup)
 
 // identityDeletionTrigger deletes any permissions or warnings associated with an identity when it is deleted.
 var identityDeletionTrigger = fmt.Sprintf(`
@@ -1537,8 +1601,11 @@ CREATE TRIGGER on_identity_delete
 	DELETE FROM auth_groups_permissions 
 		WHERE entity_type = %d 
 		AND entity_id = OLD.id;
+	DELETE FROM auth_groups_roles
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
 	END
-`, entityTypeIdentity, entityTypeIdentity)
+`, entityTypeIdentity, entityTypeIdentity, entityTypeIdentity)
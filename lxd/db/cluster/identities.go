@@ -57,8 +57,9 @@ import (
 type AuthMethod string
 
 const (
-	authMethodTLS  int64 = 1
-	authMethodOIDC int64 = 2
+	authMethodTLS    int64 = 1
+	authMethodOIDC   int64 = 2
+	authMethodBearer int64 = 3
 )
 
 // Scan implements sql.Scanner for AuthMethod. This converts the integer value back into the correct API constant or
@@ -83,6 +84,8 @@ func (a *AuthMethod) Scan(value any) error {
 		*a = api.AuthenticationMethodTLS
 	case authMethodOIDC:
 		*a = api.AuthenticationMethodOIDC
+	case authMethodBearer:
+		*a = api.AuthenticationMethodBearer
 	default:
 		return fmt.Errorf("Unknown authentication method `%d`", authMethodInt)
 	}
@@ -97,6 +100,8 @@ func (a AuthMethod) Value() (driver.Value, error) {
 		return authMethodTLS, nil
 	case api.AuthenticationMethodOIDC:
 		return authMethodOIDC, nil
+	case api.AuthenticationMethodBearer:
+		return authMethodBearer, nil
 	}
 
 	return nil, fmt.Errorf("Invalid authentication method %q", a)
@@ -117,6 +122,7 @@ const (
 	identityTypeCertificateMetricsRestricted   int64 = 4
 	identityTypeOIDCClient                     int64 = 5
 	identityTypeCertificateMetricsUnrestricted int64 = 6
+	identityTypeBearerTokenClient              int64 = 7
 )
 
 // Scan implements sql.Scanner for IdentityType. This converts the integer value back into the correct API constant or
@@ -149,6 +155,8 @@ func (i *IdentityType) Scan(value any) error {
 		*i = api.IdentityTypeCertificateMetricsUnrestricted
 	case identityTypeOIDCClient:
 		*i = api.IdentityTypeOIDCClient
+	case identityTypeBearerTokenClient:
+		*i = api.IdentityTypeBearerTokenClient
 	default:
 		return fmt.Errorf("Unknown identity type `%d`", identityTypeInt)
 	}
@@ -171,6 +179,8 @@ func (i IdentityType) Value() (driver.Value, error) {
 		return identityTypeCertificateMetricsUnrestricted, nil
 	case api.IdentityTypeOIDCClient:
 		return identityTypeOIDCClient, nil
+	case api.IdentityTypeBearerTokenClient:
+		return identityTypeBearerTokenClient, nil
 	}
 
 	return nil, fmt.Errorf("Invalid identity type %q", i)
@@ -305,6 +315,27 @@ func (i Identity) Subject() (string, error) {
 	return metadata.Subject, nil
 }
 
+// BearerMetadata contains metadata for bearer token identities. Only the hash of the current token is stored.
+type BearerMetadata struct {
+	TokenHash string `json:"token_hash,omitempty"`
+}
+
+// BearerTokenHash returns the hash of the currently issued bearer token from the identity metadata. An empty string
+// is returned if no token is issued. The AuthMethod of the Identity must be api.AuthenticationMethodBearer.
+func (i Identity) BearerTokenHash() (string, error) {
+	if i.AuthMethod != api.AuthenticationMethodBearer {
+		return "", fmt.Errorf("Cannot extract bearer token hash from identity: Identity has authentication method %q (%q required)", i.AuthMethod, api.AuthenticationMethodBearer)
+	}
+
+	var metadata BearerMetadata
+	err := json.Unmarshal([]byte(i.Metadata), &metadata)
+	if err != nil {
+		return "", fmt.Errorf("Failed to unmarshal bearer identity metadata: %w", err)
+	}
+
+	return metadata.TokenHash, nil
+}
+
 // ToAPI converts an Identity to an api.Identity, executing database queries as necessary.
 func (i *Identity) ToAPI(ctx context.Context, tx *sql.Tx, canViewGroup auth.PermissionChecker) (*api.Identity, error) {
 	groups, err := GetAuthGroupsByIdentityID(ctx, tx, i.ID)
@@ -0,0 +1,197 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t identity_provider_group_rules.mapper.go
+//go:generate mapper reset -i -b "//go:build linux && cgo && !agent"
+//
+//go:generate mapper stmt -e identity_provider_group_rule objects table=identity_provider_group_rules
+//go:generate mapper stmt -e identity_provider_group_rule objects-by-ID table=identity_provider_group_rules
+//go:generate mapper stmt -e identity_provider_group_rule objects-by-Name table=identity_provider_group_rules
+//go:generate mapper stmt -e identity_provider_group_rule id table=identity_provider_group_rules
+//go:generate mapper stmt -e identity_provider_group_rule create table=identity_provider_group_rules
+//go:generate mapper stmt -e identity_provider_group_rule delete-by-Name table=identity_provider_group_rules
+//go:generate mapper stmt -e identity_provider_group_rule update table=identity_provider_group_rules
+//go:generate mapper stmt -e identity_provider_group_rule rename table=identity_provider_group_rules
+//
+//go:generate mapper method -i -e identity_provider_group_rule GetMany
+//go:generate mapper method -i -e identity_provider_group_rule GetOne
+//go:generate mapper method -i -e identity_provider_group_rule ID
+//go:generate mapper method -i -e identity_provider_group_rule Exists
+//go:generate mapper method -i -e identity_provider_group_rule Create
+//go:generate mapper method -i -e identity_provider_group_rule DeleteOne-by-Name
+//go:generate mapper method -i -e identity_provider_group_rule Update
+//go:generate mapper method -i -e identity_provider_group_rule Rename
+
+// IdentityProviderGroupRule is the database representation of an api.IdentityProviderGroupRule.
+type IdentityProviderGroupRule struct {
+	ID          int
+	Name        string `db:"primary=true"`
+	Description string
+}
+
+// IdentityProviderGroupRuleFilter contains the columns that queries for identity provider group rules can be filtered upon.
+type IdentityProviderGroupRuleFilter struct {
+	ID   *int
+	Name *string
+}
+
+// ToAPI converts the IdentityProviderGroupRule to an api.IdentityProviderGroupRule, making more database calls as necessary.
+func (i *IdentityProviderGroupRule) ToAPI(ctx context.Context, tx *sql.Tx, canViewGroup auth.PermissionChecker) (*api.IdentityProviderGroupRule, error) {
+	rule := &api.IdentityProviderGroupRule{
+		Name:        i.Name,
+		Description: i.Description,
+	}
+
+	conditions, err := GetIdentityProviderGroupRuleConditions(ctx, tx, i.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Conditions = conditions
+
+	groups, err := GetAuthGroupsByIdentityProviderGroupRuleID(ctx, tx, i.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Groups = make([]string, 0, len(groups))
+	for _, group := range groups {
+		if canViewGroup(entity.AuthGroupURL(group.Name)) {
+			rule.Groups = append(rule.Groups, group.Name)
+		}
+	}
+
+	return rule, nil
+}
+
+// GetIdentityProviderGroupRuleConditions returns the conditions of the identity provider group rule with the given ID.
+func GetIdentityProviderGroupRuleConditions(ctx context.Context, tx *sql.Tx, ruleID int) ([]api.IdentityProviderGroupRuleCondition, error) {
+	stmt := `
+SELECT claim, pattern
+FROM identity_provider_group_rules_conditions
+WHERE identity_provider_group_rule_id = ?
+ORDER BY id`
+
+	conditions := []api.IdentityProviderGroupRuleCondition{}
+	dest := func(scan func(dest ...any) error) error {
+		condition := api.IdentityProviderGroupRuleCondition{}
+		err := scan(&condition.Claim, &condition.Pattern)
+		if err != nil {
+			return err
+		}
+
+		conditions = append(conditions, condition)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get conditions for identity provider group rule with ID `%d`: %w", ruleID, err)
+	}
+
+	return conditions, nil
+}
+
+// SetIdentityProviderGroupRuleConditions deletes all conditions of the identity provider group rule with the given ID
+// from the `identity_provider_group_rules_conditions` table. Then it inserts a new row for each given condition.
+func SetIdentityProviderGroupRuleConditions(ctx context.Context, tx *sql.Tx, ruleID int, conditions []api.IdentityProviderGroupRuleCondition) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM identity_provider_group_rules_conditions WHERE identity_provider_group_rule_id = ?`, ruleID)
+	if err != nil {
+		return fmt.Errorf("Failed to delete existing identity provider group rule conditions: %w", err)
+	}
+
+	for _, condition := range conditions {
+		_, err := tx.ExecContext(ctx, `INSERT INTO identity_provider_group_rules_conditions (identity_provider_group_rule_id, claim, pattern) VALUES (?, ?, ?)`, ruleID, condition.Claim, condition.Pattern)
+		if err != nil {
+			return fmt.Errorf("Failed to write identity provider group rule conditions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetAuthGroupsByIdentityProviderGroupRuleID returns the groups that the identity provider group rule with the given
+// ID resolves to.
+func GetAuthGroupsByIdentityProviderGroupRuleID(ctx context.Context, tx *sql.Tx, ruleID int) ([]AuthGroup, error) {
+	stmt := `
+SELECT auth_groups.id, auth_groups.name, auth_groups.description
+FROM auth_groups_identity_provider_group_rules
+JOIN auth_groups ON auth_groups_identity_provider_group_rules.auth_group_id = auth_groups.id
+WHERE auth_groups_identity_provider_group_rules.identity_provider_group_rule_id = ?
+ORDER BY auth_groups.name`
+
+	var result []AuthGroup
+	dest := func(scan func(dest ...any) error) error {
+		g := AuthGroup{}
+		err := scan(&g.ID, &g.Name, &g.Description)
+		if err != nil {
+			return err
+		}
+
+		result = append(result, g)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get group mappings for identity provider group rule with ID `%d`: %w", ruleID, err)
+	}
+
+	return result, nil
+}
+
+// SetIdentityProviderGroupRuleMapping deletes all auth_group -> identity_provider_group_rule mappings from the
+// `auth_groups_identity_provider_group_rules` table where the rule ID is equal to the given value. Then it inserts new
+// associations into the table where the group IDs correspond to the given group names.
+func SetIdentityProviderGroupRuleMapping(ctx context.Context, tx *sql.Tx, ruleID int, groupNames []string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM auth_groups_identity_provider_group_rules WHERE identity_provider_group_rule_id = ?`, ruleID)
+	if err != nil {
+		return fmt.Errorf("Failed to delete existing identity provider group rule mappings: %w", err)
+	}
+
+	if len(groupNames) == 0 {
+		return nil
+	}
+
+	args := []any{ruleID}
+	for _, groupName := range groupNames {
+		args = append(args, groupName)
+	}
+
+	q := fmt.Sprintf(`
+INSERT INTO auth_groups_identity_provider_group_rules (auth_group_id, identity_provider_group_rule_id)
+SELECT auth_groups.id, ?
+FROM auth_groups
+WHERE auth_groups.name IN %s
+`, query.Params(len(groupNames)))
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("Failed to write identity provider group rule mappings: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Failed to check validity of identity provider group rule mapping creation: %w", err)
+	}
+
+	if int(rowsAffected) != len(groupNames) {
+		return fmt.Errorf("Failed to write expected number of rows to identity provider group rule association table (expected %d, got %d)", len(groupNames), rowsAffected)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+)
+
+// IdentityProviderGroupRuleGenerated is an interface of generated methods for IdentityProviderGroupRule.
+type IdentityProviderGroupRuleGenerated interface {
+	// GetIdentityProviderGroupRules returns all available identity_provider_group_rules.
+	// generator: identity_provider_group_rule GetMany
+	GetIdentityProviderGroupRules(ctx context.Context, tx *sql.Tx, filters ...IdentityProviderGroupRuleFilter) ([]IdentityProviderGroupRule, error)
+
+	// GetIdentityProviderGroupRule returns the identity_provider_group_rule with the given key.
+	// generator: identity_provider_group_rule GetOne
+	GetIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, name string) (*IdentityProviderGroupRule, error)
+
+	// GetIdentityProviderGroupRuleID return the ID of the identity_provider_group_rule with the given key.
+	// generator: identity_provider_group_rule ID
+	GetIdentityProviderGroupRuleID(ctx context.Context, tx *sql.Tx, name string) (int64, error)
+
+	// IdentityProviderGroupRuleExists checks if a identity_provider_group_rule with the given key exists.
+	// generator: identity_provider_group_rule Exists
+	IdentityProviderGroupRuleExists(ctx context.Context, tx *sql.Tx, name string) (bool, error)
+
+	// CreateIdentityProviderGroupRule adds a new identity_provider_group_rule to the database.
+	// generator: identity_provider_group_rule Create
+	CreateIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, object IdentityProviderGroupRule) (int64, error)
+
+	// DeleteIdentityProviderGroupRule deletes the identity_provider_group_rule matching the given key parameters.
+	// generator: identity_provider_group_rule DeleteOne-by-Name
+	DeleteIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, name string) error
+
+	// UpdateIdentityProviderGroupRule updates the identity_provider_group_rule matching the given key parameters.
+	// generator: identity_provider_group_rule Update
+	UpdateIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, name string, object IdentityProviderGroupRule) error
+
+	// RenameIdentityProviderGroupRule renames the identity_provider_group_rule matching the given key parameters.
+	// generator: identity_provider_group_rule Rename
+	RenameIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, name string, to string) error
+}
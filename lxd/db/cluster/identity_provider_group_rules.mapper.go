@@ -0,0 +1,387 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+// The code below was generated by lxd-generate - DO NOT EDIT!
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+var _ = api.ServerEnvironment{}
+
+var identityProviderGroupRuleObjects = RegisterStmt(`
+SELECT identity_provider_group_rules.id, identity_provider_group_rules.name, identity_provider_group_rules.description
+  FROM identity_provider_group_rules
+  ORDER BY identity_provider_group_rules.name
+`)
+
+var identityProviderGroupRuleObjectsByID = RegisterStmt(`
+SELECT identity_provider_group_rules.id, identity_provider_group_rules.name, identity_provider_group_rules.description
+  FROM identity_provider_group_rules
+  WHERE ( identity_provider_group_rules.id = ? )
+  ORDER BY identity_provider_group_rules.name
+`)
+
+var identityProviderGroupRuleObjectsByName = RegisterStmt(`
+SELECT identity_provider_group_rules.id, identity_provider_group_rules.name, identity_provider_group_rules.description
+  FROM identity_provider_group_rules
+  WHERE ( identity_provider_group_rules.name = ? )
+  ORDER BY identity_provider_group_rules.name
+`)
+
+var identityProviderGroupRuleID = RegisterStmt(`
+SELECT identity_provider_group_rules.id FROM identity_provider_group_rules
+  WHERE identity_provider_group_rules.name = ?
+`)
+
+var identityProviderGroupRuleCreate = RegisterStmt(`
+INSERT INTO identity_provider_group_rules (name, description)
+  VALUES (?, ?)
+`)
+
+var identityProviderGroupRuleDeleteByName = RegisterStmt(`
+DELETE FROM identity_provider_group_rules WHERE name = ?
+`)
+
+var identityProviderGroupRuleUpdate = RegisterStmt(`
+UPDATE identity_provider_group_rules
+  SET name = ?, description = ?
+ WHERE id = ?
+`)
+
+var identityProviderGroupRuleRename = RegisterStmt(`
+UPDATE identity_provider_group_rules SET name = ? WHERE name = ?
+`)
+
+// identityProviderGroupRuleColumns returns a string of column names to be used with a SELECT statement for the entity.
+// Use this function when building statements to retrieve database entries matching the IdentityProviderGroupRule entity.
+func identityProviderGroupRuleColumns() string {
+	return "identity_providers_groups_rules.id, identity_providers_groups_rules.name, identity_providers_groups_rules.description"
+}
+
+// getIdentityProviderGroupRules can be used to run handwritten sql.Stmts to return a slice of objects.
+func getIdentityProviderGroupRules(ctx context.Context, stmt *sql.Stmt, args ...any) ([]IdentityProviderGroupRule, error) {
+	objects := make([]IdentityProviderGroupRule, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		i := IdentityProviderGroupRule{}
+		err := scan(&i.ID, &i.Name, &i.Description)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, i)
+
+		return nil
+	}
+
+	err := query.SelectObjects(ctx, stmt, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"identity_providers_groups_rules\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// getIdentityProviderGroupRulesRaw can be used to run handwritten query strings to return a slice of objects.
+func getIdentityProviderGroupRulesRaw(ctx context.Context, tx *sql.Tx, sql string, args ...any) ([]IdentityProviderGroupRule, error) {
+	objects := make([]IdentityProviderGroupRule, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		i := IdentityProviderGroupRule{}
+		err := scan(&i.ID, &i.Name, &i.Description)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, i)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, sql, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"identity_providers_groups_rules\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// GetIdentityProviderGroupRules returns all available identity_provider_group_rules.
+// generator: identity_provider_group_rule GetMany
+func GetIdentityProviderGroupRules(ctx context.Context, tx *sql.Tx, filters ...IdentityProviderGroupRuleFilter) ([]IdentityProviderGroupRule, error) {
+	var err error
+
+	// Result slice.
+	objects := make([]IdentityProviderGroupRule, 0)
+
+	// Pick the prepared statement and arguments to use based on active criteria.
+	var sqlStmt *sql.Stmt
+	args := []any{}
+	queryParts := [2]string{}
+
+	if len(filters) == 0 {
+		sqlStmt, err = Stmt(tx, identityProviderGroupRuleObjects)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get \"identityProviderGroupRuleObjects\" prepared statement: %w", err)
+		}
+	}
+
+	for i, filter := range filters {
+		if filter.Name != nil && filter.ID == nil {
+			args = append(args, []any{filter.Name}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, identityProviderGroupRuleObjectsByName)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"identityProviderGroupRuleObjectsByName\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(identityProviderGroupRuleObjectsByName)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"identityProviderGroupRuleObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID != nil && filter.Name == nil {
+			args = append(args, []any{filter.ID}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, identityProviderGroupRuleObjectsByID)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"identityProviderGroupRuleObjectsByID\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(identityProviderGroupRuleObjectsByID)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"identityProviderGroupRuleObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID == nil && filter.Name == nil {
+			return nil, fmt.Errorf("Cannot filter on empty IdentityProviderGroupRuleFilter")
+		} else {
+			return nil, fmt.Errorf("No statement exists for the given Filter")
+		}
+	}
+
+	// Select.
+	if sqlStmt != nil {
+		objects, err = getIdentityProviderGroupRules(ctx, sqlStmt, args...)
+	} else {
+		queryStr := strings.Join(queryParts[:], "ORDER BY")
+		objects, err = getIdentityProviderGroupRulesRaw(ctx, tx, queryStr, args...)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"identity_providers_groups_rules\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// GetIdentityProviderGroupRule returns the identity_provider_group_rule with the given key.
+// generator: identity_provider_group_rule GetOne
+func GetIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, name string) (*IdentityProviderGroupRule, error) {
+	filter := IdentityProviderGroupRuleFilter{}
+	filter.Name = &name
+
+	objects, err := GetIdentityProviderGroupRules(ctx, tx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"identity_providers_groups_rules\" table: %w", err)
+	}
+
+	switch len(objects) {
+	case 0:
+		return nil, api.StatusErrorf(http.StatusNotFound, "IdentityProviderGroupRule not found")
+	case 1:
+		return &objects[0], nil
+	default:
+		return nil, fmt.Errorf("More than one \"identity_providers_groups_rules\" entry matches")
+	}
+}
+
+// GetIdentityProviderGroupRuleID return the ID of the identity_provider_group_rule with the given key.
+// generator: identity_provider_group_rule ID
+func GetIdentityProviderGroupRuleID(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	stmt, err := Stmt(tx, identityProviderGroupRuleID)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"identityProviderGroupRuleID\" prepared statement: %w", err)
+	}
+
+	row := stmt.QueryRowContext(ctx, name)
+	var id int64
+	err = row.Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, api.StatusErrorf(http.StatusNotFound, "IdentityProviderGroupRule not found")
+	}
+
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"identity_providers_groups_rules\" ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// IdentityProviderGroupRuleExists checks if a identity_provider_group_rule with the given key exists.
+// generator: identity_provider_group_rule Exists
+func IdentityProviderGroupRuleExists(ctx context.Context, tx *sql.Tx, name string) (bool, error) {
+	_, err := GetIdentityProviderGroupRuleID(ctx, tx, name)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CreateIdentityProviderGroupRule adds a new identity_provider_group_rule to the database.
+// generator: identity_provider_group_rule Create
+func CreateIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, object IdentityProviderGroupRule) (int64, error) {
+	// Check if a identity_provider_group_rule with the same key exists.
+	exists, err := IdentityProviderGroupRuleExists(ctx, tx, object.Name)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to check for duplicates: %w", err)
+	}
+
+	if exists {
+		return -1, api.StatusErrorf(http.StatusConflict, "This \"identity_providers_groups_rules\" entry already exists")
+	}
+
+	args := make([]any, 2)
+
+	// Populate the statement arguments.
+	args[0] = object.Name
+	args[1] = object.Description
+
+	// Prepared statement to use.
+	stmt, err := Stmt(tx, identityProviderGroupRuleCreate)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"identityProviderGroupRuleCreate\" prepared statement: %w", err)
+	}
+
+	// Execute the statement.
+	result, err := stmt.Exec(args...)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to create \"identity_providers_groups_rules\" entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Failed to fetch \"identity_providers_groups_rules\" entry ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteIdentityProviderGroupRule deletes the identity_provider_group_rule matching the given key parameters.
+// generator: identity_provider_group_rule DeleteOne-by-Name
+func DeleteIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, name string) error {
+	stmt, err := Stmt(tx, identityProviderGroupRuleDeleteByName)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"identityProviderGroupRuleDeleteByName\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(name)
+	if err != nil {
+		return fmt.Errorf("Delete \"identity_providers_groups_rules\": %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "IdentityProviderGroupRule not found")
+	} else if n > 1 {
+		return fmt.Errorf("Query deleted %d IdentityProviderGroupRule rows instead of 1", n)
+	}
+
+	return nil
+}
+
+// UpdateIdentityProviderGroupRule updates the identity_provider_group_rule matching the given key parameters.
+// generator: identity_provider_group_rule Update
+func UpdateIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, name string, object IdentityProviderGroupRule) error {
+	id, err := GetIdentityProviderGroupRuleID(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := Stmt(tx, identityProviderGroupRuleUpdate)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"identityProviderGroupRuleUpdate\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(object.Name, object.Description, id)
+	if err != nil {
+		return fmt.Errorf("Update \"identity_providers_groups_rules\" entry failed: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n != 1 {
+		return fmt.Errorf("Query updated %d rows instead of 1", n)
+	}
+
+	return nil
+}
+
+// RenameIdentityProviderGroupRule renames the identity_provider_group_rule matching the given key parameters.
+// generator: identity_provider_group_rule Rename
+func RenameIdentityProviderGroupRule(ctx context.Context, tx *sql.Tx, name string, to string) error {
+	stmt, err := Stmt(tx, identityProviderGroupRuleRename)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"identityProviderGroupRuleRename\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(to, name)
+	if err != nil {
+		return fmt.Errorf("Rename IdentityProviderGroupRule failed: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows failed: %w", err)
+	}
+
+	if n != 1 {
+		return fmt.Errorf("Query affected %d rows instead of 1", n)
+	}
+
+	return nil
+}
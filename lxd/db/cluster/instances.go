@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/canonical/lxd/lxd/db/query"
 	"github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/shared/api"
@@ -133,3 +134,24 @@ func (i *Instance) ToAPI(ctx context.Context, tx *sql.Tx, globalConfig map[strin
 		Project:         i.Project,
 	}, nil
 }
+
+// GetInstancesByConfigKey returns all instances, in any project, that have the given configuration key set.
+func GetInstancesByConfigKey(ctx context.Context, tx *sql.Tx, key string) ([]Instance, error) {
+	ids, err := query.SelectIntegers(ctx, tx, "SELECT instance_id FROM instances_config WHERE key = ?", key)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]Instance, 0, len(ids))
+	for _, id := range ids {
+		instanceID := id
+		result, err := GetInstances(ctx, tx, InstanceFilter{ID: &instanceID})
+		if err != nil {
+			return nil, err
+		}
+
+		instances = append(instances, result...)
+	}
+
+	return instances, nil
+}
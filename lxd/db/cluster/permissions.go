@@ -114,7 +114,8 @@ func GetPermissionEntityURLs(ctx context.Context, tx *sql.Tx, permissions []Perm
 	return validPermissions, entityURLs, nil
 }
 
-// GetDistinctPermissionsByGroupNames gets all distinct permissions that the groups with the given names have been granted.
+// GetDistinctPermissionsByGroupNames gets all distinct permissions that the groups with the given names have been granted,
+// either directly or via the roles assigned to them.
 func GetDistinctPermissionsByGroupNames(ctx context.Context, tx *sql.Tx, groupNames []string) ([]Permission, error) {
 	if len(groupNames) == 0 {
 		return nil, nil
@@ -125,11 +126,20 @@ func GetDistinctPermissionsByGroupNames(ctx context.Context, tx *sql.Tx, groupNa
 		args = append(args, effectiveGroup)
 	}
 
+	// The group names are used by both parts of the query.
+	args = append(args, args...)
+
 	q := fmt.Sprintf(`
 SELECT DISTINCT auth_groups_permissions.entitlement, auth_groups_permissions.entity_type, auth_groups_permissions.entity_id
 FROM auth_groups_permissions
 JOIN auth_groups ON auth_groups_permissions.auth_group_id = auth_groups.id
-WHERE auth_groups.name IN %s`, query.Params(len(groupNames)))
+WHERE auth_groups.name IN %s
+UNION
+SELECT auth_roles_entitlements.entitlement, auth_groups_roles.entity_type, auth_groups_roles.entity_id
+FROM auth_groups_roles
+JOIN auth_roles_entitlements ON auth_groups_roles.auth_role_id = auth_roles_entitlements.auth_role_id
+JOIN auth_groups ON auth_groups_roles.auth_group_id = auth_groups.id
+WHERE auth_groups.name IN %s`, query.Params(len(groupNames)), query.Params(len(groupNames)))
 
 	rows, err := tx.QueryContext(ctx, q, args...)
 	if err != nil {
@@ -149,3 +159,62 @@ WHERE auth_groups.name IN %s`, query.Params(len(groupNames)))
 
 	return permissions, nil
 }
+
+// GetAuthGroupNamesWithEntitlement returns the names of the groups that have been granted the entitlement on the
+// entity with the given type and ID, either directly or via a role assigned to them on the entity.
+func GetAuthGroupNamesWithEntitlement(ctx context.Context, tx *sql.Tx, entitlement auth.Entitlement, entityType EntityType, entityID int) ([]string, error) {
+	q := `
+SELECT auth_groups.name
+FROM auth_groups_permissions
+JOIN auth_groups ON auth_groups_permissions.auth_group_id = auth_groups.id
+WHERE auth_groups_permissions.entitlement = ? AND auth_groups_permissions.entity_type = ? AND auth_groups_permissions.entity_id = ?
+UNION
+SELECT auth_groups.name
+FROM auth_groups_roles
+JOIN auth_roles_entitlements ON auth_groups_roles.auth_role_id = auth_roles_entitlements.auth_role_id
+JOIN auth_groups ON auth_groups_roles.auth_group_id = auth_groups.id
+WHERE auth_roles_entitlements.entitlement = ? AND auth_groups_roles.entity_type = ? AND auth_groups_roles.entity_id = ?
+`
+	groupNames, err := query.SelectStrings(ctx, tx, q, entitlement, entityType, entityID, entitlement, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get groups with entitlement %q: %w", entitlement, err)
+	}
+
+	return groupNames, nil
+}
+
+// GetAuthGroupPermissionsByEntitlement returns the permissions with the given entitlement on entities of the given
+// type that the group with the given name has been granted, either directly or via the roles assigned to it.
+func GetAuthGroupPermissionsByEntitlement(ctx context.Context, tx *sql.Tx, groupName string, entitlement auth.Entitlement, entityType EntityType) ([]Permission, error) {
+	q := `
+SELECT auth_groups_permissions.entity_type, auth_groups_permissions.entity_id, auth_groups_permissions.entitlement
+FROM auth_groups_permissions
+JOIN auth_groups ON auth_groups_permissions.auth_group_id = auth_groups.id
+WHERE auth_groups_permissions.entitlement = ? AND auth_groups_permissions.entity_type = ? AND auth_groups.name = ?
+UNION
+SELECT auth_groups_roles.entity_type, auth_groups_roles.entity_id, auth_roles_entitlements.entitlement
+FROM auth_groups_roles
+JOIN auth_roles_entitlements ON auth_groups_roles.auth_role_id = auth_roles_entitlements.auth_role_id
+JOIN auth_groups ON auth_groups_roles.auth_group_id = auth_groups.id
+WHERE auth_roles_entitlements.entitlement = ? AND auth_groups_roles.entity_type = ? AND auth_groups.name = ?
+`
+	var permissions []Permission
+	dest := func(scan func(dest ...any) error) error {
+		var permission Permission
+		err := scan(&permission.EntityType, &permission.EntityID, &permission.Entitlement)
+		if err != nil {
+			return err
+		}
+
+		permissions = append(permissions, permission)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, q, dest, entitlement, entityType, groupName, entitlement, entityType, groupName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get permissions of group %q with entitlement %q: %w", groupName, entitlement, err)
+	}
+
+	return permissions, nil
+}
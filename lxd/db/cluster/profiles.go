@@ -5,7 +5,10 @@ package cluster
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 )
 
@@ -51,7 +54,23 @@ type ProfileFilter struct {
 }
 
 // ToAPI returns a cluster Profile as an API struct.
+// If the profile inherits from other profiles, the expanded config and devices are set too.
 func (p *Profile) ToAPI(ctx context.Context, tx *sql.Tx) (*api.Profile, error) {
+	return p.toAPI(ctx, tx, "", nil, nil)
+}
+
+// ToAPIWithOverride returns a cluster Profile as an API struct, using the given config and devices in place of
+// those of the named profile (either the profile itself or one it inherits from) when expanding it.
+// This is used to get the expanded config and devices of a profile from before the named profile was updated.
+func (p *Profile) ToAPIWithOverride(ctx context.Context, tx *sql.Tx, profileName string, override api.ProfilePut) (*api.Profile, error) {
+	return p.toAPI(ctx, tx, profileName, &override, nil)
+}
+
+func (p *Profile) toAPI(ctx context.Context, tx *sql.Tx, overrideName string, override *api.ProfilePut, seen []int) (*api.Profile, error) {
+	if shared.ValueInSlice(p.ID, seen) {
+		return nil, fmt.Errorf("Profile %q inherits from itself", p.Name)
+	}
+
 	config, err := GetProfileConfig(ctx, tx, p.ID)
 	if err != nil {
 		return nil, err
@@ -62,16 +81,128 @@ func (p *Profile) ToAPI(ctx context.Context, tx *sql.Tx) (*api.Profile, error) {
 		return nil, err
 	}
 
+	parents, err := GetProfileParents(ctx, tx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	profile := &api.Profile{
 		Name:        p.Name,
 		Description: p.Description,
 		Config:      config,
 		Devices:     DevicesToAPI(devices),
+		Inherits:    make([]string, 0, len(parents)),
+	}
+
+	for _, parent := range parents {
+		profile.Inherits = append(profile.Inherits, parent.Name)
+	}
+
+	if override != nil && p.Name == overrideName {
+		profile.Config = override.Config
+		profile.Devices = override.Devices
+	}
+
+	if len(parents) == 0 {
+		return profile, nil
+	}
+
+	// Layer the profile over the expanded config and devices of its parents, in order.
+	profile.ExpandedConfig = map[string]string{}
+	profile.ExpandedDevices = map[string]map[string]string{}
+	for _, parent := range parents {
+		apiParent, err := parent.toAPI(ctx, tx, overrideName, override, append(seen, p.ID))
+		if err != nil {
+			return nil, err
+		}
+
+		for key, value := range apiParent.EffectiveConfig() {
+			profile.ExpandedConfig[key] = value
+		}
+
+		for name, device := range apiParent.EffectiveDevices() {
+			profile.ExpandedDevices[name] = device
+		}
+	}
+
+	for key, value := range profile.Config {
+		profile.ExpandedConfig[key] = value
+	}
+
+	for name, device := range profile.Devices {
+		profile.ExpandedDevices[name] = device
 	}
 
 	return profile, nil
 }
 
+// GetProfileParents returns the profiles that the profile with the given ID inherits from, in order.
+func GetProfileParents(ctx context.Context, tx *sql.Tx, profileID int) ([]Profile, error) {
+	stmt := `
+SELECT profiles.id, projects.name, profiles.name, coalesce(profiles.description, '')
+FROM profiles_inherits
+JOIN profiles ON profiles.id = profiles_inherits.parent_id
+JOIN projects ON projects.id = profiles.project_id
+WHERE profiles_inherits.profile_id = ?
+ORDER BY profiles_inherits.apply_order`
+
+	parents := []Profile{}
+	dest := func(scan func(dest ...any) error) error {
+		parent := Profile{}
+		err := scan(&parent.ID, &parent.Project, &parent.Name, &parent.Description)
+		if err != nil {
+			return err
+		}
+
+		parents = append(parents, parent)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting inherited profiles: %w", err)
+	}
+
+	return parents, nil
+}
+
+// GetProfileDescendants returns the names of the profiles that inherit from the profile with the given ID,
+// either directly or through other profiles.
+func GetProfileDescendants(ctx context.Context, tx *sql.Tx, profileID int) ([]string, error) {
+	stmt := `
+WITH RECURSIVE descendants(id) AS (
+    SELECT profile_id FROM profiles_inherits WHERE parent_id = ?
+    UNION
+    SELECT profiles_inherits.profile_id FROM profiles_inherits JOIN descendants ON profiles_inherits.parent_id = descendants.id
+)
+SELECT profiles.name FROM profiles JOIN descendants ON profiles.id = descendants.id ORDER BY profiles.name`
+
+	names, err := query.SelectStrings(ctx, tx, stmt, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting inheriting profiles: %w", err)
+	}
+
+	return names, nil
+}
+
+// UpdateProfileParents replaces the profiles that the profile with the given ID inherits from.
+func UpdateProfileParents(ctx context.Context, tx *sql.Tx, profileID int64, parentIDs []int64) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM profiles_inherits WHERE profile_id = ?", profileID)
+	if err != nil {
+		return fmt.Errorf("Failed deleting inherited profiles: %w", err)
+	}
+
+	for i, parentID := range parentIDs {
+		_, err = tx.ExecContext(ctx, "INSERT INTO profiles_inherits (profile_id, parent_id, apply_order) VALUES (?, ?, ?)", profileID, parentID, i)
+		if err != nil {
+			return fmt.Errorf("Failed adding inherited profile: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // GetProfilesIfEnabled returns the profiles from the given project, or the
 // default project if "features.profiles" is not set.
 func GetProfilesIfEnabled(ctx context.Context, tx *sql.Tx, projectName string, names []string) ([]Profile, error) {
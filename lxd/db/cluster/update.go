@@ -110,6 +110,225 @@ var updates = map[int]schema.Update{
 	71: updateFromV70,
 	72: updateFromV71,
 	73: updateFromV72,
+	74: updateFromV73,
+	75: updateFromV74,
+	76: updateFromV75,
+	77: updateFromV76,
+	78: updateFromV77,
+	79: updateFromV78,
+	80: updateFromV79,
+	81: updateFromV80,
+}
+
+func updateFromV80(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+ALTER TABLE networks_peers ADD COLUMN type INTEGER NOT NULL DEFAULT 0;
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV79(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE certificate_authority (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    certificate TEXT NOT NULL,
+    encrypted_key TEXT NOT NULL
+);
+
+CREATE TABLE certificate_authority_revocations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    serial_number TEXT NOT NULL,
+    revoked_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    UNIQUE (serial_number)
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV78(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE profiles_inherits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    profile_id INTEGER NOT NULL,
+    parent_id INTEGER NOT NULL,
+    apply_order INTEGER NOT NULL default 0,
+    UNIQUE (profile_id, parent_id),
+    FOREIGN KEY (profile_id) REFERENCES profiles (id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES profiles (id) ON DELETE CASCADE
+);
+
+CREATE INDEX profiles_inherits_parent_id_idx ON profiles_inherits (parent_id);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV77(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    date DATETIME NOT NULL,
+    location TEXT NOT NULL,
+    identity TEXT NOT NULL,
+    authentication_method TEXT NOT NULL,
+    project TEXT NOT NULL,
+    method TEXT NOT NULL,
+    entity_url TEXT NOT NULL,
+    body_digest TEXT NOT NULL,
+    status_code INTEGER NOT NULL
+);
+
+CREATE INDEX audit_log_date_idx ON audit_log (date);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV76(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE device_groups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    project_id INTEGER NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+
+CREATE TABLE device_groups_devices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    device_group_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    type INTEGER NOT NULL default 0,
+    UNIQUE (device_group_id, name),
+    FOREIGN KEY (device_group_id) REFERENCES device_groups (id) ON DELETE CASCADE
+);
+
+CREATE TABLE device_groups_devices_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    device_group_device_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (device_group_device_id, key),
+    FOREIGN KEY (device_group_device_id) REFERENCES device_groups_devices (id) ON DELETE CASCADE
+);
+
+CREATE INDEX device_groups_project_id_idx ON device_groups (project_id);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV75(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE identity_provider_group_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (name)
+);
+
+CREATE TABLE identity_provider_group_rules_conditions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    identity_provider_group_rule_id INTEGER NOT NULL,
+    claim TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    FOREIGN KEY (identity_provider_group_rule_id) REFERENCES identity_provider_group_rules (id) ON DELETE CASCADE
+);
+
+CREATE TABLE auth_groups_identity_provider_group_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    auth_group_id INTEGER NOT NULL,
+    identity_provider_group_rule_id INTEGER NOT NULL,
+    FOREIGN KEY (auth_group_id) REFERENCES auth_groups (id) ON DELETE CASCADE,
+    FOREIGN KEY (identity_provider_group_rule_id) REFERENCES identity_provider_group_rules (id) ON DELETE CASCADE,
+    UNIQUE (auth_group_id, identity_provider_group_rule_id)
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV74(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE auth_roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    entity_type INTEGER NOT NULL,
+    UNIQUE (name)
+);
+
+CREATE TABLE auth_roles_entitlements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    auth_role_id INTEGER NOT NULL,
+    entitlement TEXT NOT NULL,
+    FOREIGN KEY (auth_role_id) REFERENCES auth_roles (id) ON DELETE CASCADE,
+    UNIQUE (auth_role_id, entitlement)
+);
+
+CREATE TABLE auth_groups_roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    auth_group_id INTEGER NOT NULL,
+    auth_role_id INTEGER NOT NULL,
+    entity_type INTEGER NOT NULL,
+    entity_id INTEGER NOT NULL,
+    FOREIGN KEY (auth_group_id) REFERENCES auth_groups (id) ON DELETE CASCADE,
+    FOREIGN KEY (auth_role_id) REFERENCES auth_roles (id) ON DELETE CASCADE,
+    UNIQUE (auth_group_id, auth_role_id, entity_id)
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV73(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE storage_pools_benchmarks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    storage_pool_id INTEGER NOT NULL,
+    node_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    size INTEGER NOT NULL,
+    sequential_read INTEGER NOT NULL,
+    sequential_write INTEGER NOT NULL,
+    random_read_iops INTEGER NOT NULL,
+    random_write_iops INTEGER NOT NULL,
+    random_read_latency INTEGER NOT NULL,
+    random_write_latency INTEGER NOT NULL,
+    FOREIGN KEY (storage_pool_id) REFERENCES storage_pools (id) ON DELETE CASCADE,
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func updateFromV72(ctx context.Context, tx *sql.Tx) error {
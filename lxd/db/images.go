@@ -23,6 +23,7 @@ var ImageSourceProtocol = map[int]string{
 	0: "lxd",
 	1: "direct",
 	2: "simplestreams",
+	3: "oci",
 }
 
 // GetLocalImagesFingerprints returns the fingerprints of all local images.
@@ -1110,6 +1111,17 @@ SELECT DISTINCT nodes.address FROM nodes WHERE nodes.address NOT IN (
 	return c.getNodesByImageFingerprint(ctx, q, fingerprint, nil)
 }
 
+// GetNodeNamesWithImage returns the names of all the nodes (online or not) which have the image.
+func (c *ClusterTx) GetNodeNamesWithImage(ctx context.Context, fingerprint string) ([]string, error) {
+	q := `
+SELECT DISTINCT nodes.name FROM nodes
+  JOIN images_nodes ON images_nodes.node_id = nodes.id
+  JOIN images ON images_nodes.image_id = images.id
+WHERE images.fingerprint = ?
+	`
+	return query.SelectStrings(ctx, c.tx, q, fingerprint)
+}
+
 func (c *ClusterTx) getNodesByImageFingerprint(ctx context.Context, stmt string, fingerprint string, autoUpdate *bool) ([]string, error) {
 	var addresses []string // Addresses of online nodes with the image
 
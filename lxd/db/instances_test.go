@@ -5,6 +5,7 @@ package db_test
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"testing"
 	"time"
 
@@ -286,6 +287,58 @@ func TestInstanceList(t *testing.T) {
 	}, instances[0].Devices.CloneNative())
 }
 
+// The number of queries needed to load instances along with their snapshots and backups must not depend on the
+// number of instances.
+func TestInstanceList_QueryCount(t *testing.T) {
+	c, queries, clusterCleanup := db.NewTestClusterWithQueryCounter(t)
+	defer clusterCleanup()
+
+	addInstancesWithSnapshotsAndBackups(t, c, 0, 2)
+
+	queries.Store(0)
+	instances, snapshots, backups := loadInstancesWithSnapshotsAndBackups(t, c)
+	baseline := queries.Load()
+
+	assert.Len(t, instances, 2)
+	for _, inst := range instances {
+		require.Len(t, snapshots[inst.ID], 2)
+		assert.Equal(t, inst.Name+"/snap0", snapshots[inst.ID][0].Name)
+		assert.Equal(t, inst.Name+"/snap1", snapshots[inst.ID][1].Name)
+		assert.Equal(t, map[string]string{"snap": "0"}, snapshots[inst.ID][0].Config)
+		assert.True(t, snapshots[inst.ID][0].Snapshot)
+
+		require.Len(t, backups[inst.ID], 1)
+		assert.Equal(t, inst.Name+"/backup0", backups[inst.ID][0].Name)
+		assert.True(t, backups[inst.ID][0].InstanceOnly)
+	}
+
+	addInstancesWithSnapshotsAndBackups(t, c, 2, 20)
+
+	queries.Store(0)
+	instances, snapshots, backups = loadInstancesWithSnapshotsAndBackups(t, c)
+
+	assert.Len(t, instances, 22)
+	assert.Len(t, snapshots, 22)
+	assert.Len(t, backups, 22)
+	assert.Equal(t, baseline, queries.Load())
+}
+
+func BenchmarkInstanceList(b *testing.B) {
+	c, queries, clusterCleanup := db.NewTestClusterWithQueryCounter(b)
+	defer clusterCleanup()
+
+	addInstancesWithSnapshotsAndBackups(b, c, 0, 100)
+
+	queries.Store(0)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		loadInstancesWithSnapshotsAndBackups(b, c)
+	}
+
+	b.ReportMetric(float64(queries.Load())/float64(b.N), "queries/op")
+}
+
 func TestCreateInstance(t *testing.T) {
 	tx, cleanup := db.NewTestClusterTx(t)
 	defer cleanup()
@@ -546,6 +599,107 @@ func TestGetLocalInstancesInProject(t *testing.T) {
 	assert.Equal(t, map[string]map[string]string{"root": {"type": "disk", "x": "y"}}, cluster.DevicesToAPI(c3Devices))
 }
 
+// Add count instances, each with config, a device, two snapshots and a backup.
+func addInstancesWithSnapshotsAndBackups(t testing.TB, c *db.Cluster, first int, count int) {
+	err := c.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		for i := first; i < first+count; i++ {
+			name := fmt.Sprintf("c%d", i)
+
+			id, err := cluster.CreateInstance(ctx, tx.Tx(), cluster.Instance{
+				Project:      "default",
+				Name:         name,
+				Node:         "none",
+				Type:         instancetype.Container,
+				Architecture: 1,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = cluster.CreateInstanceConfig(ctx, tx.Tx(), id, map[string]string{"a": "b"})
+			if err != nil {
+				return err
+			}
+
+			err = cluster.CreateInstanceDevices(ctx, tx.Tx(), id, map[string]cluster.Device{"eth0": {Name: "eth0", Type: cluster.TypeNIC, Config: map[string]string{"c": "d"}}})
+			if err != nil {
+				return err
+			}
+
+			err = cluster.UpdateInstanceProfiles(ctx, tx.Tx(), int(id), "default", []string{"default"})
+			if err != nil {
+				return err
+			}
+
+			for j := 0; j < 2; j++ {
+				snapshotID, err := cluster.CreateInstanceSnapshot(ctx, tx.Tx(), cluster.InstanceSnapshot{
+					Project:      "default",
+					Instance:     name,
+					Name:         fmt.Sprintf("snap%d", j),
+					CreationDate: time.Now().Add(time.Duration(j) * time.Second),
+				})
+				if err != nil {
+					return err
+				}
+
+				err = cluster.CreateInstanceSnapshotConfig(ctx, tx.Tx(), snapshotID, map[string]string{"snap": fmt.Sprintf("%d", j)})
+				if err != nil {
+					return err
+				}
+			}
+
+			err = tx.CreateInstanceBackup(ctx, db.InstanceBackup{
+				InstanceID:   int(id),
+				Name:         name + "/backup0",
+				CreationDate: time.Now(),
+				ExpiryDate:   time.Now().Add(time.Hour),
+				InstanceOnly: true,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// Load all instances along with their snapshots and backups.
+func loadInstancesWithSnapshotsAndBackups(t testing.TB, c *db.Cluster) ([]db.InstanceArgs, map[int][]db.InstanceArgs, map[int][]db.InstanceBackup) {
+	var instances []db.InstanceArgs
+	var snapshots map[int][]db.InstanceArgs
+	var backups map[int][]db.InstanceBackup
+
+	err := c.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			instances = append(instances, inst)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		parents := make([]cluster.Instance, 0, len(instances))
+		instanceIDs := make([]int, 0, len(instances))
+		for _, inst := range instances {
+			parents = append(parents, cluster.Instance{ID: inst.ID, Project: inst.Project, Name: inst.Name, Node: inst.Node, Type: inst.Type, Architecture: inst.Architecture})
+			instanceIDs = append(instanceIDs, inst.ID)
+		}
+
+		snapshots, err = tx.GetInstancesSnapshotsArgs(ctx, parents...)
+		if err != nil {
+			return err
+		}
+
+		backups, err = tx.GetInstancesBackups(ctx, instanceIDs...)
+		return err
+	})
+	require.NoError(t, err)
+
+	return instances, snapshots, backups
+}
+
 func addContainer(t *testing.T, tx *db.ClusterTx, nodeID int64, name string) {
 	stmt := `
 INSERT INTO instances(node_id, name, architecture, type, project_id, description) VALUES (?, ?, 1, ?, 1, '')
@@ -13,6 +13,37 @@ import (
 	"github.com/canonical/lxd/shared/api"
 )
 
+// NetworkPeerType indicates the type of a network peering.
+type NetworkPeerType int
+
+// Network peering types.
+const (
+	NetworkPeerTypeLocal  NetworkPeerType = iota // Peering with a network of the same deployment.
+	NetworkPeerTypeRemote                        // Peering with a network of another deployment through OVN interconnection.
+)
+
+// networkPeerTypeNames maps network peering types to their API names.
+var networkPeerTypeNames = map[NetworkPeerType]string{
+	NetworkPeerTypeLocal:  api.NetworkPeerTypeLocal,
+	NetworkPeerTypeRemote: api.NetworkPeerTypeRemote,
+}
+
+// NetworkPeerTypeFromAPI converts an API network peering type to its database value.
+// An empty type is a local peering.
+func NetworkPeerTypeFromAPI(peerType string) (NetworkPeerType, error) {
+	if peerType == "" {
+		return NetworkPeerTypeLocal, nil
+	}
+
+	for dbType, name := range networkPeerTypeNames {
+		if name == peerType {
+			return dbType, nil
+		}
+	}
+
+	return -1, fmt.Errorf("Invalid network peer type %q", peerType)
+}
+
 // CreateNetworkPeer creates a new Network Peer and returns its ID.
 // If there is a mutual peering on the target network side the both peer entries are upated to link to each other's
 // repspective network ID.
@@ -22,12 +53,24 @@ func (c *ClusterTx) CreateNetworkPeer(ctx context.Context, networkID int64, info
 	var localPeerID int64
 	var targetPeerNetworkID = int64(-1) // -1 means no mutual peering exists.
 
+	peerType, err := NetworkPeerTypeFromAPI(info.Type)
+	if err != nil {
+		return -1, false, err
+	}
+
+	// Remote peers have no target network in this deployment.
+	var targetProject, targetNetwork any
+	if peerType == NetworkPeerTypeLocal {
+		targetProject = info.TargetProject
+		targetNetwork = info.TargetNetwork
+	}
+
 	// Insert a new Network pending peer record.
 	result, err := c.tx.ExecContext(ctx, `
 		INSERT INTO networks_peers
-		(network_id, name, description, target_network_project, target_network_name)
-		VALUES (?, ?, ?, ?, ?)
-		`, networkID, info.Name, info.Description, info.TargetProject, info.TargetNetwork)
+		(network_id, name, description, target_network_project, target_network_name, type)
+		VALUES (?, ?, ?, ?, ?, ?)
+		`, networkID, info.Name, info.Description, targetProject, targetNetwork, peerType)
 	if err != nil {
 		return -1, false, err
 	}
@@ -43,6 +86,10 @@ func (c *ClusterTx) CreateNetworkPeer(ctx context.Context, networkID int64, info
 		return -1, false, err
 	}
 
+	if peerType == NetworkPeerTypeRemote {
+		return localPeerID, false, nil
+	}
+
 	// Check if we are creating a mutual peering of an existing peer and if so then update both sides
 	// with the respective network IDs. This query looks up our network peer's network name and project
 	// name and then checks if there are any unlinked (target_network_id IS NULL) peers that have
@@ -156,7 +203,8 @@ func (c *ClusterTx) GetNetworkPeer(ctx context.Context, networkID int64, peerNam
 		IFNULL(local_peer.target_network_project, ""),
 		IFNULL(local_peer.target_network_name, ""),
 		IFNULL(target_peer_network.name, "") AS target_peer_network_name,
-		IFNULL(target_peer_project.name, "") AS target_peer_network_project
+		IFNULL(target_peer_project.name, "") AS target_peer_network_project,
+		local_peer.type
 	FROM networks_peers AS local_peer
 	LEFT JOIN networks_peers AS target_peer
 		ON target_peer.network_id = local_peer.target_network_id
@@ -174,8 +222,9 @@ func (c *ClusterTx) GetNetworkPeer(ctx context.Context, networkID int64, peerNam
 	var peer api.NetworkPeer
 	var targetPeerNetworkName string
 	var targetPeerNetworkProject string
+	var peerType NetworkPeerType
 
-	err = c.tx.QueryRowContext(ctx, q, networkID, peerName).Scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject)
+	err = c.tx.QueryRowContext(ctx, q, networkID, peerName).Scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject, &peerType)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Network peer not found")
@@ -189,15 +238,22 @@ func (c *ClusterTx) GetNetworkPeer(ctx context.Context, networkID int64, peerNam
 		return -1, nil, err
 	}
 
-	networkPeerPopulatePeerInfo(&peer, targetPeerNetworkProject, targetPeerNetworkName)
+	networkPeerPopulatePeerInfo(&peer, peerType, targetPeerNetworkProject, targetPeerNetworkName)
 
 	return peerID, &peer, nil
 }
 
-// networkPeerPopulatePeerInfo populates the supplied peer's Status, TargetProject and TargetNetwork fields.
+// networkPeerPopulatePeerInfo populates the supplied peer's Type, Status, TargetProject and TargetNetwork fields.
 // It uses the state of the targetPeerNetworkProject and targetPeerNetworkName arguments to decide whether the
 // peering is mutually created and whether to use those values rather than the values contained in the peer.
-func networkPeerPopulatePeerInfo(peer *api.NetworkPeer, targetPeerNetworkProject string, targetPeerNetworkName string) {
+// Remote peers don't have a mutual peering in this deployment and are always considered created.
+func networkPeerPopulatePeerInfo(peer *api.NetworkPeer, peerType NetworkPeerType, targetPeerNetworkProject string, targetPeerNetworkName string) {
+	peer.Type = networkPeerTypeNames[peerType]
+	if peerType == NetworkPeerTypeRemote {
+		peer.Status = api.NetworkStatusCreated
+		return
+	}
+
 	// Peer has mutual peering from target network.
 	if targetPeerNetworkName != "" && targetPeerNetworkProject != "" {
 		if peer.TargetNetwork != "" || peer.TargetProject != "" {
@@ -270,7 +326,8 @@ func (c *ClusterTx) GetNetworkPeers(ctx context.Context, networkID int64) (map[i
 		IFNULL(local_peer.target_network_project, ""),
 		IFNULL(local_peer.target_network_name, ""),
 		IFNULL(target_peer_network.name, "") AS target_peer_network_name,
-		IFNULL(target_peer_project.name, "") AS target_peer_network_project
+		IFNULL(target_peer_project.name, "") AS target_peer_network_project,
+		local_peer.type
 	FROM networks_peers AS local_peer
 	LEFT JOIN networks_peers AS target_peer
 		ON target_peer.network_id = local_peer.target_network_id
@@ -290,13 +347,14 @@ func (c *ClusterTx) GetNetworkPeers(ctx context.Context, networkID int64) (map[i
 		var peer api.NetworkPeer
 		var targetPeerNetworkName string
 		var targetPeerNetworkProject string
+		var peerType NetworkPeerType
 
-		err := scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject)
+		err := scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject, &peerType)
 		if err != nil {
 			return err
 		}
 
-		networkPeerPopulatePeerInfo(&peer, targetPeerNetworkProject, targetPeerNetworkName)
+		networkPeerPopulatePeerInfo(&peer, peerType, targetPeerNetworkProject, targetPeerNetworkName)
 
 		peers[peerID] = &peer
 
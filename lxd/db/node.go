@@ -54,6 +54,7 @@ const (
 	ClusterMemberStateCreated   = 0
 	ClusterMemberStatePending   = 1
 	ClusterMemberStateEvacuated = 2
+	ClusterMemberStateUpgrading = 3
 )
 
 // NodeInfo holds information about a single LXD instance in a cluster.
@@ -91,7 +92,6 @@ type NodeInfoArgs struct {
 // ToAPI returns a LXD API entry.
 func (n NodeInfo) ToAPI(ctx context.Context, tx *ClusterTx, args NodeInfoArgs) (*api.ClusterMember, error) {
 	var err error
-	var maxVersion [2]int
 	var failureDomain string
 
 	domainID := args.MemberFailureDomains[n.Address]
@@ -113,6 +113,8 @@ func (n NodeInfo) ToAPI(ctx context.Context, tx *ClusterTx, args NodeInfoArgs) (
 	result.URL = fmt.Sprintf("https://%s", n.Address)
 	result.Database = false
 	result.Config = n.Config
+	result.SchemaVersion = n.Schema
+	result.APIExtensions = n.APIExtensions
 
 	result.Roles = make([]string, 0, len(n.Roles))
 	for _, r := range n.Roles {
@@ -151,12 +153,15 @@ func (n NodeInfo) ToAPI(ctx context.Context, tx *ClusterTx, args NodeInfoArgs) (
 	if n.State == ClusterMemberStateEvacuated {
 		result.Status = "Evacuated"
 		result.Message = "Unavailable due to maintenance"
+	} else if n.State == ClusterMemberStateUpgrading {
+		result.Status = "Upgrading"
+		result.Message = "Unavailable due to upgrade"
 	} else if n.IsOffline(args.OfflineThreshold) {
 		result.Status = "Offline"
 		result.Message = fmt.Sprintf("No heartbeat for %s (%s)", time.Since(n.Heartbeat), n.Heartbeat)
 	} else {
 		// Check if up to date.
-		n, err := util.CompareVersions(maxVersion, n.Version())
+		n, err := util.CompareVersions(args.MaxMemberVersion, n.Version())
 		if err != nil {
 			return nil, err
 		}
@@ -1174,7 +1179,7 @@ func nodeIsOffline(threshold time.Duration, heartbeat time.Time) bool {
 	return heartbeat.Before(offlineTime) || heartbeat.Equal(offlineTime)
 }
 
-// LocalNodeIsEvacuated returns whether the local member is in the evacuated state.
+// LocalNodeIsEvacuated returns whether the local member is in the evacuated state, or drained for an upgrade.
 func (c *Cluster) LocalNodeIsEvacuated() bool {
 	isEvacuated := false
 
@@ -1189,7 +1194,7 @@ func (c *Cluster) LocalNodeIsEvacuated() bool {
 			return nil
 		}
 
-		isEvacuated = node.State == ClusterMemberStateEvacuated
+		isEvacuated = node.State == ClusterMemberStateEvacuated || node.State == ClusterMemberStateUpgrading
 		return nil
 	})
 	if err != nil {
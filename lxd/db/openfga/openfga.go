@@ -16,7 +16,6 @@ import (
 	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
-	"github.com/canonical/lxd/lxd/db/query"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 )
@@ -261,14 +260,8 @@ func (o *openfgaStore) ReadUsersetTuples(ctx context.Context, store string, filt
 			return err
 		}
 
-		// Get all groups with the permission.
-		q := `
-SELECT auth_groups.name
-FROM auth_groups_permissions
-JOIN auth_groups ON auth_groups_permissions.auth_group_id = auth_groups.id
-WHERE auth_groups_permissions.entitlement = ? AND auth_groups_permissions.entity_type = ? AND auth_groups_permissions.entity_id = ?
-`
-		groupNames, err = query.SelectStrings(ctx, tx.Tx(), q, filter.Relation, cluster.EntityType(entityType), entityRef.EntityID)
+		// Get all groups with the permission, either directly or via a role assigned on the entity.
+		groupNames, err = cluster.GetAuthGroupNamesWithEntitlement(ctx, tx.Tx(), auth.Entitlement(filter.Relation), cluster.EntityType(entityType), entityRef.EntityID)
 		if err != nil {
 			return err
 		}
@@ -458,34 +451,18 @@ func (o *openfgaStore) ReadStartingWithUser(ctx context.Context, store string, f
 		return nil, fmt.Errorf("ReadStartingWithUser: Unexpected user filter entity type %q", userEntityType)
 	}
 
-	// Construct a query to list permissions with the given entity type and entitlement for the given group.
-	q := `
-SELECT auth_groups_permissions.entity_type, auth_groups_permissions.entity_id, auth_groups_permissions.entitlement
-FROM auth_groups_permissions
-JOIN auth_groups ON auth_groups_permissions.auth_group_id = auth_groups.id
-WHERE auth_groups_permissions.entitlement = ? AND auth_groups_permissions.entity_type = ? AND auth_groups.name = ?
-`
 	groupName := userURLPathArguments[0]
-	args := []any{filter.Relation, cluster.EntityType(filter.ObjectType), groupName}
 
 	var entityURLs map[entity.Type]map[int]*api.URL
 	var permissions []cluster.Permission
 	err = o.clusterDB.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-		rows, err := tx.Tx().QueryContext(ctx, q, args...)
+		// List the permissions with the given entity type and entitlement for the given group. This includes the
+		// permissions granted by the roles assigned to the group.
+		permissions, err = cluster.GetAuthGroupPermissionsByEntitlement(ctx, tx.Tx(), groupName, auth.Entitlement(filter.Relation), cluster.EntityType(filter.ObjectType))
 		if err != nil {
 			return err
 		}
 
-		for rows.Next() {
-			var permission cluster.Permission
-			err = rows.Scan(&permission.EntityType, &permission.EntityID, &permission.Entitlement)
-			if err != nil {
-				return err
-			}
-
-			permissions = append(permissions, permission)
-		}
-
 		// Get the URLs of the permissions we've queried for and filter out any invalid ones.
 		// Ignore the dangling permissions to make as few queries as possible.
 		permissions, entityURLs, err = cluster.GetPermissionEntityURLs(ctx, tx.Tx(), permissions)
@@ -76,6 +76,11 @@ const (
 	RenewServerCertificate
 	RemoveExpiredTokens
 	ClusterHeal
+	RemoveOrphanedResources
+	StoragePoolBenchmark
+	NetworkCapture
+	AuditLogPrune
+	StoragePoolMigrate
 )
 
 // Description return a human-readable description of the operation type.
@@ -199,6 +204,16 @@ func (t Type) Description() string {
 		return "Remove expired tokens"
 	case ClusterHeal:
 		return "Healing cluster"
+	case RemoveOrphanedResources:
+		return "Remove orphaned resources"
+	case StoragePoolBenchmark:
+		return "Benchmarking storage pool"
+	case NetworkCapture:
+		return "Capturing network traffic"
+	case AuditLogPrune:
+		return "Pruning audit log"
+	case StoragePoolMigrate:
+		return "Migrating storage pool"
 	default:
 		return "Executing operation"
 	}
@@ -280,6 +295,13 @@ func (t Type) Permission() (entity.Type, auth.Entitlement) {
 		return entity.TypeStorageVolume, auth.EntitlementCanManageBackups
 	case CustomVolumeBackupRestore:
 		return entity.TypeStorageVolume, auth.EntitlementCanEdit
+
+	case StoragePoolBenchmark:
+		return entity.TypeStoragePool, auth.EntitlementCanEdit
+	case StoragePoolMigrate:
+		return entity.TypeStoragePool, auth.EntitlementCanEdit
+	case NetworkCapture:
+		return entity.TypeNetwork, auth.EntitlementCanEdit
 	}
 
 	return "", ""
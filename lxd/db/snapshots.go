@@ -5,6 +5,9 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/canonical/lxd/lxd/db/cluster"
@@ -96,3 +99,100 @@ func (c *ClusterTx) GetInstanceSnapshotID(ctx context.Context, project, instance
 	id, err := cluster.GetInstanceSnapshotID(ctx, c.tx, project, instance, name)
 	return int(id), err
 }
+
+// GetInstancesSnapshotsArgs returns the snapshots of the given instances keyed by instance ID, sorted by creation date.
+// The snapshots of all the instances, along with their config and devices, are loaded with a fixed number of
+// queries rather than with queries for each instance.
+func (c *ClusterTx) GetInstancesSnapshotsArgs(ctx context.Context, instances ...cluster.Instance) (map[int][]InstanceArgs, error) {
+	result := make(map[int][]InstanceArgs, len(instances))
+	if len(instances) == 0 {
+		return result, nil
+	}
+
+	instancesByID := make(map[int]cluster.Instance, len(instances))
+
+	// Don't use query parameters for the IN statement to workaround an issue in Dqlite (apparently)
+	// that means that >255 query parameters causes partial result sets. See #10705
+	// This is safe as the inputs are ints.
+	var q strings.Builder
+
+	q.WriteString(`SELECT
+		instances_snapshots.id,
+		instances_snapshots.instance_id,
+		instances_snapshots.name,
+		instances_snapshots.creation_date,
+		instances_snapshots.stateful,
+		coalesce(instances_snapshots.description, ''),
+		instances_snapshots.expiry_date
+	FROM instances_snapshots
+	WHERE instances_snapshots.instance_id IN (`)
+
+	q.Grow(len(instances) * 2) // We know the minimum length of the separators and integers.
+
+	for i, instance := range instances {
+		if i > 0 {
+			q.WriteString(",")
+		}
+
+		q.WriteString(fmt.Sprintf("%d", instance.ID))
+		instancesByID[instance.ID] = instance
+	}
+
+	q.WriteString(`)`)
+
+	snapshotParentIDs := map[int]int{}
+	dbSnapshots := []cluster.Instance{}
+	err := query.Scan(ctx, c.Tx(), q.String(), func(scan func(dest ...any) error) error {
+		var instanceID int
+		snapshot := cluster.InstanceSnapshot{}
+
+		err := scan(&snapshot.ID, &instanceID, &snapshot.Name, &snapshot.CreationDate, &snapshot.Stateful, &snapshot.Description, &snapshot.ExpiryDate)
+		if err != nil {
+			return err
+		}
+
+		parent, found := instancesByID[instanceID]
+		if !found {
+			return fmt.Errorf("Failed loading instance snapshot, referenced instance %d not loaded", instanceID)
+		}
+
+		snapshot.Project = parent.Project
+		snapshot.Instance = parent.Name
+
+		snapshotParentIDs[snapshot.ID] = instanceID
+		dbSnapshots = append(dbSnapshots, snapshot.ToInstance(parent.Name, parent.Node, parent.Type, parent.Architecture))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance snapshots: %w", err)
+	}
+
+	if len(dbSnapshots) == 0 {
+		return result, nil
+	}
+
+	// Fill the snapshots with their config and devices.
+	snapshotArgs, err := c.InstancesToInstanceArgs(ctx, false, dbSnapshots...)
+	if err != nil {
+		return nil, err
+	}
+
+	for snapshotID, args := range snapshotArgs {
+		instanceID := snapshotParentIDs[snapshotID]
+		result[instanceID] = append(result[instanceID], args)
+	}
+
+	// Sort by creation date and then by ID like the snapshots of a single instance.
+	for _, snapshots := range result {
+		sort.SliceStable(snapshots, func(i, j int) bool {
+			if snapshots[i].CreationDate.Equal(snapshots[j].CreationDate) {
+				return snapshots[i].ID < snapshots[j].ID
+			}
+
+			return snapshots[i].CreationDate.Before(snapshots[j].CreationDate)
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,107 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// StoragePoolBenchmarksMax is the number of benchmark results kept per storage pool and cluster member.
+const StoragePoolBenchmarksMax = 10
+
+// GetStoragePoolBenchmarks returns the benchmark results of the storage pool with the given ID, oldest first.
+// If memberName is not empty, only the results of that cluster member are returned.
+func (c *ClusterTx) GetStoragePoolBenchmarks(ctx context.Context, poolID int64, memberName string) ([]api.StoragePoolBenchmark, error) {
+	var args []any
+
+	q := `
+SELECT storage_pools_benchmarks.id, nodes.name, storage_pools_benchmarks.created_at, storage_pools_benchmarks.size,
+  storage_pools_benchmarks.sequential_read, storage_pools_benchmarks.sequential_write,
+  storage_pools_benchmarks.random_read_iops, storage_pools_benchmarks.random_write_iops,
+  storage_pools_benchmarks.random_read_latency, storage_pools_benchmarks.random_write_latency
+FROM storage_pools_benchmarks
+JOIN nodes ON nodes.id = storage_pools_benchmarks.node_id
+WHERE storage_pools_benchmarks.storage_pool_id = ?`
+	args = append(args, poolID)
+
+	if memberName != "" {
+		q += " AND nodes.name = ?"
+		args = append(args, memberName)
+	}
+
+	q += " ORDER BY storage_pools_benchmarks.id"
+
+	benchmarks := []api.StoragePoolBenchmark{}
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var b api.StoragePoolBenchmark
+
+		err := scan(&b.ID, &b.Location, &b.CreatedAt, &b.Size, &b.SequentialRead, &b.SequentialWrite, &b.RandomReadIOPS, &b.RandomWriteIOPS, &b.RandomReadLatency, &b.RandomWriteLatency)
+		if err != nil {
+			return err
+		}
+
+		benchmarks = append(benchmarks, b)
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading storage pool benchmarks: %w", err)
+	}
+
+	return benchmarks, nil
+}
+
+// CreateStoragePoolBenchmark records the result of a benchmark run on the given cluster member.
+// Only the most recent StoragePoolBenchmarksMax results of each member are kept.
+func (c *ClusterTx) CreateStoragePoolBenchmark(ctx context.Context, poolID int64, memberID int64, b api.StoragePoolBenchmark) (int64, error) {
+	q := `
+INSERT INTO storage_pools_benchmarks (storage_pool_id, node_id, created_at, size, sequential_read, sequential_write,
+  random_read_iops, random_write_iops, random_read_latency, random_write_latency)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+	result, err := c.tx.ExecContext(ctx, q, poolID, memberID, b.CreatedAt, b.Size, b.SequentialRead, b.SequentialWrite, b.RandomReadIOPS, b.RandomWriteIOPS, b.RandomReadLatency, b.RandomWriteLatency)
+	if err != nil {
+		return -1, fmt.Errorf("Failed inserting storage pool benchmark: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Failed getting storage pool benchmark ID: %w", err)
+	}
+
+	q = `
+DELETE FROM storage_pools_benchmarks WHERE storage_pool_id = ? AND node_id = ? AND id NOT IN (
+  SELECT id FROM storage_pools_benchmarks WHERE storage_pool_id = ? AND node_id = ? ORDER BY id DESC LIMIT ?
+)
+`
+	_, err = c.tx.ExecContext(ctx, q, poolID, memberID, poolID, memberID, StoragePoolBenchmarksMax)
+	if err != nil {
+		return -1, fmt.Errorf("Failed pruning storage pool benchmarks: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteStoragePoolBenchmark deletes the benchmark result with the given ID from the storage pool.
+func (c *ClusterTx) DeleteStoragePoolBenchmark(ctx context.Context, poolID int64, id int64) error {
+	result, err := c.tx.ExecContext(ctx, "DELETE FROM storage_pools_benchmarks WHERE storage_pool_id = ? AND id = ?", poolID, id)
+	if err != nil {
+		return fmt.Errorf("Failed deleting storage pool benchmark: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Storage pool benchmark not found")
+	}
+
+	return nil
+}
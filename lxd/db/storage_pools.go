@@ -877,6 +877,9 @@ var NodeSpecificStorageConfig = []string{
 	"zfs.pool_name",
 	"lvm.thinpool_name",
 	"lvm.vg_name",
+	"lvm.iscsi.portal",
+	"lvm.iscsi.target",
+	"lvm.iscsi.lun",
 }
 
 // IsRemoteStorage return whether a given pool is backed by remote storage.
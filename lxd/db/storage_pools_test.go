@@ -286,3 +286,40 @@ func TestCreateStoragePoolVolume_Snapshot(t *testing.T) {
 		return nil
 	})
 }
+
+// Only the most recent benchmark results of each member are kept.
+func TestCreateStoragePoolBenchmark(t *testing.T) {
+	clusterDB, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	err := clusterDB.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		poolID, err := tx.CreateStoragePool(ctx, "p1", "", "dir", nil)
+		require.NoError(t, err)
+
+		var lastID int64
+		for i := 0; i < db.StoragePoolBenchmarksMax+2; i++ {
+			lastID, err = tx.CreateStoragePoolBenchmark(ctx, poolID, tx.GetNodeID(), api.StoragePoolBenchmark{CreatedAt: time.Now(), Size: int64(i)})
+			require.NoError(t, err)
+		}
+
+		benchmarks, err := tx.GetStoragePoolBenchmarks(ctx, poolID, "")
+		require.NoError(t, err)
+		require.Len(t, benchmarks, db.StoragePoolBenchmarksMax)
+		assert.Equal(t, int64(2), benchmarks[0].Size)
+		assert.Equal(t, lastID, benchmarks[len(benchmarks)-1].ID)
+		assert.Equal(t, "none", benchmarks[0].Location)
+
+		benchmarks, err = tx.GetStoragePoolBenchmarks(ctx, poolID, "other")
+		require.NoError(t, err)
+		assert.Empty(t, benchmarks)
+
+		err = tx.DeleteStoragePoolBenchmark(ctx, poolID, lastID)
+		require.NoError(t, err)
+
+		err = tx.DeleteStoragePoolBenchmark(ctx, poolID, lastID)
+		assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -8,6 +8,8 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -57,16 +59,41 @@ func NewTestNodeTx(t *testing.T) (*NodeTx, func()) {
 // NewTestCluster creates a new Cluster for testing purposes, along with a function
 // that can be used to clean it up when done.
 func NewTestCluster(t *testing.T) (*Cluster, func()) {
-	// Create an in-memory dqlite SQL server and associated store.
-	dir, store, serverCleanup := NewTestDqliteServer(t)
+	return newTestCluster(t, newLogFunc(t))
+}
 
+// NewTestClusterWithQueryCounter creates a new Cluster for testing purposes which counts the SQL statements
+// it runs, along with a function that can be used to clean it up when done.
+func NewTestClusterWithQueryCounter(t testing.TB) (*Cluster, *atomic.Int64, func()) {
+	counter := &atomic.Int64{}
 	log := newLogFunc(t)
 
+	// Statements are traced at debug level, so count them and only forward the other messages.
+	countingLog := func(l client.LogLevel, format string, a ...any) {
+		if l == client.LogDebug && strings.Contains(format, "request ") {
+			counter.Add(1)
+			return
+		}
+
+		log(l, format, a...)
+	}
+
+	cluster, cleanup := newTestCluster(t, countingLog, driver.WithTracing(client.LogDebug))
+
+	return cluster, counter, cleanup
+}
+
+func newTestCluster(t testing.TB, log client.LogFunc, options ...driver.Option) (*Cluster, func()) {
+	// Create an in-memory dqlite SQL server and associated store.
+	dir, store, serverCleanup := NewTestDqliteServer(t)
+
 	dial := func(ctx context.Context, address string) (net.Conn, error) {
 		return net.Dial("unix", address)
 	}
 
-	cluster, err := OpenCluster(context.Background(), "test.db", store, "1", dir, 5*time.Second, nil, driver.WithLogFunc(log), driver.WithDialFunc(dial))
+	options = append([]driver.Option{driver.WithLogFunc(log), driver.WithDialFunc(dial)}, options...)
+
+	cluster, err := OpenCluster(context.Background(), "test.db", store, "1", dir, 5*time.Second, nil, options...)
 	require.NoError(t, err)
 
 	cleanup := func() {
@@ -101,7 +128,7 @@ func NewTestClusterTx(t *testing.T) (*ClusterTx, func()) {
 //
 // Return the directory backing the test server and a newly created server
 // store that can be used to connect to it.
-func NewTestDqliteServer(t *testing.T) (string, driver.NodeStore, func()) {
+func NewTestDqliteServer(t testing.TB) (string, driver.NodeStore, func()) {
 	t.Helper()
 
 	listener, err := net.Listen("unix", "")
@@ -135,7 +162,7 @@ func NewTestDqliteServer(t *testing.T) (string, driver.NodeStore, func()) {
 }
 
 // Return a new temporary directory.
-func newDir(t *testing.T) (string, func()) {
+func newDir(t testing.TB) (string, func()) {
 	t.Helper()
 
 	dir, err := os.MkdirTemp("", "dqlite-replication-test-")
@@ -153,7 +180,7 @@ func newDir(t *testing.T) (string, func()) {
 	return dir, cleanup
 }
 
-func newLogFunc(t *testing.T) client.LogFunc {
+func newLogFunc(t testing.TB) client.LogFunc {
 	return func(l client.LogLevel, format string, a ...any) {
 		format = fmt.Sprintf("%s: %s", l.String(), format)
 		t.Logf(format, a...)
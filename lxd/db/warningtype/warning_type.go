@@ -58,6 +58,16 @@ const (
 	StoragePoolUnvailable
 	// UnableToUpdateClusterCertificate represents the unable to update cluster certificate warning.
 	UnableToUpdateClusterCertificate
+	// OrphanedResourcesRemoved represents the removal of resources left behind by stopped instances or operations.
+	OrphanedResourcesRemoved
+	// InstanceAgentUnresponsive represents a VM agent that stopped reporting its status.
+	InstanceAgentUnresponsive
+	// ClusterMemberDrift represents settings that differ between cluster members.
+	ClusterMemberDrift
+	// InstanceImageOutdated represents an instance whose base image was refreshed to a newer image.
+	InstanceImageOutdated
+	// ImageReplicationFailure represents an image which isn't replicated on enough cluster members.
+	ImageReplicationFailure
 )
 
 // TypeNames associates a warning code to its name.
@@ -88,6 +98,11 @@ var TypeNames = map[Type]string{
 	InstanceTypeNotOperational:             "Instance type not operational",
 	StoragePoolUnvailable:                  "Storage pool unavailable",
 	UnableToUpdateClusterCertificate:       "Unable to update cluster certificate",
+	OrphanedResourcesRemoved:               "Orphaned resources removed",
+	InstanceAgentUnresponsive:              "Instance agent unresponsive",
+	ClusterMemberDrift:                     "Settings differ between cluster members",
+	InstanceImageOutdated:                  "Instance image outdated",
+	ImageReplicationFailure:                "Image not replicated on enough cluster members",
 }
 
 // Severity returns the severity of the warning type.
@@ -145,6 +160,16 @@ func (t Type) Severity() Severity {
 		return SeverityHigh
 	case UnableToUpdateClusterCertificate:
 		return SeverityLow
+	case OrphanedResourcesRemoved:
+		return SeverityLow
+	case InstanceAgentUnresponsive:
+		return SeverityModerate
+	case ClusterMemberDrift:
+		return SeverityModerate
+	case InstanceImageOutdated:
+		return SeverityLow
+	case ImageReplicationFailure:
+		return SeverityModerate
 	}
 
 	return SeverityLow
@@ -64,6 +64,7 @@ type RunConfig struct {
 	USBDevice        []USBDeviceItem  // USB device configuration settings.
 	TPMDevice        []RunConfigItem  // TPM device configuration settings.
 	PCIDevice        []RunConfigItem  // PCI device configuration settings.
+	ShmemDevice      []RunConfigItem  // Shared memory device configuration settings.
 	Revert           revert.Hook      // Revert setup of device on post-setup error.
 }
 
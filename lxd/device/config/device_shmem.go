@@ -0,0 +1,12 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// ShmemPath returns the host path of a named shared memory region of a project.
+func ShmemPath(projectName string, regionName string) string {
+	return shared.VarPath("shmem", fmt.Sprintf("%s_%s", projectName, regionName))
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
 )
 
 // Device represents a LXD container device.
@@ -72,6 +73,19 @@ func (device Device) Validate(rules map[string]func(value string) error) error {
 			continue
 		}
 
+		if k == "startup.order" {
+			if device["type"] == "disk" && device["path"] == "/" {
+				return fmt.Errorf("Invalid device option %q: Cannot be set on the root disk", k)
+			}
+
+			err := validate.IsInt64(device[k])
+			if err != nil {
+				return fmt.Errorf("Invalid value for device option %q: %w", k, err)
+			}
+
+			continue
+		}
+
 		return fmt.Errorf("Invalid device option %q", k)
 	}
 
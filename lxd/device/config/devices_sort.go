@@ -1,5 +1,9 @@
 package config
 
+import (
+	"strconv"
+)
+
 // DeviceNamed contains the name of a device and its config.
 type DeviceNamed struct {
 	Name   string
@@ -17,7 +21,12 @@ func (devices DevicesSortable) Less(i, j int) bool {
 	a := devices[i]
 	b := devices[j]
 
-	// First sort by types.
+	// First sort by the explicit startup order, if any.
+	if a.Config.startupOrder() != b.Config.startupOrder() {
+		return a.Config.startupOrder() < b.Config.startupOrder()
+	}
+
+	// Then sort by types.
 	if a.Config["type"] != b.Config["type"] {
 		// In VMs, network interface names are derived from PCI
 		// location. As a result of that, we must ensure that nic devices will
@@ -78,3 +87,9 @@ func (devices DevicesSortable) Less(i, j int) bool {
 func (devices DevicesSortable) Swap(i, j int) {
 	devices[i], devices[j] = devices[j], devices[i]
 }
+
+// startupOrder returns the value of the "startup.order" option of the device, or zero if not set (or invalid).
+func (device Device) startupOrder() int64 {
+	order, _ := strconv.ParseInt(device["startup.order"], 10, 64)
+	return order
+}
@@ -49,3 +49,24 @@ func TestSortableDevices(t *testing.T) {
 	result = devices.Reversed()
 	assert.Equal(t, expectedReversed, result)
 }
+
+func TestSortableDevicesStartupOrder(t *testing.T) {
+	devices := Devices{
+		"eth0":   Device{"type": "nic"},
+		"eth1":   Device{"type": "nic", "startup.order": "10"},
+		"gpu0":   Device{"type": "gpu", "startup.order": "-1"},
+		"root":   Device{"type": "disk", "path": "/"},
+		"shared": Device{"type": "disk", "path": "/srv"},
+	}
+
+	expectedSorted := DevicesSortable{
+		DeviceNamed{Name: "gpu0", Config: Device{"type": "gpu", "startup.order": "-1"}},
+		DeviceNamed{Name: "eth0", Config: Device{"type": "nic"}},
+		DeviceNamed{Name: "root", Config: Device{"type": "disk", "path": "/"}},
+		DeviceNamed{Name: "shared", Config: Device{"type": "disk", "path": "/srv"}},
+		DeviceNamed{Name: "eth1", Config: Device{"type": "nic", "startup.order": "10"}},
+	}
+
+	result := devices.Sorted()
+	assert.Equal(t, expectedSorted, result)
+}
@@ -51,7 +51,8 @@ func DiskParseRBDFormat(rbd string) (poolName string, volumeName string, options
 }
 
 // DiskGetRBDFormat returns a rbd formatted string with the given values.
-func DiskGetRBDFormat(clusterName string, userName string, poolName string, volumeName string) string {
+// The namespace option is only included when a RADOS namespace is specified.
+func DiskGetRBDFormat(clusterName string, userName string, poolName string, namespace string, volumeName string) string {
 	// Configuration values containing :, @, or = can be escaped with a leading \ character.
 	// According to https://docs.ceph.com/docs/hammer/rbd/qemu-rbd/#usage
 	optEscaper := strings.NewReplacer(":", `\:`, "@", `\@`, "=", `\=`)
@@ -61,6 +62,10 @@ func DiskGetRBDFormat(clusterName string, userName string, poolName string, volu
 		fmt.Sprintf("conf=/etc/ceph/%s.conf", optEscaper.Replace(clusterName)),
 	}
 
+	if namespace != "" {
+		opts = append(opts, fmt.Sprintf("namespace=%s", optEscaper.Replace(namespace)))
+	}
+
 	return fmt.Sprintf("%s%s%s/%s%s%s", RBDFormatPrefix, RBDFormatSeparator, optEscaper.Replace(poolName), optEscaper.Replace(volumeName), RBDFormatSeparator, strings.Join(opts, ":"))
 }
 
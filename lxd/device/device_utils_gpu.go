@@ -1,8 +1,11 @@
 package device
 
 import (
+	"fmt"
 	"strings"
 
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/validate"
 )
 
@@ -14,3 +17,68 @@ func gpuValidMigUUID(value string) error {
 
 	return validate.IsUUID(strings.TrimPrefix(value, "MIG-"))
 }
+
+// GPUMdevAvailable checks that the mediated devices needed by the mdev GPU devices in the supplied devices
+// can be created on a server with the supplied resources. Each device is allocated to the first card (or
+// virtual function) that it selects and that has an available mediated device of the requested profile.
+func GPUMdevAvailable(devices deviceConfig.Devices, res *api.Resources) error {
+	// Track the remaining mediated devices per PCI address and profile.
+	remaining := make(map[string]uint64)
+	available := func(pciAddress string, profile string, mdev map[string]api.ResourcesGPUCardMdev) bool {
+		v, ok := mdev[profile]
+		if !ok {
+			return false
+		}
+
+		key := pciAddress + "/" + profile
+		_, ok = remaining[key]
+		if !ok {
+			remaining[key] = v.Available
+		}
+
+		if remaining[key] == 0 {
+			return false
+		}
+
+		remaining[key]--
+
+		return true
+	}
+
+	for _, devName := range devices.Sorted() {
+		dev := devName.Config
+		if dev["type"] != "gpu" || dev["gputype"] != "mdev" {
+			continue
+		}
+
+		allocated := false
+		for _, gpu := range res.GPU.Cards {
+			if !gpuSelected(dev, gpu) {
+				continue
+			}
+
+			// Like on start, only look at the virtual functions if the card itself lacks the profile.
+			_, found := gpu.Mdev[dev["mdev"]]
+			if found {
+				allocated = available(gpu.PCIAddress, dev["mdev"], gpu.Mdev)
+			} else if gpu.SRIOV != nil {
+				for _, vf := range gpu.SRIOV.VFs {
+					if available(vf.PCIAddress, dev["mdev"], vf.Mdev) {
+						allocated = true
+						break
+					}
+				}
+			}
+
+			if allocated {
+				break
+			}
+		}
+
+		if !allocated {
+			return fmt.Errorf("No available mdev for profile %q for device %q", dev["mdev"], devName.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package device
+
+import (
+	"fmt"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func ExampleGPUMdevAvailable() {
+	res := &api.Resources{
+		GPU: api.ResourcesGPU{
+			Cards: []api.ResourcesGPUCard{
+				{
+					PCIAddress: "0000:00:02.0",
+					VendorID:   "8086",
+					Mdev: map[string]api.ResourcesGPUCardMdev{
+						"i915-GVTg_V5_4": {Available: 1},
+					},
+				},
+				{
+					PCIAddress: "0000:01:00.0",
+					VendorID:   "10de",
+					SRIOV: &api.ResourcesGPUCardSRIOV{
+						VFs: []api.ResourcesGPUCard{
+							{PCIAddress: "0000:01:00.4", Mdev: map[string]api.ResourcesGPUCardMdev{"nvidia-1": {Available: 1}}},
+							{PCIAddress: "0000:01:00.5", Mdev: map[string]api.ResourcesGPUCardMdev{"nvidia-1": {Available: 1}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []deviceConfig.Devices{
+		{"gpu0": {"type": "gpu", "gputype": "mdev", "mdev": "i915-GVTg_V5_4"}},                                                                       // available
+		{"gpu0": {"type": "gpu", "gputype": "mdev", "mdev": "i915-GVTg_V5_4", "vendorid": "10de"}},                                                   // not on selected card
+		{"gpu0": {"type": "gpu", "gputype": "mdev", "mdev": "i915-GVTg_V5_4"}, "gpu1": {"type": "gpu", "gputype": "mdev", "mdev": "i915-GVTg_V5_4"}}, // exhausted
+		{"gpu0": {"type": "gpu", "gputype": "mdev", "mdev": "nvidia-1"}, "gpu1": {"type": "gpu", "gputype": "mdev", "mdev": "nvidia-1"}},             // available on VFs
+		{"gpu0": {"type": "gpu", "gputype": "physical"}},                                                                                             // not mdev
+	}
+
+	for _, devices := range tests {
+		err := GPUMdevAvailable(devices, res)
+		fmt.Println(err)
+	}
+
+	// Output: <nil>
+	// No available mdev for profile "i915-GVTg_V5_4" for device "gpu0"
+	// No available mdev for profile "i915-GVTg_V5_4" for device "gpu1"
+	// <nil>
+	// <nil>
+}
@@ -518,6 +518,12 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 		}
 	}
 
+	// Resolve the target to mirror the traffic to, if any.
+	mirrorActions, err := networkHostVethMirrorActions(d, veth)
+	if err != nil {
+		return err
+	}
+
 	// Clean any existing entry
 	qdisc := &ip.Qdisc{Dev: veth, Root: true}
 	_ = qdisc.Delete()
@@ -538,7 +544,8 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 			return fmt.Errorf("Failed to create limit tc class: %s", err)
 		}
 
-		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "1:0", Protocol: "all", Flowid: "1:1"}, Value: "0", Mask: "0"}
+		// Mirror the traffic as it's classified into the rate limited class.
+		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "1:0", Protocol: "all", Flowid: "1:1"}, Value: "0", Mask: "0", Actions: mirrorActions}
 		err = filter.Add()
 		if err != nil {
 			return fmt.Errorf("Failed to create tc filter: %s", err)
@@ -552,14 +559,21 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 			return fmt.Errorf("Failed to create ingress tc qdisc: %s", err)
 		}
 
-		police := &ip.ActionPolice{Rate: fmt.Sprintf("%dbit", egressInt), Burst: "1024k", Mtu: "64kb", Drop: true}
-		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "ffff:0", Protocol: "all"}, Value: "0", Mask: "0", Actions: []ip.Action{police}}
+		// Only mirror the traffic that conforms to the rate limit.
+		police := &ip.ActionPolice{Rate: fmt.Sprintf("%dbit", egressInt), Burst: "1024k", Mtu: "64kb", Drop: true, Pipe: len(mirrorActions) > 0}
+		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "ffff:0", Protocol: "all"}, Value: "0", Mask: "0", Actions: append([]ip.Action{police}, mirrorActions...)}
 		err = filter.Add()
 		if err != nil {
 			return fmt.Errorf("Failed to create ingress tc filter: %s", err)
 		}
 	}
 
+	// Apply traffic mirroring in the directions without limits.
+	err = networkSetupHostVethMirror(d, veth, mirrorActions)
+	if err != nil {
+		return err
+	}
+
 	var networkPriority uint64
 	if d.config["limits.priority"] != "" {
 		networkPriority, err = strconv.ParseUint(d.config["limits.priority"], 10, 32)
@@ -591,6 +605,115 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 	return nil
 }
 
+// networkHostVethMirrorActions returns the tc actions mirroring the traffic of the host side veth device to the
+// device specified in the security.mirror.target setting. Returns nil if the traffic isn't mirrored.
+func networkHostVethMirrorActions(d *deviceCommon, veth string) ([]ip.Action, error) {
+	if d.config["security.mirror.target"] == "" {
+		return nil, nil
+	}
+
+	target, err := networkMirrorTargetInterface(d.state, d.inst.Project().Name, d.config["security.mirror.target"])
+	if err != nil {
+		return nil, err
+	}
+
+	if target == veth {
+		return nil, fmt.Errorf("Mirror target %q cannot be the device itself", d.config["security.mirror.target"])
+	}
+
+	return []ip.Action{&ip.ActionMirred{Dev: target}}, nil
+}
+
+// networkSetupHostVethMirror mirrors the traffic of the host side veth device in the directions without limits.
+// The traffic in the limited directions is mirrored by the limit filters once it has passed the limits.
+// Must be called after the limits have been set up by networkSetupHostVethLimits.
+func networkSetupHostVethMirror(d *deviceCommon, veth string, mirrorActions []ip.Action) error {
+	if len(mirrorActions) == 0 {
+		return nil
+	}
+
+	// Traffic sent to the instance leaves through the root qdisc of the host side device.
+	if d.config["limits.ingress"] == "" {
+		qdiscPrio := &ip.QdiscPrio{Qdisc: ip.Qdisc{Dev: veth, Handle: "1:0", Root: true}}
+		err := qdiscPrio.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create root tc qdisc: %s", err)
+		}
+
+		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "1:0", Protocol: "all"}, Value: "0", Mask: "0", Actions: mirrorActions}
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create mirror tc filter: %s", err)
+		}
+	}
+
+	// Traffic sent by the instance enters through the ingress qdisc of the host side device.
+	if d.config["limits.egress"] == "" {
+		qdisc := &ip.Qdisc{Dev: veth, Handle: "ffff:0", Ingress: true}
+		err := qdisc.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create ingress tc qdisc: %s", err)
+		}
+
+		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "ffff:0", Protocol: "all"}, Value: "0", Mask: "0", Actions: mirrorActions}
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create ingress mirror tc filter: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// networkMirrorTargetInterface returns the host interface to mirror traffic to for the given mirror target.
+// The target is either a host interface name or an instance NIC in the form <instance>/<device>.
+func networkMirrorTargetInterface(s *state.State, projectName string, target string) (string, error) {
+	instName, devName, found := strings.Cut(target, "/")
+	if !found {
+		if !network.InterfaceExists(target) {
+			return "", fmt.Errorf("Mirror target interface %q not found", target)
+		}
+
+		return target, nil
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, instName)
+	if err != nil {
+		return "", fmt.Errorf("Failed loading mirror target instance %q: %w", instName, err)
+	}
+
+	if !inst.IsRunning() {
+		return "", fmt.Errorf("Mirror target instance %q isn't running", instName)
+	}
+
+	hostName := inst.LocalConfig()[fmt.Sprintf("volatile.%s.host_name", devName)]
+	if hostName == "" || !network.InterfaceExists(hostName) {
+		return "", fmt.Errorf("Mirror target device %q not found on instance %q", devName, instName)
+	}
+
+	return hostName, nil
+}
+
+// networkValidMirrorTarget validates the security.mirror.target value.
+func networkValidMirrorTarget(value string) error {
+	instName, devName, found := strings.Cut(value, "/")
+	if !found {
+		return validate.IsInterfaceName(value)
+	}
+
+	err := instance.ValidName(instName, false)
+	if err != nil {
+		return fmt.Errorf("Invalid mirror target instance name: %w", err)
+	}
+
+	err = validate.IsDeviceName(devName)
+	if err != nil {
+		return fmt.Errorf("Invalid mirror target device name: %w", err)
+	}
+
+	return nil
+}
+
 // networkClearHostVethLimits clears any network rate limits to the veth device specified in the config.
 func networkClearHostVethLimits(d *deviceCommon) error {
 	err := d.state.Firewall.InstanceClearNetPrio(d.inst.Project().Name, d.inst.Name(), d.config["host_name"])
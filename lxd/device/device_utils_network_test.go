@@ -0,0 +1,41 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkValidMirrorTarget(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		// Host interfaces.
+		{value: "eth0"},
+		{value: "ids0"},
+		{value: "eth0.100"},
+		{value: "", wantErr: true},
+		{value: "averyveryverylongname", wantErr: true},
+		{value: "eth 0", wantErr: true},
+
+		// Instance NICs.
+		{value: "ids/eth0"},
+		{value: "ids-1/eth-mirror"},
+		{value: "ids/", wantErr: true},
+		{value: "/eth0", wantErr: true},
+		{value: "ids_1/eth0", wantErr: true},
+		{value: "ids/eth 0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := networkValidMirrorTarget(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -59,6 +59,10 @@ const DiskIOUring = "io_uring"
 // DiskLoopBacked is used to indicate disk is backed onto a loop device.
 const DiskLoopBacked = "loop"
 
+// DiskVhostUserBlk is used to indicate disk is served by a vhost-user-blk target (the mount dev path is the
+// target's socket path).
+const DiskVhostUserBlk = "vhost-user-blk"
+
 type diskBlockLimit struct {
 	readBps   int64
 	readIops  int64
@@ -112,6 +116,11 @@ func (d *disk) sourceIsCeph() bool {
 	return strings.HasPrefix(d.config["source"], "ceph:")
 }
 
+// sourceIsVhostUserBlk returns true if the disks source config setting is a vhost-user-blk socket.
+func (d *disk) sourceIsVhostUserBlk() bool {
+	return strings.HasPrefix(d.config["source"], "vhost-user-blk:")
+}
+
 // CanHotPlug returns whether the device can be managed whilst the instance is running.
 func (d *disk) CanHotPlug() bool {
 	// Containers support hot-plugging all disk types.
@@ -140,7 +149,8 @@ func (d *disk) isRequired(devConfig deviceConfig.Device) bool {
 }
 
 // sourceIsLocalPath returns true if the source supplied should be considered a local path on the host.
-// It returns false if the disk source is empty, a VM cloud-init config drive, or a remote ceph/cephfs path.
+// It returns false if the disk source is empty, a VM cloud-init config drive, a remote ceph/cephfs path or a
+// vhost-user-blk socket.
 func (d *disk) sourceIsLocalPath(source string) bool {
 	if source == "" {
 		return false
@@ -150,7 +160,7 @@ func (d *disk) sourceIsLocalPath(source string) bool {
 		return false
 	}
 
-	if d.sourceIsCeph() || d.sourceIsCephFs() {
+	if d.sourceIsCeph() || d.sourceIsCephFs() || d.sourceIsVhostUserBlk() {
 		return false
 	}
 
@@ -330,14 +340,52 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		//  shortdesc: Caching mode for the device
 		"io.cache": validate.Optional(validate.IsOneOf("none", "writeback", "unsafe")),
 		// lxdmeta:generate(entities=device-disk; group=device-conf; key=io.bus)
-		// Possible values are `virtio-scsi` or `nvme`.
+		// Possible values are `virtio-scsi`, `virtio-blk` or `nvme`.
 		// ---
 		//  type: string
 		//  defaultdesc: `virtio-scsi`
 		//  required: no
 		//  condition: virtual machine
 		//  shortdesc: Bus for the device
-		"io.bus": validate.Optional(validate.IsOneOf("virtio-scsi", "nvme")),
+		"io.bus": validate.Optional(validate.IsOneOf("virtio-scsi", "virtio-blk", "nvme")),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=io.threads)
+		// Each I/O thread is a dedicated QEMU thread that processes the requests of the disk outside of the main loop.
+		// When using more than one I/O thread, the queues of the disk are distributed between them (requires QEMU 9.0 or later).
+		// ---
+		//  type: integer
+		//  required: no
+		//  condition: virtual machine with `virtio-blk` bus
+		//  shortdesc: Number of dedicated I/O threads for the device
+		"io.threads": validate.Optional(validate.IsInRange(1, 64)),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=io.threads.cpus)
+		// A comma-separated list of host CPU IDs or ranges (for example, `0-3,8`) to pin the I/O threads of the disk to.
+		// ---
+		//  type: string
+		//  required: no
+		//  condition: virtual machine with `io.threads`
+		//  shortdesc: Host CPUs to pin the I/O threads to
+		"io.threads.cpus": validate.Optional(validate.IsValidCPUSet),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=io.queue_size)
+		// The size must be a power of two.
+		// ---
+		//  type: integer
+		//  defaultdesc: `256`
+		//  required: no
+		//  condition: virtual machine with `virtio-blk` bus
+		//  shortdesc: Size of each virtqueue of the device (queue depth)
+		"io.queue_size": validate.Optional(func(value string) error {
+			err := validate.IsInRange(2, 1024)(value)
+			if err != nil {
+				return err
+			}
+
+			size, _ := strconv.Atoi(value)
+			if size&(size-1) != 0 {
+				return fmt.Errorf("Queue size must be a power of two")
+			}
+
+			return nil
+		}),
 	}
 
 	err := d.config.Validate(rules)
@@ -353,6 +401,24 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("IO cache configuration cannot be applied to containers")
 	}
 
+	for _, key := range []string{"io.threads", "io.threads.cpus", "io.queue_size"} {
+		if d.config[key] == "" {
+			continue
+		}
+
+		if instConf.Type() == instancetype.Container {
+			return fmt.Errorf("IO tuning configuration cannot be applied to containers")
+		}
+
+		if d.config["io.bus"] != "virtio-blk" {
+			return fmt.Errorf(`The %q option requires "io.bus" to be set to "virtio-blk"`, key)
+		}
+	}
+
+	if d.config["io.threads.cpus"] != "" && d.config["io.threads"] == "" {
+		return fmt.Errorf(`The "io.threads.cpus" option requires "io.threads" to be set`)
+	}
+
 	if d.config["required"] != "" && d.config["optional"] != "" {
 		return fmt.Errorf(`Cannot use both "required" and deprecated "optional" properties at the same time`)
 	}
@@ -385,6 +451,37 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("Recursive read-only bind-mounts aren't currently supported by the kernel")
 	}
 
+	if d.sourceIsVhostUserBlk() {
+		if instConf.Type() != instancetype.VM {
+			return fmt.Errorf("vhost-user-blk disks are only supported by virtual machines")
+		}
+
+		sockPath := strings.TrimPrefix(d.config["source"], "vhost-user-blk:")
+		if !filepath.IsAbs(sockPath) {
+			return fmt.Errorf("vhost-user-blk socket path must be absolute")
+		}
+
+		if d.config["pool"] != "" || d.config["path"] != "" {
+			return fmt.Errorf(`vhost-user-blk disks cannot have a "pool" or "path" property set`)
+		}
+
+		for _, key := range []string{"io.bus", "io.cache", "io.threads", "io.threads.cpus", "io.queue_size", "limits.read", "limits.write", "limits.max", "readonly"} {
+			if d.config[key] != "" {
+				return fmt.Errorf("Invalid option %q for vhost-user-blk disks (managed by the vhost-user-blk target)", key)
+			}
+		}
+
+		// The vhost-user-blk target accesses the guest memory directly, so it must be backed by shared
+		// hugepages. Only check this for instances as profiles don't necessarily carry the memory config.
+		if d.inst != nil && !shared.IsTrue(instConf.ExpandedConfig()["limits.memory.hugepages"]) {
+			return fmt.Errorf(`vhost-user-blk disks require "limits.memory.hugepages" to be enabled`)
+		}
+
+		if d.inst != nil && d.isRequired(d.config) && !shared.PathExists(shared.HostPath(sockPath)) {
+			return fmt.Errorf("Missing vhost-user-blk socket %q for disk %q", sockPath, d.name)
+		}
+	}
+
 	// Check ceph options are only used when ceph or cephfs type source is specified.
 	if !(d.sourceIsCeph() || d.sourceIsCephFs()) && (d.config["ceph.cluster_name"] != "" || d.config["ceph.user_name"] != "") {
 		return fmt.Errorf("Invalid options ceph.cluster_name/ceph.user_name for source %q", d.config["source"])
@@ -460,22 +557,28 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 					return fmt.Errorf("Failed loading custom volume: %w", err)
 				}
 
-				// Check storage volume is available to mount on this cluster member.
-				remoteInstance, err := storagePools.VolumeUsedByExclusiveRemoteInstancesWithProfiles(d.state, d.config["pool"], storageProjectName, &dbVolume.StorageVolume)
+				contentType, err := storagePools.VolumeContentTypeNameToContentType(dbVolume.ContentType)
 				if err != nil {
-					return fmt.Errorf("Failed checking if custom volume is exclusively attached to another instance: %w", err)
+					return err
 				}
 
-				if remoteInstance != nil {
-					return fmt.Errorf("Custom volume is already attached to an instance on a different node")
-				}
+				readOnly := shared.IsTrue(d.config["readonly"])
 
-				// Check that block volumes are *only* attached to VM instances.
-				contentType, err := storagePools.VolumeContentTypeNameToContentType(dbVolume.ContentType)
-				if err != nil {
-					return err
+				// Check storage volume is available to mount on this cluster member.
+				// Block volumes that are shared between instances can be attached on multiple members, in
+				// which case the sharing check below applies instead.
+				if contentType != cluster.StoragePoolVolumeContentTypeBlock || (!readOnly && shared.IsFalseOrEmpty(dbVolume.Config["security.shared"])) {
+					remoteInstance, err := storagePools.VolumeUsedByExclusiveRemoteInstancesWithProfiles(d.state, d.config["pool"], storageProjectName, &dbVolume.StorageVolume)
+					if err != nil {
+						return fmt.Errorf("Failed checking if custom volume is exclusively attached to another instance: %w", err)
+					}
+
+					if remoteInstance != nil {
+						return fmt.Errorf("Custom volume is already attached to an instance on a different node")
+					}
 				}
 
+				// Check that block volumes are *only* attached to VM instances.
 				if contentType == cluster.StoragePoolVolumeContentTypeBlock {
 					if instConf.Type() == instancetype.Container {
 						return fmt.Errorf("Custom block volumes cannot be used on containers")
@@ -484,6 +587,20 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 					if d.config["path"] != "" {
 						return fmt.Errorf("Custom block volumes cannot have a path defined")
 					}
+
+					// Check that the volume isn't attached to other instances in a conflicting way.
+					conflictInstance, err := storagePools.VolumeBlockSharingConflict(d.state, d.config["pool"], storageProjectName, &dbVolume.StorageVolume, d.inst.Project().Name, d.inst.Name(), readOnly)
+					if err != nil {
+						return fmt.Errorf("Failed checking if custom block volume is attached to other instances: %w", err)
+					}
+
+					if conflictInstance != nil {
+						if readOnly {
+							return fmt.Errorf("Custom block volume is attached with write access to instance %q in project %q", conflictInstance.Name, conflictInstance.Project)
+						}
+
+						return fmt.Errorf(`Custom block volume is already attached to instance %q in project %q (attach it with "readonly=true" on all instances or enable "security.shared" on the volume)`, conflictInstance.Name, conflictInstance.Project)
+					}
 				} else if contentType == cluster.StoragePoolVolumeContentTypeISO {
 					if instConf.Type() == instancetype.Container {
 						return fmt.Errorf("Custom ISO volumes cannot be used on containers")
@@ -906,6 +1023,19 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 		opts = append(opts, fmt.Sprintf("cache=%s", d.config["io.cache"]))
 	}
 
+	// Add the I/O tuning settings if set.
+	if d.config["io.threads"] != "" {
+		opts = append(opts, fmt.Sprintf("iothreads=%s", d.config["io.threads"]))
+	}
+
+	if d.config["io.threads.cpus"] != "" {
+		opts = append(opts, fmt.Sprintf("iothreads.cpus=%s", d.config["io.threads.cpus"]))
+	}
+
+	if d.config["io.queue_size"] != "" {
+		opts = append(opts, fmt.Sprintf("queue_size=%s", d.config["io.queue_size"]))
+	}
+
 	// Add I/O limits if set.
 	var diskLimits *deviceConfig.DiskLimits
 	if d.config["limits.read"] != "" || d.config["limits.write"] != "" || d.config["limits.max"] != "" {
@@ -941,6 +1071,23 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 			},
 		}
 
+		return &runConf, nil
+	} else if d.sourceIsVhostUserBlk() {
+		// The disk is served by an external vhost-user-blk target (such as SPDK), so only pass its socket
+		// path to QEMU.
+		sockPath := shared.HostPath(strings.TrimPrefix(d.config["source"], "vhost-user-blk:"))
+		if !shared.PathExists(sockPath) {
+			return nil, diskSourceNotFoundError{msg: fmt.Sprintf("Missing vhost-user-blk socket %q", sockPath)}
+		}
+
+		runConf.Mounts = []deviceConfig.MountEntryItem{
+			{
+				DevPath: sockPath,
+				DevName: d.name,
+				Opts:    append(opts, DiskVhostUserBlk),
+			},
+		}
+
 		return &runConf, nil
 	} else if d.config["source"] == diskSourceCloudInit {
 		// This is a special virtual disk source that can be attached to a VM to provide cloud-init config.
@@ -979,7 +1126,7 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 			clusterName, userName := d.cephCreds()
 			runConf.Mounts = []deviceConfig.MountEntryItem{
 				{
-					DevPath: DiskGetRBDFormat(clusterName, userName, fields[0], fields[1]),
+					DevPath: DiskGetRBDFormat(clusterName, userName, fields[0], "", fields[1]),
 					DevName: d.name,
 					Opts:    opts,
 					Limits:  diskLimits,
@@ -1044,7 +1191,7 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 					}
 
 					mount := deviceConfig.MountEntryItem{
-						DevPath: DiskGetRBDFormat(clusterName, userName, poolName, d.config["source"]),
+						DevPath: DiskGetRBDFormat(clusterName, userName, poolName, config["ceph.osd.pool_namespace"], d.config["source"]),
 						DevName: d.name,
 						Opts:    opts,
 						Limits:  diskLimits,
@@ -1052,6 +1199,8 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 
 					if contentType == cluster.StoragePoolVolumeContentTypeISO {
 						mount.FSType = "iso9660"
+					} else if shared.IsTrue(d.config["readonly"]) {
+						mount.Opts = append(mount.Opts, "ro")
 					}
 
 					runConf.Mounts = []deviceConfig.MountEntryItem{mount}
@@ -2383,13 +2532,7 @@ func (d *disk) generateVMConfigDrive() (string, error) {
 	instanceConfig := d.inst.ExpandedConfig()
 
 	// Use an empty vendor-data file if no custom vendor-data supplied.
-	vendorData, ok := instanceConfig["cloud-init.vendor-data"]
-	if !ok {
-		vendorData = instanceConfig["user.vendor-data"]
-		if vendorData == "" {
-			vendorData = "#cloud-config\n{}"
-		}
-	}
+	vendorData := instance.CloudInitData(instanceConfig, "vendor-data")
 
 	err = os.WriteFile(filepath.Join(scratchDir, "vendor-data"), []byte(vendorData), 0400)
 	if err != nil {
@@ -2397,13 +2540,7 @@ func (d *disk) generateVMConfigDrive() (string, error) {
 	}
 
 	// Use an empty user-data file if no custom user-data supplied.
-	userData, ok := instanceConfig["cloud-init.user-data"]
-	if !ok {
-		userData = instanceConfig["user.user-data"]
-		if userData == "" {
-			userData = "#cloud-config\n{}"
-		}
-	}
+	userData := instance.CloudInitData(instanceConfig, "user-data")
 
 	err = os.WriteFile(filepath.Join(scratchDir, "user-data"), []byte(userData), 0400)
 	if err != nil {
@@ -2411,11 +2548,7 @@ func (d *disk) generateVMConfigDrive() (string, error) {
 	}
 
 	// Include a network-config file if the user configured it.
-	networkConfig, ok := instanceConfig["cloud-init.network-config"]
-	if !ok {
-		networkConfig = instanceConfig["user.network-config"]
-	}
-
+	networkConfig := instance.CloudInitData(instanceConfig, "network-config")
 	if networkConfig != "" {
 		err = os.WriteFile(filepath.Join(scratchDir, "network-config"), []byte(networkConfig), 0400)
 		if err != nil {
@@ -289,6 +289,21 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  managed: no
 		//  shortdesc: Whether to respect port isolation
 		"security.port_isolation": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=security.mirror.target)
+		// Set this option to mirror all traffic sent and received by the NIC to another device, for example to feed an intrusion detection system.
+		// Specify either the name of a host interface or a NIC of another running instance on the same server in the form `<instance>/<device>`.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Device to mirror the NIC traffic to
+
+		// lxdmeta:generate(entities=device-nic-{p2p+routed}; group=device-conf; key=security.mirror.target)
+		// Set this option to mirror all traffic sent and received by the NIC to another device, for example to feed an intrusion detection system.
+		// Specify either the name of a host interface or a NIC of another running instance on the same server in the form `<instance>/<device>`.
+		// ---
+		//  type: string
+		//  shortdesc: Device to mirror the NIC traffic to
+		"security.mirror.target": validate.Optional(networkValidMirrorTarget),
 		// lxdmeta:generate(entities=device-nic-{bridged+macvlan+sriov}; group=device-conf; key=maas.subnet.ipv4)
 		//
 		// ---
@@ -1,6 +1,7 @@
 package device
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strings"
@@ -12,6 +13,8 @@ import (
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/netutils"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/validate"
 )
@@ -49,6 +52,22 @@ func (d *nicIPVLAN) validateConfig(instConf instance.ConfigReader) error {
 
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
 	rules["gvrp"] = validate.Optional(validate.IsBool)
+	// lxdmeta:generate(entities=device-nic-ipvlan; group=device-conf; key=liveness.check)
+	// When enabled in `l3s` mode, the neighbour proxy entries of the instance addresses are only added on
+	// the parent interface while the instance is running and the addresses are configured on the instance
+	// interface.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to only announce the instance addresses on the parent network while they are live
+	rules["liveness.check"] = validate.Optional(validate.IsBool)
+	// lxdmeta:generate(entities=device-nic-ipvlan; group=device-conf; key=liveness.interval)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `10`
+	//  shortdesc: Interval in seconds between the liveness checks of the instance
+	rules["liveness.interval"] = validate.Optional(validate.IsInRange(1, 3600))
 	rules["ipv4.address"] = func(value string) error {
 		if value == "" {
 			return nil
@@ -278,6 +297,9 @@ func (d *nicIPVLAN) Start() (*deviceConfig.RunConfig, error) {
 		nic = append(nic, deviceConfig.RunConfigItem{Key: "mtu", Value: d.config["mtu"]})
 	}
 
+	// Addresses announced on the parent network once found live (if liveness checks are enabled).
+	var livenessAddresses []net.IP
+
 	// Perform network configuration.
 	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
 		var ipFamilyArg string
@@ -339,6 +361,12 @@ func (d *nicIPVLAN) Start() (*deviceConfig.RunConfig, error) {
 				}
 
 				// Add neighbour proxy entries on the host for l3s mode.
+				// With liveness checks, the entry is only added once the instance is running.
+				if shared.IsTrue(d.config["liveness.check"]) {
+					livenessAddresses = append(livenessAddresses, addr.IP)
+					continue
+				}
+
 				np := ip.NeighProxy{
 					DevName: parentName,
 					Addr:    addr.IP,
@@ -374,10 +402,89 @@ func (d *nicIPVLAN) Start() (*deviceConfig.RunConfig, error) {
 
 	runConf.NetworkInterface = nic
 
+	if len(livenessAddresses) > 0 {
+		d.startLiveness(parentName, livenessAddresses)
+	}
+
 	revert.Success()
 	return &runConf, nil
 }
 
+// startLiveness starts the liveness checks of the instance addresses.
+// The instance side of an ipvlan interface can't be reached from the host through the parent interface, so
+// the addresses are checked against the interfaces configured inside the instance instead.
+func (d *nicIPVLAN) startLiveness(parentName string, addresses []net.IP) {
+	probe := func(ctx context.Context, addr net.IP) (bool, error) {
+		pid := d.inst.InitPID()
+		if pid < 1 {
+			return false, nil
+		}
+
+		var networks map[string]api.InstanceStateNetwork
+		if d.state.OS.NetnsGetifaddrs {
+			var err error
+			networks, err = netutils.NetnsGetifaddrs(int32(pid), nil)
+			if err != nil {
+				return false, err
+			}
+		} else {
+			state, err := d.inst.RenderState(nil)
+			if err != nil {
+				return false, err
+			}
+
+			networks = state.Network
+		}
+
+		return ipvlanAddressConfigured(networks, d.config["name"], addr), nil
+	}
+
+	nicLivenessStart(&d.deviceCommon, parentName, addresses, nicLivenessInterval(d.config), probe)
+}
+
+// ipvlanAddressConfigured returns whether the address is configured on the named instance interface and
+// whether that interface is up.
+func ipvlanAddressConfigured(networks map[string]api.InstanceStateNetwork, name string, addr net.IP) bool {
+	nw, ok := networks[name]
+	if !ok || nw.State != "up" {
+		return false
+	}
+
+	for _, nwAddr := range nw.Addresses {
+		if addr.Equal(net.ParseIP(nwAddr.Address)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Register restarts the liveness checks of the instance addresses on LXD startup.
+func (d *nicIPVLAN) Register() error {
+	if !shared.IsTrue(d.config["liveness.check"]) || d.mode() != ipvlanModeL3S || !d.inst.IsRunning() {
+		return nil
+	}
+
+	var addresses []net.IP
+	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
+		for _, addrStr := range shared.SplitNTrimSpace(d.config[fmt.Sprintf("%s.address", keyPrefix)], ",", -1, true) {
+			addr, err := d.parseAddress(addrStr, keyPrefix, ipvlanModeL3S)
+			if err != nil {
+				return err
+			}
+
+			addresses = append(addresses, addr.IP)
+		}
+	}
+
+	if len(addresses) > 0 {
+		parentName := network.GetHostDevice(d.config["parent"], d.config["vlan"])
+		d.startLiveness(parentName, addresses)
+	}
+
+	return nil
+}
+
 // setupParentSysctls configures the required sysctls on the parent to allow l2proxy to work.
 // Because of our policy not to modify sysctls on existing interfaces, this should only be called
 // if we created the parent interface.
@@ -452,6 +559,9 @@ func (d *nicIPVLAN) postStop() error {
 	mode := d.mode()
 	parentName := network.GetHostDevice(d.config["parent"], d.config["vlan"])
 
+	// Stop the liveness checks (this removes the neighbour proxy entries they added).
+	nicLivenessStop(&d.deviceCommon)
+
 	// Clean up host-side network configuration.
 	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
 		var ipFamilyArg string
@@ -487,14 +597,16 @@ func (d *nicIPVLAN) postStop() error {
 					errs = append(errs, err)
 				}
 
-				np := ip.NeighProxy{
-					DevName: parentName,
-					Addr:    addr.IP,
-				}
+				if shared.IsFalseOrEmpty(d.config["liveness.check"]) {
+					np := ip.NeighProxy{
+						DevName: parentName,
+						Addr:    addr.IP,
+					}
 
-				err = np.Delete()
-				if err != nil {
-					errs = append(errs, err)
+					err = np.Delete()
+					if err != nil {
+						errs = append(errs, err)
+					}
 				}
 
 				// Remove static routes to instance IPs from custom routing tables if specified.
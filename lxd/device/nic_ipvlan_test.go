@@ -0,0 +1,50 @@
+package device
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestIPVLANAddressConfigured(t *testing.T) {
+	networks := map[string]api.InstanceStateNetwork{
+		"eth0": {
+			State: "up",
+			Addresses: []api.InstanceStateNetworkAddress{
+				{Family: "inet", Address: "192.0.2.10", Netmask: "32"},
+				{Family: "inet6", Address: "2001:db8::10", Netmask: "128"},
+			},
+		},
+		"eth1": {
+			State: "down",
+			Addresses: []api.InstanceStateNetworkAddress{
+				{Family: "inet", Address: "192.0.2.11", Netmask: "32"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		nic  string
+		addr string
+		want bool
+	}{
+		{name: "IPv4 address configured", nic: "eth0", addr: "192.0.2.10", want: true},
+		{name: "IPv6 address configured", nic: "eth0", addr: "2001:db8:0::10", want: true},
+		{name: "Address not configured", nic: "eth0", addr: "192.0.2.12"},
+		{name: "Address on another interface", nic: "eth0", addr: "192.0.2.11"},
+		{name: "Interface down", nic: "eth1", addr: "192.0.2.11"},
+		{name: "Interface missing", nic: "eth2", addr: "192.0.2.10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ipvlanAddressConfigured(networks, tt.nic, net.ParseIP(tt.addr)))
+		})
+	}
+
+	assert.False(t, ipvlanAddressConfigured(nil, "eth0", net.ParseIP("192.0.2.10")))
+}
@@ -0,0 +1,240 @@
+package device
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// nicLivenessDefaultInterval is the default interval between liveness checks of the NIC addresses.
+const nicLivenessDefaultInterval = 10 * time.Second
+
+// nicLivenessMonitors holds the running liveness monitors keyed by instance and device name.
+var nicLivenessMonitors = map[string]*nicLivenessMonitor{}
+var nicLivenessMonitorsMu sync.Mutex
+
+// nicLivenessAddress records the announcement state of a single NIC address.
+type nicLivenessAddress struct {
+	addr        net.IP
+	announced   bool
+	lastChecked time.Time
+	reason      string
+}
+
+// nicLivenessMonitor periodically checks the liveness of the NIC addresses and only keeps the neighbour proxy
+// entries on the parent interface for the addresses that are live.
+type nicLivenessMonitor struct {
+	d        *deviceCommon
+	parent   string
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	// probe checks whether the address is reachable. If nil, only the instance running state is checked.
+	probe func(ctx context.Context, addr net.IP) (bool, error)
+
+	mu        sync.Mutex
+	addresses []*nicLivenessAddress
+}
+
+// nicLivenessInterval returns the interval between the liveness checks configured on the NIC.
+func nicLivenessInterval(config map[string]string) time.Duration {
+	if config["liveness.interval"] == "" {
+		return nicLivenessDefaultInterval
+	}
+
+	seconds, err := strconv.Atoi(config["liveness.interval"])
+	if err != nil {
+		return nicLivenessDefaultInterval
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// nicLivenessKey returns the key of the liveness monitor of the given device.
+func nicLivenessKey(d *deviceCommon) string {
+	return project.Instance(d.inst.Project().Name, d.inst.Name()) + "/" + d.name
+}
+
+// nicLivenessStart starts the liveness monitor of the device, replacing any existing one.
+// The neighbour proxy entries of the addresses are only added once they are found live.
+func nicLivenessStart(d *deviceCommon, parent string, addresses []net.IP, interval time.Duration, probe func(ctx context.Context, addr net.IP) (bool, error)) {
+	nicLivenessStop(d)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m := &nicLivenessMonitor{
+		d:        d,
+		parent:   parent,
+		interval: interval,
+		probe:    probe,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	// Pick up the entries that already exist (for example when the monitor is restarted with LXD).
+	existing, err := (&ip.NeighProxy{DevName: parent}).Show()
+	if err != nil {
+		d.logger.Warn("Failed listing neighbour proxies", logger.Ctx{"parent": parent, "err": err})
+	}
+
+	for _, addr := range addresses {
+		a := &nicLivenessAddress{addr: addr, reason: "Not checked yet"}
+		for _, entry := range existing {
+			if entry.Addr.Equal(addr) {
+				a.announced = true
+				break
+			}
+		}
+
+		m.addresses = append(m.addresses, a)
+	}
+
+	nicLivenessMonitorsMu.Lock()
+	nicLivenessMonitors[nicLivenessKey(d)] = m
+	nicLivenessMonitorsMu.Unlock()
+
+	go m.run(ctx)
+}
+
+// nicLivenessStop stops the liveness monitor of the device (if any) and removes the neighbour proxy entries
+// it added.
+func nicLivenessStop(d *deviceCommon) {
+	key := nicLivenessKey(d)
+
+	nicLivenessMonitorsMu.Lock()
+	m, ok := nicLivenessMonitors[key]
+	delete(nicLivenessMonitors, key)
+	nicLivenessMonitorsMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	m.cancel()
+	<-m.done
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, a := range m.addresses {
+		if a.announced {
+			m.withdraw(a, "Device stopped")
+		}
+	}
+}
+
+// NICAnnouncements returns the neighbour proxy announcement state of the addresses of the given instance NIC.
+// Returns nil if the liveness checks aren't enabled on the NIC.
+func NICAnnouncements(projectName string, instanceName string, deviceName string) []api.InstanceStateNetworkAnnouncement {
+	nicLivenessMonitorsMu.Lock()
+	m, ok := nicLivenessMonitors[project.Instance(projectName, instanceName)+"/"+deviceName]
+	nicLivenessMonitorsMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	announcements := make([]api.InstanceStateNetworkAnnouncement, 0, len(m.addresses))
+	for _, a := range m.addresses {
+		announcements = append(announcements, api.InstanceStateNetworkAnnouncement{
+			Address:     a.addr.String(),
+			Parent:      m.parent,
+			Announced:   a.announced,
+			LastChecked: a.lastChecked,
+			Reason:      a.reason,
+		})
+	}
+
+	return announcements
+}
+
+// run performs the liveness checks until the context is cancelled.
+func (m *nicLivenessMonitor) run(ctx context.Context) {
+	defer close(m.done)
+
+	// Give the instance a moment to start before the first check.
+	delay := time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		m.check(ctx)
+		delay = m.interval
+	}
+}
+
+// check updates the neighbour proxy entries based on the current liveness of the addresses.
+func (m *nicLivenessMonitor) check(ctx context.Context) {
+	running := m.d.inst.IsRunning()
+
+	for _, a := range m.addresses {
+		live := running
+		reason := ""
+
+		if !running {
+			reason = "Instance not running"
+		} else if m.probe != nil {
+			probeCtx, cancel := context.WithTimeout(ctx, time.Second)
+			reachable, err := m.probe(probeCtx, a.addr)
+			cancel()
+
+			if err != nil {
+				m.d.logger.Debug("Failed checking NIC address liveness", logger.Ctx{"address": a.addr.String(), "err": err})
+			}
+
+			if !reachable {
+				live = false
+				reason = "Address not reachable"
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		m.mu.Lock()
+		a.lastChecked = time.Now()
+
+		if live && !a.announced {
+			np := ip.NeighProxy{DevName: m.parent, Addr: a.addr}
+			err := np.Add()
+			if err != nil {
+				m.d.logger.Warn("Failed adding neighbour proxy", logger.Ctx{"address": a.addr.String(), "parent": m.parent, "err": err})
+				reason = "Failed adding neighbour proxy"
+			} else {
+				a.announced = true
+			}
+		} else if !live && a.announced {
+			m.withdraw(a, reason)
+		}
+
+		a.reason = reason
+		m.mu.Unlock()
+	}
+}
+
+// withdraw removes the neighbour proxy entry of the address. Must be called with m.mu held.
+func (m *nicLivenessMonitor) withdraw(a *nicLivenessAddress, reason string) {
+	np := ip.NeighProxy{DevName: m.parent, Addr: a.addr}
+	err := np.Delete()
+	if err != nil {
+		m.d.logger.Warn("Failed removing neighbour proxy", logger.Ctx{"address": a.addr.String(), "parent": m.parent, "err": err})
+	}
+
+	a.announced = false
+	a.reason = reason
+}
@@ -0,0 +1,27 @@
+package device
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNicLivenessInterval(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: nicLivenessDefaultInterval},
+		{value: "1", want: time.Second},
+		{value: "30", want: 30 * time.Second},
+		{value: "3600", want: time.Hour},
+		{value: "foo", want: nicLivenessDefaultInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, nicLivenessInterval(map[string]string{"liveness.interval": tt.value}))
+		})
+	}
+}
@@ -38,6 +38,7 @@ func (d *nicP2P) validateConfig(instConf instance.ConfigReader) error {
 		"limits.egress",
 		"limits.max",
 		"limits.priority",
+		"security.mirror.target",
 		"ipv4.routes",
 		"ipv6.routes",
 		"boot.priority",
@@ -68,7 +69,7 @@ func (d *nicP2P) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "security.mirror.target", "ipv4.routes", "ipv6.routes"}
 }
 
 // Start is run when the device is added to a running instance or instance is starting up.
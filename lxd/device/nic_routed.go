@@ -43,7 +43,7 @@ func (d *nicRouted) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "security.mirror.target"}
 }
 
 // validateConfig checks the supplied config for correctness.
@@ -70,6 +70,7 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		"limits.egress",
 		"limits.max",
 		"limits.priority",
+		"security.mirror.target",
 		"ipv4.gateway",
 		"ipv6.gateway",
 		"ipv4.routes",
@@ -99,6 +100,22 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 	//  defaultdesc: `true`
 	//  shortdesc: Whether to probe the parent network for IPv6 address availability
 	rules["ipv6.neighbor_probe"] = validate.Optional(validate.IsBool)
+	// lxdmeta:generate(entities=device-nic-routed; group=device-conf; key=liveness.check)
+	// When enabled, the neighbour proxy entries of the instance addresses are only added on the parent
+	// interface while the instance is running and the addresses answer ARP/NDP requests on the host-side
+	// interface.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to only announce the instance addresses on the parent network while they are live
+	rules["liveness.check"] = validate.Optional(validate.IsBool)
+	// lxdmeta:generate(entities=device-nic-routed; group=device-conf; key=liveness.interval)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `10`
+	//  shortdesc: Interval in seconds between the liveness checks of the instance addresses
+	rules["liveness.interval"] = validate.Optional(validate.IsInRange(1, 3600))
 
 	err = d.config.Validate(rules)
 	if err != nil {
@@ -382,6 +399,9 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		return nil, fmt.Errorf("Error setting up reverse path filter: %w", err)
 	}
 
+	// Addresses announced on the parent network once found live (if liveness checks are enabled).
+	var livenessAddresses []net.IP
+
 	// Perform host-side address configuration.
 	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
 		subnetSize := 32
@@ -450,7 +470,10 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 			}
 
 			// If there is a parent interface, add neighbour proxy entry.
-			if d.effectiveParentName != "" {
+			// With liveness checks, the entry is only added once the address is found live.
+			if d.effectiveParentName != "" && shared.IsTrue(d.config["liveness.check"]) {
+				livenessAddresses = append(livenessAddresses, net.ParseIP(addrStr))
+			} else if d.effectiveParentName != "" {
 				np := ip.NeighProxy{
 					DevName: d.effectiveParentName,
 					Addr:    net.ParseIP(addrStr),
@@ -540,10 +563,43 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		}...)
 	}
 
+	if len(livenessAddresses) > 0 {
+		d.startLiveness(d.effectiveParentName, saveData["host_name"], livenessAddresses)
+	}
+
 	revert.Success()
 	return &runConf, nil
 }
 
+// startLiveness starts the liveness checks of the instance addresses on the host-side interface.
+func (d *nicRouted) startLiveness(parentName string, hostName string, addresses []net.IP) {
+	probe := func(ctx context.Context, addr net.IP) (bool, error) {
+		return isIPAvailable(ctx, addr, hostName)
+	}
+
+	nicLivenessStart(&d.deviceCommon, parentName, addresses, nicLivenessInterval(d.config), probe)
+}
+
+// Register restarts the liveness checks of the instance addresses on LXD startup.
+func (d *nicRouted) Register() error {
+	if !shared.IsTrue(d.config["liveness.check"]) || d.config["parent"] == "" || !d.inst.IsRunning() {
+		return nil
+	}
+
+	var addresses []net.IP
+	for _, key := range []string{"ipv4.address", "ipv6.address"} {
+		for _, addr := range shared.SplitNTrimSpace(d.config[key], ",", -1, true) {
+			addresses = append(addresses, net.ParseIP(addr))
+		}
+	}
+
+	if len(addresses) > 0 {
+		d.startLiveness(network.GetHostDevice(d.config["parent"], d.config["vlan"]), d.volatileGet()["host_name"], addresses)
+	}
+
+	return nil
+}
+
 // setupParentSysctls configures the required sysctls on the parent to allow l2proxy to work.
 // Because of our policy not to modify sysctls on existing interfaces, this should only be called
 // if we created the parent interface.
@@ -644,8 +700,11 @@ func (d *nicRouted) postStop() error {
 		}
 	}
 
+	// Stop the liveness checks (this removes the neighbour proxy entries they added).
+	nicLivenessStop(&d.deviceCommon)
+
 	// Delete IP neighbour proxy entries on the parent.
-	if d.effectiveParentName != "" {
+	if d.effectiveParentName != "" && shared.IsFalseOrEmpty(d.config["liveness.check"]) {
 		for _, key := range []string{"ipv4.address", "ipv6.address"} {
 			for _, addr := range shared.SplitNTrimSpace(d.config[key], ",", -1, true) {
 				neighProxy := &ip.NeighProxy{
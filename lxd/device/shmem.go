@@ -0,0 +1,285 @@
+package device
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// shmemBackendIvshmem exposes a shared memory region to the instance through an ivshmem-plain PCI device.
+const shmemBackendIvshmem = "ivshmem"
+
+// shmemBackendVhostUser connects the instance to a vhost-user backend on the host, sharing the instance memory.
+const shmemBackendVhostUser = "vhost-user"
+
+// shmemDefaultSize is the size of the shared memory region when not specified.
+const shmemDefaultSize = "4MiB"
+
+type shmem struct {
+	deviceCommon
+}
+
+// validateConfig checks the supplied config for correctness.
+func (d *shmem) validateConfig(instConf instance.ConfigReader) error {
+	if !instanceSupported(instConf.Type(), instancetype.VM) {
+		return ErrUnsupportedDevType
+	}
+
+	rules := map[string]func(string) error{
+		// lxdmeta:generate(entities=device-shmem; group=device-conf; key=backend)
+		// Possible values are `ivshmem` and `vhost-user`.
+		// ---
+		//  type: string
+		//  defaultdesc: `ivshmem`
+		//  shortdesc: How the shared memory is exposed to the instance
+		"backend": validate.Optional(validate.IsOneOf(shmemBackendIvshmem, shmemBackendVhostUser)),
+	}
+
+	if d.config["backend"] == shmemBackendVhostUser {
+		// lxdmeta:generate(entities=device-shmem; group=device-conf; key=source)
+		// Path of the socket of the vhost-user backend (for example a DPDK application) on the host.
+		// ---
+		//  type: string
+		//  required: for `vhost-user`
+		//  condition: `vhost-user` backend
+		//  shortdesc: Path to the vhost-user socket
+		rules["source"] = validate.IsAbsFilePath
+	} else {
+		// lxdmeta:generate(entities=device-shmem; group=device-conf; key=name)
+		// Instances of the same project on the same host that use the same region name share the region.
+		// When not set, the region is private to the instance and removed when it stops.
+		// ---
+		//  type: string
+		//  condition: `ivshmem` backend
+		//  shortdesc: Name of the shared memory region
+		rules["name"] = validate.Optional(validate.IsDeviceName)
+
+		// lxdmeta:generate(entities=device-shmem; group=device-conf; key=size)
+		// The size must be a power of two.
+		// ---
+		//  type: string
+		//  defaultdesc: `4MiB`
+		//  condition: `ivshmem` backend
+		//  shortdesc: Size of the shared memory region
+		rules["size"] = validate.Optional(func(value string) error {
+			size, err := units.ParseByteSizeString(value)
+			if err != nil {
+				return err
+			}
+
+			if size <= 0 || size&(size-1) != 0 {
+				return fmt.Errorf("Size must be a power of two")
+			}
+
+			return nil
+		})
+
+		// lxdmeta:generate(entities=device-shmem; group=device-conf; key=uid)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `0`
+		//  condition: `ivshmem` backend
+		//  shortdesc: UID of the owner of the region on the host
+		rules["uid"] = unixValidUserID
+
+		// lxdmeta:generate(entities=device-shmem; group=device-conf; key=gid)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `0`
+		//  condition: `ivshmem` backend
+		//  shortdesc: GID of the owner of the region on the host
+		rules["gid"] = unixValidUserID
+
+		// lxdmeta:generate(entities=device-shmem; group=device-conf; key=mode)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `0600`
+		//  condition: `ivshmem` backend
+		//  shortdesc: Mode of the region on the host
+		rules["mode"] = unixValidOctalFileMode
+	}
+
+	err := d.config.Validate(rules)
+	if err != nil {
+		return fmt.Errorf("Failed to validate config: %w", err)
+	}
+
+	return nil
+}
+
+// validateEnvironment checks the runtime environment for correctness.
+func (d *shmem) validateEnvironment() error {
+	if shared.IsTrue(d.inst.ExpandedConfig()["migration.stateful"]) {
+		return fmt.Errorf("Shared memory devices cannot be used when migration.stateful is enabled")
+	}
+
+	if d.config["backend"] == shmemBackendVhostUser && !shared.PathExists(d.config["source"]) {
+		return fmt.Errorf("Missing vhost-user socket %q", d.config["source"])
+	}
+
+	return nil
+}
+
+// regionPath returns the path of the shared memory region on the host.
+func (d *shmem) regionPath() string {
+	if d.config["name"] == "" {
+		return filepath.Join(d.inst.DevicesPath(), fmt.Sprintf("shmem.%s", filesystem.PathNameEncode(d.name)))
+	}
+
+	return deviceConfig.ShmemPath(d.inst.Project().Name, d.config["name"])
+}
+
+// Start is run when the device is added to the instance.
+func (d *shmem) Start() (*deviceConfig.RunConfig, error) {
+	err := d.validateEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to validate environment: %w", err)
+	}
+
+	runConf := deviceConfig.RunConfig{}
+
+	if d.config["backend"] == shmemBackendVhostUser {
+		runConf.ShmemDevice = []deviceConfig.RunConfigItem{
+			{Key: "devName", Value: d.name},
+			{Key: "backend", Value: shmemBackendVhostUser},
+			{Key: "path", Value: d.config["source"]},
+		}
+
+		return &runConf, nil
+	}
+
+	sizeStr := d.config["size"]
+	if sizeStr == "" {
+		sizeStr = shmemDefaultSize
+	}
+
+	size, err := units.ParseByteSizeString(sizeStr)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := d.createRegion(size)
+	if err != nil {
+		return nil, err
+	}
+
+	runConf.ShmemDevice = []deviceConfig.RunConfigItem{
+		{Key: "devName", Value: d.name},
+		{Key: "backend", Value: shmemBackendIvshmem},
+		{Key: "path", Value: path},
+		{Key: "size", Value: strconv.FormatInt(size, 10)},
+	}
+
+	return &runConf, nil
+}
+
+// createRegion creates the file backing the shared memory region (if missing) and applies its ownership and mode.
+func (d *shmem) createRegion(size int64) (string, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	path := d.regionPath()
+
+	err := os.MkdirAll(filepath.Dir(path), 0711)
+	if err != nil {
+		return "", fmt.Errorf("Failed creating shared memory directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return "", fmt.Errorf("Failed opening shared memory region %q: %w", path, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	// A region that is already in use by another instance must keep its size.
+	if fi.Size() == 0 {
+		revert.Add(func() { _ = os.Remove(path) })
+
+		err = f.Truncate(size)
+		if err != nil {
+			return "", fmt.Errorf("Failed resizing shared memory region %q: %w", path, err)
+		}
+	} else if fi.Size() != size {
+		return "", fmt.Errorf("Shared memory region %q already exists with a different size (%d bytes)", d.config["name"], fi.Size())
+	}
+
+	uid := 0
+	if d.config["uid"] != "" {
+		uid, err = strconv.Atoi(d.config["uid"])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	gid := 0
+	if d.config["gid"] != "" {
+		gid, err = strconv.Atoi(d.config["gid"])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	mode := uint64(0600)
+	if d.config["mode"] != "" {
+		mode, err = strconv.ParseUint(d.config["mode"], 8, 32)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = f.Chown(uid, gid)
+	if err != nil {
+		return "", fmt.Errorf("Failed setting owner of shared memory region %q: %w", path, err)
+	}
+
+	err = f.Chmod(os.FileMode(mode))
+	if err != nil {
+		return "", fmt.Errorf("Failed setting mode of shared memory region %q: %w", path, err)
+	}
+
+	revert.Success()
+	return path, nil
+}
+
+// Stop is run when the device is removed from the instance.
+func (d *shmem) Stop() (*deviceConfig.RunConfig, error) {
+	runConf := deviceConfig.RunConfig{
+		PostHooks: []func() error{d.postStop},
+	}
+
+	return &runConf, nil
+}
+
+// postStop is run after the device is removed from the instance.
+func (d *shmem) postStop() error {
+	// Named regions may be used by other instances or host processes, so only private regions are removed.
+	if d.config["backend"] == shmemBackendVhostUser || d.config["name"] != "" {
+		return nil
+	}
+
+	err := os.Remove(d.regionPath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed removing shared memory region: %w", err)
+	}
+
+	return nil
+}
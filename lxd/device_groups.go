@@ -0,0 +1,807 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var deviceGroupsCmd = APIEndpoint{
+	Path: "device-groups",
+
+	Get:  APIEndpointAction{Handler: deviceGroupsGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewProfiles)},
+	Post: APIEndpointAction{Handler: deviceGroupsPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateProfiles)},
+}
+
+var deviceGroupCmd = APIEndpoint{
+	Path: "device-groups/{name}",
+
+	Delete: APIEndpointAction{Handler: deviceGroupDelete, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanDeleteProfiles)},
+	Get:    APIEndpointAction{Handler: deviceGroupGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewProfiles)},
+	Patch:  APIEndpointAction{Handler: deviceGroupPatch, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditProfiles)},
+	Post:   APIEndpointAction{Handler: deviceGroupPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditProfiles)},
+	Put:    APIEndpointAction{Handler: deviceGroupPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditProfiles)},
+}
+
+// deviceGroupConfigKey returns the instance configuration key listing the devices added by the given device group.
+func deviceGroupConfigKey(name string) string {
+	return instancetype.ConfigVolatileDeviceGroupPrefix + name + ".devices"
+}
+
+// deviceGroupValidateName checks that the given device group name is valid.
+func deviceGroupValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("No name provided")
+	}
+
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("Device group names may not contain slashes")
+	}
+
+	if shared.ValueInSlice(name, []string{".", ".."}) {
+		return fmt.Errorf("Invalid device group name %q", name)
+	}
+
+	return nil
+}
+
+// deviceGroupValidateDevices checks the devices of a device group.
+func deviceGroupValidateDevices(s *state.State, p api.Project, devices map[string]map[string]string) error {
+	for name, device := range devices {
+		if instancetype.IsRootDiskDevice(device) {
+			return fmt.Errorf("Device groups cannot contain a root disk device (%q)", name)
+		}
+	}
+
+	// At this point we don't know the instance type, so just use instancetype.Any type for validation.
+	return instance.ValidDevices(s, p, instancetype.Any, deviceConfig.NewDevices(devices), nil)
+}
+
+// deviceGroupUsedBy returns the URLs of all the instances the given device group is attached to.
+// Instances use the device groups of the same project as their profiles.
+func deviceGroupUsedBy(ctx context.Context, tx *db.ClusterTx, group dbCluster.DeviceGroup) ([]string, error) {
+	instances, err := dbCluster.GetInstancesByConfigKey(ctx, tx.Tx(), deviceGroupConfigKey(group.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	profileProjects := map[string]string{}
+	usedBy := []string{}
+	for _, inst := range instances {
+		profileProject, ok := profileProjects[inst.Project]
+		if !ok {
+			dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), inst.Project)
+			if err != nil {
+				return nil, err
+			}
+
+			apiProject, err := dbProject.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return nil, err
+			}
+
+			profileProject = project.ProfileProjectFromRecord(apiProject)
+			profileProjects[inst.Project] = profileProject
+		}
+
+		if profileProject != group.Project {
+			continue
+		}
+
+		apiInst := &api.Instance{Name: inst.Name}
+		usedBy = append(usedBy, apiInst.URL(version.APIVersion, inst.Project).String())
+	}
+
+	return usedBy, nil
+}
+
+// swagger:operation GET /1.0/device-groups device-groups device_groups_get
+//
+//	Get the device groups
+//
+//	Returns a list of device groups (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/device-groups/gpu-workstation",
+//	              "/1.0/device-groups/usb-audio"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/device-groups?recursion=1 device-groups device_groups_get_recursion1
+//
+//	Get the device groups
+//
+//	Returns a list of device groups (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of device groups
+//	          items:
+//	            $ref: "#/definitions/DeviceGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deviceGroupsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	recursion := util.IsRecursionRequest(r)
+
+	var apiDeviceGroups []*api.DeviceGroup
+	var deviceGroupURLs []string
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		filter := dbCluster.DeviceGroupFilter{
+			Project: &p.Name,
+		}
+
+		groups, err := dbCluster.GetDeviceGroups(ctx, tx.Tx(), filter)
+		if err != nil {
+			return err
+		}
+
+		if recursion {
+			apiDeviceGroups = make([]*api.DeviceGroup, 0, len(groups))
+			for _, group := range groups {
+				apiDeviceGroup, err := group.ToAPI(ctx, tx.Tx())
+				if err != nil {
+					return err
+				}
+
+				apiDeviceGroup.UsedBy, err = deviceGroupUsedBy(ctx, tx, group)
+				if err != nil {
+					return err
+				}
+
+				apiDeviceGroups = append(apiDeviceGroups, apiDeviceGroup)
+			}
+		} else {
+			deviceGroupURLs = make([]string, 0, len(groups))
+			for _, group := range groups {
+				apiDeviceGroup := api.DeviceGroup{Name: group.Name}
+				deviceGroupURLs = append(deviceGroupURLs, apiDeviceGroup.URL(version.APIVersion, p.Name).String())
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, deviceGroupURLs)
+	}
+
+	for _, apiDeviceGroup := range apiDeviceGroups {
+		apiDeviceGroup.UsedBy = project.FilterUsedBy(s.Authorizer, r, apiDeviceGroup.UsedBy)
+	}
+
+	return response.SyncResponse(true, apiDeviceGroups)
+}
+
+// swagger:operation POST /1.0/device-groups device-groups device_groups_post
+//
+//	Add a device group
+//
+//	Creates a new device group.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: device group
+//	    description: Device group
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/DeviceGroupsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deviceGroupsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.DeviceGroupsPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Quick checks.
+	err = deviceGroupValidateName(req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = deviceGroupValidateDevices(s, *p, req.Devices)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		devices, err := dbCluster.APIToDevices(req.Devices)
+		if err != nil {
+			return err
+		}
+
+		exists, err := dbCluster.DeviceGroupExists(ctx, tx.Tx(), p.Name, req.Name)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return api.StatusErrorf(http.StatusConflict, "The device group already exists")
+		}
+
+		group := dbCluster.DeviceGroup{
+			Project:     p.Name,
+			Name:        req.Name,
+			Description: req.Description,
+		}
+
+		id, err := dbCluster.CreateDeviceGroup(ctx, tx.Tx(), group)
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.CreateDeviceGroupDevices(ctx, tx.Tx(), id, devices)
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Error inserting %q into database: %w", req.Name, err))
+	}
+
+	requestor := request.CreateRequestor(r)
+	lc := lifecycle.DeviceGroupCreated.Event(req.Name, p.Name, requestor, nil)
+	s.Events.SendLifecycle(p.Name, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation GET /1.0/device-groups/{name} device-groups device_group_get
+//
+//	Get the device group
+//
+//	Gets a specific device group.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Device group
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/DeviceGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deviceGroupGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var resp *api.DeviceGroup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		group, err := dbCluster.GetDeviceGroup(ctx, tx.Tx(), p.Name, name)
+		if err != nil {
+			return err
+		}
+
+		resp, err = group.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		resp.UsedBy, err = deviceGroupUsedBy(ctx, tx, *group)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	resp.UsedBy = project.FilterUsedBy(s.Authorizer, r, resp.UsedBy)
+
+	etag := []any{resp.Description, resp.Devices}
+	return response.SyncResponseETag(true, resp, etag)
+}
+
+// swagger:operation PUT /1.0/device-groups/{name} device-groups device_group_put
+//
+//	Update the device group
+//
+//	Updates the entire device group.
+//	Instances the device group is already attached to are not changed, the new devices are used the next time
+//	the device group is attached.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: device group
+//	    description: Device group configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/DeviceGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deviceGroupPut(d *Daemon, r *http.Request) response.Response {
+	return doDeviceGroupUpdate(d, r, false)
+}
+
+// swagger:operation PATCH /1.0/device-groups/{name} device-groups device_group_patch
+//
+//	Partially update the device group
+//
+//	Updates a subset of the device group.
+//	Instances the device group is already attached to are not changed, the new devices are used the next time
+//	the device group is attached.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: device group
+//	    description: Device group configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/DeviceGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deviceGroupPatch(d *Daemon, r *http.Request) response.Response {
+	return doDeviceGroupUpdate(d, r, true)
+}
+
+// doDeviceGroupUpdate updates a device group, merging the request with the current device group when patching.
+func doDeviceGroupUpdate(d *Daemon, r *http.Request, isPatch bool) response.Response {
+	s := d.State()
+
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var current *api.DeviceGroup
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		group, err := dbCluster.GetDeviceGroup(ctx, tx.Tx(), p.Name, name)
+		if err != nil {
+			return err
+		}
+
+		current, err = group.ToAPI(ctx, tx.Tx())
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	etag := []any{current.Description, current.Devices}
+	err = util.EtagCheck(r, etag)
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	req := api.DeviceGroupPut{}
+	err = json.NewDecoder(bytes.NewReader(body)).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if isPatch {
+		reqRaw := shared.Jmap{}
+		err = json.NewDecoder(bytes.NewReader(body)).Decode(&reqRaw)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		// Get Description.
+		_, err = reqRaw.GetString("description")
+		if err != nil {
+			req.Description = current.Description
+		}
+
+		// Get Devices.
+		if req.Devices == nil {
+			req.Devices = current.Devices
+		} else {
+			for k, v := range current.Devices {
+				_, ok := req.Devices[k]
+				if !ok {
+					req.Devices[k] = v
+				}
+			}
+		}
+	}
+
+	err = deviceGroupValidateDevices(s, *p, req.Devices)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		devices, err := dbCluster.APIToDevices(req.Devices)
+		if err != nil {
+			return err
+		}
+
+		id, err := dbCluster.GetDeviceGroupID(ctx, tx.Tx(), p.Name, name)
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.UpdateDeviceGroup(ctx, tx.Tx(), p.Name, name, dbCluster.DeviceGroup{
+			Project:     p.Name,
+			Name:        name,
+			Description: req.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.UpdateDeviceGroupDevices(ctx, tx.Tx(), id, devices)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+	s.Events.SendLifecycle(p.Name, lifecycle.DeviceGroupUpdated.Event(name, p.Name, requestor, nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/device-groups/{name} device-groups device_group_post
+//
+//	Rename the device group
+//
+//	Renames an existing device group.
+//	Device groups that are attached to instances cannot be renamed.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: device group
+//	    description: Device group rename request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/DeviceGroupPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deviceGroupPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.DeviceGroupPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Quick checks.
+	err = deviceGroupValidateName(req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		group, err := dbCluster.GetDeviceGroup(ctx, tx.Tx(), p.Name, name)
+		if err != nil {
+			return err
+		}
+
+		// The name of the device group is recorded in the configuration of the instances it is attached to.
+		usedBy, err := deviceGroupUsedBy(ctx, tx, *group)
+		if err != nil {
+			return err
+		}
+
+		if len(usedBy) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "Device group is currently attached to instances")
+		}
+
+		// Check that the name isn't already in use.
+		exists, err := dbCluster.DeviceGroupExists(ctx, tx.Tx(), p.Name, req.Name)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return api.StatusErrorf(http.StatusConflict, "Name %q already in use", req.Name)
+		}
+
+		return dbCluster.RenameDeviceGroup(ctx, tx.Tx(), p.Name, name, req.Name)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+	lc := lifecycle.DeviceGroupRenamed.Event(req.Name, p.Name, requestor, logger.Ctx{"old_name": name})
+	s.Events.SendLifecycle(p.Name, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/device-groups/{name} device-groups device_group_delete
+//
+//	Delete the device group
+//
+//	Removes the device group.
+//	Device groups that are attached to instances cannot be deleted.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deviceGroupDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		group, err := dbCluster.GetDeviceGroup(ctx, tx.Tx(), p.Name, name)
+		if err != nil {
+			return err
+		}
+
+		usedBy, err := deviceGroupUsedBy(ctx, tx, *group)
+		if err != nil {
+			return err
+		}
+
+		if len(usedBy) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "Device group is currently attached to instances")
+		}
+
+		return dbCluster.DeleteDeviceGroup(ctx, tx.Tx(), p.Name, name)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+	s.Events.SendLifecycle(p.Name, lifecycle.DeviceGroupDeleted.Event(name, p.Name, requestor, nil))
+
+	return response.EmptySyncResponse
+}
@@ -165,6 +165,19 @@ func deviceNetlinkListener() (chan []string, chan []string, chan device.USBEvent
 				}
 			}
 
+			// NUMA nodes coming and going (such as memory hotplug) change the CPU topology too.
+			if props["SUBSYSTEM"] == "node" && !udevEvent {
+				if !shared.ValueInSlice(props["ACTION"], []string{"add", "remove", "online", "offline"}) {
+					continue
+				}
+
+				select {
+				case chCPU <- []string{path.Base(props["DEVPATH"]), props["ACTION"]}:
+				default:
+					// Channel is full, drop the event
+				}
+			}
+
 			if props["SUBSYSTEM"] == "net" && !udevEvent {
 				if props["ACTION"] != "add" && props["ACTION"] != "removed" {
 					continue
@@ -415,8 +428,12 @@ func fillFixedInstances(fixedInstances map[int64][]instance.Instance, inst insta
 // in ascending order until the required number of CPUs have been assigned.
 // Finally, the pinning map is used to set the new CPU pinning for each instance, updating it to the new balanced state.
 //
+// Virtual machines with `limits.cpu.rebalance` set to false keep their current vCPU pinning (as long as it is still
+// valid) and only count towards the usage of the CPUs they are pinned to.
+//
 // Overall, this function ensures that the CPU resources of the host are utilized effectively amongst all the instances running on it.
-func deviceTaskBalance(s *state.State) {
+// The reason is used when logging the pinning changes of virtual machines.
+func deviceTaskBalance(s *state.State, reason string) {
 	min := func(x, y int) int {
 		if x < y {
 			return x
@@ -496,6 +513,7 @@ func deviceTaskBalance(s *state.State) {
 
 	fixedInstances := map[int64][]instance.Instance{}
 	balancedInstances := map[instance.Instance]int{}
+	keptInstances := map[instance.Instance][]int64{}
 	for _, c := range instances {
 		conf := c.ExpandedConfig()
 		cpuNodes := conf["limits.cpu.nodes"]
@@ -536,6 +554,20 @@ func deviceTaskBalance(s *state.State) {
 		if err == nil {
 			// Load-balance
 			count = min(count, len(cpus))
+
+			if c.Type() == instancetype.VM && shared.IsFalse(conf["limits.cpu.rebalance"]) {
+				allowedCpus := cpus
+				if len(numaCpus) > 0 {
+					allowedCpus = numaCpus
+				}
+
+				current := deviceTaskCurrentPinning(c, allowedCpus, count)
+				if current != nil {
+					keptInstances[c] = current
+					continue
+				}
+			}
+
 			if len(numaCpus) > 0 {
 				fillFixedInstances(fixedInstances, c, cpus, numaCpus, count, true)
 			} else {
@@ -570,6 +602,15 @@ func deviceTaskBalance(s *state.State) {
 		usage[id] = cpu
 	}
 
+	for _, ids := range keptInstances {
+		for _, id := range ids {
+			c, ok := usage[id]
+			if ok {
+				*c.count += 1
+			}
+		}
+	}
+
 	for cpu, ctns := range fixedInstances {
 		c, ok := usage[cpu]
 		if !ok {
@@ -616,11 +657,54 @@ func deviceTaskBalance(s *state.State) {
 
 	// Set the new pinning
 	for inst, set := range pinning {
+		var previous []string
+
+		vm, isVM := inst.(instance.VM)
+		if isVM {
+			previous, _ = vm.CPUAffinity()
+		}
+
 		err = inst.SetAffinity(set)
 		if err != nil {
 			logger.Error("Error setting CPU affinity for the instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			continue
+		}
+
+		if isVM && strings.Join(previous, ",") != strings.Join(set, ",") {
+			logger.Info("Rebalanced vCPU pinning of the instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "reason": reason, "cpus": strings.Join(set, ","), "previous": strings.Join(previous, ",")})
+		}
+	}
+
+	for inst, ids := range keptInstances {
+		logger.Debug("Kept vCPU pinning of the instance as rebalancing is disabled", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "reason": reason, "cpus": ids})
+	}
+}
+
+// deviceTaskCurrentPinning returns the CPUs the vCPUs of the virtual machine are currently pinned to.
+// It returns nil if the vCPUs aren't pinned, if the number of vCPUs doesn't match the expected count or if any of
+// the CPUs isn't in the allowed list anymore (for example, because it went offline).
+func deviceTaskCurrentPinning(inst instance.Instance, allowedCpus []int64, count int) []int64 {
+	vm, ok := inst.(instance.VM)
+	if !ok {
+		return nil
+	}
+
+	set, err := vm.CPUAffinity()
+	if err != nil || len(set) != count {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(set))
+	for _, strID := range set {
+		id, err := strconv.ParseInt(strID, 10, 64)
+		if err != nil || !shared.ValueInSlice(id, allowedCpus) {
+			return nil
 		}
+
+		ids = append(ids, id)
 	}
+
+	return ids
 }
 
 // deviceEventListener starts the event listener for resource scheduling.
@@ -646,8 +730,8 @@ func deviceEventListener(stateFunc func() *state.State) {
 				continue
 			}
 
-			logger.Debugf("Scheduler: cpu: %s is now %s: re-balancing", e[0], e[1])
-			deviceTaskBalance(s)
+			logger.Debugf("Scheduler: %s is now %s: re-balancing", e[0], e[1])
+			deviceTaskBalance(s, fmt.Sprintf("%s %s", e[0], e[1]))
 		case e := <-chNetlinkNetwork:
 			if len(e) != 2 {
 				logger.Errorf("Scheduler: received an invalid network hotplug event")
@@ -684,7 +768,7 @@ func deviceEventListener(stateFunc func() *state.State) {
 			}
 
 			logger.Debugf("Scheduler: %s %s %s: re-balancing", e[0], e[1], e[2])
-			deviceTaskBalance(s)
+			deviceTaskBalance(s, fmt.Sprintf("%s %s %s", e[0], e[1], e[2]))
 		}
 	}
 }
@@ -12,10 +12,12 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"golang.org/x/sys/unix"
 
 	"github.com/canonical/lxd/lxd/events"
+	"github.com/canonical/lxd/lxd/identity"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/lifecycle"
@@ -123,6 +125,56 @@ var devlxdMetadataGet = devLxdHandler{"/1.0/meta-data", func(d *Daemon, inst ins
 	return response.DevLxdResponse(http.StatusOK, fmt.Sprintf("#cloud-config\ninstance-id: %s\nlocal-hostname: %s\n%s", inst.CloudInitID(), inst.Name(), value), "raw", inst.Type() == instancetype.VM)
 }}
 
+// devlxdCloudInitDataGet returns a handler serving the cloud-init data of the given kind in the format expected by
+// the cloud-init NoCloud datasource.
+func devlxdCloudInitDataGet(kind string) devLxdHandler {
+	return devLxdHandler{"/1.0/" + kind, func(d *Daemon, inst instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+		if shared.IsFalse(inst.ExpandedConfig()["security.devlxd"]) {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), inst.Type() == instancetype.VM)
+		}
+
+		value := instance.CloudInitData(inst.ExpandedConfig(), kind)
+		if value == "" {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusNotFound, "not found"), inst.Type() == instancetype.VM)
+		}
+
+		return response.DevLxdResponse(http.StatusOK, value, "raw", inst.Type() == instancetype.VM)
+	}}
+}
+
+var devlxdTokenPost = devLxdHandler{"/1.0/token", func(d *Daemon, inst instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if shared.IsFalse(inst.ExpandedConfig()["security.devlxd"]) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), inst.Type() == instancetype.VM)
+	}
+
+	if shared.IsFalseOrEmpty(inst.ExpandedConfig()["security.devlxd.token"]) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), inst.Type() == instancetype.VM)
+	}
+
+	if r.Method != http.MethodPost {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusMethodNotAllowed, fmt.Sprintf("method %q not allowed", r.Method)), inst.Type() == instancetype.VM)
+	}
+
+	// Bind the token to the nonce of the instance so that unsetting the nonce revokes all the issued tokens.
+	nonce := inst.LocalConfig()[identity.DevLXDTokenNonceKey]
+	if nonce == "" {
+		nonce = uuid.New().String()
+		err := inst.VolatileSet(map[string]string{identity.DevLXDTokenNonceKey: nonce})
+		if err != nil {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, "internal server error"), inst.Type() == instancetype.VM)
+		}
+	}
+
+	// Sign with the cluster certificate so that the token can be used against any cluster member.
+	key := identity.DevLXDTokenKey(d.State().Endpoints.NetworkCert().PrivateKey())
+	token, expiresAt, err := identity.NewDevLXDToken(key, inst.Project().Name, inst.Name(), nonce)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, "internal server error"), inst.Type() == instancetype.VM)
+	}
+
+	return response.DevLxdResponse(http.StatusOK, api.DevLXDToken{Token: token, ExpiresAt: expiresAt}, "json", inst.Type() == instancetype.VM)
+}}
+
 var devlxdEventsGet = devLxdHandler{"/1.0/events", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
 	if shared.IsFalse(c.ExpandedConfig()["security.devlxd"]) {
 		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
@@ -266,8 +318,12 @@ var handlers = []devLxdHandler{
 	devlxdConfigGet,
 	devlxdConfigKeyGet,
 	devlxdMetadataGet,
+	devlxdCloudInitDataGet("user-data"),
+	devlxdCloudInitDataGet("vendor-data"),
+	devlxdCloudInitDataGet("network-config"),
 	devlxdEventsGet,
 	devlxdImageExport,
+	devlxdTokenPost,
 	devlxdDevicesGet,
 }
 
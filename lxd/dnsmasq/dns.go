@@ -0,0 +1,236 @@
+package dnsmasq
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// dnsRecordsMutex serialises the conflict checks and updates of the DNS records of all networks.
+var dnsRecordsMutex sync.Mutex
+
+// DNSRecord represents a DNS record served by dnsmasq from the DNS records directory of a network.
+type DNSRecord struct {
+	Name     string
+	IP       net.IP
+	Project  string
+	Instance string
+	Device   string
+}
+
+// DNSRecordsPath returns the path to the directory containing the DNS records of a network.
+// It is passed to dnsmasq using --hostsdir, so that new records are loaded without signalling dnsmasq.
+func DNSRecordsPath(network string) string {
+	return shared.VarPath("networks", network, "dnsmasq.dns")
+}
+
+// dnsRecordHostName returns the host name used for the DNS records of an instance.
+func dnsRecordHostName(projectName string, instanceName string, domain string) string {
+	if domain == "" {
+		domain = "lxd"
+	}
+
+	return fmt.Sprintf("%s.%s", project.DNS(projectName, instanceName), domain)
+}
+
+// UpdateDNSRecord writes the DNS records for a network/instance/device combination.
+// The records are written to a temporary file which is then renamed into place so that dnsmasq never reads a
+// partially written file. New records are picked up by dnsmasq automatically, but dnsmasq must be reloaded for
+// replaced or removed records to be dropped. If another instance device already has a record with the same name,
+// an api.StatusError with http.StatusConflict is returned and the records are left untouched.
+func UpdateDNSRecord(network string, projectName string, instanceName string, deviceName string, domain string, ipv4Address string, ipv6Address string) error {
+	var ips []net.IP
+	for _, address := range []string{ipv4Address, ipv6Address} {
+		ip := net.ParseIP(address)
+		if ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+
+	if len(ips) == 0 {
+		return RemoveDNSRecord(network, projectName, instanceName, deviceName)
+	}
+
+	dnsRecordsMutex.Lock()
+	defer dnsRecordsMutex.Unlock()
+
+	hostName := dnsRecordHostName(projectName, instanceName, domain)
+	fileName := StaticAllocationFileName(projectName, instanceName, deviceName)
+
+	records, err := DNSRecords(network)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if record.Name != hostName || StaticAllocationFileName(record.Project, record.Instance, record.Device) == fileName {
+			continue
+		}
+
+		return api.StatusErrorf(http.StatusConflict, "DNS name %q is already registered for device %q of instance %q in project %q", hostName, record.Device, record.Instance, record.Project)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# project=%s instance=%s device=%s\n", projectName, instanceName, deviceName)
+	for _, ip := range ips {
+		fmt.Fprintf(&sb, "%s %s %s\n", ip.String(), hostName, project.DNS(projectName, instanceName))
+	}
+
+	path := filepath.Join(DNSRecordsPath(network), fileName)
+
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if string(current) == sb.String() {
+		return nil
+	}
+
+	// dnsmasq ignores files starting with a dot in its hosts directory.
+	tmpPath := filepath.Join(DNSRecordsPath(network), "."+fileName)
+	err = os.WriteFile(tmpPath, []byte(sb.String()), 0644)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// RemoveDNSRecord removes the DNS records for a network/instance/device combination.
+// dnsmasq must be reloaded for the removed records to be dropped.
+func RemoveDNSRecord(network string, projectName string, instanceName string, deviceName string) error {
+	dnsRecordsMutex.Lock()
+	defer dnsRecordsMutex.Unlock()
+
+	err := os.Remove(filepath.Join(DNSRecordsPath(network), StaticAllocationFileName(projectName, instanceName, deviceName)))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// DNSRecords returns the DNS records currently written for a network.
+func DNSRecords(network string) ([]DNSRecord, error) {
+	entries, err := os.ReadDir(DNSRecordsPath(network))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var records []DNSRecord
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		fileRecords, err := readDNSRecordFile(filepath.Join(DNSRecordsPath(network), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, fileRecords...)
+	}
+
+	return records, nil
+}
+
+// readDNSRecordFile parses a DNS record file written by UpdateDNSRecord.
+func readDNSRecordFile(path string) ([]DNSRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	var records []DNSRecord
+	var owner DNSRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// The owner of the records is stored in the header comment.
+		header, ok := strings.CutPrefix(line, "#")
+		if ok {
+			for _, field := range strings.Fields(header) {
+				key, value, _ := strings.Cut(field, "=")
+				switch key {
+				case "project":
+					owner.Project = value
+				case "instance":
+					owner.Instance = value
+				case "device":
+					owner.Device = value
+				}
+			}
+
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		ip := net.ParseIP(fields[0])
+		if ip == nil {
+			return nil, fmt.Errorf("Error parsing IP address %q in %q", fields[0], path)
+		}
+
+		record := owner
+		record.Name = fields[1]
+		record.IP = ip
+		records = append(records, record)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// ClearDNSRecords removes all the DNS records of a network.
+func ClearDNSRecords(network string) error {
+	dnsRecordsMutex.Lock()
+	defer dnsRecordsMutex.Unlock()
+
+	entries, err := os.ReadDir(DNSRecordsPath(network))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, entry := range entries {
+		err = os.Remove(filepath.Join(DNSRecordsPath(network), entry.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"strings"
 	"sync"
@@ -13,6 +14,8 @@ import (
 	"github.com/canonical/lxd/lxd/storage/filesystem"
 	"github.com/canonical/lxd/lxd/subprocess"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/version"
 )
 
@@ -42,7 +45,8 @@ func UpdateStaticEntry(network string, projectName string, instanceName string,
 		line += fmt.Sprintf(",[%s]", ipv6Address)
 	}
 
-	if netConfig["dns.mode"] == "" || netConfig["dns.mode"] == "managed" {
+	dnsManaged := netConfig["dns.mode"] == "" || netConfig["dns.mode"] == "managed"
+	if dnsManaged {
 		line += fmt.Sprintf(",%s", project.DNS(projectName, instanceName))
 	}
 
@@ -56,6 +60,19 @@ func UpdateStaticEntry(network string, projectName string, instanceName string,
 		return err
 	}
 
+	// Register the DNS records for the known addresses. A name conflict with another instance is not fatal, the
+	// existing records are kept so that the name keeps resolving to the instance that registered it first.
+	if dnsManaged && shared.PathExists(DNSRecordsPath(network)) {
+		err = UpdateDNSRecord(network, projectName, instanceName, deviceName, netConfig["dns.domain"], ipv4Address, ipv6Address)
+		if err != nil {
+			if !api.StatusErrorCheck(err, http.StatusConflict) {
+				return err
+			}
+
+			logger.Warn("Skipping DNS record registration", logger.Ctx{"network": network, "project": projectName, "instance": instanceName, "device": deviceName, "err": err})
+		}
+	}
+
 	return nil
 }
 
@@ -67,7 +84,7 @@ func RemoveStaticEntry(network string, projectName string, instanceName string,
 		return err
 	}
 
-	return nil
+	return RemoveDNSRecord(network, projectName, instanceName, deviceName)
 }
 
 // Kill kills dnsmasq for a particular network (or optionally reloads it).
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// hostShutdownInhibitorActions are the lifecycle actions that can change whether host shutdowns must be blocked.
+var hostShutdownInhibitorActions = []string{
+	string(lifecycle.InstanceStarted),
+	string(lifecycle.InstanceStopped),
+	string(lifecycle.InstanceShutdown),
+	string(lifecycle.InstanceRestarted),
+	string(lifecycle.InstanceUpdated),
+	string(lifecycle.InstanceDeleted),
+}
+
+// hostShutdownInhibitor holds a systemd inhibitor lock that blocks host shutdowns while instances with
+// security.protection.host_shutdown are running.
+type hostShutdownInhibitor struct {
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	reason string
+}
+
+// handleEvent refreshes the inhibitor lock when an instance lifecycle event is received.
+func (i *hostShutdownInhibitor) handleEvent(s *state.State, event api.Event) {
+	if event.Type != api.EventTypeLifecycle {
+		return
+	}
+
+	lifecycleEvent := api.EventLifecycle{}
+	err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+	if err != nil {
+		return
+	}
+
+	if !shared.ValueInSlice(lifecycleEvent.Action, hostShutdownInhibitorActions) {
+		return
+	}
+
+	i.refresh(s)
+}
+
+// refresh takes or releases the inhibitor lock depending on the protected instances running on this member.
+func (i *hostShutdownInhibitor) refresh(s *state.State) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// Don't take the lock again once the daemon is shutting down.
+	if s.ShutdownCtx.Err() != nil {
+		i.release()
+		return
+	}
+
+	var protected []string
+
+	if !s.LocalConfig.HostShutdownProtectionOverride() {
+		instances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Warn("Failed loading instances to check host shutdown protection", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range instances {
+			if shared.IsTrue(inst.ExpandedConfig()["security.protection.host_shutdown"]) && inst.IsRunning() {
+				protected = append(protected, fmt.Sprintf("%s/%s", inst.Project().Name, inst.Name()))
+			}
+		}
+	}
+
+	if len(protected) == 0 {
+		i.release()
+		return
+	}
+
+	sort.Strings(protected)
+	reason := fmt.Sprintf("Protected instances are running: %s", strings.Join(protected, ", "))
+	if i.cmd != nil && i.reason == reason {
+		return
+	}
+
+	// Replace the existing lock so that the reason shown to the user is up to date.
+	i.release()
+
+	path, err := exec.LookPath("systemd-inhibit")
+	if err != nil {
+		logger.Warn("Unable to block host shutdowns for protected instances", logger.Ctx{"err": err})
+		return
+	}
+
+	cmd := exec.Command(path, "--what=shutdown", "--who=LXD", "--why="+reason, "--mode=block", "sleep", "infinity")
+	err = cmd.Start()
+	if err != nil {
+		logger.Warn("Failed blocking host shutdowns for protected instances", logger.Ctx{"err": err})
+		return
+	}
+
+	i.cmd = cmd
+	i.reason = reason
+
+	logger.Info("Blocking host shutdowns", logger.Ctx{"instances": protected})
+}
+
+// release releases the inhibitor lock if held. Must be called with the mutex held.
+func (i *hostShutdownInhibitor) release() {
+	if i.cmd == nil {
+		return
+	}
+
+	_ = i.cmd.Process.Kill()
+	_ = i.cmd.Wait()
+
+	i.cmd = nil
+	i.reason = ""
+
+	logger.Info("Stopped blocking host shutdowns")
+}
+
+// stop releases the inhibitor lock.
+func (i *hostShutdownInhibitor) stop() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.release()
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
 	"github.com/canonical/lxd/client"
@@ -20,6 +21,7 @@ import (
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -43,6 +45,10 @@ var identitiesByAuthenticationMethodCmd = APIEndpoint{
 		Handler:       getIdentities,
 		AccessHandler: allowAuthenticated,
 	},
+	Post: APIEndpointAction{
+		Handler:       createIdentity,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanCreateIdentities),
+	},
 }
 
 var identityCmd = APIEndpoint{
@@ -60,6 +66,23 @@ var identityCmd = APIEndpoint{
 		Handler:       patchIdentity,
 		AccessHandler: identityAccessHandler(auth.EntitlementCanEdit),
 	},
+	Delete: APIEndpointAction{
+		Handler:       deleteIdentity,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanDelete),
+	},
+}
+
+var identityBearerTokenCmd = APIEndpoint{
+	Name: "identity_bearer_token",
+	Path: "auth/identities/{authenticationMethod}/{nameOrIdentifier}/token",
+	Post: APIEndpointAction{
+		Handler:       issueIdentityBearerToken,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanEdit),
+	},
+	Delete: APIEndpointAction{
+		Handler:       revokeIdentityBearerToken,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanEdit),
+	},
 }
 
 const (
@@ -497,8 +520,9 @@ func getCurrentIdentityInfo(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Current identity information must be requested via the HTTPS API"))
 	}
 
-	// Identity provider groups may not be present.
+	// Identity provider groups and groups resolved from identity provider group rules may not be present.
 	identityProviderGroupNames, _ := request.GetCtxValue[[]string](r.Context(), request.CtxIdentityProviderGroups)
+	ruleGroupNames, _ := request.GetCtxValue[[]string](r.Context(), request.CtxIdentityProviderGroupRuleGroups)
 
 	s := d.State()
 	var apiIdentity *api.Identity
@@ -523,7 +547,7 @@ func getCurrentIdentityInfo(d *Daemon, r *http.Request) response.Response {
 			return fmt.Errorf("Failed to get effective groups: %w", err)
 		}
 
-		for _, mappedGroup := range mappedGroups {
+		for _, mappedGroup := range append(mappedGroups, ruleGroupNames...) {
 			if !shared.ValueInSlice(mappedGroup, effectiveGroups) {
 				effectiveGroups = append(effectiveGroups, mappedGroup)
 			}
@@ -755,6 +779,311 @@ func patchIdentity(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
+// swagger:operation POST /1.0/auth/identities/bearer identities identities_post_bearer
+//
+//	Add a bearer token identity
+//
+//	Creates a new service account identity that authenticates with a bearer token issued by LXD.
+//	A token must be issued for the identity before it can be used.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: identity
+//	    description: Identity request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/IdentitiesBearerPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func createIdentity(d *Daemon, r *http.Request) response.Response {
+	authenticationMethod, err := url.PathUnescape(mux.Vars(r)["authenticationMethod"])
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to unescape path argument: %w", err))
+	}
+
+	if authenticationMethod != api.AuthenticationMethodBearer {
+		return response.NotImplemented(fmt.Errorf("Creating identities is only supported for authentication method %q", api.AuthenticationMethodBearer))
+	}
+
+	var req api.IdentitiesBearerPost
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Failed to unmarshal request body: %w", err))
+	}
+
+	if req.Name == "" {
+		return response.BadRequest(fmt.Errorf("Identity name must not be empty"))
+	}
+
+	metadata, err := json.Marshal(dbCluster.BearerMetadata{})
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to marshal bearer identity metadata: %w", err))
+	}
+
+	identifier := uuid.New().String()
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Bearer identities are referred to by name, so the name must be unique.
+		dbAuthMethod := dbCluster.AuthMethod(api.AuthenticationMethodBearer)
+		existing, err := dbCluster.GetIdentitys(ctx, tx.Tx(), dbCluster.IdentityFilter{
+			AuthMethod: &dbAuthMethod,
+			Name:       &req.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(existing) > 0 {
+			return api.StatusErrorf(http.StatusConflict, "An identity with name %q already exists", req.Name)
+		}
+
+		id, err := dbCluster.CreateIdentity(ctx, tx.Tx(), dbCluster.Identity{
+			AuthMethod: api.AuthenticationMethodBearer,
+			Type:       api.IdentityTypeBearerTokenClient,
+			Identifier: identifier,
+			Name:       req.Name,
+			Metadata:   string(metadata),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.SetIdentityAuthGroups(ctx, tx.Tx(), int(id), req.Groups)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifyIdentityCacheRefresh(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the identity creation.
+	lc := lifecycle.IdentityCreated.Event(api.AuthenticationMethodBearer, identifier, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	s.UpdateIdentityCache()
+
+	return response.SyncResponseLocation(true, nil, entity.IdentityURL(api.AuthenticationMethodBearer, identifier).String())
+}
+
+// swagger:operation DELETE /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier} identities identity_delete
+//
+//	Delete the identity
+//
+//	Removes the identity. Only bearer token identities can be deleted.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deleteIdentity(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if id.AuthMethod != api.AuthenticationMethodBearer {
+		return response.NotImplemented(fmt.Errorf("Deleting identities is only supported for authentication method %q", api.AuthenticationMethodBearer))
+	}
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.DeleteIdentity(ctx, tx.Tx(), id.AuthMethod, id.Identifier)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifyIdentityCacheRefresh(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the identity deletion.
+	lc := lifecycle.IdentityDeleted.Event(string(id.AuthMethod), id.Identifier, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	s.UpdateIdentityCache()
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/auth/identities/bearer/{nameOrIdentifier}/token identities identity_bearer_token_post
+//
+//	Issue a bearer token
+//
+//	Issues a new bearer token for the identity. Any previously issued token for the identity is revoked.
+//	The token is only returned in this response and cannot be retrieved afterwards.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Bearer token
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/IdentityBearerToken"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func issueIdentityBearerToken(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if id.AuthMethod != api.AuthenticationMethodBearer {
+		return response.BadRequest(fmt.Errorf("Tokens can only be issued for identities with authentication method %q", api.AuthenticationMethodBearer))
+	}
+
+	token, tokenHash, err := identity.NewBearerToken(id.Identifier)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	err = setIdentityBearerTokenHash(r.Context(), d.State(), id, tokenHash)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the identity update.
+	lc := lifecycle.IdentityUpdated.Event(string(id.AuthMethod), id.Identifier, request.CreateRequestor(r), map[string]any{"token": "issued"})
+	d.State().Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.SyncResponse(true, api.IdentityBearerToken{Token: token})
+}
+
+// swagger:operation DELETE /1.0/auth/identities/bearer/{nameOrIdentifier}/token identities identity_bearer_token_delete
+//
+//	Revoke the bearer token
+//
+//	Revokes the bearer token of the identity. The identity is kept and a new token can be issued later.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func revokeIdentityBearerToken(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if id.AuthMethod != api.AuthenticationMethodBearer {
+		return response.BadRequest(fmt.Errorf("Tokens can only be revoked for identities with authentication method %q", api.AuthenticationMethodBearer))
+	}
+
+	err = setIdentityBearerTokenHash(r.Context(), d.State(), id, "")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the identity update.
+	lc := lifecycle.IdentityUpdated.Event(string(id.AuthMethod), id.Identifier, request.CreateRequestor(r), map[string]any{"token": "revoked"})
+	d.State().Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.EmptySyncResponse
+}
+
+// setIdentityBearerTokenHash stores the hash of the bearer token of the given identity and refreshes the identity
+// cache on all cluster members. An empty hash revokes the token of the identity.
+func setIdentityBearerTokenHash(ctx context.Context, s *state.State, id *dbCluster.Identity, tokenHash string) error {
+	metadata, err := json.Marshal(dbCluster.BearerMetadata{TokenHash: tokenHash})
+	if err != nil {
+		return fmt.Errorf("Failed to marshal bearer identity metadata: %w", err)
+	}
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.UpdateIdentity(ctx, tx.Tx(), id.AuthMethod, id.Identifier, dbCluster.Identity{
+			AuthMethod: id.AuthMethod,
+			Type:       id.Type,
+			Identifier: id.Identifier,
+			Name:       id.Name,
+			Metadata:   string(metadata),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = notifyIdentityCacheRefresh(s)
+	if err != nil {
+		return err
+	}
+
+	s.UpdateIdentityCache()
+
+	return nil
+}
+
+// notifyIdentityCacheRefresh notifies other cluster members to update their identity cache.
+func notifyIdentityCacheRefresh(s *state.State) error {
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	return notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPost, "/internal/identity-cache-refresh", nil, "")
+		return err
+	})
+}
+
 // updateIdentityCache reads all identities from the database and sets them in the identity.Cache.
 // The certificates in the local database are replaced with identities in the cluster database that
 // are of type api.IdentityTypeCertificateServer. This ensures that this cluster member is able to
@@ -768,6 +1097,7 @@ func updateIdentityCache(d *Daemon) {
 	projects := make(map[int][]string)
 	groups := make(map[int][]string)
 	idpGroupMapping := make(map[string][]string)
+	var idpGroupRules []api.IdentityProviderGroupRule
 	var err error
 	err = s.DB.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		identities, err = dbCluster.GetIdentitys(ctx, tx.Tx())
@@ -810,6 +1140,23 @@ func updateIdentityCache(d *Daemon) {
 			idpGroupMapping[apiIDPGroup.Name] = apiIDPGroup.Groups
 		}
 
+		dbIDPGroupRules, err := dbCluster.GetIdentityProviderGroupRules(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		for _, dbIDPGroupRule := range dbIDPGroupRules {
+			// Internal method does not need a permission checker.
+			apiIDPGroupRule, err := dbIDPGroupRule.ToAPI(ctx, tx.Tx(), func(_ *api.URL) bool { return true })
+			if err != nil {
+				// Don't fail the whole refresh because of a single rule.
+				logger.Warn("Failed loading identity provider group rule", logger.Ctx{"rule": dbIDPGroupRule.Name, "err": err})
+				continue
+			}
+
+			idpGroupRules = append(idpGroupRules, *apiIDPGroupRule)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -845,6 +1192,14 @@ func updateIdentityCache(d *Daemon) {
 			}
 
 			cacheEntry.Subject = subject
+		} else if cacheEntry.AuthenticationMethod == api.AuthenticationMethodBearer {
+			tokenHash, err := id.BearerTokenHash()
+			if err != nil {
+				logger.Warn("Failed to extract bearer token hash from bearer identity metadata", logger.Ctx{"err": err})
+				continue
+			}
+
+			cacheEntry.BearerTokenHash = tokenHash
 		}
 
 		identityCacheEntries = append(identityCacheEntries, cacheEntry)
@@ -874,6 +1229,8 @@ func updateIdentityCache(d *Daemon) {
 	if err != nil {
 		logger.Warn("Failed to update identity cache", logger.Ctx{"err": err})
 	}
+
+	d.identityCache.ReplaceIdentityProviderGroupRules(idpGroupRules)
 }
 
 // updateIdentityCacheFromLocal loads trusted server certificates from local database into the identity cache.
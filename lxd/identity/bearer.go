@@ -0,0 +1,66 @@
+package identity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// BearerTokenPrefix is prepended to all bearer tokens issued by LXD. It allows these tokens to be distinguished from
+// OIDC access tokens, which are sent in the same Authorization header.
+const BearerTokenPrefix = "lxd-bearer-"
+
+// NewBearerToken generates a new bearer token for the identity with the given identifier. The token is returned along
+// with its hash, which is the only value that should be stored.
+func NewBearerToken(identifier string) (token string, hash string, err error) {
+	secret := make([]byte, 32)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to generate bearer token secret: %w", err)
+	}
+
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+
+	return BearerTokenPrefix + identifier + "." + encodedSecret, BearerTokenHash(encodedSecret), nil
+}
+
+// BearerTokenHash returns the hex encoded SHA-256 hash of a bearer token secret.
+func BearerTokenHash(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// BearerTokenFromRequest returns the identifier and secret of a bearer token issued by LXD found in the Authorization
+// header of the request. The last return value is false if the request does not contain such a token.
+func BearerTokenFromRequest(r *http.Request) (identifier string, secret string, ok bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", "", false
+	}
+
+	token, ok = strings.CutPrefix(token, BearerTokenPrefix)
+	if !ok {
+		return "", "", false
+	}
+
+	identifier, secret, ok = strings.Cut(token, ".")
+	if !ok || identifier == "" || secret == "" {
+		return "", "", false
+	}
+
+	return identifier, secret, true
+}
+
+// VerifyBearerToken checks the given bearer token secret against the hash of the token issued for the cache entry.
+func (c CacheEntry) VerifyBearerToken(secret string) bool {
+	if c.BearerTokenHash == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(BearerTokenHash(secret)), []byte(c.BearerTokenHash)) == 1
+}
@@ -8,17 +8,21 @@ import (
 
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
 )
 
 // Cache represents a thread-safe in-memory cache of the identities in the database.
 type Cache struct {
 	// entries is a map of authentication method to map of identifier to CacheEntry. The identifier is either a
-	// certificate fingerprint (tls) or an email address (oidc).
+	// certificate fingerprint (tls), an email address (oidc) or a UUID (bearer).
 	entries map[string]map[string]*CacheEntry
 
 	// identityProviderGroups is a map of identity provider group name to slice of LXD group names.
 	identityProviderGroups map[string]*[]string
-	mu                     sync.RWMutex
+
+	// identityProviderGroupRules are the rules mapping OIDC token claims to LXD groups.
+	identityProviderGroupRules []identityProviderGroupRule
+	mu                         sync.RWMutex
 }
 
 // CacheEntry represents an identity.
@@ -35,6 +39,10 @@ type CacheEntry struct {
 
 	// Subject is optional. It is only set when AuthenticationMethod is api.AuthenticationMethodOIDC.
 	Subject string
+
+	// BearerTokenHash is optional. It is only set when AuthenticationMethod is api.AuthenticationMethodBearer and a
+	// token has been issued for the identity.
+	BearerTokenHash string
 }
 
 // Get returns a single CacheEntry by its authentication method and identifier.
@@ -204,3 +212,45 @@ func (c *Cache) GetIdentityProviderGroupMapping(idpGroup string) ([]string, erro
 
 	return *lxdGroups, nil
 }
+
+// ReplaceIdentityProviderGroupRules replaces all identity provider group rules in the cache with the given rules.
+// Invalid rules are logged and skipped, so that they don't prevent the other rules from applying.
+func (c *Cache) ReplaceIdentityProviderGroupRules(rules []api.IdentityProviderGroupRule) {
+	compiledRules := make([]identityProviderGroupRule, 0, len(rules))
+	for _, rule := range rules {
+		compiledRule, err := compileIdentityProviderGroupRule(rule)
+		if err != nil {
+			logger.Warn("Skipping invalid identity provider group rule", logger.Ctx{"rule": rule.Name, "err": err})
+			continue
+		}
+
+		compiledRules = append(compiledRules, *compiledRule)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.identityProviderGroupRules = compiledRules
+}
+
+// GetIdentityProviderGroupRuleMapping returns the distinct auth groups that the identity provider group rules matching
+// the given OIDC token claims resolve to.
+func (c *Cache) GetIdentityProviderGroupRuleMapping(claims map[string]any) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var lxdGroups []string
+	for _, rule := range c.identityProviderGroupRules {
+		if !rule.matches(claims) {
+			continue
+		}
+
+		for _, lxdGroup := range rule.groups {
+			if !shared.ValueInSlice(lxdGroup, lxdGroups) {
+				lxdGroups = append(lxdGroups, lxdGroup)
+			}
+		}
+	}
+
+	return lxdGroups
+}
@@ -0,0 +1,101 @@
+package identity
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// DevLXDTokenPrefix is prepended to all instance scoped tokens issued over devlxd. It allows these tokens to be
+// distinguished from bearer tokens and OIDC access tokens, which are sent in the same Authorization header.
+const DevLXDTokenPrefix = "lxd-devlxd-"
+
+// DevLXDTokenTTL is how long an instance scoped token issued over devlxd remains valid.
+const DevLXDTokenTTL = time.Hour
+
+// DevLXDTokenNonceKey is the volatile instance configuration key holding the nonce that instance scoped tokens are
+// bound to. Unsetting it revokes all the tokens issued for the instance.
+const DevLXDTokenNonceKey = "volatile.devlxd.token_nonce"
+
+// DevLXDTokenClaims contains the details of the instance an instance scoped token was issued for.
+type DevLXDTokenClaims struct {
+	Project   string    `json:"project"`
+	Instance  string    `json:"instance"`
+	Nonce     string    `json:"nonce"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// expiry returns the expiry date of the token.
+func (c DevLXDTokenClaims) expiry() time.Time {
+	return c.ExpiresAt
+}
+
+// Username returns the username given to requests authenticated with the token.
+func (c DevLXDTokenClaims) Username() string {
+	return c.Project + "/" + c.Instance
+}
+
+// CheckInstance returns an error if the token can't be used anymore given the expanded configuration of the instance it
+// was issued for, either because tokens were disabled for the instance or because the token was revoked.
+func (c DevLXDTokenClaims) CheckInstance(expandedConfig map[string]string) error {
+	if shared.IsFalseOrEmpty(expandedConfig["security.devlxd.token"]) {
+		return fmt.Errorf("Tokens are disabled for the instance")
+	}
+
+	if c.Nonce == "" || c.Nonce != expandedConfig[DevLXDTokenNonceKey] {
+		return fmt.Errorf("Token has been revoked")
+	}
+
+	return nil
+}
+
+// DevLXDTokenUsername returns the project and instance names from the username of a request authenticated with an
+// instance scoped token.
+func DevLXDTokenUsername(username string) (projectName string, instanceName string, err error) {
+	projectName, instanceName, ok := strings.Cut(username, "/")
+	if !ok || projectName == "" || instanceName == "" {
+		return "", "", fmt.Errorf("Invalid devlxd token username %q", username)
+	}
+
+	return projectName, instanceName, nil
+}
+
+// DevLXDTokenKey derives the key used to sign instance scoped tokens from the given certificate private key.
+// The key of the cluster certificate should be used so that tokens can be verified by all cluster members.
+func DevLXDTokenKey(privateKey []byte) []byte {
+	return signedTokenKey(DevLXDTokenPrefix, privateKey)
+}
+
+// NewDevLXDToken issues a signed token for the given instance, valid for DevLXDTokenTTL.
+// The token is bound to the nonce found in the DevLXDTokenNonceKey configuration key of the instance.
+func NewDevLXDToken(key []byte, projectName string, instanceName string, nonce string) (token string, expiresAt time.Time, err error) {
+	claims := DevLXDTokenClaims{
+		Project:   projectName,
+		Instance:  instanceName,
+		Nonce:     nonce,
+		ExpiresAt: time.Now().Add(DevLXDTokenTTL).UTC().Truncate(time.Second),
+	}
+
+	token, err = newSignedToken(key, DevLXDTokenPrefix, claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("Failed to encode devlxd token: %w", err)
+	}
+
+	return token, claims.ExpiresAt, nil
+}
+
+// DevLXDTokenFromRequest returns the claims of an instance scoped token found in the Authorization header of the
+// request. The second return value is false if the request does not contain such a token. An error is returned if the
+// token is present but its signature is invalid or it has expired.
+func DevLXDTokenFromRequest(r *http.Request, key []byte) (*DevLXDTokenClaims, bool, error) {
+	claims := &DevLXDTokenClaims{}
+	ok, err := signedTokenFromRequest(r, key, DevLXDTokenPrefix, claims)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	return claims, true, nil
+}
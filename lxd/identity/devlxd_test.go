@@ -0,0 +1,228 @@
+package identity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func devLXDTokenRequest(t *testing.T, authorization string) *http.Request {
+	r, err := http.NewRequest(http.MethodGet, "https://lxd/1.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+
+	return r
+}
+
+// signDevLXDTokenClaims returns a token for arbitrary claims, allowing to build tokens that NewDevLXDToken wouldn't issue.
+func signDevLXDTokenClaims(t *testing.T, key []byte, claims DevLXDTokenClaims) string {
+	token, err := newSignedToken(key, DevLXDTokenPrefix, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestDevLXDTokenFromRequest(t *testing.T) {
+	key := DevLXDTokenKey([]byte("cluster key"))
+
+	token, expiresAt, err := NewDevLXDToken(key, "default", "c1", "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expiresAt.After(time.Now().Add(DevLXDTokenTTL)) {
+		t.Fatalf("Token expires after the TTL: %v", expiresAt)
+	}
+
+	encodedPayload, encodedSignature, _ := strings.Cut(strings.TrimPrefix(token, DevLXDTokenPrefix), ".")
+
+	// Replace the instance in the payload while keeping the original signature.
+	forgedPayload, err := json.Marshal(DevLXDTokenClaims{Project: "default", Instance: "c2", Nonce: "nonce", ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		key           []byte
+		found         bool
+		err           string
+	}{
+		{
+			name:  "No authorization header",
+			key:   key,
+			found: false,
+		},
+		{
+			name:          "Other bearer token",
+			authorization: "Bearer lxd-access-foo.bar",
+			key:           key,
+			found:         false,
+		},
+		{
+			name:          "Not a bearer token",
+			authorization: "Basic " + token,
+			key:           key,
+			found:         false,
+		},
+		{
+			name:          "Valid token",
+			authorization: "Bearer " + token,
+			key:           key,
+			found:         true,
+		},
+		{
+			name:          "Wrong key",
+			authorization: "Bearer " + token,
+			key:           DevLXDTokenKey([]byte("other key")),
+			found:         true,
+			err:           "Invalid token signature",
+		},
+		{
+			name:          "Tampered payload",
+			authorization: "Bearer " + DevLXDTokenPrefix + base64.RawURLEncoding.EncodeToString(forgedPayload) + "." + encodedSignature,
+			key:           key,
+			found:         true,
+			err:           "Invalid token signature",
+		},
+		{
+			name:          "Tampered signature",
+			authorization: "Bearer " + DevLXDTokenPrefix + encodedPayload + "." + base64.RawURLEncoding.EncodeToString([]byte("signature")),
+			key:           key,
+			found:         true,
+			err:           "Invalid token signature",
+		},
+		{
+			name:          "Missing signature",
+			authorization: "Bearer " + DevLXDTokenPrefix + encodedPayload,
+			key:           key,
+			found:         true,
+			err:           "Malformed token",
+		},
+		{
+			name:          "Expired token",
+			authorization: "Bearer " + signDevLXDTokenClaims(t, key, DevLXDTokenClaims{Project: "default", Instance: "c1", Nonce: "nonce", ExpiresAt: time.Now().Add(-time.Second)}),
+			key:           key,
+			found:         true,
+			err:           "Token has expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, found, err := DevLXDTokenFromRequest(devLXDTokenRequest(t, tt.authorization), tt.key)
+			if found != tt.found {
+				t.Fatalf("Expected found to be %v, got %v", tt.found, found)
+			}
+
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Expected error %q, got %v", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !tt.found {
+				return
+			}
+
+			if claims.Username() != "default/c1" || claims.Nonce != "nonce" || !claims.ExpiresAt.Equal(expiresAt) {
+				t.Fatalf("Unexpected claims %+v", claims)
+			}
+		})
+	}
+}
+
+func TestDevLXDTokenClaimsCheckInstance(t *testing.T) {
+	claims := DevLXDTokenClaims{Project: "default", Instance: "c1", Nonce: "nonce"}
+
+	tests := []struct {
+		name   string
+		claims DevLXDTokenClaims
+		config map[string]string
+		err    string
+	}{
+		{
+			name:   "Valid",
+			claims: claims,
+			config: map[string]string{"security.devlxd.token": "true", DevLXDTokenNonceKey: "nonce"},
+		},
+		{
+			name:   "Tokens disabled",
+			claims: claims,
+			config: map[string]string{"security.devlxd.token": "false", DevLXDTokenNonceKey: "nonce"},
+			err:    "Tokens are disabled for the instance",
+		},
+		{
+			name:   "Tokens not enabled",
+			claims: claims,
+			config: map[string]string{DevLXDTokenNonceKey: "nonce"},
+			err:    "Tokens are disabled for the instance",
+		},
+		{
+			name:   "Nonce unset",
+			claims: claims,
+			config: map[string]string{"security.devlxd.token": "true"},
+			err:    "Token has been revoked",
+		},
+		{
+			name:   "Nonce rotated",
+			claims: claims,
+			config: map[string]string{"security.devlxd.token": "true", DevLXDTokenNonceKey: "other"},
+			err:    "Token has been revoked",
+		},
+		{
+			name:   "Token without nonce",
+			claims: DevLXDTokenClaims{Project: "default", Instance: "c1"},
+			config: map[string]string{"security.devlxd.token": "true", DevLXDTokenNonceKey: ""},
+			err:    "Token has been revoked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.claims.CheckInstance(tt.config)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("Expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestDevLXDTokenUsername(t *testing.T) {
+	projectName, instanceName, err := DevLXDTokenUsername("default/c1")
+	if err != nil || projectName != "default" || instanceName != "c1" {
+		t.Fatalf("Unexpected result: %q, %q, %v", projectName, instanceName, err)
+	}
+
+	for _, username := range []string{"", "default", "default/", "/c1"} {
+		_, _, err := DevLXDTokenUsername(username)
+		if err == nil {
+			t.Errorf("Expected username %q to be rejected", username)
+		}
+	}
+}
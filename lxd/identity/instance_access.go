@@ -0,0 +1,125 @@
+package identity
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// InstanceAccessTokenPrefix is prepended to all instance access tokens. It allows these tokens to be distinguished
+// from the other tokens sent in the Authorization header.
+const InstanceAccessTokenPrefix = "lxd-access-"
+
+// InstanceAccessTokenMaxTTL is the longest validity an instance access token can be issued with.
+const InstanceAccessTokenMaxTTL = 24 * time.Hour
+
+// instanceAccessReadOnlySuffix is appended to the access kind in the username of read-only tokens.
+const instanceAccessReadOnlySuffix = "-readonly"
+
+// InstanceAccessTokenClaims contains the details of the access granted by an instance access token.
+type InstanceAccessTokenClaims struct {
+	Project   string    `json:"project"`
+	Instance  string    `json:"instance"`
+	Access    string    `json:"access"`
+	ReadOnly  bool      `json:"read_only"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// expiry returns the expiry date of the token.
+func (c InstanceAccessTokenClaims) expiry() time.Time {
+	return c.ExpiresAt
+}
+
+// Username returns the username given to requests authenticated with the token.
+// As these tokens have no associated identity, the username carries all the details needed for authorization.
+func (c InstanceAccessTokenClaims) Username() string {
+	access := c.Access
+	if c.ReadOnly {
+		access += instanceAccessReadOnlySuffix
+	}
+
+	return c.Project + "/" + c.Instance + "/" + access
+}
+
+// CheckConsolePost restricts a console request made with the token to the access it grants. Read-only tokens can only
+// watch the text console, so their requests are made read-only and requests for the VGA console or to disconnect the
+// other clients are rejected.
+func (c InstanceAccessTokenClaims) CheckConsolePost(post *api.InstanceConsolePost) error {
+	if !c.ReadOnly {
+		return nil
+	}
+
+	// An empty type defaults to the text console.
+	if post.Type != "" && post.Type != "console" {
+		return api.StatusErrorf(http.StatusForbidden, "Read-only token only grants access to the text console")
+	}
+
+	if post.Force {
+		return api.StatusErrorf(http.StatusForbidden, "Read-only token can't disconnect the other console clients")
+	}
+
+	post.ReadOnly = true
+
+	return nil
+}
+
+// InstanceAccessTokenUsername returns the claims encoded in the username of a request authenticated with an instance
+// access token. The expiry isn't part of the username and is left unset.
+func InstanceAccessTokenUsername(username string) (*InstanceAccessTokenClaims, error) {
+	fields := strings.Split(username, "/")
+	if len(fields) != 3 || fields[0] == "" || fields[1] == "" {
+		return nil, fmt.Errorf("Invalid instance access token username %q", username)
+	}
+
+	claims := &InstanceAccessTokenClaims{
+		Project:  fields[0],
+		Instance: fields[1],
+	}
+
+	claims.Access, claims.ReadOnly = strings.CutSuffix(fields[2], instanceAccessReadOnlySuffix)
+	if claims.Access != api.InstanceAccessExec && claims.Access != api.InstanceAccessConsole {
+		return nil, fmt.Errorf("Invalid instance access token username %q", username)
+	}
+
+	return claims, nil
+}
+
+// InstanceAccessTokenKey derives the key used to sign instance access tokens from the given certificate private key.
+// The key of the cluster certificate should be used so that tokens can be verified by all cluster members.
+func InstanceAccessTokenKey(privateKey []byte) []byte {
+	return signedTokenKey(InstanceAccessTokenPrefix, privateKey)
+}
+
+// NewInstanceAccessToken issues a signed token granting the given access to an instance for the given duration.
+func NewInstanceAccessToken(key []byte, projectName string, instanceName string, access string, readOnly bool, ttl time.Duration) (token string, expiresAt time.Time, err error) {
+	claims := InstanceAccessTokenClaims{
+		Project:   projectName,
+		Instance:  instanceName,
+		Access:    access,
+		ReadOnly:  readOnly,
+		ExpiresAt: time.Now().Add(ttl).UTC().Truncate(time.Second),
+	}
+
+	token, err = newSignedToken(key, InstanceAccessTokenPrefix, claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("Failed to encode instance access token: %w", err)
+	}
+
+	return token, claims.ExpiresAt, nil
+}
+
+// InstanceAccessTokenFromRequest returns the claims of an instance access token found in the Authorization header of
+// the request. The second return value is false if the request does not contain such a token. An error is returned if
+// the token is present but its signature is invalid or it has expired.
+func InstanceAccessTokenFromRequest(r *http.Request, key []byte) (*InstanceAccessTokenClaims, bool, error) {
+	claims := &InstanceAccessTokenClaims{}
+	ok, err := signedTokenFromRequest(r, key, InstanceAccessTokenPrefix, claims)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	return claims, true, nil
+}
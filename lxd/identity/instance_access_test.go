@@ -0,0 +1,223 @@
+package identity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestInstanceAccessTokenFromRequest(t *testing.T) {
+	key := InstanceAccessTokenKey([]byte("cluster key"))
+
+	token, expiresAt, err := NewInstanceAccessToken(key, "default", "c1", api.InstanceAccessConsole, true, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, encodedSignature, _ := strings.Cut(strings.TrimPrefix(token, InstanceAccessTokenPrefix), ".")
+
+	// Grant write access in the payload while keeping the original signature.
+	forgedPayload, err := json.Marshal(InstanceAccessTokenClaims{Project: "default", Instance: "c1", Access: api.InstanceAccessConsole, ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := newSignedToken(key, InstanceAccessTokenPrefix, InstanceAccessTokenClaims{Project: "default", Instance: "c1", Access: api.InstanceAccessExec, ExpiresAt: time.Now().Add(-time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A devlxd token signed with the devlxd key and presented as an instance access token.
+	devLXDKey := DevLXDTokenKey([]byte("cluster key"))
+	devLXDToken, _, err := NewDevLXDToken(devLXDKey, "default", "c1", "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		found         bool
+		err           string
+	}{
+		{
+			name: "No authorization header",
+		},
+		{
+			name:          "Devlxd token",
+			authorization: "Bearer " + devLXDToken,
+		},
+		{
+			name:          "Devlxd token with instance access prefix",
+			authorization: "Bearer " + InstanceAccessTokenPrefix + strings.TrimPrefix(devLXDToken, DevLXDTokenPrefix),
+			found:         true,
+			err:           "Invalid token signature",
+		},
+		{
+			name:          "Valid token",
+			authorization: "Bearer " + token,
+			found:         true,
+		},
+		{
+			name:          "Forged claims",
+			authorization: "Bearer " + InstanceAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(forgedPayload) + "." + encodedSignature,
+			found:         true,
+			err:           "Invalid token signature",
+		},
+		{
+			name:          "Invalid signature encoding",
+			authorization: "Bearer " + strings.TrimSuffix(token, encodedSignature) + "!!!",
+			found:         true,
+			err:           "Invalid token signature",
+		},
+		{
+			name:          "Expired token",
+			authorization: "Bearer " + expired,
+			found:         true,
+			err:           "Token has expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, "https://lxd/1.0", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+
+			claims, found, err := InstanceAccessTokenFromRequest(r, key)
+			if found != tt.found {
+				t.Fatalf("Expected found to be %v, got %v", tt.found, found)
+			}
+
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Expected error %q, got %v", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if found && claims.Username() != "default/c1/console-readonly" {
+				t.Fatalf("Unexpected claims %+v", claims)
+			}
+		})
+	}
+}
+
+func TestInstanceAccessTokenUsername(t *testing.T) {
+	for _, claims := range []InstanceAccessTokenClaims{
+		{Project: "default", Instance: "c1", Access: api.InstanceAccessExec},
+		{Project: "default", Instance: "c1", Access: api.InstanceAccessConsole},
+		{Project: "p1", Instance: "v1", Access: api.InstanceAccessConsole, ReadOnly: true},
+	} {
+		parsed, err := InstanceAccessTokenUsername(claims.Username())
+		if err != nil {
+			t.Fatalf("Failed parsing username %q: %v", claims.Username(), err)
+		}
+
+		if *parsed != claims {
+			t.Fatalf("Expected %+v, got %+v", claims, *parsed)
+		}
+	}
+
+	for _, username := range []string{"", "default/c1", "default/c1/shell", "/c1/exec", "default//exec", "default/c1/exec/extra"} {
+		_, err := InstanceAccessTokenUsername(username)
+		if err == nil {
+			t.Errorf("Expected username %q to be rejected", username)
+		}
+	}
+}
+
+func TestInstanceAccessTokenClaimsCheckConsolePost(t *testing.T) {
+	readOnly := InstanceAccessTokenClaims{Project: "default", Instance: "c1", Access: api.InstanceAccessConsole, ReadOnly: true}
+	readWrite := InstanceAccessTokenClaims{Project: "default", Instance: "c1", Access: api.InstanceAccessConsole}
+
+	tests := []struct {
+		name     string
+		claims   InstanceAccessTokenClaims
+		post     api.InstanceConsolePost
+		readOnly bool
+		err      bool
+	}{
+		{
+			name:     "Read-only token is made read-only",
+			claims:   readOnly,
+			post:     api.InstanceConsolePost{},
+			readOnly: true,
+		},
+		{
+			name:     "Read-only token on text console",
+			claims:   readOnly,
+			post:     api.InstanceConsolePost{Type: "console"},
+			readOnly: true,
+		},
+		{
+			name:   "Read-only token on VGA console",
+			claims: readOnly,
+			post:   api.InstanceConsolePost{Type: "vga"},
+			err:    true,
+		},
+		{
+			name:   "Read-only token forcing attachment",
+			claims: readOnly,
+			post:   api.InstanceConsolePost{Force: true},
+			err:    true,
+		},
+		{
+			name:   "Read-write token",
+			claims: readWrite,
+			post:   api.InstanceConsolePost{Type: "console", Force: true},
+		},
+		{
+			name:   "Read-write token on VGA console",
+			claims: readWrite,
+			post:   api.InstanceConsolePost{Type: "vga"},
+		},
+		{
+			name:     "Read-write token requesting read-only",
+			claims:   readWrite,
+			post:     api.InstanceConsolePost{ReadOnly: true},
+			readOnly: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := tt.post
+			err := tt.claims.CheckConsolePost(&post)
+			if tt.err {
+				if !api.StatusErrorCheck(err, http.StatusForbidden) {
+					t.Fatalf("Expected forbidden error, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if post.ReadOnly != tt.readOnly {
+				t.Fatalf("Expected read-only to be %v, got %v", tt.readOnly, post.ReadOnly)
+			}
+
+			if post.Force != tt.post.Force || post.Type != tt.post.Type {
+				t.Fatalf("Unexpected change of the request %+v", post)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package identity
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// identityProviderGroupRule is a compiled api.IdentityProviderGroupRule.
+type identityProviderGroupRule struct {
+	conditions []identityProviderGroupRuleCondition
+	groups     []string
+}
+
+// identityProviderGroupRuleCondition is a compiled api.IdentityProviderGroupRuleCondition.
+type identityProviderGroupRuleCondition struct {
+	claim   string
+	pattern *regexp.Regexp
+}
+
+// CompileIdentityProviderGroupRulePattern converts the `*` and `?` wildcards of an identity provider group rule
+// condition pattern into an anchored regular expression.
+func CompileIdentityProviderGroupRulePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("Pattern cannot be empty")
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+// ValidateIdentityProviderGroupRuleConditions validates the conditions of an identity provider group rule.
+func ValidateIdentityProviderGroupRuleConditions(conditions []api.IdentityProviderGroupRuleCondition) error {
+	if len(conditions) == 0 {
+		return fmt.Errorf("Rules must have at least one condition")
+	}
+
+	for _, condition := range conditions {
+		if condition.Claim == "" {
+			return fmt.Errorf("Condition claim cannot be empty")
+		}
+
+		_, err := CompileIdentityProviderGroupRulePattern(condition.Pattern)
+		if err != nil {
+			return fmt.Errorf("Invalid pattern %q for claim %q: %w", condition.Pattern, condition.Claim, err)
+		}
+	}
+
+	return nil
+}
+
+// compileIdentityProviderGroupRule compiles the conditions of the given rule.
+func compileIdentityProviderGroupRule(rule api.IdentityProviderGroupRule) (*identityProviderGroupRule, error) {
+	compiled := &identityProviderGroupRule{
+		conditions: make([]identityProviderGroupRuleCondition, 0, len(rule.Conditions)),
+		groups:     append([]string(nil), rule.Groups...),
+	}
+
+	for _, condition := range rule.Conditions {
+		pattern, err := CompileIdentityProviderGroupRulePattern(condition.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern %q in identity provider group rule %q: %w", condition.Pattern, rule.Name, err)
+		}
+
+		compiled.conditions = append(compiled.conditions, identityProviderGroupRuleCondition{
+			claim:   condition.Claim,
+			pattern: pattern,
+		})
+	}
+
+	return compiled, nil
+}
+
+// matches returns true if all conditions of the rule are met by the given claims.
+func (r *identityProviderGroupRule) matches(claims map[string]any) bool {
+	if len(r.conditions) == 0 {
+		return false
+	}
+
+	for _, condition := range r.conditions {
+		if !condition.matches(claims[condition.claim]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches returns true if the claim value, or any of its values if it is a list, matches the condition pattern.
+// Only scalar values are matched.
+func (c identityProviderGroupRuleCondition) matches(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return c.pattern.MatchString(v)
+	case bool:
+		return c.pattern.MatchString(strconv.FormatBool(v))
+	case float64:
+		return c.pattern.MatchString(strconv.FormatFloat(v, 'f', -1, 64))
+	case []any:
+		for _, element := range v {
+			_, isList := element.([]any)
+			if !isList && c.matches(element) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
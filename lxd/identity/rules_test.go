@@ -0,0 +1,234 @@
+package identity
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestCompileIdentityProviderGroupRulePattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		value    string
+		expected bool
+	}{
+		// Patterns are anchored.
+		{pattern: "admin", value: "admin", expected: true},
+		{pattern: "admin", value: "admins", expected: false},
+		{pattern: "admin", value: "sysadmin", expected: false},
+		{pattern: "admin", value: "sysadmins", expected: false},
+
+		// Wildcards.
+		{pattern: "*", value: "", expected: true},
+		{pattern: "*", value: "anything", expected: true},
+		{pattern: "*@example.com", value: "user@example.com", expected: true},
+		{pattern: "*@example.com", value: "user@example.com.evil", expected: false},
+		{pattern: "team-?", value: "team-a", expected: true},
+		{pattern: "team-?", value: "team-", expected: false},
+		{pattern: "team-?", value: "team-ab", expected: false},
+
+		// Regular expression metacharacters are matched literally.
+		{pattern: "*@example.com", value: "user@exampleXcom", expected: false},
+		{pattern: "a+b", value: "a+b", expected: true},
+		{pattern: "a+b", value: "aab", expected: false},
+		{pattern: "(admin|dev)", value: "(admin|dev)", expected: true},
+		{pattern: "(admin|dev)", value: "admin", expected: false},
+		{pattern: "[ab]", value: "a", expected: false},
+		{pattern: "^admin$", value: "^admin$", expected: true},
+		{pattern: `a\d`, value: "a1", expected: false},
+		{pattern: `a\d`, value: `a\d`, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.value, func(t *testing.T) {
+			pattern, err := CompileIdentityProviderGroupRulePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			matched := pattern.MatchString(tt.value)
+			if matched != tt.expected {
+				t.Fatalf("Expected pattern %q matching %q to be %v, got %v", tt.pattern, tt.value, tt.expected, matched)
+			}
+		})
+	}
+
+	_, err := CompileIdentityProviderGroupRulePattern("")
+	if err == nil {
+		t.Fatal("Expected empty pattern to be rejected")
+	}
+}
+
+func TestIdentityProviderGroupRuleMatches(t *testing.T) {
+	// Claims are decoded from JSON as they would be from an ID token or userinfo response.
+	var claims map[string]any
+	err := json.Unmarshal([]byte(`{
+		"email": "user@example.com",
+		"email_verified": true,
+		"groups": ["dev", "ops", ["nested"]],
+		"level": 3,
+		"org": {"name": "example"}
+	}`), &claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		conditions []api.IdentityProviderGroupRuleCondition
+		expected   bool
+	}{
+		{
+			name:       "String claim",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "email", Pattern: "*@example.com"}},
+			expected:   true,
+		},
+		{
+			name:       "String claim mismatch",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "email", Pattern: "*@example.org"}},
+			expected:   false,
+		},
+		{
+			name:       "Bool claim",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "email_verified", Pattern: "true"}},
+			expected:   true,
+		},
+		{
+			name:       "Bool claim mismatch",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "email_verified", Pattern: "false"}},
+			expected:   false,
+		},
+		{
+			name:       "Number claim",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "level", Pattern: "3"}},
+			expected:   true,
+		},
+		{
+			name:       "List claim element",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "groups", Pattern: "ops"}},
+			expected:   true,
+		},
+		{
+			name:       "List claim wildcard element",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "groups", Pattern: "d*"}},
+			expected:   true,
+		},
+		{
+			name:       "List claim no element",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "groups", Pattern: "admin"}},
+			expected:   false,
+		},
+		{
+			name:       "Nested list elements are ignored",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "groups", Pattern: "nested"}},
+			expected:   false,
+		},
+		{
+			name:       "Object claims are ignored",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "org", Pattern: "*"}},
+			expected:   false,
+		},
+		{
+			name:       "Missing claim",
+			conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "missing", Pattern: "*"}},
+			expected:   false,
+		},
+		{
+			name: "All conditions met",
+			conditions: []api.IdentityProviderGroupRuleCondition{
+				{Claim: "email", Pattern: "*@example.com"},
+				{Claim: "groups", Pattern: "dev"},
+			},
+			expected: true,
+		},
+		{
+			name: "One condition not met",
+			conditions: []api.IdentityProviderGroupRuleCondition{
+				{Claim: "email", Pattern: "*@example.com"},
+				{Claim: "groups", Pattern: "admin"},
+			},
+			expected: false,
+		},
+		{
+			name:       "No conditions",
+			conditions: nil,
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := compileIdentityProviderGroupRule(api.IdentityProviderGroupRule{Name: "rule", Conditions: tt.conditions})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			matched := rule.matches(claims)
+			if matched != tt.expected {
+				t.Fatalf("Expected %v, got %v", tt.expected, matched)
+			}
+		})
+	}
+}
+
+func TestCacheIdentityProviderGroupRuleMapping(t *testing.T) {
+	cache := &Cache{}
+
+	cache.ReplaceIdentityProviderGroupRules([]api.IdentityProviderGroupRule{
+		{
+			Name:       "engineering",
+			Conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "groups", Pattern: "team-*"}},
+			Groups:     []string{"developers", "viewers"},
+		},
+		{
+			Name:       "invalid",
+			Conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "email", Pattern: ""}},
+			Groups:     []string{"admins"},
+		},
+		{
+			Name:       "example",
+			Conditions: []api.IdentityProviderGroupRuleCondition{{Claim: "email", Pattern: "*@example.com"}},
+			Groups:     []string{"viewers"},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		expected []string
+	}{
+		{
+			name:     "Groups of all matching rules are distinct",
+			claims:   map[string]any{"email": "user@example.com", "groups": []any{"team-a", "team-b"}},
+			expected: []string{"developers", "viewers"},
+		},
+		{
+			name:     "Single matching rule",
+			claims:   map[string]any{"email": "user@example.com"},
+			expected: []string{"viewers"},
+		},
+		{
+			name:     "No matching rule",
+			claims:   map[string]any{"email": "user@example.org"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lxdGroups := cache.GetIdentityProviderGroupRuleMapping(tt.claims)
+			if !slices.Equal(lxdGroups, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, lxdGroups)
+			}
+		})
+	}
+
+	// Replacing the rules removes the previous ones.
+	cache.ReplaceIdentityProviderGroupRules(nil)
+	lxdGroups := cache.GetIdentityProviderGroupRuleMapping(map[string]any{"email": "user@example.com"})
+	if len(lxdGroups) != 0 {
+		t.Fatalf("Expected no groups, got %v", lxdGroups)
+	}
+}
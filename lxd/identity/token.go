@@ -0,0 +1,85 @@
+package identity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// signedTokenClaims is implemented by the claims of the stateless tokens signed by LXD.
+type signedTokenClaims interface {
+	expiry() time.Time
+}
+
+// signedTokenKey derives the key used to sign the tokens with the given prefix from a certificate private key.
+// Deriving a different key for each kind of token ensures a token of one kind can't be used as another kind.
+func signedTokenKey(prefix string, privateKey []byte) []byte {
+	hash := sha256.Sum256(append([]byte(prefix), privateKey...))
+	return hash[:]
+}
+
+// newSignedToken returns a token made of the given prefix, the encoded claims and their HMAC-SHA256 signature.
+func newSignedToken(key []byte, prefix string, claims signedTokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	signature := base64.RawURLEncoding.EncodeToString(tokenSignature(key, encodedPayload))
+
+	return prefix + encodedPayload + "." + signature, nil
+}
+
+// signedTokenFromRequest decodes the claims of a token with the given prefix found in the Authorization header of the
+// request. The return value is false if the request does not contain such a token. An error is returned if the token
+// is present but its signature is invalid or it has expired.
+func signedTokenFromRequest(r *http.Request, key []byte, prefix string, claims signedTokenClaims) (bool, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false, nil
+	}
+
+	token, ok = strings.CutPrefix(token, prefix)
+	if !ok {
+		return false, nil
+	}
+
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return true, fmt.Errorf("Malformed token")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, tokenSignature(key, encodedPayload)) {
+		return true, fmt.Errorf("Invalid token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return true, fmt.Errorf("Malformed token")
+	}
+
+	err = json.Unmarshal(payload, claims)
+	if err != nil {
+		return true, fmt.Errorf("Malformed token")
+	}
+
+	if time.Now().After(claims.expiry()) {
+		return true, fmt.Errorf("Token has expired")
+	}
+
+	return true, nil
+}
+
+// tokenSignature returns the HMAC-SHA256 signature of an encoded token payload.
+func tokenSignature(key []byte, encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
@@ -26,6 +26,8 @@ func AuthenticationMethodFromIdentityType(identityType string) (string, error) {
 		return api.AuthenticationMethodTLS, nil
 	case api.IdentityTypeOIDCClient:
 		return api.AuthenticationMethodOIDC, nil
+	case api.IdentityTypeBearerTokenClient:
+		return api.AuthenticationMethodBearer, nil
 	}
 
 	return "", fmt.Errorf("Identity type %q not recognized", identityType)
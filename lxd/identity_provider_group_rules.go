@@ -0,0 +1,641 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/identity"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Identity provider group rules map OIDC clients to groups in the same way as identity provider groups, so they are
+// managed with the same server level entitlements.
+var identityProviderGroupRulesCmd = APIEndpoint{
+	Name: "identity_provider_group_rules",
+	Path: "auth/identity-provider-group-rules",
+	Get: APIEndpointAction{
+		Handler:       getIdentityProviderGroupRules,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewIdentityProviderGroups),
+	},
+	Post: APIEndpointAction{
+		Handler:       createIdentityProviderGroupRule,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanCreateIdentityProviderGroups),
+	},
+}
+
+var identityProviderGroupRuleCmd = APIEndpoint{
+	Name: "identity_provider_group_rule",
+	Path: "auth/identity-provider-group-rules/{ruleName}",
+	Get: APIEndpointAction{
+		Handler:       getIdentityProviderGroupRule,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewIdentityProviderGroups),
+	},
+	Put: APIEndpointAction{
+		Handler:       updateIdentityProviderGroupRule,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEditIdentityProviderGroups),
+	},
+	Post: APIEndpointAction{
+		Handler:       renameIdentityProviderGroupRule,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEditIdentityProviderGroups),
+	},
+	Delete: APIEndpointAction{
+		Handler:       deleteIdentityProviderGroupRule,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanDeleteIdentityProviderGroups),
+	},
+	Patch: APIEndpointAction{
+		Handler:       patchIdentityProviderGroupRule,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEditIdentityProviderGroups),
+	},
+}
+
+func validateIdentityProviderGroupRuleName(name string) error {
+	if name == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Identity provider group rule name cannot be empty")
+	}
+
+	if strings.Contains(name, "/") {
+		return api.StatusErrorf(http.StatusBadRequest, "Identity provider group rule name cannot contain a forward slash")
+	}
+
+	return nil
+}
+
+// identityProviderGroupRuleURL returns the URL of the identity provider group rule with the given name.
+func identityProviderGroupRuleURL(ruleName string) *api.URL {
+	return api.NewURL().Path(version.APIVersion, "auth", "identity-provider-group-rules", ruleName)
+}
+
+// refreshIdentityProviderGroupRules notifies other cluster members to update their identity cache and then updates the
+// local identity cache, so that changes to the rules apply to subsequent requests.
+func refreshIdentityProviderGroupRules(s *state.State) error {
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	err = notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPost, "/internal/identity-cache-refresh", nil, "")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.UpdateIdentityCache()
+
+	return nil
+}
+
+// swagger:operation GET /1.0/auth/identity-provider-group-rules identity_provider_group_rules identity_provider_group_rules_get
+//
+//	Get the identity provider group rules
+//
+//	Returns a list of identity provider group rules (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/auth/identity-provider-group-rules/engineering-teams",
+//	              "/1.0/auth/identity-provider-group-rules/contractors"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/auth/identity-provider-group-rules?recursion=1 identity_provider_group_rules identity_provider_group_rules_get_recursion1
+//
+//	Get the identity provider group rules
+//
+//	Returns a list of identity provider group rules.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of identity provider group rules
+//	          items:
+//	            $ref: "#/definitions/IdentityProviderGroupRule"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func getIdentityProviderGroupRules(d *Daemon, r *http.Request) response.Response {
+	recursion := request.QueryParam(r, "recursion")
+	s := d.State()
+
+	canViewGroup, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeAuthGroup)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed to get a permission checker: %w", err))
+	}
+
+	var apiRules []api.IdentityProviderGroupRule
+	var rules []dbCluster.IdentityProviderGroupRule
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		rules, err = dbCluster.GetIdentityProviderGroupRules(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		if recursion != "1" {
+			return nil
+		}
+
+		apiRules = make([]api.IdentityProviderGroupRule, 0, len(rules))
+		for _, rule := range rules {
+			apiRule, err := rule.ToAPI(ctx, tx.Tx(), canViewGroup)
+			if err != nil {
+				return err
+			}
+
+			apiRules = append(apiRules, *apiRule)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if recursion == "1" {
+		return response.SyncResponse(true, apiRules)
+	}
+
+	ruleURLs := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ruleURLs = append(ruleURLs, identityProviderGroupRuleURL(rule.Name).String())
+	}
+
+	return response.SyncResponse(true, ruleURLs)
+}
+
+// swagger:operation POST /1.0/auth/identity-provider-group-rules identity_provider_group_rules identity_provider_group_rules_post
+//
+//	Create a new identity provider group rule
+//
+//	Creates a new identity provider group rule.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: rule
+//	    description: Identity provider group rule request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/IdentityProviderGroupRulesPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func createIdentityProviderGroupRule(d *Daemon, r *http.Request) response.Response {
+	var rule api.IdentityProviderGroupRulesPost
+	err := json.NewDecoder(r.Body).Decode(&rule)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Failed to unmarshal request body: %w", err))
+	}
+
+	err = validateIdentityProviderGroupRuleName(rule.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = identity.ValidateIdentityProviderGroupRuleConditions(rule.Conditions)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, err := dbCluster.CreateIdentityProviderGroupRule(ctx, tx.Tx(), dbCluster.IdentityProviderGroupRule{
+			Name:        rule.Name,
+			Description: rule.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.SetIdentityProviderGroupRuleConditions(ctx, tx.Tx(), int(id), rule.Conditions)
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.SetIdentityProviderGroupRuleMapping(ctx, tx.Tx(), int(id), rule.Groups)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = refreshIdentityProviderGroupRules(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the rule creation.
+	lc := lifecycle.IdentityProviderGroupRuleCreated.Event(rule.Name, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.SyncResponseLocation(true, nil, identityProviderGroupRuleURL(rule.Name).String())
+}
+
+// swagger:operation GET /1.0/auth/identity-provider-group-rules/{ruleName} identity_provider_group_rules identity_provider_group_rule_get
+//
+//	Get the identity provider group rule
+//
+//	Gets a specific identity provider group rule.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/IdentityProviderGroupRule"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func getIdentityProviderGroupRule(d *Daemon, r *http.Request) response.Response {
+	ruleName, err := url.PathUnescape(mux.Vars(r)["ruleName"])
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to unescape identity provider group rule name path parameter: %w", err))
+	}
+
+	s := d.State()
+	canViewGroup, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeAuthGroup)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var apiRule *api.IdentityProviderGroupRule
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		rule, err := dbCluster.GetIdentityProviderGroupRule(ctx, tx.Tx(), ruleName)
+		if err != nil {
+			return err
+		}
+
+		apiRule, err = rule.ToAPI(ctx, tx.Tx(), canViewGroup)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, apiRule, apiRule)
+}
+
+// swagger:operation PUT /1.0/auth/identity-provider-group-rules/{ruleName} identity_provider_group_rules identity_provider_group_rule_put
+//
+//	Update the identity provider group rule
+//
+//	Replaces the editable fields of an identity provider group rule.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: rule
+//	    description: Update request
+//	    schema:
+//	      $ref: "#/definitions/IdentityProviderGroupRulePut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func updateIdentityProviderGroupRule(d *Daemon, r *http.Request) response.Response {
+	return doIdentityProviderGroupRuleUpdate(d, r, false)
+}
+
+// swagger:operation PATCH /1.0/auth/identity-provider-group-rules/{ruleName} identity_provider_group_rules identity_provider_group_rule_patch
+//
+//	Partially update the identity provider group rule
+//
+//	Updates the description of an identity provider group rule and adds the given conditions and groups to it.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: rule
+//	    description: Update request
+//	    schema:
+//	      $ref: "#/definitions/IdentityProviderGroupRulePut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func patchIdentityProviderGroupRule(d *Daemon, r *http.Request) response.Response {
+	return doIdentityProviderGroupRuleUpdate(d, r, true)
+}
+
+// doIdentityProviderGroupRuleUpdate updates the rule with the request body. When patching, the description is only
+// changed if set and the given conditions and groups are added to the existing ones.
+func doIdentityProviderGroupRuleUpdate(d *Daemon, r *http.Request, patch bool) response.Response {
+	ruleName, err := url.PathUnescape(mux.Vars(r)["ruleName"])
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to unescape path argument: %w", err))
+	}
+
+	var rulePut api.IdentityProviderGroupRulePut
+	err = json.NewDecoder(r.Body).Decode(&rulePut)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Failed to unmarshal request body: %w", err))
+	}
+
+	s := d.State()
+	canViewGroup, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeAuthGroup)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		rule, err := dbCluster.GetIdentityProviderGroupRule(ctx, tx.Tx(), ruleName)
+		if err != nil {
+			return err
+		}
+
+		apiRule, err := rule.ToAPI(ctx, tx.Tx(), canViewGroup)
+		if err != nil {
+			return err
+		}
+
+		err = util.EtagCheck(r, apiRule)
+		if err != nil {
+			return err
+		}
+
+		if patch {
+			if rulePut.Description == "" {
+				rulePut.Description = apiRule.Description
+			}
+
+			for _, condition := range apiRule.Conditions {
+				if !shared.ValueInSlice(condition, rulePut.Conditions) {
+					rulePut.Conditions = append(rulePut.Conditions, condition)
+				}
+			}
+
+			for _, group := range apiRule.Groups {
+				if !shared.ValueInSlice(group, rulePut.Groups) {
+					rulePut.Groups = append(rulePut.Groups, group)
+				}
+			}
+		}
+
+		err = identity.ValidateIdentityProviderGroupRuleConditions(rulePut.Conditions)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "%w", err)
+		}
+
+		err = dbCluster.UpdateIdentityProviderGroupRule(ctx, tx.Tx(), ruleName, dbCluster.IdentityProviderGroupRule{
+			Name:        ruleName,
+			Description: rulePut.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.SetIdentityProviderGroupRuleConditions(ctx, tx.Tx(), rule.ID, rulePut.Conditions)
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.SetIdentityProviderGroupRuleMapping(ctx, tx.Tx(), rule.ID, rulePut.Groups)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = refreshIdentityProviderGroupRules(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the rule update.
+	lc := lifecycle.IdentityProviderGroupRuleUpdated.Event(ruleName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/auth/identity-provider-group-rules/{ruleName} identity_provider_group_rules identity_provider_group_rule_post
+//
+//	Rename the identity provider group rule
+//
+//	Renames the identity provider group rule.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: rule
+//	    description: Update request
+//	    schema:
+//	      $ref: "#/definitions/IdentityProviderGroupRulePost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func renameIdentityProviderGroupRule(d *Daemon, r *http.Request) response.Response {
+	ruleName, err := url.PathUnescape(mux.Vars(r)["ruleName"])
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to unescape path argument: %w", err))
+	}
+
+	var rulePost api.IdentityProviderGroupRulePost
+	err = json.NewDecoder(r.Body).Decode(&rulePost)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Failed to unmarshal request body: %w", err))
+	}
+
+	err = validateIdentityProviderGroupRuleName(rulePost.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		exists, err := dbCluster.IdentityProviderGroupRuleExists(ctx, tx.Tx(), rulePost.Name)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return api.StatusErrorf(http.StatusConflict, "Identity provider group rule %q already exists", rulePost.Name)
+		}
+
+		return dbCluster.RenameIdentityProviderGroupRule(ctx, tx.Tx(), ruleName, rulePost.Name)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the rule rename.
+	lc := lifecycle.IdentityProviderGroupRuleRenamed.Event(rulePost.Name, request.CreateRequestor(r), map[string]any{"old_name": ruleName})
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.SyncResponseLocation(true, nil, identityProviderGroupRuleURL(rulePost.Name).String())
+}
+
+// swagger:operation DELETE /1.0/auth/identity-provider-group-rules/{ruleName} identity_provider_group_rules identity_provider_group_rule_delete
+//
+//	Delete the identity provider group rule
+//
+//	Deletes the identity provider group rule.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deleteIdentityProviderGroupRule(d *Daemon, r *http.Request) response.Response {
+	ruleName, err := url.PathUnescape(mux.Vars(r)["ruleName"])
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to unescape path argument: %w", err))
+	}
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.DeleteIdentityProviderGroupRule(ctx, tx.Tx(), ruleName)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = refreshIdentityProviderGroupRules(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the rule deletion.
+	lc := lifecycle.IdentityProviderGroupRuleDeleted.Event(ruleName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.EmptySyncResponse
+}
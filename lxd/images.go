@@ -33,6 +33,7 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/db/warningtype"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/lifecycle"
@@ -45,6 +46,7 @@ import (
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/lxd/warnings"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
@@ -63,6 +65,12 @@ var imagesCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: imagesPost, AllowUntrusted: true},
 }
 
+var imageCacheExpiryCmd = APIEndpoint{
+	Path: "images/cache-expiry",
+
+	Get: APIEndpointAction{Handler: imageCacheExpiryGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewImages)},
+}
+
 var imageCmd = APIEndpoint{
 	Path: "images/{fingerprint}",
 
@@ -91,6 +99,13 @@ var imageRefreshCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: imageRefresh, AccessHandler: allowPermission(entity.TypeImage, auth.EntitlementCanEdit, "fingerprint")},
 }
 
+var imageSyncCmd = APIEndpoint{
+	Path: "images/{fingerprint}/sync",
+
+	Get:  APIEndpointAction{Handler: imageSyncGet, AccessHandler: allowPermission(entity.TypeImage, auth.EntitlementCanView, "fingerprint")},
+	Post: APIEndpointAction{Handler: imageSyncPost, AccessHandler: allowPermission(entity.TypeImage, auth.EntitlementCanEdit, "fingerprint")},
+}
+
 var imageAliasesCmd = APIEndpoint{
 	Path: "images/aliases",
 
@@ -1226,7 +1241,7 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 	imageOp, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.ImageDownload, nil, metadata, run, nil, nil, r)
 	if err != nil {
 		cleanup(builddir, post)
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(imageOp)
@@ -1665,12 +1680,145 @@ func imagesGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, result)
 }
 
+// swagger:operation GET /1.0/images/cache-expiry images image_cache_expiry_get
+//
+//	Preview the image cache expiry
+//
+//	Returns the expired cached images of the project that the next image cache expiry run deletes.
+//	An expiry in days can be passed to preview the effect of changing `images.remote_cache_expiry`.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: expiry
+//	    description: Number of days after which unused cached images expire
+//	    type: integer
+//	    example: 10
+//	responses:
+//	  "200":
+//	    description: Expired cached images
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of expired cached images
+//	          items:
+//	            $ref: "#/definitions/ImageCacheExpiry"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func imageCacheExpiryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+	projectName := request.ProjectParam(r)
+
+	var expiryDays *int64
+	if r.FormValue("expiry") != "" {
+		days, err := strconv.ParseInt(r.FormValue("expiry"), 10, 64)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid expiry %q: %w", r.FormValue("expiry"), err))
+		}
+
+		expiryDays = &days
+	}
+
+	result := []api.ImageCacheExpiry{}
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Cached images of projects without the images feature are in the default project.
+		hasImages, err := dbCluster.ProjectHasImages(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		imageProjectName := projectName
+		if !hasImages {
+			imageProjectName = api.ProjectDefaultName
+		}
+
+		// The expiry policy of the project the cached images belong to applies.
+		projectsExpiry, err := getImagesCacheExpiry(ctx, s, tx)
+		if err != nil {
+			return err
+		}
+
+		expiry, ok := projectsExpiry[imageProjectName]
+		if !ok {
+			return api.StatusErrorf(http.StatusNotFound, "Project not found")
+		}
+
+		if expiryDays != nil {
+			expiry.days = *expiryDays
+		}
+
+		referencedImages, err := getImagesReferenced(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("Failed getting images referenced by instances: %w", err)
+		}
+
+		cached := true
+		images, err := dbCluster.GetImages(ctx, tx.Tx(), dbCluster.ImageFilter{Cached: &cached, Project: &imageProjectName})
+		if err != nil {
+			return fmt.Errorf("Failed getting images: %w", err)
+		}
+
+		for _, image := range images {
+			referenced := shared.ValueInSlice(image.Fingerprint, referencedImages[image.Project])
+			imageExpiry, expires := expiry.expiry(image, referenced)
+			if !expires || imageExpiry.After(time.Now()) {
+				continue
+			}
+
+			result = append(result, api.ImageCacheExpiry{
+				Fingerprint: image.Fingerprint,
+				Project:     image.Project,
+				LastUsedAt:  image.LastUseDate.Time,
+				ExpiresAt:   imageExpiry,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, result)
+}
+
 func autoUpdateImagesTask(d *Daemon) (task.Func, task.Schedule) {
 	f := func(ctx context.Context) {
 		s := d.State()
 
 		opRun := func(op *operations.Operation) error {
-			return autoUpdateImages(ctx, s)
+			err := autoUpdateImages(ctx, s)
+			if err != nil {
+				return err
+			}
+
+			return autoRebuildOutdatedInstances(ctx, s, op)
 		}
 
 		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ImagesUpdate, nil, nil, opRun, nil, nil, nil)
@@ -1784,6 +1932,7 @@ func autoUpdateImages(ctx context.Context, s *state.State) error {
 		}
 
 		var deleteIDs []int
+		var refreshedProjects []string
 		var newImage *api.Image
 
 		for _, image := range images {
@@ -1815,6 +1964,7 @@ func autoUpdateImages(ctx context.Context, s *state.State) error {
 				}
 			} else {
 				deleteIDs = append(deleteIDs, image.ID)
+				refreshedProjects = append(refreshedProjects, image.Project)
 			}
 
 			// newInfo will have the same content for each image in the list.
@@ -1836,6 +1986,11 @@ func autoUpdateImages(ctx context.Context, s *state.State) error {
 				}
 			}
 
+			err = autoUpdateImageInstances(ctx, s, fingerprint, refreshedProjects, newImage)
+			if err != nil {
+				logger.Error("Failed marking instances using the refreshed image", logger.Ctx{"err": err, "fingerprint": fingerprint})
+			}
+
 			_ = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 				for _, ID := range deleteIDs {
 					// Remove the database entry for the image after distributing to cluster members.
@@ -1853,6 +2008,127 @@ func autoUpdateImages(ctx context.Context, s *state.State) error {
 	return nil
 }
 
+// autoUpdateImageInstances records the new image in the instances that were created from the refreshed image and
+// that have rebuild.image_refresh set, and raises a warning for each of them.
+// The projects are the projects in which the image was refreshed.
+func autoUpdateImageInstances(ctx context.Context, s *state.State, oldFingerprint string, projects []string, newImage *api.Image) error {
+	type outdatedInstance struct {
+		id       int
+		name     string
+		project  string
+		location string
+	}
+
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var outdated []outdatedInstance
+
+		err := tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			if dbInst.Config["volatile.base_image"] != oldFingerprint && dbInst.Config["volatile.base_image.latest"] != oldFingerprint {
+				return nil
+			}
+
+			// Instances of projects without their own images use the images of the default project.
+			imageProject := p.Name
+			if shared.IsFalse(p.Config["features.images"]) {
+				imageProject = api.ProjectDefaultName
+			}
+
+			if !shared.ValueInSlice(imageProject, projects) {
+				return nil
+			}
+
+			expandedConfig := instancetype.ExpandInstanceConfig(nil, dbInst.Config, dbInst.Profiles)
+			if !shared.ValueInSlice(expandedConfig["rebuild.image_refresh"], []string{"warn", "rebuild"}) {
+				return nil
+			}
+
+			outdated = append(outdated, outdatedInstance{id: dbInst.ID, name: dbInst.Name, project: dbInst.Project, location: dbInst.Node})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, inst := range outdated {
+			err = tx.UpdateInstanceConfig(inst.id, map[string]string{"volatile.base_image.latest": newImage.Fingerprint})
+			if err != nil {
+				return fmt.Errorf("Failed updating instance %q (project %q): %w", inst.name, inst.project, err)
+			}
+
+			err = tx.UpsertWarning(ctx, inst.location, inst.project, entity.TypeInstance, inst.id, warningtype.InstanceImageOutdated, fmt.Sprintf("Image %q of the instance was refreshed to %q", oldFingerprint, newImage.Fingerprint))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// autoRebuildOutdatedInstances rebuilds the stopped local instances that have rebuild.image_refresh set to
+// "rebuild" from the newer image recorded in their volatile.base_image.latest key.
+func autoRebuildOutdatedInstances(ctx context.Context, s *state.State, op *operations.Operation) error {
+	type outdatedInstance struct {
+		inst instance.Instance
+		img  *api.Image
+	}
+
+	var outdated []outdatedInstance
+
+	filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			latest := dbInst.Config["volatile.base_image.latest"]
+			if latest == "" {
+				return nil
+			}
+
+			inst, err := instance.Load(s, dbInst, p)
+			if err != nil {
+				return fmt.Errorf("Failed loading instance %q (project %q): %w", dbInst.Name, dbInst.Project, err)
+			}
+
+			if inst.ExpandedConfig()["rebuild.image_refresh"] != "rebuild" || inst.IsRunning() {
+				return nil
+			}
+
+			imageProject := p.Name
+			if shared.IsFalse(p.Config["features.images"]) {
+				imageProject = api.ProjectDefaultName
+			}
+
+			_, img, err := tx.GetImage(ctx, latest, dbCluster.ImageFilter{Project: &imageProject})
+			if err != nil {
+				logger.Warn("Failed getting newer image of instance", logger.Ctx{"err": err, "instance": dbInst.Name, "project": dbInst.Project, "fingerprint": latest})
+				return nil
+			}
+
+			outdated = append(outdated, outdatedInstance{inst: inst, img: img})
+			return nil
+		}, filter)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed getting outdated instances: %w", err)
+	}
+
+	for _, entry := range outdated {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		l := logger.AddContext(logger.Ctx{"instance": entry.inst.Name(), "project": entry.inst.Project().Name, "fingerprint": entry.img.Fingerprint})
+		l.Info("Rebuilding instance from refreshed image")
+
+		err = instanceRebuildFromImage(s, nil, entry.inst, entry.img, nil, op)
+		if err != nil {
+			l.Error("Failed rebuilding instance from refreshed image", logger.Ctx{"err": err})
+			continue
+		}
+	}
+
+	return nil
+}
+
 func distributeImage(ctx context.Context, s *state.State, nodes []string, oldFingerprint string, newImage *api.Image) error {
 	// Get config of all nodes (incl. own) and check for storage.images_volume.
 	// If the setting is missing, distribute the image to the node.
@@ -2467,39 +2743,125 @@ func pruneLeftoverImages(s *state.State) {
 	logger.Infof("Done cleaning up leftover image files")
 }
 
-func pruneExpiredImages(ctx context.Context, s *state.State, op *operations.Operation) error {
-	var err error
-	var projectsImageRemoteCacheExpiryDays map[string]int64
-	var allImages map[string][]dbCluster.Image
+// imageCacheExpiry represents the cached image expiry policy of a project.
+type imageCacheExpiry struct {
+	// Number of days after which an unused cached image expires. Disabled if zero or lower.
+	days int64
 
-	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-		// Get an image remote cache expiry days value for each project and store keyed on project name.
-		globalImageRemoteCacheExpiryDays := s.GlobalConfig.ImagesRemoteCacheExpiryDays()
+	// Whether cached images that instances in the project are based on are kept.
+	keepReferenced bool
+}
 
-		dbProjects, err := dbCluster.GetProjects(ctx, tx.Tx())
+// expiry returns when the cached image expires, and false if it doesn't expire.
+func (e imageCacheExpiry) expiry(image dbCluster.Image, referenced bool) (time.Time, bool) {
+	if e.days <= 0 || (e.keepReferenced && referenced) {
+		return time.Time{}, false
+	}
+
+	timestamp := image.UploadDate
+	if !image.LastUseDate.Time.IsZero() {
+		timestamp = image.LastUseDate.Time
+	}
+
+	return timestamp.Add(time.Duration(e.days) * time.Hour * 24), true
+}
+
+// getImagesCacheExpiry returns the cached image expiry policy of each project keyed on project name.
+func getImagesCacheExpiry(ctx context.Context, s *state.State, tx *db.ClusterTx) (map[string]imageCacheExpiry, error) {
+	globalImageRemoteCacheExpiryDays := s.GlobalConfig.ImagesRemoteCacheExpiryDays()
+
+	dbProjects, err := dbCluster.GetProjects(ctx, tx.Tx())
+	if err != nil {
+		return nil, err
+	}
+
+	projectsExpiry := make(map[string]imageCacheExpiry, len(dbProjects))
+	for _, p := range dbProjects {
+		p, err := p.ToAPI(ctx, tx.Tx())
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		projectsImageRemoteCacheExpiryDays = make(map[string]int64, len(dbProjects))
-		for _, p := range dbProjects {
-			p, err := p.ToAPI(ctx, tx.Tx())
+		expiry := imageCacheExpiry{
+			days:           globalImageRemoteCacheExpiryDays,
+			keepReferenced: shared.IsTrue(p.Config["images.remote_cache_keep_referenced"]),
+		}
+
+		// If there is a project specific image expiry set use that.
+		if p.Config["images.remote_cache_expiry"] != "" {
+			expiry.days, err = strconv.ParseInt(p.Config["images.remote_cache_expiry"], 10, 64)
 			if err != nil {
-				return err
+				return nil, fmt.Errorf("Unable to fetch project configuration: %w", err)
 			}
+		}
 
-			// If there is a project specific image expiry set use that.
-			if p.Config["images.remote_cache_expiry"] != "" {
-				expiry, err := strconv.ParseInt(p.Config["images.remote_cache_expiry"], 10, 64)
-				if err != nil {
-					return fmt.Errorf("Unable to fetch project configuration: %w", err)
-				}
+		projectsExpiry[p.Name] = expiry
+	}
 
-				projectsImageRemoteCacheExpiryDays[p.Name] = expiry
-			} else {
-				// Otherwise use the global default.
-				projectsImageRemoteCacheExpiryDays[p.Name] = globalImageRemoteCacheExpiryDays
+	return projectsExpiry, nil
+}
+
+// getImagesReferenced returns the fingerprints of the images that instances are based on, keyed on the project
+// the images belong to.
+func getImagesReferenced(ctx context.Context, tx *db.ClusterTx) (map[string][]string, error) {
+	baseImageKey := "volatile.base_image"
+	instancesConfig, err := dbCluster.GetConfig(ctx, tx.Tx(), "instance", dbCluster.ConfigFilter{Key: &baseImageKey})
+	if err != nil {
+		return nil, err
+	}
+
+	dbInstances, err := dbCluster.GetInstances(ctx, tx.Tx())
+	if err != nil {
+		return nil, err
+	}
+
+	// Images of projects without the images feature are in the default project.
+	projectsWithImages := make(map[string]string)
+
+	referenced := make(map[string][]string)
+	for _, dbInst := range dbInstances {
+		fingerprint := instancesConfig[dbInst.ID][baseImageKey]
+		if fingerprint == "" {
+			continue
+		}
+
+		imageProject, ok := projectsWithImages[dbInst.Project]
+		if !ok {
+			hasImages, err := dbCluster.ProjectHasImages(ctx, tx.Tx(), dbInst.Project)
+			if err != nil {
+				return nil, err
+			}
+
+			imageProject = api.ProjectDefaultName
+			if hasImages {
+				imageProject = dbInst.Project
 			}
+
+			projectsWithImages[dbInst.Project] = imageProject
+		}
+
+		referenced[imageProject] = append(referenced[imageProject], fingerprint)
+	}
+
+	return referenced, nil
+}
+
+func pruneExpiredImages(ctx context.Context, s *state.State, op *operations.Operation) error {
+	var err error
+	var projectsExpiry map[string]imageCacheExpiry
+	var referencedImages map[string][]string
+	var allImages map[string][]dbCluster.Image
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		// Get the image cache expiry policy of each project.
+		projectsExpiry, err = getImagesCacheExpiry(ctx, s, tx)
+		if err != nil {
+			return err
+		}
+
+		referencedImages, err = getImagesReferenced(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("Failed getting images referenced by instances: %w", err)
 		}
 
 		// Get all cached images across all projects and store them keyed on fingerprint.
@@ -2531,24 +2893,12 @@ func pruneExpiredImages(ctx context.Context, s *state.State, op *operations.Oper
 
 		dbImagesDeleted := 0
 		for _, dbImage := range dbImages {
-			// Get expiry days for image's project.
-			expiryDays := projectsImageRemoteCacheExpiryDays[dbImage.Project]
-
-			// Skip if no project expiry time set.
-			if expiryDays <= 0 {
-				continue
-			}
-
-			// Figure out the expiry of image.
-			timestamp := dbImage.UploadDate
-			if !dbImage.LastUseDate.Time.IsZero() {
-				timestamp = dbImage.LastUseDate.Time
-			}
-
-			imageExpiry := timestamp.Add(time.Duration(expiryDays) * time.Hour * 24)
+			// Figure out the expiry of image using the policy of its project.
+			referenced := shared.ValueInSlice(fingerprint, referencedImages[dbImage.Project])
+			imageExpiry, expires := projectsExpiry[dbImage.Project].expiry(dbImage, referenced)
 
 			// Skip if image is not expired.
-			if imageExpiry.After(time.Now()) {
+			if !expires || imageExpiry.After(time.Now()) {
 				continue
 			}
 
@@ -2815,7 +3165,7 @@ func imageDelete(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.ImageDelete, resources, nil, do, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -4267,7 +4617,7 @@ func imageExportPost(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.ImageDownload, nil, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -4463,6 +4813,11 @@ func imageRefresh(d *Daemon, r *http.Request) response.Response {
 				}
 			}
 
+			err = autoUpdateImageInstances(s.ShutdownCtx, s, fingerprint, []string{projectName}, newImage)
+			if err != nil {
+				logger.Error("Failed marking instances using the refreshed image", logger.Ctx{"err": err, "fingerprint": fingerprint})
+			}
+
 			err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 				// Remove the database entry for the image after distributing to cluster members.
 				return tx.DeleteImage(ctx, imageID)
@@ -4477,7 +4832,7 @@ func imageRefresh(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.ImageRefresh, nil, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -4563,6 +4918,11 @@ func autoSyncImages(ctx context.Context, s *state.State) error {
 				logger.Error("Failed to synchronize images", logger.Ctx{"err": err, "project": projectName, "fingerprint": fingerprint})
 			}
 
+			err = imageSyncWarning(ctx, s, projectName, fingerprint, err)
+			if err != nil {
+				logger.Warn("Failed to update image replication warning", logger.Ctx{"err": err, "project": projectName, "fingerprint": fingerprint})
+			}
+
 			ch <- nil
 		}(projects[0], fingerprint)
 
@@ -4696,6 +5056,258 @@ func imageSyncBetweenNodes(ctx context.Context, s *state.State, r *http.Request,
 	return nil
 }
 
+// imageSyncStatus returns the replication status of the image across the cluster members.
+func imageSyncStatus(ctx context.Context, s *state.State, fingerprint string) (*api.ImageSyncStatus, error) {
+	status := api.ImageSyncStatus{
+		Fingerprint: fingerprint,
+		Members:     []api.ImageSyncMember{},
+	}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		nodes, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to get cluster members: %w", err)
+		}
+
+		offlineThreshold, err := tx.GetNodeOfflineThreshold(ctx)
+		if err != nil {
+			return err
+		}
+
+		nodeNames, err := tx.GetNodeNamesWithImage(ctx, fingerprint)
+		if err != nil {
+			return fmt.Errorf("Failed to get cluster members with the image: %w", err)
+		}
+
+		for _, node := range nodes {
+			status.Members = append(status.Members, api.ImageSyncMember{
+				Name:      node.Name,
+				Available: shared.ValueInSlice(node.Name, nodeNames),
+				Online:    !node.IsOffline(offlineThreshold),
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// -1 means that the image should be replicated on all members.
+	status.DesiredReplicas = len(status.Members)
+	minimalReplica := s.GlobalConfig.ImagesMinimalReplica()
+	if minimalReplica != -1 && int(minimalReplica) < status.DesiredReplicas {
+		status.DesiredReplicas = int(minimalReplica)
+	}
+
+	onlineCount := 0
+	availableCount := 0
+	for _, member := range status.Members {
+		if !member.Online {
+			continue
+		}
+
+		onlineCount++
+		if member.Available {
+			availableCount++
+		}
+	}
+
+	// Offline members can't receive a copy of the image, so they don't count as missing copies.
+	status.Synced = availableCount > 0 && availableCount >= min(status.DesiredReplicas, onlineCount)
+
+	return &status, nil
+}
+
+// imageSyncWarning raises or resolves the replication warning of the image depending on its replication status
+// and on the error of the last synchronization attempt.
+func imageSyncWarning(ctx context.Context, s *state.State, projectName string, fingerprint string, syncErr error) error {
+	var imageID int
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		imageID, _, err = tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	status, err := imageSyncStatus(ctx, s, fingerprint)
+	if err != nil {
+		return err
+	}
+
+	if syncErr == nil && status.Synced {
+		return warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, projectName, warningtype.ImageReplicationFailure, entity.TypeImage, imageID)
+	}
+
+	availableCount := 0
+	for _, member := range status.Members {
+		if member.Online && member.Available {
+			availableCount++
+		}
+	}
+
+	msg := fmt.Sprintf("Image %q is available on %d of the %d desired cluster members", fingerprint, availableCount, status.DesiredReplicas)
+	if syncErr != nil {
+		msg = fmt.Sprintf("%s: %v", msg, syncErr)
+	}
+
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, projectName, entity.TypeImage, imageID, warningtype.ImageReplicationFailure, msg)
+	})
+}
+
+// swagger:operation GET /1.0/images/{fingerprint}/sync images image_sync_get
+//
+//	Get the image replication status
+//
+//	Returns the replication status of the image on each cluster member.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Image replication status
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ImageSyncStatus"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func imageSyncGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	projectName := request.ProjectParam(r)
+	fingerprint, err := url.PathUnescape(mux.Vars(r)["fingerprint"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var imageInfo *api.Image
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, imageInfo, err = tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	status, err := imageSyncStatus(r.Context(), s, imageInfo.Fingerprint)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, status)
+}
+
+// swagger:operation POST /1.0/images/{fingerprint}/sync images image_sync_post
+//
+//	Repair the image replication
+//
+//	Copies the image to the cluster members missing it until it's replicated on enough members.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func imageSyncPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	projectName := request.ProjectParam(r)
+	fingerprint, err := url.PathUnescape(mux.Vars(r)["fingerprint"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var imageInfo *api.Image
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, imageInfo, err = tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(op *operations.Operation) error {
+		syncErr := imageSyncBetweenNodes(s.ShutdownCtx, s, nil, projectName, imageInfo.Fingerprint)
+
+		err := imageSyncWarning(s.ShutdownCtx, s, projectName, imageInfo.Fingerprint, syncErr)
+		if err != nil {
+			logger.Warn("Failed to update image replication warning", logger.Ctx{"err": err, "project": projectName, "fingerprint": imageInfo.Fingerprint})
+		}
+
+		if syncErr != nil {
+			return fmt.Errorf("Failed to replicate image %q: %w", imageInfo.Fingerprint, syncErr)
+		}
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["images"] = []api.URL{*api.NewURL().Path(version.APIVersion, "images", imageInfo.Fingerprint)}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.ImagesSynchronize, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
 func createTokenResponse(s *state.State, r *http.Request, projectName string, fingerprint string, metadata shared.Jmap) response.Response {
 	secret, err := shared.RandomCryptoString()
 	if err != nil {
@@ -4715,7 +5327,7 @@ func createTokenResponse(s *state.State, r *http.Request, projectName string, fi
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassToken, operationtype.ImageToken, resources, meta, nil, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	s.Events.SendLifecycle(projectName, lifecycle.ImageSecretCreated.Event(fingerprint, projectName, op.Requestor(), nil))
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -23,6 +24,7 @@ import (
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
@@ -194,7 +196,7 @@ func instanceCreateFromImage(s *state.State, img *api.Image, args db.InstanceArg
 	return nil
 }
 
-func instanceRebuildFromImage(s *state.State, r *http.Request, inst instance.Instance, img *api.Image, op *operations.Operation) error {
+func instanceRebuildFromImage(s *state.State, r *http.Request, inst instance.Instance, img *api.Image, preservePaths []string, op *operations.Operation) error {
 	// Validate the type of the image matches the type of the instance.
 	imgType, err := instancetype.New(img.Type)
 	if err != nil {
@@ -210,7 +212,7 @@ func instanceRebuildFromImage(s *state.State, r *http.Request, inst instance.Ins
 		return err
 	}
 
-	err = inst.Rebuild(img, op)
+	err = inst.Rebuild(img, preservePaths, op)
 	if err != nil {
 		return fmt.Errorf("Failed rebuilding instance from image: %w", err)
 	}
@@ -218,8 +220,8 @@ func instanceRebuildFromImage(s *state.State, r *http.Request, inst instance.Ins
 	return nil
 }
 
-func instanceRebuildFromEmpty(inst instance.Instance, op *operations.Operation) error {
-	err := inst.Rebuild(nil, op) // Rebuild as empty.
+func instanceRebuildFromEmpty(inst instance.Instance, preservePaths []string, op *operations.Operation) error {
+	err := inst.Rebuild(nil, preservePaths, op) // Rebuild as empty.
 	if err != nil {
 		return fmt.Errorf("Failed rebuilding as an empty instance: %w", err)
 	}
@@ -478,23 +480,44 @@ func instanceLoadNodeProjectAll(ctx context.Context, s *state.State, project str
 	return instances, nil
 }
 
-func autoCreateInstanceSnapshots(ctx context.Context, s *state.State, instances []instance.Instance) error {
+// scheduledInstanceSnapshot is an instance that is due for a scheduled snapshot.
+type scheduledInstanceSnapshot struct {
+	inst instance.Instance
+
+	// schedule is the named snapshot schedule that is due, nil for snapshots.schedule.
+	schedule *util.SnapshotSchedule
+}
+
+func autoCreateInstanceSnapshots(ctx context.Context, s *state.State, snapshots []scheduledInstanceSnapshot) error {
 	// Make the snapshots.
-	for _, inst := range instances {
+	for _, snapshot := range snapshots {
 		err := ctx.Err()
 		if err != nil {
 			return err
 		}
 
+		inst := snapshot.inst
 		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
-		snapshotName, err := instance.NextSnapshotName(s, inst, "snap%d")
+		var snapshotName string
+		expiryExpression := inst.ExpandedConfig()["snapshots.expiry"]
+		if snapshot.schedule != nil {
+			l = l.AddContext(logger.Ctx{"schedule": snapshot.schedule.Name})
+
+			snapshotName, err = instance.NextSnapshotNameFromPattern(s, inst, snapshot.schedule.Pattern())
+			if snapshot.schedule.Expiry != "" {
+				expiryExpression = snapshot.schedule.Expiry
+			}
+		} else {
+			snapshotName, err = instance.NextSnapshotName(s, inst, "snap%d")
+		}
+
 		if err != nil {
 			l.Error("Error retrieving next snapshot name", logger.Ctx{"err": err})
 			return err
 		}
 
-		expiry, err := shared.GetExpiry(time.Now(), inst.ExpandedConfig()["snapshots.expiry"])
+		expiry, err := shared.GetExpiry(time.Now(), expiryExpression)
 		if err != nil {
 			l.Error("Error getting snapshots.expiry date")
 			return err
@@ -505,11 +528,45 @@ func autoCreateInstanceSnapshots(ctx context.Context, s *state.State, instances
 			l.Error("Error creating snapshot", logger.Ctx{"snapshot": snapshotName, "err": err})
 			return err
 		}
+
+		if snapshot.schedule != nil && snapshot.schedule.Keep > 0 {
+			err = pruneInstanceScheduleSnapshots(ctx, inst, *snapshot.schedule)
+			if err != nil {
+				l.Error("Error pruning snapshots of schedule", logger.Ctx{"err": err})
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
+// pruneInstanceScheduleSnapshots deletes the oldest snapshots taken by the named snapshot schedule in excess
+// of the number of snapshots the schedule keeps.
+func pruneInstanceScheduleSnapshots(ctx context.Context, inst instance.Instance, schedule util.SnapshotSchedule) error {
+	snapshots, err := inst.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	owned := make([]instance.Instance, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		_, snapshotName, _ := api.GetParentAndSnapshotName(snapshot.Name())
+		if schedule.Owns(snapshotName) {
+			owned = append(owned, snapshot)
+		}
+	}
+
+	if len(owned) <= schedule.Keep {
+		return nil
+	}
+
+	// Delete the oldest snapshots first.
+	sort.Slice(owned, func(i, j int) bool { return owned[i].CreationDate().Before(owned[j].CreationDate()) })
+
+	return pruneExpiredInstanceSnapshots(ctx, owned[:len(owned)-schedule.Keep])
+}
+
 var instSnapshotsPruneRunning = sync.Map{}
 
 func pruneExpiredInstanceSnapshots(ctx context.Context, snapshots []instance.Instance) error {
@@ -541,7 +598,8 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 	// `f` creates new scheduled instance snapshots and then, prune the expired ones
 	f := func(ctx context.Context) {
 		s := d.State()
-		var instances, expiredSnapshotInstances []instance.Instance
+		var instances []scheduledInstanceSnapshot
+		var expiredSnapshotInstances []instance.Instance
 
 		// Get list of expired instance snapshots for this local member.
 		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
@@ -629,14 +687,22 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 					return fmt.Errorf("Failed loading instance %q (project %q) for snapshot task: %w", dbInst.Name, dbInst.Project, err)
 				}
 
-				// Check if instance has snapshot schedule enabled.
-				schedule, ok := inst.ExpandedConfig()["snapshots.schedule"]
-				if !ok || schedule == "" {
-					return nil
+				var due []scheduledInstanceSnapshot
+
+				// Check if the snapshot schedule of the instance is due.
+				schedule := inst.ExpandedConfig()["snapshots.schedule"]
+				if schedule != "" && snapshotIsScheduledNow(schedule, int64(inst.ID())) {
+					due = append(due, scheduledInstanceSnapshot{inst: inst})
+				}
+
+				// Check if any of the named snapshot schedules of the instance are due.
+				for _, namedSchedule := range util.SnapshotSchedules(inst.ExpandedConfig()) {
+					if snapshotIsScheduledNow(namedSchedule.Schedule, int64(inst.ID())) {
+						due = append(due, scheduledInstanceSnapshot{inst: inst, schedule: &namedSchedule})
+					}
 				}
 
-				// Check if snapshot is scheduled.
-				if !snapshotIsScheduledNow(schedule, int64(inst.ID())) {
+				if len(due) == 0 {
 					return nil
 				}
 
@@ -646,7 +712,7 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 				}
 
 				logger.Debug("Scheduling auto instance snapshot", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name})
-				instances = append(instances, inst)
+				instances = append(instances, due...)
 
 				return nil
 			}, filter)
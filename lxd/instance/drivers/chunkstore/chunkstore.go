@@ -0,0 +1,349 @@
+package chunkstore
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// Chunk size bounds of the content defined chunking. The chunk boundaries only depend on the content, so that a
+// change in the stream only affects the chunks around it even if it shifts the rest of the stream.
+const (
+	minChunkSize = 256 * 1024
+	maxChunkSize = 4 * 1024 * 1024
+
+	// chunkMask yields an average chunk size of 1MiB.
+	chunkMask = (1 << 20) - 1
+)
+
+// gear is the table of the rolling hash used to find the chunk boundaries.
+// It must not change as otherwise the chunks of new streams wouldn't match the chunks already in existing stores.
+var gear [256]uint64
+
+func init() {
+	// Fill the table using splitmix64 with a fixed seed.
+	seed := uint64(0x4c58445f43484e4b)
+	for i := range gear {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		gear[i] = z ^ (z >> 31)
+	}
+}
+
+// Manifest lists the chunks a stream is made of, in order.
+type Manifest struct {
+	Size   int64    `json:"size"`
+	Chunks []string `json:"chunks"`
+}
+
+// Store is a content addressed store of the chunks of streams.
+// Chunks shared by several streams are only stored once.
+type Store struct {
+	path string
+}
+
+// New returns the store at the given path. The directory is created when the first stream is written.
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// chunkPath returns the path of the chunk with the given hash.
+func (s *Store) chunkPath(hash string) string {
+	return filepath.Join(s.path, "chunks", hash[:2], hash)
+}
+
+// refPath returns the path of the reference with the given name.
+func (s *Store) refPath(name string) string {
+	return filepath.Join(s.path, "refs", name+".json")
+}
+
+// Write splits the stream into chunks and adds the chunks that aren't in the store yet.
+// It returns the manifest of the stream and the number of bytes of the stream that were newly stored.
+func (s *Store) Write(r io.Reader) (*Manifest, int64, error) {
+	manifest := &Manifest{Chunks: []string{}}
+	var stored int64
+
+	br := bufio.NewReaderSize(r, maxChunkSize)
+	buf := make([]byte, 0, maxChunkSize)
+
+	for {
+		chunk, err := nextChunk(br, buf)
+		if len(chunk) > 0 {
+			sum := sha256.Sum256(chunk)
+			hash := hex.EncodeToString(sum[:])
+
+			added, errAdd := s.addChunk(hash, chunk)
+			if errAdd != nil {
+				return nil, -1, errAdd
+			}
+
+			if added {
+				stored += int64(len(chunk))
+			}
+
+			manifest.Chunks = append(manifest.Chunks, hash)
+			manifest.Size += int64(len(chunk))
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, -1, err
+		}
+	}
+
+	return manifest, stored, nil
+}
+
+// nextChunk reads the next chunk of the stream into buf.
+// It returns io.EOF along with the last chunk once the end of the stream is reached.
+func nextChunk(br *bufio.Reader, buf []byte) ([]byte, error) {
+	buf = buf[:0]
+	var hash uint64
+
+	for len(buf) < maxChunkSize {
+		b, err := br.ReadByte()
+		if err != nil {
+			return buf, err
+		}
+
+		buf = append(buf, b)
+		hash = (hash << 1) + gear[b]
+
+		if len(buf) >= minChunkSize && hash&chunkMask == 0 {
+			break
+		}
+	}
+
+	return buf, nil
+}
+
+// addChunk stores a chunk unless it already exists. Returns whether the chunk was added.
+func (s *Store) addChunk(hash string, chunk []byte) (bool, error) {
+	path := s.chunkPath(hash)
+	if shared.PathExists(path) {
+		return false, nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return false, err
+	}
+
+	// Write to a temporary file first so that an interrupted write doesn't leave a truncated chunk behind.
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = os.Remove(f.Name()) }()
+	defer func() { _ = f.Close() }()
+
+	w, err := gzip.NewWriterLevel(f, gzip.BestSpeed)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = w.Write(chunk)
+	if err != nil {
+		return false, fmt.Errorf("Failed writing chunk %q: %w", hash, err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return false, fmt.Errorf("Failed writing chunk %q: %w", hash, err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return false, err
+	}
+
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Reader returns a reader of the stream described by the manifest.
+// The content of each chunk is checked against its hash.
+func (s *Store) Reader(manifest *Manifest) io.ReadCloser {
+	pipeRead, pipeWrite := io.Pipe()
+
+	go func() {
+		for _, hash := range manifest.Chunks {
+			err := s.readChunk(hash, pipeWrite)
+			if err != nil {
+				_ = pipeWrite.CloseWithError(err)
+				return
+			}
+		}
+
+		_ = pipeWrite.Close()
+	}()
+
+	return pipeRead
+}
+
+// readChunk writes the content of a chunk to w.
+func (s *Store) readChunk(hash string, w io.Writer) error {
+	f, err := os.Open(s.chunkPath(hash))
+	if err != nil {
+		return fmt.Errorf("Failed opening chunk %q: %w", hash, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("Failed reading chunk %q: %w", hash, err)
+	}
+
+	defer func() { _ = r.Close() }()
+
+	chunk, err := io.ReadAll(io.LimitReader(r, maxChunkSize+1))
+	if err != nil {
+		return fmt.Errorf("Failed reading chunk %q: %w", hash, err)
+	}
+
+	sum := sha256.Sum256(chunk)
+	if hex.EncodeToString(sum[:]) != hash {
+		return fmt.Errorf("Chunk %q is corrupted", hash)
+	}
+
+	_, err = w.Write(chunk)
+	return err
+}
+
+// AddReference records that the stream with the given name uses the chunks of the manifest.
+// Chunks that are referenced aren't removed by Prune.
+func (s *Store) AddReference(name string, manifest *Manifest) error {
+	err := os.MkdirAll(filepath.Join(s.path, "refs"), 0700)
+	if err != nil {
+		return err
+	}
+
+	return WriteManifest(s.refPath(name), manifest)
+}
+
+// RemoveReference removes the reference with the given name.
+func (s *Store) RemoveReference(name string) error {
+	err := os.Remove(s.refPath(name))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// References returns the names of the references of the store.
+func (s *Store) References() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.path, "refs"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), ".json")
+		if found {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// Prune removes the chunks that aren't used by any reference. Returns the number of removed chunks.
+func (s *Store) Prune() (int, error) {
+	names, err := s.References()
+	if err != nil {
+		return -1, err
+	}
+
+	used := map[string]bool{}
+	for _, name := range names {
+		manifest, err := ReadManifest(s.refPath(name))
+		if err != nil {
+			return -1, err
+		}
+
+		for _, hash := range manifest.Chunks {
+			used[hash] = true
+		}
+	}
+
+	removed := 0
+	chunksPath := filepath.Join(s.path, "chunks")
+	err = filepath.WalkDir(chunksPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == chunksPath {
+				return filepath.SkipDir
+			}
+
+			return err
+		}
+
+		if entry.IsDir() || used[entry.Name()] {
+			return nil
+		}
+
+		err = os.Remove(path)
+		if err != nil {
+			return err
+		}
+
+		removed++
+		return nil
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return removed, nil
+}
+
+// ReadManifest reads a manifest from a file.
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	err = json.Unmarshal(data, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing manifest %q: %w", path, err)
+	}
+
+	return manifest, nil
+}
+
+// WriteManifest writes a manifest to a file.
+func WriteManifest(path string, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
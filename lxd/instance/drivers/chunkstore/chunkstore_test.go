@@ -0,0 +1,128 @@
+package chunkstore
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// randomData returns size bytes of deterministic pseudo random data.
+func randomData(seed int64, size int) []byte {
+	data := make([]byte, size)
+	_, _ = rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func TestStoreWriteRead(t *testing.T) {
+	store := New(t.TempDir())
+
+	data := randomData(1, 8*1024*1024)
+	manifest, stored, err := store.Write(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Size != int64(len(data)) || stored != int64(len(data)) {
+		t.Fatalf("Unexpected size %d (stored %d), expected %d", manifest.Size, stored, len(data))
+	}
+
+	r := store.Reader(manifest)
+	defer func() { _ = r.Close() }()
+
+	result, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(result, data) {
+		t.Fatal("Stream read from the store doesn't match the written stream")
+	}
+}
+
+func TestStoreIncremental(t *testing.T) {
+	store := New(t.TempDir())
+
+	data := randomData(2, 16*1024*1024)
+	_, _, err := store.Write(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Insert a few bytes in the middle, shifting the rest of the stream.
+	changed := append(append(append([]byte{}, data[:8*1024*1024]...), []byte("changed")...), data[8*1024*1024:]...)
+	manifest, stored, err := store.Write(bytes.NewReader(changed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored > maxChunkSize*2 {
+		t.Fatalf("Stored %d bytes for a small change, expected at most %d", stored, maxChunkSize*2)
+	}
+
+	result, err := io.ReadAll(store.Reader(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(result, changed) {
+		t.Fatal("Stream read from the store doesn't match the written stream")
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	store := New(t.TempDir())
+
+	first, _, err := store.Write(bytes.NewReader(randomData(3, 4*1024*1024)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, _, err := store.Write(bytes.NewReader(randomData(4, 4*1024*1024)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.AddReference("snap0", first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.AddReference("snap1", second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := store.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed != 0 {
+		t.Fatalf("Pruned %d chunks still in use", removed)
+	}
+
+	err = store.RemoveReference("snap0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err = store.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed != len(first.Chunks) {
+		t.Fatalf("Pruned %d chunks, expected %d", removed, len(first.Chunks))
+	}
+
+	_, err = io.ReadAll(store.Reader(second))
+	if err != nil {
+		t.Fatalf("Failed reading remaining stream: %v", err)
+	}
+
+	_, err = io.ReadAll(store.Reader(first))
+	if err == nil {
+		t.Fatal("Expected reading a pruned stream to fail")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -16,6 +17,7 @@ import (
 	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/warningtype"
 	"github.com/canonical/lxd/lxd/device"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/device/nictype"
@@ -23,12 +25,14 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/instance/operationlock"
 	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/linux"
 	"github.com/canonical/lxd/lxd/locking"
 	"github.com/canonical/lxd/lxd/maas"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/warnings"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
@@ -616,6 +620,7 @@ func (d *common) rebuildCommon(inst instance.Instance, img *api.Image, op *opera
 	}
 
 	delete(instLocalConfig, "volatile.base_image")
+	delete(instLocalConfig, "volatile.base_image.latest")
 	if img != nil {
 		for k, v := range img.Properties {
 			instLocalConfig[fmt.Sprintf("image.%s", k)] = v
@@ -672,6 +677,10 @@ func (d *common) rebuildCommon(inst instance.Instance, img *api.Image, op *opera
 	}
 
 	d.localConfig = instLocalConfig
+
+	// The instance now uses the image it was rebuilt from.
+	_ = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(d.state.DB.Cluster, d.project.Name, warningtype.InstanceImageOutdated, entity.TypeInstance, d.id)
+
 	return nil
 }
 
@@ -1021,6 +1030,10 @@ func (d *common) validateStartup(statusCode api.StatusCode) error {
 		return err
 	}
 
+	if shared.IsTrue(d.expandedConfig["security.core_scheduling"]) && !d.state.OS.CoreScheduling {
+		return fmt.Errorf("Core scheduling isn't supported by the kernel")
+	}
+
 	return nil
 }
 
@@ -1116,9 +1129,13 @@ func (d *common) canMigrate(inst instance.Instance) (migrate bool, live bool) {
 	}
 
 	// Check if set up for live migration.
-	// Limit automatic live-migration to virtual machines for now.
-	if inst.Type() == instancetype.VM {
+	// Containers can only be live-migrated if CRIU is available.
+	switch inst.Type() {
+	case instancetype.VM:
 		live = shared.IsTrue(config["migration.stateful"])
+	case instancetype.Container:
+		_, err := exec.LookPath("criu")
+		live = err == nil && shared.IsTrue(config["migration.stateful"])
 	}
 
 	return true, live
@@ -1152,8 +1169,29 @@ func (d *common) recordLastState() error {
 	})
 }
 
+// coreSchedulingEnabled returns whether the instance should be placed in its own core scheduling domain.
+func (d *common) coreSchedulingEnabled() bool {
+	return d.state.OS.CoreScheduling && shared.IsTrueOrEmpty(d.expandedConfig["security.core_scheduling"])
+}
+
+// coreSchedulingCookie returns the core scheduling cookie of the thread with the given PID.
+// Zero is returned if core scheduling isn't used by the instance or the cookie can't be retrieved.
+func (d *common) coreSchedulingCookie(pid int) uint64 {
+	if pid <= 0 || !d.coreSchedulingEnabled() {
+		return 0
+	}
+
+	cookie, err := linux.CoreSchedulingCookie(pid)
+	if err != nil {
+		d.logger.Debug("Failed getting core scheduling cookie", logger.Ctx{"pid": pid, "err": err})
+		return 0
+	}
+
+	return cookie
+}
+
 func (d *common) setCoreSched(pids []int) error {
-	if !d.state.OS.CoreScheduling {
+	if !d.coreSchedulingEnabled() {
 		return nil
 	}
 
@@ -1419,15 +1457,19 @@ func (d *common) devicesRegister(inst instance.Instance) {
 }
 
 // devicesUpdate applies device changes to an instance.
-func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfig.Devices, addDevices deviceConfig.Devices, updateDevices deviceConfig.Devices, oldExpandedDevices deviceConfig.Devices, instanceRunning bool, userRequested bool) (devlxdEvents []map[string]any, err error) {
+// Returns a revert hook that restores the previous devices, allowing the caller to undo the device changes if
+// applying the rest of the instance update fails.
+func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfig.Devices, addDevices deviceConfig.Devices, updateDevices deviceConfig.Devices, oldExpandedDevices deviceConfig.Devices, instanceRunning bool, userRequested bool) (devlxdEvents []map[string]any, cleanup revert.Hook, err error) {
 	revert := revert.New()
 	defer revert.Fail()
 
 	dm, ok := inst.(deviceManager)
 	if !ok {
-		return nil, fmt.Errorf("Instance is not compatible with deviceManager interface")
+		return nil, nil, fmt.Errorf("Instance is not compatible with deviceManager interface")
 	}
 
+	newExpandedDevices := d.expandedDevices.Clone()
+
 	// Remove devices in reverse order to how they were added.
 	for _, entry := range removeDevices.Reversed() {
 		l := d.logger.AddContext(logger.Ctx{"device": entry.Name, "userRequested": userRequested})
@@ -1446,19 +1488,58 @@ func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfi
 			if instanceRunning {
 				err = dm.deviceStop(dev, instanceRunning, "")
 				if err != nil {
-					return nil, fmt.Errorf("Failed to stop device %q: %w", dev.Name(), err)
+					return nil, nil, fmt.Errorf("Failed to stop device %q: %w", dev.Name(), err)
 				}
 
-				devlxdEvents = append(devlxdEvents, map[string]any{
+				event := map[string]any{
 					"action": "removed",
 					"name":   entry.Name,
 					"config": entry.Config,
-				})
+				}
+
+				// Indicate to the lxd-agent which path to unmount inside the guest.
+				if d.Type() == instancetype.VM && entry.Config["type"] == "disk" && entry.Config["path"] != "" {
+					if shared.IsTrueOrEmpty(d.expandedConfig["agent.mounts"]) {
+						event["mount"] = instancetype.VMAgentMount{
+							Target: entry.Config["path"],
+						}
+					} else {
+						event["skip_mount"] = true
+					}
+				}
+
+				devlxdEvents = append(devlxdEvents, event)
 			}
 
 			err = d.deviceRemove(dev, instanceRunning)
 			if err != nil && err != device.ErrUnsupportedDevType {
-				return nil, fmt.Errorf("Failed to remove device %q: %w", dev.Name(), err)
+				return nil, nil, fmt.Errorf("Failed to remove device %q: %w", dev.Name(), err)
+			}
+
+			// Restore the removed device if a later step fails.
+			revert.Add(func() {
+				err := d.deviceAdd(dev, instanceRunning)
+				if err != nil {
+					l.Error("Failed to add device back after update failed", logger.Ctx{"err": err})
+					return
+				}
+
+				if instanceRunning {
+					_, err = dm.deviceStart(dev, instanceRunning)
+					if err != nil && err != device.ErrUnsupportedDevType {
+						l.Error("Failed to start device again after update failed", logger.Ctx{"err": err})
+					}
+				}
+			})
+		}
+
+		// Keep the volatile keys of the removed device so that they can be restored on failure
+		// (such as a NIC's generated MAC address).
+		devicePrefix := fmt.Sprintf("volatile.%s.", entry.Name)
+		oldVolatile := map[string]string{}
+		for k, v := range d.localConfig {
+			if strings.HasPrefix(k, devicePrefix) {
+				oldVolatile[k] = v
 			}
 		}
 
@@ -1467,7 +1548,16 @@ func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfi
 		// this device (as its an actual removal or a device type change).
 		err = d.deviceVolatileReset(entry.Name, entry.Config, addDevices[entry.Name])
 		if err != nil {
-			return nil, fmt.Errorf("Failed to reset volatile data for device %q: %w", entry.Name, err)
+			return nil, nil, fmt.Errorf("Failed to reset volatile data for device %q: %w", entry.Name, err)
+		}
+
+		if len(oldVolatile) > 0 {
+			revert.Add(func() {
+				err := d.VolatileSet(oldVolatile)
+				if err != nil {
+					l.Error("Failed to restore volatile data after update failed", logger.Ctx{"err": err})
+				}
+			})
 		}
 	}
 
@@ -1481,7 +1571,7 @@ func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfi
 			}
 
 			if userRequested {
-				return nil, fmt.Errorf("Failed add validation for device %q: %w", entry.Name, err)
+				return nil, nil, fmt.Errorf("Failed add validation for device %q: %w", entry.Name, err)
 			}
 
 			// If update is non-user requested (i.e from a snapshot restore), there's nothing we can
@@ -1494,7 +1584,7 @@ func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfi
 		err = d.deviceAdd(dev, instanceRunning)
 		if err != nil {
 			if userRequested {
-				return nil, fmt.Errorf("Failed to add device %q: %w", dev.Name(), err)
+				return nil, nil, fmt.Errorf("Failed to add device %q: %w", dev.Name(), err)
 			}
 
 			// If update is non-user requested (i.e from a snapshot restore), there's nothing we can
@@ -1507,12 +1597,12 @@ func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfi
 		if instanceRunning {
 			err = dev.PreStartCheck()
 			if err != nil {
-				return nil, fmt.Errorf("Failed pre-start check for device %q: %w", dev.Name(), err)
+				return nil, nil, fmt.Errorf("Failed pre-start check for device %q: %w", dev.Name(), err)
 			}
 
 			runConf, err := dm.deviceStart(dev, instanceRunning)
 			if err != nil && err != device.ErrUnsupportedDevType {
-				return nil, fmt.Errorf("Failed to start device %q: %w", dev.Name(), err)
+				return nil, nil, fmt.Errorf("Failed to start device %q: %w", dev.Name(), err)
 			}
 
 			revert.Add(func() { _ = dm.deviceStop(dev, instanceRunning, "") })
@@ -1523,13 +1613,24 @@ func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfi
 				"config": entry.Config,
 			}
 
-			if len(runConf.Mounts) > 0 {
+			// Indicate to the lxd-agent which share to mount inside the guest.
+			if len(runConf.Mounts) > 0 && shared.IsFalse(d.expandedConfig["agent.mounts"]) {
+				event["skip_mount"] = true
+			} else if len(runConf.Mounts) > 0 {
 				for _, opt := range runConf.Mounts[0].Opts {
 					if strings.HasPrefix(opt, "mountTag=") {
 						parts := strings.SplitN(opt, "=", 2)
-						event["mount"] = instancetype.VMAgentMount{
+						mount := instancetype.VMAgentMount{
 							Source: parts[1],
+							Target: entry.Config["path"],
+							FSType: "virtiofs",
+						}
+
+						if shared.ValueInSlice("ro", runConf.Mounts[0].Opts) {
+							mount.Options = append(mount.Options, "ro")
 						}
+
+						event["mount"] = mount
 					}
 				}
 			}
@@ -1547,7 +1648,7 @@ func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfi
 			}
 
 			if userRequested {
-				return nil, fmt.Errorf("Failed update validation for device %q: %w", entry.Name, err)
+				return nil, nil, fmt.Errorf("Failed update validation for device %q: %w", entry.Name, err)
 			}
 
 			// If update is non-user requested (i.e from a snapshot restore), there's nothing we can
@@ -1586,12 +1687,27 @@ func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfi
 
 		err = dev.Update(oldExpandedDevices, instanceRunning)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to update device %q: %w", dev.Name(), err)
+			return nil, nil, fmt.Errorf("Failed to update device %q: %w", dev.Name(), err)
+		}
+
+		// Apply the previous device config again if a later step fails.
+		oldDev, err := d.deviceLoad(inst, entry.Name, oldExpandedDevices[entry.Name])
+		if err != nil {
+			l.Warn("Failed to load previous device config, device changes won't be reverted on failure", logger.Ctx{"err": err})
+			continue
 		}
+
+		revert.Add(func() {
+			err := oldDev.Update(newExpandedDevices, instanceRunning)
+			if err != nil {
+				l.Error("Failed to restore previous device config after update failed", logger.Ctx{"err": err})
+			}
+		})
 	}
 
+	cleanup = revert.Clone().Fail
 	revert.Success()
-	return devlxdEvents, nil
+	return devlxdEvents, cleanup, nil
 }
 
 // devicesRemove runs device removal function for each device.
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net"
 	"net/http"
 	"os"
@@ -712,15 +713,17 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
-	if d.state.OS.ContainerCoreScheduling {
-		err = lxcSetConfigItem(cc, "lxc.sched.core", "1")
-		if err != nil {
-			return nil, err
-		}
-	} else if d.state.OS.CoreScheduling {
-		err = lxcSetConfigItem(cc, "lxc.hook.start-host", fmt.Sprintf("/proc/%d/exe forkcoresched 1", os.Getpid()))
-		if err != nil {
-			return nil, err
+	if d.coreSchedulingEnabled() {
+		if d.state.OS.ContainerCoreScheduling {
+			err = lxcSetConfigItem(cc, "lxc.sched.core", "1")
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			err = lxcSetConfigItem(cc, "lxc.hook.start-host", fmt.Sprintf("/proc/%d/exe forkcoresched 1", os.Getpid()))
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -1051,6 +1054,50 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// Containers created from OCI images run the entrypoint of the image instead of an init system.
+	entrypoint := d.expandedConfig["image.oci.entrypoint"]
+	if entrypoint != "" {
+		err = lxcSetConfigItem(cc, "lxc.init.cmd", entrypoint)
+		if err != nil {
+			return nil, err
+		}
+
+		ociConfig := map[string]string{
+			"lxc.init.cwd": d.expandedConfig["image.oci.cwd"],
+			"lxc.init.uid": d.expandedConfig["image.oci.uid"],
+			"lxc.init.gid": d.expandedConfig["image.oci.gid"],
+		}
+
+		for key, value := range ociConfig {
+			if value == "" {
+				continue
+			}
+
+			err = lxcSetConfigItem(cc, key, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// The environment of the image doesn't override the environment of the instance.
+		for k, v := range d.expandedConfig {
+			name, found := strings.CutPrefix(k, "image.oci.env.")
+			if !found {
+				continue
+			}
+
+			_, overridden := d.expandedConfig["environment."+name]
+			if overridden {
+				continue
+			}
+
+			err = lxcSetConfigItem(cc, "lxc.environment", fmt.Sprintf("%s=%s", name, v))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Setup NVIDIA runtime
 	if shared.IsTrue(d.expandedConfig["nvidia.runtime"]) {
 		hookDir := os.Getenv("LXD_LXC_HOOK")
@@ -2227,6 +2274,14 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		}
 	}
 
+	// Pass the KVM devices if requested.
+	if shared.IsTrue(d.expandedConfig["security.kvm"]) {
+		err = d.setupKVMDevices(cc, nextIdmap)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
 	// Override NVIDIA_VISIBLE_DEVICES if we have devices that need it.
 	if len(nvidiaDevices) > 0 {
 		err = lxcSetConfigItem(cc, "lxc.environment", fmt.Sprintf("NVIDIA_VISIBLE_DEVICES=%s", strings.Join(nvidiaDevices, ",")))
@@ -2872,8 +2927,151 @@ func (d *lxc) Restart(timeout time.Duration) error {
 }
 
 // Rebuild rebuilds the instance using the supplied image fingerprint as source.
-func (d *lxc) Rebuild(img *api.Image, op *operations.Operation) error {
-	return d.rebuildCommon(d, img, op)
+// The directories of the root filesystem listed in preservePaths are stashed before the rebuild and restored after.
+func (d *lxc) Rebuild(img *api.Image, preservePaths []string, op *operations.Operation) error {
+	if len(preservePaths) == 0 {
+		return d.rebuildCommon(d, img, op)
+	}
+
+	stashPath, err := os.MkdirTemp(shared.VarPath("backups"), "lxd_rebuild_")
+	if err != nil {
+		return fmt.Errorf("Failed creating temporary directory for preserved paths: %w", err)
+	}
+
+	d.updateProgress("Stashing preserved paths")
+
+	stashed, err := d.rebuildStashPaths(stashPath, preservePaths)
+	if err != nil {
+		_ = os.RemoveAll(stashPath)
+		return err
+	}
+
+	// From here on the preserved data is only kept in the stash, so leave it in place on failure.
+	err = d.rebuildCommon(d, img, op)
+	if err != nil {
+		return fmt.Errorf("Failed rebuilding instance (preserved paths were left in %q): %w", stashPath, err)
+	}
+
+	d.updateProgress("Restoring preserved paths")
+
+	err = d.rebuildRestorePaths(preservePaths, stashed)
+	if err != nil {
+		return fmt.Errorf("Failed restoring preserved paths (they were left in %q): %w", stashPath, err)
+	}
+
+	d.updateProgress("")
+
+	return os.RemoveAll(stashPath)
+}
+
+// rebuildStashPaths copies the preserved directories of the root filesystem into stashPath.
+// The copies are unshifted from the on-disk idmap, as the rebuilt root filesystem gets shifted again on next start.
+// Returns the stash directory of each preserved path that exists.
+func (d *lxc) rebuildStashPaths(stashPath string, preservePaths []string) (map[string]string, error) {
+	_, err := d.mount()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = d.unmount() }()
+
+	diskIdmap, err := d.DiskIdmap()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting on-disk idmap: %w", err)
+	}
+
+	stashed := make(map[string]string, len(preservePaths))
+	for i, path := range preservePaths {
+		sourcePath, err := rebuildPreservedHostPath(d.RootfsPath(), path)
+		if err != nil {
+			return nil, err
+		}
+
+		fi, err := os.Lstat(sourcePath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				d.logger.Debug("Skipping missing preserved path", logger.Ctx{"path": path})
+				continue
+			}
+
+			return nil, err
+		}
+
+		if !fi.IsDir() {
+			return nil, fmt.Errorf("Preserved path %q isn't a directory", path)
+		}
+
+		targetPath := filepath.Join(stashPath, strconv.Itoa(i))
+		_, err = rsync.LocalCopy(sourcePath, targetPath, "", true)
+		if err != nil {
+			return nil, fmt.Errorf("Failed stashing preserved path %q: %w", path, err)
+		}
+
+		if diskIdmap != nil {
+			err = diskIdmap.UnshiftRootfs(targetPath, nil)
+			if err != nil {
+				return nil, fmt.Errorf("Failed unshifting preserved path %q: %w", path, err)
+			}
+		}
+
+		stashed[path] = targetPath
+	}
+
+	return stashed, nil
+}
+
+// rebuildRestorePaths copies the stashed directories back into the rebuilt root filesystem.
+// Existing content of the image at those paths is replaced.
+func (d *lxc) rebuildRestorePaths(preservePaths []string, stashed map[string]string) error {
+	_, err := d.mount()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = d.unmount() }()
+
+	for _, path := range preservePaths {
+		stashedPath, ok := stashed[path]
+		if !ok {
+			continue
+		}
+
+		targetPath, err := rebuildPreservedHostPath(d.RootfsPath(), path)
+		if err != nil {
+			return err
+		}
+
+		_, err = rsync.LocalCopy(stashedPath, targetPath, "", true)
+		if err != nil {
+			return fmt.Errorf("Failed restoring preserved path %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// rebuildPreservedHostPath returns the host path of path inside the root filesystem at rootfsPath.
+// Symlinks aren't allowed in the existing part of the path, so that it can't lead outside of the root filesystem.
+func rebuildPreservedHostPath(rootfsPath string, path string) (string, error) {
+	hostPath := rootfsPath
+	for _, part := range strings.Split(strings.Trim(filepath.Clean(path), "/"), "/") {
+		hostPath = filepath.Join(hostPath, part)
+
+		fi, err := os.Lstat(hostPath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+
+			return "", err
+		}
+
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("Preserved path %q must not contain symlinks", path)
+		}
+	}
+
+	return filepath.Join(rootfsPath, path), nil
 }
 
 // onStopNS is triggered by LXC's stop hook once a container is shutdown but before the container's
@@ -3338,6 +3536,7 @@ func (d *lxc) renderState(statusCode api.StatusCode, hostInterfaces []net.Interf
 
 	if d.isRunningStatusCode(statusCode) {
 		status.CPU = d.cpuState()
+		status.CPU.CoreSchedulingCookie = d.coreSchedulingCookie(pid)
 		status.Memory = d.memoryState()
 		status.Network = d.networkState(hostInterfaces)
 		status.Pid = int64(pid)
@@ -4384,11 +4583,13 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 	isRunning := d.IsRunning()
 
 	// Use the device interface to apply update changes.
-	devlxdEvents, err := d.devicesUpdate(d, removeDevices, addDevices, updateDevices, oldExpandedDevices, isRunning, userRequested)
+	devlxdEvents, cleanup, err := d.devicesUpdate(d, removeDevices, addDevices, updateDevices, oldExpandedDevices, isRunning, userRequested)
 	if err != nil {
 		return err
 	}
 
+	reverter.Add(cleanup)
+
 	// Update MAAS (must run after the MAC addresses have been generated).
 	updateMAAS := false
 	for _, key := range []string{"maas.subnet.ipv4", "maas.subnet.ipv6", "ipv4.address", "ipv6.address"} {
@@ -4801,9 +5002,9 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 
 	// Send devlxd notifications
 	if isRunning {
-		// Config changes (only for user.* keys
+		// Config changes (only for user.* and cloud-init.* keys)
 		for _, key := range changedConfig {
-			if !strings.HasPrefix(key, "user.") {
+			if !shared.StringHasPrefix(key, "user.", "cloud-init.") {
 				continue
 			}
 
@@ -5186,6 +5387,11 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 	d.logger.Info("Migration send starting")
 	defer d.logger.Info("Migration send stopped")
 
+	// Check for stateful support.
+	if args.Live && shared.IsFalseOrEmpty(d.expandedConfig["migration.stateful"]) {
+		return fmt.Errorf("Stateful migration requires migration.stateful to be set to true")
+	}
+
 	// Wait for essential migration connections before negotiation.
 	connectionsCtx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
@@ -5224,6 +5430,9 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 	indexHeaderVersion := migration.IndexHeaderVersion
 	offerHeader.IndexHeaderVersion = &indexHeaderVersion
 
+	// Indicate to target whether the migration will be retried if it fails.
+	offerHeader.Retry = proto.Bool(args.Retry)
+
 	// Add CRIU and predump info to source header.
 	maxDumpIterations := 0
 	if args.Live {
@@ -5309,6 +5518,7 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 		VolumeOnly:         !args.Snapshots,
 		Info:               &migration.Info{Config: srcConfig},
 		ClusterMove:        args.ClusterMoveSourceName != "",
+		BandwidthLimit:     args.BandwidthLimit,
 	}
 
 	// Only send the snapshots that the target requests when refreshing.
@@ -5381,11 +5591,29 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 	// Don't defer close this one as its needed potentially after this function has ended.
 	dumpSuccess := make(chan error, 1)
 
-	g.Go(func() error {
+	g.Go(func() (err error) {
 		d.logger.Debug("Migrate send transfer started")
 		defer d.logger.Debug("Migrate send transfer finished")
 
-		var err error
+		// Report local failures (such as a failed CRIU dump) to the target before the migration
+		// connections get closed, so that the target fails with the actual cause rather than with
+		// a disconnection error.
+		defer func() {
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+
+			msg := migration.MigrationControl{
+				Success: proto.Bool(false),
+				Message: proto.String(err.Error()),
+			}
+
+			d.logger.Debug("Sending migration failure to target", logger.Ctx{"err": err})
+			sendErr := args.ControlSend(&msg)
+			if sendErr != nil {
+				d.logger.Warn("Failed sending migration failure to target", logger.Ctx{"err": sendErr})
+			}
+		}()
 
 		d.logger.Debug("Starting storage migration phase")
 
@@ -5401,12 +5629,16 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 
 			// Setup rsync options (used for CRIU state transfers).
 			rsyncBwlimit := pool.Driver().Config()["rsync.bwlimit"]
+			if rsyncBwlimit == "" && args.BandwidthLimit > 0 {
+				rsyncBwlimit = rsync.BwlimitFromBytes(args.BandwidthLimit)
+			}
+
 			rsyncFeatures := respHeader.GetRsyncFeaturesSlice()
 
 			if respHeader.Criu == nil {
 				return fmt.Errorf("Got no CRIU socket type for live migration")
 			} else if *respHeader.Criu != migration.CRIUType_CRIU_RSYNC {
-				return fmt.Errorf("Formats other than criu rsync not understood (%q)", respHeader.Criu)
+				return fmt.Errorf("Formats other than criu rsync not understood (%q)", respHeader.GetCriu())
 			}
 
 			checkpointDir, err := os.MkdirTemp("", "lxd_checkpoint_")
@@ -5514,8 +5746,7 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 							return err
 						}
 
-						preDumpDir = fmt.Sprintf("%03d", preDumpCounter)
-						preDumpCounter++
+						preDumpDir = dumpDir
 					}
 				} else {
 					d.logger.Debug("The other side does not support pre-copy")
@@ -6134,7 +6365,10 @@ func (d *lxc) MigrateReceive(args instance.MigrateReceiveArgs) error {
 
 		// Only delete all instance volumes on error if the pool volume creation has succeeded to
 		// avoid deleting an existing conflicting volume.
-		if !volTargetArgs.Refresh && !isRemoteClusterMove {
+		// Volumes refreshed as part of a cluster move were created by a previous attempt of the move, so
+		// are also deleted, unless the source is going to retry the move and can then reuse them.
+		keepVolumes := args.ClusterMoveSourceName != "" && offerHeader.GetRetry()
+		if (!volTargetArgs.Refresh || args.ClusterMoveSourceName != "") && !isRemoteClusterMove && !keepVolumes {
 			revert.Add(func() {
 				snapshots, _ := d.Snapshots()
 				snapshotCount := len(snapshots)
@@ -7100,7 +7334,7 @@ func (d *lxc) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, st
 		fmt.Sprintf("%d", req.Group),
 	}
 
-	if d.state.OS.CoreScheduling && !d.state.OS.ContainerCoreScheduling {
+	if d.coreSchedulingEnabled() && !d.state.OS.ContainerCoreScheduling {
 		args = append(args, "1")
 	} else {
 		args = append(args, "0")
@@ -7381,6 +7615,30 @@ func (d *lxc) networkState(hostInterfaces []net.Interface) map[string]api.Instan
 		}
 	}
 
+	// Add the neighbour proxy announcements of the NIC devices (matched on hwaddr).
+	for k, m := range d.expandedDevices {
+		if m["type"] != "nic" {
+			continue
+		}
+
+		announcements := device.NICAnnouncements(d.project.Name, d.name, k)
+		if announcements == nil {
+			continue
+		}
+
+		hwaddr := m["hwaddr"]
+		if hwaddr == "" {
+			hwaddr = d.localConfig[fmt.Sprintf("volatile.%s.hwaddr", k)]
+		}
+
+		for name, dev := range result {
+			if dev.Hwaddr == hwaddr {
+				dev.Announcements = announcements
+				result[name] = dev
+			}
+		}
+	}
+
 	return result
 }
 
@@ -7783,6 +8041,62 @@ func (d *lxc) InsertSeccompUnixDevice(prefix string, m deviceConfig.Device, pid
 	return d.insertMountLXD(devPath, tgtPath, "none", unix.MS_BIND, pid, idmap.IdmapStorageNone)
 }
 
+// setupKVMDevices creates the device nodes needed to run KVM virtual machines inside the container and configures
+// LXC to mount them and allow them in the devices cgroup. The /dev/kvm device is required while the vhost devices
+// are only passed if present on the host.
+func (d *lxc) setupKVMDevices(cc *liblxc.Container, idmapSet *idmap.IdmapSet) error {
+	kvmDevices := []string{"/dev/kvm", "/dev/vhost-net", "/dev/vhost-vsock"}
+
+	for _, path := range kvmDevices {
+		if !shared.PathExists(path) {
+			if path == "/dev/kvm" {
+				return fmt.Errorf("security.kvm requires /dev/kvm to be available on the host")
+			}
+
+			continue
+		}
+
+		m := deviceConfig.Device{
+			"type":   "unix-char",
+			"source": path,
+			"path":   path,
+		}
+
+		// The devices are owned by the container root and accessible to all its users, like on most distributions.
+		// When nested, the host devices are bind-mounted as is.
+		if !d.state.OS.RunningInUserNS {
+			m["mode"] = "0666"
+		}
+
+		dev, err := device.UnixDeviceCreate(d.state, idmapSet, d.DevicesPath(), "unix.security-kvm", m, true)
+		if err != nil {
+			return fmt.Errorf("Failed to setup KVM device %q: %w", path, err)
+		}
+
+		err = lxcSetConfigItem(cc, "lxc.mount.entry", fmt.Sprintf("%s %s none bind,create=file 0 0", shared.EscapePathFstab(dev.HostPath), shared.EscapePathFstab(dev.RelativePath)))
+		if err != nil {
+			return fmt.Errorf("Failed to setup KVM device mount %q: %w", path, err)
+		}
+
+		if !d.isCurrentlyPrivileged() || d.state.OS.RunningInUserNS {
+			continue
+		}
+
+		rule := fmt.Sprintf("%s %d:%d rwm", dev.Type, dev.Major, dev.Minor)
+		if d.state.OS.CGInfo.Layout == cgroup.CgroupsUnified {
+			err = lxcSetConfigItem(cc, "lxc.cgroup2.devices.allow", rule)
+		} else {
+			err = lxcSetConfigItem(cc, "lxc.cgroup.devices.allow", rule)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed to setup KVM device cgroup %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
 func (d *lxc) removeUnixDevices() error {
 	// Check that we indeed have devices to remove
 	if !shared.PathExists(d.DevicesPath()) {
@@ -8443,6 +8757,82 @@ func (d *lxc) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error
 	return out, nil
 }
 
+// DiskIOStats returns the I/O counters of the disk devices of the running instance, keyed by device name.
+// The cgroup I/O statistics are accounted per block device, so only the storage volumes which are mounted from
+// their own block device are reported.
+func (d *lxc) DiskIOStats() (map[string]api.StorageVolumeStateIO, error) {
+	if !d.IsRunning() {
+		return nil, ErrInstanceIsStopped
+	}
+
+	cc, err := d.initLXC(false)
+	if err != nil {
+		return nil, err
+	}
+
+	cg, err := d.cgroup(cc, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ioStats, err := cg.GetIOStats()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]api.StorageVolumeStateIO)
+
+	for devName, dev := range d.expandedDevices {
+		if dev["type"] != "disk" || dev["pool"] == "" {
+			continue
+		}
+
+		var volName string
+		var volType storageDrivers.VolumeType
+		if dev["source"] != "" {
+			volName = project.StorageVolume(d.project.Name, dev["source"])
+			volType = storageDrivers.VolumeTypeCustom
+		} else {
+			volName = project.Instance(d.project.Name, d.name)
+			volType = storageDrivers.VolumeTypeContainer
+		}
+
+		// Volumes which aren't mounted (such as those of the dir driver) share the block device of the pool.
+		mountpoint := storageDrivers.GetVolumeMountPath(dev["pool"], volType, volName)
+		if !filesystem.IsMountPoint(mountpoint) {
+			continue
+		}
+
+		stat := unix.Stat_t{}
+		err = unix.Stat(mountpoint, &stat)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to stat %s: %w", mountpoint, err)
+		}
+
+		// Resolve the kernel name of the block device (such as dm-3) which the cgroup statistics are keyed by.
+		// Volumes of file systems without a block device (such as ZFS datasets) don't have one.
+		blockDev, err := os.Readlink(fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(uint64(stat.Dev)), unix.Minor(uint64(stat.Dev))))
+		if err != nil {
+			continue
+		}
+
+		stats, ok := ioStats[filepath.Base(blockDev)]
+		if !ok {
+			continue
+		}
+
+		out[devName] = api.StorageVolumeStateIO{
+			Device:          devName,
+			ReadBytes:       stats.ReadBytes,
+			ReadsCompleted:  stats.ReadsCompleted,
+			WrittenBytes:    stats.WrittenBytes,
+			WritesCompleted: stats.WritesCompleted,
+		}
+	}
+
+	return out, nil
+}
+
 func (d *lxc) getFSStats() (*metrics.MetricSet, error) {
 	type mountInfo struct {
 		Mountpoint string
@@ -61,6 +61,7 @@ import (
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/device/nictype"
 	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/drivers/chunkstore"
 	"github.com/canonical/lxd/lxd/instance/drivers/qmp"
 	"github.com/canonical/lxd/lxd/instance/drivers/uefi"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
@@ -91,6 +92,7 @@ import (
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/version"
+	"github.com/canonical/lxd/shared/ws"
 )
 
 // QEMUDefaultCPUCores defines the default number of cores a VM will get if no limit specified.
@@ -376,6 +378,9 @@ type qemu struct {
 
 	// Stateful migration streams.
 	migrationReceiveStateful map[string]io.ReadWriteCloser
+
+	// Whether the stateful migration stream can switch to post-copy mode.
+	migrationReceivePostcopy bool
 }
 
 // getAgentClient returns the current agent client handle.
@@ -420,7 +425,7 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 	state := d.state
 
 	return func(event string, data map[string]any) {
-		if !shared.ValueInSlice(event, []string{qmp.EventVMShutdown, qmp.EventAgentStarted}) {
+		if !shared.ValueInSlice(event, []string{qmp.EventVMShutdown, qmp.EventAgentStarted, qmp.EventAgentSilent}) {
 			return // Don't bother loading the instance from DB if we aren't going to handle the event.
 		}
 
@@ -452,11 +457,16 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 
 		if event == qmp.EventAgentStarted {
 			d.logger.Debug("Instance agent started")
+			d.resetAgentWatchdog(true)
+
 			err := d.advertiseVsockAddress()
 			if err != nil {
 				d.logger.Warn("Failed to advertise vsock address to instance agent", logger.Ctx{"err": err})
 				return
 			}
+		} else if event == qmp.EventAgentSilent {
+			seconds, _ := data["seconds"].(int)
+			d.onAgentSilent(seconds)
 		} else if event == qmp.EventVMShutdown {
 			target := "stop"
 			entry, ok := data["reason"]
@@ -655,7 +665,8 @@ func (d *qemu) onStop(target string) error {
 	}
 
 	// Cleanup.
-	d.cleanupDevices() // Must be called before unmount.
+	d.resetAgentWatchdog(false) // Keep the warning around if the agent watchdog restarted the instance.
+	d.cleanupDevices()          // Must be called before unmount.
 	_ = os.Remove(d.pidFilePath())
 	_ = os.Remove(d.monitorPath())
 
@@ -765,13 +776,19 @@ func (d *qemu) Shutdown(timeout time.Duration) error {
 
 	d.logger.Debug("Shutdown request sent to instance")
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	// If configured, only wait for part of the timeout for the guest to respond to the powerdown request
+	// before falling back to the lxd-agent and then force stopping the instance.
+	shutdown := qemuShutdownSequence{
+		config:        d.expandedConfig,
+		logger:        d.logger,
+		wait:          func(timeout time.Duration) error { return d.shutdownWait(op, timeout) },
+		stopped:       func() bool { return d.statusCode() == api.Stopped },
+		agentShutdown: d.agentShutdown,
+		forceStop:     func() error { return d.Stop(false) }, // Stop inherits the ongoing stop operation and marks it as Done.
+	}
+
+	err = shutdown.run(timeout)
 
-	// Wait for operation lock to be Done or context to timeout. The operation lock is normally completed by
-	// onStop which picks up the same lock and then marks it as Done after the instance stops and the devices
-	// have been cleaned up. However if the operation has failed for another reason we collect the error here.
-	err = op.Wait(ctx)
 	status := d.statusCode()
 	if status != api.Stopped {
 		errPrefix := fmt.Errorf("Failed shutting down instance, status is %q", status)
@@ -791,13 +808,140 @@ func (d *qemu) Shutdown(timeout time.Duration) error {
 	return nil
 }
 
+// shutdownWait waits for the stop operation lock to be Done or for the timeout to be reached.
+// The operation lock is normally completed by onStop which picks up the same lock and then marks it as Done after
+// the instance stops and the devices have been cleaned up. However if the operation has failed for another reason
+// the error is returned here.
+func (d *qemu) shutdownWait(op *operationlock.InstanceOperation, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return op.Wait(ctx)
+}
+
+// qemuShutdownSequence is the shutdown fallback chain of a virtual machine, run after the ACPI powerdown request
+// was sent: wait for the guest to shut down, then ask the lxd-agent to shut it down, and finally force-stop it.
+type qemuShutdownSequence struct {
+	config map[string]string
+	logger logger.Logger
+
+	// now returns the current time, defaults to time.Now.
+	now func() time.Time
+
+	// wait waits for the instance to stop, up to the given timeout.
+	wait func(timeout time.Duration) error
+
+	// stopped returns whether the instance is stopped.
+	stopped func() bool
+
+	// agentShutdown asks the lxd-agent to shut the guest down.
+	agentShutdown func() error
+
+	// forceStop force-stops the instance.
+	forceStop func() error
+}
+
+// run waits for the instance to stop within the given timeout. If boot.shutdown.acpi_timeout is set, only that
+// part of the timeout is spent waiting for the guest to respond to the powerdown request before falling back to
+// the lxd-agent and then force-stopping the instance.
+func (s *qemuShutdownSequence) run(timeout time.Duration) error {
+	now := s.now
+	if now == nil {
+		now = time.Now
+	}
+
+	start := now()
+	acpiTimeout, fallback := qemuShutdownStepTimeout(s.config, "boot.shutdown.acpi_timeout", timeout)
+
+	err := s.wait(acpiTimeout)
+	if !fallback || s.stopped() {
+		return err
+	}
+
+	remaining := timeout - now().Sub(start)
+	if remaining > 0 {
+		s.logger.Warn("Instance didn't respond to powerdown request, requesting shutdown through lxd-agent")
+
+		err := s.agentShutdown()
+		if err != nil {
+			s.logger.Warn("Failed requesting shutdown through lxd-agent", logger.Ctx{"err": err})
+		} else {
+			agentTimeout, _ := qemuShutdownStepTimeout(s.config, "boot.shutdown.agent_timeout", remaining)
+
+			err = s.wait(agentTimeout)
+			if s.stopped() {
+				return err
+			}
+		}
+	}
+
+	s.logger.Warn("Instance didn't shut down cleanly, forcing stop")
+
+	return s.forceStop()
+}
+
+// qemuShutdownStepTimeout returns how long a step of the shutdown fallback chain may take based on the given
+// config key, capped to the remaining shutdown timeout. Returns false if the key isn't set.
+func qemuShutdownStepTimeout(config map[string]string, key string, remaining time.Duration) (time.Duration, bool) {
+	value := config[key]
+	if value == "" {
+		return remaining, false
+	}
+
+	seconds, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return remaining, false
+	}
+
+	stepTimeout := time.Duration(seconds) * time.Second
+	if stepTimeout > remaining {
+		return remaining, true
+	}
+
+	return stepTimeout, true
+}
+
+// agentShutdown asks the lxd-agent to run "shutdown -h now" inside the guest.
+// The command isn't waited for as the agent connection goes away with the guest.
+func (d *qemu) agentShutdown() error {
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+
+	req := api.InstanceExecPost{
+		Command: []string{"shutdown", "-h", "now"},
+	}
+
+	cmd, err := d.Exec(req, devNull, devNull, devNull)
+	if err != nil {
+		_ = devNull.Close()
+		return err
+	}
+
+	go func() {
+		defer func() { _ = devNull.Close() }()
+
+		_, err := cmd.Wait()
+		if err != nil {
+			d.logger.Debug("Agent shutdown command ended", logger.Ctx{"err": err})
+		}
+	}()
+
+	return nil
+}
+
 // Restart restart the instance.
 func (d *qemu) Restart(timeout time.Duration) error {
 	return d.restartCommon(d, timeout)
 }
 
 // Rebuild rebuilds the instance using the supplied image fingerprint as source.
-func (d *qemu) Rebuild(img *api.Image, op *operations.Operation) error {
+func (d *qemu) Rebuild(img *api.Image, preservePaths []string, op *operations.Operation) error {
+	if len(preservePaths) > 0 {
+		return fmt.Errorf("Preserving paths isn't supported for virtual machines")
+	}
+
 	return d.rebuildCommon(d, img, op)
 }
 
@@ -864,13 +1008,19 @@ func (d *qemu) killQemuProcess(pid int) error {
 }
 
 // restoreState restores the VM state from a file handle.
-func (d *qemu) restoreStateHandle(ctx context.Context, monitor *qmp.Monitor, f *os.File) error {
+// If postcopy is true, the guest is resumed as soon as the migration stream switches to post-copy mode.
+func (d *qemu) restoreStateHandle(ctx context.Context, monitor *qmp.Monitor, f *os.File, postcopy bool) error {
 	err := monitor.SendFile("migration", f)
 	if err != nil {
 		return err
 	}
 
-	err = monitor.MigrateIncoming(ctx, "fd:migration")
+	if postcopy {
+		err = monitor.MigrateIncomingPostcopy(ctx, "fd:migration")
+	} else {
+		err = monitor.MigrateIncoming(ctx, "fd:migration")
+	}
+
 	if err != nil {
 		return err
 	}
@@ -878,6 +1028,32 @@ func (d *qemu) restoreStateHandle(ctx context.Context, monitor *qmp.Monitor, f *
 	return nil
 }
 
+// migrationSocket returns a socket to pass to QEMU for a migration stream carried over conn.
+// Unlike a pipe, the socket lets the target send page requests back to the source, as needed for post-copy.
+// The caller is responsible for closing the returned file once it has been passed to QEMU.
+func (d *qemu) migrationSocket(conn io.ReadWriteCloser) (*os.File, error) {
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating migration socket pair: %w", err)
+	}
+
+	qemuFile := os.NewFile(uintptr(fds[0]), "migration")
+	connFile := os.NewFile(uintptr(fds[1]), "migration-conn")
+
+	go func() {
+		go func() { _, _ = io.Copy(conn, connFile) }()
+
+		_, err := io.Copy(connFile, conn)
+		if err != nil {
+			d.logger.Warn("Failed reading from migration connection", logger.Ctx{"err": err})
+		}
+
+		_ = connFile.Close()
+	}()
+
+	return qemuFile, nil
+}
+
 // restoreState restores VM state from state file or from migration source if d.migrationReceiveStateful set.
 func (d *qemu) restoreState(monitor *qmp.Monitor) error {
 	if d.migrationReceiveStateful != nil {
@@ -922,27 +1098,47 @@ func (d *qemu) restoreState(monitor *qmp.Monitor) error {
 
 		// Receive checkpoint from QEMU process on source.
 		d.logger.Debug("Stateful migration checkpoint receive starting")
-		pipeRead, pipeWrite, err := os.Pipe()
-		if err != nil {
-			return err
-		}
+		var stateFile *os.File
+		if d.migrationReceivePostcopy {
+			// Post-copy needs the return path of a socket to request pages from the source.
+			err := monitor.MigrateSetCapabilities(map[string]bool{"postcopy-ram": true})
+			if err != nil {
+				return fmt.Errorf("Failed setting migration capabilities: %w", err)
+			}
 
-		go func() {
-			_, err := io.Copy(pipeWrite, stateConn)
+			stateFile, err = d.migrationSocket(stateConn)
 			if err != nil {
-				d.logger.Warn("Failed reading from state connection", logger.Ctx{"err": err})
+				return err
 			}
 
-			_ = pipeRead.Close()
-			_ = pipeWrite.Close()
-		}()
+			defer func() { _ = stateFile.Close() }()
+		} else {
+			pipeRead, pipeWrite, err := os.Pipe()
+			if err != nil {
+				return err
+			}
+
+			go func() {
+				_, err := io.Copy(pipeWrite, stateConn)
+				if err != nil {
+					d.logger.Warn("Failed reading from state connection", logger.Ctx{"err": err})
+				}
+
+				_ = pipeRead.Close()
+				_ = pipeWrite.Close()
+			}()
+
+			stateFile = pipeRead
+		}
 
-		err = d.restoreStateHandle(context.Background(), monitor, pipeRead)
+		err := d.restoreStateHandle(context.Background(), monitor, stateFile, d.migrationReceivePostcopy)
 		if err != nil {
 			return fmt.Errorf("Failed restoring checkpoint from source: %w", err)
 		}
 
 		d.logger.Debug("Stateful migration checkpoint receive finished")
+	} else if shared.PathExists(d.stateManifestPath()) {
+		return d.restoreStateIncremental(monitor)
 	} else {
 		statePath := d.StatePath()
 		d.logger.Debug("Stateful checkpoint restore starting", logger.Ctx{"source": statePath})
@@ -977,7 +1173,7 @@ func (d *qemu) restoreState(monitor *qmp.Monitor) error {
 			_ = pipeWrite.Close()
 		}()
 
-		err = d.restoreStateHandle(context.Background(), monitor, pipeRead)
+		err = d.restoreStateHandle(context.Background(), monitor, pipeRead, false)
 		if err != nil {
 			return fmt.Errorf("Failed restoring state from %q: %w", stateFile.Name(), err)
 		}
@@ -1055,6 +1251,161 @@ func (d *qemu) saveState(monitor *qmp.Monitor) error {
 	return nil
 }
 
+// stateStorePath returns the path of the store holding the memory state of incremental stateful snapshots.
+func (d *qemu) stateStorePath() string {
+	return filepath.Join(d.Path(), "state.store")
+}
+
+// stateManifestPath returns the path of the manifest of an incrementally stored memory state.
+func (d *qemu) stateManifestPath() string {
+	return d.StatePath() + ".manifest"
+}
+
+// saveStateIncremental dumps the current VM state into the state store, only writing the parts of the state that
+// aren't in the store yet, and writes the manifest of the state next to the state file.
+// Once dumped, the VM is in a paused state and it's up to the caller to resume or kill it.
+func (d *qemu) saveStateIncremental(monitor *qmp.Monitor) (*chunkstore.Manifest, error) {
+	manifestPath := d.stateManifestPath()
+	d.logger.Debug("Incremental stateful checkpoint starting", logger.Ctx{"target": manifestPath})
+	defer d.logger.Debug("Incremental stateful checkpoint finished", logger.Ctx{"target": manifestPath})
+
+	store := chunkstore.New(d.stateStorePath())
+
+	// Drop the chunks that were only used by deleted snapshots.
+	err := d.pruneStateStore(store)
+	if err != nil {
+		return nil, fmt.Errorf("Failed pruning state store: %w", err)
+	}
+
+	pipeRead, pipeWrite, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = pipeRead.Close()
+		_ = pipeWrite.Close()
+	}()
+
+	type storeResult struct {
+		manifest *chunkstore.Manifest
+		stored   int64
+		err      error
+	}
+
+	resultCh := make(chan storeResult, 1)
+	go func() {
+		manifest, stored, err := store.Write(pipeRead)
+		resultCh <- storeResult{manifest: manifest, stored: stored, err: err}
+	}()
+
+	err = d.saveStateHandle(monitor, pipeWrite)
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing state save: %w", err)
+	}
+
+	err = monitor.MigrateWait("completed")
+	if err != nil {
+		return nil, fmt.Errorf("Failed saving state: %w", err)
+	}
+
+	// Signal the end of the state stream to the store.
+	_ = pipeWrite.Close()
+
+	result := <-resultCh
+	if result.err != nil {
+		return nil, fmt.Errorf("Failed storing state: %w", result.err)
+	}
+
+	d.logger.Debug("Stored incremental stateful checkpoint", logger.Ctx{"size": result.manifest.Size, "stored": result.stored})
+
+	err = chunkstore.WriteManifest(manifestPath, result.manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed writing state manifest: %w", err)
+	}
+
+	return result.manifest, nil
+}
+
+// restoreStateIncremental restores the VM state from the state store using the manifest next to the state file.
+func (d *qemu) restoreStateIncremental(monitor *qmp.Monitor) error {
+	manifestPath := d.stateManifestPath()
+	d.logger.Debug("Incremental stateful checkpoint restore starting", logger.Ctx{"source": manifestPath})
+	defer d.logger.Debug("Incremental stateful checkpoint restore finished", logger.Ctx{"source": manifestPath})
+
+	manifest, err := chunkstore.ReadManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("Failed reading state manifest: %w", err)
+	}
+
+	stateReader := chunkstore.New(d.stateStorePath()).Reader(manifest)
+	defer func() { _ = stateReader.Close() }()
+
+	pipeRead, pipeWrite, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		_, err := io.Copy(pipeWrite, stateReader)
+		if err != nil {
+			d.logger.Warn("Failed reading from state store", logger.Ctx{"path": d.stateStorePath(), "err": err})
+		}
+
+		_ = pipeRead.Close()
+		_ = pipeWrite.Close()
+	}()
+
+	err = d.restoreStateHandle(context.Background(), monitor, pipeRead, false)
+	if err != nil {
+		return fmt.Errorf("Failed restoring state from %q: %w", d.stateStorePath(), err)
+	}
+
+	return nil
+}
+
+// pruneStateStore removes the references of deleted snapshots from the state store along with the chunks that
+// are no longer used by any snapshot.
+func (d *qemu) pruneStateStore(store *chunkstore.Store) error {
+	snapshots, err := d.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	snapshotNames := make(map[string]bool, len(snapshots))
+	for _, snapshot := range snapshots {
+		_, snapshotName, _ := api.GetParentAndSnapshotName(snapshot.Name())
+		snapshotNames[snapshotName] = true
+	}
+
+	refs, err := store.References()
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if snapshotNames[ref] {
+			continue
+		}
+
+		err = store.RemoveReference(ref)
+		if err != nil {
+			return err
+		}
+	}
+
+	removed, err := store.Prune()
+	if err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		d.logger.Debug("Pruned state store", logger.Ctx{"chunks": removed})
+	}
+
+	return nil
+}
+
 // validateRootDiskStatefulStop validates the state of the root disk before stopping the instance.
 func (d *qemu) validateRootDiskStatefulStop() error {
 	// checks if the root disk device exists and retrieves the storage pool.
@@ -1295,6 +1646,11 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		volatileSet["volatile.apply_nvram"] = ""
 	}
 
+	// The VM starts with its full memory, so clear any previous automatic balloon target.
+	if d.localConfig["volatile.memory.balloon_target"] != "" {
+		volatileSet["volatile.memory.balloon_target"] = ""
+	}
+
 	// Apply any volatile changes that need to be made.
 	err = d.VolatileSet(volatileSet)
 	if err != nil {
@@ -1534,6 +1890,12 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 			return err
 		}
 
+		err = os.Remove(d.stateManifestPath())
+		if err != nil && !os.IsNotExist(err) {
+			op.Done(err)
+			return err
+		}
+
 		d.stateful = false
 		err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 			return tx.UpdateInstanceStatefulFlag(ctx, d.id, false)
@@ -1618,6 +1980,12 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		qemuCmd = append(qemuCmd, fields...)
 	}
 
+	// Only keep the end of the console output of the previous runs.
+	err = d.trimConsoleHistory()
+	if err != nil {
+		d.logger.Warn("Failed trimming console history", logger.Ctx{"err": err})
+	}
+
 	// Run the qemu command via forklimits so we can selectively increase ulimits.
 	forkLimitsCmd := []string{
 		"forklimits",
@@ -1785,6 +2153,7 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	if stateful {
 		// Cleanup state.
 		_ = os.Remove(d.StatePath())
+		_ = os.Remove(d.stateManifestPath())
 		d.stateful = false
 
 		err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -2296,7 +2665,13 @@ func (d *qemu) deviceAttachBlockDevice(mount deviceConfig.MountEntryItem) error
 		return fmt.Errorf("Failed to connect to QMP monitor: %w", err)
 	}
 
-	monHook, err := d.addDriveConfig(nil, nil, mount)
+	var monHook monitorHook
+	if shared.ValueInSlice(device.DiskVhostUserBlk, mount.Opts) {
+		monHook, err = d.addDriveVhostUserBlkConfig(nil, nil, mount)
+	} else {
+		monHook, err = d.addDriveConfig(nil, nil, mount)
+	}
+
 	if err != nil {
 		return fmt.Errorf("Failed to add drive config: %w", err)
 	}
@@ -2344,6 +2719,42 @@ func (d *qemu) deviceDetachPath(deviceName string) error {
 	return nil
 }
 
+func (d *qemu) deviceDetachVhostUserBlk(deviceName string) error {
+	// Check if the agent is running.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, filesystem.PathNameEncode(deviceName))
+	chardevID := d.generateQemuDeviceName(deviceName)
+
+	err = monitor.RemoveDevice(deviceID)
+	if err != nil {
+		return err
+	}
+
+	waitDuration := time.Duration(time.Second * time.Duration(10))
+	waitUntil := time.Now().Add(waitDuration)
+	for {
+		err = monitor.RemoveCharDevice(chardevID)
+		if err == nil {
+			break
+		}
+
+		if api.StatusErrorCheck(err, http.StatusLocked) {
+			time.Sleep(time.Second * time.Duration(2))
+			continue
+		}
+
+		if time.Now().After(waitUntil) {
+			return fmt.Errorf("Failed to detach vhost-user-blk device after %v: %w", waitDuration, err)
+		}
+	}
+
+	return nil
+}
+
 func (d *qemu) deviceDetachBlockDevice(deviceName string) error {
 	// Check if the agent is running.
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
@@ -2382,6 +2793,12 @@ func (d *qemu) deviceDetachBlockDevice(deviceName string) error {
 		}
 	}
 
+	// Remove the dedicated I/O threads of the drive now that its device is gone.
+	err = d.removeDriveIOThreads(monitor, blockDevName)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -2487,6 +2904,11 @@ func (d *qemu) deviceStop(dev device.Device, instanceRunning bool, _ string) err
 				if err != nil {
 					return err
 				}
+			} else if strings.HasPrefix(configCopy["source"], "vhost-user-blk:") {
+				err = d.deviceDetachVhostUserBlk(dev.Name())
+				if err != nil {
+					return err
+				}
 			} else {
 				err = d.deviceDetachBlockDevice(dev.Name())
 				if err != nil {
@@ -2651,6 +3073,11 @@ func (d *qemu) consolePath() string {
 	return filepath.Join(d.LogPath(), "qemu.console")
 }
 
+// consoleHistoryPath returns the path of the file keeping the text console output.
+func (d *qemu) consoleHistoryPath() string {
+	return filepath.Join(d.LogPath(), "console.log")
+}
+
 func (d *qemu) spicePath() string {
 	return filepath.Join(d.LogPath(), "qemu.spice")
 }
@@ -3206,7 +3633,7 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 	cfg = append(cfg, qemuControlSocket(&qemuControlSocketOpts{d.monitorPath()})...)
 
 	// Console output.
-	cfg = append(cfg, qemuConsole(&qemuConsoleOpts{d.consolePath()})...)
+	cfg = append(cfg, qemuConsole(&qemuConsoleOpts{path: d.consolePath(), logPath: d.consoleHistoryPath()})...)
 
 	// Setup the bus allocator.
 	bus := qemuNewBus(busName, &cfg)
@@ -3441,10 +3868,12 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 					break
 				}
 
+				vhostUserBlk := shared.ValueInSlice(device.DiskVhostUserBlk, drive.Opts)
+
 				qemuDev := make(map[string]string)
-				if busName == "nvme" {
+				if shared.ValueInSlice(busName, []string{"nvme", "virtio-blk"}) || vhostUserBlk {
 					// Allocate a PCI(e) port and write it to the config file so QMP can "hotplug" the
-					// NVME drive into it later.
+					// NVME, virtio-blk or vhost-user-blk drive into it later.
 					devBus, devAddr, multi := bus.allocate(busFunctionGroupNone)
 
 					// Populate the qemu device with port info.
@@ -3460,6 +3889,8 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 					monHook, err = d.addRootDriveConfig(qemuDev, mountInfo, bootIndexes, drive)
 				} else if drive.FSType == "9p" {
 					err = d.addDriveDirConfig(&cfg, bus, fdFiles, &agentMounts, drive)
+				} else if vhostUserBlk {
+					monHook, err = d.addDriveVhostUserBlkConfig(qemuDev, bootIndexes, drive)
 				} else {
 					monHook, err = d.addDriveConfig(qemuDev, bootIndexes, drive)
 				}
@@ -3532,6 +3963,18 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 				return "", nil, err
 			}
 		}
+
+		// Add shared memory device.
+		if len(runConf.ShmemDevice) > 0 {
+			monHook, err := d.addShmemDevConfig(&cfg, bus, runConf.ShmemDevice)
+			if err != nil {
+				return "", nil, err
+			}
+
+			if monHook != nil {
+				monHooks = append(monHooks, monHook)
+			}
+		}
 	}
 
 	// VM generation ID is only available on x86.
@@ -3721,7 +4164,7 @@ func (d *qemu) addRootDriveConfig(qemuDev map[string]string, mountInfo *storageP
 				clusterName = storageDrivers.CephDefaultUser
 			}
 
-			driveConf.DevPath = device.DiskGetRBDFormat(clusterName, userName, config["ceph.osd.pool_name"], vol.Name())
+			driveConf.DevPath = device.DiskGetRBDFormat(clusterName, userName, config["ceph.osd.pool_name"], config["ceph.osd.pool_namespace"], vol.Name())
 		}
 	}
 
@@ -3752,8 +4195,10 @@ func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os
 		agentMount.Options = append(agentMount.Options, "ro")
 	}
 
-	// Record the 9p mount for the agent.
-	*agentMounts = append(*agentMounts, agentMount)
+	// Record the 9p mount for the agent unless in-guest mounts are disabled.
+	if shared.IsTrueOrEmpty(d.expandedConfig["agent.mounts"]) {
+		*agentMounts = append(*agentMounts, agentMount)
+	}
 
 	// Check if the disk device has provided a virtiofsd socket path.
 	var virtiofsdSockPath string
@@ -3942,6 +4387,24 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 		break
 	}
 
+	// Check if the user has requested dedicated I/O threads or a specific queue size.
+	var ioThreads int
+	var ioThreadsCPUs string
+	var queueSize string
+	for _, opt := range driveConf.Opts {
+		if strings.HasPrefix(opt, "iothreads=") {
+			var err error
+			ioThreads, err = strconv.Atoi(strings.TrimPrefix(opt, "iothreads="))
+			if err != nil {
+				return nil, fmt.Errorf("Invalid I/O threads count %q: %w", opt, err)
+			}
+		} else if strings.HasPrefix(opt, "iothreads.cpus=") {
+			ioThreadsCPUs = strings.TrimPrefix(opt, "iothreads.cpus=")
+		} else if strings.HasPrefix(opt, "queue_size=") {
+			queueSize = strings.TrimPrefix(opt, "queue_size=")
+		}
+	}
+
 	// QMP uses two separate values for the cache.
 	directCache := true   // Bypass host cache, use O_DIRECT semantics by default.
 	noFlushCache := false // Don't ignore any flush requests for the device.
@@ -4003,6 +4466,7 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 		userName := storageDrivers.CephDefaultUser
 		clusterName := storageDrivers.CephDefaultCluster
 		poolName := ""
+		namespace := ""
 
 		for _, option := range opts {
 			fields := strings.Split(option, "=")
@@ -4014,6 +4478,8 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 				userName = fields[1]
 			} else if fields[0] == "pool" {
 				poolName = fields[1]
+			} else if fields[0] == "namespace" {
+				namespace = fields[1]
 			} else if fields[0] == "conf" {
 				baseName := filepath.Base(fields[1])
 				clusterName = strings.TrimSuffix(baseName, ".conf")
@@ -4032,6 +4498,10 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 		blockDev["server"] = []map[string]string{}
 		blockDev["conf"] = fmt.Sprintf("/etc/ceph/%s.conf", clusterName)
 
+		if namespace != "" {
+			blockDev["namespace"] = namespace
+		}
+
 		// Setup the Ceph cluster config (monitors and keyring).
 		monitors, err := storageDrivers.CephMonitors(clusterName)
 		if err != nil {
@@ -4090,7 +4560,11 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 		} else if media == "cdrom" {
 			qemuDev["driver"] = "scsi-cd"
 		}
-	} else if bus == "nvme" {
+	} else if bus == "nvme" || bus == "virtio-blk" {
+		if bus == "virtio-blk" && media == "cdrom" {
+			return nil, fmt.Errorf("The virtio-blk bus cannot be used for ISO images")
+		}
+
 		if qemuDev["bus"] == "" {
 			// Figure out a hotplug slot.
 			pciDevID := qemuPCIDeviceIDStart
@@ -4107,12 +4581,33 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 			}
 
 			pciDeviceName := fmt.Sprintf("%s%d", busDevicePortPrefix, pciDevID)
-			d.logger.Debug("Using PCI bus device to hotplug NVME into", logger.Ctx{"device": driveConf.DevName, "port": pciDeviceName})
+			d.logger.Debug("Using PCI bus device to hotplug drive into", logger.Ctx{"device": driveConf.DevName, "bus": bus, "port": pciDeviceName})
 			qemuDev["bus"] = pciDeviceName
 			qemuDev["addr"] = "00.0"
 		}
 
-		qemuDev["driver"] = "nvme"
+		if bus == "virtio-blk" {
+			qemuDev["driver"] = "virtio-blk-pci"
+
+			if queueSize != "" {
+				qemuDev["queue-size"] = queueSize
+			}
+		} else {
+			qemuDev["driver"] = "nvme"
+		}
+	}
+
+	if ioThreads > 0 && bus != "virtio-blk" {
+		return nil, fmt.Errorf("Dedicated I/O threads are only supported with the virtio-blk bus")
+	}
+
+	var ioThreadsPins []int64
+	if ioThreadsCPUs != "" {
+		var err error
+		ioThreadsPins, err = resources.ParseCpuset(ioThreadsCPUs)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing I/O threads CPUs %q: %w", ioThreadsCPUs, err)
+		}
 	}
 
 	if bootIndexes != nil {
@@ -4160,13 +4655,35 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 			blockDev["filename"] = fmt.Sprintf("/dev/fdset/%d", info.ID)
 		}
 
-		err := m.AddBlockDevice(blockDev, qemuDev)
-		if err != nil {
-			return fmt.Errorf("Failed adding block device for disk device %q: %w", driveConf.DevName, err)
+		if ioThreads > 0 {
+			ioThreadIDs, err := d.addDriveIOThreads(m, qemuDevDrive, ioThreads, ioThreadsPins)
+			if err != nil {
+				return fmt.Errorf("Failed adding I/O threads for disk device %q: %w", driveConf.DevName, err)
+			}
+
+			revert.Add(func() { _ = d.removeDriveIOThreads(m, qemuDevDrive) })
+
+			// Add the block device without its device so that the device can be linked to the I/O threads.
+			err = m.AddBlockDevice(blockDev, nil)
+			if err != nil {
+				return fmt.Errorf("Failed adding block device for disk device %q: %w", driveConf.DevName, err)
+			}
+
+			revert.Add(func() { _ = m.RemoveBlockDevice(qemuDevDrive) })
+
+			err = m.AddDeviceWithIOThreads(qemuDev, ioThreadIDs)
+			if err != nil {
+				return fmt.Errorf("Failed adding device for disk device %q: %w", driveConf.DevName, err)
+			}
+		} else {
+			err := m.AddBlockDevice(blockDev, qemuDev)
+			if err != nil {
+				return fmt.Errorf("Failed adding block device for disk device %q: %w", driveConf.DevName, err)
+			}
 		}
 
 		if driveConf.Limits != nil {
-			err = m.SetBlockThrottle(qemuDev["id"], int(driveConf.Limits.ReadBytes), int(driveConf.Limits.WriteBytes), int(driveConf.Limits.ReadIOps), int(driveConf.Limits.WriteIOps))
+			err := m.SetBlockThrottle(qemuDev["id"], int(driveConf.Limits.ReadBytes), int(driveConf.Limits.WriteBytes), int(driveConf.Limits.ReadIOps), int(driveConf.Limits.WriteIOps))
 			if err != nil {
 				return fmt.Errorf("Failed applying limits for disk device %q: %w", driveConf.DevName, err)
 			}
@@ -4179,6 +4696,195 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 	return monHook, nil
 }
 
+// addDriveIOThreads adds the dedicated I/O threads of a drive and pins them to the given host CPUs (if any).
+// Returns the IDs of the I/O threads.
+func (d *qemu) addDriveIOThreads(m *qmp.Monitor, nodeName string, count int, pins []int64) ([]string, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	ioThreadIDs := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		ioThreadID := fmt.Sprintf("%s_iothread%d", nodeName, i)
+
+		err := m.AddIOThread(ioThreadID)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { _ = m.RemoveIOThread(ioThreadID) })
+		ioThreadIDs = append(ioThreadIDs, ioThreadID)
+	}
+
+	if len(pins) > 0 {
+		threadIDs, err := m.GetIOThreads()
+		if err != nil {
+			return nil, err
+		}
+
+		affinitySet := unix.CPUSet{}
+		for _, pin := range pins {
+			affinitySet.Set(int(pin))
+		}
+
+		for _, ioThreadID := range ioThreadIDs {
+			threadID, ok := threadIDs[ioThreadID]
+			if !ok {
+				return nil, fmt.Errorf("Failed finding host thread of I/O thread %q", ioThreadID)
+			}
+
+			err := unix.SchedSetaffinity(threadID, &affinitySet)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to set I/O thread affinity: %w", err)
+			}
+		}
+	}
+
+	revert.Success()
+	return ioThreadIDs, nil
+}
+
+// removeDriveIOThreads removes the dedicated I/O threads of a drive (if any).
+func (d *qemu) removeDriveIOThreads(m *qmp.Monitor, nodeName string) error {
+	threadIDs, err := m.GetIOThreads()
+	if err != nil {
+		return err
+	}
+
+	for ioThreadID := range threadIDs {
+		if !strings.HasPrefix(ioThreadID, nodeName+"_iothread") {
+			continue
+		}
+
+		err := m.RemoveIOThread(ioThreadID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addDriveVhostUserBlkConfig adds the qemu config required for connecting a drive to a vhost-user-blk target.
+// The qemuDev map is expected to be preconfigured with the settings for an existing port to use for the device.
+func (d *qemu) addDriveVhostUserBlkConfig(qemuDev map[string]string, bootIndexes map[string]int, driveConf deviceConfig.MountEntryItem) (monitorHook, error) {
+	// The vhost-user-blk target needs access to the guest memory, which is only set up as shared memory on
+	// x86_64 (other architectures use a private hugepages mapping).
+	if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+		return nil, fmt.Errorf("vhost-user-blk disks are only supported on x86_64")
+	}
+
+	if !shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		return nil, fmt.Errorf(`vhost-user-blk disks require "limits.memory.hugepages" to be enabled`)
+	}
+
+	escapedDeviceName := filesystem.PathNameEncode(driveConf.DevName)
+	chardevID := d.generateQemuDeviceName(driveConf.DevName)
+
+	if qemuDev == nil {
+		qemuDev = map[string]string{}
+	}
+
+	if qemuDev["bus"] == "" {
+		// Figure out a hotplug slot.
+		pciDevID := qemuPCIDeviceIDStart
+
+		// Iterate through all the instance devices in the same sorted order as is used when allocating the
+		// boot time devices in order to find the PCI bus slot device we would have used at boot time.
+		// Then attempt to use that same device, assuming it is available.
+		for _, dev := range d.expandedDevices.Sorted() {
+			if dev.Name == driveConf.DevName {
+				break // Found our device.
+			}
+
+			pciDevID++
+		}
+
+		pciDeviceName := fmt.Sprintf("%s%d", busDevicePortPrefix, pciDevID)
+		d.logger.Debug("Using PCI bus device to hotplug vhost-user-blk into", logger.Ctx{"device": driveConf.DevName, "port": pciDeviceName})
+		qemuDev["bus"] = pciDeviceName
+		qemuDev["addr"] = "00.0"
+	}
+
+	qemuDev["driver"] = "vhost-user-blk-pci"
+	qemuDev["id"] = fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)
+	qemuDev["chardev"] = chardevID
+
+	if bootIndexes != nil {
+		qemuDev["bootindex"] = strconv.Itoa(bootIndexes[driveConf.DevName])
+	}
+
+	monHook := func(m *qmp.Monitor) error {
+		revert := revert.New()
+		defer revert.Fail()
+
+		// Connect to the target on behalf of QEMU as its confinement doesn't allow it to access arbitrary
+		// sockets. Open the socket through O_PATH first to support long socket paths.
+		socketFile, err := os.OpenFile(driveConf.DevPath, unix.O_PATH|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("Failed opening vhost-user-blk socket %q: %w", driveConf.DevPath, err)
+		}
+
+		defer func() { _ = socketFile.Close() }()
+
+		addr, err := net.ResolveUnixAddr("unix", fmt.Sprintf("/dev/fd/%d", socketFile.Fd()))
+		if err != nil {
+			return err
+		}
+
+		conn, err := net.DialUnix("unix", nil, addr)
+		if err != nil {
+			return fmt.Errorf("Failed connecting to vhost-user-blk socket %q: %w", driveConf.DevPath, err)
+		}
+
+		defer func() { _ = conn.Close() }() // Close file after device has been added.
+
+		connFile, err := conn.File()
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = connFile.Close() }()
+
+		err = m.SendFile(chardevID, connFile)
+		if err != nil {
+			return fmt.Errorf("Failed sending vhost-user-blk socket for disk device %q: %w", driveConf.DevName, err)
+		}
+
+		revert.Add(func() { _ = m.CloseFile(chardevID) })
+
+		err = m.AddCharDevice(map[string]any{
+			"id": chardevID,
+			"backend": map[string]any{
+				"type": "socket",
+				"data": map[string]any{
+					"addr": map[string]any{
+						"type": "fd",
+						"data": map[string]any{
+							"str": chardevID,
+						},
+					},
+					"server": false,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("Failed adding character device for disk device %q: %w", driveConf.DevName, err)
+		}
+
+		revert.Add(func() { _ = m.RemoveCharDevice(chardevID) })
+
+		err = m.AddDevice(qemuDev)
+		if err != nil {
+			return fmt.Errorf("Failed adding vhost-user-blk device for disk device %q: %w", driveConf.DevName, err)
+		}
+
+		revert.Success()
+		return nil
+	}
+
+	return monHook, nil
+}
+
 // addNetDevConfig adds the qemu config required for adding a network device.
 // The qemuDev map is expected to be preconfigured with the settings for an existing port to use for the device.
 func (d *qemu) addNetDevConfig(busName string, qemuDev map[string]string, bootIndexes map[string]int, nicConfig []deviceConfig.RunConfigItem) (monitorHook, error) {
@@ -4674,73 +5380,223 @@ func (d *qemu) addGPUDevConfig(cfg *[]cfgSection, bus *qemuBus, gpuConfig []devi
 		}
 	}
 
-	return nil
-}
+	return nil
+}
+
+func (d *qemu) addUSBDeviceConfig(usbDev deviceConfig.USBDeviceItem) (monitorHook, error) {
+	device := map[string]string{
+		"id":     fmt.Sprintf("%s%s", qemuDeviceIDPrefix, usbDev.DeviceName),
+		"driver": "usb-host",
+		"bus":    "qemu_usb.0",
+	}
+
+	monHook := func(m *qmp.Monitor) error {
+		revert := revert.New()
+		defer revert.Fail()
+
+		f, err := os.OpenFile(usbDev.HostDevicePath, unix.O_RDWR, 0)
+		if err != nil {
+			return fmt.Errorf("Failed to open host device: %w", err)
+		}
+
+		defer func() { _ = f.Close() }()
+
+		info, err := m.SendFileWithFDSet(device["id"], f, false)
+		if err != nil {
+			return fmt.Errorf("Failed to send file descriptor: %w", err)
+		}
+
+		revert.Add(func() {
+			_ = m.RemoveFDFromFDSet(device["id"])
+		})
+
+		device["hostdevice"] = fmt.Sprintf("/dev/fdset/%d", info.ID)
+
+		err = m.AddDevice(device)
+		if err != nil {
+			return fmt.Errorf("Failed to add device: %w", err)
+		}
+
+		revert.Success()
+		return nil
+	}
+
+	return monHook, nil
+}
+
+func (d *qemu) addTPMDeviceConfig(cfg *[]cfgSection, tpmConfig []deviceConfig.RunConfigItem, fdFiles *[]*os.File) error {
+	var devName, socketPath string
+
+	for _, tpmItem := range tpmConfig {
+		if tpmItem.Key == "path" {
+			socketPath = tpmItem.Value
+		} else if tpmItem.Key == "devName" {
+			devName = tpmItem.Value
+		}
+	}
+
+	shortPath, err := d.shortenedFilePath(socketPath, fdFiles)
+	if err != nil {
+		return err
+	}
+
+	tpmOpts := qemuTPMOpts{
+		devName: devName,
+		path:    shortPath,
+	}
+	*cfg = append(*cfg, qemuTPM(&tpmOpts)...)
+
+	return nil
+}
+
+// addShmemDevConfig adds the qemu config required for adding a shared memory device.
+// The ivshmem backend maps the shared memory region into a PCI device, while the vhost-user backend connects a
+// virtio-net device to the vhost-user socket once QEMU has started.
+func (d *qemu) addShmemDevConfig(cfg *[]cfgSection, bus *qemuBus, shmemConfig []deviceConfig.RunConfigItem) (monitorHook, error) {
+	var devName, backend, path string
+	var size int64
+	for _, shmemItem := range shmemConfig {
+		switch shmemItem.Key {
+		case "devName":
+			devName = shmemItem.Value
+		case "backend":
+			backend = shmemItem.Value
+		case "path":
+			path = shmemItem.Value
+		case "size":
+			var err error
+			size, err = strconv.ParseInt(shmemItem.Value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid shared memory size %q: %w", shmemItem.Value, err)
+			}
+		}
+	}
+
+	if !shared.ValueInSlice(bus.name, []string{"pcie", "pci"}) {
+		return nil, fmt.Errorf("Shared memory devices require a PCI bus")
+	}
+
+	devBus, devAddr, multi := bus.allocate(busFunctionGroupNone)
+
+	if backend != "vhost-user" {
+		shmemOpts := qemuShmemOpts{
+			dev: qemuDevOpts{
+				busName:       bus.name,
+				devBus:        devBus,
+				devAddr:       devAddr,
+				multifunction: multi,
+			},
+			devName: devName,
+			path:    path,
+			size:    size,
+		}
+		*cfg = append(*cfg, qemuShmem(&shmemOpts)...)
+
+		return nil, nil
+	}
+
+	// The vhost-user backend needs access to the guest memory, which is only set up as shared memory on
+	// x86_64 (other architectures use a private hugepages mapping).
+	if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+		return nil, fmt.Errorf("vhost-user shared memory devices are only supported on x86_64")
+	}
+
+	if !shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		return nil, fmt.Errorf(`vhost-user shared memory devices require "limits.memory.hugepages" to be enabled`)
+	}
 
-func (d *qemu) addUSBDeviceConfig(usbDev deviceConfig.USBDeviceItem) (monitorHook, error) {
-	device := map[string]string{
-		"id":     fmt.Sprintf("%s%s", qemuDeviceIDPrefix, usbDev.DeviceName),
-		"driver": "usb-host",
-		"bus":    "qemu_usb.0",
+	escapedDeviceName := filesystem.PathNameEncode(devName)
+	chardevID := d.generateQemuDeviceName(devName)
+	netDevID := fmt.Sprintf("lxd_%s", escapedDeviceName)
+
+	qemuDev := map[string]string{
+		"driver": "virtio-net-pci",
+		"id":     fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName),
+		"bus":    devBus,
+		"addr":   devAddr,
+		"netdev": netDevID,
+	}
+
+	if multi {
+		qemuDev["multifunction"] = "on"
 	}
 
 	monHook := func(m *qmp.Monitor) error {
 		revert := revert.New()
 		defer revert.Fail()
 
-		f, err := os.OpenFile(usbDev.HostDevicePath, unix.O_RDWR, 0)
+		// Connect to the backend on behalf of QEMU as its confinement doesn't allow it to access arbitrary
+		// sockets. Open the socket through O_PATH first to support long socket paths.
+		socketFile, err := os.OpenFile(path, unix.O_PATH|unix.O_CLOEXEC, 0)
 		if err != nil {
-			return fmt.Errorf("Failed to open host device: %w", err)
+			return fmt.Errorf("Failed opening vhost-user socket %q: %w", path, err)
 		}
 
-		defer func() { _ = f.Close() }()
+		defer func() { _ = socketFile.Close() }()
 
-		info, err := m.SendFileWithFDSet(device["id"], f, false)
+		addr, err := net.ResolveUnixAddr("unix", fmt.Sprintf("/dev/fd/%d", socketFile.Fd()))
 		if err != nil {
-			return fmt.Errorf("Failed to send file descriptor: %w", err)
+			return err
 		}
 
-		revert.Add(func() {
-			_ = m.RemoveFDFromFDSet(device["id"])
-		})
+		conn, err := net.DialUnix("unix", nil, addr)
+		if err != nil {
+			return fmt.Errorf("Failed connecting to vhost-user socket %q: %w", path, err)
+		}
 
-		device["hostdevice"] = fmt.Sprintf("/dev/fdset/%d", info.ID)
+		defer func() { _ = conn.Close() }() // Close file after device has been added.
 
-		err = m.AddDevice(device)
+		connFile, err := conn.File()
 		if err != nil {
-			return fmt.Errorf("Failed to add device: %w", err)
+			return err
 		}
 
-		revert.Success()
-		return nil
-	}
+		defer func() { _ = connFile.Close() }()
 
-	return monHook, nil
-}
+		err = m.SendFile(chardevID, connFile)
+		if err != nil {
+			return fmt.Errorf("Failed sending vhost-user socket for shared memory device %q: %w", devName, err)
+		}
 
-func (d *qemu) addTPMDeviceConfig(cfg *[]cfgSection, tpmConfig []deviceConfig.RunConfigItem, fdFiles *[]*os.File) error {
-	var devName, socketPath string
+		revert.Add(func() { _ = m.CloseFile(chardevID) })
 
-	for _, tpmItem := range tpmConfig {
-		if tpmItem.Key == "path" {
-			socketPath = tpmItem.Value
-		} else if tpmItem.Key == "devName" {
-			devName = tpmItem.Value
+		err = m.AddCharDevice(map[string]any{
+			"id": chardevID,
+			"backend": map[string]any{
+				"type": "socket",
+				"data": map[string]any{
+					"addr": map[string]any{
+						"type": "fd",
+						"data": map[string]any{
+							"str": chardevID,
+						},
+					},
+					"server": false,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("Failed adding character device for shared memory device %q: %w", devName, err)
 		}
-	}
 
-	shortPath, err := d.shortenedFilePath(socketPath, fdFiles)
-	if err != nil {
-		return err
-	}
+		revert.Add(func() { _ = m.RemoveCharDevice(chardevID) })
 
-	tpmOpts := qemuTPMOpts{
-		devName: devName,
-		path:    shortPath,
+		netDev := map[string]any{
+			"type":    "vhost-user",
+			"id":      netDevID,
+			"chardev": chardevID,
+		}
+
+		err = m.AddNIC(netDev, qemuDev)
+		if err != nil {
+			return fmt.Errorf("Failed adding vhost-user device for shared memory device %q: %w", devName, err)
+		}
+
+		revert.Success()
+		return nil
 	}
-	*cfg = append(*cfg, qemuTPM(&tpmOpts)...)
 
-	return nil
+	return monHook, nil
 }
 
 func (d *qemu) addVmgenDeviceConfig(cfg *[]cfgSection, guid string) error {
@@ -4979,6 +5835,9 @@ func (d *qemu) IsPrivileged() bool {
 func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 	var err error
 	var monitor *qmp.Monitor
+	var stateManifest *chunkstore.Manifest
+
+	incremental := shared.IsTrue(d.expandedConfig["snapshots.stateful.incremental"])
 
 	// Deal with state.
 	if stateful {
@@ -4999,7 +5858,12 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 		}
 
 		// Dump the state.
-		err = d.saveState(monitor)
+		if incremental {
+			stateManifest, err = d.saveStateIncremental(monitor)
+		} else {
+			err = d.saveState(monitor)
+		}
+
 		if err != nil {
 			return err
 		}
@@ -5014,7 +5878,19 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 	// Resume the VM once the disk state has been saved.
 	if stateful {
 		// Remove the state from the main volume.
-		err = os.Remove(d.StatePath())
+		if incremental {
+			// Keep the chunks of the state in the store of the main volume, so that the next stateful
+			// snapshot only needs to add the chunks that changed.
+			err = chunkstore.New(d.stateStorePath()).AddReference(name, stateManifest)
+			if err != nil {
+				return fmt.Errorf("Failed adding state of snapshot to state store: %w", err)
+			}
+
+			err = os.Remove(d.stateManifestPath())
+		} else {
+			err = os.Remove(d.StatePath())
+		}
+
 		if err != nil {
 			return err
 		}
@@ -5605,29 +6481,36 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 	isRunning := d.IsRunning()
 
 	// Use the device interface to apply update changes.
-	devlxdEvents, err := d.devicesUpdate(d, removeDevices, addDevices, updateDevices, oldExpandedDevices, isRunning, userRequested)
+	devlxdEvents, cleanup, err := d.devicesUpdate(d, removeDevices, addDevices, updateDevices, oldExpandedDevices, isRunning, userRequested)
 	if err != nil {
 		return err
 	}
 
+	revert.Add(cleanup)
+
 	cpuLimitWasChanged := false
 
 	if isRunning {
 		// Only certain keys can be changed on a running VM.
 		liveUpdateKeys := []string{
 			"cluster.evacuate",
+			"limits.cpu.rebalance",
 			"limits.memory",
 			"security.agent.metrics",
 			"security.csm",
 			"security.devlxd",
+			"security.protection.host_shutdown",
 			"security.secureboot",
 		}
 
 		liveUpdateKeyPrefixes := []string{
+			"agent.watchdog.",
 			"boot.",
 			"cloud-init.",
+			"console.",
 			"environment.",
 			"image.",
+			"limits.memory.balloon.",
 			"snapshots.",
 			"user.",
 			"volatile.",
@@ -5699,6 +6582,9 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 						return fmt.Errorf("Failed updating memory limit: %w", err)
 					}
 				}
+
+				// The new limit replaces any automatic balloon target.
+				delete(d.localConfig, "volatile.memory.balloon_target")
 			} else if key == "security.csm" {
 				// Defer rebuilding nvram until next start.
 				d.localConfig["volatile.apply_nvram"] = "true"
@@ -5815,15 +6701,15 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 	// Changes have been applied and recorded, do not revert if an error occurs from here.
 	revert.Success()
 
-	if cpuLimitWasChanged {
+	if cpuLimitWasChanged || (isRunning && shared.ValueInSlice("limits.cpu.rebalance", changedConfig)) {
 		// Trigger a scheduler re-run
 		cgroup.TaskSchedulerTrigger("virtual-machine", d.name, "changed")
 	}
 
 	if isRunning {
-		// Send devlxd notifications only for user.* key changes
+		// Send devlxd notifications only for user.* and cloud-init.* key changes
 		for _, key := range changedConfig {
-			if !strings.HasPrefix(key, "user.") {
+			if !shared.StringHasPrefix(key, "user.", "cloud-init.") {
 				continue
 			}
 
@@ -6527,6 +7413,9 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 	indexHeaderVersion := migration.IndexHeaderVersion
 	offerHeader.IndexHeaderVersion = &indexHeaderVersion
 
+	// Indicate to target whether the migration will be retried if it fails.
+	offerHeader.Retry = proto.Bool(args.Retry)
+
 	// For VMs, send block device size hint in offer header so that target can create the volume the same size.
 	blockSize, err := storagePools.InstanceDiskBlockSize(pool, d, d.op)
 	if err != nil {
@@ -6558,6 +7447,11 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 	// fulfil the "live" part of the request, albeit with longer pause of the instance during the process.
 	if args.Live {
 		offerHeader.Criu = migration.CRIUType_VM_QEMU.Enum()
+
+		// Offer switching the state transfer to post-copy mode if requested.
+		if d.expandedConfig["migration.mode"] == "postcopy" {
+			offerHeader.Postcopy = proto.Bool(true)
+		}
 	}
 
 	// Send offer to target.
@@ -6594,6 +7488,7 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 		VolumeOnly:         !args.Snapshots,
 		Info:               &migration.Info{Config: srcConfig},
 		ClusterMove:        args.ClusterMoveSourceName != "",
+		BandwidthLimit:     args.BandwidthLimit,
 	}
 
 	// Only send the snapshots that the target requests when refreshing.
@@ -6684,7 +7579,7 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 				defer instanceRefClear(d)
 			}
 
-			err = d.migrateSendLive(pool, args.ClusterMoveSourceName, blockSize, filesystemConn, stateConn, volSourceArgs)
+			err = d.migrateSendLive(pool, args.ClusterMoveSourceName, blockSize, filesystemConn, stateConn, respHeader.GetPostcopy(), volSourceArgs)
 			if err != nil {
 				return err
 			}
@@ -6718,7 +7613,9 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 }
 
 // migrateSendLive performs live migration send process.
-func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName string, rootDiskSize int64, filesystemConn io.ReadWriteCloser, stateConn io.ReadWriteCloser, volSourceArgs *migration.VolumeSourceArgs) error {
+// If postcopy is true, the state transfer switches to post-copy mode and the guest is resumed on the target before
+// all of its memory has been transferred.
+func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName string, rootDiskSize int64, filesystemConn io.ReadWriteCloser, stateConn io.ReadWriteCloser, postcopy bool, volSourceArgs *migration.VolumeSourceArgs) error {
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
 	if err != nil {
 		return err
@@ -6739,7 +7636,10 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 		// Setup migration capabilities.
 		capabilities := map[string]bool{
 			// Automatically throttle down the guest to speed up convergence of RAM migration.
-			"auto-converge": true,
+			"auto-converge": shared.IsTrueOrEmpty(d.expandedConfig["migration.auto_converge"]),
+
+			// Only send the changes of RAM pages that are sent again if requested.
+			"xbzrle": shared.IsTrue(d.expandedConfig["migration.xbzrle"]),
 
 			// Allow the migration to be paused after the source qemu releases the block devices but
 			// before the serialisation of the device state, to avoid a race condition between
@@ -6751,6 +7651,10 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 			"zero-blocks": true,
 		}
 
+		if postcopy {
+			capabilities["postcopy-ram"] = true
+		}
+
 		err = monitor.MigrateSetCapabilities(capabilities)
 		if err != nil {
 			return fmt.Errorf("Failed setting migration capabilities: %w", err)
@@ -6845,13 +7749,39 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 		// Still set some options for shared storage.
 		capabilities := map[string]bool{
 			// Automatically throttle down the guest to speed up convergence of RAM migration.
-			"auto-converge": true,
+			"auto-converge": shared.IsTrueOrEmpty(d.expandedConfig["migration.auto_converge"]),
+
+			// Only send the changes of RAM pages that are sent again if requested.
+			"xbzrle": shared.IsTrue(d.expandedConfig["migration.xbzrle"]),
+		}
+
+		if postcopy {
+			capabilities["postcopy-ram"] = true
+
+			// Pause the migration before switching to post-copy, so that the point after which the guest
+			// can no longer be resumed on the source is known.
+			capabilities["pause-before-switchover"] = true
 		}
 
 		err = monitor.MigrateSetCapabilities(capabilities)
 		if err != nil {
 			return fmt.Errorf("Failed setting migration capabilities: %w", err)
 		}
+
+		defer revert.Fail()
+	}
+
+	// Size the cache of sent RAM pages used to compute their changes.
+	if shared.IsTrue(d.expandedConfig["migration.xbzrle"]) && d.expandedConfig["migration.xbzrle.cache_size"] != "" {
+		cacheSize, err := units.ParseByteSizeString(d.expandedConfig["migration.xbzrle.cache_size"])
+		if err != nil {
+			return err
+		}
+
+		err = monitor.MigrateSetParameters(map[string]any{"xbzrle-cache-size": cacheSize})
+		if err != nil {
+			return fmt.Errorf("Failed setting migration parameters: %w", err)
+		}
 	}
 
 	// Perform storage transfer while instance is still running.
@@ -6942,25 +7872,46 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 	d.logger.Debug("Stateful migration checkpoint send starting")
 
 	// Send checkpoint to QEMU process on target. This will pause the guest OS (if not already paused).
-	pipeRead, pipeWrite, err := os.Pipe()
-	if err != nil {
-		return err
-	}
+	var stateFile *os.File
+	if postcopy {
+		// Post-copy needs the return path of a socket to receive page requests from the target.
+		stateFile, err = d.migrationSocket(stateConn)
+		if err != nil {
+			return err
+		}
 
-	defer func() {
-		_ = pipeRead.Close()
-		_ = pipeWrite.Close()
-	}()
+		defer func() { _ = stateFile.Close() }()
+	} else {
+		pipeRead, pipeWrite, err := os.Pipe()
+		if err != nil {
+			return err
+		}
 
-	go func() { _, _ = io.Copy(stateConn, pipeRead) }()
+		defer func() {
+			_ = pipeRead.Close()
+			_ = pipeWrite.Close()
+		}()
 
-	err = d.saveStateHandle(monitor, pipeWrite)
+		go func() { _, _ = io.Copy(stateConn, pipeRead) }()
+
+		stateFile = pipeWrite
+	}
+
+	err = d.saveStateHandle(monitor, stateFile)
 	if err != nil {
 		return fmt.Errorf("Failed starting state transfer to target: %w", err)
 	}
 
+	if postcopy {
+		// Switch to post-copy as soon as possible, the migration then pauses before the switchover.
+		err = monitor.MigrateStartPostcopy()
+		if err != nil {
+			return fmt.Errorf("Failed switching state transfer to post-copy: %w", err)
+		}
+	}
+
 	// Non-shared storage snapshot transfer finalization.
-	if !sharedStorage {
+	if !sharedStorage || postcopy {
 		// Wait until state transfer has reached pre-switchover state (the guest OS will remain paused).
 		err = monitor.MigrateWait("pre-switchover")
 		if err != nil {
@@ -6969,14 +7920,16 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 
 		d.logger.Debug("Stateful migration checkpoint reached pre-switchover phase")
 
-		// Complete the migration snapshot sync process (the guest OS will remain paused).
-		d.logger.Debug("Migration storage snapshot transfer commit started")
-		err = monitor.BlockJobCancel(rootSnapshotDiskName)
-		if err != nil {
-			return fmt.Errorf("Failed cancelling block job: %w", err)
-		}
+		if !sharedStorage {
+			// Complete the migration snapshot sync process (the guest OS will remain paused).
+			d.logger.Debug("Migration storage snapshot transfer commit started")
+			err = monitor.BlockJobCancel(rootSnapshotDiskName)
+			if err != nil {
+				return fmt.Errorf("Failed cancelling block job: %w", err)
+			}
 
-		d.logger.Debug("Migration storage snapshot transfer commit finished")
+			d.logger.Debug("Migration storage snapshot transfer commit finished")
+		}
 
 		// Finalise the migration state transfer (the guest OS will remain paused).
 		err = monitor.MigrateContinue("pre-switchover")
@@ -6987,6 +7940,23 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 		d.logger.Debug("Stateful migration checkpoint send continuing")
 	}
 
+	if postcopy {
+		// Once switched to post-copy the guest runs on the target, so it can no longer be resumed on the source
+		// without losing what happened since. If the migration fails from now on, the instance state is lost
+		// and the source is stopped instead of resumed.
+		revert.Success()
+		revert.Add(func() {
+			d.logger.Error("Post-copy migration failed, stopping instance as its state cannot be recovered")
+
+			err := d.Stop(false)
+			if err != nil {
+				d.logger.Warn("Failed stopping instance", logger.Ctx{"err": err})
+			}
+		})
+
+		d.logger.Debug("Stateful migration checkpoint switched to post-copy")
+	}
+
 	// Wait until the migration state transfer has completed (the guest OS will remain paused).
 	err = monitor.MigrateWait("completed")
 	if err != nil {
@@ -6995,6 +7965,11 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 
 	d.logger.Debug("Stateful migration checkpoint send finished")
 
+	if postcopy {
+		// The whole state has reached the target, so there is no need to stop the source anymore.
+		revert.Success()
+	}
+
 	if clusterMoveSourceName != "" {
 		// If doing an intra-cluster member move then we will be deleting the instance on the source,
 		// so lets just stop it after migration is completed.
@@ -7170,6 +8145,11 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 	if args.Live && offerHeader.Criu != nil && *offerHeader.Criu == migration.CRIUType_VM_QEMU {
 		respHeader.Criu = migration.CRIUType_VM_QEMU.Enum()
 		useStateConn = true
+
+		// Accept switching the state transfer to post-copy mode if requested by the source.
+		if offerHeader.GetPostcopy() {
+			respHeader.Postcopy = proto.Bool(true)
+		}
 	}
 
 	// Send response to source.
@@ -7354,8 +8334,11 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 
 		// Only delete all instance volumes on error if the pool volume creation has succeeded to
 		// avoid deleting an existing conflicting volume.
+		// Volumes refreshed as part of a cluster move were created by a previous attempt of the move, so
+		// are also deleted, unless the source is going to retry the move and can then reuse them.
 		isRemoteClusterMove := args.ClusterMoveSourceName != "" && poolInfo.Remote
-		if !volTargetArgs.Refresh && !isRemoteClusterMove {
+		keepVolumes := args.ClusterMoveSourceName != "" && offerHeader.GetRetry()
+		if (!volTargetArgs.Refresh || args.ClusterMoveSourceName != "") && !isRemoteClusterMove && !keepVolumes {
 			revert.Add(func() {
 				snapshots, _ := d.Snapshots()
 				snapshotCount := len(snapshots)
@@ -7383,6 +8366,8 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 					api.SecretNameState: stateConn,
 				}
 
+				d.migrationReceivePostcopy = respHeader.GetPostcopy()
+
 				// Populate the filesystem connection handle if doing non-shared storage migration.
 				sharedStorage := args.ClusterMoveSourceName != "" && poolInfo.Remote
 				if !sharedStorage {
@@ -7495,6 +8480,43 @@ func (d *qemu) SetAffinity(set []string) error {
 	return nil
 }
 
+// CPUAffinity returns the host CPU each vCPU thread is pinned to (indexed by vCPU).
+// It returns nil if any of the vCPU threads isn't pinned to a single host CPU.
+func (d *qemu) CPUAffinity() ([]string, error) {
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the list of PIDs from the VM.
+	pids, err := monitor.GetCPUs()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get VM instance's QEMU process list: %w", err)
+	}
+
+	set := make([]string, 0, len(pids))
+	for _, pid := range pids {
+		affinitySet := unix.CPUSet{}
+		err := unix.SchedGetaffinity(pid, &affinitySet)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get QEMU process affinity: %w", err)
+		}
+
+		if affinitySet.Count() != 1 {
+			return nil, nil
+		}
+
+		for i := 0; i < len(affinitySet)*64; i++ {
+			if affinitySet.IsSet(i) {
+				set = append(set, strconv.Itoa(i))
+				break
+			}
+		}
+	}
+
+	return set, nil
+}
+
 // FileSFTPConn returns a connection to the agent SFTP endpoint.
 func (d *qemu) FileSFTPConn() (net.Conn, error) {
 	// VMs, unlike containers, cannot perform file operations if not running and using the lxd-agent.
@@ -7594,7 +8616,8 @@ func (d *qemu) Console(protocol string) (*os.File, chan error, error) {
 	var path string
 	switch protocol {
 	case instance.ConsoleTypeConsole:
-		path = d.consolePath()
+		// The text console is shared between clients.
+		return d.ConsoleAttach(false, nil)
 	case instance.ConsoleTypeVGA:
 		path = d.spicePath()
 	default:
@@ -7645,26 +8668,46 @@ func (d *qemu) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, s
 	controlResCh := make(chan error)
 
 	// This is the signal control handler, it receives signals from lxc CLI and forwards them to the VM agent.
-	controlHandler := func(control *websocket.Conn) {
-		closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
-		defer func() { _ = control.WriteMessage(websocket.CloseMessage, closeMsg) }()
-
-		for {
-			select {
-			case cmd := <-controlSendCh:
-				controlResCh <- control.WriteJSON(cmd)
-			case <-dataDone:
-				return
+	controlHandler := func(dataDone chan bool) func(control *websocket.Conn) {
+		return func(control *websocket.Conn) {
+			closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			defer func() { _ = control.WriteMessage(websocket.CloseMessage, closeMsg) }()
+
+			for {
+				select {
+				case cmd := <-controlSendCh:
+					controlResCh <- control.WriteJSON(cmd)
+				case <-dataDone:
+					return
+				}
 			}
 		}
 	}
 
 	args := lxd.InstanceExecArgs{
 		Stdin:    stdin,
-		Stdout:   stdout,
-		Stderr:   stderr,
 		DataDone: dataDone,
-		Control:  controlHandler,
+		Control:  controlHandler(dataDone),
+	}
+
+	// Keep track of how much output was received so that it can be resumed if the lxd-agent restarts while the
+	// command is running.
+	stdoutOutput := &qemuExecOutput{w: io.Discard}
+	if stdout != nil {
+		stdoutOutput.w = stdout
+	}
+
+	stderrOutput := &qemuExecOutput{w: io.Discard}
+	if stderr != nil {
+		stderrOutput.w = stderr
+	}
+
+	if req.Interactive {
+		args.Stdout = stdout
+		args.Stderr = stderr
+	} else {
+		args.Stdout = stdoutOutput
+		args.Stderr = stderrOutput
 	}
 
 	// Always needed for VM exec, as even for non-websocket requests from the client we need to connect the
@@ -7685,12 +8728,112 @@ func (d *qemu) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, s
 		controlResCh:     controlResCh,
 	}
 
+	// Non-interactive commands keep running if the lxd-agent restarts, so allow re-attaching to them.
+	if !req.Interactive {
+		sessionID := op.Get().ID
+
+		instCmd.reattachFunc = func() (lxd.Operation, chan bool, error) {
+			dataDone := make(chan bool)
+
+			op, err := d.execReattach(sessionID, stdoutOutput, stderrOutput, controlHandler(dataDone), dataDone)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return op, dataDone, nil
+		}
+	}
+
 	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceExec.Event(d, logger.Ctx{"command": req.Command}))
 
 	revert.Success()
 	return instCmd, nil
 }
 
+// execReattach waits for the lxd-agent to be available again and re-attaches to the output of the non-interactive
+// exec session with the given ID, resuming from what was already received.
+func (d *qemu) execReattach(sessionID string, stdout *qemuExecOutput, stderr *qemuExecOutput, control func(conn *websocket.Conn), dataDone chan bool) (lxd.Operation, error) {
+	var agent lxd.InstanceServer
+
+	d.logger.Debug("Re-attaching to exec session", logger.Ctx{"session": sessionID})
+
+	timeout := time.Now().Add(qemuExecReattachTimeout)
+	for {
+		client, err := d.getAgentClient()
+		if err == nil {
+			agent, err = lxd.ConnectLXDHTTP(nil, client)
+			if err == nil {
+				break
+			}
+		}
+
+		if !d.IsRunning() || time.Now().After(timeout) {
+			return nil, fmt.Errorf("Failed to reconnect to lxd-agent: %w", err)
+		}
+
+		time.Sleep(time.Second)
+	}
+
+	req := agentAPI.ExecSessionPost{
+		StdoutOffset: stdout.n,
+		StderrOffset: stderr.n,
+	}
+
+	op, _, err := agent.RawOperation("POST", "/exec/"+url.PathEscape(sessionID), req, "")
+	if err != nil {
+		agent.Disconnect()
+		return nil, err
+	}
+
+	opAPI := op.Get()
+	fds, _ := opAPI.Metadata["fds"].(map[string]any)
+	secret := func(fd string) string {
+		value, _ := fds[fd].(string)
+		return value
+	}
+
+	controlConn, err := agent.GetOperationWebsocket(opAPI.ID, secret(api.SecretNameControl))
+	if err != nil {
+		agent.Disconnect()
+		return nil, err
+	}
+
+	stdoutConn, err := agent.GetOperationWebsocket(opAPI.ID, secret("1"))
+	if err != nil {
+		_ = controlConn.Close()
+		agent.Disconnect()
+		return nil, err
+	}
+
+	stderrConn, err := agent.GetOperationWebsocket(opAPI.ID, secret("2"))
+	if err != nil {
+		_ = controlConn.Close()
+		_ = stdoutConn.Close()
+		agent.Disconnect()
+		return nil, err
+	}
+
+	go func() {
+		_, _, _ = controlConn.ReadMessage() // Consume pings from the agent.
+	}()
+
+	go control(controlConn)
+
+	stdoutDone := ws.MirrorWrite(stdoutConn, stdout)
+	stderrDone := ws.MirrorWrite(stderrConn, stderr)
+
+	go func() {
+		<-stdoutDone
+		<-stderrDone
+		_ = stdoutConn.Close()
+		_ = stderrConn.Close()
+		agent.Disconnect()
+		close(dataDone)
+	}()
+
+	return op, nil
+}
+
 // Render returns info about the instance.
 func (d *qemu) Render(options ...func(response any) error) (state any, etag any, err error) {
 	profileNames := make([]string, 0, len(d.profiles))
@@ -7876,11 +9019,33 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 				if netStatus.Hwaddr == hwaddr {
 					if netStatus.HostName == "" {
 						netStatus.HostName = d.localConfig[fmt.Sprintf("volatile.%s.host_name", k)]
-						status.Network[netName] = netStatus
 					}
+
+					netStatus.Announcements = device.NICAnnouncements(d.project.Name, d.name, k)
+					status.Network[netName] = netStatus
+				}
+			}
+		}
+
+		// All the vCPU threads share the same core scheduling cookie.
+		if d.coreSchedulingEnabled() {
+			monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+			if err == nil {
+				pids, err := monitor.GetCPUs()
+				if err == nil && len(pids) > 0 {
+					status.CPU.CoreSchedulingCookie = d.coreSchedulingCookie(pids[0])
 				}
 			}
 		}
+
+		// Add the memory balloon statistics.
+		monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+		if err == nil {
+			status.Memory.Balloon, err = d.memoryBalloonState(monitor)
+			if err != nil {
+				d.logger.Debug("Failed getting memory balloon statistics", logger.Ctx{"err": err})
+			}
+		}
 	}
 
 	status.Pid = int64(pid)
@@ -8752,26 +9917,36 @@ func (d *qemu) version() (*version.DottedVersion, error) {
 // If the instance is not running, it returns ErrInstanceIsStopped.
 // If agent metrics are enabled, it tries to get the metrics from the agent.
 // If the agent is not reachable, it falls back to getting the metrics directly from QEMU.
+// The statistics of the QEMU block devices are always added to the metrics.
 func (d *qemu) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error) {
 	if !d.IsRunning() {
 		return nil, ErrInstanceIsStopped
 	}
 
+	var metricSet *metrics.MetricSet
+	var err error
+
 	if d.agentMetricsEnabled() {
-		metrics, err := d.getAgentMetrics()
-		if err != nil {
-			if !errors.Is(err, errQemuAgentOffline) {
-				d.logger.Warn("Could not get VM metrics from agent", logger.Ctx{"err": err})
-			}
+		metricSet, err = d.getAgentMetrics()
+		if err != nil && !errors.Is(err, errQemuAgentOffline) {
+			d.logger.Warn("Could not get VM metrics from agent", logger.Ctx{"err": err})
+		}
+	}
 
-			// Fallback data if agent is not reachable.
-			return d.getQemuMetrics()
+	// Fallback data if agent is not reachable.
+	if metricSet == nil {
+		metricSet, err = d.getQemuMetrics()
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		return metrics, nil
+	err = d.addQemuBlockMetrics(metricSet)
+	if err != nil {
+		d.logger.Warn("Failed to get block device metrics", logger.Ctx{"err": err})
 	}
 
-	return d.getQemuMetrics()
+	return metricSet, nil
 }
 
 func (d *qemu) getAgentMetrics() (*metrics.MetricSet, error) {
@@ -0,0 +1,216 @@
+package drivers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/instance/drivers/qmp"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// qemuBalloonPath is the QOM path of the balloon device.
+const qemuBalloonPath = "/machine/peripheral/qemu_balloon"
+
+// qemuBalloonStatsInterval is the interval in seconds at which the guest reports its memory statistics.
+const qemuBalloonStatsInterval = 10
+
+// qemuBalloonPressure is the percentage of available guest memory below which the balloon is deflated.
+const qemuBalloonPressure = 10
+
+// qemuBalloonHeadroom is the percentage of available guest memory left to the guest when reclaiming memory.
+const qemuBalloonHeadroom = 20
+
+// qemuBalloonMinStep is the minimum change of the balloon target, avoiding constant small adjustments.
+const qemuBalloonMinStep = 64 * 1024 * 1024
+
+// memoryLimitBytes returns the memory limit of the VM in bytes.
+func (d *qemu) memoryLimitBytes() (int64, error) {
+	memSize := d.expandedConfig["limits.memory"]
+	if memSize == "" {
+		memSize = QEMUDefaultMemSize // Default if no memory limit specified.
+	}
+
+	return units.ParseByteSizeString(memSize)
+}
+
+// memoryBalloonFloorBytes returns the size in bytes below which the automatic balloon doesn't shrink the VM memory.
+func (d *qemu) memoryBalloonFloorBytes(limitBytes int64) (int64, error) {
+	floor := d.expandedConfig["limits.memory.balloon.floor"]
+	if floor == "" {
+		floor = "50%"
+	}
+
+	percentStr, isPercent := strings.CutSuffix(floor, "%")
+	if isPercent {
+		percent, err := strconv.ParseInt(percentStr, 10, 64)
+		if err != nil {
+			return -1, fmt.Errorf("Invalid limits.memory.balloon.floor: %w", err)
+		}
+
+		return limitBytes * percent / 100, nil
+	}
+
+	floorBytes, err := units.ParseByteSizeString(floor)
+	if err != nil {
+		return -1, fmt.Errorf("Invalid limits.memory.balloon.floor: %w", err)
+	}
+
+	return min(floorBytes, limitBytes), nil
+}
+
+// memoryBalloonStats returns the memory statistics reported by the guest.
+// If the guest isn't reporting statistics yet, polling is enabled and nil is returned.
+func (d *qemu) memoryBalloonStats(monitor *qmp.Monitor) (*qmp.MemoryBalloonStats, error) {
+	stats, err := monitor.GetMemoryBalloonStats(qemuBalloonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats == nil {
+		err = monitor.SetMemoryBalloonStatsInterval(qemuBalloonPath, qemuBalloonStatsInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+// memoryBalloonState returns the memory balloon statistics of the running VM.
+func (d *qemu) memoryBalloonState(monitor *qmp.Monitor) (*api.InstanceStateMemoryBalloon, error) {
+	actual, err := monitor.GetMemoryBalloonSizeBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.InstanceStateMemoryBalloon{
+		Actual:         actual,
+		GuestFree:      -1,
+		GuestAvailable: -1,
+	}
+
+	state.Target, err = strconv.ParseInt(d.localConfig["volatile.memory.balloon_target"], 10, 64)
+	if err != nil {
+		state.Target, err = d.memoryLimitBytes()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stats, err := d.memoryBalloonStats(monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats != nil {
+		state.GuestFree = stats.FreeMemory
+		state.GuestAvailable = stats.AvailableMemory
+	}
+
+	return &state, nil
+}
+
+// UpdateMemoryBalloon applies the automatic memory balloon policy to the running VM.
+// Memory that the guest doesn't use is gradually reclaimed down to limits.memory.balloon.floor, and all the
+// memory is returned to the guest as soon as it runs low on available memory.
+func (d *qemu) UpdateMemoryBalloon() error {
+	enabled := shared.IsTrue(d.expandedConfig["limits.memory.balloon.auto"]) && !shared.IsTrue(d.expandedConfig["limits.memory.hugepages"])
+	if !enabled && d.localConfig["volatile.memory.balloon_target"] == "" {
+		return nil
+	}
+
+	if !d.IsRunning() {
+		return nil
+	}
+
+	limitBytes, err := d.memoryLimitBytes()
+	if err != nil {
+		return err
+	}
+
+	// Connect to the monitor.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err // The VM isn't running as no monitor socket available.
+	}
+
+	actualBytes, err := monitor.GetMemoryBalloonSizeBytes()
+	if err != nil {
+		return err
+	}
+
+	targetBytes := limitBytes
+	if enabled {
+		floorBytes, err := d.memoryBalloonFloorBytes(limitBytes)
+		if err != nil {
+			return err
+		}
+
+		stats, err := d.memoryBalloonStats(monitor)
+		if err != nil {
+			return err
+		}
+
+		// Wait for the guest to report its memory usage.
+		if stats == nil {
+			return nil
+		}
+
+		available := stats.AvailableMemory
+		if available < 0 {
+			available = stats.FreeMemory
+		}
+
+		if available < 0 {
+			return nil
+		}
+
+		aggressiveness := int64(25)
+		if d.expandedConfig["limits.memory.balloon.aggressiveness"] != "" {
+			aggressiveness, err = strconv.ParseInt(d.expandedConfig["limits.memory.balloon.aggressiveness"], 10, 64)
+			if err != nil {
+				return fmt.Errorf("Invalid limits.memory.balloon.aggressiveness: %w", err)
+			}
+		}
+
+		reclaimable := available - actualBytes*qemuBalloonHeadroom/100
+		if available < actualBytes*qemuBalloonPressure/100 {
+			// The guest is under memory pressure, give all its memory back.
+			targetBytes = limitBytes
+		} else if reclaimable > 0 {
+			// Reclaim part of the memory the guest doesn't need.
+			targetBytes = max(floorBytes, actualBytes-reclaimable*aggressiveness/100)
+		} else {
+			targetBytes = actualBytes
+		}
+	}
+
+	diff := targetBytes - actualBytes
+	if diff < 0 {
+		diff = -diff
+	}
+
+	// Skip small adjustments, unless all the memory is being returned to the guest.
+	restoring := targetBytes == limitBytes && d.localConfig["volatile.memory.balloon_target"] != ""
+	if diff < qemuBalloonMinStep && !restoring {
+		return nil
+	}
+
+	err = monitor.SetMemoryBalloonSizeBytes(targetBytes)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Debug("Updated memory balloon", logger.Ctx{"actual": actualBytes, "target": targetBytes})
+
+	target := ""
+	if targetBytes != limitBytes {
+		target = strconv.FormatInt(targetBytes, 10)
+	}
+
+	return d.VolatileSet(map[string]string{"volatile.memory.balloon_target": target})
+}
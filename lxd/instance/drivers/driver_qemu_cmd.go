@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"sync"
+	"time"
 
 	"golang.org/x/sys/unix"
 
@@ -13,14 +15,42 @@ import (
 	"github.com/canonical/lxd/shared/logger"
 )
 
+// qemuExecReattachTimeout is how long to wait for the lxd-agent to come back when re-attaching to a command.
+const qemuExecReattachTimeout = 30 * time.Second
+
+// qemuExecOutput counts the bytes written to an output of a command so that the output can be resumed from the
+// same offset when re-attaching to the command.
+type qemuExecOutput struct {
+	w io.Writer
+	n int64
+}
+
+// Write writes to the underlying writer and counts the bytes written.
+func (o *qemuExecOutput) Write(p []byte) (int, error) {
+	n, err := o.w.Write(p)
+	o.n += int64(n)
+
+	return n, err
+}
+
 // Cmd represents a running command for an Qemu VM.
 type qemuCmd struct {
 	attachedChildPid int
 	cmd              lxd.Operation
 	dataDone         chan bool
+	dataDoneMu       sync.Mutex
 	controlSendCh    chan api.InstanceExecControl
 	controlResCh     chan error
 	cleanupFunc      func()
+	reattachFunc     func() (lxd.Operation, chan bool, error)
+}
+
+// getDataDone returns the channel that is closed when the data operations of the command are done.
+func (c *qemuCmd) getDataDone() chan bool {
+	c.dataDoneMu.Lock()
+	defer c.dataDoneMu.Unlock()
+
+	return c.dataDone
 }
 
 // PID returns the attached child's process ID.
@@ -37,7 +67,7 @@ func (c *qemuCmd) Signal(sig unix.Signal) error {
 
 	// Check handler hasn't finished.
 	select {
-	case <-c.dataDone:
+	case <-c.getDataDone():
 		return fmt.Errorf("no such process") // Aligns with error retured from unix.Kill in lxc's Signal().
 	default:
 	}
@@ -58,18 +88,35 @@ func (c *qemuCmd) Wait() (int, error) {
 
 	exitStatus := -1
 	opAPI := c.cmd.Get()
-	if opAPI.Metadata != nil {
-		exitStatusRaw, ok := opAPI.Metadata["return"].(float64)
-		if ok {
-			exitStatus = int(exitStatusRaw)
-
-			// Convert special exit statuses into errors.
-			switch exitStatus {
-			case 127:
-				err = ErrExecCommandNotFound
-			case 126:
-				err = ErrExecCommandNotExecutable
-			}
+	exitStatusRaw, finished := opAPI.Metadata["return"].(float64)
+
+	// If the connection to the lxd-agent was lost before the command finished (for example because the agent
+	// restarted), re-attach to the command and resume its output.
+	if err != nil && !finished && c.reattachFunc != nil {
+		<-c.getDataDone()
+
+		op, dataDone, reattachErr := c.reattachFunc()
+		if reattachErr == nil {
+			c.dataDoneMu.Lock()
+			c.cmd = op
+			c.dataDone = dataDone
+			c.dataDoneMu.Unlock()
+
+			return c.Wait()
+		}
+
+		logger.Warn("Failed re-attaching to command", logger.Ctx{"err": reattachErr})
+	}
+
+	if finished {
+		exitStatus = int(exitStatusRaw)
+
+		// Convert special exit statuses into errors.
+		switch exitStatus {
+		case 127:
+			err = ErrExecCommandNotFound
+		case 126:
+			err = ErrExecCommandNotExecutable
 		}
 	}
 
@@ -84,7 +131,7 @@ func (c *qemuCmd) Wait() (int, error) {
 		return exitStatus, err
 	}
 
-	<-c.dataDone
+	<-c.getDataDone()
 
 	if c.cleanupFunc != nil {
 		defer c.cleanupFunc()
@@ -105,7 +152,7 @@ func (c *qemuCmd) WindowResize(fd, winchWidth, winchHeight int) error {
 
 	// Check handler hasn't finished.
 	select {
-	case <-c.dataDone:
+	case <-c.getDataDone():
 		return fmt.Errorf("no such process") // Aligns with error retured from unix.Kill in lxc's Signal().
 	default:
 	}
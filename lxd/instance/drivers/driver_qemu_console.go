@@ -0,0 +1,383 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// qemuConsoleWriteTimeout is how long a console client can block the console output before being disconnected.
+const qemuConsoleWriteTimeout = 5 * time.Second
+
+// qemuConsoleSessionBuffer is the number of console output chunks queued for a client before it is disconnected.
+const qemuConsoleSessionBuffer = 64
+
+// errQemuConsoleMuxClosed is returned when attaching to a multiplexer whose console socket has been closed.
+var errQemuConsoleMuxClosed = errors.New("Console multiplexer is closed")
+
+// qemuConsoleMuxes holds the text console multiplexers of the running VMs keyed on instance ID.
+// qemuConsoleMuxesMu only protects the map, each multiplexer has its own lock.
+var qemuConsoleMuxes = map[int]*qemuConsoleMux{}
+var qemuConsoleMuxesMu sync.Mutex
+
+// qemuConsoleMux shares the single connection QEMU accepts on the text console socket between multiple clients.
+// The console output is sent to all the clients and the input of all the clients is sent to the console.
+type qemuConsoleMux struct {
+	instanceID int
+	conn       net.Conn
+	logger     logger.Logger
+
+	mu       sync.Mutex
+	closed   bool
+	sessions map[string]*qemuConsoleSession
+}
+
+// qemuConsoleSession represents a client attached to the text console through the multiplexer.
+type qemuConsoleSession struct {
+	info   api.InstanceConsoleSession
+	conn   net.Conn
+	output chan []byte
+}
+
+// newQemuConsoleMux returns a multiplexer for the console connection and starts sending the console output to its sessions.
+func newQemuConsoleMux(instanceID int, conn net.Conn, l logger.Logger) *qemuConsoleMux {
+	m := &qemuConsoleMux{
+		instanceID: instanceID,
+		conn:       conn,
+		logger:     l,
+		sessions:   map[string]*qemuConsoleSession{},
+	}
+
+	go m.readConsole()
+
+	return m
+}
+
+// consoleMux returns the text console multiplexer of the VM, connecting to the console socket if needed.
+func (d *qemu) consoleMux() (*qemuConsoleMux, error) {
+	qemuConsoleMuxesMu.Lock()
+	defer qemuConsoleMuxesMu.Unlock()
+
+	mux, ok := qemuConsoleMuxes[d.id]
+	if ok {
+		return mux, nil
+	}
+
+	conn, err := net.Dial("unix", d.consolePath())
+	if err != nil {
+		return nil, fmt.Errorf("Connect to console socket %q: %w", d.consolePath(), err)
+	}
+
+	mux = newQemuConsoleMux(d.id, conn, d.logger)
+	qemuConsoleMuxes[d.id] = mux
+
+	return mux, nil
+}
+
+// readConsole sends the console output to all the sessions until the console socket is closed.
+// The output is queued on each session so that a slow client cannot block the others.
+func (m *qemuConsoleMux) readConsole() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := m.conn.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+
+			m.mu.Lock()
+			for id, session := range m.sessions {
+				select {
+				case session.output <- data:
+				default:
+					m.logger.Warn("Disconnecting unresponsive console client", logger.Ctx{"session": id})
+					m.removeSession(id)
+				}
+			}
+
+			m.mu.Unlock()
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	// The console socket is gone (likely because the VM stopped), so disconnect all the sessions.
+	m.close()
+}
+
+// writeSession sends the queued console output to a session until the session is removed.
+func (m *qemuConsoleMux) writeSession(session *qemuConsoleSession) {
+	failed := false
+	for data := range session.output {
+		if failed {
+			continue
+		}
+
+		_ = session.conn.SetWriteDeadline(time.Now().Add(qemuConsoleWriteTimeout))
+		_, err := session.conn.Write(data)
+		if err != nil {
+			m.logger.Warn("Disconnecting unresponsive console client", logger.Ctx{"session": session.info.ID, "err": err})
+			failed = true
+
+			m.mu.Lock()
+			m.removeSession(session.info.ID)
+			m.mu.Unlock()
+		}
+	}
+}
+
+// readSession sends the input of a session to the console until the session is closed.
+func (m *qemuConsoleMux) readSession(session *qemuConsoleSession) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := session.conn.Read(buf)
+		if n > 0 {
+			_, err := m.conn.Write(buf[:n])
+			if err != nil {
+				break
+			}
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	m.mu.Lock()
+	m.removeSession(session.info.ID)
+	last := len(m.sessions) == 0
+	m.mu.Unlock()
+
+	// Release the console socket once the last session is gone so that QEMU accepts other connections.
+	if last {
+		m.close()
+	}
+}
+
+// attach adds a new session to the multiplexer and returns the client end of its connection.
+// Other sessions are disconnected if force is true.
+func (m *qemuConsoleMux) attach(force bool, info api.InstanceConsoleSession) (*os.File, error) {
+	// Give one end of a socket pair to the client and keep the other one in the multiplexer.
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating console socket pair: %w", err)
+	}
+
+	file := os.NewFile(uintptr(fds[0]), "console")
+	muxFile := os.NewFile(uintptr(fds[1]), "console-mux")
+	conn, err := net.FileConn(muxFile)
+	_ = muxFile.Close()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("Failed getting console socket connection: %w", err)
+	}
+
+	session := &qemuConsoleSession{
+		info:   info,
+		conn:   conn,
+		output: make(chan []byte, qemuConsoleSessionBuffer),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		_ = conn.Close()
+		_ = file.Close()
+		return nil, errQemuConsoleMuxClosed
+	}
+
+	if force {
+		for id := range m.sessions {
+			m.logger.Info("Disconnecting console client", logger.Ctx{"session": id})
+			m.removeSession(id)
+		}
+	}
+
+	m.sessions[session.info.ID] = session
+
+	go m.writeSession(session)
+	go m.readSession(session)
+
+	return file, nil
+}
+
+// sessionList returns the sessions attached to the multiplexer sorted by creation date.
+func (m *qemuConsoleMux) sessionList() []api.InstanceConsoleSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sessions := make([]api.InstanceConsoleSession, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session.info)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	return sessions
+}
+
+// removeSession disconnects a session. Must be called with the multiplexer lock held.
+func (m *qemuConsoleMux) removeSession(id string) {
+	session, ok := m.sessions[id]
+	if !ok {
+		return
+	}
+
+	_ = session.conn.Close()
+	close(session.output)
+	delete(m.sessions, id)
+}
+
+// close disconnects all the sessions, closes the console socket and unregisters the multiplexer.
+func (m *qemuConsoleMux) close() {
+	m.mu.Lock()
+	for id := range m.sessions {
+		m.removeSession(id)
+	}
+
+	m.closed = true
+	_ = m.conn.Close()
+	m.mu.Unlock()
+
+	qemuConsoleMuxesMu.Lock()
+	if qemuConsoleMuxes[m.instanceID] == m {
+		delete(qemuConsoleMuxes, m.instanceID)
+	}
+
+	qemuConsoleMuxesMu.Unlock()
+}
+
+// ConsoleAttach attaches a new client to the text console of the VM.
+// Other clients attached to the console are disconnected if force is true.
+func (d *qemu) ConsoleAttach(force bool, requestor *api.EventLifecycleRequestor) (*os.File, chan error, error) {
+	info := api.InstanceConsoleSession{
+		ID:        uuid.New().String(),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if requestor != nil {
+		info.Username = requestor.Username
+		info.Address = requestor.Address
+	}
+
+	var file *os.File
+	for {
+		mux, err := d.consoleMux()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		file, err = mux.attach(force, info)
+		if err == nil {
+			break
+		}
+
+		// The multiplexer was closed after being looked up, so get a new one.
+		if !errors.Is(err, errQemuConsoleMuxClosed) {
+			return nil, nil, err
+		}
+	}
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceConsole.Event(d, logger.Ctx{"type": instance.ConsoleTypeConsole}))
+
+	return file, make(chan error, 1), nil
+}
+
+// ConsoleSessions returns the clients attached to the text console of the VM.
+func (d *qemu) ConsoleSessions() []api.InstanceConsoleSession {
+	qemuConsoleMuxesMu.Lock()
+	mux, ok := qemuConsoleMuxes[d.id]
+	qemuConsoleMuxesMu.Unlock()
+
+	if !ok {
+		return []api.InstanceConsoleSession{}
+	}
+
+	return mux.sessionList()
+}
+
+// consoleHistorySize returns the amount of console output to keep in bytes.
+func (d *qemu) consoleHistorySize() int64 {
+	size := d.expandedConfig["console.history_size"]
+	if size == "" {
+		size = "64KiB"
+	}
+
+	sizeBytes, err := units.ParseByteSizeString(size)
+	if err != nil {
+		return 0
+	}
+
+	return sizeBytes
+}
+
+// trimConsoleHistory truncates the console output file to the configured size.
+// QEMU appends to the file, so it can be trimmed from the start while the VM is stopped.
+func (d *qemu) trimConsoleHistory() error {
+	history, err := d.ConsoleHistory()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(d.consoleHistoryPath(), history, 0600)
+}
+
+// ConsoleHistory returns the end of the text console output of the VM, up to the configured size.
+// The output is kept when no client is attached to the console and after the VM stops.
+func (d *qemu) ConsoleHistory() ([]byte, error) {
+	f, err := os.Open(d.consoleHistoryPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []byte{}, nil
+		}
+
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := d.consoleHistorySize()
+	if st.Size() > size {
+		_, err = f.Seek(-size, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return io.ReadAll(f)
+}
+
+// ConsoleHistoryClear clears the text console output of the VM.
+func (d *qemu) ConsoleHistoryClear() error {
+	// QEMU opens the file in append mode, so truncating it is safe while the VM is running.
+	err := os.Truncate(d.consoleHistoryPath(), 0)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
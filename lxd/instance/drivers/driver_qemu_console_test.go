@@ -0,0 +1,138 @@
+package drivers
+
+import (
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// newTestConsoleMux returns a multiplexer along with the QEMU end of its console connection.
+func newTestConsoleMux(t *testing.T) (*qemuConsoleMux, net.Conn) {
+	console, conn := net.Pipe()
+	mux := newQemuConsoleMux(-1, conn, logger.AddContext(logger.Ctx{}))
+
+	t.Cleanup(func() {
+		_ = console.Close()
+		mux.close()
+	})
+
+	return mux, console
+}
+
+func attachTestSession(t *testing.T, mux *qemuConsoleMux, force bool, id string, createdAt time.Time) *os.File {
+	file, err := mux.attach(force, api.InstanceConsoleSession{ID: id, CreatedAt: createdAt})
+	if err != nil {
+		t.Fatalf("Failed attaching session %q: %v", id, err)
+	}
+
+	t.Cleanup(func() { _ = file.Close() })
+
+	return file
+}
+
+func readTestConsole(t *testing.T, r io.Reader, expected string) {
+	buf := make([]byte, len(expected))
+	_, err := io.ReadFull(r, buf)
+	if err != nil {
+		t.Fatalf("Failed reading %q: %v", expected, err)
+	}
+
+	if string(buf) != expected {
+		t.Fatalf("Expected %q, got %q", expected, string(buf))
+	}
+}
+
+func TestQemuConsoleMuxFanOut(t *testing.T) {
+	mux, console := newTestConsoleMux(t)
+
+	now := time.Now()
+	first := attachTestSession(t, mux, false, "first", now)
+	second := attachTestSession(t, mux, false, "second", now.Add(time.Second))
+
+	// The console output is sent to every session.
+	_, err := console.Write([]byte("login: "))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readTestConsole(t, first, "login: ")
+	readTestConsole(t, second, "login: ")
+
+	// The input of every session is sent to the console.
+	_, err = second.Write([]byte("root\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readTestConsole(t, console, "root\n")
+}
+
+func TestQemuConsoleMuxForce(t *testing.T) {
+	mux, console := newTestConsoleMux(t)
+
+	now := time.Now()
+	first := attachTestSession(t, mux, false, "first", now)
+	second := attachTestSession(t, mux, true, "second", now.Add(time.Second))
+
+	// The existing session is disconnected.
+	_ = first.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err := first.Read(make([]byte, 1))
+	if err != io.EOF {
+		t.Fatalf("Expected the first session to be disconnected, got %v", err)
+	}
+
+	sessions := mux.sessionList()
+	if len(sessions) != 1 || sessions[0].ID != "second" {
+		t.Fatalf("Unexpected sessions %v", sessions)
+	}
+
+	// The new session keeps receiving the console output.
+	_, err = console.Write([]byte("login: "))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readTestConsole(t, second, "login: ")
+}
+
+func TestQemuConsoleMuxSessions(t *testing.T) {
+	mux, _ := newTestConsoleMux(t)
+
+	now := time.Now()
+	attachTestSession(t, mux, false, "second", now.Add(time.Second))
+	first := attachTestSession(t, mux, false, "first", now)
+
+	sessions := mux.sessionList()
+	if len(sessions) != 2 || sessions[0].ID != "first" || sessions[1].ID != "second" {
+		t.Fatalf("Unexpected sessions %v", sessions)
+	}
+
+	// A session is removed once its client disconnects.
+	_ = first.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(mux.sessionList()) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the closed session to be removed, got %v", mux.sessionList())
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Sessions can't be attached once the console socket is closed.
+	mux.close()
+
+	_, err := mux.attach(false, api.InstanceConsoleSession{ID: "third", CreatedAt: now})
+	if err != errQemuConsoleMuxClosed {
+		t.Fatalf("Expected %v, got %v", errQemuConsoleMuxClosed, err)
+	}
+
+	if len(mux.sessionList()) != 0 {
+		t.Fatalf("Expected no sessions after close, got %v", mux.sessionList())
+	}
+}
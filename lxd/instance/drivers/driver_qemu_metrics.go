@@ -7,12 +7,15 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/drivers/qmp"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/units"
 )
@@ -96,6 +99,95 @@ func (d *qemu) getQemuDiskMetrics(monitor *qmp.Monitor) (map[string]metrics.Disk
 	return out, nil
 }
 
+// addQemuBlockMetrics adds the statistics of the QEMU block devices to the metric set.
+// Unlike the disk metrics, these are gathered on the host and labelled with the name of the disk device.
+func (d *qemu) addQemuBlockMetrics(metricSet *metrics.MetricSet) error {
+	// Connect to the monitor.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	stats, err := monitor.GetBlockStats()
+	if err != nil {
+		return err
+	}
+
+	for qdev, stat := range stats {
+		devName := qemuBlockDeviceName(qdev)
+		if devName == "" {
+			continue
+		}
+
+		sample := func(value float64) metrics.Sample {
+			return metrics.Sample{Labels: map[string]string{"device": devName}, Value: value}
+		}
+
+		metricSet.AddSamples(metrics.BlockReadBytesTotal, sample(float64(stat.BytesRead)))
+		metricSet.AddSamples(metrics.BlockReadsCompletedTotal, sample(float64(stat.ReadsCompleted)))
+		metricSet.AddSamples(metrics.BlockReadSecondsTotal, sample(time.Duration(stat.ReadTimeNs).Seconds()))
+		metricSet.AddSamples(metrics.BlockWrittenBytesTotal, sample(float64(stat.BytesWritten)))
+		metricSet.AddSamples(metrics.BlockWritesCompletedTotal, sample(float64(stat.WritesCompleted)))
+		metricSet.AddSamples(metrics.BlockWriteSecondsTotal, sample(time.Duration(stat.WriteTimeNs).Seconds()))
+	}
+
+	return nil
+}
+
+// DiskIOStats returns the I/O counters of the disk devices of the running instance, keyed by device name.
+func (d *qemu) DiskIOStats() (map[string]api.StorageVolumeStateIO, error) {
+	if !d.IsRunning() {
+		return nil, ErrInstanceIsStopped
+	}
+
+	// Connect to the monitor.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := monitor.GetBlockStats()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]api.StorageVolumeStateIO)
+
+	for qdev, stat := range stats {
+		devName := qemuBlockDeviceName(qdev)
+		if devName == "" {
+			continue
+		}
+
+		out[devName] = api.StorageVolumeStateIO{
+			Device:          devName,
+			ReadBytes:       uint64(stat.BytesRead),
+			ReadsCompleted:  uint64(stat.ReadsCompleted),
+			ReadTimeNs:      uint64(stat.ReadTimeNs),
+			WrittenBytes:    uint64(stat.BytesWritten),
+			WritesCompleted: uint64(stat.WritesCompleted),
+			WriteTimeNs:     uint64(stat.WriteTimeNs),
+		}
+	}
+
+	return out, nil
+}
+
+// qemuBlockDeviceName returns the name of the disk device from the qdev of a QEMU block device.
+// Returns an empty string for block devices that were not added for a disk device.
+func qemuBlockDeviceName(qdev string) string {
+	// The virtio-blk devices are reported using their QOM path, e.g. "/machine/peripheral/dev-lxd_root/virtio-backend".
+	qdev = strings.TrimPrefix(qdev, "/machine/peripheral/")
+	qdev, _, _ = strings.Cut(qdev, "/")
+
+	devName, ok := strings.CutPrefix(qdev, qemuDeviceIDPrefix)
+	if !ok {
+		return ""
+	}
+
+	return filesystem.PathNameDecode(devName)
+}
+
 func (d *qemu) getQemuMemoryMetrics() (metrics.MemoryMetrics, error) {
 	out := metrics.MemoryMetrics{}
 
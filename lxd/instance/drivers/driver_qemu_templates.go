@@ -592,19 +592,27 @@ func qemuControlSocket(opts *qemuControlSocketOpts) []cfgSection {
 }
 
 type qemuConsoleOpts struct {
-	path string
+	path    string
+	logPath string
 }
 
 func qemuConsole(opts *qemuConsoleOpts) []cfgSection {
+	entries := []cfgEntry{
+		{key: "backend", value: "socket"},
+		{key: "path", value: opts.path},
+		{key: "server", value: "on"},
+		{key: "wait", value: "off"},
+	}
+
+	// Keep a copy of the console output, regardless of whether a client is connected.
+	if opts.logPath != "" {
+		entries = append(entries, cfgEntry{key: "logfile", value: opts.logPath}, cfgEntry{key: "logappend", value: "on"})
+	}
+
 	return []cfgSection{{
 		name:    `chardev "console"`,
 		comment: "Console",
-		entries: []cfgEntry{
-			{key: "backend", value: "socket"},
-			{key: "path", value: opts.path},
-			{key: "server", value: "on"},
-			{key: "wait", value: "off"},
-		},
+		entries: entries,
 	}}
 }
 
@@ -785,6 +793,41 @@ func qemuPCIPhysical(opts *qemuPCIPhysicalOpts) []cfgSection {
 	}}
 }
 
+type qemuShmemOpts struct {
+	dev     qemuDevOpts
+	devName string
+	path    string
+	size    int64
+}
+
+func qemuShmem(opts *qemuShmemOpts) []cfgSection {
+	deviceOpts := qemuDevEntriesOpts{
+		dev:     opts.dev,
+		pciName: "ivshmem-plain",
+	}
+
+	memdev := fmt.Sprintf("qemu_shmem-memdev_%s", opts.devName)
+
+	entries := append(qemuDeviceEntries(&deviceOpts), []cfgEntry{
+		{key: "memdev", value: memdev},
+	}...)
+
+	return []cfgSection{{
+		name: fmt.Sprintf(`object "%s"`, memdev),
+		entries: []cfgEntry{
+			{key: "qom-type", value: "memory-backend-file"},
+			{key: "mem-path", value: opts.path},
+			{key: "size", value: fmt.Sprintf("%d", opts.size)},
+			{key: "share", value: "on"},
+		},
+	}, {
+		// Devices use "lxd_" prefix indicating that this is a user named device.
+		name:    fmt.Sprintf(`device "dev-lxd_%s"`, opts.devName),
+		comment: fmt.Sprintf(`Shared memory ("%s" device)`, opts.devName),
+		entries: entries,
+	}}
+}
+
 type qemuGPUDevPhysicalOpts struct {
 	dev         qemuDevOpts
 	devName     string
@@ -0,0 +1,162 @@
+package drivers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// testShutdownGuest simulates a guest going through the shutdown fallback chain on a fake clock.
+type testShutdownGuest struct {
+	now   time.Time
+	steps []string
+
+	// Whether the guest shuts down when asked through ACPI or through the lxd-agent.
+	stopsOnACPI  bool
+	stopsOnAgent bool
+	agentErr     error
+
+	agentAsked bool
+	stopped    bool
+}
+
+func (g *testShutdownGuest) sequence(config map[string]string) *qemuShutdownSequence {
+	return &qemuShutdownSequence{
+		config: config,
+		logger: logger.AddContext(logger.Ctx{}),
+		now:    func() time.Time { return g.now },
+		wait: func(timeout time.Duration) error {
+			g.steps = append(g.steps, "wait "+timeout.String())
+
+			if g.stopsOnACPI || (g.agentAsked && g.stopsOnAgent) {
+				g.now = g.now.Add(time.Second)
+				g.stopped = true
+				return nil
+			}
+
+			g.now = g.now.Add(timeout)
+			return errors.New("Timed out")
+		},
+		stopped: func() bool { return g.stopped },
+		agentShutdown: func() error {
+			g.steps = append(g.steps, "agent")
+			if g.agentErr != nil {
+				return g.agentErr
+			}
+
+			g.agentAsked = true
+			return nil
+		},
+		forceStop: func() error {
+			g.steps = append(g.steps, "force")
+			g.stopped = true
+			return nil
+		},
+	}
+}
+
+func TestQemuShutdownSequence(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       map[string]string
+		timeout      time.Duration
+		stopsOnACPI  bool
+		stopsOnAgent bool
+		agentErr     error
+		wantSteps    []string
+		wantErr      bool
+	}{
+		{
+			name:        "Without ACPI timeout the guest stops on ACPI",
+			config:      map[string]string{},
+			timeout:     time.Minute,
+			stopsOnACPI: true,
+			wantSteps:   []string{"wait 1m0s"},
+		},
+		{
+			name:      "Without ACPI timeout the whole timeout is spent waiting for ACPI",
+			config:    map[string]string{},
+			timeout:   time.Minute,
+			wantSteps: []string{"wait 1m0s"},
+			wantErr:   true,
+		},
+		{
+			name:      "Invalid ACPI timeout is ignored",
+			config:    map[string]string{"boot.shutdown.acpi_timeout": "foo"},
+			timeout:   time.Minute,
+			wantSteps: []string{"wait 1m0s"},
+			wantErr:   true,
+		},
+		{
+			name:        "Guest stops on ACPI before the ACPI timeout",
+			config:      map[string]string{"boot.shutdown.acpi_timeout": "10"},
+			timeout:     time.Minute,
+			stopsOnACPI: true,
+			wantSteps:   []string{"wait 10s"},
+		},
+		{
+			name:         "Guest stops through the agent within the remaining timeout",
+			config:       map[string]string{"boot.shutdown.acpi_timeout": "10"},
+			timeout:      time.Minute,
+			stopsOnAgent: true,
+			wantSteps:    []string{"wait 10s", "agent", "wait 50s"},
+		},
+		{
+			name:         "Guest stops through the agent within the agent timeout",
+			config:       map[string]string{"boot.shutdown.acpi_timeout": "10", "boot.shutdown.agent_timeout": "20"},
+			timeout:      time.Minute,
+			stopsOnAgent: true,
+			wantSteps:    []string{"wait 10s", "agent", "wait 20s"},
+		},
+		{
+			name:      "Guest ignoring the agent is force-stopped after the agent timeout",
+			config:    map[string]string{"boot.shutdown.acpi_timeout": "10", "boot.shutdown.agent_timeout": "20"},
+			timeout:   time.Minute,
+			wantSteps: []string{"wait 10s", "agent", "wait 20s", "force"},
+		},
+		{
+			name:      "Agent timeout is capped to the remaining timeout",
+			config:    map[string]string{"boot.shutdown.acpi_timeout": "10", "boot.shutdown.agent_timeout": "120"},
+			timeout:   time.Minute,
+			wantSteps: []string{"wait 10s", "agent", "wait 50s", "force"},
+		},
+		{
+			name:      "Guest is force-stopped if the agent can't be reached",
+			config:    map[string]string{"boot.shutdown.acpi_timeout": "10"},
+			timeout:   time.Minute,
+			agentErr:  errors.New("Agent not running"),
+			wantSteps: []string{"wait 10s", "agent", "force"},
+		},
+		{
+			name:      "ACPI timeout longer than the timeout leaves no time for the agent",
+			config:    map[string]string{"boot.shutdown.acpi_timeout": "120"},
+			timeout:   time.Minute,
+			wantSteps: []string{"wait 1m0s", "force"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guest := &testShutdownGuest{
+				now:          time.Now(),
+				stopsOnACPI:  tt.stopsOnACPI,
+				stopsOnAgent: tt.stopsOnAgent,
+				agentErr:     tt.agentErr,
+			}
+
+			err := guest.sequence(tt.config).run(tt.timeout)
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected an error")
+			} else if !tt.wantErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(guest.steps, tt.wantSteps) {
+				t.Fatalf("Expected steps %v, got %v", tt.wantSteps, guest.steps)
+			}
+		})
+	}
+}
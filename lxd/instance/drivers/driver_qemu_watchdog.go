@@ -0,0 +1,81 @@
+package drivers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/instance/operationlock"
+	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// qemuAgentWatchdogFired records the VMs for which the agent watchdog action already ran, keyed on instance ID.
+// Entries are removed when the agent reports its status again or the VM stops.
+var qemuAgentWatchdogFired = map[int]bool{}
+var qemuAgentWatchdogFiredMu sync.Mutex
+
+// onAgentSilent runs the configured agent watchdog action if the agent has been silent for longer than allowed.
+func (d *qemu) onAgentSilent(seconds int) {
+	timeout, err := strconv.Atoi(d.expandedConfig["agent.watchdog.timeout"])
+	if err != nil || timeout <= 0 || seconds < timeout {
+		return
+	}
+
+	// The agent can't report its status while the VM is paused or while an operation is in progress on it
+	// (for example a live migration).
+	if d.IsFrozen() || operationlock.Get(d.Project().Name, d.Name()) != nil {
+		return
+	}
+
+	qemuAgentWatchdogFiredMu.Lock()
+	if qemuAgentWatchdogFired[d.id] {
+		qemuAgentWatchdogFiredMu.Unlock()
+		return
+	}
+
+	qemuAgentWatchdogFired[d.id] = true
+	qemuAgentWatchdogFiredMu.Unlock()
+
+	action := d.expandedConfig["agent.watchdog.action"]
+	if action == "" {
+		action = "warn"
+	}
+
+	d.logger.Warn("Instance agent stopped reporting its status", logger.Ctx{"seconds": seconds, "action": action})
+
+	message := fmt.Sprintf("Agent didn't report its status for %d seconds", seconds)
+	if action == "restart" {
+		message += ", restarting instance"
+	}
+
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, d.project.Name, entity.TypeInstance, d.ID(), warningtype.InstanceAgentUnresponsive, message)
+	})
+	if err != nil {
+		d.logger.Warn("Failed to create instance agent warning", logger.Ctx{"err": err})
+	}
+
+	if action == "restart" {
+		err = d.Restart(0)
+		if err != nil {
+			d.logger.Error("Failed to restart instance with unresponsive agent", logger.Ctx{"err": err})
+		}
+	}
+}
+
+// resetAgentWatchdog re-arms the agent watchdog of the VM.
+// The agent watchdog warning is resolved too if resolve is true.
+func (d *qemu) resetAgentWatchdog(resolve bool) {
+	qemuAgentWatchdogFiredMu.Lock()
+	delete(qemuAgentWatchdogFired, d.id)
+	qemuAgentWatchdogFiredMu.Unlock()
+
+	if resolve && d.expandedConfig["agent.watchdog.timeout"] != "" {
+		_ = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(d.state.DB.Cluster, d.project.Name, warningtype.InstanceAgentUnresponsive, entity.TypeInstance, d.ID())
+	}
+}
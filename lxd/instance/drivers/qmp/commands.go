@@ -279,6 +279,16 @@ func (m *Monitor) MigrateSetCapabilities(caps map[string]bool) error {
 	return nil
 }
 
+// MigrateSetParameters sets the parameters used during migration.
+func (m *Monitor) MigrateSetParameters(params map[string]any) error {
+	err := m.run("migrate-set-parameters", params, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Migrate starts a migration stream.
 func (m *Monitor) Migrate(uri string) error {
 	// Query the status.
@@ -313,6 +323,11 @@ func (m *Monitor) MigrateWait(state string) error {
 			return fmt.Errorf("Migrate call failed")
 		}
 
+		// Recovering an interrupted post-copy migration isn't supported, so consider it failed.
+		if resp.Return.Status == "postcopy-paused" {
+			return fmt.Errorf("Migrate call interrupted during post-copy")
+		}
+
 		if resp.Return.Status == state {
 			return nil
 		}
@@ -337,8 +352,25 @@ func (m *Monitor) MigrateContinue(fromState string) error {
 	return nil
 }
 
+// MigrateStartPostcopy switches a running migration stream to post-copy mode.
+// This requires the "postcopy-ram" capability to be enabled on both the source and the target.
+func (m *Monitor) MigrateStartPostcopy() error {
+	return m.run("migrate-start-postcopy", nil, nil)
+}
+
 // MigrateIncoming starts the receiver of a migration stream.
 func (m *Monitor) MigrateIncoming(ctx context.Context, uri string) error {
+	return m.migrateIncoming(ctx, uri, false)
+}
+
+// MigrateIncomingPostcopy starts the receiver of a migration stream that can switch to post-copy mode.
+// The emulation is started as soon as the migration switches to post-copy, the remaining memory is then received
+// while the guest is running.
+func (m *Monitor) MigrateIncomingPostcopy(ctx context.Context, uri string) error {
+	return m.migrateIncoming(ctx, uri, true)
+}
+
+func (m *Monitor) migrateIncoming(ctx context.Context, uri string, postcopy bool) error {
 	// Query the status.
 	args := map[string]string{"uri": uri}
 	err := m.run("migrate-incoming", args, nil)
@@ -347,6 +379,7 @@ func (m *Monitor) MigrateIncoming(ctx context.Context, uri string) error {
 	}
 
 	// Wait until it completes or fails.
+	started := false
 	for {
 		// Prepare the response.
 		var resp struct {
@@ -364,6 +397,19 @@ func (m *Monitor) MigrateIncoming(ctx context.Context, uri string) error {
 			return fmt.Errorf("Migrate incoming call failed")
 		}
 
+		if resp.Return.Status == "postcopy-paused" {
+			return fmt.Errorf("Migrate incoming call interrupted during post-copy")
+		}
+
+		if postcopy && !started && resp.Return.Status == "postcopy-active" {
+			err = m.Start()
+			if err != nil {
+				return err
+			}
+
+			started = true
+		}
+
 		if resp.Return.Status == "completed" {
 			return nil
 		}
@@ -465,6 +511,53 @@ func (m *Monitor) SetMemoryBalloonSizeBytes(sizeBytes int64) error {
 	return m.run("balloon", args, nil)
 }
 
+// MemoryBalloonStats represents the memory statistics reported by the guest through the balloon device.
+type MemoryBalloonStats struct {
+	FreeMemory      int64 `json:"stat-free-memory"`
+	AvailableMemory int64 `json:"stat-available-memory"`
+	TotalMemory     int64 `json:"stat-total-memory"`
+}
+
+// SetMemoryBalloonStatsInterval sets the interval in seconds at which the balloon device at the given QOM path
+// polls the guest for memory statistics. An interval of 0 disables polling.
+func (m *Monitor) SetMemoryBalloonStatsInterval(path string, interval int) error {
+	args := map[string]any{
+		"path":     path,
+		"property": "guest-stats-polling-interval",
+		"value":    interval,
+	}
+
+	return m.run("qom-set", args, nil)
+}
+
+// GetMemoryBalloonStats returns the memory statistics last reported by the guest through the balloon device at
+// the given QOM path. It returns nil if the guest hasn't reported any statistics yet.
+func (m *Monitor) GetMemoryBalloonStats(path string) (*MemoryBalloonStats, error) {
+	// Prepare the response.
+	var resp struct {
+		Return struct {
+			Stats      MemoryBalloonStats `json:"stats"`
+			LastUpdate int64              `json:"last-update"`
+		} `json:"return"`
+	}
+
+	args := map[string]string{
+		"path":     path,
+		"property": "guest-stats",
+	}
+
+	err := m.run("qom-get", args, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Return.LastUpdate == 0 {
+		return nil, nil
+	}
+
+	return &resp.Return.Stats, nil
+}
+
 // AddBlockDevice adds a block device.
 func (m *Monitor) AddBlockDevice(blockDev map[string]any, device map[string]string) error {
 	revert := revert.New()
@@ -563,6 +656,28 @@ func (m *Monitor) AddDevice(device map[string]string) error {
 	return nil
 }
 
+// AddDeviceWithIOThreads adds a new device whose virtqueues are processed by the given I/O threads.
+// With more than one I/O thread, the virtqueues are distributed between them.
+func (m *Monitor) AddDeviceWithIOThreads(device map[string]string, ioThreads []string) error {
+	args := make(map[string]any, len(device)+1)
+	for key, value := range device {
+		args[key] = value
+	}
+
+	if len(ioThreads) == 1 {
+		args["iothread"] = ioThreads[0]
+	} else if len(ioThreads) > 1 {
+		mapping := make([]map[string]string, 0, len(ioThreads))
+		for _, ioThread := range ioThreads {
+			mapping = append(mapping, map[string]string{"iothread": ioThread})
+		}
+
+		args["iothread-vq-mapping"] = mapping
+	}
+
+	return m.run("device_add", args, nil)
+}
+
 // RemoveDevice removes a device.
 func (m *Monitor) RemoveDevice(deviceID string) error {
 	if deviceID != "" {
@@ -700,8 +815,10 @@ func (m *Monitor) QueryPCI() ([]PCIDevice, error) {
 type BlockStats struct {
 	BytesWritten    int `json:"wr_bytes"`
 	WritesCompleted int `json:"wr_operations"`
+	WriteTimeNs     int `json:"wr_total_time_ns"`
 	BytesRead       int `json:"rd_bytes"`
 	ReadsCompleted  int `json:"rd_operations"`
+	ReadTimeNs      int `json:"rd_total_time_ns"`
 }
 
 // GetBlockStats return block device stats.
@@ -746,6 +863,57 @@ func (m *Monitor) AddSecret(id string, secret string) error {
 	return nil
 }
 
+// AddIOThread adds an I/O thread object with the given ID.
+func (m *Monitor) AddIOThread(id string) error {
+	args := map[string]any{
+		"qom-type": "iothread",
+		"id":       id,
+	}
+
+	err := m.run("object-add", &args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed adding I/O thread: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveIOThread removes the I/O thread object with the given ID.
+func (m *Monitor) RemoveIOThread(id string) error {
+	args := map[string]string{
+		"id": id,
+	}
+
+	err := m.run("object-del", &args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed removing I/O thread: %w", err)
+	}
+
+	return nil
+}
+
+// GetIOThreads returns the host thread ID of each I/O thread (indexed by I/O thread ID).
+func (m *Monitor) GetIOThreads() (map[string]int, error) {
+	var resp struct {
+		Return []struct {
+			ID       string `json:"id"`
+			ThreadID int    `json:"thread-id"`
+		} `json:"return"`
+	}
+
+	err := m.run("query-iothreads", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed querying I/O threads: %w", err)
+	}
+
+	ioThreads := make(map[string]int, len(resp.Return))
+	for _, ioThread := range resp.Return {
+		ioThreads[ioThread.ID] = ioThread.ThreadID
+	}
+
+	return ioThreads, nil
+}
+
 // AMDSEVCapabilities represents the SEV capabilities of QEMU.
 type AMDSEVCapabilities struct {
 	PDH             string `json:"pdh"`               // Platform Diffie-Hellman key (base64-encoded)
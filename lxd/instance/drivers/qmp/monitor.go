@@ -22,6 +22,13 @@ var RingbufSize = 16
 // EventAgentStarted is the event sent once the lxd-agent has started.
 var EventAgentStarted = "LXD-AGENT-STARTED"
 
+// EventAgentSilent is the event sent while a started lxd-agent hasn't reported its status for a while.
+// The event data contains the number of seconds since the agent last reported its status.
+var EventAgentSilent = "LXD-AGENT-SILENT"
+
+// AgentSilentThreshold is how long a started lxd-agent can go without reporting its status before being considered silent.
+var AgentSilentThreshold = 20 * time.Second
+
 // EventVMShutdown is the event sent when VM guest shuts down.
 var EventVMShutdown = "SHUTDOWN"
 
@@ -34,6 +41,8 @@ type Monitor struct {
 	qmp  *qmp.SocketMonitor
 
 	agentStarted      bool
+	agentLastSeen     time.Time
+	agentSilent       bool
 	agentStartedMu    sync.Mutex
 	disconnected      bool
 	chDisconnect      chan struct{}
@@ -63,23 +72,44 @@ func (m *Monitor) start() error {
 			return
 		}
 
+		m.agentStartedMu.Lock()
+		defer m.agentStartedMu.Unlock()
+
 		// Extract the last entry.
 		entries := strings.Split(resp.Return, "\n")
 		if len(entries) > 1 {
 			status := entries[len(entries)-2]
 
-			m.agentStartedMu.Lock()
+			if status == "STOPPED" {
+				m.agentStarted = false
+				m.agentSilent = false
+				return
+			}
+
+			// Any other status is written periodically by a running agent.
+			m.agentLastSeen = time.Now()
+
 			if status == "STARTED" {
-				if !m.agentStarted && m.eventHandler != nil {
+				// Also report the agent as started when it recovers from being silent.
+				if (!m.agentStarted || m.agentSilent) && m.eventHandler != nil {
 					go m.eventHandler(EventAgentStarted, nil)
 				}
 
 				m.agentStarted = true
-			} else if status == "STOPPED" {
-				m.agentStarted = false
+				m.agentSilent = false
 			}
 
-			m.agentStartedMu.Unlock()
+			return
+		}
+
+		// Nothing was written since the last read, check whether the agent went silent.
+		silence := time.Since(m.agentLastSeen)
+		if m.agentStarted && silence > AgentSilentThreshold {
+			m.agentSilent = true
+
+			if m.eventHandler != nil {
+				go m.eventHandler(EventAgentSilent, map[string]any{"seconds": int(silence.Seconds())})
+			}
 		}
 	}
 
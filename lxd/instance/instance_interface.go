@@ -78,7 +78,7 @@ type Instance interface {
 	Start(stateful bool) error
 	Stop(stateful bool) error
 	Restart(timeout time.Duration) error
-	Rebuild(img *api.Image, op *operations.Operation) error
+	Rebuild(img *api.Image, preservePaths []string, op *operations.Operation) error
 	Unfreeze() error
 	RegisterDevices()
 
@@ -168,6 +168,7 @@ type Instance interface {
 	DeferTemplateApply(trigger TemplateTrigger) error
 
 	Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error)
+	DiskIOStats() (map[string]api.StorageVolumeStateIO, error)
 }
 
 // Container interface is for container specific functions.
@@ -189,9 +190,23 @@ type VM interface {
 
 	AgentCertificate() *x509.Certificate
 
+	// Text console multiplexing.
+	ConsoleAttach(force bool, requestor *api.EventLifecycleRequestor) (*os.File, chan error, error)
+	ConsoleSessions() []api.InstanceConsoleSession
+
+	// Text console history.
+	ConsoleHistory() ([]byte, error)
+	ConsoleHistoryClear() error
+
 	// UEFI vars handling.
 	UEFIVars() (*api.InstanceUEFIVars, error)
 	UEFIVarsUpdate(newUEFIVarsSet api.InstanceUEFIVars) error
+
+	// vCPU pinning.
+	CPUAffinity() ([]string, error)
+
+	// Automatic memory ballooning.
+	UpdateMemoryBalloon() error
 }
 
 // CriuMigrationArgs arguments for CRIU migration.
@@ -226,6 +241,7 @@ type MigrateArgs struct {
 	Live                  bool
 	Disconnect            func()
 	ClusterMoveSourceName string // Will be empty if not a cluster move, othwise indicates the source instance.
+	BandwidthLimit        int64  // Maximum transfer rate in bytes per second (0 for no limit).
 }
 
 // MigrateSendArgs represent arguments for instance migration send.
@@ -233,6 +249,7 @@ type MigrateSendArgs struct {
 	MigrateArgs
 
 	AllowInconsistent bool
+	Retry             bool // Whether the migration will be retried if it fails.
 }
 
 // MigrateReceiveArgs represent arguments for instance migration receive.
@@ -627,6 +627,16 @@ func SuitableArchitectures(ctx context.Context, s *state.State, tx *db.ClusterTx
 				if err != nil {
 					return nil, err
 				}
+			} else if req.Source.Protocol == "oci" {
+				// Remote OCI registry.
+				remote, err = lxd.ConnectOCI(req.Source.Server, &lxd.ConnectionArgs{
+					TLSServerCert: req.Source.Certificate,
+					UserAgent:     version.UserAgent,
+					Proxy:         s.Proxy,
+				})
+				if err != nil {
+					return nil, err
+				}
 			} else {
 				return nil, api.StatusErrorf(http.StatusBadRequest, "Unsupported remote image server protocol %q", req.Source.Protocol)
 			}
@@ -1004,13 +1014,19 @@ func CreateInternal(s *state.State, args db.InstanceArgs, clearLogDir bool) (Ins
 
 // NextSnapshotName finds the next snapshot for an instance.
 func NextSnapshotName(s *state.State, inst Instance, defaultPattern string) (string, error) {
-	var err error
-
 	pattern := inst.ExpandedConfig()["snapshots.pattern"]
 	if pattern == "" {
 		pattern = defaultPattern
 	}
 
+	return NextSnapshotNameFromPattern(s, inst, pattern)
+}
+
+// NextSnapshotNameFromPattern finds the next snapshot for an instance using the given pattern,
+// ignoring the snapshots.pattern setting of the instance.
+func NextSnapshotNameFromPattern(s *state.State, inst Instance, pattern string) (string, error) {
+	var err error
+
 	pattern, err = shared.RenderTemplate(pattern, pongo2.Context{
 		"creation_date": time.Now(),
 	})
@@ -1136,6 +1152,22 @@ func IsSameLogicalInstance(inst Instance, dbInst *db.InstanceArgs) bool {
 	return false
 }
 
+// CloudInitData returns the cloud-init data of the given kind (`user-data`, `vendor-data` or `network-config`)
+// from the instance config. The `cloud-init.*` key takes precedence over the legacy `user.*` key.
+// An empty cloud-config is returned for user-data and vendor-data if none is set.
+func CloudInitData(config map[string]string, kind string) string {
+	value, ok := config["cloud-init."+kind]
+	if !ok {
+		value = config["user."+kind]
+	}
+
+	if value == "" && kind != "network-config" {
+		return "#cloud-config\n{}"
+	}
+
+	return value
+}
+
 // SnapshotToProtobuf converts a snapshot record to a migration snapshot record.
 func SnapshotToProtobuf(snap *api.InstanceSnapshot) *migration.Snapshot {
 	config := make([]*migration.Config, 0, len(snap.Config))
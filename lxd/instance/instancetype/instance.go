@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/validate"
@@ -27,6 +28,9 @@ const (
 // ConfigVolatilePrefix indicates the prefix used for volatile config keys.
 const ConfigVolatilePrefix = "volatile."
 
+// ConfigVolatileDeviceGroupPrefix indicates the prefix used for the keys tracking the device groups attached to an instance.
+const ConfigVolatileDeviceGroupPrefix = "volatile.device_group."
+
 // IsRootDiskDevice returns true if the given device representation is configured as root disk for
 // an instance. It typically get passed a specific entry of api.Instance.Devices.
 func IsRootDiskDevice(device map[string]string) bool {
@@ -185,8 +189,9 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//   - `auto` *(default)*: The system will automatically decide the best evacuation method based on the
 	//      instance's type and configured devices:
 	//     + If any device is not suitable for migration, the instance will not be migrated (only stopped).
-	//     + Live migration will be used only for virtual machines with the `migration.stateful` setting
-	//       enabled and for which all its devices can be migrated as well.
+	//     + Live migration will be used only for instances with the `migration.stateful` setting
+	//       enabled and for which all its devices can be migrated as well. Containers additionally
+	//       require CRIU to be available.
 	//   - `live-migrate`: Instances are live-migrated to another node. This means the instance remains running
 	//      and operational during the migration process, ensuring minimal disruption.
 	//   - `migrate`: In this mode, instances are migrated to another node in the cluster. The migration
@@ -276,6 +281,16 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 
 	// Caller is responsible for full validation of any raw.* value.
 
+	// lxdmeta:generate(entities=instance; group=migration; key=migration.stateful)
+	// For virtual machines, enabling this option prevents the use of some features that are incompatible with it.
+	// For containers, this option only controls live migration, which relies on CRIU being available on both the source and the target server.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false` or value from profiles or `instances.migration.stateful` (if set)
+	//  liveupdate: no
+	//  shortdesc: Whether to allow for live migration, stateful stop/start and snapshots
+	"migration.stateful": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=raw; key=raw.apparmor)
 	// The specified entries are appended to the generated profile.
 	// ---
@@ -293,6 +308,20 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Raw idmap configuration
 	"raw.idmap": validate.IsAny,
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.core_scheduling)
+	// When enabled, the processes of a container or the vCPU threads of a virtual machine are placed in a
+	// core scheduling domain of their own, so that they never share a physical core with the tasks of other
+	// instances or of the host. This mitigates the leakage of data between SMT siblings.
+	//
+	// The core scheduling cookie of the instance is reported in its state.
+	// Setting this option explicitly to `true` prevents the instance from starting if the kernel doesn't support core scheduling.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true` (if supported by the kernel)
+	//  liveupdate: no
+	//  shortdesc: Whether to isolate the instance in its own core scheduling domain
+	"security.core_scheduling": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=security; key=security.devlxd)
 	// See {ref}`dev-lxd` for more information.
 	// ---
@@ -302,6 +331,17 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Whether `/dev/lxd` is present in the instance
 	"security.devlxd": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.devlxd.token)
+	// When enabled, workloads in the instance can request a short-lived token through `/dev/lxd` that grants
+	// read-only access to the instance over the main LXD API.
+	// See {ref}`dev-lxd` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Controls the availability of the `/1.0/token` API over `devlxd`
+	"security.devlxd.token": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=security; key=security.protection.delete)
 	//
 	// ---
@@ -311,6 +351,40 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Prevents the instance from being deleted
 	"security.protection.delete": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=rebuild.image_refresh)
+	// Possible values are `none`, `warn` and `rebuild`.
+	// When set to `warn` or `rebuild` and the cached image that the instance was created from is refreshed, LXD records the fingerprint of the newer image in {config:option}`instance-volatile:volatile.base_image.latest` and raises a warning for the instance.
+	// When set to `rebuild`, LXD also rebuilds the instance from the newer image once it is stopped.
+	// See {ref}`instances-rebuild-image-refresh` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `none`
+	//  liveupdate: yes
+	//  shortdesc: What to do when the image of the instance is refreshed
+	"rebuild.image_refresh": validate.Optional(validate.IsOneOf("none", "warn", "rebuild")),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=storage.trim.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`) or a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`).
+	// The file systems of running virtual machines are trimmed by running `fstrim` through the LXD agent, and the file systems of running containers are trimmed from the host on block-backed storage pools.
+	// This overrides the `storage.trim.schedule` option of the storage pool of the instance's root disk.
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: yes
+	//  shortdesc: Schedule for discarding unused blocks of the instance's file systems
+	"storage.trim.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
+
+	// lxdmeta:generate(entities=instance; group=security; key=security.protection.host_shutdown)
+	// While an instance with this option set is running, LXD holds a systemd inhibitor lock that blocks
+	// shutting down or rebooting the host.
+	// Set {config:option}`server-core:core.host_shutdown_protection_override` on the LXD server to release the lock.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Prevents the host from shutting down while the instance is running
+	"security.protection.host_shutdown": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.schedule)
 	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic snapshots.
 	//
@@ -370,6 +444,13 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Hash of the base image
 	"volatile.base_image": validate.IsAny,
 
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.base_image.latest)
+	// The hash of the image that the base image of the instance was refreshed to (empty if the instance is up to date).
+	// ---
+	//  type: string
+	//  shortdesc: Hash of the newer base image
+	"volatile.base_image.latest": validate.IsAny,
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.cloud_init.instance-id)
 	//
 	// ---
@@ -377,6 +458,14 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: `instance-id` (UUID) exposed to `cloud-init`
 	"volatile.cloud-init.instance-id": validate.Optional(validate.IsUUID),
 
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.devlxd.token_nonce)
+	// Tokens issued over `devlxd` are only valid while this value is unchanged.
+	// Unset it to revoke all the tokens issued for the instance.
+	// ---
+	//  type: string
+	//  shortdesc: Nonce that `devlxd` tokens are bound to
+	"volatile.devlxd.token_nonce": validate.Optional(validate.IsUUID),
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.evacuate.origin)
 	// The cluster member that the instance lived on before evacuation.
 	// ---
@@ -705,6 +794,18 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: The size of the idmap to use
 	"security.idmap.size": validate.Optional(validate.IsUint32),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.kvm)
+	// When enabled, the `/dev/kvm`, `/dev/vhost-net` and `/dev/vhost-vsock` devices of the host are passed
+	// to the container, allowing it to run virtual machines.
+	// See {ref}`instances-security-kvm` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Whether to pass the KVM devices to the container
+	"security.kvm": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=security; key=security.nesting)
 	//
 	// ---
@@ -905,6 +1006,19 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 
 // InstanceConfigKeysVM is a map of config key to validator. (keys applying to VM only).
 var InstanceConfigKeysVM = map[string]func(value string) error{
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.cpu.rebalance)
+	// When the host CPUs or NUMA nodes go online or offline, or other instances start or stop, LXD recomputes the
+	// load-balanced vCPU pinning of the running instances and applies it live.
+	// If this option is set to `false`, the instance keeps its current vCPU pinning as long as all the CPUs it is pinned to are still available.
+	// This option has no effect if {config:option}`instance-resource-limits:limits.cpu` specifies a range or list of CPUs.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to rebalance the vCPU pinning of the running instance
+	"limits.cpu.rebalance": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.memory.hugepages)
 	// If this option is set to `false`, regular system memory is used.
 	// ---
@@ -915,15 +1029,128 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to back the instance using huge pages
 	"limits.memory.hugepages": validate.Optional(validate.IsBool),
 
-	// lxdmeta:generate(entities=instance; group=migration; key=migration.stateful)
-	// Enabling this option prevents the use of some features that are incompatible with it.
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.memory.balloon.auto)
+	// When enabled, LXD periodically inflates the memory balloon of the running VM to reclaim memory that the guest doesn't use, and deflates it again when the guest runs low on memory.
+	// See {ref}`instances-limit-memory-balloon` for more information.
 	// ---
 	//  type: bool
-	//  defaultdesc: `false` or value from profiles or `instances.migration.stateful` (if set)
-	//  liveupdate: no
+	//  defaultdesc: `false`
+	//  liveupdate: yes
 	//  condition: virtual machine
-	//  shortdesc: Whether to allow for stateful stop/start and snapshots
-	"migration.stateful": validate.Optional(validate.IsBool),
+	//  shortdesc: Whether to automatically reclaim unused memory from the instance
+	"limits.memory.balloon.auto": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.memory.balloon.floor)
+	// Percentage of {config:option}`instance-resource-limits:limits.memory` or a fixed value in bytes.
+	// The automatic memory balloon never shrinks the memory of the instance below this size.
+	// ---
+	//  type: string
+	//  defaultdesc: `50%`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Minimum memory size when reclaiming unused memory
+	"limits.memory.balloon.floor": func(value string) error {
+		if value == "" {
+			return nil
+		}
+
+		if strings.HasSuffix(value, "%") {
+			num, err := strconv.ParseInt(strings.TrimSuffix(value, "%"), 10, 64)
+			if err != nil {
+				return err
+			}
+
+			if num <= 0 || num > 100 {
+				return errors.New("Memory floor must be between 1% and 100%")
+			}
+
+			return nil
+		}
+
+		return validate.IsSize(value)
+	},
+
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.memory.balloon.aggressiveness)
+	// Specify the percentage (between 1 and 100) of the unused guest memory that is reclaimed each minute.
+	// ---
+	//  type: integer
+	//  defaultdesc: `25`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: How quickly unused memory is reclaimed
+	"limits.memory.balloon.aggressiveness": validate.Optional(validate.IsInRange(1, 100)),
+
+	// lxdmeta:generate(entities=instance; group=migration; key=migration.auto_converge)
+	// When enabled, QEMU throttles the vCPUs of the instance during live migration if its memory is written to faster than it can be transferred.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to throttle the instance to help live migration converge
+	"migration.auto_converge": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=migration; key=migration.xbzrle)
+	// When enabled, QEMU only sends the changes to memory pages that are transferred again during live migration (XBZRLE compression).
+	// This reduces the amount of data sent for instances that repeatedly write to the same pages, at the cost of CPU time and memory on the source.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to send memory page changes during live migration
+	"migration.xbzrle": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=migration; key=migration.xbzrle.cache_size)
+	// The size of the cache of previously sent memory pages used by {config:option}`instance-migration:migration.xbzrle`.
+	// A larger cache allows sending the changes of more pages.
+	//
+	// The value must be a power of two and is specified with a size suffix, for example, `512MiB`.
+	// ---
+	//  type: string
+	//  defaultdesc: QEMU default (`64MiB`)
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Size of the XBZRLE page cache used during live migration
+	"migration.xbzrle.cache_size": validate.Optional(validate.IsSize),
+
+	// lxdmeta:generate(entities=instance; group=migration; key=migration.mode)
+	// Possible values are `precopy` and `postcopy`.
+	// With `precopy`, the memory of the instance is fully transferred before it is resumed on the target, which can fail to converge for guests that write to their memory quickly.
+	// With `postcopy`, the instance is resumed on the target as soon as possible and the remaining memory is then transferred on demand.
+	// If the migration fails after the instance was resumed on the target, the instance is stopped and its state is lost.
+	//
+	// If the target doesn't support post-copy migration, `precopy` is used.
+	// ---
+	//  type: string
+	//  defaultdesc: `precopy`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Memory transfer mode used for live migration
+	"migration.mode": validate.Optional(validate.IsOneOf("precopy", "postcopy")),
+
+	// lxdmeta:generate(entities=instance; group=migration; key=migration.retries)
+	// Live migrations between cluster members that fail while the instance is still running on the source
+	// are retried with an exponential backoff, starting at 5 seconds.
+	// The storage volumes transferred by the failed attempt are refreshed rather than transferred again.
+	// ---
+	//  type: integer
+	//  defaultdesc: `3`
+	//  liveupdate: yes
+	//  shortdesc: Number of times a failed live migration between cluster members is retried
+	"migration.retries": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.stateful.incremental)
+	// When enabled, stateful snapshots store the memory state of the instance in chunks that are shared between snapshots.
+	// Only the chunks that changed since the previous stateful snapshot are written, which makes frequent stateful snapshots of instances with a lot of memory much faster.
+	// The chunks are kept in the state volume of the instance, so {config:option}`device-disk-device-conf:size.state` must leave room for them.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to store the memory state of stateful snapshots incrementally
+	"snapshots.stateful.incremental": validate.Optional(validate.IsBool),
 
 	// Caller is responsible for full validation of any raw.* value.
 
@@ -1022,6 +1249,16 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  liveupdate: no
 	//  shortdesc: Free-form user key/value storage
 
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=agent.mounts)
+	// When enabled, the `lxd-agent` mounts the file system disk devices inside the virtual machine, both on boot and when they are attached to or detached from the running instance.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to mount disk devices inside the guest
+	"agent.mounts": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=miscellaneous; key=agent.nic_config)
 	// For containers, the name and MTU of the default network interfaces is used for the instance devices.
 	// For virtual machines, set this option to `true` to set the name and MTU of the default network interfaces to be the same as the instance devices.
@@ -1033,6 +1270,37 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to use the name and MTU of the default network interfaces
 	"agent.nic_config": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=console.history_size)
+	// The text console output of the virtual machine is kept even when no client is attached to the console, and can be retrieved with `lxc console --show-log`.
+	// When the instance starts, only the end of the output of the previous runs is kept.
+	// ---
+	//  type: string
+	//  defaultdesc: `64KiB`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Amount of text console output to keep
+	"console.history_size": validate.Optional(validate.IsSize),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=agent.watchdog.timeout)
+	// When set, LXD monitors the status that the `lxd-agent` periodically reports and runs {config:option}`instance-miscellaneous:agent.watchdog.action` if the agent has been started but stops reporting for the given number of seconds.
+	// The agent reports its status every five seconds, and the check happens every ten seconds.
+	// ---
+	//  type: integer
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Number of seconds the agent can be silent before the watchdog action is run
+	"agent.watchdog.timeout": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=agent.watchdog.action)
+	// Possible values are `warn` (create a warning for the instance) and `restart` (create a warning and force-restart the instance).
+	// ---
+	//  type: string
+	//  defaultdesc: `warn`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: What to do when the agent watchdog fires
+	"agent.watchdog.action": validate.Optional(validate.IsOneOf("warn", "restart")),
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.apply_nvram)
 	//
 	// ---
@@ -1047,6 +1315,13 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Instance `vsock ID` used as of last start
 	"volatile.vsock_id": validate.Optional(validate.IsInt64),
 
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.memory.balloon_target)
+	//
+	// ---
+	//  type: integer
+	//  shortdesc: Memory size in bytes requested by the automatic memory balloon
+	"volatile.memory.balloon_target": validate.Optional(validate.IsInt64),
+
 	// lxdmeta:generate(entities=instance; group=boot; key=boot.debug_edk2)
 	// The instance should use a debug version of the `edk2`.
 	// A log file can be found in `$LXD_DIR/logs/<instance_name>/edk2.log`.
@@ -1054,6 +1329,30 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  type: bool
 	//  shortdesc: Enable debug version of the `edk2`
 	"boot.debug_edk2": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.shutdown.acpi_timeout)
+	// Number of seconds to wait for the guest to respond to the ACPI powerdown request.
+	// When set, LXD then asks the `lxd-agent` to run `shutdown -h now` inside the guest
+	// and force-stops the instance if it still hasn't shut down after {config:option}`instance-boot:boot.shutdown.agent_timeout`.
+	// When unset, the whole shutdown timeout is spent waiting for the ACPI powerdown.
+	// ---
+	//  type: integer
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: How long to wait for the ACPI powerdown before using the agent
+	"boot.shutdown.acpi_timeout": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.shutdown.agent_timeout)
+	// Number of seconds to wait for the guest to shut down after the `lxd-agent` was asked to shut it down.
+	// The instance is force-stopped afterwards.
+	// Only used if {config:option}`instance-boot:boot.shutdown.acpi_timeout` is set.
+	// ---
+	//  type: integer
+	//  defaultdesc: remaining shutdown timeout
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: How long to wait for the agent-initiated shutdown before force-stopping
+	"boot.shutdown.agent_timeout": validate.Optional(validate.IsUint32),
 }
 
 // ConfigKeyChecker returns a function that will check whether or not
@@ -1198,6 +1497,15 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 			return validate.IsAny, nil
 		}
 
+		// lxdmeta:generate(entities=instance; group=volatile; key=volatile.device_group.<name>.devices)
+		// Comma-separated list of the devices that were added to the instance when attaching the device group.
+		// ---
+		//  type: string
+		//  shortdesc: Devices added by a device group
+		if strings.HasPrefix(key, ConfigVolatileDeviceGroupPrefix) && strings.HasSuffix(key, ".devices") {
+			return validate.IsAny, nil
+		}
+
 		if strings.HasSuffix(key, ".driver") {
 			return validate.IsAny, nil
 		}
@@ -1211,6 +1519,35 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 		}
 	}
 
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.schedules.<name>.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`) or a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`).
+	// The snapshots taken by the schedule are named `<name>-%d`.
+	//
+	// See {ref}`instance-options-snapshots-schedules` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: Schedule of a named snapshot schedule
+
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.schedules.<name>.keep)
+	// When a snapshot is taken by the schedule, the oldest snapshots of the schedule in excess of this number are deleted.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0` (keep all)
+	//  liveupdate: no
+	//  shortdesc: Number of snapshots of a named snapshot schedule to keep
+
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.schedules.<name>.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  defaultdesc: same as `snapshots.expiry`
+	//  liveupdate: no
+	//  shortdesc: When the snapshots of a named snapshot schedule are to be deleted
+	if strings.HasPrefix(key, util.SnapshotSchedulesPrefix) {
+		return util.SnapshotScheduleKeyChecker(key, []string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"})
+	}
+
 	if strings.HasPrefix(key, "environment.") {
 		return validate.IsAny, nil
 	}
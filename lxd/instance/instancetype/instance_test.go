@@ -0,0 +1,24 @@
+package instancetype
+
+import (
+	"testing"
+)
+
+func TestConfigKeyCheckerMigrationStateful(t *testing.T) {
+	for _, instanceType := range []Type{Any, Container, VM} {
+		checker, err := ConfigKeyChecker("migration.stateful", instanceType)
+		if err != nil {
+			t.Fatalf("migration.stateful isn't a valid key for instance type %q: %v", instanceType, err)
+		}
+
+		err = checker("true")
+		if err != nil {
+			t.Errorf("Unexpected error validating migration.stateful for instance type %q: %v", instanceType, err)
+		}
+
+		err = checker("foo")
+		if err == nil {
+			t.Errorf("Expected an error validating an invalid migration.stateful value for instance type %q", instanceType)
+		}
+	}
+}
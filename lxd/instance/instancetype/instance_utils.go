@@ -25,7 +25,7 @@ func ExpandInstanceConfig(globalConfig map[string]any, config map[string]string,
 	// Apply all the profiles.
 	profileConfigs := make([]map[string]string, len(profiles))
 	for i, profile := range profiles {
-		profileConfigs[i] = profile.Config
+		profileConfigs[i] = profile.EffectiveConfig()
 	}
 
 	for i := range profileConfigs {
@@ -49,7 +49,7 @@ func ExpandInstanceDevices(devices deviceConfig.Devices, profiles []api.Profile)
 	// Apply all the profiles.
 	profileDevices := make([]deviceConfig.Devices, len(profiles))
 	for i, profile := range profiles {
-		profileDevices[i] = deviceConfig.NewDevices(profile.Devices)
+		profileDevices[i] = deviceConfig.NewDevices(profile.EffectiveDevices())
 	}
 
 	for i := range profileDevices {
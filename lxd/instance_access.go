@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/identity"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instanceAccessTokenDefaultTTL is the validity of instance access tokens issued without an explicit expiry.
+const instanceAccessTokenDefaultTTL = time.Hour
+
+// swagger:operation POST /1.0/instances/{name}/access-tokens instances instance_access_tokens_post
+//
+//	Issue a temporary access token
+//
+//	Issues a short-lived token granting exec or console access to this instance only.
+//	The token is used as a bearer token against the API and can't be revoked before it expires.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: token
+//	    description: Token request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceAccessTokensPost"
+//	responses:
+//	  "200":
+//	    description: Access token
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceAccessToken"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceAccessTokensPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	req := api.InstanceAccessTokensPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var entitlement auth.Entitlement
+	switch req.Access {
+	case api.InstanceAccessExec:
+		if req.ReadOnly {
+			return response.BadRequest(fmt.Errorf("Read-only access is only supported for the console"))
+		}
+
+		entitlement = auth.EntitlementCanExec
+	case api.InstanceAccessConsole:
+		entitlement = auth.EntitlementCanAccessConsole
+	default:
+		return response.BadRequest(fmt.Errorf("Invalid access %q, must be one of: %s, %s", req.Access, api.InstanceAccessExec, api.InstanceAccessConsole))
+	}
+
+	ttl := instanceAccessTokenDefaultTTL
+	if req.Expiry != "" {
+		ttl, err = time.ParseDuration(req.Expiry)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid expiry: %w", err))
+		}
+
+		if ttl <= 0 || ttl > identity.InstanceAccessTokenMaxTTL {
+			return response.BadRequest(fmt.Errorf("Expiry must be positive and at most %s", identity.InstanceAccessTokenMaxTTL))
+		}
+	}
+
+	// Only the access the requestor has on the instance can be delegated.
+	err = s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(projectName, name), entitlement)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	key := identity.InstanceAccessTokenKey(s.Endpoints.NetworkCert().PrivateKey())
+	token, expiresAt, err := identity.NewInstanceAccessToken(key, inst.Project().Name, inst.Name(), req.Access, req.ReadOnly, ttl)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+	logger.Info("Issued instance access token", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "access": req.Access, "readOnly": req.ReadOnly, "expiresAt": expiresAt, "requestor": requestor.Username})
+
+	return response.SyncResponse(true, api.InstanceAccessToken{
+		Token:     token,
+		Access:    req.Access,
+		ReadOnly:  req.ReadOnly,
+		ExpiresAt: expiresAt,
+	})
+}
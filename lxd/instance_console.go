@@ -19,6 +19,7 @@ import (
 
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/identity"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/operations"
@@ -61,6 +62,15 @@ type consoleWs struct {
 
 	// channel type (either console or vga)
 	protocol string
+
+	// whether to disconnect the other clients attached to a VM text console
+	force bool
+
+	// whether input from the client is ignored
+	readOnly bool
+
+	// requestor attaching to the console
+	requestor *api.EventLifecycleRequestor
 }
 
 // Metadata returns a map of metadata.
@@ -208,7 +218,18 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 	<-s.allConnected
 
 	// Get console from instance.
-	console, consoleDisconnectCh, err := s.instance.Console(s.protocol)
+	var console *os.File
+	var consoleDisconnectCh chan error
+	var err error
+
+	vm, isVM := s.instance.(instance.VM)
+	if isVM {
+		// The text console of VMs is shared between clients.
+		console, consoleDisconnectCh, err = vm.ConsoleAttach(s.force, s.requestor)
+	} else {
+		console, consoleDisconnectCh, err = s.instance.Console(s.protocol)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -216,7 +237,7 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 	defer func() { _ = console.Close() }()
 
 	// Detect size of window and set it into console.
-	if s.width > 0 && s.height > 0 {
+	if s.width > 0 && s.height > 0 && !s.readOnly {
 		_ = shared.SetSize(int(console.Fd()), s.width, s.height)
 	}
 
@@ -256,7 +277,7 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 				continue
 			}
 
-			if command.Command == "window-resize" {
+			if command.Command == "window-resize" && !s.readOnly {
 				winchWidth, err := strconv.Atoi(command.Args["width"])
 				if err != nil {
 					logger.Debugf("Unable to extract window width: %s", err)
@@ -291,7 +312,14 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 		defer l.Debug("Finished mirroring websocket to console")
 
 		l.Debug("Started mirroring websocket")
-		readDone, writeDone := ws.Mirror(conn, console)
+		var readDone, writeDone chan error
+		if s.readOnly {
+			// Keep consuming the client messages to detect disconnection, but don't pass them to the console.
+			readDone = ws.MirrorRead(conn, console)
+			writeDone = ws.MirrorWrite(conn, io.Discard)
+		} else {
+			readDone, writeDone = ws.Mirror(conn, console)
+		}
 
 		<-readDone
 		l.Debug("Finished mirroring console to websocket")
@@ -321,11 +349,14 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 
 	// Write a reset escape sequence to the console to cancel any ongoing reads to the handle
 	// and then close it. This ordering is important, close the console before closing the
-	// websocket to ensure console doesn't get stuck reading.
-	_, err = console.Write([]byte("\x1bc"))
-	if err != nil {
-		_ = console.Close()
-		return err
+	// websocket to ensure console doesn't get stuck reading. This is skipped for VMs as the
+	// text console may still be used by other clients.
+	if !isVM {
+		_, err = console.Write([]byte("\x1bc"))
+		if err != nil {
+			_ = console.Close()
+			return err
+		}
 	}
 
 	err = console.Close()
@@ -446,6 +477,20 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Requests authenticated with a read-only instance access token can only watch the console.
+	requestor := request.CreateRequestor(r)
+	if requestor.Protocol == api.AuthenticationMethodInstanceAccess {
+		claims, err := identity.InstanceAccessTokenUsername(requestor.Username)
+		if err != nil {
+			return response.Forbidden(err)
+		}
+
+		err = claims.CheckConsolePost(&post)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// Forward the request if the container is remote.
 	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, name, r, instanceType)
 	if err != nil {
@@ -485,6 +530,18 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("VGA console is only supported by virtual machines"))
 	}
 
+	if post.Force && (post.Type != instance.ConsoleTypeConsole || inst.Type() != instancetype.VM) {
+		return response.BadRequest(fmt.Errorf("Forcing console attachment is only supported for the text console of virtual machines"))
+	}
+
+	if post.ReadOnly && post.Type != instance.ConsoleTypeConsole {
+		return response.BadRequest(fmt.Errorf("Read-only attachment is only supported for the text console"))
+	}
+
+	if post.ReadOnly && post.Force {
+		return response.BadRequest(fmt.Errorf("Read-only attachment can't disconnect the other clients"))
+	}
+
 	if !inst.IsRunning() {
 		return response.BadRequest(fmt.Errorf("Instance is not running"))
 	}
@@ -512,6 +569,9 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 	ws.width = post.Width
 	ws.height = post.Height
 	ws.protocol = post.Type
+	ws.force = post.Force
+	ws.readOnly = post.ReadOnly
+	ws.requestor = request.CreateRequestor(r)
 
 	resources := map[string][]api.URL{}
 	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", ws.instance.Name())}
@@ -522,17 +582,106 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, operationtype.ConsoleShow, resources, ws.Metadata(), ws.Do, nil, ws.Connect, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
 }
 
+// swagger:operation GET /1.0/instances/{name}/console/sessions instances instance_console_sessions_get
+//
+//	Get the console sessions
+//
+//	Returns the clients attached to the text console of a virtual machine.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Console sessions
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of console sessions
+//	          items:
+//	            $ref: "#/definitions/InstanceConsoleSession"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceConsoleSessionsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Forward the request if the instance is remote.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	vm, ok := inst.(instance.VM)
+	if !ok {
+		return response.BadRequest(fmt.Errorf("Console sessions are only supported by virtual machines"))
+	}
+
+	return response.SyncResponse(true, vm.ConsoleSessions())
+}
+
 // swagger:operation GET /1.0/instances/{name}/console instances instance_console_get
 //
 //	Get console log
 //
 //	Gets the console log for the instance.
+//	For virtual machines, this is the end of the text console output.
 //
 //	---
 //	produces:
@@ -543,6 +692,11 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: type
+//	    description: Console log type
+//	    type: string
+//	    example: history
 //	responses:
 //	  "200":
 //	     description: Raw console log
@@ -587,8 +741,9 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 		return resp
 	}
 
-	if !liblxc.RuntimeLiblxcVersionAtLeast(liblxc.Version(), 3, 0, 0) {
-		return response.BadRequest(fmt.Errorf("Querying the console buffer requires liblxc >= 3.0"))
+	logType := request.QueryParam(r, "type")
+	if logType != "" && logType != "history" {
+		return response.BadRequest(fmt.Errorf("Unknown console log type %q", logType))
 	}
 
 	inst, err := instance.LoadByProjectAndName(s, projectName, name)
@@ -596,6 +751,30 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	ent := response.FileResponseEntry{}
+
+	if inst.Type() == instancetype.VM {
+		vm, ok := inst.(instance.VM)
+		if !ok {
+			return response.SmartError(fmt.Errorf("Invalid instance type"))
+		}
+
+		history, err := vm.ConsoleHistory()
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		ent.File = bytes.NewReader(history)
+		ent.FileModified = time.Now()
+		ent.FileSize = int64(len(history))
+
+		return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+	}
+
+	if !liblxc.RuntimeLiblxcVersionAtLeast(liblxc.Version(), 3, 0, 0) {
+		return response.BadRequest(fmt.Errorf("Querying the console buffer requires liblxc >= 3.0"))
+	}
+
 	if inst.Type() != instancetype.Container {
 		return response.SmartError(fmt.Errorf("Instance is not container type"))
 	}
@@ -605,7 +784,6 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Invalid instance type"))
 	}
 
-	ent := response.FileResponseEntry{}
 	if !c.IsRunning() {
 		// Hand back the contents of the console ringbuffer logfile.
 		consoleBufferLogPath := c.ConsoleBufferLogPath()
@@ -671,10 +849,6 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func instanceConsoleLogDelete(d *Daemon, r *http.Request) response.Response {
-	if !liblxc.RuntimeLiblxcVersionAtLeast(liblxc.Version(), 3, 0, 0) {
-		return response.BadRequest(fmt.Errorf("Clearing the console buffer requires liblxc >= 3.0"))
-	}
-
 	name, err := url.PathUnescape(mux.Vars(r)["name"])
 	if err != nil {
 		return response.SmartError(err)
@@ -691,6 +865,19 @@ func instanceConsoleLogDelete(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if inst.Type() == instancetype.VM {
+		vm, ok := inst.(instance.VM)
+		if !ok {
+			return response.SmartError(fmt.Errorf("Invalid instance type"))
+		}
+
+		return response.SmartError(vm.ConsoleHistoryClear())
+	}
+
+	if !liblxc.RuntimeLiblxcVersionAtLeast(liblxc.Version(), 3, 0, 0) {
+		return response.BadRequest(fmt.Errorf("Clearing the console buffer requires liblxc >= 3.0"))
+	}
+
 	if inst.Type() != instancetype.Container {
 		return response.SmartError(fmt.Errorf("Instance is not container type"))
 	}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	projecthelpers "github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/osarch"
+)
+
+var instanceDeviceGroupsCmd = APIEndpoint{
+	Name: "instanceDeviceGroups",
+	Path: "instances/{name}/device-groups",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerDeviceGroups", Path: "containers/{name}/device-groups"},
+		{Name: "vmDeviceGroups", Path: "virtual-machines/{name}/device-groups"},
+	},
+
+	Post: APIEndpointAction{Handler: instanceDeviceGroupsPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+var instanceDeviceGroupCmd = APIEndpoint{
+	Name: "instanceDeviceGroup",
+	Path: "instances/{name}/device-groups/{group}",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerDeviceGroup", Path: "containers/{name}/device-groups/{group}"},
+		{Name: "vmDeviceGroup", Path: "virtual-machines/{name}/device-groups/{group}"},
+	},
+
+	Delete: APIEndpointAction{Handler: instanceDeviceGroupDelete, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+// swagger:operation POST /1.0/instances/{name}/device-groups instances instance_device_groups_post
+//
+//	Attach a device group
+//
+//	Adds all the devices of a device group to the instance.
+//	If the instance is running, the devices are hotplugged.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: device group
+//	    description: Device group to attach
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceDeviceGroupsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceDeviceGroupsPost(d *Daemon, r *http.Request) response.Response {
+	req := api.InstanceDeviceGroupsPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Name == "" {
+		return response.BadRequest(fmt.Errorf("No device group name provided"))
+	}
+
+	return instanceDeviceGroupUpdate(d, r, req.Name, true)
+}
+
+// swagger:operation DELETE /1.0/instances/{name}/device-groups/{group} instances instance_device_group_delete
+//
+//	Detach a device group
+//
+//	Removes the devices that were added to the instance when attaching the device group.
+//	If the instance is running, the devices are hot-unplugged.
+//	Detaching fails if one of those devices was modified on the instance and no longer matches the device group.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceDeviceGroupDelete(d *Daemon, r *http.Request) response.Response {
+	groupName, err := url.PathUnescape(mux.Vars(r)["group"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return instanceDeviceGroupUpdate(d, r, groupName, false)
+}
+
+// instanceDeviceGroupUpdate attaches or detaches the given device group to or from the instance of the request.
+func instanceDeviceGroupUpdate(d *Daemon, r *http.Request, groupName string, attach bool) response.Response {
+	// Don't mess with instance while in setup mode.
+	<-d.waitReady.Done()
+
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	unlock, err := instanceOperationLock(s.ShutdownCtx, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	defer unlock()
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if attach {
+		err = instanceDeviceGroupAttach(s, inst, groupName)
+	} else {
+		err = instanceDeviceGroupDetach(s, inst, groupName)
+	}
+
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// instanceDeviceGroupAttach adds the devices of the device group to the instance and records them in the
+// instance configuration so that they can be removed together when detaching the device group.
+func instanceDeviceGroupAttach(s *state.State, inst instance.Instance, groupName string) error {
+	key := deviceGroupConfigKey(groupName)
+	if inst.LocalConfig()[key] != "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Device group %q is already attached to the instance", groupName)
+	}
+
+	group, err := instanceDeviceGroupLoad(s, inst, groupName)
+	if err != nil {
+		return err
+	}
+
+	if len(group.Devices) == 0 {
+		return api.StatusErrorf(http.StatusBadRequest, "Device group %q has no devices", groupName)
+	}
+
+	devices := inst.LocalDevices().CloneNative()
+	deviceNames := make([]string, 0, len(group.Devices))
+	for devName, dev := range group.Devices {
+		_, ok := devices[devName]
+		if ok {
+			return api.StatusErrorf(http.StatusBadRequest, "Device %q of device group %q already exists on the instance", devName, groupName)
+		}
+
+		devices[devName] = dev
+		deviceNames = append(deviceNames, devName)
+	}
+
+	sort.Strings(deviceNames)
+
+	config := make(map[string]string, len(inst.LocalConfig())+1)
+	for k, v := range inst.LocalConfig() {
+		config[k] = v
+	}
+
+	config[key] = strings.Join(deviceNames, ",")
+
+	return instanceDeviceGroupApply(s, inst, config, devices)
+}
+
+// instanceDeviceGroupDetach removes the devices added by the device group from the instance.
+func instanceDeviceGroupDetach(s *state.State, inst instance.Instance, groupName string) error {
+	key := deviceGroupConfigKey(groupName)
+	value := inst.LocalConfig()[key]
+	if value == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Device group %q isn't attached to the instance", groupName)
+	}
+
+	group, err := instanceDeviceGroupLoad(s, inst, groupName)
+	if err != nil {
+		return err
+	}
+
+	// Only remove the devices that are still the ones added by the device group, refusing to remove a device that
+	// was modified or replaced on the instance since the device group was attached.
+	groupDevices := deviceConfig.NewDevices(group.Devices)
+	devices := inst.LocalDevices().CloneNative()
+	for _, devName := range shared.SplitNTrimSpace(value, ",", -1, true) {
+		dev, ok := devices[devName]
+		if !ok {
+			continue
+		}
+
+		if !groupDevices.Contains(devName, dev) {
+			return api.StatusErrorf(http.StatusBadRequest, "Device %q doesn't match its definition in device group %q, remove it from the instance first", devName, groupName)
+		}
+
+		delete(devices, devName)
+	}
+
+	config := make(map[string]string, len(inst.LocalConfig()))
+	for k, v := range inst.LocalConfig() {
+		if k != key {
+			config[k] = v
+		}
+	}
+
+	return instanceDeviceGroupApply(s, inst, config, devices)
+}
+
+// instanceDeviceGroupLoad returns the device group with the given name from the project the instance uses device
+// groups from. Instances use the device groups of the same project as their profiles.
+func instanceDeviceGroupLoad(s *state.State, inst instance.Instance, groupName string) (*api.DeviceGroup, error) {
+	instProject := inst.Project()
+	groupProjectName := projecthelpers.ProfileProjectFromRecord(&instProject)
+
+	var group *api.DeviceGroup
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbGroup, err := dbCluster.GetDeviceGroup(ctx, tx.Tx(), groupProjectName, groupName)
+		if err != nil {
+			return fmt.Errorf("Failed loading device group %q: %w", groupName, err)
+		}
+
+		group, err = dbGroup.ToAPI(ctx, tx.Tx())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// instanceDeviceGroupApply updates the instance with the given local configuration and devices, keeping all its
+// other properties.
+func instanceDeviceGroupApply(s *state.State, inst instance.Instance, config map[string]string, devices map[string]map[string]string) error {
+	projectName := inst.Project().Name
+
+	profileNames := make([]string, 0, len(inst.Profiles()))
+	for _, profile := range inst.Profiles() {
+		profileNames = append(profileNames, profile.Name)
+	}
+
+	architectureName, err := osarch.ArchitectureName(inst.Architecture())
+	if err != nil {
+		return err
+	}
+
+	req := api.InstancePut{
+		Architecture: architectureName,
+		Config:       config,
+		Description:  inst.Description(),
+		Devices:      devices,
+		Ephemeral:    inst.IsEphemeral(),
+		Profiles:     profileNames,
+	}
+
+	// Check project limits.
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return projecthelpers.AllowInstanceUpdate(s.GlobalConfig, tx, projectName, inst.Name(), req, inst.LocalConfig())
+	})
+	if err != nil {
+		return err
+	}
+
+	args := db.InstanceArgs{
+		Architecture: inst.Architecture(),
+		Config:       config,
+		Description:  inst.Description(),
+		Devices:      deviceConfig.NewDevices(devices),
+		Ephemeral:    inst.IsEphemeral(),
+		Profiles:     inst.Profiles(),
+		Project:      projectName,
+	}
+
+	return inst.Update(args, true)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/canonical/lxd/lxd/apparmor"
+	"github.com/canonical/lxd/lxd/backup"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/ws"
+)
+
+// instanceDiskImportConnectTimeout is how long to wait for the client to connect to the disk import websocket.
+const instanceDiskImportConnectTimeout = 30 * time.Second
+
+// instanceDiskImportFormats are the disk formats that can be imported.
+var instanceDiskImportFormats = []string{"qcow2", "raw", "vmdk", "vhdx", "vdi"}
+
+// instanceDiskImportWs receives the disk streamed by the client over the websocket of the operation.
+type instanceDiskImportWs struct {
+	secret    string
+	connected chan *websocket.Conn
+}
+
+// Metadata returns a map of metadata.
+func (c *instanceDiskImportWs) Metadata() any {
+	return shared.Jmap{"fds": shared.Jmap{"0": c.secret}}
+}
+
+// Connect connects to the websocket.
+func (c *instanceDiskImportWs) Connect(op *operations.Operation, r *http.Request, w http.ResponseWriter) error {
+	secret := r.FormValue("secret")
+	if secret == "" {
+		return fmt.Errorf("missing secret")
+	}
+
+	if secret != c.secret {
+		return os.ErrPermission
+	}
+
+	conn, err := ws.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case c.connected <- conn:
+	default:
+		_ = conn.Close()
+		return fmt.Errorf("Disk import websocket already connected")
+	}
+
+	return nil
+}
+
+// wait waits for the client to connect to the websocket.
+func (c *instanceDiskImportWs) wait() (*websocket.Conn, error) {
+	select {
+	case conn := <-c.connected:
+		return conn, nil
+	case <-time.After(instanceDiskImportConnectTimeout):
+		return nil, fmt.Errorf("Timed out waiting for the disk import websocket to connect")
+	}
+}
+
+// instanceImportDisk converts the disk read from data into the root volume of the instance.
+// If size is positive, the disk must be exactly size bytes long.
+func instanceImportDisk(s *state.State, inst instance.Instance, data io.Reader, size int64, op *operations.Operation) error {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	setProgress := func(text string) {
+		meta := op.Metadata()
+		if meta == nil {
+			meta = make(map[string]any)
+		}
+
+		if meta["create_instance_from_disk_progress"] != text {
+			meta["create_instance_from_disk_progress"] = text
+			_ = op.UpdateMetadata(meta)
+		}
+	}
+
+	// Stream the disk into a temporary file as its format must be inspected before converting it.
+	diskFile, err := os.CreateTemp(shared.VarPath("backups"), fmt.Sprintf("%s_disk_", backup.WorkingDirPrefix))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(diskFile.Name()) }()
+	defer func() { _ = diskFile.Close() }()
+
+	reader := &ioprogress.ProgressReader{
+		ReadCloser: io.NopCloser(data),
+		Tracker: &ioprogress.ProgressTracker{
+			Length: size,
+			Handler: func(value, speed int64) {
+				if size > 0 {
+					setProgress(fmt.Sprintf("%d%% (%s/s)", value, units.GetByteSizeString(speed, 2)))
+				} else {
+					setProgress(fmt.Sprintf("%s (%s/s)", units.GetByteSizeString(value, 2), units.GetByteSizeString(speed, 2)))
+				}
+			},
+		},
+	}
+
+	l.Debug("Receiving disk")
+
+	received, err := io.Copy(diskFile, reader)
+	if err != nil {
+		return fmt.Errorf("Failed receiving disk: %w", err)
+	}
+
+	if size > 0 && received != size {
+		return fmt.Errorf("Received %d bytes of disk instead of %d", received, size)
+	}
+
+	err = diskFile.Close()
+	if err != nil {
+		return err
+	}
+
+	// Use prlimit because qemu-img can consume considerable RAM & CPU time if fed a maliciously crafted disk.
+	imgJSON, err := apparmor.QemuImg(s.OS, []string{"prlimit", "--cpu=2", "--as=1073741824", "qemu-img", "info", "--output=json", diskFile.Name()}, diskFile.Name(), "")
+	if err != nil {
+		return fmt.Errorf("Failed reading disk info: %w", err)
+	}
+
+	imgInfo := struct {
+		Format          string `json:"format"`
+		VirtualSize     int64  `json:"virtual-size"`
+		BackingFilename string `json:"backing-filename"`
+		FormatSpecific  struct {
+			Data struct {
+				DataFile string `json:"data-file"`
+			} `json:"data"`
+		} `json:"format-specific"`
+	}{}
+
+	err = json.Unmarshal([]byte(imgJSON), &imgInfo)
+	if err != nil {
+		return fmt.Errorf("Failed unmarshalling disk info: %w (%q)", err, imgJSON)
+	}
+
+	if !shared.ValueInSlice(imgInfo.Format, instanceDiskImportFormats) {
+		return fmt.Errorf("Unsupported disk format %q", imgInfo.Format)
+	}
+
+	// Disks referring to other files could be used to read files of the host.
+	if imgInfo.BackingFilename != "" || imgInfo.FormatSpecific.Data.DataFile != "" {
+		return fmt.Errorf("Disks with a backing or external data file can't be imported")
+	}
+
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return fmt.Errorf("Failed loading instance storage pool: %w", err)
+	}
+
+	mountInfo, err := pool.MountInstance(inst, op)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = pool.UnmountInstance(inst, op) }()
+
+	if mountInfo.DiskPath == "" {
+		return fmt.Errorf("Root volume of instance %q has no disk", inst.Name())
+	}
+
+	// Grow the root volume if the disk doesn't fit in it.
+	volSize, err := storageDrivers.BlockDiskSizeBytes(mountInfo.DiskPath)
+	if err != nil {
+		return fmt.Errorf("Failed getting the size of the root volume: %w", err)
+	}
+
+	if volSize < imgInfo.VirtualSize {
+		l.Debug("Increasing root volume size", logger.Ctx{"oldSize": volSize, "newSize": imgInfo.VirtualSize})
+
+		err = pool.SetInstanceQuota(inst, fmt.Sprintf("%d", imgInfo.VirtualSize), "", op)
+		if err != nil {
+			return fmt.Errorf("Failed increasing the root volume size: %w", err)
+		}
+	}
+
+	setProgress(fmt.Sprintf("Converting %s disk", imgInfo.Format))
+	l.Debug("Converting disk", logger.Ctx{"format": imgInfo.Format, "virtualSize": imgInfo.VirtualSize})
+
+	cmd := []string{
+		"nice", "-n19", // Run with low priority to reduce CPU impact on other processes.
+		"qemu-img", "convert", "-f", imgInfo.Format, "-O", "raw",
+	}
+
+	if shared.IsBlockdevPath(mountInfo.DiskPath) {
+		cmd = append(cmd, "-W")
+	}
+
+	cmd = append(cmd, diskFile.Name(), mountInfo.DiskPath)
+
+	_, err = apparmor.QemuImg(s.OS, cmd, diskFile.Name(), mountInfo.DiskPath)
+	if err != nil {
+		return fmt.Errorf("Failed converting disk: %w", err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -71,6 +72,10 @@ func instanceFileHandler(d *Daemon, r *http.Request) response.Response {
 
 	switch r.Method {
 	case "GET":
+		if shared.IsTrue(request.QueryParam(r, "glob")) {
+			return instanceFileGlob(inst, path)
+		}
+
 		return instanceFileGet(s, inst, path, r)
 	case "HEAD":
 		return instanceFileHead(inst, path)
@@ -88,6 +93,8 @@ func instanceFileHandler(d *Daemon, r *http.Request) response.Response {
 //	Get a file
 //
 //	Gets the file content. If it's a directory, a json list of files will be returned instead.
+//	If the glob parameter is set, the path is expanded as a glob pattern and a json list of the matching paths
+//	is returned.
 //
 //	---
 //	produces:
@@ -104,6 +111,11 @@ func instanceFileHandler(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: glob
+//	    description: Treat the path as a glob pattern and return the list of matching paths
+//	    type: boolean
+//	    example: true
 //	responses:
 //	  "200":
 //	     description: Raw file or directory listing
@@ -264,6 +276,32 @@ func instanceFileGet(s *state.State, inst instance.Instance, path string, r *htt
 	return response.InternalError(fmt.Errorf("Bad file type: %s", fileType))
 }
 
+// instanceFileGlob returns the paths in the instance matching the glob pattern.
+func instanceFileGlob(inst instance.Instance, pattern string) response.Response {
+	// Get a SFTP client.
+	client, err := inst.FileSFTP()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	matches, err := client.Glob(pattern)
+	if err != nil {
+		if errors.Is(err, sftp.ErrBadPattern) {
+			return response.BadRequest(fmt.Errorf("Invalid glob pattern %q: %w", pattern, err))
+		}
+
+		return response.SmartError(err)
+	}
+
+	if matches == nil {
+		matches = []string{}
+	}
+
+	return response.SyncResponse(true, matches)
+}
+
 // swagger:operation HEAD /1.0/instances/{name}/files instances instance_files_head
 //
 //	Get metadata for a file
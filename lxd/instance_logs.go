@@ -1,16 +1,25 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 
 	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/project"
@@ -21,6 +30,7 @@ import (
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/version"
+	"github.com/canonical/lxd/shared/ws"
 )
 
 var instanceLogCmd = APIEndpoint{
@@ -183,6 +193,9 @@ func instanceLogsGet(d *Daemon, r *http.Request) response.Response {
 //
 //	Gets the log file.
 //
+//	When `follow` is set, the connection is upgraded to a websocket over which the log file is streamed,
+//	including what gets appended to it later on, until the client disconnects.
+//
 //	---
 //	produces:
 //	  - application/json
@@ -193,6 +206,16 @@ func instanceLogsGet(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: lines
+//	    description: Only return the given number of lines from the end of the log file
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: follow
+//	    description: Stream the log file over a websocket as it grows
+//	    type: boolean
+//	    example: true
 //	responses:
 //	  "200":
 //	     description: Raw file
@@ -233,14 +256,15 @@ func instanceLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	// Handle requests targeted to a container on a different node
-	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
-	if err != nil {
-		return response.SmartError(err)
-	}
+	follow := shared.IsTrue(request.QueryParam(r, "follow"))
 
-	if resp != nil {
-		return resp
+	lines := -1
+	linesStr := request.QueryParam(r, "lines")
+	if linesStr != "" {
+		lines, err = strconv.Atoi(linesStr)
+		if err != nil || lines < 0 {
+			return response.BadRequest(fmt.Errorf("Invalid number of lines %q", linesStr))
+		}
 	}
 
 	file, err := url.PathUnescape(mux.Vars(r)["file"])
@@ -248,6 +272,50 @@ func instanceLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if follow {
+		if !validLogFileName(file) {
+			return response.BadRequest(fmt.Errorf("Log file name %q not valid", file))
+		}
+
+		// The log file is streamed over a websocket, so proxy it from the member running the instance.
+		client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, name, r, instanceType)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if client != nil {
+			source, err := client.GetInstanceLogfileFollow(name, file, lines)
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			return response.ManualResponse(func(w http.ResponseWriter) error {
+				target, err := ws.Upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					_ = source.Close()
+					return err
+				}
+
+				<-ws.Proxy(source, target)
+
+				_ = source.Close()
+				_ = target.Close()
+
+				return nil
+			})
+		}
+	} else {
+		// Handle requests targeted to a container on a different node
+		resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if resp != nil {
+			return resp
+		}
+	}
+
 	err = instance.ValidName(name, false)
 	if err != nil {
 		return response.BadRequest(err)
@@ -257,16 +325,201 @@ func instanceLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Log file name %q not valid", file))
 	}
 
+	path := shared.LogPath(project.Instance(projectName, name), file)
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceLogRetrieved.Event(file, inst, request.CreateRequestor(r), nil))
+
+	if follow {
+		return response.ManualResponse(func(w http.ResponseWriter) error {
+			conn, err := ws.Upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = conn.Close() }()
+
+			return instanceLogFollow(conn, path, lines)
+		})
+	}
+
+	if lines >= 0 {
+		f, err := os.Open(path)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		defer func() { _ = f.Close() }()
+
+		offset, err := logTailOffset(f, lines)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		_, err = f.Seek(offset, io.SeekStart)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		content, err := io.ReadAll(f)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		ent := response.FileResponseEntry{
+			File:         bytes.NewReader(content),
+			FileModified: time.Now(),
+			FileSize:     int64(len(content)),
+			Filename:     file,
+		}
+
+		return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+	}
+
 	ent := response.FileResponseEntry{
-		Path:     shared.LogPath(project.Instance(projectName, name), file),
+		Path:     path,
 		Filename: file,
 	}
 
-	s.Events.SendLifecycle(projectName, lifecycle.InstanceLogRetrieved.Event(file, inst, request.CreateRequestor(r), nil))
-
 	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
 }
 
+// instanceLogFollow sends the content of the log file over the websocket, starting with its last lines (or the
+// whole file if lines is negative), and then keeps sending what gets appended to it until the client disconnects.
+// The file is reopened if it gets truncated or replaced, for example when the instance is restarted.
+func instanceLogFollow(conn *websocket.Conn, path string, lines int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Detect the client disconnecting.
+	go func() {
+		for {
+			_, _, err := conn.NextReader()
+			if err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	var f *os.File
+	var fInfo os.FileInfo
+	var offset int64
+
+	defer func() {
+		if f != nil {
+			_ = f.Close()
+		}
+	}()
+
+	buf := make([]byte, 32*1024)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if f != nil {
+			// Reopen the file if it got truncated or replaced.
+			info, err := os.Stat(path)
+			if err != nil || !os.SameFile(info, fInfo) || info.Size() < offset {
+				_ = f.Close()
+				f = nil
+				lines = -1
+			}
+		}
+
+		if f == nil {
+			var err error
+			f, err = os.Open(path)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			// If the file doesn't exist yet, wait for it to be created.
+			if err == nil {
+				fInfo, err = f.Stat()
+				if err != nil {
+					return err
+				}
+
+				offset = 0
+				if lines >= 0 {
+					offset, err = logTailOffset(f, lines)
+					if err != nil {
+						return err
+					}
+				}
+
+				// Any data added to the file from now on is sent in full.
+				lines = -1
+			}
+		}
+
+		for f != nil {
+			n, err := f.ReadAt(buf, offset)
+			if n > 0 {
+				errWrite := conn.WriteMessage(websocket.BinaryMessage, buf[:n])
+				if errWrite != nil {
+					return nil
+				}
+
+				offset += int64(n)
+			}
+
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// logTailOffset returns the offset at which the last lines of the file start.
+func logTailOffset(f *os.File, lines int) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return -1, err
+	}
+
+	size := info.Size()
+	if lines == 0 {
+		return size, nil
+	}
+
+	buf := make([]byte, 4096)
+	found := 0
+	offset := size
+
+	for offset > 0 {
+		chunkSize := min(int64(len(buf)), offset)
+		offset -= chunkSize
+
+		_, err := f.ReadAt(buf[:chunkSize], offset)
+		if err != nil && err != io.EOF {
+			return -1, err
+		}
+
+		for i := chunkSize - 1; i >= 0; i-- {
+			// Skip the line break that terminates the last line.
+			if buf[i] != '\n' || offset+i == size-1 {
+				continue
+			}
+
+			found++
+			if found == lines {
+				return offset + i + 1, nil
+			}
+		}
+	}
+
+	return 0, nil
+}
+
 // swagger:operation DELETE /1.0/instances/{name}/logs/{filename} instances instance_log_delete
 //
 //	Delete the log file
@@ -341,8 +594,8 @@ func instanceLogDelete(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Log file name %q not valid", file))
 	}
 
-	if !strings.HasSuffix(file, ".log") || file == "lxc.log" || file == "qemu.log" {
-		return response.BadRequest(fmt.Errorf("Only log files excluding qemu.log and lxc.log may be deleted"))
+	if !strings.HasSuffix(file, ".log") || file == "lxc.log" || file == "qemu.log" || file == "console.log" {
+		return response.BadRequest(fmt.Errorf("Only log files excluding qemu.log, lxc.log and console.log may be deleted"))
 	}
 
 	err = os.Remove(shared.LogPath(project.Instance(projectName, name), file))
@@ -687,6 +940,7 @@ func validLogFileName(fname string) bool {
 	 */
 	return fname == "lxc.log" ||
 		fname == "lxc.conf" ||
+		fname == "console.log" ||
 		fname == "qemu.log" ||
 		fname == "qemu.conf" ||
 		strings.HasPrefix(fname, "migration_") ||
@@ -9,6 +9,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -31,9 +35,14 @@ import (
 	apiScriptlet "github.com/canonical/lxd/shared/api/scriptlet"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/version"
 )
 
+// instancePostTargetAuto is the target used to let the cluster pick the member to move an instance to.
+const instancePostTargetAuto = "auto"
+
 // swagger:operation POST /1.0/instances/{name} instances instance_post
 //
 //	Rename or move/migrate an instance
@@ -57,6 +66,11 @@ import (
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member, cluster group (prefixed with "@") or "auto" to move the instance to
+//	    type: string
+//	    example: auto
 //	  - in: body
 //	    name: migration
 //	    description: Migration request
@@ -105,6 +119,9 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Target only allowed when clustered"))
 	}
 
+	// Whether the cluster picks the member to move the instance to.
+	autoTarget := false
+
 	// A POST to /instances/<name>?target=<member> is meant to be used to
 	// move an instance from one member to another within a cluster.
 	//
@@ -122,6 +139,17 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 	//       if we can't know for sure that it's indeed not
 	//       running?
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// A target of "auto" leaves the choice of the cluster member to the placement logic, unless a
+		// cluster member is actually named "auto".
+		if target == instancePostTargetAuto {
+			_, err := tx.GetNodeByName(ctx, target)
+			if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return fmt.Errorf("Failed to get cluster member %q: %w", target, err)
+			}
+
+			autoTarget = err != nil
+		}
+
 		// Load source node.
 		sourceAddress, err := tx.GetNodeAddressOfInstance(ctx, projectName, name, instanceType)
 		if err != nil {
@@ -179,6 +207,11 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			return resp
 		}
 	} else if sourceNodeOffline {
+		// Moving an instance off an offline member must be handled by the target member, so it has to be known.
+		if autoTarget {
+			return response.BadRequest(fmt.Errorf("A cluster member must be targeted when the instance's cluster member is offline"))
+		}
+
 		// If a target was specified, forward the request to the relevant node.
 		resp := forwardedResponseIfTargetIsRemote(s, r)
 		if resp != nil {
@@ -211,9 +244,19 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 
 			var targetGroupName string
 
-			targetMemberInfo, targetGroupName, err = project.CheckTarget(ctx, s.Authorizer, r, tx, targetProject, target, allMembers)
-			if err != nil {
-				return err
+			if autoTarget {
+				// Letting the cluster pick the member still moves the instance to another member, so the
+				// project's cluster targeting restriction applies. The current member is passed as the
+				// requested target since the restriction applies whatever member ends up being picked.
+				err = project.CheckClusterTargetRestriction(s.Authorizer, r, targetProject, inst.Location())
+				if err != nil {
+					return err
+				}
+			} else {
+				targetMemberInfo, targetGroupName, err = project.CheckTarget(ctx, s.Authorizer, r, tx, targetProject, target, allMembers)
+				if err != nil {
+					return err
+				}
 			}
 
 			if targetMemberInfo == nil {
@@ -223,6 +266,15 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				if err != nil {
 					return err
 				}
+
+				// The instance is being moved away from its current member, so don't consider it.
+				candidateMembers = slices.DeleteFunc(candidateMembers, func(member db.NodeInfo) bool {
+					return member.Name == inst.Location()
+				})
+
+				if len(candidateMembers) == 0 {
+					return api.StatusErrorf(http.StatusBadRequest, "No suitable cluster member could be found to move the instance to")
+				}
 			}
 
 			return nil
@@ -231,6 +283,14 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
+		if targetMemberInfo == nil {
+			// Only consider the cluster members that can create the mediated devices of mdev GPU devices.
+			candidateMembers, err = clusterMembersWithGPUMdev(s, r, inst.ExpandedDevices(), candidateMembers)
+			if err != nil {
+				return response.SmartError(err)
+			}
+		}
+
 		if targetMemberInfo == nil && s.GlobalConfig.InstancesPlacementScriptlet() != "" {
 			leaderAddress, err := d.gateway.LeaderAddress()
 			if err != nil {
@@ -258,18 +318,8 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 
 		// If no member was selected yet, pick the member with the least number of instances.
 		if targetMemberInfo == nil {
-			var filteredCandidateMembers []db.NodeInfo
-
-			// The instance might already be placed on the node with least number of instances.
-			// Therefore remove it from the list of possible candidates if existent.
-			for _, candidateMember := range candidateMembers {
-				if candidateMember.Name != inst.Location() {
-					filteredCandidateMembers = append(filteredCandidateMembers, candidateMember)
-				}
-			}
-
 			err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-				targetMemberInfo, err = tx.GetNodeWithLeastInstances(ctx, filteredCandidateMembers)
+				targetMemberInfo, err = tx.GetNodeWithLeastInstances(ctx, candidateMembers)
 				return err
 			})
 			if err != nil {
@@ -319,6 +369,15 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	bwlimit, err := instancePostBandwidthLimit(req.BandwidthLimit)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.StatelessFallback && (!req.Migration || targetMemberInfo == nil || req.Pool != "" || req.Project != "") {
+		return response.BadRequest(fmt.Errorf("Stateless fallback is only supported when moving an instance between cluster members"))
+	}
+
 	if req.Migration {
 		// Server-side instance migration.
 		if req.Pool != "" || req.Project != "" {
@@ -339,7 +398,7 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
 			op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceMigrate, resources, nil, run, nil, nil, r)
 			if err != nil {
-				return response.InternalError(err)
+				return response.SmartError(err)
 			}
 
 			return operations.OperationResponse(op)
@@ -375,14 +434,14 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 
 			op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceMigrate, resources, nil, run, nil, nil, r)
 			if err != nil {
-				return response.InternalError(err)
+				return response.SmartError(err)
 			}
 
 			return operations.OperationResponse(op)
 		}
 
 		instanceOnly := req.InstanceOnly || req.ContainerOnly
-		ws, err := newMigrationSource(inst, req.Live, instanceOnly, req.AllowInconsistent, "", req.Target)
+		ws, err := newMigrationSource(inst, req.Live, instanceOnly, req.AllowInconsistent, "", bwlimit, req.Target)
 		if err != nil {
 			return response.InternalError(err)
 		}
@@ -407,7 +466,7 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			// Push mode.
 			op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceMigrate, resources, nil, run, nil, nil, r)
 			if err != nil {
-				return response.InternalError(err)
+				return response.SmartError(err)
 			}
 
 			return operations.OperationResponse(op)
@@ -416,7 +475,7 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 		// Pull mode.
 		op, err := operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, operationtype.InstanceMigrate, resources, ws.Metadata(), run, cancel, ws.Connect, r)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 
 		return operations.OperationResponse(op)
@@ -447,7 +506,7 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceRename, resources, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -630,8 +689,22 @@ func instancePostMigration(s *state.State, inst instance.Instance, newName strin
 	return nil
 }
 
+// instancePostMigrationDefaultRetries is the number of times a failed live migration between cluster members is
+// retried if migration.retries isn't set.
+const instancePostMigrationDefaultRetries = 3
+
+// Migration modes reported in the operation metadata of an instance move between cluster members.
+const (
+	instancePostMigrationModeLive      = "live"
+	instancePostMigrationModeStateless = "stateless"
+)
+
+// instancePostMigrationRetryDelay is the delay before the first retry of a failed live migration between cluster
+// members, which doubles after each attempt.
+const instancePostMigrationRetryDelay = 5 * time.Second
+
 // Move a non-ceph instance to another cluster node. Source and target members must be online.
-func instancePostClusteringMigrate(s *state.State, r *http.Request, srcPool storagePools.Pool, srcInst instance.Instance, newInstName string, srcMember db.NodeInfo, newMember db.NodeInfo, stateful bool, allowInconsistent bool) (func(op *operations.Operation) error, error) {
+func instancePostClusteringMigrate(s *state.State, r *http.Request, srcPool storagePools.Pool, srcInst instance.Instance, newInstName string, srcMember db.NodeInfo, newMember db.NodeInfo, stateful bool, statelessFallback bool, allowInconsistent bool, bwlimit int64) (func(op *operations.Operation) error, error) {
 	srcMemberOffline := srcMember.IsOffline(s.GlobalConfig.OfflineThreshold())
 
 	// Make sure that the source member is online if we end up being called from another member after a
@@ -723,71 +796,195 @@ func instancePostClusteringMigrate(s *state.State, r *http.Request, srcPool stor
 			return fmt.Errorf("Unexpected result from source instance render: %w", err)
 		}
 
-		srcMigration, err := newMigrationSource(srcInst, live, false, allowInconsistent, srcInstName, nil)
-		if err != nil {
-			return fmt.Errorf("Failed setting up instance migration on source: %w", err)
-		}
+		// Helper function to perform a single attempt of the migration to the destination member.
+		// If retry is true, the destination keeps the transferred volumes if the attempt fails so that the
+		// next attempt only needs to refresh them.
+		migrate := func(retry bool, updateMetadata func(metadata map[string]any)) error {
+			srcMigration, err := newMigrationSource(srcInst, live, false, allowInconsistent, srcInstName, bwlimit, nil)
+			if err != nil {
+				return fmt.Errorf("Failed setting up instance migration on source: %w", err)
+			}
 
-		run := func(op *operations.Operation) error {
-			return srcMigration.Do(s, op)
-		}
+			srcMigration.retry = retry
 
-		cancel := func(op *operations.Operation) error {
-			srcMigration.disconnect()
+			run := func(op *operations.Operation) error {
+				return srcMigration.Do(s, op)
+			}
+
+			cancel := func(op *operations.Operation) error {
+				srcMigration.disconnect()
+				return nil
+			}
+
+			srcOp, err := operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, operationtype.InstanceMigrate, resources, srcMigration.Metadata(), run, cancel, srcMigration.Connect, r)
+			if err != nil {
+				return err
+			}
+
+			err = srcOp.Start()
+			if err != nil {
+				return fmt.Errorf("Failed starting migration source operation: %w", err)
+			}
+
+			// Ensure the source operation has finished before returning on failure, so that it doesn't
+			// overlap with the next attempt.
+			reverter := revert.New()
+			defer reverter.Fail()
+
+			reverter.Add(func() {
+				srcMigration.disconnect()
+
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+
+				_ = srcOp.Wait(ctx)
+			})
+
+			sourceSecrets := make(map[string]string, len(srcMigration.conns))
+			for connName, conn := range srcMigration.conns {
+				sourceSecrets[connName] = conn.Secret()
+			}
+
+			// Request pull mode migration on destination.
+			destOp, err := dest.CreateInstance(api.InstancesPost{
+				Name:        newInstName,
+				InstancePut: srcInstInfo.Writable(),
+				Type:        api.InstanceType(srcInstInfo.Type),
+				Source: api.InstanceSource{
+					Type:        "migration",
+					Mode:        "pull",
+					Operation:   fmt.Sprintf("https://%s%s", srcMember.Address, srcOp.URL()),
+					Websockets:  sourceSecrets,
+					Certificate: string(networkCert.PublicKey()),
+					Live:        live,
+					Source:      srcInstName,
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("Failed requesting instance create on destination: %w", err)
+			}
+
+			handler := func(newOp api.Operation) {
+				updateMetadata(newOp.Metadata)
+			}
+
+			_, err = destOp.AddHandler(handler)
+			if err != nil {
+				return err
+			}
+
+			err = destOp.Wait()
+			if err != nil {
+				return fmt.Errorf("Instance move to destination failed: %w", err)
+			}
+
+			err = srcOp.Wait(context.Background())
+			if err != nil {
+				return fmt.Errorf("Instance move to destination failed on source: %w", err)
+			}
+
+			reverter.Success()
 			return nil
 		}
 
-		srcOp, err := operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, operationtype.InstanceMigrate, resources, srcMigration.Metadata(), run, cancel, srcMigration.Connect, r)
-		if err != nil {
-			return err
+		// Live migrations are retried if they fail while the instance is still running on the source, as
+		// the failure may have been caused by a transient network error.
+		retries := 0
+		if live {
+			retries = instancePostMigrationDefaultRetries
+			if srcInst.ExpandedConfig()["migration.retries"] != "" {
+				retries, err = strconv.Atoi(srcInst.ExpandedConfig()["migration.retries"])
+				if err != nil {
+					return fmt.Errorf("Invalid migration.retries: %w", err)
+				}
+			}
 		}
 
-		err = srcOp.Start()
-		if err != nil {
-			return fmt.Errorf("Failed starting migration source operation: %w", err)
-		}
-
-		sourceSecrets := make(map[string]string, len(srcMigration.conns))
-		for connName, conn := range srcMigration.conns {
-			sourceSecrets[connName] = conn.Secret()
-		}
-
-		// Request pull mode migration on destination.
-		destOp, err := dest.CreateInstance(api.InstancesPost{
-			Name:        newInstName,
-			InstancePut: srcInstInfo.Writable(),
-			Type:        api.InstanceType(srcInstInfo.Type),
-			Source: api.InstanceSource{
-				Type:        "migration",
-				Mode:        "pull",
-				Operation:   fmt.Sprintf("https://%s%s", srcMember.Address, srcOp.URL()),
-				Websockets:  sourceSecrets,
-				Certificate: string(networkCert.PublicKey()),
-				Live:        live,
-				Source:      srcInstName,
-			},
-		})
-		if err != nil {
-			return fmt.Errorf("Failed requesting instance create on destination: %w", err)
-		}
+		var attemptsMu sync.Mutex
+		attempts := []map[string]any{}
 
-		handler := func(newOp api.Operation) {
-			_ = op.UpdateMetadata(newOp.Metadata)
+		migrationMode := instancePostMigrationModeStateless
+		if live {
+			migrationMode = instancePostMigrationModeLive
 		}
 
-		_, err = destOp.AddHandler(handler)
-		if err != nil {
-			return err
-		}
+		// Helper function to update the operation metadata with the attempt history and the migration mode
+		// in use alongside the progress reported by the destination.
+		updateMetadata := func(metadata map[string]any) {
+			attemptsMu.Lock()
+			defer attemptsMu.Unlock()
 
-		err = destOp.Wait()
-		if err != nil {
-			return fmt.Errorf("Instance move to destination failed: %w", err)
+			opMetadata := make(map[string]any, len(metadata)+2)
+			for k, v := range metadata {
+				opMetadata[k] = v
+			}
+
+			opMetadata["migration_attempts"] = slices.Clone(attempts)
+			opMetadata["migration_mode"] = migrationMode
+			_ = op.UpdateMetadata(opMetadata)
 		}
 
-		err = srcOp.Wait(context.Background())
-		if err != nil {
-			return fmt.Errorf("Instance move to destination failed on source: %w", err)
+		for attempt := 1; ; attempt++ {
+			// When falling back to a stateless move, the volumes transferred by the live attempts are kept
+			// on the destination so that the stateless attempt only needs to refresh them.
+			startedAt := time.Now()
+			err = migrate(live && (attempt <= retries || statelessFallback), updateMetadata)
+
+			result := map[string]any{
+				"attempt":     attempt,
+				"live":        live,
+				"started_at":  startedAt,
+				"finished_at": time.Now(),
+				"error":       "",
+			}
+
+			if err != nil {
+				result["error"] = err.Error()
+			}
+
+			attemptsMu.Lock()
+			attempts = append(attempts, result)
+			attemptsMu.Unlock()
+
+			updateMetadata(nil)
+
+			if err == nil {
+				break
+			}
+
+			// Once the live attempts are exhausted, stop the instance and move it statelessly if requested.
+			if live && statelessFallback && (attempt > retries || !srcInst.IsRunning()) {
+				logger.Warn("Falling back to stateless instance migration", logger.Ctx{"project": projectName, "instance": srcInstName, "attempt": attempt, "err": err})
+
+				if srcInst.IsRunning() {
+					err = srcInst.Stop(false)
+					if err != nil {
+						return fmt.Errorf("Failed statelessly stopping instance %q: %w", srcInstName, err)
+					}
+				}
+
+				attemptsMu.Lock()
+				live = false
+				migrationMode = instancePostMigrationModeStateless
+				attemptsMu.Unlock()
+
+				updateMetadata(nil)
+				continue
+			}
+
+			// Only retry if the instance is still running on the source, otherwise its state is lost.
+			if attempt > retries || !srcInst.IsRunning() {
+				if attempt > 1 {
+					return fmt.Errorf("Instance move failed after %d attempts: %w", attempt, err)
+				}
+
+				return err
+			}
+
+			// Back off exponentially between attempts.
+			delay := min(instancePostMigrationRetryDelay<<(attempt-1), time.Minute)
+			logger.Warn("Retrying failed instance live migration", logger.Ctx{"project": projectName, "instance": srcInstName, "attempt": attempt, "delay": delay, "err": err})
+			time.Sleep(delay)
 		}
 
 		err = s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -852,7 +1049,8 @@ func instancePostClusteringMigrate(s *state.State, r *http.Request, srcPool stor
 			}
 		}
 
-		if !stateful && srcInstRunning {
+		// Start the instance on the destination if it was running but wasn't live migrated.
+		if !live && srcInstRunning {
 			req := api.InstanceStatePut{
 				Action: "start",
 			}
@@ -993,10 +1191,33 @@ func migrateInstance(s *state.State, r *http.Request, inst instance.Instance, ta
 		return f(op)
 	}
 
-	f, err := instancePostClusteringMigrate(s, r, srcPool, inst, req.Name, srcMember, newMember, req.Live, req.AllowInconsistent)
+	bwlimit, err := instancePostBandwidthLimit(req.BandwidthLimit)
+	if err != nil {
+		return err
+	}
+
+	f, err := instancePostClusteringMigrate(s, r, srcPool, inst, req.Name, srcMember, newMember, req.Live, req.StatelessFallback, req.AllowInconsistent, bwlimit)
 	if err != nil {
 		return err
 	}
 
 	return f(op)
 }
+
+// instancePostBandwidthLimit parses the bandwidth limit of a migration request into bytes per second.
+func instancePostBandwidthLimit(limit string) (int64, error) {
+	if limit == "" {
+		return 0, nil
+	}
+
+	bwlimit, err := units.ParseByteSizeString(limit)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid bandwidth limit %q: %w", limit, err)
+	}
+
+	if bwlimit <= 0 {
+		return 0, fmt.Errorf("Bandwidth limit must be greater than 0")
+	}
+
+	return bwlimit, nil
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -34,6 +36,8 @@ import (
 //	Update the instance
 //
 //	Updates the instance configuration or trigger a snapshot restore.
+//	When `dry-run` is set, the update is only validated and the changes it would make to the local and
+//	expanded configuration of the instance are returned.
 //
 //	---
 //	consumes:
@@ -46,12 +50,37 @@ import (
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: dry-run
+//	    description: Only validate the update and return the changes it would make
+//	    type: boolean
+//	    example: true
 //	  - in: body
 //	    name: instance
 //	    description: Update request
 //	    schema:
 //	      $ref: "#/definitions/InstancePut"
 //	responses:
+//	  "200":
+//	    description: Changes that would be made (dry run)
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ConfigDiff"
 //	  "202":
 //	    $ref: "#/responses/Operation"
 //	  "400":
@@ -154,6 +183,22 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
+		if shared.IsTrue(request.QueryParam(r, "dry-run")) {
+			diff, err := instanceUpdateDryRun(s, inst, configRaw, apiProfiles)
+			if err != nil {
+				return response.BadRequest(err)
+			}
+
+			return response.SyncResponse(true, diff)
+		}
+
+		// Validate the expanded config and devices resulting from the new profile list before applying
+		// anything, so that an invalid update is rejected without partially changing the instance.
+		_, _, err = instanceUpdateValidate(s, inst, configRaw, apiProfiles)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
 		// Update container configuration
 		do = func(op *operations.Operation) error {
 			defer unlock()
@@ -197,13 +242,86 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, opType, resources, nil, do, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	revert.Success()
 	return operations.OperationResponse(op)
 }
 
+// instanceUpdateDryRun validates the instance update and returns the changes it would make to the local and
+// expanded configuration of the instance, without applying them.
+func instanceUpdateDryRun(s *state.State, inst instance.Instance, req api.InstancePut, profiles []api.Profile) (*api.ConfigDiff, error) {
+	expandedConfig, expandedDevices, err := instanceUpdateValidate(s, inst, req, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	oldProfileNames := make([]string, 0, len(inst.Profiles()))
+	for _, profile := range inst.Profiles() {
+		oldProfileNames = append(oldProfileNames, profile.Name)
+	}
+
+	oldEntity := configDiffMap(inst.LocalConfig(), inst.LocalDevices().CloneNative())
+	oldEntity["description"] = inst.Description()
+	oldEntity["ephemeral"] = strconv.FormatBool(inst.IsEphemeral())
+	oldEntity["profiles"] = strings.Join(oldProfileNames, ",")
+
+	newEntity := configDiffMap(req.Config, req.Devices)
+	newEntity["description"] = req.Description
+	newEntity["ephemeral"] = strconv.FormatBool(req.Ephemeral)
+	newEntity["profiles"] = strings.Join(req.Profiles, ",")
+
+	diff := &api.ConfigDiff{
+		Changes: util.DiffConfigs(oldEntity, newEntity),
+		Instances: map[string][]api.ConfigDiffEntry{
+			inst.Name(): util.DiffConfigs(configDiffMap(inst.ExpandedConfig(), inst.ExpandedDevices().CloneNative()), configDiffMap(expandedConfig, expandedDevices.CloneNative())),
+		},
+	}
+
+	return diff, nil
+}
+
+// instanceUpdateValidate validates the local and expanded configuration and devices the instance would have after
+// the update, and returns the expanded configuration and devices.
+func instanceUpdateValidate(s *state.State, inst instance.Instance, req api.InstancePut, profiles []api.Profile) (map[string]string, deviceConfig.Devices, error) {
+	var globalConfigDump map[string]any
+	if s.GlobalConfig != nil {
+		globalConfigDump = s.GlobalConfig.Dump()
+	}
+
+	devices := deviceConfig.NewDevices(req.Devices)
+	expandedConfig := instancetype.ExpandInstanceConfig(globalConfigDump, req.Config, profiles)
+	expandedDevices := instancetype.ExpandInstanceDevices(devices, profiles)
+
+	err := instance.ValidConfig(s.OS, req.Config, false, inst.Type())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = instance.ValidConfig(s.OS, expandedConfig, true, inst.Type())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = instance.ValidDevices(s, inst.Project(), inst.Type(), devices, expandedDevices)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return expandedConfig, expandedDevices, nil
+}
+
+// configDiffMap merges the config and the flattened devices of an entity into a single map to compare them.
+func configDiffMap(config map[string]string, devices map[string]map[string]string) map[string]string {
+	diffMap := util.FlattenDevices(devices)
+	for key, value := range config {
+		diffMap[key] = value
+	}
+
+	return diffMap
+}
+
 func instanceSnapRestore(s *state.State, projectName string, name string, snap string, stateful bool) error {
 	// normalize snapshot name
 	if !shared.IsSnapshot(snap) {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"path/filepath"
 
 	"github.com/gorilla/mux"
 
@@ -135,9 +136,23 @@ func instanceRebuildPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Instance must be stopped to be rebuilt"))
 	}
 
+	if len(req.PreservePaths) > 0 {
+		if inst.Type() != instancetype.Container {
+			return response.BadRequest(fmt.Errorf("Preserving paths is only supported for containers"))
+		}
+
+		for _, path := range req.PreservePaths {
+			if !filepath.IsAbs(path) || filepath.Clean(path) == "/" {
+				return response.BadRequest(fmt.Errorf("Invalid preserved path %q, must be an absolute path below the root directory", path))
+			}
+		}
+	}
+
 	run := func(op *operations.Operation) error {
+		inst.SetOperation(op)
+
 		if req.Source.Type == "none" {
-			return instanceRebuildFromEmpty(inst, op)
+			return instanceRebuildFromEmpty(inst, req.PreservePaths, op)
 		}
 
 		if req.Source.Server != "" {
@@ -151,7 +166,7 @@ func instanceRebuildPost(d *Daemon, r *http.Request) response.Response {
 			return fmt.Errorf("Image not provided for instance rebuild")
 		}
 
-		return instanceRebuildFromImage(s, r, inst, sourceImage, op)
+		return instanceRebuildFromImage(s, r, inst, sourceImage, req.PreservePaths, op)
 	}
 
 	resources := map[string][]api.URL{}
@@ -163,7 +178,7 @@ func instanceRebuildPost(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, targetProject.Name, operations.OperationClassTask, operationtype.InstanceRebuild, resources, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -101,6 +101,7 @@ type sftpServeResponse struct {
 	req         *http.Request
 	projectName string
 	instName    string
+	volName     string
 	instConn    net.Conn
 }
 
@@ -139,13 +140,19 @@ func (r *sftpServeResponse) Render(w http.ResponseWriter) error {
 	}
 
 	ctx, cancel := context.WithCancel(r.req.Context())
-	l := logger.AddContext(logger.Ctx{
-		"project":  r.projectName,
-		"instance": r.instName,
-		"local":    remoteConn.LocalAddr(),
-		"remote":   remoteConn.RemoteAddr(),
-		"err":      err,
-	})
+	logCtx := logger.Ctx{
+		"project": r.projectName,
+		"local":   remoteConn.LocalAddr(),
+		"remote":  remoteConn.RemoteAddr(),
+	}
+
+	if r.volName != "" {
+		logCtx["volume"] = r.volName
+	} else {
+		logCtx["instance"] = r.instName
+	}
+
+	l := logger.AddContext(logCtx)
 
 	wg := sync.WaitGroup{}
 	wg.Add(1)
@@ -343,7 +343,7 @@ func instanceSnapshotsPost(d *Daemon, r *http.Request) response.Response {
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.SnapshotCreate, resources, nil, snapshot, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -545,7 +545,7 @@ func snapshotPut(s *state.State, r *http.Request, snapInst instance.Instance) re
 
 	op, err := operations.OperationCreate(s, snapInst.Project().Name, operations.OperationClassTask, opType, resources, nil, do, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -682,7 +682,7 @@ func snapshotPost(s *state.State, r *http.Request, snapInst instance.Instance) r
 			}
 		}
 
-		ws, err := newMigrationSource(snapInst, reqNew.Live, true, false, "", req.Target)
+		ws, err := newMigrationSource(snapInst, reqNew.Live, true, false, "", 0, req.Target)
 		if err != nil {
 			return response.SmartError(err)
 		}
@@ -703,7 +703,7 @@ func snapshotPost(s *state.State, r *http.Request, snapInst instance.Instance) r
 			// Push mode.
 			op, err := operations.OperationCreate(s, snapInst.Project().Name, operations.OperationClassTask, operationtype.SnapshotTransfer, resources, nil, run, nil, nil, r)
 			if err != nil {
-				return response.InternalError(err)
+				return response.SmartError(err)
 			}
 
 			return operations.OperationResponse(op)
@@ -712,7 +712,7 @@ func snapshotPost(s *state.State, r *http.Request, snapInst instance.Instance) r
 		// Pull mode.
 		op, err := operations.OperationCreate(s, snapInst.Project().Name, operations.OperationClassWebsocket, operationtype.SnapshotTransfer, resources, ws.Metadata(), run, nil, ws.Connect, r)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 
 		return operations.OperationResponse(op)
@@ -758,7 +758,7 @@ func snapshotPost(s *state.State, r *http.Request, snapInst instance.Instance) r
 
 	op, err := operations.OperationCreate(s, snapInst.Project().Name, operations.OperationClassTask, operationtype.SnapshotRename, resources, nil, rename, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -807,7 +807,7 @@ func snapshotDelete(s *state.State, r *http.Request, snapInst instance.Instance)
 
 	op, err := operations.OperationCreate(s, snapInst.Project().Name, operations.OperationClassTask, operationtype.SnapshotDelete, resources, nil, remove, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -19,6 +19,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/lxd/warnings"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -153,6 +154,27 @@ var instanceConsoleCmd = APIEndpoint{
 	Delete: APIEndpointAction{Handler: instanceConsoleLogDelete, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
 }
 
+var instanceConsoleSessionsCmd = APIEndpoint{
+	Name: "instanceConsoleSessions",
+	Path: "instances/{name}/console/sessions",
+	Aliases: []APIEndpointAlias{
+		{Name: "vmConsoleSessions", Path: "virtual-machines/{name}/console/sessions"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceConsoleSessionsGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+}
+
+var instanceAccessTokensCmd = APIEndpoint{
+	Name: "instanceAccessTokens",
+	Path: "instances/{name}/access-tokens",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerAccessTokens", Path: "containers/{name}/access-tokens"},
+		{Name: "vmAccessTokens", Path: "virtual-machines/{name}/access-tokens"},
+	},
+
+	Post: APIEndpointAction{Handler: instanceAccessTokensPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
 var instanceExecCmd = APIEndpoint{
 	Name: "instanceExec",
 	Path: "instances/{name}/exec",
@@ -279,6 +301,11 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 
 	// Start the instances
 	for _, inst := range instances {
+		// Stop starting instances if the daemon is shutting down.
+		if s.ShutdownCtx.Err() != nil {
+			return
+		}
+
 		if !instanceShouldAutoStart(inst) {
 			continue
 		}
@@ -503,3 +530,34 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 	wg.Wait()
 	close(instShutdownCh)
 }
+
+// instancesUpdateMemoryBalloonsTask applies the automatic memory balloon policy to the VMs running on this member.
+func instancesUpdateMemoryBalloonsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		instances, err := instance.LoadNodeAll(s, instancetype.VM)
+		if err != nil {
+			logger.Warn("Failed loading instances to update memory balloons", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range instances {
+			if ctx.Err() != nil {
+				return
+			}
+
+			vm, ok := inst.(instance.VM)
+			if !ok {
+				continue
+			}
+
+			err = vm.UpdateMemoryBalloon()
+			if err != nil {
+				logger.Warn("Failed updating memory balloon", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			}
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
@@ -219,6 +220,63 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //    "500":
 //      $ref: "#/responses/InternalServerError"
 
+// swagger:operation GET /1.0/instances?recursion=3 instances instances_get_recursion3
+//
+//  Get the instances and their state
+//
+//  Returns a list of instances (full structs) including their state.
+//
+//  The main difference between recursion=2 and recursion=3 is that the
+//  latter doesn't include the snapshot and backup information, making it
+//  a cheaper way to retrieve the state of many instances in a single API call.
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: all-projects
+//      description: Retrieve instances from all projects
+//      type: boolean
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of instances
+//            items:
+//              $ref: "#/definitions/InstanceFull"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
 func instancesGet(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
@@ -397,7 +455,7 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 					return
 				}
 
-				cs, err := doContainersFullGetFromNode(filteredProjects, memberAddress, allProjects, networkCert, s.ServerCert(), r, instanceType)
+				cs, err := doContainersFullGetFromNode(filteredProjects, memberAddress, allProjects, networkCert, s.ServerCert(), r, instanceType, recursion == 3)
 				if err != nil {
 					for _, inst := range instances {
 						resultErrListAppend(inst, err)
@@ -431,16 +489,32 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 
 			hostInterfaces, _ := net.Interfaces()
 
-			// Get the local instances.
-			localInstancesByID := make(map[int64]instance.Instance)
-			for _, projectName := range filteredProjects {
-				insts, err := instanceLoadNodeProjectAll(r.Context(), s, projectName, instanceType)
+			// Get the local instances in a single pass, even when listing all projects.
+			var insts []instance.Instance
+			if allProjects {
+				insts, err = instance.LoadNodeAll(s, instanceType)
+				if err != nil {
+					return nil, fmt.Errorf("Failed loading instances: %w", err)
+				}
+			} else {
+				insts, err = instanceLoadNodeProjectAll(r.Context(), s, projectName, instanceType)
 				if err != nil {
 					return nil, fmt.Errorf("Failed loading instances for project %q: %w", projectName, err)
 				}
+			}
 
-				for _, inst := range insts {
-					localInstancesByID[int64(inst.ID())] = inst
+			localInstancesByID := make(map[int64]instance.Instance)
+			for _, inst := range insts {
+				localInstancesByID[int64(inst.ID())] = inst
+			}
+
+			// Load the snapshots and backups of all the listed instances at once rather than per instance.
+			var snapshotsByID map[int][]db.InstanceArgs
+			var backupsByID map[int][]db.InstanceBackup
+			if recursion >= 2 && recursion != 3 {
+				snapshotsByID, backupsByID, err = instancesLoadSnapshotsAndBackups(r.Context(), s, localInstancesByID, instances)
+				if err != nil {
+					return nil, err
 				}
 			}
 
@@ -472,7 +546,25 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 							continue
 						}
 
-						c, _, err := inst.RenderFull(hostInterfaces)
+						if recursion == 3 {
+							c, _, err := inst.Render()
+							if err != nil {
+								resultErrListAppend(dbInst, err)
+								continue
+							}
+
+							instFull := &api.InstanceFull{Instance: *c.(*api.Instance)}
+							instFull.State, err = inst.RenderState(hostInterfaces)
+							if err != nil {
+								resultErrListAppend(dbInst, err)
+							} else {
+								resultFullListAppend(instFull)
+							}
+
+							continue
+						}
+
+						c, err := instanceRenderFull(s, inst, hostInterfaces, snapshotsByID[inst.ID()], backupsByID[inst.ID()])
 						if err != nil {
 							resultErrListAppend(dbInst, err)
 						} else {
@@ -539,6 +631,102 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 	return resultFullList, nil
 }
 
+// instancesLoadSnapshotsAndBackups loads the snapshots and backups of the listed local instances, keyed by
+// instance ID, with a fixed number of queries.
+func instancesLoadSnapshotsAndBackups(ctx context.Context, s *state.State, localInstancesByID map[int64]instance.Instance, instances []db.Instance) (map[int][]db.InstanceArgs, map[int][]db.InstanceBackup, error) {
+	parents := make([]dbCluster.Instance, 0, len(instances))
+	instanceIDs := make([]int, 0, len(instances))
+	for _, dbInst := range instances {
+		inst, found := localInstancesByID[dbInst.ID]
+		if !found {
+			continue
+		}
+
+		parents = append(parents, dbCluster.Instance{
+			ID:           inst.ID(),
+			Project:      inst.Project().Name,
+			Name:         inst.Name(),
+			Node:         inst.Location(),
+			Type:         inst.Type(),
+			Architecture: inst.Architecture(),
+		})
+
+		instanceIDs = append(instanceIDs, inst.ID())
+	}
+
+	var snapshotsByID map[int][]db.InstanceArgs
+	var backupsByID map[int][]db.InstanceBackup
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		snapshotsByID, err = tx.GetInstancesSnapshotsArgs(ctx, parents...)
+		if err != nil {
+			return err
+		}
+
+		backupsByID, err = tx.GetInstancesBackups(ctx, instanceIDs...)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return snapshotsByID, backupsByID, nil
+}
+
+// instanceRenderFull renders the full representation of an instance from its preloaded snapshots and backups.
+func instanceRenderFull(s *state.State, inst instance.Instance, hostInterfaces []net.Interface, snapshots []db.InstanceArgs, backups []db.InstanceBackup) (*api.InstanceFull, error) {
+	c, _, err := inst.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	instFull := &api.InstanceFull{Instance: *c.(*api.Instance)}
+
+	instFull.State, err = inst.RenderState(hostInterfaces)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snapshotArgs := range snapshots {
+		// Populate profile info that was already loaded.
+		snapshotArgs.Profiles = inst.Profiles()
+
+		snap, err := instance.Load(s, snapshotArgs, inst.Project())
+		if err != nil {
+			return nil, err
+		}
+
+		render, _, err := snap.Render()
+		if err != nil {
+			return nil, err
+		}
+
+		if instFull.Snapshots == nil {
+			instFull.Snapshots = []api.InstanceSnapshot{}
+		}
+
+		instFull.Snapshots = append(instFull.Snapshots, *render.(*api.InstanceSnapshot))
+	}
+
+	for _, args := range backups {
+		render := backup.NewInstanceBackup(s, inst, args.ID, args.Name, args.CreationDate, args.ExpiryDate, args.InstanceOnly, args.OptimizedStorage).Render()
+
+		if instFull.Backups == nil {
+			instFull.Backups = []api.InstanceBackup{}
+		}
+
+		instFull.Backups = append(instFull.Backups, *render)
+	}
+
+	return instFull, nil
+}
+
 // Fetch information about the containers on the given remote node, using the
 // rest API and with a timeout of 30 seconds.
 func doContainersGetFromNode(projects []string, node string, allProjects bool, networkCert *shared.CertInfo, serverCert *shared.CertInfo, r *http.Request, instanceType instancetype.Type) ([]api.Instance, error) {
@@ -590,7 +778,7 @@ func doContainersGetFromNode(projects []string, node string, allProjects bool, n
 	return containers, err
 }
 
-func doContainersFullGetFromNode(projects []string, node string, allProjects bool, networkCert *shared.CertInfo, serverCert *shared.CertInfo, r *http.Request, instanceType instancetype.Type) ([]api.InstanceFull, error) {
+func doContainersFullGetFromNode(projects []string, node string, allProjects bool, networkCert *shared.CertInfo, serverCert *shared.CertInfo, r *http.Request, instanceType instancetype.Type, stateOnly bool) ([]api.InstanceFull, error) {
 	f := func() ([]api.InstanceFull, error) {
 		client, err := cluster.Connect(node, networkCert, serverCert, r, true)
 		if err != nil {
@@ -599,7 +787,12 @@ func doContainersFullGetFromNode(projects []string, node string, allProjects boo
 
 		var instances []api.InstanceFull
 		if allProjects {
-			instances, err = client.GetInstancesFullAllProjects(api.InstanceType(instanceType.String()))
+			if stateOnly {
+				instances, err = client.GetInstancesWithStateAllProjects(api.InstanceType(instanceType.String()), nil)
+			} else {
+				instances, err = client.GetInstancesFullAllProjects(api.InstanceType(instanceType.String()))
+			}
+
 			if err != nil {
 				return nil, fmt.Errorf("Failed to get instances from member %s: %w", node, err)
 			}
@@ -607,7 +800,13 @@ func doContainersFullGetFromNode(projects []string, node string, allProjects boo
 			for _, project := range projects {
 				client = client.UseProject(project)
 
-				tmpInstances, err := client.GetInstancesFull(api.InstanceType(instanceType.String()))
+				var tmpInstances []api.InstanceFull
+				if stateOnly {
+					tmpInstances, err = client.GetInstancesWithState(api.InstanceType(instanceType.String()), nil)
+				} else {
+					tmpInstances, err = client.GetInstancesFull(api.InstanceType(instanceType.String()))
+				}
+
 				if err != nil {
 					return nil, fmt.Errorf("Failed to get instances from member %s: %w", node, err)
 				}
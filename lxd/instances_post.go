@@ -14,12 +14,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
+	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/archive"
 	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/device"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
@@ -27,6 +29,7 @@ import (
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/resources"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/scriptlet"
 	"github.com/canonical/lxd/lxd/state"
@@ -38,6 +41,7 @@ import (
 	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/version"
+	"github.com/canonical/lxd/shared/ws"
 )
 
 func ensureDownloadedImageFitWithinBudget(s *state.State, r *http.Request, op *operations.Operation, p api.Project, imgAlias string, source api.InstanceSource, imgType string) (*api.Image, error) {
@@ -134,7 +138,7 @@ func createFromImage(s *state.State, r *http.Request, p api.Project, profiles []
 
 	op, err := operations.OperationCreate(s, p.Name, operations.OperationClassTask, operationtype.InstanceCreate, resources, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -185,10 +189,99 @@ func createFromNone(s *state.State, r *http.Request, projectName string, profile
 	}
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceCreate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// createFromDisk creates an empty virtual machine and imports the disk streamed by the client over the operation
+// websocket into its root volume.
+func createFromDisk(s *state.State, r *http.Request, projectName string, profiles []api.Profile, req *api.InstancesPost) response.Response {
+	if s.DB.Cluster.LocalNodeIsEvacuated() {
+		return response.Forbidden(fmt.Errorf("Cluster member is evacuated"))
+	}
+
+	dbType, err := instancetype.New(string(req.Type))
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if dbType != instancetype.VM {
+		return response.BadRequest(fmt.Errorf("Disks can only be imported into virtual machines"))
+	}
+
+	if req.Source.DiskSize < 0 {
+		return response.BadRequest(fmt.Errorf("Invalid disk size %d", req.Source.DiskSize))
+	}
+
+	devices := deviceConfig.NewDevices(req.Devices)
+
+	args := db.InstanceArgs{
+		Project:     projectName,
+		Config:      req.Config,
+		Type:        dbType,
+		Description: req.Description,
+		Devices:     deviceConfig.ApplyDeviceInitialValues(devices, profiles),
+		Ephemeral:   req.Ephemeral,
+		Name:        req.Name,
+		Profiles:    profiles,
+	}
+
+	if req.Architecture != "" {
+		architecture, err := osarch.ArchitectureId(req.Architecture)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		args.Architecture = architecture
+	}
+
+	diskImport := &instanceDiskImportWs{connected: make(chan *websocket.Conn, 1)}
+	diskImport.secret, err = shared.RandomCryptoString()
 	if err != nil {
 		return response.InternalError(err)
 	}
 
+	run := func(op *operations.Operation) error {
+		revert := revert.New()
+		defer revert.Fail()
+
+		inst, err := instanceCreateAsEmpty(s, args)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = inst.Delete(true) })
+
+		conn, err := diskImport.wait()
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		err = instanceImportDisk(s, inst, ws.NewWrapper(conn), req.Source.DiskSize, op)
+		if err != nil {
+			return err
+		}
+
+		revert.Success()
+
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", req.Name)}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, operationtype.InstanceCreate, resources, diskImport.Metadata(), run, nil, diskImport.Connect, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	return operations.OperationResponse(op)
 }
 
@@ -299,6 +392,28 @@ func createFromMigration(s *state.State, r *http.Request, projectName string, pr
 		}
 	}
 
+	// When retrying a failed cluster move, reuse the volume kept on this member by the previous attempt.
+	if inst != nil && clusterMoveSourceName != "" && !req.Source.Refresh {
+		pool, err := storagePools.LoadByInstance(s, inst)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		volType, err := storagePools.InstanceTypeToVolumeType(inst.Type())
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if !pool.Driver().Info().Remote {
+			_, err = storagePools.VolumeDBGet(pool, projectName, inst.Name(), volType)
+			if err != nil && !response.IsNotFoundError(err) {
+				return response.SmartError(err)
+			}
+
+			req.Source.Refresh = err == nil
+		}
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -391,12 +506,12 @@ func createFromMigration(s *state.State, r *http.Request, projectName string, pr
 	if push {
 		op, err = operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, operationtype.InstanceCreate, resources, sink.Metadata(), run, nil, sink.Connect, r)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 	} else {
 		op, err = operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceCreate, resources, nil, run, nil, nil, r)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 	}
 
@@ -559,7 +674,7 @@ func createFromCopy(s *state.State, r *http.Request, projectName string, profile
 
 	op, err := operations.OperationCreate(s, targetProject, operations.OperationClassTask, operationtype.InstanceCreate, resources, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -779,7 +894,7 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 
 	op, err := operations.OperationCreate(s, bInfo.Project, operations.OperationClassTask, operationtype.BackupRestore, resources, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	revert.Success()
@@ -1120,6 +1235,12 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	if s.ServerClustered && !clusterNotification && targetMemberInfo == nil {
+		// Only consider the cluster members that can create the mediated devices of mdev GPU devices.
+		candidateMembers, err = clusterMembersWithGPUMdev(s, r, instancetype.ExpandInstanceDevices(deviceConfig.NewDevices(req.Devices), profiles), candidateMembers)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Run instance placement scriptlet if enabled and no cluster member selected yet.
 		if s.GlobalConfig.InstancesPlacementScriptlet() != "" {
 			leaderAddress, err := d.gateway.LeaderAddress()
@@ -1184,6 +1305,8 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return createFromImage(s, r, *targetProject, profiles, sourceImage, sourceImageRef, &req)
 	case "none":
 		return createFromNone(s, r, targetProjectName, profiles, &req)
+	case "disk":
+		return createFromDisk(s, r, targetProjectName, profiles, &req)
 	case "migration":
 		return createFromMigration(s, r, targetProjectName, profiles, &req)
 	case "copy":
@@ -1357,3 +1480,60 @@ func clusterCopyContainerInternal(s *state.State, r *http.Request, source instan
 	// Run the migration
 	return createFromMigration(s, nil, projectName, profiles, req)
 }
+
+// clusterMembersWithGPUMdev returns the candidate members on which the mediated devices needed by the mdev GPU
+// devices in the supplied devices can be created. The candidate members are returned unchanged if there are no
+// such devices.
+func clusterMembersWithGPUMdev(s *state.State, r *http.Request, devices deviceConfig.Devices, candidateMembers []db.NodeInfo) ([]db.NodeInfo, error) {
+	mdevRequested := false
+	for _, dev := range devices {
+		if dev["type"] == "gpu" && dev["gputype"] == "mdev" {
+			mdevRequested = true
+			break
+		}
+	}
+
+	if !mdevRequested {
+		return candidateMembers, nil
+	}
+
+	var lastErr error
+	members := make([]db.NodeInfo, 0, len(candidateMembers))
+	for _, member := range candidateMembers {
+		var res *api.Resources
+		var err error
+
+		if member.Name == s.ServerName {
+			res, err = resources.GetResources()
+		} else {
+			var client lxd.InstanceServer
+			client, err = cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+			if err == nil {
+				res, err = client.GetServerResources()
+			}
+		}
+
+		if err != nil {
+			logger.Warn("Failed getting cluster member resources", logger.Ctx{"member": member.Name, "err": err})
+			continue
+		}
+
+		err = device.GPUMdevAvailable(devices, res)
+		if err != nil {
+			lastErr = fmt.Errorf("Cluster member %q: %w", member.Name, err)
+			continue
+		}
+
+		members = append(members, member)
+	}
+
+	if len(members) == 0 {
+		if lastErr != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "No cluster member has the requested mediated devices available: %w", lastErr)
+		}
+
+		return nil, api.StatusErrorf(http.StatusBadRequest, "No cluster member has the requested mediated devices available")
+	}
+
+	return members, nil
+}
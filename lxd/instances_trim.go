@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// fstrimReclaimedRegex matches the number of bytes reported by each line of "fstrim -v" output.
+var fstrimReclaimedRegex = regexp.MustCompile(`\((\d+) bytes\) trimmed`)
+
+// fstrimReclaimed returns the total number of bytes reported as trimmed in the "fstrim -v" output.
+func fstrimReclaimed(output string) uint64 {
+	var total uint64
+	for _, match := range fstrimReclaimedRegex.FindAllStringSubmatch(output, -1) {
+		reclaimed, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		total += reclaimed
+	}
+
+	return total
+}
+
+// instanceTrimSchedule returns the trim schedule of the instance, falling back to the one of its root disk pool.
+func instanceTrimSchedule(s *state.State, inst instance.Instance) (string, error) {
+	schedule := inst.ExpandedConfig()["storage.trim.schedule"]
+	if schedule != "" {
+		return schedule, nil
+	}
+
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return "", err
+	}
+
+	return pool.Driver().Config()["storage.trim.schedule"], nil
+}
+
+// instanceTrim discards the unused blocks of the instance's file systems and returns the number of bytes reclaimed.
+// File systems of virtual machines are trimmed from inside the guest through the agent, which passes the discards
+// down to the storage layer. Container file systems are trimmed from the host, which is only done on block backed
+// pools as the file system of the other pools is shared with the host.
+func instanceTrim(s *state.State, inst instance.Instance) (uint64, error) {
+	if inst.Type() == instancetype.Container {
+		pool, err := storagePools.LoadByInstance(s, inst)
+		if err != nil {
+			return 0, err
+		}
+
+		if !pool.Driver().Info().BlockBacking {
+			return 0, nil
+		}
+
+		output, err := shared.RunCommand("fstrim", "-v", inst.RootfsPath())
+		if err != nil {
+			return 0, err
+		}
+
+		return fstrimReclaimed(output), nil
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = devNull.Close() }()
+
+	outputReader, outputWriter, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = outputReader.Close() }()
+
+	req := api.InstanceExecPost{
+		Command: []string{"fstrim", "-av"},
+	}
+
+	cmd, err := inst.Exec(req, devNull, outputWriter, outputWriter)
+	if err != nil {
+		_ = outputWriter.Close()
+		return 0, err
+	}
+
+	// Collect the output until the command is done and the pipe is closed.
+	var output bytes.Buffer
+	outputDone := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&output, outputReader)
+		close(outputDone)
+	}()
+
+	exitCode, err := cmd.Wait()
+	_ = outputWriter.Close()
+	<-outputDone
+	if err != nil {
+		return 0, err
+	}
+
+	if exitCode != 0 {
+		return 0, fmt.Errorf("Failed running fstrim in the instance (exit code %d): %s", exitCode, strings.TrimSpace(output.String()))
+	}
+
+	return fstrimReclaimed(output.String()), nil
+}
+
+// instancesTrimTask trims the file systems of the running instances of this member whose trim schedule is due.
+func instancesTrimTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		instances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Warn("Failed loading instances to trim", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range instances {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if !inst.IsRunning() {
+				continue
+			}
+
+			schedule, err := instanceTrimSchedule(s, inst)
+			if err != nil {
+				logger.Warn("Failed getting instance trim schedule", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+				continue
+			}
+
+			if schedule == "" || !snapshotIsScheduledNow(schedule, int64(inst.ID())) {
+				continue
+			}
+
+			l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+			l.Debug("Trimming instance file systems")
+
+			reclaimed, err := instanceTrim(s, inst)
+			if err != nil {
+				l.Warn("Failed trimming instance file systems", logger.Ctx{"err": err})
+				continue
+			}
+
+			l.Debug("Trimmed instance file systems", logger.Ctx{"reclaimed": reclaimed})
+			s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceTrimmed.Event(inst, map[string]any{"reclaimed": reclaimed}))
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
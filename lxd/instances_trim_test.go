@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFstrimReclaimed(t *testing.T) {
+	tests := []struct {
+		output string
+		want   uint64
+	}{
+		{output: "", want: 0},
+		{output: "/: 1.2 GiB (1288490188 bytes) trimmed on /dev/sda2\n", want: 1288490188},
+		{output: "/boot/efi: 98.8 MiB (103596032 bytes) trimmed on /dev/sda1\n/: 0 B (0 bytes) trimmed on /dev/sda2\n", want: 103596032},
+		{output: "fstrim: /: the discard operation is not supported\n", want: 0},
+	}
+
+	for _, test := range tests {
+		got := fstrimReclaimed(test.output)
+		if got != test.want {
+			t.Errorf("fstrimReclaimed(%q) = %d, want %d", test.output, got, test.want)
+		}
+	}
+}
@@ -15,6 +15,9 @@ type ActionPolice struct {
 	Burst string
 	Mtu   string
 	Drop  bool
+
+	// Pipe passes conforming packets on to the next action instead of accepting them.
+	Pipe bool
 }
 
 // AddAction generates a part of command specific for 'police' action.
@@ -32,18 +35,32 @@ func (a *ActionPolice) AddAction() []string {
 		result = append(result, "mtu", a.Mtu)
 	}
 
-	if a.Drop {
+	if a.Drop && a.Pipe {
+		result = append(result, "conform-exceed", "drop/pipe")
+	} else if a.Drop {
 		result = append(result, "drop")
 	}
 
 	return result
 }
 
+// ActionMirred represents an action of 'mirred' type mirroring packets to another device.
+type ActionMirred struct {
+	Dev string
+}
+
+// AddAction generates a part of command specific for 'mirred' action.
+// The packet is passed on to the next action after it has been mirrored.
+func (a *ActionMirred) AddAction() []string {
+	return []string{"action", "mirred", "egress", "mirror", "dev", a.Dev, "pipe"}
+}
+
 // Filter represents filter object.
 type Filter struct {
 	Dev      string
 	Parent   string
 	Protocol string
+	Priority string
 	Flowid   string
 }
 
@@ -63,6 +80,10 @@ func (u32 *U32Filter) Add() error {
 	}
 
 	cmd = append(cmd, "protocol", u32.Protocol)
+	if u32.Priority != "" {
+		cmd = append(cmd, "prio", u32.Priority)
+	}
+
 	cmd = append(cmd, "u32", "match", "u32", u32.Value, u32.Mask)
 
 	for _, action := range u32.Actions {
@@ -0,0 +1,29 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// DeviceGroupAction represents a lifecycle event action for device groups.
+type DeviceGroupAction string
+
+// All supported lifecycle events for device groups.
+const (
+	DeviceGroupCreated = DeviceGroupAction(api.EventLifecycleDeviceGroupCreated)
+	DeviceGroupDeleted = DeviceGroupAction(api.EventLifecycleDeviceGroupDeleted)
+	DeviceGroupUpdated = DeviceGroupAction(api.EventLifecycleDeviceGroupUpdated)
+	DeviceGroupRenamed = DeviceGroupAction(api.EventLifecycleDeviceGroupRenamed)
+)
+
+// Event creates the lifecycle event for an action on a device group.
+func (a DeviceGroupAction) Event(name string, projectName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "device-groups", name).Project(projectName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
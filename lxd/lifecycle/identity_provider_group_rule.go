@@ -0,0 +1,29 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// IdentityProviderGroupRuleAction represents a lifecycle event action for identity provider group rules.
+type IdentityProviderGroupRuleAction string
+
+// All supported lifecycle events for identity provider group rules.
+const (
+	IdentityProviderGroupRuleCreated = IdentityProviderGroupRuleAction(api.EventLifecycleIdentityProviderGroupRuleCreated)
+	IdentityProviderGroupRuleUpdated = IdentityProviderGroupRuleAction(api.EventLifecycleIdentityProviderGroupRuleUpdated)
+	IdentityProviderGroupRuleRenamed = IdentityProviderGroupRuleAction(api.EventLifecycleIdentityProviderGroupRuleRenamed)
+	IdentityProviderGroupRuleDeleted = IdentityProviderGroupRuleAction(api.EventLifecycleIdentityProviderGroupRuleDeleted)
+)
+
+// Event creates the lifecycle event for an action on an identity provider group rule.
+func (a IdentityProviderGroupRuleAction) Event(ruleName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "auth", "identity-provider-group-rules", ruleName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
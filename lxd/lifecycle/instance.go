@@ -37,6 +37,7 @@ const (
 	InstanceFileRetrieved    = InstanceAction(api.EventLifecycleInstanceFileRetrieved)
 	InstanceFilePushed       = InstanceAction(api.EventLifecycleInstanceFilePushed)
 	InstanceFileDeleted      = InstanceAction(api.EventLifecycleInstanceFileDeleted)
+	InstanceTrimmed          = InstanceAction(api.EventLifecycleInstanceTrimmed)
 )
 
 // Event creates the lifecycle event for an action on an instance.
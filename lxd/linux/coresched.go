@@ -0,0 +1,22 @@
+//go:build linux
+
+package linux
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// CoreSchedulingCookie returns the core scheduling cookie of the thread with the given PID.
+// A zero cookie means that the thread isn't part of any core scheduling domain.
+func CoreSchedulingCookie(pid int) (uint64, error) {
+	var cookie uint64
+
+	err := unix.Prctl(unix.PR_SCHED_CORE, unix.PR_SCHED_CORE_GET, uintptr(pid), unix.PR_SCHED_CORE_SCOPE_THREAD, uintptr(unsafe.Pointer(&cookie)))
+	if err != nil {
+		return 0, err
+	}
+
+	return cookie, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/node"
+	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
 )
@@ -136,7 +137,7 @@ func (c *cmdActivateifneeded) Run(cmd *cobra.Command, args []string) error {
 
 		// Check for scheduled instance snapshots
 		config := inst.ExpandedConfig()
-		if config["snapshots.schedule"] != "" {
+		if config["snapshots.schedule"] != "" || len(util.SnapshotSchedules(config)) > 0 {
 			logger.Debugf("Daemon has scheduled instance snapshots, activating...")
 			_, err := lxd.ConnectLXDUnix("", nil)
 			return err
@@ -158,7 +159,7 @@ func (c *cmdActivateifneeded) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	for _, vol := range volumes {
-		if vol.Config["snapshots.schedule"] != "" {
+		if vol.Config["snapshots.schedule"] != "" || len(util.SnapshotSchedules(vol.Config)) > 0 {
 			logger.Debugf("Daemon has scheduled volume snapshots, activating...")
 			_, err := lxd.ConnectLXDUnix("", nil)
 			return err
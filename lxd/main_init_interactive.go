@@ -265,11 +265,65 @@ func (c *cmdInit) askClustering(config *api.InitPreseed, d lxd.InstanceServer, s
 				return fmt.Errorf("Failed to retrieve cluster information: %w", err)
 			}
 
+			// Get suggested values for the member config keys if supported by the cluster.
+			suggestions := map[string]string{}
+			if client.HasExtension("cluster_join_preseed") {
+				preseed, err := client.GetClusterJoinPreseed(config.Cluster.ServerName, config.Cluster.ServerAddress)
+				if err != nil {
+					return fmt.Errorf("Failed to retrieve cluster join preseed: %w", err)
+				}
+
+				for _, key := range preseed.Cluster.MemberConfig {
+					suggestions[key.Entity+"/"+key.Name+"/"+key.Key] = key.Value
+				}
+			}
+
+			// When the cluster can check the member configuration, use the suggested values and only
+			// prompt for the keys that still need a value.
+			if client.HasExtension("cluster_join_check") {
+				for i, config := range cluster.MemberConfig {
+					cluster.MemberConfig[i].Value = suggestions[config.Entity+"/"+config.Name+"/"+config.Key]
+					if cluster.MemberConfig[i].Value != "" {
+						fmt.Printf("Using %s: %s\n", config.Description, cluster.MemberConfig[i].Value)
+					}
+				}
+
+				check, err := client.CheckClusterJoin(api.ClusterMemberJoinCheckPost{
+					ServerName:   config.Cluster.ServerName,
+					MemberConfig: cluster.MemberConfig,
+				})
+				if err != nil {
+					return fmt.Errorf("Failed checking member configuration: %w", err)
+				}
+
+				for _, missing := range check.MissingConfig {
+					configValue, err := c.global.asker.AskString(fmt.Sprintf("Choose %s: ", missing.Description), "", validate.IsNotEmpty)
+					if err != nil {
+						return err
+					}
+
+					for i, config := range cluster.MemberConfig {
+						if config.Entity == missing.Entity && config.Name == missing.Name && config.Key == missing.Key {
+							cluster.MemberConfig[i].Value = configValue
+						}
+					}
+				}
+
+				config.Cluster.MemberConfig = cluster.MemberConfig
+
+				return nil
+			}
+
 			for i, config := range cluster.MemberConfig {
 				question := fmt.Sprintf("Choose %s: ", config.Description)
 
+				suggestion := suggestions[config.Entity+"/"+config.Name+"/"+config.Key]
+				if suggestion != "" {
+					question = fmt.Sprintf("Choose %s [default=%s]: ", config.Description, suggestion)
+				}
+
 				// Allow for empty values.
-				configValue, err := c.global.asker.AskString(question, "", validate.Optional())
+				configValue, err := c.global.asker.AskString(question, suggestion, validate.Optional())
 				if err != nil {
 					return err
 				}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -15,6 +18,11 @@ import (
 
 type cmdSql struct {
 	global *cmdGlobal
+
+	flagFormat   string
+	flagLocal    bool
+	flagReadOnly bool
+	flagTimeout  int
 }
 
 func (c *cmdSql) Command() *cobra.Command {
@@ -49,9 +57,26 @@ func (c *cmdSql) Command() *cobra.Command {
 
   This command targets the global LXD database and works in both local
   and cluster mode.
+
+  Queries against the global database are executed by the database leader.
+  Use --timeout to give up on queries that don't complete in time, for
+  example when the cluster has lost quorum.
+
+  With --local, the queries against the global database are instead run
+  against a copy of the replica held by the targeted cluster member, which
+  works even if the leader can't be reached. The replica may lag behind the
+  leader, and only SELECT queries are accepted. Only members that are
+  database voters or stand-bys hold a replica.
+
+  With --read-only, only SELECT queries are accepted and none of the
+  queries is run if the batch contains any other statement.
 `
 	cmd.RunE = c.Run
 	cmd.Hidden = true
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", "Format (csv|json|table)"+"``")
+	cmd.Flags().BoolVar(&c.flagLocal, "local", false, "Query the local replica of the global database instead of the leader")
+	cmd.Flags().BoolVar(&c.flagReadOnly, "read-only", false, "Only allow queries that don't modify the database")
+	cmd.Flags().IntVarP(&c.flagTimeout, "timeout", "t", 0, "Number of seconds to wait for the queries before giving up"+"``")
 
 	return cmd
 }
@@ -76,6 +101,18 @@ func (c *cmdSql) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("Invalid database type")
 	}
 
+	if !shared.ValueInSlice(c.flagFormat, []string{"csv", "json", "table"}) {
+		return fmt.Errorf("Invalid format %q", c.flagFormat)
+	}
+
+	if c.flagTimeout < 0 {
+		return fmt.Errorf("Invalid timeout %d", c.flagTimeout)
+	}
+
+	if c.flagLocal && database != "global" {
+		return fmt.Errorf("The --local flag can only be used with the global database")
+	}
+
 	if query == "-" {
 		// Read from stdin
 		bytes, err := io.ReadAll(os.Stdin)
@@ -91,6 +128,11 @@ func (c *cmdSql) Run(cmd *cobra.Command, args []string) error {
 		SkipGetServer: true,
 	}
 
+	// Give the server a chance to report its own timeout before giving up on the request.
+	if c.flagTimeout > 0 {
+		lxdArgs.HTTPClient = &http.Client{Timeout: time.Duration(c.flagTimeout+5) * time.Second}
+	}
+
 	d, err := lxd.ConnectLXDUnix("", &lxdArgs)
 	if err != nil {
 		return err
@@ -102,6 +144,14 @@ func (c *cmdSql) Run(cmd *cobra.Command, args []string) error {
 			url += "&schema=1"
 		}
 
+		if c.flagTimeout > 0 {
+			url += fmt.Sprintf("&timeout=%d", c.flagTimeout)
+		}
+
+		if c.flagLocal {
+			url += "&local=1"
+		}
+
 		response, _, err := d.RawQuery("GET", url, nil, "")
 		if err != nil {
 			return fmt.Errorf("failed to request dump: %w", err)
@@ -120,6 +170,9 @@ func (c *cmdSql) Run(cmd *cobra.Command, args []string) error {
 	data := internalSQLQuery{
 		Database: database,
 		Query:    query,
+		ReadOnly: c.flagReadOnly,
+		Local:    c.flagLocal,
+		Timeout:  c.flagTimeout,
 	}
 
 	response, _, err := d.RawQuery("POST", "/internal/sql", data, "")
@@ -133,12 +186,28 @@ func (c *cmdSql) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.flagFormat == "json" {
+		results := batch.Results
+		if results == nil {
+			results = []internalSQLResult{}
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
 	for i, result := range batch.Results {
 		if len(batch.Results) > 1 {
 			fmt.Printf("=> Query %d:\n\n", i)
 		}
 
-		if result.Type == "select" {
+		if result.Type == "select" && c.flagFormat == "csv" {
+			err = sqlPrintSelectResultCSV(result)
+			if err != nil {
+				return err
+			}
+		} else if result.Type == "select" {
 			sqlPrintSelectResult(result)
 		} else {
 			fmt.Printf("Rows affected: %d\n", result.RowsAffected)
@@ -168,3 +237,27 @@ func sqlPrintSelectResult(result internalSQLResult) {
 
 	table.Render()
 }
+
+// sqlPrintSelectResultCSV prints the result of a select query in CSV format, starting with the column names.
+func sqlPrintSelectResultCSV(result internalSQLResult) error {
+	w := csv.NewWriter(os.Stdout)
+	err := w.Write(result.Columns)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range result.Rows {
+		data := make([]string, 0, len(row))
+		for _, col := range row {
+			data = append(data, fmt.Sprintf("%v", col))
+		}
+
+		err = w.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
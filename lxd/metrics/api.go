@@ -9,6 +9,10 @@ type Metrics struct {
 	Memory         MemoryMetrics                `json:"memory" yaml:"memory"`
 	Network        map[string]NetworkMetrics    `json:"network" yaml:"network"`
 	ProcessesTotal uint64                       `json:"procs_total" yaml:"procs_total"`
+	ProcessesState map[string]uint64            `json:"procs_state" yaml:"procs_state"`
+
+	// Sessions is only reported by the lxd-agent, so it is left unset when the number isn't known.
+	Sessions *uint64 `json:"sessions,omitempty" yaml:"sessions,omitempty"`
 }
 
 // CPUMetrics represents CPU metrics for an instance.
@@ -33,11 +37,14 @@ type DiskMetrics struct {
 
 // FilesystemMetrics represents filesystem metrics for an instance.
 type FilesystemMetrics struct {
+	Device         string `json:"device" yaml:"device"`
 	Mountpoint     string `json:"mountpoint" yaml:"mountpoint"`
 	FSType         string `json:"fstype" yaml:"fstype"`
 	AvailableBytes uint64 `json:"filesystem_avail_bytes" yaml:"filesystem_avail_bytes"`
 	FreeBytes      uint64 `json:"filesystem_free_bytes" yaml:"filesystem_free_bytes"`
 	SizeBytes      uint64 `json:"filesystem_size_bytes" yaml:"filesystem_size_bytes"`
+	Files          uint64 `json:"filesystem_files" yaml:"filesystem_files"`
+	FilesFree      uint64 `json:"filesystem_files_free" yaml:"filesystem_files_free"`
 }
 
 // MemoryMetrics represents memory metrics for an instance.
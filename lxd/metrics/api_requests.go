@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// APIRequest identifies a group of API requests for the request metrics.
+type APIRequest struct {
+	// Endpoint is the route of the request, e.g. "/1.0/instances/{name}".
+	Endpoint string
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// Code is the HTTP status code of the response.
+	Code int
+
+	// Project is the project of the request, empty if the request isn't tied to a single project.
+	Project string
+
+	// IdentityType is the identity type of the caller, e.g. "Client certificate (restricted)" or "unix".
+	IdentityType string
+
+	// Instance is the name of the instance the request targets, if any.
+	Instance string
+}
+
+type apiRequestCounters struct {
+	count    uint64
+	duration time.Duration
+}
+
+var apiRequestsMu sync.Mutex
+var apiRequests = map[APIRequest]*apiRequestCounters{}
+
+// TrackAPIRequest records a completed API request and how long it took to handle.
+func TrackAPIRequest(req APIRequest, duration time.Duration) {
+	apiRequestsMu.Lock()
+	defer apiRequestsMu.Unlock()
+
+	counters, ok := apiRequests[req]
+	if !ok {
+		counters = &apiRequestCounters{}
+		apiRequests[req] = counters
+	}
+
+	counters.count++
+	counters.duration += duration
+}
+
+// APIRequestMetrics returns the metrics of the API requests handled so far.
+// If projectName is not empty, only the requests of that project are included.
+func APIRequestMetrics(projectName string) *MetricSet {
+	out := NewMetricSet(nil)
+
+	apiRequestsMu.Lock()
+	defer apiRequestsMu.Unlock()
+
+	for req, counters := range apiRequests {
+		if projectName != "" && req.Project != projectName {
+			continue
+		}
+
+		labels := map[string]string{
+			"endpoint":      req.Endpoint,
+			"method":        req.Method,
+			"code":          strconv.Itoa(req.Code),
+			"identity_type": req.IdentityType,
+		}
+
+		if req.Project != "" {
+			labels["project"] = req.Project
+		}
+
+		if req.Instance != "" {
+			labels["name"] = req.Instance
+		}
+
+		durationLabels := make(map[string]string, len(labels))
+		for k, v := range labels {
+			durationLabels[k] = v
+		}
+
+		out.AddSamples(APIRequestsTotal, Sample{Labels: labels, Value: float64(counters.count)})
+		out.AddSamples(APIRequestSecondsTotal, Sample{Labels: durationLabels, Value: counters.duration.Seconds()})
+	}
+
+	return out
+}
@@ -91,6 +91,10 @@ func (m *MetricSet) String() string {
 
 	gaugeMetrics := []MetricType{
 		ProcsTotal,
+		ProcsState,
+		Sessions,
+		FilesystemFiles,
+		FilesystemFilesFree,
 		CPUs,
 		GoGoroutines,
 		GoHeapObjects,
@@ -235,12 +239,23 @@ func MetricSetFromAPI(metrics *Metrics, labels map[string]string) (*MetricSet, e
 	}
 
 	// Filesystem stats
-	for dev, stats := range metrics.Filesystem {
+	for key, stats := range metrics.Filesystem {
+		// Older agents key the filesystems by device rather than by mount.
+		dev := stats.Device
+		if dev == "" {
+			dev = key
+		}
+
 		labels := map[string]string{"device": dev, "fstype": stats.FSType, "mountpoint": stats.Mountpoint}
 
 		set.AddSamples(FilesystemAvailBytes, Sample{Value: float64(stats.AvailableBytes), Labels: labels})
 		set.AddSamples(FilesystemFreeBytes, Sample{Value: float64(stats.FreeBytes), Labels: labels})
 		set.AddSamples(FilesystemSizeBytes, Sample{Value: float64(stats.SizeBytes), Labels: labels})
+
+		if stats.Files > 0 {
+			set.AddSamples(FilesystemFiles, Sample{Value: float64(stats.Files), Labels: labels})
+			set.AddSamples(FilesystemFilesFree, Sample{Value: float64(stats.FilesFree), Labels: labels})
+		}
 	}
 
 	// Memory stats
@@ -282,5 +297,15 @@ func MetricSetFromAPI(metrics *Metrics, labels map[string]string) (*MetricSet, e
 	// Procs stats
 	set.AddSamples(ProcsTotal, Sample{Value: float64(metrics.ProcessesTotal)})
 
+	for state, count := range metrics.ProcessesState {
+		// The "state" label is already used for the power state of the instance.
+		set.AddSamples(ProcsState, Sample{Value: float64(count), Labels: map[string]string{"process_state": state}})
+	}
+
+	// Sessions stats
+	if metrics.Sessions != nil {
+		set.AddSamples(Sessions, Sample{Value: float64(*metrics.Sessions)})
+	}
+
 	return set, nil
 }
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -56,3 +57,32 @@ func TestMetricSet_FilterSamples(t *testing.T) {
 		require.Contains(t, hasKeys, "project")
 	}
 }
+
+func TestAPIRequestMetrics(t *testing.T) {
+	req := APIRequest{
+		Endpoint:     "/1.0/instances/{name}",
+		Method:       "GET",
+		Code:         200,
+		Project:      "foo",
+		IdentityType: "unix",
+		Instance:     "c1",
+	}
+
+	TrackAPIRequest(req, time.Second)
+	TrackAPIRequest(req, 2*time.Second)
+	TrackAPIRequest(APIRequest{Endpoint: "/1.0", Method: "GET", Code: 200, Project: "bar", IdentityType: "unix"}, time.Second)
+
+	m := APIRequestMetrics("foo")
+
+	labels := map[string]string{
+		"endpoint":      "/1.0/instances/{name}",
+		"method":        "GET",
+		"code":          "200",
+		"project":       "foo",
+		"identity_type": "unix",
+		"name":          "c1",
+	}
+
+	require.Equal(t, []Sample{{Labels: labels, Value: 2}}, m.set[APIRequestsTotal])
+	require.Equal(t, []Sample{{Labels: labels, Value: 3}}, m.set[APIRequestSecondsTotal])
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"sync"
+)
+
+type rejectedOperation struct {
+	project string
+	limit   string
+}
+
+var rejectedOperationsMu sync.Mutex
+var rejectedOperations = map[rejectedOperation]uint64{}
+
+// TrackRejectedOperation records an operation that was rejected because it exceeded the given project limit.
+func TrackRejectedOperation(projectName string, limit string) {
+	rejectedOperationsMu.Lock()
+	defer rejectedOperationsMu.Unlock()
+
+	rejectedOperations[rejectedOperation{project: projectName, limit: limit}]++
+}
+
+// RejectedOperationMetrics returns the metrics of the operations rejected so far.
+// If projectName is not empty, only the rejected operations of that project are included.
+func RejectedOperationMetrics(projectName string) *MetricSet {
+	out := NewMetricSet(nil)
+
+	rejectedOperationsMu.Lock()
+	defer rejectedOperationsMu.Unlock()
+
+	for rejected, count := range rejectedOperations {
+		if projectName != "" && rejected.project != projectName {
+			continue
+		}
+
+		out.AddSamples(OperationsRejectedTotal, Sample{Labels: map[string]string{"project": rejected.project, "limit": rejected.limit}, Value: float64(count)})
+	}
+
+	return out
+}
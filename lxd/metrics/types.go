@@ -146,6 +146,32 @@ const (
 	GoNextGCBytes
 	// Instances represents the instance count.
 	Instances
+	// APIRequestsTotal represents the number of handled API requests.
+	APIRequestsTotal
+	// APIRequestSecondsTotal represents the total time spent handling API requests in seconds.
+	APIRequestSecondsTotal
+	// BlockReadBytesTotal represents the read bytes for a QEMU block device.
+	BlockReadBytesTotal
+	// BlockReadsCompletedTotal represents the completed reads for a QEMU block device.
+	BlockReadsCompletedTotal
+	// BlockReadSecondsTotal represents the time spent reading from a QEMU block device in seconds.
+	BlockReadSecondsTotal
+	// BlockWrittenBytesTotal represents the written bytes for a QEMU block device.
+	BlockWrittenBytesTotal
+	// BlockWritesCompletedTotal represents the completed writes for a QEMU block device.
+	BlockWritesCompletedTotal
+	// BlockWriteSecondsTotal represents the time spent writing to a QEMU block device in seconds.
+	BlockWriteSecondsTotal
+	// OperationsRejectedTotal represents the number of operations rejected due to project limits.
+	OperationsRejectedTotal
+	// FilesystemFiles represents the number of inodes of a filesystem.
+	FilesystemFiles
+	// FilesystemFilesFree represents the number of free inodes of a filesystem.
+	FilesystemFilesFree
+	// ProcsState represents the number of processes in a given state.
+	ProcsState
+	// Sessions represents the number of logged-in user sessions.
+	Sessions
 )
 
 // MetricNames associates a metric type to its name.
@@ -212,9 +238,22 @@ var MetricNames = map[MetricType]string{
 	NetworkTransmitPacketsTotal: "lxd_network_transmit_packets_total",
 	OperationsTotal:             "lxd_operations_total",
 	ProcsTotal:                  "lxd_procs_total",
+	ProcsState:                  "lxd_procs_state",
+	Sessions:                    "lxd_sessions",
+	FilesystemFiles:             "lxd_filesystem_files",
+	FilesystemFilesFree:         "lxd_filesystem_files_free",
 	UptimeSeconds:               "lxd_uptime_seconds",
 	WarningsTotal:               "lxd_warnings_total",
 	Instances:                   "lxd_instances",
+	APIRequestsTotal:            "lxd_api_requests_total",
+	APIRequestSecondsTotal:      "lxd_api_request_duration_seconds_total",
+	BlockReadBytesTotal:         "lxd_block_read_bytes_total",
+	BlockReadsCompletedTotal:    "lxd_block_reads_completed_total",
+	BlockReadSecondsTotal:       "lxd_block_read_seconds_total",
+	BlockWrittenBytesTotal:      "lxd_block_written_bytes_total",
+	BlockWritesCompletedTotal:   "lxd_block_writes_completed_total",
+	BlockWriteSecondsTotal:      "lxd_block_write_seconds_total",
+	OperationsRejectedTotal:     "lxd_operations_rejected_total",
 }
 
 // MetricHeaders represents the metric headers which contain help messages as specified by OpenMetrics.
@@ -281,7 +320,20 @@ var MetricHeaders = map[MetricType]string{
 	NetworkTransmitPacketsTotal: "# HELP lxd_network_transmit_packets_total The amount of transmitted packets on a given interface.",
 	OperationsTotal:             "# HELP lxd_operations_total The number of running operations",
 	ProcsTotal:                  "# HELP lxd_procs_total The number of running processes.",
+	ProcsState:                  "# HELP lxd_procs_state The number of processes in a given state.",
+	Sessions:                    "# HELP lxd_sessions The number of logged-in user sessions.",
+	FilesystemFiles:             "# HELP lxd_filesystem_files The number of inodes of the filesystem.",
+	FilesystemFilesFree:         "# HELP lxd_filesystem_files_free The number of free inodes of the filesystem.",
 	UptimeSeconds:               "# HELP lxd_uptime_seconds The daemon uptime in seconds.",
 	WarningsTotal:               "# HELP lxd_warnings_total The number of active warnings.",
 	Instances:                   "# HELP lxd_instances The number of instances.",
+	APIRequestsTotal:            "# HELP lxd_api_requests_total The number of handled API requests.",
+	APIRequestSecondsTotal:      "# HELP lxd_api_request_duration_seconds_total The total time spent handling API requests in seconds.",
+	BlockReadBytesTotal:         "# HELP lxd_block_read_bytes_total The total number of bytes read from a QEMU block device.",
+	BlockReadsCompletedTotal:    "# HELP lxd_block_reads_completed_total The total number of completed reads from a QEMU block device.",
+	BlockReadSecondsTotal:       "# HELP lxd_block_read_seconds_total The total time spent reading from a QEMU block device in seconds.",
+	BlockWrittenBytesTotal:      "# HELP lxd_block_written_bytes_total The total number of bytes written to a QEMU block device.",
+	BlockWritesCompletedTotal:   "# HELP lxd_block_writes_completed_total The total number of completed writes to a QEMU block device.",
+	BlockWriteSecondsTotal:      "# HELP lxd_block_write_seconds_total The total time spent writing to a QEMU block device in seconds.",
+	OperationsRejectedTotal:     "# HELP lxd_operations_rejected_total The number of operations rejected due to project limits.",
 }
@@ -138,6 +138,8 @@ type migrationSourceWs struct {
 	migrationFields
 
 	clusterMoveSourceName string
+	bwlimit               int64
+	retry                 bool
 
 	pushCertificate  string
 	pushOperationURL string
@@ -20,13 +20,14 @@ import (
 	"github.com/canonical/lxd/shared/logger"
 )
 
-func newMigrationSource(inst instance.Instance, stateful bool, instanceOnly bool, allowInconsistent bool, clusterMoveSourceName string, pushTarget *api.InstancePostTarget) (*migrationSourceWs, error) {
+func newMigrationSource(inst instance.Instance, stateful bool, instanceOnly bool, allowInconsistent bool, clusterMoveSourceName string, bwlimit int64, pushTarget *api.InstancePostTarget) (*migrationSourceWs, error) {
 	ret := migrationSourceWs{
 		migrationFields: migrationFields{
 			instance:          inst,
 			allowInconsistent: allowInconsistent,
 		},
 		clusterMoveSourceName: clusterMoveSourceName,
+		bwlimit:               bwlimit,
 	}
 
 	if pushTarget != nil {
@@ -110,7 +111,7 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 			return nil, fmt.Errorf("Failed getting migration source control connection: %w", err)
 		}
 
-		return wsConn, nil
+		return migration.NewBwlimitConn(wsConn, s.bwlimit), nil
 	}
 
 	filesystemConnFunc := func(ctx context.Context) (io.ReadWriteCloser, error) {
@@ -124,7 +125,7 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 			return nil, fmt.Errorf("Failed getting migration source filesystem connection: %w", err)
 		}
 
-		return wsConn, nil
+		return migration.NewBwlimitConn(wsConn, s.bwlimit), nil
 	}
 
 	s.instance.SetOperation(migrateOp)
@@ -144,8 +145,10 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 				}
 			},
 			ClusterMoveSourceName: s.clusterMoveSourceName,
+			BandwidthLimit:        s.bwlimit,
 		},
 		AllowInconsistent: s.allowInconsistent,
+		Retry:             s.retry,
 	})
 	if err != nil {
 		l.Error("Failed migration on source", logger.Ctx{"err": err})
@@ -0,0 +1,72 @@
+package migration
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// bwlimitMaxBurst is how far behind its limit a connection can fall before the unused bandwidth is discarded.
+// This prevents a connection that was idle for a while from sending at full speed.
+const bwlimitMaxBurst = time.Second
+
+// bwlimitConn is a connection whose writes are throttled to a maximum rate.
+type bwlimitConn struct {
+	io.ReadWriteCloser
+
+	mu      sync.Mutex
+	limit   int64
+	start   time.Time
+	written int64
+}
+
+// NewBwlimitConn wraps the connection so that writing to it doesn't exceed limit bytes per second.
+// The connection is returned as is if limit isn't positive.
+func NewBwlimitConn(conn io.ReadWriteCloser, limit int64) io.ReadWriteCloser {
+	if limit <= 0 {
+		return conn
+	}
+
+	return &bwlimitConn{
+		ReadWriteCloser: conn,
+		limit:           limit,
+		start:           time.Now(),
+	}
+}
+
+// Write writes p to the connection, waiting as needed to keep the rate below the limit.
+func (c *bwlimitConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Write in chunks of a tenth of the limit to keep the rate smooth.
+	chunkSize := max(c.limit/10, 1)
+
+	total := 0
+	for len(p) > 0 {
+		elapsed := time.Since(c.start)
+		expected := time.Duration(float64(c.written) / float64(c.limit) * float64(time.Second))
+		if elapsed-expected > bwlimitMaxBurst {
+			c.start = time.Now()
+			c.written = 0
+		}
+
+		n, err := c.ReadWriteCloser.Write(p[:min(int64(len(p)), chunkSize)])
+		total += n
+		c.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+
+		p = p[n:]
+
+		// Wait until the data written so far is within the limit.
+		expected = time.Duration(float64(c.written) / float64(c.limit) * float64(time.Second))
+		elapsed = time.Since(c.start)
+		if expected > elapsed {
+			time.Sleep(expected - elapsed)
+		}
+	}
+
+	return total, nil
+}
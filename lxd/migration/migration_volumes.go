@@ -60,6 +60,7 @@ type VolumeSourceArgs struct {
 	Info               *Info
 	VolumeOnly         bool
 	ClusterMove        bool
+	BandwidthLimit     int64 // Maximum transfer rate in bytes per second (0 for no limit).
 }
 
 // VolumeTargetArgs represents the arguments needed to setup a volume migration sink.
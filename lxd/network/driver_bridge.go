@@ -30,6 +30,7 @@ import (
 	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/locking"
 	"github.com/canonical/lxd/lxd/network/acl"
 	"github.com/canonical/lxd/lxd/network/openvswitch"
 	"github.com/canonical/lxd/lxd/project"
@@ -70,6 +71,7 @@ func (n *bridge) DBType() db.NetworkType {
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
+	info.ForwardHealthCheck = true
 
 	return info
 }
@@ -1992,6 +1994,16 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			}
 		}
 
+		// Serve the DNS records of instances from a dedicated hosts directory, which dnsmasq watches for new records.
+		if shared.ValueInSlice(n.config["dns.mode"], []string{"", "managed"}) {
+			err = os.MkdirAll(dnsmasq.DNSRecordsPath(n.name), 0755)
+			if err != nil {
+				return err
+			}
+
+			dnsmasqCmd = append(dnsmasqCmd, fmt.Sprintf("--hostsdir=%s", dnsmasq.DNSRecordsPath(n.name)))
+		}
+
 		// Create a config file to contain additional config (and to prevent dnsmasq from reading /etc/dnsmasq.conf)
 		err = os.WriteFile(shared.VarPath("networks", n.name, "dnsmasq.raw"), []byte(fmt.Sprintf("%s\n", n.config["raw.dnsmasq"])), 0644)
 		if err != nil {
@@ -2169,6 +2181,9 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 func (n *bridge) Stop() error {
 	n.logger.Debug("Stop")
 
+	// Stop the address forward health checks.
+	forwardHealthStop(n.id)
+
 	if !n.isRunning() {
 		return nil
 	}
@@ -3061,6 +3076,13 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType request.ClientType) (net.IP, error) {
 	memberSpecific := true // bridge supports per-member forwards.
 
+	unlock, err := locking.Lock(context.TODO(), n.forwardLockName())
+	if err != nil {
+		return nil, err
+	}
+
+	defer unlock()
+
 	// Convert listen address to subnet so we can check its valid and can be used.
 	listenAddressNet, err := ParseIPToNet(forward.ListenAddress)
 	if err != nil {
@@ -3237,10 +3259,17 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 func (n *bridge) ForwardUpdate(listenAddress string, req api.NetworkForwardPut, clientType request.ClientType) error {
 	memberSpecific := true // bridge supports per-member forwards.
 
+	unlock, err := locking.Lock(context.TODO(), n.forwardLockName())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
 	var curForwardID int64
 	var curForward *api.NetworkForward
 
-	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 
 		curForwardID, curForward, err = tx.GetNetworkForward(ctx, n.ID(), memberSpecific, listenAddress)
@@ -3307,10 +3336,18 @@ func (n *bridge) ForwardUpdate(listenAddress string, req api.NetworkForwardPut,
 // ForwardDelete deletes a network forward.
 func (n *bridge) ForwardDelete(listenAddress string, clientType request.ClientType) error {
 	memberSpecific := true // bridge supports per-member forwards.
+
+	unlock, err := locking.Lock(context.TODO(), n.forwardLockName())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
 	var forwardID int64
 	var forward *api.NetworkForward
 
-	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 
 		forwardID, forward, err = tx.GetNetworkForward(ctx, n.ID(), memberSpecific, listenAddress)
@@ -3362,6 +3399,44 @@ func (n *bridge) ForwardDelete(listenAddress string, clientType request.ClientTy
 	return nil
 }
 
+// ForwardState returns the health state of the port specifications of a network forward.
+func (n *bridge) ForwardState(listenAddress string) (*api.NetworkForwardState, error) {
+	memberSpecific := true // bridge supports per-member forwards.
+
+	var forward *api.NetworkForward
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		_, forward, err = tx.GetNetworkForward(ctx, n.ID(), memberSpecific, listenAddress)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return forwardHealthState(n.id, forward), nil
+}
+
+// forwardLockName returns the name of the lock held while changing the address forwards of the network.
+func (n *bridge) forwardLockName() string {
+	return fmt.Sprintf("network.forward.%d", n.id)
+}
+
+// forwardHealthApply re-applies the address forwards after the health of a forward port target changed.
+// It holds the same lock as the forward changes so that it doesn't race with them.
+func (n *bridge) forwardHealthApply() error {
+	unlock, err := locking.Lock(context.TODO(), n.forwardLockName())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	return n.forwardSetupFirewall()
+}
+
 // forwardSetupFirewall applies all network address forwards defined for this network and this member.
 func (n *bridge) forwardSetupFirewall() error {
 	memberSpecific := true // Get all forwards for this cluster member.
@@ -3381,8 +3456,10 @@ func (n *bridge) forwardSetupFirewall() error {
 
 	var fwForwards []firewallDrivers.AddressForward
 	ipVersions := make(map[uint]struct{})
+	forwardListenIPs := make(map[int64]net.IP, len(forwards))
+	forwardPortMaps := make(map[int64][]*forwardPortMap, len(forwards))
 
-	for _, forward := range forwards {
+	for forwardID, forward := range forwards {
 		// Convert listen address to subnet so we can check its valid and can be used.
 		listenAddressNet, err := ParseIPToNet(forward.ListenAddress)
 		if err != nil {
@@ -3401,7 +3478,25 @@ func (n *bridge) forwardSetupFirewall() error {
 			return fmt.Errorf("Failed validating firewall address forward for listen address %q: %w", forward.ListenAddress, err)
 		}
 
-		fwForwards = append(fwForwards, n.forwardConvertToFirewallForwards(listenAddressNet.IP, net.ParseIP(forward.Config["target_address"]), portMaps)...)
+		forwardListenIPs[forwardID] = listenAddressNet.IP
+		forwardPortMaps[forwardID] = portMaps
+	}
+
+	// Start or update the health checks of the forward ports.
+	forwardHealthSetup(n.logger, n.id, forwards, forwardPortMaps, n.forwardHealthApply)
+
+	for forwardID, forward := range forwards {
+		portMaps := forwardPortMaps[forwardID]
+
+		// Forward the traffic of the health checked ports to their currently active address.
+		for portSpecID, portSpec := range forward.Ports {
+			activeAddress := forwardHealthActiveAddress(n.id, forward.ListenAddress, portSpec)
+			if activeAddress != nil {
+				portMaps[portSpecID].target.address = activeAddress
+			}
+		}
+
+		fwForwards = append(fwForwards, n.forwardConvertToFirewallForwards(forwardListenIPs[forwardID], net.ParseIP(forward.Config["target_address"]), portMaps)...)
 	}
 
 	if len(forwards) > 0 {
@@ -3641,6 +3736,68 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 	return leases, nil
 }
 
+// DNSRecords returns a list of the DNS records served for instances on the bridged network. It will reach out to
+// other cluster members as needed.
+// The projectName passed here refers to the initial project from the API request which may differ from the network's project.
+func (n *bridge) DNSRecords(projectName string, clientType request.ClientType) ([]api.NetworkDNSRecord, error) {
+	records := []api.NetworkDNSRecord{}
+
+	localRecords, err := dnsmasq.DNSRecords(n.name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading DNS records: %w", err)
+	}
+
+	for _, record := range localRecords {
+		// Other cluster members get all local records, they are filtered on the member handling the request.
+		if clientType == request.ClientTypeNormal && record.Project != projectName {
+			continue
+		}
+
+		recordType := "AAAA"
+		if record.IP.To4() != nil {
+			recordType = "A"
+		}
+
+		records = append(records, api.NetworkDNSRecord{
+			Name:     record.Name,
+			Type:     recordType,
+			Address:  record.IP.String(),
+			Project:  record.Project,
+			Instance: record.Instance,
+			Device:   record.Device,
+			Location: n.state.ServerName,
+		})
+	}
+
+	// Collect records from other servers.
+	if clientType == request.ClientTypeNormal {
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return nil, err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			memberRecords, err := client.GetNetworkDNSRecords(n.name)
+			if err != nil {
+				return err
+			}
+
+			for _, record := range memberRecords {
+				if record.Project == projectName {
+					records = append(records, record)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return records, nil
+}
+
 // UsesDNSMasq indicates if network's config indicates if it needs to use dnsmasq.
 func (n *bridge) UsesDNSMasq() bool {
 	return n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) || !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"})
@@ -30,6 +30,7 @@ type Info struct {
 	Projects           bool // Indicates if driver can be used in network enabled projects.
 	NodeSpecificConfig bool // Whether driver has cluster node specific config as a prerequisite for creation.
 	AddressForwards    bool // Indicates if driver supports address forwards.
+	ForwardHealthCheck bool // Indicates if driver supports health checks and failover of address forward ports.
 	LoadBalancers      bool // Indicates if driver supports load balancers.
 	Peering            bool // Indicates if the driver supports network peering.
 }
@@ -51,6 +52,12 @@ type loadBalancerPortMap struct {
 	listenPorts []uint64
 	protocol    string
 	targets     []forwardTarget
+
+	// sessionPersistence is whether the connections of a client are sent to the same target based on its IP.
+	sessionPersistence bool
+
+	// sessionPersistenceTimeout is how long in seconds an idle client keeps its target (0 for no timeout).
+	sessionPersistenceTimeout uint64
 }
 
 // subnetUsageType indicates the type of use for a subnet.
@@ -527,6 +534,11 @@ func (n *common) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
 	return nil
 }
 
+// MembersRefresh is a no-op.
+func (n *common) MembersRefresh() error {
+	return nil
+}
+
 // notifyDependentNetworks allows any dependent networks to apply changes to themselves when this network changes.
 func (n *common) notifyDependentNetworks(changedKeys []string) {
 	if n.Project() != api.ProjectDefaultName {
@@ -659,6 +671,12 @@ func (n *common) bgpClear(config map[string]string) error {
 		return err
 	}
 
+	// Clear existing load balancer prefixes for network.
+	err = n.state.BGP.RemovePrefixByOwner(fmt.Sprintf("network_%d_load_balancer", n.id))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -925,6 +943,44 @@ func (n *common) forwardValidate(listenAddress net.IP, forward api.NetworkForwar
 			return nil, fmt.Errorf("Target address is not within the network subnet in port specification %d", portSpecID)
 		}
 
+		// Check failover address and health check.
+		if portSpec.FailoverAddress != "" {
+			if portSpec.HealthCheck == nil {
+				return nil, fmt.Errorf("Failover address requires a health check in port specification %d", portSpecID)
+			}
+
+			failoverAddress := net.ParseIP(portSpec.FailoverAddress)
+			if failoverAddress == nil {
+				return nil, fmt.Errorf("Invalid failover address in port specification %d", portSpecID)
+			}
+
+			if failoverAddress.Equal(targetAddress) {
+				return nil, fmt.Errorf("Failover address is same as target address in port specification %d", portSpecID)
+			}
+
+			if listenIsIP4 != (failoverAddress.To4() != nil) {
+				return nil, fmt.Errorf("Cannot mix IP versions in listen address and port specification %d failover address", portSpecID)
+			}
+
+			if netSubnet != nil && !SubnetContainsIP(netSubnet, failoverAddress) {
+				return nil, fmt.Errorf("Failover address is not within the network subnet in port specification %d", portSpecID)
+			}
+		}
+
+		if portSpec.HealthCheck != nil {
+			if !shared.ValueInSlice(portSpec.HealthCheck.Protocol, []string{"", "tcp", "icmp"}) {
+				return nil, fmt.Errorf("Invalid health check protocol in port specification %d, protocol must be one of: tcp, icmp", portSpecID)
+			}
+
+			if portSpec.HealthCheck.Port < 0 || portSpec.HealthCheck.Port > 65535 {
+				return nil, fmt.Errorf("Invalid health check port in port specification %d", portSpecID)
+			}
+
+			if portSpec.HealthCheck.Interval < 0 || portSpec.HealthCheck.Interval > 3600 {
+				return nil, fmt.Errorf("Invalid health check interval in port specification %d, interval must be between 1 and 3600 seconds (or 0 for the default)", portSpecID)
+			}
+		}
+
 		// Check valid listen port(s) supplied.
 		listenPortRanges := shared.SplitNTrimSpace(portSpec.ListenPort, ",", -1, true)
 		if len(listenPortRanges) <= 0 {
@@ -1005,6 +1061,11 @@ func (n *common) ForwardDelete(listenAddress string, clientType request.ClientTy
 	return ErrNotImplemented
 }
 
+// ForwardState returns ErrNotImplemented for drivers that do not support forward health checks.
+func (n *common) ForwardState(listenAddress string) (*api.NetworkForwardState, error) {
+	return nil, ErrNotImplemented
+}
+
 // forwardBGPSetupPrefixes exports external forward addresses as prefixes.
 func (n *common) forwardBGPSetupPrefixes() error {
 	var fwdListenAddresses map[int64]string
@@ -1173,18 +1234,32 @@ func (n *common) loadBalancerValidate(listenAddress net.IP, forward api.NetworkL
 		}
 	}
 
-	// Look for any unknown config fields.
-	for k := range forward.Config {
+	// Validate config fields.
+	loadBalancerConfigRules := map[string]func(value string) error{
+		"bgp.advertise":                 validate.Optional(validate.IsBool),
+		"bgp.withdraw_on_backends_down": validate.Optional(validate.IsBool),
+	}
+
+	for k, v := range forward.Config {
 		// User keys are not validated.
 		if shared.IsUserConfig(k) {
 			continue
 		}
 
-		return nil, fmt.Errorf("Invalid option %q", k)
+		validator, found := loadBalancerConfigRules[k]
+		if !found {
+			return nil, fmt.Errorf("Invalid option %q", k)
+		}
+
+		err := validator(v)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for option %q: %w", k, err)
+		}
 	}
 
 	// Validate port rules.
 	validPortProcols := []string{"tcp", "udp"}
+	validSessionPersistences := []string{"", "none", "source_ip"}
 
 	// Used to ensure that each listen port is only used once.
 	listenPorts := map[string]map[int64]struct{}{
@@ -1254,10 +1329,24 @@ func (n *common) loadBalancerValidate(listenAddress net.IP, forward api.NetworkL
 			return nil, fmt.Errorf("Missing listen port in port specification %d", portSpecID)
 		}
 
+		if !shared.ValueInSlice(portSpec.SessionPersistence, validSessionPersistences) {
+			return nil, fmt.Errorf("Invalid session persistence in port specification %d, session persistence must be one of: none, source_ip", portSpecID)
+		}
+
+		if portSpec.SessionPersistenceTimeout < 0 {
+			return nil, fmt.Errorf("Invalid session persistence timeout in port specification %d, timeout must be positive", portSpecID)
+		}
+
+		if portSpec.SessionPersistenceTimeout > 0 && portSpec.SessionPersistence != "source_ip" {
+			return nil, fmt.Errorf("Session persistence timeout requires the source_ip session persistence in port specification %d", portSpecID)
+		}
+
 		portMap := loadBalancerPortMap{
-			listenPorts: make([]uint64, 0),
-			protocol:    portSpec.Protocol,
-			targets:     make([]forwardTarget, 0, len(portSpec.TargetBackend)),
+			listenPorts:               make([]uint64, 0),
+			protocol:                  portSpec.Protocol,
+			targets:                   make([]forwardTarget, 0, len(portSpec.TargetBackend)),
+			sessionPersistence:        portSpec.SessionPersistence == "source_ip",
+			sessionPersistenceTimeout: uint64(portSpec.SessionPersistenceTimeout),
 		}
 
 		for _, pr := range listenPortRanges {
@@ -1318,78 +1407,9 @@ func (n *common) LoadBalancerDelete(listenAddress string, clientType request.Cli
 	return ErrNotImplemented
 }
 
-// loadBalancerBGPSetupPrefixes exports external load balancer addresses as prefixes.
-func (n *common) loadBalancerBGPSetupPrefixes() error {
-	var listenAddresses map[int64]string
-
-	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		var err error
-
-		// Retrieve network forwards before clearing existing prefixes, and separate them by IP family.
-		listenAddresses, err = tx.GetNetworkLoadBalancerListenAddresses(ctx, n.ID(), true)
-
-		return err
-	})
-	if err != nil {
-		return fmt.Errorf("Failed loading network forwards: %w", err)
-	}
-
-	listenAddressesByFamily := map[uint][]string{
-		4: make([]string, 0),
-		6: make([]string, 0),
-	}
-
-	for _, listenAddress := range listenAddresses {
-		if strings.Contains(listenAddress, ":") {
-			listenAddressesByFamily[6] = append(listenAddressesByFamily[6], listenAddress)
-		} else {
-			listenAddressesByFamily[4] = append(listenAddressesByFamily[4], listenAddress)
-		}
-	}
-
-	// Use load balancer specific owner string (different from the network prefixes) so that these can be
-	// reapplied independently of the network's own prefixes.
-	bgpOwner := fmt.Sprintf("network_%d_load_balancer", n.id)
-
-	// Clear existing address load balancer prefixes for network.
-	err = n.state.BGP.RemovePrefixByOwner(bgpOwner)
-	if err != nil {
-		return err
-	}
-
-	// Add the new prefixes.
-	for _, ipVersion := range []uint{4, 6} {
-		nextHopAddr := n.bgpNextHopAddress(ipVersion)
-		natEnabled := shared.IsTrue(n.config[fmt.Sprintf("ipv%d.nat", ipVersion)])
-		_, netSubnet, _ := net.ParseCIDR(n.config[fmt.Sprintf("ipv%d.address", ipVersion)])
-
-		routeSubnetSize := 128
-		if ipVersion == 4 {
-			routeSubnetSize = 32
-		}
-
-		// Export external forward listen addresses.
-		for _, listenAddress := range listenAddressesByFamily[ipVersion] {
-			listenAddr := net.ParseIP(listenAddress)
-
-			// Don't export internal address forwards (those inside the NAT enabled network's subnet).
-			if natEnabled && netSubnet != nil && netSubnet.Contains(listenAddr) {
-				continue
-			}
-
-			_, ipRouteSubnet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", listenAddr.String(), routeSubnetSize))
-			if err != nil {
-				return err
-			}
-
-			err = n.state.BGP.AddPrefix(*ipRouteSubnet, nextHopAddr, bgpOwner)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+// LoadBalancerBGPRefresh returns ErrNotImplemented for drivers that do not support load balancers.
+func (n *common) LoadBalancerBGPRefresh() error {
+	return ErrNotImplemented
 }
 
 // Leases returns ErrNotImplemented for drivers that don't support address leases.
@@ -1397,6 +1417,11 @@ func (n *common) Leases(projectName string, clientType request.ClientType) ([]ap
 	return nil, ErrNotImplemented
 }
 
+// DNSRecords returns ErrNotImplemented for drivers that don't serve instance DNS records.
+func (n *common) DNSRecords(projectName string, clientType request.ClientType) ([]api.NetworkDNSRecord, error) {
+	return nil, ErrNotImplemented
+}
+
 // PeerCreate returns ErrNotImplemented for drivers that do not support forwards.
 func (n *common) PeerCreate(forward api.NetworkPeersPost) error {
 	return ErrNotImplemented
@@ -1413,7 +1438,8 @@ func (n *common) PeerDelete(peerName string) error {
 }
 
 // peerValidate validates the peer request.
-func (n *common) peerValidate(peerName string, peer *api.NetworkPeerPut) error {
+// The rules argument contains the validators of the config keys supported by the driver, besides user keys.
+func (n *common) peerValidate(peerName string, peer *api.NetworkPeerPut, rules map[string]func(value string) error) error {
 	err := acl.ValidName(peerName)
 	if err != nil {
 		return err
@@ -1434,7 +1460,15 @@ func (n *common) peerValidate(peerName string, peer *api.NetworkPeerPut) error {
 			continue
 		}
 
-		return fmt.Errorf("Invalid option %q", k)
+		validator, found := rules[k]
+		if !found {
+			return fmt.Errorf("Invalid option %q", k)
+		}
+
+		err := validator(peer.Config[k])
+		if err != nil {
+			return fmt.Errorf("Invalid value for peer option %q: %w", k, err)
+		}
 	}
 
 	return nil
@@ -1119,6 +1119,26 @@ func (n *ovn) getLogicalRouterPeerPortName(peerNetworkID int64) openvswitch.OVNR
 	return openvswitch.OVNRouterPort(fmt.Sprintf("%s-lrp-peer-net%d", n.getRouterName(), peerNetworkID))
 }
 
+// getLogicalRouterInterconnectPortName returns OVN logical router port name to use for a remote peer connection.
+func (n *ovn) getLogicalRouterInterconnectPortName(peerID int64) openvswitch.OVNRouterPort {
+	return openvswitch.OVNRouterPort(fmt.Sprintf("%s-lrp-ic-peer%d", n.getRouterName(), peerID))
+}
+
+// getInterconnectSwitchPortName returns OVN transit switch port name to use for a remote peer connection.
+// Transit switch ports are visible to all availability zones, so the name includes the local availability zone.
+func (n *ovn) getInterconnectSwitchPortName(azName string, peerID int64) openvswitch.OVNSwitchPort {
+	return openvswitch.OVNSwitchPort(fmt.Sprintf("%s-%s-lsp-ic-peer%d", azName, n.getNetworkPrefix(), peerID))
+}
+
+// getPeerTransitSwitchName returns OVN transit switch name to use for a remote peer connection.
+func (n *ovn) getPeerTransitSwitchName(peerName string, peerConfig map[string]string) openvswitch.OVNSwitch {
+	if peerConfig["ovn.ic.transit_switch"] != "" {
+		return openvswitch.OVNSwitch(peerConfig["ovn.ic.transit_switch"])
+	}
+
+	return openvswitch.OVNSwitch(fmt.Sprintf("lxd-ts-%s", peerName))
+}
+
 // setupUplinkPort initialises the uplink connection. Returns the derived ovnUplinkVars settings used
 // during the initial creation of the logical network.
 func (n *ovn) setupUplinkPort(routerMAC net.HardwareAddr) (*ovnUplinkVars, error) {
@@ -2880,6 +2900,29 @@ func (n *ovn) Delete(clientType request.ClientType) error {
 			return fmt.Errorf("Failed to get OVN client: %w", err)
 		}
 
+		// Disconnect from the transit switches of remote peers, as those aren't removed with the router.
+		var peers map[int64]*api.NetworkPeer
+
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			peers, err = tx.GetNetworkPeers(ctx, n.ID())
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed loading network peers: %w", err)
+		}
+
+		for peerID, peer := range peers {
+			if peer.Type != api.NetworkPeerTypeRemote {
+				continue
+			}
+
+			err = n.peerRemoteDelete(client, peerID)
+			if err != nil {
+				return err
+			}
+		}
+
 		err = client.LogicalRouterDelete(n.getRouterName())
 		if err != nil {
 			return err
@@ -3104,6 +3147,12 @@ func (n *ovn) Start() error {
 		return err
 	}
 
+	// Refresh exported BGP prefixes for load balancers.
+	err = n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
 	revert.Success()
 
 	// Ensure network is marked as available now its started.
@@ -3454,6 +3503,12 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 		return err
 	}
 
+	// Refresh exported BGP prefixes for load balancers.
+	err = n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
 	revert.Success()
 	return nil
 }
@@ -4204,6 +4259,12 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 		n.logger.Debug("Cleared NIC default rule", logger.Ctx{"port": instancePortName})
 	}
 
+	// Refresh the exported load balancer prefixes that depend on the NIC being up.
+	err = n.loadBalancerBGPRefreshBackends(dnsIPs)
+	if err != nil {
+		n.logger.Warn("Failed refreshing BGP prefixes for load balancers", logger.Ctx{"port": instancePortName, "err": err})
+	}
+
 	revert.Success()
 	return instancePortName, dnsIPs, nil
 }
@@ -4383,6 +4444,12 @@ func (n *ovn) InstanceDevicePortStop(ovsExternalOVNPort openvswitch.OVNSwitchPor
 		}
 	}
 
+	// Refresh the exported load balancer prefixes that depended on the NIC being up.
+	err = n.loadBalancerBGPRefreshBackends(dnsIPs)
+	if err != nil {
+		n.logger.Warn("Failed refreshing BGP prefixes for load balancers", logger.Ctx{"port": instancePortName, "err": err})
+	}
+
 	return nil
 }
 
@@ -5111,6 +5178,215 @@ func (n *ovn) ForwardDelete(listenAddress string, clientType request.ClientType)
 	return nil
 }
 
+// loadBalancerBGPSetupPrefixes exports external load balancer addresses as prefixes.
+// Load balancers with bgp.advertise disabled aren't exported, and load balancers with
+// bgp.withdraw_on_backends_down enabled are only exported while at least one of their backends is up.
+func (n *ovn) loadBalancerBGPSetupPrefixes() error {
+	var loadBalancers map[int64]*api.NetworkLoadBalancer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		// Retrieve load balancers before clearing existing prefixes.
+		loadBalancers, err = tx.GetNetworkLoadBalancers(ctx, n.ID(), true)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
+
+	// Addresses of the started instance NICs, only loaded if needed.
+	var activeIPs map[string]struct{}
+
+	listenAddressesByFamily := map[uint][]string{
+		4: make([]string, 0),
+		6: make([]string, 0),
+	}
+
+	for _, loadBalancer := range loadBalancers {
+		if shared.IsFalse(loadBalancer.Config["bgp.advertise"]) {
+			continue
+		}
+
+		if shared.IsTrue(loadBalancer.Config["bgp.withdraw_on_backends_down"]) {
+			if activeIPs == nil {
+				activeIPs, err = n.loadBalancerActiveIPs()
+				if err != nil {
+					return err
+				}
+			}
+
+			if !n.loadBalancerBackendsUp(loadBalancer, activeIPs) {
+				n.logger.Debug("Not exporting load balancer address as no backend is up", logger.Ctx{"listenAddress": loadBalancer.ListenAddress})
+				continue
+			}
+		}
+
+		if strings.Contains(loadBalancer.ListenAddress, ":") {
+			listenAddressesByFamily[6] = append(listenAddressesByFamily[6], loadBalancer.ListenAddress)
+		} else {
+			listenAddressesByFamily[4] = append(listenAddressesByFamily[4], loadBalancer.ListenAddress)
+		}
+	}
+
+	// Use load balancer specific owner string (different from the network prefixes) so that these can be
+	// reapplied independently of the network's own prefixes.
+	bgpOwner := fmt.Sprintf("network_%d_load_balancer", n.id)
+
+	// Clear existing address load balancer prefixes for network.
+	err = n.state.BGP.RemovePrefixByOwner(bgpOwner)
+	if err != nil {
+		return err
+	}
+
+	// Add the new prefixes.
+	for _, ipVersion := range []uint{4, 6} {
+		nextHopAddr := n.bgpNextHopAddress(ipVersion)
+		natEnabled := shared.IsTrue(n.config[fmt.Sprintf("ipv%d.nat", ipVersion)])
+		_, netSubnet, _ := net.ParseCIDR(n.config[fmt.Sprintf("ipv%d.address", ipVersion)])
+
+		routeSubnetSize := 128
+		if ipVersion == 4 {
+			routeSubnetSize = 32
+		}
+
+		// Export external load balancer listen addresses.
+		for _, listenAddress := range listenAddressesByFamily[ipVersion] {
+			listenAddr := net.ParseIP(listenAddress)
+
+			// Don't export internal load balancers (those inside the NAT enabled network's subnet).
+			if natEnabled && netSubnet != nil && netSubnet.Contains(listenAddr) {
+				continue
+			}
+
+			_, ipRouteSubnet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", listenAddr.String(), routeSubnetSize))
+			if err != nil {
+				return err
+			}
+
+			err = n.state.BGP.AddPrefix(*ipRouteSubnet, nextHopAddr, bgpOwner)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadBalancerActiveIPs returns the addresses of the instance NICs that are started on the network.
+func (n *ovn) loadBalancerActiveIPs() (map[string]struct{}, error) {
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get OVN client: %w", err)
+	}
+
+	portIPs, err := client.LogicalSwitchIPs(n.getIntSwitchName())
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting logical switch port IPs: %w", err)
+	}
+
+	activeIPs := make(map[string]struct{})
+	for _, ips := range portIPs {
+		for _, ip := range ips {
+			activeIPs[ip.String()] = struct{}{}
+		}
+	}
+
+	return activeIPs, nil
+}
+
+// loadBalancerBackendsUp returns true if the target address of any of the load balancer backends belongs to a
+// started instance NIC.
+func (n *ovn) loadBalancerBackendsUp(loadBalancer *api.NetworkLoadBalancer, activeIPs map[string]struct{}) bool {
+	for _, backend := range loadBalancer.Backends {
+		targetAddress := net.ParseIP(backend.TargetAddress)
+		if targetAddress == nil {
+			continue
+		}
+
+		_, found := activeIPs[targetAddress.String()]
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadBalancerBGPWithdrawsOnBackends returns true if any of the load balancers that withdraw their prefix when all
+// backends are down has a backend using one of the addresses.
+func loadBalancerBGPWithdrawsOnBackends(loadBalancers map[int64]*api.NetworkLoadBalancer, backendIPs []net.IP) bool {
+	for _, loadBalancer := range loadBalancers {
+		if shared.IsFalse(loadBalancer.Config["bgp.advertise"]) || shared.IsFalseOrEmpty(loadBalancer.Config["bgp.withdraw_on_backends_down"]) {
+			continue
+		}
+
+		for _, backend := range loadBalancer.Backends {
+			targetAddress := net.ParseIP(backend.TargetAddress)
+			if targetAddress != nil && IPInSlice(targetAddress, backendIPs) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// loadBalancerBGPRefreshBackends refreshes the exported load balancer prefixes on all cluster members if any of
+// the load balancers that withdraw their prefix when all backends are down has a backend using the addresses.
+// The other members are notified through the internal API so the load balancer config isn't rewritten.
+func (n *ovn) loadBalancerBGPRefreshBackends(backendIPs []net.IP) error {
+	if len(backendIPs) == 0 {
+		return nil
+	}
+
+	var loadBalancers map[int64]*api.NetworkLoadBalancer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		loadBalancers, err = tx.GetNetworkLoadBalancers(ctx, n.ID(), false)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
+
+	if !loadBalancerBGPWithdrawsOnBackends(loadBalancers, backendIPs) {
+		return nil
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err = n.LoadBalancerBGPRefresh()
+	if err != nil {
+		return err
+	}
+
+	// Notify all other members to refresh their BGP prefixes.
+	notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	return notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPost, api.NewURL().Path("internal", "networks", n.name, "load-balancers", "bgp-refresh").Project(n.project).String(), nil, "")
+		return err
+	})
+}
+
+// LoadBalancerBGPRefresh refreshes the BGP prefixes exported for the load balancers on the local member.
+func (n *ovn) LoadBalancerBGPRefresh() error {
+	err := n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
+	return nil
+}
+
 // loadBalancerFlattenVIPs flattens port maps into format compatible with OVN load balancers.
 func (n *ovn) loadBalancerFlattenVIPs(listenAddress net.IP, portMaps []*loadBalancerPortMap) []openvswitch.OVNLoadBalancerVIP {
 	var vips []openvswitch.OVNLoadBalancerVIP
@@ -5118,9 +5394,11 @@ func (n *ovn) loadBalancerFlattenVIPs(listenAddress net.IP, portMaps []*loadBala
 	for _, portMap := range portMaps {
 		for i, lp := range portMap.listenPorts {
 			vip := openvswitch.OVNLoadBalancerVIP{
-				ListenAddress: listenAddress,
-				Protocol:      portMap.protocol,
-				ListenPort:    lp,
+				ListenAddress:      listenAddress,
+				Protocol:           portMap.protocol,
+				ListenPort:         lp,
+				SessionPersistence: portMap.sessionPersistence,
+				AffinityTimeout:    portMap.sessionPersistenceTimeout,
 			}
 
 			for _, target := range portMap.targets {
@@ -5561,14 +5839,31 @@ func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
 
 	// Perform create-time validation.
 
-	// Default to network's project if target project not specified.
-	if peer.TargetProject == "" {
-		peer.TargetProject = n.Project()
+	// Default to a peering with a network of this deployment.
+	if peer.Type == "" {
+		peer.Type = api.NetworkPeerTypeLocal
 	}
 
-	// Target network name is required.
-	if peer.TargetNetwork == "" {
-		return api.StatusErrorf(http.StatusBadRequest, "Target network is required")
+	switch peer.Type {
+	case api.NetworkPeerTypeLocal:
+		// Default to network's project if target project not specified.
+		if peer.TargetProject == "" {
+			peer.TargetProject = n.Project()
+		}
+
+		// Target network name is required.
+		if peer.TargetNetwork == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Target network is required")
+		}
+
+	case api.NetworkPeerTypeRemote:
+		// The remote network is reached through the transit switch rather than a target network.
+		if peer.TargetProject != "" || peer.TargetNetwork != "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Target project and network cannot be set for remote peers")
+		}
+
+	default:
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid peer type %q", peer.Type)
 	}
 
 	var peers map[int64]*api.NetworkPeer
@@ -5591,13 +5886,17 @@ func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
 			return api.StatusErrorf(http.StatusConflict, "A peer for that name already exists")
 		}
 
-		if peer.TargetProject == existingPeer.TargetProject && peer.TargetNetwork == existingPeer.TargetNetwork {
+		if peer.Type == api.NetworkPeerTypeLocal && peer.TargetProject == existingPeer.TargetProject && peer.TargetNetwork == existingPeer.TargetNetwork {
 			return api.StatusErrorf(http.StatusConflict, "A peer for that target network already exists")
 		}
+
+		if peer.Type == api.NetworkPeerTypeRemote && existingPeer.Type == api.NetworkPeerTypeRemote && n.getPeerTransitSwitchName(peer.Name, peer.Config) == n.getPeerTransitSwitchName(existingPeer.Name, existingPeer.Config) {
+			return api.StatusErrorf(http.StatusConflict, "A peer for that transit switch already exists")
+		}
 	}
 
 	// Perform general (create and update) validation.
-	err = n.peerValidate(peer.Name, &peer.NetworkPeerPut)
+	err = n.peerValidate(peer.Name, peer.Type, &peer.NetworkPeerPut)
 	if err != nil {
 		return err
 	}
@@ -5697,10 +5996,208 @@ func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
 		}
 	}
 
+	if peer.Type == api.NetworkPeerTypeRemote {
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		revert.Add(func() { _ = n.peerRemoteDelete(client, peerID) })
+
+		err = n.peerRemoteSetup(client, peerID, peer.Name, peer.Config)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert.Success()
 	return nil
 }
 
+// peerValidate validates the peer request, including the interconnection settings of remote peers.
+func (n *ovn) peerValidate(peerName string, peerType string, peer *api.NetworkPeerPut) error {
+	if peerType != api.NetworkPeerTypeRemote {
+		return n.common.peerValidate(peerName, peer, nil)
+	}
+
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=network-peering; group=peering-remote-conf; key=ovn.ic.northbound_connection)
+		// For example, `ssl:192.0.2.10:6645,ssl:192.0.2.11:6645`.
+		// The database must be shared by the OVN deployments of both LXD deployments.
+		// ---
+		//  type: string
+		//  required: yes
+		//  shortdesc: OVN interconnection northbound database connection string
+		"ovn.ic.northbound_connection": validate.IsNotEmpty,
+
+		// lxdmeta:generate(entities=network-peering; group=peering-remote-conf; key=ovn.ic.ca_cert)
+		//
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: SSL certificate authority of the OVN interconnection database
+		"ovn.ic.ca_cert": validate.IsAny,
+
+		// lxdmeta:generate(entities=network-peering; group=peering-remote-conf; key=ovn.ic.client_cert)
+		//
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: SSL client certificate for the OVN interconnection database
+		"ovn.ic.client_cert": validate.IsAny,
+
+		// lxdmeta:generate(entities=network-peering; group=peering-remote-conf; key=ovn.ic.client_key)
+		//
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: SSL client key for the OVN interconnection database
+		"ovn.ic.client_key": validate.IsAny,
+
+		// lxdmeta:generate(entities=network-peering; group=peering-remote-conf; key=ovn.ic.transit_switch)
+		// Both deployments must use the same transit switch.
+		// ---
+		//  type: string
+		//  required: no
+		//  defaultdesc: `lxd-ts-<peer name>`
+		//  shortdesc: Name of the OVN transit switch connecting the networks
+		"ovn.ic.transit_switch": validate.IsAny,
+
+		// lxdmeta:generate(entities=network-peering; group=peering-remote-conf; key=ovn.ic.address)
+		// Comma-separated list of addresses in CIDR notation (for example, `169.254.100.1/24`).
+		// Each network connected to the transit switch needs its own addresses in the same subnets.
+		// ---
+		//  type: string
+		//  required: yes
+		//  shortdesc: Addresses of the network router on the transit switch
+		"ovn.ic.address": validate.IsListOf(validate.IsNetworkAddressCIDR),
+	}
+
+	err := n.common.peerValidate(peerName, peer, rules)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range []string{"ovn.ic.northbound_connection", "ovn.ic.address"} {
+		if peer.Config[key] == "" {
+			return fmt.Errorf("Peer option %q is required for remote peers", key)
+		}
+	}
+
+	if strings.Contains(peer.Config["ovn.ic.northbound_connection"], "ssl:") && (peer.Config["ovn.ic.ca_cert"] == "" || peer.Config["ovn.ic.client_cert"] == "" || peer.Config["ovn.ic.client_key"] == "") {
+		return fmt.Errorf(`Peer options "ovn.ic.ca_cert", "ovn.ic.client_cert" and "ovn.ic.client_key" are required for SSL connections`)
+	}
+
+	return nil
+}
+
+// peerRemoteSetup connects the network's router to the transit switch of a remote peering and enables the
+// exchange of routes with the other OVN availability zones.
+func (n *ovn) peerRemoteSetup(client *openvswitch.OVN, peerID int64, peerName string, peerConfig map[string]string) error {
+	azName, err := client.AvailabilityZoneName()
+	if err != nil {
+		return fmt.Errorf("Failed getting OVN availability zone name: %w", err)
+	}
+
+	if azName == "" {
+		return fmt.Errorf("The OVN availability zone name must be set for OVN interconnection")
+	}
+
+	icClient := openvswitch.NewOVNIC(peerConfig["ovn.ic.northbound_connection"], peerConfig["ovn.ic.ca_cert"], peerConfig["ovn.ic.client_cert"], peerConfig["ovn.ic.client_key"])
+
+	transitSwitch := n.getPeerTransitSwitchName(peerName, peerConfig)
+	err = icClient.TransitSwitchAdd(transitSwitch, true)
+	if err != nil {
+		return fmt.Errorf("Failed adding OVN transit switch %q: %w", transitSwitch, err)
+	}
+
+	// Wait for the OVN interconnection controller to create the transit switch in the local availability zone.
+	for i := 0; ; i++ {
+		_, err = client.LogicalSwitchPorts(transitSwitch)
+		if err == nil {
+			break
+		}
+
+		if i >= 30 {
+			return fmt.Errorf("Transit switch %q not found in the local OVN availability zone, check that ovn-ic is running: %w", transitSwitch, err)
+		}
+
+		time.Sleep(time.Second)
+	}
+
+	routerMAC, err := n.getRouterMAC()
+	if err != nil {
+		return fmt.Errorf("Failed getting router MAC address: %w", err)
+	}
+
+	routerIPs := []*net.IPNet{}
+	for _, address := range shared.SplitNTrimSpace(peerConfig["ovn.ic.address"], ",", -1, true) {
+		ip, ipNet, err := net.ParseCIDR(address)
+		if err != nil {
+			return err
+		}
+
+		ipNet.IP = ip
+		routerIPs = append(routerIPs, ipNet)
+	}
+
+	routerPort := n.getLogicalRouterInterconnectPortName(peerID)
+	err = client.LogicalRouterPortAdd(n.getRouterName(), routerPort, routerMAC, n.getBridgeMTU(), routerIPs, true)
+	if err != nil {
+		return fmt.Errorf("Failed adding router port for remote peer: %w", err)
+	}
+
+	// Bind the router port to the network's chassis so it acts as the gateway towards the transit switch.
+	err = client.LogicalRouterPortLinkChassisGroup(routerPort, n.getChassisGroupName())
+	if err != nil {
+		return fmt.Errorf("Failed linking router port to chassis group: %w", err)
+	}
+
+	switchPort := n.getInterconnectSwitchPortName(azName, peerID)
+	err = client.LogicalSwitchPortAdd(transitSwitch, switchPort, nil, true)
+	if err != nil {
+		return fmt.Errorf("Failed adding transit switch port for remote peer: %w", err)
+	}
+
+	err = client.LogicalSwitchPortLinkRouter(switchPort, routerPort)
+	if err != nil {
+		return fmt.Errorf("Failed linking transit switch port to router port: %w", err)
+	}
+
+	// Advertise the routes of the networks connected to transit switches and learn the routes of the other
+	// availability zones.
+	err = client.NBGlobalSetOptions(map[string]string{
+		"ic-route-adv":   "true",
+		"ic-route-learn": "true",
+	})
+	if err != nil {
+		return fmt.Errorf("Failed enabling OVN interconnection route exchange: %w", err)
+	}
+
+	return nil
+}
+
+// peerRemoteDelete disconnects the network's router from the transit switch of a remote peering.
+// The transit switch itself is left in place as it is shared with the other deployment.
+func (n *ovn) peerRemoteDelete(client *openvswitch.OVN, peerID int64) error {
+	azName, err := client.AvailabilityZoneName()
+	if err != nil {
+		return fmt.Errorf("Failed getting OVN availability zone name: %w", err)
+	}
+
+	err = client.LogicalSwitchPortDelete(n.getInterconnectSwitchPortName(azName, peerID))
+	if err != nil {
+		return fmt.Errorf("Failed deleting transit switch port for remote peer: %w", err)
+	}
+
+	err = client.LogicalRouterPortDelete(n.getLogicalRouterInterconnectPortName(peerID))
+	if err != nil {
+		return fmt.Errorf("Failed deleting router port for remote peer: %w", err)
+	}
+
+	return nil
+}
+
 // peerGetLocalOpts returns peering options prefilled with local router and local NIC routes config.
 // It can then be modified with the target peering network options.
 func (n *ovn) peerGetLocalOpts(localNICRoutes []net.IPNet) (*openvswitch.OVNRouterPeering, error) {
@@ -5852,7 +6349,7 @@ func (n *ovn) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
 		return err
 	}
 
-	err = n.peerValidate(peerName, &req)
+	err = n.peerValidate(peerName, curPeer.Type, &req)
 	if err != nil {
 		return err
 	}
@@ -5884,6 +6381,42 @@ func (n *ovn) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
 		return err
 	}
 
+	revert.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetworkPeer(ctx, n.ID(), curPeerID, curPeer.Writable())
+		})
+	})
+
+	// Reconnect remote peers to the transit switch when their interconnection settings change.
+	if curPeer.Type == api.NetworkPeerTypeRemote {
+		icChanged := false
+		for _, key := range []string{"ovn.ic.northbound_connection", "ovn.ic.ca_cert", "ovn.ic.client_cert", "ovn.ic.client_key", "ovn.ic.transit_switch", "ovn.ic.address"} {
+			if curPeer.Config[key] != req.Config[key] {
+				icChanged = true
+				break
+			}
+		}
+
+		if icChanged {
+			client, err := openvswitch.NewOVN(n.state)
+			if err != nil {
+				return fmt.Errorf("Failed to get OVN client: %w", err)
+			}
+
+			err = n.peerRemoteDelete(client, curPeerID)
+			if err != nil {
+				return err
+			}
+
+			revert.Add(func() { _ = n.peerRemoteSetup(client, curPeerID, curPeer.Name, curPeer.Config) })
+
+			err = n.peerRemoteSetup(client, curPeerID, curPeer.Name, req.Config)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	revert.Success()
 	return nil
 }
@@ -5913,7 +6446,17 @@ func (n *ovn) PeerDelete(peerName string) error {
 		return fmt.Errorf("Cannot delete a Peer that is in use")
 	}
 
-	if peer.Status == api.NetworkStatusCreated {
+	if peer.Type == api.NetworkPeerTypeRemote {
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		err = n.peerRemoteDelete(client, peerID)
+		if err != nil {
+			return err
+		}
+	} else if peer.Status == api.NetworkStatusCreated {
 		targetNet, err := LoadByName(n.state, peer.TargetProject, peer.TargetNetwork)
 		if err != nil {
 			return fmt.Errorf("Failed loading target network: %w", err)
@@ -5976,7 +6519,8 @@ func (n *ovn) forPeers(f func(targetOVNNet *ovn) error) error {
 	}
 
 	for _, peer := range peers {
-		if peer.Status != api.NetworkStatusCreated {
+		// Remote peers have no target network in this deployment.
+		if peer.Status != api.NetworkStatusCreated || peer.Type == api.NetworkPeerTypeRemote {
 			continue
 		}
 
@@ -0,0 +1,65 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func Test_loadBalancerBGPWithdrawsOnBackends(t *testing.T) {
+	loadBalancer := func(config map[string]string, targetAddresses ...string) *api.NetworkLoadBalancer {
+		lb := &api.NetworkLoadBalancer{ListenAddress: "192.0.2.1"}
+		lb.Config = config
+
+		for _, targetAddress := range targetAddresses {
+			lb.Backends = append(lb.Backends, api.NetworkLoadBalancerBackend{TargetAddress: targetAddress})
+		}
+
+		return lb
+	}
+
+	withdraw := map[string]string{"bgp.withdraw_on_backends_down": "true"}
+	backendIPs := []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("fd42::2")}
+
+	tests := []struct {
+		name          string
+		loadBalancers map[int64]*api.NetworkLoadBalancer
+		want          bool
+	}{
+		{
+			name:          "No load balancers",
+			loadBalancers: map[int64]*api.NetworkLoadBalancer{},
+		},
+		{
+			name:          "Backend address without withdrawal",
+			loadBalancers: map[int64]*api.NetworkLoadBalancer{1: loadBalancer(nil, "10.0.0.2")},
+		},
+		{
+			name:          "Backend address with withdrawal but advertisement disabled",
+			loadBalancers: map[int64]*api.NetworkLoadBalancer{1: loadBalancer(map[string]string{"bgp.advertise": "false", "bgp.withdraw_on_backends_down": "true"}, "10.0.0.2")},
+		},
+		{
+			name:          "Other backend address with withdrawal",
+			loadBalancers: map[int64]*api.NetworkLoadBalancer{1: loadBalancer(withdraw, "10.0.0.3", "invalid")},
+		},
+		{
+			name:          "Backend address with withdrawal",
+			loadBalancers: map[int64]*api.NetworkLoadBalancer{1: loadBalancer(withdraw, "10.0.0.3", "10.0.0.2")},
+			want:          true,
+		},
+		{
+			name:          "IPv6 backend address with withdrawal",
+			loadBalancers: map[int64]*api.NetworkLoadBalancer{1: loadBalancer(nil, "10.0.0.2"), 2: loadBalancer(withdraw, "fd42:0::2")},
+			want:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, loadBalancerBGPWithdrawsOnBackends(tt.loadBalancers, backendIPs))
+		})
+	}
+}
@@ -0,0 +1,802 @@
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+
+	lxd "github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/apparmor"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/dnsmasq/dhcpalloc"
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// Default MTU for wireguard networks, leaving room for the WireGuard encapsulation overhead.
+const wireguardMTUDefault = 1420
+
+// Default UDP port the WireGuard interface listens on.
+const wireguardPortDefault = 51820
+
+// Default prefix length of the subnet each cluster member allocates instance addresses from.
+const wireguardMemberPrefixDefault = 24
+
+// wireguardVolatilePublicKey is the member specific config key the public key of the member is published in.
+const wireguardVolatilePublicKey = "volatile.wireguard.public_key"
+
+// wireguard represents a LXD wireguard network.
+//
+// Each cluster member runs a local bridge the instances connect to, and allocates the instance addresses from its
+// own subnet of the overlay. The members are connected together in a full mesh of WireGuard tunnels, with each
+// member publishing its public key in the cluster database for the others to pick up.
+type wireguard struct {
+	common
+}
+
+// DBType returns the network type DB ID.
+func (n *wireguard) DBType() db.NetworkType {
+	return db.NetworkTypeWireguard
+}
+
+// wireguardInterfaceName returns the name of the WireGuard interface of the network.
+func wireguardInterfaceName(networkName string) string {
+	return fmt.Sprintf("%s-wg", networkName)
+}
+
+// wireguardMemberPrefix returns the prefix length of the cluster member subnets.
+func wireguardMemberPrefix(config map[string]string) int {
+	if config["wireguard.member_prefix"] == "" {
+		return wireguardMemberPrefixDefault
+	}
+
+	prefix, err := strconv.Atoi(config["wireguard.member_prefix"])
+	if err != nil {
+		return wireguardMemberPrefixDefault
+	}
+
+	return prefix
+}
+
+// wireguardPort returns the UDP port the WireGuard interfaces listen on.
+func wireguardPort(config map[string]string) string {
+	if config["wireguard.port"] == "" {
+		return strconv.Itoa(wireguardPortDefault)
+	}
+
+	return config["wireguard.port"]
+}
+
+// wireguardMemberSubnet returns the subnet of the overlay the given cluster member allocates addresses from.
+// The first subnet of the overlay isn't used as cluster member IDs start at 1.
+func wireguardMemberSubnet(config map[string]string, memberID int64) (*net.IPNet, error) {
+	_, overlay, err := net.ParseCIDR(config["ipv4.address"])
+	if err != nil {
+		return nil, err
+	}
+
+	overlayPrefix, _ := overlay.Mask.Size()
+	memberPrefix := wireguardMemberPrefix(config)
+
+	if memberID <= 0 || memberID >= int64(1)<<(memberPrefix-overlayPrefix) {
+		return nil, fmt.Errorf("Cluster member ID %d doesn't fit in the /%d member subnets of %q", memberID, memberPrefix, overlay.String())
+	}
+
+	start := binary.BigEndian.Uint32(overlay.IP.To4()) + uint32(memberID)<<(32-memberPrefix)
+	subnetIP := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(subnetIP, start)
+
+	return &net.IPNet{IP: subnetIP, Mask: net.CIDRMask(memberPrefix, 32)}, nil
+}
+
+// ValidateName validates network name.
+func (n *wireguard) ValidateName(name string) error {
+	err := validate.IsInterfaceName(name)
+	if err != nil {
+		return err
+	}
+
+	// The name of the WireGuard interface is derived from the network name so it must be valid too.
+	err = validate.IsInterfaceName(wireguardInterfaceName(name))
+	if err != nil {
+		return fmt.Errorf("Network name too long: %w", err)
+	}
+
+	// Apply common name validation that applies to all network types.
+	return n.common.ValidateName(name)
+}
+
+// Validate network config.
+func (n *wireguard) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv4.address)
+		// Use CIDR notation. Each cluster member allocates the addresses of its instances from its own
+		// subnet of this overlay, see {config:option}`network-wireguard-network-conf:wireguard.member_prefix`.
+		// ---
+		//  type: string
+		//  required: yes
+		//  shortdesc: IPv4 subnet of the overlay network
+		"ipv4.address": validate.Required(validate.IsNetworkV4),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv4.nat)
+		// Traffic between the instances of the overlay network is never translated.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to use NAT for traffic leaving the overlay network
+		"ipv4.nat": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv4.firewall)
+		//
+		// ---
+		//  type: bool
+		//  defaultdesc: `true`
+		//  shortdesc: Whether to generate filtering firewall rules for this network
+		"ipv4.firewall": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv4.dhcp)
+		//
+		// ---
+		//  type: bool
+		//  defaultdesc: `true`
+		//  shortdesc: Whether to allocate IPv4 addresses using DHCP
+		"ipv4.dhcp": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=ipv4.dhcp.expiry)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv4 DHCP
+		//  defaultdesc: `1h`
+		//  shortdesc: When to expire DHCP leases
+		"ipv4.dhcp.expiry": validate.IsAny,
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=bridge.mtu)
+		// The MTU is used for both the local bridge and the WireGuard interface. The default leaves room for
+		// the WireGuard encapsulation overhead on an underlay network with an MTU of 1500.
+		// ---
+		//  type: integer
+		//  defaultdesc: `1420`
+		//  shortdesc: MTU of the overlay network
+		"bridge.mtu": validate.Optional(validate.IsNetworkMTU),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=dns.domain)
+		//
+		// ---
+		//  type: string
+		//  defaultdesc: `lxd`
+		//  shortdesc: Domain to advertise to DHCP clients and use for DNS resolution
+		"dns.domain": validate.IsAny,
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=dns.mode)
+		// Possible values are `none` for no DNS record, `managed` for LXD-generated static records, and `dynamic` for client-generated records.
+		// ---
+		//  type: string
+		//  defaultdesc: `managed`
+		//  shortdesc: DNS registration mode
+		"dns.mode": validate.Optional(validate.IsOneOf("dynamic", "managed", "none")),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=dns.search)
+		// Specify a comma-separated list of domains.
+		// ---
+		//  type: string
+		//  defaultdesc: `dns.domain` value
+		//  shortdesc: Full domain search list
+		"dns.search": validate.IsAny,
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=wireguard.member_prefix)
+		// The prefix length must be longer than the one of {config:option}`network-wireguard-network-conf:ipv4.address`.
+		// The cluster member with ID `N` uses the `N`th subnet of that size in the overlay.
+		// ---
+		//  type: integer
+		//  defaultdesc: `24`
+		//  shortdesc: Prefix length of the subnet of each cluster member
+		"wireguard.member_prefix": validate.Optional(validate.IsInRange(8, 30)),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=wireguard.port)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `51820`
+		//  shortdesc: UDP port the WireGuard interface listens on
+		"wireguard.port": validate.Optional(validate.IsNetworkPort),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=volatile.wireguard.public_key)
+		// LXD generates the key pair when the network starts on the cluster member.
+		// ---
+		//  type: string
+		//  shortdesc: Public key of the WireGuard interface of the cluster member
+		wireguardVolatilePublicKey: validate.IsAny,
+
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=user.*)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: User-provided free-form key/value pairs
+	}
+
+	err := n.validate(config, rules)
+	if err != nil {
+		return err
+	}
+
+	_, overlay, err := net.ParseCIDR(config["ipv4.address"])
+	if err != nil {
+		return err
+	}
+
+	overlayPrefix, _ := overlay.Mask.Size()
+	if wireguardMemberPrefix(config) <= overlayPrefix {
+		return fmt.Errorf(`"wireguard.member_prefix" must be longer than the prefix of "ipv4.address"`)
+	}
+
+	return nil
+}
+
+// bridgeConfig returns the config of the local bridge of the cluster member derived from the network config.
+// The bridge uses the overlay prefix with an address in the member subnet so that the instances consider the
+// whole overlay on-link, and the host answers ARP requests for the addresses of the other members' subnets.
+func (n *wireguard) bridgeConfig(config map[string]string) (map[string]string, error) {
+	_, overlay, err := net.ParseCIDR(config["ipv4.address"])
+	if err != nil {
+		return nil, err
+	}
+
+	subnet, err := wireguardMemberSubnet(config, n.state.DB.Cluster.GetNodeID())
+	if err != nil {
+		return nil, err
+	}
+
+	bridgeConfig := make(map[string]string, len(config))
+	for k, v := range config {
+		if strings.HasPrefix(k, "wireguard.") || strings.HasPrefix(k, "volatile.wireguard.") {
+			continue
+		}
+
+		bridgeConfig[k] = v
+	}
+
+	overlayPrefix, _ := overlay.Mask.Size()
+	bridgeConfig["ipv4.address"] = fmt.Sprintf("%s/%d", dhcpalloc.GetIP(subnet, 1), overlayPrefix)
+	bridgeConfig["ipv4.dhcp.ranges"] = fmt.Sprintf("%s-%s", dhcpalloc.GetIP(subnet, 2), dhcpalloc.GetIP(subnet, -2))
+	bridgeConfig["ipv6.address"] = "none"
+
+	if bridgeConfig["bridge.mtu"] == "" {
+		bridgeConfig["bridge.mtu"] = strconv.Itoa(wireguardMTUDefault)
+	}
+
+	return bridgeConfig, nil
+}
+
+// localBridge returns a bridge driver for the local bridge of the network using the supplied bridge config.
+func (n *wireguard) localBridge(bridgeConfig map[string]string) *bridge {
+	b := &bridge{common: n.common}
+	b.config = bridgeConfig
+
+	return b
+}
+
+// DHCPv4Subnet returns the subnet of the cluster member (if DHCP is enabled on network).
+func (n *wireguard) DHCPv4Subnet() *net.IPNet {
+	if shared.IsFalse(n.config["ipv4.dhcp"]) {
+		return nil
+	}
+
+	subnet, err := wireguardMemberSubnet(n.config, n.state.DB.Cluster.GetNodeID())
+	if err != nil {
+		return nil
+	}
+
+	return subnet
+}
+
+// DHCPv4Ranges returns the addresses of the subnet of the cluster member that can be allocated to instances.
+func (n *wireguard) DHCPv4Ranges() []shared.IPRange {
+	subnet := n.DHCPv4Subnet()
+	if subnet == nil {
+		return nil
+	}
+
+	return []shared.IPRange{{Start: dhcpalloc.GetIP(subnet, 2), End: dhcpalloc.GetIP(subnet, -2)}}
+}
+
+// Create checks whether the bridge and WireGuard interface names are used already.
+func (n *wireguard) Create(clientType request.ClientType) error {
+	n.logger.Debug("Create", logger.Ctx{"clientType": clientType, "config": n.config})
+
+	for _, name := range []string{n.name, wireguardInterfaceName(n.name)} {
+		if InterfaceExists(name) {
+			return fmt.Errorf("Network interface %q already exists", name)
+		}
+	}
+
+	return nil
+}
+
+// isRunning returns whether the network is up.
+func (n *wireguard) isRunning() bool {
+	return InterfaceExists(n.name)
+}
+
+// Delete deletes a network.
+func (n *wireguard) Delete(clientType request.ClientType) error {
+	n.logger.Debug("Delete", logger.Ctx{"clientType": clientType})
+
+	if n.isRunning() {
+		err := n.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Delete apparmor profiles.
+	err := apparmor.NetworkDelete(n.state.OS, n)
+	if err != nil {
+		return err
+	}
+
+	return n.common.delete()
+}
+
+// Rename renames a network.
+func (n *wireguard) Rename(newName string) error {
+	n.logger.Debug("Rename", logger.Ctx{"newName": newName})
+
+	for _, name := range []string{newName, wireguardInterfaceName(newName)} {
+		if InterfaceExists(name) {
+			return fmt.Errorf("Network interface %q already exists", name)
+		}
+	}
+
+	// Bring the network down.
+	if n.isRunning() {
+		err := n.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rename common steps.
+	err := n.common.rename(newName)
+	if err != nil {
+		return err
+	}
+
+	// Bring the network up.
+	err = n.Start()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Start starts the network.
+func (n *wireguard) Start() error {
+	n.logger.Debug("Start")
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { n.setUnavailable() })
+
+	err := n.setup(nil)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	// Ensure network is marked as available now its started.
+	n.setAvailable()
+
+	return nil
+}
+
+// setup sets up the local bridge and the WireGuard interface of the network.
+func (n *wireguard) setup(oldConfig map[string]string) error {
+	// If we are in mock mode, just no-op.
+	if n.state.OS.MockMode {
+		return nil
+	}
+
+	n.logger.Debug("Setting up network")
+
+	bridgeConfig, err := n.bridgeConfig(n.config)
+	if err != nil {
+		return err
+	}
+
+	var oldBridgeConfig map[string]string
+	if oldConfig != nil {
+		// The old config may not be usable anymore (for example after a change of the member prefix), in
+		// which case the bridge is set up from scratch.
+		oldBridgeConfig, _ = n.bridgeConfig(oldConfig)
+	}
+
+	// Setup the local bridge the instances connect to.
+	err = n.localBridge(bridgeConfig).setup(oldBridgeConfig)
+	if err != nil {
+		return err
+	}
+
+	// Answer ARP requests from the instances for the addresses of the other members' subnets.
+	err = util.SysctlSet(fmt.Sprintf("net/ipv4/conf/%s/proxy_arp", n.name), "1")
+	if err != nil {
+		return err
+	}
+
+	// Setup the WireGuard interface.
+	wgName := wireguardInterfaceName(n.name)
+	if !InterfaceExists(wgName) {
+		wgLink := &ip.Wireguard{Link: ip.Link{Name: wgName}}
+		err = wgLink.Add()
+		if err != nil {
+			return err
+		}
+	}
+
+	mtu, err := strconv.ParseUint(bridgeConfig["bridge.mtu"], 10, 32)
+	if err != nil {
+		return fmt.Errorf("Invalid MTU %q: %w", bridgeConfig["bridge.mtu"], err)
+	}
+
+	wgLink := &ip.Link{Name: wgName}
+	err = wgLink.SetMTU(uint32(mtu))
+	if err != nil {
+		return err
+	}
+
+	err = wgLink.SetUp()
+	if err != nil {
+		return err
+	}
+
+	// Publish the public key of the cluster member so that the other members can add it as a peer.
+	_, publicKey, err := n.keys()
+	if err != nil {
+		return err
+	}
+
+	if n.config[wireguardVolatilePublicKey] != publicKey {
+		n.config[wireguardVolatilePublicKey] = publicKey
+
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetwork(ctx, n.project, n.name, n.description, n.config)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed saving WireGuard public key: %w", err)
+		}
+
+		n.notifyPeers()
+	}
+
+	return n.setupPeers()
+}
+
+// keys returns the base64 encoded private and public keys of the local WireGuard interface.
+// The private key is generated the first time and stored with the network.
+func (n *wireguard) keys() (string, string, error) {
+	keyPath := shared.VarPath("networks", n.name, "wireguard.key")
+
+	var privateKey []byte
+
+	content, err := os.ReadFile(keyPath)
+	if err == nil {
+		privateKey, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+		if err != nil || len(privateKey) != curve25519.ScalarSize {
+			return "", "", fmt.Errorf("Invalid WireGuard private key in %q", keyPath)
+		}
+	} else if errors.Is(err, fs.ErrNotExist) {
+		privateKey = make([]byte, curve25519.ScalarSize)
+		_, err = rand.Read(privateKey)
+		if err != nil {
+			return "", "", fmt.Errorf("Failed generating WireGuard private key: %w", err)
+		}
+
+		// Clamp the key as described in RFC 7748.
+		privateKey[0] &= 248
+		privateKey[31] = (privateKey[31] & 127) | 64
+
+		err = os.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(privateKey)+"\n"), 0600)
+		if err != nil {
+			return "", "", fmt.Errorf("Failed writing WireGuard private key: %w", err)
+		}
+	} else {
+		return "", "", err
+	}
+
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed deriving WireGuard public key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(privateKey), base64.StdEncoding.EncodeToString(publicKey), nil
+}
+
+// notifyPeers asks the other online cluster members to refresh their WireGuard peers.
+func (n *wireguard) notifyPeers() {
+	if !n.state.ServerClustered {
+		return
+	}
+
+	notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		n.logger.Warn("Failed notifying cluster members of WireGuard public key change", logger.Ctx{"err": err})
+		return
+	}
+
+	err = notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPost, api.NewURL().Path("internal", "networks", n.name, "members-refresh").Project(n.project).String(), nil, "")
+		return err
+	})
+	if err != nil {
+		n.logger.Warn("Failed notifying cluster members of WireGuard public key change", logger.Ctx{"err": err})
+	}
+}
+
+// setupPeers configures the other cluster members that published their public key as peers of the local
+// WireGuard interface, and routes their subnets through it.
+func (n *wireguard) setupPeers() error {
+	privateKey, _, err := n.keys()
+	if err != nil {
+		return err
+	}
+
+	var members []db.NodeInfo
+	var memberConfigs map[int64]map[string]string
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		members, err = tx.GetNodes(ctx)
+		if err != nil {
+			return err
+		}
+
+		memberConfigs, err = tx.GetNetworkMemberConfigs(ctx, n.id)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading WireGuard peers: %w", err)
+	}
+
+	conf, routes := n.peersConfig(privateKey, n.state.DB.Cluster.GetNodeID(), members, memberConfigs)
+
+	wgName := wireguardInterfaceName(n.name)
+	confPath := shared.VarPath("networks", n.name, "wireguard.conf")
+
+	err = os.WriteFile(confPath, []byte(conf), 0600)
+	if err != nil {
+		return fmt.Errorf("Failed writing WireGuard config: %w", err)
+	}
+
+	_, err = shared.RunCommand("wg", "syncconf", wgName, confPath)
+	if err != nil {
+		return fmt.Errorf("Failed configuring WireGuard interface %q: %w", wgName, err)
+	}
+
+	// Route the subnets of the peers through the WireGuard interface, only changing the routes that differ so
+	// that the traffic to the existing peers isn't interrupted.
+	r := &ip.Route{
+		DevName: wgName,
+		Proto:   "static",
+		Family:  ip.FamilyV4,
+	}
+
+	currentRoutes, err := r.Show()
+	if err != nil {
+		return err
+	}
+
+	addRoutes, removeRoutes := wireguardRoutesDiff(currentRoutes, routes)
+
+	for _, route := range removeRoutes {
+		r := &ip.Route{
+			DevName: wgName,
+			Route:   route,
+			Proto:   "static",
+			Family:  ip.FamilyV4,
+		}
+
+		err = r.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, route := range addRoutes {
+		r := &ip.Route{
+			DevName: wgName,
+			Route:   route,
+			Proto:   "static",
+			Family:  ip.FamilyV4,
+		}
+
+		err = r.Add()
+		if err != nil {
+			return err
+		}
+	}
+
+	n.logger.Debug("Configured WireGuard peers", logger.Ctx{"peers": routes})
+
+	return nil
+}
+
+// wireguardRoutesDiff returns the routes to add and to remove to go from the current routes, as listed by
+// `ip route show`, to the wanted routes.
+func wireguardRoutesDiff(currentRoutes []string, wantedRoutes []string) ([]string, []string) {
+	current := make(map[string]struct{}, len(currentRoutes))
+	removeRoutes := []string{}
+	for _, line := range currentRoutes {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		current[fields[0]] = struct{}{}
+
+		if !shared.ValueInSlice(fields[0], wantedRoutes) {
+			removeRoutes = append(removeRoutes, fields[0])
+		}
+	}
+
+	addRoutes := []string{}
+	for _, route := range wantedRoutes {
+		_, found := current[route]
+		if !found {
+			addRoutes = append(addRoutes, route)
+		}
+	}
+
+	return addRoutes, removeRoutes
+}
+
+// peersConfig returns the config of the local WireGuard interface to pass to `wg syncconf`, with the other cluster
+// members that published their public key as peers, and the subnets of those peers to route through the interface.
+func (n *wireguard) peersConfig(privateKey string, localMemberID int64, members []db.NodeInfo, memberConfigs map[int64]map[string]string) (string, []string) {
+	port := wireguardPort(n.config)
+
+	var conf strings.Builder
+	_, _ = fmt.Fprintf(&conf, "[Interface]\nPrivateKey = %s\nListenPort = %s\n", privateKey, port)
+
+	routes := []string{}
+	for _, member := range members {
+		publicKey := memberConfigs[member.ID][wireguardVolatilePublicKey]
+		if member.ID == localMemberID || publicKey == "" {
+			continue
+		}
+
+		subnet, err := wireguardMemberSubnet(n.config, member.ID)
+		if err != nil {
+			n.logger.Warn("Skipping WireGuard peer", logger.Ctx{"member": member.Name, "err": err})
+			continue
+		}
+
+		host, _, err := net.SplitHostPort(member.Address)
+		if err != nil {
+			n.logger.Warn("Skipping WireGuard peer", logger.Ctx{"member": member.Name, "err": err})
+			continue
+		}
+
+		_, _ = fmt.Fprintf(&conf, "\n[Peer]\nPublicKey = %s\nEndpoint = %s\nAllowedIPs = %s\nPersistentKeepalive = 25\n", publicKey, net.JoinHostPort(host, port), subnet.String())
+		routes = append(routes, subnet.String())
+	}
+
+	return conf.String(), routes
+}
+
+// Stop stops the network.
+func (n *wireguard) Stop() error {
+	n.logger.Debug("Stop")
+
+	wgName := wireguardInterfaceName(n.name)
+	if InterfaceExists(wgName) {
+		wgLink := &ip.Link{Name: wgName}
+		err := wgLink.Delete()
+		if err != nil {
+			return err
+		}
+	}
+
+	// The bridge teardown only depends on the config keys shared with the bridge driver.
+	return n.localBridge(n.config).Stop()
+}
+
+// Update updates the network. Accepts notification boolean indicating if this update request is coming from a
+// cluster notification, in which case do not update the database, just apply local changes needed.
+func (n *wireguard) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
+	n.logger.Debug("Update", logger.Ctx{"clientType": clientType, "newNetwork": newNetwork})
+
+	dbUpdateNeeded, changedKeys, oldNetwork, err := n.common.configChanged(newNetwork)
+	if err != nil {
+		return err
+	}
+
+	if !dbUpdateNeeded {
+		return nil // Nothing changed.
+	}
+
+	// If the network as a whole has not had any previous creation attempts, or the node itself is still
+	// pending, then don't apply the new settings to the node, just to the database record (ready for the
+	// actual global create request to be initiated).
+	if n.Status() == api.NetworkStatusPending || n.LocalStatus() == api.NetworkStatusPending {
+		return n.common.update(newNetwork, targetNode, clientType)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	if len(changedKeys) > 0 {
+		// Define a function which reverts everything.
+		revert.Add(func() {
+			// Reset changes to all nodes and database.
+			_ = n.common.update(oldNetwork, targetNode, clientType)
+
+			// Reset any change that was made to the local network.
+			_ = n.setup(newNetwork.Config)
+		})
+	}
+
+	// Apply changes to all nodes and database.
+	err = n.common.update(newNetwork, targetNode, clientType)
+	if err != nil {
+		return err
+	}
+
+	// Restart the network if needed.
+	if len(changedKeys) > 0 {
+		err = n.setup(oldNetwork.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// MembersRefresh refreshes the WireGuard peers after another cluster member published its public key.
+func (n *wireguard) MembersRefresh() error {
+	if n.LocalStatus() != api.NetworkStatusCreated || !n.isRunning() {
+		return nil
+	}
+
+	return n.setupPeers()
+}
+
+// HandleHeartbeat refreshes the WireGuard peers when the cluster members change.
+func (n *wireguard) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
+	if !n.isRunning() {
+		return nil
+	}
+
+	return n.setupPeers()
+}
+
+// Leases returns a list of leases for the network.
+func (n *wireguard) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
+	bridgeConfig, err := n.bridgeConfig(n.config)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.localBridge(bridgeConfig).Leases(projectName, clientType)
+}
+
+// DNSRecords returns a list of the DNS records served for instances on the network.
+func (n *wireguard) DNSRecords(projectName string, clientType request.ClientType) ([]api.NetworkDNSRecord, error) {
+	bridgeConfig, err := n.bridgeConfig(n.config)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.localBridge(bridgeConfig).DNSRecords(projectName, clientType)
+}
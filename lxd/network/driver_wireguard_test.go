@@ -0,0 +1,253 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+func Test_wireguardMemberSubnet(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]string
+		memberID int64
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "First member with default prefix",
+			config:   map[string]string{"ipv4.address": "10.0.0.0/16"},
+			memberID: 1,
+			want:     "10.0.1.0/24",
+		},
+		{
+			name:     "Last member with default prefix",
+			config:   map[string]string{"ipv4.address": "10.0.0.0/16"},
+			memberID: 255,
+			want:     "10.0.255.0/24",
+		},
+		{
+			name:     "Member ID beyond the overlay",
+			config:   map[string]string{"ipv4.address": "10.0.0.0/16"},
+			memberID: 256,
+			wantErr:  true,
+		},
+		{
+			name:     "Member ID zero",
+			config:   map[string]string{"ipv4.address": "10.0.0.0/16"},
+			memberID: 0,
+			wantErr:  true,
+		},
+		{
+			name:     "Overlay address isn't the network address",
+			config:   map[string]string{"ipv4.address": "10.0.42.1/16"},
+			memberID: 2,
+			want:     "10.0.2.0/24",
+		},
+		{
+			name:     "Custom member prefix",
+			config:   map[string]string{"ipv4.address": "10.1.0.0/24", "wireguard.member_prefix": "28"},
+			memberID: 1,
+			want:     "10.1.0.16/28",
+		},
+		{
+			name:     "Last member with custom member prefix",
+			config:   map[string]string{"ipv4.address": "10.1.0.0/24", "wireguard.member_prefix": "28"},
+			memberID: 15,
+			want:     "10.1.0.240/28",
+		},
+		{
+			name:     "Member ID beyond the overlay with custom member prefix",
+			config:   map[string]string{"ipv4.address": "10.1.0.0/24", "wireguard.member_prefix": "28"},
+			memberID: 16,
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid overlay",
+			config:   map[string]string{"ipv4.address": "foo"},
+			memberID: 1,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subnet, err := wireguardMemberSubnet(tt.config, tt.memberID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, subnet.String())
+		})
+	}
+}
+
+func Test_wireguardValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "Minimal config",
+			config: map[string]string{"ipv4.address": "10.0.0.0/16"},
+		},
+		{
+			name: "Full config",
+			config: map[string]string{
+				"ipv4.address":            "10.0.0.0/16",
+				"ipv4.nat":                "true",
+				"ipv4.dhcp.expiry":        "2h",
+				"bridge.mtu":              "1380",
+				"dns.mode":                "managed",
+				"wireguard.member_prefix": "20",
+				"wireguard.port":          "51821",
+				"user.foo":                "bar",
+			},
+		},
+		{
+			name:    "Missing overlay subnet",
+			config:  map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "Overlay subnet without prefix",
+			config:  map[string]string{"ipv4.address": "10.0.0.1"},
+			wantErr: true,
+		},
+		{
+			name:    "Member prefix not longer than the overlay prefix",
+			config:  map[string]string{"ipv4.address": "10.0.0.0/24", "wireguard.member_prefix": "24"},
+			wantErr: true,
+		},
+		{
+			name:    "Default member prefix not longer than the overlay prefix",
+			config:  map[string]string{"ipv4.address": "10.0.0.0/24"},
+			wantErr: true,
+		},
+		{
+			name:    "Member prefix out of range",
+			config:  map[string]string{"ipv4.address": "10.0.0.0/16", "wireguard.member_prefix": "31"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid port",
+			config:  map[string]string{"ipv4.address": "10.0.0.0/16", "wireguard.port": "70000"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid DNS mode",
+			config:  map[string]string{"ipv4.address": "10.0.0.0/16", "dns.mode": "foo"},
+			wantErr: true,
+		},
+		{
+			name:    "IPv6 isn't supported",
+			config:  map[string]string{"ipv4.address": "10.0.0.0/16", "ipv6.address": "fd42::1/64"},
+			wantErr: true,
+		},
+	}
+
+	n := &wireguard{common: common{name: "wgtest"}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := n.Validate(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_wireguardPeersConfig(t *testing.T) {
+	n := &wireguard{common: common{
+		logger: logger.Log,
+		name:   "wgtest",
+		config: map[string]string{
+			"ipv4.address":   "10.0.0.0/16",
+			"wireguard.port": "51821",
+		},
+	}}
+
+	members := []db.NodeInfo{
+		{ID: 1, Name: "member1", Address: "192.0.2.1:8443"},
+		{ID: 2, Name: "member2", Address: "192.0.2.2:8443"},
+		{ID: 3, Name: "member3", Address: "192.0.2.3:8443"},
+		{ID: 4, Name: "member4", Address: "[2001:db8::4]:8443"},
+		{ID: 5, Name: "member5", Address: "invalid"},
+		{ID: 256, Name: "member256", Address: "192.0.2.6:8443"},
+	}
+
+	memberConfigs := map[int64]map[string]string{
+		1:   {wireguardVolatilePublicKey: "key1"},
+		2:   {wireguardVolatilePublicKey: "key2"},
+		4:   {wireguardVolatilePublicKey: "key4"},
+		5:   {wireguardVolatilePublicKey: "key5"},
+		256: {wireguardVolatilePublicKey: "key256"},
+	}
+
+	conf, routes := n.peersConfig("privkey", 1, members, memberConfigs)
+
+	// The local member, members without a public key, and members without a valid address or subnet are skipped.
+	expected := `[Interface]
+PrivateKey = privkey
+ListenPort = 51821
+
+[Peer]
+PublicKey = key2
+Endpoint = 192.0.2.2:51821
+AllowedIPs = 10.0.2.0/24
+PersistentKeepalive = 25
+
+[Peer]
+PublicKey = key4
+Endpoint = [2001:db8::4]:51821
+AllowedIPs = 10.0.4.0/24
+PersistentKeepalive = 25
+`
+
+	assert.Equal(t, expected, conf)
+	assert.Equal(t, []string{"10.0.2.0/24", "10.0.4.0/24"}, routes)
+
+	// Without peers only the interface is configured.
+	conf, routes = n.peersConfig("privkey", 1, members[:1], memberConfigs)
+	assert.Equal(t, "[Interface]\nPrivateKey = privkey\nListenPort = 51821\n", conf)
+	assert.Empty(t, routes)
+}
+
+func Test_wireguardRoutesDiff(t *testing.T) {
+	currentRoutes := []string{
+		"10.0.2.0/24 scope link",
+		"10.0.3.0/24 scope link linkdown",
+		"",
+	}
+
+	// Only the routes that changed are added and removed.
+	addRoutes, removeRoutes := wireguardRoutesDiff(currentRoutes, []string{"10.0.2.0/24", "10.0.4.0/24"})
+	assert.Equal(t, []string{"10.0.4.0/24"}, addRoutes)
+	assert.Equal(t, []string{"10.0.3.0/24"}, removeRoutes)
+
+	// Unchanged routes aren't touched.
+	addRoutes, removeRoutes = wireguardRoutesDiff(currentRoutes, []string{"10.0.2.0/24", "10.0.3.0/24"})
+	assert.Empty(t, addRoutes)
+	assert.Empty(t, removeRoutes)
+
+	// All routes are removed without peers.
+	addRoutes, removeRoutes = wireguardRoutesDiff(currentRoutes, []string{})
+	assert.Empty(t, addRoutes)
+	assert.Equal(t, []string{"10.0.2.0/24", "10.0.3.0/24"}, removeRoutes)
+
+	// All routes are added initially.
+	addRoutes, removeRoutes = wireguardRoutesDiff(nil, []string{"10.0.2.0/24"})
+	assert.Equal(t, []string{"10.0.2.0/24"}, addRoutes)
+	assert.Empty(t, removeRoutes)
+}
@@ -0,0 +1,159 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+	"unsafe"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"golang.org/x/sys/unix"
+)
+
+// captureSnapLen is the maximum number of bytes captured per packet.
+const captureSnapLen = 65535
+
+// captureHeaderSize is the size of the pcap file header.
+const captureHeaderSize = 24
+
+// capturePacketHeaderSize is the size of the pcap header preceding each packet.
+const capturePacketHeaderSize = 16
+
+// capturePacketReader reads the next packet into buf and returns the original length of the packet and the time it
+// was received at.
+type capturePacketReader func(buf []byte) (int, time.Time, error)
+
+// Capture captures the traffic of the host interface and writes it in pcap format to w.
+// The capture stops once ctx is done or when writing the next packet would exceed maxSize bytes.
+func Capture(ctx context.Context, ifName string, w io.Writer, maxSize int64) error {
+	if maxSize < captureHeaderSize {
+		return fmt.Errorf("Capture size must be at least %d bytes", captureHeaderSize)
+	}
+
+	iface, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return fmt.Errorf("Failed getting interface %q: %w", ifName, err)
+	}
+
+	// Capture all protocols in both directions.
+	protocol := captureHtons(unix.ETH_P_ALL)
+
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, int(protocol))
+	if err != nil {
+		return fmt.Errorf("Failed opening packet socket: %w", err)
+	}
+
+	defer func() { _ = unix.Close(fd) }()
+
+	err = unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: protocol, Ifindex: iface.Index})
+	if err != nil {
+		return fmt.Errorf("Failed binding packet socket to %q: %w", ifName, err)
+	}
+
+	// Use a receive timeout so that the context is checked regularly on idle interfaces.
+	err = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &unix.Timeval{Usec: 500000})
+	if err != nil {
+		return fmt.Errorf("Failed setting packet socket timeout: %w", err)
+	}
+
+	// Have the kernel record when each packet is received.
+	err = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_TIMESTAMP, 1)
+	if err != nil {
+		return fmt.Errorf("Failed enabling packet socket timestamps: %w", err)
+	}
+
+	oob := make([]byte, unix.CmsgSpace(int(unsafe.Sizeof(unix.Timeval{}))))
+	read := func(buf []byte) (int, time.Time, error) {
+		// MSG_TRUNC makes recvmsg return the original length of truncated packets.
+		n, oobn, _, _, err := unix.Recvmsg(fd, buf, oob, unix.MSG_TRUNC)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+
+		return n, captureTimestamp(oob[:oobn]), nil
+	}
+
+	return captureWrite(ctx, read, w, maxSize)
+}
+
+// captureWrite writes the packets returned by read in pcap format to w until ctx is done or writing the next packet
+// would exceed maxSize bytes.
+func captureWrite(ctx context.Context, read capturePacketReader, w io.Writer, maxSize int64) error {
+	// Each packet is written to w in a single call.
+	var out bytes.Buffer
+	writer := pcapgo.NewWriter(&out)
+
+	err := writer.WriteFileHeader(captureSnapLen, layers.LinkTypeEthernet)
+	if err != nil {
+		return err
+	}
+
+	written, err := out.WriteTo(w)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, captureSnapLen)
+	for ctx.Err() == nil {
+		n, timestamp, err := read(buf)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+
+			return fmt.Errorf("Failed reading packet: %w", err)
+		}
+
+		captureLen := min(n, len(buf))
+		if written+capturePacketHeaderSize+int64(captureLen) > maxSize {
+			return nil
+		}
+
+		out.Reset()
+		err = writer.WritePacket(gopacket.CaptureInfo{Timestamp: timestamp, CaptureLength: captureLen, Length: n}, buf[:captureLen])
+		if err != nil {
+			return err
+		}
+
+		n64, err := out.WriteTo(w)
+		if err != nil {
+			return err
+		}
+
+		written += n64
+	}
+
+	return nil
+}
+
+// captureTimestamp returns the receive time of a packet recorded by the kernel in the control messages oob.
+// Falls back to the current time if the control messages don't contain it.
+func captureTimestamp(oob []byte) time.Time {
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return time.Now()
+	}
+
+	for _, msg := range msgs {
+		if msg.Header.Level != unix.SOL_SOCKET || msg.Header.Type != unix.SCM_TIMESTAMP || len(msg.Data) < int(unsafe.Sizeof(unix.Timeval{})) {
+			continue
+		}
+
+		tv := (*unix.Timeval)(unsafe.Pointer(&msg.Data[0]))
+
+		return time.Unix(tv.Unix())
+	}
+
+	return time.Now()
+}
+
+// captureHtons converts a short from host to network byte order.
+func captureHtons(i uint16) uint16 {
+	return (i<<8)&0xff00 | i>>8
+}
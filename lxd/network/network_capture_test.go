@@ -0,0 +1,186 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// testCapturePacket is a packet returned by the test packet reader.
+type testCapturePacket struct {
+	length    int
+	timestamp time.Time
+	err       error
+}
+
+// testCaptureReader returns a packet reader returning the packets one after the other, and cancelling the context
+// once all were read.
+func testCaptureReader(cancel context.CancelFunc, packets []testCapturePacket) capturePacketReader {
+	return func(buf []byte) (int, time.Time, error) {
+		if len(packets) == 0 {
+			cancel()
+			return 0, time.Time{}, unix.EAGAIN
+		}
+
+		p := packets[0]
+		packets = packets[1:]
+
+		for i := range buf[:min(p.length, len(buf))] {
+			buf[i] = byte(p.length)
+		}
+
+		return p.length, p.timestamp, p.err
+	}
+}
+
+// testCaptureRead parses the pcap output of a capture.
+func testCaptureRead(t *testing.T, out []byte) (*pcapgo.Reader, []testCapturePacket) {
+	r, err := pcapgo.NewReader(bytes.NewReader(out))
+	require.NoError(t, err)
+
+	packets := []testCapturePacket{}
+	for {
+		data, ci, err := r.ReadPacketData()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		assert.Len(t, data, ci.CaptureLength)
+		packets = append(packets, testCapturePacket{length: ci.Length, timestamp: ci.Timestamp})
+	}
+
+	return r, packets
+}
+
+func Test_captureWrite(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Timeouts and interruptions are retried, truncated packets keep their original length.
+	read := testCaptureReader(cancel, []testCapturePacket{
+		{length: 60, timestamp: start},
+		{err: unix.EAGAIN},
+		{length: 1500, timestamp: start.Add(time.Millisecond)},
+		{err: unix.EINTR},
+		{length: captureSnapLen + 100, timestamp: start.Add(time.Second)},
+	})
+
+	var out bytes.Buffer
+	err := captureWrite(ctx, read, &out, 1024*1024)
+	require.NoError(t, err)
+
+	r, packets := testCaptureRead(t, out.Bytes())
+	assert.Equal(t, layers.LinkTypeEthernet, r.LinkType())
+	assert.Equal(t, uint32(captureSnapLen), r.Snaplen())
+
+	require.Len(t, packets, 3)
+	for i, want := range []testCapturePacket{{length: 60, timestamp: start}, {length: 1500, timestamp: start.Add(time.Millisecond)}, {length: captureSnapLen + 100, timestamp: start.Add(time.Second)}} {
+		assert.Equal(t, want.length, packets[i].length)
+		assert.True(t, want.timestamp.Equal(packets[i].timestamp), "Packet %d has timestamp %v, want %v", i, packets[i].timestamp, want.timestamp)
+	}
+
+	// Other read errors stop the capture.
+	read = testCaptureReader(cancel, []testCapturePacket{{err: unix.ENETDOWN}})
+	err = captureWrite(context.Background(), read, io.Discard, 1024*1024)
+	assert.ErrorIs(t, err, unix.ENETDOWN)
+}
+
+func Test_captureWriteMaxSize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	packets := []testCapturePacket{}
+	for range 10 {
+		packets = append(packets, testCapturePacket{length: 100, timestamp: time.Now()})
+	}
+
+	// The capture stops before the packet that would exceed the size limit.
+	maxSize := int64(captureHeaderSize + 3*(capturePacketHeaderSize+100) + 50)
+
+	var out bytes.Buffer
+	err := captureWrite(ctx, testCaptureReader(cancel, packets), &out, maxSize)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, int64(out.Len()), maxSize)
+
+	_, written := testCaptureRead(t, out.Bytes())
+	assert.Len(t, written, 3)
+}
+
+func Test_captureTimestamp(t *testing.T) {
+	want := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+	tvSize := int(unsafe.Sizeof(unix.Timeval{}))
+
+	oob := make([]byte, unix.CmsgSpace(tvSize))
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&oob[0]))
+	h.Level = unix.SOL_SOCKET
+	h.Type = unix.SCM_TIMESTAMP
+	h.SetLen(unix.CmsgLen(tvSize))
+	*(*unix.Timeval)(unsafe.Pointer(&oob[unix.CmsgLen(0)])) = unix.NsecToTimeval(want.UnixNano())
+
+	assert.True(t, want.Equal(captureTimestamp(oob)))
+
+	// Without a timestamp, the current time is used.
+	before := time.Now()
+	got := captureTimestamp(nil)
+	assert.False(t, got.Before(before))
+}
+
+func TestCapture(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Opening packet sockets requires root")
+	}
+
+	assert.Error(t, Capture(context.Background(), "lo", io.Discard, captureHeaderSize-1))
+	assert.Error(t, Capture(context.Background(), "nonexistent0", io.Discard, 1024))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- Capture(ctx, "lo", pw, 1024*1024)
+		_ = pw.Close()
+	}()
+
+	outCh := make(chan []byte, 1)
+	go func() {
+		out, _ := io.ReadAll(pr)
+		outCh <- out
+	}()
+
+	// Send some traffic over the loopback interface.
+	time.Sleep(200 * time.Millisecond)
+	sent := time.Now()
+
+	conn, err := net.Dial("udp", "127.0.0.1:9")
+	require.NoError(t, err)
+
+	_, err = conn.Write([]byte("capture"))
+	require.NoError(t, err)
+	_ = conn.Close()
+
+	require.NoError(t, <-done)
+
+	_, packets := testCaptureRead(t, <-outCh)
+	require.NotEmpty(t, packets)
+
+	// The kernel timestamps are taken when the packets were sent.
+	for _, p := range packets {
+		assert.WithinDuration(t, sent, p.timestamp, time.Second)
+	}
+}
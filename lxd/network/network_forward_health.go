@@ -0,0 +1,359 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// forwardHealthDefaultInterval is the default interval between health checks of a forward port target address.
+const forwardHealthDefaultInterval = 10 * time.Second
+
+// forwardHealthMonitors holds the running forward health monitors keyed by network ID.
+var forwardHealthMonitors = map[int64]*forwardHealthMonitor{}
+var forwardHealthMonitorsMu sync.Mutex
+
+// forwardHealthPort records the health state of a single forward port specification.
+type forwardHealthPort struct {
+	listenAddress string
+	protocol      string
+	listenPort    string
+	target        net.IP
+	failover      net.IP
+	check         api.NetworkForwardPortHealthCheck
+	checkPort     int
+
+	active      net.IP
+	status      string
+	reason      string
+	lastChecked time.Time
+	nextCheck   time.Time
+}
+
+// forwardHealthMonitor periodically checks the target addresses of the forward ports of a network that have a
+// health check configured, and re-applies the forwards when the address traffic should be sent to changes.
+type forwardHealthMonitor struct {
+	logger logger.Logger
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	ports map[string]*forwardHealthPort
+	apply func() error
+}
+
+// forwardHealthKey returns the key identifying a port specification of a forward.
+// The listen ports of a protocol can only be used once on a listen address so the key is unique.
+func forwardHealthKey(listenAddress string, portSpec api.NetworkForwardPort) string {
+	return fmt.Sprintf("%s/%s/%s", listenAddress, portSpec.Protocol, portSpec.ListenPort)
+}
+
+// forwardHealthNewPort returns the health state of a port specification with the defaults applied.
+func forwardHealthNewPort(listenAddress string, portSpec api.NetworkForwardPort, portMap *forwardPortMap) *forwardHealthPort {
+	p := &forwardHealthPort{
+		listenAddress: listenAddress,
+		protocol:      portSpec.Protocol,
+		listenPort:    portSpec.ListenPort,
+		target:        portMap.target.address,
+		failover:      net.ParseIP(portSpec.FailoverAddress),
+		check:         *portSpec.HealthCheck,
+		active:        portMap.target.address,
+		status:        api.NetworkForwardPortStatusUnknown,
+		reason:        "Not checked yet",
+	}
+
+	if p.check.Protocol == "" {
+		p.check.Protocol = "tcp"
+	}
+
+	p.checkPort = p.check.Port
+	if p.checkPort == 0 {
+		if len(portMap.target.ports) > 0 {
+			p.checkPort = int(portMap.target.ports[0])
+		} else if len(portMap.listenPorts) > 0 {
+			p.checkPort = int(portMap.listenPorts[0])
+		}
+	}
+
+	return p
+}
+
+// equal returns true if both ports have the same target addresses and health check configuration.
+func (p *forwardHealthPort) equal(other *forwardHealthPort) bool {
+	return p.target.Equal(other.target) && p.failover.Equal(other.failover) && p.check == other.check && p.checkPort == other.checkPort
+}
+
+// interval returns the interval between the health checks of the port.
+func (p *forwardHealthPort) interval() time.Duration {
+	if p.check.Interval <= 0 {
+		return forwardHealthDefaultInterval
+	}
+
+	return time.Duration(p.check.Interval) * time.Second
+}
+
+// probe checks whether the address is healthy, returns nil if it is.
+func (p *forwardHealthPort) probe(ctx context.Context, address net.IP) error {
+	if p.check.Protocol == "icmp" {
+		return pingIP(ctx, address)
+	}
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address.String(), strconv.Itoa(p.checkPort)))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// forwardHealthSetup reconciles the health monitor of the network with the port specifications of the forwards.
+// The existing health state is kept for the ports whose configuration hasn't changed. The apply function is
+// called (without any lock held) when the active address of a port changes.
+func forwardHealthSetup(l logger.Logger, networkID int64, forwards map[int64]*api.NetworkForward, portMaps map[int64][]*forwardPortMap, apply func() error) {
+	forwardHealthMonitorsMu.Lock()
+	defer forwardHealthMonitorsMu.Unlock()
+
+	ports := make(map[string]*forwardHealthPort)
+	for forwardID, forward := range forwards {
+		for portSpecID, portSpec := range forward.Ports {
+			if portSpec.HealthCheck == nil || portSpecID >= len(portMaps[forwardID]) {
+				continue
+			}
+
+			key := forwardHealthKey(forward.ListenAddress, portSpec)
+			ports[key] = forwardHealthNewPort(forward.ListenAddress, portSpec, portMaps[forwardID][portSpecID])
+		}
+	}
+
+	m, ok := forwardHealthMonitors[networkID]
+	if len(ports) == 0 {
+		if ok {
+			m.cancel()
+			delete(forwardHealthMonitors, networkID)
+		}
+
+		return
+	}
+
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		m = &forwardHealthMonitor{
+			logger: l,
+			cancel: cancel,
+			ports:  make(map[string]*forwardHealthPort),
+		}
+
+		forwardHealthMonitors[networkID] = m
+
+		go m.run(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, p := range ports {
+		cur, found := m.ports[key]
+		if found && cur.equal(p) {
+			ports[key] = cur
+		}
+	}
+
+	m.ports = ports
+	m.apply = apply
+}
+
+// forwardHealthStop stops the health monitor of the network (if any).
+func forwardHealthStop(networkID int64) {
+	forwardHealthMonitorsMu.Lock()
+	defer forwardHealthMonitorsMu.Unlock()
+
+	m, ok := forwardHealthMonitors[networkID]
+	if !ok {
+		return
+	}
+
+	m.cancel()
+	delete(forwardHealthMonitors, networkID)
+}
+
+// forwardHealthActiveAddress returns the address the traffic of the port specification should be forwarded to.
+// Returns nil if the port isn't monitored.
+func forwardHealthActiveAddress(networkID int64, listenAddress string, portSpec api.NetworkForwardPort) net.IP {
+	forwardHealthMonitorsMu.Lock()
+	m, ok := forwardHealthMonitors[networkID]
+	forwardHealthMonitorsMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.ports[forwardHealthKey(listenAddress, portSpec)]
+	if !ok {
+		return nil
+	}
+
+	return p.active
+}
+
+// forwardHealthState returns the state of the port specifications of the forward.
+func forwardHealthState(networkID int64, forward *api.NetworkForward) *api.NetworkForwardState {
+	forwardHealthMonitorsMu.Lock()
+	m := forwardHealthMonitors[networkID]
+	forwardHealthMonitorsMu.Unlock()
+
+	state := &api.NetworkForwardState{
+		Ports: make([]api.NetworkForwardPortState, 0, len(forward.Ports)),
+	}
+
+	if m != nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+	}
+
+	for _, portSpec := range forward.Ports {
+		portState := api.NetworkForwardPortState{
+			Protocol:      portSpec.Protocol,
+			ListenPort:    portSpec.ListenPort,
+			ActiveAddress: portSpec.TargetAddress,
+			Status:        api.NetworkForwardPortStatusUnknown,
+		}
+
+		if portSpec.HealthCheck == nil {
+			portState.Reason = "No health check configured"
+		} else if m != nil {
+			p, ok := m.ports[forwardHealthKey(forward.ListenAddress, portSpec)]
+			if ok {
+				portState.ActiveAddress = p.active.String()
+				portState.Status = p.status
+				portState.Reason = p.reason
+				portState.LastChecked = p.lastChecked
+			}
+		}
+
+		state.Ports = append(state.Ports, portState)
+	}
+
+	return state
+}
+
+// run performs the health checks until the context is cancelled.
+func (m *forwardHealthMonitor) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+
+		m.mu.Lock()
+		due := make([]*forwardHealthPort, 0, len(m.ports))
+		for _, p := range m.ports {
+			if !time.Now().Before(p.nextCheck) {
+				due = append(due, p)
+			}
+		}
+
+		m.mu.Unlock()
+
+		changed := false
+		for _, p := range due {
+			if m.check(ctx, p) {
+				changed = true
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		m.mu.Lock()
+		apply := m.apply
+		m.mu.Unlock()
+
+		err := apply()
+		if err != nil {
+			m.logger.Error("Failed applying address forwards after health change", logger.Ctx{"err": err})
+		}
+	}
+}
+
+// check updates the health state of the port, returns true if the active address changed.
+func (m *forwardHealthMonitor) check(ctx context.Context, p *forwardHealthPort) bool {
+	probe := func(address net.IP) error {
+		probeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		return p.probe(probeCtx, address)
+	}
+
+	active := p.target
+	status := api.NetworkForwardPortStatusHealthy
+	reason := ""
+
+	err := probe(p.target)
+	if err != nil {
+		m.logger.Debug("Address forward target address health check failed", logger.Ctx{"listenAddress": p.listenAddress, "target": p.target.String(), "err": err})
+
+		status = api.NetworkForwardPortStatusUnhealthy
+		reason = "Target address not reachable"
+
+		if p.failover != nil {
+			err = probe(p.failover)
+			if err == nil {
+				active = p.failover
+				status = api.NetworkForwardPortStatusFailover
+			} else {
+				reason = "Target and failover addresses not reachable"
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changed := !active.Equal(p.active)
+	if changed {
+		m.logger.Info("Address forward target changed", logger.Ctx{"listenAddress": p.listenAddress, "protocol": p.protocol, "listenPort": p.listenPort, "old": p.active.String(), "new": active.String(), "status": status})
+	}
+
+	p.active = active
+	p.status = status
+	p.reason = reason
+	p.lastChecked = time.Now()
+	p.nextCheck = p.lastChecked.Add(p.interval())
+
+	return changed
+}
+
+// ForwardHealthCheckValidate checks that the network driver supports the health checks configured in the forward.
+func ForwardHealthCheckValidate(n Network, forward api.NetworkForwardPut) error {
+	if n.Info().ForwardHealthCheck {
+		return nil
+	}
+
+	for portSpecID, portSpec := range forward.Ports {
+		if portSpec.HealthCheck != nil || portSpec.FailoverAddress != "" {
+			return fmt.Errorf("Network driver %q does not support health checks in port specification %d", n.Type(), portSpecID)
+		}
+	}
+
+	return nil
+}
@@ -143,7 +143,7 @@ func UsedBy(s *state.State, networkProjectName string, networkID int64, networkN
 		}
 
 		for _, peer := range peers {
-			if peer.Status == api.NetworkStatusCreated {
+			if peer.Status == api.NetworkStatusCreated && peer.Type != api.NetworkPeerTypeRemote {
 				// Add the target project/network of the peering as using this network.
 				usedBy = append(usedBy, api.NewURL().Path(version.APIVersion, "networks", peer.TargetNetwork).Project(peer.TargetProject).String())
 
@@ -509,6 +509,11 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 			}
 		}
 
+		err = dnsmasq.ClearDNSRecords(network)
+		if err != nil {
+			return err
+		}
+
 		// Apply the changes.
 		for entryIdx, entry := range entries {
 			hwaddr := entry[0]
@@ -75,6 +75,7 @@ const ovnExtIDLXDSwitchPort = "lxd_switch_port"
 const ovnExtIDLXDProjectID = "lxd_project_id"
 const ovnExtIDLXDPortGroup = "lxd_port_group"
 const ovnExtIDLXDLocation = "lxd_location"
+const ovnExtIDLXDLoadBalancer = "lxd_load_balancer"
 
 // OVNIPv6RAOpts IPv6 router advertisements options that can be applied to a router.
 type OVNIPv6RAOpts struct {
@@ -141,10 +142,12 @@ type OVNLoadBalancerTarget struct {
 
 // OVNLoadBalancerVIP represents a OVN load balancer Virtual IP entry.
 type OVNLoadBalancerVIP struct {
-	Protocol      string // Either "tcp" or "udp". But only applies to port based VIPs.
-	ListenAddress net.IP
-	ListenPort    uint64
-	Targets       []OVNLoadBalancerTarget
+	Protocol           string // Either "tcp" or "udp". But only applies to port based VIPs.
+	ListenAddress      net.IP
+	ListenPort         uint64
+	Targets            []OVNLoadBalancerTarget
+	SessionPersistence bool   // Select the target based on the source IP of the client.
+	AffinityTimeout    uint64 // Keep the target of an idle client for that many seconds (requires SessionPersistence).
 }
 
 // OVNRouterRoute represents a static route added to a logical router.
@@ -301,6 +304,12 @@ func (o *OVN) xbctl(southbound bool, extraArgs ...string) (string, error) {
 		cmd = "ovn-sbctl"
 	}
 
+	return runOVNCommand(cmd, dbAddr, o.sslCACert, o.sslClientCert, o.sslClientKey, extraArgs...)
+}
+
+// runOVNCommand executes an OVN database command line tool against the given database, passing the SSL
+// certificates and key through memory backed files when connecting over SSL.
+func runOVNCommand(cmd string, dbAddr string, sslCACert string, sslClientCert string, sslClientKey string, extraArgs ...string) (string, error) {
 	if strings.HasPrefix(dbAddr, "unix:") {
 		dbAddr = fmt.Sprintf("unix:%s", shared.HostPathFollow(strings.TrimPrefix(dbAddr, "unix:")))
 	}
@@ -312,7 +321,7 @@ func (o *OVN) xbctl(southbound bool, extraArgs ...string) (string, error) {
 	files := []*os.File{}
 	if strings.Contains(dbAddr, "ssl:") {
 		// Handle client certificate.
-		clientCertFile, err := linux.CreateMemfd([]byte(o.sslClientCert))
+		clientCertFile, err := linux.CreateMemfd([]byte(sslClientCert))
 		if err != nil {
 			return "", err
 		}
@@ -321,7 +330,7 @@ func (o *OVN) xbctl(southbound bool, extraArgs ...string) (string, error) {
 		files = append(files, clientCertFile)
 
 		// Handle client key.
-		clientKeyFile, err := linux.CreateMemfd([]byte(o.sslClientKey))
+		clientKeyFile, err := linux.CreateMemfd([]byte(sslClientKey))
 		if err != nil {
 			return "", err
 		}
@@ -330,7 +339,7 @@ func (o *OVN) xbctl(southbound bool, extraArgs ...string) (string, error) {
 		files = append(files, clientKeyFile)
 
 		// Handle CA certificate.
-		caCertFile, err := linux.CreateMemfd([]byte(o.sslCACert))
+		caCertFile, err := linux.CreateMemfd([]byte(sslCACert))
 		if err != nil {
 			return "", err
 		}
@@ -1888,15 +1897,22 @@ func (o *OVN) loadBalancerUUIDs(loadBalancerName OVNLoadBalancer) ([]string, err
 	var lbUUIDs []string
 
 	// Use find command in order to workaround OVN bug where duplicate records of same name can exist.
-	for _, lbName := range []string{lbTCPName, lbUDPName} {
-		output, err := o.nbctl("--format=csv", "--no-headings", "--data=bare", "--colum=_uuid", "find", "load_balancer",
-			fmt.Sprintf(`name="%s"`, lbName),
-		)
+	// The records with session persistence have a name depending on their settings so are found by external ID.
+	for _, condition := range []string{
+		fmt.Sprintf(`name="%s"`, lbTCPName),
+		fmt.Sprintf(`name="%s"`, lbUDPName),
+		fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDLoadBalancer, loadBalancerName),
+	} {
+		output, err := o.nbctl("--format=csv", "--no-headings", "--data=bare", "--colum=_uuid", "find", "load_balancer", condition)
 		if err != nil {
 			return nil, err
 		}
 
-		lbUUIDs = append(lbUUIDs, shared.SplitNTrimSpace(strings.TrimSpace(output), "\n", -1, true)...)
+		for _, lbUUID := range shared.SplitNTrimSpace(strings.TrimSpace(output), "\n", -1, true) {
+			if !shared.ValueInSlice(lbUUID, lbUUIDs) {
+				lbUUIDs = append(lbUUIDs, lbUUID)
+			}
+		}
 	}
 
 	return lbUUIDs, nil
@@ -1905,9 +1921,6 @@ func (o *OVN) loadBalancerUUIDs(loadBalancerName OVNLoadBalancer) ([]string, err
 // LoadBalancerApply creates a new load balancer (if doesn't exist) on the specified routers and switches.
 // Providing an empty set of vips will delete the load balancer.
 func (o *OVN) LoadBalancerApply(loadBalancerName OVNLoadBalancer, routers []OVNRouter, vips ...OVNLoadBalancerVIP) error {
-	lbTCPName := fmt.Sprintf("%s-tcp", loadBalancerName)
-	lbUDPName := fmt.Sprintf("%s-udp", loadBalancerName)
-
 	// Remove load balancers if they exist.
 	lbUUIDs, err := o.loadBalancerUUIDs(loadBalancerName)
 	if err != nil {
@@ -1933,6 +1946,11 @@ func (o *OVN) LoadBalancerApply(loadBalancerName OVNLoadBalancer, routers []OVNR
 		return ip.String()
 	}
 
+	// The session persistence settings apply to a whole load balancer record, so VIPs with different settings
+	// are added to separate records. Keep one VIP of each record to apply its settings.
+	var lbNames []string
+	lbSettings := make(map[string]OVNLoadBalancerVIP)
+
 	// Build up the commands to add VIPs to the load balancer.
 	for _, r := range vips {
 		if r.ListenAddress == nil {
@@ -1947,10 +1965,25 @@ func (o *OVN) LoadBalancerApply(loadBalancerName OVNLoadBalancer, routers []OVNR
 			args = append(args, "--")
 		}
 
+		if r.AffinityTimeout > 0 && !r.SessionPersistence {
+			return fmt.Errorf("The affinity timeout requires session persistence")
+		}
+
+		lbName := fmt.Sprintf("%s-tcp", loadBalancerName)
 		if r.Protocol == "udp" {
-			args = append(args, "lb-add", lbUDPName)
-		} else {
-			args = append(args, "lb-add", lbTCPName)
+			lbName = fmt.Sprintf("%s-udp", loadBalancerName)
+		}
+
+		if r.SessionPersistence {
+			lbName = fmt.Sprintf("%s-source-ip-%d", lbName, r.AffinityTimeout)
+		}
+
+		args = append(args, "lb-add", lbName)
+
+		_, found := lbSettings[lbName]
+		if !found {
+			lbNames = append(lbNames, lbName)
+			lbSettings[lbName] = r
 		}
 
 		targetArgs := make([]string, 0, len(r.Targets))
@@ -1981,6 +2014,20 @@ func (o *OVN) LoadBalancerApply(loadBalancerName OVNLoadBalancer, routers []OVNR
 		}
 	}
 
+	// Tag the load balancer records and apply their session persistence settings.
+	for _, lbName := range lbNames {
+		args = append(args, "--", "set", "load_balancer", lbName, fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDLoadBalancer, loadBalancerName))
+
+		settings := lbSettings[lbName]
+		if settings.SessionPersistence {
+			args = append(args, "selection_fields=ip_src")
+
+			if settings.AffinityTimeout > 0 {
+				args = append(args, fmt.Sprintf("options:affinity_timeout=%d", settings.AffinityTimeout))
+			}
+		}
+	}
+
 	// Apply the load balancer changes.
 	if len(args) > 0 {
 		_, err := o.nbctl(args...)
@@ -2422,3 +2469,33 @@ func (o *OVN) GetLogicalRouterPortActiveChassisHostname(ovnRouterPort OVNRouterP
 
 	return strings.TrimSpace(hostname), err
 }
+
+// AvailabilityZoneName returns the name of the OVN availability zone, which must be set for OVN interconnection.
+func (o *OVN) AvailabilityZoneName() (string, error) {
+	output, err := o.nbctl("get", "NB_Global", ".", "name")
+	if err != nil {
+		return "", err
+	}
+
+	name, err := unquote(strings.TrimSpace(output))
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// NBGlobalSetOptions sets options in the global northbound configuration.
+func (o *OVN) NBGlobalSetOptions(options map[string]string) error {
+	args := []string{"set", "NB_Global", "."}
+	for key, value := range options {
+		args = append(args, fmt.Sprintf("options:%s=%s", key, value))
+	}
+
+	_, err := o.nbctl(args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
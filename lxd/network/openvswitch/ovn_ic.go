@@ -0,0 +1,44 @@
+package openvswitch
+
+// OVNIC is a wrapper for the OVN interconnection northbound database shared between OVN deployments.
+type OVNIC struct {
+	dbAddr string
+
+	sslCACert     string
+	sslClientCert string
+	sslClientKey  string
+}
+
+// NewOVNIC initialises a new OVN interconnection client wrapper for the given database connection string and,
+// when connecting over SSL, the CA certificate and client key pair.
+func NewOVNIC(dbAddr string, sslCACert string, sslClientCert string, sslClientKey string) *OVNIC {
+	return &OVNIC{
+		dbAddr:        dbAddr,
+		sslCACert:     sslCACert,
+		sslClientCert: sslClientCert,
+		sslClientKey:  sslClientKey,
+	}
+}
+
+// nbctl executes ovn-ic-nbctl with arguments to connect to wrapper's interconnection northbound database.
+func (o *OVNIC) nbctl(args ...string) (string, error) {
+	return runOVNCommand("ovn-ic-nbctl", o.dbAddr, o.sslCACert, o.sslClientCert, o.sslClientKey, args...)
+}
+
+// TransitSwitchAdd adds a named transit switch. The OVN interconnection controllers then create a logical switch
+// with the same name in the northbound database of each availability zone.
+// If mayExist is true, then an existing resource of the same name is not treated as an error.
+func (o *OVNIC) TransitSwitchAdd(switchName OVNSwitch, mayExist bool) error {
+	args := []string{}
+
+	if mayExist {
+		args = append(args, "--may-exist")
+	}
+
+	_, err := o.nbctl(append(args, "ts-add", string(switchName))...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/version"
+	"github.com/canonical/lxd/shared/ws"
+)
+
+// networkCaptureDefaultDuration is the default duration of a network capture.
+const networkCaptureDefaultDuration = 30 * time.Second
+
+// networkCaptureMaxDuration is the maximum duration of a network capture.
+const networkCaptureMaxDuration = time.Hour
+
+// networkCaptureMaxSize is the default and maximum size of a network capture.
+const networkCaptureMaxSize = 1024 * 1024 * 1024
+
+// networkCaptureConnectTimeout is how long to wait for the client to connect to the capture websocket.
+const networkCaptureConnectTimeout = 30 * time.Second
+
+var networkCaptureCmd = APIEndpoint{
+	Path: "networks/{networkName}/capture",
+
+	Post: APIEndpointAction{Handler: networkCapturePost, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanEdit, "networkName")},
+}
+
+// networkCaptureWs streams the captured traffic to the websocket of the operation.
+type networkCaptureWs struct {
+	ifName   string
+	duration time.Duration
+	maxSize  int64
+
+	secret    string
+	connected chan *websocket.Conn
+}
+
+// Metadata returns a map of metadata.
+func (c *networkCaptureWs) Metadata() any {
+	return shared.Jmap{"fds": shared.Jmap{"0": c.secret}}
+}
+
+// Connect connects to the websocket.
+func (c *networkCaptureWs) Connect(op *operations.Operation, r *http.Request, w http.ResponseWriter) error {
+	secret := r.FormValue("secret")
+	if secret == "" {
+		return fmt.Errorf("missing secret")
+	}
+
+	if secret != c.secret {
+		return os.ErrPermission
+	}
+
+	conn, err := ws.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case c.connected <- conn:
+	default:
+		_ = conn.Close()
+		return fmt.Errorf("Capture websocket already connected")
+	}
+
+	return nil
+}
+
+// Do runs the capture once the client is connected.
+func (c *networkCaptureWs) Do(op *operations.Operation) error {
+	var conn *websocket.Conn
+
+	select {
+	case conn = <-c.connected:
+	case <-time.After(networkCaptureConnectTimeout):
+		return fmt.Errorf("Timed out waiting for the capture websocket to connect")
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.duration)
+	defer cancel()
+
+	// Stop the capture if the client disconnects.
+	go func() {
+		for {
+			_, _, err := conn.NextReader()
+			if err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	logger.Info("Started network capture", logger.Ctx{"interface": c.ifName, "duration": c.duration, "maxSize": c.maxSize})
+
+	err := network.Capture(ctx, c.ifName, ws.NewWrapper(conn), c.maxSize)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Finished network capture", logger.Ctx{"interface": c.ifName})
+
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+	return nil
+}
+
+// swagger:operation POST /1.0/networks/{name}/capture networks network_capture_post
+//
+//	Capture the network traffic
+//
+//	Starts a time-limited capture of the traffic on the host interface of the network, or of an instance NIC
+//	connected to it. The captured traffic is streamed in pcap format over the operation websocket.
+//
+//	Capturing the parent interface of a macvlan, physical or sriov network also requires the permission to edit
+//	the server, as the interface carries traffic that doesn't belong to the network.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	  - in: body
+//	    name: capture
+//	    description: Capture request
+//	    required: false
+//	    schema:
+//	      $ref: "#/definitions/NetworkCapturePost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkCapturePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// If a target was specified, forward the request to the relevant node.
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkCapturePost{}
+	if r.ContentLength > 0 {
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
+	duration, maxSize, err := networkCaptureLimits(req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if (req.Instance == "") != (req.Device == "") {
+		return response.BadRequest(fmt.Errorf("Both the instance and the device must be specified to capture an instance NIC"))
+	}
+
+	networkProjectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, networkProjectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	var ifName string
+	resources := map[string][]api.URL{}
+	resources["networks"] = []api.URL{*api.NewURL().Path(version.APIVersion, "networks", networkName)}
+
+	if req.Instance != "" {
+		projectName := request.ProjectParam(r)
+
+		// Capturing the traffic of an instance also requires the permission to edit it.
+		err = s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(projectName, req.Instance), auth.EntitlementCanEdit)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Forward the request if the instance is remote.
+		client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, req.Instance, r, instancetype.Any)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if client != nil {
+			url := api.NewURL().Path(version.APIVersion, "networks", networkName, "capture").Project(projectName)
+			resp, _, err := client.RawQuery("POST", url.String(), req, "")
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			opAPI, err := resp.MetadataAsOperation()
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			return operations.ForwardedOperationResponse(projectName, opAPI)
+		}
+
+		inst, err := instance.LoadByProjectAndName(s, projectName, req.Instance)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		ifName, err = networkCaptureNICInterface(inst, n, req.Device)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", req.Instance).Project(projectName)}
+	} else {
+		var sharedInterface bool
+		ifName, sharedInterface, err = networkCaptureInterface(n)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// The traffic of an interface that is shared with the host isn't limited to the network, so capturing
+		// it also requires the permission to edit the server.
+		if sharedInterface {
+			err = s.Authorizer.CheckPermission(r.Context(), r, entity.ServerURL(), auth.EntitlementCanEdit)
+			if err != nil {
+				return response.SmartError(err)
+			}
+		}
+	}
+
+	if !network.InterfaceExists(ifName) {
+		return response.BadRequest(fmt.Errorf("Interface %q not found on this member", ifName))
+	}
+
+	capture := &networkCaptureWs{
+		ifName:    ifName,
+		duration:  duration,
+		maxSize:   maxSize,
+		connected: make(chan *websocket.Conn, 1),
+	}
+
+	capture.secret, err = shared.RandomCryptoString()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	op, err := operations.OperationCreate(s, networkProjectName, operations.OperationClassWebsocket, operationtype.NetworkCapture, resources, capture.Metadata(), capture.Do, nil, capture.Connect, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// networkCaptureLimits returns the duration and the maximum size of the capture requested, with the defaults applied.
+func networkCaptureLimits(req api.NetworkCapturePost) (time.Duration, int64, error) {
+	var err error
+
+	duration := networkCaptureDefaultDuration
+	if req.Duration != "" {
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid capture duration %q: %w", req.Duration, err)
+		}
+
+		if duration <= 0 || duration > networkCaptureMaxDuration {
+			return 0, 0, fmt.Errorf("Capture duration must be between 0 and %s", networkCaptureMaxDuration)
+		}
+	}
+
+	maxSize := int64(networkCaptureMaxSize)
+	if req.MaxSize != "" {
+		maxSize, err = units.ParseByteSizeString(req.MaxSize)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid capture size %q: %w", req.MaxSize, err)
+		}
+
+		if maxSize <= 0 || maxSize > networkCaptureMaxSize {
+			return 0, 0, fmt.Errorf("Capture size must be between 0 and %s", units.GetByteSizeStringIEC(networkCaptureMaxSize, 0))
+		}
+	}
+
+	return duration, maxSize, nil
+}
+
+// networkCaptureInterface returns the host interface used to capture the traffic of the network, and whether that
+// interface is a parent interface that also carries traffic that doesn't belong to the network.
+func networkCaptureInterface(n network.Network) (string, bool, error) {
+	switch n.Type() {
+	case "bridge", "wireguard":
+		return n.Name(), false, nil
+	case "macvlan", "physical", "sriov":
+		return n.Config()["parent"], true, nil
+	}
+
+	return "", false, api.StatusErrorf(http.StatusBadRequest, "Networks of type %q can't be captured", n.Type())
+}
+
+// networkCaptureNICInterface returns the host side interface of the instance NIC connected to the network.
+func networkCaptureNICInterface(inst instance.Instance, n network.Network, deviceName string) (string, error) {
+	devConfig, ok := inst.ExpandedDevices()[deviceName]
+	if !ok || devConfig["type"] != "nic" {
+		return "", api.StatusErrorf(http.StatusNotFound, "NIC %q not found on instance %q", deviceName, inst.Name())
+	}
+
+	if devConfig["network"] != n.Name() && devConfig["parent"] != n.Name() {
+		return "", api.StatusErrorf(http.StatusBadRequest, "NIC %q of instance %q isn't connected to network %q", deviceName, inst.Name(), n.Name())
+	}
+
+	if !inst.IsRunning() {
+		return "", api.StatusErrorf(http.StatusBadRequest, "Instance %q isn't running", inst.Name())
+	}
+
+	hostName := inst.ExpandedConfig()[fmt.Sprintf("volatile.%s.host_name", deviceName)]
+	if hostName == "" {
+		return "", api.StatusErrorf(http.StatusBadRequest, "NIC %q of instance %q doesn't have a host side interface", deviceName, inst.Name())
+	}
+
+	return hostName, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestNetworkCaptureLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          api.NetworkCapturePost
+		wantDuration time.Duration
+		wantMaxSize  int64
+		wantErr      bool
+	}{
+		{
+			name:         "Defaults",
+			wantDuration: networkCaptureDefaultDuration,
+			wantMaxSize:  networkCaptureMaxSize,
+		},
+		{
+			name:         "Custom limits",
+			req:          api.NetworkCapturePost{Duration: "90s", MaxSize: "10MiB"},
+			wantDuration: 90 * time.Second,
+			wantMaxSize:  10 * 1024 * 1024,
+		},
+		{
+			name:         "Maximum limits",
+			req:          api.NetworkCapturePost{Duration: "1h", MaxSize: "1GiB"},
+			wantDuration: networkCaptureMaxDuration,
+			wantMaxSize:  networkCaptureMaxSize,
+		},
+		{
+			name:    "Invalid duration",
+			req:     api.NetworkCapturePost{Duration: "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "Zero duration",
+			req:     api.NetworkCapturePost{Duration: "0s"},
+			wantErr: true,
+		},
+		{
+			name:    "Negative duration",
+			req:     api.NetworkCapturePost{Duration: "-1m"},
+			wantErr: true,
+		},
+		{
+			name:    "Duration over the maximum",
+			req:     api.NetworkCapturePost{Duration: "61m"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid size",
+			req:     api.NetworkCapturePost{MaxSize: "big"},
+			wantErr: true,
+		},
+		{
+			name:    "Zero size",
+			req:     api.NetworkCapturePost{MaxSize: "0"},
+			wantErr: true,
+		},
+		{
+			name:    "Size over the maximum",
+			req:     api.NetworkCapturePost{MaxSize: "2GiB"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duration, maxSize, err := networkCaptureLimits(tt.req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDuration, duration)
+			assert.Equal(t, tt.wantMaxSize, maxSize)
+		})
+	}
+}
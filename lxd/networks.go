@@ -67,6 +67,12 @@ var networkLeasesCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: networkLeasesGet, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanView, "networkName")},
 }
 
+var networkDNSRecordsCmd = APIEndpoint{
+	Path: "networks/{networkName}/dns-records",
+
+	Get: APIEndpointAction{Handler: networkDNSRecordsGet, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanView, "networkName")},
+}
+
 var networkStateCmd = APIEndpoint{
 	Path: "networks/{networkName}/state",
 
@@ -1165,6 +1171,7 @@ func networkPost(d *Daemon, r *http.Request) response.Response {
 //	Update the network
 //
 //	Updates the entire network configuration.
+//	When `dry-run` is set, the update is only validated and the configuration changes it would make are returned.
 //
 //	---
 //	consumes:
@@ -1182,6 +1189,11 @@ func networkPost(d *Daemon, r *http.Request) response.Response {
 //	    description: Cluster member name
 //	    type: string
 //	    example: lxd01
+//	  - in: query
+//	    name: dry-run
+//	    description: Only validate the update and return the changes it would make
+//	    type: boolean
+//	    example: true
 //	  - in: body
 //	    name: network
 //	    description: Network configuration
@@ -1284,8 +1296,12 @@ func networkPut(d *Daemon, r *http.Request) response.Response {
 	}
 
 	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+	dryRun := shared.IsTrue(request.QueryParam(r, "dry-run"))
 
-	response := doNetworkUpdate(projectName, n, req, targetNode, clientType, r.Method, s.ServerClustered)
+	response := doNetworkUpdate(projectName, n, req, targetNode, clientType, r.Method, s.ServerClustered, dryRun)
+	if dryRun {
+		return response
+	}
 
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(projectName, lifecycle.NetworkUpdated.Event(n, requestor, nil))
@@ -1338,7 +1354,8 @@ func networkPatch(d *Daemon, r *http.Request) response.Response {
 
 // doNetworkUpdate loads the current local network config, merges with the requested network config, validates
 // and applies the changes. Will also notify other cluster nodes of non-node specific config if needed.
-func doNetworkUpdate(projectName string, n network.Network, req api.NetworkPut, targetNode string, clientType clusterRequest.ClientType, httpMethod string, clustered bool) response.Response {
+// If dryRun is true, the changes that would be made are returned instead of being applied.
+func doNetworkUpdate(projectName string, n network.Network, req api.NetworkPut, targetNode string, clientType clusterRequest.ClientType, httpMethod string, clustered bool, dryRun bool) response.Response {
 	if req.Config == nil {
 		req.Config = map[string]string{}
 	}
@@ -1371,6 +1388,16 @@ func doNetworkUpdate(projectName string, n network.Network, req api.NetworkPut,
 		return response.BadRequest(err)
 	}
 
+	if dryRun {
+		oldEntity := util.CopyConfig(n.Config())
+		oldEntity["description"] = n.Description()
+
+		newEntity := util.CopyConfig(req.Config)
+		newEntity["description"] = req.Description
+
+		return response.SyncResponse(true, &api.ConfigDiff{Changes: util.DiffConfigs(oldEntity, newEntity)})
+	}
+
 	// Apply the new configuration (will also notify other cluster nodes if needed).
 	err = n.Update(req, targetNode, clientType)
 	if err != nil {
@@ -1461,6 +1488,82 @@ func networkLeasesGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, leases)
 }
 
+// swagger:operation GET /1.0/networks/{name}/dns-records networks networks_dns_records_get
+//
+//	Get the DNS records
+//
+//	Returns a list of the DNS records served for instances on the network.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of DNS records
+//	          items:
+//	            $ref: "#/definitions/NetworkDNSRecord"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkDNSRecordsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Attempt to load the network.
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+	records, err := n.DNSRecords(reqProject.Name, clientType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, records)
+}
+
 func networkStartup(s *state.State) error {
 	var err error
 
@@ -38,7 +38,7 @@ func networkAutoAttach(cluster *db.Cluster, devName string) error {
 	return nil
 }
 
-// networkUpdateForkdnsServersTask runs every 30s and refreshes the forkdns servers list.
+// networkUpdateForkdnsServersTask runs every 30s and refreshes the forkdns servers list and the WireGuard peers.
 func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIHeartbeat) error {
 	logger.Debug("Refreshing forkdns servers")
 
@@ -64,7 +64,7 @@ func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIH
 			continue
 		}
 
-		if n.Type() == "bridge" && n.Config()["bridge.mode"] == "fan" {
+		if (n.Type() == "bridge" && n.Config()["bridge.mode"] == "fan") || n.Type() == "wireguard" {
 			err := n.HandleHeartbeat(heartbeatData)
 			if err != nil {
 				return err
@@ -123,6 +123,11 @@ func (c *Config) SyslogSocket() bool {
 	return c.m.GetBool("core.syslog_socket")
 }
 
+// HostShutdownProtectionOverride returns true if host shutdowns shouldn't be blocked by protected instances.
+func (c *Config) HostShutdownProtectionOverride() bool {
+	return c.m.GetBool("core.host_shutdown_protection_override")
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]any {
@@ -220,6 +225,18 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Address to bind the authoritative DNS server to
 	"core.dns_address": {Validator: validate.Optional(validate.IsListenAddress(true, true, false))},
 
+	// Override of the host shutdown protection
+
+	// lxdmeta:generate(entities=server; group=core; key=core.host_shutdown_protection_override)
+	// Set this option to `true` to allow the host to shut down even if instances with
+	// {config:option}`instance-security:security.protection.host_shutdown` set are running.
+	// ---
+	//  type: bool
+	//  scope: local
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to allow host shutdowns while protected instances are running
+	"core.host_shutdown_protection_override": {Validator: validate.Optional(validate.IsBool), Type: config.Bool},
+
 	// Network address for the metrics server
 
 	// lxdmeta:generate(entities=server; group=core; key=core.metrics_address)
@@ -0,0 +1,92 @@
+package operations
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instanceCreations records when instances were created in each project, used for limits.instances.creation_rate.
+// Protected by operationsLock.
+var instanceCreations = make(map[string][]time.Time)
+
+// projectLimits represents the operation related limits of a project.
+// A negative value means that there is no limit.
+type projectLimits struct {
+	operations           int
+	instanceCreationRate int
+}
+
+// newProjectLimits parses the operation related limits from the project config.
+func newProjectLimits(config map[string]string) (*projectLimits, error) {
+	limits := &projectLimits{operations: -1, instanceCreationRate: -1}
+
+	for key, limit := range map[string]*int{"limits.operations": &limits.operations, "limits.instances.creation_rate": &limits.instanceCreationRate} {
+		value := config[key]
+		if value == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value %q for %q: %w", value, key, err)
+		}
+
+		*limit = n
+	}
+
+	return limits, nil
+}
+
+// check returns an error if creating the operation would exceed the limits of its project.
+// It must be called with operationsLock held and records the instance creations that are allowed.
+func (l *projectLimits) check(op *Operation) error {
+	if l.operations >= 0 && op.class != OperationClassToken {
+		count := 0
+		for _, existing := range operations {
+			if existing.projectName != op.projectName || existing.class == OperationClassToken {
+				continue
+			}
+
+			if existing.status == api.Pending || existing.status == api.Running {
+				count++
+			}
+		}
+
+		if count >= l.operations {
+			return l.reject(op, "limits.operations", fmt.Sprintf("Project %q has reached its limit of %d concurrent operations", op.projectName, l.operations))
+		}
+	}
+
+	if op.dbOpType == operationtype.InstanceCreate {
+		// Only keep the creations of the last hour.
+		since := time.Now().Add(-time.Hour)
+		creations := instanceCreations[op.projectName]
+		for len(creations) > 0 && creations[0].Before(since) {
+			creations = creations[1:]
+		}
+
+		if l.instanceCreationRate >= 0 && len(creations) >= l.instanceCreationRate {
+			instanceCreations[op.projectName] = creations
+			return l.reject(op, "limits.instances.creation_rate", fmt.Sprintf("Project %q has reached its limit of %d instance creations per hour", op.projectName, l.instanceCreationRate))
+		}
+
+		instanceCreations[op.projectName] = append(creations, op.createdAt)
+	}
+
+	return nil
+}
+
+// reject records the rejected operation and returns the error for it.
+func (l *projectLimits) reject(op *Operation, limit string, reason string) error {
+	logger.Warn("Rejecting operation exceeding project limit", logger.Ctx{"project": op.projectName, "description": op.description, "limit": limit})
+	metrics.TrackRejectedOperation(op.projectName, limit)
+
+	return api.StatusErrorf(http.StatusTooManyRequests, "%s", reason)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared/api"
 )
 
@@ -43,6 +44,25 @@ func registerDBOperation(op *Operation, opType operationtype.Type) error {
 	return nil
 }
 
+func loadProjectLimits(s *state.State, projectName string) (*projectLimits, error) {
+	var config map[string]string
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		projectID, err := cluster.GetProjectID(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return fmt.Errorf("Fetch project ID: %w", err)
+		}
+
+		config, err = cluster.GetProjectConfig(ctx, tx.Tx(), int(projectID))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading project %q limits: %w", projectName, err)
+	}
+
+	return newProjectLimits(config)
+}
+
 func removeDBOperation(op *Operation) error {
 	if op.state == nil {
 		return nil
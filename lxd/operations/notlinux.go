@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared/api"
 )
 
@@ -17,6 +18,10 @@ func registerDBOperation(op *Operation, opType operationtype.Type) error {
 	return nil
 }
 
+func loadProjectLimits(s *state.State, projectName string) (*projectLimits, error) {
+	return nil, fmt.Errorf("loadProjectLimits not supported on this platform")
+}
+
 func removeDBOperation(op *Operation) error {
 	if op.state != nil {
 		return fmt.Errorf("registerDBOperation not supported on this platform")
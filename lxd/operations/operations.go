@@ -189,7 +189,24 @@ func OperationCreate(s *state.State, projectName string, opClass OperationClass,
 		op.SetRequestor(r)
 	}
 
+	// Enforce the operation limits of the project on API requests.
+	var limits *projectLimits
+	if s != nil && r != nil && projectName != "" {
+		limits, err = loadProjectLimits(s, projectName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	operationsLock.Lock()
+	if limits != nil {
+		err = limits.check(&op)
+		if err != nil {
+			operationsLock.Unlock()
+			return nil, err
+		}
+	}
+
 	operations[op.id] = &op
 	operationsLock.Unlock()
 
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/instance/operationlock"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/lxd/subprocess"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// orphanedResourcesThreshold is how long the daemon must have been running before orphaned resources are
+// removed. This gives instances, devices and operations started during startup time to settle.
+const orphanedResourcesThreshold = 10 * time.Minute
+
+// orphanedResource describes a resource removed by the orphaned resources task.
+type orphanedResource struct {
+	inst    instance.Instance
+	message string
+}
+
+func autoRemoveOrphanedResourcesTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// Don't interfere with a daemon that is still starting up.
+		if d.waitReady.Err() == nil || time.Since(s.StartTime) < orphanedResourcesThreshold {
+			logger.Debug("Skipping remove orphaned resources task since daemon was started recently")
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			return autoRemoveOrphanedResources(ctx, s)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.RemoveOrphanedResources, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating remove orphaned resources operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting remove orphaned resources operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed removing orphaned resources", logger.Ctx{"err": err})
+			return
+		}
+	}
+
+	return f, task.Every(orphanedResourcesThreshold, task.SkipFirst)
+}
+
+// autoRemoveOrphanedResources removes resources left behind on the local member by operations and instances
+// that are no longer running. This covers operation records that have no matching running operation, helper
+// processes (forkproxy, virtiofsd, swtpm, ...) of stopped instances and stale mounts in the devices directory.
+// Each removed resource is logged and recorded as a warning.
+func autoRemoveOrphanedResources(ctx context.Context, s *state.State) error {
+	logger.Debug("Removing orphaned resources")
+
+	removed, err := removeOrphanedOperations(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	deviceRemoved, err := removeOrphanedDeviceResources(s)
+	if err != nil {
+		return err
+	}
+
+	removed = append(removed, deviceRemoved...)
+
+	for _, res := range removed {
+		projectName := ""
+		entityType := entity.Type("")
+		entityID := -1
+		logCtx := logger.Ctx{}
+
+		if res.inst != nil {
+			projectName = res.inst.Project().Name
+			entityType = entity.TypeInstance
+			entityID = res.inst.ID()
+			logCtx["project"] = projectName
+			logCtx["instance"] = res.inst.Name()
+		}
+
+		logger.Warn(res.message, logCtx)
+
+		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, projectName, entityType, entityID, warningtype.OrphanedResourcesRemoved, res.message)
+		})
+		if err != nil {
+			logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+		}
+	}
+
+	logger.Debug("Done removing orphaned resources")
+
+	return nil
+}
+
+// orphanedOperationsFirstSeen records when each operation record without a matching running operation was
+// first found, so that records are only removed once they have been orphaned for a while.
+var orphanedOperationsFirstSeen = map[string]time.Time{}
+var orphanedOperationsFirstSeenMu sync.Mutex
+
+// orphanedOperations returns the UUIDs of the operation records that have had no matching running operation
+// for at least the given threshold. The firstSeen map is updated with the records found without a running
+// operation for the first time and pruned of the ones that aren't orphaned anymore.
+func orphanedOperations(dbUUIDs []string, isRunning func(uuid string) bool, firstSeen map[string]time.Time, now time.Time, threshold time.Duration) []string {
+	var orphaned []string
+
+	current := make(map[string]bool, len(dbUUIDs))
+	for _, uuid := range dbUUIDs {
+		current[uuid] = true
+
+		if isRunning(uuid) {
+			delete(firstSeen, uuid)
+			continue
+		}
+
+		seen, ok := firstSeen[uuid]
+		if !ok {
+			firstSeen[uuid] = now
+			continue
+		}
+
+		if now.Sub(seen) >= threshold {
+			orphaned = append(orphaned, uuid)
+		}
+	}
+
+	for uuid := range firstSeen {
+		if !current[uuid] {
+			delete(firstSeen, uuid)
+		}
+	}
+
+	return orphaned
+}
+
+// removeOrphanedOperations deletes the operation records of the local member that haven't belonged to any
+// operation known to this daemon for at least orphanedResourcesThreshold. Such records are left behind if an
+// operation couldn't be cleaned up properly, and would otherwise show up as running forever.
+func removeOrphanedOperations(ctx context.Context, s *state.State) ([]orphanedResource, error) {
+	orphanedOperationsFirstSeenMu.Lock()
+	defer orphanedOperationsFirstSeenMu.Unlock()
+
+	// Operations are added to the local operations map before being stored in the database, so a record
+	// whose operation is running is always found in the map when checked within the transaction.
+	isRunning := func(uuid string) bool {
+		_, err := operations.OperationGetInternal(uuid)
+		return err == nil
+	}
+
+	var removed []orphanedResource
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		removed = nil
+
+		memberID := tx.GetNodeID()
+		filter := dbCluster.OperationFilter{NodeID: &memberID}
+
+		dbOperations, err := dbCluster.GetOperations(ctx, tx.Tx(), filter)
+		if err != nil {
+			return fmt.Errorf("Failed getting operations: %w", err)
+		}
+
+		dbUUIDs := make([]string, 0, len(dbOperations))
+		dbTypes := make(map[string]operationtype.Type, len(dbOperations))
+		for _, dbOp := range dbOperations {
+			dbUUIDs = append(dbUUIDs, dbOp.UUID)
+			dbTypes[dbOp.UUID] = dbOp.Type
+		}
+
+		for _, uuid := range orphanedOperations(dbUUIDs, isRunning, orphanedOperationsFirstSeen, time.Now(), orphanedResourcesThreshold) {
+			err = dbCluster.DeleteOperation(ctx, tx.Tx(), uuid)
+			if err != nil {
+				return fmt.Errorf("Failed deleting operation %q: %w", uuid, err)
+			}
+
+			removed = append(removed, orphanedResource{message: fmt.Sprintf("Removed orphaned operation %q (%s)", uuid, dbTypes[uuid].Description())})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to remove orphaned operations: %w", err)
+	}
+
+	return removed, nil
+}
+
+// removeOrphanedDeviceResources stops the helper processes and unmounts the mounts found in the devices
+// directories of stopped or deleted local instances.
+func removeOrphanedDeviceResources(s *state.State) ([]orphanedResource, error) {
+	insts, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading local instances: %w", err)
+	}
+
+	instsByDir := make(map[string]instance.Instance, len(insts))
+	for _, inst := range insts {
+		instsByDir[project.Instance(inst.Project().Name, inst.Name())] = inst
+	}
+
+	entries, err := os.ReadDir(shared.VarPath("devices"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed listing devices directory: %w", err)
+	}
+
+	var removed []orphanedResource
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		inst := instsByDir[entry.Name()]
+		if inst != nil {
+			// Leave instances that are running or being started or stopped alone.
+			if inst.IsRunning() || operationlock.Get(inst.Project().Name, inst.Name()) != nil {
+				continue
+			}
+		}
+
+		devicesPath := shared.VarPath("devices", entry.Name())
+
+		pidPaths, err := filepath.Glob(filepath.Join(devicesPath, "*.pid"))
+		if err != nil {
+			return nil, err
+		}
+
+		proxyPidPaths, err := filepath.Glob(filepath.Join(devicesPath, "proxy.*"))
+		if err != nil {
+			return nil, err
+		}
+
+		pidPaths = append(pidPaths, proxyPidPaths...)
+
+		// Stopped VMs may also have left their config drive virtiofsd process behind.
+		if inst != nil && inst.Type() == instancetype.VM {
+			pidPaths = append(pidPaths, filepath.Join(inst.LogPath(), "virtiofsd.pid"))
+		}
+
+		for _, pidPath := range pidPaths {
+			msg, err := removeOrphanedProcess(pidPath)
+			if err != nil {
+				logger.Warn("Failed removing orphaned process", logger.Ctx{"path": pidPath, "err": err})
+				continue
+			}
+
+			if msg != "" {
+				removed = append(removed, orphanedResource{inst: inst, message: msg})
+			}
+		}
+
+		mounts, err := orphanedMounts(devicesPath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, mountPath := range mounts {
+			err := unix.Unmount(mountPath, unix.MNT_DETACH)
+			if err != nil {
+				logger.Warn("Failed unmounting orphaned device mount", logger.Ctx{"path": mountPath, "err": err})
+				continue
+			}
+
+			removed = append(removed, orphanedResource{inst: inst, message: fmt.Sprintf("Unmounted orphaned device mount %q", mountPath)})
+		}
+	}
+
+	return removed, nil
+}
+
+// removeOrphanedProcess stops the process recorded in pidPath and removes the PID file.
+// The process is only stopped if its command line still matches the recorded one, so that an unrelated
+// process that has since reused the PID is left alone. Returns a description of the action taken, if any.
+func removeOrphanedProcess(pidPath string) (string, error) {
+	if !shared.PathExists(pidPath) {
+		return "", nil
+	}
+
+	p, err := subprocess.ImportProcess(pidPath)
+	if err != nil {
+		// Not a process PID file.
+		return "", nil
+	}
+
+	var cmdline []byte
+	if p.PID > 0 {
+		cmdline, _ = os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", p.PID))
+	}
+
+	if len(cmdline) == 0 {
+		// The process is gone, only the stale PID file is left.
+		err = os.Remove(pidPath)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Removed stale PID file %q", pidPath), nil
+	}
+
+	args := strings.Split(string(bytes.TrimRight(cmdline, "\x00")), "\x00")
+	if !slices.Equal(args[1:], p.Args) {
+		// The PID has been reused by an unrelated process.
+		err = os.Remove(pidPath)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Removed stale PID file %q", pidPath), nil
+	}
+
+	err = p.Stop()
+	if err != nil && err != subprocess.ErrNotRunning {
+		return "", err
+	}
+
+	err = os.Remove(pidPath)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Stopped orphaned %q process (PID %d)", filepath.Base(p.Name), p.PID), nil
+}
+
+// orphanedMounts returns the mount points found directly inside devicesPath.
+func orphanedMounts(devicesPath string) ([]string, error) {
+	entries, err := os.ReadDir(devicesPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed listing %q: %w", devicesPath, err)
+	}
+
+	var mounts []string
+	for _, entry := range entries {
+		path := filepath.Join(devicesPath, entry.Name())
+		if filesystem.IsMountPoint(path) {
+			mounts = append(mounts, path)
+		}
+	}
+
+	return mounts, nil
+}
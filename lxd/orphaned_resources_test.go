@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/canonical/lxd/lxd/subprocess"
+)
+
+func TestOrphanedOperations(t *testing.T) {
+	now := time.Now()
+	threshold := 10 * time.Minute
+
+	running := map[string]bool{"running": true}
+	isRunning := func(uuid string) bool { return running[uuid] }
+
+	firstSeen := map[string]time.Time{
+		"old":     now.Add(-threshold),
+		"recent":  now.Add(-time.Minute),
+		"running": now.Add(-time.Hour),
+		"deleted": now.Add(-time.Hour),
+	}
+
+	orphaned := orphanedOperations([]string{"old", "recent", "running", "new"}, isRunning, firstSeen, now, threshold)
+
+	// Only the record that has been orphaned for at least the threshold is returned.
+	if !slices.Equal(orphaned, []string{"old"}) {
+		t.Errorf("Unexpected orphaned operations %v", orphaned)
+	}
+
+	// Records seen for the first time are only recorded.
+	seen, ok := firstSeen["new"]
+	if !ok || !seen.Equal(now) {
+		t.Errorf("Expected operation %q to be recorded as first seen now", "new")
+	}
+
+	// Records with a running operation or not in the database anymore are forgotten.
+	for _, uuid := range []string{"running", "deleted"} {
+		_, ok := firstSeen[uuid]
+		if ok {
+			t.Errorf("Expected operation %q to be removed from the first seen records", uuid)
+		}
+	}
+
+	// A record created after the local operations were checked is never returned straight away.
+	orphaned = orphanedOperations([]string{"created"}, func(uuid string) bool { return false }, map[string]time.Time{}, now, threshold)
+	if len(orphaned) > 0 {
+		t.Errorf("Unexpected orphaned operations %v", orphaned)
+	}
+}
+
+func TestRemoveOrphanedProcess(t *testing.T) {
+	dir := t.TempDir()
+
+	// A missing PID file is ignored.
+	msg, err := removeOrphanedProcess(filepath.Join(dir, "missing.pid"))
+	if err != nil || msg != "" {
+		t.Fatalf("Unexpected result for missing PID file: %q, %v", msg, err)
+	}
+
+	// A running process matching the PID file is stopped.
+	p, err := subprocess.NewProcess("sleep", []string{"60"}, "", "")
+	if err != nil {
+		t.Fatalf("Failed creating process: %v", err)
+	}
+
+	err = p.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Failed starting process: %v", err)
+	}
+
+	pidPath := filepath.Join(dir, "sleep.pid")
+	err = p.Save(pidPath)
+	if err != nil {
+		t.Fatalf("Failed saving process: %v", err)
+	}
+
+	msg, err = removeOrphanedProcess(pidPath)
+	if err != nil || msg == "" {
+		t.Fatalf("Expected the process to be stopped: %q, %v", msg, err)
+	}
+
+	_, err = os.Stat(pidPath)
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected PID file to be removed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = p.Wait(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected process to be stopped")
+	}
+
+	// A PID file whose process is gone is removed without stopping anything.
+	err = p.Save(pidPath)
+	if err != nil {
+		t.Fatalf("Failed saving process: %v", err)
+	}
+
+	msg, err = removeOrphanedProcess(pidPath)
+	if err != nil || msg == "" {
+		t.Fatalf("Expected the stale PID file to be removed: %q, %v", msg, err)
+	}
+
+	_, err = os.Stat(pidPath)
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected stale PID file to be removed")
+	}
+
+	// A process that has reused the PID with a different command line is left alone.
+	other, err := subprocess.NewProcess("sleep", []string{"60"}, "", "")
+	if err != nil {
+		t.Fatalf("Failed creating process: %v", err)
+	}
+
+	err = other.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Failed starting process: %v", err)
+	}
+
+	defer func() { _ = other.Stop() }()
+
+	other.Args = []string{"120"}
+	err = other.Save(pidPath)
+	if err != nil {
+		t.Fatalf("Failed saving process: %v", err)
+	}
+
+	_, err = removeOrphanedProcess(pidPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = os.Stat(fmt.Sprintf("/proc/%d", other.PID))
+	if err != nil {
+		t.Errorf("Expected unrelated process to keep running")
+	}
+}
+
+func TestOrphanedMounts(t *testing.T) {
+	dir := t.TempDir()
+
+	mounts, err := orphanedMounts(filepath.Join(dir, "missing"))
+	if err != nil || len(mounts) > 0 {
+		t.Fatalf("Unexpected result for missing directory: %v, %v", mounts, err)
+	}
+
+	err = os.Mkdir(filepath.Join(dir, "disk.foo"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mounts, err = orphanedMounts(dir)
+	if err != nil || len(mounts) > 0 {
+		t.Fatalf("Unexpected mounts found in a directory without mounts: %v, %v", mounts, err)
+	}
+}
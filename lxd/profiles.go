@@ -104,6 +104,7 @@ var profileCmd = APIEndpoint{
 //	Get the profiles
 //
 //	Returns a list of profiles (structs).
+//	With recursion=2, the expanded config and devices (including those of the inherited profiles) are returned too.
 //
 //	---
 //	produces:
@@ -151,6 +152,7 @@ func profilesGet(d *Daemon, r *http.Request) response.Response {
 	}
 
 	recursion := util.IsRecursionRequest(r)
+	expanded := r.FormValue("recursion") == "2"
 
 	request.SetCtxValue(r, request.CtxEffectiveProjectName, p.Name)
 	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeProfile)
@@ -182,6 +184,8 @@ func profilesGet(d *Daemon, r *http.Request) response.Response {
 					return err
 				}
 
+				profileSetExpanded(apiProfile, expanded)
+
 				apiProfile.UsedBy, err = profileUsedBy(ctx, tx, profile)
 				if err != nil {
 					return err
@@ -335,6 +339,16 @@ func profilesPost(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		parentIDs, err := profileParentIDs(ctx, tx, p.Name, req.Name, 0, req.Inherits)
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.UpdateProfileParents(ctx, tx.Tx(), id, parentIDs)
+		if err != nil {
+			return err
+		}
+
 		return err
 	})
 	if err != nil {
@@ -353,6 +367,7 @@ func profilesPost(d *Daemon, r *http.Request) response.Response {
 //	Get the profile
 //
 //	Gets a specific profile.
+//	With recursion=1, the expanded config and devices (including those of the inherited profiles) are returned too.
 //
 //	---
 //	produces:
@@ -363,6 +378,11 @@ func profilesPost(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: recursion
+//	    description: Whether to include the expanded config and devices
+//	    type: integer
+//	    example: 1
 //	responses:
 //	  "200":
 //	    description: Profile
@@ -414,6 +434,8 @@ func profileGet(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		profileSetExpanded(resp, util.IsRecursionRequest(r))
+
 		resp.UsedBy, err = profileUsedBy(ctx, tx, *profile)
 		if err != nil {
 			return err
@@ -427,7 +449,7 @@ func profileGet(d *Daemon, r *http.Request) response.Response {
 
 	resp.UsedBy = project.FilterUsedBy(s.Authorizer, r, resp.UsedBy)
 
-	etag := []any{resp.Config, resp.Description, resp.Devices}
+	etag := []any{resp.Config, resp.Description, resp.Devices, resp.Inherits}
 	return response.SyncResponseETag(true, resp, etag)
 }
 
@@ -436,6 +458,8 @@ func profileGet(d *Daemon, r *http.Request) response.Response {
 //	Update the profile
 //
 //	Updates the entire profile configuration.
+//	When `dry-run` is set, the update is only validated and the changes it would make to the profile
+//	and to the expanded configuration of the instances using it are returned.
 //
 //	---
 //	consumes:
@@ -448,6 +472,11 @@ func profileGet(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: dry-run
+//	    description: Only validate the update and return the changes it would make
+//	    type: boolean
+//	    example: true
 //	  - in: body
 //	    name: profile
 //	    description: Profile configuration
@@ -514,7 +543,7 @@ func profilePut(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Validate the ETag.
-	etag := []any{profile.Config, profile.Description, profile.Devices}
+	etag := []any{profile.Config, profile.Description, profile.Devices, profile.Inherits}
 	err = util.EtagCheck(r, etag)
 	if err != nil {
 		return response.PreconditionFailed(err)
@@ -526,6 +555,15 @@ func profilePut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	if shared.IsTrue(request.QueryParam(r, "dry-run")) {
+		diff, err := profileUpdateDryRun(s, *p, name, profile, req)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		return response.SyncResponse(true, diff)
+	}
+
 	err = doProfileUpdate(s, *p, name, id, profile, req)
 
 	if err == nil && !isClusterNotification(r) {
@@ -619,7 +657,7 @@ func profilePatch(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Validate the ETag.
-	etag := []any{profile.Config, profile.Description, profile.Devices}
+	etag := []any{profile.Config, profile.Description, profile.Devices, profile.Inherits}
 	err = util.EtagCheck(r, etag)
 	if err != nil {
 		return response.PreconditionFailed(err)
@@ -675,6 +713,12 @@ func profilePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Get Inherits.
+	_, ok := reqRaw["inherits"]
+	if !ok {
+		req.Inherits = profile.Inherits
+	}
+
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(p.Name, lifecycle.ProfileUpdated.Event(name, p.Name, requestor, nil))
 
@@ -825,6 +869,15 @@ func profileDelete(d *Daemon, r *http.Request) response.Response {
 			return fmt.Errorf("Profile is currently in use")
 		}
 
+		descendants, err := dbCluster.GetProfileDescendants(ctx, tx.Tx(), profile.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(descendants) > 0 {
+			return fmt.Errorf("Profile is inherited by other profiles: %s", strings.Join(descendants, ", "))
+		}
+
 		return dbCluster.DeleteProfile(ctx, tx.Tx(), p.Name, name)
 	})
 	if err != nil {
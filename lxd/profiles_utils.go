@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
@@ -11,10 +14,16 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 )
 
-func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64, profile *api.Profile, req api.ProfilePut) error {
+// errProfileDryRun is used to roll back the database transaction of a profile update dry run.
+var errProfileDryRun = errors.New("Profile update dry run")
+
+// validateProfileUpdate checks the project limits and validates the new profile configuration.
+func validateProfileUpdate(s *state.State, p api.Project, profileName string, req api.ProfilePut) error {
 	// Check project limits.
 	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		return project.AllowProfileUpdate(s.GlobalConfig, tx, p.Name, profileName, req)
@@ -31,7 +40,64 @@ func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64
 
 	// Profiles can be applied to any instance type, so just use instancetype.Any type for validation so that
 	// instance type specific validation checks are not performed.
-	err = instance.ValidDevices(s, p, instancetype.Any, deviceConfig.NewDevices(req.Devices), nil)
+	return instance.ValidDevices(s, p, instancetype.Any, deviceConfig.NewDevices(req.Devices), nil)
+}
+
+// updateProfileDB stores the new profile configuration in the database.
+func updateProfileDB(ctx context.Context, tx *db.ClusterTx, projectName string, profileName string, req api.ProfilePut) error {
+	devices, err := cluster.APIToDevices(req.Devices)
+	if err != nil {
+		return err
+	}
+
+	err = cluster.UpdateProfile(ctx, tx.Tx(), projectName, profileName, cluster.Profile{
+		Project:     projectName,
+		Name:        profileName,
+		Description: req.Description,
+	})
+	if err != nil {
+		return err
+	}
+
+	id, err := cluster.GetProfileID(ctx, tx.Tx(), projectName, profileName)
+	if err != nil {
+		return err
+	}
+
+	err = cluster.UpdateProfileConfig(ctx, tx.Tx(), id, req.Config)
+	if err != nil {
+		return err
+	}
+
+	err = cluster.UpdateProfileDevices(ctx, tx.Tx(), id, devices)
+	if err != nil {
+		return err
+	}
+
+	parentIDs, err := profileParentIDs(ctx, tx, projectName, profileName, int(id), req.Inherits)
+	if err != nil {
+		return err
+	}
+
+	err = cluster.UpdateProfileParents(ctx, tx.Tx(), id, parentIDs)
+	if err != nil {
+		return err
+	}
+
+	newProfiles, err := cluster.GetProfilesIfEnabled(ctx, tx.Tx(), projectName, []string{profileName})
+	if err != nil {
+		return err
+	}
+
+	if len(newProfiles) != 1 {
+		return fmt.Errorf("Failed to find profile %q in project %q", profileName, projectName)
+	}
+
+	return nil
+}
+
+func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64, profile *api.Profile, req api.ProfilePut) error {
+	err := validateProfileUpdate(s, p, profileName, req)
 	if err != nil {
 		return err
 	}
@@ -86,45 +152,7 @@ func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64
 
 	// Update the database.
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		devices, err := cluster.APIToDevices(req.Devices)
-		if err != nil {
-			return err
-		}
-
-		err = cluster.UpdateProfile(ctx, tx.Tx(), p.Name, profileName, cluster.Profile{
-			Project:     p.Name,
-			Name:        profileName,
-			Description: req.Description,
-		})
-		if err != nil {
-			return err
-		}
-
-		id, err := cluster.GetProfileID(ctx, tx.Tx(), p.Name, profileName)
-		if err != nil {
-			return err
-		}
-
-		err = cluster.UpdateProfileConfig(ctx, tx.Tx(), id, req.Config)
-		if err != nil {
-			return err
-		}
-
-		err = cluster.UpdateProfileDevices(ctx, tx.Tx(), id, devices)
-		if err != nil {
-			return err
-		}
-
-		newProfiles, err := cluster.GetProfilesIfEnabled(ctx, tx.Tx(), p.Name, []string{profileName})
-		if err != nil {
-			return err
-		}
-
-		if len(newProfiles) != 1 {
-			return fmt.Errorf("Failed to find profile %q in project %q", profileName, p.Name)
-		}
-
-		return nil
+		return updateProfileDB(ctx, tx, p.Name, profileName, req)
 	})
 	if err != nil {
 		return err
@@ -157,6 +185,96 @@ func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64
 	return nil
 }
 
+// profileUpdateDryRun validates the profile update and returns the changes it would make to the profile and to
+// the expanded configuration of the instances using it, without applying them.
+// The new profile configuration is stored in a database transaction that is then rolled back, so that
+// inheritance between profiles is resolved the same way as for a real update.
+func profileUpdateDryRun(s *state.State, p api.Project, profileName string, profile *api.Profile, req api.ProfilePut) (*api.ConfigDiff, error) {
+	err := validateProfileUpdate(s, p, profileName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	insts, projects, err := getProfileInstancesInfo(s.DB.Cluster, p.Name, profileName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query instances associated with profile %q: %w", profileName, err)
+	}
+
+	newInstProfiles := make(map[int][]api.Profile, len(insts))
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := updateProfileDB(ctx, tx, p.Name, profileName, req)
+		if err != nil {
+			return err
+		}
+
+		for id, inst := range insts {
+			profileNames := make([]string, 0, len(inst.Profiles))
+			for _, instProfile := range inst.Profiles {
+				profileNames = append(profileNames, instProfile.Name)
+			}
+
+			newInstProfiles[id], err = tx.GetProfiles(ctx, inst.Project, profileNames)
+			if err != nil {
+				return err
+			}
+		}
+
+		return errProfileDryRun
+	})
+	if err != nil && !errors.Is(err, errProfileDryRun) {
+		return nil, err
+	}
+
+	var globalConfigDump map[string]any
+	if s.GlobalConfig != nil {
+		globalConfigDump = s.GlobalConfig.Dump()
+	}
+
+	oldEntity := configDiffMap(profile.Config, profile.Devices)
+	oldEntity["description"] = profile.Description
+	oldEntity["inherits"] = strings.Join(profile.Inherits, ",")
+
+	newEntity := configDiffMap(req.Config, req.Devices)
+	newEntity["description"] = req.Description
+	newEntity["inherits"] = strings.Join(req.Inherits, ",")
+
+	diff := &api.ConfigDiff{
+		Changes:   util.DiffConfigs(oldEntity, newEntity),
+		Instances: map[string][]api.ConfigDiffEntry{},
+	}
+
+	for id, inst := range insts {
+		oldExpandedConfig := instancetype.ExpandInstanceConfig(globalConfigDump, inst.Config, inst.Profiles)
+		oldExpandedDevices := instancetype.ExpandInstanceDevices(inst.Devices, inst.Profiles)
+		newExpandedConfig := instancetype.ExpandInstanceConfig(globalConfigDump, inst.Config, newInstProfiles[id])
+		newExpandedDevices := instancetype.ExpandInstanceDevices(inst.Devices, newInstProfiles[id])
+
+		err = instance.ValidConfig(s.OS, newExpandedConfig, true, inst.Type)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid configuration for instance %q in project %q: %w", inst.Name, inst.Project, err)
+		}
+
+		err = instance.ValidDevices(s, *projects[inst.Project], inst.Type, inst.Devices, newExpandedDevices)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid devices for instance %q in project %q: %w", inst.Name, inst.Project, err)
+		}
+
+		changes := util.DiffConfigs(configDiffMap(oldExpandedConfig, oldExpandedDevices.CloneNative()), configDiffMap(newExpandedConfig, newExpandedDevices.CloneNative()))
+		if len(changes) == 0 {
+			continue
+		}
+
+		instName := inst.Name
+		if inst.Project != p.Name {
+			instName = inst.Project + "/" + inst.Name
+		}
+
+		diff.Instances[instName] = changes
+	}
+
+	return diff, nil
+}
+
 // Like doProfileUpdate but does not update the database, since it was already
 // updated by doProfileUpdate itself, called on the notifying node.
 func doProfileUpdateCluster(s *state.State, projectName string, profileName string, old api.ProfilePut) error {
@@ -180,11 +298,38 @@ func doProfileUpdateCluster(s *state.State, projectName string, profileName stri
 				// doProfileUpdateInstance will detect the changes and apply them.
 				inst.Profiles[i].Config = old.Config
 				inst.Profiles[i].Devices = old.Devices
-				break
 			}
 		}
 
-		err := doProfileUpdateInstance(s, inst, *projects[inst.Project])
+		// Profiles inheriting from the updated profile have their expanded config and devices recomputed
+		// using the old config and devices in the same way.
+		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			for i, profile := range inst.Profiles {
+				if len(profile.Inherits) == 0 {
+					continue
+				}
+
+				dbProfile, err := cluster.GetProfile(ctx, tx.Tx(), projectName, profile.Name)
+				if err != nil {
+					return err
+				}
+
+				oldProfile, err := dbProfile.ToAPIWithOverride(ctx, tx.Tx(), profileName, old)
+				if err != nil {
+					return err
+				}
+
+				inst.Profiles[i] = *oldProfile
+			}
+
+			return nil
+		})
+		if err != nil {
+			failures[&inst] = err
+			continue
+		}
+
+		err = doProfileUpdateInstance(s, inst, *projects[inst.Project])
 		if err != nil {
 			failures[&inst] = err
 		}
@@ -243,17 +388,41 @@ func doProfileUpdateInstance(s *state.State, args db.InstanceArgs, p api.Project
 	}, true)
 }
 
-// Query the db for information about instances associated with the given profile.
+// Query the db for information about instances associated with the given profile, either directly or through
+// profiles inheriting from it.
 func getProfileInstancesInfo(dbCluster *db.Cluster, projectName string, profileName string) (map[int]db.InstanceArgs, map[string]*api.Project, error) {
-	var projectInstNames map[string][]string
+	projectInstNames := map[string][]string{}
 
 	// Query the db for information about instances associated with the given profile.
 	err := dbCluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		var err error
+		profileNames := []string{profileName}
 
-		projectInstNames, err = tx.GetInstancesWithProfile(ctx, projectName, profileName)
+		profileID, err := cluster.GetProfileID(ctx, tx.Tx(), projectName, profileName)
+		if err == nil {
+			descendants, err := cluster.GetProfileDescendants(ctx, tx.Tx(), int(profileID))
+			if err != nil {
+				return err
+			}
 
-		return err
+			profileNames = append(profileNames, descendants...)
+		}
+
+		for _, name := range profileNames {
+			instNames, err := tx.GetInstancesWithProfile(ctx, projectName, name)
+			if err != nil {
+				return err
+			}
+
+			for instProject, names := range instNames {
+				for _, instName := range names {
+					if !shared.ValueInSlice(instName, projectInstNames[instProject]) {
+						projectInstNames[instProject] = append(projectInstNames[instProject], instName)
+					}
+				}
+			}
+		}
+
+		return nil
 	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("Failed to query instances with profile %q: %w", profileName, err)
@@ -303,3 +472,57 @@ func getProfileInstancesInfo(dbCluster *db.Cluster, projectName string, profileN
 
 	return instances, projects, nil
 }
+
+// profileParentIDs validates the profiles that a profile inherits from and returns their IDs in order.
+// The inherited profiles must exist in the same project and must not inherit from the profile themselves.
+// The profileID is zero for a profile that doesn't exist yet.
+func profileParentIDs(ctx context.Context, tx *db.ClusterTx, projectName string, profileName string, profileID int, inherits []string) ([]int64, error) {
+	descendants := []string{}
+	if profileID > 0 {
+		var err error
+		descendants, err = cluster.GetProfileDescendants(ctx, tx.Tx(), profileID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parentIDs := make([]int64, 0, len(inherits))
+	for i, parentName := range inherits {
+		if parentName == profileName {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Profile %q cannot inherit from itself", profileName)
+		}
+
+		if shared.ValueInSlice(parentName, inherits[:i]) {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Profile %q is inherited more than once", parentName)
+		}
+
+		if shared.ValueInSlice(parentName, descendants) {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Inheriting from profile %q would create a cycle as it inherits from profile %q", parentName, profileName)
+		}
+
+		parentID, err := cluster.GetProfileID(ctx, tx.Tx(), projectName, parentName)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Inherited profile %q not found", parentName)
+			}
+
+			return nil, err
+		}
+
+		parentIDs = append(parentIDs, parentID)
+	}
+
+	return parentIDs, nil
+}
+
+// profileSetExpanded sets the expanded config and devices of the profile if requested, or clears them otherwise.
+func profileSetExpanded(profile *api.Profile, expanded bool) {
+	if !expanded {
+		profile.ExpandedConfig = nil
+		profile.ExpandedDevices = nil
+		return
+	}
+
+	profile.ExpandedConfig = profile.EffectiveConfig()
+	profile.ExpandedDevices = profile.EffectiveDevices()
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/canonical/lxd/lxd/idmap"
+	"github.com/canonical/lxd/shared/api"
 )
 
 func TestParseHostIDMapRange(t *testing.T) {
@@ -75,3 +76,55 @@ func TestParseHostIDMapRange(t *testing.T) {
 		assert.Equal(t, idmaps, expected)
 	}
 }
+
+func TestCheckRestrictions_MirrorTarget(t *testing.T) {
+	tests := []struct {
+		name          string
+		projectConfig map[string]string
+		target        string
+		wantErr       bool
+	}{
+		{
+			name:          "Instance NIC target",
+			projectConfig: map[string]string{"restricted": "true", "restricted.devices.nic": "allow"},
+			target:        "ids/eth0",
+		},
+		{
+			name:          "Host interface target",
+			projectConfig: map[string]string{"restricted": "true", "restricted.devices.nic": "allow"},
+			target:        "eth0",
+			wantErr:       true,
+		},
+		{
+			name:          "Host interface target on managed network NIC",
+			projectConfig: map[string]string{"restricted": "true"},
+			target:        "eth0",
+			wantErr:       true,
+		},
+		{
+			name:          "No target",
+			projectConfig: map[string]string{"restricted": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := map[string]string{"type": "nic", "network": "lxdbr0"}
+			if tt.target != "" {
+				device["security.mirror.target"] = tt.target
+			}
+
+			project := api.Project{Name: "restricted", Config: tt.projectConfig}
+			instances := []api.Instance{{Name: "c1", Type: "container", Devices: map[string]map[string]string{"eth0": device}}}
+			profiles := []api.Profile{{Name: "default", Devices: map[string]map[string]string{"eth0": device}}}
+
+			for _, err := range []error{checkRestrictions(project, instances, nil), checkRestrictions(project, nil, profiles)} {
+				if tt.wantErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			}
+		})
+	}
+}
@@ -585,6 +585,15 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 				return nil
 			}
 
+		case "restricted.devices.shmem":
+			devicesChecks["shmem"] = func(device map[string]string) error {
+				if restrictionValue != "allow" {
+					return fmt.Errorf("Shared memory devices are forbidden")
+				}
+
+				return nil
+			}
+
 		case "restricted.devices.proxy":
 			devicesChecks["proxy"] = func(device map[string]string) error {
 				if restrictionValue != "allow" {
@@ -606,6 +615,12 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 					}
 				}
 
+				// Only allow mirroring traffic to the NICs of other instances in the project.
+				// Host interfaces would expose the traffic outside of the project.
+				if device["security.mirror.target"] != "" && !strings.Contains(device["security.mirror.target"], "/") {
+					return fmt.Errorf("Mirroring traffic to host interfaces is forbidden")
+				}
+
 				// Check if the NIC's parent/network setting is allowed based on the
 				// restricted.devices.nic and restricted.networks.access settings.
 				if device["network"] != "" {
@@ -825,6 +840,7 @@ var allRestrictions = map[string]string{
 	"restricted.devices.usb":               "block",
 	"restricted.devices.pci":               "block",
 	"restricted.devices.proxy":             "block",
+	"restricted.devices.shmem":             "block",
 	"restricted.devices.nic":               "managed",
 	"restricted.devices.disk":              "managed",
 	"restricted.devices.disk.paths":        "",
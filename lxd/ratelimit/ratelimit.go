@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// maxIdleBuckets is the number of buckets kept before the full (idle) buckets are removed.
+const maxIdleBuckets = 1024
+
+// bucket is a token bucket for a single key.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a thread-safe set of token buckets keyed by an arbitrary string (such as a project name or identity).
+// Each bucket holds up to burst tokens and is refilled at rate tokens per second. Every request takes one token.
+type Limiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+
+	// now is used in place of time.Now for testing.
+	now func() time.Time
+}
+
+// NewLimiter returns a Limiter that doesn't limit anything until configured.
+func NewLimiter() *Limiter {
+	return &Limiter{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// Configure sets the number of requests allowed per second and the burst size. A rate of zero disables limiting.
+// A burst of zero allows one second worth of requests. All existing buckets are reset.
+func (l *Limiter) Configure(rate int64, burst int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if burst <= 0 {
+		burst = rate
+	}
+
+	l.rate = float64(rate)
+	l.burst = float64(burst)
+	l.buckets = map[string]*bucket{}
+}
+
+// Enabled returns whether the limiter has been configured with a rate.
+func (l *Limiter) Enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rate > 0
+}
+
+// Allow takes a token from the bucket of the given key. If the bucket is empty, it returns false along with the
+// duration after which a token will be available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	now := l.now()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.prune(now)
+		}
+
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+
+	return true, 0
+}
+
+// prune removes the buckets that have been refilled since last used, as they are equivalent to new buckets.
+func (l *Limiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
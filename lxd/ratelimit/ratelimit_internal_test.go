@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A limiter that isn't configured allows all requests.
+func TestLimiter_Unconfigured(t *testing.T) {
+	l := NewLimiter()
+	assert.False(t, l.Enabled())
+
+	for i := 0; i < 100; i++ {
+		ok, _ := l.Allow("default")
+		assert.True(t, ok)
+	}
+}
+
+// Requests over the burst size are rejected until the bucket is refilled.
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter()
+	l.now = func() time.Time { return now }
+	l.Configure(2, 4)
+	assert.True(t, l.Enabled())
+
+	for i := 0; i < 4; i++ {
+		ok, _ := l.Allow("default")
+		assert.True(t, ok)
+	}
+
+	ok, wait := l.Allow("default")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Other keys have their own bucket.
+	ok, _ = l.Allow("foo")
+	assert.True(t, ok)
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("default")
+	assert.True(t, ok)
+
+	ok, _ = l.Allow("default")
+	assert.False(t, ok)
+}
+
+// The burst size defaults to the rate.
+func TestLimiter_DefaultBurst(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter()
+	l.now = func() time.Time { return now }
+	l.Configure(3, 0)
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("default")
+		assert.True(t, ok)
+	}
+
+	ok, _ := l.Allow("default")
+	assert.False(t, ok)
+}
@@ -24,6 +24,10 @@ const (
 	// This contains groups defined by the identity provider if the identity authenticated with OIDC.
 	CtxIdentityProviderGroups CtxKey = "identity_provider_groups"
 
+	// CtxIdentityProviderGroupRuleGroups is the identity provider group rule groups field in the request context.
+	// This contains the LXD groups resolved from identity provider group rules if the identity authenticated with OIDC.
+	CtxIdentityProviderGroupRuleGroups CtxKey = "identity_provider_group_rule_groups"
+
 	// CtxForwardedAddress is the forwarded address field in request context.
 	CtxForwardedAddress CtxKey = "forwarded_address"
 
@@ -38,6 +42,11 @@ const (
 	// member.
 	CtxForwardedIdentityProviderGroups CtxKey = "identity_provider_groups"
 
+	// CtxForwardedIdentityProviderGroupRuleGroups is the forwarded identity provider group rule groups field in the
+	// request context. This contains the LXD groups resolved from identity provider group rules if the identity
+	// authenticated with OIDC on another cluster member.
+	CtxForwardedIdentityProviderGroupRuleGroups CtxKey = "forwarded_identity_provider_group_rule_groups"
+
 	// CtxEffectiveProjectName is used to indicate that the effective project of a resource is different from the project
 	// specified in the URL. (For example, if a project has `features.networks=false`, any networks in this project actually
 	// belong to the default project).
@@ -61,4 +70,8 @@ const (
 	// HeaderForwardedIdentityProviderGroups is the forwarded identity provider groups field in request header.
 	// This will be a JSON marshalled []string.
 	HeaderForwardedIdentityProviderGroups = "X-LXD-forwarded-identity-provider-groups"
+
+	// HeaderForwardedIdentityProviderGroupRuleGroups is the forwarded identity provider group rule groups field in
+	// request header. This will be a JSON marshalled []string.
+	HeaderForwardedIdentityProviderGroupRuleGroups = "X-LXD-forwarded-identity-provider-group-rule-groups"
 )
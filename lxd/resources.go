@@ -1,17 +1,23 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 
 	"github.com/gorilla/mux"
 
 	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/device/pci"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/resources"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/version"
 )
 
 var api10ResourcesCmd = APIEndpoint{
@@ -81,9 +87,101 @@ func api10ResourcesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	err = resourcesGPUAllocations(s, res)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	return response.SyncResponse(true, res)
 }
 
+// resourcesGPUAllocations records which local instances the active mediated devices and the SR-IOV virtual
+// functions are allocated to.
+func resourcesGPUAllocations(s *state.State, res *api.Resources) error {
+	insts, err := instance.LoadNodeAll(s, instancetype.VM)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	allocations := make(map[string]string)
+	vfAllocations := make(map[string]string)
+	for _, inst := range insts {
+		localConfig := inst.LocalConfig()
+		instanceURL := api.NewURL().Path(version.APIVersion, "instances", inst.Name()).Project(inst.Project().Name).String()
+
+		for devName, dev := range inst.ExpandedDevices() {
+			if dev["type"] != "gpu" {
+				continue
+			}
+
+			switch dev["gputype"] {
+			case "mdev":
+				mdevUUID := localConfig["volatile."+devName+".vgpu.uuid"]
+				if mdevUUID != "" {
+					allocations[mdevUUID] = instanceURL
+				}
+
+			case "sriov":
+				parentPCIAddress := localConfig["volatile."+devName+".last_state.pci.parent"]
+				vfID := localConfig["volatile."+devName+".last_state.vf.id"]
+				if parentPCIAddress == "" || vfID == "" {
+					continue
+				}
+
+				// Resolve the PCI address of the virtual function.
+				vfDev, err := pci.ParseUeventFile(fmt.Sprintf("/sys/bus/pci/devices/%s/virtfn%s/uevent", parentPCIAddress, vfID))
+				if err != nil {
+					continue
+				}
+
+				vfAllocations[vfDev.SlotName] = instanceURL
+			}
+		}
+	}
+
+	setAllocations := func(mdev map[string]api.ResourcesGPUCardMdev) {
+		for profile, entry := range mdev {
+			for _, mdevUUID := range entry.Devices {
+				instanceURL, ok := allocations[mdevUUID]
+				if !ok {
+					continue
+				}
+
+				if entry.Allocations == nil {
+					entry.Allocations = make(map[string]string)
+				}
+
+				entry.Allocations[mdevUUID] = instanceURL
+			}
+
+			mdev[profile] = entry
+		}
+	}
+
+	for _, gpu := range res.GPU.Cards {
+		setAllocations(gpu.Mdev)
+
+		if gpu.SRIOV != nil {
+			for _, vf := range gpu.SRIOV.VFs {
+				setAllocations(vf.Mdev)
+
+				instanceURL, ok := vfAllocations[vf.PCIAddress]
+				if !ok {
+					continue
+				}
+
+				if gpu.SRIOV.Allocations == nil {
+					gpu.SRIOV.Allocations = make(map[string]string)
+				}
+
+				gpu.SRIOV.Allocations[vf.PCIAddress] = instanceURL
+			}
+		}
+	}
+
+	return nil
+}
+
 // swagger:operation GET /1.0/storage-pools/{name}/resources storage storage_pool_resources
 //
 //	Get storage pool resources information
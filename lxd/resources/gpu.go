@@ -188,6 +188,9 @@ func gpuAddDeviceInfo(devicePath string, nvidiaCards map[string]*api.ResourcesGP
 		}
 	}
 
+	// PCIe link
+	card.Link = pciLink(devicePath)
+
 	deviceUSBPath := filepath.Join(devicePath, "device", "busnum")
 	if sysfsExists(deviceUSBPath) {
 		// USB address
@@ -583,6 +586,13 @@ func GetGPU() (*api.ResourcesGPU, error) {
 		if card.SRIOV != nil {
 			card.SRIOV.VFs = pciVFs[card.PCIAddress]
 			gpu.Total += uint64(len(card.SRIOV.VFs))
+
+			// VFs bound to vfio-pci are passed through to an instance.
+			for _, vf := range card.SRIOV.VFs {
+				if vf.Driver != "vfio-pci" {
+					card.SRIOV.AvailableVFs++
+				}
+			}
 		}
 
 		gpu.Total++
@@ -91,6 +91,9 @@ func networkAddDeviceInfo(devicePath string, pciDB *pcidb.PCIDB, uname unix.Utsn
 		}
 	}
 
+	// PCIe link
+	card.Link = pciLink(deviceDeviceDir)
+
 	// USB address
 	usbAddr, err := usbAddress(deviceDeviceDir)
 	if err != nil {
@@ -465,6 +468,14 @@ func GetNetwork() (*api.ResourcesNetwork, error) {
 		if card.SRIOV != nil {
 			card.SRIOV.VFs = pciVFs[card.PCIAddress]
 			network.Total += uint64(len(card.SRIOV.VFs))
+
+			// VFs bound to vfio-pci are passed through to an instance and VFs without
+			// ports on the host have had their interface moved into an instance.
+			for _, vf := range card.SRIOV.VFs {
+				if vf.Driver != "vfio-pci" && len(vf.Ports) > 0 {
+					card.SRIOV.AvailableVFs++
+				}
+			}
 		}
 
 		network.Total++
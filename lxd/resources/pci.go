@@ -78,6 +78,9 @@ func GetPCI() (*api.ResourcesPCI, error) {
 			}
 		}
 
+		// Get PCIe link
+		device.Link = pciLink(devicePath)
+
 		// Get PCI address
 		device.PCIAddress = entryName
 
@@ -152,3 +155,51 @@ func GetPCI() (*api.ResourcesPCI, error) {
 
 	return &pci, nil
 }
+
+// pciLinkSpeed parses a PCIe link speed as reported by sysfs (e.g. "8.0 GT/s PCIe") into GT/s.
+// Zero is returned for unknown speeds.
+func pciLinkSpeed(value string) float64 {
+	fields := strings.Fields(value)
+	if len(fields) < 2 || fields[1] != "GT/s" {
+		return 0
+	}
+
+	speed, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+
+	return speed
+}
+
+// pciLink returns the PCIe link details of the PCI device at devicePath, or nil if it doesn't have a PCIe link.
+// The link attributes of some devices can't be read depending on their power state, so read failures are ignored.
+func pciLink(devicePath string) *api.ResourcesPCILink {
+	if !sysfsExists(filepath.Join(devicePath, "current_link_speed")) {
+		return nil
+	}
+
+	link := api.ResourcesPCILink{}
+
+	content, err := os.ReadFile(filepath.Join(devicePath, "current_link_speed"))
+	if err == nil {
+		link.CurrentSpeed = pciLinkSpeed(string(content))
+	}
+
+	content, err = os.ReadFile(filepath.Join(devicePath, "max_link_speed"))
+	if err == nil {
+		link.MaximumSpeed = pciLinkSpeed(string(content))
+	}
+
+	width, err := readUint(filepath.Join(devicePath, "current_link_width"))
+	if err == nil {
+		link.CurrentWidth = width
+	}
+
+	width, err = readUint(filepath.Join(devicePath, "max_link_width"))
+	if err == nil {
+		link.MaximumWidth = width
+	}
+
+	return &link
+}
@@ -122,6 +122,12 @@ func LocalCopy(source string, dest string, bwlimit string, xattrs bool, rsyncArg
 	return msg, nil
 }
 
+// BwlimitFromBytes converts a bandwidth limit in bytes per second to a value for the --bwlimit argument of rsync.
+func BwlimitFromBytes(limit int64) string {
+	// Without a suffix, rsync uses units of 1024 bytes per second.
+	return fmt.Sprintf("%d", max(limit/1024, 1))
+}
+
 // Send sets up the sending half of an rsync, to recursively send the
 // directory pointed to by path over the websocket.
 func Send(name string, path string, conn io.ReadWriteCloser, tracker *ioprogress.ProgressTracker, features []string, bwlimit string, execPath string, rsyncArgs ...string) error {
@@ -55,6 +55,10 @@ import (
 var unavailablePools = make(map[string]struct{})
 var unavailablePoolsMu = sync.Mutex{}
 
+// migratingPools holds the names of the storage pools whose volumes are being moved to another storage pool.
+var migratingPools = make(map[string]struct{})
+var migratingPoolsMu = sync.Mutex{}
+
 // instanceDiskVolumeEffectiveFields fields from the instance disks that are applied to the volume's effective
 // config (but not stored in the disk's volume database record).
 var instanceDiskVolumeEffectiveFields = []string{
@@ -141,6 +145,40 @@ func (b *lxdBackend) isStatusReady() error {
 	return nil
 }
 
+// isNotMigrating returns an error if the volumes of the pool are being moved to another storage pool, in which
+// case no new volumes can be created on it.
+func (b *lxdBackend) isNotMigrating() error {
+	migratingPoolsMu.Lock()
+	defer migratingPoolsMu.Unlock()
+
+	_, found := migratingPools[b.name]
+	if found {
+		return api.StatusErrorf(http.StatusConflict, "Storage pool %q is being migrated to another storage pool", b.name)
+	}
+
+	return nil
+}
+
+// MarkMigrating marks the storage pool as having its volumes moved to another storage pool, so that no new volumes
+// are created on it in the meantime. Returns a function that removes the mark.
+func MarkMigrating(poolName string) (func(), error) {
+	migratingPoolsMu.Lock()
+	defer migratingPoolsMu.Unlock()
+
+	_, found := migratingPools[poolName]
+	if found {
+		return nil, api.StatusErrorf(http.StatusConflict, "Storage pool %q is already being migrated", poolName)
+	}
+
+	migratingPools[poolName] = struct{}{}
+
+	return func() {
+		migratingPoolsMu.Lock()
+		delete(migratingPools, poolName)
+		migratingPoolsMu.Unlock()
+	}, nil
+}
+
 // ToAPI returns the storage pool as an API representation.
 func (b *lxdBackend) ToAPI() api.StoragePool {
 	return b.db
@@ -264,6 +302,84 @@ func (b *lxdBackend) GetResources() (*api.ResourcesStoragePool, error) {
 	return b.driver.GetResources()
 }
 
+// Benchmark runs a short I/O benchmark against a temporary custom volume on the pool.
+// The volume isn't recorded in the database and is deleted once the benchmark is done.
+func (b *lxdBackend) Benchmark(ctx context.Context, size int64, op *operations.Operation) (*api.StoragePoolBenchmark, error) {
+	l := b.logger.AddContext(logger.Ctx{"size": size})
+	l.Debug("Benchmark started")
+	defer l.Debug("Benchmark finished")
+
+	if size > BenchmarkMaxSize {
+		return nil, fmt.Errorf("Benchmark size must be at most %d bytes", BenchmarkMaxSize)
+	}
+
+	// Use a storage name that can't clash with any project prefixed custom volume or with benchmarks running
+	// on other members of a remote pool.
+	vol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentTypeFS, "lxd-benchmark-"+b.state.ServerName, nil)
+
+	// Run one benchmark at a time so that concurrent requests don't remove each other's volume.
+	unlock, err := locking.Lock(ctx, drivers.OperationLockName("Benchmark", b.name, vol.Type(), vol.ContentType(), vol.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	defer unlock()
+
+	err = b.driver.FillVolumeConfig(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the volume is large enough to hold the test file and the filesystem overhead.
+	minSize := size + size/10 + 64*1024*1024
+	if vol.ConfigSize() != "" {
+		volSize, err := units.ParseByteSizeString(vol.ConfigSize())
+		if err != nil {
+			return nil, err
+		}
+
+		if volSize < minSize {
+			vol.SetConfigSize(fmt.Sprintf("%d", minSize))
+		}
+	}
+
+	// Remove any volume left behind by an interrupted benchmark.
+	exists, err := b.driver.HasVolume(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		err = b.driver.DeleteVolume(vol, op)
+		if err != nil {
+			return nil, fmt.Errorf("Failed deleting leftover benchmark volume: %w", err)
+		}
+	}
+
+	err = b.driver.CreateVolume(vol, nil, op)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating benchmark volume: %w", err)
+	}
+
+	defer func() {
+		err := b.driver.DeleteVolume(vol, op)
+		if err != nil {
+			l.Warn("Failed deleting benchmark volume", logger.Ctx{"err": err})
+		}
+	}()
+
+	var result *api.StoragePoolBenchmark
+	err = vol.MountTask(func(mountPath string, op *operations.Operation) error {
+		result, err = Benchmark(ctx, mountPath, size, b.driver.Info().DirectIO)
+		return err
+	}, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // IsUsed returns whether the storage pool is used by any volumes or profiles (excluding image volumes).
 func (b *lxdBackend) IsUsed() (bool, error) {
 	usedBy, err := UsedBy(context.TODO(), b.state, b, true, true, cluster.StoragePoolVolumeTypeNameImage)
@@ -652,6 +768,11 @@ func (b *lxdBackend) CreateInstance(inst instance.Instance, op *operations.Opera
 		return err
 	}
 
+	err = b.isNotMigrating()
+	if err != nil {
+		return err
+	}
+
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
 		return err
@@ -715,8 +836,13 @@ func (b *lxdBackend) CreateInstanceFromBackup(srcBackup backup.Info, srcData io.
 	l.Debug("CreateInstanceFromBackup started")
 	defer l.Debug("CreateInstanceFromBackup finished")
 
+	err := b.isNotMigrating()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Validate the names in the backup.yaml file as these could be malicious.
-	err := instance.ValidName(srcBackup.Name, false)
+	err = instance.ValidName(srcBackup.Name, false)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1013,6 +1139,11 @@ func (b *lxdBackend) CreateInstanceFromCopy(inst instance.Instance, src instance
 		return err
 	}
 
+	err = b.isNotMigrating()
+	if err != nil {
+		return err
+	}
+
 	if inst.Type() != src.Type() {
 		return fmt.Errorf("Instance types must match")
 	}
@@ -1817,6 +1948,11 @@ func (b *lxdBackend) CreateInstanceFromImage(inst instance.Instance, fingerprint
 		return err
 	}
 
+	err = b.isNotMigrating()
+	if err != nil {
+		return err
+	}
+
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
 		return err
@@ -1957,6 +2093,11 @@ func (b *lxdBackend) CreateInstanceFromMigration(inst instance.Instance, conn io
 		return err
 	}
 
+	err = b.isNotMigrating()
+	if err != nil {
+		return err
+	}
+
 	if args.Config != nil {
 		return fmt.Errorf("Migration VolumeTargetArgs.Config cannot be set for instances")
 	}
@@ -4749,6 +4890,11 @@ func (b *lxdBackend) CreateCustomVolume(projectName string, volName string, desc
 		return err
 	}
 
+	err = b.isNotMigrating()
+	if err != nil {
+		return err
+	}
+
 	// Get the volume name on storage.
 	volStorageName := project.StorageVolume(projectName, volName)
 	vol := b.GetNewVolume(drivers.VolumeTypeCustom, contentType, volStorageName, config)
@@ -4805,6 +4951,11 @@ func (b *lxdBackend) CreateCustomVolumeFromCopy(projectName string, srcProjectNa
 		return err
 	}
 
+	err = b.isNotMigrating()
+	if err != nil {
+		return err
+	}
+
 	if srcProjectName == "" {
 		srcProjectName = projectName
 	}
@@ -5226,6 +5377,11 @@ func (b *lxdBackend) CreateCustomVolumeFromMigration(projectName string, conn io
 		return err
 	}
 
+	err = b.isNotMigrating()
+	if err != nil {
+		return err
+	}
+
 	storagePoolSupported := false
 	for _, supportedType := range b.Driver().Info().VolumeTypes {
 		if supportedType == drivers.VolumeTypeCustom {
@@ -5877,6 +6033,60 @@ func (b *lxdBackend) UnmountCustomVolume(projectName, volName string, op *operat
 	return b.driver.UnmountVolume(vol, false, op)
 }
 
+// MountCustomVolumeSnapshot mounts a custom volume snapshot read-only.
+func (b *lxdBackend) MountCustomVolumeSnapshot(projectName, volName string, op *operations.Operation) (*MountInfo, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName})
+	l.Debug("MountCustomVolumeSnapshot started")
+	defer l.Debug("MountCustomVolumeSnapshot finished")
+
+	if !shared.IsSnapshot(volName) {
+		return nil, fmt.Errorf("Volume must be a snapshot")
+	}
+
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	volume, err := VolumeDBGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, volName)
+	snapVol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, volume.Config)
+
+	err = b.driver.MountVolumeSnapshot(snapVol, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MountInfo{}, nil
+}
+
+// UnmountCustomVolumeSnapshot unmounts a custom volume snapshot.
+func (b *lxdBackend) UnmountCustomVolumeSnapshot(projectName, volName string, op *operations.Operation) (bool, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName})
+	l.Debug("UnmountCustomVolumeSnapshot started")
+	defer l.Debug("UnmountCustomVolumeSnapshot finished")
+
+	if !shared.IsSnapshot(volName) {
+		return false, fmt.Errorf("Volume must be a snapshot")
+	}
+
+	volume, err := VolumeDBGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return false, err
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, volName)
+	snapVol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, volume.Config)
+
+	return b.driver.UnmountVolumeSnapshot(snapVol, op)
+}
+
 // ImportCustomVolume takes an existing custom volume on the storage backend and ensures that the DB records,
 // volume directories and symlinks are restored as needed to make it operational with LXD.
 // Used during the recovery import stage.
@@ -7112,6 +7322,11 @@ func (b *lxdBackend) CreateCustomVolumeFromISO(projectName string, volName strin
 	l.Debug("CreateCustomVolumeFromISO started")
 	defer l.Debug("CreateCustomVolumeFromISO finished")
 
+	err := b.isNotMigrating()
+	if err != nil {
+		return err
+	}
+
 	// Check whether we are allowed to create volumes.
 	req := api.StorageVolumesPost{
 		Name: volName,
@@ -7122,7 +7337,7 @@ func (b *lxdBackend) CreateCustomVolumeFromISO(projectName string, volName strin
 		},
 	}
 
-	err := b.state.DB.Cluster.Transaction(b.state.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+	err = b.state.DB.Cluster.Transaction(b.state.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		return project.AllowVolumeCreation(b.state.GlobalConfig, tx, projectName, req)
 	})
 	if err != nil {
@@ -7186,6 +7401,11 @@ func (b *lxdBackend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData
 	l.Debug("CreateCustomVolumeFromBackup started")
 	defer l.Debug("CreateCustomVolumeFromBackup finished")
 
+	err := b.isNotMigrating()
+	if err != nil {
+		return err
+	}
+
 	if srcBackup.Config == nil || srcBackup.Config.Volume == nil {
 		return fmt.Errorf("Valid volume config not found in index")
 	}
@@ -7195,7 +7415,7 @@ func (b *lxdBackend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData
 	}
 
 	// Validate the names in the index.yaml file as these could be malicious.
-	err := ValidVolumeName(srcBackup.Name)
+	err = ValidVolumeName(srcBackup.Name)
 	if err != nil {
 		return err
 	}
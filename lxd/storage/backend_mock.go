@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"io"
 	"net/url"
 	"time"
@@ -76,6 +77,10 @@ func (b *mockBackend) GetResources() (*api.ResourcesStoragePool, error) {
 	return nil, nil
 }
 
+func (b *mockBackend) Benchmark(ctx context.Context, size int64, op *operations.Operation) (*api.StoragePoolBenchmark, error) {
+	return nil, nil
+}
+
 func (b *mockBackend) IsUsed() (bool, error) {
 	return false, nil
 }
@@ -321,6 +326,14 @@ func (b *mockBackend) UnmountCustomVolume(projectName string, volName string, op
 	return true, nil
 }
 
+func (b *mockBackend) MountCustomVolumeSnapshot(projectName string, volName string, op *operations.Operation) (*MountInfo, error) {
+	return nil, nil
+}
+
+func (b *mockBackend) UnmountCustomVolumeSnapshot(projectName string, volName string, op *operations.Operation) (bool, error) {
+	return true, nil
+}
+
 func (b *mockBackend) ImportCustomVolume(projectName string, poolVol *backupConfig.Config, op *operations.Operation) (revert.Hook, error) {
 	return nil, nil
 }
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// benchmarkSequentialBlockSize is the block size used for the sequential tests.
+const benchmarkSequentialBlockSize = 1024 * 1024
+
+// benchmarkRandomBlockSize is the block size used for the random tests.
+const benchmarkRandomBlockSize = 4096
+
+// benchmarkRandomDuration is how long each of the random tests runs for.
+const benchmarkRandomDuration = 5 * time.Second
+
+// BenchmarkMaxSize is the maximum size of the benchmark test file.
+const BenchmarkMaxSize = 16 * 1024 * 1024 * 1024
+
+// Benchmark runs a short I/O benchmark in the directory at path using a test file of the given size.
+// It measures sequential read and write throughput followed by random 4KiB reads and synchronous writes.
+// If directIO is true, the page cache is bypassed using O_DIRECT, otherwise it is dropped between the tests.
+func Benchmark(ctx context.Context, path string, size int64, directIO bool) (*api.StoragePoolBenchmark, error) {
+	if size < benchmarkSequentialBlockSize {
+		return nil, fmt.Errorf("Benchmark size must be at least %d bytes", benchmarkSequentialBlockSize)
+	}
+
+	// Round the size down to a full number of blocks.
+	size -= size % benchmarkSequentialBlockSize
+
+	flags := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	if directIO {
+		flags |= unix.O_DIRECT
+	}
+
+	testPath := filepath.Join(path, "benchmark.img")
+	f, err := os.OpenFile(testPath, flags, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating benchmark file: %w", err)
+	}
+
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(testPath)
+	}()
+
+	// Use page aligned buffers as required by O_DIRECT.
+	buf, err := unix.Mmap(-1, 0, benchmarkSequentialBlockSize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return nil, fmt.Errorf("Failed allocating benchmark buffer: %w", err)
+	}
+
+	defer func() { _ = unix.Munmap(buf) }()
+
+	// Fill the buffer with random data so that compression and deduplication don't skew the results.
+	_, err = rand.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	dropCache := func() {
+		if !directIO {
+			_ = unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+		}
+	}
+
+	result := &api.StoragePoolBenchmark{
+		CreatedAt: time.Now(),
+		Size:      size,
+	}
+
+	// Sequential write.
+	start := time.Now()
+	for offset := int64(0); offset < size; offset += benchmarkSequentialBlockSize {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		_, err = f.WriteAt(buf, offset)
+		if err != nil {
+			return nil, fmt.Errorf("Failed sequential write: %w", err)
+		}
+	}
+
+	err = f.Sync()
+	if err != nil {
+		return nil, fmt.Errorf("Failed syncing benchmark file: %w", err)
+	}
+
+	result.SequentialWrite = benchmarkRate(size, time.Since(start))
+
+	// Sequential read.
+	dropCache()
+	start = time.Now()
+	for offset := int64(0); offset < size; offset += benchmarkSequentialBlockSize {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		_, err = f.ReadAt(buf, offset)
+		if err != nil {
+			return nil, fmt.Errorf("Failed sequential read: %w", err)
+		}
+	}
+
+	result.SequentialRead = benchmarkRate(size, time.Since(start))
+
+	// Random reads and writes on 4KiB aligned blocks.
+	blocks := big.NewInt(size / benchmarkRandomBlockSize)
+	block := buf[:benchmarkRandomBlockSize]
+
+	randomTest := func(do func(offset int64) error) (int64, int64, error) {
+		var ops int64
+		var latency time.Duration
+
+		dropCache()
+		start := time.Now()
+		for time.Since(start) < benchmarkRandomDuration {
+			if ctx.Err() != nil {
+				return -1, -1, ctx.Err()
+			}
+
+			n, err := rand.Int(rand.Reader, blocks)
+			if err != nil {
+				return -1, -1, err
+			}
+
+			opStart := time.Now()
+			err = do(n.Int64() * benchmarkRandomBlockSize)
+			if err != nil {
+				return -1, -1, err
+			}
+
+			latency += time.Since(opStart)
+			ops++
+		}
+
+		return benchmarkRate(ops, time.Since(start)), latency.Microseconds() / ops, nil
+	}
+
+	result.RandomReadIOPS, result.RandomReadLatency, err = randomTest(func(offset int64) error {
+		_, err := f.ReadAt(block, offset)
+		if err != nil {
+			return fmt.Errorf("Failed random read: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.RandomWriteIOPS, result.RandomWriteLatency, err = randomTest(func(offset int64) error {
+		_, err := f.WriteAt(block, offset)
+		if err != nil {
+			return fmt.Errorf("Failed random write: %w", err)
+		}
+
+		err = unix.Fdatasync(int(f.Fd()))
+		if err != nil {
+			return fmt.Errorf("Failed syncing random write: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// benchmarkRate returns the per second rate of count over the given duration.
+func benchmarkRate(count int64, duration time.Duration) int64 {
+	if duration <= 0 {
+		return count
+	}
+
+	return int64(float64(count) / duration.Seconds())
+}
@@ -183,6 +183,14 @@ func (d *ceph) Create() error {
 			d.logger.Warn("Failed to initialize pool", logger.Ctx{"pool": d.config["ceph.osd.pool_name"], "cluster": d.config["ceph.cluster_name"]})
 		}
 
+		// Create the RADOS namespace in the new osd pool.
+		if d.config["ceph.osd.pool_namespace"] != "" {
+			err = d.osdCreateNamespace()
+			if err != nil {
+				return err
+			}
+		}
+
 		// Create placeholder storage volume. Other LXD instances will use this to detect whether this osd
 		// pool is already in use by another LXD instance.
 		err = d.rbdCreateVolume(placeholderVol, "0")
@@ -192,6 +200,23 @@ func (d *ceph) Create() error {
 
 		d.config["volatile.pool.pristine"] = "true"
 	} else {
+		// Use an existing RADOS namespace or create it in the existing osd pool.
+		if d.config["ceph.osd.pool_namespace"] != "" {
+			namespaceExists, err := d.osdNamespaceExists()
+			if err != nil {
+				return fmt.Errorf("Failed checking the existence of the RADOS namespace %q in the ceph %q osd pool: %w", d.config["ceph.osd.pool_namespace"], d.config["ceph.osd.pool_name"], err)
+			}
+
+			if !namespaceExists {
+				err = d.osdCreateNamespace()
+				if err != nil {
+					return err
+				}
+
+				revert.Add(func() { _ = d.osdDeleteNamespace() })
+			}
+		}
+
 		volExists, err := d.HasVolume(placeholderVol)
 		if err != nil {
 			return err
@@ -201,6 +226,10 @@ func (d *ceph) Create() error {
 			// ceph.osd.force_reuse is deprecated and should not be used. OSD pools are a logical
 			// construct there is no good reason not to create one for dedicated use by LXD.
 			if shared.IsFalseOrEmpty(d.config["ceph.osd.force_reuse"]) {
+				if d.config["ceph.osd.pool_namespace"] != "" {
+					return fmt.Errorf("Namespace '%s' of pool '%s' in cluster '%s' seems to be in use by another LXD instance", d.config["ceph.osd.pool_namespace"], d.config["ceph.osd.pool_name"], d.config["ceph.cluster_name"])
+				}
+
 				return fmt.Errorf("Pool '%s' in cluster '%s' seems to be in use by another LXD instance", d.config["ceph.osd.pool_name"], d.config["ceph.cluster_name"])
 			}
 
@@ -261,9 +290,30 @@ func (d *ceph) Delete(op *operations.Operation) error {
 		d.logger.Warn("Pool does not exist", logger.Ctx{"pool": d.config["ceph.osd.pool_name"], "cluster": d.config["ceph.cluster_name"]})
 	}
 
-	// Check whether we own the pool and only remove in this case.
-	if shared.IsTrue(d.config["volatile.pool.pristine"]) {
-		// Delete the osd pool.
+	// When using a RADOS namespace, the osd pool is shared with other storage pools, so only the
+	// namespace is removed (along with the placeholder volume) and only if we own it.
+	if d.config["ceph.osd.pool_namespace"] != "" {
+		if poolExists && shared.IsTrue(d.config["volatile.pool.pristine"]) {
+			placeholderVol := d.getPlaceholderVolume()
+			volExists, err := d.HasVolume(placeholderVol)
+			if err != nil {
+				return err
+			}
+
+			if volExists {
+				err = d.rbdDeleteVolume(placeholderVol)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = d.osdDeleteNamespace()
+			if err != nil {
+				return fmt.Errorf("Failed removing RADOS namespace %q: %w", d.config["ceph.osd.pool_namespace"], err)
+			}
+		}
+	} else if shared.IsTrue(d.config["volatile.pool.pristine"]) {
+		// Check whether we own the pool and only remove in this case.
 		if poolExists {
 			err := d.osdDeletePool()
 			if err != nil {
@@ -317,6 +367,21 @@ func (d *ceph) Validate(config map[string]string) error {
 		//  type: string
 		//  shortdesc: Name of the OSD data pool
 		"ceph.osd.data_pool_name": validate.IsAny,
+		// lxdmeta:generate(entities=storage-ceph; group=pool-conf; key=ceph.osd.pool_namespace)
+		// When set, the storage volumes are created in this RADOS namespace of the OSD storage pool.
+		// This allows several storage pools (for example, one per tenant) to share the same OSD storage pool while
+		// keeping their volumes isolated from each other. The namespace is created if it doesn't exist.
+		// This option cannot be changed after the storage pool has been created.
+		// ---
+		//  type: string
+		//  shortdesc: Name of the RADOS namespace in the OSD storage pool
+		"ceph.osd.pool_namespace": validate.Optional(func(value string) error {
+			if strings.ContainsAny(value, "/@") {
+				return fmt.Errorf("RADOS namespace names cannot contain %q or %q", "/", "@")
+			}
+
+			return nil
+		}),
 		// lxdmeta:generate(entities=storage-ceph; group=pool-conf; key=ceph.rbd.clone_copy)
 		// Enable this option to use RBD lightweight clones rather than full dataset copies.
 		// ---
@@ -359,6 +424,11 @@ func (d *ceph) Validate(config map[string]string) error {
 
 // Update applies any driver changes required from a configuration change.
 func (d *ceph) Update(changedConfig map[string]string) error {
+	_, changed := changedConfig["ceph.osd.pool_namespace"]
+	if changed {
+		return fmt.Errorf("ceph.osd.pool_namespace cannot be changed")
+	}
+
 	return nil
 }
 
@@ -98,6 +98,75 @@ func (d *ceph) osdDeletePool() error {
 	return nil
 }
 
+// osdNamespaceExists checks whether the RADOS namespace of the storage pool exists in the OSD pool.
+func (d *ceph) osdNamespaceExists() (bool, error) {
+	msg, err := shared.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"--pool", d.config["ceph.osd.pool_name"],
+		"--format", "json",
+		"namespace",
+		"ls")
+	if err != nil {
+		return false, err
+	}
+
+	var namespaces []struct {
+		Name string `json:"name"`
+	}
+
+	err = json.Unmarshal([]byte(msg), &namespaces)
+	if err != nil {
+		return false, fmt.Errorf("Failed parsing RADOS namespaces: %w", err)
+	}
+
+	for _, namespace := range namespaces {
+		if namespace.Name == d.config["ceph.osd.pool_namespace"] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// osdCreateNamespace creates the RADOS namespace of the storage pool in the OSD pool.
+func (d *ceph) osdCreateNamespace() error {
+	_, err := shared.TryRunCommand("rbd", d.rbdArgs("namespace", "create")...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// osdDeleteNamespace removes the RADOS namespace of the storage pool from the OSD pool.
+// The namespace must not contain any storage volumes.
+func (d *ceph) osdDeleteNamespace() error {
+	_, err := shared.RunCommand("rbd", d.rbdArgs("namespace", "remove")...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// rbdArgs returns the arguments selecting the Ceph user, cluster, OSD pool and RADOS namespace of the storage
+// pool, followed by the given arguments.
+func (d *ceph) rbdArgs(args ...string) []string {
+	cmd := []string{
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"--pool", d.config["ceph.osd.pool_name"],
+	}
+
+	if d.config["ceph.osd.pool_namespace"] != "" {
+		cmd = append(cmd, "--namespace", d.config["ceph.osd.pool_namespace"])
+	}
+
+	return append(cmd, args...)
+}
+
 // rbdCreateVolume creates an RBD storage volume.
 // Note that the default set of features is intentionally limited
 // by passing --image-feature explicitly. This is done to ensure that
@@ -110,11 +179,7 @@ func (d *ceph) rbdCreateVolume(vol Volume, size string) error {
 		return err
 	}
 
-	cmd := []string{
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-	}
+	cmd := d.rbdArgs()
 
 	if d.config["ceph.rbd.features"] != "" {
 		for _, feature := range shared.SplitNTrimSpace(d.config["ceph.rbd.features"], ",", -1, true) {
@@ -144,12 +209,9 @@ func (d *ceph) rbdCreateVolume(vol Volume, size string) error {
 //     to check for the existence of the pool first.
 func (d *ceph) rbdDeleteVolume(vol Volume) error {
 	_, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"rm",
-		d.getRBDVolumeName(vol, "", false, false))
+		"rbd", d.rbdArgs(
+			"rm",
+			d.getRBDVolumeName(vol, "", false, false))...)
 	if err != nil {
 		return err
 	}
@@ -163,12 +225,9 @@ func (d *ceph) rbdDeleteVolume(vol Volume) error {
 func (d *ceph) rbdMapVolume(vol Volume) (string, error) {
 	rbdName := d.getRBDVolumeName(vol, "", false, false)
 	devPath, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"map",
-		rbdName)
+		"rbd", d.rbdArgs(
+			"map",
+			rbdName)...)
 	if err != nil {
 		return "", err
 	}
@@ -194,12 +253,9 @@ func (d *ceph) rbdUnmapVolume(vol Volume, unmapUntilEINVAL bool) error {
 
 again:
 	_, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"unmap",
-		rbdVol)
+		"rbd", d.rbdArgs(
+			"unmap",
+			rbdVol)...)
 	if err != nil {
 		runError, ok := err.(shared.RunError)
 		if ok {
@@ -246,12 +302,9 @@ again:
 func (d *ceph) rbdUnmapVolumeSnapshot(vol Volume, snapshotName string, unmapUntilEINVAL bool) error {
 again:
 	_, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"unmap",
-		d.getRBDVolumeName(vol, snapshotName, false, false))
+		"rbd", d.rbdArgs(
+			"unmap",
+			d.getRBDVolumeName(vol, snapshotName, false, false))...)
 	if err != nil {
 		runError, ok := err.(shared.RunError)
 		if ok {
@@ -277,14 +330,11 @@ again:
 // rbdCreateVolumeSnapshot creates a read-write snapshot of a given RBD storage volume.
 func (d *ceph) rbdCreateVolumeSnapshot(vol Volume, snapshotName string) error {
 	_, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"snap",
-		"create",
-		"--snap", snapshotName,
-		d.getRBDVolumeName(vol, "", false, false))
+		"rbd", d.rbdArgs(
+			"snap",
+			"create",
+			"--snap", snapshotName,
+			d.getRBDVolumeName(vol, "", false, false))...)
 	if err != nil {
 		return err
 	}
@@ -296,14 +346,11 @@ func (d *ceph) rbdCreateVolumeSnapshot(vol Volume, snapshotName string) error {
 // This is a precondition to be able to create RBD clones from a given snapshot.
 func (d *ceph) rbdProtectVolumeSnapshot(vol Volume, snapshotName string) error {
 	_, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"snap",
-		"protect",
-		"--snap", snapshotName,
-		d.getRBDVolumeName(vol, "", false, false))
+		"rbd", d.rbdArgs(
+			"snap",
+			"protect",
+			"--snap", snapshotName,
+			d.getRBDVolumeName(vol, "", false, false))...)
 	if err != nil {
 		runError, ok := err.(shared.RunError)
 		if ok {
@@ -327,14 +374,11 @@ func (d *ceph) rbdProtectVolumeSnapshot(vol Volume, snapshotName string) error {
 // - This command will only succeed if the snapshot does not have any clones.
 func (d *ceph) rbdUnprotectVolumeSnapshot(vol Volume, snapshotName string) error {
 	_, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"snap",
-		"unprotect",
-		"--snap", snapshotName,
-		d.getRBDVolumeName(vol, "", false, false))
+		"rbd", d.rbdArgs(
+			"snap",
+			"unprotect",
+			"--snap", snapshotName,
+			d.getRBDVolumeName(vol, "", false, false))...)
 	if err != nil {
 		runError, ok := err.(shared.RunError)
 		if ok {
@@ -388,13 +432,10 @@ func (d *ceph) rbdCreateClone(sourceVol Volume, sourceSnapshotName string, targe
 // rbdListSnapshotClones list all clones of an RBD snapshot.
 func (d *ceph) rbdListSnapshotClones(vol Volume, snapshotName string) ([]string, error) {
 	msg, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"children",
-		"--image", d.getRBDVolumeName(vol, "", false, false),
-		"--snap", snapshotName)
+		"rbd", d.rbdArgs(
+			"children",
+			"--image", d.getRBDVolumeName(vol, "", false, false),
+			"--snap", snapshotName)...)
 	if err != nil {
 		return nil, err
 	}
@@ -492,12 +533,9 @@ func (d *ceph) rbdRenameVolumeSnapshot(vol Volume, oldSnapshotName string, newSn
 //     helper library provides two small functions to do this but see below.
 func (d *ceph) rbdGetVolumeParent(vol Volume) (string, error) {
 	msg, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"info",
-		d.getRBDVolumeName(vol, "", false, false))
+		"rbd", d.rbdArgs(
+			"info",
+			d.getRBDVolumeName(vol, "", false, false))...)
 	if err != nil {
 		return "", err
 	}
@@ -526,13 +564,10 @@ func (d *ceph) rbdGetVolumeParent(vol Volume) (string, error) {
 // unprotected.
 func (d *ceph) rbdDeleteVolumeSnapshot(vol Volume, snapshotName string) error {
 	_, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"snap",
-		"rm",
-		d.getRBDVolumeName(vol, snapshotName, false, false))
+		"rbd", d.rbdArgs(
+			"snap",
+			"rm",
+			d.getRBDVolumeName(vol, snapshotName, false, false))...)
 	if err != nil {
 		return err
 	}
@@ -548,14 +583,11 @@ func (d *ceph) rbdDeleteVolumeSnapshot(vol Volume, snapshotName string) error {
 // <rbd-snapshot-name>.
 func (d *ceph) rbdListVolumeSnapshots(vol Volume) ([]string, error) {
 	msg, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"--format", "json",
-		"snap",
-		"ls",
-		d.getRBDVolumeName(vol, "", false, false))
+		"rbd", d.rbdArgs(
+			"--format", "json",
+			"snap",
+			"ls",
+			d.getRBDVolumeName(vol, "", false, false))...)
 	if err != nil {
 		return []string{}, err
 	}
@@ -877,17 +909,18 @@ func (d *ceph) deleteVolumeSnapshot(vol Volume, snapshotName string) (int, error
 
 // parseParent splits a string describing a RBD storage entity into its components.
 // This can be used on strings like: <osd-pool-name>/<lxd-specific-prefix>_<rbd-storage-volume>@<rbd-snapshot-name>
+// or <osd-pool-name>/<rados-namespace>/<lxd-specific-prefix>_<rbd-storage-volume>@<rbd-snapshot-name>
 // and will return a Volume and snapshot name.
 func (d *ceph) parseParent(parent string) (Volume, string, error) {
 	vol := Volume{}
 
-	idx := strings.Index(parent, "/")
+	idx := strings.LastIndex(parent, "/")
 	if idx == -1 {
 		return vol, "", fmt.Errorf("Pool delimiter not found")
 	}
 
 	slider := parent[(idx + 1):]
-	poolName := parent[:idx]
+	poolName, _, _ := strings.Cut(parent[:idx], "/")
 
 	// Match image volumes and extract their various parts into a Volume struct.
 	// Looks for volumes like:
@@ -1034,6 +1067,17 @@ func (d *ceph) getRBDMappedDevPath(vol Volume, mapIfMissing bool) (bool, string,
 			continue
 		}
 
+		// Get the namespace for the RBD device (older kernels don't report it).
+		devPoolNamespace, err := os.ReadFile(fmt.Sprintf("/sys/devices/rbd/%s/pool_ns", fName))
+		if err != nil && !os.IsNotExist(err) {
+			return false, "", err
+		}
+
+		// Skip if the namespaces don't match.
+		if strings.TrimSpace(string(devPoolNamespace)) != d.config["ceph.osd.pool_namespace"] {
+			continue
+		}
+
 		// Get the volume name for the RBD device.
 		devName, err := os.ReadFile(fmt.Sprintf("/sys/devices/rbd/%s/name", fName))
 		if err != nil {
@@ -1108,10 +1152,14 @@ func (d *ceph) generateUUID(fsType string, devPath string) error {
 func (d *ceph) getRBDVolumeName(vol Volume, snapName string, zombie bool, withPoolName bool) string {
 	out := CephGetRBDImageName(vol, snapName, zombie)
 
-	// If needed, the output will be prefixed with the pool name, e.g.
-	// <pool>/<type>_<volname>@<snapname>.
+	// If needed, the output will be prefixed with the pool name and namespace (if any), e.g.
+	// <pool>/<type>_<volname>@<snapname> or <pool>/<namespace>/<type>_<volname>@<snapname>.
 	if withPoolName {
-		out = fmt.Sprintf("%s/%s", d.config["ceph.osd.pool_name"], out)
+		if d.config["ceph.osd.pool_namespace"] != "" {
+			out = fmt.Sprintf("%s/%s/%s", d.config["ceph.osd.pool_name"], d.config["ceph.osd.pool_namespace"], out)
+		} else {
+			out = fmt.Sprintf("%s/%s", d.config["ceph.osd.pool_name"], out)
+		}
 	}
 
 	return out
@@ -1259,13 +1307,10 @@ func (d *ceph) resizeVolume(vol Volume, sizeBytes int64, allowShrink bool) error
 		args = append(args, "--allow-shrink")
 	}
 
-	args = append(args,
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
+	args = append(args, d.rbdArgs(
 		"--size", fmt.Sprintf("%dB", sizeBytes),
 		d.getRBDVolumeName(vol, "", false, false),
-	)
+	)...)
 
 	// Resize the block device.
 	_, err := shared.TryRunCommand("rbd", args...)
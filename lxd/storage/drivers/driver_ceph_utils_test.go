@@ -131,6 +131,7 @@ func Example_ceph_parseParent() {
 		"pool/container_bar@zombie_snapshot_ce77e971-6c1b-45c0-b193-dba9ec5e7d82",
 		"pool/container_test-project_c4.block",
 		"pool/zombie_container_test-project_c1_28e7a7ab-740a-490c-8118-7caf7810f83b@zombie_snapshot_1027f4ab-de11-4cee-8015-bd532a1fed76",
+		"pool/tenant1/container_test-project_c5@snapshot_snap0",
 	}
 
 	for _, parent := range parents {
@@ -148,6 +149,7 @@ func Example_ceph_parseParent() {
 	// pool container bar  filesystem zombie_snapshot_ce77e971-6c1b-45c0-b193-dba9ec5e7d82 <nil>
 	// pool container test-project_c4  block  <nil>
 	// pool zombie_container test-project_c1_28e7a7ab-740a-490c-8118-7caf7810f83b  filesystem zombie_snapshot_1027f4ab-de11-4cee-8015-bd532a1fed76 <nil>
+	// pool container test-project_c5  filesystem snapshot_snap0 <nil>
 }
 
 func Test_ceph_findLastCommonSnapshot(t *testing.T) {
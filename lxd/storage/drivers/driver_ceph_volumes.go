@@ -293,14 +293,11 @@ func (d *ceph) getVolumeSize(volumeName string) (int64, error) {
 	}{}
 
 	jsonInfo, err := shared.TryRunCommand(
-		"rbd",
-		"info",
-		"--format", "json",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		volumeName,
-	)
+		"rbd", d.rbdArgs(
+			"info",
+			"--format", "json",
+			volumeName,
+		)...)
 	if err != nil {
 		return -1, err
 	}
@@ -1022,13 +1019,10 @@ func (d *ceph) DeleteVolume(vol Volume, op *operations.Operation) error {
 
 			// Delete snapshots.
 			_, err := shared.RunCommand(
-				"rbd",
-				"--id", d.config["ceph.user.name"],
-				"--cluster", d.config["ceph.cluster_name"],
-				"--pool", d.config["ceph.osd.pool_name"],
-				"snap",
-				"purge",
-				d.getRBDVolumeName(vol, "", false, false))
+				"rbd", d.rbdArgs(
+					"snap",
+					"purge",
+					d.getRBDVolumeName(vol, "", false, false))...)
 			if err != nil {
 				return err
 			}
@@ -1084,13 +1078,10 @@ func (d *ceph) hasVolume(rbdVolumeName string) (bool, error) {
 	defer cancel()
 
 	_, err := shared.RunCommandContext(ctx,
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"info",
-		rbdVolumeName,
-	)
+		"rbd", d.rbdArgs(
+			"info",
+			rbdVolumeName,
+		)...)
 
 	if err != nil {
 		runErr, ok := err.(shared.RunError)
@@ -1253,14 +1244,11 @@ func (d *ceph) GetVolumeUsage(vol Volume) (int64, error) {
 	defer cancel()
 
 	jsonInfo, err := shared.RunCommandContext(ctx,
-		"rbd",
-		"du",
-		"--format", "json",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		d.getRBDVolumeName(vol, "", false, false),
-	)
+		"rbd", d.rbdArgs(
+			"du",
+			"--format", "json",
+			d.getRBDVolumeName(vol, "", false, false),
+		)...)
 	if err != nil {
 		return -1, err
 	}
@@ -1421,12 +1409,7 @@ func (d *ceph) GetVolumeDiskPath(vol Volume) (string, error) {
 func (d *ceph) ListVolumes() ([]Volume, error) {
 	vols := make(map[string]Volume)
 
-	cmd := exec.Command("rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"ls",
-	)
+	cmd := exec.Command("rbd", d.rbdArgs("ls")...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -1924,12 +1907,9 @@ func (d *ceph) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) er
 func (d *ceph) DeleteVolumeSnapshot(snapVol Volume, op *operations.Operation) error {
 	// Check if snapshot exists, and return if not.
 	_, err := shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"info",
-		d.getRBDVolumeName(snapVol, "", false, false))
+		"rbd", d.rbdArgs(
+			"info",
+			d.getRBDVolumeName(snapVol, "", false, false))...)
 	if err != nil {
 		return nil
 	}
@@ -2194,14 +2174,11 @@ func (d *ceph) restoreVolume(vol Volume, snapVol Volume, op *operations.Operatio
 	_, snapshotName, _ := api.GetParentAndSnapshotName(snapVol.name)
 
 	_, err = shared.RunCommand(
-		"rbd",
-		"--id", d.config["ceph.user.name"],
-		"--cluster", d.config["ceph.cluster_name"],
-		"--pool", d.config["ceph.osd.pool_name"],
-		"snap",
-		"rollback",
-		"--snap", fmt.Sprintf("snapshot_%s", snapshotName),
-		d.getRBDVolumeName(vol, "", false, false))
+		"rbd", d.rbdArgs(
+			"snap",
+			"rollback",
+			"--snap", fmt.Sprintf("snapshot_%s", snapshotName),
+			d.getRBDVolumeName(vol, "", false, false))...)
 	if err != nil {
 		return err
 	}
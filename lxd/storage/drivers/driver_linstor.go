@@ -0,0 +1,299 @@
+package drivers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/migration"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// linstorDefaultResourceGroupName represents the default LINSTOR resource group name.
+const linstorDefaultResourceGroupName = "lxd"
+
+// linstorDefaultResourceGroupPlaceCount represents the default number of replicas of each volume.
+const linstorDefaultResourceGroupPlaceCount = "2"
+
+var linstorLoaded bool
+var linstorVersion string
+
+type linstor struct {
+	common
+
+	// Holds the low level HTTP client for the LINSTOR API.
+	// Use linstor.client() to retrieve the client struct.
+	httpClient *linstorClient
+}
+
+// load is used to run one-time action per-driver rather than per-pool.
+func (d *linstor) load() error {
+	// Done if previously loaded.
+	if linstorLoaded {
+		return nil
+	}
+
+	// The DRBD kernel module is required to access the volumes.
+	err := util.LoadModule("drbd")
+	if err != nil {
+		return fmt.Errorf("Failed to load the DRBD kernel module: %w", err)
+	}
+
+	// Detect and record the version.
+	out, err := os.ReadFile("/sys/module/drbd/version")
+	if err != nil {
+		return fmt.Errorf("Failed to get DRBD version: %w", err)
+	}
+
+	version := strings.TrimSpace(string(out))
+	if !strings.HasPrefix(version, "9.") {
+		return fmt.Errorf("DRBD version 9 is required, found %q", version)
+	}
+
+	linstorVersion = fmt.Sprintf("%s (DRBD)", version)
+
+	linstorLoaded = true
+	return nil
+}
+
+// isRemote returns true indicating this driver uses remote storage.
+func (d *linstor) isRemote() bool {
+	return true
+}
+
+// Info returns info about the driver and its environment.
+func (d *linstor) Info() Info {
+	return Info{
+		Name:                         "linstor",
+		Version:                      linstorVersion,
+		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMBlockFilesystemSize,
+		OptimizedImages:              false,
+		PreservesInodes:              false,
+		Remote:                       d.isRemote(),
+		VolumeTypes:                  []VolumeType{VolumeTypeCustom, VolumeTypeVM, VolumeTypeContainer, VolumeTypeImage},
+		BlockBacking:                 true,
+		RunningCopyFreeze:            true,
+		DirectIO:                     true,
+		IOUring:                      true,
+		MountedRoot:                  false,
+	}
+}
+
+// FillConfig populates the storage pool's configuration file with the default values.
+func (d *linstor) FillConfig() error {
+	if d.config["linstor.resource_group.name"] == "" {
+		d.config["linstor.resource_group.name"] = linstorDefaultResourceGroupName
+	}
+
+	if d.config["linstor.resource_group.place_count"] == "" {
+		d.config["linstor.resource_group.place_count"] = linstorDefaultResourceGroupPlaceCount
+	}
+
+	return nil
+}
+
+// Create is called during pool creation and is effectively using an empty driver struct.
+// WARNING: The Create() function cannot rely on any of the struct attributes being set.
+func (d *linstor) Create() error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	err := d.FillConfig()
+	if err != nil {
+		return err
+	}
+
+	// Since the controller isn't a cluster member specific key the general validation
+	// rules allow an empty string in order to create the pending storage pools.
+	if d.config["linstor.controller_connection"] == "" {
+		return fmt.Errorf("The linstor.controller_connection cannot be empty")
+	}
+
+	client := d.client()
+
+	// Check that the controller is reachable.
+	_, err = client.getControllerVersion()
+	if err != nil {
+		return err
+	}
+
+	// Use an existing resource group or create a new one.
+	_, err = client.getResourceGroup(d.config["linstor.resource_group.name"])
+	if err == nil {
+		d.config["volatile.pool.pristine"] = "false"
+	} else if api.StatusErrorCheck(err, http.StatusNotFound) {
+		err = client.createResourceGroup(d.config["linstor.resource_group.name"], d.config["linstor.resource_group.place_count"], d.config["linstor.resource_group.storage_pool"])
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = client.deleteResourceGroup(d.config["linstor.resource_group.name"]) })
+
+		d.config["volatile.pool.pristine"] = "true"
+	} else {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// Delete removes the storage pool from the storage device.
+func (d *linstor) Delete(op *operations.Operation) error {
+	// Only remove the resource group if it was created by LXD.
+	if shared.IsTrue(d.config["volatile.pool.pristine"]) {
+		err := d.client().deleteResourceGroup(d.config["linstor.resource_group.name"])
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+	}
+
+	// If the user completely destroyed it, call it done.
+	if !shared.PathExists(GetPoolMountPath(d.name)) {
+		return nil
+	}
+
+	// On delete, wipe everything in the directory.
+	return wipeDirectory(GetPoolMountPath(d.name))
+}
+
+// Validate checks that all provided keys are supported and that no conflicting or missing configuration is present.
+func (d *linstor) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=storage-linstor; group=pool-conf; key=linstor.controller_connection)
+		// For example, `http://linstor-controller:3370`.
+		// ---
+		//  type: string
+		//  shortdesc: Address of the LINSTOR controller
+		"linstor.controller_connection": validate.Optional(validate.IsRequestURL),
+		// lxdmeta:generate(entities=storage-linstor; group=pool-conf; key=linstor.resource_group.name)
+		// The resource group is created if it doesn't exist.
+		// ---
+		//  type: string
+		//  defaultdesc: `lxd`
+		//  shortdesc: Name of the LINSTOR resource group used for the volumes
+		"linstor.resource_group.name": validate.IsAny,
+		// lxdmeta:generate(entities=storage-linstor; group=pool-conf; key=linstor.resource_group.place_count)
+		// Only used when LXD creates the resource group.
+		// ---
+		//  type: integer
+		//  defaultdesc: `2`
+		//  shortdesc: Number of replicas of each volume
+		"linstor.resource_group.place_count": validate.Optional(validate.IsInRange(1, 16)),
+		// lxdmeta:generate(entities=storage-linstor; group=pool-conf; key=linstor.resource_group.storage_pool)
+		// Only used when LXD creates the resource group.
+		// ---
+		//  type: string
+		//  shortdesc: Name of the LINSTOR storage pool in which to place the replicas
+		"linstor.resource_group.storage_pool": validate.IsAny,
+		// lxdmeta:generate(entities=storage-linstor; group=pool-conf; key=linstor.diskless)
+		// When enabled, a volume can be used on a cluster member that doesn't hold a replica of it by attaching it as a diskless DRBD resource.
+		// When disabled, instances can only be started on the cluster members holding a replica of their volumes.
+		// ---
+		//  type: bool
+		//  defaultdesc: `true`
+		//  shortdesc: Whether to attach volumes without a local replica over the network
+		"linstor.diskless": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=storage-linstor; group=pool-conf; key=volatile.pool.pristine)
+		//
+		// ---
+		//  type: string
+		//  defaultdesc: `true`
+		//  shortdesc: Whether the resource group was created by LXD
+		"volatile.pool.pristine": validate.IsAny,
+	}
+
+	return d.validatePool(config, rules, d.commonVolumeRules())
+}
+
+// Update applies any driver changes required from a configuration change.
+func (d *linstor) Update(changedConfig map[string]string) error {
+	for _, key := range []string{"linstor.resource_group.name", "linstor.resource_group.place_count", "linstor.resource_group.storage_pool"} {
+		_, changed := changedConfig[key]
+		if changed {
+			return fmt.Errorf("%s cannot be changed", key)
+		}
+	}
+
+	return nil
+}
+
+// Mount mounts the storage pool.
+func (d *linstor) Mount() (bool, error) {
+	// Nothing to do here.
+	return true, nil
+}
+
+// Unmount unmounts the storage pool.
+func (d *linstor) Unmount() (bool, error) {
+	// Nothing to do here.
+	return true, nil
+}
+
+// GetResources returns the pool resource usage information.
+func (d *linstor) GetResources() (*api.ResourcesStoragePool, error) {
+	resourceGroup, err := d.client().getResourceGroup(d.config["linstor.resource_group.name"])
+	if err != nil {
+		return nil, err
+	}
+
+	storagePools, err := d.client().getStoragePools(resourceGroup.SelectFilter.StoragePool)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalKiB uint64
+	var freeKiB uint64
+	for _, storagePool := range storagePools {
+		totalKiB += storagePool.TotalCapacity
+		freeKiB += storagePool.FreeCapacity
+	}
+
+	// Each volume is replicated to multiple storage pools.
+	placeCount := uint64(resourceGroup.SelectFilter.PlaceCount)
+	if placeCount < 1 {
+		placeCount = 1
+	}
+
+	res := &api.ResourcesStoragePool{}
+	res.Space.Total = totalKiB * 1024 / placeCount
+	res.Space.Used = (totalKiB - freeKiB) * 1024 / placeCount
+
+	return res, nil
+}
+
+// MigrationTypes returns the type of transfer methods to be used when doing migrations between pools in preference order.
+func (d *linstor) MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool) []migration.Type {
+	var rsyncFeatures []string
+
+	// Do not pass compression argument to rsync if the associated
+	// config key, that is rsync.compression, is set to false.
+	if shared.IsFalse(d.Config()["rsync.compression"]) {
+		rsyncFeatures = []string{"xattrs", "delete", "bidirectional"}
+	} else {
+		rsyncFeatures = []string{"xattrs", "delete", "compress", "bidirectional"}
+	}
+
+	if IsContentBlock(contentType) {
+		return []migration.Type{
+			{
+				FSType:   migration.MigrationFSType_BLOCK_AND_RSYNC,
+				Features: rsyncFeatures,
+			},
+		}
+	}
+
+	return []migration.Type{
+		{
+			FSType:   migration.MigrationFSType_RSYNC,
+			Features: rsyncFeatures,
+		},
+	}
+}
@@ -0,0 +1,755 @@
+package drivers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// linstorBlockVolSuffix suffix used for block content type volumes.
+const linstorBlockVolSuffix = "-b"
+
+// linstorISOVolSuffix suffix used for iso content type volumes.
+const linstorISOVolSuffix = "-i"
+
+// linstorSnapshotVolPrefix prefix used for the resources restored from snapshots in order to access them.
+const linstorSnapshotVolPrefix = "s"
+
+// linstorDisklessFlag is set on resources which don't hold a replica of the data.
+const linstorDisklessFlag = "DISKLESS"
+
+// linstorTieBreakerFlag is set on diskless resources created by LINSTOR to maintain quorum.
+const linstorTieBreakerFlag = "TIE_BREAKER"
+
+// linstorVolTypePrefixes maps volume type to storage volume name prefix.
+var linstorVolTypePrefixes = map[VolumeType]string{
+	VolumeTypeContainer: "c",
+	VolumeTypeVM:        "v",
+	VolumeTypeImage:     "i",
+	VolumeTypeCustom:    "u",
+}
+
+// linstorError contains the messages returned by the LINSTOR API in case of error.
+type linstorError []struct {
+	RetCode int64  `json:"ret_code"`
+	Message string `json:"message"`
+	Cause   string `json:"cause"`
+}
+
+// Error returns all the messages of the LINSTOR API error.
+func (l linstorError) Error() string {
+	var errorStrings []string
+	for _, rc := range l {
+		if rc.Cause != "" {
+			errorStrings = append(errorStrings, fmt.Sprintf("%s (%s)", rc.Message, rc.Cause))
+		} else {
+			errorStrings = append(errorStrings, rc.Message)
+		}
+	}
+
+	return strings.Join(errorStrings, ", ")
+}
+
+// linstorResourceGroup represents a resource group in LINSTOR.
+type linstorResourceGroup struct {
+	Name         string `json:"name"`
+	SelectFilter struct {
+		PlaceCount  int    `json:"place_count"`
+		StoragePool string `json:"storage_pool"`
+	} `json:"select_filter"`
+}
+
+// linstorResource represents a resource (a replica or a diskless attachment of a volume) on a LINSTOR node.
+type linstorResource struct {
+	Name     string   `json:"name"`
+	NodeName string   `json:"node_name"`
+	Flags    []string `json:"flags"`
+	Volumes  []struct {
+		VolumeNumber int    `json:"volume_number"`
+		DevicePath   string `json:"device_path"`
+	} `json:"volumes"`
+}
+
+// linstorStoragePool represents a storage pool on a LINSTOR node.
+type linstorStoragePool struct {
+	StoragePoolName string `json:"storage_pool_name"`
+	NodeName        string `json:"node_name"`
+	ProviderKind    string `json:"provider_kind"`
+	FreeCapacity    uint64 `json:"free_capacity"`
+	TotalCapacity   uint64 `json:"total_capacity"`
+}
+
+// linstorSnapshot represents a snapshot of a resource definition in LINSTOR.
+type linstorSnapshot struct {
+	Name string `json:"name"`
+}
+
+// linstorClient holds the LINSTOR HTTP client.
+type linstorClient struct {
+	driver *linstor
+}
+
+// newLinstorClient creates a new instance of the HTTP LINSTOR client.
+func newLinstorClient(driver *linstor) *linstorClient {
+	return &linstorClient{
+		driver: driver,
+	}
+}
+
+// request issues a HTTP request against the LINSTOR controller.
+func (l *linstorClient) request(method string, path string, contents any, response any) error {
+	var body io.Reader
+	if contents != nil {
+		buf := &bytes.Buffer{}
+		err := json.NewEncoder(buf).Encode(contents)
+		if err != nil {
+			return fmt.Errorf("Failed to write request body: %w", err)
+		}
+
+		body = buf
+	}
+
+	u := fmt.Sprintf("%s%s", strings.TrimSuffix(l.driver.config["linstor.controller_connection"], "/"), path)
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return fmt.Errorf("Failed to create request: %w", err)
+	}
+
+	req.Header.Add("Accept", "application/json")
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		linstorErr := linstorError{}
+		err = json.NewDecoder(resp.Body).Decode(&linstorErr)
+		if err != nil || len(linstorErr) == 0 {
+			return api.StatusErrorf(resp.StatusCode, "LINSTOR request failed: %s", resp.Status)
+		}
+
+		return api.StatusErrorf(resp.StatusCode, "LINSTOR request failed: %w", linstorErr)
+	}
+
+	if response != nil {
+		err = json.NewDecoder(resp.Body).Decode(response)
+		if err != nil {
+			return fmt.Errorf("Failed to read response body: %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// getControllerVersion returns the version of the LINSTOR controller.
+func (l *linstorClient) getControllerVersion() (string, error) {
+	var actualResponse struct {
+		Version string `json:"version"`
+	}
+
+	err := l.request(http.MethodGet, "/v1/controller/version", nil, &actualResponse)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get LINSTOR controller version: %w", err)
+	}
+
+	return actualResponse.Version, nil
+}
+
+// getResourceGroup returns the resource group behind name.
+func (l *linstorClient) getResourceGroup(name string) (*linstorResourceGroup, error) {
+	var actualResponse linstorResourceGroup
+	err := l.request(http.MethodGet, fmt.Sprintf("/v1/resource-groups/%s", url.PathEscape(name)), nil, &actualResponse)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get resource group %q: %w", name, err)
+	}
+
+	return &actualResponse, nil
+}
+
+// createResourceGroup creates a resource group (along with its volume group) placing its resources on placeCount
+// nodes, optionally in the given storage pool.
+func (l *linstorClient) createResourceGroup(name string, placeCount string, storagePool string) error {
+	selectFilter := map[string]any{}
+
+	if placeCount != "" {
+		var count int
+		_, err := fmt.Sscanf(placeCount, "%d", &count)
+		if err != nil {
+			return fmt.Errorf("Invalid place count %q: %w", placeCount, err)
+		}
+
+		selectFilter["place_count"] = count
+	}
+
+	if storagePool != "" {
+		selectFilter["storage_pool"] = storagePool
+	}
+
+	err := l.request(http.MethodPost, "/v1/resource-groups", map[string]any{"name": name, "select_filter": selectFilter}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to create resource group %q: %w", name, err)
+	}
+
+	err = l.request(http.MethodPost, fmt.Sprintf("/v1/resource-groups/%s/volume-groups", url.PathEscape(name)), map[string]any{}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to create volume group of resource group %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// deleteResourceGroup deletes the resource group behind name.
+func (l *linstorClient) deleteResourceGroup(name string) error {
+	err := l.request(http.MethodDelete, fmt.Sprintf("/v1/resource-groups/%s", url.PathEscape(name)), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to delete resource group %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// getStoragePools returns the storage pools with the given name on all the nodes.
+// If the name is empty, all the storage pools holding data are returned.
+func (l *linstorClient) getStoragePools(name string) ([]linstorStoragePool, error) {
+	path := "/v1/view/storage-pools"
+	if name != "" {
+		path = fmt.Sprintf("%s?storage_pools=%s", path, url.QueryEscape(name))
+	}
+
+	var actualResponse []linstorStoragePool
+	err := l.request(http.MethodGet, path, nil, &actualResponse)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get storage pools: %w", err)
+	}
+
+	storagePools := make([]linstorStoragePool, 0, len(actualResponse))
+	for _, storagePool := range actualResponse {
+		if storagePool.ProviderKind == linstorDisklessFlag {
+			continue
+		}
+
+		storagePools = append(storagePools, storagePool)
+	}
+
+	return storagePools, nil
+}
+
+// spawnResourceDefinition creates a resource definition with a single volume of the given size from the resource
+// group and places its resources on the nodes according to the resource group.
+func (l *linstorClient) spawnResourceDefinition(resourceGroup string, name string, sizeKiB int64) error {
+	err := l.request(http.MethodPost, fmt.Sprintf("/v1/resource-groups/%s/spawn", url.PathEscape(resourceGroup)), map[string]any{
+		"resource_definition_name": name,
+		"volume_sizes":             []int64{sizeKiB},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to create resource definition %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// createResourceDefinition creates an empty resource definition in the resource group.
+func (l *linstorClient) createResourceDefinition(resourceGroup string, name string) error {
+	err := l.request(http.MethodPost, "/v1/resource-definitions", map[string]any{
+		"resource_definition": map[string]any{
+			"name":                name,
+			"resource_group_name": resourceGroup,
+		},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to create resource definition %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// resourceDefinitionExists checks whether the resource definition behind name exists.
+func (l *linstorClient) resourceDefinitionExists(name string) (bool, error) {
+	err := l.request(http.MethodGet, fmt.Sprintf("/v1/resource-definitions/%s", url.PathEscape(name)), nil, nil)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("Failed to get resource definition %q: %w", name, err)
+	}
+
+	return true, nil
+}
+
+// deleteResourceDefinition deletes the resource definition behind name along with all its resources.
+func (l *linstorClient) deleteResourceDefinition(name string) error {
+	err := l.request(http.MethodDelete, fmt.Sprintf("/v1/resource-definitions/%s", url.PathEscape(name)), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to delete resource definition %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// setVolumeSize sets the size of the volume of the resource definition.
+func (l *linstorClient) setVolumeSize(name string, sizeKiB int64) error {
+	err := l.request(http.MethodPut, fmt.Sprintf("/v1/resource-definitions/%s/volume-definitions/0", url.PathEscape(name)), map[string]any{"size_kib": sizeKiB}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to resize volume of resource definition %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// cloneResourceDefinition clones the resource definition behind name into a new one and waits for the clone to
+// complete.
+func (l *linstorClient) cloneResourceDefinition(ctx context.Context, name string, cloneName string) error {
+	err := l.request(http.MethodPost, fmt.Sprintf("/v1/resource-definitions/%s/clone", url.PathEscape(name)), map[string]any{"name": cloneName}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to clone resource definition %q: %w", name, err)
+	}
+
+	for {
+		var actualResponse struct {
+			Status string `json:"status"`
+		}
+
+		err = l.request(http.MethodGet, fmt.Sprintf("/v1/resource-definitions/%s/clone/%s", url.PathEscape(name), url.PathEscape(cloneName)), nil, &actualResponse)
+		if err != nil {
+			return fmt.Errorf("Failed to get clone status of resource definition %q: %w", cloneName, err)
+		}
+
+		switch actualResponse.Status {
+		case "COMPLETE":
+			return nil
+		case "FAILED":
+			return fmt.Errorf("Failed to clone resource definition %q", name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// getResources returns the resources of the resource definition behind name.
+func (l *linstorClient) getResources(name string) ([]linstorResource, error) {
+	var actualResponse []linstorResource
+	err := l.request(http.MethodGet, fmt.Sprintf("/v1/resource-definitions/%s/resources", url.PathEscape(name)), nil, &actualResponse)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get resources of resource definition %q: %w", name, err)
+	}
+
+	return actualResponse, nil
+}
+
+// makeResourceAvailable ensures that the resource definition behind name has a resource on the given node.
+// If the node doesn't hold a replica, a diskless resource is created.
+func (l *linstorClient) makeResourceAvailable(name string, nodeName string) error {
+	err := l.request(http.MethodPost, fmt.Sprintf("/v1/resource-definitions/%s/resources/%s/make-available", url.PathEscape(name), url.PathEscape(nodeName)), map[string]any{"diskful": false}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to make resource %q available on node %q: %w", name, nodeName, err)
+	}
+
+	return nil
+}
+
+// deleteResource deletes the resource of the resource definition behind name from the given node.
+func (l *linstorClient) deleteResource(name string, nodeName string) error {
+	err := l.request(http.MethodDelete, fmt.Sprintf("/v1/resource-definitions/%s/resources/%s", url.PathEscape(name), url.PathEscape(nodeName)), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to delete resource %q from node %q: %w", name, nodeName, err)
+	}
+
+	return nil
+}
+
+// getSnapshots returns the snapshots of the resource definition behind name.
+func (l *linstorClient) getSnapshots(name string) ([]linstorSnapshot, error) {
+	var actualResponse []linstorSnapshot
+	err := l.request(http.MethodGet, fmt.Sprintf("/v1/resource-definitions/%s/snapshots", url.PathEscape(name)), nil, &actualResponse)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get snapshots of resource definition %q: %w", name, err)
+	}
+
+	return actualResponse, nil
+}
+
+// getSnapshotResourceName returns the name of the resource definition holding the snapshot behind snapshotName.
+func (l *linstorClient) getSnapshotResourceName(snapshotName string) (string, error) {
+	var actualResponse []struct {
+		Name         string `json:"name"`
+		ResourceName string `json:"resource_name"`
+	}
+
+	err := l.request(http.MethodGet, fmt.Sprintf("/v1/view/snapshots?snapshots=%s", url.QueryEscape(snapshotName)), nil, &actualResponse)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get snapshot %q: %w", snapshotName, err)
+	}
+
+	for _, snapshot := range actualResponse {
+		if snapshot.Name == snapshotName {
+			return snapshot.ResourceName, nil
+		}
+	}
+
+	return "", api.StatusErrorf(http.StatusNotFound, "Snapshot %q not found", snapshotName)
+}
+
+// createSnapshot creates a snapshot of the resource definition behind name.
+func (l *linstorClient) createSnapshot(name string, snapshotName string) error {
+	err := l.request(http.MethodPost, fmt.Sprintf("/v1/resource-definitions/%s/snapshots", url.PathEscape(name)), map[string]any{"name": snapshotName}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to create snapshot %q of resource definition %q: %w", snapshotName, name, err)
+	}
+
+	return nil
+}
+
+// deleteSnapshot deletes the snapshot of the resource definition behind name.
+func (l *linstorClient) deleteSnapshot(name string, snapshotName string) error {
+	err := l.request(http.MethodDelete, fmt.Sprintf("/v1/resource-definitions/%s/snapshots/%s", url.PathEscape(name), url.PathEscape(snapshotName)), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to delete snapshot %q of resource definition %q: %w", snapshotName, name, err)
+	}
+
+	return nil
+}
+
+// rollbackSnapshot rolls back the resource definition behind name to the given snapshot.
+func (l *linstorClient) rollbackSnapshot(name string, snapshotName string) error {
+	err := l.request(http.MethodPost, fmt.Sprintf("/v1/resource-definitions/%s/snapshot-rollback/%s", url.PathEscape(name), url.PathEscape(snapshotName)), nil, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to roll back resource definition %q to snapshot %q: %w", name, snapshotName, err)
+	}
+
+	return nil
+}
+
+// restoreSnapshot restores the snapshot of the resource definition behind name into the existing (empty) resource
+// definition behind targetName.
+func (l *linstorClient) restoreSnapshot(name string, snapshotName string, targetName string) error {
+	err := l.request(http.MethodPost, fmt.Sprintf("/v1/resource-definitions/%s/snapshot-restore-volume-definition/%s", url.PathEscape(name), url.PathEscape(snapshotName)), map[string]any{"to_resource": targetName}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to restore volume definition of snapshot %q of resource definition %q: %w", snapshotName, name, err)
+	}
+
+	err = l.request(http.MethodPost, fmt.Sprintf("/v1/resource-definitions/%s/snapshot-restore-resource/%s", url.PathEscape(name), url.PathEscape(snapshotName)), map[string]any{"to_resource": targetName}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to restore resources of snapshot %q of resource definition %q: %w", snapshotName, name, err)
+	}
+
+	return nil
+}
+
+// client returns the drivers LINSTOR client.
+// A new client gets created if it not yet exists.
+func (d *linstor) client() *linstorClient {
+	if d.httpClient == nil {
+		d.httpClient = newLinstorClient(d)
+	}
+
+	return d.httpClient
+}
+
+// getNodeName returns the name of the LINSTOR satellite node running on this host.
+// The satellite is expected to use the name of the cluster member (or the hostname if not clustered).
+func (d *linstor) getNodeName() (string, error) {
+	if d.state.ServerName != "none" {
+		return d.state.ServerName, nil
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("Failed to get hostname: %w", err)
+	}
+
+	return hostname, nil
+}
+
+// getResourceName returns the name of the LINSTOR resource definition derived from the volume.
+// Snapshots are accessed through a resource definition restored from the LINSTOR snapshot.
+func (d *linstor) getResourceName(vol Volume) (string, error) {
+	volUUID, err := uuid.Parse(vol.config["volatile.uuid"])
+	if err != nil {
+		return "", fmt.Errorf(`Failed parsing "volatile.uuid" from volume %q: %w`, vol.name, err)
+	}
+
+	var suffix string
+	if vol.contentType == ContentTypeBlock {
+		suffix = linstorBlockVolSuffix
+	} else if vol.contentType == ContentTypeISO {
+		suffix = linstorISOVolSuffix
+	}
+
+	volumeTypePrefix := linstorVolTypePrefixes[vol.volType]
+	if vol.IsSnapshot() {
+		volumeTypePrefix = linstorSnapshotVolPrefix
+	}
+
+	return fmt.Sprintf("lxd-%s-%s%s", volumeTypePrefix, volUUID.String(), suffix), nil
+}
+
+// getSnapshotName returns the name of the LINSTOR snapshot derived from the snapshot volume.
+func (d *linstor) getSnapshotName(snapVol Volume) (string, error) {
+	snapUUID, err := uuid.Parse(snapVol.config["volatile.uuid"])
+	if err != nil {
+		return "", fmt.Errorf(`Failed parsing "volatile.uuid" from volume %q: %w`, snapVol.name, err)
+	}
+
+	var suffix string
+	if snapVol.contentType == ContentTypeBlock {
+		suffix = linstorBlockVolSuffix
+	}
+
+	return fmt.Sprintf("lxd-%s%s", snapUUID.String(), suffix), nil
+}
+
+// getSnapshotResourceName returns the name of the resource definition holding the snapshot along with the name
+// of the LINSTOR snapshot.
+// If the parent volume's UUID isn't set on the snapshot volume, the resource definition is looked up in LINSTOR.
+func (d *linstor) getSnapshotResourceName(snapVol Volume) (string, string, error) {
+	snapshotName, err := d.getSnapshotName(snapVol)
+	if err != nil {
+		return "", "", err
+	}
+
+	if snapVol.parentUUID == "" {
+		resourceName, err := d.client().getSnapshotResourceName(snapshotName)
+		if err != nil {
+			return "", "", err
+		}
+
+		return resourceName, snapshotName, nil
+	}
+
+	parentName, _, _ := api.GetParentAndSnapshotName(snapVol.name)
+	parentVol := NewVolume(d, d.name, snapVol.volType, snapVol.contentType, parentName, map[string]string{"volatile.uuid": snapVol.parentUUID}, nil)
+	resourceName, err := d.getResourceName(parentVol)
+	if err != nil {
+		return "", "", err
+	}
+
+	return resourceName, snapshotName, nil
+}
+
+// restoreSnapshotResource restores the snapshot into its own resource definition (if not already done) so that it
+// can be accessed. The returned hook removes the resource definition again.
+func (d *linstor) restoreSnapshotResource(snapVol Volume) (revert.Hook, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	resourceName, err := d.getResourceName(snapVol)
+	if err != nil {
+		return nil, err
+	}
+
+	client := d.client()
+	exists, err := client.resourceDefinitionExists(resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		parentResourceName, snapshotName, err := d.getSnapshotResourceName(snapVol)
+		if err != nil {
+			return nil, err
+		}
+
+		err = client.createResourceDefinition(d.config["linstor.resource_group.name"], resourceName)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { _ = client.deleteResourceDefinition(resourceName) })
+
+		err = client.restoreSnapshot(parentResourceName, snapshotName, resourceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cleanup := revert.Clone().Fail
+	revert.Success()
+	return cleanup, nil
+}
+
+// getLocalResource returns the resource of the resource definition on this host or nil if there is none.
+func (d *linstor) getLocalResource(resourceName string) (*linstorResource, error) {
+	nodeName, err := d.getNodeName()
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := d.client().getResources(resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range resources {
+		if resource.NodeName == nodeName {
+			return &resource, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// mapVolume ensures the volume is accessible on this host.
+// If this host doesn't hold a replica of the volume, the volume is attached as a diskless resource if allowed by
+// the pool configuration.
+func (d *linstor) mapVolume(vol Volume) (revert.Hook, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if vol.IsSnapshot() {
+		cleanup, err := d.restoreSnapshotResource(vol)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(cleanup)
+	}
+
+	resourceName, err := d.getResourceName(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := d.getLocalResource(resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if resource == nil {
+		nodeName, err := d.getNodeName()
+		if err != nil {
+			return nil, err
+		}
+
+		if shared.IsFalse(d.config["linstor.diskless"]) {
+			return nil, fmt.Errorf("Volume %q has no replica on node %q and diskless access is disabled", vol.name, nodeName)
+		}
+
+		client := d.client()
+		err = client.makeResourceAvailable(resourceName, nodeName)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { _ = client.deleteResource(resourceName, nodeName) })
+	}
+
+	cleanup := revert.Clone().Fail
+	revert.Success()
+	return cleanup, nil
+}
+
+// getMappedDevPath returns the local device path for the given volume.
+// Indicate with mapVolume if the volume should get mapped to the system if it isn't present.
+func (d *linstor) getMappedDevPath(vol Volume, mapVolume bool) (string, revert.Hook, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if mapVolume {
+		cleanup, err := d.mapVolume(vol)
+		if err != nil {
+			return "", nil, err
+		}
+
+		revert.Add(cleanup)
+	}
+
+	resourceName, err := d.getResourceName(vol)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resource, err := d.getLocalResource(resourceName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if resource == nil || len(resource.Volumes) == 0 || resource.Volumes[0].DevicePath == "" {
+		return "", nil, fmt.Errorf("LINSTOR volume not found on this host: %q", vol.name)
+	}
+
+	devPath := resource.Volumes[0].DevicePath
+
+	// It might take a while for the DRBD device to appear.
+	ctx, cancel := context.WithTimeout(d.state.ShutdownCtx, 10*time.Second)
+	defer cancel()
+
+	for !shared.PathExists(devPath) {
+		if !mapVolume {
+			return "", nil, fmt.Errorf("LINSTOR volume not mapped: %q", vol.name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", nil, fmt.Errorf("Timeout exceeded for LINSTOR volume discovery: %q", vol.name)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	cleanup := revert.Clone().Fail
+	revert.Success()
+	return devPath, cleanup, nil
+}
+
+// unmapVolume removes the diskless resource of the volume from this host (if any).
+// Replicas are kept as they hold the data of the volume.
+func (d *linstor) unmapVolume(vol Volume) error {
+	resourceName, err := d.getResourceName(vol)
+	if err != nil {
+		return err
+	}
+
+	resource, err := d.getLocalResource(resourceName)
+	if err != nil {
+		return err
+	}
+
+	if resource == nil || !shared.ValueInSlice(linstorDisklessFlag, resource.Flags) || shared.ValueInSlice(linstorTieBreakerFlag, resource.Flags) {
+		return nil
+	}
+
+	err = d.client().deleteResource(resourceName, resource.NodeName)
+	if err != nil {
+		return err
+	}
+
+	// Wait until the device has disappeared.
+	if len(resource.Volumes) > 0 && resource.Volumes[0].DevicePath != "" {
+		ctx, cancel := context.WithTimeout(d.state.ShutdownCtx, 10*time.Second)
+		defer cancel()
+
+		if !waitGone(ctx, resource.Volumes[0].DevicePath) {
+			return fmt.Errorf("Timeout whilst waiting for LINSTOR volume to disappear: %q", vol.name)
+		}
+	}
+
+	return nil
+}
+
+// linstorSizeKiB converts the size in bytes into KiB, rounding up.
+func linstorSizeKiB(sizeBytes int64) int64 {
+	return (sizeBytes + 1023) / 1024
+}
@@ -131,6 +131,28 @@ func (d *lvm) Create() error {
 
 	var usingLoopFile bool
 
+	// Connect the LUN backing the pool and use it as the physical device.
+	if d.usesSharedLUN() {
+		if d.usesISCSI() && d.config["source"] != "" {
+			return fmt.Errorf("Cannot specify source when using an iSCSI target")
+		}
+
+		if !d.usesISCSI() && !filepath.IsAbs(d.config["source"]) {
+			return fmt.Errorf("The source must be the path of a block device when using multipath")
+		}
+
+		if d.usesISCSI() {
+			revert.Add(func() { _ = d.iscsiLogout() })
+		}
+
+		devPath, err := d.connectLUN(d.config["source"])
+		if err != nil {
+			return err
+		}
+
+		d.config["source"] = devPath
+	}
+
 	if d.config["source"] == "" || d.config["source"] == defaultSource {
 		usingLoopFile = true
 
@@ -380,6 +402,14 @@ func (d *lvm) Create() error {
 
 	d.logger.Debug("LXD marker tag added to volume group", logger.Ctx{"vg_name": d.config["lvm.vg_name"]})
 
+	// Mark the volume group as owned by this server so that other servers seeing the same LUN don't use it.
+	if d.usesSharedLUN() {
+		err = d.claimVolumeGroup(vgTags)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert.Success()
 	return nil
 }
@@ -459,6 +489,14 @@ func (d *lvm) Delete(op *operations.Operation) error {
 
 			d.logger.Debug("Volume group removed", logger.Ctx{"vg_name": d.config["lvm.vg_name"]})
 		} else {
+			// Release the volume group so that other servers sharing the LUN can use it.
+			if d.usesSharedLUN() {
+				err = d.releaseVolumeGroup(vgTags)
+				if err != nil {
+					return fmt.Errorf("Failed to remove owner tag on volume group for the lvm storage pool: %w", err)
+				}
+			}
+
 			// Otherwise just remove the lvmVgPoolMarker tag to indicate LXD no longer uses this VG.
 			if shared.ValueInSlice(lvmVgPoolMarker, vgTags) {
 				_, err = shared.TryRunCommand("vgchange", "--deltag", lvmVgPoolMarker, d.config["lvm.vg_name"])
@@ -494,6 +532,14 @@ func (d *lvm) Delete(op *operations.Operation) error {
 		d.logger.Debug("Physical loop file removed", logger.Ctx{"file_name": d.config["source"]})
 	}
 
+	// Disconnect from the iSCSI target now that the volume group isn't used anymore.
+	if d.usesISCSI() {
+		err = d.iscsiLogout()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Wipe everything in the storage pool directory.
 	err = wipeDirectory(GetPoolMountPath(d.name))
 	if err != nil {
@@ -543,6 +589,44 @@ func (d *lvm) Validate(config map[string]string) error {
 		//  defaultdesc: `false`
 		//  shortdesc: Force using an existing non-empty volume group
 		"lvm.vg.force_reuse": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=storage-lvm; group=pool-conf; key=lvm.iscsi.portal)
+		// The port defaults to `3260` if not specified.
+		// ---
+		//  type: string
+		//  shortdesc: Address of the iSCSI portal to discover the target on
+		"lvm.iscsi.portal": validate.Optional(validate.IsListenAddress(true, false, false)),
+		// lxdmeta:generate(entities=storage-lvm; group=pool-conf; key=lvm.iscsi.target)
+		// When set, LXD logs into the target when the storage pool is created or mounted and uses the LUN as the physical device for the volume group.
+		// ---
+		//  type: string
+		//  shortdesc: IQN of the iSCSI target providing the LUN
+		"lvm.iscsi.target": validate.IsAny,
+		// lxdmeta:generate(entities=storage-lvm; group=pool-conf; key=lvm.iscsi.lun)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `0`
+		//  shortdesc: Number of the iSCSI LUN to use
+		"lvm.iscsi.lun": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=storage-lvm; group=pool-conf; key=lvm.iscsi.chap.username)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: User name for CHAP authentication with the iSCSI target
+		"lvm.iscsi.chap.username": validate.IsAny,
+		// lxdmeta:generate(entities=storage-lvm; group=pool-conf; key=lvm.iscsi.chap.password)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Password for CHAP authentication with the iSCSI target
+		"lvm.iscsi.chap.password": validate.IsAny,
+		// lxdmeta:generate(entities=storage-lvm; group=pool-conf; key=lvm.multipath)
+		// When enabled, LXD uses the multipath device that holds the iSCSI LUN or the Fibre Channel LUN set in `source`.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to access the LUN through dm-multipath
+		"lvm.multipath": validate.Optional(validate.IsBool),
 	}
 
 	err := d.validatePool(config, rules, d.commonVolumeRules())
@@ -550,6 +634,14 @@ func (d *lvm) Validate(config map[string]string) error {
 		return err
 	}
 
+	if config["lvm.iscsi.target"] != "" && config["lvm.iscsi.portal"] == "" {
+		return fmt.Errorf("The key lvm.iscsi.portal is required when lvm.iscsi.target is set")
+	}
+
+	if config["lvm.iscsi.chap.username"] != "" && config["lvm.iscsi.chap.password"] == "" {
+		return fmt.Errorf("The key lvm.iscsi.chap.password is required when lvm.iscsi.chap.username is set")
+	}
+
 	if shared.IsFalse(config["lvm.use_thinpool"]) {
 		if config["lvm.thinpool_name"] != "" {
 			return fmt.Errorf("The key lvm.use_thinpool cannot be set to false when lvm.thinpool_name is set")
@@ -575,6 +667,13 @@ func (d *lvm) Update(changedConfig map[string]string) error {
 		return fmt.Errorf("lvm.thinpool_metadata_size cannot be changed")
 	}
 
+	for _, key := range []string{"lvm.iscsi.portal", "lvm.iscsi.target", "lvm.iscsi.lun", "lvm.multipath"} {
+		_, changed = changedConfig[key]
+		if changed {
+			return fmt.Errorf("%s cannot be changed", key)
+		}
+	}
+
 	_, changed = changedConfig["volume.lvm.stripes"]
 	if changed && d.usesThinpool() {
 		return fmt.Errorf("volume.lvm.stripes cannot be changed when using thin pool")
@@ -673,6 +772,14 @@ func (d *lvm) Mount() (bool, error) {
 	revert := revert.New()
 	defer revert.Fail()
 
+	// Log into the iSCSI target if needed so that the volume group becomes visible.
+	if d.usesISCSI() {
+		_, err := d.connectLUN("")
+		if err != nil {
+			return false, err
+		}
+	}
+
 	// Open the loop file if the source points to a non-block device file.
 	// This ensures that auto clear isn't enabled on the loop file.
 	if filepath.IsAbs(d.config["source"]) && !shared.IsBlockdevPath(d.config["source"]) {
@@ -699,10 +806,38 @@ func (d *lvm) Mount() (bool, error) {
 				time.Sleep(1 * time.Second)
 			}
 		}
+	} else if d.usesISCSI() && !vgExists {
+		// Wait for the volume group to be detected on the newly connected LUN.
+		waitUntil := time.Now().Add(waitDuration)
+		for {
+			vgExists, _, _ = d.volumeGroupExists(d.config["lvm.vg_name"])
+			if vgExists {
+				break
+			}
+
+			if time.Now().After(waitUntil) {
+				return false, fmt.Errorf("Volume group %q not found", d.config["lvm.vg_name"])
+			}
+
+			time.Sleep(1 * time.Second)
+		}
 	} else if !vgExists {
 		return false, fmt.Errorf("Volume group %s not found", d.config["lvm.vg_name"])
 	}
 
+	// Refuse to use a volume group on a shared LUN that is in use by another server.
+	if d.usesSharedLUN() {
+		_, vgTags, err := d.volumeGroupExists(d.config["lvm.vg_name"])
+		if err != nil {
+			return false, err
+		}
+
+		err = d.claimVolumeGroup(vgTags)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	// Ensure thinpool exists if needed for storage pool.
 	if d.usesThinpool() {
 		waitUntil := time.Now().Add(waitDuration)
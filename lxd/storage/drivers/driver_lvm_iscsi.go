@@ -0,0 +1,286 @@
+package drivers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/locking"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// lvmVgOwnerPrefix is the prefix of the tag used to record which server owns a volume group on a shared LUN.
+const lvmVgOwnerPrefix = "lxd_owner_"
+
+// lvmISCSIDefaultPort is the port used when the iSCSI portal doesn't specify one.
+const lvmISCSIDefaultPort = "3260"
+
+// lvmDeviceTimeout is how long to wait for LUN and multipath devices to appear.
+const lvmDeviceTimeout = 30 * time.Second
+
+// usesISCSI indicates whether the pool is backed by an iSCSI LUN.
+func (d *lvm) usesISCSI() bool {
+	return d.config["lvm.iscsi.target"] != ""
+}
+
+// usesSharedLUN indicates whether the pool is backed by a LUN that may be visible to other servers.
+func (d *lvm) usesSharedLUN() bool {
+	return d.usesISCSI() || shared.IsTrue(d.config["lvm.multipath"])
+}
+
+// iscsiPortal returns the configured iSCSI portal including the port.
+func (d *lvm) iscsiPortal() string {
+	portal := d.config["lvm.iscsi.portal"]
+
+	_, _, err := net.SplitHostPort(portal)
+	if err != nil {
+		return net.JoinHostPort(strings.Trim(portal, "[]"), lvmISCSIDefaultPort)
+	}
+
+	return portal
+}
+
+// iscsiDevPath returns the path of the configured iSCSI LUN.
+func (d *lvm) iscsiDevPath() string {
+	lun := d.config["lvm.iscsi.lun"]
+	if lun == "" {
+		lun = "0"
+	}
+
+	return filepath.Join("/dev/disk/by-path", fmt.Sprintf("ip-%s-iscsi-%s-lun-%s", d.iscsiPortal(), d.config["lvm.iscsi.target"], lun))
+}
+
+// iscsiadm runs iscsiadm against the configured target and portal.
+func (d *lvm) iscsiadm(args ...string) (string, error) {
+	args = append([]string{"-m", "node", "-T", d.config["lvm.iscsi.target"], "-p", d.iscsiPortal()}, args...)
+	return shared.RunCommand("iscsiadm", args...)
+}
+
+// iscsiLogin discovers the configured iSCSI target and logs into it if there is no active session yet.
+// Returns the path of the LUN once it is available.
+func (d *lvm) iscsiLogin() (string, error) {
+	unlock, err := locking.Lock(d.state.ShutdownCtx, "iscsi")
+	if err != nil {
+		return "", err
+	}
+
+	defer unlock()
+
+	devPath := d.iscsiDevPath()
+
+	// Check for an existing session.
+	if !shared.PathExists(devPath) {
+		_, err = shared.RunCommand("iscsiadm", "-m", "discovery", "-t", "sendtargets", "-p", d.iscsiPortal())
+		if err != nil {
+			return "", fmt.Errorf("Failed discovering iSCSI targets on portal %q: %w", d.iscsiPortal(), err)
+		}
+
+		if d.config["lvm.iscsi.chap.username"] != "" {
+			settings := map[string]string{
+				"node.session.auth.authmethod": "CHAP",
+				"node.session.auth.username":   d.config["lvm.iscsi.chap.username"],
+				"node.session.auth.password":   d.config["lvm.iscsi.chap.password"],
+			}
+
+			for name, value := range settings {
+				_, err = d.iscsiadm("-o", "update", "-n", name, "-v", value)
+				if err != nil {
+					return "", fmt.Errorf("Failed configuring iSCSI authentication: %w", err)
+				}
+			}
+		}
+
+		// Let LXD log into the target when the pool is mounted rather than the iSCSI initiator on boot.
+		_, err = d.iscsiadm("-o", "update", "-n", "node.startup", "-v", "manual")
+		if err != nil {
+			return "", fmt.Errorf("Failed configuring iSCSI node: %w", err)
+		}
+
+		_, err = d.iscsiadm("--login")
+		if err != nil && !strings.Contains(err.Error(), "already present") {
+			return "", fmt.Errorf("Failed logging into iSCSI target %q: %w", d.config["lvm.iscsi.target"], err)
+		}
+
+		d.logger.Debug("Logged into iSCSI target", logger.Ctx{"portal": d.iscsiPortal(), "target": d.config["lvm.iscsi.target"]})
+	}
+
+	ctx, cancel := context.WithTimeout(d.state.ShutdownCtx, lvmDeviceTimeout)
+	defer cancel()
+
+	if !tryExists(ctx, devPath) {
+		return "", fmt.Errorf("Timeout waiting for iSCSI LUN %q to appear", devPath)
+	}
+
+	return devPath, nil
+}
+
+// iscsiLogout logs out of the configured iSCSI target and removes the node record.
+func (d *lvm) iscsiLogout() error {
+	unlock, err := locking.Lock(d.state.ShutdownCtx, "iscsi")
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	_, err = d.iscsiadm("--logout")
+	if err != nil && !strings.Contains(err.Error(), "No matching sessions") {
+		return fmt.Errorf("Failed logging out of iSCSI target %q: %w", d.config["lvm.iscsi.target"], err)
+	}
+
+	_, err = d.iscsiadm("-o", "delete")
+	if err != nil {
+		d.logger.Warn("Failed removing iSCSI node record", logger.Ctx{"target": d.config["lvm.iscsi.target"], "err": err})
+	}
+
+	d.logger.Debug("Logged out of iSCSI target", logger.Ctx{"portal": d.iscsiPortal(), "target": d.config["lvm.iscsi.target"]})
+
+	return nil
+}
+
+// multipathDevPath returns the path of the multipath device that holds the given path of the LUN.
+// Waits for multipathd to set up the device if needed.
+func (d *lvm) multipathDevPath(devPath string) (string, error) {
+	realPath, err := filepath.EvalSymlinks(devPath)
+	if err != nil {
+		return "", fmt.Errorf("Failed resolving %q: %w", devPath, err)
+	}
+
+	// The path may already be a multipath device (for example /dev/mapper/mpatha).
+	devName := filepath.Base(realPath)
+	if d.isMultipathDevice(devName) {
+		return realPath, nil
+	}
+
+	// Ask multipathd to pick up the path in case it isn't part of a map yet.
+	_, err = shared.RunCommand("multipath", realPath)
+	if err != nil {
+		d.logger.Debug("Failed adding path to multipath", logger.Ctx{"dev": realPath, "err": err})
+	}
+
+	waitUntil := time.Now().Add(lvmDeviceTimeout)
+	for {
+		holders, _ := os.ReadDir(filepath.Join("/sys/class/block", devName, "holders"))
+		for _, holder := range holders {
+			if !d.isMultipathDevice(holder.Name()) {
+				continue
+			}
+
+			name, err := os.ReadFile(filepath.Join("/sys/class/block", holder.Name(), "dm", "name"))
+			if err != nil {
+				return "", err
+			}
+
+			return filepath.Join("/dev/mapper", strings.TrimSpace(string(name))), nil
+		}
+
+		if time.Now().After(waitUntil) {
+			return "", fmt.Errorf("No multipath device found for %q", devPath)
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+// isMultipathDevice returns true if the block device with the given name is a multipath device.
+func (d *lvm) isMultipathDevice(devName string) bool {
+	uuid, err := os.ReadFile(filepath.Join("/sys/class/block", devName, "dm", "uuid"))
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(string(uuid), "mpath-")
+}
+
+// connectLUN connects the LUN backing the pool and returns the path of the device to use.
+// Returns an empty path if the pool isn't backed by a shared LUN.
+func (d *lvm) connectLUN(devPath string) (string, error) {
+	var err error
+
+	if d.usesISCSI() {
+		devPath, err = d.iscsiLogin()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if shared.IsTrue(d.config["lvm.multipath"]) {
+		devPath, err = d.multipathDevPath(devPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return devPath, nil
+}
+
+// ownerName returns the name used to mark the volume groups owned by this server.
+func (d *lvm) ownerName() (string, error) {
+	if d.state.ServerName != "none" {
+		return d.state.ServerName, nil
+	}
+
+	return os.Hostname()
+}
+
+// volumeGroupOwner returns the name of the server owning a volume group from its tags.
+func (d *lvm) volumeGroupOwner(vgTags []string) string {
+	for _, tag := range vgTags {
+		owner, ok := strings.CutPrefix(tag, lvmVgOwnerPrefix)
+		if ok {
+			return owner
+		}
+	}
+
+	return ""
+}
+
+// claimVolumeGroup marks the volume group as owned by this server.
+// Fails if the volume group is owned by another server to prevent the volume group from being activated by more
+// than one server at a time, which would corrupt its metadata.
+func (d *lvm) claimVolumeGroup(vgTags []string) error {
+	name, err := d.ownerName()
+	if err != nil {
+		return err
+	}
+
+	owner := d.volumeGroupOwner(vgTags)
+	if owner == name {
+		return nil
+	}
+
+	if owner != "" {
+		return fmt.Errorf("Volume group %q is owned by %q", d.config["lvm.vg_name"], owner)
+	}
+
+	_, err = shared.TryRunCommand("vgchange", "--addtag", lvmVgOwnerPrefix+name, d.config["lvm.vg_name"])
+	if err != nil {
+		return err
+	}
+
+	d.logger.Debug("Owner tag added to volume group", logger.Ctx{"vg_name": d.config["lvm.vg_name"], "owner": name})
+
+	return nil
+}
+
+// releaseVolumeGroup removes the owner tag from the volume group.
+func (d *lvm) releaseVolumeGroup(vgTags []string) error {
+	owner := d.volumeGroupOwner(vgTags)
+	if owner == "" {
+		return nil
+	}
+
+	_, err := shared.TryRunCommand("vgchange", "--deltag", lvmVgOwnerPrefix+owner, d.config["lvm.vg_name"])
+	if err != nil {
+		return err
+	}
+
+	d.logger.Debug("Owner tag removed from volume group", logger.Ctx{"vg_name": d.config["lvm.vg_name"], "owner": owner})
+
+	return nil
+}
@@ -149,6 +149,9 @@ func genericVFSRenameVolumeSnapshot(d Driver, snapVol Volume, newSnapshotName st
 // genericVFSMigrateVolume is a generic MigrateVolume implementation for VFS-only drivers.
 func genericVFSMigrateVolume(d Driver, s *state.State, vol VolumeCopy, conn io.ReadWriteCloser, volSrcArgs *migration.VolumeSourceArgs, op *operations.Operation) error {
 	bwlimit := d.Config()["rsync.bwlimit"]
+	if bwlimit == "" && volSrcArgs.BandwidthLimit > 0 {
+		bwlimit = rsync.BwlimitFromBytes(volSrcArgs.BandwidthLimit)
+	}
 	var rsyncArgs []string
 
 	// For VM volumes, exclude the generic root disk image file from being transferred via rsync, as it will
@@ -11,6 +11,7 @@ var drivers = map[string]func() driver{
 	"cephfs":     func() driver { return &cephfs{} },
 	"cephobject": func() driver { return &cephobject{} },
 	"dir":        func() driver { return &dir{} },
+	"linstor":    func() driver { return &linstor{} },
 	"lvm":        func() driver { return &lvm{} },
 	"powerflex":  func() driver { return &powerflex{} },
 	"zfs":        func() driver { return &zfs{} },
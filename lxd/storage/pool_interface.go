@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"io"
 	"net/url"
 	"time"
@@ -51,6 +52,7 @@ type Pool interface {
 
 	GetResources() (*api.ResourcesStoragePool, error)
 	IsUsed() (bool, error)
+	Benchmark(ctx context.Context, size int64, op *operations.Operation) (*api.StoragePoolBenchmark, error)
 	Delete(clientType request.ClientType, op *operations.Operation) error
 	Update(clientType request.ClientType, newDesc string, newConfig map[string]string, op *operations.Operation) error
 
@@ -122,6 +124,8 @@ type Pool interface {
 	GetCustomVolumeUsage(projectName string, volName string) (*VolumeUsage, error)
 	MountCustomVolume(projectName string, volName string, op *operations.Operation) (*MountInfo, error)
 	UnmountCustomVolume(projectName string, volName string, op *operations.Operation) (bool, error)
+	MountCustomVolumeSnapshot(projectName string, volName string, op *operations.Operation) (*MountInfo, error)
+	UnmountCustomVolumeSnapshot(projectName string, volName string, op *operations.Operation) (bool, error)
 	ImportCustomVolume(projectName string, poolVol *backupConfig.Config, op *operations.Operation) (revert.Hook, error)
 	RefreshCustomVolume(projectName string, srcProjectName string, volName, desc string, config map[string]string, srcPoolName, srcVolName string, snapshots bool, op *operations.Operation) error
 	GenerateCustomVolumeBackupConfig(projectName string, volName string, snapshots bool, op *operations.Operation) (*backupConfig.Config, error)
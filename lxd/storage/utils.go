@@ -26,6 +26,7 @@ import (
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/storage/drivers"
 	"github.com/canonical/lxd/lxd/sys"
+	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/ioprogress"
@@ -484,7 +485,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  defaultdesc: same as `volume.size`
 		//  shortdesc: Size/quota of the storage bucket
 		"size": validate.Optional(validate.IsSize),
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=snapshots.expiry)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=snapshots.expiry)
 		// Specify an expression like `1M 2H 3d 4w 5m 6y`.
 		// ---
 		//  type: string
@@ -496,7 +497,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 			_, err := shared.GetExpiry(time.Time{}, value)
 			return err
 		},
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=snapshots.schedule)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=snapshots.schedule)
 		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic snapshots (the default).
 		// ---
 		//  type: string
@@ -504,7 +505,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  defaultdesc: same as `snapshots.schedule`
 		//  shortdesc: Schedule for automatic volume snapshots
 		"snapshots.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=snapshots.pattern)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=snapshots.pattern)
 		// You can specify a naming template that is used for scheduled snapshots and unnamed snapshots.
 		//
 		// {{snapshot_pattern_detail}}
@@ -518,7 +519,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 
 	// security.shifted and security.unmapped are only relevant for custom filesystem volumes.
 	if (vol == nil) || (vol != nil && vol.Type() == drivers.VolumeTypeCustom && vol.ContentType() == drivers.ContentTypeFS) {
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=security.shifted)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=security.shifted)
 		// Enabling this option allows attaching the volume to multiple isolated instances.
 		// ---
 		//  type: bool
@@ -526,7 +527,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  defaultdesc: same as `volume.security.shifted` or `false`
 		//  shortdesc: Enable ID shifting overlay
 		rules["security.shifted"] = validate.Optional(validate.IsBool)
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=security.unmapped)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=security.unmapped)
 		//
 		// ---
 		//  type: bool
@@ -536,9 +537,23 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		rules["security.unmapped"] = validate.Optional(validate.IsBool)
 	}
 
+	// security.shared is only relevant for custom block volumes.
+	if vol != nil && vol.Type() == drivers.VolumeTypeCustom && vol.ContentType() == drivers.ContentTypeBlock {
+		// lxdmeta:generate(entities=storage-btrfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=security.shared)
+		// Enabling this option allows attaching the volume to multiple instances with write access.
+		// Only enable it if the instances coordinate their access to the volume, for example through a cluster file system, as data corruption might occur otherwise.
+		// Volumes that all instances attach read-only can be shared without enabling this option.
+		// ---
+		//  type: bool
+		//  condition: custom block volume
+		//  defaultdesc: `false`
+		//  shortdesc: Allow attaching the volume to multiple instances with write access
+		rules["security.shared"] = validate.Optional(validate.IsBool)
+	}
+
 	// Those keys are only valid for volumes.
 	if vol != nil {
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=volatile.uuid)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=volatile.uuid)
 		//
 		// ---
 		//  type: string
@@ -598,7 +613,7 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  shortdesc: Whether to wipe the block device before creating the pool
 		"source.wipe":             validate.Optional(validate.IsBool),
 		"volatile.initial_source": validate.IsAny,
-		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex; group=pool-conf; key=rsync.bwlimit)
+		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex,storage-linstor; group=pool-conf; key=rsync.bwlimit)
 		// When `rsync` must be used to transfer storage entities, this option specifies the upper limit
 		// to be placed on the socket I/O.
 		// ---
@@ -606,13 +621,21 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  defaultdesc: `0` (no limit)
 		//  shortdesc: Upper limit on the socket I/O for `rsync`
 		"rsync.bwlimit": validate.Optional(validate.IsSize),
-		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex; group=pool-conf; key=rsync.compression)
+		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex,storage-linstor; group=pool-conf; key=rsync.compression)
 		//
 		// ---
 		//  type: bool
 		//  defaultdesc: `true`
 		//  shortdesc: Whether to use compression while migrating storage pools
 		"rsync.compression": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=storage-btrfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=pool-conf; key=storage.trim.schedule)
+		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`) or a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`).
+		// Applies to the running instances using the pool as their root disk, unless {config:option}`instance-miscellaneous:storage.trim.schedule` is set on the instance.
+		// ---
+		//  type: string
+		//  defaultdesc: empty
+		//  shortdesc: Schedule for discarding unused blocks of the instances' file systems
+		"storage.trim.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
 	}
 
 	// Add to pool config rules (prefixed with volume.*) which are common for pool and volume.
@@ -649,6 +672,45 @@ func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error
 		rules["volatile.rootfs.size"] = validate.Optional(validate.IsInt64)
 	}
 
+	// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=snapshots.schedules.<name>.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`) or a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`).
+	// The snapshots taken by the schedule are named `<name>-%d`.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  shortdesc: Schedule of a named volume snapshot schedule
+
+	// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=snapshots.schedules.<name>.keep)
+	// When a snapshot is taken by the schedule, the oldest snapshots of the schedule in excess of this number are deleted.
+	// ---
+	//  type: integer
+	//  condition: custom volume
+	//  defaultdesc: `0` (keep all)
+	//  shortdesc: Number of snapshots of a named volume snapshot schedule to keep
+
+	// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-linstor; group=volume-conf; key=snapshots.schedules.<name>.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  defaultdesc: same as `snapshots.expiry`
+	//  shortdesc: When the snapshots of a named volume snapshot schedule are to be deleted
+	if vol.Type() == drivers.VolumeTypeCustom {
+		for key := range vol.Config() {
+			if !strings.HasPrefix(key, util.SnapshotSchedulesPrefix) {
+				continue
+			}
+
+			validator, err := util.SnapshotScheduleKeyChecker(key, []string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})
+			if err != nil {
+				rules[key] = func(string) error { return err }
+				continue
+			}
+
+			rules[key] = validator
+		}
+	}
+
 	return rules
 }
 
@@ -1080,6 +1142,45 @@ func VolumeUsedByExclusiveRemoteInstancesWithProfiles(s *state.State, poolName s
 	return remoteInstance, nil
 }
 
+// VolumeBlockSharingConflict checks if a custom block volume can be attached to the instance instName in project
+// instProjectName. A block volume can only be attached to multiple instances if all of them attach it read-only,
+// unless "security.shared" is enabled on the volume. Returns the instance that prevents the volume from being
+// attached, or nil if the volume can be attached.
+func VolumeBlockSharingConflict(s *state.State, poolName string, projectName string, vol *api.StorageVolume, instProjectName string, instName string, readOnly bool) (*db.InstanceArgs, error) {
+	if shared.IsTrue(vol.Config["security.shared"]) {
+		return nil, nil
+	}
+
+	var conflictInstance *db.InstanceArgs
+	err := VolumeUsedByInstanceDevices(s, poolName, projectName, vol, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+		if dbInst.Project == instProjectName && dbInst.Name == instName {
+			return nil
+		}
+
+		if readOnly {
+			devices := instancetype.ExpandInstanceDevices(dbInst.Devices.Clone(), dbInst.Profiles)
+			for _, devName := range usedByDevices {
+				if shared.IsTrue(devices[devName]["readonly"]) {
+					continue
+				}
+
+				conflictInstance = &dbInst
+				return db.ErrInstanceListStop // Stop the search, the volume is attached with write access.
+			}
+
+			return nil
+		}
+
+		conflictInstance = &dbInst
+		return db.ErrInstanceListStop // Stop the search, the volume is attached to another instance.
+	})
+	if err != nil && err != db.ErrInstanceListStop {
+		return nil, err
+	}
+
+	return conflictInstance, nil
+}
+
 // VolumeUsedByDaemon indicates whether the volume is used by daemon storage.
 func VolumeUsedByDaemon(s *state.State, poolName string, volumeName string) (bool, error) {
 	var storageBackups string
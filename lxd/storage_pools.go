@@ -659,6 +659,7 @@ func storagePoolGet(d *Daemon, r *http.Request) response.Response {
 //	Update the storage pool
 //
 //	Updates the entire storage pool configuration.
+//	When `dry-run` is set, the update is only validated and the configuration changes it would make are returned.
 //
 //	---
 //	consumes:
@@ -676,6 +677,11 @@ func storagePoolGet(d *Daemon, r *http.Request) response.Response {
 //	    description: Cluster member name
 //	    type: string
 //	    example: lxd01
+//	  - in: query
+//	    name: dry-run
+//	    description: Only validate the update and return the changes it would make
+//	    type: boolean
+//	    example: true
 //	  - in: body
 //	    name: storage pool
 //	    description: Storage pool configuration
@@ -769,8 +775,12 @@ func storagePoolPut(d *Daemon, r *http.Request) response.Response {
 	}
 
 	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+	dryRun := shared.IsTrue(request.QueryParam(r, "dry-run"))
 
-	response := doStoragePoolUpdate(s, pool, req, targetNode, clientType, r.Method, s.ServerClustered)
+	response := doStoragePoolUpdate(s, pool, req, targetNode, clientType, r.Method, s.ServerClustered, dryRun)
+	if dryRun {
+		return response
+	}
 
 	requestor := request.CreateRequestor(r)
 
@@ -829,7 +839,7 @@ func storagePoolPatch(d *Daemon, r *http.Request) response.Response {
 
 // doStoragePoolUpdate takes the current local storage pool config, merges with the requested storage pool config,
 // validates and applies the changes. Will also notify other cluster nodes of non-node specific config if needed.
-func doStoragePoolUpdate(s *state.State, pool storagePools.Pool, req api.StoragePoolPut, targetNode string, clientType clusterRequest.ClientType, httpMethod string, clustered bool) response.Response {
+func doStoragePoolUpdate(s *state.State, pool storagePools.Pool, req api.StoragePoolPut, targetNode string, clientType clusterRequest.ClientType, httpMethod string, clustered bool, dryRun bool) response.Response {
 	if req.Config == nil {
 		req.Config = map[string]string{}
 	}
@@ -862,6 +872,16 @@ func doStoragePoolUpdate(s *state.State, pool storagePools.Pool, req api.Storage
 		return response.BadRequest(err)
 	}
 
+	if dryRun {
+		oldEntity := util.CopyConfig(pool.Driver().Config())
+		oldEntity["description"] = pool.Description()
+
+		newEntity := util.CopyConfig(req.Config)
+		newEntity["description"] = req.Description
+
+		return response.SyncResponse(true, &api.ConfigDiff{Changes: util.DiffConfigs(oldEntity, newEntity)})
+	}
+
 	// Notify the other nodes, unless this is itself a notification.
 	if clustered && clientType != clusterRequest.ClientTypeNotifier && targetNode == "" {
 		notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAll)
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// storagePoolBenchmarkDefaultSize is the default size of the benchmark test file.
+const storagePoolBenchmarkDefaultSize = "256MiB"
+
+var storagePoolBenchmarksCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/benchmarks",
+
+	Get:  APIEndpointAction{Handler: storagePoolBenchmarksGet, AccessHandler: allowPermission(entity.TypeStoragePool, auth.EntitlementCanView, "poolName")},
+	Post: APIEndpointAction{Handler: storagePoolBenchmarksPost, AccessHandler: allowPermission(entity.TypeStoragePool, auth.EntitlementCanEdit, "poolName")},
+}
+
+var storagePoolBenchmarkCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/benchmarks/{id}",
+
+	Delete: APIEndpointAction{Handler: storagePoolBenchmarkDelete, AccessHandler: allowPermission(entity.TypeStoragePool, auth.EntitlementCanEdit, "poolName")},
+	Get:    APIEndpointAction{Handler: storagePoolBenchmarkGet, AccessHandler: allowPermission(entity.TypeStoragePool, auth.EntitlementCanView, "poolName")},
+}
+
+// API endpoints
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/benchmarks storage storage_pool_benchmarks_get
+//
+//	Get the storage pool benchmarks
+//
+//	Returns a list of storage pool benchmark results (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/storage-pools/default/benchmarks/1",
+//	              "/1.0/storage-pools/default/benchmarks/2"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/benchmarks?recursion=1 storage storage_pool_benchmarks_get_recursion1
+//
+//	Get the storage pool benchmarks
+//
+//	Returns a list of storage pool benchmark results (structs), oldest first.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of storage pool benchmarks
+//	          items:
+//	            $ref: "#/definitions/StoragePoolBenchmark"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBenchmarksGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// The results of all members are stored in the global database, so the target only filters them.
+	memberName := request.QueryParam(r, "target")
+
+	var benchmarks []api.StoragePoolBenchmark
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		benchmarks, err = tx.GetStoragePoolBenchmarks(ctx, pool.ID(), memberName)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if util.IsRecursionRequest(r) {
+		return response.SyncResponse(true, benchmarks)
+	}
+
+	urls := make([]string, 0, len(benchmarks))
+	for _, benchmark := range benchmarks {
+		urls = append(urls, api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "benchmarks", strconv.FormatInt(benchmark.ID, 10)).String())
+	}
+
+	return response.SyncResponse(true, urls)
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/benchmarks storage storage_pool_benchmarks_post
+//
+//	Run a storage pool benchmark
+//
+//	Runs a short I/O benchmark against a temporary volume on the storage pool and records the result.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	  - in: body
+//	    name: benchmark
+//	    description: Benchmark request
+//	    required: false
+//	    schema:
+//	      $ref: "#/definitions/StoragePoolBenchmarksPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBenchmarksPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.StoragePoolBenchmarksPost{}
+	if r.ContentLength > 0 {
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
+	if req.Size == "" {
+		req.Size = storagePoolBenchmarkDefaultSize
+	}
+
+	size, err := units.ParseByteSizeString(req.Size)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid benchmark size %q: %w", req.Size, err))
+	}
+
+	if size < 1024*1024 {
+		return response.BadRequest(fmt.Errorf("Benchmark size must be at least 1MiB"))
+	}
+
+	if size > storagePools.BenchmarkMaxSize {
+		return response.BadRequest(fmt.Errorf("Benchmark size must be at most %s", units.GetByteSizeStringIEC(storagePools.BenchmarkMaxSize, 0)))
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if pool.LocalStatus() != api.StoragePoolStatusCreated {
+		return response.BadRequest(fmt.Errorf("Storage pool is not available on this member"))
+	}
+
+	ctx, cancel := context.WithCancel(s.ShutdownCtx)
+
+	run := func(op *operations.Operation) error {
+		defer cancel()
+
+		benchmark, err := pool.Benchmark(ctx, size, op)
+		if err != nil {
+			return err
+		}
+
+		benchmark.Location = s.ServerName
+
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			benchmark.ID, err = tx.CreateStoragePoolBenchmark(ctx, pool.ID(), tx.GetNodeID(), *benchmark)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		return op.UpdateMetadata(map[string]any{"benchmark": benchmark})
+	}
+
+	onCancel := func(op *operations.Operation) error {
+		cancel()
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_pools"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName)}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.StoragePoolBenchmark, resources, nil, run, onCancel, nil, r)
+	if err != nil {
+		cancel()
+		return response.SmartError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/benchmarks/{id} storage storage_pool_benchmark_get
+//
+//	Get the storage pool benchmark
+//
+//	Gets a specific storage pool benchmark result.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Storage pool benchmark
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/StoragePoolBenchmark"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBenchmarkGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	pool, id, err := storagePoolBenchmarkParams(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var benchmarks []api.StoragePoolBenchmark
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		benchmarks, err = tx.GetStoragePoolBenchmarks(ctx, pool.ID(), "")
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, benchmark := range benchmarks {
+		if benchmark.ID == id {
+			return response.SyncResponse(true, benchmark)
+		}
+	}
+
+	return response.NotFound(fmt.Errorf("Storage pool benchmark not found"))
+}
+
+// swagger:operation DELETE /1.0/storage-pools/{poolName}/benchmarks/{id} storage storage_pool_benchmark_delete
+//
+//	Delete the storage pool benchmark
+//
+//	Removes a storage pool benchmark result.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBenchmarkDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	pool, id, err := storagePoolBenchmarkParams(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteStoragePoolBenchmark(ctx, pool.ID(), id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// storagePoolBenchmarkParams returns the storage pool and benchmark ID of a storage pool benchmark request.
+func storagePoolBenchmarkParams(s *state.State, r *http.Request) (storagePools.Pool, int64, error) {
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return nil, -1, err
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return nil, -1, api.StatusErrorf(http.StatusBadRequest, "Invalid storage pool benchmark ID %q", mux.Vars(r)["id"])
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return pool, id, nil
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var storagePoolMigrateCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/migrate",
+
+	Post: APIEndpointAction{Handler: storagePoolMigratePost, AccessHandler: allowPermission(entity.TypeStoragePool, auth.EntitlementCanEdit, "poolName")},
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/migrate storage storage_pool_migrate_post
+//
+//	Migrate the storage pool volumes
+//
+//	Moves all instance and custom volumes of the storage pool on this server to another storage pool.
+//	The root disk devices of the moved instances are updated to use the new storage pool.
+//	If moving a volume fails, the volumes moved so far are moved back.
+//
+//	When `dry_run` is set, the volumes that would be moved are returned without moving them.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	  - in: body
+//	    name: migration
+//	    description: Migration request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/StoragePoolMigratePost"
+//	responses:
+//	  "200":
+//	    description: Volumes that would be moved (dry run)
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of volumes
+//	          items:
+//	            $ref: "#/definitions/StoragePoolMigrateVolume"
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolMigratePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.StoragePoolMigratePost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Pool == "" {
+		return response.BadRequest(fmt.Errorf("No target storage pool provided"))
+	}
+
+	if req.Pool == poolName {
+		return response.BadRequest(fmt.Errorf("Source and target storage pools must be different"))
+	}
+
+	// The volumes are created on the target pool, so the caller must be allowed to edit it as well.
+	err = s.Authorizer.CheckPermission(r.Context(), r, entity.StoragePoolURL(req.Pool), auth.EntitlementCanEdit)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	newPool, err := storagePools.LoadByName(s, req.Pool)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if pool.LocalStatus() != api.StoragePoolStatusCreated {
+		return response.BadRequest(fmt.Errorf("Storage pool is not available on this member"))
+	}
+
+	if newPool.LocalStatus() != api.StoragePoolStatusCreated {
+		return response.BadRequest(fmt.Errorf("Target storage pool is not available on this member"))
+	}
+
+	// Mark the source pool before listing its volumes, so that no volumes are created on it afterwards and left
+	// behind by the migration.
+	unmark, err := storagePools.MarkMigrating(pool.Name())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(unmark)
+
+	volumes, err := storagePoolMigrateVolumes(r.Context(), s, pool, newPool)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if req.DryRun {
+		return response.SyncResponse(true, volumes)
+	}
+
+	run := func(op *operations.Operation) error {
+		return storagePoolMigrate(s, pool, newPool, volumes, unmark, op)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_pools"] = []api.URL{
+		*api.NewURL().Path(version.APIVersion, "storage-pools", pool.Name()),
+		*api.NewURL().Path(version.APIVersion, "storage-pools", newPool.Name()),
+	}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.StoragePoolMigrate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// The mark is removed by the operation once it's done.
+	revert.Success()
+
+	return operations.OperationResponse(op)
+}
+
+// storagePoolMigrateVolumes returns the volumes of the storage pool on this member that are moved when migrating
+// it to newPool. Instance volumes come first, followed by custom volumes. An error is returned if any of the
+// volumes can't be moved, so that nothing is touched unless the whole pool can be migrated.
+// The volumes of a remote pool are shared by all members, so they are all moved at once regardless of the member
+// the instances are located on, which requires newPool to be a remote pool as well.
+func storagePoolMigrateVolumes(ctx context.Context, s *state.State, pool storagePools.Pool, newPool storagePools.Pool) ([]api.StoragePoolMigrateVolume, error) {
+	remote := pool.Driver().Info().Remote
+	poolID := pool.ID()
+	filters := make([]db.StorageVolumeFilter, 0, 3)
+	for _, volType := range []int{cluster.StoragePoolVolumeTypeContainer, cluster.StoragePoolVolumeTypeVM, cluster.StoragePoolVolumeTypeCustom} {
+		filters = append(filters, db.StorageVolumeFilter{Type: &volType, PoolID: &poolID})
+	}
+
+	var dbVolumes []*db.StorageVolume
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbVolumes, err = tx.GetStorageVolumes(ctx, true, filters...)
+		if err != nil {
+			return err
+		}
+
+		// Custom volumes keep their name, so it must not be in use on the target pool yet.
+		for _, dbVol := range dbVolumes {
+			if dbVol.Type != cluster.StoragePoolVolumeTypeNameCustom || shared.IsSnapshot(dbVol.Name) {
+				continue
+			}
+
+			_, err = tx.GetStoragePoolNodeVolumeID(ctx, dbVol.Project, dbVol.Name, cluster.StoragePoolVolumeTypeCustom, newPool.ID())
+			if err == nil {
+				return api.StatusErrorf(http.StatusConflict, "Custom volume %q in project %q already exists on storage pool %q", dbVol.Name, dbVol.Project, newPool.Name())
+			} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the snapshots of each volume, they are moved along with it.
+	snapshots := map[string]int{}
+	for _, dbVol := range dbVolumes {
+		if shared.IsSnapshot(dbVol.Name) {
+			parentName, _, _ := api.GetParentAndSnapshotName(dbVol.Name)
+			snapshots[dbVol.Project+"/"+dbVol.Type+"/"+parentName]++
+		}
+	}
+
+	instVolumes := []api.StoragePoolMigrateVolume{}
+	customVolumes := []api.StoragePoolMigrateVolume{}
+	for _, dbVol := range dbVolumes {
+		if shared.IsSnapshot(dbVol.Name) {
+			continue
+		}
+
+		vol := api.StoragePoolMigrateVolume{
+			Name:      dbVol.Name,
+			Type:      dbVol.Type,
+			Project:   dbVol.Project,
+			Snapshots: snapshots[dbVol.Project+"/"+dbVol.Type+"/"+dbVol.Name],
+		}
+
+		if dbVol.Type == cluster.StoragePoolVolumeTypeNameCustom {
+			used, err := storagePools.VolumeUsedByDaemon(s, pool.Name(), dbVol.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			if used {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Custom volume %q is used by LXD itself and cannot be moved", dbVol.Name)
+			}
+
+			err = storagePools.VolumeUsedByInstanceDevices(s, pool.Name(), dbVol.Project, &dbVol.StorageVolume, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+				inst, err := instance.Load(s, dbInst, project)
+				if err != nil {
+					return err
+				}
+
+				if inst.IsRunning() {
+					return api.StatusErrorf(http.StatusBadRequest, "Custom volume %q in project %q is in use by running instance %q", dbVol.Name, dbVol.Project, inst.Name())
+				}
+
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			customVolumes = append(customVolumes, vol)
+			continue
+		}
+
+		inst, err := instance.LoadByProjectAndName(s, dbVol.Project, dbVol.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading instance %q in project %q: %w", dbVol.Name, dbVol.Project, err)
+		}
+
+		if s.ServerClustered && inst.Location() != s.ServerName {
+			if !remote {
+				continue
+			}
+
+			if !newPool.Driver().Info().Remote {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Instance %q in project %q is located on cluster member %q and can only be moved to a remote storage pool", inst.Name(), inst.Project().Name, inst.Location())
+			}
+		}
+
+		if inst.IsRunning() {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Instance %q in project %q must be stopped", inst.Name(), inst.Project().Name)
+		}
+
+		instVolumes = append(instVolumes, vol)
+	}
+
+	return append(instVolumes, customVolumes...), nil
+}
+
+// storagePoolMigrate moves the volumes from pool to newPool one after the other, reporting the progress in the
+// operation metadata. If a volume can't be moved, the volumes that were already moved are moved back.
+// The mark of the source pool is removed with unmark before the volumes are moved back to it.
+func storagePoolMigrate(s *state.State, pool storagePools.Pool, newPool storagePools.Pool, volumes []api.StoragePoolMigrateVolume, unmark func(), op *operations.Operation) error {
+	revert := revert.New()
+	defer revert.Fail()
+	defer unmark()
+
+	for i, vol := range volumes {
+		err := op.ExtendMetadata(map[string]any{"migrate_progress": fmt.Sprintf("%d/%d %s (%s, project %s)", i+1, len(volumes), vol.Name, vol.Type, vol.Project)})
+		if err != nil {
+			return err
+		}
+
+		l := logger.AddContext(logger.Ctx{"project": vol.Project, "volume": vol.Name, "type": vol.Type, "pool": pool.Name(), "newPool": newPool.Name()})
+		l.Info("Moving volume to new storage pool")
+
+		if vol.Type == cluster.StoragePoolVolumeTypeNameCustom {
+			err = storagePoolMigrateCustomVolume(s, vol.Project, vol.Name, pool, newPool, op)
+			if err != nil {
+				return fmt.Errorf("Failed moving custom volume %q in project %q: %w", vol.Name, vol.Project, err)
+			}
+
+			revert.Add(func() {
+				err := storagePoolMigrateCustomVolume(s, vol.Project, vol.Name, newPool, pool, op)
+				if err != nil {
+					l.Error("Failed moving volume back to original storage pool", logger.Ctx{"err": err})
+				}
+			})
+		} else {
+			err = storagePoolMigrateInstance(s, vol.Project, vol.Name, newPool, op)
+			if err != nil {
+				return fmt.Errorf("Failed moving instance %q in project %q: %w", vol.Name, vol.Project, err)
+			}
+
+			revert.Add(func() {
+				err := storagePoolMigrateInstance(s, vol.Project, vol.Name, pool, op)
+				if err != nil {
+					l.Error("Failed moving volume back to original storage pool", logger.Ctx{"err": err})
+				}
+			})
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// storagePoolMigrateInstance moves an instance and its snapshots to newPool, pointing its root disk device to it.
+func storagePoolMigrateInstance(s *state.State, projectName string, instName string, newPool storagePools.Pool, op *operations.Operation) error {
+	inst, err := instance.LoadByProjectAndName(s, projectName, instName)
+	if err != nil {
+		return err
+	}
+
+	return instancePostMigration(s, inst, inst.Name(), newPool.Name(), "", nil, nil, nil, false, false, false, op)
+}
+
+// storagePoolMigrateCustomVolume moves a custom volume and its snapshots from pool to newPool.
+func storagePoolMigrateCustomVolume(s *state.State, projectName string, volName string, pool storagePools.Pool, newPool storagePools.Pool, op *operations.Operation) error {
+	var dbVol *db.StorageVolume
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbVol, err = tx.GetStoragePoolVolume(ctx, pool.ID(), projectName, cluster.StoragePoolVolumeTypeCustom, volName, true)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	newVol := dbVol.StorageVolume
+	newVol.Pool = newPool.Name()
+
+	return storagePoolVolumeMove(s, projectName, projectName, pool, &dbVol.StorageVolume, newPool, &newVol, op)
+}
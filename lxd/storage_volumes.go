@@ -1041,6 +1041,8 @@ func storagePoolVolumesPost(d *Daemon, r *http.Request) response.Response {
 		return doVolumeCreateOrCopy(s, r, requestProjectName, projectName, poolName, &req)
 	case "migration":
 		return doVolumeMigration(s, r, requestProjectName, projectName, poolName, &req)
+	case "url":
+		return createStoragePoolVolumeFromURL(s, r, requestProjectName, projectName, poolName, &req)
 	default:
 		return response.BadRequest(fmt.Errorf("Unknown source type %q", req.Source.Type))
 	}
@@ -1127,7 +1129,7 @@ func doCustomVolumeRefresh(s *state.State, r *http.Request, requestProjectName s
 
 	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeCopy, nil, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -1181,7 +1183,7 @@ func doVolumeCreateOrCopy(s *state.State, r *http.Request, requestProjectName st
 	// Volume copy operations potentially take a long time, so run as an async operation.
 	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeCopy, nil, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -1256,12 +1258,12 @@ func doVolumeMigration(s *state.State, r *http.Request, requestProjectName strin
 	if push {
 		op, err = operations.OperationCreate(s, requestProjectName, operations.OperationClassWebsocket, operationtype.VolumeCreate, resources, sink.Metadata(), run, nil, sink.Connect, r)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 	} else {
 		op, err = operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeCopy, resources, nil, run, nil, nil, r)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 	}
 
@@ -1532,7 +1534,7 @@ func storagePoolVolumePost(d *Daemon, r *http.Request) response.Response {
 
 		op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.VolumeMigrate, resources, nil, run, nil, nil, r)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 
 		return operations.OperationResponse(op)
@@ -1817,7 +1819,7 @@ func storagePoolVolumeTypePostMigration(state *state.State, r *http.Request, req
 		// Push mode.
 		op, err := operations.OperationCreate(state, requestProjectName, operations.OperationClassTask, operationtype.VolumeMigrate, resources, nil, run, nil, nil, r)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 
 		return operations.OperationResponse(op)
@@ -1826,7 +1828,7 @@ func storagePoolVolumeTypePostMigration(state *state.State, r *http.Request, req
 	// Pull mode.
 	op, err := operations.OperationCreate(state, requestProjectName, operations.OperationClassWebsocket, operationtype.VolumeMigrate, resources, ws.Metadata(), run, nil, ws.Connect, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -1883,41 +1885,47 @@ func storagePoolVolumeTypePostMove(s *state.State, r *http.Request, poolName str
 	}
 
 	run := func(op *operations.Operation) error {
-		revert := revert.New()
-		defer revert.Fail()
+		return storagePoolVolumeMove(s, requestProjectName, projectName, pool, vol, newPool, &newVol, op)
+	}
 
-		// Update devices using the volume in instances and profiles.
-		err = storagePoolVolumeUpdateUsers(s, requestProjectName, pool.Name(), vol, newPool.Name(), &newVol)
-		if err != nil {
-			return err
-		}
+	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeMove, nil, nil, run, nil, nil, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
 
-		revert.Add(func() {
-			_ = storagePoolVolumeUpdateUsers(s, projectName, newPool.Name(), &newVol, pool.Name(), vol)
-		})
+	return operations.OperationResponse(op)
+}
 
-		// Provide empty description and nil config to instruct CreateCustomVolumeFromCopy to copy it
-		// from source volume.
-		err = newPool.CreateCustomVolumeFromCopy(projectName, requestProjectName, newVol.Name, "", nil, pool.Name(), vol.Name, true, op)
-		if err != nil {
-			return err
-		}
+// storagePoolVolumeMove moves a custom volume and its snapshots to another pool and project, updating the
+// devices of the instances and profiles using it.
+func storagePoolVolumeMove(s *state.State, projectName string, newProjectName string, pool storagePools.Pool, vol *api.StorageVolume, newPool storagePools.Pool, newVol *api.StorageVolume, op *operations.Operation) error {
+	revert := revert.New()
+	defer revert.Fail()
 
-		err = pool.DeleteCustomVolume(requestProjectName, vol.Name, op)
-		if err != nil {
-			return err
-		}
+	// Update devices using the volume in instances and profiles.
+	err := storagePoolVolumeUpdateUsers(s, projectName, pool.Name(), vol, newPool.Name(), newVol)
+	if err != nil {
+		return err
+	}
 
-		revert.Success()
-		return nil
+	revert.Add(func() {
+		_ = storagePoolVolumeUpdateUsers(s, newProjectName, newPool.Name(), newVol, pool.Name(), vol)
+	})
+
+	// Provide empty description and nil config to instruct CreateCustomVolumeFromCopy to copy it
+	// from source volume.
+	err = newPool.CreateCustomVolumeFromCopy(newProjectName, projectName, newVol.Name, "", nil, pool.Name(), vol.Name, true, op)
+	if err != nil {
+		return err
 	}
 
-	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeMove, nil, nil, run, nil, nil, r)
+	err = pool.DeleteCustomVolume(projectName, vol.Name, op)
 	if err != nil {
-		return response.InternalError(err)
+		return err
 	}
 
-	return operations.OperationResponse(op)
+	revert.Success()
+	return nil
 }
 
 // swagger:operation GET /1.0/storage-pools/{poolName}/volumes/{type}/{volumeName} storage storage_pool_volume_type_get
@@ -2555,7 +2563,7 @@ func createStoragePoolVolumeFromISO(s *state.State, r *http.Request, requestProj
 
 	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeCreate, resources, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	revert.Success()
@@ -2721,7 +2729,7 @@ func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestP
 
 	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.CustomVolumeBackupRestore, resources, nil, run, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	revert.Success()
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/cluster"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// storageVolumeSnapshotSFTPTimeout is how long a custom volume snapshot is kept exposed over SFTP before it is torn
+// down, regardless of whether clients are still connected.
+const storageVolumeSnapshotSFTPTimeout = time.Hour
+
+// storageVolumeSnapshotSFTPServers are the running SFTP servers keyed by pool, project and snapshot name.
+var storageVolumeSnapshotSFTPServers = map[string]*storageVolumeSnapshotSFTPServer{}
+var storageVolumeSnapshotSFTPServersMu sync.Mutex
+
+// storageVolumeSnapshotSFTPServer is a forkfile process serving a read-only mount of a custom volume snapshot.
+type storageVolumeSnapshotSFTPServer struct {
+	addr *net.UnixAddr
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/volumes/{type}/{volumeName}/snapshots/{snapshotName}/sftp storage storage_pool_volumes_type_snapshot_sftp
+//
+//	Get the storage volume snapshot SFTP connection
+//
+//	Upgrades the request to an SFTP connection of the custom storage volume snapshot's filesystem.
+//	The snapshot is mounted read-only and unmounted once the SFTP server is idle or after one hour.
+//
+//	---
+//	produces:
+//	  - application/json
+//	  - application/octet-stream
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "101":
+//	    description: Switching protocols to SFTP
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolVolumeSnapshotTypeSFTPHandler(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	volumeTypeName, err := url.PathUnescape(mux.Vars(r)["type"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	volumeName, err := url.PathUnescape(mux.Vars(r)["volumeName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	snapshotName, err := url.PathUnescape(mux.Vars(r)["snapshotName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if r.Header.Get("Upgrade") != "sftp" {
+		return response.SmartError(api.StatusErrorf(http.StatusBadRequest, "Missing or invalid upgrade header"))
+	}
+
+	// Convert the volume type name to our internal integer representation.
+	volumeType, err := storagePools.VolumeTypeNameToDBType(volumeTypeName)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if volumeType != dbCluster.StoragePoolVolumeTypeCustom {
+		return response.BadRequest(fmt.Errorf("Only custom volume snapshots can be accessed over SFTP"))
+	}
+
+	requestProjectName := request.ProjectParam(r)
+	projectName, err := project.StorageVolumeProject(s.DB.Cluster, requestProjectName, volumeType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	fullSnapshotName := storageDrivers.GetSnapshotVolumeName(volumeName, snapshotName)
+
+	resp := &sftpServeResponse{
+		req:         r,
+		projectName: projectName,
+		volName:     fullSnapshotName,
+	}
+
+	// Forward the request if the volume is remote.
+	var client lxd.InstanceServer
+	target := request.QueryParam(r, "target")
+	if target != "" {
+		address, err := cluster.ResolveTarget(r.Context(), s, target)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if address != "" {
+			remote, err := cluster.Connect(address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			client = remote.UseTarget(target)
+		}
+	} else {
+		remote, err := cluster.ConnectIfVolumeIsRemote(s, poolName, projectName, fullSnapshotName, volumeType, s.Endpoints.NetworkCert(), s.ServerCert(), r)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if remote != nil {
+			client = remote
+		}
+	}
+
+	if client != nil {
+		resp.instConn, err = client.UseProject(requestProjectName).GetStoragePoolVolumeSnapshotSFTPConn(poolName, volumeTypeName, volumeName, snapshotName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return resp
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	dbVolume, err := storagePools.VolumeDBGet(pool, projectName, fullSnapshotName, storageDrivers.VolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if dbVolume.ContentType != dbCluster.StoragePoolVolumeContentTypeNameFS {
+		return response.BadRequest(fmt.Errorf("Only filesystem volume snapshots can be accessed over SFTP"))
+	}
+
+	resp.instConn, err = storageVolumeSnapshotSFTPConn(s, pool, projectName, fullSnapshotName)
+	if err != nil {
+		return response.SmartError(api.StatusErrorf(http.StatusInternalServerError, "Failed getting storage volume snapshot SFTP connection: %w", err))
+	}
+
+	return resp
+}
+
+// storageVolumeSnapshotSFTPConn returns a connection to the SFTP server of the custom volume snapshot, mounting the
+// snapshot and starting the server if needed.
+func storageVolumeSnapshotSFTPConn(s *state.State, pool storagePools.Pool, projectName string, snapshotName string) (net.Conn, error) {
+	storageVolumeSnapshotSFTPServersMu.Lock()
+	defer storageVolumeSnapshotSFTPServersMu.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%s", pool.Name(), projectName, snapshotName)
+
+	// Attempt to connect to an existing server.
+	server := storageVolumeSnapshotSFTPServers[key]
+	if server != nil {
+		conn, err := net.DialUnix("unix", nil, server.addr)
+		if err == nil {
+			return conn, nil
+		}
+	}
+
+	l := logger.AddContext(logger.Ctx{"pool": pool.Name(), "project": projectName, "volume": snapshotName})
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	_, err := pool.MountCustomVolumeSnapshot(projectName, snapshotName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Add(func() { _, _ = pool.UnmountCustomVolumeSnapshot(projectName, snapshotName, nil) })
+
+	// Use an abstract socket as the server only needs to be reachable from this process.
+	addr := &net.UnixAddr{Name: fmt.Sprintf("@lxd/sftp/%s", uuid.New().String()), Net: "unix"}
+	listener, err := net.ListenUnix("unix", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Add(func() { _ = listener.Close() })
+
+	listenerFile, err := listener.File()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = listenerFile.Close() }()
+
+	volStorageName := project.StorageVolume(projectName, snapshotName)
+	rootfsFile, err := os.Open(storageDrivers.GetVolumeMountPath(pool.Name(), storageDrivers.VolumeTypeCustom, volStorageName))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rootfsFile.Close() }()
+
+	// Without a PID, forkfile serves the content of the rootfs fd from a chroot.
+	forkfile := exec.Cmd{
+		Path:       s.OS.ExecPath,
+		Args:       []string{s.OS.ExecPath, "forkfile", "--", "3", "4", "-1", "0"},
+		ExtraFiles: []*os.File{listenerFile, rootfsFile},
+	}
+
+	var stderr bytes.Buffer
+	forkfile.Stderr = &stderr
+
+	err = forkfile.Start()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to run forkfile: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	revert.Add(func() {
+		_ = forkfile.Process.Kill()
+		_ = forkfile.Wait()
+	})
+
+	conn, err := net.DialUnix("unix", nil, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	server = &storageVolumeSnapshotSFTPServer{addr: addr}
+	storageVolumeSnapshotSFTPServers[key] = server
+
+	l.Info("Exposing storage volume snapshot over SFTP")
+
+	// Tear down the server and the mount once forkfile exits on its own after being idle or after the timeout.
+	go func() {
+		timer := time.AfterFunc(storageVolumeSnapshotSFTPTimeout, func() {
+			l.Info("Storage volume snapshot SFTP access expired")
+			_ = forkfile.Process.Kill()
+		})
+
+		err := forkfile.Wait()
+		expired := !timer.Stop()
+		if err != nil && !expired {
+			l.Error("SFTP server stopped with error", logger.Ctx{"err": err, "stderr": strings.TrimSpace(stderr.String())})
+		}
+
+		storageVolumeSnapshotSFTPServersMu.Lock()
+		defer storageVolumeSnapshotSFTPServersMu.Unlock()
+
+		if storageVolumeSnapshotSFTPServers[key] == server {
+			delete(storageVolumeSnapshotSFTPServers, key)
+		}
+
+		_ = listener.Close()
+
+		_, err = pool.UnmountCustomVolumeSnapshot(projectName, snapshotName, nil)
+		if err != nil {
+			l.Warn("Failed unmounting storage volume snapshot", logger.Ctx{"err": err})
+		}
+
+		l.Info("Stopped exposing storage volume snapshot over SFTP")
+	}()
+
+	revert.Success()
+
+	return conn, nil
+}
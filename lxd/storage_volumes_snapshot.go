@@ -51,6 +51,12 @@ var storagePoolVolumeSnapshotTypeCmd = APIEndpoint{
 	Put:    APIEndpointAction{Handler: storagePoolVolumeSnapshotTypePut, AccessHandler: allowPermission(entity.TypeStorageVolume, auth.EntitlementCanManageSnapshots, "poolName", "type", "volumeName")},
 }
 
+var storagePoolVolumeSnapshotTypeSFTPCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/volumes/{type}/{volumeName}/snapshots/{snapshotName}/sftp",
+
+	Get: APIEndpointAction{Handler: storagePoolVolumeSnapshotTypeSFTPHandler, AccessHandler: allowPermission(entity.TypeStorageVolume, auth.EntitlementCanManageSnapshots, "poolName", "type", "volumeName")},
+}
+
 // swagger:operation POST /1.0/storage-pools/{poolName}/volumes/{type}/{volumeName}/snapshots storage storage_pool_volumes_type_snapshots_post
 //
 //	Create a storage volume snapshot
@@ -258,7 +264,7 @@ func storagePoolVolumeSnapshotsTypePost(d *Daemon, r *http.Request) response.Res
 
 	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeSnapshotCreate, resources, nil, snapshot, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -609,7 +615,7 @@ func storagePoolVolumeSnapshotTypePost(d *Daemon, r *http.Request) response.Resp
 
 	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeSnapshotRename, resources, nil, snapshotRename, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -1165,7 +1171,7 @@ func storagePoolVolumeSnapshotTypeDelete(d *Daemon, r *http.Request) response.Re
 
 	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeSnapshotDelete, resources, nil, snapshotDelete, nil, nil, r)
 	if err != nil {
-		return response.InternalError(err)
+		return response.SmartError(err)
 	}
 
 	return operations.OperationResponse(op)
@@ -1174,7 +1180,8 @@ func storagePoolVolumeSnapshotTypeDelete(d *Daemon, r *http.Request) response.Re
 func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, task.Schedule) {
 	f := func(ctx context.Context) {
 		s := d.State()
-		var volumes, remoteVolumes, expiredSnapshots, expiredRemoteSnapshots []db.StorageVolumeArgs
+		var volumes, remoteVolumes []scheduledVolumeSnapshot
+		var expiredSnapshots, expiredRemoteSnapshots []db.StorageVolumeArgs
 		var memberCount int
 		var onlineMemberIDs []int64
 
@@ -1221,23 +1228,32 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 					continue
 				}
 
-				schedule, ok := v.Config["snapshots.schedule"]
-				if !ok || schedule == "" {
-					continue
+				var due []scheduledVolumeSnapshot
+
+				// Check if the snapshot schedule of the volume is due.
+				schedule := v.Config["snapshots.schedule"]
+				if schedule != "" && snapshotIsScheduledNow(schedule, v.ID) {
+					due = append(due, scheduledVolumeSnapshot{vol: v})
+				}
+
+				// Check if any of the named snapshot schedules of the volume are due.
+				for _, namedSchedule := range util.SnapshotSchedules(v.Config) {
+					if snapshotIsScheduledNow(namedSchedule.Schedule, v.ID) {
+						due = append(due, scheduledVolumeSnapshot{vol: v, schedule: &namedSchedule})
+					}
 				}
 
-				// Check if snapshot is scheduled.
-				if !snapshotIsScheduledNow(schedule, v.ID) {
+				if len(due) == 0 {
 					continue
 				}
 
 				if v.NodeID < 0 {
 					// Keep a separate list of remote volumes in order to select a member to
 					// perform the snapshot later.
-					remoteVolumes = append(remoteVolumes, v)
+					remoteVolumes = append(remoteVolumes, due...)
 				} else {
 					logger.Debug("Scheduling local auto custom volume snapshot", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
-					volumes = append(volumes, v) // Always include local volumes.
+					volumes = append(volumes, due...) // Always include local volumes.
 				}
 			}
 
@@ -1308,7 +1324,9 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 			if memberCount > 1 && len(onlineMemberIDs) <= 0 {
 				logger.Error("Skipping remote volumes for auto custom volume snapshot task due to no online members")
 			} else {
-				for _, v := range remoteVolumes {
+				for _, snapshot := range remoteVolumes {
+					v := snapshot.vol
+
 					// If there are multiple cluster members, a stable random member is chosen
 					// to perform the snapshot from. This avoids taking the snapshot on every
 					// member and spreads the load taking the snapshots across the online
@@ -1327,7 +1345,7 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 					}
 
 					logger.Debug("Scheduling remote auto custom volume snapshot", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
-					volumes = append(volumes, v)
+					volumes = append(volumes, snapshot)
 				}
 			}
 		}
@@ -1429,20 +1447,40 @@ func pruneExpiredCustomVolumeSnapshots(ctx context.Context, s *state.State, expi
 	return nil
 }
 
-func autoCreateCustomVolumeSnapshots(ctx context.Context, s *state.State, volumes []db.StorageVolumeArgs) error {
+// scheduledVolumeSnapshot is a custom volume that is due for a scheduled snapshot.
+type scheduledVolumeSnapshot struct {
+	vol db.StorageVolumeArgs
+
+	// schedule is the named snapshot schedule that is due, nil for snapshots.schedule.
+	schedule *util.SnapshotSchedule
+}
+
+func autoCreateCustomVolumeSnapshots(ctx context.Context, s *state.State, snapshots []scheduledVolumeSnapshot) error {
 	// Make the snapshots sequentially.
-	for _, v := range volumes {
+	for _, snapshot := range snapshots {
 		err := ctx.Err()
 		if err != nil {
 			return err // Stop if context is cancelled.
 		}
 
-		snapshotName, err := volumeDetermineNextSnapshotName(s, v, "snap%d")
+		v := snapshot.vol
+
+		var snapshotName string
+		expiryExpression := v.Config["snapshots.expiry"]
+		if snapshot.schedule != nil {
+			snapshotName, err = volumeDetermineNextSnapshotNameFromPattern(s, v, snapshot.schedule.Pattern())
+			if snapshot.schedule.Expiry != "" {
+				expiryExpression = snapshot.schedule.Expiry
+			}
+		} else {
+			snapshotName, err = volumeDetermineNextSnapshotName(s, v, "snap%d")
+		}
+
 		if err != nil {
 			return fmt.Errorf("Error retrieving next snapshot name for volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
 		}
 
-		expiry, err := shared.GetExpiry(time.Now(), v.Config["snapshots.expiry"])
+		expiry, err := shared.GetExpiry(time.Now(), expiryExpression)
 		if err != nil {
 			return fmt.Errorf("Error getting snapshot expiry for volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
 		}
@@ -1456,19 +1494,68 @@ func autoCreateCustomVolumeSnapshots(ctx context.Context, s *state.State, volume
 		if err != nil {
 			return fmt.Errorf("Error creating snapshot for volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
 		}
+
+		if snapshot.schedule != nil && snapshot.schedule.Keep > 0 {
+			err = pruneCustomVolumeScheduleSnapshots(ctx, s, v, *snapshot.schedule)
+			if err != nil {
+				return fmt.Errorf("Error pruning snapshots of schedule %q for volume %q (project %q, pool %q): %w", snapshot.schedule.Name, v.Name, v.ProjectName, v.PoolName, err)
+			}
+		}
 	}
 
 	return nil
 }
 
-func volumeDetermineNextSnapshotName(s *state.State, volume db.StorageVolumeArgs, defaultPattern string) (string, error) {
-	var err error
+// pruneCustomVolumeScheduleSnapshots deletes the oldest snapshots taken by the named snapshot schedule in
+// excess of the number of snapshots the schedule keeps.
+func pruneCustomVolumeScheduleSnapshots(ctx context.Context, s *state.State, volume db.StorageVolumeArgs, schedule util.SnapshotSchedule) error {
+	var snapshots []db.StorageVolumeArgs
 
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		poolID, err := tx.GetStoragePoolID(ctx, volume.PoolName)
+		if err != nil {
+			return err
+		}
+
+		// The snapshots are returned in the order they were created.
+		snapshots, err = tx.GetLocalStoragePoolVolumeSnapshotsWithType(ctx, volume.ProjectName, volume.Name, dbCluster.StoragePoolVolumeTypeCustom, poolID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	owned := make([]db.StorageVolumeArgs, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		_, snapshotName, _ := api.GetParentAndSnapshotName(snapshot.Name)
+		if schedule.Owns(snapshotName) {
+			snapshot.PoolName = volume.PoolName
+			owned = append(owned, snapshot)
+		}
+	}
+
+	if len(owned) <= schedule.Keep {
+		return nil
+	}
+
+	// Delete the oldest snapshots first.
+	return pruneExpiredCustomVolumeSnapshots(ctx, s, owned[:len(owned)-schedule.Keep])
+}
+
+func volumeDetermineNextSnapshotName(s *state.State, volume db.StorageVolumeArgs, defaultPattern string) (string, error) {
 	pattern, ok := volume.Config["snapshots.pattern"]
 	if !ok {
 		pattern = defaultPattern
 	}
 
+	return volumeDetermineNextSnapshotNameFromPattern(s, volume, pattern)
+}
+
+// volumeDetermineNextSnapshotNameFromPattern finds the next snapshot name for a volume using the given pattern,
+// ignoring the snapshots.pattern setting of the volume.
+func volumeDetermineNextSnapshotNameFromPattern(s *state.State, volume db.StorageVolumeArgs, pattern string) (string, error) {
+	var err error
+
 	pattern, err = shared.RenderTemplate(pattern, pongo2.Context{
 		"creation_date": time.Now(),
 	})
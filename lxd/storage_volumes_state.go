@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -8,16 +9,19 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
 )
 
 var storagePoolVolumeTypeStateCmd = APIEndpoint{
@@ -30,7 +34,7 @@ var storagePoolVolumeTypeStateCmd = APIEndpoint{
 //
 //	Get the storage volume state
 //
-//	Gets a specific storage volume state (usage data).
+//	Gets a specific storage volume state (usage data and I/O counters of the running instances using it).
 //
 //	---
 //	produces:
@@ -116,14 +120,20 @@ func storagePoolVolumeTypeStateGet(d *Daemon, r *http.Request) response.Response
 		return response.SmartError(err)
 	}
 
-	// Fetch the current usage.
+	// Fetch the current usage and I/O counters.
 	var usage *storagePools.VolumeUsage
+	var ioStats []api.StorageVolumeStateIO
 	if volumeType == cluster.StoragePoolVolumeTypeCustom {
 		// Custom volumes.
 		usage, err = pool.GetCustomVolumeUsage(projectName, volumeName)
 		if err != nil {
 			return response.SmartError(err)
 		}
+
+		ioStats, err = storagePoolVolumeIOStats(s, poolName, projectName, volumeName)
+		if err != nil {
+			return response.SmartError(err)
+		}
 	} else {
 		resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, volumeName, instancetype.Any)
 		if err != nil {
@@ -144,6 +154,11 @@ func storagePoolVolumeTypeStateGet(d *Daemon, r *http.Request) response.Response
 		if err != nil {
 			return response.SmartError(err)
 		}
+
+		rootDiskName, _, err := instancetype.GetRootDiskDevice(inst.ExpandedDevices().CloneNative())
+		if err == nil {
+			ioStats = instanceDiskIOStats(inst, []string{rootDiskName})
+		}
 	}
 
 	// Prepare the state struct.
@@ -160,5 +175,75 @@ func storagePoolVolumeTypeStateGet(d *Daemon, r *http.Request) response.Response
 		state.Usage.Total = usage.Total
 	}
 
+	state.IO = ioStats
+
 	return response.SyncResponse(true, state)
 }
+
+// storagePoolVolumeIOStats returns the I/O counters of a custom volume for each running instance on this member
+// that it is attached to.
+func storagePoolVolumeIOStats(s *state.State, poolName string, projectName string, volumeName string) ([]api.StorageVolumeStateIO, error) {
+	var dbVolume *db.StorageVolume
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		poolID, err := tx.GetStoragePoolID(ctx, poolName)
+		if err != nil {
+			return err
+		}
+
+		dbVolume, err = tx.GetStoragePoolVolume(ctx, poolID, projectName, cluster.StoragePoolVolumeTypeCustom, volumeName, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ioStats := []api.StorageVolumeStateIO{}
+	err = storagePools.VolumeUsedByInstanceDevices(s, poolName, projectName, &dbVolume.StorageVolume, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+		// Instances on other members are accounted for by their own member.
+		if s.ServerClustered && dbInst.Node != s.ServerName {
+			return nil
+		}
+
+		inst, err := instance.Load(s, dbInst, project)
+		if err != nil {
+			return err
+		}
+
+		ioStats = append(ioStats, instanceDiskIOStats(inst, usedByDevices)...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ioStats, nil
+}
+
+// instanceDiskIOStats returns the I/O counters of the given disk devices of a running instance.
+// Failing to get the counters isn't fatal as they are only informational.
+func instanceDiskIOStats(inst instance.Instance, devNames []string) []api.StorageVolumeStateIO {
+	if !inst.IsRunning() {
+		return nil
+	}
+
+	stats, err := inst.DiskIOStats()
+	if err != nil {
+		logger.Warn("Failed getting disk I/O statistics", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+		return nil
+	}
+
+	ioStats := make([]api.StorageVolumeStateIO, 0, len(devNames))
+	for _, devName := range devNames {
+		devStats, ok := stats[devName]
+		if !ok {
+			continue
+		}
+
+		devStats.Instance = inst.Name()
+		devStats.Project = inst.Project().Name
+		ioStats = append(ioStats, devStats)
+	}
+
+	return ioStats
+}
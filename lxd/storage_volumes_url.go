@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/cancel"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// volumeURLDownloadAttempts is the number of times an interrupted download is resumed before giving up.
+const volumeURLDownloadAttempts = 5
+
+// volumeURLChecksum parses a checksum in the "<algorithm>:<hex digest>" format.
+func volumeURLChecksum(checksum string) (hash.Hash, string, error) {
+	algorithm, digest, ok := strings.Cut(checksum, ":")
+	if !ok {
+		return nil, "", fmt.Errorf("Checksum must be in the <algorithm>:<digest> format")
+	}
+
+	var hashFunc hash.Hash
+	switch algorithm {
+	case "sha256":
+		hashFunc = sha256.New()
+	case "sha512":
+		hashFunc = sha512.New()
+	default:
+		return nil, "", fmt.Errorf("Unsupported checksum algorithm %q", algorithm)
+	}
+
+	digest = strings.ToLower(digest)
+	decoded, err := hex.DecodeString(digest)
+	if err != nil || len(decoded) != hashFunc.Size() {
+		return nil, "", fmt.Errorf("Invalid %s checksum %q", algorithm, digest)
+	}
+
+	return hashFunc, digest, nil
+}
+
+// downloadVolumeURL downloads the content at the given URL into the target file.
+// Interrupted downloads are resumed using ranged requests when the remote server supports them.
+func downloadVolumeURL(httpClient *http.Client, canceler *cancel.HTTPRequestCanceller, sourceURL string, target *os.File, progress func(ioprogress.ProgressData)) (int64, error) {
+	var lastErr error
+	total := int64(-1)
+
+	for attempt := 0; attempt < volumeURLDownloadAttempts; attempt++ {
+		if attempt > 0 {
+			logger.Warn("Resuming interrupted volume download", logger.Ctx{"url": sourceURL, "attempt": attempt, "err": lastErr})
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+
+		offset, err := target.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1, err
+		}
+
+		if total >= 0 && offset == total {
+			return offset, nil
+		}
+
+		req, err := http.NewRequest("GET", sourceURL, nil)
+		if err != nil {
+			return -1, err
+		}
+
+		req.Header.Set("User-Agent", version.UserAgent)
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+
+		resp, doneCh, err := cancel.CancelableDownload(canceler, httpClient.Do, req)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return -1, err
+			}
+
+			lastErr = err
+			continue
+		}
+
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+			// Resuming from the current offset.
+		case http.StatusOK:
+			// The server doesn't support ranged requests, start over.
+			offset = 0
+			err = target.Truncate(0)
+			if err == nil {
+				_, err = target.Seek(0, io.SeekStart)
+			}
+
+			if err != nil {
+				_ = resp.Body.Close()
+				close(doneCh)
+				return -1, err
+			}
+
+		default:
+			_ = resp.Body.Close()
+			close(doneCh)
+			return -1, fmt.Errorf("Unable to fetch %q: %s", sourceURL, resp.Status)
+		}
+
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+
+		body := &ioprogress.ProgressReader{
+			ReadCloser: resp.Body,
+			Tracker: &ioprogress.ProgressTracker{
+				Length: resp.ContentLength,
+				Handler: func(percent int64, speed int64) {
+					if total > 0 {
+						percent = (offset + resp.ContentLength*percent/100) * 100 / total
+					}
+
+					progress(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
+				},
+			},
+		}
+
+		_, err = io.Copy(target, body)
+		_ = body.Close()
+		close(doneCh)
+		if err == nil {
+			return target.Seek(0, io.SeekEnd)
+		}
+
+		if errors.Is(err, context.Canceled) {
+			return -1, err
+		}
+
+		lastErr = err
+	}
+
+	return -1, fmt.Errorf("Failed downloading %q after %d attempts: %w", sourceURL, volumeURLDownloadAttempts, lastErr)
+}
+
+// createStoragePoolVolumeFromURL creates a custom ISO volume from content that the server downloads from a URL.
+func createStoragePoolVolumeFromURL(s *state.State, r *http.Request, requestProjectName string, projectName string, poolName string, req *api.StorageVolumesPost) response.Response {
+	if req.ContentType != cluster.StoragePoolVolumeContentTypeNameISO {
+		return response.BadRequest(fmt.Errorf("Only ISO volumes can be imported from a URL"))
+	}
+
+	sourceURL, err := url.Parse(req.Source.URL)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid source URL: %w", err))
+	}
+
+	if !shared.ValueInSlice(sourceURL.Scheme, []string{"http", "https"}) {
+		return response.BadRequest(fmt.Errorf("Source URL must use http or https"))
+	}
+
+	var hashFunc hash.Hash
+	var digest string
+	if req.Source.Checksum != "" {
+		hashFunc, digest, err = volumeURLChecksum(req.Source.Checksum)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
+	httpClient, err := util.HTTPClient("", s.Proxy)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	canceler := cancel.NewHTTPRequestCanceller()
+
+	run := func(op *operations.Operation) error {
+		// Create isos directory if needed.
+		if !shared.PathExists(shared.VarPath("isos")) {
+			err := os.MkdirAll(shared.VarPath("isos"), 0644)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Create temporary file to store the downloaded data.
+		isoFile, err := os.CreateTemp(shared.VarPath("isos"), fmt.Sprintf("%s_", "lxd_iso"))
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = isoFile.Close()
+			_ = os.Remove(isoFile.Name())
+		}()
+
+		progress := func(progress ioprogress.ProgressData) {
+			meta := op.Metadata()
+			if meta == nil {
+				meta = make(map[string]any)
+			}
+
+			if meta["download_progress"] != progress.Text {
+				meta["download_progress"] = progress.Text
+				_ = op.UpdateMetadata(meta)
+			}
+		}
+
+		size, err := downloadVolumeURL(httpClient, canceler, req.Source.URL, isoFile, progress)
+		if err != nil {
+			return err
+		}
+
+		// Verify the checksum over the whole file as the download may have been resumed.
+		if hashFunc != nil {
+			_, err = isoFile.Seek(0, io.SeekStart)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(hashFunc, isoFile)
+			if err != nil {
+				return err
+			}
+
+			result := fmt.Sprintf("%x", hashFunc.Sum(nil))
+			if result != digest {
+				return fmt.Errorf("Checksum mismatch for %q: %s != %s", req.Source.URL, result, digest)
+			}
+		}
+
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			return err
+		}
+
+		err = pool.CreateCustomVolumeFromISO(projectName, req.Name, isoFile, size, op)
+		if err != nil {
+			return fmt.Errorf("Failed creating custom volume from ISO: %w", err)
+		}
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_volumes"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "volumes", "custom", req.Name)}
+
+	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeCreate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	op.SetCanceler(canceler)
+
+	return operations.OperationResponse(op)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 )
 
 // CompareConfigs compares two config maps and returns an error if they differ.
@@ -60,3 +61,40 @@ func CopyConfig(config map[string]string) map[string]string {
 
 	return copy
 }
+
+// DiffConfigs returns the changes between two config maps, sorted by key.
+func DiffConfigs(oldConfig map[string]string, newConfig map[string]string) []api.ConfigDiffEntry {
+	changes := []api.ConfigDiffEntry{}
+
+	for key, oldValue := range oldConfig {
+		newValue, ok := newConfig[key]
+		if !ok {
+			changes = append(changes, api.ConfigDiffEntry{Key: key, Action: "removed", Old: oldValue})
+		} else if newValue != oldValue {
+			changes = append(changes, api.ConfigDiffEntry{Key: key, Action: "changed", Old: oldValue, New: newValue})
+		}
+	}
+
+	for key, newValue := range newConfig {
+		_, ok := oldConfig[key]
+		if !ok {
+			changes = append(changes, api.ConfigDiffEntry{Key: key, Action: "added", New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+
+	return changes
+}
+
+// FlattenDevices returns the devices as a config map with keys in the "devices.<name>.<key>" format.
+func FlattenDevices(devices map[string]map[string]string) map[string]string {
+	config := map[string]string{}
+	for name, device := range devices {
+		for key, value := range device {
+			config["devices."+name+"."+key] = value
+		}
+	}
+
+	return config
+}
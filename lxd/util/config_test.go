@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
 )
 
 func Test_CompareConfigsMismatch(t *testing.T) {
@@ -40,3 +41,28 @@ func Test_CompareConfigs(t *testing.T) {
 	err := util.CompareConfigs(config1, config2, []string{"foo"})
 	assert.NoError(t, err)
 }
+
+func Test_DiffConfigs(t *testing.T) {
+	oldConfig := map[string]string{"foo": "bar", "baz": "buz", "old": "value"}
+	newConfig := map[string]string{"foo": "egg", "baz": "buz", "new": "value"}
+
+	changes := util.DiffConfigs(oldConfig, newConfig)
+	assert.Equal(t, []api.ConfigDiffEntry{
+		{Key: "foo", Action: "changed", Old: "bar", New: "egg"},
+		{Key: "new", Action: "added", New: "value"},
+		{Key: "old", Action: "removed", Old: "value"},
+	}, changes)
+
+	assert.Empty(t, util.DiffConfigs(oldConfig, oldConfig))
+}
+
+func Test_FlattenDevices(t *testing.T) {
+	devices := map[string]map[string]string{
+		"eth0": {"type": "nic", "network": "lxdbr0"},
+	}
+
+	assert.Equal(t, map[string]string{
+		"devices.eth0.type":    "nic",
+		"devices.eth0.network": "lxdbr0",
+	}, util.FlattenDevices(devices))
+}
@@ -0,0 +1,129 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// SnapshotSchedulesPrefix is the config key prefix of the named snapshot schedules.
+const SnapshotSchedulesPrefix = "snapshots.schedules."
+
+// snapshotScheduleNameRegex matches the valid names of snapshot schedules.
+var snapshotScheduleNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// SnapshotSchedule is a named snapshot schedule with its own retention.
+type SnapshotSchedule struct {
+	Name     string
+	Schedule string
+	Expiry   string
+
+	// Keep is the number of snapshots of the schedule to keep, 0 keeps them all.
+	Keep int
+}
+
+// Pattern returns the name pattern of the snapshots taken by the schedule.
+func (s SnapshotSchedule) Pattern() string {
+	return s.Name + "-%d"
+}
+
+// Owns returns whether the snapshot with the given name was taken by the schedule.
+func (s SnapshotSchedule) Owns(snapshotName string) bool {
+	index, found := strings.CutPrefix(snapshotName, s.Name+"-")
+	if !found || index == "" {
+		return false
+	}
+
+	_, err := strconv.ParseUint(index, 10, 64)
+	return err == nil
+}
+
+// SnapshotScheduleConfigKey splits a named snapshot schedule config key into the schedule name and field.
+// Returns false if the key isn't a named snapshot schedule key.
+func SnapshotScheduleConfigKey(key string) (string, string, bool) {
+	rest, found := strings.CutPrefix(key, SnapshotSchedulesPrefix)
+	if !found {
+		return "", "", false
+	}
+
+	name, field, found := strings.Cut(rest, ".")
+	if !found {
+		return "", "", false
+	}
+
+	return name, field, true
+}
+
+// SnapshotScheduleKeyChecker returns the validator for a named snapshot schedule config key.
+// The aliases are the schedule aliases accepted in addition to cron expressions.
+func SnapshotScheduleKeyChecker(key string, aliases []string) (func(value string) error, error) {
+	name, field, ok := SnapshotScheduleConfigKey(key)
+	if !ok {
+		return nil, fmt.Errorf("Invalid snapshot schedule configuration key %q", key)
+	}
+
+	if !snapshotScheduleNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("Invalid snapshot schedule name %q: Name must be 1-63 characters long and only contain lowercase letters, numbers, dashes and underscores", name)
+	}
+
+	switch field {
+	case "schedule":
+		return validate.Optional(validate.IsCron(aliases)), nil
+	case "expiry":
+		return func(value string) error {
+			_, err := shared.GetExpiry(time.Time{}, value)
+			return err
+		}, nil
+	case "keep":
+		return validate.Optional(validate.IsUint32), nil
+	}
+
+	return nil, fmt.Errorf("Unknown snapshot schedule configuration key %q", key)
+}
+
+// SnapshotSchedules returns the named snapshot schedules defined in the config, sorted by name.
+// Schedules without a schedule expression are skipped.
+func SnapshotSchedules(config map[string]string) []SnapshotSchedule {
+	schedules := map[string]*SnapshotSchedule{}
+
+	for key, value := range config {
+		name, field, ok := SnapshotScheduleConfigKey(key)
+		if !ok {
+			continue
+		}
+
+		schedule, found := schedules[name]
+		if !found {
+			schedule = &SnapshotSchedule{Name: name}
+			schedules[name] = schedule
+		}
+
+		switch field {
+		case "schedule":
+			schedule.Schedule = value
+		case "expiry":
+			schedule.Expiry = value
+		case "keep":
+			schedule.Keep, _ = strconv.Atoi(value)
+		}
+	}
+
+	result := make([]SnapshotSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.Schedule == "" {
+			continue
+		}
+
+		result = append(result, *schedule)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result
+}
@@ -0,0 +1,68 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/util"
+)
+
+func Test_SnapshotSchedules(t *testing.T) {
+	config := map[string]string{
+		"snapshots.schedule":                  "@daily",
+		"snapshots.schedules.hourly.schedule": "@hourly",
+		"snapshots.schedules.hourly.keep":     "24",
+		"snapshots.schedules.daily.schedule":  "0 3 * * *",
+		"snapshots.schedules.daily.expiry":    "1w",
+		"snapshots.schedules.unused.keep":     "4",
+		"user.foo":                            "bar",
+	}
+
+	schedules := util.SnapshotSchedules(config)
+	assert.Equal(t, []util.SnapshotSchedule{
+		{Name: "daily", Schedule: "0 3 * * *", Expiry: "1w"},
+		{Name: "hourly", Schedule: "@hourly", Keep: 24},
+	}, schedules)
+}
+
+func Test_SnapshotScheduleOwns(t *testing.T) {
+	schedule := util.SnapshotSchedule{Name: "hourly"}
+
+	assert.Equal(t, "hourly-%d", schedule.Pattern())
+	assert.True(t, schedule.Owns("hourly-0"))
+	assert.True(t, schedule.Owns("hourly-12"))
+	assert.False(t, schedule.Owns("hourly-"))
+	assert.False(t, schedule.Owns("hourly-foo"))
+	assert.False(t, schedule.Owns("daily-1"))
+	assert.False(t, schedule.Owns("snap0"))
+}
+
+func Test_SnapshotScheduleKeyChecker(t *testing.T) {
+	aliases := []string{"@hourly", "@daily"}
+
+	validator, err := util.SnapshotScheduleKeyChecker("snapshots.schedules.hourly.schedule", aliases)
+	assert.NoError(t, err)
+	assert.NoError(t, validator("@hourly"))
+	assert.NoError(t, validator("0 * * * *"))
+	assert.Error(t, validator("@startup"))
+
+	validator, err = util.SnapshotScheduleKeyChecker("snapshots.schedules.hourly.keep", aliases)
+	assert.NoError(t, err)
+	assert.NoError(t, validator("24"))
+	assert.Error(t, validator("-1"))
+
+	validator, err = util.SnapshotScheduleKeyChecker("snapshots.schedules.hourly.expiry", aliases)
+	assert.NoError(t, err)
+	assert.NoError(t, validator("1d"))
+	assert.Error(t, validator("1x"))
+
+	_, err = util.SnapshotScheduleKeyChecker("snapshots.schedules.hourly.foo", aliases)
+	assert.Error(t, err)
+
+	_, err = util.SnapshotScheduleKeyChecker("snapshots.schedules.Hourly.schedule", aliases)
+	assert.Error(t, err)
+
+	_, err = util.SnapshotScheduleKeyChecker("snapshots.schedules.hourly", aliases)
+	assert.Error(t, err)
+}
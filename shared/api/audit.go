@@ -0,0 +1,48 @@
+package api
+
+import (
+	"time"
+)
+
+// AuditEntry represents a mutating API request recorded in the audit log.
+//
+// swagger:model
+//
+// API extension: audit_log.
+type AuditEntry struct {
+	// When the request was received
+	// Example: 2024-06-10T11:53:24.752398689Z
+	Date time.Time `json:"date" yaml:"date"`
+
+	// What cluster member handled the request
+	// Example: node1
+	Location string `json:"location" yaml:"location"`
+
+	// Identifier of the caller (certificate fingerprint, email address or bearer token identity)
+	// Example: jane.doe@example.com
+	Identity string `json:"identity" yaml:"identity"`
+
+	// Authentication method of the caller
+	// Example: oidc
+	AuthenticationMethod string `json:"authentication_method" yaml:"authentication_method"`
+
+	// The project of the request
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// HTTP method of the request
+	// Example: PUT
+	Method string `json:"method" yaml:"method"`
+
+	// The entity targeted by the request
+	// Example: /1.0/instances/c1?project=default
+	EntityURL string `json:"entity_url" yaml:"entity_url"`
+
+	// SHA-256 digest of the request body (empty if there was no body)
+	// Example: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+	BodyDigest string `json:"body_digest" yaml:"body_digest"`
+
+	// HTTP status code of the response
+	// Example: 200
+	StatusCode int `json:"status_code" yaml:"status_code"`
+}
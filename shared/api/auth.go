@@ -6,6 +6,22 @@ const (
 
 	// AuthenticationMethodOIDC is a token based authentication method.
 	AuthenticationMethodOIDC = "oidc"
+
+	// AuthenticationMethodBearer is a token based authentication method using bearer tokens issued by LXD.
+	//
+	// API extension: auth_bearer.
+	AuthenticationMethodBearer = "bearer"
+
+	// AuthenticationMethodDevLXD is a token based authentication method using instance scoped tokens issued over devlxd.
+	//
+	// API extension: devlxd_token.
+	AuthenticationMethodDevLXD = "devlxd"
+
+	// AuthenticationMethodInstanceAccess is a token based authentication method using temporary tokens granting
+	// exec or console access to a single instance.
+	//
+	// API extension: instance_access_tokens.
+	AuthenticationMethodInstanceAccess = "instance-access"
 )
 
 const (
@@ -26,6 +42,11 @@ const (
 
 	// IdentityTypeOIDCClient represents an identity that authenticates with OIDC.
 	IdentityTypeOIDCClient = "OIDC client"
+
+	// IdentityTypeBearerTokenClient represents a service account that authenticates with a bearer token issued by LXD.
+	//
+	// API extension: auth_bearer.
+	IdentityTypeBearerTokenClient = "Client token bearer"
 )
 
 // Identity is the type for an authenticated party that can make requests to the HTTPS API.
@@ -99,6 +120,32 @@ type IdentityPut struct {
 	Groups []string `json:"groups" yaml:"groups"`
 }
 
+// IdentitiesBearerPost contains the fields for creating a bearer token identity (service account).
+//
+// swagger:model
+//
+// API extension: auth_bearer.
+type IdentitiesBearerPost struct {
+	// Name is the name of the identity.
+	// Example: ci-runner
+	Name string `json:"name" yaml:"name"`
+
+	// Groups is the list of groups for which the identity is a member.
+	// Example: ["foo", "bar"]
+	Groups []string `json:"groups" yaml:"groups"`
+}
+
+// IdentityBearerToken contains a bearer token issued for an identity.
+//
+// swagger:model
+//
+// API extension: auth_bearer.
+type IdentityBearerToken struct {
+	// Token is the bearer token. It is only returned when issued and cannot be retrieved afterwards.
+	// Example: lxd-bearer-0ea0ff7c-6d2f-4d6a-8d7e-97b0bd1ac4c4.Dqf6DRq3wTSGAHCNxBdk1RBiU2IY8mVeMtglOq6kbS8
+	Token string `json:"token" yaml:"token"`
+}
+
 // AuthGroup is the type for a LXD group.
 //
 // swagger:model
@@ -123,6 +170,11 @@ type AuthGroup struct {
 	// includes this group.
 	// Example: ["sales", "operations"]
 	IdentityProviderGroups []string `json:"identity_provider_groups" yaml:"identity_provider_groups"`
+
+	// Roles are a list of roles assigned to the group.
+	//
+	// API extension: auth_roles.
+	Roles []AuthGroupRole `json:"roles" yaml:"roles"`
 }
 
 // Writable converts a AuthGroup struct into a AuthGroupPut struct (filters read-only fields).
@@ -130,6 +182,7 @@ func (g AuthGroup) Writable() AuthGroupPut {
 	return AuthGroupPut{
 		Description: g.Description,
 		Permissions: g.Permissions,
+		Roles:       g.Roles,
 	}
 }
 
@@ -137,6 +190,7 @@ func (g AuthGroup) Writable() AuthGroupPut {
 func (g *AuthGroup) SetWritable(put AuthGroupPut) {
 	g.Description = put.Description
 	g.Permissions = put.Permissions
+	g.Roles = put.Roles
 }
 
 // AuthGroupsPost is used for creating a new group.
@@ -172,6 +226,107 @@ type AuthGroupPut struct {
 
 	// Permissions are a list of permissions.
 	Permissions []Permission `json:"permissions" yaml:"permissions"`
+
+	// Roles are a list of roles assigned to the group.
+	//
+	// API extension: auth_roles.
+	Roles []AuthGroupRole `json:"roles" yaml:"roles"`
+}
+
+// AuthGroupRole represents a role that is assigned to a group on a specific entity.
+//
+// swagger:model
+//
+// API extension: auth_roles.
+type AuthGroupRole struct {
+	// Role is the name of the role.
+	// Example: instance-operator
+	Role string `json:"role" yaml:"role"`
+
+	// EntityReference is the URL of the entity that the role applies to.
+	// Example: /1.0/instances/c1?project=default
+	EntityReference string `json:"url" yaml:"url"`
+}
+
+// AuthRole is a named set of entitlements on an entity type, defined by an administrator.
+//
+// swagger:model
+//
+// API extension: auth_roles.
+type AuthRole struct {
+	// Name is the name of the role.
+	// Example: instance-operator
+	Name string `json:"name" yaml:"name"`
+
+	// Description is a short description of the role.
+	// Example: Operate instances without changing their configuration.
+	Description string `json:"description" yaml:"description"`
+
+	// EntityType is the type of entity the role can be assigned on.
+	// Example: instance
+	EntityType string `json:"entity_type" yaml:"entity_type"`
+
+	// Entitlements are the entitlements granted by the role.
+	// Example: ["can_view", "can_update_state", "can_exec"]
+	Entitlements []string `json:"entitlements" yaml:"entitlements"`
+
+	// Groups are the groups the role is assigned to.
+	// Example: ["operators"]
+	Groups []string `json:"groups" yaml:"groups"`
+}
+
+// Writable converts a AuthRole struct into a AuthRolePut struct (filters read-only fields).
+func (r AuthRole) Writable() AuthRolePut {
+	return AuthRolePut{
+		Description:  r.Description,
+		Entitlements: r.Entitlements,
+	}
+}
+
+// SetWritable sets applicable values from AuthRolePut struct to AuthRole struct.
+func (r *AuthRole) SetWritable(put AuthRolePut) {
+	r.Description = put.Description
+	r.Entitlements = put.Entitlements
+}
+
+// AuthRolesPost is used for creating a new role.
+//
+// swagger:model
+//
+// API extension: auth_roles.
+type AuthRolesPost struct {
+	AuthRolePost `yaml:",inline"`
+	AuthRolePut  `yaml:",inline"`
+
+	// EntityType is the type of entity the role can be assigned on.
+	// Example: instance
+	EntityType string `json:"entity_type" yaml:"entity_type"`
+}
+
+// AuthRolePost is used for renaming a role.
+//
+// swagger:model
+//
+// API extension: auth_roles.
+type AuthRolePost struct {
+	// Name is the name of the role.
+	// Example: instance-operator
+	Name string `json:"name" yaml:"name"`
+}
+
+// AuthRolePut contains the editable fields of a role.
+//
+// swagger:model
+//
+// API extension: auth_roles.
+type AuthRolePut struct {
+	// Description is a short description of the role.
+	// Example: Operate instances without changing their configuration.
+	Description string `json:"description" yaml:"description"`
+
+	// Entitlements are the entitlements granted by the role.
+	// Example: ["can_view", "can_update_state", "can_exec"]
+	Entitlements []string `json:"entitlements" yaml:"entitlements"`
 }
 
 // IdentityProviderGroup represents a mapping between LXD groups and groups defined by an identity provider.
@@ -221,6 +376,100 @@ type IdentityProviderGroupPut struct {
 	Groups []string `json:"groups" yaml:"groups"`
 }
 
+// IdentityProviderGroupRule maps OIDC clients to LXD groups based on the claims of their tokens.
+//
+// swagger:model
+//
+// API extension: identity_provider_group_rules.
+type IdentityProviderGroupRule struct {
+	// Name is the name of the rule.
+	// Example: engineering-teams
+	Name string `json:"name" yaml:"name"`
+
+	// Description is a short description of the rule.
+	// Example: Engineering teams get access to their team project.
+	Description string `json:"description" yaml:"description"`
+
+	// Conditions are the conditions on the token claims that must all be met for the rule to apply.
+	Conditions []IdentityProviderGroupRuleCondition `json:"conditions" yaml:"conditions"`
+
+	// Groups are the groups that the rule resolves to.
+	// Example: ["developers"]
+	Groups []string `json:"groups" yaml:"groups"`
+}
+
+// Writable converts a IdentityProviderGroupRule struct into a IdentityProviderGroupRulePut struct (filters read-only fields).
+func (r IdentityProviderGroupRule) Writable() IdentityProviderGroupRulePut {
+	return IdentityProviderGroupRulePut{
+		Description: r.Description,
+		Conditions:  r.Conditions,
+		Groups:      r.Groups,
+	}
+}
+
+// SetWritable sets applicable values from IdentityProviderGroupRulePut struct to IdentityProviderGroupRule struct.
+func (r *IdentityProviderGroupRule) SetWritable(put IdentityProviderGroupRulePut) {
+	r.Description = put.Description
+	r.Conditions = put.Conditions
+	r.Groups = put.Groups
+}
+
+// IdentityProviderGroupRuleCondition is a condition on a single claim of an OIDC token.
+//
+// swagger:model
+//
+// API extension: identity_provider_group_rules.
+type IdentityProviderGroupRuleCondition struct {
+	// Claim is the name of the claim. The claim configured with `oidc.groups.claim` can be matched to select
+	// identity provider groups.
+	// Example: groups
+	Claim string `json:"claim" yaml:"claim"`
+
+	// Pattern is matched against the value of the claim, or against each value if the claim is a list. The `*` and `?`
+	// wildcards match any sequence of characters and any single character respectively.
+	// Example: team-*
+	Pattern string `json:"pattern" yaml:"pattern"`
+}
+
+// IdentityProviderGroupRulesPost is used for creating a new identity provider group rule.
+//
+// swagger:model
+//
+// API extension: identity_provider_group_rules.
+type IdentityProviderGroupRulesPost struct {
+	IdentityProviderGroupRulePost `yaml:",inline"`
+	IdentityProviderGroupRulePut  `yaml:",inline"`
+}
+
+// IdentityProviderGroupRulePost is used for renaming an IdentityProviderGroupRule.
+//
+// swagger:model
+//
+// API extension: identity_provider_group_rules.
+type IdentityProviderGroupRulePost struct {
+	// Name is the name of the rule.
+	// Example: engineering-teams
+	Name string `json:"name" yaml:"name"`
+}
+
+// IdentityProviderGroupRulePut contains the editable fields of an IdentityProviderGroupRule.
+//
+// swagger:model
+//
+// API extension: identity_provider_group_rules.
+type IdentityProviderGroupRulePut struct {
+	// Description is a short description of the rule.
+	// Example: Engineering teams get access to their team project.
+	Description string `json:"description" yaml:"description"`
+
+	// Conditions are the conditions on the token claims that must all be met for the rule to apply.
+	Conditions []IdentityProviderGroupRuleCondition `json:"conditions" yaml:"conditions"`
+
+	// Groups are the groups that the rule resolves to.
+	// Example: ["developers"]
+	Groups []string `json:"groups" yaml:"groups"`
+}
+
 // Permission represents a permission that may be granted to a group.
 //
 // swagger:model
@@ -63,6 +63,18 @@ type CertificatesPost struct {
 	//
 	// API extension: certificate_token
 	Token bool `json:"token" yaml:"token"`
+
+	// Expiry date of the token (defaults to the server's core.remote_token_expiry)
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	//
+	// API extension: certificate_token_expiry
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+
+	// Certificate signing request to be signed by the built-in certificate authority, as PEM encoded PKCS #10 request
+	// Example: PEM certificate signing request
+	//
+	// API extension: certificate_authority
+	CertificateSigningRequest string `json:"certificate_signing_request" yaml:"certificate_signing_request"`
 }
 
 // CertificatePut represents the modifiable fields of a LXD certificate
@@ -96,6 +108,12 @@ type CertificatePut struct {
 	//
 	// API extension: certificate_self_renewal
 	Certificate string `json:"certificate" yaml:"certificate"`
+
+	// Certificate signing request to be signed by the built-in certificate authority, replacing the current certificate
+	// Example: PEM certificate signing request
+	//
+	// API extension: certificate_authority
+	CertificateSigningRequest string `json:"certificate_signing_request" yaml:"certificate_signing_request"`
 }
 
 // Certificate represents a LXD certificate
@@ -159,6 +177,40 @@ func (c *Certificate) URL(apiVersion string) *URL {
 	return NewURL().Path(apiVersion, "certificates", c.Fingerprint)
 }
 
+// CertificateIssued represents a client certificate issued by the built-in certificate authority
+//
+// swagger:model
+//
+// API extension: certificate_authority.
+type CertificateIssued struct {
+	// The issued certificate, as PEM encoded X509 certificate
+	// Example: X509 PEM certificate
+	Certificate string `json:"certificate" yaml:"certificate"`
+
+	// SHA256 fingerprint of the issued certificate
+	// Example: fd200419b271f1dc2a5591b693cc5774b7f234e1ff8c6b78ad703b6888fe2b69
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+
+	// Expiry date of the issued certificate
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
+// CertificateAuthority represents the built-in certificate authority
+//
+// swagger:model
+//
+// API extension: certificate_authority.
+type CertificateAuthority struct {
+	// The certificate of the authority, as PEM encoded X509 certificate
+	// Example: X509 PEM certificate
+	Certificate string `json:"certificate" yaml:"certificate"`
+
+	// The revocation list of the certificates issued by the authority, as PEM encoded X509 CRL
+	// Example: X509 PEM CRL
+	RevocationList string `json:"revocation_list" yaml:"revocation_list"`
+}
+
 // CertificateAddToken represents the fields contained within an encoded certificate add token.
 //
 // swagger:model
@@ -3,9 +3,15 @@ package api
 import (
 	"encoding/base64"
 	"encoding/json"
+	"net/url"
 	"time"
 )
 
+// ClusterMemberJoinTokenURLPrefix is the prefix of cluster member join tokens encoded as URLs.
+//
+// API extension: cluster_join_check.
+const ClusterMemberJoinTokenURLPrefix = "lxd://join?token="
+
 // Cluster represents high-level information about a LXD cluster.
 //
 // swagger:model
@@ -143,6 +149,36 @@ func (t *ClusterMemberJoinToken) String() string {
 	return base64.StdEncoding.EncodeToString(joinTokenJSON)
 }
 
+// URL encodes the cluster member join token as a URL, which is better suited for sharing
+// through links or QR codes.
+func (t *ClusterMemberJoinToken) URL() string {
+	return ClusterMemberJoinTokenURLPrefix + url.QueryEscape(t.String())
+}
+
+// ClusterMemberJoinCheckPost represents the configuration a new member intends to join the cluster with
+//
+// swagger:model
+//
+// API extension: cluster_join_check.
+type ClusterMemberJoinCheckPost struct {
+	// The name of the new cluster member
+	// Example: lxd02
+	ServerName string `json:"server_name" yaml:"server_name"`
+
+	// The member specific configuration keys of the storage pools and networks
+	MemberConfig []ClusterMemberConfigKey `json:"member_config" yaml:"member_config"`
+}
+
+// ClusterMemberJoinCheck represents the result of checking the configuration of a new member
+//
+// swagger:model
+//
+// API extension: cluster_join_check.
+type ClusterMemberJoinCheck struct {
+	// The member specific configuration keys that still need a value, along with a suggested value
+	MissingConfig []ClusterMemberConfigKey `json:"missing_config" yaml:"missing_config"`
+}
+
 // ClusterMemberPost represents the fields required to rename a LXD node.
 //
 // swagger:model
@@ -215,6 +251,18 @@ type ClusterMember struct {
 	//
 	// API extension: clustering_groups
 	Groups []string `json:"groups" yaml:"groups"`
+
+	// Database schema version of the LXD code running on the cluster member
+	// Example: 76
+	//
+	// API extension: clustering_rolling_upgrade
+	SchemaVersion int `json:"schema_version" yaml:"schema_version"`
+
+	// Number of API extensions of the LXD code running on the cluster member
+	// Example: 425
+	//
+	// API extension: clustering_rolling_upgrade
+	APIExtensions int `json:"api_extensions" yaml:"api_extensions"`
 }
 
 // Writable converts a full Profile struct into a ProfilePut struct (filters read-only fields).
@@ -286,7 +334,7 @@ type ClusterCertificatePut struct {
 //
 // API extension: clustering_evacuation.
 type ClusterMemberStatePost struct {
-	// The action to be performed. Valid actions are "evacuate" and "restore".
+	// The action to be performed. Valid actions are "evacuate", "upgrade" and "restore".
 	// Example: evacuate
 	Action string `json:"action" yaml:"action"`
 
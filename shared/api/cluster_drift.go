@@ -0,0 +1,16 @@
+package api
+
+// ClusterDrift represents a setting that differs between cluster members.
+//
+// swagger:model
+//
+// API extension: cluster_drift.
+type ClusterDrift struct {
+	// Name of the setting
+	// Example: driver.qemu
+	Setting string `json:"setting" yaml:"setting"`
+
+	// Value of the setting on each cluster member (empty if not set or not available)
+	// Example: {"server01": "8.2.2", "server02": "8.0.4"}
+	Values map[string]string `json:"values" yaml:"values"`
+}
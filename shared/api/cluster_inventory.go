@@ -0,0 +1,58 @@
+package api
+
+// ClusterInventory represents a page of the cluster inventory.
+//
+// swagger:model
+//
+// API extension: cluster_inventory.
+type ClusterInventory struct {
+	// Entities of the cluster in this page, ordered by URL
+	Entries []ClusterInventoryEntry `json:"entries" yaml:"entries"`
+
+	// Value to pass as the "after" parameter to get the next page (empty on the last page)
+	// Example: /1.0/instances/c1?project=default
+	Next string `json:"next" yaml:"next"`
+}
+
+// ClusterInventoryEntry represents an entity in the cluster inventory.
+//
+// swagger:model
+//
+// API extension: cluster_inventory.
+type ClusterInventoryEntry struct {
+	// Type of the entity
+	// Example: instance
+	EntityType string `json:"entity_type" yaml:"entity_type"`
+
+	// URL of the entity
+	// Example: /1.0/instances/c1?project=default
+	URL string `json:"url" yaml:"url"`
+
+	// Name of the entity
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Project of the entity (empty for entities that aren't project specific)
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Cluster member the entity is located on (empty for entities that aren't member specific)
+	// Example: server01
+	Location string `json:"location" yaml:"location"`
+
+	// Description of the entity
+	// Example: My test instance
+	Description string `json:"description" yaml:"description"`
+
+	// Status of the entity (if tracked in the database)
+	// Example: Online
+	Status string `json:"status" yaml:"status"`
+
+	// Configuration of the entity (volatile keys are left out)
+	// Example: {"limits.cpu": "2"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Properties specific to the type of entity
+	// Example: {"type": "container", "architecture": "x86_64"}
+	Properties map[string]string `json:"properties" yaml:"properties"`
+}
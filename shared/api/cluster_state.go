@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // ClusterMemberSysInfo represents the sysinfo of a cluster member.
 //
 // swagger:model
@@ -26,3 +30,72 @@ type ClusterMemberState struct {
 	SysInfo      ClusterMemberSysInfo        `json:"sysinfo" yaml:"sysinfo"`
 	StoragePools map[string]StoragePoolState `json:"storage_pools" yaml:"storage_pools"`
 }
+
+// ClusterState represents the health of the cluster database and of its members
+//
+// swagger:model
+//
+// API extension: cluster_state.
+type ClusterState struct {
+	// Address of the current database leader
+	// Example: 10.0.0.30:8443
+	LeaderAddress string `json:"leader_address" yaml:"leader_address"`
+
+	// Highest database schema version among the cluster members
+	// Example: 72
+	SchemaVersion int `json:"schema_version" yaml:"schema_version"`
+
+	// Highest number of API extensions among the cluster members
+	// Example: 400
+	APIExtensions int `json:"api_extensions" yaml:"api_extensions"`
+
+	// Health of each cluster member
+	Members []ClusterStateMember `json:"members" yaml:"members"`
+}
+
+// ClusterStateMember represents the health of a single cluster member
+//
+// swagger:model
+//
+// API extension: cluster_state.
+type ClusterStateMember struct {
+	// Name of the cluster member
+	// Example: lxd01
+	ServerName string `json:"server_name" yaml:"server_name"`
+
+	// Address of the cluster member
+	// Example: 10.0.0.30:8443
+	Address string `json:"address" yaml:"address"`
+
+	// Status of the cluster member
+	// Example: Online
+	Status string `json:"status" yaml:"status"`
+
+	// Additional status information
+	// Example: Fully operational
+	Message string `json:"message" yaml:"message"`
+
+	// Role of the member in the database cluster (voter, stand-by, spare or empty if not part of it)
+	// Example: voter
+	DatabaseRole string `json:"database_role" yaml:"database_role"`
+
+	// Whether the member is the database leader
+	// Example: true
+	DatabaseLeader bool `json:"database_leader" yaml:"database_leader"`
+
+	// When the member last responded to a heartbeat
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	LastHeartbeat time.Time `json:"last_heartbeat" yaml:"last_heartbeat"`
+
+	// Database schema version of the LXD code running on the member
+	// Example: 72
+	SchemaVersion int `json:"schema_version" yaml:"schema_version"`
+
+	// Number of API extensions of the LXD code running on the member
+	// Example: 400
+	APIExtensions int `json:"api_extensions" yaml:"api_extensions"`
+
+	// Whether the member runs an older version than the others and must be upgraded
+	// Example: false
+	PendingUpgrade bool `json:"pending_upgrade" yaml:"pending_upgrade"`
+}
@@ -0,0 +1,39 @@
+package api
+
+// ConfigDiffEntry represents a configuration key that would be changed by an update
+//
+// swagger:model
+//
+// API extension: config_dry_run.
+type ConfigDiffEntry struct {
+	// Configuration key (device keys are prefixed with "devices.<name>.")
+	// Example: limits.cpu
+	Key string `json:"key" yaml:"key"`
+
+	// Whether the key is added, changed or removed
+	// Example: changed
+	Action string `json:"action" yaml:"action"`
+
+	// Current value of the key (empty if the key is added)
+	// Example: 2
+	Old string `json:"old" yaml:"old"`
+
+	// New value of the key (empty if the key is removed)
+	// Example: 4
+	New string `json:"new" yaml:"new"`
+}
+
+// ConfigDiff represents the changes that would be made by an update performed as a dry run
+//
+// swagger:model
+//
+// API extension: config_dry_run.
+type ConfigDiff struct {
+	// Changes to the entity itself
+	Changes []ConfigDiffEntry `json:"changes" yaml:"changes"`
+
+	// Changes to the expanded configuration of the affected instances (by instance name)
+	// Instances of another project are prefixed with the project name and a slash.
+	// Example: {"c1": [{"key": "limits.cpu", "action": "changed", "old": "2", "new": "4"}]}
+	Instances map[string][]ConfigDiffEntry `json:"instances,omitempty" yaml:"instances,omitempty"`
+}
@@ -0,0 +1,100 @@
+package api
+
+// DeviceGroupsPost represents the fields of a new LXD device group
+//
+// swagger:model
+//
+// API extension: device_groups.
+type DeviceGroupsPost struct {
+	DeviceGroupPut `yaml:",inline"`
+
+	// The name of the new device group
+	// Example: gpu-workstation
+	Name string `json:"name" yaml:"name"`
+}
+
+// DeviceGroupPost represents the fields required to rename a LXD device group
+//
+// swagger:model
+//
+// API extension: device_groups.
+type DeviceGroupPost struct {
+	// The new name for the device group
+	// Example: gpu-desktop
+	Name string `json:"name" yaml:"name"`
+}
+
+// DeviceGroupPut represents the modifiable fields of a LXD device group
+//
+// swagger:model
+//
+// API extension: device_groups.
+type DeviceGroupPut struct {
+	// Description of the device group
+	// Example: GPU with USB controller and audio
+	Description string `json:"description" yaml:"description"`
+
+	// List of devices
+	// Example: {"gpu": {"type": "gpu", "pci": "0000:01:00.0"}, "usb": {"type": "pci", "address": "0000:00:14.0"}}
+	Devices map[string]map[string]string `json:"devices" yaml:"devices"`
+}
+
+// DeviceGroup represents a LXD device group
+//
+// swagger:model
+//
+// API extension: device_groups.
+type DeviceGroup struct {
+	// The device group name
+	// Read only: true
+	// Example: gpu-workstation
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the device group
+	// Example: GPU with USB controller and audio
+	Description string `json:"description" yaml:"description"`
+
+	// List of devices
+	// Example: {"gpu": {"type": "gpu", "pci": "0000:01:00.0"}, "usb": {"type": "pci", "address": "0000:00:14.0"}}
+	Devices map[string]map[string]string `json:"devices" yaml:"devices"`
+
+	// List of URLs of instances the device group is attached to
+	// Read only: true
+	// Example: ["/1.0/instances/desktop01"]
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+
+	// Project name
+	// Read only: true
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+}
+
+// Writable converts a full DeviceGroup struct into a DeviceGroupPut struct (filters read-only fields).
+func (group *DeviceGroup) Writable() DeviceGroupPut {
+	return DeviceGroupPut{
+		Description: group.Description,
+		Devices:     group.Devices,
+	}
+}
+
+// SetWritable sets applicable values from DeviceGroupPut struct to DeviceGroup struct.
+func (group *DeviceGroup) SetWritable(put DeviceGroupPut) {
+	group.Description = put.Description
+	group.Devices = put.Devices
+}
+
+// URL returns the URL for the device group.
+func (group *DeviceGroup) URL(apiVersion string, projectName string) *URL {
+	return NewURL().Path(apiVersion, "device-groups", group.Name).Project(projectName)
+}
+
+// InstanceDeviceGroupsPost represents the fields required to attach a device group to an instance
+//
+// swagger:model
+//
+// API extension: device_groups.
+type InstanceDeviceGroupsPost struct {
+	// Name of the device group to attach
+	// Example: gpu-workstation
+	Name string `json:"name" yaml:"name"`
+}
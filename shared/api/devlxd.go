@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // DevLXDPut represents the modifiable data.
 type DevLXDPut struct {
 	// Instance state
@@ -23,3 +27,16 @@ type DevLXDGet struct {
 	// Example: lxd01
 	Location string `json:"location" yaml:"location"`
 }
+
+// DevLXDToken represents an instance scoped token issued over devlxd.
+//
+// API extension: devlxd_token.
+type DevLXDToken struct {
+	// Token granting read-only access to the instance over the main API
+	// Example: lxd-devlxd-eyJwcm9qZWN0IjoiZGVmYXVsdCJ9.c2lnbmF0dXJl
+	Token string `json:"token" yaml:"token"`
+
+	// When the token expires
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
@@ -18,6 +18,10 @@ const (
 	EventLifecycleClusterMemberUpdated              = "cluster-member-updated"
 	EventLifecycleClusterTokenCreated               = "cluster-token-created"
 	EventLifecycleConfigUpdated                     = "config-updated"
+	EventLifecycleDeviceGroupCreated                = "device-group-created"
+	EventLifecycleDeviceGroupDeleted                = "device-group-deleted"
+	EventLifecycleDeviceGroupRenamed                = "device-group-renamed"
+	EventLifecycleDeviceGroupUpdated                = "device-group-updated"
 	EventLifecycleImageAliasCreated                 = "image-alias-created"
 	EventLifecycleImageAliasDeleted                 = "image-alias-deleted"
 	EventLifecycleImageAliasRenamed                 = "image-alias-renamed"
@@ -61,6 +65,7 @@ const (
 	EventLifecycleInstanceSnapshotUpdated           = "instance-snapshot-updated"
 	EventLifecycleInstanceStarted                   = "instance-started"
 	EventLifecycleInstanceStopped                   = "instance-stopped"
+	EventLifecycleInstanceTrimmed                   = "instance-trimmed"
 	EventLifecycleInstanceUpdated                   = "instance-updated"
 	EventLifecycleNetworkACLCreated                 = "network-acl-created"
 	EventLifecycleNetworkACLDeleted                 = "network-acl-deleted"
@@ -121,6 +126,7 @@ const (
 	EventLifecycleWarningReset                      = "warning-reset"
 	EventLifecycleIdentityCreated                   = "identity-created"
 	EventLifecycleIdentityUpdated                   = "identity-updated"
+	EventLifecycleIdentityDeleted                   = "identity-deleted"
 	EventLifecycleAuthGroupCreated                  = "auth-group-created"
 	EventLifecycleAuthGroupUpdated                  = "auth-group-updated"
 	EventLifecycleAuthGroupRenamed                  = "auth-group-renamed"
@@ -129,4 +135,12 @@ const (
 	EventLifecycleIdentityProviderGroupUpdated      = "identity-provider-group-updated"
 	EventLifecycleIdentityProviderGroupRenamed      = "identity-provider-group-renamed"
 	EventLifecycleIdentityProviderGroupDeleted      = "identity-provider-group-deleted"
+	EventLifecycleAuthRoleCreated                   = "auth-role-created"
+	EventLifecycleAuthRoleUpdated                   = "auth-role-updated"
+	EventLifecycleAuthRoleRenamed                   = "auth-role-renamed"
+	EventLifecycleAuthRoleDeleted                   = "auth-role-deleted"
+	EventLifecycleIdentityProviderGroupRuleCreated  = "identity-provider-group-rule-created"
+	EventLifecycleIdentityProviderGroupRuleUpdated  = "identity-provider-group-rule-updated"
+	EventLifecycleIdentityProviderGroupRuleRenamed  = "identity-provider-group-rule-renamed"
+	EventLifecycleIdentityProviderGroupRuleDeleted  = "identity-provider-group-rule-deleted"
 )
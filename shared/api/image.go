@@ -228,6 +228,29 @@ func (img *Image) URL(apiVersion string, project string) *URL {
 	return NewURL().Path(apiVersion, "images", img.Fingerprint).Project(project)
 }
 
+// ImageCacheExpiry represents an expired cached image that the next image cache expiry run deletes
+//
+// swagger:model
+//
+// API extension: images_remote_cache_expiry_preview.
+type ImageCacheExpiry struct {
+	// Image fingerprint
+	// Example: 06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+
+	// Project the cached image belongs to
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Last time the image was used
+	// Example: 2021-03-22T20:39:00.575185384-04:00
+	LastUsedAt time.Time `json:"last_used_at" yaml:"last_used_at"`
+
+	// When the image expired
+	// Example: 2021-04-01T20:39:00.575185384-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
 // ImageAlias represents an alias from the alias list of a LXD image
 //
 // swagger:model
@@ -364,3 +387,44 @@ type ImageMetadataTemplate struct {
 	// Example: {"foo": "bar"}
 	Properties map[string]string `json:"properties" yaml:"properties"`
 }
+
+// ImageSyncStatus represents the replication status of an image across the cluster members
+//
+// swagger:model
+//
+// API extension: image_sync_status.
+type ImageSyncStatus struct {
+	// Image fingerprint
+	// Example: 06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+
+	// Number of cluster members the image should be replicated on
+	// Example: 3
+	DesiredReplicas int `json:"desired_replicas" yaml:"desired_replicas"`
+
+	// Whether the image is replicated on enough online cluster members
+	// Example: false
+	Synced bool `json:"synced" yaml:"synced"`
+
+	// Replication status of the image on each cluster member
+	Members []ImageSyncMember `json:"members" yaml:"members"`
+}
+
+// ImageSyncMember represents the replication status of an image on a cluster member
+//
+// swagger:model
+//
+// API extension: image_sync_status.
+type ImageSyncMember struct {
+	// Name of the cluster member
+	// Example: lxd01
+	Name string `json:"name" yaml:"name"`
+
+	// Whether the cluster member has a copy of the image
+	// Example: true
+	Available bool `json:"available" yaml:"available"`
+
+	// Whether the cluster member is online
+	// Example: true
+	Online bool `json:"online" yaml:"online"`
+}
@@ -125,6 +125,18 @@ type InstancePost struct {
 	//
 	// API extension: instance_move_config
 	Profiles []string
+
+	// Maximum transfer rate of the migration data, in bytes per second (migration only)
+	// Example: 10MiB
+	//
+	// API extension: instance_migration_bandwidth_limit
+	BandwidthLimit string `json:"bandwidth_limit" yaml:"bandwidth_limit"`
+
+	// Whether to stop the instance and move it statelessly if its live migration keeps failing (cluster moves only)
+	// Example: false
+	//
+	// API extension: instance_move_stateless_fallback
+	StatelessFallback bool `json:"stateless_fallback" yaml:"stateless_fallback"`
 }
 
 // InstancePostTarget represents the migration target host and operation.
@@ -193,6 +205,12 @@ type InstancePut struct {
 type InstanceRebuildPost struct {
 	// Rebuild source
 	Source InstanceSource `json:"source" yaml:"source"`
+
+	// Directories of the root filesystem to preserve across the rebuild (containers only)
+	// Example: ["/var/lib/mysql"]
+	//
+	// API extension: instance_rebuild_preserve_paths
+	PreservePaths []string `json:"preserve_paths" yaml:"preserve_paths"`
 }
 
 // Instance represents a LXD instance.
@@ -418,6 +436,12 @@ type InstanceSource struct {
 	//
 	// API extension: instance_allow_inconsistent_copy
 	AllowInconsistent bool `json:"allow_inconsistent" yaml:"allow_inconsistent"`
+
+	// Size in bytes of the disk streamed over the operation websocket (for disk)
+	// Example: 10737418240
+	//
+	// API extension: instance_import_disk
+	DiskSize int64 `json:"disk_size,omitempty" yaml:"disk_size,omitempty"`
 }
 
 // InstanceUEFIVars represents the UEFI variables of a LXD virtual machine.
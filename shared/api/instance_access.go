@@ -0,0 +1,59 @@
+package api
+
+import (
+	"time"
+)
+
+const (
+	// InstanceAccessExec grants command execution in the instance.
+	//
+	// API extension: instance_access_tokens.
+	InstanceAccessExec = "exec"
+
+	// InstanceAccessConsole grants access to the console of the instance.
+	//
+	// API extension: instance_access_tokens.
+	InstanceAccessConsole = "console"
+)
+
+// InstanceAccessTokensPost represents the fields required to issue a temporary access token for an instance
+//
+// swagger:model
+//
+// API extension: instance_access_tokens.
+type InstanceAccessTokensPost struct {
+	// Kind of access granted by the token (exec or console)
+	// Example: console
+	Access string `json:"access" yaml:"access"`
+
+	// Whether the console can only be watched (console access only)
+	// Example: true
+	ReadOnly bool `json:"read_only" yaml:"read_only"`
+
+	// How long the token remains valid (Go duration, defaults to 1h, maximum 24h)
+	// Example: 30m
+	Expiry string `json:"expiry" yaml:"expiry"`
+}
+
+// InstanceAccessToken represents a temporary token granting exec or console access to a single instance
+//
+// swagger:model
+//
+// API extension: instance_access_tokens.
+type InstanceAccessToken struct {
+	// Token to use as a bearer token against the LXD API
+	// Example: lxd-access-eyJwcm9qZWN0IjoiZGVmYXVsdCJ9.c2lnbmF0dXJl
+	Token string `json:"token" yaml:"token"`
+
+	// Kind of access granted by the token (exec or console)
+	// Example: console
+	Access string `json:"access" yaml:"access"`
+
+	// Whether the console can only be watched (console access only)
+	// Example: true
+	ReadOnly bool `json:"read_only" yaml:"read_only"`
+
+	// When the token expires
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // InstanceConsoleControl represents a message on the instance console "control" socket.
 //
 // API extension: instances.
@@ -27,4 +31,39 @@ type InstanceConsolePost struct {
 	//
 	// API extension: console_vga_type
 	Type string `json:"type" yaml:"type"`
+
+	// Whether to disconnect the other clients attached to the console (console type only)
+	// Example: false
+	//
+	// API extension: console_multiplexing
+	Force bool `json:"force" yaml:"force"`
+
+	// Whether to only show the console output, ignoring any input (console type only)
+	// Example: false
+	//
+	// API extension: instance_access_tokens
+	ReadOnly bool `json:"read_only" yaml:"read_only"`
+}
+
+// InstanceConsoleSession represents a client attached to the text console of an instance
+//
+// swagger:model
+//
+// API extension: console_multiplexing.
+type InstanceConsoleSession struct {
+	// Session identifier
+	// Example: 1e4dc5a3-0de8-4b4d-89e5-7ad1b1e5b1f2
+	ID string `json:"id" yaml:"id"`
+
+	// Name of the user who attached to the console
+	// Example: foo
+	Username string `json:"username" yaml:"username"`
+
+	// Address the client attached from
+	// Example: 10.0.2.15
+	Address string `json:"address" yaml:"address"`
+
+	// When the client attached to the console
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
 }
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // InstanceStatePut represents the modifiable fields of a LXD instance's state.
 //
 // swagger:model
@@ -84,6 +88,12 @@ type InstanceStateCPU struct {
 	// CPU usage in nanoseconds
 	// Example: 3637691016
 	Usage int64 `json:"usage" yaml:"usage"`
+
+	// Core scheduling cookie of the instance (0 if not in a core scheduling domain)
+	// Example: 1872617283
+	//
+	// API extension: instance_core_scheduling
+	CoreSchedulingCookie uint64 `json:"core_scheduling_cookie" yaml:"core_scheduling_cookie"`
 }
 
 // InstanceStateMemory represents the memory information section of a LXD instance's state.
@@ -113,6 +123,34 @@ type InstanceStateMemory struct {
 	// Peak SWAP usage in bytes
 	// Example: 12297557
 	SwapUsagePeak int64 `json:"swap_usage_peak" yaml:"swap_usage_peak"`
+
+	// Memory balloon statistics (virtual machines only)
+	//
+	// API extension: instances_state_memory_balloon
+	Balloon *InstanceStateMemoryBalloon `json:"balloon,omitempty" yaml:"balloon,omitempty"`
+}
+
+// InstanceStateMemoryBalloon represents the memory balloon statistics of a LXD virtual machine.
+//
+// swagger:model
+//
+// API extension: instances_state_memory_balloon.
+type InstanceStateMemoryBalloon struct {
+	// Current memory size of the VM in bytes (excluding the memory held by the balloon)
+	// Example: 1073741824
+	Actual int64 `json:"actual" yaml:"actual"`
+
+	// Requested memory size of the VM in bytes
+	// Example: 1073741824
+	Target int64 `json:"target" yaml:"target"`
+
+	// Free memory as reported by the guest in bytes (-1 if not reported)
+	// Example: 536870912
+	GuestFree int64 `json:"guest_free" yaml:"guest_free"`
+
+	// Available memory as reported by the guest in bytes (-1 if not reported)
+	// Example: 805306368
+	GuestAvailable int64 `json:"guest_available" yaml:"guest_available"`
 }
 
 // InstanceStateNetwork represents the network information section of a LXD instance's state.
@@ -146,6 +184,39 @@ type InstanceStateNetwork struct {
 	// Type of interface (broadcast, loopback, point-to-point, ...)
 	// Example: broadcast
 	Type string `json:"type" yaml:"type"`
+
+	// Neighbour proxy announcements of the instance addresses on the parent network (when liveness checks are enabled)
+	//
+	// API extension: instance_nic_liveness
+	Announcements []InstanceStateNetworkAnnouncement `json:"announcements,omitempty" yaml:"announcements,omitempty"`
+}
+
+// InstanceStateNetworkAnnouncement represents the announcement of an instance address on the parent network
+// through a neighbour proxy (ARP/NDP) entry.
+//
+// swagger:model
+//
+// API extension: instance_nic_liveness.
+type InstanceStateNetworkAnnouncement struct {
+	// Instance address
+	// Example: 192.0.2.10
+	Address string `json:"address" yaml:"address"`
+
+	// Parent interface on which the address is announced
+	// Example: eth0
+	Parent string `json:"parent" yaml:"parent"`
+
+	// Whether the address is currently announced
+	// Example: true
+	Announced bool `json:"announced" yaml:"announced"`
+
+	// When the liveness of the address was last checked
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	LastChecked time.Time `json:"last_checked" yaml:"last_checked"`
+
+	// Why the address isn't announced (if it isn't)
+	// Example: Address not reachable
+	Reason string `json:"reason" yaml:"reason"`
 }
 
 // InstanceStateNetworkAddress represents a network address as part of the network section of a LXD
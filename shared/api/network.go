@@ -154,6 +154,41 @@ type NetworkLease struct {
 	Location string `json:"location" yaml:"location"`
 }
 
+// NetworkDNSRecord represents a DNS record served by LXD for an instance on a network
+//
+// swagger:model
+//
+// API extension: network_bridge_dns_records.
+type NetworkDNSRecord struct {
+	// The fully qualified name of the record
+	// Example: c1.lxd
+	Name string `json:"name" yaml:"name"`
+
+	// The type of record (A or AAAA)
+	// Example: A
+	Type string `json:"type" yaml:"type"`
+
+	// The IP address
+	// Example: 10.0.0.98
+	Address string `json:"address" yaml:"address"`
+
+	// The project of the instance the record belongs to
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// The name of the instance the record belongs to
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+
+	// The name of the instance NIC device the record belongs to
+	// Example: eth0
+	Device string `json:"device" yaml:"device"`
+
+	// What cluster member this record is served from
+	// Example: lxd01
+	Location string `json:"location" yaml:"location"`
+}
+
 // NetworkState represents the network state
 //
 // swagger:model
@@ -0,0 +1,24 @@
+package api
+
+// NetworkCapturePost represents the fields of a network traffic capture request
+//
+// swagger:model
+//
+// API extension: network_capture.
+type NetworkCapturePost struct {
+	// How long to capture the traffic for (defaults to 30s, at most 1h)
+	// Example: 30s
+	Duration string `json:"duration" yaml:"duration"`
+
+	// Maximum size of the capture (defaults to and at most 1GiB)
+	// Example: 100MiB
+	MaxSize string `json:"max_size" yaml:"max_size"`
+
+	// Name of the instance whose NIC should be captured instead of the whole network
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Name of the instance NIC device to capture
+	// Example: eth0
+	Device string `json:"device" yaml:"device"`
+}
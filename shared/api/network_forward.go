@@ -3,6 +3,7 @@ package api
 import (
 	"net"
 	"strings"
+	"time"
 )
 
 // NetworkForwardPort represents a port specification in a network address forward
@@ -66,6 +67,75 @@ type NetworkForwardPort struct {
 	// TargetAddress to forward ListenPorts to
 	// Example: 198.51.100.2
 	TargetAddress string `json:"target_address" yaml:"target_address"`
+
+	// lxdmeta:generate(entities=network-forward; group=port-properties; key=failover_address)
+	// Traffic is forwarded to this address while the health check of the `target_address` fails.
+	// Requires `health_check` to be set.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: IP address to fail over to
+
+	// FailoverAddress to forward ListenPorts to while TargetAddress is unhealthy
+	// Example: 198.51.100.3
+	//
+	// API extension: network_forward_health_check.
+	FailoverAddress string `json:"failover_address,omitempty" yaml:"failover_address,omitempty"`
+
+	// lxdmeta:generate(entities=network-forward; group=port-properties; key=health_check)
+	// See {ref}`network-forwards-health-checks`.
+	// ---
+	//  type: health check
+	//  required: no
+	//  shortdesc: Health check of the target address
+
+	// HealthCheck of the target address (optional)
+	//
+	// API extension: network_forward_health_check.
+	HealthCheck *NetworkForwardPortHealthCheck `json:"health_check,omitempty" yaml:"health_check,omitempty"`
+}
+
+// NetworkForwardPortHealthCheck represents the health check of the target address of a port specification
+//
+// swagger:model
+//
+// API extension: network_forward_health_check.
+type NetworkForwardPortHealthCheck struct {
+	// lxdmeta:generate(entities=network-forward; group=health-check-properties; key=protocol)
+	//  Possible values are `tcp` (connect to the port) and `icmp` (ping the address).
+	// ---
+	//  type: string
+	//  required: no
+	//  defaultdesc: `tcp`
+	//  shortdesc: Protocol used for the health check
+
+	// Protocol used to check the target address (either tcp or icmp)
+	// Example: tcp
+	Protocol string `json:"protocol" yaml:"protocol"`
+
+	// lxdmeta:generate(entities=network-forward; group=health-check-properties; key=port)
+	// Only used with the `tcp` protocol.
+	// ---
+	//  type: integer
+	//  required: no
+	//  defaultdesc: first target port (or first listen port)
+	//  shortdesc: Port used for the health check
+
+	// Port to check on the target address
+	// Example: 80
+	Port int `json:"port,omitempty" yaml:"port,omitempty"`
+
+	// lxdmeta:generate(entities=network-forward; group=health-check-properties; key=interval)
+	// Must be between 1 and 3600. `0` uses the default interval.
+	// ---
+	//  type: integer
+	//  required: no
+	//  defaultdesc: `10`
+	//  shortdesc: Interval between health checks (in seconds)
+
+	// Interval between checks (in seconds, 0 for the default)
+	// Example: 10
+	Interval int `json:"interval,omitempty" yaml:"interval,omitempty"`
 }
 
 // Normalise normalises the fields in the rule so that they are comparable with ones stored.
@@ -73,12 +143,22 @@ func (p *NetworkForwardPort) Normalise() {
 	p.Description = strings.TrimSpace(p.Description)
 	p.Protocol = strings.TrimSpace(p.Protocol)
 	p.TargetAddress = strings.TrimSpace(p.TargetAddress)
+	p.FailoverAddress = strings.TrimSpace(p.FailoverAddress)
 
 	ip := net.ParseIP(p.TargetAddress)
 	if ip != nil {
 		p.TargetAddress = ip.String() // Replace with canonical form if specified.
 	}
 
+	ip = net.ParseIP(p.FailoverAddress)
+	if ip != nil {
+		p.FailoverAddress = ip.String() // Replace with canonical form if specified.
+	}
+
+	if p.HealthCheck != nil {
+		p.HealthCheck.Protocol = strings.TrimSpace(p.HealthCheck.Protocol)
+	}
+
 	// Remove space from ListenPort list.
 	subjects := strings.Split(p.ListenPort, ",")
 	for i, s := range subjects {
@@ -232,3 +312,56 @@ func (f *NetworkForward) SetWritable(put NetworkForwardPut) {
 	f.Config = put.Config
 	f.Ports = put.Ports
 }
+
+// NetworkForwardPortStatusHealthy indicates that the target address of the port specification is healthy.
+const NetworkForwardPortStatusHealthy = "healthy"
+
+// NetworkForwardPortStatusFailover indicates that traffic is forwarded to the failover address.
+const NetworkForwardPortStatusFailover = "failover"
+
+// NetworkForwardPortStatusUnhealthy indicates that neither the target address nor the failover address is healthy.
+const NetworkForwardPortStatusUnhealthy = "unhealthy"
+
+// NetworkForwardPortStatusUnknown indicates that the port specification hasn't been checked (yet).
+const NetworkForwardPortStatusUnknown = "unknown"
+
+// NetworkForwardState is used for showing the current state of a network address forward
+//
+// swagger:model
+//
+// API extension: network_forward_health_check.
+type NetworkForwardState struct {
+	// State of the port specifications
+	Ports []NetworkForwardPortState `json:"ports" yaml:"ports"`
+}
+
+// NetworkForwardPortState represents the current state of a port specification
+//
+// swagger:model
+//
+// API extension: network_forward_health_check.
+type NetworkForwardPortState struct {
+	// Protocol of the port specification
+	// Example: tcp
+	Protocol string `json:"protocol" yaml:"protocol"`
+
+	// ListenPort(s) of the port specification
+	// Example: 80,81,8080-8090
+	ListenPort string `json:"listen_port" yaml:"listen_port"`
+
+	// Address the traffic is currently forwarded to
+	// Example: 198.51.100.2
+	ActiveAddress string `json:"active_address" yaml:"active_address"`
+
+	// Health status (healthy, failover, unhealthy or unknown)
+	// Example: healthy
+	Status string `json:"status" yaml:"status"`
+
+	// Time of the last health check
+	// Example: 2024-05-01T10:20:30Z
+	LastChecked time.Time `json:"last_checked" yaml:"last_checked"`
+
+	// Reason for the current status
+	// Example: Target address not reachable
+	Reason string `json:"reason" yaml:"reason"`
+}
@@ -125,12 +125,44 @@ type NetworkLoadBalancerPort struct {
 	// TargetBackend backend names to load balance ListenPorts to
 	// Example: ["c1-http","c2-http"]
 	TargetBackend []string `json:"target_backend" yaml:"target_backend"`
+
+	// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-port-properties; key=session_persistence)
+	// Possible values are `none` and `source_ip`.
+	// With `source_ip`, the connections of a client are sent to the same backend based on the client IP address.
+	// ---
+	//  type: string
+	//  required: no
+	//  defaultdesc: `none`
+	//  shortdesc: Session persistence mode
+
+	// Session persistence mode (none or source_ip)
+	// Example: source_ip
+	//
+	// API extension: network_load_balancer_session_persistence
+	SessionPersistence string `json:"session_persistence,omitempty" yaml:"session_persistence,omitempty"`
+
+	// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-port-properties; key=session_persistence_timeout)
+	// Only used with the `source_ip` session persistence mode.
+	// When set, a client keeps being sent to the same backend until it was idle for that many seconds, even if the list of backends changes.
+	// Otherwise, the backend of a client only depends on a hash of its IP address.
+	// ---
+	//  type: integer
+	//  required: no
+	//  defaultdesc: `0`
+	//  shortdesc: Session persistence timeout in seconds
+
+	// Session persistence timeout in seconds (for source_ip)
+	// Example: 3600
+	//
+	// API extension: network_load_balancer_session_persistence
+	SessionPersistenceTimeout int64 `json:"session_persistence_timeout,omitempty" yaml:"session_persistence_timeout,omitempty"`
 }
 
 // Normalise normalises the fields in the load balancer port so that they are comparable with ones stored.
 func (p *NetworkLoadBalancerPort) Normalise() {
 	p.Description = strings.TrimSpace(p.Description)
 	p.Protocol = strings.TrimSpace(p.Protocol)
+	p.SessionPersistence = strings.TrimSpace(p.SessionPersistence)
 
 	// Remove space from ListenPort list.
 	subjects := strings.Split(p.ListenPort, ",")
@@ -179,7 +211,7 @@ type NetworkLoadBalancerPut struct {
 	Description string `json:"description" yaml:"description"`
 
 	// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-properties; key=config)
-	// The only supported keys are `user.*` custom keys.
+	// The supported keys are `bgp.advertise`, `bgp.withdraw_on_backends_down` (see {ref}`network-load-balancers-bgp`) and `user.*` custom keys.
 	// ---
 	//  type: string set
 	//  required: no
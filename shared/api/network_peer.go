@@ -1,5 +1,15 @@
 package api
 
+// NetworkPeerTypeLocal is a peering with another network of the same LXD deployment.
+//
+// API extension: network_peer_ovn_interconnect.
+const NetworkPeerTypeLocal = "local"
+
+// NetworkPeerTypeRemote is a peering with a network of another LXD deployment through OVN interconnection.
+//
+// API extension: network_peer_ovn_interconnect.
+const NetworkPeerTypeRemote = "remote"
+
 // NetworkPeersPost represents the fields of a new LXD network peering
 //
 // swagger:model
@@ -40,6 +50,21 @@ type NetworkPeersPost struct {
 	// Name of the target network
 	// Example: network1
 	TargetNetwork string `json:"target_network" yaml:"target_network"`
+
+	// lxdmeta:generate(entities=network-peering; group=peering-properties; key=type)
+	// Either `local` (default) to peer with another network of this deployment, or `remote` to
+	// connect the network to a transit switch shared with another deployment through OVN interconnection.
+	// This option must be set at create time.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Type of network peering
+
+	// Type of peering (local or remote)
+	// Example: remote
+	//
+	// API extension: network_peer_ovn_interconnect
+	Type string `json:"type" yaml:"type"`
 }
 
 // NetworkPeerPut represents the modifiable fields of a LXD network peering
@@ -60,7 +85,8 @@ type NetworkPeerPut struct {
 	Description string `json:"description" yaml:"description"`
 
 	// lxdmeta:generate(entities=network-peering; group=peering-properties; key=config)
-	// The only supported keys are `user.*` custom keys.
+	// Local peerings only support `user.*` custom keys.
+	// Remote peerings additionally support the `ovn.ic.*` keys (see {ref}`network-ovn-peers-remote`).
 	// ---
 	//  type: string set
 	//  required: no
@@ -96,6 +122,13 @@ type NetworkPeer struct {
 	// Example: network1
 	TargetNetwork string `json:"target_network" yaml:"target_network"`
 
+	// Type of peering (local or remote)
+	// Read only: true
+	// Example: remote
+	//
+	// API extension: network_peer_ovn_interconnect
+	Type string `json:"type" yaml:"type"`
+
 	// lxdmeta:generate(entities=network-peering; group=peering-properties; key=status)
 	// Indicates if mutual peering exists with the target network.
 	// This property is read-only and cannot be updated.
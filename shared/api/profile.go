@@ -35,6 +35,12 @@ type ProfilePut struct {
 	// List of devices
 	// Example: {"root": {"type": "disk", "pool": "default", "path": "/"}, "eth0": {"type": "nic", "network": "lxdbr0", "name": "eth0"}}
 	Devices map[string]map[string]string `json:"devices" yaml:"devices"`
+
+	// List of profiles whose config and devices this profile is layered over (applied in order)
+	// Example: ["base", "gpu"]
+	//
+	// API extension: profile_inheritance
+	Inherits []string `json:"inherits" yaml:"inherits"`
 }
 
 // Profile represents a LXD profile
@@ -58,6 +64,26 @@ type Profile struct {
 	// Example: {"root": {"type": "disk", "pool": "default", "path": "/"}, "eth0": {"type": "nic", "network": "lxdbr0", "name": "eth0"}}
 	Devices map[string]map[string]string `json:"devices" yaml:"devices"`
 
+	// List of profiles whose config and devices this profile is layered over (applied in order)
+	// Example: ["base", "gpu"]
+	//
+	// API extension: profile_inheritance
+	Inherits []string `json:"inherits" yaml:"inherits"`
+
+	// Instance configuration map including the config of the inherited profiles (only returned with recursion)
+	// Read only: true
+	// Example: {"limits.cpu": "4", "limits.memory": "4GiB", "security.nesting": "true"}
+	//
+	// API extension: profile_inheritance
+	ExpandedConfig map[string]string `json:"expanded_config,omitempty" yaml:"expanded_config,omitempty"`
+
+	// List of devices including the devices of the inherited profiles (only returned with recursion)
+	// Read only: true
+	// Example: {"root": {"type": "disk", "pool": "default", "path": "/"}, "gpu": {"type": "gpu"}}
+	//
+	// API extension: profile_inheritance
+	ExpandedDevices map[string]map[string]string `json:"expanded_devices,omitempty" yaml:"expanded_devices,omitempty"`
+
 	// List of URLs of objects using this profile
 	// Read only: true
 	// Example: ["/1.0/instances/c1", "/1.0/instances/v1"]
@@ -72,6 +98,7 @@ func (profile *Profile) Writable() ProfilePut {
 		Description: profile.Description,
 		Config:      profile.Config,
 		Devices:     profile.Devices,
+		Inherits:    profile.Inherits,
 	}
 }
 
@@ -80,6 +107,25 @@ func (profile *Profile) SetWritable(put ProfilePut) {
 	profile.Description = put.Description
 	profile.Config = put.Config
 	profile.Devices = put.Devices
+	profile.Inherits = put.Inherits
+}
+
+// EffectiveConfig returns the expanded config of the profile if it inherits from other profiles, or its own config.
+func (profile *Profile) EffectiveConfig() map[string]string {
+	if profile.ExpandedConfig != nil {
+		return profile.ExpandedConfig
+	}
+
+	return profile.Config
+}
+
+// EffectiveDevices returns the expanded devices of the profile if it inherits from other profiles, or its own devices.
+func (profile *Profile) EffectiveDevices() map[string]map[string]string {
+	if profile.ExpandedDevices != nil {
+		return profile.ExpandedDevices
+	}
+
+	return profile.Devices
 }
 
 // URL returns the URL for the profile.
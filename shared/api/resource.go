@@ -223,6 +223,12 @@ type ResourcesGPUCard struct {
 	// Example: 0000:00:02.0
 	PCIAddress string `json:"pci_address,omitempty" yaml:"pci_address,omitempty"`
 
+	// PCIe link of the device
+	// Example: {"current_speed": 8, "current_width": 16, "maximum_speed": 16, "maximum_width": 16}
+	//
+	// API extension: resources_pci_link
+	Link *ResourcesPCILink `json:"link,omitempty" yaml:"link,omitempty"`
+
 	// Name of the vendor
 	// Example: Intel Corporation
 	Vendor string `json:"vendor,omitempty" yaml:"vendor,omitempty"`
@@ -295,9 +301,21 @@ type ResourcesGPUCardSRIOV struct {
 	// Example: 0
 	MaximumVFs uint64 `json:"maximum_vfs" yaml:"maximum_vfs"`
 
+	// Number of configured VFs that aren't in use by an instance
+	// Example: 0
+	//
+	// API extension: resources_pci_link
+	AvailableVFs uint64 `json:"available_vfs" yaml:"available_vfs"`
+
 	// List of VFs (as additional GPU devices)
 	// Example: null
 	VFs []ResourcesGPUCard `json:"vfs" yaml:"vfs"`
+
+	// Instances the VFs are allocated to (VF PCI address to instance URL)
+	// Example: {"0000:03:00.1": "/1.0/instances/v1?project=default"}
+	//
+	// API extension: resources_gpu_vf_allocations
+	Allocations map[string]string `json:"allocations,omitempty" yaml:"allocations,omitempty"`
 }
 
 // ResourcesGPUCardNvidia represents additional information for NVIDIA GPUs
@@ -368,6 +386,12 @@ type ResourcesGPUCardMdev struct {
 	// List of active devices (UUIDs)
 	// Example: ["42200aac-0977-495c-8c9e-6c51b9092a01", "b4950c00-1437-41d9-88f6-28d61cf9b9ef"]
 	Devices []string `json:"devices" yaml:"devices"`
+
+	// Instances the active devices are allocated to (device UUID to instance URL)
+	// Example: {"42200aac-0977-495c-8c9e-6c51b9092a01": "/1.0/instances/v1?project=default"}
+	//
+	// API extension: gpu_mdev_allocations
+	Allocations map[string]string `json:"allocations,omitempty" yaml:"allocations,omitempty"`
 }
 
 // ResourcesNetwork represents the network cards available on the system
@@ -417,6 +441,12 @@ type ResourcesNetworkCard struct {
 	// Example: 0000:0d:00.0
 	PCIAddress string `json:"pci_address,omitempty" yaml:"pci_address,omitempty"`
 
+	// PCIe link of the device
+	// Example: {"current_speed": 8, "current_width": 16, "maximum_speed": 16, "maximum_width": 16}
+	//
+	// API extension: resources_pci_link
+	Link *ResourcesPCILink `json:"link,omitempty" yaml:"link,omitempty"`
+
 	// Name of the vendor
 	// Example: Aquantia Corp.
 	Vendor string `json:"vendor,omitempty" yaml:"vendor,omitempty"`
@@ -551,6 +581,12 @@ type ResourcesNetworkCardSRIOV struct {
 	// Example: 0
 	MaximumVFs uint64 `json:"maximum_vfs" yaml:"maximum_vfs"`
 
+	// Number of configured VFs that aren't in use by an instance
+	// Example: 0
+	//
+	// API extension: resources_pci_link
+	AvailableVFs uint64 `json:"available_vfs" yaml:"available_vfs"`
+
 	// List of VFs (as additional Network devices)
 	// Example: null
 	VFs []ResourcesNetworkCard `json:"vfs" yaml:"vfs"`
@@ -944,6 +980,12 @@ type ResourcesPCIDevice struct {
 	// Example: 0000:07:03.0
 	PCIAddress string `json:"pci_address" yaml:"pci_address"`
 
+	// PCIe link of the device
+	// Example: {"current_speed": 8, "current_width": 16, "maximum_speed": 16, "maximum_width": 16}
+	//
+	// API extension: resources_pci_link
+	Link *ResourcesPCILink `json:"link,omitempty" yaml:"link,omitempty"`
+
 	// Name of the vendor
 	// Example: Matrox Electronics Systems Ltd.
 	Vendor string `json:"vendor" yaml:"vendor"`
@@ -973,6 +1015,29 @@ type ResourcesPCIDevice struct {
 	VPD ResourcesPCIVPD `json:"vpd" yaml:"vpd"`
 }
 
+// ResourcesPCILink represents the PCIe link of a PCI device
+//
+// swagger:model
+//
+// API extension: resources_pci_link.
+type ResourcesPCILink struct {
+	// Current link speed (in GT/s)
+	// Example: 8
+	CurrentSpeed float64 `json:"current_speed" yaml:"current_speed"`
+
+	// Current link width (number of lanes)
+	// Example: 16
+	CurrentWidth uint64 `json:"current_width" yaml:"current_width"`
+
+	// Maximum link speed (in GT/s)
+	// Example: 16
+	MaximumSpeed float64 `json:"maximum_speed" yaml:"maximum_speed"`
+
+	// Maximum link width (number of lanes)
+	// Example: 16
+	MaximumWidth uint64 `json:"maximum_width" yaml:"maximum_width"`
+}
+
 // ResourcesPCIVPD represents VPD entries for a device
 //
 // swagger:model
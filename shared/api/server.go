@@ -196,6 +196,13 @@ type ServerUntrusted struct {
 	//
 	// API extension: oidc
 	AuthMethods []string `json:"auth_methods" yaml:"auth_methods"`
+
+	// Whether clients can get a certificate issued by the built-in certificate authority
+	// Read only: true
+	// Example: false
+	//
+	// API extension: certificate_authority
+	CertificateEnrollment bool `json:"certificate_enrollment" yaml:"certificate_enrollment"`
 }
 
 // Server represents a LXD server
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"time"
+)
+
+// ServerStartupStatusInitializing indicates that a subsystem is still being initialized.
+const ServerStartupStatusInitializing = "initializing"
+
+// ServerStartupStatusReady indicates that a subsystem was initialized successfully.
+const ServerStartupStatusReady = "ready"
+
+// ServerStartupStatusFailed indicates that a subsystem failed to initialize.
+const ServerStartupStatusFailed = "failed"
+
+// ServerStartup represents the startup progress of the LXD daemon.
+//
+// swagger:model
+//
+// API extension: server_startup.
+type ServerStartup struct {
+	// When the daemon started
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+
+	// Whether all subsystems are initialized
+	// Example: true
+	Ready bool `json:"ready" yaml:"ready"`
+
+	// Subsystems in the order their initialization started
+	Subsystems []ServerStartupSubsystem `json:"subsystems" yaml:"subsystems"`
+}
+
+// ServerStartupSubsystem represents the initialization state and timing of a daemon subsystem.
+//
+// swagger:model
+//
+// API extension: server_startup.
+type ServerStartupSubsystem struct {
+	// Name of the subsystem
+	// Example: storage
+	Name string `json:"name" yaml:"name"`
+
+	// Initialization status (initializing, ready or failed)
+	// Example: ready
+	Status string `json:"status" yaml:"status"`
+
+	// Whether the subsystem is initialized in the background after the API is available
+	// Example: false
+	Background bool `json:"background" yaml:"background"`
+
+	// When the initialization of the subsystem started
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+
+	// Time spent initializing the subsystem so far (in milliseconds)
+	// Example: 1250
+	Duration int64 `json:"duration" yaml:"duration"`
+
+	// Initialization error (if any)
+	// Example: Failed to mount storage pool
+	Error string `json:"error" yaml:"error"`
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"time"
+)
+
+// StoragePoolBenchmarksPost represents the fields of a new storage pool benchmark
+//
+// swagger:model
+//
+// API extension: storage_pool_benchmark.
+type StoragePoolBenchmarksPost struct {
+	// Size of the test file written to the temporary volume (defaults to 256MiB, at most 16GiB)
+	// Example: 1GiB
+	Size string `json:"size" yaml:"size"`
+}
+
+// StoragePoolBenchmark represents the result of a storage pool benchmark
+//
+// swagger:model
+//
+// API extension: storage_pool_benchmark.
+type StoragePoolBenchmark struct {
+	// Benchmark identifier
+	// Example: 1
+	ID int64 `json:"id" yaml:"id"`
+
+	// Cluster member the benchmark ran on
+	// Example: lxd01
+	Location string `json:"location" yaml:"location"`
+
+	// When the benchmark was run
+	// Example: 2021-03-23T20:00:00-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// Size of the test file in bytes
+	// Example: 268435456
+	Size int64 `json:"size" yaml:"size"`
+
+	// Sequential read throughput in bytes per second
+	// Example: 524288000
+	SequentialRead int64 `json:"sequential_read" yaml:"sequential_read"`
+
+	// Sequential write throughput in bytes per second
+	// Example: 419430400
+	SequentialWrite int64 `json:"sequential_write" yaml:"sequential_write"`
+
+	// Random 4KiB reads per second
+	// Example: 12000
+	RandomReadIOPS int64 `json:"random_read_iops" yaml:"random_read_iops"`
+
+	// Random 4KiB synchronous writes per second
+	// Example: 3500
+	RandomWriteIOPS int64 `json:"random_write_iops" yaml:"random_write_iops"`
+
+	// Average latency of random reads in microseconds
+	// Example: 83
+	RandomReadLatency int64 `json:"random_read_latency" yaml:"random_read_latency"`
+
+	// Average latency of random synchronous writes in microseconds
+	// Example: 285
+	RandomWriteLatency int64 `json:"random_write_latency" yaml:"random_write_latency"`
+}
@@ -0,0 +1,39 @@
+package api
+
+// StoragePoolMigratePost represents the fields required to migrate the volumes of a storage pool to another one
+//
+// swagger:model
+//
+// API extension: storage_pool_migrate.
+type StoragePoolMigratePost struct {
+	// Name of the storage pool to move the volumes to
+	// Example: local
+	Pool string `json:"pool" yaml:"pool"`
+
+	// Only list the volumes that would be moved without moving them
+	// Example: false
+	DryRun bool `json:"dry_run" yaml:"dry_run"`
+}
+
+// StoragePoolMigrateVolume represents a storage volume moved by a storage pool migration
+//
+// swagger:model
+//
+// API extension: storage_pool_migrate.
+type StoragePoolMigrateVolume struct {
+	// Volume name
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Volume type (container, virtual-machine or custom)
+	// Example: container
+	Type string `json:"type" yaml:"type"`
+
+	// Project the volume belongs to
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Number of snapshots moved along with the volume
+	// Example: 2
+	Snapshots int `json:"snapshots" yaml:"snapshots"`
+}
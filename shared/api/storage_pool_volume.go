@@ -201,7 +201,7 @@ type StorageVolumeSource struct {
 	// Example: foo
 	Name string `json:"name" yaml:"name"`
 
-	// Source type (copy or migration)
+	// Source type (copy, migration or url)
 	// Example: copy
 	Type string `json:"type" yaml:"type"`
 
@@ -256,6 +256,18 @@ type StorageVolumeSource struct {
 	//
 	// API extension: cluster_internal_custom_volume_copy
 	Location string `json:"location" yaml:"location"`
+
+	// URL to download the volume content from (for url)
+	// Example: https://example.com/disk.img
+	//
+	// API extension: storage_volume_import_url
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Expected checksum of the downloaded content, prefixed with the hash algorithm (for url)
+	// Example: sha256:50d7a5b7bd7ec3a4ff0bd6c1f36a4c6c2f0a1c8e9b4b7d5e6f1a2b3c4d5e6f7a
+	//
+	// API extension: storage_volume_import_url
+	Checksum string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
 }
 
 // Writable converts a full StorageVolume struct into a StorageVolumePut struct (filters read-only fields).
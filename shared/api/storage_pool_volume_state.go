@@ -8,6 +8,11 @@ package api
 type StorageVolumeState struct {
 	// Volume usage
 	Usage *StorageVolumeStateUsage `json:"usage" yaml:"usage"`
+
+	// I/O counters for each running instance the volume is attached to
+	//
+	// API extension: storage_volume_state_io
+	IO []StorageVolumeStateIO `json:"io,omitempty" yaml:"io,omitempty"`
 }
 
 // StorageVolumeStateUsage represents the disk usage of a volume
@@ -26,3 +31,46 @@ type StorageVolumeStateUsage struct {
 	// API extension: storage_volume_state_total
 	Total int64 `json:"total" yaml:"total"`
 }
+
+// StorageVolumeStateIO represents the I/O counters of a volume for one of the instances it is attached to
+//
+// swagger:model
+//
+// API extension: storage_volume_state_io.
+type StorageVolumeStateIO struct {
+	// Name of the instance
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Name of the disk device the volume is attached through
+	// Example: root
+	Device string `json:"device" yaml:"device"`
+
+	// Number of bytes read
+	// Example: 1693552640
+	ReadBytes uint64 `json:"read_bytes" yaml:"read_bytes"`
+
+	// Number of completed read operations
+	// Example: 24531
+	ReadsCompleted uint64 `json:"reads_completed" yaml:"reads_completed"`
+
+	// Total time spent on read operations in nanoseconds (only available for virtual machines)
+	// Example: 8451932455
+	ReadTimeNs uint64 `json:"read_time_ns,omitempty" yaml:"read_time_ns,omitempty"`
+
+	// Number of bytes written
+	// Example: 524288000
+	WrittenBytes uint64 `json:"written_bytes" yaml:"written_bytes"`
+
+	// Number of completed write operations
+	// Example: 8123
+	WritesCompleted uint64 `json:"writes_completed" yaml:"writes_completed"`
+
+	// Total time spent on write operations in nanoseconds (only available for virtual machines)
+	// Example: 3214897122
+	WriteTimeNs uint64 `json:"write_time_ns,omitempty" yaml:"write_time_ns,omitempty"`
+}
@@ -337,27 +337,11 @@ func GenerateMemCert(client bool, addHosts bool) ([]byte, []byte, error) {
 		return nil, nil, fmt.Errorf("Failed to generate serial number: %w", err)
 	}
 
-	userEntry, err := user.Current()
-	var username string
-	if err == nil {
-		username = userEntry.Username
-		if username == "" {
-			username = "UNKNOWN"
-		}
-	} else {
-		username = "UNKNOWN"
-	}
-
-	hostname, err := os.Hostname()
-	if err != nil {
-		hostname = "UNKNOWN"
-	}
-
 	template := x509.Certificate{
 		SerialNumber: serialNumber,
 		Subject: pkix.Name{
 			Organization: []string{"LXD"},
-			CommonName:   fmt.Sprintf("%s@%s", username, hostname),
+			CommonName:   certCommonName(),
 		},
 		NotBefore: validFrom,
 		NotAfter:  validTo,
@@ -408,6 +392,58 @@ func GenerateMemCert(client bool, addHosts bool) ([]byte, []byte, error) {
 	return cert, key, nil
 }
 
+// GenerateMemCertRequest creates a client certificate signing request and private key,
+// returning them as PEM encoded byte arrays in memory.
+func GenerateMemCertRequest() ([]byte, []byte, error) {
+	privk, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to generate key: %w", err)
+	}
+
+	template := x509.CertificateRequest{
+		Subject: pkix.Name{
+			Organization: []string{"LXD"},
+			CommonName:   certCommonName(),
+		},
+	}
+
+	derBytes, err := x509.CreateCertificateRequest(rand.Reader, &template, privk)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create certificate request: %w", err)
+	}
+
+	data, err := x509.MarshalECPrivateKey(privk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	csr := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: derBytes})
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: data})
+
+	return csr, key, nil
+}
+
+// certCommonName returns the common name of the generated certificates, in the form "<user>@<host>".
+func certCommonName() string {
+	userEntry, err := user.Current()
+	var username string
+	if err == nil {
+		username = userEntry.Username
+		if username == "" {
+			username = "UNKNOWN"
+		}
+	} else {
+		username = "UNKNOWN"
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "UNKNOWN"
+	}
+
+	return fmt.Sprintf("%s@%s", username, hostname)
+}
+
 // ReadCert reads a X.509 certificate from the filesystem, do PEM decoding and return its parsed content.
 func ReadCert(fpath string) (*x509.Certificate, error) {
 	cf, err := os.ReadFile(fpath)
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
 
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/yaml.v2"
@@ -69,6 +72,69 @@ func RenderTable(format string, header []string, data [][]string, raw any) error
 	return nil
 }
 
+// RenderInfo renders a single object in various formats. The human readable table format is rendered by the given
+// function, while the csv format contains a row for each field of the object, identified by its dotted JSON path.
+func RenderInfo(format string, raw any, renderTable func() error) error {
+	switch format {
+	case TableFormatTable:
+		return renderTable()
+	case TableFormatJSON, TableFormatYAML:
+		return RenderTable(format, nil, nil, raw)
+	case TableFormatCSV:
+		// Convert the object to its generic JSON representation to get the field names.
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return err
+		}
+
+		// Keep the numbers as they were encoded rather than converting them to floats.
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		var value any
+		err = decoder.Decode(&value)
+		if err != nil {
+			return err
+		}
+
+		rows := [][]string{}
+		flattenInfo("", value, &rows)
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+
+		return RenderTable(format, nil, rows, raw)
+	default:
+		return fmt.Errorf(i18n.G("Invalid format %q"), format)
+	}
+}
+
+// flattenInfo appends a key/value row for each scalar value of a generic JSON value.
+func flattenInfo(prefix string, value any, rows *[][]string) {
+	key := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+
+		return prefix + "." + name
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for name, field := range v {
+			flattenInfo(key(name), field, rows)
+		}
+
+	case []any:
+		for i, field := range v {
+			flattenInfo(key(strconv.Itoa(i)), field, rows)
+		}
+
+	case nil:
+		*rows = append(*rows, []string{prefix, ""})
+	default:
+		*rows = append(*rows, []string{prefix, fmt.Sprint(v)})
+	}
+}
+
 func getBaseTable(header []string, data [][]string) *tablewriter.Table {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAutoWrapText(false)
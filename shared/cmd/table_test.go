@@ -194,3 +194,84 @@ foo,1,/1.0/instances/foo
 		s.Equal(test.expect, output)
 	}
 }
+
+func (s *tableSuite) TestRenderInfo() {
+	type TestDataType struct {
+		SomeString  string            `json:"some_string" yaml:"some_string"`
+		SomeInteger int64             `json:"some_integer" yaml:"some_integer"`
+		SomeMap     map[string]string `json:"some_map" yaml:"some_map"`
+		SomeSlice   []string          `json:"some_slice" yaml:"some_slice"`
+	}
+
+	data := TestDataType{
+		SomeString:  "foo",
+		SomeInteger: 1693552640,
+		SomeMap:     map[string]string{"b": "2", "a": "1"},
+		SomeSlice:   []string{"x", "y"},
+	}
+
+	tests := []struct {
+		name      string
+		format    string
+		expect    string
+		expectErr error
+	}{
+		{
+			name:      "Invalid format",
+			format:    TableFormatCompact,
+			expectErr: fmt.Errorf("Invalid format \"compact\""),
+		},
+		{
+			name:   "table",
+			format: TableFormatTable,
+			expect: "Some string: foo\n",
+		},
+		{
+			name:   "json",
+			format: TableFormatJSON,
+			expect: `{"some_string":"foo","some_integer":1693552640,"some_map":{"a":"1","b":"2"},"some_slice":["x","y"]}` + "\n",
+		},
+		{
+			name:   "csv",
+			format: TableFormatCSV,
+			expect: `some_integer,1693552640
+some_map.a,1
+some_map.b,2
+some_slice.0,x
+some_slice.1,y
+some_string,foo
+`,
+		},
+	}
+
+	for i, test := range tests {
+		s.T().Logf("Test %d: %s", i, test.name)
+
+		// Set up a pipe to read from stdout.
+		stdout := os.Stdout
+		r, w, err := os.Pipe()
+		s.Require().NoError(err)
+		os.Stdout = w
+
+		// Call method but fix stdout before making any assertions.
+		actualErr := RenderInfo(test.format, data, func() error {
+			fmt.Printf("Some string: %s\n", data.SomeString)
+			return nil
+		})
+
+		// Restore stdout and close the writer now so that io.Copy gets an io.EOF and doesn't block indefinitely.
+		os.Stdout = stdout
+		err = w.Close()
+		s.Require().NoError(err)
+
+		// Read what was printed to stdout.
+		buffer := bytes.NewBuffer(nil)
+		_, err = io.Copy(buffer, r)
+		s.Require().NoError(err)
+		output := buffer.String()
+
+		// Make assertions
+		s.Equal(test.expectErr, actualErr)
+		s.Equal(test.expect, output)
+	}
+}
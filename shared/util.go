@@ -23,6 +23,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/flosch/pongo2"
@@ -1233,6 +1234,183 @@ func DownloadFileHash(ctx context.Context, httpClient *http.Client, useragent st
 	return size, nil
 }
 
+// downloadSegmentMinSize is the minimum size of a segment when downloading a file using parallel ranged requests.
+const downloadSegmentMinSize = 16 * 1024 * 1024
+
+// DownloadFileHashSegments downloads a file like DownloadFileHash, but splits it into up to the given number of
+// segments that are retrieved in parallel using ranged requests.
+// It falls back to a single request when the server doesn't support ranged requests, when the file is too small
+// to be worth splitting or when the target can't be written to and read from at arbitrary offsets.
+func DownloadFileHashSegments(ctx context.Context, httpClient *http.Client, useragent string, progress func(progress ioprogress.ProgressData), canceler *cancel.HTTPRequestCanceller, filename string, url string, hash string, hashFunc hash.Hash, target io.WriteSeeker, segments int) (int64, error) {
+	file, ok := target.(interface {
+		io.WriterAt
+		io.ReaderAt
+	})
+
+	if segments < 2 || !ok {
+		return DownloadFileHash(ctx, httpClient, useragent, progress, canceler, filename, url, hash, hashFunc, target)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Check whether the server supports ranged requests.
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	if err != nil {
+		return -1, err
+	}
+
+	if useragent != "" {
+		req.Header.Set("User-Agent", useragent)
+	}
+
+	r, err := httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	_ = r.Body.Close()
+
+	length := r.ContentLength
+	if r.StatusCode != http.StatusOK || r.Header.Get("Accept-Ranges") != "bytes" || length < 2*downloadSegmentMinSize {
+		return DownloadFileHash(ctx, httpClient, useragent, progress, canceler, filename, url, hash, hashFunc, target)
+	}
+
+	segments = int(min(int64(segments), length/downloadSegmentMinSize))
+	segmentSize := length / int64(segments)
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	defer cancelFunc()
+
+	// Report the combined progress of all segments.
+	var downloaded atomic.Int64
+	if progress != nil {
+		doneCh := make(chan struct{})
+		defer close(doneCh)
+
+		go func() {
+			start := time.Now()
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-doneCh:
+					return
+				case <-ticker.C:
+				}
+
+				total := downloaded.Load()
+				speed := int64(float64(total) / time.Since(start).Seconds())
+				text := fmt.Sprintf("%d%% (%s/s)", total*100/length, units.GetByteSizeString(speed, 2))
+				if filename != "" {
+					text = fmt.Sprintf("%s: %s", filename, text)
+				}
+
+				progress(ioprogress.ProgressData{Text: text})
+			}
+		}()
+	}
+
+	// Download the segments, stopping all of them as soon as one fails.
+	errCh := make(chan error, segments)
+	for i := 0; i < segments; i++ {
+		start := int64(i) * segmentSize
+		end := start + segmentSize - 1
+		if i == segments-1 {
+			end = length - 1
+		}
+
+		go func() {
+			errCh <- downloadFileSegment(ctx, httpClient, useragent, canceler, url, start, end, file, &downloaded)
+		}()
+	}
+
+	for i := 0; i < segments; i++ {
+		segmentErr := <-errCh
+		if segmentErr != nil && err == nil {
+			err = segmentErr
+			cancelFunc()
+		}
+	}
+
+	if err != nil {
+		return -1, err
+	}
+
+	if hashFunc != nil {
+		_, err = io.Copy(hashFunc, io.NewSectionReader(file, 0, length))
+		if err != nil {
+			return -1, err
+		}
+
+		result := fmt.Sprintf("%x", hashFunc.Sum(nil))
+		if result != hash {
+			return -1, fmt.Errorf("Hash mismatch for %s: %s != %s", url, result, hash)
+		}
+	}
+
+	// Leave the target positioned after the data like a sequential download does.
+	_, err = target.Seek(length, io.SeekStart)
+	if err != nil {
+		return -1, err
+	}
+
+	return length, nil
+}
+
+// segmentWriter writes a segment of a file and keeps track of the total amount of data written.
+type segmentWriter struct {
+	io.Writer
+	downloaded *atomic.Int64
+}
+
+// Write writes to the underlying writer and updates the downloaded counter.
+func (w *segmentWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.downloaded.Add(int64(n))
+
+	return n, err
+}
+
+// downloadFileSegment downloads the bytes from start to end (inclusive) of the file at url into target.
+func downloadFileSegment(ctx context.Context, httpClient *http.Client, useragent string, canceler *cancel.HTTPRequestCanceller, url string, start int64, end int64, target io.WriterAt, downloaded *atomic.Int64) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	if useragent != "" {
+		req.Header.Set("User-Agent", useragent)
+	}
+
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	r, doneCh, err := cancel.CancelableDownload(canceler, httpClient.Do, req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = r.Body.Close() }()
+	defer close(doneCh)
+
+	if r.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("Unable to fetch bytes %d-%d of %s: %s", start, end, url, r.Status)
+	}
+
+	size, err := io.Copy(&segmentWriter{Writer: io.NewOffsetWriter(target, start), downloaded: downloaded}, r.Body)
+	if err != nil {
+		return err
+	}
+
+	if size != end-start+1 {
+		return fmt.Errorf("Incomplete download of bytes %d-%d of %s: got %d bytes", start, end, url, size)
+	}
+
+	return nil
+}
+
 func ParseNumberFromFile(file string) (int64, error) {
 	f, err := os.Open(file)
 	if err != nil {
@@ -1391,7 +1569,17 @@ func SplitNTrimSpace(s string, sep string, n int, nilIfEmpty bool) []string {
 }
 
 // JoinTokenDecode decodes a base64 and JSON encoded join token.
+// The token can also be provided in its URL form.
 func JoinTokenDecode(input string) (*api.ClusterMemberJoinToken, error) {
+	if strings.HasPrefix(input, api.ClusterMemberJoinTokenURLPrefix) {
+		u, err := url.Parse(input)
+		if err != nil {
+			return nil, err
+		}
+
+		input = u.Query().Get("token")
+	}
+
 	joinTokenJSON, err := base64.StdEncoding.DecodeString(input)
 	if err != nil {
 		return nil, err
@@ -2,16 +2,23 @@ package shared
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
 )
 
 func TestURLEncode(t *testing.T) {
@@ -336,3 +343,59 @@ func TestRemoveElementsFromStringSlice(t *testing.T) {
 		assert.ElementsMatch(t, tt.expectedList, gotList)
 	}
 }
+
+func TestDownloadFileHashSegments(t *testing.T) {
+	data := make([]byte, 3*downloadSegmentMinSize+1234)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	var ranges atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+		}
+
+		http.ServeContent(w, r, "image", time.Time{}, bytes.NewReader(data))
+	}))
+
+	defer server.Close()
+
+	target, err := os.CreateTemp(t.TempDir(), "download")
+	require.NoError(t, err)
+
+	defer func() { _ = target.Close() }()
+
+	hash := fmt.Sprintf("%x", sha256.Sum256(data))
+	size, err := DownloadFileHashSegments(context.Background(), server.Client(), "", nil, nil, "", server.URL, hash, sha256.New(), target, 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), size)
+	assert.Equal(t, int64(3), ranges.Load())
+
+	content, err := os.ReadFile(target.Name())
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data, content))
+
+	// A hash mismatch is detected.
+	_, err = DownloadFileHashSegments(context.Background(), server.Client(), "", nil, nil, "", server.URL, "invalid", sha256.New(), target, 4)
+	assert.Error(t, err)
+}
+
+func TestJoinTokenDecode(t *testing.T) {
+	token := api.ClusterMemberJoinToken{
+		ServerName:  "lxd02",
+		Fingerprint: "57bb0ff4340b5bb28517e062023101adf788c37846dc8b619eb2c3cb4ef29436",
+		Addresses:   []string{"10.98.30.229:8443"},
+		Secret:      "2b2284d44db32675923fe0d2020477e0e9be11801ff70c435e032b97028c35cd",
+	}
+
+	for _, input := range []string{token.String(), token.URL()} {
+		decoded, err := JoinTokenDecode(input)
+		require.NoError(t, err)
+		assert.Equal(t, token.ServerName, decoded.ServerName)
+		assert.Equal(t, token.Addresses, decoded.Addresses)
+		assert.Equal(t, token.Secret, decoded.Secret)
+	}
+
+	_, err := JoinTokenDecode(api.ClusterMemberJoinTokenURLPrefix)
+	assert.Error(t, err)
+}
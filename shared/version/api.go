@@ -408,6 +408,89 @@ var APIExtensions = []string{
 	"device_usb_serial",
 	"network_allocate_external_ips",
 	"explicit_trust_token",
+	"server_startup",
+	"instance_nic_liveness",
+	"network_forward_health_check",
+	"network_load_balancer_bgp",
+	"network_wireguard",
+	"instance_nic_mirror",
+	"storage_pool_benchmark",
+	"instance_shutdown_fallback",
+	"metrics_api_requests",
+	"metrics_qemu_block",
+	"projects_limits_operations",
+	"network_capture",
+	"instances_state_bulk",
+	"instance_import_disk",
+	"network_load_balancer_session_persistence",
+	"instance_core_scheduling",
+	"instance_migration_bandwidth_limit",
+	"instance_migration_postcopy",
+	"cluster_inventory",
+	"instance_rebuild_preserve_paths",
+	"instance_migration_tuning",
+	"container_migration_stateful",
+	"instance_migration_retry",
+	"instance_move_target_auto",
+	"auth_bearer",
+	"network_bridge_dns_records",
+	"gpu_mdev_allocations",
+	"resources_pci_link",
+	"images_remote_cache_expiry_preview",
+	"console_multiplexing",
+	"storage_lvm_iscsi",
+	"agent_watchdog",
+	"console_history",
+	"instances_host_shutdown_protection",
+	"devlxd_events_cloud_init",
+	"agent_exec_sessions",
+	"devlxd_cloud_init_data",
+	"storage_volume_snapshot_sftp",
+	"disk_vhost_user_blk",
+	"devlxd_token",
+	"auth_roles",
+	"identity_provider_group_rules",
+	"cluster_join_preseed",
+	"device_groups",
+	"oidc_client_credentials",
+	"audit_log",
+	"metrics_guest_details",
+	"api_rate_limits",
+	"storage_volume_state_io",
+	"cluster_drift",
+	"device_shmem",
+	"profile_inheritance",
+	"device_startup_order",
+	"certificate_token_expiry",
+	"network_project_default",
+	"storage_ceph_namespace",
+	"instance_cpu_rebalance",
+	"storage_driver_linstor",
+	"disk_io_threads",
+	"instance_file_glob",
+	"certificate_authority",
+	"storage_pool_migrate",
+	"storage_volume_block_shared",
+	"instance_snapshot_stateful_incremental",
+	"instance_agent_mounts",
+	"snapshot_schedules",
+	"instance_rebuild_image_refresh",
+	"instance_log_follow",
+	"image_oci_protocol",
+	"image_download_segments",
+	"instances_state_memory_balloon",
+	"instances_security_kvm",
+	"config_dry_run",
+	"image_sync_status",
+	"storage_volume_import_url",
+	"instance_access_tokens",
+	"cluster_join_check",
+	"network_peer_ovn_interconnect",
+	"clustering_rolling_upgrade",
+	"resources_gpu_vf_allocations",
+	"storage_trim_schedule",
+	"cluster_state",
+	"instance_move_stateless_fallback",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
@@ -0,0 +1,16 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestAPIExtensionsUnique(t *testing.T) {
+	seen := make(map[string]bool, len(APIExtensions))
+	for _, extension := range APIExtensions {
+		if seen[extension] {
+			t.Errorf("API extension %q is defined more than once", extension)
+		}
+
+		seen[extension] = true
+	}
+}
@@ -0,0 +1,29 @@
+linstor_setup() {
+  # shellcheck disable=2039,3043
+  local LXD_DIR
+
+  LXD_DIR=$1
+
+  echo "==> Setting up LINSTOR backend in ${LXD_DIR}"
+}
+
+linstor_configure() {
+  # shellcheck disable=2039,3043
+  local LXD_DIR
+
+  LXD_DIR=$1
+
+  echo "==> Configuring LINSTOR backend in ${LXD_DIR}"
+
+  lxc storage create "lxdtest-$(basename "${LXD_DIR}")" linstor linstor.controller_connection="${LXD_LINSTOR_CONTROLLER_CONNECTION}" linstor.resource_group.name="lxdtest-$(basename "${LXD_DIR}")" linstor.resource_group.place_count=1 volume.size=25MiB
+  lxc profile device add default root disk path="/" pool="lxdtest-$(basename "${LXD_DIR}")"
+}
+
+linstor_teardown() {
+  # shellcheck disable=2039,3043
+  local LXD_DIR
+
+  LXD_DIR=$1
+
+  echo "==> Tearing down LINSTOR backend in ${LXD_DIR}"
+}
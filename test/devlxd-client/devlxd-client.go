@@ -152,6 +152,32 @@ func devlxdState(ready bool) {
 	}
 }
 
+func devlxdToken() {
+	client := http.Client{Transport: devLxdTransport}
+
+	resp, err := client.Post("http://unix/1.0/token", "application/json", nil)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Println("http error", resp.StatusCode)
+		os.Exit(1)
+	}
+
+	token := api.DevLXDToken{}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		fmt.Println("err decoding response", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(token.Token)
+}
+
 func main() {
 	c := http.Client{Transport: devLxdTransport}
 	raw, err := c.Get("http://meshuggah-rocks/")
@@ -193,6 +219,11 @@ func main() {
 			os.Exit(0)
 		}
 
+		if os.Args[1] == "token" {
+			devlxdToken()
+			os.Exit(0)
+		}
+
 		if os.Args[1] == "ready-state" {
 			ready, err := strconv.ParseBool(os.Args[2])
 			if err != nil {
@@ -26,6 +26,11 @@ spawn_lxd() {
         exit 1
     fi
 
+    if [ "${LXD_BACKEND}" = "linstor" ] && [ -z "${LXD_LINSTOR_CONTROLLER_CONNECTION:-}" ]; then
+        echo "A controller connection must be specified when using the LINSTOR driver." >&2
+        exit 1
+    fi
+
     # setup storage
     "$lxd_backend"_setup "${lxddir}"
     echo "$lxd_backend" > "${lxddir}/lxd.backend"
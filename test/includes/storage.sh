@@ -39,6 +39,10 @@ available_storage_backends() {
         storage_backends="${storage_backends} ceph"
     fi
 
+    if [ -n "${LXD_LINSTOR_CONTROLLER_CONNECTION:-}" ]; then
+        storage_backends="${storage_backends} linstor"
+    fi
+
     for backend in $storage_backends; do
         if command -v "$backend" >/dev/null 2>&1; then
             backends="$backends $backend"
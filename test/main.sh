@@ -58,6 +58,10 @@ if [ "$LXD_BACKEND" != "random" ] && ! storage_backend_available "$LXD_BACKEND";
     echo "Ceph storage backend requires that \"LXD_CEPH_CLUSTER\" be set."
     exit 1
   fi
+  if [ "${LXD_BACKEND}" = "linstor" ] && [ -z "${LXD_LINSTOR_CONTROLLER_CONNECTION:-}" ]; then
+    echo "LINSTOR storage backend requires that \"LXD_LINSTOR_CONTROLLER_CONNECTION\" be set."
+    exit 1
+  fi
   echo "Storage backend \"$LXD_BACKEND\" is not available"
   exit 1
 fi
@@ -214,6 +218,9 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_tls_restrictions "TLS restrictions"
     run_test test_oidc "OpenID Connect"
     run_test test_authorization "Authorization"
+    run_test test_authorization_devlxd_token "Authorization with devlxd tokens"
+    run_test test_authorization_bearer "Authorization with bearer tokens"
+    run_test test_authorization_identity_provider_group_rules "Authorization with identity provider group rules"
     run_test test_certificate_edit "Certificate edit"
     run_test test_basic_usage "basic usage"
     run_test test_server_info "server info"
@@ -221,6 +228,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_remote_url_with_token "remote token handling"
     run_test test_remote_admin "remote administration"
     run_test test_remote_usage "remote usage"
+    run_test test_api_rate_limits "API rate limits"
 fi
 
 if [ "${1:-"all"}" != "standalone" ]; then
@@ -257,6 +265,7 @@ if [ "${1:-"all"}" != "standalone" ]; then
     run_test test_clustering_groups "clustering groups"
     run_test test_clustering_events "clustering events"
     run_test test_clustering_uuid "clustering uuid"
+    run_test test_clustering_api_rate_limits "clustering API rate limits"
 fi
 
 if [ "${1:-"all"}" != "cluster" ]; then
@@ -294,6 +303,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_container_devices_unix_char "container devices - unix-char"
     run_test test_container_devices_unix_block "container devices - unix-block"
     run_test test_container_devices_tpm "container devices - tpm"
+    run_test test_device_groups "device groups"
     run_test test_container_move "container server-side move"
     run_test test_container_syscall_interception "container syscall interception"
     run_test test_security "security features"
@@ -309,6 +319,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_cloud_init "cloud-init"
     run_test test_exec "exec"
     run_test test_exec_exit_code "exec exit code"
+    run_test test_vm_exec_agent_restart "VM exec across lxd-agent restarts"
     run_test test_concurrent_exec "concurrent exec"
     run_test test_concurrent "concurrent startup"
     run_test test_snapshots "container snapshots"
@@ -327,7 +338,9 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_network "network management"
     run_test test_network_acl "network ACL management"
     run_test test_network_forward "network address forwards"
+    run_test test_network_capture "network capture"
     run_test test_network_zone "network DNS zones"
+    run_test test_network_wireguard "wireguard networks"
     run_test test_idmap "id mapping"
     run_test test_template "file templating"
     run_test test_pki "PKI mode"
@@ -351,10 +364,12 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_storage_driver_ceph "ceph storage driver"
     run_test test_storage_driver_cephfs "cephfs storage driver"
     run_test test_storage_driver_dir "dir storage driver"
+    run_test test_storage_driver_linstor "linstor storage driver"
     run_test test_storage_driver_zfs "zfs storage driver"
     run_test test_storage_buckets "storage buckets"
     run_test test_storage_volume_import "storage volume import"
     run_test test_storage_volume_initial_config "storage volume initial configuration"
+    run_test test_storage_pool_migrate "storage pool migrate"
     run_test test_resources "resources"
     run_test test_kernel_limits "kernel limits"
     run_test test_console "console"
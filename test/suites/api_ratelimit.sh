@@ -0,0 +1,94 @@
+test_api_rate_limits() {
+  lxc project create foo
+  gen_cert_and_key "${TEST_DIR}/ratelimit.key" "${TEST_DIR}/ratelimit.crt" "ratelimit.local"
+  lxc config trust add "${TEST_DIR}/ratelimit.crt" --restricted --projects foo
+
+  lxc config set limits.api.requests.project=1 limits.api.requests.burst=3
+
+  # Requests from a client without access to the default project don't count toward its limit.
+  for _ in $(seq 10); do
+    [ "$(curl -k -s -o /dev/null -w "%{http_code}" --key "${TEST_DIR}/ratelimit.key" --cert "${TEST_DIR}/ratelimit.crt" "https://${LXD_ADDR}/1.0/instances?project=default")" != "429" ]
+  done
+
+  [ "$(my_curl -o /dev/null -w "%{http_code}" "https://${LXD_ADDR}/1.0/instances?project=default")" = "200" ]
+
+  # Requests from a client with access to the project count toward its limit.
+  codes=""
+  for _ in $(seq 10); do
+    codes="${codes} $(curl -k -s -o /dev/null -w "%{http_code}" --key "${TEST_DIR}/ratelimit.key" --cert "${TEST_DIR}/ratelimit.crt" "https://${LXD_ADDR}/1.0/instances?project=foo")"
+  done
+
+  echo "${codes}" | grep -wF 429
+
+  # Requests made through the local Unix socket aren't limited.
+  for _ in $(seq 10); do
+    lxc list --project foo
+  done
+
+  lxc config unset limits.api.requests.project
+  lxc config unset limits.api.requests.burst
+  lxc config trust remove "$(cert_fingerprint "${TEST_DIR}/ratelimit.crt")"
+  rm "${TEST_DIR}/ratelimit.key" "${TEST_DIR}/ratelimit.crt"
+  lxc project delete foo
+}
+
+test_clustering_api_rate_limits() {
+  # shellcheck disable=2039,3043,SC2034
+  local LXD_DIR
+
+  setup_clustering_bridge
+  prefix="lxd$$"
+  bridge="${prefix}"
+
+  setup_clustering_netns 1
+  LXD_ONE_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_ONE_DIR}"
+  ns1="${prefix}1"
+  spawn_lxd_and_bootstrap_cluster "${ns1}" "${bridge}" "${LXD_ONE_DIR}"
+
+  # Add a newline at the end of each line. YAML as weird rules..
+  cert=$(sed ':a;N;$!ba;s/\n/\n\n/g' "${LXD_ONE_DIR}/cluster.crt")
+
+  # Spawn a second node
+  setup_clustering_netns 2
+  LXD_TWO_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_TWO_DIR}"
+  ns2="${prefix}2"
+  spawn_lxd_and_join_cluster "${ns2}" "${bridge}" "${cert}" 2 1 "${LXD_TWO_DIR}" "${LXD_ONE_DIR}"
+
+  LXD_DIR="${LXD_ONE_DIR}" lxc config trust add "${LXD_CONF}/client.crt"
+  LXD_DIR="${LXD_ONE_DIR}" lxc config set limits.api.requests.project=1 limits.api.requests.burst=3
+
+  # The limits apply to each member separately, so the burst is available on both members.
+  for _ in $(seq 3); do
+    [ "$(my_curl -o /dev/null -w "%{http_code}" "https://10.1.1.101:8443/1.0/instances?project=default")" = "200" ]
+  done
+
+  for _ in $(seq 3); do
+    [ "$(my_curl -o /dev/null -w "%{http_code}" "https://10.1.1.102:8443/1.0/instances?project=default")" = "200" ]
+  done
+
+  # Once used up, further requests to the same member are rejected.
+  codes=""
+  for _ in $(seq 10); do
+    codes="${codes} $(my_curl -o /dev/null -w "%{http_code}" "https://10.1.1.101:8443/1.0/instances?project=default")"
+  done
+
+  echo "${codes}" | grep -wF 429
+
+  # Cleanup
+  LXD_DIR="${LXD_ONE_DIR}" lxc config unset limits.api.requests.project
+  LXD_DIR="${LXD_ONE_DIR}" lxc config unset limits.api.requests.burst
+
+  LXD_DIR="${LXD_TWO_DIR}" lxd shutdown
+  LXD_DIR="${LXD_ONE_DIR}" lxd shutdown
+  sleep 0.5
+  rm -f "${LXD_TWO_DIR}/unix.socket"
+  rm -f "${LXD_ONE_DIR}/unix.socket"
+
+  teardown_clustering_netns
+  teardown_clustering_bridge
+
+  kill_lxd "${LXD_ONE_DIR}"
+  kill_lxd "${LXD_TWO_DIR}"
+}
@@ -133,6 +133,9 @@ EOF
   # Perform access checks
   fine_grained_authorization
 
+  # Check access granted through custom roles
+  auth_roles
+
   # Cleanup
   lxc auth group delete test-group
   lxc auth identity-provider-group delete test-idp-group
@@ -261,6 +264,51 @@ fine_grained_authorization() {
   lxc storage delete test-pool
 }
 
+auth_roles() {
+  lxc launch testimage role-foo
+
+  # The test-group has no permissions at this point.
+  ! lxc_remote query oidc:/1.0/instances/role-foo || false
+
+  # Roles only apply to a single entity type.
+  ! lxc auth role create role-viewer instance can_view not_an_entitlement || false
+  ! lxc auth role create role-viewer instance can_create_instances || false
+  lxc auth role create role-viewer instance can_view
+  ! lxc auth group role add test-group role-viewer default || false # Wrong entity type
+
+  # Assigning the role on the instance grants its entitlements on that instance only.
+  lxc auth group role add test-group role-viewer role-foo project=default
+  [ "$(lxc_remote query oidc:/1.0/instances/role-foo | jq -r '.name')" = "role-foo" ]
+  ! LXC_LOCAL='' lxc_remote exec oidc:role-foo -- true || false
+  lxc auth group show test-group | grep -F 'role: role-viewer'
+
+  # Changes to the role entitlements apply immediately.
+  lxc auth group permission add test-group project default can_view_events
+  lxc auth role entitlement add role-viewer can_exec
+  LXC_LOCAL='' lxc_remote exec oidc:role-foo -- true
+  lxc auth role entitlement remove role-viewer can_exec
+  ! LXC_LOCAL='' lxc_remote exec oidc:role-foo -- true || false
+  lxc auth group permission remove test-group project default can_view_events
+
+  # Roles can't be deleted while assigned.
+  ! lxc auth role delete role-viewer || false
+
+  # Removing the assignment removes the access.
+  lxc auth group role remove test-group role-viewer role-foo project=default
+  ! lxc_remote query oidc:/1.0/instances/role-foo || false
+  ! lxc auth group show test-group | grep -F 'role-viewer' || false
+
+  # Assignments are removed along with their entity.
+  lxc auth group role add test-group role-viewer role-foo project=default
+  lxc delete role-foo --force
+  [ "$(lxd sql global --format csv "SELECT count(*) FROM auth_groups_roles" | tail -n1)" = "0" ]
+  ! lxc auth group show test-group | grep -F 'role-viewer' || false
+
+  # The role can be deleted once it is not assigned anymore.
+  lxc auth role delete role-viewer
+  ! lxc auth role show role-viewer || false
+}
+
 user_is_not_server_admin() {
   # Can always see server info (type-bound public access https://openfga.dev/docs/modeling/public-access).
   lxc_remote info oidc: > /dev/null
@@ -444,3 +492,182 @@ user_is_instance_user() {
   # We can't edit the instance though
   ! lxc_remote config set "oidc:${instance_name}" user.fizz=buzz || false
 }
+
+test_authorization_devlxd_token() {
+  ensure_import_testimage
+
+  (
+    cd devlxd-client || return
+    # Use -buildvcs=false here to prevent git complaining about untrusted directory when tests are run as root.
+    go build -tags netgo -v -buildvcs=false ./...
+  )
+
+  lxc launch testimage c1
+  lxc launch testimage c2
+  lxc file push --mode 0755 "devlxd-client/devlxd-client" c1/bin/
+
+  # Tokens must be enabled for the instance.
+  ! lxc exec c1 -- devlxd-client token || false
+  lxc config set c1 security.devlxd.token=true
+
+  token="$(lxc exec c1 -- devlxd-client token)"
+  nonce="$(lxc config get c1 volatile.devlxd.token_nonce)"
+  [ -n "${nonce}" ]
+
+  # The token only grants read-only access to the server and to the instance it was issued for.
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0")" = "200" ]
+  [ "$(curl -sk -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" = "trusted" ]
+  [ "$(curl -sk -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0/instances/c1" | jq -r '.metadata.name')" = "c1" ]
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0/instances/c2")" != "200" ]
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" -X PATCH -d '{"config": {"user.foo": "bar"}}' "https://${LXD_ADDR}/1.0/instances/c1")" != "200" ]
+  [ -z "$(lxc config get c1 user.foo)" ]
+
+  # A tampered token is rejected.
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}x" "https://${LXD_ADDR}/1.0/instances/c1")" != "200" ]
+
+  # Tokens stop working while tokens are disabled for the instance.
+  lxc config set c1 security.devlxd.token=false
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0/instances/c1")" != "200" ]
+  lxc config set c1 security.devlxd.token=true
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0/instances/c1")" = "200" ]
+
+  # Unsetting the nonce revokes the issued tokens.
+  lxc config unset c1 volatile.devlxd.token_nonce
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0/instances/c1")" != "200" ]
+
+  # New tokens are bound to a new nonce.
+  token="$(lxc exec c1 -- devlxd-client token)"
+  [ "$(lxc config get c1 volatile.devlxd.token_nonce)" != "${nonce}" ]
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0/instances/c1")" = "200" ]
+
+  # Tokens of a deleted instance can't be used against an instance recreated with the same name.
+  lxc delete -f c1
+  lxc launch testimage c1 -c security.devlxd.token=true
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0/instances/c1")" != "200" ]
+
+  lxc delete -f c1 c2
+}
+
+test_authorization_bearer() {
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  lxc auth group create bearer-group
+  lxc auth group permission add bearer-group project default can_view
+  lxc auth identity create bearer/ci-runner --group bearer-group
+  lxc auth identity list --format csv | grep -F 'bearer,' | grep -F 'ci-runner'
+
+  # Identities with another authentication method can't be created.
+  ! lxc auth identity create tls/foo || false
+
+  # Check the issued token grants the permissions of the groups of the identity.
+  token="$(lxc auth identity token issue ci-runner)"
+  [ "$(curl -sk -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" = "trusted" ]
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0/projects/default")" = "200" ]
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" -H "Authorization: Bearer ${token}" -X PATCH -d '{"config": {"user.foo": "bar"}}' "https://${LXD_ADDR}/1.0/projects/default")" != "200" ]
+
+  # Check the token can be used by a remote.
+  lxc remote add bearer "${LXD_ADDR}" --accept-certificate --auth-type=bearer --token="${token}"
+  [ "$(lxc query bearer:/1.0 | jq -r '.auth')" = "trusted" ]
+  [ "$(lxc query bearer:/1.0/projects/default | jq -r '.name')" = "default" ]
+  lxc project show bearer:default
+  lxc remote remove bearer
+
+  # A token with a wrong secret is rejected.
+  [ "$(curl -sk -H "Authorization: Bearer ${token}x" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" != "trusted" ]
+
+  # Rotating the token revokes the previous one.
+  new_token="$(lxc auth identity token issue ci-runner)"
+  [ "${new_token}" != "${token}" ]
+  [ "$(curl -sk -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" != "trusted" ]
+  [ "$(curl -sk -H "Authorization: Bearer ${new_token}" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" = "trusted" ]
+
+  # Revoked tokens are rejected.
+  lxc auth identity token revoke ci-runner
+  [ "$(curl -sk -H "Authorization: Bearer ${new_token}" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" != "trusted" ]
+
+  # Tokens of deleted identities are rejected.
+  token="$(lxc auth identity token issue ci-runner)"
+  [ "$(curl -sk -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" = "trusted" ]
+  lxc auth identity delete bearer/ci-runner
+  [ "$(curl -sk -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" != "trusted" ]
+  ! lxc auth identity token issue ci-runner || false
+
+  lxc auth group delete bearer-group
+}
+
+test_authorization_identity_provider_group_rules() {
+  lxc auth group create rule-group
+  lxc auth group create other-group
+
+  ### RULE MANAGEMENT ###
+  ! lxc auth identity-provider-group-rule create engineering || false # No conditions
+  ! lxc auth identity-provider-group-rule create engineering email || false # Bad condition
+  ! lxc auth identity-provider-group-rule create engineering email= || false # Empty pattern
+  ! lxc auth identity-provider-group-rule create engineering =foo || false # Empty claim
+  ! lxc auth identity-provider-group-rule create engineering email=foo --group not-found || false # Group not found
+  ! lxc auth identity-provider-group-rule create foo/bar email=foo || false # Invalid name
+
+  lxc auth identity-provider-group-rule create engineering email=*@example.com --group rule-group --description "Example users"
+  ! lxc auth identity-provider-group-rule create engineering email=*@example.com || false # Already exists
+
+  [ "$(lxc auth identity-provider-group-rule list --format csv)" = 'engineering,email=*@example.com,rule-group,Example users' ]
+  [ "$(lxc query /1.0/auth/identity-provider-group-rules/engineering | jq -r '.conditions[0].pattern')" = '*@example.com' ]
+
+  # Rules can be edited, patched, renamed and deleted.
+  lxc auth identity-provider-group-rule show engineering | sed 's/^description: .*/description: Engineering/' | lxc auth identity-provider-group-rule edit engineering
+  [ "$(lxc query /1.0/auth/identity-provider-group-rules/engineering | jq -r '.description')" = "Engineering" ]
+  ! lxc auth identity-provider-group-rule show engineering | sed 's/pattern: .*/pattern: ""/' | lxc auth identity-provider-group-rule edit engineering || false
+
+  lxc query -X PATCH -d '{"groups": ["other-group"]}' /1.0/auth/identity-provider-group-rules/engineering
+  [ "$(lxc query /1.0/auth/identity-provider-group-rules/engineering | jq -r '.groups | sort | join(",")')" = "other-group,rule-group" ]
+
+  lxc auth identity-provider-group-rule create contractors email=*@contractor.example.com --group other-group
+  ! lxc auth identity-provider-group-rule rename engineering contractors || false # Already exists
+  lxc auth identity-provider-group-rule rename engineering eng
+  ! lxc auth identity-provider-group-rule show engineering || false
+  lxc auth identity-provider-group-rule show eng
+  lxc auth identity-provider-group-rule delete contractors
+  ! lxc auth identity-provider-group-rule delete contractors || false
+
+  ### GROUP MAPPING ###
+  spawn_oidc
+  lxc config set "oidc.issuer=http://127.0.0.1:$(cat "${TEST_DIR}/oidc.port")/"
+  lxc config set "oidc.client.id=device"
+
+  set_oidc test-user test-user@example.com
+  BROWSER=curl lxc remote add --accept-certificate oidc "${LXD_ADDR}" --auth-type oidc
+
+  # The groups of the matching rules apply to the identity, without it being a member of them.
+  [ "$(lxc_remote query oidc:/1.0/auth/identities/current | jq -r '.effective_groups | sort | join(",")')" = "other-group,rule-group" ]
+  [ "$(lxc_remote query oidc:/1.0/auth/identities/current | jq -r '.groups | length')" = "0" ]
+
+  # Permissions of the mapped groups are granted.
+  ! lxc_remote project show oidc:default || false
+  lxc auth group permission add rule-group project default viewer
+  lxc_remote project show oidc:default
+
+  # Rules that don't match anymore don't apply.
+  lxc query -X PUT -d '{"conditions": [{"claim": "email", "pattern": "*@example.org"}], "groups": ["rule-group"]}' /1.0/auth/identity-provider-group-rules/eng
+  [ "$(lxc_remote query oidc:/1.0/auth/identities/current | jq -r '.effective_groups | length')" = "0" ]
+  ! lxc_remote project show oidc:default || false
+
+  # An invalid stored rule doesn't prevent the other rules from applying.
+  lxd sql global "UPDATE identity_provider_group_rules_conditions SET pattern = '' WHERE identity_provider_group_rule_id = (SELECT id FROM identity_provider_group_rules WHERE name = 'eng')"
+  lxc auth identity-provider-group-rule create example email=test-user@* --group other-group
+  [ "$(lxc_remote query oidc:/1.0/auth/identities/current | jq -r '.effective_groups | join(",")')" = "other-group" ]
+
+  # Deleting a rule removes its groups.
+  lxc auth identity-provider-group-rule delete example
+  [ "$(lxc_remote query oidc:/1.0/auth/identities/current | jq -r '.effective_groups | length')" = "0" ]
+
+  # Cleanup
+  lxc auth identity-provider-group-rule delete eng
+  lxc auth identity delete oidc/test-user@example.com
+  lxc auth group delete rule-group
+  lxc auth group delete other-group
+  lxc remote remove oidc
+  kill_oidc
+  rm "${TEST_DIR}/oidc.user"
+  lxc config unset oidc.issuer
+  lxc config unset oidc.client.id
+}
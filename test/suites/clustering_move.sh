@@ -64,6 +64,11 @@ test_clustering_move() {
   LXD_DIR="${LXD_ONE_DIR}" lxc info c1 | grep -q "Location: node3"
   ! LXD_DIR="${LXD_ONE_DIR}" lxc move c1 --target=@foobar3 || false
 
+  # c1 can be moved to the member picked by the cluster, which is the one with the least instances
+  # other than its current member.
+  LXD_DIR="${LXD_ONE_DIR}" lxc move c1 --target=auto
+  LXD_DIR="${LXD_ONE_DIR}" lxc info c1 | grep -q "Location: node1"
+
   # Perform standard move tests using the `scheduler.instance` cluster member setting.
   LXD_DIR="${LXD_ONE_DIR}" lxc cluster set node2 scheduler.instance=group
   LXD_DIR="${LXD_ONE_DIR}" lxc cluster set node3 scheduler.instance=manual
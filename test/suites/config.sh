@@ -148,6 +148,18 @@ test_config_profiles() {
   lxc profile remove foo one
   [ "$(lxc list -f json foo | jq -r '.[0].profiles | join(" ")')" = "" ]
 
+  # check that a profile list resulting in an invalid expanded config is rejected and leaves the instance unchanged
+  lxc profile set one raw.seccomp "2
+blacklist
+reject_force_umount"
+  lxc profile set two security.syscalls.deny reject_force_umount
+  lxc profile assign foo one
+  ! lxc profile assign foo one,two || false
+  [ "$(lxc list -f json foo | jq -r '.[0].profiles | join(" ")')" = "one" ]
+  lxc profile assign foo ""
+  lxc profile unset one raw.seccomp
+  lxc profile unset two security.syscalls.deny
+
   lxc profile create stdintest
   echo "BADCONF" | lxc profile set stdintest user.user_data -
   lxc profile show stdintest | grep BADCONF
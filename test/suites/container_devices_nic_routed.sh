@@ -199,6 +199,23 @@ test_container_devices_nic_routed() {
   lxc exec "${ctName}2" -- ping6 -c2 -W5 "2001:db8::1${ipRand}"
 
   lxc stop -f "${ctName}2"
+
+  # Check neighbour proxy entries are only added once the addresses are live when liveness checks are enabled.
+  lxc config device set "${ctName}2" eth0 liveness.check=true liveness.interval=1
+  lxc start "${ctName}2"
+  sleep 3
+  ip neigh show proxy dev "${ctName}" | grep "192.0.2.2${ipRand}"
+  [ "$(lxc query "/1.0/instances/${ctName}2/state" | jq -r '.network.eth0.announcements[] | select(.address == "192.0.2.2'"${ipRand}"'") | .announced')" = "true" ]
+
+  # Check neighbour proxy entries are withdrawn when the address stops answering.
+  lxc exec "${ctName}2" -- ip link set eth0 down
+  sleep 5
+  ! ip neigh show proxy dev "${ctName}" | grep "192.0.2.2${ipRand}" || false
+  [ "$(lxc query "/1.0/instances/${ctName}2/state" | jq -r '.network.eth0.announcements[] | select(.address == "192.0.2.2'"${ipRand}"'") | .reason')" = "Address not reachable" ]
+  lxc stop -f "${ctName}2"
+  lxc config device unset "${ctName}2" eth0 liveness.check
+  lxc config device unset "${ctName}2" eth0 liveness.interval
+
   lxc stop -f "${ctName}"
 
   # Check routed ontop of VLAN parent with custom routing tables.
@@ -0,0 +1,86 @@
+test_device_groups() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  mkdir -p "${TEST_DIR}/device-group-a" "${TEST_DIR}/device-group-b"
+  touch "${TEST_DIR}/device-group-a/a" "${TEST_DIR}/device-group-b/b"
+
+  lxc device-group create shared-dirs << EOF2
+description: Shared directories
+devices:
+  dir-a:
+    type: disk
+    source: ${TEST_DIR}/device-group-a
+    path: /mnt/a
+  dir-b:
+    type: disk
+    source: ${TEST_DIR}/device-group-b
+    path: /mnt/b
+EOF2
+  lxc device-group show shared-dirs | grep -F 'description: Shared directories'
+  lxc device-group list | grep -F 'shared-dirs'
+
+  # Root disk devices can't be part of a device group.
+  ! lxc device-group create bad-root << EOF2 || false
+devices:
+  root:
+    type: disk
+    path: /
+    pool: lxdtest-$(basename "${LXD_DIR}")
+EOF2
+
+  lxc launch testimage c1
+
+  # Attach the device group to the running instance, the devices are hotplugged.
+  lxc device-group attach c1 shared-dirs
+  [ "$(lxc config get c1 volatile.device_group.shared-dirs.devices)" = "dir-a,dir-b" ]
+  lxc config device show c1 | grep -F 'dir-a:'
+  lxc config device show c1 | grep -F 'dir-b:'
+  lxc exec c1 -- test -e /mnt/a/a
+  lxc exec c1 -- test -e /mnt/b/b
+  lxc device-group show shared-dirs | grep -F '/1.0/instances/c1'
+
+  # Attaching twice fails, and so do name conflicts with existing devices.
+  ! lxc device-group attach c1 shared-dirs || false
+  lxc device-group create conflicting << EOF2
+devices:
+  dir-a:
+    type: disk
+    source: ${TEST_DIR}/device-group-a
+    path: /mnt/other
+EOF2
+  ! lxc device-group attach c1 conflicting || false
+  [ "$(lxc config get c1 volatile.device_group.conflicting.devices)" = "" ]
+  lxc device-group delete conflicting
+
+  # Attached device groups can't be renamed or deleted.
+  ! lxc device-group rename shared-dirs other-dirs || false
+  ! lxc device-group delete shared-dirs || false
+
+  # Detaching refuses to remove a device that was modified since the device group was attached.
+  lxc config device set c1 dir-b path=/mnt/b-modified
+  ! lxc device-group detach c1 shared-dirs || false
+  lxc config device show c1 | grep -F 'dir-a:'
+  lxc exec c1 -- test -e /mnt/a/a
+  lxc config device set c1 dir-b path=/mnt/b
+
+  # Detach the device group from the running instance, the devices are hot-unplugged.
+  lxc device-group detach c1 shared-dirs
+  [ "$(lxc config get c1 volatile.device_group.shared-dirs.devices)" = "" ]
+  ! lxc config device show c1 | grep -F 'dir-a:' || false
+  ! lxc config device show c1 | grep -F 'dir-b:' || false
+  ! lxc exec c1 -- test -e /mnt/a/a || false
+  ! lxc exec c1 -- test -e /mnt/b/b || false
+  ! lxc device-group detach c1 shared-dirs || false
+
+  # Devices already removed from the instance are skipped when detaching.
+  lxc device-group attach c1 shared-dirs
+  lxc config device remove c1 dir-a
+  lxc device-group detach c1 shared-dirs
+  ! lxc config device show c1 | grep -F 'dir-b:' || false
+
+  lxc device-group rename shared-dirs other-dirs
+  lxc device-group delete other-dirs
+  lxc delete -f c1
+  rm -rf "${TEST_DIR}/device-group-a" "${TEST_DIR}/device-group-b"
+}
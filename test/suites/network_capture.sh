@@ -0,0 +1,57 @@
+test_network_capture() {
+  ensure_import_testimage
+
+  brName="lxdt$$"
+  lxc network create "${brName}" ipv4.address=192.0.2.1/24 ipv4.nat=false ipv6.address=none
+
+  # The capture is written in pcap format, starting with the file header.
+  lxc network capture "${brName}" --duration 1s > "${TEST_DIR}/capture.pcap"
+  [ "$(head -c 4 "${TEST_DIR}/capture.pcap" | od -An -tx1 | tr -d ' \n')" = "d4c3b2a1" ]
+  [ "$(stat -c %s "${TEST_DIR}/capture.pcap")" -ge 24 ]
+
+  # Capture the traffic of an instance NIC.
+  lxc launch testimage c1 -n "${brName}"
+  lxc exec c1 -- ip addr add 192.0.2.2/24 dev eth0
+  (sleep 1; lxc exec c1 -- ping -c 3 -W 1 192.0.2.1 || true) &
+  lxc network capture "${brName}" --instance c1 --device eth0 --duration 5s > "${TEST_DIR}/capture.pcap"
+  wait
+  [ "$(stat -c %s "${TEST_DIR}/capture.pcap")" -gt 24 ]
+
+  # The size limit is never exceeded.
+  (sleep 1; lxc exec c1 -- ping -c 3 -i 0.2 192.0.2.1 || true) &
+  lxc network capture "${brName}" --instance c1 --device eth0 --duration 5s --max-size 200B > "${TEST_DIR}/capture.pcap"
+  wait
+  [ "$(stat -c %s "${TEST_DIR}/capture.pcap")" -le 200 ]
+
+  # The duration and size limits are validated.
+  ! lxc network capture "${brName}" --duration 0s || false
+  ! lxc network capture "${brName}" --duration 2h || false
+  ! lxc network capture "${brName}" --max-size 2GiB || false
+  ! lxc network capture "${brName}" --instance c1 || false
+  ! lxc network capture "${brName}" --instance c1 --device eth1 || false
+
+  # A client restricted to another project can't capture the traffic of the network.
+  lxc project create foo
+  gen_cert_and_key "${TEST_DIR}/capture.key" "${TEST_DIR}/capture.crt" "capture.local"
+  lxc config trust add "${TEST_DIR}/capture.crt" --restricted --projects foo
+  [ "$(curl -k -s -o /dev/null -w "%{http_code}" --key "${TEST_DIR}/capture.key" --cert "${TEST_DIR}/capture.crt" -X POST -d '{}' "https://${LXD_ADDR}/1.0/networks/${brName}/capture")" = "403" ]
+  lxc config trust remove "$(cert_fingerprint "${TEST_DIR}/capture.crt")"
+
+  # A client restricted to the project of the network can capture its traffic, but not the traffic of a parent
+  # interface that is shared with the host, which requires the permission to edit the server.
+  ip link add "dummy$$" type dummy
+  lxc network create "${brName}-mv" --type=macvlan parent="dummy$$"
+  lxc config trust add "${TEST_DIR}/capture.crt" --restricted --projects default
+  [ "$(curl -k -s -o /dev/null -w "%{http_code}" --key "${TEST_DIR}/capture.key" --cert "${TEST_DIR}/capture.crt" -X POST -d '{"duration": "1s"}' "https://${LXD_ADDR}/1.0/networks/${brName}/capture")" = "202" ]
+  [ "$(curl -k -s -o /dev/null -w "%{http_code}" --key "${TEST_DIR}/capture.key" --cert "${TEST_DIR}/capture.crt" -X POST -d '{"duration": "1s"}' "https://${LXD_ADDR}/1.0/networks/${brName}-mv/capture")" = "403" ]
+  lxc network capture "${brName}-mv" --duration 1s > "${TEST_DIR}/capture.pcap"
+
+  # Cleanup
+  lxc config trust remove "$(cert_fingerprint "${TEST_DIR}/capture.crt")"
+  rm "${TEST_DIR}/capture.key" "${TEST_DIR}/capture.crt" "${TEST_DIR}/capture.pcap"
+  lxc delete -f c1
+  lxc network delete "${brName}-mv"
+  ip link delete "dummy$$"
+  lxc network delete "${brName}"
+  lxc project delete foo
+}
@@ -132,6 +132,22 @@ test_network_forward() {
     [ "$(nft -nn list chain inet lxd "fwdpstrt.${netName}" | wc -l)" -eq 7 ]
   fi
 
+  # Check health checked port fails over to the failover address while the target address is unreachable.
+  lxc network forward create "${netName}" 198.51.100.2
+  ! lxc query -X PUT "/1.0/networks/${netName}/forwards/198.51.100.2" --data '{"ports":[{"protocol":"tcp","listen_port":"80","target_address":"192.0.2.4","failover_address":"192.0.2.4","health_check":{"protocol":"icmp"}}]}' || false
+  ! lxc query -X PUT "/1.0/networks/${netName}/forwards/198.51.100.2" --data '{"ports":[{"protocol":"tcp","listen_port":"80","target_address":"192.0.2.4","failover_address":"192.0.2.1"}]}' || false
+  lxc query -X PUT "/1.0/networks/${netName}/forwards/198.51.100.2" --data '{"ports":[{"protocol":"tcp","listen_port":"80","target_address":"192.0.2.4","failover_address":"192.0.2.1","health_check":{"protocol":"icmp","interval":1}}]}'
+  sleep 5
+  [ "$(lxc query "/1.0/networks/${netName}/forwards/198.51.100.2/state" | jq -r '.ports[0].status')" = "failover" ]
+  [ "$(lxc query "/1.0/networks/${netName}/forwards/198.51.100.2/state" | jq -r '.ports[0].active_address')" = "192.0.2.1" ]
+  if [ "$firewallDriver" = "xtables" ]; then
+    iptables -w -t nat -S | grep -- "-A PREROUTING -d 198.51.100.2/32 -p tcp -m tcp --dport 80 -m comment --comment \"generated for LXD network-forward ${netName}\" -j DNAT --to-destination 192.0.2.1"
+  else
+    nft -nn list chain inet lxd "fwdprert.${netName}" | grep "ip daddr 198.51.100.2 tcp dport 80 dnat ip to 192.0.2.1"
+  fi
+
+  lxc network forward delete "${netName}" 198.51.100.2
+
   # Check forward is exported via BGP prefixes before network delete.
   lxc query /internal/testing/bgp | grep "198.51.100.1/32"
 
@@ -0,0 +1,66 @@
+test_network_wireguard() {
+  if ! command -v wg >/dev/null 2>&1; then
+    echo "==> SKIP: wireguard network tests (missing wg tool)"
+    return
+  fi
+
+  if ! ip link add lxdwgtest type wireguard >/dev/null 2>&1; then
+    echo "==> SKIP: wireguard network tests (missing kernel support)"
+    return
+  fi
+
+  ip link delete lxdwgtest
+
+  ensure_import_testimage
+
+  netName="wgt$$"
+
+  # Check config validation.
+  ! lxc network create "${netName}" --type=wireguard || false
+  ! lxc network create "${netName}" --type=wireguard ipv4.address=10.250.0.0/24 || false
+  ! lxc network create "${netName}" --type=wireguard ipv4.address=10.250.0.0/16 wireguard.member_prefix=16 || false
+  ! lxc network create "${netName}" --type=wireguard ipv4.address=10.250.0.0/16 ipv6.address=fd42:4242:4242:1010::1/64 || false
+  ! lxc network create "${netName}" --type=wireguard ipv4.address=10.250.0.0/16 wireguard.port=70000 || false
+
+  lxc network create "${netName}" --type=wireguard ipv4.address=10.250.0.0/16 wireguard.port=51899
+
+  # The local bridge uses an address of the member subnet with the overlay prefix.
+  ip -4 addr show dev "${netName}" | grep -F "10.250.1.1/16"
+  [ "$(cat "/sys/class/net/${netName}/mtu")" = "1420" ]
+  [ "$(cat "/proc/sys/net/ipv4/conf/${netName}/proxy_arp")" = "1" ]
+
+  # The WireGuard interface listens on the configured port and its public key is published.
+  [ "$(wg show "${netName}-wg" listen-port)" = "51899" ]
+  [ "$(cat "/sys/class/net/${netName}-wg/mtu")" = "1420" ]
+  [ "$(lxc network get "${netName}" volatile.wireguard.public_key)" = "$(wg show "${netName}-wg" public-key)" ]
+
+  # Without other cluster members there are no peers.
+  [ "$(wg show "${netName}-wg" peers)" = "" ]
+
+  # Instances connect to the local bridge.
+  lxc launch testimage wgc1 -n "${netName}"
+  [ "$(lxc config device get wgc1 eth0 network)" = "${netName}" ]
+  ls "/sys/class/net/${netName}/brif/" | grep -q .
+  lxc delete -f wgc1
+
+  # Config changes are applied to the running network.
+  lxc network set "${netName}" wireguard.port 51898
+  [ "$(wg show "${netName}-wg" listen-port)" = "51898" ]
+  lxc network set "${netName}" wireguard.member_prefix 20
+  ip -4 addr show dev "${netName}" | grep -F "10.250.16.1/16"
+  lxc network set "${netName}" bridge.mtu 1380
+  [ "$(cat "/sys/class/net/${netName}/mtu")" = "1380" ]
+  [ "$(cat "/sys/class/net/${netName}-wg/mtu")" = "1380" ]
+
+  # The key pair is kept across restarts of the network.
+  publicKey="$(lxc network get "${netName}" volatile.wireguard.public_key)"
+  shutdown_lxd "${LXD_DIR}"
+  respawn_lxd "${LXD_DIR}" true
+  [ "$(lxc network get "${netName}" volatile.wireguard.public_key)" = "${publicKey}" ]
+  [ "$(wg show "${netName}-wg" public-key)" = "${publicKey}" ]
+
+  # Deleting the network removes both interfaces.
+  lxc network delete "${netName}"
+  ! ip link show "${netName}" || false
+  ! ip link show "${netName}-wg" || false
+}
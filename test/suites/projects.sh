@@ -933,6 +933,12 @@ test_projects_restrictions() {
   # It's possible to attach managed network devices.
   lxc profile device add default eth0 nic network="${netManaged}"
 
+  # It's only possible to mirror NIC traffic to the NICs of other instances in the project.
+  ! lxc profile device set default eth0 security.mirror.target=lo || false
+  ! lxc config device override c1 eth0 security.mirror.target=lo || false
+  lxc profile device set default eth0 security.mirror.target=c2/eth0
+  lxc profile device unset default eth0 security.mirror.target
+
   # It's possible to attach disks backed by a pool.
   lxc config device add c1 data disk pool="${pool}" path=/mnt source="v-proj$$"
 
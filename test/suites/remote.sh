@@ -92,8 +92,24 @@ test_remote_url_with_token() {
   # Check if we can see instances
   [ "$(curl -k -s --key "${TEST_DIR}/token-client.key" --cert "${TEST_DIR}/token-client.crt" "https://${LXD_ADDR}/1.0/instances" | jq '.status_code')" -eq 200 ]
 
+  # The token can't be used again, even with another certificate
+  gen_cert_and_key "${TEST_DIR}/token-client2.key" "${TEST_DIR}/token-client2.crt" "lxd.local"
+  [ "$(curl -k -s --key "${TEST_DIR}/token-client2.key" --cert "${TEST_DIR}/token-client2.crt" -X POST -d "{\"trust_token\": ${token}}" "https://${LXD_ADDR}/1.0/certificates" | jq '.error_code')" -eq 403 ]
+  [ "$(curl -k -s --key "${TEST_DIR}/token-client2.key" --cert "${TEST_DIR}/token-client2.crt" "https://${LXD_ADDR}/1.0/instances" | jq '.error_code')" -eq 403 ]
+
   lxc config trust rm "$(lxc config trust list -f json | jq -r '.[].fingerprint')"
 
+  # Only one of concurrent requests using the same token succeeds
+  echo foo | lxc config trust add -q
+  token="$(lxc config trust list-tokens -f json | jq '.[].Token')"
+  curl -k -s --key "${TEST_DIR}/token-client.key" --cert "${TEST_DIR}/token-client.crt" -X POST -d "{\"trust_token\": ${token}}" "https://${LXD_ADDR}/1.0/certificates" &
+  curl -k -s --key "${TEST_DIR}/token-client2.key" --cert "${TEST_DIR}/token-client2.crt" -X POST -d "{\"trust_token\": ${token}}" "https://${LXD_ADDR}/1.0/certificates" &
+  wait
+  [ "$(lxc config trust list -f json | jq 'length')" -eq 1 ]
+
+  lxc config trust rm "$(lxc config trust list -f json | jq -r '.[].fingerprint')"
+  rm "${TEST_DIR}/token-client2.key" "${TEST_DIR}/token-client2.crt"
+
   # Generate new token
   echo foo | lxc config trust add -q --projects foo --restricted
 
@@ -139,6 +155,19 @@ test_remote_url_with_token() {
   # Unset token expiry
   lxc config unset core.remote_token_expiry
 
+  # Generate a token with its own expiry
+  token="$(lxc config trust add --name foo --expiry 5S | tail -n1)"
+  [ "$(lxc config trust list-tokens -f json | jq -r '.[] | select(.ClientName == "foo") | .ExpiresAt')" != "" ]
+
+  # This will cause the token to expire
+  sleep 5
+
+  # Try adding remote. This should fail.
+  ! lxc_remote remote add test "${token}" || false
+
+  # Tokens can't be restricted to missing projects
+  ! lxc config trust add --name foo --projects missing --restricted || false
+
   # Delete project
   lxc project delete foo
 }
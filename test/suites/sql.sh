@@ -21,6 +21,25 @@ test_sql() {
   # Multiple queries
   lxd sql global "SELECT * FROM config; SELECT * FROM instances" | grep -qxF "=> Query 0:"
 
+  # Read-only mode
+  lxd sql global --read-only "SELECT * FROM config" | grep -qF "user.foo"
+  ! lxd sql global --read-only "SELECT * FROM config; DELETE FROM config WHERE key='user.foo'" || false
+  lxd sql global "SELECT * FROM config" | grep -qF "user.foo"
+
+  # Local replica
+  lxd sql global --local "SELECT * FROM config" | grep -qF "user.foo"
+  ! lxd sql global --local "DELETE FROM config WHERE key='user.foo'" || false
+  ! lxd sql local --local "SELECT * FROM config" || false
+  lxd sql global --local .dump | grep -qF "user.foo"
+
+  # Timeout
+  lxd sql global --timeout 10 "SELECT * FROM config" | grep -qF "user.foo"
+
+  # Output formats
+  lxd sql global --format csv "SELECT key, value FROM config WHERE key='user.foo'" | grep -qxF "user.foo,bar"
+  [ "$(lxd sql global --format json "SELECT key, value FROM config WHERE key='user.foo'" | jq -r '.[0].rows[0][1]')" = "bar" ]
+  ! lxd sql global --format yaml "SELECT * FROM config" || false
+
   # Local database dump
   SQLITE_DUMP="${TEST_DIR}/dump.db"
   lxd sql local .dump | sqlite3 "${SQLITE_DUMP}"
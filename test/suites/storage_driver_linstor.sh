@@ -0,0 +1,129 @@
+test_storage_driver_linstor() {
+  # shellcheck disable=2039,3043
+  local LXD_STORAGE_DIR lxd_backend
+
+  lxd_backend=$(storage_backend "$LXD_DIR")
+  if [ "$lxd_backend" != "linstor" ]; then
+    return
+  fi
+
+  LXD_STORAGE_DIR=$(mktemp -d -p "${TEST_DIR}" XXXXXXXXX)
+  chmod +x "${LXD_STORAGE_DIR}"
+  spawn_lxd "${LXD_STORAGE_DIR}" false
+
+  (
+    set -e
+    # shellcheck disable=2030
+    LXD_DIR="${LXD_STORAGE_DIR}"
+
+    # shellcheck disable=SC1009
+    lxc storage create "lxdtest-$(basename "${LXD_DIR}")-pool1" linstor linstor.controller_connection="${LXD_LINSTOR_CONTROLLER_CONNECTION}" linstor.resource_group.name="lxdtest-$(basename "${LXD_DIR}")-pool1" linstor.resource_group.place_count=1 volume.size=25MiB
+    [ "$(lxc storage get "lxdtest-$(basename "${LXD_DIR}")-pool1" volatile.pool.pristine)" = "true" ]
+    [ "$(lxc storage get "lxdtest-$(basename "${LXD_DIR}")-pool1" linstor.resource_group.place_count)" = "1" ]
+    linstor --controllers "${LXD_LINSTOR_CONTROLLER_CONNECTION}" resource-group list | grep -F "lxdtest-$(basename "${LXD_DIR}")-pool1"
+
+    # Set default storage pool for image import.
+    lxc profile device add default root disk path="/" pool="lxdtest-$(basename "${LXD_DIR}")-pool1"
+
+    # Import image into default storage pool.
+    ensure_import_testimage
+
+    # Let LXD use an already existing resource group.
+    linstor --controllers "${LXD_LINSTOR_CONTROLLER_CONNECTION}" resource-group create "lxdtest-$(basename "${LXD_DIR}")-existing-resource-group" --place-count 1
+    lxc storage create "lxdtest-$(basename "${LXD_DIR}")-pool2" linstor linstor.controller_connection="${LXD_LINSTOR_CONTROLLER_CONNECTION}" linstor.resource_group.name="lxdtest-$(basename "${LXD_DIR}")-existing-resource-group" volume.size=25MiB
+    [ "$(lxc storage get "lxdtest-$(basename "${LXD_DIR}")-pool2" volatile.pool.pristine)" = "false" ]
+
+    # Test that no invalid linstor storage pool configuration keys can be set.
+    ! lxc storage create "lxdtest-$(basename "${LXD_DIR}")-invalid-linstor-pool-config" linstor linstor.controller_connection="${LXD_LINSTOR_CONTROLLER_CONNECTION}" lvm.vg_name=bla || false
+    ! lxc storage create "lxdtest-$(basename "${LXD_DIR}")-invalid-linstor-pool-config" linstor linstor.controller_connection="${LXD_LINSTOR_CONTROLLER_CONNECTION}" ceph.osd.pg_num=16 || false
+    ! lxc storage create "lxdtest-$(basename "${LXD_DIR}")-invalid-linstor-pool-config" linstor linstor.controller_connection="${LXD_LINSTOR_CONTROLLER_CONNECTION}" linstor.resource_group.place_count=0 || false
+    ! lxc storage create "lxdtest-$(basename "${LXD_DIR}")-invalid-linstor-pool-config" linstor linstor.controller_connection=bla || false
+    ! lxc storage create "lxdtest-$(basename "${LXD_DIR}")-invalid-linstor-pool-config" linstor || false
+
+    # Test that all valid linstor storage pool configuration keys can be set.
+    lxc storage create "lxdtest-$(basename "${LXD_DIR}")-valid-linstor-pool-config" linstor linstor.controller_connection="${LXD_LINSTOR_CONTROLLER_CONNECTION}" linstor.resource_group.name="lxdtest-$(basename "${LXD_DIR}")-valid-linstor-pool-config" linstor.resource_group.place_count=1 linstor.diskless=false volume.block.filesystem=ext4 volume.block.mount_options=discard volume.size=25MiB
+    lxc storage delete "lxdtest-$(basename "${LXD_DIR}")-valid-linstor-pool-config"
+    ! linstor --controllers "${LXD_LINSTOR_CONTROLLER_CONNECTION}" resource-group list | grep -F "lxdtest-$(basename "${LXD_DIR}")-valid-linstor-pool-config" || false
+
+    # The resource group of a storage pool can't be changed.
+    ! lxc storage set "lxdtest-$(basename "${LXD_DIR}")-pool1" linstor.resource_group.name=other || false
+    ! lxc storage set "lxdtest-$(basename "${LXD_DIR}")-pool1" linstor.resource_group.place_count=2 || false
+
+    # Muck around with some containers on various pools.
+    lxc init testimage c1pool1 -s "lxdtest-$(basename "${LXD_DIR}")-pool1"
+    lxc list -c b c1pool1 | grep "lxdtest-$(basename "${LXD_DIR}")-pool1"
+
+    lxc init testimage c2pool2 -s "lxdtest-$(basename "${LXD_DIR}")-pool2"
+    lxc list -c b c2pool2 | grep "lxdtest-$(basename "${LXD_DIR}")-pool2"
+
+    lxc launch testimage c3pool1 -s "lxdtest-$(basename "${LXD_DIR}")-pool1"
+    lxc list -c b c3pool1 | grep "lxdtest-$(basename "${LXD_DIR}")-pool1"
+
+    lxc launch testimage c4pool2 -s "lxdtest-$(basename "${LXD_DIR}")-pool2"
+    lxc list -c b c4pool2 | grep "lxdtest-$(basename "${LXD_DIR}")-pool2"
+
+    # Snapshots, restores and copies of containers.
+    lxc snapshot c3pool1 snap0
+    lxc exec c3pool1 -- touch /root/after-snap0
+    lxc restore c3pool1 snap0
+    ! lxc exec c3pool1 -- test -e /root/after-snap0 || false
+    lxc copy c3pool1/snap0 c5pool1
+    lxc copy c3pool1 c6pool2 -s "lxdtest-$(basename "${LXD_DIR}")-pool2"
+    lxc list -c b c6pool2 | grep "lxdtest-$(basename "${LXD_DIR}")-pool2"
+    lxc delete c3pool1/snap0
+
+    # Custom volumes.
+    lxc storage volume create "lxdtest-$(basename "${LXD_DIR}")-pool1" c1pool1
+    lxc storage volume attach "lxdtest-$(basename "${LXD_DIR}")-pool1" c1pool1 c1pool1 testDevice /opt
+    ! lxc storage volume attach "lxdtest-$(basename "${LXD_DIR}")-pool1" c1pool1 c1pool1 testDevice2 /opt || false
+    lxc storage volume detach "lxdtest-$(basename "${LXD_DIR}")-pool1" c1pool1 c1pool1
+
+    # Filesystem volumes can only be attached to a single instance at a time.
+    lxc storage volume create "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1
+    lxc storage volume attach "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1 c3pool1 testDevice /opt
+    lxc exec c3pool1 -- touch /opt/foo
+    ! lxc storage volume attach "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1 c4pool2 testDevice /opt || false
+    lxc storage volume detach "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1 c3pool1
+    lxc storage volume attach "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1 c4pool2 testDevice /opt
+    lxc exec c4pool2 -- test -e /opt/foo
+    lxc storage volume detach "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1 c4pool2
+
+    lxc storage volume snapshot "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1 snap0
+    lxc storage volume copy "lxdtest-$(basename "${LXD_DIR}")-pool2/c3pool1/snap0" "lxdtest-$(basename "${LXD_DIR}")-pool1/c3pool1-copy"
+    lxc storage volume rename "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1 c3pool1-renamed
+    lxc storage volume rename "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1-renamed c3pool1
+
+    # Volumes can only grow.
+    lxc storage volume create "lxdtest-$(basename "${LXD_DIR}")-pool1" block1 --type=block size=25MiB
+    lxc storage volume set "lxdtest-$(basename "${LXD_DIR}")-pool1" block1 size 50MiB
+    ! lxc storage volume set "lxdtest-$(basename "${LXD_DIR}")-pool1" block1 size 25MiB || false
+    lxc storage volume set "lxdtest-$(basename "${LXD_DIR}")-pool1" c1pool1 size 50MiB
+    ! lxc storage volume set "lxdtest-$(basename "${LXD_DIR}")-pool1" c1pool1 size 25MiB || false
+
+    lxc delete -f c1pool1
+    lxc delete -f c3pool1
+    lxc delete -f c5pool1
+
+    lxc delete -f c4pool2
+    lxc delete -f c2pool2
+    lxc delete -f c6pool2
+
+    lxc storage volume delete "lxdtest-$(basename "${LXD_DIR}")-pool1" c1pool1
+    lxc storage volume delete "lxdtest-$(basename "${LXD_DIR}")-pool1" c3pool1-copy
+    lxc storage volume delete "lxdtest-$(basename "${LXD_DIR}")-pool1" block1
+    lxc storage volume delete "lxdtest-$(basename "${LXD_DIR}")-pool2" c3pool1
+
+    lxc image delete testimage
+    lxc profile device remove default root
+    lxc storage delete "lxdtest-$(basename "${LXD_DIR}")-pool1"
+    lxc storage delete "lxdtest-$(basename "${LXD_DIR}")-pool2"
+
+    # Only the resource group created by LXD is removed along with the storage pool.
+    ! linstor --controllers "${LXD_LINSTOR_CONTROLLER_CONNECTION}" resource-group list | grep -F "lxdtest-$(basename "${LXD_DIR}")-pool1" || false
+    linstor --controllers "${LXD_LINSTOR_CONTROLLER_CONNECTION}" resource-group list | grep -F "lxdtest-$(basename "${LXD_DIR}")-existing-resource-group"
+    linstor --controllers "${LXD_LINSTOR_CONTROLLER_CONNECTION}" resource-group delete "lxdtest-$(basename "${LXD_DIR}")-existing-resource-group"
+  )
+
+  # shellcheck disable=SC2031
+  kill_lxd "${LXD_STORAGE_DIR}"
+}
@@ -29,6 +29,10 @@ test_storage_local_volume_handling() {
 
     lxc storage create "${pool_base}-dir" dir
 
+    if storage_backend_available "linstor"; then
+      lxc storage create "${pool_base}-linstor" linstor linstor.controller_connection="${LXD_LINSTOR_CONTROLLER_CONNECTION}" linstor.resource_group.name="${pool_base}-linstor" linstor.resource_group.place_count=1 volume.size=25MiB
+    fi
+
     if storage_backend_available "lvm"; then
       lxc storage create "${pool_base}-lvm" lvm volume.size=25MiB
     fi
@@ -52,6 +56,10 @@ test_storage_local_volume_handling() {
       pool_opts="volume.size=25MiB ceph.osd.pg_num=16"
     fi
 
+    if [ "$driver" = "linstor" ]; then
+      pool_opts="linstor.controller_connection=${LXD_LINSTOR_CONTROLLER_CONNECTION} linstor.resource_group.name=${pool}1 linstor.resource_group.place_count=1 volume.size=25MiB"
+    fi
+
     if [ "$driver" = "lvm" ]; then
       pool_opts="volume.size=25MiB"
     fi
@@ -165,8 +173,8 @@ test_storage_local_volume_handling() {
     lxc storage volume delete "${pool}1" vol1
     lxc storage delete "${pool}1"
 
-    for source_driver in "btrfs" "ceph" "cephfs" "dir" "lvm" "zfs"; do
-      for target_driver in "btrfs" "ceph" "cephfs" "dir" "lvm" "zfs"; do
+    for source_driver in "btrfs" "ceph" "cephfs" "dir" "linstor" "lvm" "zfs"; do
+      for target_driver in "btrfs" "ceph" "cephfs" "dir" "linstor" "lvm" "zfs"; do
         # shellcheck disable=SC2235
         if [ "$source_driver" != "$target_driver" ] \
             && ([ "$lxd_backend" = "$source_driver" ] || ([ "$lxd_backend" = "ceph" ] && [ "$source_driver" = "cephfs" ] && [ -n "${LXD_CEPH_CEPHFS:-}" ])) \
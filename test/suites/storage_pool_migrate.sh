@@ -0,0 +1,69 @@
+test_storage_pool_migrate() {
+  ensure_import_testimage
+
+  lxc storage create src dir
+  lxc storage create dst dir source="${TEST_DIR}/storage-pool-migrate"
+
+  lxc init testimage c1 -s src
+  lxc snapshot c1
+  lxc init testimage c2 -s src
+  lxc storage volume create src vol1
+  lxc storage volume snapshot src vol1
+
+  # The dry run lists the instance volumes first, followed by the custom volumes.
+  [ "$(lxc storage migrate src dst --dry-run --format csv)" = "default,container,c1,1
+default,container,c2,0
+default,custom,vol1,1" ]
+
+  # Running instances can't be moved.
+  lxc start c2
+  ! lxc storage migrate src dst || false
+  lxc stop c2 --force
+
+  # Custom volumes with the same name on the target pool are rejected before anything is moved.
+  lxc storage volume create dst vol1
+  ! lxc storage migrate src dst || false
+  lxc storage volume delete dst vol1
+  [ "$(lxc config device get c1 root pool)" = "src" ]
+
+  # Make creating custom volumes fail on the target pool, after the instances were moved.
+  rm -rf "${TEST_DIR}/storage-pool-migrate/custom"
+  touch "${TEST_DIR}/storage-pool-migrate/custom"
+
+  ! lxc storage migrate src dst || false
+
+  # The instances that were already moved are moved back.
+  [ "$(lxc config device get c1 root pool)" = "src" ]
+  [ "$(lxc config device get c2 root pool)" = "src" ]
+  lxc storage volume show src container/c1
+  lxc storage volume show src container/c1/snap0
+  lxc storage volume show src container/c2
+  lxc storage volume show src vol1
+  ! lxc storage volume show dst container/c1 || false
+  ! lxc storage volume show dst container/c2 || false
+
+  # The source pool can be used again after the failed migration.
+  lxc storage volume create src vol2
+  lxc storage volume delete src vol2
+
+  rm "${TEST_DIR}/storage-pool-migrate/custom"
+  mkdir "${TEST_DIR}/storage-pool-migrate/custom"
+
+  lxc storage migrate src dst
+
+  [ "$(lxc config device get c1 root pool)" = "dst" ]
+  [ "$(lxc config device get c2 root pool)" = "dst" ]
+  lxc storage volume show dst container/c1/snap0
+  lxc storage volume show dst vol1/snap0
+  [ -z "$(lxc storage volume list src --format csv)" ]
+
+  # Instances can be started from the target pool.
+  lxc start c1
+  lxc stop c1 --force
+
+  # Cleanup
+  lxc delete c1 c2
+  lxc storage volume delete dst vol1
+  lxc storage delete src
+  lxc storage delete dst
+}
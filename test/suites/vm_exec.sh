@@ -0,0 +1,44 @@
+test_vm_exec_agent_restart() {
+  image="${LXD_VM_TEST_IMAGE:-""}"
+
+  if [ "${image}" = "" ]; then
+    echo "==> SKIP: No virtual machine test image specified"
+    return
+  fi
+
+  lxc launch "${image}" v1 --vm
+
+  # Wait for the lxd-agent to be available.
+  for _ in $(seq 90); do
+    lxc exec v1 -- true && break
+    sleep 1
+  done
+
+  lxc exec v1 -- true
+
+  # A non-interactive command keeps running while the lxd-agent restarts and its output is resumed.
+  (lxc exec v1 -- sh -c 'echo before; sleep 20; echo after; exit 3' > "${TEST_DIR}/vm-exec.out" 2>&1; echo "$?" > "${TEST_DIR}/vm-exec.status") &
+  pid=$!
+
+  sleep 5
+  lxc exec v1 -- systemctl restart lxd-agent
+
+  wait "${pid}"
+  [ "$(cat "${TEST_DIR}/vm-exec.status")" = "3" ]
+  [ "$(cat "${TEST_DIR}/vm-exec.out")" = "$(printf "before\nafter")" ]
+
+  # The session is removed once its output has been sent.
+  [ "$(lxc exec v1 -- sh -c 'ls /run/lxd-agent-exec | wc -l')" = "1" ]
+
+  # The lxd-agent service doesn't keep processes other than the exec sessions around when restarted.
+  lxc exec v1 -- systemctl restart lxd-agent
+  for _ in $(seq 30); do
+    lxc exec v1 -- true && break
+    sleep 1
+  done
+
+  [ "$(lxc exec v1 -- systemctl show -p KillMode --value lxd-agent)" = "control-group" ]
+
+  rm "${TEST_DIR}/vm-exec.out" "${TEST_DIR}/vm-exec.status"
+  lxc delete -f v1
+}